@@ -0,0 +1,557 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build ignore
+// +build ignore
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	flagIni  = flag.String("ini", "custom/conf/app.example.ini", "example configuration")
+	flagDocs = flag.String("docs", "docs/content/doc/advanced/config-cheat-sheet.en-us.md", "configuration cheat sheet")
+	flagSrc  = flag.String("src", "modules/setting", "directory of the setting sources")
+	flagEnv  = flag.String("env-docs", "docs/content/doc/advanced/environment-variables.en-us.md", "environment variables documentation")
+	flagOut  = flag.String("o", "modules/setting/config_keys.go", "out")
+)
+
+const (
+	envDocsBegin = "<!-- BEGIN GENERATED CONFIGURATION VARIABLES -->"
+	envDocsEnd   = "<!-- END GENERATED CONFIGURATION VARIABLES -->"
+)
+
+var (
+	iniSectionPattern  = regexp.MustCompile(`^;?\s*\[([^\]]+)\]`)
+	iniKeyPattern      = regexp.MustCompile(`^;?\s*([A-Z][A-Z0-9_]*)\s*=`)
+	docsSectionPattern = regexp.MustCompile("^#+ .*\\([`']([^`']+)[`']")
+	docsKeyPattern     = regexp.MustCompile("^- `([A-Z][A-Z0-9_]*)`")
+)
+
+// groupPrefixes are the prefixes of sections with user defined names. The keys of
+// all these sections are collected in a "<prefix>*" section.
+var groupPrefixes = []string{"cron.", "ide.", "log.", "markup.", "queue.", "storage."}
+
+func main() {
+	flag.Parse()
+
+	keys := map[string]map[string]bool{}
+	add := func(section, key string) {
+		if section == "" {
+			section = "DEFAULT"
+		}
+		if keys[section] == nil {
+			keys[section] = map[string]bool{}
+		}
+		keys[section][key] = true
+	}
+
+	// the keys of the commented out example sections, e.g. ;[storage.my_minio]
+	examples := map[string]map[string]bool{}
+	addExample := func(section, key string) {
+		if examples[section] == nil {
+			examples[section] = map[string]bool{}
+		}
+		examples[section][key] = true
+	}
+
+	if err := parse(*flagIni, iniSectionPattern, iniKeyPattern, add, addExample); err != nil {
+		log.Fatalf("Unable to parse %s: %v", *flagIni, err)
+	}
+	if err := parse(*flagDocs, docsSectionPattern, docsKeyPattern, add, add); err != nil {
+		log.Fatalf("Unable to parse %s: %v", *flagDocs, err)
+	}
+
+	// the examples of sections with user defined names only document the keys of their group
+	for section, sectionKeys := range examples {
+		if keys[section] == nil {
+			if prefix := groupPrefixOf(section); prefix != "" {
+				section = prefix + "*"
+			}
+		}
+		for key := range sectionKeys {
+			add(section, key)
+		}
+	}
+
+	for section, sectionKeys := range keys {
+		for _, prefix := range groupPrefixes {
+			if section == strings.TrimSuffix(prefix, ".") || (strings.HasPrefix(section, prefix) && section != prefix+"*") {
+				for key := range sectionKeys {
+					add(prefix+"*", key)
+				}
+			}
+		}
+	}
+
+	types := map[string]map[string]string{}
+	if err := collectTypes(*flagSrc, func(section, key, typ string) {
+		if section == "" {
+			section = "DEFAULT"
+		}
+		// only the documented keys are of interest
+		if !keys[section][key] {
+			return
+		}
+		if types[section] == nil {
+			types[section] = map[string]string{}
+		}
+		types[section][key] = typ
+		for _, prefix := range groupPrefixes {
+			if section == strings.TrimSuffix(prefix, ".") {
+				if types[prefix+"*"] == nil {
+					types[prefix+"*"] = map[string]string{}
+				}
+				types[prefix+"*"][key] = typ
+			}
+		}
+	}); err != nil {
+		log.Fatalf("Unable to collect the types of the settings from %s: %v", *flagSrc, err)
+	}
+
+	sections := make([]string, 0, len(keys))
+	for section := range keys {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(`// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Code generated by build/generate-config-keys.go. DO NOT EDIT.
+
+package setting
+
+// knownConfigKeys are the keys of the sections documented in the example configuration and the cheat sheet
+var knownConfigKeys = map[string][]string{
+`)
+	for _, section := range sections {
+		sectionKeys := make([]string, 0, len(keys[section]))
+		for key := range keys[section] {
+			sectionKeys = append(sectionKeys, fmt.Sprintf("%q", key))
+		}
+		sort.Strings(sectionKeys)
+		fmt.Fprintf(buf, "%q: {%s},\n", section, strings.Join(sectionKeys, ", "))
+	}
+	buf.WriteString(`}
+
+// configKeyTypes are the types of the keys which are not read as strings, collected from
+// the setting structs and the calls reading the keys
+var configKeyTypes = map[string]map[string]string{
+`)
+	for _, section := range sections {
+		if len(types[section]) == 0 {
+			continue
+		}
+		sectionKeys := make([]string, 0, len(types[section]))
+		for key := range types[section] {
+			sectionKeys = append(sectionKeys, key)
+		}
+		sort.Strings(sectionKeys)
+		fmt.Fprintf(buf, "%q: {\n", section)
+		for _, key := range sectionKeys {
+			fmt.Fprintf(buf, "%q: %q,\n", key, types[section][key])
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("}\n")
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("Unable to format generated code: %v", err)
+	}
+	if err := ioutil.WriteFile(*flagOut, out, 0644); err != nil {
+		log.Fatalf("Unable to write %s: %v", *flagOut, err)
+	}
+
+	if err := writeEnvDocs(*flagEnv, sections, keys, types); err != nil {
+		log.Fatalf("Unable to write %s: %v", *flagEnv, err)
+	}
+}
+
+// groupPrefixOf returns the prefix of groupPrefixes a section with a user defined name starts with
+func groupPrefixOf(section string) string {
+	for _, prefix := range groupPrefixes {
+		if strings.HasPrefix(section, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// parse calls add for the keys of the sections of a file, or addExample for the keys of the
+// sections which are commented out
+func parse(filename string, sectionPattern, keyPattern *regexp.Regexp, add, addExample func(section, key string)) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	section := ""
+	example := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if m := sectionPattern.FindStringSubmatch(line); m != nil {
+			section = strings.TrimSuffix(m[1], ".name")
+			if strings.HasSuffix(section, ".x") {
+				section = strings.TrimSuffix(section, ".x")
+			}
+			example = strings.HasPrefix(line, ";")
+			continue
+		}
+		if m := keyPattern.FindStringSubmatch(line); m != nil {
+			if example {
+				addExample(section, m[1])
+			} else {
+				add(section, m[1])
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// typeOfExpr maps the type of a struct field to the type of the configuration value
+func typeOfExpr(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "bool":
+			return "bool"
+		case "int", "int64", "int32", "uint", "uint64", "uint32":
+			return "int"
+		case "float64", "float32":
+			return "float"
+		}
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok && pkg.Name == "time" && t.Sel.Name == "Duration" {
+			return "duration"
+		}
+	}
+	return ""
+}
+
+// typeOfMustCall maps the methods of ini.Key to the type of the configuration value
+var typeOfMustCall = map[string]string{
+	"MustBool":     "bool",
+	"MustInt":      "int",
+	"MustInt64":    "int",
+	"MustUint":     "int",
+	"MustUint64":   "int",
+	"MustFloat64":  "float",
+	"MustDuration": "duration",
+}
+
+// snackCase is ini.SnackCase which maps the names of struct fields to keys
+func snackCase(raw string) string {
+	newstr := make([]rune, 0, len(raw))
+	for i, chr := range raw {
+		if isUpper := 'A' <= chr && chr <= 'Z'; isUpper {
+			if i > 0 {
+				newstr = append(newstr, '_')
+			}
+		}
+		newstr = append(newstr, unicode.ToUpper(chr))
+	}
+	return string(newstr)
+}
+
+// collectTypes finds the types of the keys in the setting sources. The types are taken
+// from the Must* calls reading a key of a section, e.g. Cfg.Section("server").Key("X").MustBool(),
+// and from the fields of the structs sections are mapped to with MapTo.
+func collectTypes(dir string, add func(section, key, typ string)) error {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return err
+	}
+	pkg, ok := pkgs["setting"]
+	if !ok {
+		return fmt.Errorf("package setting not found")
+	}
+
+	// the struct types of the package level variables and types
+	structs := map[string]*ast.StructType{}
+	namedVars := map[string]ast.Expr{}
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if st, ok := spec.Type.(*ast.StructType); ok {
+						structs[spec.Name.Name] = st
+					}
+				case *ast.ValueSpec:
+					for i, name := range spec.Names {
+						var typ ast.Expr = spec.Type
+						if typ == nil && i < len(spec.Values) {
+							if lit, ok := spec.Values[i].(*ast.CompositeLit); ok {
+								typ = lit.Type
+							}
+						}
+						if st, ok := typ.(*ast.StructType); ok {
+							structs[name.Name] = st
+						} else if typ != nil {
+							// resolved after all type declarations have been seen
+							namedVars[name.Name] = typ
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for name, typ := range namedVars {
+		if st := resolveStruct(structs, typ); st != nil {
+			structs[name] = st
+		}
+	}
+
+	// the sections mapped to structs, e.g. Cfg.Section("git").MapTo(&Git)
+	type mapping struct {
+		section string
+		target  ast.Expr
+	}
+	mapped := []mapping{}
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			// the variables holding a section, e.g. sec := Cfg.Section("server")
+			sectionVars := map[string]string{}
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.AssignStmt:
+					if len(n.Lhs) == len(n.Rhs) {
+						for i, lhs := range n.Lhs {
+							if ident, ok := lhs.(*ast.Ident); ok {
+								if section, ok := sectionOf(sectionVars, n.Rhs[i]); ok {
+									sectionVars[ident.Name] = section
+								}
+							}
+						}
+					}
+				case *ast.CallExpr:
+					sel, ok := n.Fun.(*ast.SelectorExpr)
+					if !ok {
+						return true
+					}
+					if typ, ok := typeOfMustCall[sel.Sel.Name]; ok {
+						if section, key, ok := keyOf(sectionVars, sel.X); ok {
+							add(section, key, typ)
+						}
+					} else if sel.Sel.Name == "MapTo" && len(n.Args) == 1 {
+						if section, ok := sectionOf(sectionVars, sel.X); ok {
+							if unary, ok := n.Args[0].(*ast.UnaryExpr); ok && unary.Op == token.AND {
+								mapped = append(mapped, mapping{section, unary.X})
+							}
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	for _, m := range mapped {
+		addStructTypes(structs, m.section, resolveTarget(structs, m.target), add)
+	}
+	return nil
+}
+
+// addStructTypes adds the types of the fields of a struct mapped to a section. The fields
+// which are structs themselves are mapped to the section named by their ini tag, e.g.
+// Git.Timeout to [git.timeout].
+func addStructTypes(structs map[string]*ast.StructType, section string, st *ast.StructType, add func(section, key, typ string)) {
+	if st == nil {
+		return
+	}
+	for _, field := range st.Fields.List {
+		iniTag := ""
+		if field.Tag != nil {
+			tag, _ := strconv.Unquote(field.Tag.Value)
+			iniTag = strings.Split(reflect.StructTag(tag).Get("ini"), ",")[0]
+		}
+		if iniTag == "-" {
+			continue
+		}
+		if nested := resolveStruct(structs, field.Type); nested != nil {
+			if iniTag != "" {
+				addStructTypes(structs, iniTag, nested, add)
+			}
+			continue
+		}
+		typ := typeOfExpr(field.Type)
+		if typ == "" {
+			continue
+		}
+		for _, name := range field.Names {
+			key := snackCase(name.Name)
+			if iniTag != "" {
+				key = iniTag
+			}
+			add(section, key, typ)
+		}
+	}
+}
+
+// sectionOf returns the name of the section expr refers to
+func sectionOf(sectionVars map[string]string, expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		section, ok := sectionVars[e.Name]
+		return section, ok
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Section" || len(e.Args) != 1 {
+			return "", false
+		}
+		if recv, ok := sel.X.(*ast.Ident); !ok || recv.Name != "Cfg" {
+			return "", false
+		}
+		lit, ok := e.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return "", false
+		}
+		section, err := strconv.Unquote(lit.Value)
+		return section, err == nil
+	}
+	return "", false
+}
+
+// keyOf returns section and key of an expression like sec.Key("KEY")
+func keyOf(sectionVars map[string]string, expr ast.Expr) (string, string, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return "", "", false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Key" {
+		return "", "", false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", "", false
+	}
+	key, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", "", false
+	}
+	section, ok := sectionOf(sectionVars, sel.X)
+	return section, key, ok
+}
+
+func resolveStruct(structs map[string]*ast.StructType, expr ast.Expr) *ast.StructType {
+	switch t := expr.(type) {
+	case *ast.StructType:
+		return t
+	case *ast.Ident:
+		return structs[t.Name]
+	case *ast.StarExpr:
+		return resolveStruct(structs, t.X)
+	}
+	return nil
+}
+
+// resolveTarget returns the struct type of a MapTo target like Repository.Editor
+func resolveTarget(structs map[string]*ast.StructType, expr ast.Expr) *ast.StructType {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return structs[e.Name]
+	case *ast.SelectorExpr:
+		parent := resolveTarget(structs, e.X)
+		if parent == nil {
+			return nil
+		}
+		for _, field := range parent.Fields.List {
+			for _, name := range field.Names {
+				if name.Name == e.Sel.Name {
+					return resolveStruct(structs, field.Type)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// encodeEnvSection encodes a section name for an environment variable, the reverse
+// of setting.DecodeEnvSectionKey
+func encodeEnvSection(section string) string {
+	buf := &strings.Builder{}
+	for _, b := range []byte(strings.ToUpper(section)) {
+		if ('A' <= b && b <= 'Z') || ('0' <= b && b <= '9') || b == '_' {
+			buf.WriteByte(b)
+			continue
+		}
+		fmt.Fprintf(buf, "_0X%02X_", b)
+	}
+	return buf.String()
+}
+
+// writeEnvDocs lists the environment variables of all documented keys between the markers of the documentation
+func writeEnvDocs(filename string, sections []string, keys map[string]map[string]bool, types map[string]map[string]string) error {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	begin := bytes.Index(content, []byte(envDocsBegin))
+	end := bytes.Index(content, []byte(envDocsEnd))
+	if begin < 0 || end < begin {
+		return fmt.Errorf("markers %s and %s not found", envDocsBegin, envDocsEnd)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.Write(content[:begin+len(envDocsBegin)])
+	buf.WriteString("\n\n")
+	for _, section := range sections {
+		if strings.HasSuffix(section, "*") {
+			continue
+		}
+		fmt.Fprintf(buf, "### `%s`\n\n", section)
+		sectionKeys := make([]string, 0, len(keys[section]))
+		for key := range keys[section] {
+			sectionKeys = append(sectionKeys, key)
+		}
+		sort.Strings(sectionKeys)
+		for _, key := range sectionKeys {
+			typ := types[section][key]
+			if typ == "" {
+				typ = "string"
+			}
+			fmt.Fprintf(buf, "- `GITEA__%s__%s` (%s)\n", encodeEnvSection(section), key, typ)
+		}
+		buf.WriteString("\n")
+	}
+	buf.Write(content[end:])
+	return ioutil.WriteFile(filename, buf.Bytes(), 0644)
+}
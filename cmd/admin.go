@@ -9,9 +9,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"net/http"
 	"os"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/auth/oauth2"
@@ -19,9 +22,13 @@ import (
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
 	pwd "code.gitea.io/gitea/modules/password"
+	"code.gitea.io/gitea/modules/private"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/task"
 
+	"github.com/dustin/go-humanize"
 	"github.com/urfave/cli"
 )
 
@@ -32,7 +39,10 @@ var (
 		Usage: "Command line interface to perform common administrative operations",
 		Subcommands: []cli.Command{
 			subcmdUser,
+			subcmdRepo,
 			subcmdRepoSyncReleases,
+			subcmdRepoGC,
+			subcmdReindex,
 			subcmdRegenerate,
 			subcmdAuth,
 			subcmdSendMail,
@@ -46,6 +56,7 @@ var (
 			microcmdUserCreate,
 			microcmdUserList,
 			microcmdUserChangePassword,
+			microcmdUserResetTwoFactor,
 			microcmdUserDelete,
 		},
 	}
@@ -122,6 +133,22 @@ var (
 				Value: "",
 				Usage: "New password to set for user",
 			},
+			cli.BoolFlag{
+				Name:  "must-change-password",
+				Usage: "Require the user to change the password at the next sign in",
+			},
+		},
+	}
+
+	microcmdUserResetTwoFactor = cli.Command{
+		Name:   "reset-2fa",
+		Usage:  "Remove all second factors (TOTP, U2F and WebAuthn) of a user",
+		Action: runResetTwoFactor,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "username,u",
+				Usage: "The user to reset the second factors of",
+			},
 		},
 	}
 
@@ -145,12 +172,80 @@ var (
 		Action: runDeleteUser,
 	}
 
+	subcmdRepo = cli.Command{
+		Name:  "repo",
+		Usage: "Modify repositories",
+		Subcommands: []cli.Command{
+			microcmdRepoMigrateLFS,
+		},
+	}
+
+	microcmdRepoMigrateLFS = cli.Command{
+		Name:  "migrate-lfs",
+		Usage: "Move the files of a repository larger than a size into LFS",
+		Description: `Rewrites the history of all branches, tags and pull requests of the repository with "git lfs migrate import",
+which requires git-lfs on the server. The files above the size are replaced with LFS pointers in every commit,
+so all commit IDs change and clones of the repository have to be cloned again. The repository is in maintenance
+mode until the migration is finished.`,
+		Action: runRepoMigrateLFS,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "repo,r",
+				Usage: "Repository to migrate as owner/name",
+			},
+			cli.StringFlag{
+				Name:  "above",
+				Usage: "Files larger than this size are moved into LFS, e.g. 10 MB",
+			},
+		},
+	}
+
 	subcmdRepoSyncReleases = cli.Command{
 		Name:   "repo-sync-releases",
 		Usage:  "Synchronize repository releases with tags",
 		Action: runRepoSyncReleases,
 	}
 
+	subcmdRepoGC = cli.Command{
+		Name:   "repo-gc",
+		Usage:  "Run git gc on repositories",
+		Action: runRepoGC,
+		Flags: []cli.Flag{
+			cli.StringSliceFlag{
+				Name:  "repo,r",
+				Usage: "Repository to run git gc on as owner/name - may be repeated, defaults to all repositories",
+			},
+			cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "Timeout for git gc of a single repository - defaults to [git.timeout] GC",
+			},
+			cli.StringFlag{
+				Name:  "args",
+				Usage: "Arguments passed to git gc - defaults to [git] GC_ARGS",
+			},
+		},
+	}
+
+	subcmdReindex = cli.Command{
+		Name:   "reindex",
+		Usage:  "Rebuild the issue or code indexes of the running Gitea",
+		Action: runReindex,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "type,t",
+				Value: "issues",
+				Usage: "Indexer to rebuild: issues or code",
+			},
+			cli.StringSliceFlag{
+				Name:  "repo,r",
+				Usage: "Repository to reindex as owner/name - may be repeated, defaults to all repositories",
+			},
+			cli.BoolFlag{
+				Name: "debug",
+			},
+		},
+	}
+
 	subcmdRegenerate = cli.Command{
 		Name:  "regenerate",
 		Usage: "Regenerate specific files",
@@ -184,6 +279,7 @@ var (
 			cmdAuthUpdateLdapSimpleAuth,
 			microcmdAuthList,
 			microcmdAuthDelete,
+			microcmdAuthMigrateUsers,
 		},
 	}
 
@@ -231,6 +327,35 @@ var (
 		Action: runDeleteAuth,
 	}
 
+	microcmdAuthMigrateUsers = cli.Command{
+		Name:  "migrate-users",
+		Usage: "Migrate the users of an auth source to another one, keeping their SSH keys, tokens and memberships",
+		Flags: []cli.Flag{
+			cli.Int64Flag{
+				Name:  "from",
+				Usage: "ID of the authentication source the users are migrated from, 0 for the local users",
+			},
+			cli.Int64Flag{
+				Name:  "to",
+				Usage: "ID of the authentication source the users are migrated to, 0 for the local users",
+			},
+			cli.StringFlag{
+				Name:  "map",
+				Usage: "The identity used as login name in the target source: username or email",
+				Value: string(models.LoginSourceMappingUsername),
+			},
+			cli.StringSliceFlag{
+				Name:  "username,u",
+				Usage: "Only migrate these users, can be given several times",
+			},
+			cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Only show the users which would be migrated and the conflicts",
+			},
+		},
+		Action: runMigrateAuthUsers,
+	}
+
 	oauthCLIFlags = []cli.Flag{
 		cli.StringFlag{
 			Name:  "name",
@@ -353,7 +478,13 @@ func runChangePassword(c *cli.Context) error {
 		return err
 	}
 
-	if err = models.UpdateUserCols(user, "passwd", "passwd_hash_algo", "salt"); err != nil {
+	cols := []string{"passwd", "passwd_hash_algo", "salt"}
+	if c.IsSet("must-change-password") {
+		user.MustChangePassword = c.Bool("must-change-password")
+		cols = append(cols, "must_change_password")
+	}
+
+	if err = models.UpdateUserCols(user, cols...); err != nil {
 		return err
 	}
 
@@ -361,6 +492,29 @@ func runChangePassword(c *cli.Context) error {
 	return nil
 }
 
+func runResetTwoFactor(c *cli.Context) error {
+	if err := argsSet(c, "username"); err != nil {
+		return err
+	}
+
+	if err := initDB(); err != nil {
+		return err
+	}
+
+	user, err := models.GetUserByName(c.String("username"))
+	if err != nil {
+		return err
+	}
+
+	cnt, err := models.DeleteAllTwoFactorByUID(user.ID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed %d second factors of %s\n", cnt, user.Name)
+	return nil
+}
+
 func runCreateUser(c *cli.Context) error {
 	if err := argsSet(c, "email"); err != nil {
 		return err
@@ -578,6 +732,104 @@ func getReleaseCount(id int64) (int64, error) {
 	)
 }
 
+func runRepoGC(c *cli.Context) error {
+	if err := initDB(); err != nil {
+		return err
+	}
+
+	timeout := time.Duration(setting.Git.Timeout.GC) * time.Second
+	if c.IsSet("timeout") {
+		timeout = c.Duration("timeout")
+	}
+	args := setting.Git.GCArgs
+	if c.IsSet("args") {
+		args = strings.Fields(c.String("args"))
+	}
+
+	ctx := graceful.GetManager().ShutdownContext()
+	repoNames := c.StringSlice("repo")
+	if len(repoNames) == 0 {
+		return repo_module.GitGcRepos(ctx, timeout, args...)
+	}
+
+	for _, fullName := range repoNames {
+		parts := strings.SplitN(fullName, "/", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("Repository must be given as owner/name: %q", fullName)
+		}
+		repo, err := models.GetRepositoryByOwnerAndName(parts[0], parts[1])
+		if err != nil {
+			return err
+		}
+		if err := repo_module.GitGcRepo(ctx, repo, timeout, args...); err != nil {
+			return err
+		}
+		fmt.Printf("Garbage collected %s\n", repo.FullName())
+	}
+	return nil
+}
+
+func runRepoMigrateLFS(c *cli.Context) error {
+	if err := argsSet(c, "repo", "above"); err != nil {
+		return err
+	}
+	above, err := humanize.ParseBytes(c.String("above"))
+	if err != nil || above == 0 || above > math.MaxInt64 {
+		return fmt.Errorf("Invalid size %q", c.String("above"))
+	}
+	parts := strings.SplitN(c.String("repo"), "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("Repository must be given as owner/name: %q", c.String("repo"))
+	}
+
+	if err := initDB(); err != nil {
+		return err
+	}
+	if err := storage.Init(); err != nil {
+		return err
+	}
+	repo, err := models.GetRepositoryByOwnerAndName(parts[0], parts[1])
+	if err != nil {
+		return err
+	}
+	if migrating, err := task.IsMigratingToLFS(repo.ID); err != nil {
+		return err
+	} else if migrating {
+		return fmt.Errorf("The files of %s are already being moved into LFS", repo.FullName())
+	}
+	doer, err := models.GetAdminUser()
+	if err != nil {
+		return err
+	}
+
+	t, err := task.CreateMigrateLFSTask(doer, repo, task.MigrateToLFSOptions{Above: int64(above)})
+	if err != nil {
+		return err
+	}
+	if err := task.RunMigrateLFSTask(t, func(message string) {
+		fmt.Println(message)
+	}); err != nil {
+		return err
+	}
+	fmt.Printf("Files of %s larger than %s moved into LFS\n", repo.FullName(), humanize.Bytes(above))
+	return nil
+}
+
+func runReindex(c *cli.Context) error {
+	setup("manager", c.Bool("debug"))
+	statusCode, msg := private.Reindex(c.String("type"), c.StringSlice("repo"))
+	switch statusCode {
+	case http.StatusOK:
+	case http.StatusInternalServerError:
+		fail("InternalServerError", msg)
+	default:
+		fail(msg, "")
+	}
+
+	fmt.Fprintln(os.Stdout, msg)
+	return nil
+}
+
 func runRegenerateHooks(c *cli.Context) error {
 	if err := initDB(); err != nil {
 		return err
@@ -746,3 +998,45 @@ func runDeleteAuth(c *cli.Context) error {
 
 	return models.DeleteSource(source)
 }
+
+func runMigrateAuthUsers(c *cli.Context) error {
+	if !c.IsSet("from") || !c.IsSet("to") {
+		return fmt.Errorf("--from and --to flags are required")
+	}
+
+	if err := initDB(); err != nil {
+		return err
+	}
+
+	opts := &models.MigrateLoginSourceOptions{
+		FromSourceID: c.Int64("from"),
+		ToSourceID:   c.Int64("to"),
+		Mapping:      models.LoginSourceMapping(c.String("map")),
+		Usernames:    c.StringSlice("username"),
+	}
+	var migration *models.LoginSourceMigration
+	var err error
+	if c.Bool("dry-run") {
+		migration, err = models.PreviewLoginSourceMigration(opts)
+	} else {
+		migration, err = models.MigrateLoginSource(opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', 0)
+	fmt.Fprintf(w, "ID\tUsername\tLogin name\tConflict\n")
+	for _, mu := range migration.Users {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", mu.User.ID, mu.User.Name, mu.LoginName, mu.Conflict)
+	}
+	w.Flush()
+
+	conflicts := len(migration.Conflicts())
+	if c.Bool("dry-run") {
+		fmt.Printf("%d users would be migrated, %d users could not be migrated\n", len(migration.Users)-conflicts, conflicts)
+	} else {
+		fmt.Printf("%d users migrated, %d users could not be migrated\n", len(migration.Users)-conflicts, conflicts)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/urfave/cli"
+)
+
+// CmdConfig represents the available config sub-command.
+var CmdConfig = cli.Command{
+	Name:  "config",
+	Usage: "Work with the configuration",
+	Subcommands: []cli.Command{
+		subcmdConfigValidate,
+	},
+}
+
+var subcmdConfigValidate = cli.Command{
+	Name:  "validate",
+	Usage: "Check the configuration for problems",
+	Description: `Checks the configuration file for unknown sections and keys, conflicting settings,
+insecure values and paths which do not exist. Exits with status 1 if problems were found.`,
+	Action: runConfigValidate,
+}
+
+func runConfigValidate(ctx *cli.Context) error {
+	setting.NewContext()
+
+	warnings := setting.ValidateConfig()
+	if len(warnings) == 0 {
+		fmt.Printf("No problems found in %s\n", setting.CustomConf)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tLOCATION\tPROBLEM")
+	for _, warning := range warnings {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", warning.Type, warning.Location(), warning.Message)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return cli.NewExitError(fmt.Sprintf("Found %d problems in %s", len(warnings), setting.CustomConf), 1)
+}
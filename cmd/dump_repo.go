@@ -22,14 +22,14 @@ import (
 // CmdDumpRepository represents the available dump repository sub-command.
 var CmdDumpRepository = cli.Command{
 	Name:        "dump-repo",
-	Usage:       "Dump the repository from git/github/gitea/gitlab",
+	Usage:       "Dump the repository from git/github/gitea/gitlab/bitbucketserver",
 	Description: "This is a command for dumping the repository data.",
 	Action:      runDumpRepository,
 	Flags: []cli.Flag{
 		cli.StringFlag{
 			Name:  "git_service",
 			Value: "",
-			Usage: "Git service, git, github, gitea, gitlab. If clone_addr could be recognized, this could be ignored.",
+			Usage: "Git service, git, github, gitea, gitlab, bitbucketserver. If clone_addr could be recognized, this could be ignored.",
 		},
 		cli.StringFlag{
 			Name:  "repo_dir, r",
@@ -39,7 +39,7 @@ var CmdDumpRepository = cli.Command{
 		cli.StringFlag{
 			Name:  "clone_addr",
 			Value: "",
-			Usage: "The URL will be clone, currently could be a git/github/gitea/gitlab http/https URL",
+			Usage: "The URL will be clone, currently could be a git/github/gitea/gitlab/bitbucketserver http/https URL",
 		},
 		cli.StringFlag{
 			Name:  "auth_username",
@@ -0,0 +1,92 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/services/takeout"
+
+	"github.com/urfave/cli"
+)
+
+// CmdDumpUser represents the available dump user sub-command.
+var CmdDumpUser = cli.Command{
+	Name:        "dump-user",
+	Usage:       "Dump the data of a user to a zip archive",
+	Description: "This is a command for dumping the account, the settings, the repositories, the issues and the comments of a user, which restore-user restores.",
+	Action:      runDumpUser,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "username, u",
+			Value: "",
+			Usage: "The name of the user to dump",
+		},
+		cli.StringFlag{
+			Name:  "file, f",
+			Value: "",
+			Usage: "The zip archive to write, <username>-takeout.zip if empty",
+		},
+		cli.BoolFlag{
+			Name:  "skip-repos",
+			Usage: "Do not dump the repositories of the user",
+		},
+	},
+}
+
+func runDumpUser(ctx *cli.Context) error {
+	if !ctx.IsSet("username") {
+		return fmt.Errorf("username is not set")
+	}
+	if err := initDB(); err != nil {
+		return err
+	}
+
+	log.Trace("AppPath: %s", setting.AppPath)
+	log.Trace("AppWorkPath: %s", setting.AppWorkPath)
+	log.Trace("Custom path: %s", setting.CustomPath)
+	log.Trace("Log path: %s", setting.LogRootPath)
+
+	if err := storage.Init(); err != nil {
+		return err
+	}
+
+	u, err := models.GetUserByName(ctx.String("username"))
+	if err != nil {
+		return err
+	}
+	if u.IsOrganization() {
+		return fmt.Errorf("%s is an organization", u.Name)
+	}
+
+	fileName := ctx.String("file")
+	if fileName == "" {
+		fileName = u.Name + "-takeout.zip"
+	}
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	if err := takeout.WriteArchive(context.Background(), u, f, !ctx.Bool("skip-repos")); err != nil {
+		f.Close()
+		if err := os.Remove(fileName); err != nil {
+			log.Error("Unable to remove %s: %v", fileName, err)
+		}
+		log.Fatal("Failed to dump user %s: %v", u.Name, err)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("The data of %s has been dumped to %s\n", u.Name, fileName)
+	return nil
+}
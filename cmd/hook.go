@@ -235,10 +235,10 @@ Gitea or set your environment appropriately.`, "")
 				hookOptions.OldCommitIDs = oldCommitIDs
 				hookOptions.NewCommitIDs = newCommitIDs
 				hookOptions.RefFullNames = refFullNames
-				statusCode, msg := private.HookPreReceive(username, reponame, hookOptions)
+				statusCode, msg, warnings := private.HookPreReceive(username, reponame, hookOptions)
 				switch statusCode {
 				case http.StatusOK:
-					// no-op
+					hookPrintWarnings(warnings)
 				case http.StatusInternalServerError:
 					fail("Internal Server Error", msg)
 				default:
@@ -263,8 +263,10 @@ Gitea or set your environment appropriately.`, "")
 
 		fmt.Fprintf(out, " Checking %d branches\n", count)
 
-		statusCode, msg := private.HookPreReceive(username, reponame, hookOptions)
+		statusCode, msg, warnings := private.HookPreReceive(username, reponame, hookOptions)
 		switch statusCode {
+		case http.StatusOK:
+			hookPrintWarnings(warnings)
 		case http.StatusInternalServerError:
 			fail("Internal Server Error", msg)
 		case http.StatusForbidden:
@@ -383,6 +385,17 @@ Gitea or set your environment appropriately.`, "")
 		}
 	}
 
+	if isWiki {
+		// the refs of the wiki have changed nonetheless
+		hookOptions.IsWiki = true
+		if resp, err := private.HookPostReceive(repoUser, repoName, hookOptions); resp == nil {
+			_ = dWriter.Close()
+			fail("Internal Server Error", err)
+		}
+		_ = dWriter.Close()
+		return nil
+	}
+
 	if count == 0 {
 		if wasEmpty && masterPushed {
 			// We need to tell the repo to reset the default branch to master
@@ -428,6 +441,18 @@ Gitea or set your environment appropriately.`, "")
 	return nil
 }
 
+func hookPrintWarnings(warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	fmt.Fprintln(os.Stderr, "")
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+	fmt.Fprintln(os.Stderr, "")
+	os.Stderr.Sync()
+}
+
 func hookPrintResults(results []private.HookPostReceiveBranchResult) {
 	for _, res := range results {
 		if !res.Message {
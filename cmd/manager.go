@@ -27,6 +27,7 @@ var (
 			subcmdRestart,
 			subcmdFlushQueues,
 			subcmdLogging,
+			subcmdMaintenance,
 		},
 	}
 	subcmdShutdown = cli.Command{
@@ -67,6 +68,34 @@ var (
 			},
 		},
 	}
+	subcmdMaintenance = cli.Command{
+		Name:  "maintenance",
+		Usage: "Enable or disable the maintenance mode of the running process",
+		Subcommands: []cli.Command{
+			{
+				Name:  "enable",
+				Usage: "Enable the maintenance mode: only site administrators can use Gitea, everyone else has read-only git access and the queues are paused",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "message, m",
+						Usage: "Message shown to the users",
+					}, cli.BoolFlag{
+						Name: "debug",
+					},
+				},
+				Action: runEnableMaintenance,
+			}, {
+				Name:  "disable",
+				Usage: "Disable the maintenance mode",
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name: "debug",
+					},
+				},
+				Action: runDisableMaintenance,
+			},
+		},
+	}
 	defaultLoggingFlags = []cli.Flag{
 		cli.StringFlag{
 			Name:  "group, g",
@@ -452,3 +481,27 @@ func runReleaseReopenLogging(c *cli.Context) error {
 	fmt.Fprintln(os.Stdout, msg)
 	return nil
 }
+
+func runEnableMaintenance(c *cli.Context) error {
+	setup("manager", c.Bool("debug"))
+	statusCode, msg := private.SetMaintenanceMode(true, c.String("message"))
+	switch statusCode {
+	case http.StatusInternalServerError:
+		fail("InternalServerError", msg)
+	}
+
+	fmt.Fprintln(os.Stdout, msg)
+	return nil
+}
+
+func runDisableMaintenance(c *cli.Context) error {
+	setup("manager", c.Bool("debug"))
+	statusCode, msg := private.SetMaintenanceMode(false, "")
+	switch statusCode {
+	case http.StatusInternalServerError:
+		fail("InternalServerError", msg)
+	}
+
+	fmt.Fprintln(os.Stdout, msg)
+	return nil
+}
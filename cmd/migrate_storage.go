@@ -28,12 +28,12 @@ var CmdMigrateStorage = cli.Command{
 		cli.StringFlag{
 			Name:  "type, t",
 			Value: "",
-			Usage: "Kinds of files to migrate, currently only 'attachments' is supported",
+			Usage: "Kinds of files to migrate: attachments, lfs, avatars, repo-avatars or repo-archives",
 		},
 		cli.StringFlag{
 			Name:  "storage, s",
 			Value: "",
-			Usage: "New storage type: local (default) or minio",
+			Usage: "New storage type: local (default), minio or azureblob",
 		},
 		cli.StringFlag{
 			Name:  "path, p",
@@ -74,6 +74,51 @@ var CmdMigrateStorage = cli.Command{
 			Name:  "minio-use-ssl",
 			Usage: "Enable SSL for minio",
 		},
+		cli.StringFlag{
+			Name:  "minio-sse",
+			Value: "",
+			Usage: "Minio server-side encryption of the new objects: s3, kms or c",
+		},
+		cli.StringFlag{
+			Name:  "minio-sse-kms-key-id",
+			Value: "",
+			Usage: "Minio KMS key ID for SSE-KMS",
+		},
+		cli.StringFlag{
+			Name:  "minio-sse-c-key",
+			Value: "",
+			Usage: "Minio base64 encoded 256 bit key for SSE-C",
+		},
+		cli.StringFlag{
+			Name:  "azureblob-endpoint",
+			Value: "",
+			Usage: "Azure Blob storage endpoint (leave blank for the endpoint of the account)",
+		},
+		cli.StringFlag{
+			Name:  "azureblob-account-name",
+			Value: "",
+			Usage: "Azure Blob storage account name",
+		},
+		cli.StringFlag{
+			Name:  "azureblob-account-key",
+			Value: "",
+			Usage: "Azure Blob storage account key",
+		},
+		cli.StringFlag{
+			Name:  "azureblob-container",
+			Value: "",
+			Usage: "Azure Blob storage container",
+		},
+		cli.StringFlag{
+			Name:  "azureblob-base-path",
+			Value: "",
+			Usage: "Azure Blob storage basepath in the container",
+		},
+		cli.StringFlag{
+			Name:  "azureblob-encryption-scope",
+			Value: "",
+			Usage: "Azure Blob storage encryption scope of the new blobs",
+		},
 	},
 }
 
@@ -105,6 +150,13 @@ func migrateRepoAvatars(dstStorage storage.ObjectStorage) error {
 	})
 }
 
+func migrateRepoArchives(dstStorage storage.ObjectStorage) error {
+	return storage.RepoArchives.IterateObjects(func(path string, obj storage.Object) error {
+		_, err := dstStorage.Save(path, obj)
+		return err
+	})
+}
+
 func runMigrateStorage(ctx *cli.Context) error {
 	if err := initDB(); err != nil {
 		return err
@@ -154,6 +206,20 @@ func runMigrateStorage(ctx *cli.Context) error {
 				Location:        ctx.String("minio-location"),
 				BasePath:        ctx.String("minio-base-path"),
 				UseSSL:          ctx.Bool("minio-use-ssl"),
+				SSE:             ctx.String("minio-sse"),
+				SSEKMSKeyID:     ctx.String("minio-sse-kms-key-id"),
+				SSECKey:         ctx.String("minio-sse-c-key"),
+			})
+	case string(storage.AzureBlobStorageType):
+		dstStorage, err = storage.NewAzureBlobStorage(
+			goCtx,
+			storage.AzureBlobStorageConfig{
+				Endpoint:        ctx.String("azureblob-endpoint"),
+				AccountName:     ctx.String("azureblob-account-name"),
+				AccountKey:      ctx.String("azureblob-account-key"),
+				Container:       ctx.String("azureblob-container"),
+				BasePath:        ctx.String("azureblob-base-path"),
+				EncryptionScope: ctx.String("azureblob-encryption-scope"),
 			})
 	default:
 		return fmt.Errorf("Unsupported storage type: %s", ctx.String("storage"))
@@ -180,6 +246,10 @@ func runMigrateStorage(ctx *cli.Context) error {
 		if err := migrateRepoAvatars(dstStorage); err != nil {
 			return err
 		}
+	case "repo-archives":
+		if err := migrateRepoArchives(dstStorage); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("Unsupported storage: %s", ctx.String("type"))
 	}
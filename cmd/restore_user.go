@@ -0,0 +1,68 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/services/takeout"
+
+	"github.com/urfave/cli"
+)
+
+// CmdRestoreUser represents the available restore user sub-command.
+var CmdRestoreUser = cli.Command{
+	Name:        "restore-user",
+	Usage:       "Restore the data of a user from a zip archive",
+	Description: "This is a command for restoring the zip archives written by dump-user or downloaded from the account settings, on this or another instance.",
+	Action:      runRestoreUser,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "file, f",
+			Value: "",
+			Usage: "The zip archive to restore",
+		},
+		cli.StringFlag{
+			Name:  "username, u",
+			Value: "",
+			Usage: "The name of the user to restore the data to, the one of the archive if empty. The user is created if they do not exist.",
+		},
+	},
+}
+
+func runRestoreUser(ctx *cli.Context) error {
+	if !ctx.IsSet("file") {
+		return fmt.Errorf("file is not set")
+	}
+	if err := initDB(); err != nil {
+		return err
+	}
+
+	log.Trace("AppPath: %s", setting.AppPath)
+	log.Trace("AppWorkPath: %s", setting.AppWorkPath)
+	log.Trace("Custom path: %s", setting.CustomPath)
+	log.Trace("Log path: %s", setting.LogRootPath)
+
+	if err := storage.Init(); err != nil {
+		return err
+	}
+
+	u, passwd, err := takeout.RestoreArchive(context.Background(), ctx.String("file"), ctx.String("username"))
+	if err != nil {
+		log.Fatal("Failed to restore user: %v", err)
+		return err
+	}
+
+	if passwd != "" {
+		fmt.Printf("User %s has been created with the password %s, which must be changed at the first sign in\n", u.Name, passwd)
+	} else {
+		fmt.Printf("The data has been restored to the user %s\n", u.Name)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/urfave/cli"
+)
+
+var (
+	// CmdService represents the available service sub-command.
+	CmdService = cli.Command{
+		Name:        "service",
+		Usage:       "Manage Gitea as a Windows service",
+		Description: "A command for registering, unregistering, starting and stopping Gitea as a Windows service",
+		Subcommands: []cli.Command{
+			subcmdServiceInstall,
+			subcmdServiceUninstall,
+			subcmdServiceStart,
+			subcmdServiceStop,
+		},
+	}
+
+	serviceNameFlag = cli.StringFlag{
+		Name:  "name, n",
+		Value: "gitea",
+		Usage: "Name of the Windows service",
+	}
+
+	subcmdServiceInstall = cli.Command{
+		Name:  "install",
+		Usage: "Register Gitea as a Windows service that runs \"gitea web\" with the current configuration",
+		Flags: []cli.Flag{
+			serviceNameFlag,
+			cli.StringFlag{
+				Name:  "display-name",
+				Value: "Gitea",
+				Usage: "Display name of the Windows service",
+			},
+			cli.StringFlag{
+				Name:  "user, u",
+				Usage: "Account to run the service as - will default to the local system account",
+			},
+			cli.StringFlag{
+				Name:  "password, p",
+				Usage: "Password of the account to run the service as",
+			},
+			cli.BoolFlag{
+				Name:  "manual",
+				Usage: "Require the service to be started manually instead of at boot",
+			},
+		},
+		Action: runServiceInstall,
+	}
+
+	subcmdServiceUninstall = cli.Command{
+		Name:   "uninstall",
+		Usage:  "Unregister the Gitea Windows service",
+		Flags:  []cli.Flag{serviceNameFlag},
+		Action: runServiceUninstall,
+	}
+
+	subcmdServiceStart = cli.Command{
+		Name:   "start",
+		Usage:  "Start the Gitea Windows service",
+		Flags:  []cli.Flag{serviceNameFlag},
+		Action: runServiceStart,
+	}
+
+	subcmdServiceStop = cli.Command{
+		Name:  "stop",
+		Usage: "Gracefully stop the Gitea Windows service",
+		Flags: []cli.Flag{
+			serviceNameFlag,
+			cli.DurationFlag{
+				Name:  "timeout",
+				Value: 2 * time.Minute,
+				Usage: "Timeout to wait for the service to stop",
+			},
+		},
+		Action: runServiceStop,
+	}
+)
@@ -0,0 +1,36 @@
+// +build !windows
+
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli"
+)
+
+func serviceNotImplemented(c *cli.Context) error {
+	err := fmt.Errorf("Sorry: the 'service' subcommand is only available on Windows. Use the systemd unit in contrib/systemd instead")
+	fmt.Fprintf(os.Stderr, "%s\n", err)
+	return err
+}
+
+func runServiceInstall(c *cli.Context) error {
+	return serviceNotImplemented(c)
+}
+
+func runServiceUninstall(c *cli.Context) error {
+	return serviceNotImplemented(c)
+}
+
+func runServiceStart(c *cli.Context) error {
+	return serviceNotImplemented(c)
+}
+
+func runServiceStop(c *cli.Context) error {
+	return serviceNotImplemented(c)
+}
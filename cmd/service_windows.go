@@ -0,0 +1,172 @@
+// +build windows
+
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+	"unsafe"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/urfave/cli"
+	"golang.org/x/sys/windows"
+)
+
+// utf16PtrOrNil converts s to a UTF16 pointer returning nil for an empty string
+func utf16PtrOrNil(s string) *uint16 {
+	if s == "" {
+		return nil
+	}
+	return windows.StringToUTF16Ptr(s)
+}
+
+func openService(name string, access uint32) (windows.Handle, windows.Handle, error) {
+	scm, err := windows.OpenSCManager(nil, nil, windows.SC_MANAGER_ALL_ACCESS)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unable to connect to the service control manager: %v", err)
+	}
+	service, err := windows.OpenService(scm, windows.StringToUTF16Ptr(name), access)
+	if err != nil {
+		_ = windows.CloseServiceHandle(scm)
+		return 0, 0, fmt.Errorf("unable to open service %s: %v", name, err)
+	}
+	return scm, service, nil
+}
+
+func runServiceInstall(c *cli.Context) error {
+	name := c.String("name")
+
+	appPath, err := filepath.Abs(setting.AppPath)
+	if err != nil {
+		return err
+	}
+	workPath, err := filepath.Abs(setting.AppWorkPath)
+	if err != nil {
+		return err
+	}
+	configPath, err := filepath.Abs(setting.CustomConf)
+	if err != nil {
+		return err
+	}
+	binPath := fmt.Sprintf("%s web --work-path %s --config %s",
+		windows.EscapeArg(appPath), windows.EscapeArg(workPath), windows.EscapeArg(configPath))
+
+	startType := uint32(windows.SERVICE_AUTO_START)
+	if c.Bool("manual") {
+		startType = windows.SERVICE_DEMAND_START
+	}
+
+	scm, err := windows.OpenSCManager(nil, nil, windows.SC_MANAGER_ALL_ACCESS)
+	if err != nil {
+		return fmt.Errorf("unable to connect to the service control manager: %v", err)
+	}
+	defer windows.CloseServiceHandle(scm)
+
+	service, err := windows.CreateService(scm,
+		windows.StringToUTF16Ptr(name),
+		windows.StringToUTF16Ptr(c.String("display-name")),
+		windows.SERVICE_ALL_ACCESS,
+		windows.SERVICE_WIN32_OWN_PROCESS,
+		startType,
+		windows.SERVICE_ERROR_NORMAL,
+		windows.StringToUTF16Ptr(binPath),
+		nil, nil, nil,
+		utf16PtrOrNil(c.String("user")),
+		utf16PtrOrNil(c.String("password")))
+	if err != nil {
+		return fmt.Errorf("unable to create service %s: %v", name, err)
+	}
+	defer windows.CloseServiceHandle(service)
+
+	description := windows.SERVICE_DESCRIPTION{
+		Description: windows.StringToUTF16Ptr("Gitea - Git with a cup of tea"),
+	}
+	if err := windows.ChangeServiceConfig2(service, windows.SERVICE_CONFIG_DESCRIPTION, (*byte)(unsafe.Pointer(&description))); err != nil {
+		fmt.Printf("Unable to set description of service %s: %v\n", name, err)
+	}
+
+	// Ask the service control manager to restart us if we crash
+	actions := []windows.SC_ACTION{
+		{Type: windows.SC_ACTION_RESTART, Delay: uint32((5 * time.Second) / time.Millisecond)},
+		{Type: windows.SC_ACTION_RESTART, Delay: uint32((30 * time.Second) / time.Millisecond)},
+		{Type: windows.SC_ACTION_NONE},
+	}
+	failureActions := windows.SERVICE_FAILURE_ACTIONS{
+		ResetPeriod:  uint32((24 * time.Hour) / time.Second),
+		ActionsCount: uint32(len(actions)),
+		Actions:      &actions[0],
+	}
+	if err := windows.ChangeServiceConfig2(service, windows.SERVICE_CONFIG_FAILURE_ACTIONS, (*byte)(unsafe.Pointer(&failureActions))); err != nil {
+		fmt.Printf("Unable to set recovery actions of service %s: %v\n", name, err)
+	}
+
+	fmt.Printf("Service %s installed running: %s\n", name, binPath)
+	return nil
+}
+
+func runServiceUninstall(c *cli.Context) error {
+	name := c.String("name")
+	scm, service, err := openService(name, windows.SERVICE_ALL_ACCESS)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseServiceHandle(scm)
+	defer windows.CloseServiceHandle(service)
+
+	if err := windows.DeleteService(service); err != nil {
+		return fmt.Errorf("unable to delete service %s: %v", name, err)
+	}
+	fmt.Printf("Service %s uninstalled\n", name)
+	return nil
+}
+
+func runServiceStart(c *cli.Context) error {
+	name := c.String("name")
+	scm, service, err := openService(name, windows.SERVICE_START)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseServiceHandle(scm)
+	defer windows.CloseServiceHandle(service)
+
+	if err := windows.StartService(service, 0, nil); err != nil {
+		return fmt.Errorf("unable to start service %s: %v", name, err)
+	}
+	fmt.Printf("Service %s started\n", name)
+	return nil
+}
+
+func runServiceStop(c *cli.Context) error {
+	name := c.String("name")
+	scm, service, err := openService(name, windows.SERVICE_STOP|windows.SERVICE_QUERY_STATUS)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseServiceHandle(scm)
+	defer windows.CloseServiceHandle(service)
+
+	var status windows.SERVICE_STATUS
+	if err := windows.ControlService(service, windows.SERVICE_CONTROL_STOP, &status); err != nil {
+		return fmt.Errorf("unable to stop service %s: %v", name, err)
+	}
+
+	// The service will finish its queues and git operations before stopping so wait for it
+	deadline := time.Now().Add(c.Duration("timeout"))
+	for status.CurrentState != windows.SERVICE_STOPPED {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for service %s to stop", name)
+		}
+		time.Sleep(500 * time.Millisecond)
+		if err := windows.QueryServiceStatus(service, &status); err != nil {
+			return fmt.Errorf("unable to query status of service %s: %v", name, err)
+		}
+	}
+	fmt.Printf("Service %s stopped\n", name)
+	return nil
+}
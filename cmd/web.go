@@ -134,6 +134,12 @@ func runWeb(ctx *cli.Context) error {
 		}
 	}
 
+	if setting.InternalAPI.Separate {
+		go runInternalAPI(routes.InternalRoutes())
+	} else {
+		NoInternalAPIListener()
+	}
+
 	// Set up Chi routes
 	c := routes.NormalRoutes()
 	err := listen(c, true)
@@ -43,6 +43,12 @@ func NoInstallListener() {
 	graceful.GetManager().InformCleanup()
 }
 
+// NoInternalAPIListener tells our cleanup routine that we will not be using a separate listener
+// for the internal API
+func NoInternalAPIListener() {
+	graceful.GetManager().InformCleanup()
+}
+
 func runFCGI(network, listenAddr, name string, m http.Handler) error {
 	// This needs to handle stdin as fcgi point
 	fcgiServer := graceful.NewServer(network, listenAddr, name)
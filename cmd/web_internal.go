@@ -0,0 +1,75 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	context2 "github.com/gorilla/context"
+)
+
+// runInternalAPI serves the internal API on its own listener
+func runInternalAPI(m http.Handler) {
+	var err error
+	switch setting.InternalAPI.Protocol {
+	case setting.UnixSocket:
+		log.Info("Internal API Listen: unix://%s", setting.InternalAPI.HTTPAddr)
+		err = runHTTP("unix", setting.InternalAPI.HTTPAddr, "Internal API", context2.ClearHandler(m))
+	case setting.HTTPS:
+		listenAddr := net.JoinHostPort(setting.InternalAPI.HTTPAddr, setting.InternalAPI.HTTPPort)
+		log.Info("Internal API Listen: https://%s", listenAddr)
+		var tlsConfig *tls.Config
+		tlsConfig, err = internalAPITLSConfig()
+		if err != nil {
+			break
+		}
+		err = runHTTPSWithTLSConfig("tcp", listenAddr, "Internal API", tlsConfig, context2.ClearHandler(m))
+	default:
+		log.Fatal("Invalid internal API protocol: %s", setting.InternalAPI.Protocol)
+	}
+
+	if err != nil {
+		log.Critical("Failed to start internal API server: %v", err)
+	}
+	log.Info("Internal API Listener: %s Closed", setting.InternalAPI.HTTPAddr)
+}
+
+// internalAPITLSConfig creates the TLS configuration for the internal API listener.
+// If a client CA is configured every client must present a certificate signed by it.
+func internalAPITLSConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(setting.InternalAPI.CertFile, setting.InternalAPI.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load internal API certificate: %v", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+
+	if setting.InternalAPI.ClientCAFile == "" {
+		log.Warn("[internal_api] CLIENT_CA_FILE is not set: client certificates will not be verified")
+		return tlsConfig, nil
+	}
+
+	pem, err := ioutil.ReadFile(setting.InternalAPI.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read internal API client CA: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", setting.InternalAPI.ClientCAFile)
+	}
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	return tlsConfig, nil
+}
@@ -6,8 +6,6 @@ package main
 
 import (
 	"os"
-	"regexp"
-	"strconv"
 	"strings"
 
 	"code.gitea.io/gitea/modules/log"
@@ -123,7 +121,7 @@ func runEnvironmentToIni(c *cli.Context) error {
 			continue
 		}
 		eKey = eKey[len(prefix):]
-		sectionName, keyName := DecodeSectionKey(eKey)
+		sectionName, keyName := setting.DecodeEnvSectionKey(eKey)
 		if len(keyName) == 0 {
 			continue
 		}
@@ -167,63 +165,3 @@ func runEnvironmentToIni(c *cli.Context) error {
 	}
 	return nil
 }
-
-const escapeRegexpString = "_0[xX](([0-9a-fA-F][0-9a-fA-F])+)_"
-
-var escapeRegex = regexp.MustCompile(escapeRegexpString)
-
-// DecodeSectionKey will decode a portable string encoded Section__Key pair
-// Portable strings are considered to be of the form [A-Z0-9_]*
-// We will encode a disallowed value as the UTF8 byte string preceded by _0X and
-// followed by _. E.g. _0X2C_ for a '-' and _0X2E_ for '.'
-// Section and Key are separated by a plain '__'.
-// The entire section can be encoded as a UTF8 byte string
-func DecodeSectionKey(encoded string) (string, string) {
-	section := ""
-	key := ""
-
-	inKey := false
-	last := 0
-	escapeStringIndices := escapeRegex.FindAllStringIndex(encoded, -1)
-	for _, unescapeIdx := range escapeStringIndices {
-		preceding := encoded[last:unescapeIdx[0]]
-		if !inKey {
-			if splitter := strings.Index(preceding, "__"); splitter > -1 {
-				section += preceding[:splitter]
-				inKey = true
-				key += preceding[splitter+2:]
-			} else {
-				section += preceding
-			}
-		} else {
-			key += preceding
-		}
-		toDecode := encoded[unescapeIdx[0]+3 : unescapeIdx[1]-1]
-		decodedBytes := make([]byte, len(toDecode)/2)
-		for i := 0; i < len(toDecode)/2; i++ {
-			// Can ignore error here as we know these should be hexadecimal from the regexp
-			byteInt, _ := strconv.ParseInt(toDecode[2*i:2*i+2], 16, 0)
-			decodedBytes[i] = byte(byteInt)
-		}
-		if inKey {
-			key += string(decodedBytes)
-		} else {
-			section += string(decodedBytes)
-		}
-		last = unescapeIdx[1]
-	}
-	remaining := encoded[last:]
-	if !inKey {
-		if splitter := strings.Index(remaining, "__"); splitter > -1 {
-			section += remaining[:splitter]
-			inKey = true
-			key += remaining[splitter+2:]
-		} else {
-			section += remaining
-		}
-	} else {
-		key += remaining
-	}
-	section = strings.ToLower(section)
-	return section, key
-}
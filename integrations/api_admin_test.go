@@ -5,11 +5,13 @@
 package integrations
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 
 	jsoniter "github.com/json-iterator/go"
@@ -208,3 +210,124 @@ func TestAPIEditUser(t *testing.T) {
 	user2 = models.AssertExistsAndLoadBean(t, &models.User{LoginName: "user2"}).(*models.User)
 	assert.Equal(t, true, user2.IsRestricted)
 }
+
+func TestAPIAdminStorageStatistics(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/admin/statistics?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	assert.NoError(t, models.UpdateStorageStatistics(context.Background(), 3, 0))
+
+	req = NewRequestf(t, "GET", "/api/v1/admin/statistics?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var stat api.StorageStatistic
+	DecodeJSON(t, resp, &stat)
+	assert.EqualValues(t, models.CountRepositories(true), stat.NumRepos)
+	assert.EqualValues(t, models.CountUsers(), stat.NumUsers)
+
+	req = NewRequestf(t, "GET", "/api/v1/admin/statistics/history?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var stats []*api.StorageStatistic
+	DecodeJSON(t, resp, &stats)
+	assert.Len(t, stats, 1)
+
+	// non-admin
+	session = loginUser(t, "user2")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestf(t, "GET", "/api/v1/admin/statistics?token=%s", token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAPIAdminInstanceStats(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/admin/stats?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var stats api.InstanceStats
+	DecodeJSON(t, resp, &stats)
+	assert.Len(t, stats.InstanceID, 32)
+	assert.Equal(t, setting.AppVer, stats.Version)
+	assert.EqualValues(t, models.CountUsers(), stats.Counts.Users)
+	assert.EqualValues(t, models.CountRepositories(true), stats.Counts.Repos)
+
+	// non-admin
+	session = loginUser(t, "user2")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestf(t, "GET", "/api/v1/admin/stats?token=%s", token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAPIAdminEffectiveConfig(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/admin/config?source=file&q=security&token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var values []*api.ConfigValue
+	DecodeJSON(t, resp, &values)
+	assert.NotEmpty(t, values)
+	for _, value := range values {
+		assert.Equal(t, "security", value.Section)
+		assert.Equal(t, "file", value.Source)
+		if value.Key == "INTERNAL_TOKEN" {
+			assert.True(t, value.Masked)
+			assert.Equal(t, "******", value.Value)
+		}
+	}
+
+	// non-admin
+	session = loginUser(t, "user2")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestf(t, "GET", "/api/v1/admin/config?token=%s", token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAPIAdminMigrateAuthUsers(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+
+	source := &models.LoginSource{Type: models.LoginPAM, Name: "pam", IsActived: true, Cfg: &models.PAMConfig{ServiceName: "gitea"}}
+	assert.NoError(t, models.CreateLoginSource(source))
+
+	urlStr := fmt.Sprintf("/api/v1/admin/auths/migrate?token=%s", token)
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.MigrateLoginSourceUsersOption{
+		ToSourceID: source.ID,
+		MapBy:      "email",
+		Usernames:  []string{"user4", "user5"},
+		DryRun:     true,
+	})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var migration api.LoginSourceMigration
+	DecodeJSON(t, resp, &migration)
+	assert.True(t, migration.DryRun)
+	assert.Equal(t, 2, migration.Migrated)
+	if assert.Len(t, migration.Users, 2) {
+		assert.Equal(t, "user4@example.com", migration.Users[0].LoginName)
+	}
+	models.AssertExistsAndLoadBean(t, &models.User{ID: 4, LoginSource: 0})
+
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.MigrateLoginSourceUsersOption{
+		ToSourceID: source.ID,
+		Usernames:  []string{"user4"},
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &migration)
+	assert.Equal(t, 1, migration.Migrated)
+	models.AssertExistsAndLoadBean(t, &models.User{ID: 4, LoginType: models.LoginPAM, LoginSource: source.ID, LoginName: "user4"})
+
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.MigrateLoginSourceUsersOption{ToSourceID: models.NonexistentID})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// non-admin
+	session = loginUser(t, "user2")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/admin/auths/migrate?token=%s", token), &api.MigrateLoginSourceUsersOption{})
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
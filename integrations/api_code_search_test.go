@@ -0,0 +1,95 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPICodeSearch(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo, err := models.GetRepositoryByOwnerAndName("user2", "repo1")
+	assert.NoError(t, err)
+	executeIndexer(t, repo, code_indexer.UpdateRepoIndexer)
+
+	req := NewRequest(t, "GET", "/api/v1/code/search?q=Description&repos=user2/repo1")
+	resp := MakeRequest(t, req, http.StatusOK)
+	var results api.CodeSearchResults
+	DecodeJSON(t, resp, &results)
+	assert.EqualValues(t, 1, results.TotalCount)
+	if assert.Len(t, results.Results, 1) {
+		assert.EqualValues(t, repo.ID, results.Results[0].RepoID)
+		assert.EqualValues(t, "user2/repo1", results.Results[0].RepoFullName)
+		assert.EqualValues(t, "README.md", results.Results[0].Filename)
+		assert.Contains(t, results.Results[0].Content, "Description")
+	}
+	if assert.Len(t, results.Repositories, 1) {
+		assert.EqualValues(t, "user2/repo1", results.Repositories[0].RepoFullName)
+		assert.EqualValues(t, 1, results.Repositories[0].Count)
+	}
+
+	// the README is not in the docs directory
+	req = NewRequest(t, "GET", "/api/v1/code/search?q=Description&repos=user2/repo1&path=docs")
+	resp = MakeRequest(t, req, http.StatusOK)
+	results = api.CodeSearchResults{}
+	DecodeJSON(t, resp, &results)
+	assert.EqualValues(t, 0, results.TotalCount)
+	assert.Len(t, results.Repositories, 1)
+
+	req = NewRequest(t, "GET", "/api/v1/code/search?q=desc.*n&regexp=true&repos=user2/repo1")
+	resp = MakeRequest(t, req, http.StatusOK)
+	results = api.CodeSearchResults{}
+	DecodeJSON(t, resp, &results)
+	assert.EqualValues(t, 1, results.TotalCount)
+
+	req = NewRequest(t, "GET", "/api/v1/code/search?q=desc(&regexp=true")
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// the search is restricted to the repositories of the owner
+	req = NewRequest(t, "GET", "/api/v1/code/search?q=Description&org=user3")
+	resp = MakeRequest(t, req, http.StatusOK)
+	results = api.CodeSearchResults{}
+	DecodeJSON(t, resp, &results)
+	assert.EqualValues(t, 0, results.TotalCount)
+	assert.Empty(t, results.Results)
+
+	// nothing matches repositories which do not exist
+	req = NewRequest(t, "GET", "/api/v1/code/search?q=Description&repos=user2/repo_not_exist")
+	resp = MakeRequest(t, req, http.StatusOK)
+	results = api.CodeSearchResults{}
+	DecodeJSON(t, resp, &results)
+	assert.Empty(t, results.Results)
+
+	req = NewRequest(t, "GET", "/api/v1/code/search")
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+}
+
+func TestExploreCodeRecentSearches(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	req := NewRequest(t, "GET", "/explore/code?q=Description&repos=user2/repo1")
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertExistsAndLoadBean(t, &models.UserCodeSearch{UserID: 2, Keyword: "Description", Repos: "user2/repo1"})
+
+	req = NewRequest(t, "GET", "/explore/code")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	htmlDoc.AssertElement(t, "form[action$='/explore/code/recent/clear']", true)
+
+	req = NewRequestWithValues(t, "POST", "/explore/code/recent/clear", map[string]string{
+		"_csrf": htmlDoc.GetCSRF(),
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertNotExistsBean(t, &models.UserCodeSearch{UserID: 2})
+}
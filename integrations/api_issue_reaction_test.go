@@ -138,3 +138,39 @@ func TestAPICommentReactions(t *testing.T) {
 		assert.Equal(t, expectResponse[i].User.ID, r.User.ID)
 	}
 }
+
+func TestAPIIssueReactionCounts(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/issues/1/reactions/counts?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var apiCounts []*api.ReactionCount
+	DecodeJSON(t, resp, &apiCounts)
+	assert.Equal(t, []*api.ReactionCount{
+		{Reaction: "laugh", Total: 2, Comments: 2},
+		{Reaction: "eyes", Total: 1, Issue: 1},
+	}, apiCounts)
+
+	// comment 4 is a code review comment of pull request 2
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/issues/comments/4/reactions?token=%s", token), &api.EditReactionOption{
+		Reaction: "heart",
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/issues/2/reactions/counts?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiCounts)
+	assert.Equal(t, []*api.ReactionCount{
+		{Reaction: "heart", Total: 1, ReviewComments: 1},
+	}, apiCounts)
+
+	// the comments of other repositories are not found
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo2/issues/comments/4/reactions?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/issues/9999/reactions/counts?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
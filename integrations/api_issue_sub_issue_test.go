@@ -0,0 +1,61 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIIssueSubIssues(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+	session := loginUser(t, owner.Name)
+	token := getTokenForLoggedInUser(t, session)
+	// issues #1 and #4 of repo 1 are issues, #4 being closed, and #2 is a pull request
+	subIssuesURL := fmt.Sprintf("/api/v1/repos/%s/%s/issues/1/sub_issues", owner.Name, repo.Name)
+
+	req := NewRequestWithJSON(t, "POST", subIssuesURL+"?token="+token, &api.AddSubIssueOption{SubIssueIndex: 4})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var subIssue api.Issue
+	DecodeJSON(t, resp, &subIssue)
+	assert.EqualValues(t, 4, subIssue.Index)
+	assert.EqualValues(t, 1, subIssue.ParentIndex)
+
+	req = NewRequestWithJSON(t, "POST", subIssuesURL+"?token="+token, &api.AddSubIssueOption{SubIssueIndex: 2})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestWithJSON(t, "POST", subIssuesURL+"?token="+token, &api.AddSubIssueOption{SubIssueIndex: 1})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequest(t, "GET", subIssuesURL+"?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var tree api.SubIssueNode
+	DecodeJSON(t, resp, &tree)
+	assert.EqualValues(t, 1, tree.Issue.Index)
+	assert.Equal(t, 1, tree.Total)
+	assert.Equal(t, 1, tree.Closed)
+	if assert.Len(t, tree.SubIssues, 1) {
+		assert.EqualValues(t, 4, tree.SubIssues[0].Issue.Index)
+	}
+
+	req = NewRequest(t, "DELETE", subIssuesURL+"/4?token="+token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequest(t, "DELETE", subIssuesURL+"/4?token="+token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// a user who cannot write the issues cannot manage the sub-issues
+	session = loginUser(t, "user4")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "POST", subIssuesURL+"?token="+token, &api.AddSubIssueOption{SubIssueIndex: 4})
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
@@ -72,3 +72,50 @@ func TestAPIIssueSubscriptions(t *testing.T) {
 	session.MakeRequest(t, req, http.StatusOK)
 	testSubscription(issue5, true)
 }
+
+func TestAPIIssueSubscribers(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1}).(*models.Issue)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: issue.RepoID}).(*models.Repository)
+	privateIssue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 4}).(*models.Issue)
+	privateRepo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: privateIssue.RepoID}).(*models.Repository)
+
+	session := loginUser(t, repo.OwnerName)
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := fmt.Sprintf("/api/v1/repos/%s/%s/issues/%d/subscriptions?token=%s", repo.OwnerName, repo.Name, issue.Index, token)
+
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.IssueSubscribersOption{Usernames: []string{"user4", "user5"}})
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertExistsAndLoadBean(t, &models.IssueWatch{UserID: 4, IssueID: issue.ID, IsWatching: true})
+	models.AssertExistsAndLoadBean(t, &models.IssueWatch{UserID: 5, IssueID: issue.ID, IsWatching: true})
+
+	req = NewRequest(t, "GET", urlStr)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var users []*api.User
+	DecodeJSON(t, resp, &users)
+	assert.Len(t, users, 3)
+
+	// nothing is changed when a user does not exist
+	req = NewRequestWithJSON(t, "DELETE", urlStr, &api.IssueSubscribersOption{Usernames: []string{"user4", "user-not-exist"}})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	models.AssertExistsAndLoadBean(t, &models.IssueWatch{UserID: 4, IssueID: issue.ID, IsWatching: true})
+
+	req = NewRequestWithJSON(t, "DELETE", urlStr, &api.IssueSubscribersOption{Usernames: []string{"user4", "user5"}})
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.IssueWatch{UserID: 4, IssueID: issue.ID, IsWatching: true})
+	models.AssertNotExistsBean(t, &models.IssueWatch{UserID: 5, IssueID: issue.ID, IsWatching: true})
+
+	// the users who cannot read the issue cannot be subscribed
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/%s/issues/%d/subscriptions?token=%s", privateRepo.OwnerName, privateRepo.Name, privateIssue.Index, token),
+		&api.IssueSubscribersOption{Usernames: []string{"user4"}})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	models.AssertNotExistsBean(t, &models.IssueWatch{UserID: 4, IssueID: privateIssue.ID})
+
+	// the users who cannot write the issue cannot change the subscriptions
+	session = loginUser(t, "user4")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/%s/issues/%d/subscriptions?token=%s", repo.OwnerName, repo.Name, issue.Index, token),
+		&api.IssueSubscribersOption{Usernames: []string{"user5"}})
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
@@ -119,3 +119,35 @@ func TestAPIAddTrackedTimes(t *testing.T) {
 	assert.EqualValues(t, user2.ID, apiNewTime.UserID)
 	assert.EqualValues(t, 947688818, apiNewTime.Created.Unix())
 }
+
+func TestAPISumTrackedTimes(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	session := loginUser(t, user2.Name)
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/%s/repo1/insights/times?by=milestone&token=%s", user2.Name, token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var sums []*api.TrackedTimeSum
+	DecodeJSON(t, resp, &sums)
+	if assert.Len(t, sums, 2) {
+		assert.EqualValues(t, 1, sums[0].ID)
+		assert.Equal(t, "milestone1", sums[0].Name)
+		assert.EqualValues(t, 3682, sums[0].Time)
+		assert.EqualValues(t, 0, sums[1].ID)
+		assert.EqualValues(t, 401, sums[1].Time)
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/%s/repo1/insights/times?by=issue&user=user1&token=%s", user2.Name, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	sums = nil
+	DecodeJSON(t, resp, &sums)
+	if assert.Len(t, sums, 2) {
+		assert.EqualValues(t, 1, sums[0].IssueIndex)
+		assert.EqualValues(t, 400, sums[0].Time)
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/%s/repo1/insights/times?by=user&token=%s", user2.Name, token)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+}
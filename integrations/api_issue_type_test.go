@@ -0,0 +1,96 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIIssueTypes(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+	session := loginUser(t, owner.Name)
+	token := getTokenForLoggedInUser(t, session)
+	typesURL := fmt.Sprintf("/api/v1/repos/%s/%s/issue_types", owner.Name, repo.Name)
+
+	req := NewRequestWithJSON(t, "POST", typesURL+"?token="+token, &api.CreateIssueTypeOption{
+		Name:  "Bug",
+		Color: "ee0701",
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var bug api.IssueType
+	DecodeJSON(t, resp, &bug)
+	assert.Equal(t, "Bug", bug.Name)
+	assert.Equal(t, "ee0701", bug.Color)
+	assert.False(t, bug.IsOrgType)
+
+	req = NewRequestWithJSON(t, "POST", typesURL+"?token="+token, &api.CreateIssueTypeOption{
+		Name:  "bug",
+		Color: "#000000",
+	})
+	session.MakeRequest(t, req, http.StatusConflict)
+
+	templateFile := "bug_report.md"
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("%s/%d?token=%s", typesURL, bug.ID, token), &api.EditIssueTypeOption{
+		TemplateFile: &templateFile,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &bug)
+	assert.Equal(t, templateFile, bug.TemplateFile)
+
+	req = NewRequest(t, "GET", typesURL+"?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var types []*api.IssueType
+	DecodeJSON(t, resp, &types)
+	assert.Len(t, types, 1)
+
+	// the type of an issue is set when creating or editing it
+	issuesURL := fmt.Sprintf("/api/v1/repos/%s/%s/issues", owner.Name, repo.Name)
+	req = NewRequestWithJSON(t, "POST", issuesURL+"?token="+token, &api.CreateIssueOption{
+		Title: "crash",
+		Type:  bug.ID,
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var issue api.Issue
+	DecodeJSON(t, resp, &issue)
+	if assert.NotNil(t, issue.Type) {
+		assert.Equal(t, bug.ID, issue.Type.ID)
+	}
+
+	req = NewRequest(t, "GET", fmt.Sprintf("%s?issue_type=%d&token=%s", issuesURL, bug.ID, token))
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var issues []*api.Issue
+	DecodeJSON(t, resp, &issues)
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, issue.ID, issues[0].ID)
+	}
+
+	noType := int64(0)
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("%s/%d?token=%s", issuesURL, issue.Index, token), &api.EditIssueOption{
+		Type: &noType,
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	DecodeJSON(t, resp, &issue)
+	assert.Nil(t, issue.Type)
+
+	unknownType := int64(1000)
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("%s/%d?token=%s", issuesURL, issue.Index, token), &api.EditIssueOption{
+		Type: &unknownType,
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequest(t, "DELETE", fmt.Sprintf("%s/%d?token=%s", typesURL, bug.ID, token))
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.IssueType{ID: bug.ID})
+}
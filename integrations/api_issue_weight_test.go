@@ -0,0 +1,46 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIIssueWeight(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+	session := loginUser(t, owner.Name)
+	token := getTokenForLoggedInUser(t, session)
+	// pull request #2 of repo 1 is open and in milestone 1
+	issueURL := fmt.Sprintf("/api/v1/repos/%s/%s/issues/2?token=%s", owner.Name, repo.Name, token)
+
+	weight := int64(3)
+	req := NewRequestWithJSON(t, "PATCH", issueURL, &api.EditIssueOption{Weight: &weight})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var issue api.Issue
+	DecodeJSON(t, resp, &issue)
+	assert.EqualValues(t, 3, issue.Weight)
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: 2, Type: models.CommentTypeChangeIssueWeight, NewTitle: "3"})
+
+	weight = -1
+	req = NewRequestWithJSON(t, "PATCH", issueURL, &api.EditIssueOption{Weight: &weight})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/%s/%s/milestones/1?token=%s", owner.Name, repo.Name, token))
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var milestone api.Milestone
+	DecodeJSON(t, resp, &milestone)
+	assert.EqualValues(t, 3, milestone.OpenWeight)
+	assert.EqualValues(t, 0, milestone.ClosedWeight)
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIOrgMemberContributions(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	// the contributions are counted in the background
+	var contributions api.OrgMemberContributions
+	urlStr := "/api/v1/orgs/user3/members/user2/contributions?since=2000-01-01T00:00:00Z&token=" + token
+	for i := 0; ; i++ {
+		resp := session.MakeRequest(t, NewRequest(t, "GET", urlStr), NoExpectedStatus)
+		if resp.Code == http.StatusOK {
+			DecodeJSON(t, resp, &contributions)
+			break
+		}
+		assert.EqualValues(t, http.StatusAccepted, resp.Code)
+		if i == 50 {
+			t.Fatal("the contributions were not counted")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.EqualValues(t, 3, contributions.Repositories)
+	assert.EqualValues(t, 1, contributions.PullRequestsOpened)
+	assert.Equal(t, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), contributions.Since)
+
+	// user5 is not a member of the organization
+	session.MakeRequest(t, NewRequest(t, "GET", "/api/v1/orgs/user3/members/user5/contributions?token="+token), http.StatusNotFound)
+	session.MakeRequest(t, NewRequest(t, "GET", "/api/v1/orgs/user3/members/user2/contributions?since=2021-01-01T00:00:00Z&before=2020-01-01T00:00:00Z&token="+token), http.StatusUnprocessableEntity)
+
+	// non-members can't see the contributions of the members
+	session = loginUser(t, "user5")
+	token = getTokenForLoggedInUser(t, session)
+	session.MakeRequest(t, NewRequest(t, "GET", "/api/v1/orgs/user3/members/user2/contributions?token="+token), http.StatusForbidden)
+}
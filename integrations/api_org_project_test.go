@@ -0,0 +1,87 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIOrgProjects(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/projects?token="+token, &api.CreateProjectOption{
+		Title:    "org project",
+		Template: "basic_kanban",
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var apiProject api.Project
+	DecodeJSON(t, resp, &apiProject)
+	assert.Equal(t, "org project", apiProject.Title)
+	assert.Equal(t, api.StateOpen, apiProject.State)
+	models.AssertExistsAndLoadBean(t, &models.Project{ID: apiProject.ID, OwnerID: 3, Type: models.ProjectTypeOrganization})
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/projects?token="+token, &api.CreateProjectOption{
+		Title:    "org project",
+		Template: "unknown",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestf(t, "GET", "/api/v1/orgs/user3/projects/%d/boards?token=%s", apiProject.ID, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var apiBoards []*api.ProjectBoard
+	DecodeJSON(t, resp, &apiBoards)
+	assert.Len(t, apiBoards, 3)
+
+	// a board only accepting the issues of repo3
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/orgs/user3/projects/%d/boards?token=%s", apiProject.ID, token), &api.CreateProjectBoardOption{
+		Title:   "repo3",
+		RepoIDs: []int64{3},
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var apiBoard api.ProjectBoard
+	DecodeJSON(t, resp, &apiBoard)
+	assert.Equal(t, []int64{3}, apiBoard.RepoIDs)
+
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/orgs/user3/projects/%d/boards?token=%s", apiProject.ID, token), &api.CreateProjectBoardOption{
+		Title:   "repo1",
+		RepoIDs: []int64{1},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	boardURL := fmt.Sprintf("/api/v1/orgs/user3/projects/%d/boards/%d/issues?token=%s", apiProject.ID, apiBoard.ID, token)
+	req = NewRequestWithJSON(t, "POST", boardURL, &api.MoveProjectIssueOption{Repo: "repo3", Index: 1})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertExistsAndLoadBean(t, &models.ProjectIssue{IssueID: 6, ProjectID: apiProject.ID, ProjectBoardID: apiBoard.ID})
+
+	req = NewRequest(t, "GET", boardURL)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var apiIssues []*api.Issue
+	DecodeJSON(t, resp, &apiIssues)
+	if assert.Len(t, apiIssues, 1) {
+		assert.EqualValues(t, 6, apiIssues[0].ID)
+	}
+
+	// members cannot manage the projects
+	session4 := loginUser(t, "user4")
+	token4 := getTokenForLoggedInUser(t, session4)
+	req = NewRequestf(t, "GET", "/api/v1/orgs/user3/projects/%d?token=%s", apiProject.ID, token4)
+	session4.MakeRequest(t, req, http.StatusOK)
+	req = NewRequestf(t, "DELETE", "/api/v1/orgs/user3/projects/%d?token=%s", apiProject.ID, token4)
+	session4.MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequestf(t, "DELETE", "/api/v1/orgs/user3/projects/%d?token=%s", apiProject.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.ProjectIssue{IssueID: 6})
+}
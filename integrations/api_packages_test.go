@@ -0,0 +1,69 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestPackageRegistryCredentials(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	uploadURL := func(owner, version string) string {
+		return fmt.Sprintf("/api/packages/%s/maven/org/example/app/%s/app-%s.jar", owner, version, version)
+	}
+	newUpload := func(owner, version string) *http.Request {
+		return NewRequestWithBody(t, "PUT", uploadURL(owner, version), bytes.NewBufferString("jar"))
+	}
+
+	t.Run("Session", func(t *testing.T) {
+		session := loginUser(t, "user2")
+
+		// a session cookie alone could be sent by any site the user visits
+		session.MakeRequest(t, newUpload("user2", "1.0"), http.StatusUnauthorized)
+
+		req := newUpload("user2", "1.0")
+		req.Header.Add("X-Csrf-Token", "invalid")
+		session.MakeRequest(t, req, http.StatusBadRequest)
+
+		req = newUpload("user2", "1.0")
+		req.Header.Add("X-Csrf-Token", GetCSRF(t, session, "/user/settings"))
+		session.MakeRequest(t, req, http.StatusCreated)
+
+		session.MakeRequest(t, NewRequest(t, "GET", uploadURL("user2", "1.0")), http.StatusOK)
+	})
+
+	t.Run("Token", func(t *testing.T) {
+		token := getTokenForLoggedInUser(t, loginUser(t, "user2"))
+
+		req := newUpload("user2", "1.1")
+		req.SetBasicAuth("user2", token)
+		MakeRequest(t, req, http.StatusCreated)
+	})
+
+	t.Run("TwoFactor", func(t *testing.T) {
+		// user24 is enrolled in two-factor authentication
+		req := newUpload("user24", "1.0")
+		req = AddBasicAuthHeader(req, "user24")
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = newUpload("user24", "1.0")
+		req = AddBasicAuthHeader(req, "user24")
+		req.Header.Add("X-Gitea-OTP", "000000")
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequest(t, "GET", uploadURL("user24", "1.0"))
+		req = AddBasicAuthHeader(req, "user24")
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		// users without two-factor authentication can still use their password
+		req = newUpload("user2", "1.2")
+		req = AddBasicAuthHeader(req, "user2")
+		MakeRequest(t, req, http.StatusCreated)
+	})
+}
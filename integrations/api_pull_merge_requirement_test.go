@@ -0,0 +1,75 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	auth "code.gitea.io/gitea/modules/forms"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIPullMergeRequirements(t *testing.T) {
+	defer prepareTestEnv(t)()
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+	pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{Status: models.PullRequestStatusMergeable}, models.Cond("has_merged = ?", false)).(*models.PullRequest)
+
+	session := loginUser(t, owner.Name)
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/requirements?token=%s", owner.Name, repo.Name, pr.Index, token)
+
+	req := NewRequestWithJSON(t, http.MethodPost, urlStr, &api.SetPullMergeRequirementOption{
+		Name:        "cla",
+		Description: "waiting for the signature",
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var requirement api.PullMergeRequirement
+	DecodeJSON(t, resp, &requirement)
+	assert.Equal(t, "cla", requirement.Name)
+	assert.False(t, requirement.Satisfied)
+
+	// an unsatisfied requirement blocks the merge
+	req = NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/merge?token=%s", owner.Name, repo.Name, pr.Index, token), &auth.MergePullRequestForm{
+		Do: string(models.MergeStyleMerge),
+	})
+	session.MakeRequest(t, req, http.StatusMethodNotAllowed)
+
+	req = NewRequestWithJSON(t, http.MethodPost, urlStr, &api.SetPullMergeRequirementOption{
+		Name:      "cla",
+		Satisfied: true,
+		TargetURL: "https://cla.example.com",
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &requirement)
+	assert.True(t, requirement.Satisfied)
+
+	req = NewRequest(t, http.MethodGet, urlStr)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var requirements []*api.PullMergeRequirement
+	DecodeJSON(t, resp, &requirements)
+	if assert.Len(t, requirements, 1) {
+		assert.Equal(t, "https://cla.example.com", requirements[0].TargetURL)
+	}
+
+	req = NewRequest(t, http.MethodDelete, fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/requirements/%d?token=%s", owner.Name, repo.Name, pr.Index, requirement.ID, token))
+	session.MakeRequest(t, req, http.StatusNoContent)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// the requirements can only be set by the users who can write to the code
+	user4 := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+	session4 := loginUser(t, user4.Name)
+	token4 := getTokenForLoggedInUser(t, session4)
+	req = NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/requirements?token=%s", owner.Name, repo.Name, pr.Index, token4), &api.SetPullMergeRequirementOption{
+		Name:      "cla",
+		Satisfied: true,
+	})
+	session4.MakeRequest(t, req, http.StatusForbidden)
+}
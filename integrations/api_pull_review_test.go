@@ -5,11 +5,14 @@
 package integrations
 
 import (
+	"encoding/base64"
 	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/repofiles"
 	api "code.gitea.io/gitea/modules/structs"
 	"github.com/stretchr/testify/assert"
 )
@@ -139,6 +142,72 @@ func TestAPIPullReview(t *testing.T) {
 	req = NewRequestf(t, http.MethodDelete, "/api/v1/repos/%s/%s/pulls/%d/reviews/%d?token=%s", repo.OwnerName, repo.Name, pullIssue.Index, review.ID, token)
 	resp = session.MakeRequest(t, req, http.StatusNoContent)
 
+	// test pending review workflow
+	req = NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/reviews?token=%s", repo.OwnerName, repo.Name, pullIssue.Index, token), &api.CreatePullReviewOptions{
+		Event: api.ReviewStatePending,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &review)
+	assert.EqualValues(t, "PENDING", review.State)
+	assert.EqualValues(t, 0, review.CodeCommentsCount)
+	pendingID := review.ID
+
+	// only one pending review per user
+	req = NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/reviews?token=%s", repo.OwnerName, repo.Name, pullIssue.Index, token), &api.CreatePullReviewOptions{
+		Event: api.ReviewStatePending,
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/reviews/%d/comments?token=%s", repo.OwnerName, repo.Name, pullIssue.Index, pendingID, token), &api.CreatePullReviewComment{
+		Path:       "README.md",
+		Body:       "a pending comment",
+		NewLineNum: 1,
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var reviewComment api.PullReviewComment
+	DecodeJSON(t, resp, &reviewComment)
+	assert.EqualValues(t, pendingID, reviewComment.ReviewID)
+	assert.EqualValues(t, 1, reviewComment.LineNum)
+
+	req = NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/reviews/%d/comments?token=%s", repo.OwnerName, repo.Name, pullIssue.Index, pendingID, token), &api.CreatePullReviewComment{
+		Path:       "README.md",
+		Body:       "another pending comment",
+		OldLineNum: 1,
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var secondComment api.PullReviewComment
+	DecodeJSON(t, resp, &secondComment)
+
+	req = NewRequestf(t, http.MethodDelete, "/api/v1/repos/%s/%s/pulls/%d/reviews/%d/comments/%d?token=%s", repo.OwnerName, repo.Name, pullIssue.Index, pendingID, secondComment.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+
+	// a failing comment must not leave the other comments of the batch behind
+	req = NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/reviews?token=%s", repo.OwnerName, repo.Name, pullIssue.Index, token), &api.CreatePullReviewOptions{
+		Body:  "broken review",
+		Event: api.ReviewStateComment,
+		Comments: []api.CreatePullReviewComment{{
+			Path:       "README.md",
+			Body:       "fine",
+			NewLineNum: 2,
+		}, {
+			Path:            "README.md",
+			Body:            "invalid range",
+			NewLineNum:      1,
+			NewStartLineNum: 3,
+		}},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/reviews/%d?token=%s", repo.OwnerName, repo.Name, pullIssue.Index, pendingID, token), &api.SubmitPullReviewOptions{
+		Event: api.ReviewStateComment,
+		Body:  "submitted in one go",
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &review)
+	assert.EqualValues(t, pendingID, review.ID)
+	assert.EqualValues(t, "COMMENT", review.State)
+	assert.EqualValues(t, 1, review.CodeCommentsCount)
+
 	// test get review requests
 	// to make it simple, use same api with get review
 	pullIssue12 := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 12}).(*models.Issue)
@@ -245,3 +314,59 @@ func TestAPIPullReviewRequest(t *testing.T) {
 	req = NewRequestWithJSON(t, http.MethodDelete, fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/requested_reviewers?token=%s", repo3.OwnerName, repo3.Name, pullIssue12.Index, token), &api.PullReviewRequestOptions{})
 	session.MakeRequest(t, req, http.StatusNoContent)
 }
+
+func TestAPIPullReviewSuggestion(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+		org26 := models.AssertExistsAndLoadBean(t, &models.User{ID: 26}).(*models.User)
+		pr := createOutdatedPR(t, user, org26)
+		assert.NoError(t, pr.LoadBaseRepo())
+		assert.NoError(t, pr.LoadHeadRepo())
+		assert.NoError(t, pr.LoadIssue())
+
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+
+		suggestion := "File B with a suggested change"
+		req := NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/reviews?token=%s", pr.BaseRepo.OwnerName, pr.BaseRepo.Name, pr.Issue.Index, token), &api.CreatePullReviewOptions{
+			Body:  "review with a suggestion",
+			Event: api.ReviewStateComment,
+			Comments: []api.CreatePullReviewComment{{
+				Path:       "File_B",
+				Body:       "Please change this",
+				NewLineNum: 1,
+				Suggestion: &suggestion,
+			}},
+		})
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var review api.PullReview
+		DecodeJSON(t, resp, &review)
+
+		req = NewRequestf(t, http.MethodGet, "/api/v1/repos/%s/%s/pulls/%d/reviews/%d/comments?token=%s", pr.BaseRepo.OwnerName, pr.BaseRepo.Name, pr.Issue.Index, review.ID, token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		var comments []*api.PullReviewComment
+		DecodeJSON(t, resp, &comments)
+		if !assert.Len(t, comments, 1) {
+			return
+		}
+		if assert.NotNil(t, comments[0].Suggestion) {
+			assert.Equal(t, suggestion+"\n", *comments[0].Suggestion)
+		}
+		assert.Empty(t, comments[0].SuggestionCommitID)
+
+		applyURL := fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/reviews/%d/comments/%d/apply_suggestion?token=%s", pr.BaseRepo.OwnerName, pr.BaseRepo.Name, pr.Issue.Index, review.ID, comments[0].ID, token)
+		resp = session.MakeRequest(t, NewRequest(t, http.MethodPost, applyURL), http.StatusOK)
+		var comment api.PullReviewComment
+		DecodeJSON(t, resp, &comment)
+		assert.NotEmpty(t, comment.SuggestionCommitID)
+
+		contents, err := repofiles.GetContents(pr.HeadRepo, "File_B", pr.HeadBranch, false)
+		assert.NoError(t, err)
+		content, err := base64.StdEncoding.DecodeString(*contents.Content)
+		assert.NoError(t, err)
+		assert.Equal(t, suggestion, string(content))
+
+		// a suggestion can only be applied once
+		session.MakeRequest(t, NewRequest(t, http.MethodPost, applyURL), http.StatusConflict)
+	})
+}
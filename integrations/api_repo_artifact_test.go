@@ -0,0 +1,74 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoArtifacts(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	session := loginUser(t, user.Name)
+	token := getTokenForLoggedInUser(t, session)
+
+	// upload an artifact for the master branch
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("artifact", "build.zip")
+	assert.NoError(t, err)
+	_, err = part.Write([]byte("artifact content"))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.WriteField("sha", "master"))
+	assert.NoError(t, writer.WriteField("retention_days", "7"))
+	assert.NoError(t, writer.Close())
+
+	req := NewRequestWithBody(t, "POST", "/api/v1/repos/user2/repo1/artifacts?token="+token, body)
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var artifact api.Artifact
+	DecodeJSON(t, resp, &artifact)
+	assert.EqualValues(t, "build.zip", artifact.Name)
+	assert.EqualValues(t, "master", artifact.Ref)
+	assert.EqualValues(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", artifact.SHA)
+	assert.EqualValues(t, 16, artifact.Size)
+	assert.NotNil(t, artifact.Expires)
+
+	// list the artifacts of the commit
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/artifacts?sha=%s&token=%s", artifact.SHA, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var artifacts []*api.Artifact
+	DecodeJSON(t, resp, &artifacts)
+	assert.Len(t, artifacts, 3)
+
+	// download it
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/artifacts/%d/download?token=%s", artifact.ID, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.EqualValues(t, "artifact content", resp.Body.String())
+	req = NewRequestf(t, "GET", "/user2/repo1/artifacts/%d", artifact.ID)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.EqualValues(t, "artifact content", resp.Body.String())
+
+	// delete it
+	req = NewRequestf(t, "DELETE", "/api/v1/repos/user2/repo1/artifacts/%d?token=%s", artifact.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/artifacts/%d?token=%s", artifact.ID, token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// readers cannot upload artifacts
+	user4 := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+	session4 := loginUser(t, user4.Name)
+	token4 := getTokenForLoggedInUser(t, session4)
+	req = NewRequestf(t, "POST", "/api/v1/repos/user2/repo1/artifacts?token="+token4)
+	session4.MakeRequest(t, req, http.StatusForbidden)
+}
@@ -32,6 +32,8 @@ func getRepoEditOptionFromRepo(repo *models.Repository) *api.EditRepoOption {
 			EnableTimeTracker:                config.EnableTimetracker,
 			AllowOnlyContributorsToTrackTime: config.AllowOnlyContributorsToTrackTime,
 			EnableIssueDependencies:          config.EnableDependencies,
+			EnableDueDateReminders:           config.EnableDueDateReminders,
+			DueDateReminderDays:              config.DueDateReminderLeadDays(),
 		}
 	} else if unit, err := repo.GetUnit(models.UnitTypeExternalTracker); err == nil {
 		config := unit.ExternalTrackerConfig()
@@ -0,0 +1,35 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIReposGitStats(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	session := loginUser(t, user.Name)
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/%s/repo1/git/stats?largest_blobs=1&token="+token, user.Name)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var stats api.GitStats
+	DecodeJSON(t, resp, &stats)
+	assert.Len(t, stats.LargestBlobs, 1)
+	assert.NotNil(t, stats.Refs)
+	assert.NotZero(t, stats.Refs.Branches)
+	assert.NotZero(t, stats.Refs.Tags)
+
+	// Test a non-existent repository
+	req = NewRequestf(t, "GET", "/api/v1/repos/%s/unknown/git/stats?token="+token, user.Name)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
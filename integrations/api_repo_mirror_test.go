@@ -0,0 +1,119 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIPushMirrors(t *testing.T) {
+	defer prepareTestEnv(t)()
+	defer func(importLocalPaths bool) {
+		setting.ImportLocalPaths = importLocalPaths
+	}(setting.ImportLocalPaths)
+	setting.ImportLocalPaths = true
+
+	remotePath := filepath.Join(t.TempDir(), "remote.git")
+	assert.NoError(t, git.InitRepository(remotePath, true))
+
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := fmt.Sprintf("/api/v1/repos/user2/repo1/push_mirrors?token=%s", token)
+
+	// invalid interval
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.CreatePushMirrorOption{
+		RemoteAddress: remotePath,
+		Interval:      "1s",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CreatePushMirrorOption{
+		RemoteAddress:   remotePath,
+		Interval:        "8h",
+		SyncWindowStart: "22:00",
+		SyncWindowEnd:   "06:00",
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var mirror api.PushMirror
+	DecodeJSON(t, resp, &mirror)
+	assert.Equal(t, remotePath, mirror.RemoteAddress)
+	assert.Equal(t, "8h0m0s", mirror.Interval)
+	assert.Equal(t, "22:00", mirror.SyncWindowStart)
+	assert.Equal(t, "06:00", mirror.SyncWindowEnd)
+	assert.NotNil(t, mirror.NextUpdate)
+	models.AssertExistsAndLoadBean(t, &models.PushMirror{ID: mirror.ID, RepoID: 1})
+
+	req = NewRequest(t, "GET", urlStr)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var mirrors []*api.PushMirror
+	DecodeJSON(t, resp, &mirrors)
+	assert.Len(t, mirrors, 1)
+
+	mirrorURL := fmt.Sprintf("/api/v1/repos/user2/repo1/push_mirrors/%d?token=%s", mirror.ID, token)
+	interval := "0"
+	req = NewRequestWithJSON(t, "PATCH", mirrorURL, &api.EditPushMirrorOption{Interval: &interval, IncludeRefs: []string{"refs/tags/v*"}})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &mirror)
+	assert.Equal(t, "0s", mirror.Interval)
+	assert.Nil(t, mirror.NextUpdate)
+	assert.Equal(t, []string{"refs/tags/v*"}, mirror.IncludeRefs)
+
+	// invalid ref pattern
+	req = NewRequestWithJSON(t, "PATCH", mirrorURL, &api.EditPushMirrorOption{ExcludeRefs: []string{"v*"}})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequest(t, "DELETE", mirrorURL)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.PushMirror{ID: mirror.ID})
+	req = NewRequest(t, "GET", mirrorURL)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// the push mirrors require the admin permission of the repository
+	session = loginUser(t, "user4")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/user2/repo1/push_mirrors?token=%s", token))
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAPIEditMirror(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	// repo1 is not a mirror
+	req := NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/user2/repo1/mirror?token=%s", token))
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 5, IsMirror: true}).(*models.Repository)
+	assert.NoError(t, models.InsertMirror(&models.Mirror{RepoID: repo.ID, Interval: 8 * time.Hour, EnablePrune: true}))
+	session = loginUser(t, "user1")
+	token = getTokenForLoggedInUser(t, session)
+	urlStr := fmt.Sprintf("/api/v1/repos/user3/%s/mirror?token=%s", repo.Name, token)
+
+	start, end := "01:00", "05:30"
+	req = NewRequestWithJSON(t, "PATCH", urlStr, &api.EditMirrorOption{SyncWindowStart: &start, SyncWindowEnd: &end})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var mirror api.Mirror
+	DecodeJSON(t, resp, &mirror)
+	assert.Equal(t, "01:00", mirror.SyncWindowStart)
+	assert.Equal(t, "05:30", mirror.SyncWindowEnd)
+	models.AssertExistsAndLoadBean(t, &models.Mirror{RepoID: repo.ID, SyncWindowStart: 60, SyncWindowEnd: 5*60 + 30})
+
+	end = "25:00"
+	req = NewRequestWithJSON(t, "PATCH", urlStr, &api.EditMirrorOption{SyncWindowEnd: &end})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+}
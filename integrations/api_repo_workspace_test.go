@@ -0,0 +1,83 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoWorkspace(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	cloneLink := repo.CloneLink()
+
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/workspace")
+	resp := MakeRequest(t, req, http.StatusOK)
+	var workspace api.RepoWorkspace
+	DecodeJSON(t, resp, &workspace)
+	assert.Equal(t, cloneLink.HTTPS, workspace.CloneURL)
+	assert.Equal(t, cloneLink.SSH, workspace.SSHURL)
+	assert.Equal(t, "master", workspace.DefaultBranch)
+	assert.False(t, workspace.HasDevcontainer)
+	assert.Empty(t, workspace.DevcontainerPath)
+	if assert.Len(t, workspace.IDELinks, len(setting.IDE.AppList)) && len(workspace.IDELinks) > 0 {
+		assert.Equal(t, "vscode", workspace.IDELinks[0].Name)
+		assert.Equal(t, "vscode://vscode.git/clone?url="+url.QueryEscape(cloneLink.HTTPS), workspace.IDELinks[0].URL)
+	}
+
+	// the code of private repositories is only readable by their members
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo2/workspace")
+	MakeRequest(t, req, http.StatusNotFound)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo2/workspace?token=%s", token)
+	MakeRequest(t, req, http.StatusOK)
+}
+
+func TestRepoHomeIDELinks(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	req := NewRequest(t, "GET", "/user2/repo1")
+	resp := MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	htmlDoc.AssertElement(t, "#clone-panel a[href^='vscode://vscode.git/clone?url=']", true)
+}
+
+func TestRepoDevEnvironments(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+		repo1 := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+		_, err := createFile(user2, repo1, ".devcontainer/devcontainer.json")
+		assert.NoError(t, err)
+		_, err = createFile(user2, repo1, ".gitpod.yml")
+		assert.NoError(t, err)
+
+		req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/workspace")
+		resp := MakeRequest(t, req, http.StatusOK)
+		var workspace api.RepoWorkspace
+		DecodeJSON(t, resp, &workspace)
+		assert.True(t, workspace.HasDevcontainer)
+		assert.Equal(t, ".devcontainer/devcontainer.json", workspace.DevcontainerPath)
+		assert.True(t, workspace.HasGitpod)
+		assert.Equal(t, ".gitpod.yml", workspace.GitpodConfigPath)
+		assert.Equal(t, setting.GitpodLink(repo1.HTMLURL()), workspace.GitpodURL)
+
+		req = NewRequest(t, "GET", "/user2/repo1")
+		resp = MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		htmlDoc.AssertElement(t, "#repo-dev-environments a[href$='/src/branch/master/.devcontainer/devcontainer.json']", true)
+		htmlDoc.AssertElement(t, "#repo-dev-environments a[href^='https://gitpod.io/#']", true)
+	})
+}
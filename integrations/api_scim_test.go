@@ -0,0 +1,113 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/scim"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSCIMRequest(t *testing.T, method, urlStr, token string, v interface{}) *http.Request {
+	var req *http.Request
+	if v == nil {
+		req = NewRequest(t, method, "/api/scim/v2"+urlStr)
+	} else {
+		req = NewRequestWithJSON(t, method, "/api/scim/v2"+urlStr, v)
+		req.Header.Set("Content-Type", scim.ContentType)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestAPISCIMAuthorization(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	MakeRequest(t, newSCIMRequest(t, "GET", "/Users", "", nil), http.StatusUnauthorized)
+
+	// user2 is not an admin
+	token := getTokenForLoggedInUser(t, loginUser(t, "user2"))
+	MakeRequest(t, newSCIMRequest(t, "GET", "/Users", token, nil), http.StatusForbidden)
+
+	token = getTokenForLoggedInUser(t, loginUser(t, "user1"))
+	resp := MakeRequest(t, newSCIMRequest(t, "GET", "/ServiceProviderConfig", token, nil), http.StatusOK)
+	var config scim.ServiceProviderConfig
+	DecodeJSON(t, resp, &config)
+	assert.True(t, config.Patch.Supported)
+}
+
+func TestAPISCIMProvisioning(t *testing.T) {
+	defer prepareTestEnv(t)()
+	token := getTokenForLoggedInUser(t, loginUser(t, "user1"))
+
+	// provision a user
+	resp := MakeRequest(t, newSCIMRequest(t, "POST", "/Users", token, &scim.User{
+		Schemas:  []string{scim.SchemaUser},
+		UserName: "scimuser",
+		Name:     &scim.Name{GivenName: "Scim", FamilyName: "User"},
+		Emails:   []scim.Email{{Value: "scimuser@example.com", Type: "work", Primary: true}},
+	}), http.StatusCreated)
+	var created scim.User
+	DecodeJSON(t, resp, &created)
+	assert.Equal(t, "scimuser", created.UserName)
+	assert.True(t, *created.Active)
+	user := models.AssertExistsAndLoadBean(t, &models.User{Name: "scimuser"}).(*models.User)
+	assert.Equal(t, "Scim User", user.FullName)
+	assert.Equal(t, fmt.Sprint(user.ID), created.ID)
+
+	// the same user name cannot be provisioned twice
+	MakeRequest(t, newSCIMRequest(t, "POST", "/Users", token, &scim.User{
+		Schemas:  []string{scim.SchemaUser},
+		UserName: "scimuser",
+		Emails:   []scim.Email{{Value: "other@example.com"}},
+	}), http.StatusConflict)
+
+	resp = MakeRequest(t, newSCIMRequest(t, "GET", `/Users?filter=userName+eq+%22scimuser%22`, token, nil), http.StatusOK)
+	var list scim.ListResponse
+	DecodeJSON(t, resp, &list)
+	assert.EqualValues(t, 1, list.TotalResults)
+	MakeRequest(t, newSCIMRequest(t, "GET", `/Users?filter=title+eq+%22x%22`, token, nil), http.StatusBadRequest)
+
+	// groups are mapped to teams
+	resp = MakeRequest(t, newSCIMRequest(t, "POST", "/Groups", token, &scim.Group{
+		Schemas:     []string{scim.SchemaGroup},
+		DisplayName: "Developers",
+		Members:     []scim.Reference{{Value: created.ID}},
+	}), http.StatusCreated)
+	var group scim.Group
+	DecodeJSON(t, resp, &group)
+	assert.Len(t, group.Members, 1)
+	models.AssertExistsAndLoadBean(t, &models.TeamUser{TeamID: 2, UID: user.ID})
+
+	MakeRequest(t, newSCIMRequest(t, "PATCH", "/Groups/"+group.ID, token, &scim.PatchRequest{
+		Schemas: []string{scim.SchemaPatchOp},
+		Operations: []scim.PatchOperation{
+			{Op: "remove", Path: fmt.Sprintf(`members[value eq "%d"]`, user.ID)},
+		},
+	}), http.StatusOK)
+	models.AssertNotExistsBean(t, &models.TeamUser{TeamID: 2, UID: user.ID})
+
+	// deactivate
+	MakeRequest(t, newSCIMRequest(t, "PATCH", "/Users/"+created.ID, token, &scim.PatchRequest{
+		Schemas: []string{scim.SchemaPatchOp},
+		Operations: []scim.PatchOperation{
+			{Op: "replace", Value: []byte(`{"active":false}`)},
+		},
+	}), http.StatusOK)
+	user = models.AssertExistsAndLoadBean(t, &models.User{ID: user.ID}).(*models.User)
+	assert.True(t, user.ProhibitLogin)
+
+	MakeRequest(t, newSCIMRequest(t, "DELETE", "/Groups/"+group.ID, token, nil), http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.SCIMGroup{DisplayName: "Developers"})
+	MakeRequest(t, newSCIMRequest(t, "DELETE", "/Users/"+created.ID, token, nil), http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.User{Name: "scimuser"})
+}
@@ -10,6 +10,8 @@ import (
 
 	"code.gitea.io/gitea/models"
 	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
 )
 
 // TestAPICreateAndDeleteToken tests that token that was just created can be deleted
@@ -61,3 +63,27 @@ func TestAPIDeleteMissingToken(t *testing.T) {
 	req = AddBasicAuthHeader(req, user.Name)
 	MakeRequest(t, req, http.StatusNotFound)
 }
+
+// TestAPITokenUsage tests that the API requests made with a token are counted
+func TestAPITokenUsage(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	accessToken, err := models.GetAccessTokenBySHA(token)
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		req := NewRequestf(t, "GET", "/api/v1/user?token=%s", token)
+		MakeRequest(t, req, http.StatusOK)
+	}
+	models.AssertExistsAndLoadBean(t, &models.APIUsage{UserID: 2, TokenID: accessToken.ID, NumRequests: 2})
+
+	req := NewRequest(t, "GET", "/user/settings/applications")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "2 API requests in the last 30 days")
+
+	req = NewRequest(t, "GET", "/admin/api-usage")
+	resp = loginUser(t, "user1").MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), accessToken.Name)
+}
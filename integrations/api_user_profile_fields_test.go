@@ -0,0 +1,63 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIUserProfileFields(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	var fields []*api.UserProfileField
+	req := NewRequest(t, "GET", "/api/v1/users/user2/profile_fields")
+	resp := MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &fields)
+	if assert.Len(t, fields, 1) {
+		assert.Equal(t, "department", fields[0].Name)
+		assert.Equal(t, "Engineering", fields[0].Value)
+	}
+
+	session := loginUser(t, "user4")
+	token := getTokenForLoggedInUser(t, session)
+	req = NewRequest(t, "GET", "/api/v1/users/user2/profile_fields?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &fields)
+	assert.Len(t, fields, 2)
+
+	session = loginUser(t, "user2")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequest(t, "GET", "/api/v1/users/user2/profile_fields?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &fields)
+	assert.Len(t, fields, 3)
+
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/user/profile_fields?token="+token, &api.EditUserProfileFieldsOption{
+		Values: map[string]string{"homepage": "not a link"},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/user/profile_fields?token="+token, &api.EditUserProfileFieldsOption{
+		Values: map[string]string{"unknown": "value"},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/user/profile_fields?token="+token, &api.EditUserProfileFieldsOption{
+		Values: map[string]string{"department": "Support", "employee_id": ""},
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &fields)
+	if assert.Len(t, fields, 3) {
+		assert.Equal(t, "Support", fields[0].Value)
+		assert.Equal(t, "https://example.com/user2", fields[1].Value)
+		assert.Equal(t, "link", fields[1].Type)
+		assert.Equal(t, "limited", fields[1].Visibility)
+		assert.Empty(t, fields[2].Value)
+	}
+}
@@ -97,11 +97,8 @@ func getLDAPServerHost() string {
 	return host
 }
 
-func addAuthSourceLDAP(t *testing.T, sshKeyAttribute string) {
-	session := loginUser(t, "user1")
-	csrf := GetCSRF(t, session, "/admin/auths/new")
-	req := NewRequestWithValues(t, "POST", "/admin/auths/new", map[string]string{
-		"_csrf":                    csrf,
+func addAuthSourceLDAP(t *testing.T, sshKeyAttribute string, groupTeamMap ...string) {
+	values := map[string]string{
 		"type":                     "2",
 		"name":                     "ldap",
 		"host":                     getLDAPServerHost(),
@@ -119,7 +116,20 @@ func addAuthSourceLDAP(t *testing.T, sshKeyAttribute string) {
 		"attribute_ssh_public_key": sshKeyAttribute,
 		"is_sync_enabled":          "on",
 		"is_active":                "on",
-	})
+	}
+	if len(groupTeamMap) > 0 {
+		values["groups_enabled"] = "on"
+		values["group_dn"] = "ou=people,dc=planetexpress,dc=com"
+		values["group_filter"] = "(cn=git)"
+		values["group_member_uid"] = "member"
+		values["user_uid"] = "dn"
+		values["group_team_map"] = groupTeamMap[0]
+		values["group_team_map_removal"] = "on"
+	}
+
+	session := loginUser(t, "user1")
+	values["_csrf"] = GetCSRF(t, session, "/admin/auths/new")
+	req := NewRequestWithValues(t, "POST", "/admin/auths/new", values)
 	session.MakeRequest(t, req, http.StatusFound)
 }
 
@@ -240,3 +250,40 @@ func TestLDAPUserSSHKeySync(t *testing.T) {
 		assert.ElementsMatch(t, u.SSHKeys, syncedKeys, "Unequal number of keys synchronized for user: %s", u.UserName)
 	}
 }
+
+func TestLDAPGroupTeamSync(t *testing.T) {
+	if skipLDAPTests() {
+		t.Skip()
+		return
+	}
+	defer prepareTestEnv(t)()
+	addAuthSourceLDAP(t, "", `{"cn=ship_crew,ou=people,dc=planetexpress,dc=com": {"org26": ["team11"]}, "cn=admin_staff,ou=people,dc=planetexpress,dc=com": {"user3": ["team1"]}}`)
+	models.SyncExternalUsers(context.Background(), true)
+	assert.NoError(t, models.SyncLDAPGroupTeams(context.Background()))
+
+	org26 := models.AssertExistsAndLoadBean(t, &models.User{Name: "org26"}).(*models.User)
+	team11, err := org26.GetTeam("team11")
+	assert.NoError(t, err)
+	org3 := models.AssertExistsAndLoadBean(t, &models.User{Name: "user3"}).(*models.User)
+	team1, err := org3.GetTeam("team1")
+	assert.NoError(t, err)
+
+	isMember := func(team *models.Team, name string) bool {
+		u := models.AssertExistsAndLoadBean(t, &models.User{Name: name}).(*models.User)
+		is, err := models.IsTeamMember(team.OrgID, team.ID, u.ID)
+		assert.NoError(t, err)
+		return is
+	}
+	assert.True(t, isMember(team1, "professor"))
+	assert.True(t, isMember(team1, "hermes"))
+	assert.False(t, isMember(team1, "fry"))
+	assert.True(t, isMember(team11, "fry"))
+	assert.True(t, isMember(team11, "leela"))
+	assert.False(t, isMember(team11, "professor"))
+
+	// memberships without a corresponding group are removed
+	professor := models.AssertExistsAndLoadBean(t, &models.User{Name: "professor"}).(*models.User)
+	assert.NoError(t, models.AddTeamMember(team11, professor.ID))
+	assert.NoError(t, models.SyncLDAPGroupTeams(context.Background()))
+	assert.False(t, isMember(team11, "professor"))
+}
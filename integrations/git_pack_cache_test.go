@@ -0,0 +1,117 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitPackCache(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		if git.CheckGitVersionAtLeast("2.18") != nil {
+			t.Skip("the git version does not support the wire protocol version 2")
+		}
+
+		tmpDir, err := ioutil.TempDir("", "pack-cache")
+		assert.NoError(t, err)
+		defer util.RemoveAll(tmpDir)
+
+		git.DefaultPackCache, err = git.NewPackCache(filepath.Join(tmpDir, "cache"), time.Hour, -1)
+		assert.NoError(t, err)
+		defer func() {
+			git.DefaultPackCache = nil
+		}()
+
+		u.Path = "user2/repo1.git"
+		for i := 0; i < 2; i++ {
+			dstPath := filepath.Join(tmpDir, "clone", string(rune('a'+i)))
+			_, err = git.NewCommand("-c", "protocol.version=2", "clone", u.String(), dstPath).Run()
+			assert.NoError(t, err)
+			exist, err := util.IsExist(filepath.Join(dstPath, "README.md"))
+			assert.NoError(t, err)
+			assert.True(t, exist)
+		}
+
+		// The second clone is served from the pack cache
+		stats := git.DefaultPackCache.Stats()
+		assert.EqualValues(t, 1, stats.Misses)
+		assert.EqualValues(t, 1, stats.Hits)
+		assert.Equal(t, 1, stats.Entries)
+	})
+}
+
+func TestGitPackCacheInvalidation(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		if git.CheckGitVersionAtLeast("2.18") != nil {
+			t.Skip("the git version does not support the wire protocol version 2")
+		}
+
+		tmpDir, err := ioutil.TempDir("", "pack-cache")
+		assert.NoError(t, err)
+		defer util.RemoveAll(tmpDir)
+
+		git.DefaultPackCache, err = git.NewPackCache(filepath.Join(tmpDir, "cache"), time.Hour, -1)
+		assert.NoError(t, err)
+		defer func() {
+			git.DefaultPackCache = nil
+		}()
+
+		u.Path = "user2/repo1.git"
+		u.User = url.UserPassword("user2", userPassword)
+		clonePath := filepath.Join(tmpDir, "clone")
+		t.Run("Clone", doGitClone(clonePath, u))
+
+		// fetching the same commit into empty repositories sends the same request
+		fetch := func(name string) {
+			dstPath := filepath.Join(tmpDir, name)
+			assert.NoError(t, git.InitRepository(dstPath, true))
+			_, err := git.NewCommand("-c", "protocol.version=2", "fetch", u.String(), "refs/heads/master").RunInDir(dstPath)
+			assert.NoError(t, err)
+		}
+		fetch("a")
+		fetch("b")
+		stats := git.DefaultPackCache.Stats()
+		assert.EqualValues(t, 1, stats.Hits)
+		assert.Equal(t, 2, stats.Entries)
+
+		// after a ref has been deleted the objects it referred to may be gone,
+		// so the packs of the repository must not be served anymore
+		_, err = git.NewCommand("push", "origin", "--delete", "branch2").RunInDir(clonePath)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, git.DefaultPackCache.Stats().Entries)
+
+		fetch("c")
+		stats = git.DefaultPackCache.Stats()
+		assert.EqualValues(t, 1, stats.Hits)
+		assert.Equal(t, 1, stats.Entries)
+	})
+}
+
+func TestGitRequireWireProtocolV2(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	defer func(require bool) {
+		setting.Git.RequireWireProtocolV2 = require
+	}(setting.Git.RequireWireProtocolV2)
+	setting.Git.RequireWireProtocolV2 = true
+
+	req := NewRequest(t, "GET", "/user2/repo1.git/info/refs?service=git-upload-pack")
+	MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequest(t, "GET", "/user2/repo1.git/info/refs?service=git-upload-pack")
+	req.Header.Set("Git-Protocol", "version=2")
+	MakeRequest(t, req, http.StatusOK)
+}
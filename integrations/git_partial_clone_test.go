@@ -0,0 +1,46 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitPartialClone(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		if git.CheckGitVersionAtLeast("2.22") != nil {
+			t.Skip("the git version does not support partial clones")
+		}
+
+		u.Path = "user2/repo1.git"
+		dstPath, err := ioutil.TempDir("", "repo1-partial-clone")
+		assert.NoError(t, err)
+		defer util.RemoveAll(dstPath)
+
+		_, err = git.NewCommand("clone", "--filter=blob:none", u.String(), dstPath).Run()
+		assert.NoError(t, err)
+
+		exist, err := util.IsExist(filepath.Join(dstPath, "README.md"))
+		assert.NoError(t, err)
+		assert.True(t, exist)
+
+		// The missing blobs are fetched from the promisor remote on demand
+		promisor, err := git.NewCommand("config", "remote.origin.promisor").RunInDir(dstPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "true", strings.TrimSpace(promisor))
+		filter, err := git.NewCommand("config", "remote.origin.partialclonefilter").RunInDir(dstPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "blob:none", strings.TrimSpace(filter))
+	})
+}
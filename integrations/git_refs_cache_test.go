@@ -0,0 +1,60 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	wiki_service "code.gitea.io/gitea/services/wiki"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitRefsCache(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		git.DefaultRefsCache = git.NewRefsCache(time.Hour, -1)
+		defer func() {
+			git.DefaultRefsCache = nil
+		}()
+
+		getRefs := func(repo string) string {
+			req := NewRequest(t, "GET", "/user2/"+repo+".git/info/refs?service=git-upload-pack")
+			return MakeRequest(t, req, http.StatusOK).Body.String()
+		}
+
+		// The second advertisement is served from the refs cache
+		refs := getRefs("repo1")
+		assert.Equal(t, refs, getRefs("repo1"))
+		stats := git.DefaultRefsCache.Stats()
+		assert.EqualValues(t, 1, stats.Misses)
+		assert.EqualValues(t, 1, stats.Hits)
+
+		// A push removes the advertisements of the repository
+		user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+		repo1 := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+		resp, err := createFile(user2, repo1, "refs-cache.txt")
+		assert.NoError(t, err)
+		refs = getRefs("repo1")
+		assert.Contains(t, refs, resp.Commit.SHA)
+		stats = git.DefaultRefsCache.Stats()
+		assert.EqualValues(t, 2, stats.Misses)
+		assert.EqualValues(t, 1, stats.Invalidations)
+
+		// An edit of the wiki removes the advertisements of the wiki
+		getRefs("repo1.wiki")
+		assert.NoError(t, wiki_service.AddWikiPage(user2, repo1, "Refs Cache", "content", "Add Refs Cache"))
+		wikiRepo, err := git.OpenRepository(repo1.WikiPath())
+		assert.NoError(t, err)
+		defer wikiRepo.Close()
+		commitID, err := wikiRepo.GetBranchCommitID("master")
+		assert.NoError(t, err)
+		assert.Contains(t, getRefs("repo1.wiki"), commitID)
+	})
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueContentHistory(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// issue 1 of user2/repo1 was posted by user1
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1}).(*models.Issue)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	body := "edited content"
+	req := NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/user2/repo1/issues/%d?token=%s", issue.Index, token), &api.EditIssueOption{
+		Body: &body,
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/issues/%d/content_history?token=%s", issue.Index, token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var apiHistory []*api.ContentHistory
+	DecodeJSON(t, resp, &apiHistory)
+	if assert.Len(t, apiHistory, 2) {
+		assert.Equal(t, body, apiHistory[0].Content)
+		assert.Equal(t, "user2", apiHistory[0].Editor.UserName)
+		assert.NotEmpty(t, apiHistory[0].DiffHTML)
+		assert.Equal(t, issue.Content, apiHistory[1].Content)
+		assert.True(t, apiHistory[1].IsFirstCreated)
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/issues/comments/2/content_history?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiHistory)
+	assert.Empty(t, apiHistory)
+
+	req = NewRequest(t, "GET", fmt.Sprintf("/user2/repo1/issues/%d/content_history", issue.Index))
+	resp = MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 2, htmlDoc.doc.Find("#content-history .item").Length())
+
+	// only the poster of the issue can purge its history, if allowed
+	purgeURL := fmt.Sprintf("/user2/repo1/issues/%d/content_history/purge", issue.Index)
+	req = NewRequestWithValues(t, "POST", purgeURL, map[string]string{
+		"_csrf": GetCSRF(t, session, "/user2/repo1/issues/1"),
+	})
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	defer func(allow bool) {
+		setting.Repository.Issue.AllowPurgeContentHistory = allow
+	}(setting.Repository.Issue.AllowPurgeContentHistory)
+	setting.Repository.Issue.AllowPurgeContentHistory = true
+
+	session = loginUser(t, "user1")
+	req = NewRequestWithValues(t, "POST", purgeURL, map[string]string{
+		"_csrf": GetCSRF(t, session, "/user2/repo1/issues/1"),
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertNotExistsBean(t, &models.IssueContentHistory{IssueID: issue.ID})
+}
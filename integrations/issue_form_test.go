@@ -0,0 +1,89 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/repofiles"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testIssueForm = `name: Bug Report
+about: Report a bug
+title: "[Bug] "
+body:
+  - type: input
+    id: version
+    attributes:
+      label: Version
+    validations:
+      required: true
+  - type: dropdown
+    id: database
+    attributes:
+      label: Database
+      options:
+        - PostgreSQL
+        - SQLite
+  - type: checkboxes
+    id: terms
+    attributes:
+      label: Checks
+      options:
+        - label: I searched the existing issues
+          required: true
+`
+
+func TestNewIssueFromForm(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+		repo1 := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+		_, err := repofiles.CreateOrUpdateRepoFile(repo1, user2, &repofiles.UpdateRepoFileOptions{
+			OldBranch: repo1.DefaultBranch,
+			TreePath:  ".gitea/ISSUE_TEMPLATE/bug.yaml",
+			Content:   testIssueForm,
+			IsNewFile: true,
+		})
+		assert.NoError(t, err)
+
+		session := loginUser(t, "user2")
+		req := NewRequest(t, "GET", path.Join("user2", "repo1", "issues", "new")+"?template=bug.yaml")
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		htmlDoc.AssertElement(t, "input[name=form-field-version]", true)
+		htmlDoc.AssertElement(t, "select[name=form-field-database]", true)
+		htmlDoc.AssertElement(t, "input[name=form-field-terms-0]", true)
+		link, exists := htmlDoc.doc.Find("form.ui.form").Attr("action")
+		assert.True(t, exists)
+
+		values := map[string]string{
+			"_csrf":               htmlDoc.GetCSRF(),
+			"title":               "[Bug] Crash",
+			"template":            "bug.yaml",
+			"form-field-version":  "1.14",
+			"form-field-database": "1",
+		}
+
+		// the required checkbox is not checked
+		req = NewRequestWithValues(t, "POST", link, values)
+		session.MakeRequest(t, req, http.StatusOK)
+
+		values["form-field-terms-0"] = "on"
+		req = NewRequestWithValues(t, "POST", link, values)
+		resp = session.MakeRequest(t, req, http.StatusFound)
+
+		issue := models.AssertExistsAndLoadBean(t, &models.Issue{RepoID: repo1.ID, Title: "[Bug] Crash"}).(*models.Issue)
+		assert.Equal(t, "bug.yaml", issue.Template)
+		assert.Equal(t, "### Version\n\n1.14\n\n### Database\n\nSQLite\n\n### Checks\n\n- [x] I searched the existing issues", issue.Content)
+		assert.NotEmpty(t, test.RedirectURL(resp))
+	})
+}
@@ -5,6 +5,7 @@
 package integrations
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"path"
@@ -93,7 +94,7 @@ func TestViewIssuesKeyword(t *testing.T) {
 		RepoID: repo.ID,
 		Index:  1,
 	}).(*models.Issue)
-	issues.UpdateIssueIndexer(issue)
+	issues.UpdateIssueIndexer(context.Background(), issue)
 	time.Sleep(time.Second * 1)
 	const keyword = "first"
 	req := NewRequestf(t, "GET", "%s/issues?q=%s", repo.RelLink(), keyword)
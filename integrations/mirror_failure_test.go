@@ -0,0 +1,46 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMirrorFailures(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	m := &models.PushMirror{RepoID: 1, Interval: time.Hour}
+	assert.NoError(t, models.InsertPushMirror(m))
+	for i := 0; i < 3; i++ {
+		m.RecordFailure("remote: Repository not found.")
+	}
+	assert.NoError(t, models.UpdatePushMirror(m))
+
+	// the administrators of the repository are warned on its home page
+	session := loginUser(t, "user2")
+	resp := session.MakeRequest(t, NewRequest(t, "GET", "/user2/repo1"), http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.Contains(t, htmlDoc.doc.Find(".mirror-last-error").Text(), "remote: Repository not found.")
+
+	// but not its readers
+	session = loginUser(t, "user4")
+	resp = session.MakeRequest(t, NewRequest(t, "GET", "/user2/repo1"), http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.Zero(t, htmlDoc.doc.Find(".mirror-last-error").Length())
+
+	session = loginUser(t, "user1")
+	resp = session.MakeRequest(t, NewRequest(t, "GET", "/admin/mirrors?type=push&failing=true"), http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.Contains(t, htmlDoc.doc.Find("table").Text(), "user2/repo1")
+	assert.Contains(t, htmlDoc.doc.Find(".mirror-last-error").Text(), "remote: Repository not found.")
+
+	session.MakeRequest(t, NewRequest(t, "GET", "/admin/mirrors"), http.StatusOK)
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyConsent(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	adminSession := loginUser(t, "user1")
+	adminToken := getTokenForLoggedInUser(t, adminSession)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithValues(t, "POST", "/admin/policies/new", map[string]string{
+		"_csrf":   GetCSRF(t, adminSession, "/admin/policies/new"),
+		"kind":    "0",
+		"title":   "Terms of Service",
+		"content": "Be nice.",
+	})
+	adminSession.MakeRequest(t, req, http.StatusFound)
+	doc := models.AssertExistsAndLoadBean(t, &models.PolicyDocument{Kind: models.PolicyDocumentTerms, Version: 1}).(*models.PolicyDocument)
+
+	// the users must accept the new terms before using the site, but not the API
+	req = NewRequest(t, "GET", "/user2/repo1")
+	resp := session.MakeRequest(t, req, http.StatusFound)
+	assert.EqualValues(t, "/user/policies", test.RedirectURL(resp))
+
+	var states []*api.PolicyConsentState
+	req = NewRequest(t, "GET", "/api/v1/user/policies?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &states)
+	if assert.Len(t, states, 1) {
+		assert.Equal(t, "terms", states[0].Kind)
+		assert.True(t, states[0].Pending)
+		assert.Nil(t, states[0].Accepted)
+	}
+
+	req = NewRequestWithValues(t, "POST", "/user/policies", map[string]string{
+		"_csrf":       GetCSRF(t, session, "/user/policies"),
+		"document_id": fmt.Sprint(doc.ID),
+	})
+	resp = session.MakeRequest(t, req, http.StatusFound)
+	assert.EqualValues(t, "/user2/repo1", test.RedirectURL(resp))
+	req = NewRequest(t, "GET", "/user2/repo1")
+	session.MakeRequest(t, req, http.StatusOK)
+
+	req = NewRequest(t, "GET", "/api/v1/admin/users/user2/policies?token="+adminToken)
+	resp = adminSession.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &states)
+	if assert.Len(t, states, 1) {
+		assert.False(t, states[0].Pending)
+		assert.EqualValues(t, 1, states[0].AcceptedVersion)
+		assert.NotNil(t, states[0].Accepted)
+	}
+
+	// the publisher accepted the terms when publishing them
+	var consents []*api.PolicyConsent
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/admin/policies/%d/consents?token=%s", doc.ID, adminToken))
+	resp = adminSession.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &consents)
+	if assert.Len(t, consents, 2) {
+		assert.Equal(t, "user1", consents[0].User.UserName)
+		assert.Equal(t, "user2", consents[1].User.UserName)
+	}
+
+	var docs []*api.PolicyDocument
+	req = NewRequest(t, "GET", "/api/v1/admin/policies?token="+adminToken)
+	resp = adminSession.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &docs)
+	if assert.Len(t, docs, 1) {
+		assert.EqualValues(t, 2, docs[0].Consents)
+	}
+
+	req = NewRequest(t, "GET", "/api/v1/user/policies?token="+token)
+	session.MakeRequest(t, req, http.StatusOK)
+	req = NewRequest(t, "GET", "/api/v1/admin/policies?token="+token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
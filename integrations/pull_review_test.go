@@ -4,8 +4,15 @@
 package integrations
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestPullView_ReviewerMissed(t *testing.T) {
@@ -18,3 +25,46 @@ func TestPullView_ReviewerMissed(t *testing.T) {
 	req = NewRequest(t, "GET", "/user2/repo1/pulls/3")
 	session.MakeRequest(t, req, http.StatusOK)
 }
+
+func TestPullReviewCodeCommentContext(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+		org26 := models.AssertExistsAndLoadBean(t, &models.User{ID: 26}).(*models.User)
+		pr := createOutdatedPR(t, user, org26)
+		assert.NoError(t, pr.LoadBaseRepo())
+		assert.NoError(t, pr.LoadIssue())
+
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+
+		req := NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/reviews?token=%s", pr.BaseRepo.OwnerName, pr.BaseRepo.Name, pr.Issue.Index, token), &api.CreatePullReviewOptions{
+			Body:  "review with a code comment",
+			Event: api.ReviewStateComment,
+			Comments: []api.CreatePullReviewComment{{
+				Path:       "File_B",
+				Body:       "Please change this",
+				NewLineNum: 1,
+			}},
+		})
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var review api.PullReview
+		DecodeJSON(t, resp, &review)
+
+		req = NewRequestf(t, http.MethodGet, "/api/v1/repos/%s/%s/pulls/%d/reviews/%d/comments?token=%s", pr.BaseRepo.OwnerName, pr.BaseRepo.Name, pr.Issue.Index, review.ID, token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		var comments []*api.PullReviewComment
+		DecodeJSON(t, resp, &comments)
+		if !assert.Len(t, comments, 1) {
+			return
+		}
+
+		contextURL := fmt.Sprintf("/%s/%s/pulls/%d/comments/%d/context", pr.BaseRepo.OwnerName, pr.BaseRepo.Name, pr.Issue.Index, comments[0].ID)
+		resp = session.MakeRequest(t, NewRequest(t, http.MethodGet, contextURL+"?direction=down&left=0&right=0"), http.StatusOK)
+		assert.Contains(t, resp.Body.String(), `data-line-num="1"`)
+		session.MakeRequest(t, NewRequest(t, http.MethodGet, contextURL+"?direction=sideways"), http.StatusBadRequest)
+
+		// The comment has to belong to the pull request
+		otherURL := fmt.Sprintf("/%s/%s/pulls/%d/comments/%d/context?direction=down", pr.BaseRepo.OwnerName, pr.BaseRepo.Name, pr.Issue.Index, 4)
+		session.MakeRequest(t, NewRequest(t, http.MethodGet, otherURL), http.StatusNotFound)
+	})
+}
@@ -7,6 +7,7 @@ package integrations
 import (
 	"net/http"
 	"path"
+	"strings"
 	"testing"
 	"time"
 
@@ -78,3 +79,25 @@ func testViewTimetrackingControls(t *testing.T, session *TestSession, user, repo
 		session.MakeRequest(t, req, http.StatusNotFound)
 	}
 }
+
+func TestTimeReportExport(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+
+	req := NewRequest(t, "GET", "/user2/repo1/insights/times?from=2000-01-01&until=2000-01-31")
+	session.MakeRequest(t, req, http.StatusOK)
+
+	req = NewRequest(t, "GET", "/user2/repo1/insights/times/export?from=2000-01-01&until=2000-01-31&user=user1")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(resp.Body.String()), "\n")
+	if assert.Len(t, lines, 3) {
+		assert.Equal(t, "date,user,repository,issue,title,seconds,time", lines[0])
+		assert.Contains(t, lines[1], ",user1,user2/repo1,1,issue1,400,")
+	}
+
+	// the times of a user are reported across repositories
+	req = NewRequest(t, "GET", "/user/times/export?from=2000-01-01&until=2000-01-31")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.Len(t, strings.Split(strings.TrimSpace(resp.Body.String()), "\n"), 5)
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserFilters(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	// the filters of a repository are saved from its issue list
+	link := "/user/filters/new?repo_id=1&type=all&sort=oldest&state=open&labels=1&milestone=1"
+	req := NewRequestWithValues(t, "POST", "/user/filters/new", map[string]string{
+		"_csrf":     GetCSRF(t, session, link),
+		"name":      "Bugs",
+		"repo_id":   "1",
+		"is_pull":   "false",
+		"sort":      "oldest",
+		"state":     "open",
+		"labels":    "1",
+		"milestone": "1",
+	})
+	resp := session.MakeRequest(t, req, http.StatusFound)
+	assert.EqualValues(t, "/user2/repo1/issues?labels=1&milestone=1&sort=oldest&state=open", test.RedirectURL(resp))
+	filter := models.AssertExistsAndLoadBean(t, &models.UserFilter{UserID: 2, RepoID: 1, Name: "Bugs"}).(*models.UserFilter)
+
+	req = NewRequest(t, "GET", "/user2/repo1/issues")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.Find(`#issue-filters a[href="/user2/repo1/issues?labels=1&milestone=1&sort=oldest&state=open"]`).Length())
+
+	// the filters of the private repositories of the other users can not be saved
+	session5 := loginUser(t, "user5")
+	req = NewRequest(t, "GET", "/user/filters/new?repo_id=2")
+	session5.MakeRequest(t, req, http.StatusNotFound)
+
+	var apiFilter api.UserFilter
+	req = NewRequestWithJSON(t, "POST", "/api/v1/user/filters?token="+token, &api.CreateUserFilterOption{
+		Name:     "To review",
+		Type:     "pulls",
+		ViewType: "review_requested",
+		Repos:    []int64{1},
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	DecodeJSON(t, resp, &apiFilter)
+	assert.Equal(t, "pulls", apiFilter.Type)
+	assert.Nil(t, apiFilter.Repository)
+	assert.Equal(t, []int64{1}, apiFilter.Repos)
+	assert.Equal(t, "http://localhost:3003/pulls?repos=%5B1%5D&type=review_requested", apiFilter.HTMLURL)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/user/filters?token="+token, &api.CreateUserFilterOption{Name: "To review", Type: "pulls"})
+	session.MakeRequest(t, req, http.StatusConflict)
+
+	var apiFilters []*api.UserFilter
+	req = NewRequest(t, "GET", "/api/v1/user/filters?type=issues&token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiFilters)
+	if assert.Len(t, apiFilters, 1) {
+		assert.Equal(t, "Bugs", apiFilters[0].Name)
+		assert.Equal(t, "user2/repo1", apiFilters[0].Repository.FullName)
+		assert.Equal(t, []int64{1}, apiFilters[0].Labels)
+	}
+
+	// the saved filters are listed in the sidebar of the dashboard
+	req = NewRequest(t, "GET", "/pulls")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.Find(`a[href="/pulls?repos=%5B1%5D&type=review_requested"]`).Length())
+
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/user/filters/%d?token=%s", filter.ID, token))
+	session.MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/user/filters/%d?token=%s", filter.ID, token))
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
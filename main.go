@@ -74,6 +74,10 @@ arguments - which can alternatively be run by running the subcommand web.`
 		cmd.CmdDocs,
 		cmd.CmdDumpRepository,
 		cmd.CmdRestoreRepository,
+		cmd.CmdDumpUser,
+		cmd.CmdRestoreUser,
+		cmd.CmdConfig,
+		cmd.CmdService,
 	}
 	// Now adjust these commands to add our global configuration options
 
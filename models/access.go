@@ -9,6 +9,8 @@ import (
 	"fmt"
 
 	"code.gitea.io/gitea/modules/log"
+
+	"xorm.io/builder"
 )
 
 // AccessMode specifies the users access mode
@@ -339,3 +341,14 @@ func (repo *Repository) recalculateAccesses(e Engine) error {
 func (repo *Repository) RecalculateAccesses() error {
 	return repo.recalculateAccesses(x)
 }
+
+// GetRepoAdmins returns the active users administrating a repository: its owner if it is not an organization,
+// and the users with admin access to it through a team or as a collaborator
+func GetRepoAdmins(repo *Repository) ([]*User, error) {
+	cond := builder.In("id", builder.Select("user_id").From("access").
+		Where(builder.Eq{"repo_id": repo.ID}.And(builder.Gte{"mode": AccessModeAdmin}))).
+		Or(builder.Eq{"id": repo.OwnerID, "type": UserTypeIndividual})
+
+	users := make([]*User, 0, 5)
+	return users, x.Where(cond).And("is_active = ?", true).Asc("id").Find(&users)
+}
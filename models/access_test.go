@@ -169,3 +169,23 @@ func TestRepository_RecalculateAccesses3(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, has)
 }
+
+func TestGetRepoAdmins(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// owned by a user
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	admins, err := GetRepoAdmins(repo)
+	assert.NoError(t, err)
+	if assert.Len(t, admins, 1) {
+		assert.EqualValues(t, 2, admins[0].ID)
+	}
+
+	// owned by an organization, user 4 only has write access
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+	admins, err = GetRepoAdmins(repo)
+	assert.NoError(t, err)
+	if assert.Len(t, admins, 1) {
+		assert.EqualValues(t, 2, admins[0].ID)
+	}
+}
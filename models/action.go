@@ -395,3 +395,20 @@ func activityQueryCondition(opts GetFeedsOptions) (builder.Cond, error) {
 
 	return cond, nil
 }
+
+// GetBranchPushActions returns the latest pushes to a branch of a repository since a time, the most recent first.
+// Each push has an action per user notified of it, only the one of the pusher is returned.
+func GetBranchPushActions(repoID int64, branch string, since timeutil.TimeStamp, limit int) ([]*Action, error) {
+	actions := make([]*Action, 0, limit)
+	return actions, x.Where(builder.Eq{
+		"repo_id":    repoID,
+		"op_type":    ActionCommitRepo,
+		"is_deleted": false,
+	}).
+		And("user_id = act_user_id").
+		And(builder.In("ref_name", branch, git.BranchPrefix+branch)).
+		And(builder.Gte{"created_unix": since}).
+		Desc("created_unix", "id").
+		Limit(limit).
+		Find(&actions)
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ActionArtifact represents a file uploaded by a job of an actions run
+type ActionArtifact struct {
+	ID     int64  `xorm:"pk autoincr"`
+	RunID  int64  `xorm:"INDEX UNIQUE(s)"`
+	JobID  int64  `xorm:"INDEX"`
+	RepoID int64  `xorm:"INDEX"`
+	Name   string `xorm:"UNIQUE(s)"`
+	Size   int64
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// RelativePath returns the path of the artifact in the artifact storage
+func (a *ActionArtifact) RelativePath() string {
+	return fmt.Sprintf("%d/%d/%d", a.RepoID, a.RunID, a.ID)
+}
+
+// ErrActionArtifactNotExist represents a "ActionArtifactNotExist" kind of error.
+type ErrActionArtifactNotExist struct {
+	ID int64
+}
+
+// IsErrActionArtifactNotExist checks if an error is a ErrActionArtifactNotExist.
+func IsErrActionArtifactNotExist(err error) bool {
+	_, ok := err.(ErrActionArtifactNotExist)
+	return ok
+}
+
+func (err ErrActionArtifactNotExist) Error() string {
+	return fmt.Sprintf("actions artifact does not exist [id: %d]", err.ID)
+}
+
+// GetOrCreateActionArtifact returns the artifact of a run with the given name, creating it if it does not exist
+func GetOrCreateActionArtifact(job *ActionRunJob, name string) (*ActionArtifact, error) {
+	artifact := &ActionArtifact{
+		RunID: job.RunID,
+		Name:  name,
+	}
+	has, err := x.Get(artifact)
+	if err != nil {
+		return nil, err
+	} else if has {
+		return artifact, nil
+	}
+
+	artifact.JobID = job.ID
+	artifact.RepoID = job.RepoID
+	if _, err := x.Insert(artifact); err != nil {
+		return nil, err
+	}
+	return artifact, nil
+}
+
+// UpdateActionArtifact updates the job and the size of an artifact
+func UpdateActionArtifact(artifact *ActionArtifact) error {
+	_, err := x.ID(artifact.ID).Cols("job_id", "size").Update(artifact)
+	return err
+}
+
+// GetActionArtifactsByRunID returns the artifacts of a run ordered by name
+func GetActionArtifactsByRunID(runID int64) ([]*ActionArtifact, error) {
+	artifacts := make([]*ActionArtifact, 0, 5)
+	return artifacts, x.Where("run_id = ?", runID).Asc("name").Find(&artifacts)
+}
+
+// GetActionArtifactByRunAndID returns the artifact with the given id of a run
+func GetActionArtifactByRunAndID(runID, id int64) (*ActionArtifact, error) {
+	artifact := new(ActionArtifact)
+	has, err := x.Where("id = ? AND run_id = ?", id, runID).Get(artifact)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrActionArtifactNotExist{ID: id}
+	}
+	return artifact, nil
+}
@@ -0,0 +1,395 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/generate"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// ActionStatus represents the state of an actions run or job
+type ActionStatus int
+
+// enumerate all action statuses
+const (
+	ActionStatusUnknown   ActionStatus = iota // 0 unknown
+	ActionStatusWaiting                       // 1 waiting for a runner
+	ActionStatusRunning                       // 2 picked up by a runner
+	ActionStatusSuccess                       // 3 finished successfully
+	ActionStatusFailure                       // 4 finished with an error
+	ActionStatusCancelled                     // 5 cancelled by a user
+	ActionStatusSkipped                       // 6 skipped because a needed job did not succeed
+	ActionStatusBlocked                       // 7 waiting for the jobs it needs
+)
+
+var actionStatusNames = map[ActionStatus]string{
+	ActionStatusUnknown:   "unknown",
+	ActionStatusWaiting:   "waiting",
+	ActionStatusRunning:   "running",
+	ActionStatusSuccess:   "success",
+	ActionStatusFailure:   "failure",
+	ActionStatusCancelled: "cancelled",
+	ActionStatusSkipped:   "skipped",
+	ActionStatusBlocked:   "blocked",
+}
+
+// String returns the name of the status
+func (s ActionStatus) String() string {
+	if name, ok := actionStatusNames[s]; ok {
+		return name
+	}
+	return actionStatusNames[ActionStatusUnknown]
+}
+
+// ActionStatusFromString returns the status of the given name
+func ActionStatusFromString(name string) ActionStatus {
+	for s, n := range actionStatusNames {
+		if n == name {
+			return s
+		}
+	}
+	return ActionStatusUnknown
+}
+
+// IsDone returns true if the status will not change anymore
+func (s ActionStatus) IsDone() bool {
+	switch s {
+	case ActionStatusSuccess, ActionStatusFailure, ActionStatusCancelled, ActionStatusSkipped:
+		return true
+	}
+	return false
+}
+
+// CommitStatusState returns the commit status state representing the status
+func (s ActionStatus) CommitStatusState() api.CommitStatusState {
+	switch s {
+	case ActionStatusSuccess, ActionStatusSkipped:
+		return api.CommitStatusSuccess
+	case ActionStatusFailure:
+		return api.CommitStatusFailure
+	case ActionStatusCancelled:
+		return api.CommitStatusError
+	}
+	return api.CommitStatusPending
+}
+
+// ActionRun represents a run of a workflow triggered by an event
+type ActionRun struct {
+	ID            int64       `xorm:"pk autoincr"`
+	Title         string      `xorm:"TEXT"`
+	RepoID        int64       `xorm:"INDEX"`
+	Repo          *Repository `xorm:"-"`
+	WorkflowID    string      // file name of the workflow in the workflows directory
+	WorkflowName  string
+	TriggerUserID int64
+	TriggerUser   *User        `xorm:"-"`
+	Ref           string       `xorm:"VARCHAR(255)"`
+	CommitSHA     string       `xorm:"VARCHAR(40) INDEX"`
+	Event         string       `xorm:"VARCHAR(50)"`
+	Status        ActionStatus `xorm:"INDEX"`
+	// NeedApproval is true while the jobs of a run triggered by a pull request from a fork
+	// wait for a user who can write to the repository to approve them
+	NeedApproval bool  `xorm:"NOT NULL DEFAULT false"`
+	ApprovedBy   int64 `xorm:"NOT NULL DEFAULT 0"`
+
+	StartedUnix timeutil.TimeStamp
+	StoppedUnix timeutil.TimeStamp
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// LoadAttributes loads the repository and the user who triggered the run
+func (run *ActionRun) LoadAttributes() (err error) {
+	if run.Repo == nil {
+		if run.Repo, err = GetRepositoryByID(run.RepoID); err != nil {
+			return err
+		}
+	}
+	if run.TriggerUser == nil {
+		if run.TriggerUser, err = GetUserByID(run.TriggerUserID); err != nil {
+			if !IsErrUserNotExist(err) {
+				return err
+			}
+			run.TriggerUser = NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// Link returns the link to the page of the run
+func (run *ActionRun) Link() string {
+	if run.Repo == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/actions/runs/%d", run.Repo.Link(), run.ID)
+}
+
+// ActionRunJob represents a job of an actions run
+type ActionRunJob struct {
+	ID        int64      `xorm:"pk autoincr"`
+	RunID     int64      `xorm:"INDEX"`
+	Run       *ActionRun `xorm:"-"`
+	RepoID    int64      `xorm:"INDEX"`
+	CommitSHA string     `xorm:"VARCHAR(40)"`
+	JobID     string     // key of the job in the workflow, used by needs
+	Name      string
+	Needs     []string `xorm:"JSON TEXT"`
+	RunsOn    []string `xorm:"JSON TEXT"`
+	// WorkflowPayload is the YAML definition of the job passed to the runner
+	WorkflowPayload string       `xorm:"LONGTEXT"`
+	Status          ActionStatus `xorm:"INDEX"`
+	RunnerID        int64        `xorm:"INDEX"`
+	LogSize         int64
+	// TokenHash is the hash of the token the job clones its repository with,
+	// it is cleared when the job stops
+	TokenHash string `xorm:"INDEX"`
+	// Token is only set on the job returned by PickActionRunJob
+	Token string `xorm:"-"`
+
+	StartedUnix timeutil.TimeStamp
+	StoppedUnix timeutil.TimeStamp
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// LogRelativePath returns the path of the log of the job relative to the actions log directory
+func (job *ActionRunJob) LogRelativePath() string {
+	return fmt.Sprintf("%d/%d/%d.log", job.RepoID, job.RunID, job.ID)
+}
+
+// LoadRun loads the run of the job
+func (job *ActionRunJob) LoadRun() (err error) {
+	if job.Run == nil {
+		job.Run, err = GetActionRunByID(job.RunID)
+	}
+	return err
+}
+
+// Duration returns how long the job has been running or ran
+func (job *ActionRunJob) Duration() string {
+	return calcActionDuration(job.StartedUnix, job.StoppedUnix)
+}
+
+// Duration returns how long the run has been running or ran
+func (run *ActionRun) Duration() string {
+	return calcActionDuration(run.StartedUnix, run.StoppedUnix)
+}
+
+func calcActionDuration(started, stopped timeutil.TimeStamp) string {
+	if started == 0 {
+		return ""
+	}
+	if stopped == 0 {
+		stopped = timeutil.TimeStampNow()
+	}
+	return (time.Duration(stopped-started) * time.Second).String()
+}
+
+// ErrActionRunNotExist represents a "ActionRunNotExist" kind of error.
+type ErrActionRunNotExist struct {
+	ID     int64
+	RepoID int64
+}
+
+// IsErrActionRunNotExist checks if an error is a ErrActionRunNotExist.
+func IsErrActionRunNotExist(err error) bool {
+	_, ok := err.(ErrActionRunNotExist)
+	return ok
+}
+
+func (err ErrActionRunNotExist) Error() string {
+	return fmt.Sprintf("actions run does not exist [id: %d, repo_id: %d]", err.ID, err.RepoID)
+}
+
+// ErrActionRunJobNotExist represents a "ActionRunJobNotExist" kind of error.
+type ErrActionRunJobNotExist struct {
+	ID int64
+}
+
+// IsErrActionRunJobNotExist checks if an error is a ErrActionRunJobNotExist.
+func IsErrActionRunJobNotExist(err error) bool {
+	_, ok := err.(ErrActionRunJobNotExist)
+	return ok
+}
+
+func (err ErrActionRunJobNotExist) Error() string {
+	return fmt.Sprintf("actions run job does not exist [id: %d]", err.ID)
+}
+
+// InsertActionRun creates a run and its jobs
+func InsertActionRun(run *ActionRun, jobs []*ActionRunJob) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if _, err := sess.Insert(run); err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		job.RunID = run.ID
+		job.RepoID = run.RepoID
+		job.CommitSHA = run.CommitSHA
+		if _, err := sess.Insert(job); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// GetActionRunByID returns the run with the given id
+func GetActionRunByID(id int64) (*ActionRun, error) {
+	run := new(ActionRun)
+	has, err := x.ID(id).Get(run)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrActionRunNotExist{ID: id}
+	}
+	return run, nil
+}
+
+// GetActionRunByRepoAndID returns the run with the given id in a repository
+func GetActionRunByRepoAndID(repoID, id int64) (*ActionRun, error) {
+	run := new(ActionRun)
+	has, err := x.Where("id = ? AND repo_id = ?", id, repoID).Get(run)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrActionRunNotExist{ID: id, RepoID: repoID}
+	}
+	return run, nil
+}
+
+// FindActionRunsOptions represents the options to find the runs of a repository
+type FindActionRunsOptions struct {
+	ListOptions
+	RepoID     int64
+	WorkflowID string
+	Status     ActionStatus
+}
+
+func (opts *FindActionRunsOptions) toConds() builder.Cond {
+	cond := builder.NewCond().And(builder.Eq{"repo_id": opts.RepoID})
+	if opts.WorkflowID != "" {
+		cond = cond.And(builder.Eq{"workflow_id": opts.WorkflowID})
+	}
+	if opts.Status != ActionStatusUnknown {
+		cond = cond.And(builder.Eq{"status": opts.Status})
+	}
+	return cond
+}
+
+// FindActionRuns returns the runs of a repository newest first and their total count
+func FindActionRuns(opts *FindActionRunsOptions) ([]*ActionRun, int64, error) {
+	count, err := x.Where(opts.toConds()).Count(new(ActionRun))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sess := x.Where(opts.toConds()).Desc("id")
+	if opts.Page > 0 {
+		sess = opts.setSessionPagination(sess)
+	}
+	runs := make([]*ActionRun, 0, opts.PageSize)
+	return runs, count, sess.Find(&runs)
+}
+
+// GetActionWorkflowIDs returns the workflows of a repository which have runs
+func GetActionWorkflowIDs(repoID int64) ([]string, error) {
+	ids := make([]string, 0, 5)
+	return ids, x.Table("action_run").Where("repo_id = ?", repoID).Distinct("workflow_id").Asc("workflow_id").Find(&ids)
+}
+
+// UpdateActionRun updates the given columns of a run
+func UpdateActionRun(run *ActionRun, cols ...string) error {
+	_, err := x.ID(run.ID).Cols(cols...).Update(run)
+	return err
+}
+
+// GetActionRunJobs returns the jobs of a run in the order they were defined
+func GetActionRunJobs(runID int64) ([]*ActionRunJob, error) {
+	jobs := make([]*ActionRunJob, 0, 5)
+	return jobs, x.Where("run_id = ?", runID).Asc("id").Find(&jobs)
+}
+
+// GetActionRunJobByID returns the job with the given id
+func GetActionRunJobByID(id int64) (*ActionRunJob, error) {
+	job := new(ActionRunJob)
+	has, err := x.ID(id).Get(job)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrActionRunJobNotExist{ID: id}
+	}
+	return job, nil
+}
+
+// GetActionRunJobByToken returns the running job authenticating with the given token.
+// The token expires after the configured lifetime even if the job is still running.
+func GetActionRunJobByToken(token string) (*ActionRunJob, error) {
+	if len(token) == 0 {
+		return nil, ErrActionRunJobNotExist{}
+	}
+	job := new(ActionRunJob)
+	has, err := x.Where("token_hash = ? AND status = ?", base.EncodeSha256(token), ActionStatusRunning).Get(job)
+	if err != nil {
+		return nil, err
+	} else if !has || job.StartedUnix.AddDuration(setting.Actions.JobTokenLifetime) < timeutil.TimeStampNow() {
+		return nil, ErrActionRunJobNotExist{}
+	}
+	return job, nil
+}
+
+// UpdateActionRunJob updates the given columns of a job if it is still in one of the given statuses.
+// It returns false if the job was changed in the meantime.
+func UpdateActionRunJob(job *ActionRunJob, fromStatuses []ActionStatus, cols ...string) (bool, error) {
+	sess := x.ID(job.ID).Cols(cols...)
+	if len(fromStatuses) > 0 {
+		sess = sess.In("status", fromStatuses)
+	}
+	affected, err := sess.Update(job)
+	return affected == 1, err
+}
+
+// PickActionRunJob assigns the oldest waiting job the runner can run to the runner
+// and issues the token of the job
+func PickActionRunJob(runner *ActionRunner) (*ActionRunJob, error) {
+	jobs := make([]*ActionRunJob, 0, 10)
+	if err := x.Where("status = ?", ActionStatusWaiting).Asc("id").Limit(50).Find(&jobs); err != nil {
+		return nil, err
+	}
+
+	for _, job := range jobs {
+		if !runner.CanRun(job.RunsOn) {
+			continue
+		}
+		token, err := generate.GetRandomString(40)
+		if err != nil {
+			return nil, err
+		}
+		job.RunnerID = runner.ID
+		job.Status = ActionStatusRunning
+		job.StartedUnix = timeutil.TimeStampNow()
+		job.TokenHash = base.EncodeSha256(token)
+		picked, err := UpdateActionRunJob(job, []ActionStatus{ActionStatusWaiting}, "runner_id", "status", "started_unix", "token_hash")
+		if err != nil {
+			return nil, err
+		} else if picked {
+			job.Token = token
+			return job, nil
+		}
+		// another runner picked the job in the meantime
+	}
+	return nil, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterActionRunner(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	token, err := GetActionRunnerToken()
+	assert.NoError(t, err)
+
+	_, _, err = RegisterActionRunner("invalid", "runner", nil)
+	assert.True(t, IsErrActionRunnerNotExist(err))
+
+	runner, runnerToken, err := RegisterActionRunner(token.Token, "runner", []string{"linux"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, runnerToken, runner.TokenHash)
+
+	got, err := GetActionRunnerByToken(runnerToken)
+	assert.NoError(t, err)
+	assert.Equal(t, runner.ID, got.ID)
+
+	// runners registered before the token was reset keep working
+	newToken, err := ResetActionRunnerToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token.Token, newToken.Token)
+	_, _, err = RegisterActionRunner(token.Token, "runner", nil)
+	assert.True(t, IsErrActionRunnerNotExist(err))
+	_, err = GetActionRunnerByToken(runnerToken)
+	assert.NoError(t, err)
+}
+
+func TestPickActionRunJob(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	run := &ActionRun{RepoID: 1, CommitSHA: "65f1bf27bc3bf70f64657658635e66094edbcb4d", Status: ActionStatusWaiting}
+	jobs := []*ActionRunJob{
+		{JobID: "docker", RunsOn: []string{"linux", "docker"}, Status: ActionStatusWaiting},
+		{JobID: "linux", RunsOn: []string{"linux"}, Status: ActionStatusWaiting},
+		{JobID: "blocked", RunsOn: []string{"linux"}, Needs: []string{"linux"}, Status: ActionStatusBlocked},
+	}
+	assert.NoError(t, InsertActionRun(run, jobs))
+
+	runner := &ActionRunner{ID: 1, Labels: []string{"linux"}}
+	job, err := PickActionRunJob(runner)
+	assert.NoError(t, err)
+	if assert.NotNil(t, job) {
+		assert.Equal(t, "linux", job.JobID)
+		assert.Equal(t, ActionStatusRunning, job.Status)
+		assert.EqualValues(t, 1, job.RunnerID)
+		assert.NotEmpty(t, job.Token)
+		assert.NotEqual(t, job.Token, job.TokenHash)
+	}
+
+	job, err = PickActionRunJob(runner)
+	assert.NoError(t, err)
+	assert.Nil(t, job)
+
+	job, err = PickActionRunJob(&ActionRunner{ID: 2, Labels: []string{"docker", "linux"}})
+	assert.NoError(t, err)
+	if assert.NotNil(t, job) {
+		assert.Equal(t, "docker", job.JobID)
+	}
+}
+
+func TestGetActionRunJobByToken(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	run := &ActionRun{RepoID: 1, CommitSHA: "65f1bf27bc3bf70f64657658635e66094edbcb4d", Status: ActionStatusWaiting}
+	assert.NoError(t, InsertActionRun(run, []*ActionRunJob{{JobID: "test", Status: ActionStatusWaiting}}))
+	job, err := PickActionRunJob(&ActionRunner{ID: 1})
+	assert.NoError(t, err)
+	if !assert.NotNil(t, job) {
+		return
+	}
+
+	got, err := GetActionRunJobByToken(job.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, job.ID, got.ID)
+	assert.EqualValues(t, 1, got.RepoID)
+
+	_, err = GetActionRunJobByToken("")
+	assert.True(t, IsErrActionRunJobNotExist(err))
+	_, err = GetActionRunJobByToken(job.TokenHash)
+	assert.True(t, IsErrActionRunJobNotExist(err))
+
+	// the token expires after its lifetime
+	defer func(lifetime time.Duration) {
+		setting.Actions.JobTokenLifetime = lifetime
+	}(setting.Actions.JobTokenLifetime)
+	setting.Actions.JobTokenLifetime = -time.Minute
+	_, err = GetActionRunJobByToken(job.Token)
+	assert.True(t, IsErrActionRunJobNotExist(err))
+	setting.Actions.JobTokenLifetime = time.Hour
+
+	// and is only valid while the job is running
+	job.Status = ActionStatusSuccess
+	_, err = UpdateActionRunJob(job, nil, "status")
+	assert.NoError(t, err)
+	_, err = GetActionRunJobByToken(job.Token)
+	assert.True(t, IsErrActionRunJobNotExist(err))
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/generate"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	gouuid "github.com/google/uuid"
+)
+
+// ActionRunner represents a machine that runs the jobs of actions workflows
+type ActionRunner struct {
+	ID        int64    `xorm:"pk autoincr"`
+	UUID      string   `xorm:"CHAR(36) UNIQUE"`
+	Name      string   `xorm:"VARCHAR(255)"`
+	TokenHash string   `xorm:"UNIQUE"`
+	Labels    []string `xorm:"JSON TEXT"`
+
+	LastOnlineUnix timeutil.TimeStamp `xorm:"INDEX"`
+	CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix    timeutil.TimeStamp `xorm:"updated"`
+}
+
+// IsOnline returns true if the runner polled for jobs within the given timeout
+func (r *ActionRunner) IsOnline(timeout time.Duration) bool {
+	return r.LastOnlineUnix.AsTime().Add(timeout).After(time.Now())
+}
+
+// CanRun returns true if the runner has all labels a job requires
+func (r *ActionRunner) CanRun(runsOn []string) bool {
+	for _, label := range runsOn {
+		found := false
+		for _, l := range r.Labels {
+			if l == label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrActionRunnerNotExist represents a "ActionRunnerNotExist" kind of error.
+type ErrActionRunnerNotExist struct {
+	ID int64
+}
+
+// IsErrActionRunnerNotExist checks if an error is a ErrActionRunnerNotExist.
+func IsErrActionRunnerNotExist(err error) bool {
+	_, ok := err.(ErrActionRunnerNotExist)
+	return ok
+}
+
+func (err ErrActionRunnerNotExist) Error() string {
+	return fmt.Sprintf("actions runner does not exist [id: %d]", err.ID)
+}
+
+// ActionRunnerToken represents the token runners register themselves with
+type ActionRunnerToken struct {
+	ID       int64  `xorm:"pk autoincr"`
+	Token    string `xorm:"UNIQUE"`
+	IsActive bool
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// GetActionRunnerToken returns the active registration token, creating one if there is none
+func GetActionRunnerToken() (*ActionRunnerToken, error) {
+	token := new(ActionRunnerToken)
+	has, err := x.Where("is_active = ?", true).Desc("id").Get(token)
+	if err != nil {
+		return nil, err
+	} else if has {
+		return token, nil
+	}
+	return ResetActionRunnerToken()
+}
+
+// ResetActionRunnerToken deactivates the registration tokens and creates a new one
+func ResetActionRunnerToken() (*ActionRunnerToken, error) {
+	value, err := generate.GetRandomString(40)
+	if err != nil {
+		return nil, err
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+	if _, err := sess.Where("is_active = ?", true).Cols("is_active").Update(&ActionRunnerToken{IsActive: false}); err != nil {
+		return nil, err
+	}
+	token := &ActionRunnerToken{
+		Token:    value,
+		IsActive: true,
+	}
+	if _, err := sess.Insert(token); err != nil {
+		return nil, err
+	}
+	return token, sess.Commit()
+}
+
+// RegisterActionRunner creates a runner if the registration token is valid.
+// It returns the runner and the token the runner authenticates with, which is only stored hashed.
+func RegisterActionRunner(registrationToken, name string, labels []string) (*ActionRunner, string, error) {
+	has, err := x.Where("token = ? AND is_active = ?", registrationToken, true).Exist(new(ActionRunnerToken))
+	if err != nil {
+		return nil, "", err
+	} else if !has {
+		return nil, "", ErrActionRunnerNotExist{}
+	}
+
+	token, err := generate.GetRandomString(40)
+	if err != nil {
+		return nil, "", err
+	}
+	runner := &ActionRunner{
+		UUID:      gouuid.New().String(),
+		Name:      name,
+		TokenHash: base.EncodeSha256(token),
+		Labels:    labels,
+	}
+	if _, err := x.Insert(runner); err != nil {
+		return nil, "", err
+	}
+	return runner, token, nil
+}
+
+// GetActionRunnerByToken returns the runner authenticating with the given token
+func GetActionRunnerByToken(token string) (*ActionRunner, error) {
+	runner := new(ActionRunner)
+	has, err := x.Where("token_hash = ?", base.EncodeSha256(token)).Get(runner)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrActionRunnerNotExist{}
+	}
+	return runner, nil
+}
+
+// GetActionRunnerByID returns the runner with the given id
+func GetActionRunnerByID(id int64) (*ActionRunner, error) {
+	runner := new(ActionRunner)
+	has, err := x.ID(id).Get(runner)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrActionRunnerNotExist{ID: id}
+	}
+	return runner, nil
+}
+
+// GetActionRunners returns all runners ordered by name
+func GetActionRunners() ([]*ActionRunner, error) {
+	runners := make([]*ActionRunner, 0, 10)
+	return runners, x.Asc("name").Find(&runners)
+}
+
+// UpdateActionRunnerLastOnline records that the runner polled for jobs
+func UpdateActionRunnerLastOnline(runner *ActionRunner) error {
+	runner.LastOnlineUnix = timeutil.TimeStampNow()
+	_, err := x.ID(runner.ID).Cols("last_online_unix").NoAutoTime().Update(runner)
+	return err
+}
+
+// DeleteActionRunner deletes a runner. Jobs it is running are not affected
+// but it can no longer report their results.
+func DeleteActionRunner(id int64) error {
+	_, err := x.ID(id).Delete(new(ActionRunner))
+	return err
+}
@@ -89,3 +89,29 @@ func TestGetFeeds2(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, actions, 0)
 }
+
+func TestGetBranchPushActions(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	for _, a := range []*Action{
+		{UserID: 2, ActUserID: 2, OpType: ActionCommitRepo, RepoID: 1, RefName: "refs/heads/master"},
+		{UserID: 3, ActUserID: 2, OpType: ActionCommitRepo, RepoID: 1, RefName: "refs/heads/master"},
+		{UserID: 2, ActUserID: 2, OpType: ActionCommitRepo, RepoID: 1, RefName: "master"},
+		{UserID: 2, ActUserID: 2, OpType: ActionCommitRepo, RepoID: 1, RefName: "refs/heads/develop"},
+		{UserID: 2, ActUserID: 2, OpType: ActionPushTag, RepoID: 1, RefName: "refs/tags/master"},
+	} {
+		_, err := x.Insert(a)
+		assert.NoError(t, err)
+	}
+
+	actions, err := GetBranchPushActions(1, "master", 0, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, actions, 2) {
+		assert.EqualValues(t, "master", actions[0].GetBranch())
+		assert.EqualValues(t, 2, actions[1].UserID)
+	}
+
+	actions, err = GetBranchPushActions(1, "master", 0, 1)
+	assert.NoError(t, err)
+	assert.Len(t, actions, 1)
+}
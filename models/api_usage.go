@@ -0,0 +1,151 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// APIUsageDays is the number of days of API usage shown to the users and to the administrators
+const APIUsageDays = 30
+
+// APIUsage counts the API requests of a user authenticated by an access token or by an OAuth2 application during a
+// day
+type APIUsage struct {
+	ID     int64 `xorm:"pk autoincr"`
+	UserID int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	// TokenID is the access token used, 0 for the requests of an OAuth2 application
+	TokenID int64 `xorm:"UNIQUE(s) INDEX NOT NULL DEFAULT 0"`
+	// AppID is the OAuth2 application used, 0 for the requests with an access token
+	AppID int64 `xorm:"UNIQUE(s) INDEX NOT NULL DEFAULT 0"`
+	// Day is the start of the UTC day of the requests
+	Day          timeutil.TimeStamp `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	NumRequests  int64              `xorm:"NOT NULL DEFAULT 0"`
+	LastUsedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+}
+
+func apiUsageDay(t time.Time) timeutil.TimeStamp {
+	unix := t.Unix()
+	return timeutil.TimeStamp(unix - unix%(24*3600))
+}
+
+// IncreaseAPIUsage counts an API request of a user authenticated by an access token or by an OAuth2 application
+func IncreaseAPIUsage(userID, tokenID, appID int64) error {
+	now := time.Now()
+	day := apiUsageDay(now)
+	increase := func() (int64, error) {
+		res, err := x.Exec("UPDATE `api_usage` SET num_requests = num_requests + 1, last_used_unix = ? "+
+			"WHERE user_id = ? AND token_id = ? AND app_id = ? AND `day` = ?", now.Unix(), userID, tokenID, appID, day)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	}
+
+	if affected, err := increase(); err != nil || affected > 0 {
+		return err
+	}
+	if _, err := x.Insert(&APIUsage{
+		UserID:       userID,
+		TokenID:      tokenID,
+		AppID:        appID,
+		Day:          day,
+		NumRequests:  1,
+		LastUsedUnix: timeutil.TimeStamp(now.Unix()),
+	}); err != nil {
+		// another request of the day may have been counted meanwhile
+		if affected, err2 := increase(); err2 != nil || affected == 0 {
+			return err
+		}
+	}
+	return nil
+}
+
+// APIUsageSummary is the number of the API requests of a user with an access token or an OAuth2 application over the
+// last APIUsageDays days
+type APIUsageSummary struct {
+	UserID       int64
+	TokenID      int64
+	AppID        int64
+	NumRequests  int64
+	LastUsedUnix timeutil.TimeStamp
+
+	User  *User              `xorm:"-"`
+	Token *AccessToken       `xorm:"-"`
+	App   *OAuth2Application `xorm:"-"`
+}
+
+func findAPIUsageSummaries(cond builder.Cond, limit int) ([]*APIUsageSummary, error) {
+	since := apiUsageDay(time.Now().AddDate(0, 0, -APIUsageDays+1))
+	sess := x.Table("api_usage").
+		Select("user_id, token_id, app_id, SUM(num_requests) AS num_requests, MAX(last_used_unix) AS last_used_unix").
+		Where(cond.And(builder.Gte{"`day`": since})).
+		GroupBy("user_id, token_id, app_id").
+		OrderBy("SUM(num_requests) DESC")
+	if limit > 0 {
+		sess = sess.Limit(limit)
+	}
+	summaries := make([]*APIUsageSummary, 0, 10)
+	return summaries, sess.Find(&summaries)
+}
+
+// GetUserAPIUsage returns the API usage of a user over the last APIUsageDays days for each access token and OAuth2
+// application, the most used first
+func GetUserAPIUsage(userID int64) ([]*APIUsageSummary, error) {
+	return findAPIUsageSummaries(builder.Eq{"user_id": userID}, 0)
+}
+
+// GetTopAPIConsumers returns the access tokens and OAuth2 applications with the most API requests over the last
+// APIUsageDays days, with their users
+func GetTopAPIConsumers(limit int) ([]*APIUsageSummary, error) {
+	summaries, err := findAPIUsageSummaries(builder.NewCond(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]int64, 0, len(summaries))
+	tokenIDs := make([]int64, 0, len(summaries))
+	appIDs := make([]int64, 0, len(summaries))
+	for _, s := range summaries {
+		userIDs = append(userIDs, s.UserID)
+		if s.TokenID > 0 {
+			tokenIDs = append(tokenIDs, s.TokenID)
+		}
+		if s.AppID > 0 {
+			appIDs = append(appIDs, s.AppID)
+		}
+	}
+	users := make(map[int64]*User, len(userIDs))
+	if err := x.In("id", userIDs).Find(&users); err != nil {
+		return nil, err
+	}
+	tokens := make(map[int64]*AccessToken, len(tokenIDs))
+	if err := x.In("id", tokenIDs).Find(&tokens); err != nil {
+		return nil, err
+	}
+	apps := make(map[int64]*OAuth2Application, len(appIDs))
+	if err := x.In("id", appIDs).Find(&apps); err != nil {
+		return nil, err
+	}
+	for _, s := range summaries {
+		if s.User = users[s.UserID]; s.User == nil {
+			s.User = NewGhostUser()
+		}
+		s.Token = tokens[s.TokenID]
+		s.App = apps[s.AppID]
+	}
+	return summaries, nil
+}
+
+// DeleteOldAPIUsage deletes the API usage older than a duration
+func DeleteOldAPIUsage(ctx context.Context, olderThan time.Duration) error {
+	_, err := x.Where("`day` < ?", apiUsageDay(time.Now().Add(-olderThan))).Delete(new(APIUsage))
+	return err
+}
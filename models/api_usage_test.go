@@ -0,0 +1,54 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIUsage(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, IncreaseAPIUsage(1, 1, 0))
+	assert.NoError(t, IncreaseAPIUsage(1, 1, 0))
+	assert.NoError(t, IncreaseAPIUsage(2, 2, 0))
+	assert.NoError(t, IncreaseAPIUsage(2, 0, 1))
+	// an old usage is not counted
+	_, err := x.Insert(&APIUsage{UserID: 2, TokenID: 2, Day: apiUsageDay(time.Now().AddDate(0, 0, -APIUsageDays-1)), NumRequests: 5})
+	assert.NoError(t, err)
+	AssertExistsAndLoadBean(t, &APIUsage{UserID: 1, TokenID: 1, NumRequests: 2})
+
+	usages, err := GetUserAPIUsage(2)
+	assert.NoError(t, err)
+	if assert.Len(t, usages, 2) {
+		for _, usage := range usages {
+			assert.EqualValues(t, 1, usage.NumRequests)
+			assert.NotZero(t, usage.LastUsedUnix)
+		}
+	}
+
+	consumers, err := GetTopAPIConsumers(2)
+	assert.NoError(t, err)
+	if assert.Len(t, consumers, 2) {
+		assert.EqualValues(t, 2, consumers[0].NumRequests)
+		assert.EqualValues(t, 1, consumers[0].User.ID)
+		if assert.NotNil(t, consumers[0].Token) {
+			assert.EqualValues(t, 1, consumers[0].Token.ID)
+		}
+		assert.Nil(t, consumers[0].App)
+	}
+
+	assert.NoError(t, DeleteOldAPIUsage(context.Background(), APIUsageDays*24*time.Hour))
+	AssertNotExistsBean(t, &APIUsage{NumRequests: 5})
+	assert.EqualValues(t, 3, GetCount(t, &APIUsage{}))
+
+	// the usage of deleted tokens is deleted
+	assert.NoError(t, DeleteAccessTokenByID(1, 1))
+	AssertNotExistsBean(t, &APIUsage{TokenID: 1})
+}
@@ -20,29 +20,32 @@ import (
 
 // ProtectedBranch struct
 type ProtectedBranch struct {
-	ID                            int64  `xorm:"pk autoincr"`
-	RepoID                        int64  `xorm:"UNIQUE(s)"`
-	BranchName                    string `xorm:"UNIQUE(s)"`
-	CanPush                       bool   `xorm:"NOT NULL DEFAULT false"`
-	EnableWhitelist               bool
-	WhitelistUserIDs              []int64  `xorm:"JSON TEXT"`
-	WhitelistTeamIDs              []int64  `xorm:"JSON TEXT"`
-	EnableMergeWhitelist          bool     `xorm:"NOT NULL DEFAULT false"`
-	WhitelistDeployKeys           bool     `xorm:"NOT NULL DEFAULT false"`
-	MergeWhitelistUserIDs         []int64  `xorm:"JSON TEXT"`
-	MergeWhitelistTeamIDs         []int64  `xorm:"JSON TEXT"`
-	EnableStatusCheck             bool     `xorm:"NOT NULL DEFAULT false"`
-	StatusCheckContexts           []string `xorm:"JSON TEXT"`
-	EnableApprovalsWhitelist      bool     `xorm:"NOT NULL DEFAULT false"`
-	ApprovalsWhitelistUserIDs     []int64  `xorm:"JSON TEXT"`
-	ApprovalsWhitelistTeamIDs     []int64  `xorm:"JSON TEXT"`
-	RequiredApprovals             int64    `xorm:"NOT NULL DEFAULT 0"`
-	BlockOnRejectedReviews        bool     `xorm:"NOT NULL DEFAULT false"`
-	BlockOnOfficialReviewRequests bool     `xorm:"NOT NULL DEFAULT false"`
-	BlockOnOutdatedBranch         bool     `xorm:"NOT NULL DEFAULT false"`
-	DismissStaleApprovals         bool     `xorm:"NOT NULL DEFAULT false"`
-	RequireSignedCommits          bool     `xorm:"NOT NULL DEFAULT false"`
-	ProtectedFilePatterns         string   `xorm:"TEXT"`
+	ID                             int64  `xorm:"pk autoincr"`
+	RepoID                         int64  `xorm:"UNIQUE(s)"`
+	BranchName                     string `xorm:"UNIQUE(s)"`
+	CanPush                        bool   `xorm:"NOT NULL DEFAULT false"`
+	EnableWhitelist                bool
+	WhitelistUserIDs               []int64  `xorm:"JSON TEXT"`
+	WhitelistTeamIDs               []int64  `xorm:"JSON TEXT"`
+	EnableMergeWhitelist           bool     `xorm:"NOT NULL DEFAULT false"`
+	WhitelistDeployKeys            bool     `xorm:"NOT NULL DEFAULT false"`
+	MergeWhitelistUserIDs          []int64  `xorm:"JSON TEXT"`
+	MergeWhitelistTeamIDs          []int64  `xorm:"JSON TEXT"`
+	EnableStatusCheck              bool     `xorm:"NOT NULL DEFAULT false"`
+	StatusCheckContexts            []string `xorm:"JSON TEXT"`
+	EnableApprovalsWhitelist       bool     `xorm:"NOT NULL DEFAULT false"`
+	ApprovalsWhitelistUserIDs      []int64  `xorm:"JSON TEXT"`
+	ApprovalsWhitelistTeamIDs      []int64  `xorm:"JSON TEXT"`
+	RequiredApprovals              int64    `xorm:"NOT NULL DEFAULT 0"`
+	BlockOnRejectedReviews         bool     `xorm:"NOT NULL DEFAULT false"`
+	BlockOnOfficialReviewRequests  bool     `xorm:"NOT NULL DEFAULT false"`
+	BlockOnOutdatedBranch          bool     `xorm:"NOT NULL DEFAULT false"`
+	BlockOnUnresolvedConversations bool     `xorm:"NOT NULL DEFAULT false"`
+	RequireCodeOwnerReviews        bool     `xorm:"NOT NULL DEFAULT false"`
+	DismissStaleApprovals          bool     `xorm:"NOT NULL DEFAULT false"`
+	RequireSignedCommits           bool     `xorm:"NOT NULL DEFAULT false"`
+	EnableMergeQueue               bool     `xorm:"NOT NULL DEFAULT false"`
+	ProtectedFilePatterns          string   `xorm:"TEXT"`
 
 	CreatedUnix timeutil.TimeStamp `xorm:"created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
@@ -208,8 +211,62 @@ func (protectBranch *ProtectedBranch) MergeBlockedByOfficialReviewRequests(pr *P
 }
 
 // MergeBlockedByOutdatedBranch returns true if merge is blocked by an outdated head branch
+// The merge queue always tests pull requests on top of the latest base branch,
+// so it does not block outdated branches.
 func (protectBranch *ProtectedBranch) MergeBlockedByOutdatedBranch(pr *PullRequest) bool {
-	return protectBranch.BlockOnOutdatedBranch && pr.CommitsBehind > 0
+	return protectBranch.BlockOnOutdatedBranch && !protectBranch.EnableMergeQueue && pr.CommitsBehind > 0
+}
+
+// MergeBlockedByUnresolvedConversations returns true if merge is blocked by review conversations which are not resolved
+func (protectBranch *ProtectedBranch) MergeBlockedByUnresolvedConversations(pr *PullRequest) bool {
+	if !protectBranch.BlockOnUnresolvedConversations {
+		return false
+	}
+	unresolved, err := CountUnresolvedConversations(pr.IssueID)
+	if err != nil {
+		log.Error("MergeBlockedByUnresolvedConversations: %v", err)
+		return true
+	}
+
+	return unresolved > 0
+}
+
+// IsStatusCheckPattern returns true if a required status check context contains glob wildcards
+func IsStatusCheckPattern(context string) bool {
+	return strings.ContainsAny(context, "*?[{")
+}
+
+// CompileStatusCheckPattern compiles a required status check context into a glob.
+// A context without wildcards or with an invalid pattern only matches itself, and a
+// trailing "*" such as "ci/*" matches all contexts with that prefix.
+func CompileStatusCheckPattern(context string) glob.Glob {
+	if IsStatusCheckPattern(context) {
+		g, err := glob.Compile(context)
+		if err == nil {
+			return g
+		}
+		log.Info("Invalid status check pattern '%s' (matched literally): %v", context, err)
+	}
+	return glob.MustCompile(glob.QuoteMeta(context))
+}
+
+// GetStatusCheckPatterns returns the required status check contexts as a glob.Glob slice
+func (protectBranch *ProtectedBranch) GetStatusCheckPatterns() []glob.Glob {
+	patterns := make([]glob.Glob, 0, len(protectBranch.StatusCheckContexts))
+	for _, context := range protectBranch.StatusCheckContexts {
+		patterns = append(patterns, CompileStatusCheckPattern(context))
+	}
+	return patterns
+}
+
+// IsStatusCheckContextRequired returns true if a commit status with the given context is required by this rule
+func (protectBranch *ProtectedBranch) IsStatusCheckContextRequired(context string) bool {
+	for _, pattern := range protectBranch.GetStatusCheckPatterns() {
+		if pattern.Match(context) {
+			return true
+		}
+	}
+	return false
 }
 
 // GetProtectedFilePatterns parses a semicolon separated list of protected file patterns and returns a glob.Glob slice
@@ -78,3 +78,16 @@ func getDeletedBranch(t *testing.T, branch *DeletedBranch) *DeletedBranch {
 
 	return deletedBranch
 }
+
+func TestProtectedBranch_IsStatusCheckContextRequired(t *testing.T) {
+	protectBranch := &ProtectedBranch{
+		StatusCheckContexts: []string{"lint", "ci/build-*", "deploy/*", "[invalid"},
+	}
+	assert.True(t, protectBranch.IsStatusCheckContextRequired("lint"))
+	assert.False(t, protectBranch.IsStatusCheckContextRequired("lint/extra"))
+	assert.True(t, protectBranch.IsStatusCheckContextRequired("ci/build-linux"))
+	assert.False(t, protectBranch.IsStatusCheckContextRequired("ci/test"))
+	assert.True(t, protectBranch.IsStatusCheckContextRequired("deploy/staging/eu"))
+	assert.True(t, protectBranch.IsStatusCheckContextRequired("[invalid"))
+	assert.False(t, protectBranch.IsStatusCheckContextRequired("i"))
+}
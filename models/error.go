@@ -176,6 +176,22 @@ func (err ErrUserProhibitLogin) Error() string {
 	return fmt.Sprintf("user is not allowed login [uid: %d, name: %s]", err.UID, err.Name)
 }
 
+// ErrExternalRegistrationDisabled represents a "ErrExternalRegistrationDisabled" kind of error.
+type ErrExternalRegistrationDisabled struct {
+	LoginSource string
+	LoginName   string
+}
+
+// IsErrExternalRegistrationDisabled checks if an error is a ErrExternalRegistrationDisabled
+func IsErrExternalRegistrationDisabled(err error) bool {
+	_, ok := err.(ErrExternalRegistrationDisabled)
+	return ok
+}
+
+func (err ErrExternalRegistrationDisabled) Error() string {
+	return fmt.Sprintf("registration is disabled [login_source: %s, login_name: %s]", err.LoginSource, err.LoginName)
+}
+
 // ErrUserInactive represents a "ErrUserInactive" kind of error.
 type ErrUserInactive struct {
 	UID  int64
@@ -529,6 +545,22 @@ func (err ErrKeyAccessDenied) Error() string {
 		err.UserID, err.KeyID, err.Note)
 }
 
+// ErrRepoSigningKeyNotExist represents a "RepoSigningKeyNotExist" kind of error.
+type ErrRepoSigningKeyNotExist struct {
+	RepoID      int64
+	Fingerprint string
+}
+
+// IsErrRepoSigningKeyNotExist checks if an error is a ErrRepoSigningKeyNotExist.
+func IsErrRepoSigningKeyNotExist(err error) bool {
+	_, ok := err.(ErrRepoSigningKeyNotExist)
+	return ok
+}
+
+func (err ErrRepoSigningKeyNotExist) Error() string {
+	return fmt.Sprintf("repository signing key does not exist [repo_id: %d, fingerprint: %s]", err.RepoID, err.Fingerprint)
+}
+
 // ErrDeployKeyNotExist represents a "DeployKeyNotExist" kind of error.
 type ErrDeployKeyNotExist struct {
 	ID     int64
@@ -1056,6 +1088,21 @@ func (err ErrFilePathProtected) Error() string {
 	return fmt.Sprintf("path is protected and can not be changed [path: %s]", err.Path)
 }
 
+// ErrPatchDoesNotApply represents a "PatchDoesNotApply" kind of error.
+type ErrPatchDoesNotApply struct {
+	Reason string
+}
+
+// IsErrPatchDoesNotApply checks if an error is an ErrPatchDoesNotApply.
+func IsErrPatchDoesNotApply(err error) bool {
+	_, ok := err.(ErrPatchDoesNotApply)
+	return ok
+}
+
+func (err ErrPatchDoesNotApply) Error() string {
+	return fmt.Sprintf("patch does not apply [reason: %s]", err.Reason)
+}
+
 // ErrUserDoesNotHaveAccessToRepo represets an error where the user doesn't has access to a given repo.
 type ErrUserDoesNotHaveAccessToRepo struct {
 	UserID   int64
@@ -1357,6 +1404,37 @@ func (err ErrIssueWasClosed) Error() string {
 	return fmt.Sprintf("Issue [%d] %d was already closed", err.ID, err.Index)
 }
 
+// ErrIssueCollaboratorAlreadyExist represents a "IssueCollaboratorAlreadyExist" kind of error.
+type ErrIssueCollaboratorAlreadyExist struct {
+	IssueID int64
+	UserID  int64
+}
+
+// IsErrIssueCollaboratorAlreadyExist checks if an error is a ErrIssueCollaboratorAlreadyExist.
+func IsErrIssueCollaboratorAlreadyExist(err error) bool {
+	_, ok := err.(ErrIssueCollaboratorAlreadyExist)
+	return ok
+}
+
+func (err ErrIssueCollaboratorAlreadyExist) Error() string {
+	return fmt.Sprintf("user is already a collaborator of the issue [issue_id: %d, user_id: %d]", err.IssueID, err.UserID)
+}
+
+// ErrIssueCollaboratorNotIndividual represents a "IssueCollaboratorNotIndividual" kind of error.
+type ErrIssueCollaboratorNotIndividual struct {
+	UserID int64
+}
+
+// IsErrIssueCollaboratorNotIndividual checks if an error is a ErrIssueCollaboratorNotIndividual.
+func IsErrIssueCollaboratorNotIndividual(err error) bool {
+	_, ok := err.(ErrIssueCollaboratorNotIndividual)
+	return ok
+}
+
+func (err ErrIssueCollaboratorNotIndividual) Error() string {
+	return fmt.Sprintf("only individual users can be issue collaborators [user_id: %d]", err.UserID)
+}
+
 // ErrPullWasClosed is used close a closed pull request
 type ErrPullWasClosed struct {
 	ID    int64
@@ -1524,6 +1602,23 @@ func (err ErrMergeUnrelatedHistories) Error() string {
 	return fmt.Sprintf("Merge UnrelatedHistories Error: %v: %s\n%s", err.Err, err.StdErr, err.StdOut)
 }
 
+// ErrMergeDivergingFastForwardOnly represents an error if a fast-forward-only merge fails because the branches diverge
+type ErrMergeDivergingFastForwardOnly struct {
+	StdOut string
+	StdErr string
+	Err    error
+}
+
+// IsErrMergeDivergingFastForwardOnly checks if an error is a ErrMergeDivergingFastForwardOnly.
+func IsErrMergeDivergingFastForwardOnly(err error) bool {
+	_, ok := err.(ErrMergeDivergingFastForwardOnly)
+	return ok
+}
+
+func (err ErrMergeDivergingFastForwardOnly) Error() string {
+	return fmt.Sprintf("Merge DivergingFastForwardOnly Error: %v: %s\n%s", err.Err, err.StdErr, err.StdOut)
+}
+
 // ErrRebaseConflicts represents an error if rebase fails with a conflict
 type ErrRebaseConflicts struct {
 	Style     MergeStyle
@@ -1565,6 +1660,51 @@ func (err ErrPullRequestHasMerged) Error() string {
 		err.ID, err.IssueID, err.HeadRepoID, err.BaseRepoID, err.HeadBranch, err.BaseBranch)
 }
 
+// ErrPullAlreadyInMergeQueue represents a "PullAlreadyInMergeQueue"-error
+type ErrPullAlreadyInMergeQueue struct {
+	PullID int64
+}
+
+// IsErrPullAlreadyInMergeQueue checks if an error is a ErrPullAlreadyInMergeQueue.
+func IsErrPullAlreadyInMergeQueue(err error) bool {
+	_, ok := err.(ErrPullAlreadyInMergeQueue)
+	return ok
+}
+
+func (err ErrPullAlreadyInMergeQueue) Error() string {
+	return fmt.Sprintf("pull request is already in the merge queue [pull_id: %d]", err.PullID)
+}
+
+// ErrAlreadyScheduledToAutoMerge represents a "PullAlreadyScheduledToAutoMerge"-error
+type ErrAlreadyScheduledToAutoMerge struct {
+	PullID int64
+}
+
+// IsErrAlreadyScheduledToAutoMerge checks if an error is a ErrAlreadyScheduledToAutoMerge.
+func IsErrAlreadyScheduledToAutoMerge(err error) bool {
+	_, ok := err.(ErrAlreadyScheduledToAutoMerge)
+	return ok
+}
+
+func (err ErrAlreadyScheduledToAutoMerge) Error() string {
+	return fmt.Sprintf("pull request is already scheduled to auto merge when checks succeed [pull_id: %d]", err.PullID)
+}
+
+// ErrMergeQueueEntryNotExist represents a "MergeQueueEntryNotExist"-error
+type ErrMergeQueueEntryNotExist struct {
+	PullID int64
+}
+
+// IsErrMergeQueueEntryNotExist checks if an error is a ErrMergeQueueEntryNotExist.
+func IsErrMergeQueueEntryNotExist(err error) bool {
+	_, ok := err.(ErrMergeQueueEntryNotExist)
+	return ok
+}
+
+func (err ErrMergeQueueEntryNotExist) Error() string {
+	return fmt.Sprintf("merge queue entry does not exist [pull_id: %d]", err.PullID)
+}
+
 // _________                                       __
 // \_   ___ \  ____   _____   _____   ____   _____/  |_
 // /    \  \/ /  _ \ /     \ /     \_/ __ \ /    \   __\
@@ -1724,6 +1864,22 @@ func (err ErrProjectBoardNotExist) Error() string {
 	return fmt.Sprintf("project board does not exist [id: %d]", err.BoardID)
 }
 
+// ErrProjectBoardRepoNotAllowed represents a "ProjectBoardRepoNotAllowed" kind of error.
+type ErrProjectBoardRepoNotAllowed struct {
+	BoardID int64
+	RepoID  int64
+}
+
+// IsErrProjectBoardRepoNotAllowed checks if an error is a ErrProjectBoardRepoNotAllowed
+func IsErrProjectBoardRepoNotAllowed(err error) bool {
+	_, ok := err.(ErrProjectBoardRepoNotAllowed)
+	return ok
+}
+
+func (err ErrProjectBoardRepoNotAllowed) Error() string {
+	return fmt.Sprintf("project board does not allow the issues of the repository [id: %d, repo_id: %d]", err.BoardID, err.RepoID)
+}
+
 //    _____  .__.__                   __
 //   /     \ |__|  |   ____   _______/  |_  ____   ____   ____
 //  /  \ /  \|  |  | _/ __ \ /  ___/\   __\/  _ \ /    \_/ __ \
@@ -1971,6 +2127,52 @@ func IsErrU2FRegistrationNotExist(err error) bool {
 	return ok
 }
 
+// ErrWebAuthnCredentialNotExist represents a "ErrWebAuthnCredentialNotExist" kind of error.
+type ErrWebAuthnCredentialNotExist struct {
+	ID           int64
+	CredentialID string
+}
+
+func (err ErrWebAuthnCredentialNotExist) Error() string {
+	return fmt.Sprintf("WebAuthn credential does not exist [id: %d, credential_id: %s]", err.ID, err.CredentialID)
+}
+
+// IsErrWebAuthnCredentialNotExist checks if an error is a ErrWebAuthnCredentialNotExist.
+func IsErrWebAuthnCredentialNotExist(err error) bool {
+	_, ok := err.(ErrWebAuthnCredentialNotExist)
+	return ok
+}
+
+// ErrSCIMGroupNotExist represents a "SCIMGroupNotExist" kind of error.
+type ErrSCIMGroupNotExist struct {
+	ID int64
+}
+
+// IsErrSCIMGroupNotExist checks if an error is a ErrSCIMGroupNotExist.
+func IsErrSCIMGroupNotExist(err error) bool {
+	_, ok := err.(ErrSCIMGroupNotExist)
+	return ok
+}
+
+func (err ErrSCIMGroupNotExist) Error() string {
+	return fmt.Sprintf("SCIM group does not exist [id: %d]", err.ID)
+}
+
+// ErrSCIMGroupAlreadyExist represents a "SCIMGroupAlreadyExist" kind of error.
+type ErrSCIMGroupAlreadyExist struct {
+	DisplayName string
+}
+
+// IsErrSCIMGroupAlreadyExist checks if an error is a ErrSCIMGroupAlreadyExist.
+func IsErrSCIMGroupAlreadyExist(err error) bool {
+	_, ok := err.(ErrSCIMGroupAlreadyExist)
+	return ok
+}
+
+func (err ErrSCIMGroupAlreadyExist) Error() string {
+	return fmt.Sprintf("SCIM group already exists [display_name: %s]", err.DisplayName)
+}
+
 // .___                            ________                                   .___                   .__
 // |   | ______ ________ __   ____ \______ \   ____ ______   ____   ____    __| _/____   ____   ____ |__| ____   ______
 // |   |/  ___//  ___/  |  \_/ __ \ |    |  \_/ __ \\____ \_/ __ \ /    \  / __ |/ __ \ /    \_/ ___\|  |/ __ \ /  ___/
@@ -2098,6 +2300,38 @@ func (err ErrNotValidReviewRequest) Error() string {
 		err.RepoID)
 }
 
+// ErrInvalidCommentLineRange represents an error where the lines of a multi-line code comment are invalid
+type ErrInvalidCommentLineRange struct {
+	StartLine int64
+	Line      int64
+}
+
+// IsErrInvalidCommentLineRange checks if an error is a ErrInvalidCommentLineRange.
+func IsErrInvalidCommentLineRange(err error) bool {
+	_, ok := err.(ErrInvalidCommentLineRange)
+	return ok
+}
+
+func (err ErrInvalidCommentLineRange) Error() string {
+	return fmt.Sprintf("invalid comment line range [start_line: %d, line: %d]", err.StartLine, err.Line)
+}
+
+// ErrSuggestionNotApplicable represents an error where the change suggested by a code comment cannot be applied
+type ErrSuggestionNotApplicable struct {
+	Reason    string
+	CommentID int64
+}
+
+// IsErrSuggestionNotApplicable checks if an error is a ErrSuggestionNotApplicable.
+func IsErrSuggestionNotApplicable(err error) bool {
+	_, ok := err.(ErrSuggestionNotApplicable)
+	return ok
+}
+
+func (err ErrSuggestionNotApplicable) Error() string {
+	return fmt.Sprintf("%s [comment_id: %d]", err.Reason, err.CommentID)
+}
+
 //  ________      _____          __  .__
 //  \_____  \    /  _  \  __ ___/  |_|  |__
 //   /   |   \  /  /_\  \|  |  \   __\  |  \
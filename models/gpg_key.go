@@ -387,6 +387,7 @@ type CommitVerification struct {
 	CommittingUser *User
 	SigningEmail   string
 	SigningKey     *GPGKey
+	SigningSSHKey  *PublicKey
 	TrustStatus    string
 }
 
@@ -650,6 +651,10 @@ func ParseCommitWithSignature(c *git.Commit) *CommitVerification {
 		}
 	}
 
+	if isSSHSignature(c.Signature.Signature) {
+		return parseCommitWithSSHSignature(c, committer)
+	}
+
 	// Parsing signature
 	sig, err := extractSignature(c.Signature.Signature)
 	if err != nil { // Skipping failed to extract sign
@@ -896,11 +901,17 @@ func CalculateTrustStatus(verification *CommitVerification, repository *Reposito
 
 	var isMember bool
 	if keyMap != nil {
+		keyID := ""
+		if verification.SigningKey != nil {
+			keyID = verification.SigningKey.KeyID
+		} else if verification.SigningSSHKey != nil {
+			keyID = verification.SigningSSHKey.Fingerprint
+		}
 		var has bool
-		isMember, has = (*keyMap)[verification.SigningKey.KeyID]
+		isMember, has = (*keyMap)[keyID]
 		if !has {
 			isMember, err = repository.IsOwnerMemberCollaborator(verification.SigningUser.ID)
-			(*keyMap)[verification.SigningKey.KeyID] = isMember
+			(*keyMap)[keyID] = isMember
 		}
 	} else {
 		isMember, err = repository.IsOwnerMemberCollaborator(verification.SigningUser.ID)
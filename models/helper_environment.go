@@ -26,6 +26,7 @@ const (
 	EnvPRID         = "GITEA_PR_ID"
 	EnvIsInternal   = "GITEA_INTERNAL_PUSH"
 	EnvAppURL       = "GITEA_ROOT_URL"
+	EnvRequestID    = "GITEA_REQUEST_ID"
 )
 
 // InternalPushingEnvironment returns an os environment to switch off hooks on push
@@ -42,6 +42,10 @@ type Issue struct {
 	MilestoneID      int64      `xorm:"INDEX"`
 	Milestone        *Milestone `xorm:"-"`
 	Project          *Project   `xorm:"-"`
+	TypeID           int64      `xorm:"INDEX NOT NULL DEFAULT 0"`
+	Type             *IssueType `xorm:"-"`
+	ParentID         int64      `xorm:"INDEX NOT NULL DEFAULT 0"`
+	Parent           *Issue     `xorm:"-"`
 	Priority         int
 	AssigneeID       int64        `xorm:"-"`
 	Assignee         *User        `xorm:"-"`
@@ -51,6 +55,10 @@ type Issue struct {
 	PullRequest      *PullRequest `xorm:"-"`
 	NumComments      int
 	Ref              string
+	// Template is the file name of the issue template the issue was created from
+	Template string `xorm:"VARCHAR(255) INDEX NOT NULL DEFAULT ''"`
+	// Weight is the estimated effort of the issue, 0 if it has not been estimated
+	Weight int64 `xorm:"NOT NULL DEFAULT 0"`
 
 	DeadlineUnix timeutil.TimeStamp `xorm:"INDEX"`
 
@@ -222,6 +230,20 @@ func (issue *Issue) loadCommentsByType(e Engine, tp CommentType) (err error) {
 	return err
 }
 
+// LoadCommentsPage loads a page of the comments of the issue in the order they are shown in its timeline.
+// Unlike the API pagination the page size is not limited, the caller is responsible for choosing it.
+func (issue *Issue) LoadCommentsPage(page, pageSize int) error {
+	if page <= 0 {
+		page = 1
+	}
+	issue.Comments = make([]*Comment, 0, pageSize)
+	return x.Where("issue_id = ?", issue.ID).
+		Asc("created_unix").
+		Asc("id").
+		Limit(pageSize, (page-1)*pageSize).
+		Find(&issue.Comments)
+}
+
 func (issue *Issue) loadReactions(e Engine) (err error) {
 	if issue.Reactions != nil {
 		return nil
@@ -287,6 +309,10 @@ func (issue *Issue) loadAttributes(e Engine) (err error) {
 		return
 	}
 
+	if err = issue.loadType(e); err != nil {
+		return
+	}
+
 	if err = issue.loadAssignees(e); err != nil {
 		return
 	}
@@ -656,6 +682,12 @@ func (issue *Issue) doChangeStatus(e *xorm.Session, doer *User, isMergePull bool
 		return nil, err
 	}
 
+	if issue.IsClosed {
+		if err := moveIssueToAutoMoveBoard(e, issue, isMergePull); err != nil {
+			return nil, err
+		}
+	}
+
 	// New action comment
 	cmtType := CommentTypeClose
 	if !issue.IsClosed {
@@ -797,6 +829,7 @@ func (issue *Issue) UpdateAttachments(uuids []string) (err error) {
 
 // ChangeContent changes issue content, as the given user.
 func (issue *Issue) ChangeContent(doer *User, content string) (err error) {
+	oldContent := issue.Content
 	issue.Content = content
 
 	sess := x.NewSession()
@@ -809,6 +842,10 @@ func (issue *Issue) ChangeContent(doer *User, content string) (err error) {
 		return fmt.Errorf("UpdateIssueCols: %v", err)
 	}
 
+	if err = saveIssueContentEdit(sess, issue.ID, 0, issue.PosterID, issue.CreatedUnix, oldContent, content, doer); err != nil {
+		return fmt.Errorf("saveIssueContentEdit: %v", err)
+	}
+
 	if err = issue.addCrossReferences(sess, doer, true); err != nil {
 		return err
 	}
@@ -1111,6 +1148,7 @@ type IssuesOptions struct {
 	MilestoneIDs       []int64
 	ProjectID          int64
 	ProjectBoardID     int64
+	TypeID             int64
 	IsClosed           util.OptionalBool
 	IsPull             util.OptionalBool
 	LabelIDs           []int64
@@ -1120,6 +1158,8 @@ type IssuesOptions struct {
 	IssueIDs           []int64
 	UpdatedAfterUnix   int64
 	UpdatedBeforeUnix  int64
+	CreatedAfterUnix   int64
+	CreatedBeforeUnix  int64
 	// prioritize issues from this repo
 	PriorityRepoID int64
 	IsArchived     util.OptionalBool
@@ -1199,12 +1239,22 @@ func (opts *IssuesOptions) setupSession(sess *xorm.Session) {
 		sess.In("issue.milestone_id", opts.MilestoneIDs)
 	}
 
+	if opts.TypeID > 0 {
+		sess.And("issue.type_id = ?", opts.TypeID)
+	}
+
 	if opts.UpdatedAfterUnix != 0 {
 		sess.And(builder.Gte{"issue.updated_unix": opts.UpdatedAfterUnix})
 	}
 	if opts.UpdatedBeforeUnix != 0 {
 		sess.And(builder.Lte{"issue.updated_unix": opts.UpdatedBeforeUnix})
 	}
+	if opts.CreatedAfterUnix != 0 {
+		sess.And(builder.Gte{"issue.created_unix": opts.CreatedAfterUnix})
+	}
+	if opts.CreatedBeforeUnix != 0 {
+		sess.And(builder.Lte{"issue.created_unix": opts.CreatedBeforeUnix})
+	}
 
 	if opts.ProjectID > 0 {
 		sess.Join("INNER", "project_issue", "issue.id = project_issue.issue_id").
@@ -1443,12 +1493,17 @@ type IssueStatsOptions struct {
 	RepoID            int64
 	Labels            string
 	MilestoneID       int64
+	TypeID            int64
 	AssigneeID        int64
 	MentionedID       int64
 	PosterID          int64
 	ReviewRequestedID int64
 	IsPull            util.OptionalBool
 	IssueIDs          []int64
+	UpdatedAfterUnix  int64
+	UpdatedBeforeUnix int64
+	CreatedAfterUnix  int64
+	CreatedBeforeUnix int64
 }
 
 // GetIssueStats returns issue statistic information by given conditions.
@@ -1514,6 +1569,10 @@ func getIssueStatsChunk(opts *IssueStatsOptions, issueIDs []int64) (*IssueStats,
 			sess.And("issue.milestone_id = ?", opts.MilestoneID)
 		}
 
+		if opts.TypeID > 0 {
+			sess.And("issue.type_id = ?", opts.TypeID)
+		}
+
 		if opts.AssigneeID > 0 {
 			applyAssigneeCondition(sess, opts.AssigneeID)
 		}
@@ -1537,6 +1596,19 @@ func getIssueStatsChunk(opts *IssueStatsOptions, issueIDs []int64) (*IssueStats,
 			sess.And("issue.is_pull=?", false)
 		}
 
+		if opts.UpdatedAfterUnix != 0 {
+			sess.And(builder.Gte{"issue.updated_unix": opts.UpdatedAfterUnix})
+		}
+		if opts.UpdatedBeforeUnix != 0 {
+			sess.And(builder.Lte{"issue.updated_unix": opts.UpdatedBeforeUnix})
+		}
+		if opts.CreatedAfterUnix != 0 {
+			sess.And(builder.Gte{"issue.created_unix": opts.CreatedAfterUnix})
+		}
+		if opts.CreatedBeforeUnix != 0 {
+			sess.And(builder.Lte{"issue.created_unix": opts.CreatedBeforeUnix})
+		}
+
 		return sess
 	}
 
@@ -1721,24 +1793,37 @@ func GetRepoIssueStats(repoID, uid int64, filterMode int, isPull bool) (numOpen,
 
 // SearchIssueIDsByKeyword search issues on database
 func SearchIssueIDsByKeyword(kw string, repoIDs []int64, limit, start int) (int64, []int64, error) {
+	return SearchIssueIDsByKeywordIn(kw, nil, repoIDs, limit, start)
+}
+
+// SearchIssueIDsByKeywordIn search issues on database, in the title, the content or the comments of the issues as
+// fields lists them with "title", "content" and "comments", or in all of them if fields is empty
+func SearchIssueIDsByKeywordIn(kw string, fields []string, repoIDs []int64, limit, start int) (int64, []int64, error) {
 	repoCond := builder.In("repo_id", repoIDs)
 	subQuery := builder.Select("id").From("issue").Where(repoCond)
 	kw = strings.ToUpper(kw)
-	cond := builder.And(
-		repoCond,
-		builder.Or(
-			builder.Like{"UPPER(name)", kw},
-			builder.Like{"UPPER(content)", kw},
-			builder.In("id", builder.Select("issue_id").
-				From("comment").
-				Where(builder.And(
-					builder.Eq{"type": CommentTypeComment},
-					builder.In("issue_id", subQuery),
-					builder.Like{"UPPER(content)", kw},
-				)),
-			),
+	fieldConds := map[string]builder.Cond{
+		"title":   builder.Like{"UPPER(name)", kw},
+		"content": builder.Like{"UPPER(content)", kw},
+		"comments": builder.In("id", builder.Select("issue_id").
+			From("comment").
+			Where(builder.And(
+				builder.Eq{"type": CommentTypeComment},
+				builder.In("issue_id", subQuery),
+				builder.Like{"UPPER(content)", kw},
+			)),
 		),
-	)
+	}
+	if len(fields) == 0 {
+		fields = []string{"title", "content", "comments"}
+	}
+	kwCond := builder.NewCond()
+	for _, field := range fields {
+		if fieldCond, ok := fieldConds[field]; ok {
+			kwCond = kwCond.Or(fieldCond)
+		}
+	}
+	cond := builder.And(repoCond, kwCond)
 
 	ids := make([]int64, 0, limit)
 	res := make([]struct {
@@ -1980,52 +2065,26 @@ func (issue *Issue) ResolveMentionsByVisibility(ctx DBContext, doer *User, menti
 	}
 
 	if issue.Repo.Owner.IsOrganization() && len(mentionTeams) > 0 {
-		teams := make([]*Team, 0, len(mentionTeams))
-		if err := ctx.e.
-			Join("INNER", "team_repo", "team_repo.team_id = team.id").
-			Where("team_repo.repo_id=?", issue.Repo.ID).
-			In("team.lower_name", mentionTeams).
-			Find(&teams); err != nil {
-			return nil, fmt.Errorf("find mentioned teams: %v", err)
+		teams, err := issue.getMentionableTeams(ctx.e, mentionTeams)
+		if err != nil {
+			return nil, err
 		}
 		if len(teams) != 0 {
 			checked := make([]int64, 0, len(teams))
-			unittype := UnitTypeIssues
-			if issue.IsPull {
-				unittype = UnitTypePullRequests
-			}
 			for _, team := range teams {
-				if team.Authorize >= AccessModeOwner {
-					checked = append(checked, team.ID)
-					resolved[issue.Repo.Owner.LowerName+"/"+team.LowerName] = true
-					continue
-				}
-				has, err := ctx.e.Get(&TeamUnit{OrgID: issue.Repo.Owner.ID, TeamID: team.ID, Type: unittype})
-				if err != nil {
-					return nil, fmt.Errorf("get team units (%d): %v", team.ID, err)
-				}
-				if has {
-					checked = append(checked, team.ID)
-					resolved[issue.Repo.Owner.LowerName+"/"+team.LowerName] = true
-				}
+				checked = append(checked, team.ID)
+				resolved[issue.Repo.Owner.LowerName+"/"+team.LowerName] = true
 			}
-			if len(checked) != 0 {
-				teamusers := make([]*User, 0, 20)
-				if err := ctx.e.
-					Join("INNER", "team_user", "team_user.uid = `user`.id").
-					In("`team_user`.team_id", checked).
-					And("`user`.is_active = ?", true).
-					And("`user`.prohibit_login = ?", false).
-					Find(&teamusers); err != nil {
-					return nil, fmt.Errorf("get teams users: %v", err)
-				}
-				if len(teamusers) > 0 {
-					users = make([]*User, 0, len(teamusers))
-					for _, user := range teamusers {
-						if already, ok := resolved[user.LowerName]; !ok || !already {
-							users = append(users, user)
-							resolved[user.LowerName] = true
-						}
+			teamusers, err := getActiveTeamsMembers(ctx.e, checked)
+			if err != nil {
+				return nil, err
+			}
+			if len(teamusers) > 0 {
+				users = make([]*User, 0, len(teamusers))
+				for _, user := range teamusers {
+					if already, ok := resolved[user.LowerName]; !ok || !already {
+						users = append(users, user)
+						resolved[user.LowerName] = true
 					}
 				}
 			}
@@ -2073,6 +2132,53 @@ func (issue *Issue) ResolveMentionsByVisibility(ctx DBContext, doer *User, menti
 	return
 }
 
+// getMentionableTeams returns the teams among the named ones of the organization owning the repository of the issue
+// whose members can read the issue
+func (issue *Issue) getMentionableTeams(e Engine, names []string) ([]*Team, error) {
+	teams := make([]*Team, 0, len(names))
+	if err := e.
+		Join("INNER", "team_repo", "team_repo.team_id = team.id").
+		Where("team_repo.repo_id=?", issue.Repo.ID).
+		In("team.lower_name", names).
+		Find(&teams); err != nil {
+		return nil, fmt.Errorf("find mentioned teams: %v", err)
+	}
+
+	unittype := UnitTypeIssues
+	if issue.IsPull {
+		unittype = UnitTypePullRequests
+	}
+	checked := make([]*Team, 0, len(teams))
+	for _, team := range teams {
+		if team.Authorize >= AccessModeOwner {
+			checked = append(checked, team)
+			continue
+		}
+		has, err := e.Get(&TeamUnit{OrgID: issue.Repo.Owner.ID, TeamID: team.ID, Type: unittype})
+		if err != nil {
+			return nil, fmt.Errorf("get team units (%d): %v", team.ID, err)
+		}
+		if has {
+			checked = append(checked, team)
+		}
+	}
+	return checked, nil
+}
+
+// getActiveTeamsMembers returns the members of teams who can sign in
+func getActiveTeamsMembers(e Engine, teamIDs []int64) ([]*User, error) {
+	teamusers := make([]*User, 0, 20)
+	if err := e.
+		Join("INNER", "team_user", "team_user.uid = `user`.id").
+		In("`team_user`.team_id", teamIDs).
+		And("`user`.is_active = ?", true).
+		And("`user`.prohibit_login = ?", false).
+		Find(&teamusers); err != nil {
+		return nil, fmt.Errorf("get teams users: %v", err)
+	}
+	return teamusers, nil
+}
+
 // UpdateIssuesMigrationsByType updates all migrated repositories' issues from gitServiceType to replace originalAuthorID to posterID
 func UpdateIssuesMigrationsByType(gitServiceType structs.GitServiceType, originalAuthorID string, posterID int64) error {
 	_, err := x.Table("issue").
@@ -2130,6 +2236,11 @@ func deleteIssuesByRepoID(sess Engine, repoID int64) (attachmentPaths []string,
 		return
 	}
 
+	if _, err = sess.In("issue_id", deleteCond).
+		Delete(&IssueContentHistory{}); err != nil {
+		return
+	}
+
 	if _, err = sess.In("issue_id", deleteCond).
 		Delete(&IssueWatch{}); err != nil {
 		return
@@ -0,0 +1,147 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// IssueCollaborator represents an outside user who has been invited to a single
+// issue or pull request. The user may read and comment on that issue only,
+// without getting access to any other content of the repository.
+type IssueCollaborator struct {
+	ID          int64              `xorm:"pk autoincr"`
+	RepoID      int64              `xorm:"INDEX NOT NULL"`
+	IssueID     int64              `xorm:"UNIQUE(s) NOT NULL"`
+	UserID      int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	DoerID      int64              `xorm:"NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL"`
+}
+
+// AddIssueCollaborator invites the user to the issue and makes them watch it.
+func AddIssueCollaborator(issue *Issue, user, doer *User) error {
+	if user.IsOrganization() {
+		return ErrIssueCollaboratorNotIndividual{UserID: user.ID}
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	has, err := sess.Exist(&IssueCollaborator{IssueID: issue.ID, UserID: user.ID})
+	if err != nil {
+		return err
+	} else if has {
+		return ErrIssueCollaboratorAlreadyExist{IssueID: issue.ID, UserID: user.ID}
+	}
+
+	if _, err := sess.Insert(&IssueCollaborator{
+		RepoID:  issue.RepoID,
+		IssueID: issue.ID,
+		UserID:  user.ID,
+		DoerID:  doer.ID,
+	}); err != nil {
+		return err
+	}
+
+	iw, exists, err := getIssueWatch(sess, user.ID, issue.ID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if _, err := sess.Insert(&IssueWatch{UserID: user.ID, IssueID: issue.ID, IsWatching: true}); err != nil {
+			return err
+		}
+	} else if !iw.IsWatching {
+		iw.IsWatching = true
+		if _, err := sess.ID(iw.ID).Cols("is_watching", "updated_unix").Update(iw); err != nil {
+			return err
+		}
+	}
+
+	return sess.Commit()
+}
+
+// RemoveIssueCollaborator revokes the invitation of the user to the issue.
+func RemoveIssueCollaborator(issue *Issue, userID int64) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.Delete(&IssueCollaborator{IssueID: issue.ID, UserID: userID}); err != nil {
+		return err
+	}
+
+	// The user is not allowed to see the issue anymore if they have no other access to it
+	if err := issue.loadRepo(sess); err != nil {
+		return err
+	}
+	user, err := getUserByID(sess, userID)
+	if err != nil {
+		return err
+	}
+	if !issue.Repo.checkUnitUser(sess, user, issue.unitType()) {
+		if _, err := sess.Delete(&IssueWatch{IssueID: issue.ID, UserID: userID}); err != nil {
+			return err
+		}
+	}
+
+	return sess.Commit()
+}
+
+func isIssueCollaborator(e Engine, issueID, userID int64) (bool, error) {
+	return e.Get(&IssueCollaborator{IssueID: issueID, UserID: userID})
+}
+
+// IsIssueCollaborator returns true if the user has been invited to the issue.
+func IsIssueCollaborator(issueID, userID int64) (bool, error) {
+	return isIssueCollaborator(x, issueID, userID)
+}
+
+// HasIssueCollaborations returns true if the user has been invited to any issue of the repository.
+func HasIssueCollaborations(repoID, userID int64) (bool, error) {
+	return x.Exist(&IssueCollaborator{RepoID: repoID, UserID: userID})
+}
+
+// GetIssueCollaborators returns the users invited to the issue.
+func GetIssueCollaborators(issueID int64) ([]*User, error) {
+	users := make([]*User, 0, 2)
+	return users, x.
+		Join("INNER", "issue_collaborator", "`issue_collaborator`.user_id = `user`.id").
+		Where("`issue_collaborator`.issue_id = ?", issueID).
+		Asc("`user`.name").
+		Find(&users)
+}
+
+func (issue *Issue) unitType() UnitType {
+	if issue.IsPull {
+		return UnitTypePullRequests
+	}
+	return UnitTypeIssues
+}
+
+func (issue *Issue) checkReadable(e Engine, user *User) bool {
+	if issue.Repo.checkUnitUser(e, user, issue.unitType()) {
+		return true
+	}
+	isCollaborator, err := isIssueCollaborator(e, issue.ID, user.ID)
+	if err != nil {
+		log.Error("isIssueCollaborator: %v", err)
+		return false
+	}
+	return isCollaborator
+}
+
+// IsReadableBy returns true if the user can see the issue, either through the
+// permissions on the repository or as an invited issue collaborator.
+// The repository of the issue must be loaded.
+func (issue *Issue) IsReadableBy(user *User) bool {
+	return issue.checkReadable(x, user)
+}
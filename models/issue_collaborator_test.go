@@ -0,0 +1,69 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddIssueCollaborator(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 7}).(*Issue)
+	user := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	assert.NoError(t, AddIssueCollaborator(issue, user, doer))
+	AssertExistsAndLoadBean(t, &IssueCollaborator{RepoID: 2, IssueID: 7, UserID: 4, DoerID: 2})
+	iw := AssertExistsAndLoadBean(t, &IssueWatch{UserID: 4, IssueID: 7}).(*IssueWatch)
+	assert.True(t, iw.IsWatching)
+
+	err := AddIssueCollaborator(issue, user, doer)
+	assert.True(t, IsErrIssueCollaboratorAlreadyExist(err))
+
+	org := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+	err = AddIssueCollaborator(issue, org, doer)
+	assert.True(t, IsErrIssueCollaboratorNotIndividual(err))
+}
+
+func TestIssueCollaboratorAccess(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 7}).(*Issue)
+	assert.NoError(t, issue.LoadRepo())
+	user := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	assert.False(t, issue.IsReadableBy(user))
+	assert.NoError(t, AddIssueCollaborator(issue, user, doer))
+	assert.True(t, issue.IsReadableBy(user))
+
+	isCollaborator, err := IsIssueCollaborator(7, 4)
+	assert.NoError(t, err)
+	assert.True(t, isCollaborator)
+	isCollaborator, err = IsIssueCollaborator(4, 4)
+	assert.NoError(t, err)
+	assert.False(t, isCollaborator)
+
+	has, err := HasIssueCollaborations(2, 4)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	has, err = HasIssueCollaborations(1, 4)
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	users, err := GetIssueCollaborators(7)
+	assert.NoError(t, err)
+	if assert.Len(t, users, 1) {
+		assert.EqualValues(t, 4, users[0].ID)
+	}
+
+	assert.NoError(t, RemoveIssueCollaborator(issue, 4))
+	AssertNotExistsBean(t, &IssueCollaborator{IssueID: 7, UserID: 4})
+	AssertNotExistsBean(t, &IssueWatch{UserID: 4, IssueID: 7})
+	assert.False(t, issue.IsReadableBy(user))
+}
@@ -101,8 +101,82 @@ const (
 	CommentTypeProjectBoard
 	// Dismiss Review
 	CommentTypeDismissReview
+	// 33 Pull request converted to an issue
+	CommentTypeConvertToIssue
+	// 34 Issue created from a converted pull request
+	CommentTypeConvertFromPull
+	// 35 Pull request added to the merge queue
+	CommentTypeAddedToMergeQueue
+	// 36 Pull request removed from the merge queue
+	CommentTypeRemovedFromMergeQueue
+	// 37 Pull request scheduled to be merged when all checks succeed
+	CommentTypePRScheduledToAutoMerge
+	// 38 Scheduled auto merge of a pull request canceled
+	CommentTypePRUnScheduledToAutoMerge
+	// 39 Issue type changed
+	CommentTypeChangeIssueType
+	// 40 Parent issue set
+	CommentTypeAddParentIssue
+	// 41 Parent issue removed
+	CommentTypeRemoveParentIssue
+	// 42 Issue weight changed
+	CommentTypeChangeIssueWeight
 )
 
+var commentStrings = []string{
+	"comment",
+	"reopen",
+	"close",
+	"issue_ref",
+	"commit_ref",
+	"comment_ref",
+	"pull_ref",
+	"label",
+	"milestone",
+	"assignees",
+	"change_title",
+	"delete_branch",
+	"start_tracking",
+	"stop_tracking",
+	"add_time_manual",
+	"cancel_tracking",
+	"added_deadline",
+	"modified_deadline",
+	"removed_deadline",
+	"add_dependency",
+	"remove_dependency",
+	"code",
+	"review",
+	"lock",
+	"unlock",
+	"change_target_branch",
+	"delete_time_manual",
+	"review_request",
+	"merge_pull",
+	"pull_push",
+	"project",
+	"project_board",
+	"dismiss_review",
+	"convert_to_issue",
+	"convert_from_pull",
+	"added_to_merge_queue",
+	"removed_from_merge_queue",
+	"pull_scheduled_merge",
+	"pull_cancel_scheduled_merge",
+	"change_issue_type",
+	"add_parent_issue",
+	"remove_parent_issue",
+	"change_issue_weight",
+}
+
+// String returns the name of the comment type used by the API, e.g. "comment" or "label"
+func (t CommentType) String() string {
+	if t < 0 || int(t) >= len(commentStrings) {
+		return "unknown"
+	}
+	return commentStrings[t]
+}
+
 // CommentTag defines comment tag type
 type CommentTag int
 
@@ -154,6 +228,7 @@ type Comment struct {
 
 	CommitID        int64
 	Line            int64 // - previous line / + proposed line
+	StartLine       int64 // first line of a multi-line code comment, signed like Line, 0 for a single line
 	TreePath        string
 	Content         string `xorm:"TEXT"`
 	RenderedContent string `xorm:"-"`
@@ -178,6 +253,11 @@ type Comment struct {
 	ReviewID    int64   `xorm:"index"`
 	Invalidated bool
 
+	// SuggestionCommitSHA is the commit which applied the change suggested by a code comment
+	SuggestionCommitSHA string `xorm:"VARCHAR(40)"`
+	SuggestionApplierID int64
+	SuggestionApplier   *User `xorm:"-"`
+
 	// Reference an issue or pull from another comment, issue or PR
 	// All information is about the origin of the reference
 	RefRepoID    int64                 `xorm:"index"` // Repo where the referencing
@@ -527,11 +607,75 @@ func (c *Comment) LoadResolveDoer() (err error) {
 	return
 }
 
+// SupportsReactions returns whether users can react to the comment: a comment, a code review comment or the summary of
+// a review
+func (c *Comment) SupportsReactions() bool {
+	return c.Type == CommentTypeComment || c.Type == CommentTypeCode || c.Type == CommentTypeReview
+}
+
 // IsResolved check if an code comment is resolved
 func (c *Comment) IsResolved() bool {
 	return c.ResolveDoerID != 0 && c.Type == CommentTypeCode
 }
 
+var suggestionPattern = regexp.MustCompile("(?ms)^[ \t]*```suggestion[ \t]*\n(.*?)^[ \t]*```[ \t]*$")
+
+// Suggestion returns the replacement of the commented line, or range of lines, suggested
+// in the first ```suggestion block of a code comment. An empty suggestion removes the lines.
+func (c *Comment) Suggestion() (string, bool) {
+	if c.Type != CommentTypeCode || c.Line <= 0 {
+		return "", false
+	}
+	match := suggestionPattern.FindStringSubmatch(strings.ReplaceAll(c.Content, "\r\n", "\n"))
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// SuggestionBlock returns the ```suggestion block for the content of a code comment
+// which suggests to replace the commented lines with suggestion
+func SuggestionBlock(suggestion string) string {
+	if suggestion != "" && !strings.HasSuffix(suggestion, "\n") {
+		suggestion += "\n"
+	}
+	return "```suggestion\n" + suggestion + "```\n"
+}
+
+// HasSuggestion returns true if a code comment suggests a change of the commented lines
+func (c *Comment) HasSuggestion() bool {
+	_, ok := c.Suggestion()
+	return ok
+}
+
+// IsSuggestionApplied returns true if the suggested change has been committed
+func (c *Comment) IsSuggestionApplied() bool {
+	return c.SuggestionCommitSHA != ""
+}
+
+// LoadSuggestionApplier loads the user who applied the suggested change
+func (c *Comment) LoadSuggestionApplier() (err error) {
+	if c.SuggestionApplierID == 0 || c.SuggestionApplier != nil {
+		return nil
+	}
+	c.SuggestionApplier, err = getUserByID(x, c.SuggestionApplierID)
+	if err != nil && IsErrUserNotExist(err) {
+		c.SuggestionApplier = NewGhostUser()
+		err = nil
+	}
+	return
+}
+
+// MarkSuggestionApplied records that the change suggested by a code comment
+// has been committed by doer
+func MarkSuggestionApplied(c *Comment, doer *User, commitSHA string) error {
+	c.SuggestionCommitSHA = commitSHA
+	c.SuggestionApplierID = doer.ID
+	c.SuggestionApplier = doer
+	_, err := x.ID(c.ID).Cols("suggestion_commit_sha", "suggestion_applier_id").NoAutoTime().Update(c)
+	return err
+}
+
 // LoadDepIssueDetails loads Dependent Issue Details
 func (c *Comment) LoadDepIssueDetails() (err error) {
 	if c.DependentIssueID <= 0 || c.DependentIssue != nil {
@@ -630,6 +774,34 @@ func (c *Comment) UnsignedLine() uint64 {
 	return uint64(c.Line)
 }
 
+// UnsignedStartLine returns the first LOC of a multi-line code comment without + or -.
+// For a single line code comment it is the same as UnsignedLine.
+func (c *Comment) UnsignedStartLine() uint64 {
+	if !c.IsMultiLine() {
+		return c.UnsignedLine()
+	}
+	if c.StartLine < 0 {
+		return uint64(c.StartLine * -1)
+	}
+	return uint64(c.StartLine)
+}
+
+// IsMultiLine returns true if the code comment spans a range of lines
+func (c *Comment) IsMultiLine() bool {
+	return c.StartLine != 0 && c.StartLine != c.Line
+}
+
+// IsLineInRange returns true if the signed line is one of the lines commented by the code comment
+func (c *Comment) IsLineInRange(line int64) bool {
+	if line == 0 || (line < 0) != (c.Line < 0) {
+		return false
+	}
+	if line < 0 {
+		line *= -1
+	}
+	return uint64(line) >= c.UnsignedStartLine() && uint64(line) <= c.UnsignedLine()
+}
+
 // CodeCommentURL returns the url to a comment in code
 func (c *Comment) CodeCommentURL() string {
 	err := c.LoadIssue()
@@ -710,6 +882,7 @@ func createComment(e *xorm.Session, opts *CreateCommentOptions) (_ *Comment, err
 		CommitID:         opts.CommitID,
 		CommitSHA:        opts.CommitSHA,
 		Line:             opts.LineNum,
+		StartLine:        opts.StartLineNum,
 		Content:          opts.Content,
 		OldTitle:         opts.OldTitle,
 		NewTitle:         opts.NewTitle,
@@ -883,6 +1056,7 @@ type CreateCommentOptions struct {
 	CommitSHA        string
 	Patch            string
 	LineNum          int64
+	StartLineNum     int64
 	TreePath         string
 	ReviewID         int64
 	Content          string
@@ -967,6 +1141,7 @@ type FindCommentsOptions struct {
 	RepoID   int64
 	IssueID  int64
 	ReviewID int64
+	PosterID int64
 	Since    int64
 	Before   int64
 	Line     int64
@@ -985,6 +1160,9 @@ func (opts *FindCommentsOptions) toConds() builder.Cond {
 	if opts.ReviewID > 0 {
 		cond = cond.And(builder.Eq{"comment.review_id": opts.ReviewID})
 	}
+	if opts.PosterID > 0 {
+		cond = cond.And(builder.Eq{"comment.poster_id": opts.PosterID})
+	}
 	if opts.Since > 0 {
 		cond = cond.And(builder.Gte{"comment.updated_unix": opts.Since})
 	}
@@ -1027,6 +1205,15 @@ func FindComments(opts FindCommentsOptions) ([]*Comment, error) {
 	return findComments(x, opts)
 }
 
+// CountComments returns the number of comments according options by ignoring pagination
+func CountComments(opts FindCommentsOptions) (int64, error) {
+	sess := x.Where(opts.toConds())
+	if opts.RepoID > 0 {
+		sess.Join("INNER", "issue", "issue.id = comment.issue_id")
+	}
+	return sess.Count(&Comment{})
+}
+
 // UpdateComment updates information of comment.
 func UpdateComment(c *Comment, doer *User) error {
 	sess := x.NewSession()
@@ -1035,9 +1222,18 @@ func UpdateComment(c *Comment, doer *User) error {
 		return err
 	}
 
+	old := new(Comment)
+	if has, err := sess.ID(c.ID).Cols("content").Get(old); err != nil {
+		return err
+	} else if !has {
+		return ErrCommentNotExist{c.ID, 0}
+	}
 	if _, err := sess.ID(c.ID).AllCols().Update(c); err != nil {
 		return err
 	}
+	if err := saveIssueContentEdit(sess, c.IssueID, c.ID, c.PosterID, c.CreatedUnix, old.Content, c.Content, doer); err != nil {
+		return fmt.Errorf("saveIssueContentEdit: %v", err)
+	}
 	if err := c.loadIssue(sess); err != nil {
 		return err
 	}
@@ -1086,6 +1282,10 @@ func deleteComment(e Engine, comment *Comment) error {
 		return err
 	}
 
+	if _, err := e.Where(issueContentHistoryCond(comment.IssueID, comment.ID)).Delete(new(IssueContentHistory)); err != nil {
+		return err
+	}
+
 	return deleteReaction(e, &ReactionOptions{Comment: comment})
 }
 
@@ -1145,6 +1345,10 @@ func findCodeComments(e Engine, opts FindCommentsOptions, issue *Issue, currentU
 		return nil, err
 	}
 
+	if err := CommentList(comments).loadAttachments(e); err != nil {
+		return nil, err
+	}
+
 	// Find all reviews by ReviewID
 	reviews := make(map[int64]*Review)
 	ids := make([]int64, 0, len(comments))
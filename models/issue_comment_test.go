@@ -57,3 +57,106 @@ func TestFetchCodeComments(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, res, 1)
 }
+
+func TestCommentSuggestion(t *testing.T) {
+	kases := []struct {
+		comment    Comment
+		suggestion string
+		ok         bool
+	}{
+		{Comment{Type: CommentTypeCode, Line: 4, Content: "Better:\r\n```suggestion\r\nfmt.Println(x)\r\n```\r\n"}, "fmt.Println(x)\n", true},
+		{Comment{Type: CommentTypeCode, Line: 4, Content: "```suggestion\nfirst\n```\n```suggestion\nsecond\n```"}, "first\n", true},
+		{Comment{Type: CommentTypeCode, Line: 4, Content: "Remove it\n```suggestion\n```"}, "", true},
+		{Comment{Type: CommentTypeCode, Line: 4, Content: "```go\nfmt.Println(x)\n```"}, "", false},
+		{Comment{Type: CommentTypeCode, Line: 4, Content: "```suggestion\nunterminated"}, "", false},
+		{Comment{Type: CommentTypeCode, Line: -4, Content: "```suggestion\nold side\n```"}, "", false},
+		{Comment{Type: CommentTypeComment, Content: "```suggestion\nno code comment\n```"}, "", false},
+	}
+	for _, kase := range kases {
+		suggestion, ok := kase.comment.Suggestion()
+		assert.Equal(t, kase.ok, ok, kase.comment.Content)
+		assert.Equal(t, kase.suggestion, suggestion, kase.comment.Content)
+	}
+
+	assert.Equal(t, "```suggestion\na\nb\n```\n", SuggestionBlock("a\nb"))
+	assert.Equal(t, "```suggestion\na\nb\n```\n", SuggestionBlock("a\nb\n"))
+	assert.Equal(t, "```suggestion\n```\n", SuggestionBlock(""))
+}
+
+func TestMarkSuggestionApplied(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	comment := AssertExistsAndLoadBean(t, &Comment{ID: 4}).(*Comment)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.False(t, comment.IsSuggestionApplied())
+	assert.NoError(t, MarkSuggestionApplied(comment, doer, "65f1bf27bc3bf70f64657658635e66094edbcb4d"))
+	assert.True(t, comment.IsSuggestionApplied())
+
+	comment = AssertExistsAndLoadBean(t, &Comment{ID: 4}).(*Comment)
+	assert.Equal(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", comment.SuggestionCommitSHA)
+	assert.NoError(t, comment.LoadSuggestionApplier())
+	assert.Equal(t, doer.ID, comment.SuggestionApplier.ID)
+}
+
+func TestCommentLineRange(t *testing.T) {
+	single := &Comment{Line: 4}
+	assert.False(t, single.IsMultiLine())
+	assert.EqualValues(t, 4, single.UnsignedStartLine())
+	assert.True(t, single.IsLineInRange(4))
+	assert.False(t, single.IsLineInRange(3))
+
+	proposed := &Comment{Line: 6, StartLine: 3}
+	assert.True(t, proposed.IsMultiLine())
+	assert.EqualValues(t, 3, proposed.UnsignedStartLine())
+	assert.True(t, proposed.IsLineInRange(3))
+	assert.True(t, proposed.IsLineInRange(6))
+	assert.False(t, proposed.IsLineInRange(7))
+	assert.False(t, proposed.IsLineInRange(-4))
+	assert.False(t, proposed.IsLineInRange(0))
+
+	previous := &Comment{Line: -6, StartLine: -3}
+	assert.True(t, previous.IsMultiLine())
+	assert.EqualValues(t, 3, previous.UnsignedStartLine())
+	assert.True(t, previous.IsLineInRange(-4))
+	assert.False(t, previous.IsLineInRange(4))
+}
+
+func TestCountComments(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	count, err := CountComments(FindCommentsOptions{IssueID: 1, Type: CommentTypeUnknown})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+
+	count, err = CountComments(FindCommentsOptions{IssueID: 1, Type: CommentTypeComment})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+}
+
+func TestIssue_LoadCommentsPage(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.NoError(t, issue.LoadCommentsPage(1, 2))
+	if assert.Len(t, issue.Comments, 2) {
+		assert.EqualValues(t, 1, issue.Comments[0].ID)
+		assert.EqualValues(t, 2, issue.Comments[1].ID)
+	}
+
+	assert.NoError(t, issue.LoadCommentsPage(2, 2))
+	if assert.Len(t, issue.Comments, 1) {
+		assert.EqualValues(t, 3, issue.Comments[0].ID)
+	}
+
+	assert.NoError(t, issue.LoadCommentsPage(3, 2))
+	assert.Empty(t, issue.Comments)
+	assert.NotNil(t, issue.Comments)
+}
+
+func TestCommentType_String(t *testing.T) {
+	assert.Equal(t, "comment", CommentTypeComment.String())
+	assert.Equal(t, "code", CommentTypeCode.String())
+	assert.Equal(t, "dismiss_review", CommentTypeDismissReview.String())
+	assert.Equal(t, "pull_cancel_scheduled_merge", CommentTypePRUnScheduledToAutoMerge.String())
+	assert.Equal(t, "unknown", CommentTypeUnknown.String())
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// IssueContentHistory is a version of the content of an issue or of a comment. The versions are saved from the first
+// edit of the content on, the first edit also saving the content as it was first posted.
+type IssueContentHistory struct {
+	ID      int64 `xorm:"pk autoincr"`
+	IssueID int64 `xorm:"INDEX NOT NULL"`
+	// CommentID is 0 for the content of the issue itself
+	CommentID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	// PosterID is the user who wrote this version
+	PosterID       int64              `xorm:"NOT NULL"`
+	Poster         *User              `xorm:"-"`
+	Content        string             `xorm:"LONGTEXT"`
+	IsFirstCreated bool               `xorm:"NOT NULL DEFAULT false"`
+	EditedUnix     timeutil.TimeStamp `xorm:"INDEX"`
+}
+
+// ErrIssueContentHistoryNotExist represents a "IssueContentHistoryNotExist" kind of error.
+type ErrIssueContentHistoryNotExist struct {
+	ID int64
+}
+
+// IsErrIssueContentHistoryNotExist checks if an error is a ErrIssueContentHistoryNotExist.
+func IsErrIssueContentHistoryNotExist(err error) bool {
+	_, ok := err.(ErrIssueContentHistoryNotExist)
+	return ok
+}
+
+func (err ErrIssueContentHistoryNotExist) Error() string {
+	return fmt.Sprintf("issue content history does not exist [id: %d]", err.ID)
+}
+
+// LoadPoster loads the user who wrote the version, a ghost user if it has been deleted
+func (h *IssueContentHistory) LoadPoster() (err error) {
+	if h.Poster != nil {
+		return nil
+	}
+	if h.Poster, err = GetUserByID(h.PosterID); err != nil {
+		if !IsErrUserNotExist(err) {
+			return err
+		}
+		h.Poster = NewGhostUser()
+	}
+	return nil
+}
+
+func issueContentHistoryCond(issueID, commentID int64) builder.Cond {
+	return builder.Eq{"issue_id": issueID, "comment_id": commentID}
+}
+
+// saveIssueContentEdit saves the version of the content of an issue or of a comment written by a user who edited it.
+// The first edit also saves the content as it was first posted.
+func saveIssueContentEdit(e Engine, issueID, commentID, posterID int64, createdUnix timeutil.TimeStamp, oldContent, newContent string, doer *User) error {
+	if oldContent == newContent {
+		return nil
+	}
+	has, err := e.Where(issueContentHistoryCond(issueID, commentID)).Exist(new(IssueContentHistory))
+	if err != nil {
+		return err
+	}
+	if !has {
+		if _, err := e.Insert(&IssueContentHistory{
+			IssueID:        issueID,
+			CommentID:      commentID,
+			PosterID:       posterID,
+			Content:        oldContent,
+			IsFirstCreated: true,
+			EditedUnix:     createdUnix,
+		}); err != nil {
+			return err
+		}
+	}
+	_, err = e.Insert(&IssueContentHistory{
+		IssueID:    issueID,
+		CommentID:  commentID,
+		PosterID:   doer.ID,
+		Content:    newContent,
+		EditedUnix: timeutil.TimeStampNow(),
+	})
+	return err
+}
+
+// GetIssueContentHistories returns the versions of the content of an issue, or of one of its comments if commentID is
+// not 0, the latest first
+func GetIssueContentHistories(issueID, commentID int64) ([]*IssueContentHistory, error) {
+	histories := make([]*IssueContentHistory, 0, 5)
+	if err := x.Where(issueContentHistoryCond(issueID, commentID)).
+		Desc("edited_unix", "id").
+		Find(&histories); err != nil {
+		return nil, err
+	}
+	for _, h := range histories {
+		if err := h.LoadPoster(); err != nil {
+			return nil, err
+		}
+	}
+	return histories, nil
+}
+
+// CountIssueContentEdits returns the number of edits of the content of an issue and of its comments by comment ID, 0
+// being the content of the issue itself. The contents which were never edited are left out.
+func CountIssueContentEdits(issueID int64) (map[int64]int64, error) {
+	type versionCount struct {
+		CommentID int64
+		Count     int64
+	}
+	counts := make([]*versionCount, 0, 5)
+	if err := x.Table("issue_content_history").
+		Select("comment_id, COUNT(*) AS count").
+		Where(builder.Eq{"issue_id": issueID}).
+		GroupBy("comment_id").
+		Find(&counts); err != nil {
+		return nil, err
+	}
+	edits := make(map[int64]int64, len(counts))
+	for _, count := range counts {
+		// the first version is the content as it was first posted
+		if count.Count > 1 {
+			edits[count.CommentID] = count.Count - 1
+		}
+	}
+	return edits, nil
+}
+
+// PurgeIssueContentHistory deletes all the versions of the content of an issue, or of one of its comments if commentID
+// is not 0. The current content is kept.
+func PurgeIssueContentHistory(issueID, commentID int64) error {
+	_, err := x.Where(issueContentHistoryCond(issueID, commentID)).Delete(new(IssueContentHistory))
+	return err
+}
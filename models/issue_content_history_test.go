@@ -0,0 +1,59 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueContentHistory(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user1 := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	comment := AssertExistsAndLoadBean(t, &Comment{ID: 2}).(*Comment)
+	original := issue.Content
+
+	// the content is not saved until it is edited
+	edits, err := CountIssueContentEdits(issue.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, edits)
+
+	assert.NoError(t, issue.ChangeContent(user2, "first edit"))
+	assert.NoError(t, issue.ChangeContent(user1, "second edit"))
+	assert.NoError(t, issue.ChangeContent(user1, "second edit"))
+	comment.Content = "edited comment"
+	assert.NoError(t, UpdateComment(comment, user2))
+
+	histories, err := GetIssueContentHistories(issue.ID, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, histories, 3) {
+		assert.Equal(t, "second edit", histories[0].Content)
+		assert.Equal(t, "user1", histories[0].Poster.Name)
+		assert.Equal(t, "first edit", histories[1].Content)
+		assert.Equal(t, original, histories[2].Content)
+		assert.True(t, histories[2].IsFirstCreated)
+		assert.Equal(t, issue.PosterID, histories[2].PosterID)
+		assert.Equal(t, issue.CreatedUnix, histories[2].EditedUnix)
+	}
+	histories, err = GetIssueContentHistories(issue.ID, comment.ID)
+	assert.NoError(t, err)
+	assert.Len(t, histories, 2)
+
+	edits, err = CountIssueContentEdits(issue.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]int64{0: 2, comment.ID: 1}, edits)
+
+	assert.NoError(t, PurgeIssueContentHistory(issue.ID, 0))
+	edits, err = CountIssueContentEdits(issue.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]int64{comment.ID: 1}, edits)
+
+	assert.NoError(t, DeleteComment(comment))
+	AssertNotExistsBean(t, &IssueContentHistory{CommentID: comment.ID})
+}
@@ -111,6 +111,17 @@ func issueDepExists(e Engine, issueID, depID int64) (bool, error) {
 	return e.Where("(issue_id = ? AND dependency_id = ?)", issueID, depID).Exist(&IssueDependency{})
 }
 
+// FindIssueDependencies returns the dependencies of issues: the ones blocking them if blockedBy is true, else the ones
+// they block
+func FindIssueDependencies(issueIDs []int64, blockedBy bool) ([]*IssueDependency, error) {
+	col := "dependency_id"
+	if blockedBy {
+		col = "issue_id"
+	}
+	deps := make([]*IssueDependency, 0, len(issueIDs))
+	return deps, x.In(col, issueIDs).Asc("id").Find(&deps)
+}
+
 // IssueNoDependenciesLeft checks if issue can be closed
 func IssueNoDependenciesLeft(issue *Issue) (bool, error) {
 	return issueNoDependenciesLeft(x, issue)
@@ -0,0 +1,75 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// The number of days before a due date the assignees of an issue or a milestone can be reminded
+const (
+	DefaultDueDateReminderDays = 3
+	MaxDueDateReminderDays     = 90
+)
+
+// DueDateReminder records that the assignees of an issue or a milestone were reminded of its due date, for them to be
+// reminded once per due date
+type DueDateReminder struct {
+	ID           int64              `xorm:"pk autoincr"`
+	RepoID       int64              `xorm:"INDEX NOT NULL"`
+	IssueID      int64              `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+	MilestoneID  int64              `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+	DeadlineUnix timeutil.TimeStamp `xorm:"UNIQUE(s) NOT NULL"`
+	CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+}
+
+// GetDueDateReminderDaysByRepo returns the number of days before the due dates the assignees are reminded, by the IDs
+// of the repositories which enabled the due date reminders
+func GetDueDateReminderDaysByRepo() (map[int64]int, error) {
+	days := make(map[int64]int)
+	return days, x.Where("type = ?", UnitTypeIssues).Iterate(new(RepoUnit), func(_ int, bean interface{}) error {
+		unit := bean.(*RepoUnit)
+		if cfg := unit.IssuesConfig(); cfg.EnableDueDateReminders {
+			days[unit.RepoID] = cfg.DueDateReminderLeadDays()
+		}
+		return nil
+	})
+}
+
+// GetIssuesToRemind returns the open issues of a repository due after a time and until another one, whose assignees
+// were not reminded of their due dates yet, the earliest due first
+func GetIssuesToRemind(repoID int64, after, until timeutil.TimeStamp) ([]*Issue, error) {
+	issues := make([]*Issue, 0, 10)
+	return issues, x.Where("repo_id = ?", repoID).
+		And("is_closed = ?", false).
+		And("deadline_unix > ? AND deadline_unix <= ?", after, until).
+		And("NOT EXISTS (SELECT 1 FROM due_date_reminder WHERE due_date_reminder.issue_id = issue.id AND due_date_reminder.deadline_unix = issue.deadline_unix)").
+		Asc("deadline_unix", "id").
+		Find(&issues)
+}
+
+// GetMilestonesToRemind returns the open milestones of a repository due after a time and until another one, whose
+// assignees were not reminded of their due dates yet, the earliest due first
+func GetMilestonesToRemind(repoID int64, after, until timeutil.TimeStamp) ([]*Milestone, error) {
+	milestones := make([]*Milestone, 0, 5)
+	return milestones, x.Where("repo_id = ?", repoID).
+		And("is_closed = ?", false).
+		And("deadline_unix > ? AND deadline_unix <= ?", after, until).
+		And("NOT EXISTS (SELECT 1 FROM due_date_reminder WHERE due_date_reminder.milestone_id = milestone.id AND due_date_reminder.deadline_unix = milestone.deadline_unix)").
+		Asc("deadline_unix", "id").
+		Find(&milestones)
+}
+
+// SetIssueDueDateReminded records that the assignees of an issue were reminded of its current due date
+func SetIssueDueDateReminded(issue *Issue) error {
+	_, err := x.Insert(&DueDateReminder{RepoID: issue.RepoID, IssueID: issue.ID, DeadlineUnix: issue.DeadlineUnix})
+	return err
+}
+
+// SetMilestoneDueDateReminded records that the assignees of a milestone were reminded of its current due date
+func SetMilestoneDueDateReminded(m *Milestone) error {
+	_, err := x.Insert(&DueDateReminder{RepoID: m.RepoID, MilestoneID: m.ID, DeadlineUnix: m.DeadlineUnix})
+	return err
+}
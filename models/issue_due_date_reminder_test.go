@@ -0,0 +1,80 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDueDateReminderDaysByRepo(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	days, err := GetDueDateReminderDaysByRepo()
+	assert.NoError(t, err)
+	assert.Empty(t, days)
+
+	_, err = x.Exec("UPDATE repo_unit SET config = ? WHERE repo_id = ? AND type = ?", `{"EnableTimetracker":true,"EnableDueDateReminders":true}`, 1, UnitTypeIssues)
+	assert.NoError(t, err)
+	_, err = x.Exec("UPDATE repo_unit SET config = ? WHERE repo_id = ? AND type = ?", `{"EnableDueDateReminders":true,"DueDateReminderDays":7}`, 2, UnitTypeIssues)
+	assert.NoError(t, err)
+	days, err = GetDueDateReminderDaysByRepo()
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]int{1: DefaultDueDateReminderDays, 2: 7}, days)
+}
+
+func TestGetIssuesToRemind(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	now := timeutil.TimeStampNow()
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	issue.DeadlineUnix = now + 3600
+	assert.NoError(t, updateIssueCols(x, issue, "deadline_unix"))
+	closed := AssertExistsAndLoadBean(t, &Issue{ID: 5}).(*Issue)
+	closed.DeadlineUnix = now + 3600
+	assert.NoError(t, updateIssueCols(x, closed, "deadline_unix"))
+
+	issues, err := GetIssuesToRemind(1, now, now+86400)
+	assert.NoError(t, err)
+	if assert.Len(t, issues, 1) {
+		assert.EqualValues(t, 1, issues[0].ID)
+	}
+	issues, err = GetIssuesToRemind(1, now+3600, now+86400)
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+
+	assert.NoError(t, SetIssueDueDateReminded(issue))
+	issues, err = GetIssuesToRemind(1, now, now+86400)
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+
+	// the assignees are reminded again of a new due date
+	issue.DeadlineUnix = now + 7200
+	assert.NoError(t, updateIssueCols(x, issue, "deadline_unix"))
+	issues, err = GetIssuesToRemind(1, now, now+86400)
+	assert.NoError(t, err)
+	assert.Len(t, issues, 1)
+}
+
+func TestGetMilestonesToRemind(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	now := timeutil.TimeStampNow()
+	_, err := x.Exec("UPDATE milestone SET deadline_unix = ? WHERE id IN (1, 3)", now+3600)
+	assert.NoError(t, err)
+
+	milestones, err := GetMilestonesToRemind(1, now, now+86400)
+	assert.NoError(t, err)
+	if assert.Len(t, milestones, 1) {
+		assert.EqualValues(t, 1, milestones[0].ID)
+		assert.NoError(t, SetMilestoneDueDateReminded(milestones[0]))
+	}
+	milestones, err = GetMilestonesToRemind(1, now, now+86400)
+	assert.NoError(t, err)
+	assert.Empty(t, milestones)
+}
@@ -0,0 +1,213 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"xorm.io/builder"
+)
+
+// ErrInvalidSubIssue represents a "InvalidSubIssue" kind of error.
+type ErrInvalidSubIssue struct {
+	Reason string
+}
+
+// IsErrInvalidSubIssue checks if an error is a ErrInvalidSubIssue.
+func IsErrInvalidSubIssue(err error) bool {
+	_, ok := err.(ErrInvalidSubIssue)
+	return ok
+}
+
+func (err ErrInvalidSubIssue) Error() string {
+	return fmt.Sprintf("invalid sub-issue: %s", err.Reason)
+}
+
+// ErrCircularSubIssue represents a "CircularSubIssue" kind of error.
+type ErrCircularSubIssue struct {
+	IssueID  int64
+	ParentID int64
+}
+
+// IsErrCircularSubIssue checks if an error is a ErrCircularSubIssue.
+func IsErrCircularSubIssue(err error) bool {
+	_, ok := err.(ErrCircularSubIssue)
+	return ok
+}
+
+func (err ErrCircularSubIssue) Error() string {
+	return fmt.Sprintf("issue is an ancestor of its parent [issue_id: %d, parent_id: %d]", err.IssueID, err.ParentID)
+}
+
+func (issue *Issue) loadParent(e Engine) (err error) {
+	if (issue.Parent == nil || issue.Parent.ID != issue.ParentID) && issue.ParentID > 0 {
+		issue.Parent, err = getIssueByID(e, issue.ParentID)
+		if err != nil && !IsErrIssueNotExist(err) {
+			return fmt.Errorf("getIssueByID [parent_id: %d]: %v", issue.ParentID, err)
+		}
+	}
+	return nil
+}
+
+// LoadParent loads the parent issue of the issue
+func (issue *Issue) LoadParent() error {
+	return issue.loadParent(x)
+}
+
+// checkIssueParent checks an issue can be a sub-issue of a parent: both are issues of the same repository and the
+// issue is not an ancestor of the parent
+func checkIssueParent(e Engine, issue, parent *Issue) error {
+	if issue.IsPull || parent.IsPull {
+		return ErrInvalidSubIssue{"pull requests cannot have a parent nor sub-issues"}
+	}
+	if issue.RepoID != parent.RepoID {
+		return ErrInvalidSubIssue{"both issues must be in the same repository"}
+	}
+	if issue.ID == parent.ID {
+		return ErrCircularSubIssue{issue.ID, parent.ID}
+	}
+
+	visited := map[int64]bool{parent.ID: true}
+	for id := parent.ParentID; id > 0; {
+		if id == issue.ID {
+			return ErrCircularSubIssue{issue.ID, parent.ID}
+		}
+		if visited[id] {
+			break
+		}
+		visited[id] = true
+
+		ancestor := new(Issue)
+		has, err := e.ID(id).Cols("parent_id").Get(ancestor)
+		if err != nil {
+			return err
+		} else if !has {
+			break
+		}
+		id = ancestor.ParentID
+	}
+	return nil
+}
+
+// SetIssueParent makes an issue a sub-issue of a parent issue, a nil parent removing its parent, and adds comments to
+// the issue about the change
+func SetIssueParent(doer *User, issue, parent *Issue) error {
+	var parentID int64
+	if parent != nil {
+		parentID = parent.ID
+	}
+	if issue.ParentID == parentID {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if parent != nil {
+		if err := checkIssueParent(sess, issue, parent); err != nil {
+			return err
+		}
+	}
+	if err := issue.loadRepo(sess); err != nil {
+		return err
+	}
+
+	if issue.ParentID > 0 {
+		if _, err := createComment(sess, &CreateCommentOptions{
+			Type:             CommentTypeRemoveParentIssue,
+			Doer:             doer,
+			Repo:             issue.Repo,
+			Issue:            issue,
+			DependentIssueID: issue.ParentID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	issue.ParentID = parentID
+	issue.Parent = parent
+	if err := updateIssueCols(sess, issue, "parent_id"); err != nil {
+		return err
+	}
+
+	if parent != nil {
+		if _, err := createComment(sess, &CreateCommentOptions{
+			Type:             CommentTypeAddParentIssue,
+			Doer:             doer,
+			Repo:             issue.Repo,
+			Issue:            issue,
+			DependentIssueID: parent.ID,
+		}); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// GetSubIssues returns the direct sub-issues of an issue, ordered by index
+func GetSubIssues(issueID int64) (IssueList, error) {
+	issues := make(IssueList, 0, 10)
+	return issues, x.Where("parent_id = ?", issueID).Asc("`index`").Find(&issues)
+}
+
+// SubIssueNode is an issue of a sub-issue tree with its sub-issues. NumTotal and NumClosed count all the issues below
+// the issue, not only its direct sub-issues.
+type SubIssueNode struct {
+	Issue     *Issue
+	SubIssues []*SubIssueNode
+	NumTotal  int
+	NumClosed int
+}
+
+// Completeness returns the percentage of the issues below the issue which are closed
+func (node *SubIssueNode) Completeness() int {
+	if node.NumTotal == 0 {
+		return 0
+	}
+	return node.NumClosed * 100 / node.NumTotal
+}
+
+func (node *SubIssueNode) countSubIssues() {
+	node.NumTotal, node.NumClosed = 0, 0
+	for _, sub := range node.SubIssues {
+		sub.countSubIssues()
+		node.NumTotal += 1 + sub.NumTotal
+		node.NumClosed += sub.NumClosed
+		if sub.Issue.IsClosed {
+			node.NumClosed++
+		}
+	}
+}
+
+// GetSubIssueTree returns the tree of all the sub-issues below an issue, each level ordered by index
+func GetSubIssueTree(issue *Issue) (*SubIssueNode, error) {
+	root := &SubIssueNode{Issue: issue}
+	nodes := map[int64]*SubIssueNode{issue.ID: root}
+	parentIDs := []int64{issue.ID}
+	for len(parentIDs) > 0 {
+		issues := make(IssueList, 0, len(parentIDs))
+		if err := x.Where(builder.In("parent_id", parentIDs)).Asc("`index`").Find(&issues); err != nil {
+			return nil, err
+		}
+
+		parentIDs = parentIDs[:0]
+		for _, sub := range issues {
+			// the hierarchy has no cycle, but a sub-issue is never listed twice anyway
+			if _, ok := nodes[sub.ID]; ok {
+				continue
+			}
+			node := &SubIssueNode{Issue: sub}
+			nodes[sub.ID] = node
+			nodes[sub.ParentID].SubIssues = append(nodes[sub.ParentID].SubIssues, node)
+			parentIDs = append(parentIDs, sub.ID)
+		}
+	}
+
+	root.countSubIssues()
+	return root, nil
+}
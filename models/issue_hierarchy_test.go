@@ -0,0 +1,64 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetIssueParent(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	// issues 1 and 5 are issues of repo 1, 5 being closed, and issue 2 is a pull request
+	issue1 := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	issue5 := AssertExistsAndLoadBean(t, &Issue{ID: 5}).(*Issue)
+	issue6 := &Issue{RepoID: repo.ID, PosterID: doer.ID, Title: "sub-issue"}
+	assert.NoError(t, NewIssue(repo, issue6, nil, nil))
+
+	assert.NoError(t, SetIssueParent(doer, issue5, issue1))
+	assert.NoError(t, SetIssueParent(doer, issue6, issue5))
+	AssertExistsAndLoadBean(t, &Issue{ID: issue5.ID, ParentID: issue1.ID})
+	AssertExistsAndLoadBean(t, &Comment{IssueID: issue5.ID, Type: CommentTypeAddParentIssue, DependentIssueID: issue1.ID})
+
+	assert.True(t, IsErrCircularSubIssue(SetIssueParent(doer, issue1, issue1)))
+	assert.True(t, IsErrCircularSubIssue(SetIssueParent(doer, issue1, issue6)))
+	pull := AssertExistsAndLoadBean(t, &Issue{ID: 2}).(*Issue)
+	assert.True(t, IsErrInvalidSubIssue(SetIssueParent(doer, pull, issue1)))
+	otherRepoIssue := AssertExistsAndLoadBean(t, &Issue{ID: 4}).(*Issue)
+	assert.True(t, IsErrInvalidSubIssue(SetIssueParent(doer, otherRepoIssue, issue1)))
+
+	subIssues, err := GetSubIssues(issue1.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, subIssues, 1) {
+		assert.EqualValues(t, issue5.ID, subIssues[0].ID)
+	}
+
+	tree, err := GetSubIssueTree(issue1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, tree.NumTotal)
+	assert.Equal(t, 1, tree.NumClosed)
+	if assert.Len(t, tree.SubIssues, 1) && assert.Len(t, tree.SubIssues[0].SubIssues, 1) {
+		assert.EqualValues(t, issue6.ID, tree.SubIssues[0].SubIssues[0].Issue.ID)
+		assert.Equal(t, 1, tree.SubIssues[0].NumTotal)
+		assert.Equal(t, 0, tree.SubIssues[0].NumClosed)
+	}
+
+	// moving an issue to another parent removes it from its former parent
+	assert.NoError(t, SetIssueParent(doer, issue6, issue1))
+	AssertExistsAndLoadBean(t, &Comment{IssueID: issue6.ID, Type: CommentTypeRemoveParentIssue, DependentIssueID: issue5.ID})
+	tree, err = GetSubIssueTree(issue1)
+	assert.NoError(t, err)
+	assert.Len(t, tree.SubIssues, 2)
+	assert.Empty(t, tree.SubIssues[0].SubIssues)
+
+	assert.NoError(t, SetIssueParent(doer, issue5, nil))
+	assert.Zero(t, AssertExistsAndLoadBean(t, &Issue{ID: issue5.ID}).(*Issue).ParentID)
+	assert.NoError(t, issue6.LoadParent())
+	assert.EqualValues(t, issue1.ID, issue6.Parent.ID)
+}
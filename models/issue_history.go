@@ -0,0 +1,93 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// IssueHistory is an issue with the comments which recorded the changes of its state and of its milestone
+type IssueHistory struct {
+	Issue *Issue
+	// Comments are the close, reopen, merge and milestone comments of the issue, oldest first
+	Comments []*Comment
+}
+
+// GetMilestoneIssueHistories returns the histories of the issues and pull requests which are or have been in a milestone
+func GetMilestoneIssueHistories(milestoneID int64) ([]*IssueHistory, error) {
+	issueIDs := make([]int64, 0, 50)
+	if err := x.Table("issue").
+		Where("milestone_id = ?", milestoneID).
+		Cols("id").
+		Find(&issueIDs); err != nil {
+		return nil, fmt.Errorf("find issues: %v", err)
+	}
+	pastIssueIDs := make([]int64, 0, 10)
+	if err := x.Table("comment").
+		Where("type = ? AND (milestone_id = ? OR old_milestone_id = ?)", CommentTypeMilestone, milestoneID, milestoneID).
+		Distinct("issue_id").
+		Find(&pastIssueIDs); err != nil {
+		return nil, fmt.Errorf("find milestone comments: %v", err)
+	}
+	issueIDs = append(issueIDs, pastIssueIDs...)
+	if len(issueIDs) == 0 {
+		return []*IssueHistory{}, nil
+	}
+
+	issues, err := getIssuesByIDs(x, issueIDs)
+	if err != nil {
+		return nil, fmt.Errorf("getIssuesByIDs: %v", err)
+	}
+	histories := make([]*IssueHistory, 0, len(issues))
+	historiesByID := make(map[int64]*IssueHistory, len(issues))
+	for _, issue := range issues {
+		history := &IssueHistory{Issue: issue}
+		histories = append(histories, history)
+		historiesByID[issue.ID] = history
+	}
+
+	comments := make([]*Comment, 0, len(issues)*2)
+	if err := x.In("issue_id", issueIDs).
+		In("type", CommentTypeClose, CommentTypeReopen, CommentTypeMergePull, CommentTypeMilestone).
+		Asc("created_unix", "id").
+		Find(&comments); err != nil {
+		return nil, fmt.Errorf("find comments: %v", err)
+	}
+	for _, comment := range comments {
+		if history, ok := historiesByID[comment.IssueID]; ok {
+			history.Comments = append(history.Comments, comment)
+		}
+	}
+	return histories, nil
+}
+
+// IssueHistoryPoint is the number of open and closed issues of a milestone at a point in time
+type IssueHistoryPoint struct {
+	Time   time.Time
+	Open   int64
+	Closed int64
+}
+
+// Total returns the number of issues in the milestone at the time of the point
+func (p *IssueHistoryPoint) Total() int64 {
+	return p.Open + p.Closed
+}
+
+// MilestoneStats represents the burndown and the velocity of a milestone
+type MilestoneStats struct {
+	Milestone *Milestone
+	From      time.Time
+	Until     time.Time
+
+	// Burndown are the numbers of open and closed issues at the end of each day from From to Until
+	Burndown []*IssueHistoryPoint
+	// Weekly are the numbers of issues closed minus the numbers of issues reopened per week, the first week starts at From
+	Weekly []int64
+	// Velocity is the average number of issues closed per week
+	Velocity float64
+	// ProjectedCompletion is when the open issues will be closed at the current velocity, zero if it can't be projected
+	ProjectedCompletion time.Time
+}
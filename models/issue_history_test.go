@@ -0,0 +1,49 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetMilestoneIssueHistories(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	histories, err := GetMilestoneIssueHistories(1)
+	assert.NoError(t, err)
+	if assert.Len(t, histories, 1) {
+		assert.EqualValues(t, 2, histories[0].Issue.ID)
+		assert.Empty(t, histories[0].Comments)
+	}
+
+	// issue 1 has been moved out of the milestone, then closed
+	for _, comment := range []*Comment{
+		{Type: CommentTypeMilestone, PosterID: 2, IssueID: 1, MilestoneID: 1},
+		{Type: CommentTypeMilestone, PosterID: 2, IssueID: 1, OldMilestoneID: 1, MilestoneID: 0},
+		{Type: CommentTypeComment, PosterID: 2, IssueID: 1, Content: "not part of the history"},
+		{Type: CommentTypeClose, PosterID: 2, IssueID: 1},
+	} {
+		_, err := x.Insert(comment)
+		assert.NoError(t, err)
+	}
+
+	histories, err = GetMilestoneIssueHistories(1)
+	assert.NoError(t, err)
+	if assert.Len(t, histories, 2) {
+		assert.EqualValues(t, 1, histories[0].Issue.ID)
+		if assert.Len(t, histories[0].Comments, 3) {
+			assert.Equal(t, CommentTypeMilestone, histories[0].Comments[0].Type)
+			assert.Equal(t, CommentTypeMilestone, histories[0].Comments[1].Type)
+			assert.Equal(t, CommentTypeClose, histories[0].Comments[2].Type)
+		}
+		assert.EqualValues(t, 2, histories[1].Issue.ID)
+	}
+
+	histories, err = GetMilestoneIssueHistories(NonexistentID)
+	assert.NoError(t, err)
+	assert.Empty(t, histories)
+}
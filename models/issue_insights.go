@@ -0,0 +1,213 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+const insightsWeek = 7 * 24 * time.Hour
+
+// DefaultIssueInsightsPeriod is the period of the insights if no valid period is requested
+const DefaultIssueInsightsPeriod = "quarterly"
+
+// issueInsightsPeriods are the months of the periods the insights can be requested for
+var issueInsightsPeriods = map[string]int{
+	"monthly":    1,
+	"quarterly":  3,
+	"semiyearly": 6,
+	"yearly":     12,
+}
+
+// IssueInsightsPeriodStart returns the start of an insights period which ends now, false if the period is unknown
+func IssueInsightsPeriodStart(period string) (time.Time, bool) {
+	months, ok := issueInsightsPeriods[period]
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Now().AddDate(0, -months, 0), true
+}
+
+// IssueTemplateUsage represents the number of issues created from an issue template
+type IssueTemplateUsage struct {
+	// Template is the file name of the template, empty for issues created without a template
+	Template string
+	Count    int64
+}
+
+// LabelUsage represents how often a label was added to issues
+type LabelUsage struct {
+	Label *Label
+	Total int64
+	// Weekly are the numbers of additions per week, the first week starts at the beginning of the period
+	Weekly []int64
+}
+
+// IssueInsights represents the usage of issue templates and labels and the response times of the issues
+// of a repository within a period
+type IssueInsights struct {
+	From  time.Time
+	Until time.Time
+
+	// NumIssues is the number of issues created within the period
+	NumIssues int64
+	Templates []*IssueTemplateUsage
+	Labels    []*LabelUsage
+
+	// NumResponded is the number of issues created within the period which were commented on by someone other than their poster
+	NumResponded     int64
+	AvgFirstResponse time.Duration
+	// NumClosed is the number of issues closed within the period
+	NumClosed      int64
+	AvgTimeToClose time.Duration
+}
+
+// NumWeeks returns the number of weeks the label usage is counted for
+func (insights *IssueInsights) NumWeeks() int {
+	return int((insights.Until.Sub(insights.From) + insightsWeek - 1) / insightsWeek)
+}
+
+// GetIssueInsights returns the insights into the issues of a repository from the given time until now
+func GetIssueInsights(repoID int64, from time.Time) (*IssueInsights, error) {
+	insights := &IssueInsights{
+		From:  from,
+		Until: time.Now(),
+	}
+	if err := insights.fillTemplates(repoID); err != nil {
+		return nil, fmt.Errorf("fillTemplates: %v", err)
+	}
+	if err := insights.fillLabels(repoID); err != nil {
+		return nil, fmt.Errorf("fillLabels: %v", err)
+	}
+	if err := insights.fillFirstResponses(repoID); err != nil {
+		return nil, fmt.Errorf("fillFirstResponses: %v", err)
+	}
+	if err := insights.fillClosed(repoID); err != nil {
+		return nil, fmt.Errorf("fillClosed: %v", err)
+	}
+	return insights, nil
+}
+
+func (insights *IssueInsights) fillTemplates(repoID int64) error {
+	templates := make([]*IssueTemplateUsage, 0, 5)
+	if err := x.Table("issue").
+		Select("template, COUNT(*) AS count").
+		Where("repo_id = ? AND is_pull = ? AND created_unix >= ?", repoID, false, insights.From.Unix()).
+		GroupBy("template").
+		Find(&templates); err != nil {
+		return err
+	}
+	sort.SliceStable(templates, func(i, j int) bool {
+		if templates[i].Count != templates[j].Count {
+			return templates[i].Count > templates[j].Count
+		}
+		return templates[i].Template < templates[j].Template
+	})
+	for _, template := range templates {
+		insights.NumIssues += template.Count
+	}
+	insights.Templates = templates
+	return nil
+}
+
+func (insights *IssueInsights) fillLabels(repoID int64) error {
+	type labelAddition struct {
+		LabelID     int64
+		CreatedUnix timeutil.TimeStamp
+	}
+	additions := make([]*labelAddition, 0, 50)
+	if err := x.Table("comment").
+		Select("comment.label_id, comment.created_unix").
+		Join("INNER", "issue", "issue.id = comment.issue_id").
+		Where("issue.repo_id = ? AND issue.is_pull = ? AND comment.type = ? AND comment.content = ? AND comment.created_unix >= ?",
+			repoID, false, CommentTypeLabel, "1", insights.From.Unix()).
+		Find(&additions); err != nil {
+		return err
+	}
+
+	numWeeks := insights.NumWeeks()
+	usages := make(map[int64]*LabelUsage)
+	labelIDs := make([]int64, 0, 10)
+	for _, addition := range additions {
+		usage, ok := usages[addition.LabelID]
+		if !ok {
+			usage = &LabelUsage{Weekly: make([]int64, numWeeks)}
+			usages[addition.LabelID] = usage
+			labelIDs = append(labelIDs, addition.LabelID)
+		}
+		usage.Total++
+		week := int(addition.CreatedUnix.AsTime().Sub(insights.From) / insightsWeek)
+		if week >= 0 && week < numWeeks {
+			usage.Weekly[week]++
+		}
+	}
+
+	labels, err := GetLabelsByIDs(labelIDs)
+	if err != nil {
+		return err
+	}
+	insights.Labels = make([]*LabelUsage, 0, len(labels))
+	for _, label := range labels {
+		usage := usages[label.ID]
+		usage.Label = label
+		insights.Labels = append(insights.Labels, usage)
+	}
+	sort.SliceStable(insights.Labels, func(i, j int) bool {
+		if insights.Labels[i].Total != insights.Labels[j].Total {
+			return insights.Labels[i].Total > insights.Labels[j].Total
+		}
+		return insights.Labels[i].Label.Name < insights.Labels[j].Label.Name
+	})
+	return nil
+}
+
+func (insights *IssueInsights) fillFirstResponses(repoID int64) error {
+	type firstResponse struct {
+		CreatedUnix  timeutil.TimeStamp
+		ResponseUnix timeutil.TimeStamp
+	}
+	responses := make([]*firstResponse, 0, 50)
+	if err := x.Table("issue").
+		Select("issue.created_unix, MIN(comment.created_unix) AS response_unix").
+		Join("INNER", "comment", "comment.issue_id = issue.id AND comment.type = ? AND comment.poster_id <> issue.poster_id", CommentTypeComment).
+		Where("issue.repo_id = ? AND issue.is_pull = ? AND issue.created_unix >= ?", repoID, false, insights.From.Unix()).
+		GroupBy("issue.id, issue.created_unix").
+		Find(&responses); err != nil {
+		return err
+	}
+
+	var total int64
+	for _, response := range responses {
+		total += int64(response.ResponseUnix - response.CreatedUnix)
+	}
+	insights.NumResponded = int64(len(responses))
+	if insights.NumResponded > 0 {
+		insights.AvgFirstResponse = time.Duration(total/insights.NumResponded) * time.Second
+	}
+	return nil
+}
+
+func (insights *IssueInsights) fillClosed(repoID int64) error {
+	issues := make([]*Issue, 0, 50)
+	if err := x.Cols("created_unix", "closed_unix").
+		Where("repo_id = ? AND is_pull = ? AND is_closed = ? AND closed_unix >= ?", repoID, false, true, insights.From.Unix()).
+		Find(&issues); err != nil {
+		return err
+	}
+
+	var total int64
+	for _, issue := range issues {
+		total += int64(issue.ClosedUnix - issue.CreatedUnix)
+	}
+	insights.NumClosed = int64(len(issues))
+	if insights.NumClosed > 0 {
+		insights.AvgTimeToClose = time.Duration(total/insights.NumClosed) * time.Second
+	}
+	return nil
+}
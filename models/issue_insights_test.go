@@ -0,0 +1,80 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueInsightsPeriodStart(t *testing.T) {
+	from, ok := IssueInsightsPeriodStart("monthly")
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().AddDate(0, -1, 0), from, time.Minute)
+
+	_, ok = IssueInsightsPeriodStart("daily")
+	assert.False(t, ok)
+}
+
+func TestGetIssueInsights(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	from := time.Now().Add(-2*insightsWeek + time.Hour)
+	insights, err := GetIssueInsights(1, from)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, insights.NumIssues)
+	assert.Len(t, insights.Labels, 0)
+	assert.Equal(t, 2, insights.NumWeeks())
+
+	now := timeutil.TimeStampNow()
+	issues := []*Issue{
+		{RepoID: 1, Index: 101, PosterID: 2, Title: "bug", Template: "bug.md"},
+		{RepoID: 1, Index: 102, PosterID: 2, Title: "bug", Template: "bug.md", IsClosed: true, ClosedUnix: now + 100},
+		{RepoID: 1, Index: 103, PosterID: 2, Title: "other"},
+	}
+	for _, issue := range issues {
+		_, err := x.Insert(issue)
+		assert.NoError(t, err)
+	}
+	for _, comment := range []*Comment{
+		{Type: CommentTypeComment, PosterID: 2, IssueID: issues[0].ID, Content: "by the poster"},
+		{Type: CommentTypeComment, PosterID: 1, IssueID: issues[0].ID, Content: "first response", CreatedUnix: now + 60},
+		{Type: CommentTypeComment, PosterID: 1, IssueID: issues[0].ID, Content: "second response", CreatedUnix: now + 120},
+		{Type: CommentTypeLabel, PosterID: 1, IssueID: issues[0].ID, LabelID: 1, Content: "1"},
+		{Type: CommentTypeLabel, PosterID: 1, IssueID: issues[1].ID, LabelID: 1, Content: "1"},
+		{Type: CommentTypeLabel, PosterID: 1, IssueID: issues[1].ID, LabelID: 1, Content: ""},
+	} {
+		sess := x.NewSession()
+		if comment.CreatedUnix > 0 {
+			sess.NoAutoTime()
+		}
+		_, err := sess.Insert(comment)
+		sess.Close()
+		assert.NoError(t, err)
+	}
+
+	insights, err = GetIssueInsights(1, from)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, insights.NumIssues)
+	if assert.Len(t, insights.Templates, 2) {
+		assert.Equal(t, "bug.md", insights.Templates[0].Template)
+		assert.EqualValues(t, 2, insights.Templates[0].Count)
+		assert.Equal(t, "", insights.Templates[1].Template)
+		assert.EqualValues(t, 1, insights.Templates[1].Count)
+	}
+	if assert.Len(t, insights.Labels, 1) {
+		assert.EqualValues(t, 1, insights.Labels[0].Label.ID)
+		assert.EqualValues(t, 2, insights.Labels[0].Total)
+		assert.Equal(t, []int64{0, 2}, insights.Labels[0].Weekly)
+	}
+	assert.EqualValues(t, 1, insights.NumResponded)
+	assert.InDelta(t, 60, insights.AvgFirstResponse.Seconds(), 2)
+	assert.EqualValues(t, 1, insights.NumClosed)
+	assert.InDelta(t, 100, insights.AvgTimeToClose.Seconds(), 2)
+}
@@ -480,6 +480,10 @@ func (issues IssueList) loadAttributes(e Engine) error {
 		return fmt.Errorf("issue.loadAttributes: loadMilestones: %v", err)
 	}
 
+	if err := issues.loadTypes(e); err != nil {
+		return fmt.Errorf("issue.loadAttributes: loadTypes: %v", err)
+	}
+
 	if err := issues.loadAssignees(e); err != nil {
 		return fmt.Errorf("issue.loadAttributes: loadAssignees: %v", err)
 	}
@@ -547,3 +551,27 @@ func (issues IssueList) getApprovalCounts(e Engine) (map[int64][]*ReviewCount, e
 
 	return approvalCountMap, nil
 }
+
+// FilterVisibleTo returns the issues of the list which the user can read
+func (issues IssueList) FilterVisibleTo(user *User) (IssueList, error) {
+	if _, err := issues.loadRepositories(x); err != nil {
+		return nil, err
+	}
+
+	perms := make(map[int64]Permission)
+	visible := make(IssueList, 0, len(issues))
+	for _, issue := range issues {
+		perm, ok := perms[issue.RepoID]
+		if !ok {
+			var err error
+			if perm, err = GetUserRepoPermission(issue.Repo, user); err != nil {
+				return nil, err
+			}
+			perms[issue.RepoID] = perm
+		}
+		if perm.CanReadIssuesOrPulls(issue.IsPull) {
+			visible = append(visible, issue)
+		}
+	}
+	return visible, nil
+}
@@ -69,3 +69,23 @@ func TestIssueList_LoadAttributes(t *testing.T) {
 		}
 	}
 }
+
+func TestIssueList_FilterVisibleTo(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issueList := IssueList{
+		AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue),
+		AssertExistsAndLoadBean(t, &Issue{ID: 4}).(*Issue),
+	}
+
+	// issue 4 is in a private repository of user 2
+	visible, err := issueList.FilterVisibleTo(nil)
+	assert.NoError(t, err)
+	if assert.Len(t, visible, 1) {
+		assert.EqualValues(t, 1, visible[0].ID)
+	}
+
+	visible, err = issueList.FilterVisibleTo(AssertExistsAndLoadBean(t, &User{ID: 2}).(*User))
+	assert.NoError(t, err)
+	assert.Len(t, visible, 2)
+}
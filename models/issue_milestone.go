@@ -39,6 +39,8 @@ type Milestone struct {
 	DeadlineString string `xorm:"-"`
 
 	TotalTrackedTime int64 `xorm:"-"`
+	TotalWeight      int64 `xorm:"-"`
+	ClosedWeight     int64 `xorm:"-"`
 	TimeSinceUpdate  int64 `xorm:"-"`
 }
 
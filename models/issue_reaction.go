@@ -7,6 +7,7 @@ package models
 import (
 	"bytes"
 	"fmt"
+	"sort"
 
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
@@ -335,3 +336,65 @@ func (list ReactionList) GetMoreUserCount() int {
 	}
 	return len(list) - setting.UI.ReactionMaxUserNum
 }
+
+// ReactionCount is the number of reactions of a type on an issue or a pull request and on its comments
+type ReactionCount struct {
+	Type  string
+	Issue int64
+	// Comments counts the reactions on the comments other than the code review comments and the review summaries
+	Comments       int64
+	ReviewComments int64
+}
+
+// Total returns the number of reactions of the type on the issue and on all its comments
+func (c *ReactionCount) Total() int64 {
+	return c.Issue + c.Comments + c.ReviewComments
+}
+
+// GetIssueReactionCounts returns the number of reactions of each allowed type on an issue and on its comments, the
+// most used type first
+func GetIssueReactionCounts(issueID int64) ([]*ReactionCount, error) {
+	type reactionCount struct {
+		ReactionType string
+		// CommentType is -1 for the reactions on the issue itself
+		CommentType CommentType
+		Count       int64
+	}
+	counts := make([]*reactionCount, 0, 10)
+	if err := x.Table("reaction").
+		Join("LEFT", "comment", "comment.id = reaction.comment_id").
+		Select("reaction.type AS reaction_type, COALESCE(comment.type, -1) AS comment_type, COUNT(*) AS count").
+		Where(builder.Eq{"reaction.issue_id": issueID}).
+		And(builder.Eq{"reaction.comment_id": 0}.Or(builder.NotNull{"comment.id"})).
+		In("reaction.`type`", setting.UI.Reactions).
+		GroupBy("reaction.type, comment.type").
+		Find(&counts); err != nil {
+		return nil, err
+	}
+
+	byType := make(map[string]*ReactionCount, len(counts))
+	reactionCounts := make([]*ReactionCount, 0, len(counts))
+	for _, count := range counts {
+		reactionCount, ok := byType[count.ReactionType]
+		if !ok {
+			reactionCount = &ReactionCount{Type: count.ReactionType}
+			byType[count.ReactionType] = reactionCount
+			reactionCounts = append(reactionCounts, reactionCount)
+		}
+		switch count.CommentType {
+		case -1:
+			reactionCount.Issue += count.Count
+		case CommentTypeCode, CommentTypeReview:
+			reactionCount.ReviewComments += count.Count
+		default:
+			reactionCount.Comments += count.Count
+		}
+	}
+	sort.SliceStable(reactionCounts, func(i, j int) bool {
+		if reactionCounts[i].Total() != reactionCounts[j].Total() {
+			return reactionCounts[i].Total() > reactionCounts[j].Total()
+		}
+		return reactionCounts[i].Type < reactionCounts[j].Type
+	})
+	return reactionCounts, nil
+}
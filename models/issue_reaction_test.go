@@ -164,3 +164,30 @@ func TestIssueCommentReactionCount(t *testing.T) {
 
 	AssertNotExistsBean(t, &Reaction{Type: "heart", UserID: user1.ID, IssueID: issue1.ID, CommentID: comment1.ID})
 }
+
+func TestGetIssueReactionCounts(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// issue 1 has a reaction eyes and two reactions laugh on comment 2, reactions zzz are no longer allowed
+	counts, err := GetIssueReactionCounts(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []*ReactionCount{
+		{Type: "laugh", Comments: 2},
+		{Type: "eyes", Issue: 1},
+	}, counts)
+
+	user1 := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	pull := AssertExistsAndLoadBean(t, &Issue{ID: 2}).(*Issue)
+	codeComment := AssertExistsAndLoadBean(t, &Comment{ID: 4}).(*Comment)
+	addReaction(t, user1, pull, nil, "heart")
+	addReaction(t, user1, pull, codeComment, "heart")
+	addReaction(t, user1, pull, codeComment, "+1")
+
+	counts, err = GetIssueReactionCounts(pull.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, counts, 2) {
+		assert.Equal(t, ReactionCount{Type: "heart", Issue: 1, ReviewComments: 1}, *counts[0])
+		assert.EqualValues(t, 2, counts[0].Total())
+		assert.Equal(t, ReactionCount{Type: "+1", ReviewComments: 1}, *counts[1])
+	}
+}
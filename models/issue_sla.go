@@ -0,0 +1,340 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// IssueSLA is a service level agreement for the triage of the issues of a repository: the targets for the first
+// response to and the resolution of its issues, or of its issues with a label. Only the issues created after the SLA
+// are held to its targets. The breach label is added by the user who last saved the SLA.
+type IssueSLA struct {
+	ID     int64  `xorm:"pk autoincr"`
+	RepoID int64  `xorm:"INDEX NOT NULL"`
+	DoerID int64  `xorm:"NOT NULL"`
+	Doer   *User  `xorm:"-"`
+	Name   string `xorm:"NOT NULL"`
+	// LabelID restricts the SLA to the issues with the label, 0 for all the issues
+	LabelID int64  `xorm:"NOT NULL DEFAULT 0"`
+	Label   *Label `xorm:"-"`
+	// FirstResponseHours is the target for the first comment by someone other than the poster, 0 for none
+	FirstResponseHours int64 `xorm:"NOT NULL DEFAULT 0"`
+	// ResolutionHours is the target for closing an issue, 0 for none
+	ResolutionHours int64 `xorm:"NOT NULL DEFAULT 0"`
+	// BreachLabelID is the label added to the issues which breach a target, 0 for none
+	BreachLabelID int64  `xorm:"NOT NULL DEFAULT 0"`
+	BreachLabel   *Label `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// MaxIssueSLATargetHours is the longest target of an SLA, a year
+const MaxIssueSLATargetHours = 8760
+
+// The targets of an SLA an issue can breach
+const (
+	IssueSLATargetFirstResponse = "first_response"
+	IssueSLATargetResolution    = "resolution"
+)
+
+// IssueSLABreach records that an issue breached a target of an SLA, for an issue to be flagged once per target
+type IssueSLABreach struct {
+	ID          int64              `xorm:"pk autoincr"`
+	SLAID       int64              `xorm:"'sla_id' UNIQUE(s) NOT NULL"`
+	RepoID      int64              `xorm:"INDEX NOT NULL"`
+	IssueID     int64              `xorm:"UNIQUE(s) NOT NULL"`
+	Target      string             `xorm:"VARCHAR(20) UNIQUE(s) NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// ErrIssueSLANotExist represents a "IssueSLANotExist" kind of error.
+type ErrIssueSLANotExist struct {
+	ID int64
+}
+
+// IsErrIssueSLANotExist checks if an error is a ErrIssueSLANotExist.
+func IsErrIssueSLANotExist(err error) bool {
+	_, ok := err.(ErrIssueSLANotExist)
+	return ok
+}
+
+func (err ErrIssueSLANotExist) Error() string {
+	return fmt.Sprintf("issue SLA does not exist [id: %d]", err.ID)
+}
+
+// ErrInvalidIssueSLA represents a "InvalidIssueSLA" kind of error.
+type ErrInvalidIssueSLA struct {
+	Reason string
+}
+
+// IsErrInvalidIssueSLA checks if an error is a ErrInvalidIssueSLA.
+func IsErrInvalidIssueSLA(err error) bool {
+	_, ok := err.(ErrInvalidIssueSLA)
+	return ok
+}
+
+func (err ErrInvalidIssueSLA) Error() string {
+	return fmt.Sprintf("invalid issue SLA: %s", err.Reason)
+}
+
+// LoadAttributes loads the labels of the SLA and the user who adds the breach label, a ghost user if it has been
+// deleted. A label which has been deleted is left nil.
+func (sla *IssueSLA) LoadAttributes() (err error) {
+	if sla.Doer == nil {
+		if sla.Doer, err = GetUserByID(sla.DoerID); err != nil {
+			if !IsErrUserNotExist(err) {
+				return err
+			}
+			sla.Doer = NewGhostUser()
+		}
+	}
+	if sla.Label == nil && sla.LabelID > 0 {
+		if sla.Label, err = GetLabelByID(sla.LabelID); err != nil && !IsErrLabelNotExist(err) {
+			return err
+		}
+	}
+	if sla.BreachLabel == nil && sla.BreachLabelID > 0 {
+		if sla.BreachLabel, err = GetLabelByID(sla.BreachLabelID); err != nil && !IsErrLabelNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateIssueSLALabel checks that a label can be used by the issues of a repository
+func validateIssueSLALabel(repo *Repository, labelID int64) error {
+	if labelID == 0 {
+		return nil
+	}
+	label, err := GetLabelByID(labelID)
+	if err != nil {
+		if IsErrLabelNotExist(err) {
+			return ErrInvalidIssueSLA{fmt.Sprintf("the label %d does not exist", labelID)}
+		}
+		return err
+	}
+	if label.RepoID != repo.ID && (label.OrgID == 0 || label.OrgID != repo.OwnerID) {
+		return ErrInvalidIssueSLA{fmt.Sprintf("the label %q cannot be used in this repository", label.Name)}
+	}
+	return nil
+}
+
+func (sla *IssueSLA) validate() error {
+	sla.Name = strings.TrimSpace(sla.Name)
+	if len(sla.Name) == 0 {
+		return ErrInvalidIssueSLA{"the name is empty"}
+	}
+	if sla.FirstResponseHours < 0 || sla.ResolutionHours < 0 {
+		return ErrInvalidIssueSLA{"the targets cannot be negative"}
+	}
+	if sla.FirstResponseHours > MaxIssueSLATargetHours || sla.ResolutionHours > MaxIssueSLATargetHours {
+		return ErrInvalidIssueSLA{fmt.Sprintf("the targets cannot exceed %d hours", MaxIssueSLATargetHours)}
+	}
+	if sla.FirstResponseHours == 0 && sla.ResolutionHours == 0 {
+		return ErrInvalidIssueSLA{"there are no targets"}
+	}
+	repo, err := GetRepositoryByID(sla.RepoID)
+	if err != nil {
+		return err
+	}
+	if err := validateIssueSLALabel(repo, sla.LabelID); err != nil {
+		return err
+	}
+	return validateIssueSLALabel(repo, sla.BreachLabelID)
+}
+
+// NewIssueSLA creates an SLA for the issues of a repository
+func NewIssueSLA(sla *IssueSLA) error {
+	if err := sla.validate(); err != nil {
+		return err
+	}
+	_, err := x.Insert(sla)
+	return err
+}
+
+// UpdateIssueSLA updates an SLA, the issues which already breached its targets being flagged once only
+func UpdateIssueSLA(sla *IssueSLA) error {
+	if err := sla.validate(); err != nil {
+		return err
+	}
+	_, err := x.ID(sla.ID).Cols("doer_id", "name", "label_id", "first_response_hours", "resolution_hours", "breach_label_id").Update(sla)
+	return err
+}
+
+// DeleteIssueSLA deletes an SLA of a repository with its breaches, the breach labels being kept on the issues
+func DeleteIssueSLA(repoID, id int64) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	deleted, err := sess.Where("repo_id = ?", repoID).And("id = ?", id).Delete(new(IssueSLA))
+	if err != nil {
+		return err
+	} else if deleted == 0 {
+		return ErrIssueSLANotExist{id}
+	}
+	if _, err := sess.Where("sla_id = ?", id).Delete(new(IssueSLABreach)); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// GetIssueSLAInRepoByID returns an SLA of a repository
+func GetIssueSLAInRepoByID(repoID, id int64) (*IssueSLA, error) {
+	sla := new(IssueSLA)
+	has, err := x.Where("repo_id = ?", repoID).And("id = ?", id).Get(sla)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrIssueSLANotExist{id}
+	}
+	return sla, nil
+}
+
+// GetIssueSLAsByRepoID returns the SLAs of a repository, ordered by name
+func GetIssueSLAsByRepoID(repoID int64) ([]*IssueSLA, error) {
+	slas := make([]*IssueSLA, 0, 5)
+	return slas, x.Where("repo_id = ?", repoID).Asc("name", "id").Find(&slas)
+}
+
+// GetAllIssueSLAs returns the SLAs of all the repositories
+func GetAllIssueSLAs() ([]*IssueSLA, error) {
+	slas := make([]*IssueSLA, 0, 10)
+	return slas, x.Asc("repo_id", "id").Find(&slas)
+}
+
+// issuesCond returns the condition on the issues held to the targets of the SLA
+func (sla *IssueSLA) issuesCond() builder.Cond {
+	cond := builder.Eq{"issue.repo_id": sla.RepoID, "issue.is_pull": false}.
+		And(builder.Gte{"issue.created_unix": sla.CreatedUnix})
+	if sla.LabelID > 0 {
+		cond = cond.And(builder.In("issue.id", builder.Select("issue_id").From("issue_label").Where(builder.Eq{"label_id": sla.LabelID})))
+	}
+	return cond
+}
+
+// targetSeconds returns the number of seconds of a target of the SLA, 0 if the SLA has no such target
+func (sla *IssueSLA) targetSeconds(target string) int64 {
+	if target == IssueSLATargetFirstResponse {
+		return sla.FirstResponseHours * 3600
+	}
+	return sla.ResolutionHours * 3600
+}
+
+// issueSLAMetCond returns the condition on the issues which met a target of the SLA of the given number of seconds
+func issueSLAMetCond(target string, seconds int64) builder.Cond {
+	closedInTime := builder.Expr("(issue.is_closed = ? AND issue.closed_unix <= issue.created_unix + ?)", true, seconds)
+	if target == IssueSLATargetResolution {
+		return closedInTime
+	}
+	respondedInTime := builder.Expr("EXISTS (SELECT 1 FROM comment WHERE comment.issue_id = issue.id AND comment.type = ? AND comment.poster_id <> issue.poster_id AND comment.created_unix <= issue.created_unix + ?)",
+		CommentTypeComment, seconds)
+	// an issue closed before anyone responded needs no response
+	return builder.Or(respondedInTime, closedInTime)
+}
+
+// FindNewIssueSLABreaches returns the issues which breached a target of the SLA at a time and were not flagged yet,
+// the oldest first
+func (sla *IssueSLA) FindNewIssueSLABreaches(target string, now time.Time) ([]*Issue, error) {
+	seconds := sla.targetSeconds(target)
+	if seconds <= 0 {
+		return nil, nil
+	}
+	issues := make([]*Issue, 0, 10)
+	return issues, x.Where(sla.issuesCond()).
+		And("issue.created_unix + ? <= ?", seconds, now.Unix()).
+		And(builder.Not{issueSLAMetCond(target, seconds)}).
+		And(builder.NotIn("issue.id", builder.Select("issue_id").From("issue_sla_breach").Where(builder.Eq{"sla_id": sla.ID, "target": target}))).
+		Asc("issue.created_unix", "issue.id").
+		Find(&issues)
+}
+
+// NewIssueSLABreach records that an issue breached a target of the SLA
+func (sla *IssueSLA) NewIssueSLABreach(issue *Issue, target string) error {
+	_, err := x.Insert(&IssueSLABreach{SLAID: sla.ID, RepoID: sla.RepoID, IssueID: issue.ID, Target: target})
+	return err
+}
+
+// IssueSLATargetCompliance represents how the issues met a target of an SLA
+type IssueSLATargetCompliance struct {
+	Met      int64
+	Breached int64
+	// Pending is the number of open issues whose target is not due yet
+	Pending int64
+}
+
+// Percent returns the percentage of the issues which met the target among those which met or breached it, 100 if
+// there are none
+func (c *IssueSLATargetCompliance) Percent() int64 {
+	if c.Met+c.Breached == 0 {
+		return 100
+	}
+	return c.Met * 100 / (c.Met + c.Breached)
+}
+
+// IssueSLACompliance represents how the issues created within a period met the targets of an SLA
+type IssueSLACompliance struct {
+	SLA           *IssueSLA
+	NumIssues     int64
+	FirstResponse IssueSLATargetCompliance
+	Resolution    IssueSLATargetCompliance
+	// Breaching are the open issues which breached a target of the SLA, whenever they were created
+	Breaching []*Issue
+}
+
+// countTarget counts the issues created within a period which met, breached or are still pending a target of the SLA
+func (sla *IssueSLA) countTarget(compliance *IssueSLATargetCompliance, target string, from, now time.Time) (err error) {
+	seconds := sla.targetSeconds(target)
+	if seconds <= 0 {
+		return nil
+	}
+	cond := sla.issuesCond().And(builder.Gte{"issue.created_unix": from.Unix()})
+	metCond := issueSLAMetCond(target, seconds)
+	if compliance.Met, err = x.Where(cond).And(metCond).Count(new(Issue)); err != nil {
+		return err
+	}
+	if compliance.Breached, err = x.Where(cond).And(builder.Not{metCond}).
+		And("issue.created_unix + ? <= ?", seconds, now.Unix()).Count(new(Issue)); err != nil {
+		return err
+	}
+	compliance.Pending, err = x.Where(cond).And(builder.Not{metCond}).
+		And("issue.created_unix + ? > ?", seconds, now.Unix()).Count(new(Issue))
+	return err
+}
+
+// GetIssueSLACompliance returns how the issues created from a time until now met the targets of the SLA
+func (sla *IssueSLA) GetIssueSLACompliance(from time.Time) (*IssueSLACompliance, error) {
+	now := time.Now()
+	compliance := &IssueSLACompliance{SLA: sla}
+	var err error
+	if compliance.NumIssues, err = x.Where(sla.issuesCond()).And(builder.Gte{"issue.created_unix": from.Unix()}).Count(new(Issue)); err != nil {
+		return nil, fmt.Errorf("count issues: %v", err)
+	}
+	if err := sla.countTarget(&compliance.FirstResponse, IssueSLATargetFirstResponse, from, now); err != nil {
+		return nil, fmt.Errorf("count first responses: %v", err)
+	}
+	if err := sla.countTarget(&compliance.Resolution, IssueSLATargetResolution, from, now); err != nil {
+		return nil, fmt.Errorf("count resolutions: %v", err)
+	}
+
+	compliance.Breaching = make([]*Issue, 0, 10)
+	if err := x.Where(builder.Eq{"is_closed": false}).
+		And(builder.In("id", builder.Select("issue_id").From("issue_sla_breach").Where(builder.Eq{"sla_id": sla.ID}))).
+		Asc("created_unix", "id").
+		Find(&compliance.Breaching); err != nil {
+		return nil, fmt.Errorf("find breaching issues: %v", err)
+	}
+	return compliance, nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueSLAValidation(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.True(t, IsErrInvalidIssueSLA(NewIssueSLA(&IssueSLA{RepoID: 1, DoerID: 2, Name: " ", FirstResponseHours: 48})))
+	assert.True(t, IsErrInvalidIssueSLA(NewIssueSLA(&IssueSLA{RepoID: 1, DoerID: 2, Name: "Bugs"})))
+	assert.True(t, IsErrInvalidIssueSLA(NewIssueSLA(&IssueSLA{RepoID: 1, DoerID: 2, Name: "Bugs", ResolutionHours: -1})))
+	assert.True(t, IsErrInvalidIssueSLA(NewIssueSLA(&IssueSLA{RepoID: 1, DoerID: 2, Name: "Bugs", ResolutionHours: MaxIssueSLATargetHours + 1})))
+	// label 5 belongs to repo 10, label 3 to org 3
+	assert.True(t, IsErrInvalidIssueSLA(NewIssueSLA(&IssueSLA{RepoID: 1, DoerID: 2, Name: "Bugs", FirstResponseHours: 48, LabelID: 5})))
+	assert.True(t, IsErrInvalidIssueSLA(NewIssueSLA(&IssueSLA{RepoID: 1, DoerID: 2, Name: "Bugs", FirstResponseHours: 48, BreachLabelID: 3})))
+
+	sla := &IssueSLA{RepoID: 1, DoerID: 2, Name: " Bugs ", FirstResponseHours: 48, LabelID: 1, BreachLabelID: 2}
+	assert.NoError(t, NewIssueSLA(sla))
+	assert.Equal(t, "Bugs", sla.Name)
+	assert.NoError(t, sla.LoadAttributes())
+	assert.Equal(t, "label1", sla.Label.Name)
+	assert.Equal(t, "label2", sla.BreachLabel.Name)
+
+	sla.ResolutionHours = 24
+	assert.NoError(t, UpdateIssueSLA(sla))
+	sla, err := GetIssueSLAInRepoByID(1, sla.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 24, sla.ResolutionHours)
+
+	_, err = GetIssueSLAInRepoByID(2, sla.ID)
+	assert.True(t, IsErrIssueSLANotExist(err))
+	assert.True(t, IsErrIssueSLANotExist(DeleteIssueSLA(2, sla.ID)))
+	assert.NoError(t, DeleteIssueSLA(1, sla.ID))
+	AssertNotExistsBean(t, &IssueSLA{ID: sla.ID})
+}
+
+func TestIssueSLABreaches(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	sla := &IssueSLA{RepoID: 1, DoerID: 2, Name: "Bugs", LabelID: 1, FirstResponseHours: 1, ResolutionHours: 24}
+	assert.NoError(t, NewIssueSLA(sla))
+	now := time.Now()
+
+	// the issues created before the SLA are not held to its targets
+	issues, err := sla.FindNewIssueSLABreaches(IssueSLATargetResolution, now)
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+
+	// issue 1 with label 1 was commented on by user 3 seconds after its creation by user 1, and is still open
+	_, err = x.Exec("UPDATE issue_sla SET created_unix = 0 WHERE id = ?", sla.ID)
+	assert.NoError(t, err)
+	sla.CreatedUnix = 0
+	issues, err = sla.FindNewIssueSLABreaches(IssueSLATargetFirstResponse, now)
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+	issues, err = sla.FindNewIssueSLABreaches(IssueSLATargetResolution, now)
+	assert.NoError(t, err)
+	if assert.Len(t, issues, 1) {
+		assert.EqualValues(t, 1, issues[0].ID)
+		assert.NoError(t, sla.NewIssueSLABreach(issues[0], IssueSLATargetResolution))
+	}
+	issues, err = sla.FindNewIssueSLABreaches(IssueSLATargetResolution, now)
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+
+	compliance, err := sla.GetIssueSLACompliance(time.Unix(0, 0))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, compliance.NumIssues)
+	assert.Equal(t, IssueSLATargetCompliance{Met: 1}, compliance.FirstResponse)
+	assert.Equal(t, IssueSLATargetCompliance{Breached: 1}, compliance.Resolution)
+	assert.EqualValues(t, 100, compliance.FirstResponse.Percent())
+	assert.EqualValues(t, 0, compliance.Resolution.Percent())
+	if assert.Len(t, compliance.Breaching, 1) {
+		assert.EqualValues(t, 1, compliance.Breaching[0].ID)
+	}
+
+	// the issues created within the period only are counted
+	compliance, err = sla.GetIssueSLACompliance(now)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, compliance.NumIssues)
+	assert.EqualValues(t, 100, compliance.Resolution.Percent())
+	assert.Len(t, compliance.Breaching, 1)
+}
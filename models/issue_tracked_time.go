@@ -83,10 +83,10 @@ type FindTrackedTimesOptions struct {
 func (opts *FindTrackedTimesOptions) ToCond() builder.Cond {
 	cond := builder.NewCond().And(builder.Eq{"tracked_time.deleted": false})
 	if opts.IssueID != 0 {
-		cond = cond.And(builder.Eq{"issue_id": opts.IssueID})
+		cond = cond.And(builder.Eq{"tracked_time.issue_id": opts.IssueID})
 	}
 	if opts.UserID != 0 {
-		cond = cond.And(builder.Eq{"user_id": opts.UserID})
+		cond = cond.And(builder.Eq{"tracked_time.user_id": opts.UserID})
 	}
 	if opts.RepositoryID != 0 {
 		cond = cond.And(builder.Eq{"issue.repo_id": opts.RepositoryID})
@@ -0,0 +1,222 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// The lengths of the periods the tracked times of a report can be grouped by
+const (
+	TrackedTimeReportDaily   = "daily"
+	TrackedTimeReportWeekly  = "weekly"
+	TrackedTimeReportMonthly = "monthly"
+)
+
+// DefaultTrackedTimeReportDays is the number of days a report covers if no start is requested
+const DefaultTrackedTimeReportDays = 30
+
+// IsValidTrackedTimeReportGroup returns whether the tracked times of a report can be grouped by periods of the given length
+func IsValidTrackedTimeReportGroup(group string) bool {
+	return group == TrackedTimeReportDaily || group == TrackedTimeReportWeekly || group == TrackedTimeReportMonthly
+}
+
+// TrackedTimeReportRange parses the dates of a report in the format yyyy-mm-dd, from the start of its first day until
+// the end of its last day. The report covers the last days until now if the dates are missing or invalid.
+func TrackedTimeReportRange(fromDate, untilDate string) (from, until time.Time) {
+	now := time.Now().In(setting.DefaultUILocation)
+	until = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, setting.DefaultUILocation)
+	if t, err := time.ParseInLocation("2006-01-02", untilDate, setting.DefaultUILocation); err == nil {
+		until = t
+	}
+	from = until.AddDate(0, 0, 1-DefaultTrackedTimeReportDays)
+	if t, err := time.ParseInLocation("2006-01-02", fromDate, setting.DefaultUILocation); err == nil && !t.After(until) {
+		from = t
+	}
+	return from, until.AddDate(0, 0, 1).Add(-time.Second)
+}
+
+// trackedTimeReportPeriodStart returns the start of the period of the given length a time belongs to,
+// weeks starting on Monday
+func trackedTimeReportPeriodStart(t time.Time, group string) time.Time {
+	t = t.In(setting.DefaultUILocation)
+	switch group {
+	case TrackedTimeReportWeekly:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, setting.DefaultUILocation)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case TrackedTimeReportMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, setting.DefaultUILocation)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, setting.DefaultUILocation)
+	}
+}
+
+// TrackedTimeReportPeriod represents the tracked times of a period of a report
+type TrackedTimeReportPeriod struct {
+	Start time.Time
+	Times TrackedTimeList
+	// Total is the sum of the times in seconds
+	Total int64
+}
+
+// TrackedTimeReport represents the tracked times within a date range, grouped by periods
+type TrackedTimeReport struct {
+	From  time.Time
+	Until time.Time
+	Group string
+
+	// Periods are the periods which have tracked times, oldest first
+	Periods []*TrackedTimeReportPeriod
+	// Total is the sum of the times in seconds
+	Total int64
+}
+
+// Times returns the tracked times of all the periods of the report, oldest first
+func (report *TrackedTimeReport) Times() TrackedTimeList {
+	times := make(TrackedTimeList, 0, len(report.Periods))
+	for _, period := range report.Periods {
+		times = append(times, period.Times...)
+	}
+	return times
+}
+
+// GetTrackedTimeReport returns the tracked times matching the options within a date range, grouped by periods of the
+// given length, with their issues and users loaded
+func GetTrackedTimeReport(opts FindTrackedTimesOptions, from, until time.Time, group string) (*TrackedTimeReport, error) {
+	opts.ListOptions = ListOptions{}
+	opts.CreatedAfterUnix = from.Unix()
+	opts.CreatedBeforeUnix = until.Unix()
+
+	times := make(TrackedTimeList, 0, 50)
+	if err := opts.ToSession(x).Asc("tracked_time.created_unix", "tracked_time.id").Find(&times); err != nil {
+		return nil, fmt.Errorf("find tracked times: %v", err)
+	}
+	for _, t := range times {
+		if err := t.loadAttributes(x); err != nil {
+			if !IsErrUserNotExist(err) {
+				return nil, fmt.Errorf("loadAttributes: %v", err)
+			}
+			// the times of deleted users are kept
+			t.User = NewGhostUser()
+		}
+	}
+
+	report := &TrackedTimeReport{
+		From:    from,
+		Until:   until,
+		Group:   group,
+		Periods: make([]*TrackedTimeReportPeriod, 0, 10),
+	}
+	var period *TrackedTimeReportPeriod
+	for _, t := range times {
+		start := trackedTimeReportPeriodStart(t.Created, group)
+		if period == nil || !period.Start.Equal(start) {
+			period = &TrackedTimeReportPeriod{Start: start}
+			report.Periods = append(report.Periods, period)
+		}
+		period.Times = append(period.Times, t)
+		period.Total += t.Time
+		report.Total += t.Time
+	}
+	return report, nil
+}
+
+// The fields the sums of tracked times can be grouped by
+const (
+	TrackedTimeSumByIssue     = "issue"
+	TrackedTimeSumByLabel     = "label"
+	TrackedTimeSumByMilestone = "milestone"
+)
+
+// TrackedTimeSum represents the sum of the tracked times of an issue, a label or a milestone
+type TrackedTimeSum struct {
+	// ID is the ID of the issue, the label or the milestone, 0 for the times of the issues without a label or a milestone
+	ID int64
+	// Time is the sum of the times in seconds
+	Time int64
+
+	Issue     *Issue     `xorm:"-"`
+	Label     *Label     `xorm:"-"`
+	Milestone *Milestone `xorm:"-"`
+}
+
+// SumTrackedTimes returns the sums of the tracked times matching the options by issue, label or milestone, the biggest
+// first. The times of an issue with several labels count for each of its labels.
+func SumTrackedTimes(opts FindTrackedTimesOptions, by string) ([]*TrackedTimeSum, error) {
+	sess := x.Table("tracked_time").Join("INNER", "issue", "issue.id = tracked_time.issue_id")
+	var col string
+	switch by {
+	case TrackedTimeSumByIssue:
+		col = "tracked_time.issue_id"
+	case TrackedTimeSumByMilestone:
+		col = "issue.milestone_id"
+	case TrackedTimeSumByLabel:
+		sess = sess.Join("LEFT", "issue_label", "issue_label.issue_id = tracked_time.issue_id")
+		col = "issue_label.label_id"
+	default:
+		return nil, fmt.Errorf("unknown field to sum tracked times by: %s", by)
+	}
+
+	sums := make([]*TrackedTimeSum, 0, 10)
+	if err := sess.Where(opts.ToCond()).
+		Select("COALESCE(" + col + ", 0) AS id, SUM(tracked_time.time) AS time").
+		GroupBy(col).
+		Desc("time").
+		Find(&sums); err != nil {
+		return nil, fmt.Errorf("sum tracked times: %v", err)
+	}
+
+	ids := make([]int64, 0, len(sums))
+	for _, sum := range sums {
+		if sum.ID != 0 {
+			ids = append(ids, sum.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return sums, nil
+	}
+	switch by {
+	case TrackedTimeSumByIssue:
+		issues, err := getIssuesByIDs(x, ids)
+		if err != nil {
+			return nil, fmt.Errorf("getIssuesByIDs: %v", err)
+		}
+		issuesByID := make(map[int64]*Issue, len(issues))
+		for _, issue := range issues {
+			issuesByID[issue.ID] = issue
+		}
+		for _, sum := range sums {
+			sum.Issue = issuesByID[sum.ID]
+		}
+	case TrackedTimeSumByMilestone:
+		milestones := make([]*Milestone, 0, len(ids))
+		if err := x.In("id", ids).Find(&milestones); err != nil {
+			return nil, fmt.Errorf("find milestones: %v", err)
+		}
+		milestonesByID := make(map[int64]*Milestone, len(milestones))
+		for _, milestone := range milestones {
+			milestonesByID[milestone.ID] = milestone
+		}
+		for _, sum := range sums {
+			sum.Milestone = milestonesByID[sum.ID]
+		}
+	case TrackedTimeSumByLabel:
+		labels := make([]*Label, 0, len(ids))
+		if err := x.In("id", ids).Find(&labels); err != nil {
+			return nil, fmt.Errorf("find labels: %v", err)
+		}
+		labelsByID := make(map[int64]*Label, len(labels))
+		for _, label := range labels {
+			labelsByID[label.ID] = label
+		}
+		for _, sum := range sums {
+			sum.Label = labelsByID[sum.ID]
+		}
+	}
+	return sums, nil
+}
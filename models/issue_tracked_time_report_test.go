@@ -0,0 +1,96 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackedTimeReportRange(t *testing.T) {
+	from, until := TrackedTimeReportRange("2021-03-01", "2021-03-31")
+	assert.Equal(t, time.Date(2021, 3, 1, 0, 0, 0, 0, setting.DefaultUILocation), from)
+	assert.Equal(t, time.Date(2021, 3, 31, 23, 59, 59, 0, setting.DefaultUILocation), until)
+
+	// a start after the end is ignored
+	from, _ = TrackedTimeReportRange("2021-04-01", "2021-03-31")
+	assert.Equal(t, time.Date(2021, 3, 2, 0, 0, 0, 0, setting.DefaultUILocation), from)
+
+	from, until = TrackedTimeReportRange("", "invalid")
+	assert.True(t, until.After(time.Now()))
+	assert.Equal(t, DefaultTrackedTimeReportDays, int(until.Sub(from).Hours()/24)+1)
+}
+
+func TestTrackedTimeReportPeriodStart(t *testing.T) {
+	// a Wednesday
+	day := time.Date(2021, 3, 17, 15, 4, 5, 0, setting.DefaultUILocation)
+	assert.Equal(t, time.Date(2021, 3, 17, 0, 0, 0, 0, setting.DefaultUILocation), trackedTimeReportPeriodStart(day, TrackedTimeReportDaily))
+	assert.Equal(t, time.Date(2021, 3, 15, 0, 0, 0, 0, setting.DefaultUILocation), trackedTimeReportPeriodStart(day, TrackedTimeReportWeekly))
+	assert.Equal(t, time.Date(2021, 3, 1, 0, 0, 0, 0, setting.DefaultUILocation), trackedTimeReportPeriodStart(day, TrackedTimeReportMonthly))
+}
+
+func TestGetTrackedTimeReport(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	from := time.Unix(946684800, 0).AddDate(0, 0, -1)
+	until := time.Unix(947688815, 0).AddDate(0, 0, 1)
+	report, err := GetTrackedTimeReport(FindTrackedTimesOptions{RepositoryID: 2}, from, until, TrackedTimeReportMonthly)
+	assert.NoError(t, err)
+	// the times of issue 4, one of them tracked by a deleted user
+	assert.EqualValues(t, 75, report.Total)
+	assert.Len(t, report.Times(), 3)
+	assert.Equal(t, "Ghost", report.Times()[0].User.Name)
+	for _, period := range report.Periods {
+		assert.Equal(t, period.Start, trackedTimeReportPeriodStart(period.Times[0].Created, TrackedTimeReportMonthly))
+	}
+
+	report, err = GetTrackedTimeReport(FindTrackedTimesOptions{UserID: 2}, from, until, TrackedTimeReportDaily)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3666, report.Total)
+	assert.Len(t, report.Times(), 4)
+}
+
+func TestSumTrackedTimes(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	opts := FindTrackedTimesOptions{RepositoryID: 1}
+	sums, err := SumTrackedTimes(opts, TrackedTimeSumByIssue)
+	assert.NoError(t, err)
+	if assert.Len(t, sums, 3) {
+		assert.EqualValues(t, 2, sums[0].ID)
+		assert.EqualValues(t, 3682, sums[0].Time)
+		assert.EqualValues(t, 2, sums[0].Issue.Index)
+		assert.EqualValues(t, 400, sums[1].Time)
+		assert.EqualValues(t, 1, sums[2].Time)
+	}
+
+	sums, err = SumTrackedTimes(opts, TrackedTimeSumByLabel)
+	assert.NoError(t, err)
+	if assert.Len(t, sums, 3) {
+		assert.EqualValues(t, 1, sums[0].ID)
+		assert.EqualValues(t, 4082, sums[0].Time)
+		assert.Equal(t, "label1", sums[0].Label.Name)
+		assert.EqualValues(t, 4, sums[1].ID)
+		assert.EqualValues(t, 3682, sums[1].Time)
+	}
+
+	sums, err = SumTrackedTimes(opts, TrackedTimeSumByMilestone)
+	assert.NoError(t, err)
+	if assert.Len(t, sums, 2) {
+		assert.EqualValues(t, 1, sums[0].ID)
+		assert.EqualValues(t, 3682, sums[0].Time)
+		assert.Equal(t, "milestone1", sums[0].Milestone.Name)
+		assert.EqualValues(t, 0, sums[1].ID)
+		assert.EqualValues(t, 401, sums[1].Time)
+		assert.Nil(t, sums[1].Milestone)
+	}
+
+	_, err = SumTrackedTimes(opts, "unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,352 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// IssueType is the type of the work item an issue represents, e.g. a bug or a feature. Unlike labels an issue has at
+// most one type. The types are defined for a repository or for all the repositories of an organization.
+type IssueType struct {
+	ID          int64  `xorm:"pk autoincr"`
+	OrgID       int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
+	RepoID      int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
+	Name        string `xorm:"NOT NULL"`
+	Description string
+	Color       string `xorm:"VARCHAR(7)"`
+	// TemplateFile is the name of the issue template used by default for the new issues of the type
+	TemplateFile string
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// DefaultIssueTypes are the issue types added when initializing the types of a repository or an organization
+var DefaultIssueTypes = []IssueType{
+	{Name: "Bug", Color: "#ee0701", Description: "Something is not working"},
+	{Name: "Feature", Color: "#84b6eb", Description: "A new functionality"},
+	{Name: "Task", Color: "#fbca04", Description: "A piece of work to be done"},
+	{Name: "Epic", Color: "#5319e7", Description: "A large body of work split into other issues"},
+}
+
+// BelongsToOrg returns true if the type is defined for an organization
+func (t *IssueType) BelongsToOrg() bool {
+	return t.OrgID > 0
+}
+
+// ErrIssueTypeNotExist represents a "IssueTypeNotExist" kind of error.
+type ErrIssueTypeNotExist struct {
+	ID int64
+}
+
+// IsErrIssueTypeNotExist checks if an error is a ErrIssueTypeNotExist.
+func IsErrIssueTypeNotExist(err error) bool {
+	_, ok := err.(ErrIssueTypeNotExist)
+	return ok
+}
+
+func (err ErrIssueTypeNotExist) Error() string {
+	return fmt.Sprintf("issue type does not exist [id: %d]", err.ID)
+}
+
+// ErrIssueTypeAlreadyExist represents a "IssueTypeAlreadyExist" kind of error.
+type ErrIssueTypeAlreadyExist struct {
+	Name string
+}
+
+// IsErrIssueTypeAlreadyExist checks if an error is a ErrIssueTypeAlreadyExist.
+func IsErrIssueTypeAlreadyExist(err error) bool {
+	_, ok := err.(ErrIssueTypeAlreadyExist)
+	return ok
+}
+
+func (err ErrIssueTypeAlreadyExist) Error() string {
+	return fmt.Sprintf("issue type already exists [name: %s]", err.Name)
+}
+
+// ErrInvalidIssueType represents a "InvalidIssueType" kind of error.
+type ErrInvalidIssueType struct {
+	Reason string
+}
+
+// IsErrInvalidIssueType checks if an error is a ErrInvalidIssueType.
+func IsErrInvalidIssueType(err error) bool {
+	_, ok := err.(ErrInvalidIssueType)
+	return ok
+}
+
+func (err ErrInvalidIssueType) Error() string {
+	return fmt.Sprintf("invalid issue type: %s", err.Reason)
+}
+
+func (t *IssueType) validate(e Engine) error {
+	t.Name = strings.TrimSpace(t.Name)
+	t.Description = strings.TrimSpace(t.Description)
+	t.TemplateFile = strings.TrimSpace(t.TemplateFile)
+	if len(t.Name) == 0 {
+		return ErrInvalidIssueType{"the name is empty"}
+	}
+	if (t.OrgID > 0) == (t.RepoID > 0) {
+		return ErrInvalidIssueType{"the type must belong to either an organization or a repository"}
+	}
+	if len(t.Color) == 6 {
+		t.Color = "#" + t.Color
+	}
+	if !LabelColorPattern.MatchString(t.Color) {
+		return ErrInvalidIssueType{fmt.Sprintf("invalid color %q", t.Color)}
+	}
+
+	has, err := e.Where(builder.Eq{"org_id": t.OrgID, "repo_id": t.RepoID}).
+		And("LOWER(name) = ?", strings.ToLower(t.Name)).
+		And("id != ?", t.ID).
+		Exist(new(IssueType))
+	if err != nil {
+		return err
+	} else if has {
+		return ErrIssueTypeAlreadyExist{t.Name}
+	}
+	return nil
+}
+
+// NewIssueType creates an issue type for a repository or an organization
+func NewIssueType(t *IssueType) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := t.validate(sess); err != nil {
+		return err
+	}
+	if _, err := sess.Insert(t); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// UpdateIssueType updates the name, the description, the color and the default template of an issue type
+func UpdateIssueType(t *IssueType) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := t.validate(sess); err != nil {
+		return err
+	}
+	if _, err := sess.ID(t.ID).Cols("name", "description", "color", "template_file").Update(t); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// InitializeIssueTypes adds the default issue types a repository or an organization does not have yet
+func InitializeIssueTypes(orgID, repoID int64) error {
+	for i := range DefaultIssueTypes {
+		t := DefaultIssueTypes[i]
+		t.OrgID = orgID
+		t.RepoID = repoID
+		if err := NewIssueType(&t); err != nil && !IsErrIssueTypeAlreadyExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteIssueType deletes an issue type, the issues of the type no longer having one
+func DeleteIssueType(t *IssueType) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.ID(t.ID).Delete(new(IssueType)); err != nil {
+		return err
+	}
+	if _, err := sess.Exec("UPDATE `issue` SET type_id = 0 WHERE type_id = ?", t.ID); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func getIssueTypeByID(e Engine, id int64) (*IssueType, error) {
+	t := new(IssueType)
+	has, err := e.ID(id).Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrIssueTypeNotExist{id}
+	}
+	return t, nil
+}
+
+// GetIssueTypeByID returns an issue type
+func GetIssueTypeByID(id int64) (*IssueType, error) {
+	return getIssueTypeByID(x, id)
+}
+
+// GetIssueTypeInRepoByID returns an issue type defined for a repository
+func GetIssueTypeInRepoByID(repoID, id int64) (*IssueType, error) {
+	t, err := GetIssueTypeByID(id)
+	if err != nil {
+		return nil, err
+	} else if t.RepoID != repoID {
+		return nil, ErrIssueTypeNotExist{id}
+	}
+	return t, nil
+}
+
+// GetIssueTypeInOrgByID returns an issue type defined for an organization
+func GetIssueTypeInOrgByID(orgID, id int64) (*IssueType, error) {
+	t, err := GetIssueTypeByID(id)
+	if err != nil {
+		return nil, err
+	} else if t.OrgID != orgID {
+		return nil, ErrIssueTypeNotExist{id}
+	}
+	return t, nil
+}
+
+// GetIssueTypesByRepoID returns the issue types defined for a repository, ordered by name
+func GetIssueTypesByRepoID(repoID int64) ([]*IssueType, error) {
+	types := make([]*IssueType, 0, len(DefaultIssueTypes))
+	return types, x.Where("repo_id = ?", repoID).Asc("name", "id").Find(&types)
+}
+
+// GetIssueTypesByOrgID returns the issue types defined for an organization, ordered by name
+func GetIssueTypesByOrgID(orgID int64) ([]*IssueType, error) {
+	types := make([]*IssueType, 0, len(DefaultIssueTypes))
+	return types, x.Where("org_id = ?", orgID).Asc("name", "id").Find(&types)
+}
+
+func issueTypesOfRepoCond(repo *Repository) builder.Cond {
+	cond := builder.NewCond().Or(builder.Eq{"repo_id": repo.ID})
+	if repo.Owner != nil && repo.Owner.IsOrganization() {
+		cond = cond.Or(builder.Eq{"org_id": repo.OwnerID})
+	}
+	return cond
+}
+
+// GetIssueTypesOfRepo returns the issue types the issues of a repository can have, the ones of the repository and the
+// ones of its organization, ordered by name
+func GetIssueTypesOfRepo(repo *Repository) ([]*IssueType, error) {
+	if err := repo.GetOwner(); err != nil {
+		return nil, err
+	}
+	types := make([]*IssueType, 0, len(DefaultIssueTypes))
+	return types, x.Where(issueTypesOfRepoCond(repo)).Asc("name", "id").Find(&types)
+}
+
+// GetIssueTypeOfRepoByID returns an issue type the issues of a repository can have
+func GetIssueTypeOfRepoByID(repo *Repository, id int64) (*IssueType, error) {
+	if err := repo.GetOwner(); err != nil {
+		return nil, err
+	}
+	t := new(IssueType)
+	has, err := x.ID(id).And(issueTypesOfRepoCond(repo)).Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrIssueTypeNotExist{id}
+	}
+	return t, nil
+}
+
+func (issue *Issue) loadType(e Engine) (err error) {
+	if (issue.Type == nil || issue.Type.ID != issue.TypeID) && issue.TypeID > 0 {
+		issue.Type, err = getIssueTypeByID(e, issue.TypeID)
+		if err != nil && !IsErrIssueTypeNotExist(err) {
+			return fmt.Errorf("getIssueTypeByID [type_id: %d]: %v", issue.TypeID, err)
+		}
+	}
+	return nil
+}
+
+// LoadType loads the type of the issue
+func (issue *Issue) LoadType() error {
+	return issue.loadType(x)
+}
+
+// ChangeIssueType changes the type of an issue, 0 removing it, and adds a comment about the change
+func ChangeIssueType(issue *Issue, doer *User, typeID int64) error {
+	if issue.TypeID == typeID {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := issue.loadRepo(sess); err != nil {
+		return err
+	}
+	if err := issue.loadType(sess); err != nil {
+		return err
+	}
+	oldType := issue.Type
+	var newType *IssueType
+	if typeID > 0 {
+		var err error
+		if newType, err = GetIssueTypeOfRepoByID(issue.Repo, typeID); err != nil {
+			return err
+		}
+	}
+
+	issue.TypeID = typeID
+	issue.Type = newType
+	if err := updateIssueCols(sess, issue, "type_id"); err != nil {
+		return err
+	}
+
+	// the names of the types are kept in the comment, as the types can be deleted
+	opts := &CreateCommentOptions{
+		Type:  CommentTypeChangeIssueType,
+		Doer:  doer,
+		Repo:  issue.Repo,
+		Issue: issue,
+	}
+	if oldType != nil {
+		opts.OldTitle = oldType.Name
+	}
+	if newType != nil {
+		opts.NewTitle = newType.Name
+	}
+	if _, err := createComment(sess, opts); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func (issues IssueList) loadTypes(e Engine) error {
+	typeIDs := make([]int64, 0, len(issues))
+	for _, issue := range issues {
+		if issue.TypeID > 0 {
+			typeIDs = append(typeIDs, issue.TypeID)
+		}
+	}
+	if len(typeIDs) == 0 {
+		return nil
+	}
+
+	types := make(map[int64]*IssueType, len(typeIDs))
+	if err := e.In("id", typeIDs).Find(&types); err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		issue.Type = types[issue.TypeID]
+	}
+	return nil
+}
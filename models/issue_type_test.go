@@ -0,0 +1,69 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueTypes(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// org 3 owns repo 3, user 2 owns repo 1
+	assert.NoError(t, InitializeIssueTypes(3, 0))
+	assert.NoError(t, InitializeIssueTypes(3, 0))
+	orgTypes, err := GetIssueTypesByOrgID(3)
+	assert.NoError(t, err)
+	assert.Len(t, orgTypes, len(DefaultIssueTypes))
+
+	incident := &IssueType{RepoID: 3, Name: " Incident ", Color: "b60205"}
+	assert.NoError(t, NewIssueType(incident))
+	assert.Equal(t, "Incident", incident.Name)
+	assert.Equal(t, "#b60205", incident.Color)
+	assert.True(t, IsErrIssueTypeAlreadyExist(NewIssueType(&IssueType{RepoID: 3, Name: "incident", Color: "#000000"})))
+	assert.True(t, IsErrInvalidIssueType(NewIssueType(&IssueType{RepoID: 3, Name: "Other", Color: "red"})))
+	assert.True(t, IsErrInvalidIssueType(NewIssueType(&IssueType{OrgID: 3, RepoID: 3, Name: "Other", Color: "#000000"})))
+	assert.NoError(t, NewIssueType(&IssueType{RepoID: 1, Name: "Bug", Color: "#ee0701"}))
+
+	repo3 := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+	types, err := GetIssueTypesOfRepo(repo3)
+	assert.NoError(t, err)
+	assert.Len(t, types, len(DefaultIssueTypes)+1)
+	repo1 := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	types, err = GetIssueTypesOfRepo(repo1)
+	assert.NoError(t, err)
+	assert.Len(t, types, 1)
+	_, err = GetIssueTypeOfRepoByID(repo1, incident.ID)
+	assert.True(t, IsErrIssueTypeNotExist(err))
+
+	incident.Name = "Outage"
+	assert.NoError(t, UpdateIssueType(incident))
+	AssertExistsAndLoadBean(t, &IssueType{ID: incident.ID, Name: "Outage"})
+
+	// the type of an issue is one of the types of its repository
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 6}).(*Issue)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.True(t, IsErrIssueTypeNotExist(ChangeIssueType(issue, doer, types[0].ID)))
+	assert.NoError(t, ChangeIssueType(issue, doer, incident.ID))
+	AssertExistsAndLoadBean(t, &Comment{IssueID: issue.ID, Type: CommentTypeChangeIssueType, NewTitle: "Outage"})
+	assert.NoError(t, ChangeIssueType(issue, doer, orgTypes[0].ID))
+	AssertExistsAndLoadBean(t, &Comment{IssueID: issue.ID, Type: CommentTypeChangeIssueType, OldTitle: "Outage", NewTitle: orgTypes[0].Name})
+
+	issues, err := Issues(&IssuesOptions{RepoIDs: []int64{3}, TypeID: orgTypes[0].ID})
+	assert.NoError(t, err)
+	if assert.Len(t, issues, 1) {
+		assert.EqualValues(t, 6, issues[0].ID)
+	}
+	assert.NoError(t, IssueList(issues).LoadAttributes())
+	assert.Equal(t, orgTypes[0].Name, issues[0].Type.Name)
+
+	// deleting a type removes it from its issues
+	assert.NoError(t, DeleteIssueType(orgTypes[0]))
+	AssertNotExistsBean(t, &IssueType{ID: orgTypes[0].ID})
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 6}).(*Issue)
+	assert.Zero(t, issue.TypeID)
+}
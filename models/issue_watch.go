@@ -5,6 +5,9 @@
 package models
 
 import (
+	"strings"
+
+	"code.gitea.io/gitea/modules/references"
 	"code.gitea.io/gitea/modules/timeutil"
 )
 
@@ -23,7 +26,11 @@ type IssueWatchList []*IssueWatch
 
 // CreateOrUpdateIssueWatch set watching for a user and issue
 func CreateOrUpdateIssueWatch(userID, issueID int64, isWatching bool) error {
-	iw, exists, err := getIssueWatch(x, userID, issueID)
+	return createOrUpdateIssueWatch(x, userID, issueID, isWatching)
+}
+
+func createOrUpdateIssueWatch(e Engine, userID, issueID int64, isWatching bool) error {
+	iw, exists, err := getIssueWatch(e, userID, issueID)
 	if err != nil {
 		return err
 	}
@@ -35,19 +42,97 @@ func CreateOrUpdateIssueWatch(userID, issueID int64, isWatching bool) error {
 			IsWatching: isWatching,
 		}
 
-		if _, err := x.Insert(iw); err != nil {
+		if _, err := e.Insert(iw); err != nil {
 			return err
 		}
 	} else {
 		iw.IsWatching = isWatching
 
-		if _, err := x.ID(iw.ID).Cols("is_watching", "updated_unix").Update(iw); err != nil {
+		if _, err := e.ID(iw.ID).Cols("is_watching", "updated_unix").Update(iw); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// WatchIssueForTeamMentions subscribes to an issue the members of the teams mentioned in a content who can read the
+// issue, except the doer and the users who subscribed to or unsubscribed from the issue already. It returns the number
+// of users subscribed.
+func WatchIssueForTeamMentions(issue *Issue, doer *User, content string) (int, error) {
+	if err := issue.LoadRepo(); err != nil {
+		return 0, err
+	}
+	if err := issue.Repo.GetOwner(); err != nil {
+		return 0, err
+	}
+	if !issue.Repo.Owner.IsOrganization() {
+		return 0, nil
+	}
+
+	prefix := issue.Repo.Owner.LowerName + "/"
+	names := make([]string, 0, 2)
+	for _, mention := range references.FindAllMentionsMarkdown(content) {
+		if mention = strings.ToLower(mention); strings.HasPrefix(mention, prefix) {
+			names = append(names, strings.TrimPrefix(mention, prefix))
+		}
+	}
+	if len(names) == 0 {
+		return 0, nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return 0, err
+	}
+
+	teams, err := issue.getMentionableTeams(sess, names)
+	if err != nil || len(teams) == 0 {
+		return 0, err
+	}
+	teamIDs := make([]int64, len(teams))
+	for i, team := range teams {
+		teamIDs[i] = team.ID
+	}
+	members, err := getActiveTeamsMembers(sess, teamIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	memberIDs := make([]int64, 0, len(members))
+	for _, member := range members {
+		memberIDs = append(memberIDs, member.ID)
+	}
+	watched := make([]int64, 0, len(memberIDs))
+	if err := sess.Table("issue_watch").
+		Where("issue_id = ?", issue.ID).
+		In("user_id", memberIDs).
+		Cols("user_id").
+		Find(&watched); err != nil {
+		return 0, err
+	}
+	skipped := make(map[int64]bool, len(watched)+1)
+	skipped[doer.ID] = true
+	for _, id := range watched {
+		skipped[id] = true
+	}
+
+	watches := make([]*IssueWatch, 0, len(memberIDs))
+	for _, id := range memberIDs {
+		if !skipped[id] {
+			skipped[id] = true
+			watches = append(watches, &IssueWatch{UserID: id, IssueID: issue.ID, IsWatching: true})
+		}
+	}
+	if len(watches) == 0 {
+		return 0, nil
+	}
+	if _, err := sess.Insert(&watches); err != nil {
+		return 0, err
+	}
+	return len(watches), sess.Commit()
+}
+
 // GetIssueWatch returns all IssueWatch objects from db by user and issue
 // the current Web-UI need iw object for watchers AND explicit non-watchers
 func GetIssueWatch(userID, issueID int64) (iw *IssueWatch, exists bool, err error) {
@@ -62,3 +62,31 @@ func TestGetIssueWatchers(t *testing.T) {
 	// Issue has one watcher
 	assert.Len(t, iws, 1)
 }
+
+func TestWatchIssueForTeamMentions(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// the team owners of user17 has the members 15 and 18
+	issue := &Issue{RepoID: 24, Index: 1, PosterID: 15, Title: "mentions"}
+	_, err := x.Insert(issue)
+	assert.NoError(t, err)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 15}).(*User)
+
+	n, err := WatchIssueForTeamMentions(issue, doer, "cc @user17/owners @user17/unknown @user3/owners")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	AssertExistsAndLoadBean(t, &IssueWatch{UserID: 18, IssueID: issue.ID, IsWatching: true})
+	AssertNotExistsBean(t, &IssueWatch{UserID: doer.ID, IssueID: issue.ID})
+
+	// the users who unsubscribed are not subscribed again
+	assert.NoError(t, CreateOrUpdateIssueWatch(18, issue.ID, false))
+	n, err = WatchIssueForTeamMentions(issue, doer, "cc @user17/owners")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	AssertExistsAndLoadBean(t, &IssueWatch{UserID: 18, IssueID: issue.ID, IsWatching: false})
+
+	// the repositories of users have no team
+	n, err = WatchIssueForTeamMentions(AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue), doer, "cc @user17/owners")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strconv"
+)
+
+// ChangeIssueWeight changes the weight of an issue, 0 removing it, and adds a comment about the change
+func ChangeIssueWeight(issue *Issue, doer *User, weight int64) error {
+	if issue.Weight == weight {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := issue.loadRepo(sess); err != nil {
+		return err
+	}
+
+	oldWeight := issue.Weight
+	issue.Weight = weight
+	if err := updateIssueCols(sess, issue, "weight"); err != nil {
+		return err
+	}
+
+	opts := &CreateCommentOptions{
+		Type:  CommentTypeChangeIssueWeight,
+		Doer:  doer,
+		Repo:  issue.Repo,
+		Issue: issue,
+	}
+	if oldWeight != 0 {
+		opts.OldTitle = strconv.FormatInt(oldWeight, 10)
+	}
+	if weight != 0 {
+		opts.NewTitle = strconv.FormatInt(weight, 10)
+	}
+	if _, err := createComment(sess, opts); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func (milestones MilestoneList) loadTotalWeights(e Engine) error {
+	type weightsByMilestone struct {
+		MilestoneID int64
+		IsClosed    bool
+		Weight      int64
+	}
+	if len(milestones) == 0 {
+		return nil
+	}
+	totalWeights := make(map[int64]int64, len(milestones))
+	closedWeights := make(map[int64]int64, len(milestones))
+
+	rows, err := e.Table("issue").
+		Select("milestone_id, is_closed, sum(weight) as weight").
+		In("milestone_id", milestones.getMilestoneIDs()).
+		GroupBy("milestone_id, is_closed").
+		Rows(new(weightsByMilestone))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var weights weightsByMilestone
+		if err := rows.Scan(&weights); err != nil {
+			return err
+		}
+		totalWeights[weights.MilestoneID] += weights.Weight
+		if weights.IsClosed {
+			closedWeights[weights.MilestoneID] += weights.Weight
+		}
+	}
+
+	for _, milestone := range milestones {
+		milestone.TotalWeight = totalWeights[milestone.ID]
+		milestone.ClosedWeight = closedWeights[milestone.ID]
+	}
+	return nil
+}
+
+// LoadTotalWeights loads for every milestone in the list the sums of the weights of its issues by a batch request
+func (milestones MilestoneList) LoadTotalWeights() error {
+	return milestones.loadTotalWeights(x)
+}
+
+// LoadTotalWeight loads the sums of the weights of the issues of the milestone
+func (m *Milestone) LoadTotalWeight() error {
+	return MilestoneList{m}.loadTotalWeights(x)
+}
+
+// OpenWeight returns the sum of the weights of the open issues of the milestone
+func (m *Milestone) OpenWeight() int64 {
+	return m.TotalWeight - m.ClosedWeight
+}
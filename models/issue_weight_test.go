@@ -0,0 +1,42 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangeIssueWeight(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	// issue 2 is open and issue 5 is closed, both in repo 1 whose milestone 1 contains issue 2
+	issue2 := AssertExistsAndLoadBean(t, &Issue{ID: 2}).(*Issue)
+	issue5 := AssertExistsAndLoadBean(t, &Issue{ID: 5}).(*Issue)
+	issue5.MilestoneID = 1
+	assert.NoError(t, updateIssueCols(x, issue5, "milestone_id"))
+
+	assert.NoError(t, ChangeIssueWeight(issue2, doer, 3))
+	assert.NoError(t, ChangeIssueWeight(issue5, doer, 2))
+	assert.NoError(t, ChangeIssueWeight(issue5, doer, 5))
+	AssertExistsAndLoadBean(t, &Issue{ID: issue2.ID, Weight: 3})
+	AssertExistsAndLoadBean(t, &Comment{IssueID: issue2.ID, Type: CommentTypeChangeIssueWeight, NewTitle: "3"})
+	AssertExistsAndLoadBean(t, &Comment{IssueID: issue5.ID, Type: CommentTypeChangeIssueWeight, OldTitle: "2", NewTitle: "5"})
+
+	milestone := AssertExistsAndLoadBean(t, &Milestone{ID: 1}).(*Milestone)
+	assert.NoError(t, milestone.LoadTotalWeight())
+	assert.EqualValues(t, 8, milestone.TotalWeight)
+	assert.EqualValues(t, 5, milestone.ClosedWeight)
+	assert.EqualValues(t, 3, milestone.OpenWeight())
+
+	assert.NoError(t, ChangeIssueWeight(issue2, doer, 0))
+	AssertExistsAndLoadBean(t, &Comment{IssueID: issue2.ID, Type: CommentTypeChangeIssueWeight, OldTitle: "3", NewTitle: ""})
+	milestones := MilestoneList{milestone}
+	assert.NoError(t, milestones.LoadTotalWeights())
+	assert.EqualValues(t, 5, milestone.TotalWeight)
+	assert.EqualValues(t, 0, milestone.OpenWeight())
+}
@@ -11,12 +11,14 @@ import (
 	"fmt"
 	"net/smtp"
 	"net/textproto"
+	"sort"
 	"strconv"
 	"strings"
 
 	"code.gitea.io/gitea/modules/auth/ldap"
 	"code.gitea.io/gitea/modules/auth/oauth2"
 	"code.gitea.io/gitea/modules/auth/pam"
+	"code.gitea.io/gitea/modules/auth/saml"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
@@ -40,6 +42,7 @@ const (
 	LoginDLDAP            // 5
 	LoginOAuth2           // 6
 	LoginSSPI             // 7
+	LoginSAML             // 8
 )
 
 // LoginNames contains the name of LoginType values.
@@ -50,6 +53,7 @@ var LoginNames = map[LoginType]string{
 	LoginPAM:    "PAM",
 	LoginOAuth2: "OAuth2",
 	LoginSSPI:   "SPNEGO with SSPI",
+	LoginSAML:   "SAML 2.0",
 }
 
 // SecurityProtocolNames contains the name of SecurityProtocol values.
@@ -66,6 +70,7 @@ var (
 	_ convert.Conversion = &PAMConfig{}
 	_ convert.Conversion = &OAuth2Config{}
 	_ convert.Conversion = &SSPIConfig{}
+	_ convert.Conversion = &SAMLConfig{}
 )
 
 // LDAPConfig holds configuration for LDAP login source.
@@ -173,6 +178,23 @@ func (cfg *SSPIConfig) ToDB() ([]byte, error) {
 	return json.Marshal(cfg)
 }
 
+// SAMLConfig holds configuration for a SAML 2.0 identity provider.
+type SAMLConfig struct {
+	*saml.Source
+}
+
+// FromDB fills up a SAMLConfig from serialized format.
+func (cfg *SAMLConfig) FromDB(bs []byte) error {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	return json.Unmarshal(bs, &cfg)
+}
+
+// ToDB exports a SAMLConfig to a serialized format.
+func (cfg *SAMLConfig) ToDB() ([]byte, error) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	return json.Marshal(cfg)
+}
+
 // LoginSource represents an external way for authorizing users.
 type LoginSource struct {
 	ID            int64 `xorm:"pk autoincr"`
@@ -213,6 +235,8 @@ func (source *LoginSource) BeforeSet(colName string, val xorm.Cell) {
 			source.Cfg = new(OAuth2Config)
 		case LoginSSPI:
 			source.Cfg = new(SSPIConfig)
+		case LoginSAML:
+			source.Cfg = new(SAMLConfig)
 		default:
 			panic(fmt.Sprintf("unrecognized login source type: %v", *val))
 		}
@@ -254,6 +278,11 @@ func (source *LoginSource) IsSSPI() bool {
 	return source.Type == LoginSSPI
 }
 
+// IsSAML returns true of this source is of the SAML type.
+func (source *LoginSource) IsSAML() bool {
+	return source.Type == LoginSAML
+}
+
 // HasTLS returns true of this source supports TLS.
 func (source *LoginSource) HasTLS() bool {
 	return ((source.IsLDAP() || source.IsDLDAP()) &&
@@ -311,6 +340,11 @@ func (source *LoginSource) SSPI() *SSPIConfig {
 	return source.Cfg.(*SSPIConfig)
 }
 
+// SAML returns SAMLConfig for this source, if of SAML type.
+func (source *LoginSource) SAML() *SAMLConfig {
+	return source.Cfg.(*SAMLConfig)
+}
+
 // CreateLoginSource inserts a LoginSource in the DB if not already
 // existing with the given name.
 func CreateLoginSource(source *LoginSource) error {
@@ -391,6 +425,18 @@ func GetLoginSourceByID(id int64) (*LoginSource, error) {
 	return source, nil
 }
 
+// GetLoginSourceByName returns login source by given name.
+func GetLoginSourceByName(name string) (*LoginSource, error) {
+	source := new(LoginSource)
+	has, err := x.Where("name = ?", name).Get(source)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrLoginSourceNotExist{0}
+	}
+	return source, nil
+}
+
 // UpdateSource updates a LoginSource record in DB.
 func UpdateSource(source *LoginSource) error {
 	var originalLoginSource *LoginSource
@@ -468,6 +514,21 @@ func composeFullName(firstname, surname, username string) string {
 	}
 }
 
+// syncLDAPTeams synchronizes the team memberships of the user with their LDAP groups.
+// Failures are only logged, they must not prevent the user from signing in.
+func syncLDAPTeams(source *LoginSource, user *User, groups []string) {
+	if !source.LDAP().SyncsTeams() || groups == nil {
+		return
+	}
+	mapping, err := source.LDAP().TeamMapping()
+	if err == nil {
+		err = SyncUserTeams(user, groups, mapping, source.LDAP().GroupTeamMapRemoval)
+	}
+	if err != nil {
+		log.Error("Unable to synchronize teams of %s with LDAP source %s: %v", user.Name, source.Name, err)
+	}
+}
+
 // LoginViaLDAP queries if login/password is valid against the LDAP directory pool,
 // and create a local user if success when enabled.
 func LoginViaLDAP(user *User, login, password string, source *LoginSource) (*User, error) {
@@ -511,6 +572,8 @@ func LoginViaLDAP(user *User, login, password string, source *LoginSource) (*Use
 	}
 
 	if user != nil {
+		syncLDAPTeams(source, user, sr.Groups)
+
 		if isAttributeSSHPublicKeySet && synchronizeLdapSSHPublicKeys(user, source, sr.SSHPublicKey) {
 			return user, RewriteAllPublicKeys()
 		}
@@ -541,6 +604,9 @@ func LoginViaLDAP(user *User, login, password string, source *LoginSource) (*Use
 	}
 
 	err := CreateUser(user)
+	if err == nil {
+		syncLDAPTeams(source, user, sr.Groups)
+	}
 
 	if err == nil && isAttributeSSHPublicKeySet && addLdapSSHPublicKeys(user, source, sr.SSHPublicKey) {
 		err = RewriteAllPublicKeys()
@@ -714,6 +780,141 @@ func LoginViaPAM(user *User, login, password string, sourceID int64, cfg *PAMCon
 	return user, CreateUser(user)
 }
 
+//   _________   _____      _____  .____
+//  /   _____/  /  _  \    /     \ |    |
+//  \_____  \  /  /_\  \  /  \ /  \|    |
+//  /        \/    |    \/    Y    \    |___
+// /_______  /\____|__  /\____|__  /_______ \
+//         \/         \/         \/        \/
+
+// GetActiveSAMLLoginSourceByName returns an active SAML LoginSource with the given name.
+func GetActiveSAMLLoginSourceByName(name string) (*LoginSource, error) {
+	loginSource := new(LoginSource)
+	has, err := x.Where("name = ? and type = ? and is_actived = ?", name, LoginSAML, true).Get(loginSource)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrLoginSourceNotExist{0}
+	}
+	return loginSource, nil
+}
+
+// GetActiveSAMLLoginSourceNames returns the sorted names of all active SAML login sources.
+func GetActiveSAMLLoginSourceNames() ([]string, error) {
+	sources, err := ActiveLoginSources(LoginSAML)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(sources))
+	for _, source := range sources {
+		names = append(names, source.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// LoginViaSAML signs in the user identified by a verified SAML assertion,
+// creating a local user on first sign in, and synchronizes the team
+// memberships if a group mapping is configured.
+func LoginViaSAML(source *LoginSource, assertion *saml.Assertion) (*User, error) {
+	if !source.IsActived {
+		return nil, ErrLoginSourceNotActived
+	}
+	cfg := source.SAML()
+
+	user := &User{LoginType: LoginSAML, LoginSource: source.ID, LoginName: assertion.NameID}
+	has, err := x.Get(user)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		// like with OAuth2, the accounts of the identity provider are only registered if the self-registration is
+		// enabled, be it only through external services
+		if setting.Service.DisableRegistration {
+			return nil, ErrExternalRegistrationDisabled{LoginSource: source.Name, LoginName: assertion.NameID}
+		}
+
+		username := cfg.Username(assertion)
+		if idx := strings.Index(username, "@"); idx > -1 {
+			username = username[:idx]
+		}
+		email := cfg.Email(assertion)
+		if len(email) == 0 {
+			email = fmt.Sprintf("%s@localhost", username)
+		}
+
+		user = &User{
+			LowerName:   strings.ToLower(username),
+			Name:        username,
+			FullName:    cfg.FullName(assertion),
+			Email:       email,
+			LoginType:   LoginSAML,
+			LoginSource: source.ID,
+			LoginName:   assertion.NameID,
+			IsActive:    !(setting.Service.RegisterEmailConfirm || setting.Service.RegisterManualConfirm),
+		}
+		if err := CreateUser(user); err != nil {
+			return nil, err
+		}
+	} else if err := syncSAMLUser(user, cfg, assertion); err != nil {
+		return nil, err
+	}
+
+	// WARN: DON'T check user.IsActive, that will be checked on reqSign so that
+	// user could be hint to resend confirm email.
+	if user.ProhibitLogin {
+		return nil, ErrUserProhibitLogin{user.ID, user.Name}
+	}
+
+	if len(cfg.AttributeGroups) > 0 {
+		mapping, err := cfg.TeamMapping()
+		if err != nil {
+			return nil, err
+		}
+		if err := SyncUserTeams(user, cfg.Groups(assertion), mapping, cfg.GroupTeamMapRemoval); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+// syncSAMLUser updates the full name and the email address of a user from the attributes of the identity provider,
+// which are authoritative
+func syncSAMLUser(user *User, cfg *SAMLConfig, assertion *saml.Assertion) error {
+	var cols []string
+	if fullName := cfg.FullName(assertion); len(cfg.AttributeFullName) > 0 && fullName != user.FullName {
+		user.FullName = fullName
+		cols = append(cols, "full_name")
+	}
+	if email := strings.ToLower(cfg.Email(assertion)); len(email) > 0 && email != user.Email {
+		// the address is kept if it is invalid or used by another user, rather than refusing the login
+		err := ValidateEmail(email)
+		if err == nil {
+			err = checkDupEmail(x, &User{ID: user.ID, Type: user.Type, Email: email})
+		}
+		if err == nil {
+			var used bool
+			if used, err = x.Where("uid!=?", user.ID).And("email=?", email).Exist(new(EmailAddress)); used {
+				err = ErrEmailAlreadyUsed{email}
+			}
+		}
+		switch {
+		case err == nil:
+			user.Email = email
+			cols = append(cols, "email")
+		case IsErrEmailAlreadyUsed(err), IsErrEmailInvalid(err):
+			log.Warn("Unable to synchronize the email address of %s: %v", user.Name, err)
+		default:
+			return err
+		}
+	}
+	if len(cols) == 0 {
+		return nil
+	}
+	return UpdateUserCols(user, cols...)
+}
+
 // ExternalUserLogin attempts a login using external source types.
 func ExternalUserLogin(user *User, login, password string, source *LoginSource) (*User, error) {
 	if !source.IsActived {
@@ -776,7 +977,7 @@ func UserSignIn(username, password string) (*User, error) {
 
 	if hasUser {
 		switch user.LoginType {
-		case LoginNoType, LoginPlain, LoginOAuth2:
+		case LoginNoType, LoginPlain, LoginOAuth2, LoginSAML:
 			if user.IsPasswordSet() && user.ValidatePassword(password) {
 
 				// Update password hash if server password hash algorithm have changed
@@ -819,8 +1020,8 @@ func UserSignIn(username, password string) (*User, error) {
 	}
 
 	for _, source := range sources {
-		if source.IsOAuth2() || source.IsSSPI() {
-			// don't try to authenticate against OAuth2 and SSPI sources here
+		if source.IsOAuth2() || source.IsSSPI() || source.IsSAML() {
+			// don't try to authenticate against OAuth2, SSPI and SAML sources here
 			continue
 		}
 		authUser, err := ExternalUserLogin(nil, username, password, source)
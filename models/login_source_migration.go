@@ -0,0 +1,278 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"xorm.io/builder"
+)
+
+// LoginSourceMapping is the identity of the users of a login source mapped to their login names in another one
+type LoginSourceMapping string
+
+// The identities the users can be mapped by
+const (
+	LoginSourceMappingUsername LoginSourceMapping = "username"
+	LoginSourceMappingEmail    LoginSourceMapping = "email"
+)
+
+// IsValid returns true if the mapping is known
+func (m LoginSourceMapping) IsValid() bool {
+	return m == LoginSourceMappingUsername || m == LoginSourceMappingEmail
+}
+
+// The reasons a user cannot be migrated for
+const (
+	// LoginSourceConflictLoginNameTaken means another user of the target source has the login name
+	LoginSourceConflictLoginNameTaken = "login_name_taken"
+	// LoginSourceConflictDuplicate means several migrated users are mapped to the login name
+	LoginSourceConflictDuplicate = "duplicate"
+)
+
+// MigrateLoginSourceOptions are the options of a migration of users between login sources
+type MigrateLoginSourceOptions struct {
+	// FromSourceID and ToSourceID are the IDs of the login sources, 0 standing for the local users
+	FromSourceID int64
+	ToSourceID   int64
+	Mapping      LoginSourceMapping
+	// Usernames restricts the migration to some of the users of the source if it is not empty
+	Usernames []string
+}
+
+// LoginSourceMigrationUser is a user migrated to another login source
+type LoginSourceMigrationUser struct {
+	User *User
+	// LoginName is the login name of the user in the target source, empty for the local users
+	LoginName string
+	// Conflict is the reason the user cannot be migrated for, empty if it can be
+	Conflict string
+}
+
+// LoginSourceMigration is a migration of users between login sources
+type LoginSourceMigration struct {
+	From *LoginSource
+	To   *LoginSource
+	// Users are the users of the migration, including the ones which cannot be migrated
+	Users []*LoginSourceMigrationUser
+}
+
+// Conflicts returns the users of the migration which cannot be migrated
+func (m *LoginSourceMigration) Conflicts() []*LoginSourceMigrationUser {
+	var conflicts []*LoginSourceMigrationUser
+	for _, u := range m.Users {
+		if len(u.Conflict) > 0 {
+			conflicts = append(conflicts, u)
+		}
+	}
+	return conflicts
+}
+
+// ErrLoginSourceMigrationInvalid represents an invalid migration of users between login sources
+type ErrLoginSourceMigrationInvalid struct {
+	Reason string
+}
+
+// IsErrLoginSourceMigrationInvalid checks if an error is a ErrLoginSourceMigrationInvalid.
+func IsErrLoginSourceMigrationInvalid(err error) bool {
+	_, ok := err.(ErrLoginSourceMigrationInvalid)
+	return ok
+}
+
+func (err ErrLoginSourceMigrationInvalid) Error() string {
+	return fmt.Sprintf("invalid login source migration: %s", err.Reason)
+}
+
+// getMigrationLoginSource returns the login source of a migration, nil standing for the local users
+func getMigrationLoginSource(e Engine, id int64) (*LoginSource, error) {
+	if id == 0 {
+		return nil, nil
+	}
+	source := new(LoginSource)
+	has, err := e.ID(id).Get(source)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrLoginSourceNotExist{id}
+	}
+	if source.IsSSPI() {
+		return nil, ErrLoginSourceMigrationInvalid{fmt.Sprintf("the users of the SSPI source %s are local users", source.Name)}
+	}
+	return source, nil
+}
+
+// migrationLoginName returns the login name of a user in the target source of a migration
+func migrationLoginName(to *LoginSource, u *User, mapping LoginSourceMapping) string {
+	if to == nil {
+		return ""
+	}
+	if mapping == LoginSourceMappingEmail {
+		return u.Email
+	}
+	return u.Name
+}
+
+func previewLoginSourceMigration(e Engine, opts *MigrateLoginSourceOptions) (*LoginSourceMigration, error) {
+	if opts.FromSourceID == opts.ToSourceID {
+		return nil, ErrLoginSourceMigrationInvalid{"the users are already in the target source"}
+	}
+	if opts.ToSourceID != 0 && !opts.Mapping.IsValid() {
+		return nil, ErrLoginSourceMigrationInvalid{fmt.Sprintf("unknown mapping %q", opts.Mapping)}
+	}
+
+	migration := &LoginSourceMigration{}
+	var err error
+	if migration.From, err = getMigrationLoginSource(e, opts.FromSourceID); err != nil {
+		return nil, err
+	}
+	if migration.To, err = getMigrationLoginSource(e, opts.ToSourceID); err != nil {
+		return nil, err
+	}
+	if migration.To != nil && migration.To.IsOAuth2() && opts.Mapping != LoginSourceMappingEmail {
+		return nil, ErrLoginSourceMigrationInvalid{"the users can only be mapped by email to an OAuth2 source"}
+	}
+
+	sess := e.Where("type = ?", UserTypeIndividual)
+	if migration.From == nil {
+		sess.And(builder.In("login_type", LoginNoType, LoginPlain).Or(builder.IsNull{"login_type"}))
+	} else {
+		sess.And("login_type = ?", migration.From.Type).And("login_source = ?", migration.From.ID)
+	}
+	if len(opts.Usernames) > 0 {
+		lowerNames := make([]string, 0, len(opts.Usernames))
+		for _, name := range opts.Usernames {
+			lowerNames = append(lowerNames, strings.ToLower(name))
+		}
+		sess.In("lower_name", lowerNames)
+	}
+	var users []*User
+	if err := sess.Asc("id").Find(&users); err != nil {
+		return nil, err
+	}
+
+	// the login names of the users of the target source, which are case insensitive for most sources
+	taken := make(map[string]bool)
+	if migration.To != nil {
+		var loginNames []string
+		if err := e.Table(new(User)).Where("login_type = ?", migration.To.Type).
+			And("login_source = ?", migration.To.ID).
+			Cols("login_name").Find(&loginNames); err != nil {
+			return nil, err
+		}
+		for _, loginName := range loginNames {
+			taken[strings.ToLower(loginName)] = true
+		}
+	}
+
+	mapped := make(map[string][]*LoginSourceMigrationUser, len(users))
+	for _, u := range users {
+		mu := &LoginSourceMigrationUser{
+			User:      u,
+			LoginName: migrationLoginName(migration.To, u, opts.Mapping),
+		}
+		migration.Users = append(migration.Users, mu)
+		if len(mu.LoginName) == 0 {
+			continue
+		}
+		key := strings.ToLower(mu.LoginName)
+		if taken[key] {
+			mu.Conflict = LoginSourceConflictLoginNameTaken
+		}
+		mapped[key] = append(mapped[key], mu)
+	}
+	for _, mus := range mapped {
+		if len(mus) < 2 {
+			continue
+		}
+		for _, mu := range mus {
+			if len(mu.Conflict) == 0 {
+				mu.Conflict = LoginSourceConflictDuplicate
+			}
+		}
+	}
+	return migration, nil
+}
+
+// PreviewLoginSourceMigration returns the users MigrateLoginSource would migrate and the ones it could not, without
+// migrating them
+func PreviewLoginSourceMigration(opts *MigrateLoginSourceOptions) (*LoginSourceMigration, error) {
+	return previewLoginSourceMigration(x, opts)
+}
+
+// MigrateLoginSource migrates users from a login source to another, skipping the ones which cannot be migrated. The
+// users keep their SSH keys, access tokens, memberships and everything else, the keys synchronized from an LDAP
+// source becoming keys of their own.
+func MigrateLoginSource(opts *MigrateLoginSourceOptions) (*LoginSourceMigration, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	migration, err := previewLoginSourceMigration(sess, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	loginType, loginSource := LoginPlain, int64(0)
+	if migration.To != nil {
+		loginType, loginSource = migration.To.Type, migration.To.ID
+	}
+	for _, mu := range migration.Users {
+		if len(mu.Conflict) > 0 {
+			continue
+		}
+		mu.User.LoginType = loginType
+		mu.User.LoginSource = loginSource
+		mu.User.LoginName = mu.LoginName
+		if _, err := sess.ID(mu.User.ID).Cols("login_type", "login_source", "login_name").Update(mu.User); err != nil {
+			return nil, err
+		}
+		if migration.From != nil {
+			if _, err := sess.Where("owner_id = ?", mu.User.ID).And("login_source_id = ?", migration.From.ID).
+				Cols("login_source_id").Update(&PublicKey{}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := sess.Commit(); err != nil {
+		return nil, err
+	}
+
+	migrated := len(migration.Users) - len(migration.Conflicts())
+	log.Info("Migrated %d users from login source %d to login source %d, %d users could not be migrated",
+		migrated, opts.FromSourceID, opts.ToSourceID, len(migration.Users)-migrated)
+	return migration, nil
+}
+
+// ClaimMigratedOAuth2User returns the user migrated to an OAuth2 source whose login name is the email of an external
+// user signing in for the first time, updating the login name to the ID of the external user so that the next sign ins
+// find the user by it. It returns nil if there is no such user or the source did not verify the email, as anyone could
+// take the user over with an unverified email.
+func ClaimMigratedOAuth2User(source *LoginSource, externalID, email string, emailVerified bool) (*User, error) {
+	if len(email) == 0 || !emailVerified {
+		return nil, nil
+	}
+	var users []*User
+	if err := x.Where("login_type = ?", LoginOAuth2).
+		And("login_source = ?", source.ID).
+		And("LOWER(login_name) = ?", strings.ToLower(email)).
+		Find(&users); err != nil {
+		return nil, err
+	}
+	if len(users) != 1 {
+		return nil, nil
+	}
+
+	u := users[0]
+	u.LoginName = externalID
+	if err := UpdateUserCols(u, "login_name"); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateLoginSource(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	source := &LoginSource{Type: LoginPAM, Name: "pam", IsActived: true, Cfg: &PAMConfig{ServiceName: "gitea"}}
+	_, err := x.Insert(source)
+	assert.NoError(t, err)
+
+	_, err = PreviewLoginSourceMigration(&MigrateLoginSourceOptions{})
+	assert.True(t, IsErrLoginSourceMigrationInvalid(err))
+	_, err = PreviewLoginSourceMigration(&MigrateLoginSourceOptions{ToSourceID: source.ID, Mapping: "full_name"})
+	assert.True(t, IsErrLoginSourceMigrationInvalid(err))
+	_, err = PreviewLoginSourceMigration(&MigrateLoginSourceOptions{ToSourceID: NonexistentID, Mapping: LoginSourceMappingEmail})
+	assert.True(t, IsErrLoginSourceNotExist(err))
+
+	// user8 already signs in with the login name user2@example.com, and user5 shares the email of user4
+	_, err = x.ID(8).Cols("login_type", "login_source", "login_name").
+		Update(&User{LoginType: LoginPAM, LoginSource: source.ID, LoginName: "User2@example.com"})
+	assert.NoError(t, err)
+	_, err = x.ID(5).Cols("email").Update(&User{Email: "user4@example.com"})
+	assert.NoError(t, err)
+	_, err = x.ID(1).Cols("owner_id", "login_source_id").Update(&PublicKey{OwnerID: 8, LoginSourceID: source.ID})
+	assert.NoError(t, err)
+
+	opts := &MigrateLoginSourceOptions{
+		ToSourceID: source.ID,
+		Mapping:    LoginSourceMappingEmail,
+		Usernames:  []string{"user2", "User4", "user5", "user10", "user3"},
+	}
+	migration, err := PreviewLoginSourceMigration(opts)
+	assert.NoError(t, err)
+	conflicts := make(map[string]string, len(migration.Users))
+	for _, mu := range migration.Users {
+		conflicts[mu.User.Name] = mu.Conflict
+	}
+	// user3 is an organization
+	assert.Equal(t, map[string]string{
+		"user2":  LoginSourceConflictLoginNameTaken,
+		"user4":  LoginSourceConflictDuplicate,
+		"user5":  LoginSourceConflictDuplicate,
+		"user10": "",
+	}, conflicts)
+
+	migration, err = MigrateLoginSource(opts)
+	assert.NoError(t, err)
+	assert.Len(t, migration.Conflicts(), 3)
+	AssertExistsAndLoadBean(t, &User{ID: 10, LoginType: LoginPAM, LoginSource: source.ID, LoginName: "user10@example.com"})
+	assert.EqualValues(t, 0, AssertExistsAndLoadBean(t, &User{ID: 2}).(*User).LoginSource)
+
+	// back to the local users, the keys synchronized from the source becoming keys of the users
+	migration, err = MigrateLoginSource(&MigrateLoginSourceOptions{FromSourceID: source.ID})
+	assert.NoError(t, err)
+	assert.Len(t, migration.Users, 2)
+	assert.Empty(t, migration.Conflicts())
+	for _, id := range []int64{8, 10} {
+		u := AssertExistsAndLoadBean(t, &User{ID: id}).(*User)
+		assert.Equal(t, LoginPlain, u.LoginType)
+		assert.EqualValues(t, 0, u.LoginSource)
+		assert.Empty(t, u.LoginName)
+	}
+	key := AssertExistsAndLoadBean(t, &PublicKey{ID: 1}).(*PublicKey)
+	assert.EqualValues(t, 0, key.LoginSourceID)
+}
+
+func TestClaimMigratedOAuth2User(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	source := &LoginSource{ID: 99}
+	_, err := x.ID(10).Cols("login_type", "login_source", "login_name").
+		Update(&User{LoginType: LoginOAuth2, LoginSource: source.ID, LoginName: "user10@example.com"})
+	assert.NoError(t, err)
+
+	// an email the provider did not verify could belong to anyone
+	u, err := ClaimMigratedOAuth2User(source, "external-10", "User10@example.com", false)
+	assert.NoError(t, err)
+	assert.Nil(t, u)
+	AssertExistsAndLoadBean(t, &User{ID: 10, LoginName: "user10@example.com"})
+
+	u, err = ClaimMigratedOAuth2User(source, "external-10", "User10@example.com", true)
+	assert.NoError(t, err)
+	if assert.NotNil(t, u) {
+		assert.EqualValues(t, 10, u.ID)
+	}
+	AssertExistsAndLoadBean(t, &User{ID: 10, LoginName: "external-10"})
+
+	u, err = ClaimMigratedOAuth2User(source, "external-11", "user10@example.com", true)
+	assert.NoError(t, err)
+	assert.Nil(t, u)
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/auth/saml"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginViaSAML(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	oldService := setting.Service
+	defer func() {
+		setting.Service = oldService
+	}()
+
+	source := &LoginSource{
+		Type:      LoginSAML,
+		Name:      "saml",
+		IsActived: true,
+		Cfg: &SAMLConfig{Source: &saml.Source{
+			AttributeUsername: "uid",
+			AttributeEmail:    "mail",
+			AttributeFullName: "displayName",
+		}},
+	}
+	assert.NoError(t, CreateLoginSource(source))
+	assertion := &saml.Assertion{
+		NameID: "_saml-user",
+		Attributes: map[string][]string{
+			"uid":         {"saml-user"},
+			"mail":        {"saml-user@example.com"},
+			"displayName": {"SAML User"},
+		},
+	}
+
+	// the accounts are not registered if the registration is disabled
+	setting.Service.DisableRegistration = true
+	_, err := LoginViaSAML(source, assertion)
+	assert.True(t, IsErrExternalRegistrationDisabled(err))
+	AssertNotExistsBean(t, &User{LowerName: "saml-user"})
+
+	// they are if only the external registration is allowed
+	setting.Service.DisableRegistration = false
+	setting.Service.AllowOnlyExternalRegistration = true
+	setting.Service.RegisterManualConfirm = true
+	user, err := LoginViaSAML(source, assertion)
+	assert.NoError(t, err)
+	if !assert.NotNil(t, user) {
+		return
+	}
+	user = AssertExistsAndLoadBean(t, &User{ID: user.ID}).(*User)
+	assert.Equal(t, "saml-user", user.Name)
+	assert.Equal(t, "saml-user@example.com", user.Email)
+	assert.Equal(t, "SAML User", user.FullName)
+	assert.False(t, user.IsActive)
+
+	// the existing accounts are signed in even if the registration is disabled, their attributes are synchronized
+	setting.Service.DisableRegistration = true
+	assertion.Attributes["mail"] = []string{"SAML-User@example.org"}
+	assertion.Attributes["displayName"] = []string{"Renamed User"}
+	_, err = LoginViaSAML(source, assertion)
+	assert.NoError(t, err)
+	user = AssertExistsAndLoadBean(t, &User{ID: user.ID}).(*User)
+	assert.Equal(t, "saml-user@example.org", user.Email)
+	assert.Equal(t, "Renamed User", user.FullName)
+
+	// the addresses used by other users are not taken over
+	for _, used := range []string{"user1@example.com", "user11@example.com"} {
+		assertion.Attributes["mail"] = []string{used}
+		_, err = LoginViaSAML(source, assertion)
+		assert.NoError(t, err)
+		AssertExistsAndLoadBean(t, &User{ID: user.ID, Email: "saml-user@example.org"})
+	}
+}
@@ -80,6 +80,15 @@ func insertIssue(sess *xorm.Session, issue *Issue) error {
 		}
 	}
 
+	for _, attachment := range issue.Attachments {
+		attachment.IssueID = issue.ID
+	}
+	if len(issue.Attachments) > 0 {
+		if _, err := sess.NoAutoTime().Insert(issue.Attachments); err != nil {
+			return err
+		}
+	}
+
 	cols := make([]string, 0)
 	if !issue.IsPull {
 		sess.ID(issue.RepoID).Incr("num_issues")
@@ -161,6 +170,16 @@ func InsertIssueComments(comments []*Comment) error {
 				return err
 			}
 		}
+
+		for _, attachment := range comment.Attachments {
+			attachment.IssueID = comment.IssueID
+			attachment.CommentID = comment.ID
+		}
+		if len(comment.Attachments) > 0 {
+			if _, err := sess.NoAutoTime().Insert(comment.Attachments); err != nil {
+				return err
+			}
+		}
 	}
 
 	for issueID := range issueIDs {
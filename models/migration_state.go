@@ -0,0 +1,55 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// the entities whose incremental migration from the upstream is tracked
+const (
+	MigrationStateIssue   = "issue"
+	MigrationStateComment = "comment"
+	MigrationStateRelease = "release"
+)
+
+// MigrationState records up to when an entity of a migrated repository has been synced from its upstream, so the
+// next incremental migration only pulls in the newer ones and an interrupted one resumes from there
+type MigrationState struct {
+	ID     int64  `xorm:"pk autoincr"`
+	RepoID int64  `xorm:"UNIQUE(s) NOT NULL"`
+	Entity string `xorm:"UNIQUE(s) VARCHAR(20) NOT NULL"`
+	// CursorUnix is the upstream creation time of the latest synced entity
+	CursorUnix  timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// GetMigrationStates returns the migration states of a repository by entity
+func GetMigrationStates(repoID int64) (map[string]*MigrationState, error) {
+	states := make([]*MigrationState, 0, 3)
+	if err := x.Where("repo_id = ?", repoID).Find(&states); err != nil {
+		return nil, err
+	}
+	statesByEntity := make(map[string]*MigrationState, len(states))
+	for _, state := range states {
+		statesByEntity[state.Entity] = state
+	}
+	return statesByEntity, nil
+}
+
+// UpdateMigrationState sets the cursor of an entity of a repository
+func UpdateMigrationState(repoID int64, entity string, cursor timeutil.TimeStamp) error {
+	state := &MigrationState{RepoID: repoID, Entity: entity, CursorUnix: cursor}
+	has, err := x.Where("repo_id = ? AND entity = ?", repoID, entity).Get(new(MigrationState))
+	if err != nil {
+		return err
+	}
+	if !has {
+		_, err = x.Insert(state)
+		return err
+	}
+	_, err = x.Where("repo_id = ? AND entity = ?", repoID, entity).Cols("cursor_unix").Update(state)
+	return err
+}
@@ -0,0 +1,31 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateMigrationState(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	states, err := GetMigrationStates(1)
+	assert.NoError(t, err)
+	assert.Empty(t, states)
+
+	assert.NoError(t, UpdateMigrationState(1, MigrationStateIssue, 100))
+	assert.NoError(t, UpdateMigrationState(1, MigrationStateRelease, 200))
+	assert.NoError(t, UpdateMigrationState(1, MigrationStateIssue, 300))
+	assert.NoError(t, UpdateMigrationState(2, MigrationStateIssue, 400))
+
+	states, err = GetMigrationStates(1)
+	assert.NoError(t, err)
+	assert.Len(t, states, 2)
+	assert.EqualValues(t, 300, states[MigrationStateIssue].CursorUnix)
+	assert.EqualValues(t, 200, states[MigrationStateRelease].CursorUnix)
+	AssertCount(t, &MigrationState{RepoID: 1, Entity: MigrationStateIssue}, 1)
+}
@@ -302,6 +302,96 @@ var migrations = []Migration{
 	NewMigration("Remove invalid labels from comments", removeInvalidLabels),
 	// v177 -> v178
 	NewMigration("Delete orphaned IssueLabels", deleteOrphanedIssueLabels),
+	// v178 -> v179
+	NewMigration("Add issue collaborator table", addIssueCollaboratorTable),
+	// v179 -> v180
+	NewMigration("Add storage statistic table", addStorageStatisticTable),
+	// v180 -> v181
+	NewMigration("Add webauthn credential table", addWebAuthnCredentialTable),
+	// v181 -> v182
+	NewMigration("Add repo health table", addRepoHealthTable),
+	// v182 -> v183
+	NewMigration("Add SCIM group tables", addSCIMGroupTables),
+	// v183 -> v184
+	NewMigration("Add require code owner reviews to protected branch", addRequireCodeOwnerReviews),
+	// v184 -> v185
+	NewMigration("Add request id to hook task", addRequestIDToHookTask),
+	// v185 -> v186
+	NewMigration("Add merge queue", addMergeQueue),
+	// v186 -> v187
+	NewMigration("Add pull auto merge table", addPullAutoMergeTable),
+	// v187 -> v188
+	NewMigration("Add repository signing key table", addRepoSigningKeyTable),
+	// v188 -> v189
+	NewMigration("Add suggestion columns to comment", addSuggestionColumnsToComment),
+	// v189 -> v190
+	NewMigration("Add start line column to comment", addStartLineToComment),
+	// v190 -> v191
+	NewMigration("Add virus detection table", addVirusDetectionTable),
+	// v191 -> v192
+	NewMigration("Add block on unresolved conversations to protected branch", addBlockOnUnresolvedConversations),
+	// v192 -> v193
+	NewMigration("Add actions tables", addActionsTables),
+	// v193 -> v194
+	NewMigration("Add maintenance mode to repository", addMaintenanceModeToRepository),
+	// v194 -> v195
+	NewMigration("Add repo artifact table", addRepoArtifactTable),
+	// v195 -> v196
+	NewMigration("Add package tables", addPackageTables),
+	// v196 -> v197
+	NewMigration("Add template to issue", addTemplateToIssue),
+	// v197 -> v198
+	NewMigration("Add message to task", addMessageToTask),
+	// v198 -> v199
+	NewMigration("Add merge queue result table", addMergeQueueResult),
+	// v199 -> v200
+	NewMigration("Add maintenance results to repo health", addMaintenanceToRepoHealth),
+	// v200 -> v201
+	NewMigration("Add sync windows to mirrors and push mirror table", addSyncWindowsAndPushMirrors),
+	// v201 -> v202
+	NewMigration("Add ref filters to mirrors", addRefFiltersToMirrors),
+	// v202 -> v203
+	NewMigration("Add user code search table", addUserCodeSearchTable),
+	// v203 -> v204
+	NewMigration("Add failures to mirrors", addFailuresToMirrors),
+	// v204 -> v205
+	NewMigration("Add migration state table", addMigrationStateTable),
+	// v205 -> v206
+	NewMigration("Add profile field tables", addProfileFieldTables),
+	// v206 -> v207
+	NewMigration("Add policy document tables", addPolicyDocumentTables),
+	// v207 -> v208
+	NewMigration("Add user filter table", addUserFilterTable),
+	// v208 -> v209
+	NewMigration("Add issue type table", addIssueTypeTable),
+	// v209 -> v210
+	NewMigration("Add repo event table", addRepoEventTable),
+	// v210 -> v211
+	NewMigration("Add parent id to issues", addIssueParentID),
+	// v211 -> v212
+	NewMigration("Add user email preference table", addUserEmailPreferenceTable),
+	// v212 -> v213
+	NewMigration("Add api usage table", addAPIUsageTable),
+	// v213 -> v214
+	NewMigration("Add recurring issue table", addRecurringIssueTable),
+	// v214 -> v215
+	NewMigration("Add swimlanes to projects and work in progress limits and automation to project boards", addProjectSwimlanesAndBoardWIPLimits),
+	// v215 -> v216
+	NewMigration("Add pull merge requirement table", addPullMergeRequirementTable),
+	// v216 -> v217
+	NewMigration("Add organization projects and repository filters of project boards", addOrgProjectsAndBoardRepoFilters),
+	// v217 -> v218
+	NewMigration("Add weight to issues", addIssueWeight),
+	// v218 -> v219
+	NewMigration("Add due date reminder table", addDueDateReminderTable),
+	// v219 -> v220
+	NewMigration("Add issue SLA tables", addIssueSLATables),
+	// v220 -> v221
+	NewMigration("Add issue content history table", addIssueContentHistoryTable),
+	// v221 -> v222
+	NewMigration("Add token hash to actions run jobs", addActionRunJobTokenHash),
+	// v222 -> v223
+	NewMigration("Add approval to actions runs", addActionRunApproval),
 }
 
 // GetCurrentDBVersion returns the current db version
@@ -0,0 +1,45 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addStorageStatisticTable(x *xorm.Engine) error {
+	type StorageUsage struct {
+		ID   int64
+		Name string
+		Size int64
+	}
+
+	type StorageStatistic struct {
+		ID             int64 `xorm:"pk autoincr"`
+		RepositorySize int64 `xorm:"NOT NULL DEFAULT 0"`
+		LFSSize        int64 `xorm:"NOT NULL DEFAULT 0"`
+		AttachmentSize int64 `xorm:"NOT NULL DEFAULT 0"`
+		AvatarSize     int64 `xorm:"NOT NULL DEFAULT 0"`
+		RepoAvatarSize int64 `xorm:"NOT NULL DEFAULT 0"`
+
+		NumUsers int64 `xorm:"NOT NULL DEFAULT 0"`
+		NumOrgs  int64 `xorm:"NOT NULL DEFAULT 0"`
+		NumRepos int64 `xorm:"NOT NULL DEFAULT 0"`
+
+		LargestRepositories        []*StorageUsage `xorm:"JSON TEXT"`
+		LargestLFSOwners           []*StorageUsage `xorm:"JSON TEXT"`
+		LargestAttachmentUploaders []*StorageUsage `xorm:"JSON TEXT"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	if err := x.Sync2(new(StorageStatistic)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
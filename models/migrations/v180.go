@@ -0,0 +1,39 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// WebAuthnCredential represents a discoverable WebAuthn credential
+type WebAuthnCredential struct {
+	ID           int64 `xorm:"pk autoincr"`
+	Name         string
+	UserID       int64  `xorm:"INDEX"`
+	CredentialID string `xorm:"VARCHAR(410) UNIQUE"`
+	PublicKey    []byte
+	AAGUID       []byte
+	SignCount    uint32             `xorm:"BIGINT"`
+	CreatedUnix  timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix  timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// TableName sets the database table name to be the correct one, as the
+// autogenerated table name for this struct is "web_authn_credential".
+func (cred *WebAuthnCredential) TableName() string {
+	return "webauthn_credential"
+}
+
+func addWebAuthnCredentialTable(x *xorm.Engine) error {
+	if err := x.Sync2(new(WebAuthnCredential)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addRepoHealthTable(x *xorm.Engine) error {
+	type RepoHealth struct {
+		ID     int64 `xorm:"pk autoincr"`
+		RepoID int64 `xorm:"UNIQUE NOT NULL"`
+
+		LastGCUnix     timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+		LastGCDuration int64              `xorm:"NOT NULL DEFAULT 0"`
+		LastGCError    string             `xorm:"TEXT"`
+
+		LastFsckUnix     timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+		LastFsckDuration int64              `xorm:"NOT NULL DEFAULT 0"`
+		LastFsckError    string             `xorm:"TEXT"`
+	}
+
+	if err := x.Sync2(new(RepoHealth)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// SCIMGroup represents a group provisioned through SCIM
+type SCIMGroup struct {
+	ID          int64              `xorm:"pk autoincr"`
+	DisplayName string             `xorm:"NOT NULL"`
+	LowerName   string             `xorm:"UNIQUE NOT NULL"`
+	ExternalID  string             `xorm:"INDEX"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// TableName sets the database table name to be the correct one, as the
+// autogenerated table name for this struct is "s_c_i_m_group".
+func (g *SCIMGroup) TableName() string {
+	return "scim_group"
+}
+
+// SCIMGroupMember represents the membership of a user in a SCIM group
+type SCIMGroupMember struct {
+	ID      int64 `xorm:"pk autoincr"`
+	GroupID int64 `xorm:"UNIQUE(s) NOT NULL"`
+	UserID  int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+}
+
+// TableName sets the database table name to be the correct one, as the
+// autogenerated table name for this struct is "s_c_i_m_group_member".
+func (m *SCIMGroupMember) TableName() string {
+	return "scim_group_member"
+}
+
+func addSCIMGroupTables(x *xorm.Engine) error {
+	if err := x.Sync2(new(SCIMGroup), new(SCIMGroupMember)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
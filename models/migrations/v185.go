@@ -0,0 +1,44 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addMergeQueue(x *xorm.Engine) error {
+	type ProtectedBranch struct {
+		EnableMergeQueue bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	type MergeQueueEntry struct {
+		ID           int64  `xorm:"pk autoincr"`
+		PullID       int64  `xorm:"UNIQUE NOT NULL"`
+		RepoID       int64  `xorm:"INDEX(s) NOT NULL"`
+		BaseBranch   string `xorm:"INDEX(s) NOT NULL"`
+		DoerID       int64  `xorm:"NOT NULL"`
+		MergeStyle   string `xorm:"VARCHAR(50)"`
+		Message      string `xorm:"TEXT"`
+		HeadCommitID string `xorm:"VARCHAR(40)"`
+		Status       int    `xorm:"NOT NULL DEFAULT 0"`
+		BaseCommitID string `xorm:"VARCHAR(40)"`
+		TestCommitID string `xorm:"VARCHAR(40) INDEX"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	if err := x.Sync2(new(ProtectedBranch)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	if err := x.Sync2(new(MergeQueueEntry)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
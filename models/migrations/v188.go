@@ -0,0 +1,24 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addSuggestionColumnsToComment(x *xorm.Engine) error {
+	type Comment struct {
+		ID                  int64  `xorm:"pk autoincr"`
+		SuggestionCommitSHA string `xorm:"VARCHAR(40)"`
+		SuggestionApplierID int64
+	}
+
+	if err := x.Sync2(new(Comment)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addVirusDetectionTable(x *xorm.Engine) error {
+	type VirusDetection struct {
+		ID          int64              `xorm:"pk autoincr"`
+		Kind        string             `xorm:"VARCHAR(20) NOT NULL"`
+		Name        string             `xorm:"NOT NULL"`
+		Signature   string             `xorm:"NOT NULL"`
+		Action      string             `xorm:"VARCHAR(20) NOT NULL"`
+		UploaderID  int64              `xorm:"INDEX"`
+		RepoID      int64              `xorm:"INDEX"`
+		Path        string             `xorm:"TEXT"`
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	if err := x.Sync2(new(VirusDetection)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addActionsTables(x *xorm.Engine) error {
+	type ActionRunner struct {
+		ID             int64              `xorm:"pk autoincr"`
+		UUID           string             `xorm:"CHAR(36) UNIQUE"`
+		Name           string             `xorm:"VARCHAR(255)"`
+		TokenHash      string             `xorm:"UNIQUE"`
+		Labels         []string           `xorm:"JSON TEXT"`
+		LastOnlineUnix timeutil.TimeStamp `xorm:"INDEX"`
+		CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix    timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type ActionRunnerToken struct {
+		ID          int64  `xorm:"pk autoincr"`
+		Token       string `xorm:"UNIQUE"`
+		IsActive    bool
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type ActionRun struct {
+		ID            int64  `xorm:"pk autoincr"`
+		Title         string `xorm:"TEXT"`
+		RepoID        int64  `xorm:"INDEX"`
+		WorkflowID    string
+		WorkflowName  string
+		TriggerUserID int64
+		Ref           string `xorm:"VARCHAR(255)"`
+		CommitSHA     string `xorm:"VARCHAR(40) INDEX"`
+		Event         string `xorm:"VARCHAR(50)"`
+		Status        int    `xorm:"INDEX"`
+		StartedUnix   timeutil.TimeStamp
+		StoppedUnix   timeutil.TimeStamp
+		CreatedUnix   timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix   timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type ActionRunJob struct {
+		ID              int64  `xorm:"pk autoincr"`
+		RunID           int64  `xorm:"INDEX"`
+		RepoID          int64  `xorm:"INDEX"`
+		CommitSHA       string `xorm:"VARCHAR(40)"`
+		JobID           string
+		Name            string
+		Needs           []string `xorm:"JSON TEXT"`
+		RunsOn          []string `xorm:"JSON TEXT"`
+		WorkflowPayload string   `xorm:"LONGTEXT"`
+		Status          int      `xorm:"INDEX"`
+		RunnerID        int64    `xorm:"INDEX"`
+		LogSize         int64
+		StartedUnix     timeutil.TimeStamp
+		StoppedUnix     timeutil.TimeStamp
+		CreatedUnix     timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix     timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type ActionArtifact struct {
+		ID          int64  `xorm:"pk autoincr"`
+		RunID       int64  `xorm:"INDEX UNIQUE(s)"`
+		JobID       int64  `xorm:"INDEX"`
+		RepoID      int64  `xorm:"INDEX"`
+		Name        string `xorm:"UNIQUE(s)"`
+		Size        int64
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	if err := x.Sync2(new(ActionRunner), new(ActionRunnerToken), new(ActionRun), new(ActionRunJob), new(ActionArtifact)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addMaintenanceModeToRepository(x *xorm.Engine) error {
+	type Repository struct {
+		IsMaintenance      bool `xorm:"NOT NULL DEFAULT false"`
+		MaintenanceEndUnix timeutil.TimeStamp
+		MaintenanceMessage string `xorm:"TEXT"`
+	}
+
+	if err := x.Sync2(new(Repository)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addRepoArtifactTable(x *xorm.Engine) error {
+	type RepoArtifact struct {
+		ID            int64  `xorm:"pk autoincr"`
+		UUID          string `xorm:"uuid UNIQUE"`
+		RepoID        int64  `xorm:"INDEX"`
+		UploaderID    int64  `xorm:"INDEX"`
+		Name          string
+		CommitSHA     string `xorm:"INDEX VARCHAR(40)"`
+		Ref           string
+		Size          int64
+		DownloadCount int64              `xorm:"NOT NULL DEFAULT 0"`
+		ExpiredUnix   timeutil.TimeStamp `xorm:"INDEX"`
+		CreatedUnix   timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(RepoArtifact)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addMergeQueueResult(x *xorm.Engine) error {
+	type MergeQueueResult struct {
+		ID              int64  `xorm:"pk autoincr"`
+		RepoID          int64  `xorm:"INDEX(s) NOT NULL"`
+		BaseBranch      string `xorm:"INDEX(s) NOT NULL"`
+		PullID          int64  `xorm:"INDEX NOT NULL"`
+		DoerID          int64  `xorm:"NOT NULL"`
+		IsMerged        bool   `xorm:"NOT NULL DEFAULT false"`
+		Reason          string `xorm:"TEXT"`
+		TestCommitID    string `xorm:"VARCHAR(40)"`
+		AddedUnix       timeutil.TimeStamp
+		TestStartedUnix timeutil.TimeStamp
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	if err := x.Sync2(new(MergeQueueResult)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addMaintenanceToRepoHealth(x *xorm.Engine) error {
+	type RepoHealth struct {
+		LastMaintenanceUnix     timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+		LastMaintenanceDuration int64              `xorm:"NOT NULL DEFAULT 0"`
+		LastMaintenanceError    string             `xorm:"TEXT"`
+		LastMaintenanceTasks    string
+		GCObjectCount           int64 `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(RepoHealth)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addSyncWindowsAndPushMirrors(x *xorm.Engine) error {
+	type Mirror struct {
+		SyncWindowStart int `xorm:"NOT NULL DEFAULT 0"`
+		SyncWindowEnd   int `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	type PushMirror struct {
+		ID              int64 `xorm:"pk autoincr"`
+		RepoID          int64 `xorm:"INDEX"`
+		Interval        time.Duration
+		SyncWindowStart int                `xorm:"NOT NULL DEFAULT 0"`
+		SyncWindowEnd   int                `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix     timeutil.TimeStamp `xorm:"created"`
+		LastUpdateUnix  timeutil.TimeStamp `xorm:"INDEX"`
+		NextUpdateUnix  timeutil.TimeStamp `xorm:"INDEX"`
+		LastError       string             `xorm:"TEXT"`
+	}
+
+	if err := x.Sync2(new(Mirror), new(PushMirror)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
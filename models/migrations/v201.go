@@ -0,0 +1,28 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addRefFiltersToMirrors(x *xorm.Engine) error {
+	type Mirror struct {
+		IncludeRefs []string `xorm:"JSON TEXT"`
+		ExcludeRefs []string `xorm:"JSON TEXT"`
+	}
+
+	type PushMirror struct {
+		IncludeRefs []string `xorm:"JSON TEXT"`
+		ExcludeRefs []string `xorm:"JSON TEXT"`
+	}
+
+	if err := x.Sync2(new(Mirror), new(PushMirror)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addUserCodeSearchTable(x *xorm.Engine) error {
+	type UserCodeSearch struct {
+		ID          int64  `xorm:"pk autoincr"`
+		UserID      int64  `xorm:"INDEX NOT NULL"`
+		Keyword     string `xorm:"NOT NULL"`
+		IsMatch     bool   `xorm:"NOT NULL DEFAULT false"`
+		Org         string
+		Repos       string             `xorm:"VARCHAR(1024)"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX"`
+	}
+
+	if err := x.Sync2(new(UserCodeSearch)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addFailuresToMirrors(x *xorm.Engine) error {
+	type Mirror struct {
+		LastError           string `xorm:"TEXT"`
+		ConsecutiveFailures int    `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	type PushMirror struct {
+		ConsecutiveFailures int `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(Mirror), new(PushMirror)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addMigrationStateTable(x *xorm.Engine) error {
+	type MigrationState struct {
+		ID          int64              `xorm:"pk autoincr"`
+		RepoID      int64              `xorm:"UNIQUE(s) NOT NULL"`
+		Entity      string             `xorm:"UNIQUE(s) VARCHAR(20) NOT NULL"`
+		CursorUnix  timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	if err := x.Sync2(new(MigrationState)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addProfileFieldTables(x *xorm.Engine) error {
+	type ProfileField struct {
+		ID          int64              `xorm:"pk autoincr"`
+		Name        string             `xorm:"UNIQUE NOT NULL"`
+		Label       string             `xorm:"NOT NULL"`
+		Description string             `xorm:"TEXT"`
+		Type        int                `xorm:"NOT NULL DEFAULT 0"`
+		Visibility  int                `xorm:"NOT NULL DEFAULT 0"`
+		IsRequired  bool               `xorm:"NOT NULL DEFAULT false"`
+		SortOrder   int                `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type ProfileFieldValue struct {
+		ID      int64  `xorm:"pk autoincr"`
+		FieldID int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		UserID  int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Value   string `xorm:"VARCHAR(1024) NOT NULL"`
+	}
+
+	if err := x.Sync2(new(ProfileField), new(ProfileFieldValue)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
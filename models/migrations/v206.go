@@ -0,0 +1,41 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addPolicyDocumentTables(x *xorm.Engine) error {
+	type PolicyDocument struct {
+		ID          int64              `xorm:"pk autoincr"`
+		Kind        int                `xorm:"UNIQUE(s) NOT NULL"`
+		Version     int                `xorm:"UNIQUE(s) NOT NULL"`
+		Title       string             `xorm:"NOT NULL"`
+		Content     string             `xorm:"TEXT NOT NULL"`
+		PublisherID int64              `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	type PolicyConsent struct {
+		ID           int64              `xorm:"pk autoincr"`
+		UserID       int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		DocumentID   int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		AcceptedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	type User struct {
+		MustAcceptPolicies bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	if err := x.Sync2(new(PolicyDocument), new(PolicyConsent), new(User)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addUserFilterTable(x *xorm.Engine) error {
+	type UserFilter struct {
+		ID          int64  `xorm:"pk autoincr"`
+		UserID      int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		RepoID      int64  `xorm:"UNIQUE(s) INDEX NOT NULL DEFAULT 0"`
+		IsPull      bool   `xorm:"UNIQUE(s) NOT NULL DEFAULT false"`
+		Name        string `xorm:"UNIQUE(s) NOT NULL"`
+		ViewType    string
+		SortType    string
+		State       string
+		Keyword     string
+		Labels      string             `xorm:"VARCHAR(1024)"`
+		MilestoneID int64              `xorm:"NOT NULL DEFAULT 0"`
+		AssigneeID  int64              `xorm:"NOT NULL DEFAULT 0"`
+		Repos       string             `xorm:"VARCHAR(1024)"`
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	if err := x.Sync2(new(UserFilter)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
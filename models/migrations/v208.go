@@ -0,0 +1,39 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addIssueTypeTable(x *xorm.Engine) error {
+	type IssueType struct {
+		ID           int64  `xorm:"pk autoincr"`
+		OrgID        int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
+		RepoID       int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
+		Name         string `xorm:"NOT NULL"`
+		Description  string
+		Color        string `xorm:"VARCHAR(7)"`
+		TemplateFile string
+		CreatedUnix  timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix  timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type Issue struct {
+		TypeID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(IssueType)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	if err := x.Sync2(new(Issue)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
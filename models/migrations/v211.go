@@ -0,0 +1,25 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addUserEmailPreferenceTable(x *xorm.Engine) error {
+	type UserEmailPreference struct {
+		ID      int64  `xorm:"pk autoincr"`
+		UID     int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Event   string `xorm:"VARCHAR(20) UNIQUE(s) NOT NULL"`
+		Enabled bool   `xorm:"NOT NULL DEFAULT true"`
+	}
+
+	if err := x.Sync2(new(UserEmailPreference)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
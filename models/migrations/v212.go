@@ -0,0 +1,30 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addAPIUsageTable(x *xorm.Engine) error {
+	type APIUsage struct {
+		ID           int64              `xorm:"pk autoincr"`
+		UserID       int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		TokenID      int64              `xorm:"UNIQUE(s) INDEX NOT NULL DEFAULT 0"`
+		AppID        int64              `xorm:"UNIQUE(s) INDEX NOT NULL DEFAULT 0"`
+		Day          timeutil.TimeStamp `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		NumRequests  int64              `xorm:"NOT NULL DEFAULT 0"`
+		LastUsedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(APIUsage)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addRecurringIssueTable(x *xorm.Engine) error {
+	type RecurringIssue struct {
+		ID       int64  `xorm:"pk autoincr"`
+		RepoID   int64  `xorm:"INDEX NOT NULL"`
+		DoerID   int64  `xorm:"NOT NULL"`
+		Title    string `xorm:"NOT NULL"`
+		Content  string `xorm:"TEXT"`
+		Labels   string `xorm:"VARCHAR(1024)"`
+		Schedule string `xorm:"NOT NULL"`
+		IsActive bool   `xorm:"INDEX NOT NULL DEFAULT true"`
+
+		NextRunUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+		LastRunUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		LastIssueID int64              `xorm:"NOT NULL DEFAULT 0"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	if err := x.Sync2(new(RecurringIssue)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addProjectSwimlanesAndBoardWIPLimits(x *xorm.Engine) error {
+	type Project struct {
+		SwimlaneType uint8 `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	type ProjectBoard struct {
+		WIPLimit        int  `xorm:"NOT NULL DEFAULT 0"`
+		AutoMoveOnClose bool `xorm:"NOT NULL DEFAULT false"`
+		AutoMoveOnMerge bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	if err := x.Sync2(new(Project)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	if err := x.Sync2(new(ProjectBoard)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
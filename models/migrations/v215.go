@@ -0,0 +1,34 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addPullMergeRequirementTable(x *xorm.Engine) error {
+	type PullMergeRequirement struct {
+		ID          int64  `xorm:"pk autoincr"`
+		RepoID      int64  `xorm:"INDEX NOT NULL"`
+		PullID      int64  `xorm:"UNIQUE(s) NOT NULL"`
+		Name        string `xorm:"UNIQUE(s) VARCHAR(255) NOT NULL"`
+		IsSatisfied bool   `xorm:"NOT NULL DEFAULT false"`
+		Description string `xorm:"TEXT"`
+		TargetURL   string `xorm:"TEXT"`
+		CreatorID   int64  `xorm:"NOT NULL"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	if err := x.Sync2(new(PullMergeRequirement)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
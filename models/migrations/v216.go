@@ -0,0 +1,29 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addOrgProjectsAndBoardRepoFilters(x *xorm.Engine) error {
+	type Project struct {
+		OwnerID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	type ProjectBoard struct {
+		RepoFilter string `xorm:"TEXT"`
+	}
+
+	if err := x.Sync2(new(Project)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	if err := x.Sync2(new(ProjectBoard)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
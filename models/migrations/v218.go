@@ -0,0 +1,29 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addDueDateReminderTable(x *xorm.Engine) error {
+	type DueDateReminder struct {
+		ID           int64              `xorm:"pk autoincr"`
+		RepoID       int64              `xorm:"INDEX NOT NULL"`
+		IssueID      int64              `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+		MilestoneID  int64              `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+		DeadlineUnix timeutil.TimeStamp `xorm:"UNIQUE(s) NOT NULL"`
+		CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(DueDateReminder)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
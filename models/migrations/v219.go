@@ -0,0 +1,43 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addIssueSLATables(x *xorm.Engine) error {
+	type IssueSLA struct {
+		ID                 int64  `xorm:"pk autoincr"`
+		RepoID             int64  `xorm:"INDEX NOT NULL"`
+		DoerID             int64  `xorm:"NOT NULL"`
+		Name               string `xorm:"NOT NULL"`
+		LabelID            int64  `xorm:"NOT NULL DEFAULT 0"`
+		FirstResponseHours int64  `xorm:"NOT NULL DEFAULT 0"`
+		ResolutionHours    int64  `xorm:"NOT NULL DEFAULT 0"`
+		BreachLabelID      int64  `xorm:"NOT NULL DEFAULT 0"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type IssueSLABreach struct {
+		ID          int64              `xorm:"pk autoincr"`
+		SLAID       int64              `xorm:"'sla_id' UNIQUE(s) NOT NULL"`
+		RepoID      int64              `xorm:"INDEX NOT NULL"`
+		IssueID     int64              `xorm:"UNIQUE(s) NOT NULL"`
+		Target      string             `xorm:"VARCHAR(20) UNIQUE(s) NOT NULL"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(IssueSLA), new(IssueSLABreach)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addIssueContentHistoryTable(x *xorm.Engine) error {
+	type IssueContentHistory struct {
+		ID             int64              `xorm:"pk autoincr"`
+		IssueID        int64              `xorm:"INDEX NOT NULL"`
+		CommentID      int64              `xorm:"INDEX NOT NULL DEFAULT 0"`
+		PosterID       int64              `xorm:"NOT NULL"`
+		Content        string             `xorm:"LONGTEXT"`
+		IsFirstCreated bool               `xorm:"NOT NULL DEFAULT false"`
+		EditedUnix     timeutil.TimeStamp `xorm:"INDEX"`
+	}
+
+	if err := x.Sync2(new(IssueContentHistory)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
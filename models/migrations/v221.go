@@ -0,0 +1,22 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addActionRunJobTokenHash(x *xorm.Engine) error {
+	type ActionRunJob struct {
+		TokenHash string `xorm:"INDEX"`
+	}
+
+	if err := x.Sync2(new(ActionRunJob)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addActionRunApproval(x *xorm.Engine) error {
+	type ActionRun struct {
+		NeedApproval bool  `xorm:"NOT NULL DEFAULT false"`
+		ApprovedBy   int64 `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(ActionRun)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -88,6 +88,7 @@ func init() {
 		new(IssueLabel),
 		new(Milestone),
 		new(Mirror),
+		new(PushMirror),
 		new(Release),
 		new(LoginSource),
 		new(Webhook),
@@ -109,7 +110,45 @@ func init() {
 		new(ExternalLoginUser),
 		new(ProtectedBranch),
 		new(UserOpenID),
+		new(UserCodeSearch),
+		new(UserFilter),
+		new(UserEmailPreference),
+		new(APIUsage),
+		new(IssueType),
+		new(RepoEvent),
+		new(RecurringIssue),
+		new(DueDateReminder),
+		new(IssueSLA),
+		new(IssueSLABreach),
+		new(IssueContentHistory),
 		new(IssueWatch),
+		new(IssueCollaborator),
+		new(StorageStatistic),
+		new(WebAuthnCredential),
+		new(RepoHealth),
+		new(MigrationState),
+		new(ProfileField),
+		new(ProfileFieldValue),
+		new(PolicyDocument),
+		new(PolicyConsent),
+		new(MergeQueueEntry),
+		new(MergeQueueResult),
+		new(PullAutoMerge),
+		new(PullMergeRequirement),
+		new(RepoSigningKey),
+		new(VirusDetection),
+		new(ActionRunner),
+		new(ActionRunnerToken),
+		new(ActionRun),
+		new(ActionRunJob),
+		new(ActionArtifact),
+		new(RepoArtifact),
+		new(Package),
+		new(PackageVersion),
+		new(PackageFile),
+		new(PackageBlob),
+		new(SCIMGroup),
+		new(SCIMGroupMember),
 		new(CommitStatus),
 		new(Stopwatch),
 		new(TrackedTime),
@@ -247,10 +247,7 @@ func createOrUpdateIssueNotifications(e Engine, issueID, commentID, notification
 
 			return err
 		}
-		if issue.IsPull && !issue.Repo.checkUnitUser(e, user, UnitTypePullRequests) {
-			continue
-		}
-		if !issue.IsPull && !issue.Repo.checkUnitUser(e, user, UnitTypeIssues) {
+		if !issue.checkReadable(e, user) {
 			continue
 		}
 
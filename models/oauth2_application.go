@@ -255,6 +255,9 @@ func deleteOAuth2Application(sess *xorm.Session, id, userid int64) error {
 	if _, err := sess.Where("application_id = ?", id).Delete(new(OAuth2Grant)); err != nil {
 		return err
 	}
+	if _, err := sess.Where("app_id = ?", id).Delete(new(APIUsage)); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -135,6 +135,12 @@ func (org *User) RemoveOrgRepo(repoID int64) error {
 	return org.removeOrgRepo(x, repoID)
 }
 
+// GetOrgRepositories returns all the repositories of an organization, ordered by name
+func GetOrgRepositories(orgID int64) ([]*Repository, error) {
+	repos := make([]*Repository, 0, 10)
+	return repos, x.Where("owner_id = ?", orgID).Asc("lower_name").Find(&repos)
+}
+
 // CreateOrganization creates record of a new organization.
 func CreateOrganization(org, owner *User) (err error) {
 	if !owner.CanCreateOrganization() {
@@ -296,10 +302,27 @@ func deleteOrg(e *xorm.Session, u *User) error {
 		&OrgUser{OrgID: u.ID},
 		&TeamUser{OrgID: u.ID},
 		&TeamUnit{OrgID: u.ID},
+		&IssueType{OrgID: u.ID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
 
+	if err = deletePackagesByOwnerID(e, u.ID); err != nil {
+		return fmt.Errorf("deletePackagesByOwnerID: %v", err)
+	}
+
+	projects, _, err := getProjects(e, ProjectSearchOptions{
+		OwnerID: u.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("get projects: %v", err)
+	}
+	for i := range projects {
+		if err := deleteProjectByID(e, projects[i].ID); err != nil {
+			return fmt.Errorf("delete project [%d]: %v", projects[i].ID, err)
+		}
+	}
+
 	if _, err = e.ID(u.ID).Delete(new(User)); err != nil {
 		return fmt.Errorf("Delete: %v", err)
 	}
@@ -0,0 +1,154 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// SyncUserTeams synchronizes the team memberships of the user with the groups
+// reported by an external login source. mapping has the form
+// {"group": {"organization": ["team", ...]}}. The user is added to every team
+// one of their groups is mapped to. If removeUnmapped is set, the user is also
+// removed from the mapped teams none of their groups grant access to.
+func SyncUserTeams(user *User, groups []string, mapping map[string]map[string][]string, removeUnmapped bool) error {
+	if len(mapping) == 0 {
+		return nil
+	}
+
+	memberOf := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		memberOf[group] = true
+	}
+
+	// organization name -> team name -> should be member
+	wanted := make(map[string]map[string]bool)
+	for group, orgTeams := range mapping {
+		for orgName, teamNames := range orgTeams {
+			if wanted[orgName] == nil {
+				wanted[orgName] = make(map[string]bool)
+			}
+			for _, teamName := range teamNames {
+				wanted[orgName][teamName] = wanted[orgName][teamName] || memberOf[group]
+			}
+		}
+	}
+
+	for orgName, teams := range wanted {
+		org, err := GetOrgByName(orgName)
+		if err != nil {
+			if IsErrOrgNotExist(err) {
+				log.Warn("SyncUserTeams: organization %s of the team mapping does not exist", orgName)
+				continue
+			}
+			return fmt.Errorf("GetOrgByName: %v", err)
+		}
+		for teamName, isWanted := range teams {
+			if !isWanted && !removeUnmapped {
+				continue
+			}
+			team, err := org.GetTeam(teamName)
+			if err != nil {
+				if IsErrTeamNotExist(err) {
+					log.Warn("SyncUserTeams: team %s of organization %s does not exist", teamName, orgName)
+					continue
+				}
+				return fmt.Errorf("GetTeam: %v", err)
+			}
+			isMember, err := IsTeamMember(org.ID, team.ID, user.ID)
+			if err != nil {
+				return fmt.Errorf("IsTeamMember: %v", err)
+			}
+			if isWanted && !isMember {
+				if err := AddTeamMember(team, user.ID); err != nil {
+					return fmt.Errorf("AddTeamMember: %v", err)
+				}
+			} else if !isWanted && isMember {
+				if err := RemoveTeamMember(team, user.ID); err != nil {
+					if IsErrLastOrgOwner(err) {
+						log.Warn("SyncUserTeams: cannot remove %s from the owners of %s: last owner", user.Name, orgName)
+						continue
+					}
+					return fmt.Errorf("RemoveTeamMember: %v", err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// SyncLDAPGroupTeams synchronizes the team memberships of the users of all active
+// LDAP login sources that map groups to teams with their current LDAP groups.
+func SyncLDAPGroupTeams(ctx context.Context) error {
+	log.Trace("Doing: SyncLDAPGroupTeams")
+
+	sources, err := LoginSources()
+	if err != nil {
+		log.Error("SyncLDAPGroupTeams: %v", err)
+		return err
+	}
+
+	for _, s := range sources {
+		if !s.IsActived || !s.IsLDAP() || !s.LDAP().SyncsTeams() {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			log.Warn("SyncLDAPGroupTeams: Cancelled before synchronization of %s", s.Name)
+			return ErrCancelledf("Before synchronization of %s", s.Name)
+		default:
+		}
+
+		log.Trace("Doing: SyncLDAPGroupTeams[%s]", s.Name)
+
+		mapping, err := s.LDAP().TeamMapping()
+		if err != nil {
+			log.Error("SyncLDAPGroupTeams[%s]: %v", s.Name, err)
+			continue
+		}
+
+		sr, err := s.LDAP().SearchEntries()
+		if err != nil {
+			log.Error("SyncLDAPGroupTeams LDAP source failure [%s], skipped", s.Name)
+			continue
+		}
+		if len(sr) == 0 && !s.LDAP().AllowDeactivateAll {
+			log.Error("LDAP search found no entries but did not report an error. Refusing to remove all users from their teams")
+			continue
+		}
+
+		groups := make(map[string][]string, len(sr))
+		for _, su := range sr {
+			groups[strings.ToLower(su.Username)] = su.Groups
+		}
+
+		var users []*User
+		if err := x.Where("login_type = ?", s.Type).
+			And("login_source = ?", s.ID).
+			Find(&users); err != nil {
+			log.Error("SyncLDAPGroupTeams: %v", err)
+			return err
+		}
+
+		for _, usr := range users {
+			select {
+			case <-ctx.Done():
+				log.Warn("SyncLDAPGroupTeams: Cancelled during synchronization of %s", s.Name)
+				return ErrCancelledf("During synchronization of %s", s.Name)
+			default:
+			}
+
+			// users which are not found in LDAP anymore are in none of the groups
+			if err := SyncUserTeams(usr, groups[usr.LowerName], mapping, s.LDAP().GroupTeamMapRemoval); err != nil {
+				log.Error("SyncLDAPGroupTeams[%s]: Error synchronizing teams of %s: %v", s.Name, usr.Name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncUserTeams(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	mapping := map[string]map[string][]string{
+		"developers": {"user3": {"test_team"}},
+		"reviewers":  {"user3": {"team1"}},
+		"unknown":    {"no-such-org": {"team"}, "user3": {"no-such-team"}},
+	}
+
+	// user 4 is a member of team1 and gets added to test_team
+	assert.NoError(t, SyncUserTeams(user, []string{"developers"}, mapping, false))
+	AssertExistsAndLoadBean(t, &TeamUser{TeamID: 7, UID: 4})
+	AssertExistsAndLoadBean(t, &TeamUser{TeamID: 2, UID: 4})
+
+	// team1 is not granted by any group anymore
+	assert.NoError(t, SyncUserTeams(user, []string{"developers"}, mapping, true))
+	AssertExistsAndLoadBean(t, &TeamUser{TeamID: 7, UID: 4})
+	AssertNotExistsBean(t, &TeamUser{TeamID: 2, UID: 4})
+
+	// teams which are not part of the mapping are left alone
+	assert.NoError(t, SyncUserTeams(user, nil, map[string]map[string][]string{}, true))
+	AssertExistsAndLoadBean(t, &TeamUser{TeamID: 7, UID: 4})
+}
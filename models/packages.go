@@ -0,0 +1,647 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// PackageType represents the format of a package
+type PackageType int
+
+// Enumerate all the package types
+const (
+	PackageTypeContainer PackageType = iota + 1 // 1
+	PackageTypeNpm                              // 2
+	PackageTypePyPI                             // 3
+	PackageTypeMaven                            // 4
+	PackageTypeNuGet                            // 5
+)
+
+// PackageTypes lists all the package types
+var PackageTypes = []PackageType{
+	PackageTypeContainer,
+	PackageTypeNpm,
+	PackageTypePyPI,
+	PackageTypeMaven,
+	PackageTypeNuGet,
+}
+
+// Name returns the name of the package type used in URLs
+func (pt PackageType) Name() string {
+	switch pt {
+	case PackageTypeContainer:
+		return "container"
+	case PackageTypeNpm:
+		return "npm"
+	case PackageTypePyPI:
+		return "pypi"
+	case PackageTypeMaven:
+		return "maven"
+	case PackageTypeNuGet:
+		return "nuget"
+	}
+	return ""
+}
+
+// DisplayName returns the name of the package type shown to users
+func (pt PackageType) DisplayName() string {
+	switch pt {
+	case PackageTypeContainer:
+		return "Container"
+	case PackageTypeNpm:
+		return "npm"
+	case PackageTypePyPI:
+		return "PyPI"
+	case PackageTypeMaven:
+		return "Maven"
+	case PackageTypeNuGet:
+		return "NuGet"
+	}
+	return ""
+}
+
+// SizeLimit returns the maximum size of a file of the package type, -1 means no limit
+func (pt PackageType) SizeLimit() int64 {
+	switch pt {
+	case PackageTypeContainer:
+		return setting.Packages.LimitSizeContainer
+	case PackageTypeNpm:
+		return setting.Packages.LimitSizeNpm
+	case PackageTypePyPI:
+		return setting.Packages.LimitSizePyPI
+	case PackageTypeMaven:
+		return setting.Packages.LimitSizeMaven
+	case PackageTypeNuGet:
+		return setting.Packages.LimitSizeNuGet
+	}
+	return -1
+}
+
+// PackageTypeFromName returns the package type with the given name, 0 if there is none
+func PackageTypeFromName(name string) PackageType {
+	for _, pt := range PackageTypes {
+		if pt.Name() == name {
+			return pt
+		}
+	}
+	return 0
+}
+
+// Package represents a package of a user or an organization
+type Package struct {
+	ID          int64              `xorm:"pk autoincr"`
+	OwnerID     int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Type        PackageType        `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Name        string             `xorm:"NOT NULL"`
+	LowerName   string             `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+
+	Owner *User `xorm:"-"`
+}
+
+// LoadOwner loads the owner of the package
+func (p *Package) LoadOwner() (err error) {
+	if p.Owner == nil {
+		p.Owner, err = GetUserByID(p.OwnerID)
+	}
+	return err
+}
+
+// Link returns the link of the package page, the owner must be loaded
+func (p *Package) Link() string {
+	return fmt.Sprintf("%s/-/packages/%s/%s", p.Owner.HomeLink(), p.Type.Name(), url.PathEscape(p.Name))
+}
+
+// PackageVersion represents a published version of a package
+type PackageVersion struct {
+	ID           int64  `xorm:"pk autoincr"`
+	PackageID    int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	CreatorID    int64  `xorm:"NOT NULL DEFAULT 0"`
+	Version      string `xorm:"NOT NULL"`
+	LowerVersion string `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	// IsInternal marks versions which hold files of the package but are no versions for its users,
+	// e.g. the blobs uploaded for container images
+	IsInternal    bool               `xorm:"INDEX NOT NULL DEFAULT false"`
+	MetadataJSON  string             `xorm:"TEXT"`
+	DownloadCount int64              `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix   timeutil.TimeStamp `xorm:"INDEX created"`
+
+	Creator *User `xorm:"-"`
+}
+
+// LoadCreator loads the user who published the version
+func (pv *PackageVersion) LoadCreator() (err error) {
+	if pv.Creator == nil {
+		if pv.Creator, err = GetUserByID(pv.CreatorID); err != nil {
+			if !IsErrUserNotExist(err) {
+				return err
+			}
+			pv.Creator = NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// IncreaseDownloadCount increases the download count of the version by one
+func (pv *PackageVersion) IncreaseDownloadCount() error {
+	if _, err := x.Exec("UPDATE `package_version` SET download_count=download_count+1 WHERE id=?", pv.ID); err != nil {
+		return fmt.Errorf("increase package version download count: %v", err)
+	}
+	return nil
+}
+
+// PackageFile represents a file of a package version
+type PackageFile struct {
+	ID          int64              `xorm:"pk autoincr"`
+	VersionID   int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	BlobID      int64              `xorm:"INDEX NOT NULL"`
+	Name        string             `xorm:"NOT NULL"`
+	LowerName   string             `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+
+	Blob *PackageBlob `xorm:"-"`
+}
+
+// LoadBlob loads the blob holding the content of the file
+func (pf *PackageFile) LoadBlob() (err error) {
+	if pf.Blob == nil {
+		pf.Blob, err = GetPackageBlobByID(pf.BlobID)
+	}
+	return err
+}
+
+// PackageBlob represents the content of package files, blobs with the same content are shared
+type PackageBlob struct {
+	ID          int64              `xorm:"pk autoincr"`
+	Size        int64              `xorm:"NOT NULL DEFAULT 0"`
+	HashMD5     string             `xorm:"hash_md5 char(32) INDEX NOT NULL"`
+	HashSHA1    string             `xorm:"hash_sha1 char(40) INDEX NOT NULL"`
+	HashSHA256  string             `xorm:"hash_sha256 char(64) UNIQUE NOT NULL"`
+	HashSHA512  string             `xorm:"hash_sha512 char(128) INDEX NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// RelativePath returns the path of the blob in the packages storage
+func (pb *PackageBlob) RelativePath() string {
+	return path.Join(pb.HashSHA256[0:2], pb.HashSHA256[2:4], pb.HashSHA256)
+}
+
+// ErrPackageNotExist represents a "PackageNotExist" kind of error.
+type ErrPackageNotExist struct {
+	ID   int64
+	Name string
+}
+
+// IsErrPackageNotExist checks if an error is a ErrPackageNotExist.
+func IsErrPackageNotExist(err error) bool {
+	_, ok := err.(ErrPackageNotExist)
+	return ok
+}
+
+func (err ErrPackageNotExist) Error() string {
+	return fmt.Sprintf("package does not exist [id: %d, name: %s]", err.ID, err.Name)
+}
+
+// ErrPackageVersionNotExist represents a "PackageVersionNotExist" kind of error.
+type ErrPackageVersionNotExist struct {
+	ID      int64
+	Version string
+}
+
+// IsErrPackageVersionNotExist checks if an error is a ErrPackageVersionNotExist.
+func IsErrPackageVersionNotExist(err error) bool {
+	_, ok := err.(ErrPackageVersionNotExist)
+	return ok
+}
+
+func (err ErrPackageVersionNotExist) Error() string {
+	return fmt.Sprintf("package version does not exist [id: %d, version: %s]", err.ID, err.Version)
+}
+
+// ErrPackageVersionAlreadyExist represents a "PackageVersionAlreadyExist" kind of error.
+type ErrPackageVersionAlreadyExist struct {
+	Version string
+}
+
+// IsErrPackageVersionAlreadyExist checks if an error is a ErrPackageVersionAlreadyExist.
+func IsErrPackageVersionAlreadyExist(err error) bool {
+	_, ok := err.(ErrPackageVersionAlreadyExist)
+	return ok
+}
+
+func (err ErrPackageVersionAlreadyExist) Error() string {
+	return fmt.Sprintf("package version already exists [version: %s]", err.Version)
+}
+
+// ErrPackageFileNotExist represents a "PackageFileNotExist" kind of error.
+type ErrPackageFileNotExist struct {
+	Name string
+}
+
+// IsErrPackageFileNotExist checks if an error is a ErrPackageFileNotExist.
+func IsErrPackageFileNotExist(err error) bool {
+	_, ok := err.(ErrPackageFileNotExist)
+	return ok
+}
+
+func (err ErrPackageFileNotExist) Error() string {
+	return fmt.Sprintf("package file does not exist [name: %s]", err.Name)
+}
+
+// ErrPackageFileAlreadyExist represents a "PackageFileAlreadyExist" kind of error.
+type ErrPackageFileAlreadyExist struct {
+	Name string
+}
+
+// IsErrPackageFileAlreadyExist checks if an error is a ErrPackageFileAlreadyExist.
+func IsErrPackageFileAlreadyExist(err error) bool {
+	_, ok := err.(ErrPackageFileAlreadyExist)
+	return ok
+}
+
+func (err ErrPackageFileAlreadyExist) Error() string {
+	return fmt.Sprintf("package file already exists [name: %s]", err.Name)
+}
+
+// ErrPackageBlobNotExist represents a "PackageBlobNotExist" kind of error.
+type ErrPackageBlobNotExist struct {
+	ID         int64
+	HashSHA256 string
+}
+
+// IsErrPackageBlobNotExist checks if an error is a ErrPackageBlobNotExist.
+func IsErrPackageBlobNotExist(err error) bool {
+	_, ok := err.(ErrPackageBlobNotExist)
+	return ok
+}
+
+func (err ErrPackageBlobNotExist) Error() string {
+	return fmt.Sprintf("package blob does not exist [id: %d, sha256: %s]", err.ID, err.HashSHA256)
+}
+
+// ErrPackageQuotaExceeded represents a "PackageQuotaExceeded" kind of error.
+type ErrPackageQuotaExceeded struct {
+	Limit int64
+	// IsTotal is true if the limit of the total size of the packages of the owner is exceeded
+	IsTotal bool
+}
+
+// IsErrPackageQuotaExceeded checks if an error is a ErrPackageQuotaExceeded.
+func IsErrPackageQuotaExceeded(err error) bool {
+	_, ok := err.(ErrPackageQuotaExceeded)
+	return ok
+}
+
+func (err ErrPackageQuotaExceeded) Error() string {
+	if err.IsTotal {
+		return fmt.Sprintf("the total size of the packages of the owner would exceed the limit of %d bytes", err.Limit)
+	}
+	return fmt.Sprintf("the file is larger than the limit of %d bytes", err.Limit)
+}
+
+// GetPackageByName returns the package of the owner with the given type and name
+func GetPackageByName(ownerID int64, pt PackageType, name string) (*Package, error) {
+	p := new(Package)
+	has, err := x.Where("owner_id = ? AND type = ? AND lower_name = ?", ownerID, pt, strings.ToLower(name)).Get(p)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPackageNotExist{Name: name}
+	}
+	return p, nil
+}
+
+// GetPackageByID returns the package with the given id
+func GetPackageByID(id int64) (*Package, error) {
+	p := new(Package)
+	has, err := x.ID(id).Get(p)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPackageNotExist{ID: id}
+	}
+	return p, nil
+}
+
+// GetOrCreatePackage returns the package of the owner with the type and name of p, inserting p if it does not exist
+func GetOrCreatePackage(p *Package) (*Package, error) {
+	p.LowerName = strings.ToLower(p.Name)
+	existing, err := GetPackageByName(p.OwnerID, p.Type, p.Name)
+	if err == nil {
+		return existing, nil
+	} else if !IsErrPackageNotExist(err) {
+		return nil, err
+	}
+	if _, err := x.Insert(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FindPackagesOptions represents the options to find the packages of an owner
+type FindPackagesOptions struct {
+	ListOptions
+	OwnerID int64
+	Type    PackageType
+	Keyword string
+}
+
+func (opts *FindPackagesOptions) toConds() builder.Cond {
+	cond := builder.NewCond().And(builder.Eq{"owner_id": opts.OwnerID})
+	if opts.Type != 0 {
+		cond = cond.And(builder.Eq{"type": opts.Type})
+	}
+	if len(opts.Keyword) > 0 {
+		cond = cond.And(builder.Like{"lower_name", strings.ToLower(opts.Keyword)})
+	}
+	return cond
+}
+
+// FindPackages returns the packages of an owner matching the options, most recently updated first
+func FindPackages(opts *FindPackagesOptions) ([]*Package, int64, error) {
+	sess := x.Where(opts.toConds()).Desc("updated_unix")
+	if opts.Page > 0 {
+		sess = opts.setSessionPagination(sess)
+	}
+	packages := make([]*Package, 0, 10)
+	count, err := sess.FindAndCount(&packages)
+	return packages, count, err
+}
+
+// GetPackageVersionByName returns the version of a package, including internal versions
+func GetPackageVersionByName(packageID int64, version string) (*PackageVersion, error) {
+	pv := new(PackageVersion)
+	has, err := x.Where("package_id = ? AND lower_version = ?", packageID, strings.ToLower(version)).Get(pv)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPackageVersionNotExist{Version: version}
+	}
+	return pv, nil
+}
+
+// GetPackageVersionByID returns the package version with the given id
+func GetPackageVersionByID(id int64) (*PackageVersion, error) {
+	pv := new(PackageVersion)
+	has, err := x.ID(id).Get(pv)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPackageVersionNotExist{ID: id}
+	}
+	return pv, nil
+}
+
+// GetPackageVersions returns the versions of a package which are not internal, oldest first
+func GetPackageVersions(packageID int64) ([]*PackageVersion, error) {
+	versions := make([]*PackageVersion, 0, 10)
+	return versions, x.Where("package_id = ? AND is_internal = ?", packageID, false).Asc("created_unix", "id").Find(&versions)
+}
+
+// CreatePackageVersion inserts a new version of a package
+func CreatePackageVersion(pv *PackageVersion) error {
+	pv.LowerVersion = strings.ToLower(pv.Version)
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	has, err := sess.Where("package_id = ? AND lower_version = ?", pv.PackageID, pv.LowerVersion).Exist(new(PackageVersion))
+	if err != nil {
+		return err
+	} else if has {
+		return ErrPackageVersionAlreadyExist{Version: pv.Version}
+	}
+	if _, err := sess.Insert(pv); err != nil {
+		return err
+	}
+	if _, err := sess.ID(pv.PackageID).Cols("updated_unix").Update(&Package{UpdatedUnix: timeutil.TimeStampNow()}); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// UpdatePackageVersionMetadata updates the metadata of a package version
+func UpdatePackageVersionMetadata(pv *PackageVersion) error {
+	_, err := x.ID(pv.ID).Cols("metadata_json").Update(pv)
+	return err
+}
+
+// GetPackageFileByName returns the file of a package version with the given name
+func GetPackageFileByName(versionID int64, name string) (*PackageFile, error) {
+	pf := new(PackageFile)
+	has, err := x.Where("version_id = ? AND lower_name = ?", versionID, strings.ToLower(name)).Get(pf)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPackageFileNotExist{Name: name}
+	}
+	return pf, nil
+}
+
+// GetPackageFileInPackage returns a file with the given name of any version of a package
+func GetPackageFileInPackage(packageID int64, name string) (*PackageFile, *PackageVersion, error) {
+	pf := new(PackageFile)
+	has, err := x.Join("INNER", "package_version", "package_version.id = package_file.version_id").
+		Where("package_version.package_id = ? AND package_file.lower_name = ?", packageID, strings.ToLower(name)).
+		Desc("package_file.id").Get(pf)
+	if err != nil {
+		return nil, nil, err
+	} else if !has {
+		return nil, nil, ErrPackageFileNotExist{Name: name}
+	}
+	pv, err := GetPackageVersionByID(pf.VersionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pf, pv, nil
+}
+
+// GetPackageFiles returns the files of a package version
+func GetPackageFiles(versionID int64) ([]*PackageFile, error) {
+	files := make([]*PackageFile, 0, 5)
+	return files, x.Where("version_id = ?", versionID).Asc("lower_name").Find(&files)
+}
+
+// AddPackageFile adds a file to a package version. An existing file with the same name is replaced
+// if overwrite is true, otherwise ErrPackageFileAlreadyExist is returned.
+func AddPackageFile(pf *PackageFile, overwrite bool) error {
+	pf.LowerName = strings.ToLower(pf.Name)
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	existing := new(PackageFile)
+	has, err := sess.Where("version_id = ? AND lower_name = ?", pf.VersionID, pf.LowerName).Get(existing)
+	if err != nil {
+		return err
+	}
+	if has {
+		if !overwrite {
+			return ErrPackageFileAlreadyExist{Name: pf.Name}
+		}
+		pf.ID = existing.ID
+		if _, err := sess.ID(pf.ID).Cols("blob_id", "name").Update(pf); err != nil {
+			return err
+		}
+	} else if _, err := sess.Insert(pf); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// DeletePackageFile deletes a file of a package version, its blob is removed by the cleanup task
+func DeletePackageFile(pf *PackageFile) error {
+	_, err := x.ID(pf.ID).Delete(new(PackageFile))
+	return err
+}
+
+// GetPackageBlobByID returns the package blob with the given id
+func GetPackageBlobByID(id int64) (*PackageBlob, error) {
+	pb := new(PackageBlob)
+	has, err := x.ID(id).Get(pb)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPackageBlobNotExist{ID: id}
+	}
+	return pb, nil
+}
+
+// GetPackageBlobBySHA256 returns the package blob with the given SHA256 hash
+func GetPackageBlobBySHA256(hash string) (*PackageBlob, error) {
+	pb := new(PackageBlob)
+	has, err := x.Where("hash_sha256 = ?", strings.ToLower(hash)).Get(pb)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPackageBlobNotExist{HashSHA256: hash}
+	}
+	return pb, nil
+}
+
+// GetOrInsertPackageBlob returns the blob with the hash of pb, inserting pb if it does not exist.
+// exists is true if the blob existed before.
+func GetOrInsertPackageBlob(pb *PackageBlob) (_ *PackageBlob, exists bool, err error) {
+	existing, err := GetPackageBlobBySHA256(pb.HashSHA256)
+	if err == nil {
+		return existing, true, nil
+	} else if !IsErrPackageBlobNotExist(err) {
+		return nil, false, err
+	}
+	if _, err := x.Insert(pb); err != nil {
+		return nil, false, err
+	}
+	return pb, false, nil
+}
+
+// GetOrphanedPackageBlobs returns blobs created before the given time which belong to no package file
+func GetOrphanedPackageBlobs(olderThan time.Time, limit int) ([]*PackageBlob, error) {
+	blobs := make([]*PackageBlob, 0, limit)
+	return blobs, x.Where("created_unix < ?", olderThan.Unix()).
+		And(builder.NotIn("id", builder.Select("blob_id").From("package_file"))).
+		Limit(limit).Find(&blobs)
+}
+
+// DeletePackageBlob deletes the database record of a package blob
+func DeletePackageBlob(pb *PackageBlob) error {
+	_, err := x.ID(pb.ID).Delete(new(PackageBlob))
+	return err
+}
+
+// GetPackagesTotalSize returns the total size of the files of all packages of an owner
+func GetPackagesTotalSize(ownerID int64) (int64, error) {
+	return x.Join("INNER", "package_file", "package_file.blob_id = package_blob.id").
+		Join("INNER", "package_version", "package_version.id = package_file.version_id").
+		Join("INNER", "package", "package.id = package_version.package_id").
+		Where("package.owner_id = ?", ownerID).
+		SumInt(new(PackageBlob), "package_blob.size")
+}
+
+// GetPackageOwnerIDs returns the IDs of the users and organizations which own packages
+func GetPackageOwnerIDs() ([]int64, error) {
+	ids := make([]int64, 0, 10)
+	return ids, x.Table("package").Distinct("owner_id").Asc("owner_id").Find(&ids)
+}
+
+// GetPackageIDsByType returns the IDs of all packages of a type
+func GetPackageIDsByType(pt PackageType) ([]int64, error) {
+	ids := make([]int64, 0, 10)
+	return ids, x.Table("package").Cols("id").Where("type = ?", pt).Asc("id").Find(&ids)
+}
+
+// GetOldestPackageVersions returns the oldest versions of the packages of an owner, excluding internal versions
+func GetOldestPackageVersions(ownerID int64, limit int) ([]*PackageVersion, error) {
+	versions := make([]*PackageVersion, 0, limit)
+	return versions, x.Join("INNER", "package", "package.id = package_version.package_id").
+		Where("package.owner_id = ? AND package_version.is_internal = ?", ownerID, false).
+		Asc("package_version.created_unix", "package_version.id").
+		Limit(limit).
+		Find(&versions)
+}
+
+// DeletePackageVersion deletes a package version and its files, and the package if it has no other versions.
+// The blobs of the files are removed by the cleanup task.
+func DeletePackageVersion(pv *PackageVersion) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.Where("version_id = ?", pv.ID).Delete(new(PackageFile)); err != nil {
+		return err
+	}
+	if _, err := sess.ID(pv.ID).Delete(new(PackageVersion)); err != nil {
+		return err
+	}
+	has, err := sess.Where("package_id = ? AND is_internal = ?", pv.PackageID, false).Exist(new(PackageVersion))
+	if err != nil {
+		return err
+	}
+	if !has {
+		if err := deletePackagesByIDs(sess, []int64{pv.PackageID}); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+func deletePackagesByIDs(e Engine, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	versionIDs := builder.Select("id").From("package_version").Where(builder.In("package_id", ids))
+	if _, err := e.Where(builder.In("version_id", versionIDs)).Delete(new(PackageFile)); err != nil {
+		return err
+	}
+	if _, err := e.In("package_id", ids).Delete(new(PackageVersion)); err != nil {
+		return err
+	}
+	_, err := e.In("id", ids).Delete(new(Package))
+	return err
+}
+
+func deletePackagesByOwnerID(e Engine, ownerID int64) error {
+	ids := make([]int64, 0, 10)
+	if err := e.Table("package").Where("owner_id = ?", ownerID).Cols("id").Find(&ids); err != nil {
+		return err
+	}
+	return deletePackagesByIDs(e, ids)
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func insertTestPackageBlob(t *testing.T, hash string, size int64) *PackageBlob {
+	blob, exists, err := GetOrInsertPackageBlob(&PackageBlob{
+		Size:       size,
+		HashMD5:    strings.Repeat("0", 32),
+		HashSHA1:   strings.Repeat("0", 40),
+		HashSHA256: hash,
+		HashSHA512: strings.Repeat("0", 128),
+	})
+	assert.NoError(t, err)
+	assert.False(t, exists)
+	return blob
+}
+
+func TestPackageVersionsAndFiles(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	pkg, err := GetOrCreatePackage(&Package{OwnerID: 2, Type: PackageTypeNpm, Name: "Test-Package"})
+	assert.NoError(t, err)
+	same, err := GetOrCreatePackage(&Package{OwnerID: 2, Type: PackageTypeNpm, Name: "test-package"})
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.ID, same.ID)
+
+	assert.NoError(t, CreatePackageVersion(&PackageVersion{PackageID: pkg.ID, CreatorID: 2, Version: "1.0.0"}))
+	assert.True(t, IsErrPackageVersionAlreadyExist(CreatePackageVersion(&PackageVersion{PackageID: pkg.ID, CreatorID: 2, Version: "1.0.0"})))
+	assert.NoError(t, CreatePackageVersion(&PackageVersion{PackageID: pkg.ID, CreatorID: 2, Version: "-internal", IsInternal: true}))
+
+	versions, err := GetPackageVersions(pkg.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, versions, 1) {
+		assert.Equal(t, "1.0.0", versions[0].Version)
+	}
+	pv := versions[0]
+
+	blob := insertTestPackageBlob(t, strings.Repeat("a", 64), 10)
+	_, exists, err := GetOrInsertPackageBlob(&PackageBlob{HashSHA256: strings.Repeat("a", 64)})
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	assert.NoError(t, AddPackageFile(&PackageFile{VersionID: pv.ID, BlobID: blob.ID, Name: "test-1.0.0.tgz"}, false))
+	assert.True(t, IsErrPackageFileAlreadyExist(AddPackageFile(&PackageFile{VersionID: pv.ID, BlobID: blob.ID, Name: "TEST-1.0.0.tgz"}, false)))
+	other := insertTestPackageBlob(t, strings.Repeat("b", 64), 20)
+	assert.NoError(t, AddPackageFile(&PackageFile{VersionID: pv.ID, BlobID: other.ID, Name: "test-1.0.0.tgz"}, true))
+
+	pf, foundPv, err := GetPackageFileInPackage(pkg.ID, "test-1.0.0.tgz")
+	assert.NoError(t, err)
+	assert.Equal(t, pv.ID, foundPv.ID)
+	assert.Equal(t, other.ID, pf.BlobID)
+
+	size, err := GetPackagesTotalSize(2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 20, size)
+
+	blobs, err := GetOrphanedPackageBlobs(time.Now().Add(time.Hour), 10)
+	assert.NoError(t, err)
+	if assert.Len(t, blobs, 1) {
+		assert.Equal(t, blob.ID, blobs[0].ID)
+	}
+
+	// deleting the last version which is not internal deletes the package
+	assert.NoError(t, DeletePackageVersion(pv))
+	_, err = GetPackageByID(pkg.ID)
+	assert.True(t, IsErrPackageNotExist(err))
+	AssertNotExistsBean(t, &PackageVersion{PackageID: pkg.ID})
+	AssertNotExistsBean(t, &PackageFile{VersionID: pv.ID})
+}
+
+func TestFindPackages(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	for _, p := range []*Package{
+		{OwnerID: 2, Type: PackageTypeNpm, Name: "first"},
+		{OwnerID: 2, Type: PackageTypeMaven, Name: "org.example:second"},
+		{OwnerID: 3, Type: PackageTypeNpm, Name: "third"},
+	} {
+		_, err := GetOrCreatePackage(p)
+		assert.NoError(t, err)
+	}
+
+	_, count, err := FindPackages(&FindPackagesOptions{OwnerID: 2})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+
+	pkgs, count, err := FindPackages(&FindPackagesOptions{OwnerID: 2, Type: PackageTypeMaven})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, pkgs, 1) {
+		assert.Equal(t, "org.example:second", pkgs[0].Name)
+	}
+
+	_, count, err = FindPackages(&FindPackagesOptions{OwnerID: 2, Keyword: "FIR"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	assert.NoError(t, deletePackagesByOwnerID(x, 2))
+	_, count, err = FindPackages(&FindPackagesOptions{OwnerID: 2})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+}
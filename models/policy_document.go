@@ -0,0 +1,301 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// PolicyDocumentKind is the kind of a policy document
+type PolicyDocumentKind int
+
+// the kinds of the policy documents
+const (
+	PolicyDocumentTerms   PolicyDocumentKind = iota // the terms of service
+	PolicyDocumentPrivacy                           // the privacy policy
+)
+
+// PolicyDocumentKinds are all the kinds of policy documents, in display order
+var PolicyDocumentKinds = []PolicyDocumentKind{PolicyDocumentTerms, PolicyDocumentPrivacy}
+
+// Name returns the name of the policy document kind
+func (k PolicyDocumentKind) Name() string {
+	switch k {
+	case PolicyDocumentPrivacy:
+		return "privacy"
+	default:
+		return "terms"
+	}
+}
+
+// PolicyDocumentKindFromName returns the policy document kind of a name
+func PolicyDocumentKindFromName(name string) (PolicyDocumentKind, bool) {
+	for _, k := range PolicyDocumentKinds {
+		if k.Name() == name {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
+// PolicyDocument is a version of the terms of service or of the privacy policy of the instance. A published version
+// is never changed: a new one is published instead, which all the users must accept.
+type PolicyDocument struct {
+	ID      int64              `xorm:"pk autoincr"`
+	Kind    PolicyDocumentKind `xorm:"UNIQUE(s) NOT NULL"`
+	Version int                `xorm:"UNIQUE(s) NOT NULL"`
+	Title   string             `xorm:"NOT NULL"`
+	// Content is rendered as markdown
+	Content     string             `xorm:"TEXT NOT NULL"`
+	PublisherID int64              `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+
+	RenderedContent string `xorm:"-"`
+	NumConsents     int64  `xorm:"-"`
+}
+
+// PolicyConsent is the acceptance of a policy document by a user
+type PolicyConsent struct {
+	ID           int64              `xorm:"pk autoincr"`
+	UserID       int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	DocumentID   int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	AcceptedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// PolicyConsentState is the consent of a user to the current version of a kind of policy document
+type PolicyConsentState struct {
+	Document *PolicyDocument
+	// AcceptedVersion is the last version the user accepted, 0 if none
+	AcceptedVersion int
+	AcceptedUnix    timeutil.TimeStamp
+}
+
+// IsPending returns true if the user has not accepted the current version of the document
+func (s *PolicyConsentState) IsPending() bool {
+	return s.AcceptedVersion != s.Document.Version
+}
+
+// PolicyDocumentConsent is the acceptance of a policy document by a user, with the user
+type PolicyDocumentConsent struct {
+	*PolicyConsent
+	User *User
+}
+
+// ErrPolicyDocumentNotExist represents a "PolicyDocumentNotExist" kind of error.
+type ErrPolicyDocumentNotExist struct {
+	ID int64
+}
+
+// IsErrPolicyDocumentNotExist checks if an error is a ErrPolicyDocumentNotExist.
+func IsErrPolicyDocumentNotExist(err error) bool {
+	_, ok := err.(ErrPolicyDocumentNotExist)
+	return ok
+}
+
+func (err ErrPolicyDocumentNotExist) Error() string {
+	return fmt.Sprintf("policy document does not exist [id: %d]", err.ID)
+}
+
+func hasPolicyDocuments(e Engine) (bool, error) {
+	count, err := e.Count(new(PolicyDocument))
+	return count > 0, err
+}
+
+// PublishPolicyDocument publishes a new version of a kind of policy document, which all the users, but the publisher
+// who accepts it, must accept the next time they sign in
+func PublishPolicyDocument(doc *PolicyDocument) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	var version int
+	if _, err := sess.Table(new(PolicyDocument)).Where("kind = ?", doc.Kind).Select("MAX(version)").Get(&version); err != nil {
+		return err
+	}
+	doc.ID = 0
+	doc.Version = version + 1
+	if _, err := sess.Insert(doc); err != nil {
+		return err
+	}
+
+	if _, err := sess.Where("type = ?", UserTypeIndividual).And("id != ?", doc.PublisherID).
+		Cols("must_accept_policies").Update(&User{MustAcceptPolicies: true}); err != nil {
+		return err
+	}
+	if doc.PublisherID > 0 {
+		if _, err := sess.Insert(&PolicyConsent{UserID: doc.PublisherID, DocumentID: doc.ID}); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// GetPolicyDocumentByID returns a policy document
+func GetPolicyDocumentByID(id int64) (*PolicyDocument, error) {
+	doc := new(PolicyDocument)
+	has, err := x.ID(id).Get(doc)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPolicyDocumentNotExist{id}
+	}
+	return doc, nil
+}
+
+// GetPolicyDocuments returns all the versions of the policy documents, the last ones first, with their numbers of
+// consents
+func GetPolicyDocuments() ([]*PolicyDocument, error) {
+	docs := make([]*PolicyDocument, 0, 10)
+	if err := x.Asc("kind").Desc("version").Find(&docs); err != nil {
+		return nil, err
+	}
+
+	type consentCount struct {
+		DocumentID int64
+		Count      int64
+	}
+	counts := make([]*consentCount, 0, len(docs))
+	if err := x.Table(new(PolicyConsent)).Select("document_id, COUNT(*) AS count").
+		GroupBy("document_id").Find(&counts); err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]int64, len(counts))
+	for _, c := range counts {
+		byID[c.DocumentID] = c.Count
+	}
+	for _, doc := range docs {
+		doc.NumConsents = byID[doc.ID]
+	}
+	return docs, nil
+}
+
+func getCurrentPolicyDocuments(e Engine) ([]*PolicyDocument, error) {
+	docs := make([]*PolicyDocument, 0, len(PolicyDocumentKinds))
+	for _, kind := range PolicyDocumentKinds {
+		doc := new(PolicyDocument)
+		has, err := e.Where("kind = ?", kind).Desc("version").Get(doc)
+		if err != nil {
+			return nil, err
+		} else if has {
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
+// GetCurrentPolicyDocuments returns the last version of each kind of policy document which has been published
+func GetCurrentPolicyDocuments() ([]*PolicyDocument, error) {
+	return getCurrentPolicyDocuments(x)
+}
+
+func getPolicyConsentStates(e Engine, userID int64) ([]*PolicyConsentState, error) {
+	docs, err := getCurrentPolicyDocuments(e)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]*PolicyConsentState, 0, len(docs))
+	for _, doc := range docs {
+		state := &PolicyConsentState{Document: doc}
+		accepted := new(PolicyDocument)
+		has, err := e.Table(new(PolicyDocument)).
+			Join("INNER", "policy_consent", "policy_consent.document_id = policy_document.id").
+			Where("policy_consent.user_id = ?", userID).And("policy_document.kind = ?", doc.Kind).
+			Desc("policy_document.version").Get(accepted)
+		if err != nil {
+			return nil, err
+		}
+		if has {
+			consent := new(PolicyConsent)
+			if _, err := e.Where("user_id = ?", userID).And("document_id = ?", accepted.ID).Get(consent); err != nil {
+				return nil, err
+			}
+			state.AcceptedVersion = accepted.Version
+			state.AcceptedUnix = consent.AcceptedUnix
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// GetPolicyConsentStates returns the consents of a user to the current policy documents
+func GetPolicyConsentStates(userID int64) ([]*PolicyConsentState, error) {
+	return getPolicyConsentStates(x, userID)
+}
+
+// AcceptPolicyDocuments records the acceptance of the current policy documents by a user, who no longer has to
+// accept them. Only the documents listed in documentIDs are accepted, so that a version published while the user
+// was reading the previous one stays pending.
+func AcceptPolicyDocuments(u *User, documentIDs []int64) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	states, err := getPolicyConsentStates(sess, u.ID)
+	if err != nil {
+		return err
+	}
+	accepted := make(map[int64]bool, len(documentIDs))
+	for _, id := range documentIDs {
+		accepted[id] = true
+	}
+	pending := false
+	for _, state := range states {
+		if !state.IsPending() {
+			continue
+		}
+		if !accepted[state.Document.ID] {
+			pending = true
+			continue
+		}
+		if _, err := sess.Insert(&PolicyConsent{UserID: u.ID, DocumentID: state.Document.ID}); err != nil {
+			return err
+		}
+	}
+
+	u.MustAcceptPolicies = pending
+	if _, err := sess.ID(u.ID).Cols("must_accept_policies").Update(u); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// GetPolicyDocumentConsents returns a page of the acceptances of a policy document, in acceptance order
+func GetPolicyDocumentConsents(documentID int64, opts ListOptions) ([]*PolicyDocumentConsent, int64, error) {
+	sess := x.Where("document_id = ?", documentID)
+	if opts.Page > 0 {
+		sess = opts.setSessionPagination(sess)
+	}
+	consents := make([]*PolicyConsent, 0, opts.PageSize)
+	count, err := sess.Asc("accepted_unix", "id").FindAndCount(&consents)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	userIDs := make([]int64, 0, len(consents))
+	for _, consent := range consents {
+		userIDs = append(userIDs, consent.UserID)
+	}
+	users := make(map[int64]*User, len(userIDs))
+	if err := x.In("id", userIDs).Find(&users); err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]*PolicyDocumentConsent, 0, len(consents))
+	for _, consent := range consents {
+		u, ok := users[consent.UserID]
+		if !ok {
+			u = NewGhostUser()
+		}
+		result = append(result, &PolicyDocumentConsent{PolicyConsent: consent, User: u})
+	}
+	return result, count, nil
+}
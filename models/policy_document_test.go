@@ -0,0 +1,78 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishPolicyDocument(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	hasDocs, err := hasPolicyDocuments(x)
+	assert.NoError(t, err)
+	assert.False(t, hasDocs)
+
+	terms := &PolicyDocument{Kind: PolicyDocumentTerms, Title: "Terms", Content: "v1", PublisherID: 1}
+	assert.NoError(t, PublishPolicyDocument(terms))
+	assert.EqualValues(t, 1, terms.Version)
+	privacy := &PolicyDocument{Kind: PolicyDocumentPrivacy, Title: "Privacy", Content: "v1", PublisherID: 1}
+	assert.NoError(t, PublishPolicyDocument(privacy))
+	assert.EqualValues(t, 1, privacy.Version)
+
+	// the publisher accepts the documents, the other users must accept them
+	assert.False(t, AssertExistsAndLoadBean(t, &User{ID: 1}).(*User).MustAcceptPolicies)
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.True(t, user2.MustAcceptPolicies)
+	assert.False(t, AssertExistsAndLoadBean(t, &User{ID: 3}).(*User).MustAcceptPolicies, "organization")
+
+	states, err := GetPolicyConsentStates(2)
+	assert.NoError(t, err)
+	if assert.Len(t, states, 2) {
+		assert.True(t, states[0].IsPending())
+		assert.True(t, states[1].IsPending())
+	}
+
+	// a version published while the user reads the previous one stays pending
+	terms2 := &PolicyDocument{Kind: PolicyDocumentTerms, Title: "Terms", Content: "v2", PublisherID: 1}
+	assert.NoError(t, PublishPolicyDocument(terms2))
+	assert.EqualValues(t, 2, terms2.Version)
+	assert.NoError(t, AcceptPolicyDocuments(user2, []int64{terms.ID, privacy.ID}))
+	assert.True(t, AssertExistsAndLoadBean(t, &User{ID: 2}).(*User).MustAcceptPolicies)
+
+	assert.NoError(t, AcceptPolicyDocuments(user2, []int64{terms2.ID}))
+	assert.False(t, AssertExistsAndLoadBean(t, &User{ID: 2}).(*User).MustAcceptPolicies)
+	states, err = GetPolicyConsentStates(2)
+	assert.NoError(t, err)
+	if assert.Len(t, states, 2) {
+		assert.EqualValues(t, 2, states[0].AcceptedVersion)
+		assert.False(t, states[0].IsPending())
+		assert.EqualValues(t, 1, states[1].AcceptedVersion)
+		assert.NotZero(t, states[1].AcceptedUnix)
+	}
+
+	docs, err := GetPolicyDocuments()
+	assert.NoError(t, err)
+	if assert.Len(t, docs, 3) {
+		assert.Equal(t, terms2.ID, docs[0].ID)
+		assert.EqualValues(t, 2, docs[0].NumConsents)
+		assert.EqualValues(t, 1, docs[1].NumConsents)
+		assert.EqualValues(t, 2, docs[2].NumConsents)
+	}
+
+	consents, count, err := GetPolicyDocumentConsents(terms2.ID, ListOptions{Page: 1, PageSize: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	if assert.Len(t, consents, 1) {
+		assert.EqualValues(t, 1, consents[0].User.ID)
+	}
+
+	// the users created after the publication must accept the documents
+	u := &User{Name: "policyuser", Email: "policyuser@example.com", Passwd: "password"}
+	assert.NoError(t, CreateUser(u))
+	assert.True(t, AssertExistsAndLoadBean(t, &User{ID: u.ID}).(*User).MustAcceptPolicies)
+}
@@ -24,6 +24,15 @@ type (
 
 	// ProjectType is used to identify the type of project in question and ownership
 	ProjectType uint8
+
+	// ProjectSwimlaneType is used to identify how the issues of the boards of a project are grouped
+	ProjectSwimlaneType uint8
+
+	// ProjectSwimlanesConfig is used to identify the types of swimlanes a project can be shown with
+	ProjectSwimlanesConfig struct {
+		SwimlaneType ProjectSwimlaneType
+		Translation  string
+	}
 )
 
 const (
@@ -37,16 +46,34 @@ const (
 	ProjectTypeOrganization
 )
 
+const (
+	// ProjectSwimlaneTypeNone is a project whose issues are not grouped
+	ProjectSwimlaneTypeNone ProjectSwimlaneType = iota
+
+	// ProjectSwimlaneTypeAssignee is a project whose issues are grouped by their first assignee
+	ProjectSwimlaneTypeAssignee
+
+	// ProjectSwimlaneTypeLabel is a project whose issues are grouped by their first label
+	ProjectSwimlaneTypeLabel
+
+	// ProjectSwimlaneTypeMilestone is a project whose issues are grouped by their milestone
+	ProjectSwimlaneTypeMilestone
+)
+
 // Project represents a project board
 type Project struct {
 	ID          int64  `xorm:"pk autoincr"`
 	Title       string `xorm:"INDEX NOT NULL"`
 	Description string `xorm:"TEXT"`
 	RepoID      int64  `xorm:"INDEX"`
-	CreatorID   int64  `xorm:"NOT NULL"`
-	IsClosed    bool   `xorm:"INDEX"`
-	BoardType   ProjectBoardType
-	Type        ProjectType
+	// OwnerID is the organization of an organization project, whose issues can be of any of its repositories
+	OwnerID   int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	CreatorID int64 `xorm:"NOT NULL"`
+	IsClosed  bool  `xorm:"INDEX"`
+	BoardType ProjectBoardType
+	Type      ProjectType
+	// SwimlaneType is how the issues of the boards are grouped in horizontal lanes
+	SwimlaneType ProjectSwimlaneType `xorm:"NOT NULL DEFAULT 0"`
 
 	RenderedContent string `xorm:"-"`
 
@@ -64,19 +91,72 @@ func GetProjectsConfig() []ProjectsConfig {
 	}
 }
 
+// GetProjectSwimlanesConfig retrieves the types of swimlanes projects could have
+func GetProjectSwimlanesConfig() []ProjectSwimlanesConfig {
+	return []ProjectSwimlanesConfig{
+		{ProjectSwimlaneTypeNone, "repo.projects.swimlanes.none"},
+		{ProjectSwimlaneTypeAssignee, "repo.projects.swimlanes.assignee"},
+		{ProjectSwimlaneTypeLabel, "repo.projects.swimlanes.label"},
+		{ProjectSwimlaneTypeMilestone, "repo.projects.swimlanes.milestone"},
+	}
+}
+
+// IsProjectSwimlaneTypeValid checks if a project swimlane type is valid
+func IsProjectSwimlaneTypeValid(t ProjectSwimlaneType) bool {
+	switch t {
+	case ProjectSwimlaneTypeNone, ProjectSwimlaneTypeAssignee, ProjectSwimlaneTypeLabel, ProjectSwimlaneTypeMilestone:
+		return true
+	default:
+		return false
+	}
+}
+
+var projectSwimlaneTypeNames = map[ProjectSwimlaneType]string{
+	ProjectSwimlaneTypeNone:      "none",
+	ProjectSwimlaneTypeAssignee:  "assignee",
+	ProjectSwimlaneTypeLabel:     "label",
+	ProjectSwimlaneTypeMilestone: "milestone",
+}
+
+// Name returns the name of a project swimlane type, as used by the API
+func (t ProjectSwimlaneType) Name() string {
+	return projectSwimlaneTypeNames[t]
+}
+
+// ProjectSwimlaneTypeFromName returns the project swimlane type of a name, false if there is none
+func ProjectSwimlaneTypeFromName(name string) (ProjectSwimlaneType, bool) {
+	for t, n := range projectSwimlaneTypeNames {
+		if n == name {
+			return t, true
+		}
+	}
+	return ProjectSwimlaneTypeNone, false
+}
+
 // IsProjectTypeValid checks if a project type is valid
 func IsProjectTypeValid(p ProjectType) bool {
 	switch p {
-	case ProjectTypeRepository:
+	case ProjectTypeRepository, ProjectTypeOrganization:
 		return true
 	default:
 		return false
 	}
 }
 
+// CanContainIssuesOf returns whether the issues of a repository can be added to the project: the issues of its
+// repository for a repository project, or of any repository of its organization for an organization project
+func (p *Project) CanContainIssuesOf(repo *Repository) bool {
+	if p.Type == ProjectTypeOrganization {
+		return p.OwnerID == repo.OwnerID
+	}
+	return p.RepoID == repo.ID
+}
+
 // ProjectSearchOptions are options for GetProjects
 type ProjectSearchOptions struct {
-	RepoID   int64
+	RepoID int64
+	// OwnerID searches the projects of an organization instead of the ones of a repository
+	OwnerID  int64
 	Page     int
 	IsClosed util.OptionalBool
 	SortType string
@@ -92,6 +172,9 @@ func getProjects(e Engine, opts ProjectSearchOptions) ([]*Project, int64, error)
 	projects := make([]*Project, 0, setting.UI.IssuePagingNum)
 
 	var cond builder.Cond = builder.Eq{"repo_id": opts.RepoID}
+	if opts.OwnerID > 0 {
+		cond = builder.Eq{"owner_id": opts.OwnerID}
+	}
 	switch opts.IsClosed {
 	case util.OptionalBoolTrue:
 		cond = cond.And(builder.Eq{"is_closed": true})
@@ -138,6 +221,17 @@ func NewProject(p *Project) error {
 		return errors.New("project type is not valid")
 	}
 
+	if p.Type == ProjectTypeOrganization {
+		if p.OwnerID == 0 {
+			return errors.New("organization project has no owner")
+		}
+		p.RepoID = 0
+	}
+
+	if !IsProjectSwimlaneTypeValid(p.SwimlaneType) {
+		p.SwimlaneType = ProjectSwimlaneTypeNone
+	}
+
 	sess := x.NewSession()
 	defer sess.Close()
 
@@ -149,8 +243,10 @@ func NewProject(p *Project) error {
 		return err
 	}
 
-	if _, err := sess.Exec("UPDATE `repository` SET num_projects = num_projects + 1 WHERE id = ?", p.RepoID); err != nil {
-		return err
+	if p.Type == ProjectTypeRepository {
+		if _, err := sess.Exec("UPDATE `repository` SET num_projects = num_projects + 1 WHERE id = ?", p.RepoID); err != nil {
+			return err
+		}
 	}
 
 	if err := createBoardsForProjectsType(sess, p); err != nil {
@@ -184,9 +280,14 @@ func UpdateProject(p *Project) error {
 }
 
 func updateProject(e Engine, p *Project) error {
+	if !IsProjectSwimlaneTypeValid(p.SwimlaneType) {
+		p.SwimlaneType = ProjectSwimlaneTypeNone
+	}
+
 	_, err := e.ID(p.ID).Cols(
 		"title",
 		"description",
+		"swimlane_type",
 	).Update(p)
 	return err
 }
@@ -213,6 +314,27 @@ func updateRepositoryProjectCount(e Engine, repoID int64) error {
 	return nil
 }
 
+// GetProjectInOrgByID returns a project of an organization
+func GetProjectInOrgByID(orgID, id int64) (*Project, error) {
+	p := new(Project)
+	has, err := x.ID(id).Where("owner_id = ? AND type = ?", orgID, ProjectTypeOrganization).Get(p)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrProjectNotExist{ID: id}
+	}
+	return p, nil
+}
+
+// CountOrgProjects returns the number of open and closed projects of an organization
+func CountOrgProjects(orgID int64) (open, closed int64, err error) {
+	if open, err = x.Where("owner_id = ? AND type = ? AND is_closed = ?", orgID, ProjectTypeOrganization, false).Count(new(Project)); err != nil {
+		return 0, 0, err
+	}
+	closed, err = x.Where("owner_id = ? AND type = ? AND is_closed = ?", orgID, ProjectTypeOrganization, true).Count(new(Project))
+	return open, closed, err
+}
+
 // ChangeProjectStatusByRepoIDAndID toggles a project between opened and closed
 func ChangeProjectStatusByRepoIDAndID(repoID, projectID int64, isClosed bool) error {
 	sess := x.NewSession()
@@ -259,7 +381,7 @@ func changeProjectStatus(e Engine, p *Project, isClosed bool) error {
 	if err != nil {
 		return err
 	}
-	if count < 1 {
+	if count < 1 || p.Type != ProjectTypeRepository {
 		return nil
 	}
 
@@ -302,5 +424,8 @@ func deleteProjectByID(e Engine, id int64) error {
 		return err
 	}
 
+	if p.Type != ProjectTypeRepository {
+		return nil
+	}
 	return updateRepositoryProjectCount(e, p.RepoID)
 }
@@ -41,6 +41,16 @@ type ProjectBoard struct {
 	ProjectID int64 `xorm:"INDEX NOT NULL"`
 	CreatorID int64 `xorm:"NOT NULL"`
 
+	// WIPLimit is the number of issues above which the board is shown with a warning, 0 for no limit
+	WIPLimit int `xorm:"NOT NULL DEFAULT 0"`
+	// AutoMoveOnClose moves the issues of the project to this board when they are closed
+	AutoMoveOnClose bool `xorm:"NOT NULL DEFAULT false"`
+	// AutoMoveOnMerge moves the pull requests of the project to this board when they are merged
+	AutoMoveOnMerge bool `xorm:"NOT NULL DEFAULT false"`
+	// RepoFilter are the comma separated IDs of the repositories whose issues the board of an organization project
+	// accepts, all the repositories of the organization if empty
+	RepoFilter string `xorm:"TEXT"`
+
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
 
@@ -57,6 +67,20 @@ func IsProjectBoardTypeValid(p ProjectBoardType) bool {
 	}
 }
 
+// ProjectBoardTypeFromName returns the project board type of a name as used by the API, false if there is none
+func ProjectBoardTypeFromName(name string) (ProjectBoardType, bool) {
+	switch name {
+	case "none":
+		return ProjectBoardTypeNone, true
+	case "basic_kanban":
+		return ProjectBoardTypeBasicKanban, true
+	case "bug_triage":
+		return ProjectBoardTypeBugTriage, true
+	default:
+		return ProjectBoardTypeNone, false
+	}
+}
+
 func createBoardsForProjectsType(sess *xorm.Session, project *Project) error {
 	var items []string
 
@@ -178,6 +202,97 @@ func updateProjectBoard(e Engine, board *ProjectBoard) error {
 	return err
 }
 
+// FilterRepoIDs returns the IDs of the repositories whose issues the board accepts, all the repositories if empty
+func (b *ProjectBoard) FilterRepoIDs() []int64 {
+	return splitUserFilterIDs(b.RepoFilter)
+}
+
+// CanContainIssuesOf returns whether the issues of a repository can be moved to the board
+func (b *ProjectBoard) CanContainIssuesOf(repoID int64) bool {
+	ids := b.FilterRepoIDs()
+	if len(ids) == 0 {
+		return true
+	}
+	for _, id := range ids {
+		if id == repoID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWIPLimitExceeded returns whether the board has more issues than its work in progress limit
+func (b *ProjectBoard) IsWIPLimitExceeded() bool {
+	return b.WIPLimit > 0 && len(b.Issues) > b.WIPLimit
+}
+
+// TotalWeight returns the sum of the weights of the loaded issues of the board
+func (b *ProjectBoard) TotalWeight() int64 {
+	var weight int64
+	for _, issue := range b.Issues {
+		weight += issue.Weight
+	}
+	return weight
+}
+
+// UpdateProjectBoardSettings updates the work in progress limit, the automation and the repository filter of a project
+// board. The other boards of the project stop receiving the issues this one receives from then on, and the issues of
+// the repositories the board no longer accepts are moved out of it.
+func UpdateProjectBoardSettings(board *ProjectBoard) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if board.WIPLimit < 0 {
+		board.WIPLimit = 0
+	}
+	board.RepoFilter = JoinUserFilterIDs(board.FilterRepoIDs())
+	if _, err := sess.ID(board.ID).Cols("wip_limit", "auto_move_on_close", "auto_move_on_merge", "repo_filter").Update(board); err != nil {
+		return err
+	}
+
+	if repoIDs := board.FilterRepoIDs(); len(repoIDs) > 0 {
+		if _, err := sess.Where("project_board_id = ?", board.ID).
+			NotIn("issue_id", builder.Select("id").From("issue").Where(builder.In("repo_id", repoIDs))).
+			Cols("project_board_id").Update(&ProjectIssue{}); err != nil {
+			return err
+		}
+	}
+
+	for col, enabled := range map[string]bool{"auto_move_on_close": board.AutoMoveOnClose, "auto_move_on_merge": board.AutoMoveOnMerge} {
+		if !enabled {
+			continue
+		}
+		if _, err := sess.Where("project_id = ? AND id != ?", board.ProjectID, board.ID).
+			Cols(col).Update(&ProjectBoard{}); err != nil {
+			return err
+		}
+	}
+
+	return sess.Commit()
+}
+
+// getAutoMoveBoard returns the board of a project receiving the issues which are closed, or the pull requests which
+// are merged, nil if there is none
+func getAutoMoveBoard(e Engine, projectID int64, isMergePull bool) (*ProjectBoard, error) {
+	cols := []string{"auto_move_on_close"}
+	if isMergePull {
+		cols = []string{"auto_move_on_merge", "auto_move_on_close"}
+	}
+	for _, col := range cols {
+		board := new(ProjectBoard)
+		has, err := e.Where("project_id = ?", projectID).And(builder.Eq{col: true}).Get(board)
+		if err != nil {
+			return nil, err
+		} else if has {
+			return board, nil
+		}
+	}
+	return nil, nil
+}
+
 // GetProjectBoards fetches all boards related to a project
 // if no default board set, first board is a temporary "Uncategorized" board
 func GetProjectBoards(projectID int64) (ProjectBoardList, error) {
@@ -287,6 +402,19 @@ func (bs ProjectBoardList) LoadIssues() (IssueList, error) {
 	return issues, nil
 }
 
+// RemoveIssuesNotVisibleTo removes the loaded issues of the boards which the user cannot read, as an organization
+// project contains the issues of repositories the user may not have access to
+func (bs ProjectBoardList) RemoveIssuesNotVisibleTo(user *User) error {
+	for _, b := range bs {
+		issues, err := IssueList(b.Issues).FilterVisibleTo(user)
+		if err != nil {
+			return err
+		}
+		b.Issues = issues
+	}
+	return nil
+}
+
 // UpdateProjectBoardSorting update project board sorting
 func UpdateProjectBoardSorting(bs ProjectBoardList) error {
 	for i := range bs {
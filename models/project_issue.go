@@ -7,6 +7,7 @@ package models
 import (
 	"fmt"
 
+	"xorm.io/builder"
 	"xorm.io/xorm"
 )
 
@@ -193,6 +194,10 @@ func MoveIssueAcrossProjectBoards(issue *Issue, board *ProjectBoard) error {
 		return fmt.Errorf("issue has to be added to a project first")
 	}
 
+	if !board.CanContainIssuesOf(issue.RepoID) {
+		return ErrProjectBoardRepoNotAllowed{BoardID: board.ID, RepoID: issue.RepoID}
+	}
+
 	pis.ProjectBoardID = board.ID
 	if _, err := sess.ID(pis.ID).Cols("project_board_id").Update(&pis); err != nil {
 		return err
@@ -201,6 +206,34 @@ func MoveIssueAcrossProjectBoards(issue *Issue, board *ProjectBoard) error {
 	return sess.Commit()
 }
 
+// moveIssueToAutoMoveBoard moves a closed issue, or a merged pull request, to the board of its project receiving them,
+// if any
+func moveIssueToAutoMoveBoard(e Engine, issue *Issue, isMergePull bool) error {
+	var pis ProjectIssue
+	has, err := e.Where("issue_id=?", issue.ID).Get(&pis)
+	if err != nil || !has {
+		return err
+	}
+
+	board, err := getAutoMoveBoard(e, pis.ProjectID, isMergePull)
+	if err != nil || board == nil || board.ID == pis.ProjectBoardID || !board.CanContainIssuesOf(issue.RepoID) {
+		return err
+	}
+
+	pis.ProjectBoardID = board.ID
+	_, err = e.ID(pis.ID).Cols("project_board_id").Update(&pis)
+	return err
+}
+
+// removeRepoIssuesFromOrgProjects removes the issues of a repository from the projects of an organization, when the
+// repository leaves the organization
+func removeRepoIssuesFromOrgProjects(e Engine, repoID, orgID int64) error {
+	_, err := e.In("issue_id", builder.Select("id").From("issue").Where(builder.Eq{"repo_id": repoID})).
+		In("project_id", builder.Select("id").From("project").Where(builder.Eq{"owner_id": orgID, "type": ProjectTypeOrganization})).
+		Delete(new(ProjectIssue))
+	return err
+}
+
 func (pb *ProjectBoard) removeIssues(e Engine) error {
 	_, err := e.Exec("UPDATE `project_issue` SET project_board_id = 0 WHERE project_board_id = ? ", pb.ID)
 	return err
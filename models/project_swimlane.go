@@ -0,0 +1,67 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+// ProjectSwimlane is a horizontal lane of the boards of a project, grouping the issues of an assignee, a label or a
+// milestone
+type ProjectSwimlane struct {
+	// ID is the ID of the assignee, label or milestone of the lane, 0 for the lane of the issues without one
+	ID    int64
+	Title string
+	// Color is the color of the label of the lane
+	Color string
+	// Issues are the issues of the lane in each board, in the order of the boards
+	Issues [][]*Issue
+}
+
+// projectSwimlaneOf returns the lane of an issue, the zero lane if it has no assignee, label or milestone
+func projectSwimlaneOf(issue *Issue, swimlaneType ProjectSwimlaneType) ProjectSwimlane {
+	switch swimlaneType {
+	case ProjectSwimlaneTypeAssignee:
+		if len(issue.Assignees) > 0 {
+			return ProjectSwimlane{ID: issue.Assignees[0].ID, Title: issue.Assignees[0].GetDisplayName()}
+		}
+	case ProjectSwimlaneTypeLabel:
+		if len(issue.Labels) > 0 {
+			return ProjectSwimlane{ID: issue.Labels[0].ID, Title: issue.Labels[0].Name, Color: issue.Labels[0].Color}
+		}
+	case ProjectSwimlaneTypeMilestone:
+		if issue.MilestoneID > 0 && issue.Milestone != nil {
+			return ProjectSwimlane{ID: issue.Milestone.ID, Title: issue.Milestone.Name}
+		}
+	}
+	return ProjectSwimlane{}
+}
+
+// Swimlanes groups the loaded issues of the boards in lanes, in the order the lanes first appear and the lane of the
+// issues without an assignee, label or milestone last. An issue with several assignees or labels is in the lane of its
+// first one. All the issues are in a single lane if the swimlane type is none.
+func (bs ProjectBoardList) Swimlanes(swimlaneType ProjectSwimlaneType) []*ProjectSwimlane {
+	lanes := make([]*ProjectSwimlane, 0, 5)
+	laneIndexes := make(map[int64]int)
+	for i, b := range bs {
+		for _, issue := range b.Issues {
+			lane := projectSwimlaneOf(issue, swimlaneType)
+			idx, ok := laneIndexes[lane.ID]
+			if !ok {
+				lane.Issues = make([][]*Issue, len(bs))
+				idx = len(lanes)
+				laneIndexes[lane.ID] = idx
+				lanes = append(lanes, &lane)
+			}
+			lanes[idx].Issues[i] = append(lanes[idx].Issues[i], issue)
+		}
+	}
+
+	if idx, ok := laneIndexes[0]; ok && idx != len(lanes)-1 {
+		noneLane := lanes[idx]
+		lanes = append(append(lanes[:idx], lanes[idx+1:]...), noneLane)
+	}
+	if len(lanes) == 0 {
+		// the boards are shown even if they have no issues
+		lanes = append(lanes, &ProjectSwimlane{Issues: make([][]*Issue, len(bs))})
+	}
+	return lanes
+}
@@ -21,7 +21,7 @@ func TestIsProjectTypeValid(t *testing.T) {
 	}{
 		{ProjectTypeIndividual, false},
 		{ProjectTypeRepository, true},
-		{ProjectTypeOrganization, false},
+		{ProjectTypeOrganization, true},
 		{UnknownType, false},
 	}
 
@@ -80,3 +80,141 @@ func TestProject(t *testing.T) {
 
 	assert.True(t, projectFromDB.IsClosed)
 }
+
+func TestProjectBoardListSwimlanes(t *testing.T) {
+	user1 := &User{ID: 1, Name: "user1"}
+	user2 := &User{ID: 2, Name: "user2"}
+	issue1 := &Issue{ID: 1, Assignees: []*User{user2, user1}}
+	issue2 := &Issue{ID: 2}
+	issue3 := &Issue{ID: 3, Assignees: []*User{user1}}
+	issue4 := &Issue{ID: 4, Assignees: []*User{user2}}
+	boards := ProjectBoardList{
+		{ID: 0, Issues: []*Issue{issue1, issue2}},
+		{ID: 1, Issues: []*Issue{issue3}},
+		{ID: 2, Issues: []*Issue{issue4}},
+	}
+
+	lanes := boards.Swimlanes(ProjectSwimlaneTypeNone)
+	assert.Len(t, lanes, 1)
+	assert.Equal(t, [][]*Issue{{issue1, issue2}, {issue3}, {issue4}}, lanes[0].Issues)
+
+	lanes = boards.Swimlanes(ProjectSwimlaneTypeAssignee)
+	if assert.Len(t, lanes, 3) {
+		assert.EqualValues(t, 2, lanes[0].ID)
+		assert.Equal(t, "user2", lanes[0].Title)
+		assert.Equal(t, [][]*Issue{{issue1}, nil, {issue4}}, lanes[0].Issues)
+		assert.EqualValues(t, 1, lanes[1].ID)
+		assert.Equal(t, [][]*Issue{nil, {issue3}, nil}, lanes[1].Issues)
+		// the issues without an assignee are last
+		assert.EqualValues(t, 0, lanes[2].ID)
+		assert.Equal(t, [][]*Issue{{issue2}, nil, nil}, lanes[2].Issues)
+	}
+
+	lanes = ProjectBoardList{{ID: 1}}.Swimlanes(ProjectSwimlaneTypeLabel)
+	if assert.Len(t, lanes, 1) {
+		assert.Len(t, lanes[0].Issues, 1)
+	}
+}
+
+func TestProjectBoardAutoMove(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	board2 := AssertExistsAndLoadBean(t, &ProjectBoard{ID: 2}).(*ProjectBoard)
+	board2.AutoMoveOnClose = true
+	board2.WIPLimit = 3
+	assert.NoError(t, UpdateProjectBoardSettings(board2))
+	board3 := AssertExistsAndLoadBean(t, &ProjectBoard{ID: 3}).(*ProjectBoard)
+	board3.AutoMoveOnClose = true
+	assert.NoError(t, UpdateProjectBoardSettings(board3))
+
+	// a single board receives the closed issues
+	board2 = AssertExistsAndLoadBean(t, &ProjectBoard{ID: 2}).(*ProjectBoard)
+	assert.False(t, board2.AutoMoveOnClose)
+	assert.Equal(t, 3, board2.WIPLimit)
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	_, err := issue.ChangeStatus(doer, true)
+	assert.NoError(t, err)
+	AssertExistsAndLoadBean(t, &ProjectIssue{IssueID: 1, ProjectBoardID: 3})
+
+	// reopening an issue does not move it
+	_, err = issue.ChangeStatus(doer, false)
+	assert.NoError(t, err)
+	AssertExistsAndLoadBean(t, &ProjectIssue{IssueID: 1, ProjectBoardID: 3})
+}
+
+func TestProjectBoardIsWIPLimitExceeded(t *testing.T) {
+	board := &ProjectBoard{Issues: []*Issue{{ID: 1}, {ID: 2}}}
+	assert.False(t, board.IsWIPLimitExceeded())
+	board.WIPLimit = 2
+	assert.False(t, board.IsWIPLimitExceeded())
+	board.WIPLimit = 1
+	assert.True(t, board.IsWIPLimitExceeded())
+}
+
+func TestOrgProject(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	project := &Project{
+		Type:      ProjectTypeOrganization,
+		BoardType: ProjectBoardTypeBasicKanban,
+		Title:     "Org Project",
+		OwnerID:   3,
+		RepoID:    1,
+		CreatorID: 2,
+	}
+	assert.NoError(t, NewProject(project))
+	assert.EqualValues(t, 0, project.RepoID)
+
+	_, err := GetProjectInOrgByID(3, project.ID)
+	assert.NoError(t, err)
+	_, err = GetProjectInOrgByID(6, project.ID)
+	assert.True(t, IsErrProjectNotExist(err))
+
+	projects, count, err := GetProjects(ProjectSearchOptions{OwnerID: 3, Type: ProjectTypeOrganization})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	assert.Len(t, projects, 1)
+
+	assert.NoError(t, ChangeProjectStatus(project, true))
+	open, closed, err := CountOrgProjects(3)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, open)
+	assert.EqualValues(t, 1, closed)
+
+	repo1 := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	repo3 := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+	assert.False(t, project.CanContainIssuesOf(repo1))
+	assert.True(t, project.CanContainIssuesOf(repo3))
+	repoProject := AssertExistsAndLoadBean(t, &Project{ID: 1}).(*Project)
+	assert.True(t, repoProject.CanContainIssuesOf(repo1))
+	assert.False(t, repoProject.CanContainIssuesOf(repo3))
+
+	// the repositories of the organization are not counted
+	CheckConsistencyFor(t, &Repository{})
+}
+
+func TestProjectBoardRepoFilter(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	board := AssertExistsAndLoadBean(t, &ProjectBoard{ID: 1}).(*ProjectBoard)
+	assert.True(t, board.CanContainIssuesOf(1))
+
+	// the issues of the other repositories are moved out of the board
+	board.RepoFilter = "3"
+	assert.NoError(t, UpdateProjectBoardSettings(board))
+	board = AssertExistsAndLoadBean(t, &ProjectBoard{ID: 1}).(*ProjectBoard)
+	assert.Equal(t, []int64{3}, board.FilterRepoIDs())
+	assert.False(t, board.CanContainIssuesOf(1))
+	assert.True(t, board.CanContainIssuesOf(3))
+	AssertExistsAndLoadBean(t, &ProjectIssue{IssueID: 1, ProjectBoardID: 0})
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.True(t, IsErrProjectBoardRepoNotAllowed(MoveIssueAcrossProjectBoards(issue, board)))
+
+	board.RepoFilter = ""
+	assert.NoError(t, UpdateProjectBoardSettings(board))
+	assert.NoError(t, MoveIssueAcrossProjectBoards(issue, board))
+	AssertExistsAndLoadBean(t, &ProjectIssue{IssueID: 1, ProjectBoardID: 1})
+}
@@ -349,8 +349,12 @@ const (
 	MergeStyleRebaseMerge MergeStyle = "rebase-merge"
 	// MergeStyleSquash squash commits into single commit before merging
 	MergeStyleSquash MergeStyle = "squash"
+	// MergeStyleFastForwardOnly fast-forward the base branch to the head branch, refusing to merge otherwise
+	MergeStyleFastForwardOnly MergeStyle = "fast-forward-only"
 	// MergeStyleManuallyMerged pr has been merged manually, just mark it as merged directly
 	MergeStyleManuallyMerged MergeStyle = "manually-merged"
+	// MergeStyleRebaseUpdate rebase the head branch on to the base branch, only used to update a pull request
+	MergeStyleRebaseUpdate MergeStyle = "rebase-update-only"
 )
 
 // SetMerged sets a pull request to merged and closes the corresponding issue
@@ -573,6 +577,12 @@ func GetPullRequestByIssueID(issueID int64) (*PullRequest, error) {
 	return getPullRequestByIssueID(x, issueID)
 }
 
+// IsPullRequestMergedCommit returns true if a commit is the merge commit of a pull request of a repository
+func IsPullRequestMergedCommit(repoID int64, commitID string) (bool, error) {
+	return x.Where("base_repo_id = ? AND has_merged = ? AND merged_commit_id = ?", repoID, true, commitID).
+		Exist(new(PullRequest))
+}
+
 // Update updates all fields of pull request.
 func (pr *PullRequest) Update() error {
 	_, err := x.ID(pr.ID).AllCols().Update(pr)
@@ -627,6 +637,25 @@ func (pr *PullRequest) GetWorkInProgressPrefix() string {
 	return ""
 }
 
+// SetWorkInProgress adds or removes the work in progress prefix of the pull request title.
+// The caller is responsible for saving the changed title.
+func (pr *PullRequest) SetWorkInProgress(wip bool) {
+	if wip == pr.IsWorkInProgress() {
+		return
+	}
+
+	if wip {
+		if len(setting.Repository.PullRequest.WorkInProgressPrefixes) == 0 {
+			return
+		}
+		pr.Issue.Title = setting.Repository.PullRequest.WorkInProgressPrefixes[0] + " " + pr.Issue.Title
+		return
+	}
+
+	prefix := pr.GetWorkInProgressPrefix()
+	pr.Issue.Title = strings.TrimSpace(pr.Issue.Title[len(prefix):])
+}
+
 // UpdateCommitDivergence update Divergence of a pull request
 func (pr *PullRequest) UpdateCommitDivergence(ahead, behind int) error {
 	return pr.updateCommitDivergence(x, ahead, behind)
@@ -0,0 +1,122 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// PullAutoMerge represents a pull request scheduled to be merged when all checks succeed
+type PullAutoMerge struct {
+	ID          int64              `xorm:"pk autoincr"`
+	PullID      int64              `xorm:"UNIQUE"`
+	DoerID      int64              `xorm:"NOT NULL"`
+	Doer        *User              `xorm:"-"`
+	MergeStyle  MergeStyle         `xorm:"varchar(30)"`
+	Message     string             `xorm:"LONGTEXT"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// LoadDoer loads the user who scheduled the merge
+func (pam *PullAutoMerge) LoadDoer() (err error) {
+	if pam.Doer == nil {
+		pam.Doer, err = GetUserByID(pam.DoerID)
+	}
+	return err
+}
+
+func createAutoMergeComment(e *xorm.Session, typ CommentType, doer *User, pull *PullRequest) error {
+	if err := pull.loadIssue(e); err != nil {
+		return err
+	}
+	if err := pull.Issue.loadRepo(e); err != nil {
+		return err
+	}
+	_, err := createComment(e, &CreateCommentOptions{
+		Type:  typ,
+		Doer:  doer,
+		Repo:  pull.Issue.Repo,
+		Issue: pull.Issue,
+	})
+	return err
+}
+
+// ScheduleAutoMerge schedules a pull request to be merged by doer with the given style
+// and message when all checks succeed
+func ScheduleAutoMerge(doer *User, pull *PullRequest, style MergeStyle, message string) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	exist, err := sess.Where("pull_id = ?", pull.ID).Exist(new(PullAutoMerge))
+	if err != nil {
+		return err
+	} else if exist {
+		return ErrAlreadyScheduledToAutoMerge{PullID: pull.ID}
+	}
+
+	if _, err := sess.Insert(&PullAutoMerge{
+		PullID:     pull.ID,
+		DoerID:     doer.ID,
+		MergeStyle: style,
+		Message:    message,
+	}); err != nil {
+		return err
+	}
+
+	if err := createAutoMergeComment(sess, CommentTypePRScheduledToAutoMerge, doer, pull); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// GetScheduledMergeByPullID returns the scheduled merge of a pull request if there is one
+func GetScheduledMergeByPullID(pullID int64) (bool, *PullAutoMerge, error) {
+	scheduledPRM := new(PullAutoMerge)
+	exist, err := x.Where("pull_id = ?", pullID).Get(scheduledPRM)
+	if err != nil || !exist {
+		return false, nil, err
+	}
+	return true, scheduledPRM, nil
+}
+
+// GetScheduledMergePullIDsByRepo returns the IDs of all pull requests scheduled to auto merge
+// whose head or base repository is the given repository
+func GetScheduledMergePullIDsByRepo(repoID int64) ([]int64, error) {
+	pullIDs := make([]int64, 0, 10)
+	return pullIDs, x.Table("pull_auto_merge").
+		Join("INNER", "pull_request", "pull_request.id = pull_auto_merge.pull_id").
+		Where("pull_request.base_repo_id = ? OR pull_request.head_repo_id = ?", repoID, repoID).
+		Cols("pull_auto_merge.pull_id").
+		Find(&pullIDs)
+}
+
+// RemoveScheduledAutoMerge removes the scheduled merge of a pull request.
+// If doer is not nil a comment is added that doer canceled the merge.
+func RemoveScheduledAutoMerge(doer *User, pull *PullRequest) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	deleted, err := sess.Where("pull_id = ?", pull.ID).Delete(new(PullAutoMerge))
+	if err != nil {
+		return err
+	} else if deleted == 0 {
+		return ErrNotExist{ID: pull.ID}
+	}
+
+	if doer != nil {
+		if err := createAutoMergeComment(sess, CommentTypePRUnScheduledToAutoMerge, doer, pull); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleAutoMerge(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+
+	exist, _, err := GetScheduledMergeByPullID(pr.ID)
+	assert.NoError(t, err)
+	assert.False(t, exist)
+
+	assert.NoError(t, ScheduleAutoMerge(doer, pr, MergeStyleSquash, "Squash"))
+	AssertExistsAndLoadBean(t, &Comment{Type: CommentTypePRScheduledToAutoMerge, IssueID: pr.IssueID})
+
+	err = ScheduleAutoMerge(doer, pr, MergeStyleMerge, "")
+	assert.True(t, IsErrAlreadyScheduledToAutoMerge(err))
+
+	exist, scheduledPRM, err := GetScheduledMergeByPullID(pr.ID)
+	assert.NoError(t, err)
+	assert.True(t, exist)
+	assert.EqualValues(t, doer.ID, scheduledPRM.DoerID)
+	assert.Equal(t, MergeStyleSquash, scheduledPRM.MergeStyle)
+	assert.Equal(t, "Squash", scheduledPRM.Message)
+
+	pullIDs, err := GetScheduledMergePullIDsByRepo(pr.BaseRepoID)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{pr.ID}, pullIDs)
+
+	assert.NoError(t, RemoveScheduledAutoMerge(doer, pr))
+	AssertNotExistsBean(t, &PullAutoMerge{PullID: pr.ID})
+	AssertExistsAndLoadBean(t, &Comment{Type: CommentTypePRUnScheduledToAutoMerge, IssueID: pr.IssueID})
+
+	assert.True(t, IsErrNotExist(RemoveScheduledAutoMerge(doer, pr)))
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+)
+
+// ConvertPullRequestOptions represents the options of the conversion of a pull request to an issue.
+type ConvertPullRequestOptions struct {
+	Doer        *User
+	Pull        *Issue
+	Issue       *Issue
+	LabelIDs    []int64
+	AssigneeIDs []int64
+	WatcherIDs  []int64
+}
+
+// ConvertPullRequestResult represents what has been created by the conversion of a pull request to an issue.
+type ConvertPullRequestResult struct {
+	Mentions         []*User
+	AssigneeComments []*Comment
+	CloseComment     *Comment
+}
+
+// ConvertPullRequestToIssue creates the issue replacing a pull request with its labels and assignees,
+// lets the given users watch it and adds the timeline events referencing each other to both of them,
+// in one transaction. The pull request is closed last, so that nothing is left behind if it cannot
+// be closed, e.g. because there are dependencies left.
+func ConvertPullRequestToIssue(opts ConvertPullRequestOptions) (*ConvertPullRequestResult, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+	ctx := DBContext{sess}
+	pull, issue := opts.Pull, opts.Issue
+
+	if err := pull.loadRepo(sess); err != nil {
+		return nil, err
+	}
+	if err := pull.loadPoster(sess); err != nil {
+		return nil, err
+	}
+
+	if err := newIssue(sess, issue.Poster, NewIssueOptions{
+		Repo:     pull.Repo,
+		Issue:    issue,
+		LabelIDs: opts.LabelIDs,
+	}); err != nil {
+		if IsErrUserDoesNotHaveAccessToRepo(err) || IsErrNewIssueInsert(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("newIssue: %v", err)
+	}
+
+	result := &ConvertPullRequestResult{
+		AssigneeComments: make([]*Comment, 0, len(opts.AssigneeIDs)),
+	}
+	for _, assigneeID := range opts.AssigneeIDs {
+		assignee, err := getUserByID(sess, assigneeID)
+		if err != nil {
+			return nil, err
+		}
+		valid, err := canBeAssigned(sess, assignee, issue.Repo, false)
+		if err != nil {
+			return nil, err
+		} else if !valid {
+			return nil, ErrUserDoesNotHaveAccessToRepo{UserID: assigneeID, RepoName: issue.Repo.Name}
+		}
+		_, comment, err := issue.toggleAssignee(sess, issue.Poster, assigneeID, true)
+		if err != nil {
+			return nil, err
+		}
+		result.AssigneeComments = append(result.AssigneeComments, comment)
+	}
+
+	var err error
+	if result.Mentions, err = issue.FindAndUpdateIssueMentions(ctx, issue.Poster, issue.Content); err != nil {
+		return nil, err
+	}
+
+	for _, userID := range opts.WatcherIDs {
+		if err := createOrUpdateIssueWatch(sess, userID, issue.ID, true); err != nil {
+			return nil, fmt.Errorf("createOrUpdateIssueWatch: %v", err)
+		}
+	}
+
+	if _, err := createComment(sess, &CreateCommentOptions{
+		Type:       CommentTypeConvertToIssue,
+		Doer:       opts.Doer,
+		Repo:       pull.Repo,
+		Issue:      pull,
+		RefRepoID:  issue.RepoID,
+		RefIssueID: issue.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("createComment: %v", err)
+	}
+	if _, err := createComment(sess, &CreateCommentOptions{
+		Type:       CommentTypeConvertFromPull,
+		Doer:       opts.Doer,
+		Repo:       issue.Repo,
+		Issue:      issue,
+		RefRepoID:  pull.RepoID,
+		RefIssueID: pull.ID,
+		RefIsPull:  true,
+	}); err != nil {
+		return nil, fmt.Errorf("createComment: %v", err)
+	}
+
+	if result.CloseComment, err = pull.changeStatus(sess, opts.Doer, true, false); err != nil {
+		return nil, err
+	}
+
+	if err := sess.Commit(); err != nil {
+		return nil, fmt.Errorf("Commit: %v", err)
+	}
+	return result, nil
+}
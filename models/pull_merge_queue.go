@@ -0,0 +1,268 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// mergeQueueEstimateSamples is the number of recent merges the duration of a merge is estimated from
+const mergeQueueEstimateSamples = 20
+
+// MergeQueueStatus represents the state of a merge queue entry
+type MergeQueueStatus int
+
+// enumerate all merge queue statuses
+const (
+	MergeQueueStatusWaiting MergeQueueStatus = iota // 0 waiting for the entries before it
+	MergeQueueStatusTesting                         // 1 test commit pushed, waiting for status checks
+)
+
+// String returns the name of the status
+func (s MergeQueueStatus) String() string {
+	switch s {
+	case MergeQueueStatusWaiting:
+		return "waiting"
+	case MergeQueueStatusTesting:
+		return "testing"
+	}
+	return "unknown"
+}
+
+// MergeQueueEntry represents a pull request waiting in the merge queue of
+// a protected branch. Entries are merged in the order they were added.
+type MergeQueueEntry struct {
+	ID           int64            `xorm:"pk autoincr"`
+	PullID       int64            `xorm:"UNIQUE NOT NULL"`
+	Pull         *PullRequest     `xorm:"-"`
+	RepoID       int64            `xorm:"INDEX(s) NOT NULL"`
+	BaseBranch   string           `xorm:"INDEX(s) NOT NULL"`
+	DoerID       int64            `xorm:"NOT NULL"`
+	Doer         *User            `xorm:"-"`
+	MergeStyle   MergeStyle       `xorm:"VARCHAR(50)"`
+	Message      string           `xorm:"TEXT"`
+	HeadCommitID string           `xorm:"VARCHAR(40)"` // head of the pull request when it was added
+	Status       MergeQueueStatus `xorm:"NOT NULL DEFAULT 0"`
+	BaseCommitID string           `xorm:"VARCHAR(40)"`       // head of the base branch the test commit is built on
+	TestCommitID string           `xorm:"VARCHAR(40) INDEX"` // the merge result that is being tested
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// LoadAttributes loads the pull request and the user who added the entry
+func (e *MergeQueueEntry) LoadAttributes() (err error) {
+	if e.Pull == nil {
+		if e.Pull, err = GetPullRequestByID(e.PullID); err != nil {
+			return err
+		}
+	}
+	if e.Doer == nil {
+		if e.Doer, err = GetUserByID(e.DoerID); err != nil {
+			if !IsErrUserNotExist(err) {
+				return err
+			}
+			e.Doer = NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// TestBranchName returns the name of the branch the test commit of the entry is pushed to.
+// The pull request must be loaded.
+func (e *MergeQueueEntry) TestBranchName() string {
+	return fmt.Sprintf("gitea-merge-queue/%s/pr-%d", e.BaseBranch, e.Pull.Index)
+}
+
+// AddToMergeQueue appends a pull request to the merge queue of its base branch
+func AddToMergeQueue(entry *MergeQueueEntry) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	has, err := sess.Where("pull_id = ?", entry.PullID).Exist(new(MergeQueueEntry))
+	if err != nil {
+		return err
+	} else if has {
+		return ErrPullAlreadyInMergeQueue{PullID: entry.PullID}
+	}
+
+	entry.Status = MergeQueueStatusWaiting
+	if _, err := sess.Insert(entry); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// GetMergeQueueEntryByPullID returns the merge queue entry of a pull request
+func GetMergeQueueEntryByPullID(pullID int64) (*MergeQueueEntry, error) {
+	entry := new(MergeQueueEntry)
+	has, err := x.Where("pull_id = ?", pullID).Get(entry)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrMergeQueueEntryNotExist{PullID: pullID}
+	}
+	return entry, nil
+}
+
+// GetMergeQueueEntryByTestCommitID returns the merge queue entry testing the given commit in a repository
+func GetMergeQueueEntryByTestCommitID(repoID int64, commitID string) (*MergeQueueEntry, error) {
+	entry := new(MergeQueueEntry)
+	has, err := x.Where("repo_id = ? AND test_commit_id = ?", repoID, commitID).Get(entry)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrMergeQueueEntryNotExist{}
+	}
+	return entry, nil
+}
+
+// GetMergeQueue returns the merge queue of a branch in the order the entries will be merged.
+// If branch is empty the entries of all branches of the repository are returned.
+func GetMergeQueue(repoID int64, branch string) ([]*MergeQueueEntry, error) {
+	sess := x.Where("repo_id = ?", repoID)
+	if branch != "" {
+		sess.And("base_branch = ?", branch)
+	}
+	entries := make([]*MergeQueueEntry, 0, 10)
+	return entries, sess.OrderBy("base_branch ASC, id ASC").Find(&entries)
+}
+
+// GetMergeQueueBranches returns all repository and branch pairs that have a non-empty merge queue
+func GetMergeQueueBranches() ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 10)
+	return entries, x.Select("repo_id, base_branch").GroupBy("repo_id, base_branch").Find(&entries)
+}
+
+// Position returns the 1-based position of the entry in the merge queue of its branch
+func (e *MergeQueueEntry) Position() (int64, error) {
+	count, err := x.Where("repo_id = ? AND base_branch = ? AND id < ?", e.RepoID, e.BaseBranch, e.ID).Count(new(MergeQueueEntry))
+	if err != nil {
+		return 0, err
+	}
+	return count + 1, nil
+}
+
+// UpdateMergeQueueEntry updates the test state of a merge queue entry
+func UpdateMergeQueueEntry(entry *MergeQueueEntry) error {
+	_, err := x.ID(entry.ID).Cols("status", "base_commit_id", "test_commit_id").Update(entry)
+	return err
+}
+
+// RemoveFromMergeQueue removes a merge queue entry
+func RemoveFromMergeQueue(entry *MergeQueueEntry) error {
+	if _, err := x.ID(entry.ID).Delete(new(MergeQueueEntry)); err != nil {
+		return fmt.Errorf("delete merge queue entry %d: %v", entry.ID, err)
+	}
+	return nil
+}
+
+// MergeQueueResult records an entry which left the merge queue of a branch, either because
+// it was merged or because it was removed.
+type MergeQueueResult struct {
+	ID              int64        `xorm:"pk autoincr"`
+	RepoID          int64        `xorm:"INDEX(s) NOT NULL"`
+	BaseBranch      string       `xorm:"INDEX(s) NOT NULL"`
+	PullID          int64        `xorm:"INDEX NOT NULL"`
+	Pull            *PullRequest `xorm:"-"`
+	DoerID          int64        `xorm:"NOT NULL"` // who added the merged entry or removed it
+	Doer            *User        `xorm:"-"`
+	IsMerged        bool         `xorm:"NOT NULL DEFAULT false"`
+	Reason          string       `xorm:"TEXT"` // why the entry was removed, empty if it was removed by the doer
+	TestCommitID    string       `xorm:"VARCHAR(40)"`
+	AddedUnix       timeutil.TimeStamp
+	TestStartedUnix timeutil.TimeStamp // zero if the entry was never tested
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// NewResult returns the result of the entry leaving the merge queue
+func (e *MergeQueueEntry) NewResult(doerID int64, isMerged bool, reason string) *MergeQueueResult {
+	result := &MergeQueueResult{
+		RepoID:       e.RepoID,
+		BaseBranch:   e.BaseBranch,
+		PullID:       e.PullID,
+		Pull:         e.Pull,
+		DoerID:       doerID,
+		IsMerged:     isMerged,
+		Reason:       reason,
+		TestCommitID: e.TestCommitID,
+		AddedUnix:    e.CreatedUnix,
+	}
+	if e.Status == MergeQueueStatusTesting {
+		result.TestStartedUnix = e.UpdatedUnix
+	}
+	return result
+}
+
+// LoadAttributes loads the pull request and the doer of the result
+func (r *MergeQueueResult) LoadAttributes() (err error) {
+	if r.Pull == nil {
+		if r.Pull, err = GetPullRequestByID(r.PullID); err != nil {
+			return err
+		}
+	}
+	if err = r.Pull.LoadIssue(); err != nil {
+		return err
+	}
+	if r.Doer == nil {
+		if r.Doer, err = GetUserByID(r.DoerID); err != nil {
+			if !IsErrUserNotExist(err) {
+				return err
+			}
+			r.Doer = NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// Duration returns the time from the start of the test until the entry left the queue, zero if it was never tested
+func (r *MergeQueueResult) Duration() time.Duration {
+	if r.TestStartedUnix == 0 || r.CreatedUnix < r.TestStartedUnix {
+		return 0
+	}
+	return time.Duration(r.CreatedUnix-r.TestStartedUnix) * time.Second
+}
+
+// AddMergeQueueResult records an entry which left the merge queue
+func AddMergeQueueResult(result *MergeQueueResult) error {
+	_, err := x.Insert(result)
+	return err
+}
+
+// GetMergeQueueResults returns the most recent results of the merge queue of a branch which were merged or not
+func GetMergeQueueResults(repoID int64, branch string, isMerged bool, limit int) ([]*MergeQueueResult, error) {
+	results := make([]*MergeQueueResult, 0, limit)
+	return results, x.Where("repo_id = ? AND base_branch = ? AND is_merged = ?", repoID, branch, isMerged).
+		OrderBy("created_unix DESC, id DESC").
+		Limit(limit).
+		Find(&results)
+}
+
+// GetMergeQueueAverageDuration returns the average time the recent merges of a branch took from the start
+// of their test until they were merged, zero if no entry has been merged yet
+func GetMergeQueueAverageDuration(repoID int64, branch string) (time.Duration, error) {
+	results := make([]*MergeQueueResult, 0, mergeQueueEstimateSamples)
+	if err := x.Where("repo_id = ? AND base_branch = ? AND is_merged = ? AND test_started_unix > 0", repoID, branch, true).
+		OrderBy("created_unix DESC, id DESC").
+		Limit(mergeQueueEstimateSamples).
+		Find(&results); err != nil {
+		return 0, err
+	}
+	if len(results) == 0 {
+		return 0, nil
+	}
+	var total time.Duration
+	for _, result := range results {
+		total += result.Duration()
+	}
+	return total / time.Duration(len(results)), nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeQueue(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	first := &MergeQueueEntry{PullID: 2, RepoID: 1, BaseBranch: "master", DoerID: 2, MergeStyle: MergeStyleMerge}
+	second := &MergeQueueEntry{PullID: 5, RepoID: 1, BaseBranch: "master", DoerID: 2, MergeStyle: MergeStyleSquash}
+	assert.NoError(t, AddToMergeQueue(first))
+	assert.NoError(t, AddToMergeQueue(second))
+
+	err := AddToMergeQueue(&MergeQueueEntry{PullID: 2, RepoID: 1, BaseBranch: "master", DoerID: 1})
+	assert.True(t, IsErrPullAlreadyInMergeQueue(err))
+
+	entries, err := GetMergeQueue(1, "master")
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.EqualValues(t, 2, entries[0].PullID)
+		assert.EqualValues(t, 5, entries[1].PullID)
+	}
+	entries, err = GetMergeQueue(1, "develop")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 0)
+
+	position, err := second.Position()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, position)
+
+	branches, err := GetMergeQueueBranches()
+	assert.NoError(t, err)
+	if assert.Len(t, branches, 1) {
+		assert.EqualValues(t, 1, branches[0].RepoID)
+		assert.EqualValues(t, "master", branches[0].BaseBranch)
+	}
+
+	first.Status = MergeQueueStatusTesting
+	first.TestCommitID = "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+	assert.NoError(t, UpdateMergeQueueEntry(first))
+	entry, err := GetMergeQueueEntryByTestCommitID(1, first.TestCommitID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, first.ID, entry.ID)
+	assert.Equal(t, MergeQueueStatusTesting, entry.Status)
+
+	assert.NoError(t, RemoveFromMergeQueue(first))
+	_, err = GetMergeQueueEntryByPullID(2)
+	assert.True(t, IsErrMergeQueueEntryNotExist(err))
+
+	position, err = second.Position()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, position)
+}
+
+func TestMergeQueueResults(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	avg, err := GetMergeQueueAverageDuration(1, "master")
+	assert.NoError(t, err)
+	assert.Zero(t, avg)
+
+	now := time.Now().Unix()
+	for _, result := range []*MergeQueueResult{
+		{RepoID: 1, BaseBranch: "master", PullID: 2, DoerID: 2, IsMerged: true, TestStartedUnix: timeutil.TimeStamp(now - 600), CreatedUnix: timeutil.TimeStamp(now - 300)},
+		{RepoID: 1, BaseBranch: "master", PullID: 5, DoerID: 2, IsMerged: true, TestStartedUnix: timeutil.TimeStamp(now - 200), CreatedUnix: timeutil.TimeStamp(now - 100)},
+		{RepoID: 1, BaseBranch: "master", PullID: 2, DoerID: 2, Reason: "The required status checks failed.", CreatedUnix: timeutil.TimeStamp(now - 50)},
+		{RepoID: 1, BaseBranch: "develop", PullID: 5, DoerID: 2, IsMerged: true, TestStartedUnix: timeutil.TimeStamp(now - 10000), CreatedUnix: timeutil.TimeStamp(now)},
+	} {
+		_, err := x.NoAutoTime().Insert(result)
+		assert.NoError(t, err)
+	}
+
+	avg, err = GetMergeQueueAverageDuration(1, "master")
+	assert.NoError(t, err)
+	assert.Equal(t, 200*time.Second, avg)
+
+	failures, err := GetMergeQueueResults(1, "master", false, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, failures, 1) {
+		assert.Equal(t, "The required status checks failed.", failures[0].Reason)
+		assert.Zero(t, failures[0].Duration())
+	}
+
+	merges, err := GetMergeQueueResults(1, "master", true, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, merges, 1) {
+		assert.EqualValues(t, 5, merges[0].PullID)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// PullMergeRequirement is a named requirement an external bot registered on a pull request, e.g. a signed contributor
+// license agreement or a passed security review. The pull request cannot be merged until all its requirements are
+// satisfied. Unlike the commit statuses, a requirement stays on the pull request when new commits are pushed.
+type PullMergeRequirement struct {
+	ID          int64  `xorm:"pk autoincr"`
+	RepoID      int64  `xorm:"INDEX NOT NULL"`
+	PullID      int64  `xorm:"UNIQUE(s) NOT NULL"`
+	Name        string `xorm:"UNIQUE(s) VARCHAR(255) NOT NULL"`
+	IsSatisfied bool   `xorm:"NOT NULL DEFAULT false"`
+	Description string `xorm:"TEXT"`
+	TargetURL   string `xorm:"TEXT"`
+	// CreatorID is the user who last set the requirement
+	CreatorID int64 `xorm:"NOT NULL"`
+	Creator   *User `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// LoadCreator loads the user who last set the requirement, a ghost user if it has been deleted
+func (r *PullMergeRequirement) LoadCreator() error {
+	if r.Creator != nil {
+		return nil
+	}
+	creator, err := GetUserByID(r.CreatorID)
+	if err != nil {
+		if !IsErrUserNotExist(err) {
+			return err
+		}
+		creator = NewGhostUser()
+	}
+	r.Creator = creator
+	return nil
+}
+
+// ErrPullMergeRequirementNotExist represents a "PullMergeRequirementNotExist" kind of error.
+type ErrPullMergeRequirementNotExist struct {
+	ID     int64
+	PullID int64
+}
+
+// IsErrPullMergeRequirementNotExist checks if an error is a ErrPullMergeRequirementNotExist.
+func IsErrPullMergeRequirementNotExist(err error) bool {
+	_, ok := err.(ErrPullMergeRequirementNotExist)
+	return ok
+}
+
+func (err ErrPullMergeRequirementNotExist) Error() string {
+	return fmt.Sprintf("pull merge requirement does not exist [id: %d, pull_id: %d]", err.ID, err.PullID)
+}
+
+// SetPullMergeRequirement registers a merge requirement on a pull request, or changes the state of the requirement of
+// the pull request with the same name. It returns whether the requirement has been registered.
+func SetPullMergeRequirement(r *PullMergeRequirement) (bool, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return false, err
+	}
+
+	existing := new(PullMergeRequirement)
+	has, err := sess.Where("pull_id = ?", r.PullID).And("name = ?", r.Name).Get(existing)
+	if err != nil {
+		return false, err
+	}
+	if has {
+		r.ID = existing.ID
+		r.CreatedUnix = existing.CreatedUnix
+		if _, err := sess.ID(r.ID).Cols("is_satisfied", "description", "target_url", "creator_id").Update(r); err != nil {
+			return false, err
+		}
+	} else if _, err := sess.Insert(r); err != nil {
+		return false, err
+	}
+
+	return !has, sess.Commit()
+}
+
+// GetPullMergeRequirements returns the merge requirements of a pull request, ordered by name
+func GetPullMergeRequirements(pullID int64) ([]*PullMergeRequirement, error) {
+	rs := make([]*PullMergeRequirement, 0, 5)
+	return rs, x.Where("pull_id = ?", pullID).Asc("name").Find(&rs)
+}
+
+// HasUnsatisfiedPullMergeRequirements returns whether a pull request has merge requirements which are not satisfied
+func HasUnsatisfiedPullMergeRequirements(pullID int64) (bool, error) {
+	return x.Where("pull_id = ?", pullID).And("is_satisfied = ?", false).Exist(new(PullMergeRequirement))
+}
+
+// DeletePullMergeRequirement deletes a merge requirement of a pull request
+func DeletePullMergeRequirement(pullID, id int64) error {
+	deleted, err := x.Where("pull_id = ?", pullID).And("id = ?", id).Delete(new(PullMergeRequirement))
+	if err != nil {
+		return err
+	} else if deleted == 0 {
+		return ErrPullMergeRequirementNotExist{ID: id, PullID: pullID}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullMergeRequirements(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+
+	unsatisfied, err := HasUnsatisfiedPullMergeRequirements(pr.ID)
+	assert.NoError(t, err)
+	assert.False(t, unsatisfied)
+
+	cla := &PullMergeRequirement{RepoID: pr.BaseRepoID, PullID: pr.ID, Name: "cla", CreatorID: 2}
+	created, err := SetPullMergeRequirement(cla)
+	assert.NoError(t, err)
+	assert.True(t, created)
+	review := &PullMergeRequirement{RepoID: pr.BaseRepoID, PullID: pr.ID, Name: "security/review", IsSatisfied: true, CreatorID: 2}
+	created, err = SetPullMergeRequirement(review)
+	assert.NoError(t, err)
+	assert.True(t, created)
+
+	unsatisfied, err = HasUnsatisfiedPullMergeRequirements(pr.ID)
+	assert.NoError(t, err)
+	assert.True(t, unsatisfied)
+
+	// the requirement with the same name is changed
+	created, err = SetPullMergeRequirement(&PullMergeRequirement{
+		RepoID:      pr.BaseRepoID,
+		PullID:      pr.ID,
+		Name:        "cla",
+		IsSatisfied: true,
+		Description: "signed",
+		TargetURL:   "https://cla.example.com",
+		CreatorID:   1,
+	})
+	assert.NoError(t, err)
+	assert.False(t, created)
+
+	rs, err := GetPullMergeRequirements(pr.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, rs, 2) {
+		assert.Equal(t, cla.ID, rs[0].ID)
+		assert.True(t, rs[0].IsSatisfied)
+		assert.Equal(t, "signed", rs[0].Description)
+		assert.EqualValues(t, 1, rs[0].CreatorID)
+		assert.Equal(t, "security/review", rs[1].Name)
+	}
+
+	unsatisfied, err = HasUnsatisfiedPullMergeRequirements(pr.ID)
+	assert.NoError(t, err)
+	assert.False(t, unsatisfied)
+
+	assert.NoError(t, DeletePullMergeRequirement(pr.ID, review.ID))
+	assert.True(t, IsErrPullMergeRequirementNotExist(DeletePullMergeRequirement(pr.ID, review.ID)))
+	assert.True(t, IsErrPullMergeRequirementNotExist(DeletePullMergeRequirement(pr.ID+1, cla.ID)))
+	AssertNotExistsBean(t, &PullMergeRequirement{ID: review.ID})
+}
@@ -19,6 +19,15 @@ func (pr *PullRequest) SignMerge(u *User, tmpBasePath, baseCommit, headCommit st
 	repo := pr.BaseRepo
 
 	signingKey, signer := SigningKey(repo.RepoPath())
+	// A SSH signing key of the repository takes precedence over the instance key,
+	// signing with SSH keys requires git >= 2.34
+	if sshSigningKey, err := GetRepoSigningKey(repo.ID); err == nil {
+		if git.CheckGitVersionAtLeast("2.34") == nil {
+			signingKey, signer = sshSigningKey.Fingerprint, sshSigningKey.Signer(repo)
+		}
+	} else if !IsErrRepoSigningKeyNotExist(err) {
+		return false, "", nil, err
+	}
 	if signingKey == "" {
 		return false, "", nil, &ErrWontSign{noKey}
 	}
@@ -234,3 +234,26 @@ func TestPullRequest_GetWorkInProgressPrefixWorkInProgress(t *testing.T) {
 	pr.Issue.Title = "[wip] " + original
 	assert.Equal(t, "[wip]", pr.GetWorkInProgressPrefix())
 }
+
+func TestPullRequest_SetWorkInProgress(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	pr.LoadIssue()
+	original := pr.Issue.Title
+
+	pr.SetWorkInProgress(true)
+	assert.True(t, pr.IsWorkInProgress())
+	assert.Equal(t, "WIP: "+original, pr.Issue.Title)
+
+	pr.SetWorkInProgress(true)
+	assert.Equal(t, "WIP: "+original, pr.Issue.Title)
+
+	pr.SetWorkInProgress(false)
+	assert.False(t, pr.IsWorkInProgress())
+	assert.Equal(t, original, pr.Issue.Title)
+
+	pr.Issue.Title = "[WIP] " + original
+	pr.SetWorkInProgress(false)
+	assert.Equal(t, original, pr.Issue.Title)
+}
@@ -0,0 +1,188 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/gogs/cron"
+)
+
+// RecurringIssue is a template of the issues created in a repository on a schedule, e.g. a weekly release checklist.
+// The issues are posted by the user who last saved the recurring issue.
+type RecurringIssue struct {
+	ID      int64  `xorm:"pk autoincr"`
+	RepoID  int64  `xorm:"INDEX NOT NULL"`
+	DoerID  int64  `xorm:"NOT NULL"`
+	Doer    *User  `xorm:"-"`
+	Title   string `xorm:"NOT NULL"`
+	Content string `xorm:"TEXT"`
+	// Comma separated IDs of the labels of the issues
+	Labels string `xorm:"VARCHAR(1024)"`
+	// Schedule is a standard cron expression with 5 fields or a descriptor like @weekly
+	Schedule string `xorm:"NOT NULL"`
+	IsActive bool   `xorm:"INDEX NOT NULL DEFAULT true"`
+
+	NextRunUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	LastRunUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	LastIssueID int64              `xorm:"NOT NULL DEFAULT 0"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// LabelIDs returns the IDs of the labels of the issues
+func (r *RecurringIssue) LabelIDs() []int64 {
+	return splitUserFilterIDs(r.Labels)
+}
+
+// LoadDoer loads the user who posts the issues, a ghost user if it has been deleted
+func (r *RecurringIssue) LoadDoer() error {
+	if r.Doer != nil {
+		return nil
+	}
+	doer, err := GetUserByID(r.DoerID)
+	if err != nil {
+		if !IsErrUserNotExist(err) {
+			return err
+		}
+		doer = NewGhostUser()
+	}
+	r.Doer = doer
+	return nil
+}
+
+// ErrRecurringIssueNotExist represents a "RecurringIssueNotExist" kind of error.
+type ErrRecurringIssueNotExist struct {
+	ID int64
+}
+
+// IsErrRecurringIssueNotExist checks if an error is a ErrRecurringIssueNotExist.
+func IsErrRecurringIssueNotExist(err error) bool {
+	_, ok := err.(ErrRecurringIssueNotExist)
+	return ok
+}
+
+func (err ErrRecurringIssueNotExist) Error() string {
+	return fmt.Sprintf("recurring issue does not exist [id: %d]", err.ID)
+}
+
+// ErrInvalidRecurringIssue represents a "InvalidRecurringIssue" kind of error.
+type ErrInvalidRecurringIssue struct {
+	Reason string
+}
+
+// IsErrInvalidRecurringIssue checks if an error is a ErrInvalidRecurringIssue.
+func IsErrInvalidRecurringIssue(err error) bool {
+	_, ok := err.(ErrInvalidRecurringIssue)
+	return ok
+}
+
+func (err ErrInvalidRecurringIssue) Error() string {
+	return fmt.Sprintf("invalid recurring issue: %s", err.Reason)
+}
+
+// nextRun returns when the next issue is created after a time
+func (r *RecurringIssue) nextRun(after time.Time) (timeutil.TimeStamp, error) {
+	schedule, err := cron.ParseStandard(r.Schedule)
+	if err != nil {
+		return 0, ErrInvalidRecurringIssue{fmt.Sprintf("invalid schedule %q: %v", r.Schedule, err)}
+	}
+	next := schedule.Next(after)
+	if next.IsZero() {
+		return 0, ErrInvalidRecurringIssue{fmt.Sprintf("the schedule %q never runs", r.Schedule)}
+	}
+	return timeutil.TimeStamp(next.Unix()), nil
+}
+
+func (r *RecurringIssue) validate() (err error) {
+	r.Title = strings.TrimSpace(r.Title)
+	r.Schedule = strings.TrimSpace(r.Schedule)
+	if len(r.Title) == 0 {
+		return ErrInvalidRecurringIssue{"the title is empty"}
+	}
+	r.NextRunUnix, err = r.nextRun(time.Now())
+	return err
+}
+
+// NewRecurringIssue creates a recurring issue, scheduling its first issue
+func NewRecurringIssue(r *RecurringIssue) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+	_, err := x.Insert(r)
+	return err
+}
+
+// UpdateRecurringIssue updates a recurring issue, scheduling its next issue again
+func UpdateRecurringIssue(r *RecurringIssue) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+	_, err := x.ID(r.ID).Cols("doer_id", "title", "content", "labels", "schedule", "is_active", "next_run_unix").Update(r)
+	return err
+}
+
+// DeleteRecurringIssue deletes a recurring issue of a repository, the issues already created being kept
+func DeleteRecurringIssue(repoID, id int64) error {
+	deleted, err := x.Where("repo_id = ?", repoID).And("id = ?", id).Delete(new(RecurringIssue))
+	if err != nil {
+		return err
+	} else if deleted == 0 {
+		return ErrRecurringIssueNotExist{id}
+	}
+	return nil
+}
+
+// GetRecurringIssueInRepoByID returns a recurring issue of a repository
+func GetRecurringIssueInRepoByID(repoID, id int64) (*RecurringIssue, error) {
+	r := new(RecurringIssue)
+	has, err := x.Where("repo_id = ?", repoID).And("id = ?", id).Get(r)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRecurringIssueNotExist{id}
+	}
+	return r, nil
+}
+
+// GetRecurringIssuesByRepoID returns the recurring issues of a repository, ordered by title
+func GetRecurringIssuesByRepoID(repoID int64) ([]*RecurringIssue, error) {
+	rs := make([]*RecurringIssue, 0, 5)
+	return rs, x.Where("repo_id = ?", repoID).Asc("title", "id").Find(&rs)
+}
+
+// GetDueRecurringIssues returns the active recurring issues whose next issue is due at a time, the oldest first
+func GetDueRecurringIssues(now timeutil.TimeStamp) ([]*RecurringIssue, error) {
+	rs := make([]*RecurringIssue, 0, 10)
+	return rs, x.Where("is_active = ?", true).And("next_run_unix <= ?", now).Asc("next_run_unix").Find(&rs)
+}
+
+// FinishRecurringIssueRun records the issue created by a recurring issue, 0 if none could be created, and schedules
+// its next issue after a time. A recurring issue whose schedule became invalid is deactivated.
+func FinishRecurringIssueRun(r *RecurringIssue, issueID int64, now time.Time) error {
+	r.LastRunUnix = timeutil.TimeStamp(now.Unix())
+	if issueID > 0 {
+		r.LastIssueID = issueID
+	}
+	next, err := r.nextRun(now)
+	if err != nil {
+		r.IsActive = false
+	}
+	r.NextRunUnix = next
+	_, err = x.ID(r.ID).Cols("last_run_unix", "last_issue_id", "next_run_unix", "is_active").Update(r)
+	return err
+}
+
+// DeactivateRecurringIssue stops a recurring issue from creating issues
+func DeactivateRecurringIssue(r *RecurringIssue) error {
+	r.IsActive = false
+	_, err := x.ID(r.ID).Cols("is_active").Update(r)
+	return err
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecurringIssues(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.True(t, IsErrInvalidRecurringIssue(NewRecurringIssue(&RecurringIssue{RepoID: 1, DoerID: 2, Title: " ", Schedule: "@weekly"})))
+	assert.True(t, IsErrInvalidRecurringIssue(NewRecurringIssue(&RecurringIssue{RepoID: 1, DoerID: 2, Title: "Checklist", Schedule: "every week"})))
+
+	now := time.Now()
+	r := &RecurringIssue{RepoID: 1, DoerID: 2, Title: " Checklist ", Labels: "1,2", Schedule: "@weekly", IsActive: true}
+	assert.NoError(t, NewRecurringIssue(r))
+	assert.Equal(t, "Checklist", r.Title)
+	assert.Equal(t, []int64{1, 2}, r.LabelIDs())
+	assert.True(t, r.NextRunUnix > timeutil.TimeStamp(now.Unix()))
+	assert.True(t, r.NextRunUnix <= timeutil.TimeStamp(now.AddDate(0, 0, 7).Unix()))
+	assert.NoError(t, NewRecurringIssue(&RecurringIssue{RepoID: 1, DoerID: 2, Title: "Inactive", Schedule: "@daily"}))
+
+	rs, err := GetRecurringIssuesByRepoID(1)
+	assert.NoError(t, err)
+	assert.Len(t, rs, 2)
+	_, err = GetRecurringIssueInRepoByID(2, r.ID)
+	assert.True(t, IsErrRecurringIssueNotExist(err))
+
+	// only the active recurring issues are due
+	rs, err = GetDueRecurringIssues(timeutil.TimeStamp(now.Unix()))
+	assert.NoError(t, err)
+	assert.Len(t, rs, 0)
+	rs, err = GetDueRecurringIssues(r.NextRunUnix)
+	assert.NoError(t, err)
+	if assert.Len(t, rs, 1) {
+		assert.Equal(t, r.ID, rs[0].ID)
+	}
+
+	// a run schedules the next issue
+	run := r.NextRunUnix.AsTime()
+	assert.NoError(t, FinishRecurringIssueRun(r, 1, run))
+	r = AssertExistsAndLoadBean(t, &RecurringIssue{ID: r.ID}).(*RecurringIssue)
+	assert.EqualValues(t, 1, r.LastIssueID)
+	assert.Equal(t, timeutil.TimeStamp(run.Unix()), r.LastRunUnix)
+	assert.Equal(t, timeutil.TimeStamp(run.AddDate(0, 0, 7).Unix()), r.NextRunUnix)
+
+	r.Schedule = "0 9 * * 1"
+	assert.NoError(t, UpdateRecurringIssue(r))
+	assert.Equal(t, time.Monday, r.NextRunUnix.AsTime().Weekday())
+	assert.True(t, IsErrInvalidRecurringIssue(UpdateRecurringIssue(&RecurringIssue{ID: r.ID, Title: "Checklist", Schedule: "0 25 * * *"})))
+
+	assert.True(t, IsErrRecurringIssueNotExist(DeleteRecurringIssue(2, r.ID)))
+	assert.NoError(t, DeleteRecurringIssue(1, r.ID))
+	AssertNotExistsBean(t, &RecurringIssue{ID: r.ID})
+}
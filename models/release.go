@@ -40,6 +40,7 @@ type Release struct {
 	IsPrerelease     bool               `xorm:"NOT NULL DEFAULT false"`
 	IsTag            bool               `xorm:"NOT NULL DEFAULT false"`
 	Attachments      []*Attachment      `xorm:"-"`
+	Artifacts        []*RepoArtifact    `xorm:"-"`
 	CreatedUnix      timeutil.TimeStamp `xorm:"INDEX"`
 }
 
@@ -25,6 +25,7 @@ import (
 	"strings"
 	"time"
 
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/options"
@@ -242,6 +243,12 @@ type Repository struct {
 
 	TrustModel TrustModelType
 
+	// IsMaintenance makes the repository read-only for everyone but its administrators
+	// until MaintenanceEndUnix, or until it is turned off if MaintenanceEndUnix is zero.
+	IsMaintenance      bool `xorm:"NOT NULL DEFAULT false"`
+	MaintenanceEndUnix timeutil.TimeStamp
+	MaintenanceMessage string `xorm:"TEXT"`
+
 	// Avatar: ID(10-20)-md5(32) - must fit into 64 symbols
 	Avatar string `xorm:"VARCHAR(64)"`
 
@@ -526,6 +533,8 @@ func (repo *Repository) deleteWiki(e Engine) error {
 	wikiPaths := []string{repo.WikiPath()}
 	for _, wikiPath := range wikiPaths {
 		removeAllWithNotice(e, "Delete repository wiki", wikiPath)
+		git.InvalidateRefsCache(wikiPath)
+		git.InvalidatePackCache(wikiPath)
 	}
 
 	_, err := e.Where("repo_id = ?", repo.ID).And("type = ?", UnitTypeWiki).Delete(new(RepoUnit))
@@ -1022,7 +1031,7 @@ func GetRepoInitFile(tp, name string) ([]byte, error) {
 }
 
 var (
-	reservedRepoNames    = []string{".", ".."}
+	reservedRepoNames    = []string{".", "..", "-"}
 	reservedRepoPatterns = []string{"*.git", "*.wiki"}
 )
 
@@ -1218,6 +1227,8 @@ func ChangeRepositoryName(doer *User, repo *Repository, newRepoName string) (err
 	if err = os.Rename(repo.RepoPath(), newRepoPath); err != nil {
 		return fmt.Errorf("rename repository directory: %v", err)
 	}
+	git.InvalidateRefsCache(repo.RepoPath())
+	git.InvalidatePackCache(repo.RepoPath())
 
 	wikiPath := repo.WikiPath()
 	isExist, err := util.IsExist(wikiPath)
@@ -1229,6 +1240,8 @@ func ChangeRepositoryName(doer *User, repo *Repository, newRepoName string) (err
 		if err = os.Rename(wikiPath, WikiPath(repo.Owner.Name, newRepoName)); err != nil {
 			return fmt.Errorf("rename repository wiki: %v", err)
 		}
+		git.InvalidateRefsCache(wikiPath)
+		git.InvalidatePackCache(wikiPath)
 	}
 
 	sess := x.NewSession()
@@ -1451,12 +1464,23 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		return err
 	}
 
+	var actionArtifacts []*ActionArtifact
+	if err = sess.Where("repo_id = ?", repoID).Find(&actionArtifacts); err != nil {
+		return err
+	}
+
+	var repoArtifacts []*RepoArtifact
+	if err = sess.Where("repo_id = ?", repoID).Find(&repoArtifacts); err != nil {
+		return err
+	}
+
 	if err := deleteBeans(sess,
 		&Access{RepoID: repo.ID},
 		&Action{RepoID: repo.ID},
 		&Watch{RepoID: repoID},
 		&Star{RepoID: repoID},
 		&Mirror{RepoID: repoID},
+		&PushMirror{RepoID: repoID},
 		&Milestone{RepoID: repoID},
 		&Release{RepoID: repoID},
 		&Collaboration{RepoID: repoID},
@@ -1471,6 +1495,23 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		&LanguageStat{RepoID: repoID},
 		&Comment{RefRepoID: repoID},
 		&Task{RepoID: repoID},
+		&IssueCollaborator{RepoID: repoID},
+		&RepoHealth{RepoID: repoID},
+		&MigrationState{RepoID: repoID},
+		&RepoSigningKey{RepoID: repoID},
+		&ActionRun{RepoID: repoID},
+		&ActionRunJob{RepoID: repoID},
+		&ActionArtifact{RepoID: repoID},
+		&RepoArtifact{RepoID: repoID},
+		&MergeQueueResult{RepoID: repoID},
+		&UserFilter{RepoID: repoID},
+		&IssueType{RepoID: repoID},
+		&RepoEvent{RepoID: repoID},
+		&RecurringIssue{RepoID: repoID},
+		&DueDateReminder{RepoID: repoID},
+		&IssueSLA{RepoID: repoID},
+		&IssueSLABreach{RepoID: repoID},
+		&PullMergeRequirement{RepoID: repoID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
@@ -1521,6 +1562,9 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 	// FIXME: Remove repository files should be executed after transaction succeed.
 	repoPath := repo.RepoPath()
 	removeAllWithNotice(sess, "Delete repository files", repoPath)
+	git.InvalidateRefsCache(repoPath)
+	git.InvalidatePackCache(repoPath)
+	removeAllWithNotice(sess, "Delete actions logs", filepath.Join(setting.Actions.LogPath, strconv.FormatInt(repoID, 10)))
 
 	err = repo.deleteWiki(sess)
 	if err != nil {
@@ -1574,6 +1618,21 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		RemoveStorageWithNotice(storage.Attachments, "Delete release attachment", releaseAttachments[i])
 	}
 
+	// Remove actions artifact files.
+	for _, artifact := range actionArtifacts {
+		RemoveStorageWithNotice(storage.Actions, "Delete actions artifact", artifact.RelativePath())
+	}
+
+	// Remove artifact files.
+	for _, artifact := range repoArtifacts {
+		RemoveStorageWithNotice(storage.Artifacts, "Delete artifact", artifact.RelativePath())
+	}
+
+	// Remove archive files.
+	if err := deleteRepositoryArchivesOf(repo.ID); err != nil {
+		log.Error("Failed to remove the archives of repository %d: %v", repo.ID, err)
+	}
+
 	if len(repo.Avatar) > 0 {
 		if err := storage.RepoAvatars.Delete(repo.CustomAvatarRelativePath()); err != nil {
 			return fmt.Errorf("Failed to remove %s: %v", repo.Avatar, err)
@@ -1712,6 +1771,18 @@ func GetPrivateRepositoryCount(u *User) (int64, error) {
 
 // DeleteRepositoryArchives deletes all repositories' archives.
 func DeleteRepositoryArchives(ctx context.Context) error {
+	if err := storage.RepoArchives.IterateObjects(func(path string, obj storage.Object) error {
+		select {
+		case <-ctx.Done():
+			return ErrCancelledf("before deleting repository archive %s", path)
+		default:
+		}
+		return storage.RepoArchives.Delete(path)
+	}); err != nil {
+		return err
+	}
+
+	// Archives used to be stored in the repositories themselves
 	return x.
 		Where("id > 0").
 		Iterate(new(Repository),
@@ -1726,71 +1797,81 @@ func DeleteRepositoryArchives(ctx context.Context) error {
 			})
 }
 
-// DeleteOldRepositoryArchives deletes old repository archives.
-func DeleteOldRepositoryArchives(ctx context.Context, olderThan time.Duration) error {
+// DeleteOldRepositoryArchives deletes the repository archives which are older than olderThan, then
+// the oldest remaining ones until the archives take up no more than maxSize bytes unless it is negative.
+func DeleteOldRepositoryArchives(ctx context.Context, olderThan time.Duration, maxSize int64) error {
 	log.Trace("Doing: ArchiveCleanup")
 
-	if err := x.Where("id > 0").Iterate(new(Repository), func(idx int, bean interface{}) error {
-		return deleteOldRepositoryArchives(ctx, olderThan, idx, bean)
-	}); err != nil {
-		log.Trace("Error: ArchiveClean: %v", err)
-		return err
+	type storedArchive struct {
+		path    string
+		size    int64
+		modTime time.Time
 	}
+	var remaining []storedArchive
+	var totalSize int64
 
-	log.Trace("Finished: ArchiveCleanup")
-	return nil
-}
-
-func deleteOldRepositoryArchives(ctx context.Context, olderThan time.Duration, idx int, bean interface{}) error {
-	repo := bean.(*Repository)
-	basePath := filepath.Join(repo.RepoPath(), "archives")
-
-	for _, ty := range []string{"zip", "targz"} {
+	minimumOldestTime := time.Now().Add(-olderThan)
+	if err := storage.RepoArchives.IterateObjects(func(path string, obj storage.Object) error {
 		select {
 		case <-ctx.Done():
-			return ErrCancelledf("before deleting old repository archives with filetype %s for %s", ty, repo.FullName())
+			return ErrCancelledf("before deleting old repository archive %s", path)
 		default:
 		}
-
-		path := filepath.Join(basePath, ty)
-		file, err := os.Open(path)
-		if err != nil {
-			if !os.IsNotExist(err) {
-				log.Warn("Unable to open directory %s: %v", path, err)
-				return err
-			}
-
-			// If the directory doesn't exist, that's okay.
-			continue
-		}
-
-		files, err := file.Readdir(0)
-		file.Close()
+		info, err := obj.Stat()
 		if err != nil {
-			log.Warn("Unable to read directory %s: %v", path, err)
 			return err
 		}
+		if info.ModTime().Before(minimumOldestTime) {
+			// This is a best-effort purge, so we do not check error codes to confirm removal.
+			if err := storage.RepoArchives.Delete(path); err != nil {
+				log.Trace("Unable to delete %s, but proceeding: %v", path, err)
+			}
+			return nil
+		}
+		remaining = append(remaining, storedArchive{path: path, size: info.Size(), modTime: info.ModTime()})
+		totalSize += info.Size()
+		return nil
+	}); err != nil {
+		log.Trace("Error: ArchiveClean: %v", err)
+		return err
+	}
 
-		minimumOldestTime := time.Now().Add(-olderThan)
-		for _, info := range files {
-			if info.ModTime().Before(minimumOldestTime) && !info.IsDir() {
-				select {
-				case <-ctx.Done():
-					return ErrCancelledf("before deleting old repository archive file %s with filetype %s for %s", info.Name(), ty, repo.FullName())
-				default:
-				}
-				toDelete := filepath.Join(path, info.Name())
-				// This is a best-effort purge, so we do not check error codes to confirm removal.
-				if err = util.Remove(toDelete); err != nil {
-					log.Trace("Unable to delete %s, but proceeding: %v", toDelete, err)
-				}
+	if maxSize >= 0 && totalSize > maxSize {
+		sort.Slice(remaining, func(i, j int) bool {
+			return remaining[i].modTime.Before(remaining[j].modTime)
+		})
+		for _, archive := range remaining {
+			if totalSize <= maxSize {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return ErrCancelledf("before evicting repository archive %s", archive.path)
+			default:
+			}
+			if err := storage.RepoArchives.Delete(archive.path); err != nil {
+				log.Trace("Unable to delete %s, but proceeding: %v", archive.path, err)
+				continue
 			}
+			totalSize -= archive.size
 		}
 	}
 
+	log.Trace("Finished: ArchiveCleanup")
 	return nil
 }
 
+// deleteRepositoryArchivesOf deletes the archives of a repository from the repository archive storage
+func deleteRepositoryArchivesOf(repoID int64) error {
+	prefix := strconv.FormatInt(repoID, 10) + "/"
+	return storage.RepoArchives.IterateObjects(func(path string, obj storage.Object) error {
+		if strings.HasPrefix(path, prefix) {
+			RemoveStorageWithNotice(storage.RepoArchives, "Delete repository archive", path)
+		}
+		return nil
+	})
+}
+
 type repoChecker struct {
 	querySQL, correctSQL string
 	desc                 string
@@ -1956,6 +2037,24 @@ func (repo *Repository) SetArchiveRepoState(isArchived bool) (err error) {
 	return
 }
 
+// IsUnderMaintenance returns true if the repository is in maintenance mode and its scheduled end has not passed
+func (repo *Repository) IsUnderMaintenance() bool {
+	return repo.IsMaintenance && (repo.MaintenanceEndUnix == 0 || timeutil.TimeStampNow() < repo.MaintenanceEndUnix)
+}
+
+// SetMaintenanceMode turns the maintenance mode of a repository on with an optional end time and message, or off
+func (repo *Repository) SetMaintenanceMode(isMaintenance bool, end timeutil.TimeStamp, message string) (err error) {
+	repo.IsMaintenance = isMaintenance
+	repo.MaintenanceEndUnix = end
+	repo.MaintenanceMessage = message
+	if !isMaintenance {
+		repo.MaintenanceEndUnix = 0
+		repo.MaintenanceMessage = ""
+	}
+	_, err = x.Where("id = ?", repo.ID).Cols("is_maintenance", "maintenance_end_unix", "maintenance_message").NoAutoTime().Update(repo)
+	return
+}
+
 // ___________           __
 // \_   _____/__________|  | __
 //  |    __)/  _ \_  __ \  |/ /
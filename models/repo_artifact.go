@@ -0,0 +1,239 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	gouuid "github.com/google/uuid"
+	"xorm.io/builder"
+)
+
+// RepoArtifact represents a build artifact uploaded by an external CI system for a commit of a repository
+type RepoArtifact struct {
+	ID            int64  `xorm:"pk autoincr"`
+	UUID          string `xorm:"uuid UNIQUE"`
+	RepoID        int64  `xorm:"INDEX"`
+	UploaderID    int64  `xorm:"INDEX"`
+	Name          string
+	CommitSHA     string `xorm:"INDEX VARCHAR(40)"`
+	Ref           string
+	Size          int64
+	DownloadCount int64 `xorm:"NOT NULL DEFAULT 0"`
+
+	// ExpiredUnix is the time the artifact is deleted at, 0 keeps it forever
+	ExpiredUnix timeutil.TimeStamp `xorm:"INDEX"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+
+	Repo     *Repository `xorm:"-"`
+	Uploader *User       `xorm:"-"`
+}
+
+// RelativePath returns the path of the artifact in the artifact storage
+func (a *RepoArtifact) RelativePath() string {
+	return path.Join(a.UUID[0:1], a.UUID[1:2], a.UUID)
+}
+
+// LoadAttributes loads the repository and the uploader of the artifact
+func (a *RepoArtifact) LoadAttributes() (err error) {
+	if a.Repo == nil {
+		if a.Repo, err = GetRepositoryByID(a.RepoID); err != nil {
+			return
+		}
+	}
+	if a.Uploader == nil {
+		if a.Uploader, err = GetUserByID(a.UploaderID); err != nil {
+			if !IsErrUserNotExist(err) {
+				return
+			}
+			a.Uploader = NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// HTMLURL returns the download link of the artifact
+func (a *RepoArtifact) HTMLURL() string {
+	return fmt.Sprintf("%s/artifacts/%d", a.Repo.HTMLURL(), a.ID)
+}
+
+// IncreaseDownloadCount increases the download count of the artifact by one
+func (a *RepoArtifact) IncreaseDownloadCount() error {
+	if _, err := x.Exec("UPDATE `repo_artifact` SET download_count=download_count+1 WHERE id=?", a.ID); err != nil {
+		return fmt.Errorf("increase artifact count: %v", err)
+	}
+	return nil
+}
+
+// ErrRepoArtifactNotExist represents a "RepoArtifactNotExist" kind of error.
+type ErrRepoArtifactNotExist struct {
+	ID int64
+}
+
+// IsErrRepoArtifactNotExist checks if an error is a ErrRepoArtifactNotExist.
+func IsErrRepoArtifactNotExist(err error) bool {
+	_, ok := err.(ErrRepoArtifactNotExist)
+	return ok
+}
+
+func (err ErrRepoArtifactNotExist) Error() string {
+	return fmt.Sprintf("artifact does not exist [id: %d]", err.ID)
+}
+
+// NewRepoArtifact stores the content of an artifact and inserts it into the database
+func NewRepoArtifact(artifact *RepoArtifact, content io.Reader) (*RepoArtifact, error) {
+	artifact.UUID = gouuid.New().String()
+
+	size, err := storage.Artifacts.Save(artifact.RelativePath(), content)
+	if err != nil {
+		return nil, fmt.Errorf("Save: %v", err)
+	}
+	artifact.Size = size
+
+	if _, err := x.Insert(artifact); err != nil {
+		if err := storage.Artifacts.Delete(artifact.RelativePath()); err != nil {
+			log.Error("Unable to delete artifact %s: %v", artifact.RelativePath(), err)
+		}
+		return nil, err
+	}
+	return artifact, nil
+}
+
+// GetRepoArtifactByID returns the artifact with the given id of a repository
+func GetRepoArtifactByID(repoID, id int64) (*RepoArtifact, error) {
+	artifact := new(RepoArtifact)
+	has, err := x.Where("id = ? AND repo_id = ?", id, repoID).Get(artifact)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRepoArtifactNotExist{ID: id}
+	}
+	return artifact, nil
+}
+
+// FindRepoArtifactsOptions represents the options to find the artifacts of a repository
+type FindRepoArtifactsOptions struct {
+	ListOptions
+	RepoID     int64
+	CommitSHAs []string
+	Ref        string
+}
+
+func (opts *FindRepoArtifactsOptions) toConds() builder.Cond {
+	cond := builder.NewCond().And(builder.Eq{"repo_id": opts.RepoID})
+	if len(opts.CommitSHAs) > 0 {
+		cond = cond.And(builder.In("commit_sha", opts.CommitSHAs))
+	}
+	if len(opts.Ref) > 0 {
+		cond = cond.And(builder.Eq{"ref": opts.Ref})
+	}
+	return cond
+}
+
+// FindRepoArtifacts returns the artifacts of a repository matching the options, newest first
+func FindRepoArtifacts(opts *FindRepoArtifactsOptions) ([]*RepoArtifact, int64, error) {
+	sess := x.Where(opts.toConds()).Desc("id")
+	if opts.Page > 0 {
+		sess = opts.setSessionPagination(sess)
+	}
+	artifacts := make([]*RepoArtifact, 0, 10)
+	count, err := sess.FindAndCount(&artifacts)
+	return artifacts, count, err
+}
+
+// GetReleaseArtifacts loads the artifacts of the commits of the given releases
+func GetReleaseArtifacts(rels ...*Release) error {
+	if len(rels) == 0 {
+		return nil
+	}
+
+	shas := make([]string, 0, len(rels))
+	for _, rel := range rels {
+		if len(rel.Sha1) > 0 {
+			shas = append(shas, rel.Sha1)
+		}
+		rel.Artifacts = nil
+	}
+	if len(shas) == 0 {
+		return nil
+	}
+
+	artifacts, _, err := FindRepoArtifacts(&FindRepoArtifactsOptions{
+		RepoID:     rels[0].RepoID,
+		CommitSHAs: shas,
+	})
+	if err != nil {
+		return err
+	}
+	for _, rel := range rels {
+		for _, artifact := range artifacts {
+			if artifact.CommitSHA == rel.Sha1 {
+				rel.Artifacts = append(rel.Artifacts, artifact)
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteRepoArtifact deletes an artifact and its content
+func DeleteRepoArtifact(artifact *RepoArtifact) error {
+	if _, err := x.ID(artifact.ID).Delete(new(RepoArtifact)); err != nil {
+		return err
+	}
+	return storage.Artifacts.Delete(artifact.RelativePath())
+}
+
+// DeleteExpiredRepoArtifacts deletes the artifacts whose retention time has passed
+func DeleteExpiredRepoArtifacts(ctx context.Context) error {
+	log.Trace("Doing: DeleteExpiredRepoArtifacts")
+
+	for {
+		artifacts := make([]*RepoArtifact, 0, 100)
+		if err := x.Where("expired_unix > 0 AND expired_unix < ?", timeutil.TimeStampNow()).
+			Limit(100).Find(&artifacts); err != nil {
+			return fmt.Errorf("Find: %v", err)
+		}
+		if len(artifacts) == 0 {
+			break
+		}
+		for _, artifact := range artifacts {
+			select {
+			case <-ctx.Done():
+				return ErrCancelledf("before deleting artifact %d", artifact.ID)
+			default:
+			}
+			if err := DeleteRepoArtifact(artifact); err != nil {
+				return fmt.Errorf("DeleteRepoArtifact [%d]: %v", artifact.ID, err)
+			}
+		}
+	}
+
+	log.Trace("Finished: DeleteExpiredRepoArtifacts")
+	return nil
+}
+
+// ArtifactExpiry returns the time an artifact uploaded now is deleted at for the requested retention days,
+// using the default retention if days is 0 and limiting it to the maximum retention.
+func ArtifactExpiry(days int) timeutil.TimeStamp {
+	if days <= 0 {
+		days = setting.Artifacts.DefaultRetentionDays
+	}
+	if setting.Artifacts.MaxRetentionDays > 0 && days > setting.Artifacts.MaxRetentionDays {
+		days = setting.Artifacts.MaxRetentionDays
+	}
+	if days <= 0 {
+		return 0
+	}
+	return timeutil.TimeStampNow().AddDuration(time.Duration(days) * 24 * time.Hour)
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindRepoArtifacts(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	artifacts, count, err := FindRepoArtifacts(&FindRepoArtifactsOptions{
+		RepoID:     1,
+		CommitSHAs: []string{"65f1bf27bc3bf70f64657658635e66094edbcb4d"},
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	if assert.Len(t, artifacts, 2) {
+		assert.EqualValues(t, 2, artifacts[0].ID)
+		assert.EqualValues(t, 1, artifacts[1].ID)
+	}
+
+	_, count, err = FindRepoArtifacts(&FindRepoArtifactsOptions{RepoID: 1, Ref: "refs/heads/develop"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+
+	_, err = GetRepoArtifactByID(2, 1)
+	assert.True(t, IsErrRepoArtifactNotExist(err))
+}
+
+func TestNewAndDeleteExpiredRepoArtifacts(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	artifact, err := NewRepoArtifact(&RepoArtifact{
+		RepoID:      1,
+		UploaderID:  2,
+		Name:        "docs.zip",
+		CommitSHA:   "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		ExpiredUnix: ArtifactExpiry(1),
+	}, strings.NewReader("content"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 7, artifact.Size)
+	assert.NotZero(t, artifact.ExpiredUnix)
+
+	assert.NoError(t, DeleteExpiredRepoArtifacts(context.Background()))
+	AssertNotExistsBean(t, &RepoArtifact{ID: 2})
+	AssertExistsAndLoadBean(t, &RepoArtifact{ID: 1})
+	AssertExistsAndLoadBean(t, &RepoArtifact{ID: artifact.ID})
+}
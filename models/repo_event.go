@@ -0,0 +1,148 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// RepoEventType is the type of an administrative event of a repository
+type RepoEventType int
+
+// Enumerate all the repository event types
+const (
+	RepoEventProtectedBranchUpdate    RepoEventType = iota + 1 // 1
+	RepoEventProtectedBranchDelete                             // 2
+	RepoEventWebhookCreate                                     // 3
+	RepoEventWebhookUpdate                                     // 4
+	RepoEventWebhookDelete                                     // 5
+	RepoEventCollaboratorAdd                                   // 6
+	RepoEventCollaboratorAccessChange                          // 7
+	RepoEventCollaboratorRemove                                // 8
+	RepoEventTransferStart                                     // 9
+	RepoEventTransferCancel                                    // 10
+	RepoEventTransfer                                          // 11
+)
+
+var repoEventTypeNames = map[RepoEventType]string{
+	RepoEventProtectedBranchUpdate:    "protected_branch_update",
+	RepoEventProtectedBranchDelete:    "protected_branch_delete",
+	RepoEventWebhookCreate:            "webhook_create",
+	RepoEventWebhookUpdate:            "webhook_update",
+	RepoEventWebhookDelete:            "webhook_delete",
+	RepoEventCollaboratorAdd:          "collaborator_add",
+	RepoEventCollaboratorAccessChange: "collaborator_access_change",
+	RepoEventCollaboratorRemove:       "collaborator_remove",
+	RepoEventTransferStart:            "transfer_start",
+	RepoEventTransferCancel:           "transfer_cancel",
+	RepoEventTransfer:                 "transfer",
+}
+
+// Name returns the name of the event type, used by the API and the locale keys
+func (t RepoEventType) Name() string {
+	return repoEventTypeNames[t]
+}
+
+// RepoEventTypeFromName returns the event type of a name, 0 if the name is unknown
+func RepoEventTypeFromName(name string) RepoEventType {
+	for t, n := range repoEventTypeNames {
+		if n == name {
+			return t
+		}
+	}
+	return 0
+}
+
+// RepoEvent is an administrative event of a repository, e.g. a change of a branch protection or of the collaborators.
+// Target is what the event applies to (a branch, a user or the host of a webhook) and Content the details of the
+// event; the webhook events never keep the URL nor the secret of the webhooks.
+type RepoEvent struct {
+	ID      int64         `xorm:"pk autoincr"`
+	RepoID  int64         `xorm:"INDEX NOT NULL"`
+	DoerID  int64         `xorm:"INDEX NOT NULL"`
+	Doer    *User         `xorm:"-"`
+	Type    RepoEventType `xorm:"INDEX NOT NULL"`
+	Target  string
+	Content string `xorm:"TEXT"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// LoadDoer loads the user who caused the event, a ghost user if it has been deleted
+func (e *RepoEvent) LoadDoer() error {
+	if e.Doer != nil {
+		return nil
+	}
+	doer, err := GetUserByID(e.DoerID)
+	if err != nil {
+		if !IsErrUserNotExist(err) {
+			return err
+		}
+		doer = NewGhostUser()
+	}
+	e.Doer = doer
+	return nil
+}
+
+// AddRepoEvent adds an event to the timeline of a repository
+func AddRepoEvent(e *RepoEvent) error {
+	_, err := x.Insert(e)
+	return err
+}
+
+// RepoEventList is a list of repository events
+type RepoEventList []*RepoEvent
+
+// LoadDoers loads the users who caused the events
+func (events RepoEventList) LoadDoers() error {
+	doerIDs := make([]int64, 0, len(events))
+	for _, e := range events {
+		doerIDs = append(doerIDs, e.DoerID)
+	}
+	doers := make(map[int64]*User, len(doerIDs))
+	if err := x.In("id", doerIDs).Find(&doers); err != nil {
+		return err
+	}
+	for _, e := range events {
+		if e.Doer = doers[e.DoerID]; e.Doer == nil {
+			e.Doer = NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// FindRepoEventsOptions represents the options to find the events of a repository
+type FindRepoEventsOptions struct {
+	ListOptions
+	RepoID int64
+	Type   RepoEventType
+}
+
+func (opts *FindRepoEventsOptions) toConds() builder.Cond {
+	cond := builder.NewCond().And(builder.Eq{"repo_id": opts.RepoID})
+	if opts.Type > 0 {
+		cond = cond.And(builder.Eq{"`type`": opts.Type})
+	}
+	return cond
+}
+
+// FindRepoEvents returns the events of a repository, the latest first, with their doers and the total count
+func FindRepoEvents(opts *FindRepoEventsOptions) (RepoEventList, int64, error) {
+	sess := x.Where(opts.toConds())
+	if opts.Page > 0 {
+		sess = opts.setSessionPagination(sess)
+	}
+	events := make(RepoEventList, 0, opts.PageSize)
+	count, err := sess.Desc("created_unix", "id").FindAndCount(&events)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := events.LoadDoers(); err != nil {
+		return nil, 0, err
+	}
+	return events, count, nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoEvents(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, AddRepoEvent(&RepoEvent{RepoID: 1, DoerID: 2, Type: RepoEventProtectedBranchUpdate, Target: "master"}))
+	assert.NoError(t, AddRepoEvent(&RepoEvent{RepoID: 1, DoerID: 2, Type: RepoEventCollaboratorAdd, Target: "user4", Content: "write"}))
+	assert.NoError(t, AddRepoEvent(&RepoEvent{RepoID: 1, DoerID: 1000, Type: RepoEventWebhookDelete, Target: "example.com", Content: "gitea"}))
+	assert.NoError(t, AddRepoEvent(&RepoEvent{RepoID: 2, DoerID: 2, Type: RepoEventTransferStart, Target: "user3"}))
+
+	events, count, err := FindRepoEvents(&FindRepoEventsOptions{RepoID: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+	if assert.Len(t, events, 3) {
+		// the latest event first
+		assert.Equal(t, RepoEventWebhookDelete, events[0].Type)
+		assert.True(t, events[0].Doer.IsGhost())
+		assert.Equal(t, "user2", events[2].Doer.Name)
+	}
+
+	events, count, err = FindRepoEvents(&FindRepoEventsOptions{RepoID: 1, Type: RepoEventCollaboratorAdd})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "user4", events[0].Target)
+	}
+
+	events, count, err = FindRepoEvents(&FindRepoEventsOptions{ListOptions: ListOptions{Page: 2, PageSize: 2}, RepoID: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+	assert.Len(t, events, 1)
+
+	assert.Equal(t, RepoEventCollaboratorAccessChange, RepoEventTypeFromName(RepoEventCollaboratorAccessChange.Name()))
+	assert.EqualValues(t, 0, RepoEventTypeFromName("unknown"))
+}
@@ -0,0 +1,155 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// RepoHealth records the results of the last garbage collection,
+// the last health check (git fsck) and the last maintenance of a repository
+type RepoHealth struct {
+	ID     int64 `xorm:"pk autoincr"`
+	RepoID int64 `xorm:"UNIQUE NOT NULL"`
+
+	LastGCUnix     timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	LastGCDuration int64              `xorm:"NOT NULL DEFAULT 0"` // in milliseconds
+	LastGCError    string             `xorm:"TEXT"`
+
+	LastFsckUnix     timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	LastFsckDuration int64              `xorm:"NOT NULL DEFAULT 0"` // in milliseconds
+	LastFsckError    string             `xorm:"TEXT"`
+
+	LastMaintenanceUnix     timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	LastMaintenanceDuration int64              `xorm:"NOT NULL DEFAULT 0"` // in milliseconds
+	LastMaintenanceError    string             `xorm:"TEXT"`
+	LastMaintenanceTasks    string             // the tasks run by the last maintenance, separated by commas
+	// GCObjectCount is the number of packed objects after the last garbage collection by the maintenance,
+	// which the growth of the repository is measured against
+	GCObjectCount int64 `xorm:"NOT NULL DEFAULT 0"`
+}
+
+// HasGC returns true if the repository has been garbage collected since the results are recorded
+func (h *RepoHealth) HasGC() bool {
+	return h.LastGCUnix > 0
+}
+
+// HasFsck returns true if the repository has been checked since the results are recorded
+func (h *RepoHealth) HasFsck() bool {
+	return h.LastFsckUnix > 0
+}
+
+// HasMaintenance returns true if the repository has been maintained since the results are recorded
+func (h *RepoHealth) HasMaintenance() bool {
+	return h.LastMaintenanceUnix > 0
+}
+
+// GCDuration returns how long the last garbage collection took
+func (h *RepoHealth) GCDuration() time.Duration {
+	return time.Duration(h.LastGCDuration) * time.Millisecond
+}
+
+// FsckDuration returns how long the last health check took
+func (h *RepoHealth) FsckDuration() time.Duration {
+	return time.Duration(h.LastFsckDuration) * time.Millisecond
+}
+
+// MaintenanceDuration returns how long the last maintenance took
+func (h *RepoHealth) MaintenanceDuration() time.Duration {
+	return time.Duration(h.LastMaintenanceDuration) * time.Millisecond
+}
+
+func updateRepoHealth(e Engine, h *RepoHealth, cols ...string) error {
+	has, err := e.Where("repo_id = ?", h.RepoID).Get(new(RepoHealth))
+	if err != nil {
+		return err
+	}
+	if !has {
+		_, err = e.Insert(h)
+		return err
+	}
+	_, err = e.Where("repo_id = ?", h.RepoID).Cols(cols...).Update(h)
+	return err
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// UpdateRepoGCResult records the result of a garbage collection started at start
+func UpdateRepoGCResult(repoID int64, start time.Time, gcErr error) error {
+	return updateRepoHealth(x, &RepoHealth{
+		RepoID:         repoID,
+		LastGCUnix:     timeutil.TimeStamp(start.Unix()),
+		LastGCDuration: time.Since(start).Milliseconds(),
+		LastGCError:    errorString(gcErr),
+	}, "last_gc_unix", "last_gc_duration", "last_gc_error")
+}
+
+// UpdateRepoFsckResult records the result of a health check started at start
+func UpdateRepoFsckResult(repoID int64, start time.Time, fsckErr error) error {
+	return updateRepoHealth(x, &RepoHealth{
+		RepoID:           repoID,
+		LastFsckUnix:     timeutil.TimeStamp(start.Unix()),
+		LastFsckDuration: time.Since(start).Milliseconds(),
+		LastFsckError:    errorString(fsckErr),
+	}, "last_fsck_unix", "last_fsck_duration", "last_fsck_error")
+}
+
+// UpdateRepoMaintenanceResult records the result of a maintenance started at start which has run the tasks,
+// gcObjectCount is the number of packed objects after the last garbage collection
+func UpdateRepoMaintenanceResult(repoID int64, start time.Time, tasks []string, gcObjectCount int64, maintenanceErr error) error {
+	return updateRepoHealth(x, &RepoHealth{
+		RepoID:                  repoID,
+		LastMaintenanceUnix:     timeutil.TimeStamp(start.Unix()),
+		LastMaintenanceDuration: time.Since(start).Milliseconds(),
+		LastMaintenanceError:    errorString(maintenanceErr),
+		LastMaintenanceTasks:    strings.Join(tasks, ","),
+		GCObjectCount:           gcObjectCount,
+	}, "last_maintenance_unix", "last_maintenance_duration", "last_maintenance_error", "last_maintenance_tasks", "gc_object_count")
+}
+
+// GetRepoHealth returns the recorded health of a repository, which is empty if nothing has been recorded yet
+func GetRepoHealth(repoID int64) (*RepoHealth, error) {
+	h := &RepoHealth{RepoID: repoID}
+	if _, err := x.Where("repo_id = ?", repoID).Get(h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// GetRepoHealthMap returns the recorded health of the repositories by their ID
+func GetRepoHealthMap(repoIDs []int64) (map[int64]*RepoHealth, error) {
+	healths := make([]*RepoHealth, 0, len(repoIDs))
+	if len(repoIDs) > 0 {
+		if err := x.In("repo_id", repoIDs).Find(&healths); err != nil {
+			return nil, err
+		}
+	}
+	m := make(map[int64]*RepoHealth, len(healths))
+	for _, h := range healths {
+		m[h.RepoID] = h
+	}
+	return m, nil
+}
+
+// GetRepositoriesForFsck returns up to limit repositories with enabled health checks,
+// the ones which have not been checked for the longest time first
+func GetRepositoriesForFsck(limit int) ([]*Repository, error) {
+	repos := make([]*Repository, 0, limit)
+	return repos, x.
+		Select("`repository`.*").
+		Join("LEFT", "repo_health", "repo_health.repo_id = repository.id").
+		Where("repository.is_fsck_enabled = ?", true).
+		OrderBy("COALESCE(repo_health.last_fsck_unix, 0) ASC, repository.id ASC").
+		Limit(limit).
+		Find(&repos)
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateRepoHealth(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	h, err := GetRepoHealth(1)
+	assert.NoError(t, err)
+	assert.False(t, h.HasGC())
+	assert.False(t, h.HasFsck())
+
+	assert.NoError(t, UpdateRepoGCResult(1, time.Now(), nil))
+	assert.NoError(t, UpdateRepoFsckResult(1, time.Now(), errors.New("broken")))
+	assert.NoError(t, UpdateRepoGCResult(2, time.Now(), nil))
+
+	m, err := GetRepoHealthMap([]int64{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Len(t, m, 2)
+	assert.True(t, m[1].HasGC())
+	assert.Empty(t, m[1].LastGCError)
+	assert.True(t, m[1].HasFsck())
+	assert.Equal(t, "broken", m[1].LastFsckError)
+	assert.False(t, m[2].HasFsck())
+
+	assert.NoError(t, UpdateRepoFsckResult(1, time.Now(), nil))
+	h, err = GetRepoHealth(1)
+	assert.NoError(t, err)
+	assert.Empty(t, h.LastFsckError)
+	assert.True(t, h.HasGC())
+	assert.False(t, h.HasMaintenance())
+
+	assert.NoError(t, UpdateRepoMaintenanceResult(1, time.Now(), []string{"gc", "commit-graph"}, 42, nil))
+	h, err = GetRepoHealth(1)
+	assert.NoError(t, err)
+	assert.True(t, h.HasMaintenance())
+	assert.Equal(t, "gc,commit-graph", h.LastMaintenanceTasks)
+	assert.EqualValues(t, 42, h.GCObjectCount)
+	assert.Empty(t, h.LastMaintenanceError)
+	assert.True(t, h.HasFsck())
+}
+
+func TestGetRepositoriesForFsck(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repos, err := GetRepositoriesForFsck(2)
+	assert.NoError(t, err)
+	if assert.Len(t, repos, 2) {
+		assert.EqualValues(t, 1, repos[0].ID)
+		assert.EqualValues(t, 2, repos[1].ID)
+	}
+
+	assert.NoError(t, UpdateRepoFsckResult(1, time.Now(), nil))
+	repos, err = GetRepositoriesForFsck(2)
+	assert.NoError(t, err)
+	if assert.Len(t, repos, 2) {
+		assert.EqualValues(t, 2, repos[0].ID)
+		assert.EqualValues(t, 3, repos[1].ID)
+	}
+}
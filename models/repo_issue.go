@@ -42,3 +42,21 @@ func (repo *Repository) AllowOnlyContributorsToTrackTime() bool {
 	}
 	return u.IssuesConfig().AllowOnlyContributorsToTrackTime
 }
+
+// IsDueDateReminderEnabled returns whether the assignees of the issues and milestones due soon are reminded
+func (repo *Repository) IsDueDateReminderEnabled() bool {
+	u, err := repo.GetUnit(UnitTypeIssues)
+	if err != nil {
+		return false
+	}
+	return u.IssuesConfig().EnableDueDateReminders
+}
+
+// DueDateReminderDays returns the number of days before a due date its assignees are reminded
+func (repo *Repository) DueDateReminderDays() int {
+	u, err := repo.GetUnit(UnitTypeIssues)
+	if err != nil {
+		return DefaultDueDateReminderDays
+	}
+	return u.IssuesConfig().DueDateReminderLeadDays()
+}
@@ -462,6 +462,95 @@ func AccessibleRepoIDsQuery(user *User) *builder.Builder {
 	return builder.Select("id").From("repository").Where(accessibleRepositoryCondition(user))
 }
 
+// CodeSearchScope restricts a code search to the repositories of an owner and/or to a set of repositories
+type CodeSearchScope struct {
+	OwnerID int64
+	RepoIDs []int64
+}
+
+// IsEmpty returns whether the scope does not restrict the search
+func (scope CodeSearchScope) IsEmpty() bool {
+	return scope.OwnerID == 0 && len(scope.RepoIDs) == 0
+}
+
+// FindCodeSearchRepoIDs returns the IDs of the repositories within a scope whose code a user can read.
+// It is nil if the search is not restricted at all, which is only the case of the site administrators
+// searching without a scope.
+func FindCodeSearchRepoIDs(user *User, scope CodeSearchScope) ([]int64, error) {
+	isAdmin := user != nil && user.IsAdmin
+	if isAdmin && scope.IsEmpty() {
+		return nil, nil
+	}
+
+	cond := builder.NewCond()
+	if !isAdmin {
+		cond = cond.And(accessibleRepositoryCondition(user))
+	}
+	if scope.OwnerID > 0 {
+		cond = cond.And(builder.Eq{"`repository`.owner_id": scope.OwnerID})
+	}
+	if len(scope.RepoIDs) > 0 {
+		cond = cond.And(builder.In("`repository`.id", scope.RepoIDs))
+	}
+	repos := make([]*Repository, 0, 10)
+	if err := x.Where(cond).Find(&repos); err != nil {
+		return nil, fmt.Errorf("FindCodeSearchRepoIDs: %v", err)
+	}
+
+	repoIDs := make([]int64, 0, len(repos))
+	for _, repo := range repos {
+		// the anonymous users can read the code of all the public repositories
+		if user == nil || isAdmin || repo.CheckUnitUser(user, UnitTypeCode) {
+			repoIDs = append(repoIDs, repo.ID)
+		}
+	}
+	return repoIDs, nil
+}
+
+// GetCodeSearchScope returns the scope of a code search restricted to the repositories of the owner named ownerName,
+// if not empty, and to the repositories named by repoFullNames, if any. found is false if the owner or all of
+// the repositories do not exist, in which case the search cannot match anything.
+func GetCodeSearchScope(ownerName string, repoFullNames []string) (scope CodeSearchScope, found bool, err error) {
+	if len(ownerName) > 0 {
+		owner, err := GetUserByName(ownerName)
+		if err != nil {
+			if IsErrUserNotExist(err) {
+				return scope, false, nil
+			}
+			return scope, false, err
+		}
+		scope.OwnerID = owner.ID
+	}
+	if len(repoFullNames) > 0 {
+		if scope.RepoIDs, err = GetRepositoryIDsByFullNames(repoFullNames); err != nil {
+			return scope, false, err
+		}
+		if len(scope.RepoIDs) == 0 {
+			return scope, false, nil
+		}
+	}
+	return scope, true, nil
+}
+
+// GetRepositoryIDsByFullNames returns the IDs of the repositories named owner/name, the names which are invalid
+// or of repositories which do not exist are ignored
+func GetRepositoryIDsByFullNames(fullNames []string) ([]int64, error) {
+	cond := builder.NewCond()
+	for _, fullName := range fullNames {
+		parts := strings.SplitN(fullName, "/", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			continue
+		}
+		cond = cond.Or(builder.Eq{"lower_name": strings.ToLower(parts[1])}.And(
+			builder.In("owner_id", builder.Select("id").From("`user`").Where(builder.Eq{"lower_name": strings.ToLower(parts[0])}))))
+	}
+	repoIDs := make([]int64, 0, len(fullNames))
+	if !cond.IsValid() {
+		return repoIDs, nil
+	}
+	return repoIDs, x.Table("repository").Cols("id").Where(cond).Find(&repoIDs)
+}
+
 // FindUserAccessibleRepoIDs find all accessible repositories' ID by user's id
 func FindUserAccessibleRepoIDs(user *User) ([]int64, error) {
 	repoIDs := make([]int64, 0, 10)
@@ -355,3 +355,69 @@ func TestSearchRepositoryByTopicName(t *testing.T) {
 		})
 	}
 }
+
+func TestFindCodeSearchRepoIDs(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	admin := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	repoIDs, err := FindCodeSearchRepoIDs(admin, CodeSearchScope{})
+	assert.NoError(t, err)
+	assert.Nil(t, repoIDs)
+
+	repoIDs, err = FindCodeSearchRepoIDs(admin, CodeSearchScope{OwnerID: 3})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{3, 5, 32}, repoIDs)
+
+	repoIDs, err = FindCodeSearchRepoIDs(nil, CodeSearchScope{OwnerID: 3})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{32}, repoIDs)
+
+	repoIDs, err = FindCodeSearchRepoIDs(nil, CodeSearchScope{RepoIDs: []int64{1, 2}})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{1}, repoIDs)
+
+	repoIDs, err = FindCodeSearchRepoIDs(user, CodeSearchScope{RepoIDs: []int64{1, 2}})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{1, 2}, repoIDs)
+
+	repoIDs, err = FindCodeSearchRepoIDs(user, CodeSearchScope{OwnerID: 3, RepoIDs: []int64{1, 32}})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{32}, repoIDs)
+}
+
+func TestGetRepositoryIDsByFullNames(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repoIDs, err := GetRepositoryIDsByFullNames([]string{"user2/repo1", "User3/Repo21", "user2/repo21", "invalid", "/repo1"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{1, 32}, repoIDs)
+
+	repoIDs, err = GetRepositoryIDsByFullNames(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, repoIDs)
+}
+
+func TestGetCodeSearchScope(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	scope, found, err := GetCodeSearchScope("", nil)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.True(t, scope.IsEmpty())
+
+	scope, found, err = GetCodeSearchScope("user3", []string{"user2/repo1"})
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.EqualValues(t, 3, scope.OwnerID)
+	assert.Equal(t, []int64{1}, scope.RepoIDs)
+
+	_, found, err = GetCodeSearchScope("user_not_exist", nil)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	_, found, err = GetCodeSearchScope("", []string{"user2/repo_not_exist"})
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
@@ -6,11 +6,14 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 
+	"xorm.io/builder"
 	"xorm.io/xorm"
 )
 
@@ -22,9 +25,20 @@ type Mirror struct {
 	Interval    time.Duration
 	EnablePrune bool `xorm:"NOT NULL DEFAULT true"`
 
+	// The mirror is only synced between these minutes of the day, unless they are equal
+	SyncWindowStart int `xorm:"NOT NULL DEFAULT 0"`
+	SyncWindowEnd   int `xorm:"NOT NULL DEFAULT 0"`
+
+	// Only the refs matching these refspec patterns, like refs/tags/v*, are synced, all of them if both are empty
+	IncludeRefs []string `xorm:"JSON TEXT"`
+	ExcludeRefs []string `xorm:"JSON TEXT"`
+
 	UpdatedUnix    timeutil.TimeStamp `xorm:"INDEX"`
 	NextUpdateUnix timeutil.TimeStamp `xorm:"INDEX"`
 
+	LastError           string `xorm:"TEXT"`
+	ConsecutiveFailures int    `xorm:"INDEX NOT NULL DEFAULT 0"`
+
 	Address string `xorm:"-"`
 }
 
@@ -51,11 +65,80 @@ func (m *Mirror) AfterLoad(session *xorm.Session) {
 
 // ScheduleNextUpdate calculates and sets next update time.
 func (m *Mirror) ScheduleNextUpdate() {
-	if m.Interval != 0 {
-		m.NextUpdateUnix = timeutil.TimeStampNow().AddDuration(m.Interval)
-	} else {
-		m.NextUpdateUnix = 0
+	m.NextUpdateUnix = nextMirrorUpdate(time.Now(), m.Interval, m.SyncWindowStart, m.SyncWindowEnd)
+}
+
+// RecordSuccess resets the failures of the mirror after it was synced
+func (m *Mirror) RecordSuccess() {
+	m.LastError = ""
+	m.ConsecutiveFailures = 0
+}
+
+// RecordFailure records a failed sync of the mirror and schedules the next attempt
+func (m *Mirror) RecordFailure(errMessage string) {
+	m.LastError = errMessage
+	m.ConsecutiveFailures++
+	m.NextUpdateUnix = nextMirrorUpdate(time.Now(), mirrorRetryDelay(m.Interval, m.ConsecutiveFailures), m.SyncWindowStart, m.SyncWindowEnd)
+}
+
+// IsFailing returns whether the mirror failed to sync often enough for the administrators of its repository to be
+// notified
+func (m *Mirror) IsFailing() bool {
+	return isMirrorFailing(m.ConsecutiveFailures)
+}
+
+func isMirrorFailing(consecutiveFailures int) bool {
+	return setting.Mirror.FailureThreshold > 0 && consecutiveFailures >= setting.Mirror.FailureThreshold
+}
+
+// mirrorRetryDelay returns the delay before syncing again a mirror synced every interval which failed to sync
+// failures times in a row. It doubles with every failure, from the minimum mirror interval up to interval.
+func mirrorRetryDelay(interval time.Duration, failures int) time.Duration {
+	delay := setting.Mirror.MinInterval
+	for i := 1; i < failures && delay < interval; i++ {
+		delay *= 2
+	}
+	if delay > interval {
+		return interval
+	}
+	return delay
+}
+
+// nextMirrorUpdate returns the time of the next sync of a mirror synced every interval, delayed to the start of
+// its sync window if it falls outside of it. It is 0 if the mirror is not synced periodically.
+func nextMirrorUpdate(now time.Time, interval time.Duration, windowStart, windowEnd int) timeutil.TimeStamp {
+	if interval == 0 {
+		return 0
+	}
+	next := now.Add(interval)
+	if windowStart == windowEnd {
+		return timeutil.TimeStamp(next.Unix())
+	}
+
+	minute := next.Hour()*60 + next.Minute()
+	if windowStart < windowEnd && minute >= windowStart && minute < windowEnd ||
+		windowStart > windowEnd && (minute >= windowStart || minute < windowEnd) {
+		return timeutil.TimeStamp(next.Unix())
+	}
+	start := time.Date(next.Year(), next.Month(), next.Day(), windowStart/60, windowStart%60, 0, 0, next.Location())
+	if minute >= windowStart {
+		start = start.AddDate(0, 0, 1)
+	}
+	return timeutil.TimeStamp(start.Unix())
+}
+
+// ParseSyncWindowTime parses a time of the day formatted as HH:MM into the number of minutes since midnight
+func ParseSyncWindowTime(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of the day %q, it must be formatted as HH:MM", s)
 	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// FormatSyncWindowTime formats a number of minutes since midnight as HH:MM
+func FormatSyncWindowTime(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
 }
 
 func getMirrorByRepoID(e Engine, repoID int64) (*Mirror, error) {
@@ -98,6 +181,36 @@ func MirrorsIterate(f func(idx int, bean interface{}) error) error {
 		Iterate(new(Mirror), f)
 }
 
+// SearchMirrorsOptions are the options to list the pull or push mirrors by their health
+type SearchMirrorsOptions struct {
+	ListOptions
+	OnlyFailing bool
+}
+
+func (opts *SearchMirrorsOptions) toCond() builder.Cond {
+	cond := builder.NewCond()
+	if opts.OnlyFailing {
+		cond = cond.And(builder.Gt{"consecutive_failures": 0})
+	}
+	return cond
+}
+
+// SearchMirrors returns the mirrors, the ones which failed the most times in a row first, and their total number
+func SearchMirrors(opts *SearchMirrorsOptions) ([]*Mirror, int64, error) {
+	// the repositories of the mirrors are loaded with the session of the query, so the mirrors can't be counted with it
+	count, err := x.Where(opts.toCond()).Count(new(Mirror))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sess := x.Where(opts.toCond()).Desc("consecutive_failures").Asc("next_update_unix", "id")
+	if opts.Page > 0 {
+		sess = opts.setSessionPagination(sess)
+	}
+	mirrors := make([]*Mirror, 0, opts.PageSize)
+	return mirrors, count, sess.Find(&mirrors)
+}
+
 // InsertMirror inserts a mirror to database
 func InsertMirror(mirror *Mirror) error {
 	_, err := x.Insert(mirror)
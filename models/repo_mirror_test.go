@@ -0,0 +1,143 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextMirrorUpdate(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(day, hour, minute int) timeutil.TimeStamp {
+		return timeutil.TimeStamp(time.Date(2021, 6, day, hour, minute, 0, 0, time.UTC).Unix())
+	}
+
+	assert.EqualValues(t, 0, nextMirrorUpdate(now, 0, 0, 0))
+	assert.Equal(t, at(1, 20, 0), nextMirrorUpdate(now, 8*time.Hour, 0, 0))
+
+	// within the window
+	assert.Equal(t, at(1, 14, 0), nextMirrorUpdate(now, 2*time.Hour, 13*60, 18*60))
+	// before the window
+	assert.Equal(t, at(1, 13, 0), nextMirrorUpdate(now, 10*time.Minute, 13*60, 18*60))
+	// after the window
+	assert.Equal(t, at(2, 13, 0), nextMirrorUpdate(now, 8*time.Hour, 13*60, 18*60))
+
+	// windows across midnight
+	assert.Equal(t, at(1, 22, 0), nextMirrorUpdate(now, time.Hour, 22*60, 6*60))
+	assert.Equal(t, at(1, 23, 0), nextMirrorUpdate(now, 11*time.Hour, 22*60, 6*60))
+	assert.Equal(t, at(2, 2, 0), nextMirrorUpdate(now, 14*time.Hour, 22*60, 6*60))
+	assert.Equal(t, at(2, 22, 0), nextMirrorUpdate(now, 19*time.Hour, 22*60, 6*60))
+}
+
+func TestSyncWindowTime(t *testing.T) {
+	minutes, err := ParseSyncWindowTime("22:30")
+	assert.NoError(t, err)
+	assert.Equal(t, 22*60+30, minutes)
+	assert.Equal(t, "22:30", FormatSyncWindowTime(minutes))
+	assert.Equal(t, "00:05", FormatSyncWindowTime(5))
+
+	_, err = ParseSyncWindowTime("24:00")
+	assert.Error(t, err)
+	_, err = ParseSyncWindowTime("10pm")
+	assert.Error(t, err)
+}
+
+func TestPushMirror(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	m := &PushMirror{RepoID: 1, Interval: time.Hour, SyncWindowStart: 22 * 60, SyncWindowEnd: 6 * 60}
+	assert.NoError(t, InsertPushMirror(m))
+	assert.Equal(t, "push_mirror_1", m.RemoteName())
+
+	m, err := GetPushMirror(1, m.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, m.Repo.ID)
+	m.ScheduleNextUpdate()
+	assert.NotZero(t, m.NextUpdateUnix)
+	assert.NoError(t, UpdatePushMirror(m))
+
+	_, err = GetPushMirror(2, m.ID)
+	assert.True(t, IsErrPushMirrorNotExist(err))
+
+	mirrors, err := GetPushMirrorsByRepoID(1)
+	assert.NoError(t, err)
+	assert.Len(t, mirrors, 1)
+	assert.Equal(t, 22*60, mirrors[0].SyncWindowStart)
+
+	assert.NoError(t, DeletePushMirror(1, m.ID))
+	AssertNotExistsBean(t, &PushMirror{ID: m.ID})
+}
+
+func TestMirrorFailures(t *testing.T) {
+	defer func(minInterval time.Duration, threshold int) {
+		setting.Mirror.MinInterval = minInterval
+		setting.Mirror.FailureThreshold = threshold
+	}(setting.Mirror.MinInterval, setting.Mirror.FailureThreshold)
+	setting.Mirror.MinInterval = 10 * time.Minute
+	setting.Mirror.FailureThreshold = 3
+
+	assert.Equal(t, 10*time.Minute, mirrorRetryDelay(8*time.Hour, 1))
+	assert.Equal(t, 40*time.Minute, mirrorRetryDelay(8*time.Hour, 3))
+	assert.Equal(t, 8*time.Hour, mirrorRetryDelay(8*time.Hour, 10))
+	assert.Equal(t, 5*time.Minute, mirrorRetryDelay(5*time.Minute, 1))
+	assert.Zero(t, mirrorRetryDelay(0, 1))
+
+	m := &Mirror{Interval: 8 * time.Hour}
+	for i := 0; i < 2; i++ {
+		m.RecordFailure("exit status 128")
+		assert.False(t, m.IsFailing())
+	}
+	m.RecordFailure("exit status 128")
+	assert.True(t, m.IsFailing())
+	assert.Equal(t, 3, m.ConsecutiveFailures)
+	assert.Equal(t, "exit status 128", m.LastError)
+	assert.InDelta(t, time.Now().Add(40*time.Minute).Unix(), int64(m.NextUpdateUnix), 5)
+
+	m.RecordSuccess()
+	assert.False(t, m.IsFailing())
+	assert.Zero(t, m.ConsecutiveFailures)
+	assert.Empty(t, m.LastError)
+
+	setting.Mirror.FailureThreshold = 0
+	m.ConsecutiveFailures = 100
+	assert.False(t, m.IsFailing())
+}
+
+func TestSearchMirrors(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, InsertMirror(&Mirror{RepoID: 1, Interval: time.Hour}))
+	assert.NoError(t, InsertMirror(&Mirror{RepoID: 3, Interval: time.Hour, LastError: "exit status 128", ConsecutiveFailures: 4}))
+
+	mirrors, count, err := SearchMirrors(&SearchMirrorsOptions{ListOptions: ListOptions{Page: 1, PageSize: 10}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	if assert.Len(t, mirrors, 2) {
+		assert.EqualValues(t, 3, mirrors[0].RepoID)
+		assert.EqualValues(t, 3, mirrors[0].Repo.ID)
+		assert.EqualValues(t, 1, mirrors[1].RepoID)
+	}
+
+	mirrors, count, err = SearchMirrors(&SearchMirrorsOptions{OnlyFailing: true})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, mirrors, 1) {
+		assert.Equal(t, "exit status 128", mirrors[0].LastError)
+	}
+
+	assert.NoError(t, InsertPushMirror(&PushMirror{RepoID: 1, Interval: time.Hour, ConsecutiveFailures: 2}))
+	pushMirrors, count, err := SearchPushMirrors(&SearchMirrorsOptions{OnlyFailing: true})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, pushMirrors, 1) {
+		assert.Equal(t, 2, pushMirrors[0].ConsecutiveFailures)
+	}
+}
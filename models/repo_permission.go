@@ -157,6 +157,12 @@ func getUserRepoPermission(e Engine, repo *Repository, user *User) (perm Permiss
 				perm)
 		}()
 	}
+	// a repository under maintenance is read-only for everyone but its administrators
+	defer func() {
+		if err == nil && repo.IsUnderMaintenance() && !perm.IsAdmin() {
+			perm.capAccessMode(AccessModeRead)
+		}
+	}()
 	// anonymous user visit private repo.
 	// TODO: anonymous user visit public unit of private repo???
 	if user == nil && repo.IsPrivate {
@@ -271,6 +277,18 @@ func getUserRepoPermission(e Engine, repo *Repository, user *User) (perm Permiss
 	return
 }
 
+// capAccessMode lowers the access modes of the permission to at most mode
+func (p *Permission) capAccessMode(mode AccessMode) {
+	if p.AccessMode > mode {
+		p.AccessMode = mode
+	}
+	for t, m := range p.UnitsMode {
+		if m > mode {
+			p.UnitsMode[t] = mode
+		}
+	}
+}
+
 // IsUserRealRepoAdmin check if this user is real repo admin
 func IsUserRealRepoAdmin(repo *Repository, user *User) (bool, error) {
 	if repo.OwnerID == user.ID {
@@ -7,6 +7,8 @@ package models
 import (
 	"testing"
 
+	"code.gitea.io/gitea/modules/timeutil"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -255,3 +257,45 @@ func TestRepoPermissionPrivateOrgRepo(t *testing.T) {
 		assert.True(t, perm.CanWrite(unit.Type))
 	}
 }
+
+func TestRepoPermissionMaintenanceMode(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 4}).(*Repository)
+	assert.NoError(t, repo.getUnits(x))
+	assert.NoError(t, repo.SetMaintenanceMode(true, 0, "rewriting history"))
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 4}).(*Repository)
+	assert.True(t, repo.IsUnderMaintenance())
+	assert.EqualValues(t, "rewriting history", repo.MaintenanceMessage)
+
+	// collaborator can only read
+	collaborator := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	perm, err := GetUserRepoPermission(repo, collaborator)
+	assert.NoError(t, err)
+	for _, unit := range repo.Units {
+		assert.True(t, perm.CanRead(unit.Type))
+		assert.False(t, perm.CanWrite(unit.Type))
+	}
+
+	// owner keeps full access
+	owner := AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+	perm, err = GetUserRepoPermission(repo, owner)
+	assert.NoError(t, err)
+	for _, unit := range repo.Units {
+		assert.True(t, perm.CanWrite(unit.Type))
+	}
+
+	// maintenance mode ends at the scheduled time
+	repo.MaintenanceEndUnix = timeutil.TimeStampNow() - 1
+	assert.False(t, repo.IsUnderMaintenance())
+	perm, err = GetUserRepoPermission(repo, collaborator)
+	assert.NoError(t, err)
+	for _, unit := range repo.Units {
+		assert.True(t, perm.CanWrite(unit.Type))
+	}
+
+	assert.NoError(t, repo.SetMaintenanceMode(false, 0, ""))
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 4}).(*Repository)
+	assert.False(t, repo.IsMaintenance)
+	assert.EqualValues(t, 0, repo.MaintenanceEndUnix)
+}
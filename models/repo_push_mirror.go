@@ -0,0 +1,159 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// PushMirror represents a remote repository to which a repository is pushed periodically.
+// The address of the remote and its credentials are stored in the git config of the repository.
+type PushMirror struct {
+	ID       int64       `xorm:"pk autoincr"`
+	RepoID   int64       `xorm:"INDEX"`
+	Repo     *Repository `xorm:"-"`
+	Interval time.Duration
+
+	// The mirror is only pushed between these minutes of the day, unless they are equal
+	SyncWindowStart int `xorm:"NOT NULL DEFAULT 0"`
+	SyncWindowEnd   int `xorm:"NOT NULL DEFAULT 0"`
+
+	// Only the refs matching these refspec patterns, like refs/tags/v*, are pushed, all of them if both are empty
+	IncludeRefs []string `xorm:"JSON TEXT"`
+	ExcludeRefs []string `xorm:"JSON TEXT"`
+
+	CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+	LastUpdateUnix timeutil.TimeStamp `xorm:"INDEX"`
+	NextUpdateUnix timeutil.TimeStamp `xorm:"INDEX"`
+	LastError      string             `xorm:"TEXT"`
+
+	ConsecutiveFailures int `xorm:"INDEX NOT NULL DEFAULT 0"`
+}
+
+// ErrPushMirrorNotExist represents a "PushMirrorNotExist" kind of error.
+type ErrPushMirrorNotExist struct {
+	ID     int64
+	RepoID int64
+}
+
+// IsErrPushMirrorNotExist checks if an error is a ErrPushMirrorNotExist.
+func IsErrPushMirrorNotExist(err error) bool {
+	_, ok := err.(ErrPushMirrorNotExist)
+	return ok
+}
+
+func (err ErrPushMirrorNotExist) Error() string {
+	return fmt.Sprintf("push mirror does not exist [id: %d, repo_id: %d]", err.ID, err.RepoID)
+}
+
+// AfterLoad is invoked from XORM after setting the values of all fields of this object.
+func (m *PushMirror) AfterLoad(session *xorm.Session) {
+	var err error
+	m.Repo, err = getRepositoryByID(session, m.RepoID)
+	if err != nil {
+		log.Error("getRepositoryByID[%d]: %v", m.ID, err)
+	}
+}
+
+// RemoteName returns the name of the git remote of the mirror in the repository
+func (m *PushMirror) RemoteName() string {
+	return fmt.Sprintf("push_mirror_%d", m.ID)
+}
+
+// ScheduleNextUpdate calculates and sets next update time.
+func (m *PushMirror) ScheduleNextUpdate() {
+	m.NextUpdateUnix = nextMirrorUpdate(time.Now(), m.Interval, m.SyncWindowStart, m.SyncWindowEnd)
+}
+
+// RecordSuccess resets the failures of the mirror after it was pushed
+func (m *PushMirror) RecordSuccess() {
+	m.LastError = ""
+	m.ConsecutiveFailures = 0
+}
+
+// RecordFailure records a failed push to the mirror and schedules the next attempt
+func (m *PushMirror) RecordFailure(errMessage string) {
+	m.LastError = errMessage
+	m.ConsecutiveFailures++
+	m.NextUpdateUnix = nextMirrorUpdate(time.Now(), mirrorRetryDelay(m.Interval, m.ConsecutiveFailures), m.SyncWindowStart, m.SyncWindowEnd)
+}
+
+// IsFailing returns whether the push mirror failed often enough for the administrators of its repository to be
+// notified
+func (m *PushMirror) IsFailing() bool {
+	return isMirrorFailing(m.ConsecutiveFailures)
+}
+
+// InsertPushMirror inserts a push mirror to database
+func InsertPushMirror(m *PushMirror) error {
+	_, err := x.Insert(m)
+	return err
+}
+
+// UpdatePushMirror updates the push mirror
+func UpdatePushMirror(m *PushMirror) error {
+	_, err := x.ID(m.ID).AllCols().Update(m)
+	return err
+}
+
+// DeletePushMirror deletes a push mirror of a repository
+func DeletePushMirror(repoID, id int64) error {
+	_, err := x.Delete(&PushMirror{ID: id, RepoID: repoID})
+	return err
+}
+
+// GetPushMirror returns a push mirror of a repository
+func GetPushMirror(repoID, id int64) (*PushMirror, error) {
+	m := &PushMirror{ID: id, RepoID: repoID}
+	has, err := x.Get(m)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPushMirrorNotExist{ID: id, RepoID: repoID}
+	}
+	return m, nil
+}
+
+// GetPushMirrorByID returns a push mirror by its ID
+func GetPushMirrorByID(id int64) (*PushMirror, error) {
+	return GetPushMirror(0, id)
+}
+
+// GetPushMirrorsByRepoID returns the push mirrors of a repository
+func GetPushMirrorsByRepoID(repoID int64) ([]*PushMirror, error) {
+	mirrors := make([]*PushMirror, 0, 5)
+	return mirrors, x.Where("repo_id = ?", repoID).Asc("id").Find(&mirrors)
+}
+
+// SearchPushMirrors returns the push mirrors, the ones which failed the most times in a row first, and their total
+// number
+func SearchPushMirrors(opts *SearchMirrorsOptions) ([]*PushMirror, int64, error) {
+	// the repositories of the mirrors are loaded with the session of the query, so the mirrors can't be counted with it
+	count, err := x.Where(opts.toCond()).Count(new(PushMirror))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sess := x.Where(opts.toCond()).Desc("consecutive_failures").Asc("next_update_unix", "id")
+	if opts.Page > 0 {
+		sess = opts.setSessionPagination(sess)
+	}
+	mirrors := make([]*PushMirror, 0, opts.PageSize)
+	return mirrors, count, sess.Find(&mirrors)
+}
+
+// PushMirrorsIterate iterates the push mirrors which have to be pushed.
+func PushMirrorsIterate(f func(idx int, bean interface{}) error) error {
+	return x.
+		Where("next_update_unix<=?", time.Now().Unix()).
+		And("next_update_unix!=0").
+		Iterate(new(PushMirror), f)
+}
@@ -5,6 +5,9 @@
 package models
 
 import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"strings"
 
 	"code.gitea.io/gitea/modules/git"
@@ -104,6 +107,35 @@ func PublicSigningKey(repoPath string) (string, error) {
 	return content, nil
 }
 
+// PrepareSSHSigning configures the git repository at tmpBasePath to sign commits with the
+// SSH signing key of repo if keyID is the fingerprint of this key.
+// It returns the key argument which has to be passed to "git commit -S".
+func PrepareSSHSigning(repo *Repository, tmpBasePath, keyID string) (string, error) {
+	signingKey, err := GetRepoSigningKey(repo.ID)
+	if err != nil {
+		if IsErrRepoSigningKeyNotExist(err) {
+			return keyID, nil
+		}
+		return "", err
+	}
+	if signingKey.Fingerprint != keyID {
+		return keyID, nil
+	}
+
+	privateKey, err := signingKey.DecryptPrivateKey()
+	if err != nil {
+		return "", fmt.Errorf("DecryptPrivateKey: %v", err)
+	}
+	keyPath := filepath.Join(tmpBasePath, ".git", "gitea-signing-key")
+	if err := ioutil.WriteFile(keyPath, []byte(privateKey), 0600); err != nil {
+		return "", fmt.Errorf("unable to write signing key: %v", err)
+	}
+	if _, err := git.NewCommand("config", "--local", "gpg.format", "ssh").RunInDir(tmpBasePath); err != nil {
+		return "", fmt.Errorf("git config [gpg.format -> ssh]: %v", err)
+	}
+	return keyPath, nil
+}
+
 // SignInitialCommit determines if we should sign the initial commit to this repository
 func SignInitialCommit(repoPath string, u *User) (bool, string, *git.Signature, error) {
 	rules := signingModeFromStrings(setting.Repository.Signing.InitialCommit)
@@ -0,0 +1,174 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/secret"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// RepoSigningKey represents an SSH key generated for a repository which is used
+// to sign the commits Gitea creates in it, e.g. when merging pull requests.
+type RepoSigningKey struct {
+	ID          int64  `xorm:"pk autoincr"`
+	RepoID      int64  `xorm:"UNIQUE NOT NULL"`
+	Fingerprint string `xorm:"INDEX NOT NULL"`
+	PublicKey   string `xorm:"TEXT NOT NULL"`
+	// PrivateKey is the OpenSSH private key encrypted with the SECRET_KEY
+	PrivateKey string `xorm:"TEXT NOT NULL"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// Signer returns the identity used for the commits signed with the signing key of repo
+func (key *RepoSigningKey) Signer(repo *Repository) *git.Signature {
+	email := setting.Repository.Signing.SigningEmail
+	if email == "" {
+		email = "noreply@" + setting.Service.NoReplyAddress
+	}
+	return &git.Signature{
+		Name:  repo.FullName(),
+		Email: email,
+	}
+}
+
+// DecryptPrivateKey returns the unencrypted OpenSSH private key
+func (key *RepoSigningKey) DecryptPrivateKey() (string, error) {
+	return secret.DecryptSecret(setting.SecretKey, key.PrivateKey)
+}
+
+// GetRepoSigningKey returns the SSH signing key of a repository
+func GetRepoSigningKey(repoID int64) (*RepoSigningKey, error) {
+	key := new(RepoSigningKey)
+	has, err := x.Where("repo_id = ?", repoID).Get(key)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRepoSigningKeyNotExist{RepoID: repoID}
+	}
+	return key, nil
+}
+
+// GetRepoSigningKeyByFingerprint returns the SSH signing key with the given fingerprint
+func GetRepoSigningKeyByFingerprint(fingerprint string) (*RepoSigningKey, error) {
+	key := new(RepoSigningKey)
+	has, err := x.Where("fingerprint = ?", fingerprint).Get(key)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRepoSigningKeyNotExist{Fingerprint: fingerprint}
+	}
+	return key, nil
+}
+
+// GenerateRepoSigningKey generates a new ed25519 SSH signing key for a repository,
+// replacing its previous signing key
+func GenerateRepoSigningKey(repo *Repository) (*RepoSigningKey, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	comment := repo.FullName() + "@" + setting.Domain
+	encrypted, err := secret.EncryptSecret(setting.SecretKey, string(marshalOpenSSHPrivateKey(priv, comment)))
+	if err != nil {
+		return nil, err
+	}
+
+	key := &RepoSigningKey{
+		RepoID:      repo.ID,
+		Fingerprint: ssh.FingerprintSHA256(sshPub),
+		PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub))) + " " + comment,
+		PrivateKey:  encrypted,
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+	if _, err := sess.Delete(&RepoSigningKey{RepoID: repo.ID}); err != nil {
+		return nil, err
+	}
+	if _, err := sess.Insert(key); err != nil {
+		return nil, err
+	}
+	return key, sess.Commit()
+}
+
+// DeleteRepoSigningKey deletes the SSH signing key of a repository
+func DeleteRepoSigningKey(repoID int64) error {
+	_, err := x.Delete(&RepoSigningKey{RepoID: repoID})
+	return err
+}
+
+// marshalOpenSSHPrivateKey encodes an ed25519 private key in the unencrypted
+// "openssh-key-v1" format expected by ssh-keygen
+func marshalOpenSSHPrivateKey(key ed25519.PrivateKey, comment string) []byte {
+	pub := key.Public().(ed25519.PublicKey)
+	pubKey := struct {
+		KeyType string
+		Pub     []byte
+	}{ssh.KeyAlgoED25519, pub}
+
+	check := make([]byte, 4)
+	_, _ = rand.Read(check)
+	checkInt := binary.BigEndian.Uint32(check)
+
+	privKey := struct {
+		Check1  uint32
+		Check2  uint32
+		KeyType string
+		Pub     []byte
+		Priv    []byte
+		Comment string
+		Pad     []byte `ssh:"rest"`
+	}{
+		Check1:  checkInt,
+		Check2:  checkInt,
+		KeyType: ssh.KeyAlgoED25519,
+		Pub:     pub,
+		Priv:    key,
+		Comment: comment,
+	}
+	// the private section is padded to the cipher block size, which is 8 for "none"
+	unpadded := len(ssh.Marshal(privKey))
+	for i := 0; (unpadded+i)%8 != 0; i++ {
+		privKey.Pad = append(privKey.Pad, byte(i+1))
+	}
+
+	w := struct {
+		CipherName   string
+		KdfName      string
+		KdfOpts      string
+		NumKeys      uint32
+		PubKey       []byte
+		PrivKeyBlock []byte
+	}{
+		CipherName:   "none",
+		KdfName:      "none",
+		NumKeys:      1,
+		PubKey:       ssh.Marshal(pubKey),
+		PrivKeyBlock: ssh.Marshal(privKey),
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "OPENSSH PRIVATE KEY",
+		Bytes: append([]byte("openssh-key-v1\x00"), ssh.Marshal(w)...),
+	})
+}
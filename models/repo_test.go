@@ -6,13 +6,17 @@ package models
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
 	"fmt"
 	"image"
 	"image/png"
+	"strings"
 	"testing"
+	"time"
 
 	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/storage"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -221,3 +225,50 @@ func TestRepoGetReviewerTeams(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(teams))
 }
+
+func TestDeleteOldRepositoryArchives(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	for _, p := range []string{"1/zip/a.zip", "1/targz/a.tar.gz", "2/zip/a.zip"} {
+		_, err := storage.RepoArchives.Save(p, strings.NewReader(p))
+		assert.NoError(t, err)
+	}
+	exists := func(p string) bool {
+		_, err := storage.RepoArchives.Stat(p)
+		return err == nil
+	}
+
+	// None of the archives is older than a day
+	assert.NoError(t, DeleteOldRepositoryArchives(context.Background(), 24*time.Hour, -1))
+	assert.True(t, exists("1/zip/a.zip"))
+
+	// All archives fit into a cache of their total size
+	totalSize := int64(len("1/zip/a.zip") + len("1/targz/a.tar.gz") + len("2/zip/a.zip"))
+	assert.NoError(t, DeleteOldRepositoryArchives(context.Background(), 24*time.Hour, totalSize))
+	assert.True(t, exists("1/zip/a.zip"))
+	assert.True(t, exists("1/targz/a.tar.gz"))
+	assert.True(t, exists("2/zip/a.zip"))
+
+	// One archive has to be evicted from a smaller cache
+	assert.NoError(t, DeleteOldRepositoryArchives(context.Background(), 24*time.Hour, totalSize-1))
+	remaining := 0
+	for _, p := range []string{"1/zip/a.zip", "1/targz/a.tar.gz", "2/zip/a.zip"} {
+		if exists(p) {
+			remaining++
+		}
+	}
+	assert.Equal(t, 2, remaining)
+	for _, p := range []string{"1/zip/a.zip", "1/targz/a.tar.gz", "2/zip/a.zip"} {
+		_, err := storage.RepoArchives.Save(p, strings.NewReader(p))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, deleteRepositoryArchivesOf(1))
+	assert.False(t, exists("1/zip/a.zip"))
+	assert.False(t, exists("1/targz/a.tar.gz"))
+	assert.True(t, exists("2/zip/a.zip"))
+
+	// All archives are older than an hour from now
+	assert.NoError(t, DeleteOldRepositoryArchives(context.Background(), -time.Hour, -1))
+	assert.False(t, exists("2/zip/a.zip"))
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
@@ -351,6 +352,13 @@ func TransferOwnership(doer *User, newOwnerName string, repo *Repository) (err e
 		}
 	}
 
+	// Remove the issues from the projects of the old organization
+	if oldOwner.IsOrganization() {
+		if err := removeRepoIssuesFromOrgProjects(sess, repo.ID, oldOwner.ID); err != nil {
+			return fmt.Errorf("removeRepoIssuesFromOrgProjects: %v", err)
+		}
+	}
+
 	// Rename remote repository to new path and delete local copy.
 	dir := UserPath(newOwner.Name)
 
@@ -362,6 +370,8 @@ func TransferOwnership(doer *User, newOwnerName string, repo *Repository) (err e
 		return fmt.Errorf("rename repository directory: %v", err)
 	}
 	repoRenamed = true
+	git.InvalidateRefsCache(RepoPath(oldOwner.Name, repo.Name))
+	git.InvalidatePackCache(RepoPath(oldOwner.Name, repo.Name))
 
 	// Rename remote wiki repository to new path and delete local copy.
 	wikiPath := WikiPath(oldOwner.Name, repo.Name)
@@ -374,6 +384,8 @@ func TransferOwnership(doer *User, newOwnerName string, repo *Repository) (err e
 			return fmt.Errorf("rename repository wiki: %v", err)
 		}
 		wikiRenamed = true
+		git.InvalidateRefsCache(wikiPath)
+		git.InvalidatePackCache(wikiPath)
 	}
 
 	if err := deleteRepositoryTransfer(sess, repo.ID); err != nil {
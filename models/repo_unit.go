@@ -79,6 +79,18 @@ type IssuesConfig struct {
 	EnableTimetracker                bool
 	AllowOnlyContributorsToTrackTime bool
 	EnableDependencies               bool
+	// EnableDueDateReminders reminds the assignees of the issues and milestones due soon
+	EnableDueDateReminders bool
+	// DueDateReminderDays is the number of days before a due date its assignees are reminded
+	DueDateReminderDays int
+}
+
+// DueDateReminderLeadDays returns the number of days before a due date its assignees are reminded, the default if unset
+func (cfg *IssuesConfig) DueDateReminderLeadDays() int {
+	if cfg.DueDateReminderDays <= 0 {
+		return DefaultDueDateReminderDays
+	}
+	return cfg.DueDateReminderDays
 }
 
 // FromDB fills up a IssuesConfig from serialized format.
@@ -93,6 +105,21 @@ func (cfg *IssuesConfig) ToDB() ([]byte, error) {
 	return json.Marshal(cfg)
 }
 
+// ReviewerAssignmentStrategy is how the reviewers of new pull requests are picked from a team
+type ReviewerAssignmentStrategy string
+
+const (
+	// ReviewerAssignmentRoundRobin picks the members of the team in turn
+	ReviewerAssignmentRoundRobin ReviewerAssignmentStrategy = "round-robin"
+	// ReviewerAssignmentLeastBusy picks the members of the team with the fewest pending review requests
+	ReviewerAssignmentLeastBusy ReviewerAssignmentStrategy = "least-busy"
+)
+
+// IsValid returns true if the strategy is known
+func (s ReviewerAssignmentStrategy) IsValid() bool {
+	return s == ReviewerAssignmentRoundRobin || s == ReviewerAssignmentLeastBusy
+}
+
 // PullRequestsConfig describes pull requests config
 type PullRequestsConfig struct {
 	IgnoreWhitespaceConflicts bool
@@ -100,8 +127,19 @@ type PullRequestsConfig struct {
 	AllowRebase               bool
 	AllowRebaseMerge          bool
 	AllowSquash               bool
+	AllowFastForwardOnly      bool
 	AllowManualMerge          bool
 	AutodetectManualMerge     bool
+
+	// AutoAssignReviewersTeamID is the team reviewers are requested from when a pull request is opened, 0 to disable
+	AutoAssignReviewersTeamID   int64
+	AutoAssignReviewersCount    int
+	AutoAssignReviewersStrategy ReviewerAssignmentStrategy
+}
+
+// IsAutoAssignReviewersEnabled returns true if reviewers are requested automatically for new pull requests
+func (cfg *PullRequestsConfig) IsAutoAssignReviewersEnabled() bool {
+	return cfg.AutoAssignReviewersTeamID > 0 && cfg.AutoAssignReviewersCount > 0
 }
 
 // FromDB fills up a PullRequestsConfig from serialized format.
@@ -122,6 +160,7 @@ func (cfg *PullRequestsConfig) IsMergeStyleAllowed(mergeStyle MergeStyle) bool {
 		mergeStyle == MergeStyleRebase && cfg.AllowRebase ||
 		mergeStyle == MergeStyleRebaseMerge && cfg.AllowRebaseMerge ||
 		mergeStyle == MergeStyleSquash && cfg.AllowSquash ||
+		mergeStyle == MergeStyleFastForwardOnly && cfg.AllowFastForwardOnly ||
 		mergeStyle == MergeStyleManuallyMerged && cfg.AllowManualMerge
 }
 
@@ -140,6 +179,9 @@ func (cfg *PullRequestsConfig) AllowedMergeStyleCount() int {
 	if cfg.AllowSquash {
 		count++
 	}
+	if cfg.AllowFastForwardOnly {
+		count++
+	}
 	return count
 }
 
@@ -544,6 +544,59 @@ func getTeamReviewerByIssueIDAndTeamID(e Engine, issueID, teamID int64) (review
 	return
 }
 
+// CountPendingReviewRequests returns for each of the users the number of open pull requests
+// they have been requested to review and have not reviewed yet
+func CountPendingReviewRequests(userIDs []int64) (map[int64]int64, error) {
+	counts := make(map[int64]int64, len(userIDs))
+	if len(userIDs) == 0 {
+		return counts, nil
+	}
+
+	latestReviews := builder.Select("max(id)").From("review").
+		Where(builder.In("reviewer_id", userIDs).
+			And(builder.Eq{"reviewer_team_id": 0, "original_author_id": 0}).
+			And(builder.In("type", ReviewTypeApprove, ReviewTypeReject, ReviewTypeRequest))).
+		GroupBy("issue_id, reviewer_id")
+	openIssues := builder.Select("id").From("issue").Where(builder.Eq{"is_closed": false})
+
+	results := make([]struct {
+		ReviewerID int64
+		Count      int64
+	}, 0, len(userIDs))
+	if err := x.Table("review").
+		Select("reviewer_id, count(*) AS count").
+		Where(builder.In("id", latestReviews)).
+		And("type = ?", ReviewTypeRequest).
+		And(builder.In("issue_id", openIssues)).
+		GroupBy("reviewer_id").
+		Find(&results); err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		counts[result.ReviewerID] = result.Count
+	}
+	return counts, nil
+}
+
+// GetLastRequestedReviewer returns which of the users has been requested to review a pull request
+// of the repository most recently, or 0 if none of them has been requested yet
+func GetLastRequestedReviewer(repoID int64, userIDs []int64) (int64, error) {
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+	comment := new(Comment)
+	has, err := x.Cols("assignee_id").
+		Where(builder.Eq{"type": CommentTypeReviewRequest, "removed_assignee": false}).
+		And(builder.In("assignee_id", userIDs)).
+		And(builder.In("issue_id", builder.Select("id").From("issue").Where(builder.Eq{"repo_id": repoID, "is_pull": true}))).
+		Desc("id").
+		Get(comment)
+	if err != nil || !has {
+		return 0, err
+	}
+	return comment.AssigneeID, nil
+}
+
 // MarkReviewsAsStale marks existing reviews as stale
 func MarkReviewsAsStale(issueID int64) (err error) {
 	_, err = x.Exec("UPDATE `review` SET stale=? WHERE issue_id=?", true, issueID)
@@ -897,6 +950,37 @@ func CanMarkConversation(issue *Issue, doer *User) (permResult bool, err error)
 	return true, nil
 }
 
+// CountUnresolvedConversations returns the number of code conversations of an issue which are not resolved.
+// Like in the diff view a conversation consists of the published comments on the same line of a file and
+// it is resolved by marking its first comment. Conversations on outdated lines are not counted.
+func CountUnresolvedConversations(issueID int64) (int, error) {
+	comments := make([]*Comment, 0, 10)
+	if err := x.Table("comment").
+		Select("comment.tree_path, comment.line, comment.resolve_doer_id").
+		Join("LEFT", "review", "review.id = comment.review_id").
+		Where("comment.issue_id = ? AND comment.type = ? AND comment.invalidated = ?", issueID, CommentTypeCode, false).
+		And("review.type IS NULL OR review.type <> ?", ReviewTypePending).
+		Asc("comment.created_unix").
+		Asc("comment.id").
+		Find(&comments); err != nil {
+		return 0, err
+	}
+
+	conversations := make(map[string]bool, len(comments))
+	unresolved := 0
+	for _, comment := range comments {
+		key := fmt.Sprintf("%d:%s", comment.Line, comment.TreePath)
+		if _, ok := conversations[key]; ok {
+			continue
+		}
+		conversations[key] = true
+		if comment.ResolveDoerID == 0 {
+			unresolved++
+		}
+	}
+	return unresolved, nil
+}
+
 // DeleteReview delete a review and it's code comments
 func DeleteReview(r *Review) error {
 	sess := x.NewSession()
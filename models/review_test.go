@@ -151,3 +151,80 @@ func TestDismissReview(t *testing.T) {
 	assert.NoError(t, DismissReview(review2, false))
 	assert.NoError(t, DismissReview(review2, false))
 }
+
+func TestCountPendingReviewRequests(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// user 2 and 4 have already reviewed pull request 3
+	counts, err := CountPendingReviewRequests([]int64{2, 4})
+	assert.NoError(t, err)
+	assert.Empty(t, counts)
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 12}).(*Issue)
+	assert.NoError(t, issue.LoadRepo())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	reviewer := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	_, err = AddReviewRequest(issue, reviewer, doer)
+	assert.NoError(t, err)
+
+	counts, err = CountPendingReviewRequests([]int64{2, 4})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]int64{4: 1}, counts)
+
+	// approving or rejecting completes the request
+	_, _, err = SubmitReview(reviewer, issue, ReviewTypeApprove, "looks good", "", false)
+	assert.NoError(t, err)
+	counts, err = CountPendingReviewRequests([]int64{2, 4})
+	assert.NoError(t, err)
+	assert.Empty(t, counts)
+
+	counts, err = CountPendingReviewRequests(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, counts)
+}
+
+func TestGetLastRequestedReviewer(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 12}).(*Issue)
+	assert.NoError(t, issue.LoadRepo())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	last, err := GetLastRequestedReviewer(issue.RepoID, []int64{4, 5})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, last)
+
+	for _, id := range []int64{5, 4} {
+		reviewer := AssertExistsAndLoadBean(t, &User{ID: id}).(*User)
+		_, err = AddReviewRequest(issue, reviewer, doer)
+		assert.NoError(t, err)
+	}
+	last, err = GetLastRequestedReviewer(issue.RepoID, []int64{4, 5})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, last)
+	last, err = GetLastRequestedReviewer(issue.RepoID, []int64{5})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, last)
+
+	// requests in other repositories are ignored
+	last, err = GetLastRequestedReviewer(1, []int64{4, 5})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, last)
+}
+
+func TestCountUnresolvedConversations(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// comment 4 belongs to a pending review and comment 6 is outdated
+	unresolved, err := CountUnresolvedConversations(2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, unresolved)
+
+	comment := AssertExistsAndLoadBean(t, &Comment{ID: 5}).(*Comment)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	assert.NoError(t, MarkConversation(comment, doer, true))
+
+	unresolved, err = CountUnresolvedConversations(2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, unresolved)
+}
@@ -0,0 +1,291 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// SCIMGroup represents a group provisioned by an identity provider through SCIM.
+// Its members are synchronized to the organization teams it is mapped to.
+type SCIMGroup struct {
+	ID          int64              `xorm:"pk autoincr"`
+	DisplayName string             `xorm:"NOT NULL"`
+	LowerName   string             `xorm:"UNIQUE NOT NULL"`
+	ExternalID  string             `xorm:"INDEX"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// TableName returns a better table name for SCIMGroup
+func (g SCIMGroup) TableName() string {
+	return "scim_group"
+}
+
+// SCIMGroupMember represents the membership of a user in a SCIM group
+type SCIMGroupMember struct {
+	ID      int64 `xorm:"pk autoincr"`
+	GroupID int64 `xorm:"UNIQUE(s) NOT NULL"`
+	UserID  int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+}
+
+// TableName returns a better table name for SCIMGroupMember
+func (m SCIMGroupMember) TableName() string {
+	return "scim_group_member"
+}
+
+func isSCIMGroupExist(e Engine, id int64, displayName string) (bool, error) {
+	return e.Where("id != ?", id).
+		And("lower_name = ?", strings.ToLower(displayName)).
+		Get(new(SCIMGroup))
+}
+
+func addSCIMGroupMembers(e Engine, groupID int64, userIDs []int64) error {
+	existing, err := getSCIMGroupMemberIDs(e, groupID)
+	if err != nil {
+		return err
+	}
+	isMember := make(map[int64]bool, len(existing))
+	for _, id := range existing {
+		isMember[id] = true
+	}
+	for _, userID := range userIDs {
+		if isMember[userID] {
+			continue
+		}
+		if _, err := e.Insert(&SCIMGroupMember{GroupID: groupID, UserID: userID}); err != nil {
+			return err
+		}
+		isMember[userID] = true
+	}
+	return nil
+}
+
+// NewSCIMGroup creates a SCIM group with the given members
+func NewSCIMGroup(g *SCIMGroup, userIDs []int64) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	has, err := isSCIMGroupExist(sess, 0, g.DisplayName)
+	if err != nil {
+		return err
+	} else if has {
+		return ErrSCIMGroupAlreadyExist{g.DisplayName}
+	}
+
+	g.LowerName = strings.ToLower(g.DisplayName)
+	if _, err = sess.Insert(g); err != nil {
+		return err
+	}
+	if err = addSCIMGroupMembers(sess, g.ID, userIDs); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// GetSCIMGroupByID returns the SCIM group with the given ID
+func GetSCIMGroupByID(id int64) (*SCIMGroup, error) {
+	g := new(SCIMGroup)
+	has, err := x.ID(id).Get(g)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrSCIMGroupNotExist{id}
+	}
+	return g, nil
+}
+
+// FindSCIMGroupsOptions represents the options to find SCIM groups
+type FindSCIMGroupsOptions struct {
+	DisplayName string
+	ExternalID  string
+	Offset      int
+	Limit       int
+}
+
+func (opts *FindSCIMGroupsOptions) toConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.DisplayName != "" {
+		cond = cond.And(builder.Eq{"lower_name": strings.ToLower(opts.DisplayName)})
+	}
+	if opts.ExternalID != "" {
+		cond = cond.And(builder.Eq{"external_id": opts.ExternalID})
+	}
+	return cond
+}
+
+// FindSCIMGroups returns the SCIM groups matching the options and their total count
+func FindSCIMGroups(opts *FindSCIMGroupsOptions) ([]*SCIMGroup, int64, error) {
+	count, err := x.Where(opts.toConds()).Count(new(SCIMGroup))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	groups := make([]*SCIMGroup, 0, opts.Limit)
+	if opts.Limit <= 0 {
+		return groups, count, nil
+	}
+	return groups, count, x.Where(opts.toConds()).
+		OrderBy("id").
+		Limit(opts.Limit, opts.Offset).
+		Find(&groups)
+}
+
+// UpdateSCIMGroup updates the display name and external ID of a SCIM group
+func UpdateSCIMGroup(g *SCIMGroup) error {
+	has, err := isSCIMGroupExist(x, g.ID, g.DisplayName)
+	if err != nil {
+		return err
+	} else if has {
+		return ErrSCIMGroupAlreadyExist{g.DisplayName}
+	}
+
+	g.LowerName = strings.ToLower(g.DisplayName)
+	_, err = x.ID(g.ID).Cols("display_name", "lower_name", "external_id").Update(g)
+	return err
+}
+
+// DeleteSCIMGroup deletes a SCIM group and its memberships
+func DeleteSCIMGroup(g *SCIMGroup) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = sess.Delete(&SCIMGroupMember{GroupID: g.ID}); err != nil {
+		return err
+	}
+	if _, err = sess.ID(g.ID).Delete(new(SCIMGroup)); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func getSCIMGroupMemberIDs(e Engine, groupID int64) ([]int64, error) {
+	ids := make([]int64, 0, 10)
+	return ids, e.Table("scim_group_member").
+		Where("group_id = ?", groupID).
+		OrderBy("user_id").
+		Cols("user_id").
+		Find(&ids)
+}
+
+// GetSCIMGroupMemberIDs returns the IDs of the members of a SCIM group
+func GetSCIMGroupMemberIDs(groupID int64) ([]int64, error) {
+	return getSCIMGroupMemberIDs(x, groupID)
+}
+
+// AddSCIMGroupMembers adds the users to a SCIM group, existing members are ignored
+func AddSCIMGroupMembers(groupID int64, userIDs []int64) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+	if err = addSCIMGroupMembers(sess, groupID, userIDs); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// RemoveSCIMGroupMembers removes the users from a SCIM group
+func RemoveSCIMGroupMembers(groupID int64, userIDs []int64) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	_, err := x.Where("group_id = ?", groupID).
+		In("user_id", userIDs).
+		Delete(new(SCIMGroupMember))
+	return err
+}
+
+// ReplaceSCIMGroupMembers replaces all members of a SCIM group with the given users
+func ReplaceSCIMGroupMembers(groupID int64, userIDs []int64) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+	if _, err = sess.Delete(&SCIMGroupMember{GroupID: groupID}); err != nil {
+		return err
+	}
+	if err = addSCIMGroupMembers(sess, groupID, userIDs); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// FindSCIMUsersOptions represents the options to find users through SCIM
+type FindSCIMUsersOptions struct {
+	UserName string
+	Email    string
+	Offset   int
+	Limit    int
+}
+
+func (opts *FindSCIMUsersOptions) toConds() builder.Cond {
+	cond := builder.NewCond().And(builder.Eq{"type": UserTypeIndividual})
+	if opts.UserName != "" {
+		cond = cond.And(builder.Eq{"lower_name": strings.ToLower(opts.UserName)})
+	}
+	if opts.Email != "" {
+		cond = cond.And(builder.Eq{"email": strings.ToLower(opts.Email)})
+	}
+	return cond
+}
+
+// FindSCIMUsers returns the individual users matching the options and their total count
+func FindSCIMUsers(opts *FindSCIMUsersOptions) ([]*User, int64, error) {
+	count, err := x.Where(opts.toConds()).Count(new(User))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users := make([]*User, 0, opts.Limit)
+	if opts.Limit <= 0 {
+		return users, count, nil
+	}
+	return users, count, x.Where(opts.toConds()).
+		OrderBy("id").
+		Limit(opts.Limit, opts.Offset).
+		Find(&users)
+}
+
+// GetUserSCIMGroups returns the SCIM groups the user is a member of
+func GetUserSCIMGroups(userID int64) ([]*SCIMGroup, error) {
+	groups := make([]*SCIMGroup, 0, 5)
+	return groups, x.
+		Join("INNER", "scim_group_member", "scim_group_member.group_id = scim_group.id").
+		Where("scim_group_member.user_id = ?", userID).
+		OrderBy("scim_group.id").
+		Find(&groups)
+}
+
+// SyncSCIMUserTeams synchronizes the team memberships of the user with
+// their SCIM groups according to the configured group team mapping
+func SyncSCIMUserTeams(user *User) error {
+	if len(setting.SCIM.TeamMapping) == 0 {
+		return nil
+	}
+	groups, err := GetUserSCIMGroups(user.ID)
+	if err != nil {
+		return fmt.Errorf("GetUserSCIMGroups: %v", err)
+	}
+	names := make([]string, 0, len(groups))
+	for _, g := range groups {
+		names = append(names, g.LowerName)
+	}
+	return SyncUserTeams(user, names, setting.SCIM.TeamMapping, setting.SCIM.GroupTeamMapRemoval)
+}
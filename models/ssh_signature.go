@@ -0,0 +1,204 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	sshSignatureBegin = "-----BEGIN SSH SIGNATURE-----"
+	sshSignatureEnd   = "-----END SSH SIGNATURE-----"
+	sshSignatureMagic = "SSHSIG"
+	// sshSignatureNamespace is the namespace git uses for SSH signatures of commits and tags
+	sshSignatureNamespace = "git"
+)
+
+// sshSignature is a signature in the format created by "ssh-keygen -Y sign",
+// see https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.sshsig
+type sshSignature struct {
+	PublicKey     ssh.PublicKey
+	Namespace     string
+	HashAlgorithm string
+	Signature     *ssh.Signature
+}
+
+// isSSHSignature returns true if the armored signature is an SSH signature
+func isSSHSignature(signature string) bool {
+	return strings.HasPrefix(strings.TrimSpace(signature), sshSignatureBegin)
+}
+
+func parseSSHSignature(armored string) (*sshSignature, error) {
+	armored = strings.TrimSpace(armored)
+	if !strings.HasPrefix(armored, sshSignatureBegin) || !strings.HasSuffix(armored, sshSignatureEnd) {
+		return nil, fmt.Errorf("missing SSH signature armor")
+	}
+	body := strings.Join(strings.Fields(armored[len(sshSignatureBegin):len(armored)-len(sshSignatureEnd)]), "")
+	blob, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSH signature encoding: %v", err)
+	}
+	if !bytes.HasPrefix(blob, []byte(sshSignatureMagic)) {
+		return nil, fmt.Errorf("invalid SSH signature magic")
+	}
+
+	var wire struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}
+	if err := ssh.Unmarshal(blob[len(sshSignatureMagic):], &wire); err != nil {
+		return nil, fmt.Errorf("invalid SSH signature: %v", err)
+	}
+	if wire.Version != 1 {
+		return nil, fmt.Errorf("unsupported SSH signature version %d", wire.Version)
+	}
+
+	pub, err := ssh.ParsePublicKey(wire.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSH signature public key: %v", err)
+	}
+	sig := new(ssh.Signature)
+	if err := ssh.Unmarshal(wire.Signature, sig); err != nil {
+		return nil, fmt.Errorf("invalid SSH signature blob: %v", err)
+	}
+
+	return &sshSignature{
+		PublicKey:     pub,
+		Namespace:     wire.Namespace,
+		HashAlgorithm: wire.HashAlgorithm,
+		Signature:     sig,
+	}, nil
+}
+
+// Verify checks that the signature was created for payload with the public key of the signature
+func (s *sshSignature) Verify(payload string) error {
+	if s.Namespace != sshSignatureNamespace {
+		return fmt.Errorf("unexpected SSH signature namespace %q", s.Namespace)
+	}
+
+	var h hash.Hash
+	switch s.HashAlgorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return fmt.Errorf("unsupported SSH signature hash algorithm %q", s.HashAlgorithm)
+	}
+	_, _ = h.Write([]byte(payload))
+
+	signed := ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{
+		Namespace:     s.Namespace,
+		HashAlgorithm: s.HashAlgorithm,
+		Hash:          h.Sum(nil),
+	})
+	return s.PublicKey.Verify(append([]byte(sshSignatureMagic), signed...), s.Signature)
+}
+
+// parseCommitWithSSHSignature checks the SSH signature of a commit against the
+// repository signing keys and the SSH keys of the users
+func parseCommitWithSSHSignature(c *git.Commit, committer *User) *CommitVerification {
+	sig, err := parseSSHSignature(c.Signature.Signature)
+	if err != nil {
+		log.Error("SSH SignatureRead err: %v", err)
+		return &CommitVerification{
+			CommittingUser: committer,
+			Verified:       false,
+			Reason:         "gpg.error.extract_sign",
+		}
+	}
+	fingerprint := ssh.FingerprintSHA256(sig.PublicKey)
+
+	if err := sig.Verify(c.Signature.Payload); err != nil {
+		log.Debug("SSH signature of commit %s does not verify: %v", c.ID, err)
+		return &CommitVerification{
+			CommittingUser: committer,
+			Verified:       false,
+			Warning:        true,
+			Reason:         BadSignature,
+			SigningSSHKey:  &PublicKey{Fingerprint: fingerprint},
+		}
+	}
+
+	// The signature is valid, now find out whose key it is
+	if signingKey, err := GetRepoSigningKeyByFingerprint(fingerprint); err == nil {
+		repo, err := GetRepositoryByID(signingKey.RepoID)
+		if err != nil {
+			log.Error("GetRepositoryByID: %v", err)
+			return &CommitVerification{
+				CommittingUser: committer,
+				Verified:       false,
+				Reason:         NoKeyFound,
+				SigningSSHKey:  &PublicKey{Fingerprint: fingerprint},
+			}
+		}
+		signer := signingKey.Signer(repo)
+		return &CommitVerification{
+			CommittingUser: committer,
+			Verified:       true,
+			Reason:         fmt.Sprintf("%s / %s", signer.Name, fingerprint),
+			SigningUser: &User{
+				Name:  signer.Name,
+				Email: signer.Email,
+			},
+			SigningEmail:  signer.Email,
+			SigningSSHKey: &PublicKey{Name: signer.Name, Fingerprint: fingerprint},
+		}
+	} else if !IsErrRepoSigningKeyNotExist(err) {
+		log.Error("GetRepoSigningKeyByFingerprint: %v", err)
+	}
+
+	key := new(PublicKey)
+	has, err := x.Where("fingerprint = ? AND type = ?", fingerprint, KeyTypeUser).Get(key)
+	if err != nil {
+		log.Error("Unable to get SSH key by fingerprint %s: %v", fingerprint, err)
+	}
+	if has {
+		owner, err := GetUserByID(key.OwnerID)
+		if err == nil {
+			email := owner.Email
+			if committer.ID == owner.ID && c.Committer != nil {
+				email = c.Committer.Email
+			}
+			return &CommitVerification{
+				CommittingUser: committer,
+				Verified:       true,
+				Reason:         fmt.Sprintf("%s / %s", owner.Name, fingerprint),
+				SigningUser:    owner,
+				SigningEmail:   email,
+				SigningSSHKey:  key,
+			}
+		} else if !IsErrUserNotExist(err) {
+			log.Error("GetUserByID: %v", err)
+		}
+	}
+
+	return &CommitVerification{
+		CommittingUser: committer,
+		Verified:       false,
+		Reason:         NoKeyFound,
+		SigningSSHKey:  &PublicKey{Fingerprint: fingerprint},
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+// signSSH creates an armored SSH signature of payload like "ssh-keygen -Y sign -n <namespace>"
+func signSSH(t *testing.T, signer ssh.Signer, namespace, payload string) string {
+	h := sha512.Sum512([]byte(payload))
+	signed := ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{namespace, "", "sha512", h[:]})
+	sig, err := signer.Sign(rand.Reader, append([]byte(sshSignatureMagic), signed...))
+	assert.NoError(t, err)
+
+	blob := ssh.Marshal(struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}{1, signer.PublicKey().Marshal(), namespace, "", "sha512", ssh.Marshal(sig)})
+	encoded := base64.StdEncoding.EncodeToString(append([]byte(sshSignatureMagic), blob...))
+
+	var lines []string
+	for len(encoded) > 70 {
+		lines = append(lines, encoded[:70])
+		encoded = encoded[70:]
+	}
+	lines = append(lines, encoded)
+	return sshSignatureBegin + "\n" + strings.Join(lines, "\n") + "\n" + sshSignatureEnd + "\n"
+}
+
+func testRepoSigningKeySigner(t *testing.T, key *RepoSigningKey) ssh.Signer {
+	privateKey, err := key.DecryptPrivateKey()
+	assert.NoError(t, err)
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	assert.NoError(t, err)
+	return signer
+}
+
+func TestGenerateRepoSigningKey(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	key, err := GenerateRepoSigningKey(repo)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key.PublicKey, "ssh-ed25519 "))
+	assert.True(t, strings.HasPrefix(key.Fingerprint, "SHA256:"))
+
+	// the private key must be readable by ssh-keygen and match the public key
+	signer := testRepoSigningKeySigner(t, key)
+	assert.Equal(t, key.Fingerprint, ssh.FingerprintSHA256(signer.PublicKey()))
+
+	// generating a new key replaces the previous one
+	newKey, err := GenerateRepoSigningKey(repo)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key.Fingerprint, newKey.Fingerprint)
+	loaded, err := GetRepoSigningKey(repo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, newKey.Fingerprint, loaded.Fingerprint)
+	_, err = GetRepoSigningKeyByFingerprint(key.Fingerprint)
+	assert.True(t, IsErrRepoSigningKeyNotExist(err))
+
+	assert.NoError(t, DeleteRepoSigningKey(repo.ID))
+	_, err = GetRepoSigningKey(repo.ID)
+	assert.True(t, IsErrRepoSigningKeyNotExist(err))
+}
+
+func TestSSHSignature_Verify(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	key, err := GenerateRepoSigningKey(repo)
+	assert.NoError(t, err)
+	signer := testRepoSigningKeySigner(t, key)
+
+	payload := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\nmessage\n"
+	sig, err := parseSSHSignature(signSSH(t, signer, "git", payload))
+	assert.NoError(t, err)
+	assert.NoError(t, sig.Verify(payload))
+	assert.Error(t, sig.Verify(payload+"tampered"))
+
+	sig, err = parseSSHSignature(signSSH(t, signer, "file", payload))
+	assert.NoError(t, err)
+	assert.Error(t, sig.Verify(payload))
+
+	_, err = parseSSHSignature("-----BEGIN PGP SIGNATURE-----\n-----END PGP SIGNATURE-----")
+	assert.Error(t, err)
+}
+
+func TestParseCommitWithSSHSignature(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	key, err := GenerateRepoSigningKey(repo)
+	assert.NoError(t, err)
+	signer := testRepoSigningKeySigner(t, key)
+
+	payload := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\nmessage\n"
+	commit := &git.Commit{
+		Committer: &git.Signature{Name: "user2", Email: "user2@example.com"},
+		Signature: &git.CommitGPGSignature{
+			Signature: signSSH(t, signer, "git", payload),
+			Payload:   payload,
+		},
+	}
+
+	verification := ParseCommitWithSignature(commit)
+	assert.True(t, verification.Verified)
+	assert.EqualValues(t, 0, verification.SigningUser.ID)
+	assert.Equal(t, repo.FullName(), verification.SigningUser.Name)
+	assert.Equal(t, key.Fingerprint, verification.SigningSSHKey.Fingerprint)
+
+	commit.Signature.Payload += "tampered"
+	verification = ParseCommitWithSignature(commit)
+	assert.False(t, verification.Verified)
+	assert.True(t, verification.Warning)
+	assert.Equal(t, BadSignature, verification.Reason)
+
+	assert.NoError(t, DeleteRepoSigningKey(repo.ID))
+	commit.Signature.Payload = payload
+	verification = ParseCommitWithSignature(commit)
+	assert.False(t, verification.Verified)
+	assert.Equal(t, NoKeyFound, verification.Reason)
+}
@@ -0,0 +1,237 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// storageConsistencyGracePeriod protects objects which were just uploaded but whose
+// records are not inserted yet from being reported as orphaned
+var storageConsistencyGracePeriod = time.Hour
+
+// StorageConsistencyReport is the result of the comparison of the records of a kind of
+// object with the objects in their storage
+type StorageConsistencyReport struct {
+	Storage    string
+	NumObjects int
+	NumRecords int
+	// OrphanedObjects are the paths of objects which are not referenced by any record
+	OrphanedObjects []string
+	// MissingObjects are the paths of objects which are referenced by records but do not exist
+	MissingObjects []string
+	// Fixed is true if the orphaned objects and the records of the missing objects were removed
+	Fixed bool
+}
+
+// IsConsistent returns true if the records and the objects match
+func (r *StorageConsistencyReport) IsConsistent() bool {
+	return len(r.OrphanedObjects) == 0 && len(r.MissingObjects) == 0
+}
+
+// storageRecords describes how the records of a storage reference their objects
+type storageRecords struct {
+	name    string
+	storage func() storage.ObjectStorage
+	// paths returns the paths of all referenced objects with the time their record was created
+	paths func() (map[string]timeutil.TimeStamp, error)
+	// removeMissing removes or resets the records referencing the missing objects
+	removeMissing func(paths []string) error
+}
+
+var storageRecordKinds = []*storageRecords{
+	{
+		name:    "attachments",
+		storage: func() storage.ObjectStorage { return storage.Attachments },
+		paths: func() (map[string]timeutil.TimeStamp, error) {
+			paths := make(map[string]timeutil.TimeStamp)
+			return paths, x.Cols("uuid", "created_unix").Iterate(new(Attachment), func(_ int, bean interface{}) error {
+				a := bean.(*Attachment)
+				paths[a.RelativePath()] = a.CreatedUnix
+				return nil
+			})
+		},
+		removeMissing: func(paths []string) error {
+			uuids := make([]string, 0, len(paths))
+			for _, p := range paths {
+				uuids = append(uuids, path.Base(p))
+			}
+			return deleteInBatches(uuids, func(batch []string) error {
+				_, err := x.In("uuid", batch).NoAutoCondition().Delete(new(Attachment))
+				return err
+			})
+		},
+	},
+	{
+		name:    "lfs",
+		storage: func() storage.ObjectStorage { return storage.LFS },
+		paths: func() (map[string]timeutil.TimeStamp, error) {
+			paths := make(map[string]timeutil.TimeStamp)
+			return paths, x.Cols("oid", "created_unix").Iterate(new(LFSMetaObject), func(_ int, bean interface{}) error {
+				m := bean.(*LFSMetaObject)
+				if created, ok := paths[m.RelativePath()]; !ok || m.CreatedUnix < created {
+					paths[m.RelativePath()] = m.CreatedUnix
+				}
+				return nil
+			})
+		},
+		removeMissing: func(paths []string) error {
+			oids := make([]string, 0, len(paths))
+			for _, p := range paths {
+				oids = append(oids, strings.ReplaceAll(p, "/", ""))
+			}
+			return deleteInBatches(oids, func(batch []string) error {
+				_, err := x.In("oid", batch).NoAutoCondition().Delete(new(LFSMetaObject))
+				return err
+			})
+		},
+	},
+	{
+		name:    "avatars",
+		storage: func() storage.ObjectStorage { return storage.Avatars },
+		paths: func() (map[string]timeutil.TimeStamp, error) {
+			paths := make(map[string]timeutil.TimeStamp)
+			return paths, x.Where(builder.Neq{"avatar": ""}).Cols("avatar", "updated_unix").Iterate(new(User), func(_ int, bean interface{}) error {
+				u := bean.(*User)
+				paths[u.CustomAvatarRelativePath()] = u.UpdatedUnix
+				return nil
+			})
+		},
+		removeMissing: func(paths []string) error {
+			// the users fall back to the default avatar
+			return deleteInBatches(paths, func(batch []string) error {
+				_, err := x.In("avatar", batch).Cols("avatar", "use_custom_avatar").NoAutoTime().Update(new(User))
+				return err
+			})
+		},
+	},
+	{
+		name:    "repo-avatars",
+		storage: func() storage.ObjectStorage { return storage.RepoAvatars },
+		paths: func() (map[string]timeutil.TimeStamp, error) {
+			paths := make(map[string]timeutil.TimeStamp)
+			return paths, x.Where(builder.Neq{"avatar": ""}).Cols("avatar", "updated_unix").Iterate(new(Repository), func(_ int, bean interface{}) error {
+				repo := bean.(*Repository)
+				paths[repo.CustomAvatarRelativePath()] = repo.UpdatedUnix
+				return nil
+			})
+		},
+		removeMissing: func(paths []string) error {
+			return deleteInBatches(paths, func(batch []string) error {
+				_, err := x.In("avatar", batch).Cols("avatar").NoAutoTime().Update(new(Repository))
+				return err
+			})
+		},
+	},
+}
+
+// deleteInBatches calls fn with batches of the values to keep the size of the queries limited
+func deleteInBatches(values []string, fn func(batch []string) error) error {
+	const batchSize = 50
+	for len(values) > 0 {
+		n := batchSize
+		if len(values) < n {
+			n = len(values)
+		}
+		if err := fn(values[:n]); err != nil {
+			return err
+		}
+		values = values[n:]
+	}
+	return nil
+}
+
+// CheckStorageConsistency compares the records of attachments, LFS objects, user avatars and
+// repository avatars with the objects in their storages. If fix is true, orphaned objects are
+// deleted and records of missing objects are removed, or reset in case of avatars.
+func CheckStorageConsistency(ctx context.Context, fix bool) ([]*StorageConsistencyReport, error) {
+	reports := make([]*StorageConsistencyReport, 0, len(storageRecordKinds))
+	for _, kind := range storageRecordKinds {
+		report, err := checkStorageConsistency(ctx, kind, fix)
+		if err != nil {
+			return reports, fmt.Errorf("check %s: %v", kind.name, err)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func checkStorageConsistency(ctx context.Context, kind *storageRecords, fix bool) (*StorageConsistencyReport, error) {
+	started := time.Now()
+	report := &StorageConsistencyReport{Storage: kind.name}
+
+	// The objects are listed before the records are loaded, so that new objects
+	// have their records by then, unless they are still in the grace period.
+	objStorage := kind.storage()
+	objects := make(map[string]time.Time)
+	if err := objStorage.IterateObjects(func(p string, obj storage.Object) error {
+		select {
+		case <-ctx.Done():
+			return ErrCancelledf("during the iteration of the %s storage", kind.name)
+		default:
+		}
+		fi, err := obj.Stat()
+		if err != nil {
+			return err
+		}
+		objects[filepath.ToSlash(p)] = fi.ModTime()
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	report.NumObjects = len(objects)
+
+	records, err := kind.paths()
+	if err != nil {
+		return nil, err
+	}
+	report.NumRecords = len(records)
+
+	for p, modTime := range objects {
+		if _, ok := records[p]; !ok && modTime.Before(started.Add(-storageConsistencyGracePeriod)) {
+			report.OrphanedObjects = append(report.OrphanedObjects, p)
+		}
+	}
+	startedUnix := timeutil.TimeStamp(started.Unix())
+	for p, created := range records {
+		// records created during the iteration may reference objects which were not listed
+		if _, ok := objects[p]; !ok && created < startedUnix {
+			report.MissingObjects = append(report.MissingObjects, p)
+		}
+	}
+
+	sort.Strings(report.OrphanedObjects)
+	sort.Strings(report.MissingObjects)
+
+	if !fix || report.IsConsistent() {
+		return report, nil
+	}
+	for _, p := range report.OrphanedObjects {
+		if err := objStorage.Delete(p); err != nil {
+			return nil, fmt.Errorf("delete %s: %v", p, err)
+		}
+	}
+	if len(report.MissingObjects) > 0 {
+		if err := kind.removeMissing(report.MissingObjects); err != nil {
+			return nil, err
+		}
+	}
+	report.Fixed = true
+	log.Info("Storage consistency of %s fixed: %d orphaned objects deleted, %d records of missing objects removed",
+		kind.name, len(report.OrphanedObjects), len(report.MissingObjects))
+	return report, nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckStorageConsistency(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	attachment := AssertExistsAndLoadBean(t, &Attachment{ID: 1}).(*Attachment)
+	_, err := storage.Attachments.Save(attachment.RelativePath(), strings.NewReader("content"))
+	assert.NoError(t, err)
+	defer storage.Attachments.Delete(attachment.RelativePath())
+
+	// objects without records are only reported after the grace period
+	_, err = storage.Attachments.Save("o/r/orphan", strings.NewReader("orphan"))
+	assert.NoError(t, err)
+	reports, err := CheckStorageConsistency(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Empty(t, reports[0].OrphanedObjects)
+
+	defer func(gracePeriod time.Duration) {
+		storageConsistencyGracePeriod = gracePeriod
+	}(storageConsistencyGracePeriod)
+	storageConsistencyGracePeriod = -time.Minute
+
+	reports, err = CheckStorageConsistency(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Len(t, reports, 4)
+	report := reports[0]
+	assert.Equal(t, "attachments", report.Storage)
+	assert.Contains(t, report.OrphanedObjects, "o/r/orphan")
+	assert.Contains(t, report.MissingObjects, "a/0/a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a12")
+	assert.NotContains(t, report.MissingObjects, attachment.RelativePath())
+	assert.False(t, report.Fixed)
+
+	reports, err = CheckStorageConsistency(context.Background(), true)
+	assert.NoError(t, err)
+	assert.True(t, reports[0].Fixed)
+	_, err = storage.Attachments.Stat("o/r/orphan")
+	assert.Error(t, err)
+	AssertNotExistsBean(t, &Attachment{ID: 2})
+	AssertExistsAndLoadBean(t, &Attachment{ID: 1})
+
+	reports, err = CheckStorageConsistency(context.Background(), false)
+	assert.NoError(t, err)
+	for _, report := range reports {
+		assert.True(t, report.IsConsistent(), report.Storage)
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// StorageUsage is the storage used by a single repository or user
+type StorageUsage struct {
+	ID   int64
+	Name string
+	Size int64
+}
+
+// StorageStatistic is a snapshot of the disk usage and the growth of the instance.
+// Snapshots are taken periodically by the update_storage_statistics cron task,
+// so that the admin dashboards never have to scan the storages on demand.
+type StorageStatistic struct {
+	ID             int64 `xorm:"pk autoincr"`
+	RepositorySize int64 `xorm:"NOT NULL DEFAULT 0"`
+	LFSSize        int64 `xorm:"NOT NULL DEFAULT 0"`
+	AttachmentSize int64 `xorm:"NOT NULL DEFAULT 0"`
+	AvatarSize     int64 `xorm:"NOT NULL DEFAULT 0"`
+	RepoAvatarSize int64 `xorm:"NOT NULL DEFAULT 0"`
+
+	NumUsers int64 `xorm:"NOT NULL DEFAULT 0"`
+	NumOrgs  int64 `xorm:"NOT NULL DEFAULT 0"`
+	NumRepos int64 `xorm:"NOT NULL DEFAULT 0"`
+
+	LargestRepositories        []*StorageUsage `xorm:"JSON TEXT"`
+	LargestLFSOwners           []*StorageUsage `xorm:"JSON TEXT"`
+	LargestAttachmentUploaders []*StorageUsage `xorm:"JSON TEXT"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// TotalSize returns the disk usage of all storage types
+func (s *StorageStatistic) TotalSize() int64 {
+	return s.RepositorySize + s.LFSSize + s.AttachmentSize + s.AvatarSize + s.RepoAvatarSize
+}
+
+// GetLatestStorageStatistic returns the most recent snapshot
+func GetLatestStorageStatistic() (*StorageStatistic, error) {
+	stat := new(StorageStatistic)
+	has, err := x.Desc("id").Get(stat)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return stat, nil
+}
+
+// GetStorageStatistics returns the snapshots, most recent first
+func GetStorageStatistics(opts ListOptions) ([]*StorageStatistic, int64, error) {
+	sess := x.Desc("id")
+	if opts.Page > 0 {
+		sess = opts.setSessionPagination(sess)
+	}
+	stats := make([]*StorageStatistic, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&stats)
+	return stats, count, err
+}
+
+type storageSum struct {
+	ID    int64
+	Total int64
+}
+
+// objectStorageSize sums up the sizes of all objects of an object storage
+func objectStorageSize(ctx context.Context, s storage.ObjectStorage) (int64, error) {
+	var size int64
+	err := s.IterateObjects(func(path string, obj storage.Object) error {
+		select {
+		case <-ctx.Done():
+			return ErrCancelledf("during size calculation of %s", path)
+		default:
+		}
+		fi, err := obj.Stat()
+		if err != nil {
+			return err
+		}
+		size += fi.Size()
+		return nil
+	})
+	return size, err
+}
+
+func largestStorageUsers(sums []*storageSum) ([]*StorageUsage, error) {
+	usages := make([]*StorageUsage, 0, len(sums))
+	for _, sum := range sums {
+		name := "-"
+		u, err := GetUserByID(sum.ID)
+		if err == nil {
+			name = u.Name
+		} else if !IsErrUserNotExist(err) {
+			return nil, err
+		}
+		usages = append(usages, &StorageUsage{ID: sum.ID, Name: name, Size: sum.Total})
+	}
+	return usages, nil
+}
+
+// UpdateStorageStatistics takes a new snapshot of the disk usage and growth of the
+// instance, keeping the numTop largest consumers of every kind. Snapshots older
+// than olderThan are removed.
+func UpdateStorageStatistics(ctx context.Context, numTop int, olderThan time.Duration) error {
+	log.Trace("Doing: UpdateStorageStatistics")
+
+	stat := &StorageStatistic{
+		NumUsers: CountUsers(),
+		NumOrgs:  CountOrganizations(),
+		NumRepos: CountRepositories(true),
+	}
+
+	// The size of a repository includes its LFS objects, which are deduplicated in the LFS storage.
+	repoSize, err := x.SumInt(new(Repository), "size")
+	if err != nil {
+		return fmt.Errorf("sum repository size: %v", err)
+	}
+	repoLFSSize, err := x.SumInt(new(LFSMetaObject), "size")
+	if err != nil {
+		return fmt.Errorf("sum lfs size: %v", err)
+	}
+	stat.RepositorySize = repoSize - repoLFSSize
+	if _, err := x.SQL("SELECT COALESCE(SUM(size), 0) FROM (SELECT DISTINCT oid, size FROM lfs_meta_object) lfs").Get(&stat.LFSSize); err != nil {
+		return fmt.Errorf("sum distinct lfs size: %v", err)
+	}
+	if stat.AttachmentSize, err = x.SumInt(new(Attachment), "size"); err != nil {
+		return fmt.Errorf("sum attachment size: %v", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ErrCancelledf("before scanning the avatar storages")
+	default:
+	}
+	if stat.AvatarSize, err = objectStorageSize(ctx, storage.Avatars); err != nil {
+		return fmt.Errorf("avatar storage size: %v", err)
+	}
+	if stat.RepoAvatarSize, err = objectStorageSize(ctx, storage.RepoAvatars); err != nil {
+		return fmt.Errorf("repository avatar storage size: %v", err)
+	}
+
+	repos := make([]*Repository, 0, numTop)
+	if err := x.Where("size > 0").Desc("size").Limit(numTop).Find(&repos); err != nil {
+		return fmt.Errorf("find largest repositories: %v", err)
+	}
+	stat.LargestRepositories = make([]*StorageUsage, 0, len(repos))
+	for _, repo := range repos {
+		stat.LargestRepositories = append(stat.LargestRepositories, &StorageUsage{
+			ID:   repo.ID,
+			Name: repo.OwnerName + "/" + repo.Name,
+			Size: repo.Size,
+		})
+	}
+
+	sums := make([]*storageSum, 0, numTop)
+	if err := x.Table("lfs_meta_object").
+		Join("INNER", "repository", "repository.id = lfs_meta_object.repository_id").
+		Select("repository.owner_id AS id, SUM(lfs_meta_object.size) AS total").
+		GroupBy("repository.owner_id").
+		OrderBy("total DESC").
+		Limit(numTop).
+		Find(&sums); err != nil {
+		return fmt.Errorf("find largest lfs owners: %v", err)
+	}
+	if stat.LargestLFSOwners, err = largestStorageUsers(sums); err != nil {
+		return err
+	}
+
+	sums = make([]*storageSum, 0, numTop)
+	if err := x.Table("attachment").
+		Select("uploader_id AS id, SUM(size) AS total").
+		GroupBy("uploader_id").
+		OrderBy("total DESC").
+		Limit(numTop).
+		Find(&sums); err != nil {
+		return fmt.Errorf("find largest attachment uploaders: %v", err)
+	}
+	if stat.LargestAttachmentUploaders, err = largestStorageUsers(sums); err != nil {
+		return err
+	}
+
+	if _, err := x.Insert(stat); err != nil {
+		return err
+	}
+
+	if olderThan > 0 {
+		if _, err := x.Where("created_unix < ?", time.Now().Add(-olderThan).Unix()).Delete(new(StorageStatistic)); err != nil {
+			return fmt.Errorf("delete old storage statistics: %v", err)
+		}
+	}
+
+	log.Trace("Finished: UpdateStorageStatistics")
+	return nil
+}
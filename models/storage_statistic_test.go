@@ -0,0 +1,55 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateStorageStatistics(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	stat, err := GetLatestStorageStatistic()
+	assert.NoError(t, err)
+	assert.Nil(t, stat)
+
+	// both repositories share the same LFS object
+	_, err = x.ID(1).Cols("size").Update(&Repository{Size: 3000})
+	assert.NoError(t, err)
+	_, err = x.ID(2).Cols("size").Update(&Repository{Size: 1000})
+	assert.NoError(t, err)
+	_, err = x.Insert(&LFSMetaObject{Oid: "oid", Size: 500, RepositoryID: 1}, &LFSMetaObject{Oid: "oid", Size: 500, RepositoryID: 2})
+	assert.NoError(t, err)
+	_, err = x.ID(1).Cols("size", "uploader_id").Update(&Attachment{Size: 200, UploaderID: 1})
+	assert.NoError(t, err)
+
+	assert.NoError(t, UpdateStorageStatistics(context.Background(), 1, 0))
+
+	stat, err = GetLatestStorageStatistic()
+	assert.NoError(t, err)
+	if !assert.NotNil(t, stat) {
+		return
+	}
+	assert.EqualValues(t, 3000, stat.RepositorySize)
+	assert.EqualValues(t, 500, stat.LFSSize)
+	assert.EqualValues(t, 200, stat.AttachmentSize)
+	assert.EqualValues(t, CountUsers(), stat.NumUsers)
+	assert.EqualValues(t, CountRepositories(true), stat.NumRepos)
+
+	assert.Equal(t, []*StorageUsage{{ID: 1, Name: "user2/repo1", Size: 3000}}, stat.LargestRepositories)
+	assert.Equal(t, []*StorageUsage{{ID: 2, Name: "user2", Size: 1000}}, stat.LargestLFSOwners)
+	assert.Equal(t, []*StorageUsage{{ID: 1, Name: "user1", Size: 200}}, stat.LargestAttachmentUploaders)
+
+	assert.NoError(t, UpdateStorageStatistics(context.Background(), 1, 0))
+	stats, count, err := GetStorageStatistics(ListOptions{Page: 1, PageSize: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	if assert.Len(t, stats, 1) {
+		assert.Greater(t, stats[0].ID, stat.ID)
+	}
+}
@@ -30,6 +30,7 @@ type Task struct {
 	EndTime        timeutil.TimeStamp
 	PayloadContent string             `xorm:"TEXT"`
 	Errors         string             `xorm:"TEXT"` // if task failed, saved the error reason
+	Message        string             `xorm:"TEXT"` // progress of a running task
 	Created        timeutil.TimeStamp `xorm:"created"`
 }
 
@@ -170,6 +171,18 @@ func GetMigratingTaskByID(id, doerID int64) (*Task, *migration.MigrateOptions, e
 	return &task, &opts, nil
 }
 
+// GetLastRepoTask returns the most recent task of a type of a repository
+func GetLastRepoTask(repoID int64, tp structs.TaskType) (*Task, error) {
+	task := new(Task)
+	has, err := x.Where("repo_id = ? AND type = ?", repoID, tp).Desc("id").Get(task)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrTaskDoesNotExist{0, repoID, tp}
+	}
+	return task, nil
+}
+
 // FindTaskOptions find all tasks
 type FindTaskOptions struct {
 	Status int
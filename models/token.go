@@ -126,5 +126,6 @@ func DeleteAccessTokenByID(id, userID int64) error {
 	} else if cnt != 1 {
 		return ErrAccessTokenNotExist{}
 	}
-	return nil
+	_, err = x.Delete(&APIUsage{TokenID: id})
+	return err
 }
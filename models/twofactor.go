@@ -123,3 +123,29 @@ func DeleteTwoFactorByID(id, userID int64) error {
 	}
 	return nil
 }
+
+// DeleteAllTwoFactorByUID removes every second factor of the user: the TOTP
+// two-factor token, U2F registrations and WebAuthn credentials.
+// It returns the number of removed second factors.
+func DeleteAllTwoFactorByUID(uid int64) (int64, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, bean := range []interface{}{new(TwoFactor), new(U2FRegistration), new(WebAuthnCredential)} {
+		col := "user_id"
+		if _, ok := bean.(*TwoFactor); ok {
+			col = "uid"
+		}
+		cnt, err := sess.Where(col+"=?", uid).Delete(bean)
+		if err != nil {
+			return 0, err
+		}
+		total += cnt
+	}
+
+	return total, sess.Commit()
+}
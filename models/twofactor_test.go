@@ -0,0 +1,30 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteAllTwoFactorByUID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	cnt, err := DeleteAllTwoFactorByUID(1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+	AssertNotExistsBean(t, &U2FRegistration{UserID: 1})
+	AssertNotExistsBean(t, &WebAuthnCredential{UserID: 1})
+
+	cnt, err = DeleteAllTwoFactorByUID(24)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+	AssertNotExistsBean(t, &TwoFactor{UID: 24})
+
+	cnt, err = DeleteAllTwoFactorByUID(24)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+}
@@ -74,6 +74,14 @@ func MainTest(m *testing.M, pathToGiteaRoot string) {
 
 	setting.RepoAvatar.Storage.Path = filepath.Join(setting.AppDataPath, "repo-avatars")
 
+	setting.Actions.Storage.Path = filepath.Join(setting.AppDataPath, "actions_artifacts")
+
+	setting.Artifacts.Storage.Path = filepath.Join(setting.AppDataPath, "artifacts")
+
+	setting.Packages.Storage.Path = filepath.Join(setting.AppDataPath, "packages")
+
+	setting.RepoArchive.Storage.Path = filepath.Join(setting.AppDataPath, "repo-archive")
+
 	if err = storage.Init(); err != nil {
 		fatalTestError("storage.Init: %v\n", err)
 	}
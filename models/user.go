@@ -21,6 +21,7 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"code.gitea.io/gitea/modules/auth/ldap"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/generate"
 	"code.gitea.io/gitea/modules/git"
@@ -114,6 +115,8 @@ type User struct {
 	// MustChangePassword is an attribute that determines if a user
 	// is to change his/her password after registration.
 	MustChangePassword bool `xorm:"NOT NULL DEFAULT false"`
+	// MustAcceptPolicies is true if the user has not accepted the current terms of service or privacy policy yet
+	MustAcceptPolicies bool `xorm:"NOT NULL DEFAULT false"`
 
 	LoginType   LoginType
 	LoginSource int64 `xorm:"NOT NULL DEFAULT 0"`
@@ -234,11 +237,16 @@ func (u *User) UpdateTheme(themeName string) error {
 // email address private, otherwise the primary email address.
 func (u *User) GetEmail() string {
 	if u.KeepEmailPrivate {
-		return fmt.Sprintf("%s@%s", u.LowerName, setting.Service.NoReplyAddress)
+		return u.NoReplyEmail()
 	}
 	return u.Email
 }
 
+// NoReplyEmail returns the no-reply email address standing for the email of the user when it is hidden
+func (u *User) NoReplyEmail() string {
+	return fmt.Sprintf("%s@%s", u.LowerName, setting.Service.NoReplyAddress)
+}
+
 // GetAllUsers returns a slice of all users found in DB.
 func GetAllUsers() ([]*User, error) {
 	users := make([]*User, 0)
@@ -801,6 +809,7 @@ var (
 		"less",
 		"login",
 		"manifest.json",
+		"media-proxy",
 		"metrics",
 		"milestones",
 		"new",
@@ -815,6 +824,7 @@ var (
 		"stars",
 		"template",
 		"user",
+		"v2",
 	}, public.KnownPublicEntries...)
 
 	reservedUserPatterns = []string{"*.keys", "*.gpg"}
@@ -913,6 +923,11 @@ func CreateUser(u *User) (err error) {
 	u.EmailNotificationsPreference = setting.Admin.DefaultEmailNotification
 	u.MaxRepoCreation = -1
 	u.Theme = setting.UI.DefaultTheme
+	if u.Type == UserTypeIndividual {
+		if u.MustAcceptPolicies, err = hasPolicyDocuments(sess); err != nil {
+			return err
+		}
+	}
 
 	if _, err = sess.Insert(u); err != nil {
 		return err
@@ -1169,14 +1184,27 @@ func deleteUser(e Engine, u *User) error {
 		&IssueUser{UID: u.ID},
 		&EmailAddress{UID: u.ID},
 		&UserOpenID{UID: u.ID},
+		&UserCodeSearch{UserID: u.ID},
+		&UserFilter{UserID: u.ID},
+		&UserEmailPreference{UID: u.ID},
+		&APIUsage{UserID: u.ID},
+		&ProfileFieldValue{UserID: u.ID},
+		&PolicyConsent{UserID: u.ID},
 		&Reaction{UserID: u.ID},
 		&TeamUser{UID: u.ID},
 		&Collaboration{UserID: u.ID},
 		&Stopwatch{UserID: u.ID},
+		&IssueCollaborator{UserID: u.ID},
+		&WebAuthnCredential{UserID: u.ID},
+		&SCIMGroupMember{UserID: u.ID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
 
+	if err = deletePackagesByOwnerID(e, u.ID); err != nil {
+		return fmt.Errorf("deletePackagesByOwnerID: %v", err)
+	}
+
 	if setting.Service.UserDeleteWithCommentsMaxTime != 0 &&
 		u.CreatedUnix.AsTime().Add(setting.Service.UserDeleteWithCommentsMaxTime).After(time.Now()) {
 
@@ -1403,6 +1431,7 @@ func GetMaileableUsersByIDs(ids []int64, isMention bool) ([]*User, error) {
 			And("`prohibit_login` = ?", false).
 			And("`is_active` = ?", true).
 			And("`email_notifications_preference` IN ( ?, ?)", EmailNotificationsEnabled, EmailNotificationsOnMention).
+			And(emailEventEnabledCond(EmailEventMention)).
 			Find(&ous)
 	}
 
@@ -1414,6 +1443,22 @@ func GetMaileableUsersByIDs(ids []int64, isMention bool) ([]*User, error) {
 		Find(&ous)
 }
 
+// GetMaileableUsersByIDsForEvent gets users from ids, but only if they can receive mails and did not disable the
+// email notifications of an event
+func GetMaileableUsersByIDsForEvent(ids []int64, event EmailEvent) ([]*User, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	ous := make([]*User, 0, len(ids))
+	return ous, x.In("id", ids).
+		Where("`type` = ?", UserTypeIndividual).
+		And("`prohibit_login` = ?", false).
+		And("`is_active` = ?", true).
+		And("`email_notifications_preference` = ?", EmailNotificationsEnabled).
+		And(emailEventEnabledCond(event)).
+		Find(&ous)
+}
+
 // GetUserNamesByIDs returns usernames for all resolved users from a list of Ids.
 func GetUserNamesByIDs(ids []int64) ([]string, error) {
 	unames := make([]string, 0, len(ids))
@@ -1437,6 +1482,18 @@ func GetUsersByIDs(ids []int64) (UserList, error) {
 	return ous, err
 }
 
+// GetActiveAdmins returns the site administrators who can sign in
+func GetActiveAdmins() ([]*User, error) {
+	admins := make([]*User, 0, 5)
+	return admins, x.
+		Where("is_admin = ?", true).
+		And("is_active = ?", true).
+		And("prohibit_login = ?", false).
+		And("type = ?", UserTypeIndividual).
+		Asc("id").
+		Find(&admins)
+}
+
 // GetUserIDsByNames returns a slice of ids corresponds to names.
 func GetUserIDsByNames(names []string, ignoreNonExistent bool) ([]int64, error) {
 	ids := make([]int64, 0, len(names))
@@ -1567,6 +1624,8 @@ type SearchUserOptions struct {
 	Actor         *User // The user doing the search
 	IsActive      util.OptionalBool
 	SearchByEmail bool // Search by email as well as username/full name
+	// SearchByProfileFields searches by the values of the profile fields as well, whatever their visibility
+	SearchByProfileFields bool
 }
 
 func (opts *SearchUserOptions) toConds() builder.Cond {
@@ -1580,6 +1639,10 @@ func (opts *SearchUserOptions) toConds() builder.Cond {
 		if opts.SearchByEmail {
 			keywordCond = keywordCond.Or(builder.Like{"LOWER(email)", lowerKeyword})
 		}
+		if opts.SearchByProfileFields {
+			keywordCond = keywordCond.Or(builder.In("id",
+				builder.Select("user_id").From("profile_field_value").Where(builder.Like{"LOWER(value)", lowerKeyword})))
+		}
 
 		cond = cond.And(keywordCond)
 	}
@@ -1948,11 +2011,7 @@ func SyncExternalUsers(ctx context.Context, updateExisting bool) error {
 					}
 
 					// Check if user data has changed
-					if (len(s.LDAP().AdminFilter) > 0 && usr.IsAdmin != su.IsAdmin) ||
-						(len(s.LDAP().RestrictedFilter) > 0 && usr.IsRestricted != su.IsRestricted) ||
-						!strings.EqualFold(usr.Email, su.Mail) ||
-						usr.FullName != fullName ||
-						!usr.IsActive {
+					if ldapUserNeedsUpdate(s, usr, su, fullName) {
 
 						log.Trace("SyncExternalUsers[%s]: Updating user %s", s.Name, usr.Name)
 
@@ -2017,6 +2076,91 @@ func SyncExternalUsers(ctx context.Context, updateExisting bool) error {
 	return nil
 }
 
+// ldapUserNeedsUpdate returns true if the synchronization of an LDAP source changes the user found in it
+func ldapUserNeedsUpdate(s *LoginSource, usr *User, su *ldap.SearchResult, fullName string) bool {
+	return (len(s.LDAP().AdminFilter) > 0 && usr.IsAdmin != su.IsAdmin) ||
+		(len(s.LDAP().RestrictedFilter) > 0 && usr.IsRestricted != su.IsRestricted) ||
+		!strings.EqualFold(usr.Email, su.Mail) ||
+		usr.FullName != fullName ||
+		!usr.IsActive
+}
+
+// LDAPSyncPreview lists the names of the users the synchronization of an LDAP source would create, update and
+// deactivate, without their SSH keys and teams
+type LDAPSyncPreview struct {
+	Created     []string
+	Updated     []string
+	Deactivated []string
+	// Refused is true if the search found no entries and the source does not allow to deactivate all the users, in
+	// which case the synchronization changes nothing
+	Refused bool
+}
+
+// PreviewSyncExternalUsers searches an LDAP source and returns the changes SyncExternalUsers would make to its users,
+// without making them
+func PreviewSyncExternalUsers(s *LoginSource, updateExisting bool) (*LDAPSyncPreview, error) {
+	if !s.IsLDAP() {
+		return nil, fmt.Errorf("login source %s is not an LDAP source", s.Name)
+	}
+
+	var users []*User
+	if err := x.Where("login_type = ?", s.Type).
+		And("login_source = ?", s.ID).
+		Find(&users); err != nil {
+		return nil, err
+	}
+
+	sr, err := s.LDAP().SearchEntries()
+	if err != nil {
+		return nil, err
+	}
+	return previewLDAPSync(s, users, sr, updateExisting), nil
+}
+
+func previewLDAPSync(s *LoginSource, users []*User, sr []*ldap.SearchResult, updateExisting bool) *LDAPSyncPreview {
+	preview := &LDAPSyncPreview{}
+	if len(sr) == 0 && !s.LDAP().AllowDeactivateAll {
+		preview.Refused = true
+		return preview
+	}
+
+	usersByName := make(map[string]*User, len(users))
+	for _, usr := range users {
+		usersByName[usr.LowerName] = usr
+	}
+	found := make(map[int64]bool, len(sr))
+	for _, su := range sr {
+		if len(su.Username) == 0 {
+			continue
+		}
+		usr, ok := usersByName[strings.ToLower(su.Username)]
+		if !ok {
+			preview.Created = append(preview.Created, su.Username)
+			continue
+		}
+		if !updateExisting {
+			continue
+		}
+		found[usr.ID] = true
+
+		if len(su.Mail) == 0 {
+			su.Mail = fmt.Sprintf("%s@localhost", su.Username)
+		}
+		if ldapUserNeedsUpdate(s, usr, su, composeFullName(su.Name, su.Surname, su.Username)) {
+			preview.Updated = append(preview.Updated, usr.Name)
+		}
+	}
+
+	if updateExisting {
+		for _, usr := range users {
+			if !found[usr.ID] && usr.IsActive {
+				preview.Deactivated = append(preview.Deactivated, usr.Name)
+			}
+		}
+	}
+	return preview
+}
+
 // IterateUser iterate users
 func IterateUser(f func(user *User) error) error {
 	var start int
@@ -0,0 +1,94 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"net/url"
+
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// MaxUserCodeSearches is the number of recent code searches kept for each user
+const MaxUserCodeSearches = 10
+
+// UserCodeSearch represents a recent code search of a user
+type UserCodeSearch struct {
+	ID      int64  `xorm:"pk autoincr"`
+	UserID  int64  `xorm:"INDEX NOT NULL"`
+	Keyword string `xorm:"NOT NULL"`
+	IsMatch bool   `xorm:"NOT NULL DEFAULT false"`
+	// Name of the owner the search is restricted to
+	Org string
+	// Comma separated full names of the repositories the search is restricted to
+	Repos string `xorm:"VARCHAR(1024)"`
+
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX"`
+}
+
+// Link returns the link of the code search
+func (s *UserCodeSearch) Link() string {
+	query := url.Values{}
+	query.Set("q", s.Keyword)
+	if s.IsMatch {
+		query.Set("t", "match")
+	}
+	if len(s.Org) > 0 {
+		query.Set("org", s.Org)
+	}
+	if len(s.Repos) > 0 {
+		query.Set("repos", s.Repos)
+	}
+	return "/explore/code?" + query.Encode()
+}
+
+// AddUserCodeSearch records a code search of a user as the most recent one, and forgets the oldest searches
+// beyond MaxUserCodeSearches
+func AddUserCodeSearch(s *UserCodeSearch) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	s.UpdatedUnix = timeutil.TimeStampNow()
+	existing := &UserCodeSearch{}
+	has, err := sess.Where("user_id = ? AND keyword = ? AND is_match = ? AND org = ? AND repos = ?",
+		s.UserID, s.Keyword, s.IsMatch, s.Org, s.Repos).Get(existing)
+	if err != nil {
+		return err
+	}
+	if has {
+		s.ID = existing.ID
+		if _, err := sess.ID(s.ID).Cols("updated_unix").Update(s); err != nil {
+			return err
+		}
+	} else if _, err := sess.Insert(s); err != nil {
+		return err
+	}
+
+	var ids []int64
+	if err := sess.Table("user_code_search").Cols("id").Where("user_id = ?", s.UserID).
+		Desc("updated_unix", "id").Limit(1000, MaxUserCodeSearches).Find(&ids); err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		if _, err := sess.In("id", ids).Delete(new(UserCodeSearch)); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// GetUserCodeSearches returns the recent code searches of a user, the most recent first
+func GetUserCodeSearches(userID int64) ([]*UserCodeSearch, error) {
+	searches := make([]*UserCodeSearch, 0, MaxUserCodeSearches)
+	return searches, x.Where("user_id = ?", userID).Desc("updated_unix", "id").Find(&searches)
+}
+
+// DeleteUserCodeSearches forgets the recent code searches of a user
+func DeleteUserCodeSearches(userID int64) error {
+	_, err := x.Delete(&UserCodeSearch{UserID: userID})
+	return err
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserCodeSearches(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, AddUserCodeSearch(&UserCodeSearch{UserID: 2, Keyword: "first", Org: "user3"}))
+	assert.NoError(t, AddUserCodeSearch(&UserCodeSearch{UserID: 2, Keyword: "second", IsMatch: true, Repos: "user2/repo1"}))
+	assert.NoError(t, AddUserCodeSearch(&UserCodeSearch{UserID: 4, Keyword: "other"}))
+
+	searches, err := GetUserCodeSearches(2)
+	assert.NoError(t, err)
+	if assert.Len(t, searches, 2) {
+		assert.Equal(t, "second", searches[0].Keyword)
+		assert.Equal(t, "/explore/code?q=second&repos=user2%2Frepo1&t=match", searches[0].Link())
+		assert.Equal(t, "first", searches[1].Keyword)
+		assert.Equal(t, "/explore/code?org=user3&q=first", searches[1].Link())
+	}
+
+	// searching again does not duplicate the search
+	assert.NoError(t, AddUserCodeSearch(&UserCodeSearch{UserID: 2, Keyword: "first", Org: "user3"}))
+	AssertCount(t, &UserCodeSearch{UserID: 2}, 2)
+
+	// the oldest searches are forgotten
+	for i := 0; i < MaxUserCodeSearches; i++ {
+		assert.NoError(t, AddUserCodeSearch(&UserCodeSearch{UserID: 2, Keyword: fmt.Sprintf("keyword %d", i)}))
+	}
+	searches, err = GetUserCodeSearches(2)
+	assert.NoError(t, err)
+	assert.Len(t, searches, MaxUserCodeSearches)
+	AssertNotExistsBean(t, &UserCodeSearch{UserID: 2, Keyword: "first"})
+	AssertExistsAndLoadBean(t, &UserCodeSearch{UserID: 4, Keyword: "other"})
+
+	assert.NoError(t, DeleteUserCodeSearches(2))
+	AssertCount(t, &UserCodeSearch{UserID: 2}, 0)
+	AssertCount(t, &UserCodeSearch{UserID: 4}, 1)
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// UserContributions summarizes the contributions of a user to a set of repositories over a time range
+type UserContributions struct {
+	Commits            int64
+	PullRequestsOpened int64
+	PullRequestsMerged int64
+	Reviews            int64
+	IssuesOpened       int64
+}
+
+// timeRangeCond returns the condition of column being between since and before, which are ignored if zero
+func timeRangeCond(column string, since, before timeutil.TimeStamp) builder.Cond {
+	cond := builder.NewCond()
+	if since > 0 {
+		cond = cond.And(builder.Gte{column: since})
+	}
+	if before > 0 {
+		cond = cond.And(builder.Lt{column: before})
+	}
+	return cond
+}
+
+// CountUserContributions counts the issues and pull requests a user opened in the given repositories between since
+// and before, which are ignored if zero, the ones of its pull requests which were merged and its published reviews
+// during that time. The commits are not counted since they are read from the git repositories.
+func CountUserContributions(userID int64, repoIDs []int64, since, before timeutil.TimeStamp) (*UserContributions, error) {
+	contributions := &UserContributions{}
+	if len(repoIDs) == 0 {
+		return contributions, nil
+	}
+
+	issueCond := builder.Eq{"`issue`.poster_id": userID}.And(builder.In("`issue`.repo_id", repoIDs))
+	var err error
+	if contributions.IssuesOpened, err = x.Where(issueCond).
+		And(builder.Eq{"`issue`.is_pull": false}).
+		And(timeRangeCond("`issue`.created_unix", since, before)).
+		Count(new(Issue)); err != nil {
+		return nil, fmt.Errorf("count issues: %v", err)
+	}
+	if contributions.PullRequestsOpened, err = x.Where(issueCond).
+		And(builder.Eq{"`issue`.is_pull": true}).
+		And(timeRangeCond("`issue`.created_unix", since, before)).
+		Count(new(Issue)); err != nil {
+		return nil, fmt.Errorf("count pull requests: %v", err)
+	}
+	if contributions.PullRequestsMerged, err = x.Table("pull_request").
+		Join("INNER", "issue", "`issue`.id = `pull_request`.issue_id").
+		Where(issueCond).
+		And(builder.Eq{"`pull_request`.has_merged": true}).
+		And(timeRangeCond("`pull_request`.merged_unix", since, before)).
+		Count(new(PullRequest)); err != nil {
+		return nil, fmt.Errorf("count merged pull requests: %v", err)
+	}
+	if contributions.Reviews, err = x.Table("review").
+		Join("INNER", "issue", "`issue`.id = `review`.issue_id").
+		Where(builder.Eq{"`review`.reviewer_id": userID}).
+		And(builder.In("`issue`.repo_id", repoIDs)).
+		And(builder.In("`review`.type", ReviewTypeApprove, ReviewTypeComment, ReviewTypeReject)).
+		And(timeRangeCond("`review`.created_unix", since, before)).
+		Count(new(Review)); err != nil {
+		return nil, fmt.Errorf("count reviews: %v", err)
+	}
+	return contributions, nil
+}
+
+// FindOrgRepoIDsAccessibleBy returns the IDs of the repositories of an organization which a user can access, in
+// ascending order
+func FindOrgRepoIDsAccessibleBy(orgID int64, user *User) ([]int64, error) {
+	var cond builder.Cond = builder.Eq{"`repository`.owner_id": orgID}
+	if user == nil || !user.IsAdmin {
+		cond = cond.And(accessibleRepositoryCondition(user))
+	}
+	repoIDs := make([]int64, 0, 10)
+	return repoIDs, x.Table("repository").Cols("id").Where(cond).Asc("id").Find(&repoIDs)
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountUserContributions(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	contributions, err := CountUserContributions(1, []int64{1}, 0, 0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, &UserContributions{
+		PullRequestsOpened: 3,
+		PullRequestsMerged: 1,
+		Reviews:            2,
+		IssuesOpened:       1,
+	}, contributions)
+
+	contributions, err = CountUserContributions(1, []int64{1}, 946684815, 0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, &UserContributions{PullRequestsOpened: 2}, contributions)
+
+	contributions, err = CountUserContributions(1, []int64{1}, 0, 946684815)
+	assert.NoError(t, err)
+	assert.EqualValues(t, &UserContributions{PullRequestsOpened: 1, Reviews: 2, IssuesOpened: 1}, contributions)
+
+	contributions, err = CountUserContributions(1, nil, 0, 0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, &UserContributions{}, contributions)
+}
+
+func TestFindOrgRepoIDsAccessibleBy(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repoIDs, err := FindOrgRepoIDsAccessibleBy(3, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{32}, repoIDs)
+
+	owner := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repoIDs, err = FindOrgRepoIDsAccessibleBy(3, owner)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 5, 32}, repoIDs)
+
+	admin := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	repoIDs, err = FindOrgRepoIDsAccessibleBy(3, admin)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 5, 32}, repoIDs)
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"xorm.io/builder"
+)
+
+// EmailEvent is a kind of event a user can be notified of by email
+type EmailEvent string
+
+// Enumerate all the email events a user can choose to be notified of
+const (
+	EmailEventMention       EmailEvent = "mention"
+	EmailEventReviewRequest EmailEvent = "review_request"
+	EmailEventCIFailure     EmailEvent = "ci_failure"
+	EmailEventRelease       EmailEvent = "release"
+	EmailEventDueDate       EmailEvent = "due_date"
+)
+
+// EmailEvents are all the email events, in the order they are shown in the settings
+var EmailEvents = []EmailEvent{EmailEventMention, EmailEventReviewRequest, EmailEventCIFailure, EmailEventRelease, EmailEventDueDate}
+
+// IsValid returns true if the event is known
+func (e EmailEvent) IsValid() bool {
+	for _, event := range EmailEvents {
+		if event == e {
+			return true
+		}
+	}
+	return false
+}
+
+// UserEmailPreference is the choice of a user to be notified or not of an email event. The events without a preference
+// are notified, as long as the email notifications of the user are enabled.
+type UserEmailPreference struct {
+	ID      int64      `xorm:"pk autoincr"`
+	UID     int64      `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Event   EmailEvent `xorm:"VARCHAR(20) UNIQUE(s) NOT NULL"`
+	Enabled bool       `xorm:"NOT NULL DEFAULT true"`
+}
+
+// GetEmailPreferences returns whether the user is notified by email of each event, regardless of the email
+// notifications preference of the user
+func (u *User) GetEmailPreferences() (map[EmailEvent]bool, error) {
+	prefs := make([]*UserEmailPreference, 0, len(EmailEvents))
+	if err := x.Where("uid = ?", u.ID).Find(&prefs); err != nil {
+		return nil, err
+	}
+	enabled := make(map[EmailEvent]bool, len(EmailEvents))
+	for _, event := range EmailEvents {
+		enabled[event] = true
+	}
+	for _, pref := range prefs {
+		if event := pref.Event; event.IsValid() {
+			enabled[event] = pref.Enabled
+		}
+	}
+	return enabled, nil
+}
+
+// SetEmailPreferences saves whether the user is notified by email of events, the unknown events being ignored
+func (u *User) SetEmailPreferences(enabled map[EmailEvent]bool) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	for event, isEnabled := range enabled {
+		if !event.IsValid() {
+			continue
+		}
+		pref := &UserEmailPreference{UID: u.ID, Event: event}
+		has, err := sess.Get(pref)
+		if err != nil {
+			return err
+		}
+		pref.Enabled = isEnabled
+		if has {
+			_, err = sess.ID(pref.ID).Cols("enabled").Update(pref)
+		} else {
+			_, err = sess.Insert(pref)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// IsEmailEventEnabled returns true if the user wants to be notified by email of an event. The email notifications
+// preference of the user must be checked too.
+func (u *User) IsEmailEventEnabled(event EmailEvent) (bool, error) {
+	has, err := x.Where(builder.Eq{"uid": u.ID, "event": event, "enabled": false}).Exist(new(UserEmailPreference))
+	return !has, err
+}
+
+// emailEventEnabledCond returns the condition on the users who did not disable the email notifications of an event
+func emailEventEnabledCond(event EmailEvent) builder.Cond {
+	return builder.NotIn("`user`.id", builder.Select("uid").From("user_email_preference").
+		Where(builder.Eq{"event": event, "enabled": false}))
+}
@@ -0,0 +1,53 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserEmailPreferences(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	prefs, err := user.GetEmailPreferences()
+	assert.NoError(t, err)
+	assert.Len(t, prefs, len(EmailEvents))
+	for _, event := range EmailEvents {
+		assert.True(t, prefs[event])
+	}
+
+	assert.NoError(t, user.SetEmailPreferences(map[EmailEvent]bool{
+		EmailEventMention: false,
+		EmailEventRelease: true,
+		"unknown":         false,
+	}))
+	AssertNotExistsBean(t, &UserEmailPreference{UID: user.ID, Event: "unknown"})
+	prefs, err = user.GetEmailPreferences()
+	assert.NoError(t, err)
+	assert.False(t, prefs[EmailEventMention])
+	assert.True(t, prefs[EmailEventRelease])
+	enabled, err := user.IsEmailEventEnabled(EmailEventMention)
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+
+	// the users who disabled an event are not mailed for it
+	users, err := GetMaileableUsersByIDs([]int64{1, 2}, true)
+	assert.NoError(t, err)
+	if assert.Len(t, users, 1) {
+		assert.EqualValues(t, 1, users[0].ID)
+	}
+	users, err = GetMaileableUsersByIDsForEvent([]int64{1, 2}, EmailEventRelease)
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+
+	assert.NoError(t, user.SetEmailPreferences(map[EmailEvent]bool{EmailEventMention: true}))
+	enabled, err = user.IsEmailEventEnabled(EmailEventMention)
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+	assert.EqualValues(t, 2, GetCount(t, &UserEmailPreference{UID: user.ID}))
+}
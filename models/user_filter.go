@@ -0,0 +1,341 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// MaxUserFilters is the number of filters each user can save
+const MaxUserFilters = 50
+
+// UserFilterAnyRepo matches the filters of all the repositories and of the dashboard in FindUserFiltersOptions
+const UserFilterAnyRepo int64 = -1
+
+// UserFilter represents a named combination of filters of an issue or pull request list, saved by a user
+type UserFilter struct {
+	ID     int64 `xorm:"pk autoincr"`
+	UserID int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	// RepoID is the repository whose list is filtered, 0 for the list of the dashboard
+	RepoID int64  `xorm:"UNIQUE(s) INDEX NOT NULL DEFAULT 0"`
+	IsPull bool   `xorm:"UNIQUE(s) NOT NULL DEFAULT false"`
+	Name   string `xorm:"UNIQUE(s) NOT NULL"`
+
+	ViewType string
+	SortType string
+	// State is either "open" or "closed"
+	State   string
+	Keyword string
+	// Comma separated IDs of the labels, negative for the excluded ones
+	Labels      string `xorm:"VARCHAR(1024)"`
+	MilestoneID int64  `xorm:"NOT NULL DEFAULT 0"`
+	AssigneeID  int64  `xorm:"NOT NULL DEFAULT 0"`
+	// Comma separated IDs of the repositories the list of the dashboard is restricted to
+	Repos string `xorm:"VARCHAR(1024)"`
+
+	Repo *Repository `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// LabelIDs returns the IDs of the labels of the filter
+func (f *UserFilter) LabelIDs() []int64 {
+	return splitUserFilterIDs(f.Labels)
+}
+
+// RepoIDs returns the IDs of the repositories the list of the dashboard is restricted to
+func (f *UserFilter) RepoIDs() []int64 {
+	return splitUserFilterIDs(f.Repos)
+}
+
+func splitUserFilterIDs(s string) []int64 {
+	ids := make([]int64, 0, 5)
+	for _, id := range strings.Split(s, ",") {
+		if i, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64); err == nil && i != 0 {
+			ids = append(ids, i)
+		}
+	}
+	return ids
+}
+
+// JoinUserFilterIDs returns the comma separated IDs stored in a user filter
+func JoinUserFilterIDs(ids []int64) string {
+	strs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != 0 {
+			strs = append(strs, fmt.Sprint(id))
+		}
+	}
+	return strings.Join(strs, ",")
+}
+
+// Query returns the query parameters of the list filtered by the filter
+func (f *UserFilter) Query() url.Values {
+	query := url.Values{}
+	if len(f.ViewType) > 0 {
+		query.Set("type", f.ViewType)
+	}
+	if len(f.SortType) > 0 {
+		query.Set("sort", f.SortType)
+	}
+	if len(f.State) > 0 {
+		query.Set("state", f.State)
+	}
+	if len(f.Keyword) > 0 {
+		query.Set("q", f.Keyword)
+	}
+	if len(f.Labels) > 0 {
+		query.Set("labels", f.Labels)
+	}
+	if f.MilestoneID > 0 {
+		query.Set("milestone", fmt.Sprint(f.MilestoneID))
+	}
+	if f.AssigneeID > 0 {
+		query.Set("assignee", fmt.Sprint(f.AssigneeID))
+	}
+	if f.RepoID == 0 && len(f.Repos) > 0 {
+		query.Set("repos", "["+f.Repos+"]")
+	}
+	return query
+}
+
+// Link returns the link of the list filtered by the filter. The repository of the filter must be loaded.
+func (f *UserFilter) Link() string {
+	list := "issues"
+	if f.IsPull {
+		list = "pulls"
+	}
+	link := "/" + list
+	if f.RepoID > 0 && f.Repo != nil {
+		link = "/" + f.Repo.FullName() + link
+	}
+	if query := f.Query().Encode(); len(query) > 0 {
+		link += "?" + query
+	}
+	return link
+}
+
+// LoadRepo loads the repository of the filter, if it is not a filter of the dashboard
+func (f *UserFilter) LoadRepo() (err error) {
+	if f.RepoID > 0 && f.Repo == nil {
+		f.Repo, err = GetRepositoryByID(f.RepoID)
+	}
+	return err
+}
+
+// UnitType returns the unit of the list filtered by the filter
+func (f *UserFilter) UnitType() UnitType {
+	if f.IsPull {
+		return UnitTypePullRequests
+	}
+	return UnitTypeIssues
+}
+
+// IsReadableBy returns true if the list filtered by the filter can be read by the user. The repository of the filter
+// must be loaded.
+func (f *UserFilter) IsReadableBy(u *User) (bool, error) {
+	if f.RepoID == 0 {
+		return true, nil
+	} else if f.Repo == nil {
+		return false, nil
+	}
+	perm, err := GetUserRepoPermission(f.Repo, u)
+	if err != nil {
+		return false, err
+	}
+	return perm.CanRead(f.UnitType()), nil
+}
+
+// UserFilterList is a list of user filters
+type UserFilterList []*UserFilter
+
+// Readable returns the filters of the list whose lists can still be read by the user
+func (filters UserFilterList) Readable(u *User) (UserFilterList, error) {
+	readable := make(UserFilterList, 0, len(filters))
+	for _, f := range filters {
+		ok, err := f.IsReadableBy(u)
+		if err != nil {
+			return nil, err
+		} else if ok {
+			readable = append(readable, f)
+		}
+	}
+	return readable, nil
+}
+
+// ErrUserFilterNotExist represents a "UserFilterNotExist" kind of error.
+type ErrUserFilterNotExist struct {
+	ID int64
+}
+
+// IsErrUserFilterNotExist checks if an error is a ErrUserFilterNotExist.
+func IsErrUserFilterNotExist(err error) bool {
+	_, ok := err.(ErrUserFilterNotExist)
+	return ok
+}
+
+func (err ErrUserFilterNotExist) Error() string {
+	return fmt.Sprintf("user filter does not exist [id: %d]", err.ID)
+}
+
+// ErrUserFilterAlreadyExist represents a "UserFilterAlreadyExist" kind of error.
+type ErrUserFilterAlreadyExist struct {
+	Name string
+}
+
+// IsErrUserFilterAlreadyExist checks if an error is a ErrUserFilterAlreadyExist.
+func IsErrUserFilterAlreadyExist(err error) bool {
+	_, ok := err.(ErrUserFilterAlreadyExist)
+	return ok
+}
+
+func (err ErrUserFilterAlreadyExist) Error() string {
+	return fmt.Sprintf("user filter already exists [name: %s]", err.Name)
+}
+
+// ErrUserFiltersLimitReached represents a "UserFiltersLimitReached" kind of error.
+type ErrUserFiltersLimitReached struct {
+	Limit int
+}
+
+// IsErrUserFiltersLimitReached checks if an error is a ErrUserFiltersLimitReached.
+func IsErrUserFiltersLimitReached(err error) bool {
+	_, ok := err.(ErrUserFiltersLimitReached)
+	return ok
+}
+
+func (err ErrUserFiltersLimitReached) Error() string {
+	return fmt.Sprintf("user has reached the limit of saved filters [limit: %d]", err.Limit)
+}
+
+// SaveUserFilter saves a filter of a user, replacing the one of the same list with the same name
+func SaveUserFilter(f *UserFilter) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	f.Name = strings.TrimSpace(f.Name)
+	f.Labels = JoinUserFilterIDs(f.LabelIDs())
+	f.Repos = JoinUserFilterIDs(f.RepoIDs())
+	existing := &UserFilter{}
+	has, err := sess.Where("user_id = ? AND repo_id = ? AND is_pull = ? AND name = ?",
+		f.UserID, f.RepoID, f.IsPull, f.Name).Get(existing)
+	if err != nil {
+		return err
+	}
+	if has {
+		f.ID = existing.ID
+		if _, err := sess.ID(f.ID).AllCols().Omit("created_unix").Update(f); err != nil {
+			return err
+		}
+		return sess.Commit()
+	}
+
+	count, err := sess.Where("user_id = ?", f.UserID).Count(new(UserFilter))
+	if err != nil {
+		return err
+	} else if count >= MaxUserFilters {
+		return ErrUserFiltersLimitReached{MaxUserFilters}
+	}
+	if _, err := sess.Insert(f); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// CreateUserFilter saves a new filter of a user, failing if the list already has a filter with the same name
+func CreateUserFilter(f *UserFilter) error {
+	f.Name = strings.TrimSpace(f.Name)
+	has, err := x.Where("user_id = ? AND repo_id = ? AND is_pull = ? AND name = ?",
+		f.UserID, f.RepoID, f.IsPull, f.Name).Exist(new(UserFilter))
+	if err != nil {
+		return err
+	} else if has {
+		return ErrUserFilterAlreadyExist{f.Name}
+	}
+	return SaveUserFilter(f)
+}
+
+// GetUserFilterByID returns a filter of a user
+func GetUserFilterByID(userID, id int64) (*UserFilter, error) {
+	f := new(UserFilter)
+	has, err := x.ID(id).And("user_id = ?", userID).Get(f)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrUserFilterNotExist{id}
+	}
+	return f, nil
+}
+
+// FindUserFiltersOptions represents the options to find the filters of a user
+type FindUserFiltersOptions struct {
+	ListOptions
+	UserID int64
+	// RepoID is the repository of the filters, 0 for the ones of the dashboard or UserFilterAnyRepo for all of them
+	RepoID int64
+	IsPull util.OptionalBool
+}
+
+func (opts *FindUserFiltersOptions) toConds() builder.Cond {
+	cond := builder.NewCond().And(builder.Eq{"user_id": opts.UserID})
+	if opts.RepoID != UserFilterAnyRepo {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	if !opts.IsPull.IsNone() {
+		cond = cond.And(builder.Eq{"is_pull": opts.IsPull.IsTrue()})
+	}
+	return cond
+}
+
+// FindUserFilters returns the filters of a user, ordered by name, with their repositories
+func FindUserFilters(opts *FindUserFiltersOptions) (UserFilterList, int64, error) {
+	sess := x.Where(opts.toConds())
+	if opts.Page > 0 {
+		sess = opts.setSessionPagination(sess)
+	}
+	filters := make(UserFilterList, 0, 10)
+	count, err := sess.Asc("name", "id").FindAndCount(&filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	repoIDs := make([]int64, 0, len(filters))
+	for _, f := range filters {
+		if f.RepoID > 0 {
+			repoIDs = append(repoIDs, f.RepoID)
+		}
+	}
+	repos := make(map[int64]*Repository, len(repoIDs))
+	if err := x.In("id", repoIDs).Find(&repos); err != nil {
+		return nil, 0, err
+	}
+	for _, f := range filters {
+		f.Repo = repos[f.RepoID]
+	}
+	return filters, count, nil
+}
+
+// DeleteUserFilter deletes a filter of a user
+func DeleteUserFilter(userID, id int64) error {
+	deleted, err := x.ID(id).And("user_id = ?", userID).Delete(new(UserFilter))
+	if err != nil {
+		return err
+	} else if deleted == 0 {
+		return ErrUserFilterNotExist{id}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserFilters(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	bugs := &UserFilter{UserID: 2, RepoID: 1, Name: " Bugs ", State: "open", Labels: "1, -2,", MilestoneID: 1, AssigneeID: 2, SortType: "oldest"}
+	assert.NoError(t, CreateUserFilter(bugs))
+	assert.Equal(t, "Bugs", bugs.Name)
+	assert.Equal(t, "1,-2", bugs.Labels)
+	assert.Equal(t, []int64{1, -2}, bugs.LabelIDs())
+	review := &UserFilter{UserID: 2, IsPull: true, Name: "To review", ViewType: "review_requested", Repos: JoinUserFilterIDs([]int64{1, 3})}
+	assert.NoError(t, CreateUserFilter(review))
+	assert.Equal(t, []int64{1, 3}, review.RepoIDs())
+	assert.NoError(t, CreateUserFilter(&UserFilter{UserID: 4, Name: "Bugs"}))
+
+	// the name of a filter is unique in its list
+	assert.True(t, IsErrUserFilterAlreadyExist(CreateUserFilter(&UserFilter{UserID: 2, RepoID: 1, Name: "Bugs"})))
+	assert.NoError(t, CreateUserFilter(&UserFilter{UserID: 2, RepoID: 1, IsPull: true, Name: "Bugs"}))
+
+	// saving a filter with the same name replaces it
+	assert.NoError(t, SaveUserFilter(&UserFilter{UserID: 2, RepoID: 1, Name: "Bugs", State: "closed"}))
+	filter, err := GetUserFilterByID(2, bugs.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "closed", filter.State)
+	assert.Empty(t, filter.Labels)
+
+	filters, count, err := FindUserFilters(&FindUserFiltersOptions{UserID: 2, RepoID: UserFilterAnyRepo})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+	if assert.Len(t, filters, 3) {
+		assert.Equal(t, "/user2/repo1/issues?state=closed", filters[0].Link())
+		assert.Equal(t, "/user2/repo1/pulls", filters[1].Link())
+		assert.Equal(t, "/pulls?repos=%5B1%2C3%5D&type=review_requested", filters[2].Link())
+	}
+	filters, _, err = FindUserFilters(&FindUserFiltersOptions{UserID: 2, RepoID: 1, IsPull: util.OptionalBoolFalse})
+	assert.NoError(t, err)
+	assert.Len(t, filters, 1)
+	filters, _, err = FindUserFilters(&FindUserFiltersOptions{UserID: 2})
+	assert.NoError(t, err)
+	if assert.Len(t, filters, 1) {
+		assert.Equal(t, review.ID, filters[0].ID)
+	}
+
+	// the filters of the other users can not be read or deleted
+	_, err = GetUserFilterByID(4, bugs.ID)
+	assert.True(t, IsErrUserFilterNotExist(err))
+	assert.True(t, IsErrUserFilterNotExist(DeleteUserFilter(4, bugs.ID)))
+	assert.NoError(t, DeleteUserFilter(2, bugs.ID))
+	AssertNotExistsBean(t, &UserFilter{ID: bugs.ID})
+
+	// the number of filters of a user is limited
+	for i := int64(0); i < MaxUserFilters; i++ {
+		_ = SaveUserFilter(&UserFilter{UserID: 5, RepoID: i, Name: "filter"})
+	}
+	AssertCount(t, &UserFilter{UserID: 5}, MaxUserFilters)
+	assert.True(t, IsErrUserFiltersLimitReached(SaveUserFilter(&UserFilter{UserID: 5, Name: "other"})))
+}
+
+func TestUserFilterList_Readable(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// repo2 is a private repository of user2
+	assert.NoError(t, CreateUserFilter(&UserFilter{UserID: 4, RepoID: 1, Name: "public"}))
+	assert.NoError(t, CreateUserFilter(&UserFilter{UserID: 4, RepoID: 2, Name: "private"}))
+	assert.NoError(t, CreateUserFilter(&UserFilter{UserID: 4, Name: "dashboard"}))
+
+	filters, _, err := FindUserFilters(&FindUserFiltersOptions{UserID: 4, RepoID: UserFilterAnyRepo})
+	assert.NoError(t, err)
+	assert.Len(t, filters, 3)
+	filters, err = filters.Readable(AssertExistsAndLoadBean(t, &User{ID: 4}).(*User))
+	assert.NoError(t, err)
+	if assert.Len(t, filters, 2) {
+		assert.Equal(t, "dashboard", filters[0].Name)
+		assert.Equal(t, "public", filters[1].Name)
+	}
+}
@@ -0,0 +1,339 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/validation"
+
+	"xorm.io/builder"
+)
+
+// ProfileFieldType is the type of the values of a profile field
+type ProfileFieldType int
+
+// the types of the profile fields
+const (
+	ProfileFieldTypeText ProfileFieldType = iota // a line of text
+	ProfileFieldTypeLink                         // an http or https URL
+)
+
+// Name returns the name of the profile field type
+func (t ProfileFieldType) Name() string {
+	switch t {
+	case ProfileFieldTypeLink:
+		return "link"
+	default:
+		return "text"
+	}
+}
+
+// ProfileField is a field of the user profiles defined by the site administrators, like a department or a job title
+type ProfileField struct {
+	ID int64 `xorm:"pk autoincr"`
+	// Name identifies the field in the API
+	Name        string           `xorm:"UNIQUE NOT NULL"`
+	Label       string           `xorm:"NOT NULL"`
+	Description string           `xorm:"TEXT"`
+	Type        ProfileFieldType `xorm:"NOT NULL DEFAULT 0"`
+	// Visibility is who can see the values besides the user and the site administrators: everyone who can see the
+	// user if public, signed in users if limited and nobody if private
+	Visibility  structs.VisibleType `xorm:"NOT NULL DEFAULT 0"`
+	IsRequired  bool                `xorm:"NOT NULL DEFAULT false"`
+	SortOrder   int                 `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix timeutil.TimeStamp  `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp  `xorm:"INDEX updated"`
+}
+
+// ProfileFieldValue is the value a user filled in a profile field
+type ProfileFieldValue struct {
+	ID      int64  `xorm:"pk autoincr"`
+	FieldID int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	UserID  int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Value   string `xorm:"VARCHAR(1024) NOT NULL"`
+}
+
+// UserProfileField is a profile field with the value of a user
+type UserProfileField struct {
+	*ProfileField
+	Value string
+}
+
+// ErrProfileFieldNotExist represents a "ProfileFieldNotExist" kind of error.
+type ErrProfileFieldNotExist struct {
+	ID int64
+}
+
+// IsErrProfileFieldNotExist checks if an error is a ErrProfileFieldNotExist.
+func IsErrProfileFieldNotExist(err error) bool {
+	_, ok := err.(ErrProfileFieldNotExist)
+	return ok
+}
+
+func (err ErrProfileFieldNotExist) Error() string {
+	return fmt.Sprintf("profile field does not exist [id: %d]", err.ID)
+}
+
+// ErrProfileFieldAlreadyExist represents a "ProfileFieldAlreadyExist" kind of error.
+type ErrProfileFieldAlreadyExist struct {
+	Name string
+}
+
+// IsErrProfileFieldAlreadyExist checks if an error is a ErrProfileFieldAlreadyExist.
+func IsErrProfileFieldAlreadyExist(err error) bool {
+	_, ok := err.(ErrProfileFieldAlreadyExist)
+	return ok
+}
+
+func (err ErrProfileFieldAlreadyExist) Error() string {
+	return fmt.Sprintf("profile field already exists [name: %s]", err.Name)
+}
+
+// ErrInvalidProfileFieldValue represents a "InvalidProfileFieldValue" kind of error, the value of a required field
+// is empty or the one of a link field is not an http or https URL.
+type ErrInvalidProfileFieldValue struct {
+	Label string
+	Value string
+}
+
+// IsErrInvalidProfileFieldValue checks if an error is a ErrInvalidProfileFieldValue.
+func IsErrInvalidProfileFieldValue(err error) bool {
+	_, ok := err.(ErrInvalidProfileFieldValue)
+	return ok
+}
+
+func (err ErrInvalidProfileFieldValue) Error() string {
+	return fmt.Sprintf("invalid profile field value [label: %s, value: %s]", err.Label, err.Value)
+}
+
+// IsLink returns true if the values of the field are links
+func (f *ProfileField) IsLink() bool {
+	return f.Type == ProfileFieldTypeLink
+}
+
+// IsVisibleTo returns true if viewer, who is nil if not signed in, can see the value of the field of owner
+func (f *ProfileField) IsVisibleTo(owner, viewer *User) bool {
+	if viewer != nil && (viewer.IsAdmin || viewer.ID == owner.ID) {
+		return true
+	}
+	switch f.Visibility {
+	case structs.VisibleTypePublic:
+		return true
+	case structs.VisibleTypeLimited:
+		return viewer != nil
+	default:
+		return false
+	}
+}
+
+// ValidateValue checks a value of the field, which is trimmed
+func (f *ProfileField) ValidateValue(value string) error {
+	if value == "" {
+		if f.IsRequired {
+			return ErrInvalidProfileFieldValue{Label: f.Label, Value: value}
+		}
+		return nil
+	}
+	if f.IsLink() && !validation.IsValidURL(value) {
+		return ErrInvalidProfileFieldValue{Label: f.Label, Value: value}
+	}
+	return nil
+}
+
+// GetProfileFields returns the profile fields in display order
+func GetProfileFields() ([]*ProfileField, error) {
+	fields := make([]*ProfileField, 0, 5)
+	return fields, x.Asc("sort_order", "id").Find(&fields)
+}
+
+// GetProfileFieldByID returns a profile field
+func GetProfileFieldByID(id int64) (*ProfileField, error) {
+	field := new(ProfileField)
+	has, err := x.ID(id).Get(field)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrProfileFieldNotExist{ID: id}
+	}
+	return field, nil
+}
+
+func isProfileFieldNameTaken(e Engine, id int64, name string) (bool, error) {
+	return e.Where("id != ?", id).And("name = ?", name).Exist(new(ProfileField))
+}
+
+// CreateProfileField creates a profile field, whose name must be unique
+func CreateProfileField(field *ProfileField) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if taken, err := isProfileFieldNameTaken(sess, 0, field.Name); err != nil {
+		return err
+	} else if taken {
+		return ErrProfileFieldAlreadyExist{Name: field.Name}
+	}
+	if _, err := sess.Insert(field); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// UpdateProfileField updates a profile field, whose name must stay unique
+func UpdateProfileField(field *ProfileField) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if taken, err := isProfileFieldNameTaken(sess, field.ID, field.Name); err != nil {
+		return err
+	} else if taken {
+		return ErrProfileFieldAlreadyExist{Name: field.Name}
+	}
+	if _, err := sess.ID(field.ID).AllCols().Update(field); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// DeleteProfileField deletes a profile field and its values
+func DeleteProfileField(id int64) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if _, err := sess.Delete(&ProfileFieldValue{FieldID: id}); err != nil {
+		return err
+	}
+	if _, err := sess.ID(id).Delete(new(ProfileField)); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func getProfileFieldValues(e Engine, userIDs []int64) ([]*ProfileFieldValue, error) {
+	values := make([]*ProfileFieldValue, 0, len(userIDs))
+	return values, e.In("user_id", userIDs).Find(&values)
+}
+
+// GetUserProfileFields returns all the profile fields with the values of a user, which are empty if not filled in
+func GetUserProfileFields(userID int64) ([]*UserProfileField, error) {
+	fields, err := GetProfileFields()
+	if err != nil {
+		return nil, err
+	}
+	values, err := getProfileFieldValues(x, []int64{userID})
+	if err != nil {
+		return nil, err
+	}
+	valuesByField := make(map[int64]string, len(values))
+	for _, value := range values {
+		valuesByField[value.FieldID] = value.Value
+	}
+
+	userFields := make([]*UserProfileField, 0, len(fields))
+	for _, field := range fields {
+		userFields = append(userFields, &UserProfileField{ProfileField: field, Value: valuesByField[field.ID]})
+	}
+	return userFields, nil
+}
+
+// GetVisibleProfileFields returns the profile fields filled in by owner which viewer, who is nil if not signed in,
+// can see
+func GetVisibleProfileFields(owner, viewer *User) ([]*UserProfileField, error) {
+	fields, err := UserList{owner}.GetVisibleProfileFields(viewer)
+	if err != nil {
+		return nil, err
+	}
+	return fields[owner.ID], nil
+}
+
+// UpdateUserProfileFields sets the values of the profile fields of a user, by field ID. The values are trimmed and
+// the empty ones are removed, the ones of the fields missing in values are left unchanged.
+func UpdateUserProfileFields(userID int64, values map[int64]string) error {
+	fields, err := GetProfileFields()
+	if err != nil {
+		return err
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	for _, field := range fields {
+		value, ok := values[field.ID]
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if err := field.ValidateValue(value); err != nil {
+			return err
+		}
+
+		cond := builder.Eq{"field_id": field.ID, "user_id": userID}
+		if value == "" {
+			if _, err := sess.Where(cond).Delete(new(ProfileFieldValue)); err != nil {
+				return err
+			}
+			continue
+		}
+		if has, err := sess.Where(cond).Exist(new(ProfileFieldValue)); err != nil {
+			return err
+		} else if has {
+			if _, err := sess.Where(cond).Cols("value").Update(&ProfileFieldValue{Value: value}); err != nil {
+				return err
+			}
+		} else if _, err := sess.Insert(&ProfileFieldValue{FieldID: field.ID, UserID: userID, Value: value}); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// GetVisibleProfileFields returns the filled in profile fields of the users which viewer, who is nil if not signed
+// in, can see, by user ID
+func (users UserList) GetVisibleProfileFields(viewer *User) (map[int64][]*UserProfileField, error) {
+	results := make(map[int64][]*UserProfileField, len(users))
+	if len(users) == 0 {
+		return results, nil
+	}
+	fields, err := GetProfileFields()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return results, nil
+	}
+	values, err := getProfileFieldValues(x, users.getUserIDs())
+	if err != nil {
+		return nil, fmt.Errorf("find profile field values: %v", err)
+	}
+	valuesByUser := make(map[int64]map[int64]string, len(users))
+	for _, value := range values {
+		if valuesByUser[value.UserID] == nil {
+			valuesByUser[value.UserID] = make(map[int64]string)
+		}
+		valuesByUser[value.UserID][value.FieldID] = value.Value
+	}
+
+	for _, user := range users {
+		for _, field := range fields {
+			value, ok := valuesByUser[user.ID][field.ID]
+			if !ok || !field.IsVisibleTo(user, viewer) {
+				continue
+			}
+			results[user.ID] = append(results[user.ID], &UserProfileField{ProfileField: field, Value: value})
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileField_IsVisibleTo(t *testing.T) {
+	owner := &User{ID: 2}
+	other := &User{ID: 3}
+	admin := &User{ID: 1, IsAdmin: true}
+	for _, c := range []struct {
+		visibility                    structs.VisibleType
+		anonymous, other, admin, self bool
+	}{
+		{structs.VisibleTypePublic, true, true, true, true},
+		{structs.VisibleTypeLimited, false, true, true, true},
+		{structs.VisibleTypePrivate, false, false, true, true},
+	} {
+		field := &ProfileField{Visibility: c.visibility}
+		assert.Equal(t, c.anonymous, field.IsVisibleTo(owner, nil), c.visibility.String())
+		assert.Equal(t, c.other, field.IsVisibleTo(owner, other), c.visibility.String())
+		assert.Equal(t, c.admin, field.IsVisibleTo(owner, admin), c.visibility.String())
+		assert.Equal(t, c.self, field.IsVisibleTo(owner, owner), c.visibility.String())
+	}
+}
+
+func TestProfileField_ValidateValue(t *testing.T) {
+	assert.NoError(t, (&ProfileField{}).ValidateValue(""))
+	assert.True(t, IsErrInvalidProfileFieldValue((&ProfileField{IsRequired: true}).ValidateValue("")))
+	assert.NoError(t, (&ProfileField{Type: ProfileFieldTypeLink}).ValidateValue("https://example.com"))
+	assert.True(t, IsErrInvalidProfileFieldValue((&ProfileField{Type: ProfileFieldTypeLink}).ValidateValue("javascript:alert(1)")))
+}
+
+func TestCreateProfileField(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.True(t, IsErrProfileFieldAlreadyExist(CreateProfileField(&ProfileField{Name: "department", Label: "Team"})))
+
+	field := &ProfileField{Name: "job_title", Label: "Job title", SortOrder: 0}
+	assert.NoError(t, CreateProfileField(field))
+	fields, err := GetProfileFields()
+	assert.NoError(t, err)
+	if assert.Len(t, fields, 4) {
+		assert.Equal(t, field.ID, fields[0].ID)
+	}
+
+	field.Name = "homepage"
+	assert.True(t, IsErrProfileFieldAlreadyExist(UpdateProfileField(field)))
+
+	assert.NoError(t, DeleteProfileField(1))
+	_, err = GetProfileFieldByID(1)
+	assert.True(t, IsErrProfileFieldNotExist(err))
+	AssertNotExistsBean(t, &ProfileFieldValue{FieldID: 1})
+}
+
+func TestUpdateUserProfileFields(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	err := UpdateUserProfileFields(2, map[int64]string{2: "not a link"})
+	assert.True(t, IsErrInvalidProfileFieldValue(err))
+
+	assert.NoError(t, UpdateUserProfileFields(2, map[int64]string{1: " Support ", 3: ""}))
+	fields, err := GetUserProfileFields(2)
+	assert.NoError(t, err)
+	if assert.Len(t, fields, 3) {
+		assert.Equal(t, "Support", fields[0].Value)
+		assert.Equal(t, "https://example.com/user2", fields[1].Value)
+		assert.Empty(t, fields[2].Value)
+	}
+	AssertNotExistsBean(t, &ProfileFieldValue{FieldID: 3, UserID: 2})
+
+	assert.NoError(t, UpdateUserProfileFields(5, map[int64]string{2: "https://example.com/user5"}))
+	AssertExistsAndLoadBean(t, &ProfileFieldValue{FieldID: 2, UserID: 5, Value: "https://example.com/user5"})
+}
+
+func TestUserList_GetVisibleProfileFields(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	user4 := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	user5 := AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+
+	fields, err := UserList{user2, user4, user5}.GetVisibleProfileFields(nil)
+	assert.NoError(t, err)
+	assert.Len(t, fields[2], 1)
+	assert.Len(t, fields[4], 1)
+	assert.Empty(t, fields[5])
+
+	visible, err := GetVisibleProfileFields(user2, user4)
+	assert.NoError(t, err)
+	if assert.Len(t, visible, 2) {
+		assert.Equal(t, "department", visible[0].Name)
+		assert.Equal(t, "homepage", visible[1].Name)
+	}
+
+	visible, err = GetVisibleProfileFields(user2, user2)
+	assert.NoError(t, err)
+	assert.Len(t, visible, 3)
+}
+
+func TestSearchUsersByProfileFields(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	users, _, err := SearchUsers(&SearchUserOptions{Keyword: "engineering", Type: UserTypeIndividual, SearchByProfileFields: true})
+	assert.NoError(t, err)
+	if assert.Len(t, users, 1) {
+		assert.EqualValues(t, 2, users[0].ID)
+	}
+
+	users, _, err = SearchUsers(&SearchUserOptions{Keyword: "engineering", Type: UserTypeIndividual})
+	assert.NoError(t, err)
+	assert.Empty(t, users)
+}
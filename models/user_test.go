@@ -10,6 +10,7 @@ import (
 	"strings"
 	"testing"
 
+	"code.gitea.io/gitea/modules/auth/ldap"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 
@@ -463,3 +464,40 @@ ssh-dss AAAAB3NzaC1kc3MAAACBAOChCC7lf6Uo9n7BmZ6M8St19PZf4Tn59NriyboW2x/DZuYAz3ib
 		}
 	}
 }
+
+func TestPreviewLDAPSync(t *testing.T) {
+	source := &LoginSource{
+		Type: LoginLDAP,
+		Cfg:  &LDAPConfig{Source: &ldap.Source{AdminFilter: "(isAdmin=TRUE)"}},
+	}
+	users := []*User{
+		{ID: 1, Name: "Alice", LowerName: "alice", FullName: "Alice Smith", Email: "alice@example.com", IsActive: true},
+		{ID: 2, Name: "bob", LowerName: "bob", FullName: "Bob Jones", Email: "bob@example.com", IsActive: true},
+		{ID: 3, Name: "carol", LowerName: "carol", FullName: "carol", Email: "carol@example.com", IsActive: true},
+		{ID: 4, Name: "dave", LowerName: "dave", FullName: "dave", Email: "dave@example.com"},
+	}
+	sr := []*ldap.SearchResult{
+		{Username: "alice", Name: "Alice", Surname: "Smith", Mail: "ALICE@example.com"},
+		// the admin flag changed
+		{Username: "bob", Name: "Bob", Surname: "Jones", Mail: "bob@example.com", IsAdmin: true},
+		{Username: "erin", Mail: "erin@example.com"},
+		{Username: ""},
+	}
+
+	preview := previewLDAPSync(source, users, sr, true)
+	assert.Equal(t, []string{"erin"}, preview.Created)
+	assert.Equal(t, []string{"bob"}, preview.Updated)
+	// the users which are already inactive are not listed
+	assert.Equal(t, []string{"carol"}, preview.Deactivated)
+	assert.False(t, preview.Refused)
+
+	// the existing users are left unchanged
+	preview = previewLDAPSync(source, users, sr, false)
+	assert.Equal(t, []string{"erin"}, preview.Created)
+	assert.Empty(t, preview.Updated)
+	assert.Empty(t, preview.Deactivated)
+
+	preview = previewLDAPSync(source, users, nil, true)
+	assert.True(t, preview.Refused)
+	assert.Empty(t, preview.Deactivated)
+}
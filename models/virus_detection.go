@@ -0,0 +1,109 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// VirusDetectionKind describes what kind of upload a virus was detected in
+type VirusDetectionKind string
+
+const (
+	// VirusDetectionKindAttachment is an issue or comment attachment
+	VirusDetectionKindAttachment VirusDetectionKind = "attachment"
+	// VirusDetectionKindRelease is a release asset
+	VirusDetectionKindRelease VirusDetectionKind = "release"
+	// VirusDetectionKindLFS is a LFS object
+	VirusDetectionKindLFS VirusDetectionKind = "lfs"
+)
+
+// VirusDetection records an upload in which the virus scanner found a virus
+type VirusDetection struct {
+	ID         int64              `xorm:"pk autoincr"`
+	Kind       VirusDetectionKind `xorm:"VARCHAR(20) NOT NULL"`
+	Name       string             `xorm:"NOT NULL"`
+	Signature  string             `xorm:"NOT NULL"`
+	Action     string             `xorm:"VARCHAR(20) NOT NULL"`
+	UploaderID int64              `xorm:"INDEX"`
+	Uploader   *User              `xorm:"-"`
+	RepoID     int64              `xorm:"INDEX"`
+	Repo       *Repository        `xorm:"-"`
+	// Path is the location of the file in its storage, or in the quarantine directory if it was quarantined
+	Path string `xorm:"TEXT"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// TrKind returns the translation key of the kind of upload
+func (d *VirusDetection) TrKind() string {
+	return "admin.virus_detections.kind_" + string(d.Kind)
+}
+
+// TrAction returns the translation key of the action taken on detection
+func (d *VirusDetection) TrAction() string {
+	return "admin.virus_detections.action_" + d.Action
+}
+
+// CreateVirusDetection records a virus detection
+func CreateVirusDetection(detection *VirusDetection) error {
+	_, err := x.Insert(detection)
+	return err
+}
+
+// UpdateVirusDetectionPath updates the recorded location of the detected file
+func UpdateVirusDetectionPath(detection *VirusDetection) error {
+	_, err := x.ID(detection.ID).Cols("path").Update(detection)
+	return err
+}
+
+// CountVirusDetections returns the number of recorded virus detections
+func CountVirusDetections() (int64, error) {
+	return x.Count(new(VirusDetection))
+}
+
+// VirusDetections returns the virus detections newest first with their uploaders and repositories loaded
+func VirusDetections(page, pageSize int) ([]*VirusDetection, error) {
+	detections := make([]*VirusDetection, 0, pageSize)
+	if err := x.Limit(pageSize, (page-1)*pageSize).Desc("id").Find(&detections); err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]int64, 0, len(detections))
+	repoIDs := make([]int64, 0, len(detections))
+	for _, detection := range detections {
+		if detection.UploaderID > 0 {
+			userIDs = append(userIDs, detection.UploaderID)
+		}
+		if detection.RepoID > 0 {
+			repoIDs = append(repoIDs, detection.RepoID)
+		}
+	}
+	users := make(map[int64]*User, len(userIDs))
+	if err := x.In("id", userIDs).Find(&users); err != nil {
+		return nil, err
+	}
+	repos := make(map[int64]*Repository, len(repoIDs))
+	if err := x.In("id", repoIDs).Find(&repos); err != nil {
+		return nil, err
+	}
+	for _, detection := range detections {
+		detection.Uploader = users[detection.UploaderID]
+		if detection.Uploader == nil {
+			detection.Uploader = NewGhostUser()
+		}
+		detection.Repo = repos[detection.RepoID]
+	}
+	return detections, nil
+}
+
+// DeleteVirusDetectionsByIDs deletes the virus detection records with the given ids
+func DeleteVirusDetectionsByIDs(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := x.In("id", ids).Delete(new(VirusDetection))
+	return err
+}
@@ -0,0 +1,53 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVirusDetections(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, CreateVirusDetection(&VirusDetection{
+		Kind:       VirusDetectionKindAttachment,
+		Name:       "eicar.txt",
+		Signature:  "Eicar-Test-Signature",
+		Action:     "reject",
+		UploaderID: 2,
+		RepoID:     1,
+	}))
+	assert.NoError(t, CreateVirusDetection(&VirusDetection{
+		Kind:       VirusDetectionKindLFS,
+		Name:       "oid",
+		Signature:  "Eicar-Test-Signature",
+		Action:     "flag",
+		UploaderID: 999,
+	}))
+
+	count, err := CountVirusDetections()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+
+	detections, err := VirusDetections(1, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, detections, 2) {
+		assert.EqualValues(t, "oid", detections[0].Name)
+		assert.EqualValues(t, -1, detections[0].Uploader.ID)
+		assert.Nil(t, detections[0].Repo)
+		assert.EqualValues(t, "admin.virus_detections.kind_lfs", detections[0].TrKind())
+
+		assert.EqualValues(t, "eicar.txt", detections[1].Name)
+		assert.EqualValues(t, 2, detections[1].Uploader.ID)
+		assert.EqualValues(t, 1, detections[1].Repo.ID)
+		assert.EqualValues(t, "admin.virus_detections.action_reject", detections[1].TrAction())
+	}
+
+	assert.NoError(t, DeleteVirusDetectionsByIDs([]int64{detections[0].ID}))
+	AssertNotExistsBean(t, &VirusDetection{ID: detections[0].ID})
+	AssertExistsAndLoadBean(t, &VirusDetection{ID: detections[1].ID})
+}
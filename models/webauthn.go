@@ -0,0 +1,120 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strconv"
+
+	"code.gitea.io/gitea/modules/auth/webauthn"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// WebAuthnCredential represents a discoverable WebAuthn credential of a security key
+// or platform authenticator, which lets the user sign in without a password
+type WebAuthnCredential struct {
+	ID           int64 `xorm:"pk autoincr"`
+	Name         string
+	UserID       int64  `xorm:"INDEX"`
+	CredentialID string `xorm:"VARCHAR(410) UNIQUE"`
+	PublicKey    []byte
+	AAGUID       []byte
+	SignCount    uint32             `xorm:"BIGINT"`
+	CreatedUnix  timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix  timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// TableName returns a better table name for WebAuthnCredential
+func (cred WebAuthnCredential) TableName() string {
+	return "webauthn_credential"
+}
+
+// ToCredential converts the database entry to a webauthn.Credential
+func (cred *WebAuthnCredential) ToCredential() (*webauthn.Credential, error) {
+	id, err := webauthn.DecodeID(cred.CredentialID)
+	if err != nil {
+		return nil, err
+	}
+	return &webauthn.Credential{
+		ID:        id,
+		PublicKey: cred.PublicKey,
+		AAGUID:    cred.AAGUID,
+		SignCount: cred.SignCount,
+	}, nil
+}
+
+// UpdateSignCount will update the database value of the signature counter
+func (cred *WebAuthnCredential) UpdateSignCount() error {
+	_, err := x.ID(cred.ID).Cols("sign_count").Update(cred)
+	return err
+}
+
+// WebAuthnCredentialList is a list of *WebAuthnCredential
+type WebAuthnCredentialList []*WebAuthnCredential
+
+// CredentialIDs returns the raw credential IDs of the list
+func (list WebAuthnCredentialList) CredentialIDs() [][]byte {
+	ids := make([][]byte, 0, len(list))
+	for _, cred := range list {
+		if id, err := webauthn.DecodeID(cred.CredentialID); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// WebAuthnUserHandle returns the user handle stored by the authenticators for the user
+func WebAuthnUserHandle(u *User) []byte {
+	return []byte(strconv.FormatInt(u.ID, 10))
+}
+
+// GetWebAuthnCredentialsByUID returns all WebAuthn credentials of the given user
+func GetWebAuthnCredentialsByUID(uid int64) (WebAuthnCredentialList, error) {
+	creds := make(WebAuthnCredentialList, 0)
+	return creds, x.Where("user_id = ?", uid).Find(&creds)
+}
+
+// GetWebAuthnCredentialByID returns WebAuthn credential by id
+func GetWebAuthnCredentialByID(id int64) (*WebAuthnCredential, error) {
+	cred := new(WebAuthnCredential)
+	if found, err := x.ID(id).Get(cred); err != nil {
+		return nil, err
+	} else if !found {
+		return nil, ErrWebAuthnCredentialNotExist{ID: id}
+	}
+	return cred, nil
+}
+
+// GetWebAuthnCredentialByCredID returns WebAuthn credential by the base64url encoded credential ID
+func GetWebAuthnCredentialByCredID(credID string) (*WebAuthnCredential, error) {
+	cred := new(WebAuthnCredential)
+	if found, err := x.Where("credential_id = ?", credID).Get(cred); err != nil {
+		return nil, err
+	} else if !found {
+		return nil, ErrWebAuthnCredentialNotExist{CredentialID: credID}
+	}
+	return cred, nil
+}
+
+// CreateWebAuthnCredential stores a new WebAuthn credential of the user
+func CreateWebAuthnCredential(user *User, name string, cred *webauthn.Credential) (*WebAuthnCredential, error) {
+	c := &WebAuthnCredential{
+		UserID:       user.ID,
+		Name:         name,
+		CredentialID: webauthn.EncodeID(cred.ID),
+		PublicKey:    cred.PublicKey,
+		AAGUID:       cred.AAGUID,
+		SignCount:    cred.SignCount,
+	}
+	if _, err := x.InsertOne(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// DeleteWebAuthnCredential will delete the WebAuthn credential
+func DeleteWebAuthnCredential(cred *WebAuthnCredential) error {
+	_, err := x.ID(cred.ID).Delete(new(WebAuthnCredential))
+	return err
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/auth/webauthn"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWebAuthnCredential(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	cred, err := GetWebAuthnCredentialByID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "WebAuthn Key", cred.Name)
+
+	cred, err = GetWebAuthnCredentialByCredID("Y3JlZGVudGlhbC0x")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cred.ID)
+	c, err := cred.ToCredential()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("credential-1"), c.ID)
+
+	_, err = GetWebAuthnCredentialByID(342432)
+	assert.True(t, IsErrWebAuthnCredentialNotExist(err))
+	_, err = GetWebAuthnCredentialByCredID("unknown")
+	assert.True(t, IsErrWebAuthnCredentialNotExist(err))
+
+	creds, err := GetWebAuthnCredentialsByUID(1)
+	assert.NoError(t, err)
+	assert.Len(t, creds, 1)
+	assert.Equal(t, [][]byte{[]byte("credential-1")}, creds.CredentialIDs())
+}
+
+func TestWebAuthnCredential_UpdateSignCount(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	cred := AssertExistsAndLoadBean(t, &WebAuthnCredential{ID: 1}).(*WebAuthnCredential)
+	cred.SignCount = 0xffffffff
+	assert.NoError(t, cred.UpdateSignCount())
+	AssertExistsIf(t, true, &WebAuthnCredential{ID: 1, SignCount: 0xffffffff})
+}
+
+func TestCreateAndDeleteWebAuthnCredential(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	cred, err := CreateWebAuthnCredential(user, "Laptop", &webauthn.Credential{
+		ID:        []byte("credential-2"),
+		PublicKey: []byte("key"),
+		SignCount: 3,
+	})
+	assert.NoError(t, err)
+	AssertExistsIf(t, true, &WebAuthnCredential{UserID: user.ID, Name: "Laptop", CredentialID: "Y3JlZGVudGlhbC0y", SignCount: 3})
+
+	assert.NoError(t, DeleteWebAuthnCredential(cred))
+	AssertNotExistsBean(t, &WebAuthnCredential{ID: cred.ID})
+}
@@ -663,6 +663,8 @@ type HookTask struct {
 	IsDelivered     bool
 	Delivered       int64
 	DeliveredString string `xorm:"-"`
+	// RequestID is the ID of the request which caused the delivery, if known
+	RequestID string `xorm:"VARCHAR(64)"`
 
 	// History info.
 	IsSucceed       bool
@@ -747,6 +749,25 @@ func UpdateHookTask(t *HookTask) error {
 	return err
 }
 
+// ClaimHookTask marks a hook task as delivered before it is delivered, so that it is never delivered twice. It returns
+// false if the task has already been claimed.
+func ClaimHookTask(id int64) (bool, error) {
+	n, err := x.ID(id).And("is_delivered = ?", false).Cols("is_delivered").Update(&HookTask{IsDelivered: true})
+	return n == 1, err
+}
+
+// ResetInterruptedHookTasks marks the hook tasks which have been claimed but whose delivery has never completed, e.g.
+// because of a crash, as undelivered again. It returns the number of such tasks.
+func ResetInterruptedHookTasks() (int64, error) {
+	return x.Where("is_delivered = ? AND delivered = 0", true).Cols("is_delivered").Update(&HookTask{IsDelivered: false})
+}
+
+// FindUndeliveredHookTaskRepoIDs returns the IDs of the repositories with undelivered hook tasks
+func FindUndeliveredHookTaskRepoIDs() ([]int64, error) {
+	repoIDs := make([]int64, 0, 10)
+	return repoIDs, x.Table("hook_task").Where("is_delivered = ?", false).Distinct("repo_id").Find(&repoIDs)
+}
+
 // FindUndeliveredHookTasks represents find the undelivered hook tasks
 func FindUndeliveredHookTasks() ([]*HookTask, error) {
 	tasks := make([]*HookTask, 0, 10)
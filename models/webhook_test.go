@@ -228,6 +228,45 @@ func TestUpdateHookTask(t *testing.T) {
 	AssertExistsAndLoadBean(t, hook)
 }
 
+func TestClaimHookTask(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	hookTask := &HookTask{
+		RepoID:    3,
+		HookID:    3,
+		Typ:       GITEA,
+		URL:       "http://www.example.com/unit_test",
+		Payloader: &api.PushPayload{},
+	}
+	assert.NoError(t, CreateHookTask(hookTask))
+
+	repoIDs, err := FindUndeliveredHookTaskRepoIDs()
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3}, repoIDs)
+
+	claimed, err := ClaimHookTask(hookTask.ID)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	claimed, err = ClaimHookTask(hookTask.ID)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+
+	// the delivery of the claimed task has been interrupted
+	n, err := ResetInterruptedHookTasks()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+	assert.False(t, AssertExistsAndLoadBean(t, &HookTask{ID: hookTask.ID}).(*HookTask).IsDelivered)
+
+	claimed, err = ClaimHookTask(hookTask.ID)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	hookTask.IsDelivered = true
+	hookTask.Delivered = time.Now().UnixNano()
+	assert.NoError(t, UpdateHookTask(hookTask))
+	n, err = ResetInterruptedHookTasks()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, n)
+}
+
 func TestCleanupHookTaskTable_PerWebhook_DeletesDelivered(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	hookTask := &HookTask{
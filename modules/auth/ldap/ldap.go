@@ -13,8 +13,10 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
 
 	"github.com/go-ldap/ldap/v3"
+	jsoniter "github.com/json-iterator/go"
 )
 
 // SecurityProtocol protocol type
@@ -53,8 +55,11 @@ type Source struct {
 	GroupsEnabled         bool   // if the group checking is enabled
 	GroupDN               string // Group Search Base
 	GroupFilter           string // Group Name Filter
+	NestedGroupFilter     string // Filter of the groups a group is a member of, %s being its DN, to resolve nested groups
 	GroupMemberUID        string // Group Attribute containing array of UserUID
 	UserUID               string // User Attribute listed in Group
+	GroupTeamMap          string // JSON mapping of group DNs to organization teams
+	GroupTeamMapRemoval   bool   // Remove users from mapped teams if they are not in the corresponding group
 }
 
 // SearchResult : user data
@@ -66,8 +71,12 @@ type SearchResult struct {
 	SSHPublicKey []string // SSH Public Key
 	IsAdmin      bool     // if user is administrator
 	IsRestricted bool     // if user is restricted
+	Groups       []string // lower cased DNs of the groups of the user, nil if not looked up
 }
 
+// maxNestedGroupDepth is the number of levels of nested groups which are resolved
+const maxNestedGroupDepth = 10
+
 func (ls *Source) sanitizedUserQuery(username string) (string, bool) {
 	// See http://tools.ietf.org/search/rfc4515
 	badCharacters := "\x00()*\\"
@@ -112,6 +121,183 @@ func (ls *Source) sanitizedGroupDN(groupDn string) (string, bool) {
 	return groupDn, true
 }
 
+// SyncsTeams returns true if the team memberships of users are synchronized with their groups
+func (ls *Source) SyncsTeams() bool {
+	return ls.GroupsEnabled && len(strings.TrimSpace(ls.GroupTeamMap)) > 0
+}
+
+// TeamMapping parses the group to team mapping, which has the form
+// {"group DN": {"organization": ["team", ...]}}. Group DNs are lower cased
+// to match the Groups of search results.
+func (ls *Source) TeamMapping() (map[string]map[string][]string, error) {
+	mapping := map[string]map[string][]string{}
+	if len(strings.TrimSpace(ls.GroupTeamMap)) == 0 {
+		return mapping, nil
+	}
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.Unmarshal([]byte(ls.GroupTeamMap), &mapping); err != nil {
+		return nil, fmt.Errorf("invalid group team mapping: %v", err)
+	}
+	result := make(map[string]map[string][]string, len(mapping))
+	for group, teams := range mapping {
+		result[strings.ToLower(group)] = teams
+	}
+	return result, nil
+}
+
+// search runs the search request, in pages if paged search is enabled
+func (ls *Source) search(l *ldap.Conn, search *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	if ls.UsePagedSearch() {
+		return l.SearchWithPaging(search, ls.SearchPageSize)
+	}
+	return l.Search(search)
+}
+
+// ResolvesNestedGroups returns true if the groups the groups of the users are members of are looked up
+func (ls *Source) ResolvesNestedGroups() bool {
+	return ls.GroupsEnabled && len(strings.TrimSpace(ls.NestedGroupFilter)) > 0
+}
+
+// groupResolver resolves the nested groups of groups with the nested group filter, looking up the parents of every
+// group once
+type groupResolver struct {
+	ls      *Source
+	l       *ldap.Conn
+	parents map[string][]string
+}
+
+func (ls *Source) newGroupResolver(l *ldap.Conn) *groupResolver {
+	return &groupResolver{ls: ls, l: l, parents: make(map[string][]string)}
+}
+
+// listParents returns the lower cased DNs of the groups below the group search base the group is a member of
+func (r *groupResolver) listParents(dn string) ([]string, error) {
+	if parents, ok := r.parents[dn]; ok {
+		return parents, nil
+	}
+	groupDN, ok := r.ls.sanitizedGroupDN(r.ls.GroupDN)
+	if !ok {
+		return nil, fmt.Errorf("invalid group search base %q", r.ls.GroupDN)
+	}
+	filter := strings.ReplaceAll(r.ls.NestedGroupFilter, "%s", ldap.EscapeFilter(dn))
+
+	log.Trace("Fetching parent groups of '%s' with filter '%s' and base '%s'", dn, filter, groupDN)
+	sr, err := r.ls.search(r.l, ldap.NewSearchRequest(
+		groupDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false, filter,
+		[]string{}, nil))
+	if err != nil {
+		return nil, err
+	}
+	parents := make([]string, 0, len(sr.Entries))
+	for _, entry := range sr.Entries {
+		parents = append(parents, strings.ToLower(entry.DN))
+	}
+	r.parents[dn] = parents
+	return parents, nil
+}
+
+// resolve returns the groups with the groups they are members of, directly or through up to maxNestedGroupDepth
+// levels of nesting
+func (r *groupResolver) resolve(groups []string) ([]string, error) {
+	seen := make(map[string]bool, len(groups))
+	result := make([]string, 0, len(groups))
+	level := groups
+	for depth := 0; len(level) > 0 && depth <= maxNestedGroupDepth; depth++ {
+		var next []string
+		for _, group := range level {
+			if seen[group] {
+				continue
+			}
+			seen[group] = true
+			result = append(result, group)
+			if depth == maxNestedGroupDepth {
+				continue
+			}
+			parents, err := r.listParents(group)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, parents...)
+		}
+		level = next
+	}
+	return result, nil
+}
+
+// memberUID returns the value listed in the groups of the entry
+func (ls *Source) memberUID(entry *ldap.Entry) string {
+	if ls.UserUID == "dn" {
+		return entry.DN
+	}
+	return entry.GetAttributeValue(ls.UserUID)
+}
+
+// listGroupMemberships returns the lower cased DNs of all groups below the group
+// search base the user is listed in, and of their parent groups if nested groups
+// are resolved.
+func (ls *Source) listGroupMemberships(l *ldap.Conn, uid string) ([]string, error) {
+	groupDN, ok := ls.sanitizedGroupDN(ls.GroupDN)
+	if !ok {
+		return nil, fmt.Errorf("invalid group search base %q", ls.GroupDN)
+	}
+	groupFilter := fmt.Sprintf("(%s=%s)", ls.GroupMemberUID, ldap.EscapeFilter(uid))
+
+	log.Trace("Fetching groups of '%s' with filter '%s' and base '%s'", uid, groupFilter, groupDN)
+	search := ldap.NewSearchRequest(
+		groupDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false, groupFilter,
+		[]string{}, nil)
+
+	sr, err := ls.search(l, search)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]string, 0, len(sr.Entries))
+	for _, entry := range sr.Entries {
+		groups = append(groups, strings.ToLower(entry.DN))
+	}
+	if ls.ResolvesNestedGroups() {
+		return ls.newGroupResolver(l).resolve(groups)
+	}
+	return groups, nil
+}
+
+// listAllGroupMemberships returns the lower cased DNs of the groups below the
+// group search base for every listed user, with their parent groups if nested
+// groups are resolved.
+func (ls *Source) listAllGroupMemberships(l *ldap.Conn) (map[string][]string, error) {
+	groupDN, ok := ls.sanitizedGroupDN(ls.GroupDN)
+	if !ok {
+		return nil, fmt.Errorf("invalid group search base %q", ls.GroupDN)
+	}
+	groupFilter := fmt.Sprintf("(%s=*)", ls.GroupMemberUID)
+
+	log.Trace("Fetching all groups with filter '%s' and base '%s'", groupFilter, groupDN)
+	search := ldap.NewSearchRequest(
+		groupDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false, groupFilter,
+		[]string{ls.GroupMemberUID}, nil)
+
+	sr, err := ls.search(l, search)
+	if err != nil {
+		return nil, err
+	}
+	memberships := make(map[string][]string)
+	for _, entry := range sr.Entries {
+		dn := strings.ToLower(entry.DN)
+		for _, member := range entry.GetAttributeValues(ls.GroupMemberUID) {
+			memberships[member] = append(memberships[member], dn)
+		}
+	}
+	if ls.ResolvesNestedGroups() {
+		resolver := ls.newGroupResolver(l)
+		for member, groups := range memberships {
+			if memberships[member], err = resolver.resolve(groups); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return memberships, nil
+}
+
 func (ls *Source) findUserDN(l *ldap.Conn, name string) (string, bool) {
 	log.Trace("Search for LDAP user: %s", name)
 
@@ -307,7 +493,7 @@ func (ls *Source) SearchEntry(name, passwd string, directBind bool) *SearchResul
 	var isAttributeSSHPublicKeySet = len(strings.TrimSpace(ls.AttributeSSHPublicKey)) > 0
 
 	attribs := []string{ls.AttributeUsername, ls.AttributeName, ls.AttributeSurname, ls.AttributeMail}
-	if len(strings.TrimSpace(ls.UserUID)) > 0 {
+	if len(strings.TrimSpace(ls.UserUID)) > 0 && ls.UserUID != "dn" {
 		attribs = append(attribs, ls.UserUID)
 	}
 	if isAttributeSSHPublicKeySet {
@@ -339,7 +525,7 @@ func (ls *Source) SearchEntry(name, passwd string, directBind bool) *SearchResul
 	firstname := sr.Entries[0].GetAttributeValue(ls.AttributeName)
 	surname := sr.Entries[0].GetAttributeValue(ls.AttributeSurname)
 	mail := sr.Entries[0].GetAttributeValue(ls.AttributeMail)
-	uid := sr.Entries[0].GetAttributeValue(ls.UserUID)
+	uid := ls.memberUID(sr.Entries[0])
 
 	// Check group membership
 	if ls.GroupsEnabled {
@@ -358,7 +544,7 @@ func (ls *Source) SearchEntry(name, passwd string, directBind bool) *SearchResul
 			[]string{ls.GroupMemberUID},
 			nil)
 
-		srg, err := l.Search(groupSearch)
+		srg, err := ls.search(l, groupSearch)
 		if err != nil {
 			log.Error("LDAP group search failed: %v", err)
 			return nil
@@ -368,12 +554,27 @@ func (ls *Source) SearchEntry(name, passwd string, directBind bool) *SearchResul
 		}
 
 		isMember := false
-	Entries:
-		for _, group := range srg.Entries {
-			for _, member := range group.GetAttributeValues(ls.GroupMemberUID) {
-				if (ls.UserUID == "dn" && member == sr.Entries[0].DN) || member == uid {
+		if ls.ResolvesNestedGroups() {
+			// the user may be a member of one of the valid groups through the groups they are members of
+			memberOf, err := ls.listGroupMemberships(l, uid)
+			if err != nil {
+				log.Error("LDAP group membership search failed: %v", err)
+				return nil
+			}
+			for _, group := range srg.Entries {
+				if util.IsStringInSlice(strings.ToLower(group.DN), memberOf) {
 					isMember = true
-					break Entries
+					break
+				}
+			}
+		} else {
+		Entries:
+			for _, group := range srg.Entries {
+				for _, member := range group.GetAttributeValues(ls.GroupMemberUID) {
+					if member == uid {
+						isMember = true
+						break Entries
+					}
 				}
 			}
 		}
@@ -384,6 +585,15 @@ func (ls *Source) SearchEntry(name, passwd string, directBind bool) *SearchResul
 		}
 	}
 
+	var groups []string
+	if ls.SyncsTeams() {
+		groups, err = ls.listGroupMemberships(l, uid)
+		if err != nil {
+			// the user may still sign in, their teams are just not synchronized
+			log.Error("LDAP group membership search failed: %v", err)
+		}
+	}
+
 	if isAttributeSSHPublicKeySet {
 		sshPublicKey = sr.Entries[0].GetAttributeValues(ls.AttributeSSHPublicKey)
 	}
@@ -409,6 +619,7 @@ func (ls *Source) SearchEntry(name, passwd string, directBind bool) *SearchResul
 		SSHPublicKey: sshPublicKey,
 		IsAdmin:      isAdmin,
 		IsRestricted: isRestricted,
+		Groups:       groups,
 	}
 }
 
@@ -417,26 +628,18 @@ func (ls *Source) UsePagedSearch() bool {
 	return ls.SearchPageSize > 0
 }
 
-// SearchEntries : search an LDAP source for all users matching userFilter
-func (ls *Source) SearchEntries() ([]*SearchResult, error) {
-	l, err := dial(ls)
+// SearchEntries : search an LDAP source for all users matching userFilter. The connection is kept in a pool to be
+// reused by the next synchronizations.
+func (ls *Source) SearchEntries() (_ []*SearchResult, err error) {
+	l, err := ls.bindSync()
 	if err != nil {
 		log.Error("LDAP Connect error, %s:%v", ls.Host, err)
 		ls.Enabled = false
 		return nil, err
 	}
-	defer l.Close()
-
-	if ls.BindDN != "" && ls.BindPassword != "" {
-		err := l.Bind(ls.BindDN, ls.BindPassword)
-		if err != nil {
-			log.Debug("Failed to bind as BindDN[%s]: %v", ls.BindDN, err)
-			return nil, err
-		}
-		log.Trace("Bound as BindDN %s", ls.BindDN)
-	} else {
-		log.Trace("Proceeding with anonymous LDAP search.")
-	}
+	defer func() {
+		ls.releaseSync(l, err)
+	}()
 
 	userFilter := fmt.Sprintf(ls.Filter, "*")
 
@@ -447,17 +650,24 @@ func (ls *Source) SearchEntries() ([]*SearchResult, error) {
 		attribs = append(attribs, ls.AttributeSSHPublicKey)
 	}
 
+	var memberships map[string][]string
+	if ls.SyncsTeams() {
+		if len(strings.TrimSpace(ls.UserUID)) > 0 && ls.UserUID != "dn" {
+			attribs = append(attribs, ls.UserUID)
+		}
+		memberships, err = ls.listAllGroupMemberships(l)
+		if err != nil {
+			log.Error("LDAP group search failed: %v", err)
+			return nil, err
+		}
+	}
+
 	log.Trace("Fetching attributes '%v', '%v', '%v', '%v', '%v' with filter %s and base %s", ls.AttributeUsername, ls.AttributeName, ls.AttributeSurname, ls.AttributeMail, ls.AttributeSSHPublicKey, userFilter, ls.UserBase)
 	search := ldap.NewSearchRequest(
 		ls.UserBase, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false, userFilter,
 		attribs, nil)
 
-	var sr *ldap.SearchResult
-	if ls.UsePagedSearch() {
-		sr, err = l.SearchWithPaging(search, ls.SearchPageSize)
-	} else {
-		sr, err = l.Search(search)
-	}
+	sr, err := ls.search(l, search)
 	if err != nil {
 		log.Error("LDAP Search failed unexpectedly! (%v)", err)
 		return nil, err
@@ -479,6 +689,9 @@ func (ls *Source) SearchEntries() ([]*SearchResult, error) {
 		if isAttributeSSHPublicKeySet {
 			result[i].SSHPublicKey = v.GetAttributeValues(ls.AttributeSSHPublicKey)
 		}
+		if memberships != nil {
+			result[i].Groups = append([]string{}, memberships[ls.memberUID(v)]...)
+		}
 	}
 
 	return result, nil
@@ -0,0 +1,118 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ldap
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+const (
+	// maxIdleConns is the number of idle connections kept per server and bind DN
+	maxIdleConns = 2
+	// idleConnTimeout is how long an idle connection is kept
+	idleConnTimeout = 5 * time.Minute
+)
+
+type idleConn struct {
+	conn  *ldap.Conn
+	since time.Time
+}
+
+// connPool keeps the connections bound with the bind DN of the sources, by server and bind DN, so that the
+// synchronizations of the users and the teams of the sources of a server reuse them
+type connPool struct {
+	mu   sync.Mutex
+	idle map[string][]*idleConn
+}
+
+var pool = &connPool{idle: make(map[string][]*idleConn)}
+
+func poolKey(ls *Source) string {
+	return fmt.Sprintf("%d|%s|%d|%t|%s", ls.SecurityProtocol, ls.Host, ls.Port, ls.SkipVerify, ls.BindDN)
+}
+
+// get returns an idle connection of the pool, the expired and closed ones being closed and dropped, or nil if there
+// is none
+func (p *connPool) get(ls *Source) *ldap.Conn {
+	key := poolKey(ls)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for conns := p.idle[key]; len(conns) > 0; conns = p.idle[key] {
+		c := conns[len(conns)-1]
+		p.idle[key] = conns[:len(conns)-1]
+		if !c.conn.IsClosing() && time.Since(c.since) < idleConnTimeout {
+			return c.conn
+		}
+		c.conn.Close()
+	}
+	return nil
+}
+
+// put returns a connection to the pool, or closes it if the pool is full
+func (p *connPool) put(ls *Source, conn *ldap.Conn) {
+	if conn.IsClosing() {
+		return
+	}
+	key := poolKey(ls)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.idle[key]) >= maxIdleConns {
+		conn.Close()
+		return
+	}
+	p.idle[key] = append(p.idle[key], &idleConn{conn: conn, since: time.Now()})
+}
+
+// bindSync returns a connection of the pool or a new one, bound with the bind DN of the source or anonymous, to
+// synchronize the source. It must be released with releaseSync. A pooled connection which cannot be bound anymore
+// is replaced with a new one.
+func (ls *Source) bindSync() (*ldap.Conn, error) {
+	if l := pool.get(ls); l != nil {
+		if err := ls.bindDN(l); err == nil {
+			return l, nil
+		}
+		log.Trace("Pooled LDAP connection to %s could not be bound, dialing a new one", ls.Host)
+		l.Close()
+	}
+
+	l, err := dial(ls)
+	if err != nil {
+		return nil, err
+	}
+	if err := ls.bindDN(l); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// releaseSync returns a connection of bindSync to the pool if it was used successfully, or closes it
+func (ls *Source) releaseSync(l *ldap.Conn, err error) {
+	if err != nil {
+		l.Close()
+		return
+	}
+	pool.put(ls, l)
+}
+
+// bindDN binds with the bind DN of the source if it has one
+func (ls *Source) bindDN(l *ldap.Conn) error {
+	if ls.BindDN == "" || ls.BindPassword == "" {
+		log.Trace("Proceeding with anonymous LDAP search.")
+		return nil
+	}
+	if err := l.Bind(ls.BindDN, ls.BindPassword); err != nil {
+		log.Debug("Failed to bind as BindDN[%s]: %v", ls.BindDN, err)
+		return err
+	}
+	log.Trace("Bound as BindDN %s", ls.BindDN)
+	return nil
+}
@@ -103,6 +103,25 @@ func ProviderCallback(provider string, request *http.Request, response http.Resp
 	return user, nil
 }
 
+// IsEmailVerified returns true if the provider asserted that the user owns the email, by the email_verified claim
+// of OpenID Connect or the verified_email field of Google. Other providers do not tell, so their emails are unverified.
+func IsEmailVerified(user goth.User) bool {
+	for _, key := range []string{openidConnect.EmailVerifiedClaim, "verified_email"} {
+		switch v := user.RawData[key].(type) {
+		case bool:
+			if v {
+				return true
+			}
+		case string:
+			// some providers send the claim as a string
+			if v == "true" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // RegisterProvider register a OAuth2 provider in goth lib
 func RegisterProvider(providerName, providerType, clientID, clientSecret, openIDConnectAutoDiscoveryURL string, customURLMapping *CustomURLMapping) error {
 	provider, err := createProvider(providerName, providerType, clientID, clientSecret, openIDConnectAutoDiscoveryURL, customURLMapping)
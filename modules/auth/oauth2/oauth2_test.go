@@ -0,0 +1,29 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package oauth2
+
+import (
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsEmailVerified(t *testing.T) {
+	for _, c := range []struct {
+		rawData  map[string]interface{}
+		verified bool
+	}{
+		{nil, false},
+		{map[string]interface{}{"email_verified": true}, true},
+		{map[string]interface{}{"email_verified": "true"}, true},
+		{map[string]interface{}{"email_verified": false}, false},
+		{map[string]interface{}{"email_verified": "false"}, false},
+		{map[string]interface{}{"verified_email": true}, true},
+		{map[string]interface{}{"verified": true}, false},
+	} {
+		assert.Equal(t, c.verified, IsEmailVerified(goth.User{RawData: c.rawData}), "%v", c.rawData)
+	}
+}
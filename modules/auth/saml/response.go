@@ -0,0 +1,214 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/beevik/etree"
+)
+
+// maxClockSkew is the tolerated difference between the clocks of Gitea and the identity provider
+const maxClockSkew = 3 * time.Minute
+
+// ParseResponse decodes the base64 encoded SAMLResponse posted to the assertion
+// consumer service, verifies it was issued for the given request and returns
+// the authenticated user.
+func (source *Source) ParseResponse(samlResponse, entityID, acsURL, requestID string) (*Assertion, error) {
+	return source.parseResponse(samlResponse, entityID, acsURL, requestID, time.Now())
+}
+
+func (source *Source) parseResponse(samlResponse, entityID, acsURL, requestID string, now time.Time) (*Assertion, error) {
+	if requestID == "" {
+		return nil, errors.New("no authentication request in progress")
+	}
+	cert, err := source.Certificate()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := decodeBase64(samlResponse)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SAMLResponse encoding: %v", err)
+	}
+	response, err := parseXML(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SAMLResponse: %v", err)
+	}
+	if !is(response, nsProtocol, "Response") {
+		return nil, errors.New("SAMLResponse is not a Response")
+	}
+	if attr(response, "Version") != "2.0" {
+		return nil, errors.New("unsupported SAML version")
+	}
+	if dest := attr(response, "Destination"); dest != "" && dest != acsURL {
+		return nil, fmt.Errorf("response destination %q does not match %q", dest, acsURL)
+	}
+	if attr(response, "InResponseTo") != requestID {
+		return nil, errors.New("response does not belong to the authentication request")
+	}
+	if err := source.checkIssuer(response, false); err != nil {
+		return nil, err
+	}
+
+	status := child(response, nsProtocol, "Status")
+	if status == nil {
+		return nil, errors.New("missing response status")
+	}
+	statusCode := child(status, nsProtocol, "StatusCode")
+	if statusCode == nil {
+		return nil, errors.New("missing response status code")
+	}
+	if code := attr(statusCode, "Value"); code != statusSuccess {
+		if msg := child(status, nsProtocol, "StatusMessage"); msg != nil {
+			return nil, fmt.Errorf("authentication failed: %s (%s)", code, text(msg))
+		}
+		return nil, fmt.Errorf("authentication failed: %s", code)
+	}
+
+	if len(childElements(response, nsAssertion, "EncryptedAssertion")) > 0 {
+		return nil, errors.New("encrypted assertions are not supported")
+	}
+	assertions := childElements(response, nsAssertion, "Assertion")
+	if len(assertions) != 1 {
+		return nil, errors.New("response must contain exactly one assertion")
+	}
+	assertion := assertions[0]
+
+	// Either the whole response or the assertion itself has to be signed.
+	// Only the verified copy is used afterwards, so that nothing can be
+	// wrapped around signed content.
+	signed, err := verifySignature(response, cert, now)
+	if err == nil {
+		assertions = childElements(signed, nsAssertion, "Assertion")
+		if len(assertions) != 1 {
+			return nil, errors.New("response must contain exactly one assertion")
+		}
+		assertion = assertions[0]
+	} else if err == errNotSigned {
+		assertion, err = verifySignature(assertion, cert, now)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %v", err)
+	}
+
+	return source.parseAssertion(assertion, entityID, acsURL, requestID, now)
+}
+
+func (source *Source) checkIssuer(e *etree.Element, required bool) error {
+	iss := child(e, nsAssertion, "Issuer")
+	if iss == nil {
+		if required {
+			return errors.New("missing issuer")
+		}
+		return nil
+	}
+	if text(iss) != source.IdentityProviderEntityID {
+		return fmt.Errorf("unexpected issuer %q", text(iss))
+	}
+	return nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+func (source *Source) parseAssertion(assertion *etree.Element, entityID, acsURL, requestID string, now time.Time) (*Assertion, error) {
+	if err := source.checkIssuer(assertion, true); err != nil {
+		return nil, err
+	}
+
+	subject := child(assertion, nsAssertion, "Subject")
+	if subject == nil {
+		return nil, errors.New("missing subject")
+	}
+	nameID := child(subject, nsAssertion, "NameID")
+	if nameID == nil || text(nameID) == "" {
+		return nil, errors.New("missing name identifier")
+	}
+
+	confirmed := false
+	for _, confirmation := range childElements(subject, nsAssertion, "SubjectConfirmation") {
+		if attr(confirmation, "Method") != confirmationBearer {
+			continue
+		}
+		data := child(confirmation, nsAssertion, "SubjectConfirmationData")
+		if data == nil {
+			continue
+		}
+		if recipient := attr(data, "Recipient"); recipient != acsURL {
+			continue
+		}
+		if inResponseTo := attr(data, "InResponseTo"); inResponseTo != "" && inResponseTo != requestID {
+			continue
+		}
+		notOnOrAfter, err := parseTime(attr(data, "NotOnOrAfter"))
+		if err != nil || !now.Before(notOnOrAfter.Add(maxClockSkew)) {
+			continue
+		}
+		confirmed = true
+		break
+	}
+	if !confirmed {
+		return nil, errors.New("subject could not be confirmed")
+	}
+
+	conditions := child(assertion, nsAssertion, "Conditions")
+	if conditions == nil {
+		return nil, errors.New("missing conditions")
+	}
+	if v := attr(conditions, "NotBefore"); v != "" {
+		notBefore, err := parseTime(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NotBefore: %v", err)
+		}
+		if now.Add(maxClockSkew).Before(notBefore) {
+			return nil, errors.New("assertion is not yet valid")
+		}
+	}
+	if v := attr(conditions, "NotOnOrAfter"); v != "" {
+		notOnOrAfter, err := parseTime(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NotOnOrAfter: %v", err)
+		}
+		if !now.Before(notOnOrAfter.Add(maxClockSkew)) {
+			return nil, errors.New("assertion has expired")
+		}
+	}
+	for _, restriction := range childElements(conditions, nsAssertion, "AudienceRestriction") {
+		found := false
+		for _, audience := range childElements(restriction, nsAssertion, "Audience") {
+			if text(audience) == entityID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New("assertion is not intended for this service provider")
+		}
+	}
+
+	result := &Assertion{
+		NameID:     text(nameID),
+		Attributes: map[string][]string{},
+	}
+	if statement := child(assertion, nsAssertion, "AuthnStatement"); statement != nil {
+		result.SessionIndex = attr(statement, "SessionIndex")
+	}
+	for _, statement := range childElements(assertion, nsAssertion, "AttributeStatement") {
+		for _, attribute := range childElements(statement, nsAssertion, "Attribute") {
+			name := attr(attribute, "Name")
+			for _, value := range childElements(attribute, nsAssertion, "AttributeValue") {
+				result.Attributes[name] = append(result.Attributes[name], text(value))
+			}
+			if friendlyName := attr(attribute, "FriendlyName"); friendlyName != "" && friendlyName != name {
+				result.Attributes[friendlyName] = append(result.Attributes[friendlyName], result.Attributes[name]...)
+			}
+		}
+	}
+	return result, nil
+}
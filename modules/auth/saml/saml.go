@@ -0,0 +1,148 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package saml implements a SAML 2.0 service provider using the HTTP-Redirect
+// binding for authentication requests and the HTTP-POST binding for responses.
+package saml
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	nsProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	nsAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+
+	bindingHTTPPost    = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	statusSuccess      = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	confirmationBearer = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+
+	// NameIDFormatUnspecified lets the identity provider choose the name identifier
+	NameIDFormatUnspecified = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+	// NameIDFormatPersistent requests an opaque identifier which is stable across sessions
+	NameIDFormatPersistent = "urn:oasis:names:tc:SAML:2.0:nameid-format:persistent"
+	// NameIDFormatEmail requests the email address as name identifier
+	NameIDFormatEmail = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+)
+
+// Source holds configuration for a SAML 2.0 identity provider
+type Source struct {
+	IdentityProviderEntityID    string // entity ID of the identity provider (Issuer of responses)
+	IdentityProviderSSOURL      string // single sign on URL accepting the HTTP-Redirect binding
+	IdentityProviderCertificate string // PEM encoded certificate used to sign responses
+	ServiceProviderEntityID     string // entity ID of Gitea, defaults to the metadata URL
+	NameIDFormat                string // requested name identifier format
+	AttributeUsername           string // attribute holding the username, the NameID is used when empty
+	AttributeEmail              string // attribute holding the email address
+	AttributeFullName           string // attribute holding the full name
+	AttributeGroups             string // attribute holding the group memberships
+	GroupTeamMap                string // JSON mapping of groups to organization teams
+	GroupTeamMapRemoval         bool   // remove users from mapped teams they are no longer entitled to
+}
+
+// Available reports whether SAML can be used with the given ROOT_URL. The identity
+// provider posts its response cross-site, which browsers only send along with the
+// request ID cookie if it is a secure SameSite=None cookie, so HTTPS is required.
+func Available(appURL string) bool {
+	return strings.HasPrefix(strings.ToLower(appURL), "https://")
+}
+
+// Certificate parses the configured identity provider certificate.
+func (source *Source) Certificate() (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(strings.TrimSpace(source.IdentityProviderCertificate)))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("identity provider certificate is not a PEM encoded certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identity provider certificate: %v", err)
+	}
+	return cert, nil
+}
+
+// Validate checks that the source can be used to sign in.
+func (source *Source) Validate() error {
+	if source.IdentityProviderEntityID == "" {
+		return errors.New("identity provider entity ID is required")
+	}
+	if !strings.HasPrefix(source.IdentityProviderSSOURL, "https://") && !strings.HasPrefix(source.IdentityProviderSSOURL, "http://") {
+		return errors.New("identity provider SSO URL must be a http(s) URL")
+	}
+	if _, err := source.Certificate(); err != nil {
+		return err
+	}
+	_, err := source.TeamMapping()
+	return err
+}
+
+// TeamMapping parses the group to team mapping, which has the form
+// {"group": {"organization": ["team", ...]}}.
+func (source *Source) TeamMapping() (map[string]map[string][]string, error) {
+	mapping := map[string]map[string][]string{}
+	if strings.TrimSpace(source.GroupTeamMap) == "" {
+		return mapping, nil
+	}
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.Unmarshal([]byte(source.GroupTeamMap), &mapping); err != nil {
+		return nil, fmt.Errorf("invalid group team mapping: %v", err)
+	}
+	return mapping, nil
+}
+
+// Assertion holds the verified information about an authenticated user.
+type Assertion struct {
+	NameID       string
+	SessionIndex string
+	Attributes   map[string][]string
+}
+
+func (a *Assertion) attribute(name string) string {
+	if name == "" {
+		return ""
+	}
+	for _, v := range a.Attributes[name] {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Username returns the username of the user, falling back to the NameID.
+func (source *Source) Username(a *Assertion) string {
+	if username := a.attribute(source.AttributeUsername); username != "" {
+		return username
+	}
+	return a.NameID
+}
+
+// Email returns the email address of the user. The NameID is used if it has the email format.
+func (source *Source) Email(a *Assertion) string {
+	if email := a.attribute(source.AttributeEmail); email != "" {
+		return email
+	}
+	if source.NameIDFormat == NameIDFormatEmail {
+		return a.NameID
+	}
+	return ""
+}
+
+// FullName returns the full name of the user.
+func (source *Source) FullName(a *Assertion) string {
+	return a.attribute(source.AttributeFullName)
+}
+
+// Groups returns the groups the user is a member of.
+func (source *Source) Groups(a *Assertion) []string {
+	if source.AttributeGroups == "" {
+		return nil
+	}
+	return a.Attributes[source.AttributeGroups]
+}
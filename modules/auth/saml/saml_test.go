@@ -0,0 +1,268 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testEntityID  = "https://gitea.example.com/user/saml/idp/metadata"
+	testACSURL    = "https://gitea.example.com/user/saml/idp/acs"
+	testRequestID = "_4fee3b046395c4e751011e97f8900b5273d56685"
+)
+
+func newTestSource(t *testing.T) (*Source, dsig.X509KeyStore) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	return &Source{
+			IdentityProviderEntityID:    "https://idp.example.com",
+			IdentityProviderSSOURL:      "https://idp.example.com/sso",
+			IdentityProviderCertificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+			AttributeEmail:              "mail",
+			AttributeGroups:             "groups",
+		}, dsig.TLSCertKeyStore(tls.Certificate{
+			Certificate: [][]byte{der},
+			PrivateKey:  key,
+		})
+}
+
+const testResponse = `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="_response" Version="2.0" IssueInstant="2021-03-01T10:00:00Z" Destination="` + testACSURL + `" InResponseTo="` + testRequestID + `">
+  <saml:Issuer>https://idp.example.com</saml:Issuer>
+  <samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>
+  <saml:Assertion ID="_assertion" Version="2.0" IssueInstant="2021-03-01T10:00:00Z">
+    <saml:Issuer>https://idp.example.com</saml:Issuer>
+    <saml:Subject>
+      <saml:NameID Format="urn:oasis:names:tc:SAML:2.0:nameid-format:persistent">user30</saml:NameID>
+      <saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">
+        <saml:SubjectConfirmationData NotOnOrAfter="2021-03-01T10:05:00Z" Recipient="` + testACSURL + `" InResponseTo="` + testRequestID + `"/>
+      </saml:SubjectConfirmation>
+    </saml:Subject>
+    <saml:Conditions NotBefore="2021-03-01T09:59:00Z" NotOnOrAfter="2021-03-01T10:05:00Z">
+      <saml:AudienceRestriction><saml:Audience>` + testEntityID + `</saml:Audience></saml:AudienceRestriction>
+    </saml:Conditions>
+    <saml:AuthnStatement AuthnInstant="2021-03-01T10:00:00Z" SessionIndex="_session"/>
+    <saml:AttributeStatement>
+      <saml:Attribute Name="mail"><saml:AttributeValue>user30@example.com</saml:AttributeValue></saml:Attribute>
+      <saml:Attribute Name="urn:oid:1.3.6.1.4.1.5923.1.5.1.1" FriendlyName="groups">
+        <saml:AttributeValue>developers</saml:AttributeValue>
+        <saml:AttributeValue>admins</saml:AttributeValue>
+      </saml:Attribute>
+    </saml:AttributeStatement>
+  </saml:Assertion>
+</samlp:Response>`
+
+// signResponse signs the assertion of the response the way an identity provider would.
+func signResponse(t *testing.T, ks dsig.X509KeyStore, doc string) string {
+	return signResponseWith(t, ks, doc, dsig.RSASHA256SignatureMethod)
+}
+
+// signResponseWith signs the assertion of the response with the given signature method.
+func signResponseWith(t *testing.T, ks dsig.X509KeyStore, doc, signatureMethod string) string {
+	root, err := parseXML([]byte(doc))
+	assert.NoError(t, err)
+	assertion := child(root, nsAssertion, "Assertion")
+	nsContext, err := etreeutils.NSBuildParentContext(assertion)
+	assert.NoError(t, err)
+	detached, err := etreeutils.NSDetatch(nsContext, assertion)
+	assert.NoError(t, err)
+
+	signer := dsig.NewDefaultSigningContext(ks)
+	signer.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+	assert.NoError(t, signer.SetSignatureMethod(signatureMethod))
+	signed, err := signer.SignEnveloped(detached)
+	assert.NoError(t, err)
+
+	root.InsertChild(assertion, signed)
+	root.RemoveChild(assertion)
+	out := etree.NewDocument()
+	out.SetRoot(root)
+	result, err := out.WriteToString()
+	assert.NoError(t, err)
+	return result
+}
+
+func encode(doc string) string {
+	return base64.StdEncoding.EncodeToString([]byte(doc))
+}
+
+func TestParseResponse(t *testing.T) {
+	source, ks := newTestSource(t)
+	doc := signResponse(t, ks, testResponse)
+	encoded := encode(doc)
+	now := time.Date(2021, 3, 1, 10, 0, 30, 0, time.UTC)
+
+	assertion, err := source.parseResponse(encoded, testEntityID, testACSURL, testRequestID, now)
+	assert.NoError(t, err)
+	if assert.NotNil(t, assertion) {
+		assert.Equal(t, "user30", assertion.NameID)
+		assert.Equal(t, "_session", assertion.SessionIndex)
+		assert.Equal(t, "user30", source.Username(assertion))
+		assert.Equal(t, "user30@example.com", source.Email(assertion))
+		assert.Equal(t, []string{"developers", "admins"}, source.Groups(assertion))
+	}
+
+	// another request
+	_, err = source.parseResponse(encoded, testEntityID, testACSURL, "_other", now)
+	assert.Error(t, err)
+	// no request at all
+	_, err = source.parseResponse(encoded, testEntityID, testACSURL, "", now)
+	assert.Error(t, err)
+	// another service provider
+	_, err = source.parseResponse(encoded, "https://other.example.com", testACSURL, testRequestID, now)
+	assert.Error(t, err)
+	// expired
+	_, err = source.parseResponse(encoded, testEntityID, testACSURL, testRequestID, now.Add(time.Hour))
+	assert.Error(t, err)
+
+	// tampered content
+	tampered := strings.Replace(doc, ">user30<", ">user1<", 1)
+	_, err = source.parseResponse(encode(tampered), testEntityID, testACSURL, testRequestID, now)
+	assert.Error(t, err)
+
+	// unsigned
+	_, err = source.parseResponse(encode(testResponse), testEntityID, testACSURL, testRequestID, now)
+	assert.Error(t, err)
+
+	// signed by another key
+	other, _ := newTestSource(t)
+	_, err = other.parseResponse(encoded, testEntityID, testACSURL, testRequestID, now)
+	assert.Error(t, err)
+
+	// signed with SHA-1
+	sha1Signed := signResponseWith(t, ks, testResponse, dsig.RSASHA1SignatureMethod)
+	_, err = source.parseResponse(encode(sha1Signed), testEntityID, testACSURL, testRequestID, now)
+	assert.Error(t, err)
+}
+
+func TestParseResponseSignatureWrapping(t *testing.T) {
+	source, ks := newTestSource(t)
+	doc := signResponse(t, ks, testResponse)
+	now := time.Date(2021, 3, 1, 10, 0, 30, 0, time.UTC)
+
+	root, err := parseXML([]byte(doc))
+	assert.NoError(t, err)
+	signedAssertion := child(root, nsAssertion, "Assertion")
+	signedXML := func() string {
+		out := etree.NewDocument()
+		out.SetRoot(signedAssertion.Copy())
+		s, err := out.WriteToString()
+		assert.NoError(t, err)
+		return s
+	}()
+	evilAssertion := strings.Replace(strings.Replace(signedXML, ">user30<", ">user1<", 1), "<ds:Signature", "<ds:Removed", 1)
+	evilAssertion = strings.Replace(evilAssertion, "</ds:Signature>", "</ds:Removed>", 1)
+
+	assertionStart := strings.Index(doc, "<saml:Assertion")
+	assertionEnd := strings.Index(doc, "</saml:Assertion>") + len("</saml:Assertion>")
+	withAssertion := func(assertion string) string {
+		return doc[:assertionStart] + assertion + doc[assertionEnd:]
+	}
+
+	for name, wrapped := range map[string]string{
+		// the signed assertion is hidden in an extension, an unsigned one takes its place
+		"extensions": strings.Replace(withAssertion(evilAssertion), "<samlp:Status>",
+			`<samlp:Extensions>`+signedXML+`</samlp:Extensions><samlp:Status>`, 1),
+		// the signed assertion is moved into the unsigned one, which takes over its ID
+		"nested": withAssertion(strings.Replace(evilAssertion, "</saml:Assertion>", signedXML+"</saml:Assertion>", 1)),
+		// the signed assertion is followed by an unsigned one
+		"sibling": withAssertion(signedXML + strings.Replace(evilAssertion, `ID="_assertion"`, `ID="_evil"`, 1)),
+		// the unsigned assertion comes first
+		"sibling before": withAssertion(evilAssertion + signedXML),
+		// the signed assertion is moved into the signature of the unsigned one
+		"signature object": withAssertion(strings.Replace(evilAssertion, "<saml:Subject>",
+			`<ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><ds:Object>`+signedXML+`</ds:Object></ds:Signature><saml:Subject>`, 1)),
+	} {
+		assertion, err := source.parseResponse(encode(wrapped), testEntityID, testACSURL, testRequestID, now)
+		assert.Error(t, err, name)
+		assert.Nil(t, assertion, name)
+	}
+
+	// A comment must not truncate the name identifier, the signature does not allow it to be inserted at all.
+	commented := signResponse(t, ks, strings.Replace(testResponse, ">user30<", ">user30.evil.example.com<", 1))
+	commented = strings.Replace(commented, ">user30.evil", ">user30<!---->.evil", 1)
+	assertion, err := source.parseResponse(encode(commented), testEntityID, testACSURL, testRequestID, now)
+	assert.Error(t, err)
+	assert.Nil(t, assertion)
+
+	// Document type declarations are refused.
+	withDTD := `<?xml version="1.0"?><!DOCTYPE Response [<!ENTITY user "user1">]>` + doc
+	_, err = source.parseResponse(encode(withDTD), testEntityID, testACSURL, testRequestID, now)
+	assert.Error(t, err)
+}
+
+// The Okta responses were captured by the goxmldsig project from a real identity provider.
+func TestParseResponseOkta(t *testing.T) {
+	cert, err := ioutil.ReadFile("testdata/okta.crt")
+	assert.NoError(t, err)
+	source := &Source{
+		IdentityProviderEntityID:    "http://www.okta.com/exkrfkzzb7NyB3UeP0h7",
+		IdentityProviderSSOURL:      "https://dev.okta.com/sso",
+		IdentityProviderCertificate: string(cert),
+		AttributeEmail:              "Email",
+	}
+	const (
+		entityID  = "https://dev.sudo.wtf:8443/v1/teams/asa"
+		acsURL    = "https://dev.sudo.wtf:8443/v1/_saml_callback"
+		requestID = "_ffea96b1-44a2-4a86-9683-45807984ab5b"
+	)
+	now := time.Date(2020, 9, 1, 17, 51, 30, 0, time.UTC)
+
+	doc, err := ioutil.ReadFile("testdata/okta_response.xml")
+	assert.NoError(t, err)
+	assertion, err := source.parseResponse(encode(string(doc)), entityID, acsURL, requestID, now)
+	assert.NoError(t, err)
+	if assert.NotNil(t, assertion) {
+		assert.Equal(t, "phoebe.yu@okta.com", assertion.NameID)
+		assert.Equal(t, "phoebe.yu@okta.com", source.Email(assertion))
+	}
+
+	// the name identifier was changed after signing
+	doc, err = ioutil.ReadFile("testdata/okta_response_modified.xml")
+	assert.NoError(t, err)
+	_, err = source.parseResponse(encode(string(doc)), entityID, acsURL, requestID, now)
+	assert.Error(t, err)
+
+	// the name identifier was changed and a second signature was wrapped around the original one
+	doc, err = ioutil.ReadFile("testdata/okta_response_spoofed.xml")
+	assert.NoError(t, err)
+	_, err = source.parseResponse(encode(string(doc)), entityID, acsURL, requestID, now)
+	assert.Error(t, err)
+}
+
+func TestAuthnRequestURL(t *testing.T) {
+	source, _ := newTestSource(t)
+	u, id, err := source.AuthnRequestURL(testEntityID, testACSURL, "state")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(id, "_"))
+	assert.True(t, strings.HasPrefix(u, "https://idp.example.com/sso?"))
+	assert.Contains(t, u, "RelayState=state")
+	assert.Contains(t, u, "SAMLRequest=")
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+)
+
+var (
+	errNotSigned = errors.New("element is not signed")
+
+	// SHA-1 is not accepted, as colliding documents can be crafted
+	digestAlgorithms = map[string]bool{
+		"http://www.w3.org/2001/04/xmlenc#sha256": true,
+		"http://www.w3.org/2001/04/xmlenc#sha512": true,
+	}
+	signatureAlgorithms = map[string]bool{
+		dsig.RSASHA256SignatureMethod: true,
+		dsig.RSASHA512SignatureMethod: true,
+	}
+	transformAlgorithms = map[string]bool{
+		string(dsig.EnvelopedSignatureAltorithmId):       true,
+		string(dsig.CanonicalXML10ExclusiveAlgorithmId): true,
+	}
+)
+
+func decodeBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+}
+
+// checkSignatureAlgorithms restricts the enveloped signature of e to the
+// algorithms used by identity providers today, before it is verified.
+func checkSignatureAlgorithms(e, signature *etree.Element) error {
+	signedInfo := child(signature, dsig.Namespace, dsig.SignedInfoTag)
+	if signedInfo == nil {
+		return errors.New("missing SignedInfo")
+	}
+	c14nMethod := child(signedInfo, dsig.Namespace, dsig.CanonicalizationMethodTag)
+	if c14nMethod == nil || attr(c14nMethod, dsig.AlgorithmAttr) != string(dsig.CanonicalXML10ExclusiveAlgorithmId) {
+		return errors.New("unsupported canonicalization method")
+	}
+	signatureMethod := child(signedInfo, dsig.Namespace, dsig.SignatureMethodTag)
+	if signatureMethod == nil || !signatureAlgorithms[attr(signatureMethod, dsig.AlgorithmAttr)] {
+		return errors.New("unsupported signature method")
+	}
+
+	references := childElements(signedInfo, dsig.Namespace, dsig.ReferenceTag)
+	if len(references) != 1 {
+		return errors.New("expected exactly one signature reference")
+	}
+	reference := references[0]
+	if id := attr(e, "ID"); id == "" || attr(reference, dsig.URIAttr) != "#"+id {
+		return errors.New("signature does not reference the signed element")
+	}
+	if transforms := child(reference, dsig.Namespace, dsig.TransformsTag); transforms != nil {
+		for _, transform := range childElements(transforms, dsig.Namespace, dsig.TransformTag) {
+			if !transformAlgorithms[attr(transform, dsig.AlgorithmAttr)] {
+				return fmt.Errorf("unsupported transform %q", attr(transform, dsig.AlgorithmAttr))
+			}
+		}
+	}
+	digestMethod := child(reference, dsig.Namespace, dsig.DigestMethodTag)
+	if digestMethod == nil || !digestAlgorithms[attr(digestMethod, dsig.AlgorithmAttr)] {
+		return errors.New("unsupported digest method")
+	}
+	return nil
+}
+
+// verifySignature checks the enveloped signature of e against the certificate
+// and returns the verified copy of e. Only the returned element can be trusted,
+// callers must not look up signed content elsewhere in the document.
+func verifySignature(e *etree.Element, cert *x509.Certificate, now time.Time) (*etree.Element, error) {
+	signatures := childElements(e, dsig.Namespace, dsig.SignatureTag)
+	if len(signatures) == 0 {
+		return nil, errNotSigned
+	} else if len(signatures) > 1 {
+		return nil, errors.New("more than one signature")
+	}
+	if err := checkSignatureAlgorithms(e, signatures[0]); err != nil {
+		return nil, err
+	}
+
+	// The signed element is verified on its own, so the namespaces declared
+	// by its ancestors have to be copied onto it.
+	nsContext, err := etreeutils.NSBuildParentContext(e)
+	if err != nil {
+		return nil, err
+	}
+	detached, err := etreeutils.NSDetatch(nsContext, e)
+	if err != nil {
+		return nil, err
+	}
+
+	validationContext := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{
+		Roots: []*x509.Certificate{cert},
+	})
+	validationContext.Clock = dsig.NewFakeClockAt(now)
+	return validationContext.Validate(detached)
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+func parseXML(data []byte) (*etree.Element, error) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(data); err != nil {
+		return nil, err
+	}
+	for _, token := range doc.Child {
+		if _, ok := token.(*etree.Directive); ok {
+			return nil, errors.New("xml directives are not allowed")
+		}
+	}
+	root := doc.Root()
+	if root == nil {
+		return nil, errors.New("empty document")
+	}
+	return root, nil
+}
+
+func is(e *etree.Element, ns, local string) bool {
+	return e.Tag == local && e.NamespaceURI() == ns
+}
+
+// attr returns the value of the unqualified attribute with the given name.
+func attr(e *etree.Element, local string) string {
+	for _, a := range e.Attr {
+		if a.Space == "" && a.Key == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func childElements(e *etree.Element, ns, local string) []*etree.Element {
+	var res []*etree.Element
+	for _, c := range e.ChildElements() {
+		if is(c, ns, local) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+func child(e *etree.Element, ns, local string) *etree.Element {
+	if children := childElements(e, ns, local); len(children) > 0 {
+		return children[0]
+	}
+	return nil
+}
+
+// text returns all character data of e. Unlike etree's Text it does not stop
+// at the first comment, so that a comment can never truncate a value.
+func text(e *etree.Element) string {
+	var sb strings.Builder
+	for _, c := range e.Child {
+		if cd, ok := c.(*etree.CharData); ok {
+			sb.WriteString(cd.Data)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
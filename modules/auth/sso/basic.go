@@ -70,16 +70,16 @@ func (b *Basic) VerifyAuthData(req *http.Request, w http.ResponseWriter, store D
 		authToken = passwd
 	}
 
-	uid := CheckOAuthAccessToken(authToken)
-	if uid != 0 {
+	if grant := getOAuthAccessTokenGrant(authToken); grant != nil {
 		var err error
 		store.GetData()["IsApiToken"] = true
 
-		u, err = models.GetUserByID(uid)
+		u, err = models.GetUserByID(grant.UserID)
 		if err != nil {
 			log.Error("GetUserByID:  %v", err)
 			return nil
 		}
+		increaseAPIUsage(grant.UserID, 0, grant.ApplicationID)
 	}
 	token, err := models.GetAccessTokenBySHA(authToken)
 	if err == nil {
@@ -93,6 +93,7 @@ func (b *Basic) VerifyAuthData(req *http.Request, w http.ResponseWriter, store D
 		if err = models.UpdateAccessToken(token); err != nil {
 			log.Error("UpdateAccessToken:  %v", err)
 		}
+		increaseAPIUsage(token.UID, token.ID, 0)
 	} else if !models.IsErrAccessTokenNotExist(err) && !models.IsErrAccessTokenEmpty(err) {
 		log.Error("GetAccessTokenBySha: %v", err)
 	}
@@ -23,26 +23,41 @@ var (
 
 // CheckOAuthAccessToken returns uid of user from oauth token
 func CheckOAuthAccessToken(accessToken string) int64 {
+	if grant := getOAuthAccessTokenGrant(accessToken); grant != nil {
+		return grant.UserID
+	}
+	return 0
+}
+
+// getOAuthAccessTokenGrant returns the grant of a valid oauth token, nil if the token is invalid
+func getOAuthAccessTokenGrant(accessToken string) *models.OAuth2Grant {
 	// JWT tokens require a "."
 	if !strings.Contains(accessToken, ".") {
-		return 0
+		return nil
 	}
 	token, err := models.ParseOAuth2Token(accessToken)
 	if err != nil {
 		log.Trace("ParseOAuth2Token: %v", err)
-		return 0
+		return nil
 	}
 	var grant *models.OAuth2Grant
 	if grant, err = models.GetOAuth2GrantByID(token.GrantID); err != nil || grant == nil {
-		return 0
+		return nil
 	}
 	if token.Type != models.TypeAccessToken {
-		return 0
+		return nil
 	}
 	if token.ExpiresAt < time.Now().Unix() || token.IssuedAt > time.Now().Unix() {
-		return 0
+		return nil
+	}
+	return grant
+}
+
+// increaseAPIUsage counts an API request authenticated by an access token or by an OAuth2 application
+func increaseAPIUsage(userID, tokenID, appID int64) {
+	if err := models.IncreaseAPIUsage(userID, tokenID, appID); err != nil {
+		log.Error("IncreaseAPIUsage: %v", err)
 	}
-	return grant.UserID
 }
 
 // OAuth2 implements the SingleSignOn interface and authenticates requests
@@ -86,11 +101,13 @@ func (o *OAuth2) userIDFromToken(req *http.Request, store DataStore) int64 {
 
 	// Let's see if token is valid.
 	if strings.Contains(tokenSHA, ".") {
-		uid := CheckOAuthAccessToken(tokenSHA)
-		if uid != 0 {
-			store.GetData()["IsApiToken"] = true
+		grant := getOAuthAccessTokenGrant(tokenSHA)
+		if grant == nil {
+			return 0
 		}
-		return uid
+		store.GetData()["IsApiToken"] = true
+		increaseAPIUsage(grant.UserID, 0, grant.ApplicationID)
+		return grant.UserID
 	}
 	t, err := models.GetAccessTokenBySHA(tokenSHA)
 	if err != nil {
@@ -103,6 +120,7 @@ func (o *OAuth2) userIDFromToken(req *http.Request, store DataStore) int64 {
 	if err = models.UpdateAccessToken(t); err != nil {
 		log.Error("UpdateAccessToken: %v", err)
 	}
+	increaseAPIUsage(t.UID, t.ID, 0)
 	store.GetData()["IsApiToken"] = true
 	return t.UID
 }
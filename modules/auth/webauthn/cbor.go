@@ -0,0 +1,34 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webauthn
+
+import (
+	"bytes"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// cborDecMode decodes the CTAP2 canonical CBOR sent by authenticators, which
+// never contains duplicate map keys, indefinite length items or tags
+var cborDecMode = func() cbor.DecMode {
+	dm, err := cbor.DecOptions{
+		DupMapKey:   cbor.DupMapKeyEnforcedAPF,
+		IndefLength: cbor.IndefLengthForbidden,
+		TagsMd:      cbor.TagsForbidden,
+	}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+	return dm
+}()
+
+// decodeCBOR decodes the first CBOR item of data into v and returns the remaining bytes
+func decodeCBOR(data []byte, v interface{}) ([]byte, error) {
+	dec := cborDecMode.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(v); err != nil {
+		return nil, err
+	}
+	return data[dec.NumBytesRead():], nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// COSE algorithm identifiers
+const (
+	algES256 = -7
+	algEdDSA = -8
+	algRS256 = -257
+)
+
+// COSE key types
+const (
+	ktyOKP = 1
+	ktyEC2 = 2
+	ktyRSA = 3
+)
+
+// COSE curves
+const (
+	crvP256    = 1
+	crvEd25519 = 6
+)
+
+type publicKey struct {
+	alg int64
+	key crypto.PublicKey
+}
+
+// coseKey maps the labels of a COSE_Key to their raw values, the type of a
+// value depends on the key type
+type coseKey map[int64]cbor.RawMessage
+
+func (m coseKey) int(label int64) (int64, bool) {
+	var v int64
+	raw, ok := m[label]
+	if !ok || cborDecMode.Unmarshal(raw, &v) != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+func (m coseKey) bytes(label int64) ([]byte, bool) {
+	var v []byte
+	raw, ok := m[label]
+	if !ok || cborDecMode.Unmarshal(raw, &v) != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// parsePublicKey parses a COSE_Key of a supported algorithm
+func parsePublicKey(data []byte) (*publicKey, error) {
+	var m coseKey
+	if rest, err := decodeCBOR(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid public key: %v", err)
+	} else if len(rest) != 0 {
+		return nil, errors.New("trailing data after public key")
+	}
+	kty, _ := m.int(1)
+	alg, _ := m.int(3)
+
+	switch {
+	case kty == ktyEC2 && alg == algES256:
+		crv, _ := m.int(-1)
+		x, okX := m.bytes(-2)
+		y, okY := m.bytes(-3)
+		if crv != crvP256 || !okX || !okY || len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid EC2 public key")
+		}
+		key := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("EC2 public key is not on the curve")
+		}
+		return &publicKey{alg: alg, key: key}, nil
+	case kty == ktyOKP && alg == algEdDSA:
+		crv, _ := m.int(-1)
+		x, ok := m.bytes(-2)
+		if crv != crvEd25519 || !ok || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid OKP public key")
+		}
+		return &publicKey{alg: alg, key: ed25519.PublicKey(x)}, nil
+	case kty == ktyRSA && alg == algRS256:
+		n, okN := m.bytes(-1)
+		e, okE := m.bytes(-2)
+		if !okN || !okE || len(e) == 0 || len(e) > 4 || len(n) < 256 {
+			return nil, errors.New("invalid RSA public key")
+		}
+		return &publicKey{alg: alg, key: &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}}, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %d with algorithm %d", kty, alg)
+}
+
+func (k *publicKey) verify(data, sig []byte) error {
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		var esig struct {
+			R, S *big.Int
+		}
+		if rest, err := asn1.Unmarshal(sig, &esig); err != nil || len(rest) != 0 {
+			return errors.New("invalid signature")
+		}
+		h := sha256.Sum256(data)
+		if !ecdsa.Verify(key, h[:], esig.R, esig.S) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, data, sig) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		h := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, h[:], sig)
+	}
+	return errors.New("unsupported public key")
+}
@@ -0,0 +1,400 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package webauthn implements the relying party side of the Web Authentication API
+// for passwordless sign in with discoverable credentials (resident keys).
+//
+// Gitea requests no attestation: the attestation statement of a new credential is
+// not verified, the authenticator is trusted on first use like U2F security keys.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	flagUserPresent        = 0x01
+	flagUserVerified       = 0x04
+	flagAttestedCredential = 0x40
+
+	challengeLength = 32
+	// timeout for the browser in milliseconds
+	timeout = 60000
+
+	// MaxCredentialIDLength is the longest supported credential ID in bytes
+	MaxCredentialIDLength = 300
+)
+
+// RelyingParty identifies the Gitea instance towards the authenticators
+type RelyingParty struct {
+	ID     string
+	Name   string
+	Origin string
+}
+
+// NewRelyingParty derives the relying party from the public URL of the instance
+func NewRelyingParty(appURL, name string) (*RelyingParty, error) {
+	u, err := url.Parse(appURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("no host in %q", appURL)
+	}
+	return &RelyingParty{
+		ID:     u.Hostname(),
+		Name:   name,
+		Origin: u.Scheme + "://" + u.Host,
+	}, nil
+}
+
+// Credential is a public key credential created by an authenticator
+type Credential struct {
+	ID        []byte
+	PublicKey []byte // COSE_Key
+	AAGUID    []byte
+	SignCount uint32
+}
+
+// User is the account a new credential is created for
+type User struct {
+	Handle      []byte
+	Name        string
+	DisplayName string
+}
+
+// EncodeID encodes binary values like credential IDs the way they are exchanged with the browser
+func EncodeID(id []byte) string {
+	return base64.RawURLEncoding.EncodeToString(id)
+}
+
+// DecodeID decodes binary values exchanged with the browser
+func DecodeID(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// NewChallenge returns a new random challenge
+func NewChallenge() (string, error) {
+	b := make([]byte, challengeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return EncodeID(b), nil
+}
+
+// CredentialDescriptor identifies a credential, the ID is base64url encoded
+type CredentialDescriptor struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+func descriptors(ids [][]byte) []CredentialDescriptor {
+	ds := make([]CredentialDescriptor, 0, len(ids))
+	for _, id := range ids {
+		ds = append(ds, CredentialDescriptor{Type: "public-key", ID: EncodeID(id)})
+	}
+	return ds
+}
+
+// CreationOptions are passed to navigator.credentials.create(), binary values are base64url encoded
+type CreationOptions struct {
+	Challenge string `json:"challenge"`
+	RP        struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"rp"`
+	User struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+	} `json:"user"`
+	PubKeyCredParams []struct {
+		Type string `json:"type"`
+		Alg  int64  `json:"alg"`
+	} `json:"pubKeyCredParams"`
+	Timeout                int                    `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection struct {
+		ResidentKey        string `json:"residentKey"`
+		RequireResidentKey bool   `json:"requireResidentKey"`
+		UserVerification   string `json:"userVerification"`
+	} `json:"authenticatorSelection"`
+	Attestation string `json:"attestation"`
+}
+
+// CreationOptions returns the options to create a discoverable credential for the user,
+// excluding the credentials the user already registered
+func (rp *RelyingParty) CreationOptions(challenge string, user *User, exclude [][]byte) *CreationOptions {
+	opts := &CreationOptions{
+		Challenge:          challenge,
+		Timeout:            timeout,
+		ExcludeCredentials: descriptors(exclude),
+		Attestation:        "none",
+	}
+	opts.RP.ID = rp.ID
+	opts.RP.Name = rp.Name
+	opts.User.ID = EncodeID(user.Handle)
+	opts.User.Name = user.Name
+	opts.User.DisplayName = user.DisplayName
+	for _, alg := range []int64{algES256, algEdDSA, algRS256} {
+		opts.PubKeyCredParams = append(opts.PubKeyCredParams, struct {
+			Type string `json:"type"`
+			Alg  int64  `json:"alg"`
+		}{Type: "public-key", Alg: alg})
+	}
+	opts.AuthenticatorSelection.ResidentKey = "required"
+	opts.AuthenticatorSelection.RequireResidentKey = true
+	opts.AuthenticatorSelection.UserVerification = "required"
+	return opts
+}
+
+// RequestOptions are passed to navigator.credentials.get(), binary values are base64url encoded
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	Timeout          int                    `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// RequestOptions returns the options to get an assertion. Without allowed credentials
+// the authenticator lets the user choose one of their discoverable credentials.
+func (rp *RelyingParty) RequestOptions(challenge string, allow [][]byte) *RequestOptions {
+	return &RequestOptions{
+		Challenge:        challenge,
+		Timeout:          timeout,
+		RPID:             rp.ID,
+		AllowCredentials: descriptors(allow),
+		UserVerification: "required",
+	}
+}
+
+// CreationResponse is the credential returned by navigator.credentials.create(),
+// binary values are base64url encoded
+type CreationResponse struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AttestationObject string `json:"attestationObject"`
+	} `json:"response"`
+}
+
+// AssertionResponse is the credential returned by navigator.credentials.get(),
+// binary values are base64url encoded
+type AssertionResponse struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AuthenticatorData string `json:"authenticatorData"`
+		Signature         string `json:"signature"`
+		UserHandle        string `json:"userHandle"`
+	} `json:"response"`
+}
+
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+func (rp *RelyingParty) verifyClientData(raw []byte, typ, challenge string) error {
+	var cd clientData
+	if err := jsoniter.Unmarshal(raw, &cd); err != nil {
+		return fmt.Errorf("invalid client data: %v", err)
+	}
+	if cd.Type != typ {
+		return fmt.Errorf("unexpected client data type %q", cd.Type)
+	}
+	if challenge == "" || subtle.ConstantTimeCompare([]byte(strings.TrimRight(cd.Challenge, "=")), []byte(challenge)) != 1 {
+		return errors.New("challenge mismatch")
+	}
+	if cd.Origin != rp.Origin {
+		return fmt.Errorf("unexpected origin %q", cd.Origin)
+	}
+	if cd.CrossOrigin {
+		return errors.New("cross-origin requests are not allowed")
+	}
+	return nil
+}
+
+// attestationObject is the CBOR map returned on creation, the attestation
+// statement is ignored as no attestation is requested
+type attestationObject struct {
+	Fmt      string `cbor:"fmt"`
+	AuthData []byte `cbor:"authData"`
+}
+
+type authenticatorData struct {
+	RPIDHash  []byte
+	Flags     byte
+	SignCount uint32
+	AAGUID    []byte
+	CredID    []byte
+	PublicKey []byte
+}
+
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, errors.New("authenticator data too short")
+	}
+	ad := &authenticatorData{
+		RPIDHash:  data[:32],
+		Flags:     data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	if ad.Flags&flagAttestedCredential == 0 {
+		return ad, nil
+	}
+
+	rest := data[37:]
+	if len(rest) < 18 {
+		return nil, errors.New("attested credential data too short")
+	}
+	ad.AAGUID = rest[:16]
+	idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if len(rest) < idLength {
+		return nil, errors.New("credential ID truncated")
+	}
+	ad.CredID = rest[:idLength]
+	rest = rest[idLength:]
+	var key cbor.RawMessage
+	remaining, err := decodeCBOR(rest, &key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential public key: %v", err)
+	}
+	ad.PublicKey = rest[:len(rest)-len(remaining)]
+	return ad, nil
+}
+
+func (rp *RelyingParty) verifyAuthenticatorData(ad *authenticatorData) error {
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if !bytes.Equal(ad.RPIDHash, rpIDHash[:]) {
+		return errors.New("relying party ID mismatch")
+	}
+	if ad.Flags&flagUserPresent == 0 {
+		return errors.New("user not present")
+	}
+	if ad.Flags&flagUserVerified == 0 {
+		return errors.New("user not verified")
+	}
+	return nil
+}
+
+// VerifyCreation verifies a new credential and returns it
+func (rp *RelyingParty) VerifyCreation(resp *CreationResponse, challenge string) (*Credential, error) {
+	if resp.Type != "public-key" {
+		return nil, fmt.Errorf("unexpected credential type %q", resp.Type)
+	}
+	rawClientData, err := DecodeID(resp.Response.ClientDataJSON)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client data: %v", err)
+	}
+	if err := rp.verifyClientData(rawClientData, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+
+	rawAttestation, err := DecodeID(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation object: %v", err)
+	}
+	var attestation attestationObject
+	if rest, err := decodeCBOR(rawAttestation, &attestation); err != nil {
+		return nil, fmt.Errorf("invalid attestation object: %v", err)
+	} else if len(rest) != 0 {
+		return nil, errors.New("trailing data after attestation object")
+	}
+	if len(attestation.AuthData) == 0 {
+		return nil, errors.New("missing authenticator data")
+	}
+	ad, err := parseAuthenticatorData(attestation.AuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(ad); err != nil {
+		return nil, err
+	}
+	if ad.CredID == nil {
+		return nil, errors.New("missing attested credential data")
+	}
+	if len(ad.CredID) > MaxCredentialIDLength {
+		return nil, errors.New("credential ID too long")
+	}
+	if id, err := DecodeID(resp.ID); err != nil || !bytes.Equal(id, ad.CredID) {
+		return nil, errors.New("credential ID mismatch")
+	}
+	if _, err := parsePublicKey(ad.PublicKey); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:        ad.CredID,
+		PublicKey: ad.PublicKey,
+		AAGUID:    ad.AAGUID,
+		SignCount: ad.SignCount,
+	}, nil
+}
+
+// VerifyAssertion verifies the assertion of a registered credential and returns the new signature counter
+func (rp *RelyingParty) VerifyAssertion(resp *AssertionResponse, challenge string, cred *Credential) (uint32, error) {
+	if resp.Type != "public-key" {
+		return 0, fmt.Errorf("unexpected credential type %q", resp.Type)
+	}
+	if id, err := DecodeID(resp.ID); err != nil || !bytes.Equal(id, cred.ID) {
+		return 0, errors.New("credential ID mismatch")
+	}
+	rawClientData, err := DecodeID(resp.Response.ClientDataJSON)
+	if err != nil {
+		return 0, fmt.Errorf("invalid client data: %v", err)
+	}
+	if err := rp.verifyClientData(rawClientData, "webauthn.get", challenge); err != nil {
+		return 0, err
+	}
+	rawAuthData, err := DecodeID(resp.Response.AuthenticatorData)
+	if err != nil {
+		return 0, fmt.Errorf("invalid authenticator data: %v", err)
+	}
+	ad, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return 0, err
+	}
+	if err := rp.verifyAuthenticatorData(ad); err != nil {
+		return 0, err
+	}
+	sig, err := DecodeID(resp.Response.Signature)
+	if err != nil {
+		return 0, fmt.Errorf("invalid signature: %v", err)
+	}
+
+	key, err := parsePublicKey(cred.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+	clientDataHash := sha256.Sum256(rawClientData)
+	if err := key.verify(append(rawAuthData[:len(rawAuthData):len(rawAuthData)], clientDataHash[:]...), sig); err != nil {
+		return 0, err
+	}
+
+	// A counter that does not increase indicates a cloned authenticator,
+	// authenticators without a counter always return zero
+	if (ad.SignCount != 0 || cred.SignCount != 0) && ad.SignCount <= cred.SignCount {
+		return 0, errors.New("signature counter did not increase")
+	}
+	return ad.SignCount, nil
+}
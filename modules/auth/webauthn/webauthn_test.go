@@ -0,0 +1,242 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeCBOR(v interface{}) []byte {
+	em, err := cbor.CTAP2EncOptions().EncMode()
+	if err != nil {
+		panic(err)
+	}
+	data, err := em.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// authenticator is a software authenticator with a single ES256 credential
+type authenticator struct {
+	t     *testing.T
+	key   *ecdsa.PrivateKey
+	id    []byte
+	count uint32
+}
+
+func newAuthenticator(t *testing.T) *authenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	return &authenticator{t: t, key: key, id: []byte("credential-1")}
+}
+
+func (a *authenticator) authData(rpID string, flags byte, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[33:], a.count)
+	if attested {
+		data = append(data, make([]byte, 16)...)
+		data = append(data, byte(len(a.id)>>8), byte(len(a.id)))
+		data = append(data, a.id...)
+		data = append(data, encodeCBOR(map[interface{}]interface{}{
+			1:  ktyEC2,
+			3:  algES256,
+			-1: crvP256,
+			-2: pad32(a.key.X.Bytes()),
+			-3: pad32(a.key.Y.Bytes()),
+		})...)
+	}
+	return data
+}
+
+func pad32(b []byte) []byte {
+	return append(make([]byte, 32-len(b)), b...)
+}
+
+func clientDataJSON(t *testing.T, typ, challenge, origin string) []byte {
+	data, err := jsoniter.Marshal(clientData{Type: typ, Challenge: challenge, Origin: origin})
+	assert.NoError(t, err)
+	return data
+}
+
+func (a *authenticator) create(rp *RelyingParty, challenge, origin string, flags byte) *CreationResponse {
+	resp := &CreationResponse{ID: EncodeID(a.id), Type: "public-key"}
+	resp.Response.ClientDataJSON = EncodeID(clientDataJSON(a.t, "webauthn.create", challenge, origin))
+	resp.Response.AttestationObject = EncodeID(encodeCBOR(map[interface{}]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[interface{}]interface{}{},
+		"authData": a.authData(rp.ID, flags, true),
+	}))
+	return resp
+}
+
+func (a *authenticator) get(rp *RelyingParty, challenge, origin string) *AssertionResponse {
+	a.count++
+	rawClientData := clientDataJSON(a.t, "webauthn.get", challenge, origin)
+	authData := a.authData(rp.ID, flagUserPresent|flagUserVerified, false)
+	clientDataHash := sha256.Sum256(rawClientData)
+	h := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	r, ss, err := ecdsa.Sign(rand.Reader, a.key, h[:])
+	assert.NoError(a.t, err)
+	sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, ss})
+	assert.NoError(a.t, err)
+
+	resp := &AssertionResponse{ID: EncodeID(a.id), Type: "public-key"}
+	resp.Response.ClientDataJSON = EncodeID(rawClientData)
+	resp.Response.AuthenticatorData = EncodeID(authData)
+	resp.Response.Signature = EncodeID(sig)
+	resp.Response.UserHandle = EncodeID([]byte("1"))
+	return resp
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	var v map[string][]byte
+	rest, err := decodeCBOR(append(encodeCBOR(map[string][]byte{"a": {1, 2}}), 0xff), &v)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xff}, rest)
+	assert.Equal(t, map[string][]byte{"a": {1, 2}}, v)
+
+	for _, data := range [][]byte{
+		{},
+		{0x5a, 0xff, 0xff, 0xff, 0xff}, // truncated byte string
+		{0xa2, 0x61, 0x61, 0x01, 0x61, 0x61, 0x02}, // duplicate map key
+		{0xbf, 0x61, 0x61, 0x01, 0xff},             // indefinite length map
+		{0xc2, 0x41, 0x01},                         // tag
+	} {
+		_, err = decodeCBOR(data, &v)
+		assert.Error(t, err, "%x", data)
+	}
+}
+
+func TestRegistrationAndAssertion(t *testing.T) {
+	rp, err := NewRelyingParty("https://gitea.example.com:3000/sub/", "Gitea")
+	assert.NoError(t, err)
+	assert.Equal(t, "gitea.example.com", rp.ID)
+	assert.Equal(t, "https://gitea.example.com:3000", rp.Origin)
+
+	a := newAuthenticator(t)
+	challenge, err := NewChallenge()
+	assert.NoError(t, err)
+
+	cred, err := rp.VerifyCreation(a.create(rp, challenge, rp.Origin, flagUserPresent|flagUserVerified|flagAttestedCredential), challenge)
+	assert.NoError(t, err)
+	if !assert.NotNil(t, cred) {
+		return
+	}
+	assert.Equal(t, a.id, cred.ID)
+
+	// another challenge, another origin, no user verification
+	_, err = rp.VerifyCreation(a.create(rp, challenge, rp.Origin, flagUserPresent|flagUserVerified|flagAttestedCredential), "other")
+	assert.Error(t, err)
+	_, err = rp.VerifyCreation(a.create(rp, challenge, "https://evil.example.com", flagUserPresent|flagUserVerified|flagAttestedCredential), challenge)
+	assert.Error(t, err)
+	_, err = rp.VerifyCreation(a.create(rp, challenge, rp.Origin, flagUserPresent|flagAttestedCredential), challenge)
+	assert.Error(t, err)
+
+	challenge, err = NewChallenge()
+	assert.NoError(t, err)
+	count, err := rp.VerifyAssertion(a.get(rp, challenge, rp.Origin), challenge, cred)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	cred.SignCount = count
+
+	// replayed counter
+	a.count = 0
+	_, err = rp.VerifyAssertion(a.get(rp, challenge, rp.Origin), challenge, cred)
+	assert.Error(t, err)
+
+	// tampered authenticator data
+	resp := a.get(rp, challenge, rp.Origin)
+	authData, _ := DecodeID(resp.Response.AuthenticatorData)
+	authData[36]++
+	resp.Response.AuthenticatorData = EncodeID(authData)
+	_, err = rp.VerifyAssertion(resp, challenge, cred)
+	assert.Error(t, err)
+
+	// signed by another key
+	other := newAuthenticator(t)
+	other.count = 10
+	_, err = rp.VerifyAssertion(other.get(rp, challenge, rp.Origin), challenge, cred)
+	assert.Error(t, err)
+}
+
+// Credentials created and used with webauthn.io, taken from the test vectors
+// of github.com/duo-labs/webauthn
+const (
+	touchIDCredentialID      = "AI7D5q2P0LS-Fal9ZT7CHM2N5BLbUunF92T8b6iYC199bO2kagSuU05-5dZGqb1SP0A0lyTWng"
+	touchIDAttestationObject = "o2NmbXRkbm9uZWdhdHRTdG10oGhhdXRoRGF0YVi7dKbqkhPJnC90siSSsyDPQCYqlMGpUKA5fyklC2CEHvBFXJJiFa3OAAI1vMYKZIsLJfHwVQMANwCOw-atj9C0vhWpfWU-whzNjeQS21Lpxfdk_G-omAtffWztpGoErlNOfuXWRqm9Uj9ANJck1p6lAQIDJiABIVggKAhfsdHcBIc0KPgAcRyAIK_-Vi-nCXHkRHPNaCMBZ-4iWCBxB8fGYQSBONi9uvq0gv95dGWlhJrBwCsj_a4LJQKVHQ"
+	touchIDCreateClientData  = "eyJjaGFsbGVuZ2UiOiJmeWV1dUdQOXp1ZWoyRkdqZXZpNzlienFNS1d4aTRQWUlhXzV3ajI2MVcwIiwib3JpZ2luIjoiaHR0cHM6Ly93ZWJhdXRobi5pbyIsInR5cGUiOiJ3ZWJhdXRobi5jcmVhdGUifQ"
+	touchIDCreateChallenge   = "fyeuuGP9zuej2FGjevi79bzqMKWxi4PYIa_5wj261W0"
+	touchIDAuthenticatorData = "dKbqkhPJnC90siSSsyDPQCYqlMGpUKA5fyklC2CEHvBFXJJiGa3OAAI1vMYKZIsLJfHwVQMANwCOw-atj9C0vhWpfWU-whzNjeQS21Lpxfdk_G-omAtffWztpGoErlNOfuXWRqm9Uj9ANJck1p6lAQIDJiABIVggKAhfsdHcBIc0KPgAcRyAIK_-Vi-nCXHkRHPNaCMBZ-4iWCBxB8fGYQSBONi9uvq0gv95dGWlhJrBwCsj_a4LJQKVHQ"
+	touchIDGetClientData     = "eyJjaGFsbGVuZ2UiOiJFNFBUY0lIX0hmWDFwQzZTaWdrMVNDOU5BbGdlenROMDQzOXZpOHpfYzlrIiwibmV3X2tleXNfbWF5X2JlX2FkZGVkX2hlcmUiOiJkbyBub3QgY29tcGFyZSBjbGllbnREYXRhSlNPTiBhZ2FpbnN0IGEgdGVtcGxhdGUuIFNlZSBodHRwczovL2dvby5nbC95YWJQZXgiLCJvcmlnaW4iOiJodHRwczovL3dlYmF1dGhuLmlvIiwidHlwZSI6IndlYmF1dGhuLmdldCJ9"
+	touchIDGetChallenge      = "E4PTcIH_HfX1pC6Sigk1SC9NAlgeztN0439vi8z_c9k"
+	touchIDSignature         = "MEUCIBtIVOQxzFYdyWQyxaLR0tik1TnuPhGVhXVSNgFwLmN5AiEAnxXdCq0UeAVGWxOaFcjBZ_mEZoXqNboY5IkQDdlWZYc"
+
+	// created by a security key without user verification
+	securityKeyCredentialID      = "6xrtBhJQW6QU4tOaB4rrHaS2Ks0yDDL_q8jDC16DEjZ-VLVf4kCRkvl2xp2D71sTPYns-exsHQHTy3G-zJRK8g"
+	securityKeyAttestationObject = "o2NmbXRkbm9uZWdhdHRTdG10oGhhdXRoRGF0YVjEdKbqkhPJnC90siSSsyDPQCYqlMGpUKA5fyklC2CEHvBBAAAAAAAAAAAAAAAAAAAAAAAAAAAAQOsa7QYSUFukFOLTmgeK6x2ktirNMgwy_6vIwwtegxI2flS1X-JAkZL5dsadg-9bEz2J7PnsbB0B08txvsyUSvKlAQIDJiABIVggLKF5xS0_BntttUIrm2Z2tgZ4uQDwllbdIfrrBMABCNciWCDHwin8Zdkr56iSIh0MrB5qZiEzYLQpEOREhMUkY6q4Vw"
+	securityKeyClientData        = "eyJjaGFsbGVuZ2UiOiJXOEd6RlU4cEdqaG9SYldyTERsYW1BZnFfeTRTMUNaRzFWdW9lUkxBUnJFIiwib3JpZ2luIjoiaHR0cHM6Ly93ZWJhdXRobi5pbyIsInR5cGUiOiJ3ZWJhdXRobi5jcmVhdGUifQ"
+	securityKeyChallenge         = "W8GzFU8pGjhoRbWrLDlamAfq_y4S1CZG1VuoeRLARrE"
+)
+
+func TestRealAuthenticator(t *testing.T) {
+	rp := &RelyingParty{ID: "webauthn.io", Name: "webauthn.io", Origin: "https://webauthn.io"}
+
+	create := &CreationResponse{ID: touchIDCredentialID, Type: "public-key"}
+	create.Response.AttestationObject = touchIDAttestationObject
+	create.Response.ClientDataJSON = touchIDCreateClientData
+	cred, err := rp.VerifyCreation(create, touchIDCreateChallenge)
+	assert.NoError(t, err)
+	if !assert.NotNil(t, cred) {
+		return
+	}
+	assert.Equal(t, touchIDCredentialID, EncodeID(cred.ID))
+	assert.EqualValues(t, 1553097237, cred.SignCount)
+
+	get := &AssertionResponse{ID: touchIDCredentialID, Type: "public-key"}
+	get.Response.AuthenticatorData = touchIDAuthenticatorData
+	get.Response.ClientDataJSON = touchIDGetClientData
+	get.Response.Signature = touchIDSignature
+	count, err := rp.VerifyAssertion(get, touchIDGetChallenge, cred)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1553097241, count)
+
+	// the registration challenge does not sign in
+	_, err = rp.VerifyAssertion(get, touchIDCreateChallenge, cred)
+	assert.Error(t, err)
+
+	// a flipped bit in the signature
+	sig, _ := DecodeID(touchIDSignature)
+	sig[len(sig)-1] ^= 1
+	get.Response.Signature = EncodeID(sig)
+	_, err = rp.VerifyAssertion(get, touchIDGetChallenge, cred)
+	assert.Error(t, err)
+
+	// another relying party
+	other := &RelyingParty{ID: "gitea.io", Name: "Gitea", Origin: "https://webauthn.io"}
+	_, err = other.VerifyCreation(create, touchIDCreateChallenge)
+	assert.Error(t, err)
+
+	// the authenticator data is parsed, but the user was not verified
+	create = &CreationResponse{ID: securityKeyCredentialID, Type: "public-key"}
+	create.Response.AttestationObject = securityKeyAttestationObject
+	create.Response.ClientDataJSON = securityKeyClientData
+	_, err = rp.VerifyCreation(create, securityKeyChallenge)
+	assert.EqualError(t, err, "user not verified")
+}
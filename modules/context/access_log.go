@@ -35,6 +35,7 @@ func AccessLogger() func(http.Handler) http.Handler {
 				identity = val
 			}
 			rw := w.(ResponseWriter)
+			requestID := log.RequestIDFromContext(req.Context())
 
 			buf := bytes.NewBuffer([]byte{})
 			err := logTemplate.Execute(buf, routerLoggerOptions{
@@ -43,15 +44,16 @@ func AccessLogger() func(http.Handler) http.Handler {
 				Start:          &start,
 				ResponseWriter: rw,
 				Ctx: map[string]interface{}{
-					"RemoteAddr": req.RemoteAddr,
+					"RemoteAddr": setting.LoggedRemoteAddr(req.RemoteAddr),
 					"Req":        req,
+					"RequestID":  requestID,
 				},
 			})
 			if err != nil {
 				log.Error("Could not set up chi access logger: %v", err.Error())
 			}
 
-			err = logger.SendLog(log.INFO, "", "", 0, buf.String(), "")
+			err = logger.SendLogWithRequestID(log.INFO, requestID, "", "", 0, buf.String(), "")
 			if err != nil {
 				log.Error("Could not set up chi access logger: %v", err.Error())
 			}
@@ -52,6 +52,14 @@ func Toggle(options *ToggleOptions) func(ctx *Context) {
 				ctx.Redirect(setting.AppSubURL + "/")
 				return
 			}
+
+			if !ctx.User.MustChangePassword && ctx.User.MustAcceptPolicies && ctx.Req.URL.Path != "/user/policies" {
+				if ctx.Req.URL.Path != "/user/events" {
+					middleware.SetRedirectToCookie(ctx.Resp, setting.AppSubURL+ctx.Req.URL.RequestURI())
+				}
+				ctx.Redirect(setting.AppSubURL + "/user/policies")
+				return
+			}
 		}
 
 		// Redirect to dashboard if user tries to visit any non-login page.
@@ -473,9 +473,9 @@ func (ctx *Context) GetCookieFloat64(name string) float64 {
 	return v
 }
 
-// RemoteAddr returns the client machie ip address
+// RemoteAddr returns the client machine ip address as it is written in the logs, which depends on the privacy settings
 func (ctx *Context) RemoteAddr() string {
-	return ctx.Req.RemoteAddr
+	return setting.LoggedRemoteAddr(ctx.Req.RemoteAddr)
 }
 
 // Params returns the param on route
@@ -687,6 +687,8 @@ func Contexter() func(next http.Handler) http.Handler {
 			ctx.Data["EnableSwagger"] = setting.API.EnableSwagger
 			ctx.Data["EnableOpenIDSignIn"] = setting.Service.EnableOpenIDSignIn
 			ctx.Data["DisableMigrations"] = setting.Repository.DisableMigrations
+			ctx.Data["EnableActions"] = setting.Actions.Enabled
+			ctx.Data["EnablePackages"] = setting.Packages.Enabled
 
 			ctx.Data["ManifestData"] = setting.ManifestData
 
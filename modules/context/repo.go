@@ -16,6 +16,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/issueform"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup/markdown"
 	"code.gitea.io/gitea/modules/setting"
@@ -80,11 +81,15 @@ func (r *Repository) CanCreateBranch() bool {
 	return r.Permission.CanWrite(models.UnitTypeCode) && r.Repository.CanCreateBranch()
 }
 
-// RepoMustNotBeArchived checks if a repo is archived
+// RepoMustNotBeArchived checks if a repo is archived or under maintenance
 func RepoMustNotBeArchived() func(ctx *Context) {
 	return func(ctx *Context) {
 		if ctx.Repo.Repository.IsArchived {
 			ctx.NotFound("IsArchived", fmt.Errorf(ctx.Tr("repo.archive.title")))
+			return
+		}
+		if ctx.Repo.Repository.IsUnderMaintenance() && !ctx.Repo.IsAdmin() {
+			ctx.NotFound("IsUnderMaintenance", fmt.Errorf(ctx.Tr("repo.maintenance.title")))
 		}
 	}
 }
@@ -238,6 +243,52 @@ func (r *Repository) GetEditorconfig() (*editorconfig.Editorconfig, error) {
 	return editorconfig.Parse(reader)
 }
 
+var (
+	// devcontainerPaths are the paths of the development container configuration, by order of precedence
+	devcontainerPaths = []string{".devcontainer/devcontainer.json", ".devcontainer.json"}
+	// gitpodConfigPaths are the paths of the Gitpod configuration
+	gitpodConfigPaths = []string{".gitpod.yml"}
+)
+
+// GetDevcontainerPath returns the path of the development container configuration in the HEAD of the default
+// repo branch, empty if there is none.
+func (r *Repository) GetDevcontainerPath() (string, error) {
+	return r.getDefaultBranchFilePath(devcontainerPaths)
+}
+
+// GetGitpodConfigPath returns the path of the Gitpod configuration in the HEAD of the default repo branch, empty if
+// there is none.
+func (r *Repository) GetGitpodConfigPath() (string, error) {
+	return r.getDefaultBranchFilePath(gitpodConfigPaths)
+}
+
+// getDefaultBranchFilePath returns the first of treePaths which is a file in the HEAD of the default repo branch
+func (r *Repository) getDefaultBranchFilePath(treePaths []string) (string, error) {
+	if r.GitRepo == nil || r.Repository.IsEmpty {
+		return "", nil
+	}
+	commit, err := r.GitRepo.GetBranchCommit(r.Repository.DefaultBranch)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	for _, treePath := range treePaths {
+		entry, err := commit.GetTreeEntryByPath(treePath)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				continue
+			}
+			return "", err
+		}
+		if entry.IsRegular() {
+			return treePath, nil
+		}
+	}
+	return "", nil
+}
+
 // RetrieveBaseRepo retrieves base repository
 func RetrieveBaseRepo(ctx *Context, repo *models.Repository) {
 	// Non-fork repository will not return error in this method.
@@ -331,7 +382,7 @@ func RedirectToRepo(ctx *Context, redirectRepoID int64) {
 	ctx.Redirect(path.Join(setting.AppSubURL, redirectPath))
 }
 
-func repoAssignment(ctx *Context, repo *models.Repository) {
+func repoAssignment(ctx *Context, repo *models.Repository, allowIssueCollaborators bool) {
 	var err error
 	if err = repo.GetOwner(); err != nil {
 		ctx.ServerError("GetOwner", err)
@@ -350,8 +401,20 @@ func repoAssignment(ctx *Context, repo *models.Repository) {
 			EarlyResponseForGoGetMeta(ctx)
 			return
 		}
-		ctx.NotFound("no access right", nil)
-		return
+		// Users invited to single issues get through where the handlers grant them
+		// access to these issues only.
+		isIssueCollaborator := false
+		if allowIssueCollaborators && ctx.User != nil {
+			isIssueCollaborator, err = models.HasIssueCollaborations(repo.ID, ctx.User.ID)
+			if err != nil {
+				ctx.ServerError("HasIssueCollaborations", err)
+				return
+			}
+		}
+		if !isIssueCollaborator {
+			ctx.NotFound("no access right", nil)
+			return
+		}
 	}
 	ctx.Data["HasAccess"] = true
 	ctx.Data["Permission"] = &ctx.Repo.Permission
@@ -389,12 +452,23 @@ func RepoIDAssignment() func(ctx *Context) {
 			return
 		}
 
-		repoAssignment(ctx, repo)
+		repoAssignment(ctx, repo, false)
 	}
 }
 
 // RepoAssignment returns a middleware to handle repository assignment
 func RepoAssignment() func(http.Handler) http.Handler {
+	return repoAssignmentMiddleware(false)
+}
+
+// IssueCollaboratorRepoAssignment returns a middleware to handle repository assignment,
+// which also lets through the users invited to single issues of the repository.
+// The handlers behind it must only grant these users access to their issues.
+func IssueCollaboratorRepoAssignment() func(http.Handler) http.Handler {
+	return repoAssignmentMiddleware(true)
+}
+
+func repoAssignmentMiddleware(allowIssueCollaborators bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 			var (
@@ -451,7 +525,7 @@ func RepoAssignment() func(http.Handler) http.Handler {
 			}
 			repo.Owner = owner
 
-			repoAssignment(ctx, repo)
+			repoAssignment(ctx, repo, allowIssueCollaborators)
 			if ctx.Written() {
 				return
 			}
@@ -525,7 +599,7 @@ func RepoAssignment() func(http.Handler) http.Handler {
 				return
 			}
 
-			gitRepo, err := git.OpenRepository(models.RepoPath(userName, repoName))
+			gitRepo, err := git.OpenRepositoryCtx(ctx.Req.Context(), models.RepoPath(userName, repoName))
 			if err != nil {
 				ctx.ServerError("RepoAssignment Invalid repo "+models.RepoPath(userName, repoName), err)
 				return
@@ -746,7 +820,7 @@ func RepoRefByType(refType RepoRefType) func(http.Handler) http.Handler {
 
 			if ctx.Repo.GitRepo == nil {
 				repoPath := models.RepoPath(ctx.Repo.Owner.Name, ctx.Repo.Repository.Name)
-				ctx.Repo.GitRepo, err = git.OpenRepository(repoPath)
+				ctx.Repo.GitRepo, err = git.OpenRepositoryCtx(ctx.Req.Context(), repoPath)
 				if err != nil {
 					ctx.ServerError("RepoRef Invalid repo "+repoPath, err)
 					return
@@ -881,15 +955,19 @@ func UnitTypes() func(ctx *Context) {
 	}
 }
 
+// loadDefaultBranchCommit loads the commit of the default branch if no commit has been loaded yet
+func (ctx *Context) loadDefaultBranchCommit() (err error) {
+	if ctx.Repo.Commit == nil {
+		ctx.Repo.Commit, err = ctx.Repo.GitRepo.GetBranchCommit(ctx.Repo.Repository.DefaultBranch)
+	}
+	return err
+}
+
 // IssueTemplatesFromDefaultBranch checks for issue templates in the repo's default branch
 func (ctx *Context) IssueTemplatesFromDefaultBranch() []api.IssueTemplate {
 	var issueTemplates []api.IssueTemplate
-	if ctx.Repo.Commit == nil {
-		var err error
-		ctx.Repo.Commit, err = ctx.Repo.GitRepo.GetBranchCommit(ctx.Repo.Repository.DefaultBranch)
-		if err != nil {
-			return issueTemplates
-		}
+	if err := ctx.loadDefaultBranchCommit(); err != nil {
+		return issueTemplates
 	}
 
 	for _, dirName := range IssueTemplateDirCandidates {
@@ -902,33 +980,48 @@ func (ctx *Context) IssueTemplatesFromDefaultBranch() []api.IssueTemplate {
 			return issueTemplates
 		}
 		for _, entry := range entries {
-			if strings.HasSuffix(entry.Name(), ".md") {
-				if entry.Blob().Size() >= setting.UI.MaxDisplayFileSize {
-					log.Debug("Issue template is too large: %s", entry.Name())
-					continue
-				}
-				r, err := entry.Blob().DataAsync()
-				if err != nil {
-					log.Debug("DataAsync: %v", err)
-					continue
-				}
-				defer r.Close()
-				data, err := ioutil.ReadAll(r)
-				if err != nil {
-					log.Debug("ReadAll: %v", err)
-					continue
-				}
-				var it api.IssueTemplate
-				content, err := markdown.ExtractMetadata(string(data), &it)
+			if issueform.IsConfigFile(entry.Name()) {
+				continue
+			}
+			isForm := issueform.IsFormFile(entry.Name())
+			if !isForm && !strings.HasSuffix(entry.Name(), ".md") {
+				continue
+			}
+			if entry.Blob().Size() >= setting.UI.MaxDisplayFileSize {
+				log.Debug("Issue template is too large: %s", entry.Name())
+				continue
+			}
+			r, err := entry.Blob().DataAsync()
+			if err != nil {
+				log.Debug("DataAsync: %v", err)
+				continue
+			}
+			defer r.Close()
+			data, err := ioutil.ReadAll(r)
+			if err != nil {
+				log.Debug("ReadAll: %v", err)
+				continue
+			}
+			if isForm {
+				it, err := issueform.Parse(data)
 				if err != nil {
-					log.Debug("ExtractMetadata: %v", err)
+					log.Debug("Parse %s: %v", entry.Name(), err)
 					continue
 				}
-				it.Content = content
 				it.FileName = entry.Name()
-				if it.Valid() {
-					issueTemplates = append(issueTemplates, it)
-				}
+				issueTemplates = append(issueTemplates, *it)
+				continue
+			}
+			var it api.IssueTemplate
+			content, err := markdown.ExtractMetadata(string(data), &it)
+			if err != nil {
+				log.Debug("ExtractMetadata: %v", err)
+				continue
+			}
+			it.Content = content
+			it.FileName = entry.Name()
+			if it.Valid() {
+				issueTemplates = append(issueTemplates, it)
 			}
 		}
 		if len(issueTemplates) > 0 {
@@ -937,3 +1030,49 @@ func (ctx *Context) IssueTemplatesFromDefaultBranch() []api.IssueTemplate {
 	}
 	return issueTemplates
 }
+
+// IssueConfigFromDefaultBranch returns the configuration of the issue template chooser in the repo's default branch,
+// the default configuration if there is none. The default configuration is also returned with the error of an
+// invalid configuration.
+func (ctx *Context) IssueConfigFromDefaultBranch() (*api.IssueConfig, error) {
+	if err := ctx.loadDefaultBranchCommit(); err != nil {
+		return issueform.DefaultConfig(), nil
+	}
+
+	for _, dirName := range IssueTemplateDirCandidates {
+		for _, fileName := range []string{"config.yaml", "config.yml"} {
+			entry, err := ctx.Repo.Commit.GetTreeEntryByPath(path.Join(dirName, fileName))
+			if err != nil {
+				continue
+			}
+			if entry.Blob().Size() >= setting.UI.MaxDisplayFileSize {
+				return issueform.DefaultConfig(), issueform.ErrInvalidConfig{Reason: "the file is too large"}
+			}
+			r, err := entry.Blob().DataAsync()
+			if err != nil {
+				return issueform.DefaultConfig(), err
+			}
+			data, err := ioutil.ReadAll(r)
+			r.Close()
+			if err != nil {
+				return issueform.DefaultConfig(), err
+			}
+			config, err := issueform.ParseConfig(data)
+			if err != nil {
+				return issueform.DefaultConfig(), err
+			}
+			return config, nil
+		}
+	}
+	return issueform.DefaultConfig(), nil
+}
+
+// HasIssueTemplateChooser returns true if the new issues of the repository are created from the template chooser,
+// which lists the issue templates and the contact links
+func (ctx *Context) HasIssueTemplateChooser() bool {
+	if len(ctx.IssueTemplatesFromDefaultBranch()) > 0 {
+		return true
+	}
+	config, _ := ctx.IssueConfigFromDefaultBranch()
+	return len(config.ContactLinks) > 0
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// ToCodeSearchResult converts a code_indexer.Result in repo to api.CodeSearchResult
+func ToCodeSearchResult(result *code_indexer.Result, repo *models.Repository) *api.CodeSearchResult {
+	htmlURL := fmt.Sprintf("%s/src/commit/%s/%s", repo.HTMLURL(), result.CommitID, util.PathEscapeSegments(result.Filename))
+	if len(result.LineNumbers) > 0 {
+		htmlURL += fmt.Sprintf("#L%d", result.LineNumbers[0])
+	}
+	return &api.CodeSearchResult{
+		RepoID:       repo.ID,
+		RepoFullName: repo.FullName(),
+		Filename:     result.Filename,
+		CommitID:     result.CommitID,
+		HTMLURL:      htmlURL,
+		Language:     result.Language,
+		LineNumbers:  result.LineNumbers,
+		Content:      result.Content,
+		Updated:      result.UpdatedUnix.AsTime(),
+	}
+}
+
+// ToCodeSearchLanguage converts a code_indexer.SearchResultLanguages to api.CodeSearchLanguage
+func ToCodeSearchLanguage(language *code_indexer.SearchResultLanguages) *api.CodeSearchLanguage {
+	return &api.CodeSearchLanguage{
+		Language: language.Language,
+		Color:    language.Color,
+		Count:    language.Count,
+	}
+}
+
+// ToCodeSearchDirectory converts a code_indexer.SearchResultDirectory to api.CodeSearchDirectory
+func ToCodeSearchDirectory(directory *code_indexer.SearchResultDirectory) *api.CodeSearchDirectory {
+	return &api.CodeSearchDirectory{
+		Directory: directory.Directory,
+		Count:     directory.Count,
+	}
+}
+
+// ToCodeSearchRepository converts a code_indexer.SearchResultRepo of repo to api.CodeSearchRepository
+func ToCodeSearchRepository(result *code_indexer.SearchResultRepo, repo *models.Repository) *api.CodeSearchRepository {
+	return &api.CodeSearchRepository{
+		RepoID:       repo.ID,
+		RepoFullName: repo.FullName(),
+		Count:        result.Count,
+	}
+}
@@ -8,11 +8,13 @@ package convert
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
@@ -105,29 +107,32 @@ func ToBranchProtection(bp *models.ProtectedBranch) *api.BranchProtection {
 	}
 
 	return &api.BranchProtection{
-		BranchName:                    bp.BranchName,
-		EnablePush:                    bp.CanPush,
-		EnablePushWhitelist:           bp.EnableWhitelist,
-		PushWhitelistUsernames:        pushWhitelistUsernames,
-		PushWhitelistTeams:            pushWhitelistTeams,
-		PushWhitelistDeployKeys:       bp.WhitelistDeployKeys,
-		EnableMergeWhitelist:          bp.EnableMergeWhitelist,
-		MergeWhitelistUsernames:       mergeWhitelistUsernames,
-		MergeWhitelistTeams:           mergeWhitelistTeams,
-		EnableStatusCheck:             bp.EnableStatusCheck,
-		StatusCheckContexts:           bp.StatusCheckContexts,
-		RequiredApprovals:             bp.RequiredApprovals,
-		EnableApprovalsWhitelist:      bp.EnableApprovalsWhitelist,
-		ApprovalsWhitelistUsernames:   approvalsWhitelistUsernames,
-		ApprovalsWhitelistTeams:       approvalsWhitelistTeams,
-		BlockOnRejectedReviews:        bp.BlockOnRejectedReviews,
-		BlockOnOfficialReviewRequests: bp.BlockOnOfficialReviewRequests,
-		BlockOnOutdatedBranch:         bp.BlockOnOutdatedBranch,
-		DismissStaleApprovals:         bp.DismissStaleApprovals,
-		RequireSignedCommits:          bp.RequireSignedCommits,
-		ProtectedFilePatterns:         bp.ProtectedFilePatterns,
-		Created:                       bp.CreatedUnix.AsTime(),
-		Updated:                       bp.UpdatedUnix.AsTime(),
+		BranchName:                     bp.BranchName,
+		EnablePush:                     bp.CanPush,
+		EnablePushWhitelist:            bp.EnableWhitelist,
+		PushWhitelistUsernames:         pushWhitelistUsernames,
+		PushWhitelistTeams:             pushWhitelistTeams,
+		PushWhitelistDeployKeys:        bp.WhitelistDeployKeys,
+		EnableMergeWhitelist:           bp.EnableMergeWhitelist,
+		MergeWhitelistUsernames:        mergeWhitelistUsernames,
+		MergeWhitelistTeams:            mergeWhitelistTeams,
+		EnableStatusCheck:              bp.EnableStatusCheck,
+		StatusCheckContexts:            bp.StatusCheckContexts,
+		RequiredApprovals:              bp.RequiredApprovals,
+		EnableApprovalsWhitelist:       bp.EnableApprovalsWhitelist,
+		ApprovalsWhitelistUsernames:    approvalsWhitelistUsernames,
+		ApprovalsWhitelistTeams:        approvalsWhitelistTeams,
+		BlockOnRejectedReviews:         bp.BlockOnRejectedReviews,
+		BlockOnOfficialReviewRequests:  bp.BlockOnOfficialReviewRequests,
+		BlockOnOutdatedBranch:          bp.BlockOnOutdatedBranch,
+		BlockOnUnresolvedConversations: bp.BlockOnUnresolvedConversations,
+		RequireCodeOwnerReviews:        bp.RequireCodeOwnerReviews,
+		DismissStaleApprovals:          bp.DismissStaleApprovals,
+		RequireSignedCommits:           bp.RequireSignedCommits,
+		EnableMergeQueue:               bp.EnableMergeQueue,
+		ProtectedFilePatterns:          bp.ProtectedFilePatterns,
+		Created:                        bp.CreatedUnix.AsTime(),
+		Updated:                        bp.UpdatedUnix.AsTime(),
 	}
 }
 
@@ -356,3 +361,166 @@ func ToLFSLock(l *models.LFSLock) *api.LFSLock {
 		},
 	}
 }
+
+func toStorageUsages(usages []*models.StorageUsage) []*api.StorageUsage {
+	result := make([]*api.StorageUsage, 0, len(usages))
+	for _, u := range usages {
+		result = append(result, &api.StorageUsage{
+			ID:   u.ID,
+			Name: u.Name,
+			Size: u.Size,
+		})
+	}
+	return result
+}
+
+// ToStorageStatistic convert models.StorageStatistic to api.StorageStatistic
+func ToStorageStatistic(s *models.StorageStatistic) *api.StorageStatistic {
+	return &api.StorageStatistic{
+		RepositorySize:             s.RepositorySize,
+		LFSSize:                    s.LFSSize,
+		AttachmentSize:             s.AttachmentSize,
+		AvatarSize:                 s.AvatarSize,
+		RepoAvatarSize:             s.RepoAvatarSize,
+		TotalSize:                  s.TotalSize(),
+		NumUsers:                   s.NumUsers,
+		NumOrgs:                    s.NumOrgs,
+		NumRepos:                   s.NumRepos,
+		LargestRepositories:        toStorageUsages(s.LargestRepositories),
+		LargestLFSOwners:           toStorageUsages(s.LargestLFSOwners),
+		LargestAttachmentUploaders: toStorageUsages(s.LargestAttachmentUploaders),
+		Created:                    s.CreatedUnix.AsTime(),
+	}
+}
+
+// ToGitStats convert git.ObjectStats to api.GitStats
+func ToGitStats(repo *models.Repository, s *git.ObjectStats) *api.GitStats {
+	blobs := make([]*api.GitBlobSize, 0, len(s.LargestBlobs))
+	for _, b := range s.LargestBlobs {
+		sha := b.ID.String()
+		blobs = append(blobs, &api.GitBlobSize{
+			SHA:      sha,
+			URL:      repo.APIURL() + "/git/blobs/" + sha,
+			Size:     b.Size,
+			DiskSize: b.DiskSize,
+		})
+	}
+	// git count-objects reports sizes in KiB
+	return &api.GitStats{
+		LooseObjects:  s.Count,
+		LooseSize:     s.Size * 1024,
+		PackedObjects: s.InPack,
+		Packs:         s.Packs,
+		PackSize:      s.SizePack * 1024,
+		PrunePackable: s.PrunePack,
+		Garbage:       s.Garbage,
+		GarbageSize:   s.SizeGarbage * 1024,
+		LargestBlobs:  blobs,
+		Refs: &api.GitRefCounts{
+			Branches:     s.Refs.Branches,
+			Tags:         s.Refs.Tags,
+			PullRequests: s.Refs.PullRequests,
+			Others:       s.Refs.Others,
+		},
+	}
+}
+
+// ToIssueInsights convert models.IssueInsights to api.IssueInsights
+func ToIssueInsights(insights *models.IssueInsights) *api.IssueInsights {
+	templates := make([]*api.IssueTemplateUsage, 0, len(insights.Templates))
+	for _, t := range insights.Templates {
+		templates = append(templates, &api.IssueTemplateUsage{
+			Template: t.Template,
+			Count:    t.Count,
+		})
+	}
+	labels := make([]*api.LabelUsage, 0, len(insights.Labels))
+	for _, l := range insights.Labels {
+		labels = append(labels, &api.LabelUsage{
+			Label:  ToLabel(l.Label),
+			Total:  l.Total,
+			Weekly: l.Weekly,
+		})
+	}
+	return &api.IssueInsights{
+		From:                    insights.From,
+		Until:                   insights.Until,
+		Issues:                  insights.NumIssues,
+		Templates:               templates,
+		Labels:                  labels,
+		IssuesResponded:         insights.NumResponded,
+		AvgFirstResponseSeconds: int64(insights.AvgFirstResponse.Seconds()),
+		IssuesClosed:            insights.NumClosed,
+		AvgTimeToCloseSeconds:   int64(insights.AvgTimeToClose.Seconds()),
+	}
+}
+
+// ToPolicyDocument convert models.PolicyDocument to api.PolicyDocument
+func ToPolicyDocument(doc *models.PolicyDocument) *api.PolicyDocument {
+	return &api.PolicyDocument{
+		ID:        doc.ID,
+		Kind:      doc.Kind.Name(),
+		Version:   doc.Version,
+		Title:     doc.Title,
+		Content:   doc.Content,
+		Consents:  doc.NumConsents,
+		Published: doc.CreatedUnix.AsTime(),
+	}
+}
+
+// ToPolicyConsentState convert models.PolicyConsentState to api.PolicyConsentState
+func ToPolicyConsentState(state *models.PolicyConsentState) *api.PolicyConsentState {
+	apiState := &api.PolicyConsentState{
+		Kind:            state.Document.Kind.Name(),
+		DocumentID:      state.Document.ID,
+		CurrentVersion:  state.Document.Version,
+		AcceptedVersion: state.AcceptedVersion,
+		Pending:         state.IsPending(),
+	}
+	if state.AcceptedVersion > 0 {
+		accepted := state.AcceptedUnix.AsTime()
+		apiState.Accepted = &accepted
+	}
+	return apiState
+}
+
+// ToPolicyConsentStates convert the consent states of a user to API format
+func ToPolicyConsentStates(states []*models.PolicyConsentState) []*api.PolicyConsentState {
+	apiStates := make([]*api.PolicyConsentState, len(states))
+	for i := range states {
+		apiStates[i] = ToPolicyConsentState(states[i])
+	}
+	return apiStates
+}
+
+// ToUserFilter convert models.UserFilter to api.UserFilter, the repository of the filter must be loaded
+func ToUserFilter(f *models.UserFilter) *api.UserFilter {
+	apiFilter := &api.UserFilter{
+		ID:        f.ID,
+		Name:      f.Name,
+		Type:      "issues",
+		ViewType:  f.ViewType,
+		Sort:      f.SortType,
+		State:     f.State,
+		Keyword:   f.Keyword,
+		Labels:    f.LabelIDs(),
+		Milestone: f.MilestoneID,
+		Assignee:  f.AssigneeID,
+		Repos:     f.RepoIDs(),
+		HTMLURL:   setting.AppURL + strings.TrimPrefix(f.Link(), "/"),
+		Created:   f.CreatedUnix.AsTime(),
+		Updated:   f.UpdatedUnix.AsTime(),
+	}
+	if f.IsPull {
+		apiFilter.Type = "pulls"
+	}
+	if f.Repo != nil {
+		apiFilter.Repository = &api.RepositoryMeta{
+			ID:       f.Repo.ID,
+			Name:     f.Repo.Name,
+			Owner:    f.Repo.OwnerName,
+			FullName: f.Repo.FullName(),
+		}
+	}
+	return apiFilter
+}
@@ -39,6 +39,7 @@ func ToAPIIssue(issue *models.Issue) *api.Issue {
 		State:    issue.State(),
 		IsLocked: issue.IsLocked,
 		Comments: issue.NumComments,
+		Weight:   issue.Weight,
 		Created:  issue.CreatedUnix.AsTime(),
 		Updated:  issue.UpdatedUnix.AsTime(),
 	}
@@ -61,6 +62,20 @@ func ToAPIIssue(issue *models.Issue) *api.Issue {
 		apiIssue.Milestone = ToAPIMilestone(issue.Milestone)
 	}
 
+	if err := issue.LoadType(); err != nil {
+		return &api.Issue{}
+	}
+	if issue.Type != nil {
+		apiIssue.Type = ToIssueType(issue.Type)
+	}
+
+	if err := issue.LoadParent(); err != nil {
+		return &api.Issue{}
+	}
+	if issue.Parent != nil {
+		apiIssue.ParentIndex = issue.Parent.Index
+	}
+
 	if err := issue.LoadAssignees(); err != nil {
 		return &api.Issue{}
 	}
@@ -167,6 +182,28 @@ func ToTrackedTimeList(tl models.TrackedTimeList) api.TrackedTimeList {
 	return result
 }
 
+// ToTrackedTimeSums converts the sums of tracked times to API format
+func ToTrackedTimeSums(sums []*models.TrackedTimeSum) []*api.TrackedTimeSum {
+	result := make([]*api.TrackedTimeSum, 0, len(sums))
+	for _, sum := range sums {
+		apiSum := &api.TrackedTimeSum{
+			ID:   sum.ID,
+			Time: sum.Time,
+		}
+		switch {
+		case sum.Issue != nil:
+			apiSum.IssueIndex = sum.Issue.Index
+			apiSum.Name = sum.Issue.Title
+		case sum.Label != nil:
+			apiSum.Name = sum.Label.Name
+		case sum.Milestone != nil:
+			apiSum.Name = sum.Milestone.Name
+		}
+		result = append(result, apiSum)
+	}
+	return result
+}
+
 // ToLabel converts Label to API format
 func ToLabel(label *models.Label) *api.Label {
 	return &api.Label{
@@ -186,6 +223,41 @@ func ToLabelList(labels []*models.Label) []*api.Label {
 	return result
 }
 
+// ToSubIssueNode converts a tree of sub-issues to API format
+func ToSubIssueNode(node *models.SubIssueNode) *api.SubIssueNode {
+	result := &api.SubIssueNode{
+		Issue:     ToAPIIssue(node.Issue),
+		SubIssues: make([]*api.SubIssueNode, len(node.SubIssues)),
+		Total:     node.NumTotal,
+		Closed:    node.NumClosed,
+	}
+	for i := range node.SubIssues {
+		result.SubIssues[i] = ToSubIssueNode(node.SubIssues[i])
+	}
+	return result
+}
+
+// ToIssueType converts IssueType to API format
+func ToIssueType(t *models.IssueType) *api.IssueType {
+	return &api.IssueType{
+		ID:           t.ID,
+		Name:         t.Name,
+		Color:        strings.TrimLeft(t.Color, "#"),
+		Description:  t.Description,
+		TemplateFile: t.TemplateFile,
+		IsOrgType:    t.BelongsToOrg(),
+	}
+}
+
+// ToIssueTypeList converts list of IssueType to API format
+func ToIssueTypeList(types []*models.IssueType) []*api.IssueType {
+	result := make([]*api.IssueType, len(types))
+	for i := range types {
+		result[i] = ToIssueType(types[i])
+	}
+	return result
+}
+
 // ToAPIMilestone converts Milestone into API Format
 func ToAPIMilestone(m *models.Milestone) *api.Milestone {
 	apiMilestone := &api.Milestone{
@@ -195,6 +267,8 @@ func ToAPIMilestone(m *models.Milestone) *api.Milestone {
 		Description:  m.Content,
 		OpenIssues:   m.NumOpenIssues,
 		ClosedIssues: m.NumClosedIssues,
+		OpenWeight:   m.OpenWeight(),
+		ClosedWeight: m.ClosedWeight,
 		Created:      m.CreatedUnix.AsTime(),
 		Updated:      m.UpdatedUnix.AsTimePtr(),
 	}
@@ -206,3 +280,27 @@ func ToAPIMilestone(m *models.Milestone) *api.Milestone {
 	}
 	return apiMilestone
 }
+
+// ToMilestoneStats converts models.MilestoneStats to api.MilestoneStats
+func ToMilestoneStats(stats *models.MilestoneStats) *api.MilestoneStats {
+	burndown := make([]*api.MilestoneBurndownPoint, 0, len(stats.Burndown))
+	for _, point := range stats.Burndown {
+		burndown = append(burndown, &api.MilestoneBurndownPoint{
+			Date:   point.Time.Format("2006-01-02"),
+			Open:   point.Open,
+			Closed: point.Closed,
+		})
+	}
+	apiStats := &api.MilestoneStats{
+		Milestone: ToAPIMilestone(stats.Milestone),
+		From:      stats.From,
+		Until:     stats.Until,
+		Burndown:  burndown,
+		Weekly:    stats.Weekly,
+		Velocity:  stats.Velocity,
+	}
+	if !stats.ProjectedCompletion.IsZero() {
+		apiStats.ProjectedCompletion = &stats.ProjectedCompletion
+	}
+	return apiStats
+}
@@ -6,6 +6,7 @@ package convert
 
 import (
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/references"
 	api "code.gitea.io/gitea/modules/structs"
 )
 
@@ -22,3 +23,109 @@ func ToComment(c *models.Comment) *api.Comment {
 		Updated:  c.UpdatedUnix.AsTime(),
 	}
 }
+
+var xrefActionStrings = map[references.XRefAction]string{
+	references.XRefActionNone:     "none",
+	references.XRefActionCloses:   "closes",
+	references.XRefActionReopens:  "reopens",
+	references.XRefActionNeutered: "neutered",
+}
+
+// ToTimelineComment converts a models.Comment of any type to the api.TimelineComment format,
+// the issue and the poster of the comment must have been loaded
+func ToTimelineComment(c *models.Comment) (*api.TimelineComment, error) {
+	if err := c.LoadMilestone(); err != nil {
+		return nil, err
+	}
+	if err := c.LoadAssigneeUserAndTeam(); err != nil {
+		return nil, err
+	}
+	if err := c.LoadResolveDoer(); err != nil {
+		return nil, err
+	}
+	if err := c.LoadDepIssueDetails(); err != nil && !models.IsErrIssueNotExist(err) {
+		return nil, err
+	}
+	if err := c.LoadTime(); err != nil && !models.IsErrNotExist(err) {
+		return nil, err
+	}
+	if c.LabelID > 0 {
+		if err := c.LoadLabel(); err != nil {
+			return nil, err
+		}
+	}
+
+	comment := &api.TimelineComment{
+		ID:       c.ID,
+		Type:     c.Type.String(),
+		Poster:   ToUser(c.Poster, false, false),
+		HTMLURL:  c.HTMLURL(),
+		IssueURL: c.IssueURL(),
+		PRURL:    c.PRURL(),
+		Body:     c.Content,
+		Created:  c.CreatedUnix.AsTime(),
+		Updated:  c.UpdatedUnix.AsTime(),
+
+		OldProjectID: c.OldProjectID,
+		ProjectID:    c.ProjectID,
+		OldTitle:     c.OldTitle,
+		NewTitle:     c.NewTitle,
+		OldRef:       c.OldRef,
+		NewRef:       c.NewRef,
+
+		RefAction:    xrefActionStrings[c.RefAction],
+		RefCommitSHA: c.CommitSHA,
+
+		ReviewID: c.ReviewID,
+
+		RemovedAssignee: c.RemovedAssignee,
+	}
+
+	if c.OldMilestone != nil {
+		comment.OldMilestone = ToAPIMilestone(c.OldMilestone)
+	}
+	if c.Milestone != nil {
+		comment.Milestone = ToAPIMilestone(c.Milestone)
+	}
+	if c.Time != nil {
+		if err := c.Time.LoadAttributes(); err != nil {
+			return nil, err
+		}
+		comment.TrackedTime = ToTrackedTime(c.Time)
+	}
+	if c.RefIssueID != 0 {
+		issue, err := models.GetIssueByID(c.RefIssueID)
+		if err != nil && !models.IsErrIssueNotExist(err) {
+			return nil, err
+		} else if err == nil {
+			comment.RefIssue = ToAPIIssue(issue)
+		}
+	}
+	if c.RefCommentID != 0 {
+		refComment, err := models.GetCommentByID(c.RefCommentID)
+		if err != nil && !models.IsErrCommentNotExist(err) {
+			return nil, err
+		} else if err == nil {
+			if err := refComment.LoadPoster(); err != nil {
+				return nil, err
+			}
+			comment.RefComment = ToComment(refComment)
+		}
+	}
+	if c.Label != nil {
+		comment.Label = ToLabel(c.Label)
+	}
+	if c.Assignee != nil {
+		comment.Assignee = ToUser(c.Assignee, false, false)
+	}
+	if c.AssigneeTeam != nil {
+		comment.AssigneeTeam = ToTeam(c.AssigneeTeam)
+	}
+	if c.ResolveDoer != nil {
+		comment.ResolveDoer = ToUser(c.ResolveDoer, false, false)
+	}
+	if c.DependentIssue != nil {
+		comment.DependentIssue = ToAPIIssue(c.DependentIssue)
+	}
+	return comment, nil
+}
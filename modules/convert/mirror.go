@@ -0,0 +1,56 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToMirror converts a models.Mirror to api.Mirror, address is its remote address without credentials
+func ToMirror(m *models.Mirror, address string) *api.Mirror {
+	mirror := &api.Mirror{
+		RemoteAddress:   address,
+		Interval:        m.Interval.String(),
+		EnablePrune:     m.EnablePrune,
+		SyncWindowStart: models.FormatSyncWindowTime(m.SyncWindowStart),
+		SyncWindowEnd:   models.FormatSyncWindowTime(m.SyncWindowEnd),
+		IncludeRefs:     m.IncludeRefs,
+		ExcludeRefs:     m.ExcludeRefs,
+		Updated:         m.UpdatedUnix.AsTime(),
+
+		LastError:           m.LastError,
+		ConsecutiveFailures: m.ConsecutiveFailures,
+	}
+	if m.NextUpdateUnix > 0 {
+		mirror.NextUpdate = m.NextUpdateUnix.AsTimePtr()
+	}
+	return mirror
+}
+
+// ToPushMirror converts a models.PushMirror to api.PushMirror, address is its remote address without credentials
+func ToPushMirror(m *models.PushMirror, address string) *api.PushMirror {
+	mirror := &api.PushMirror{
+		ID:              m.ID,
+		RemoteName:      m.RemoteName(),
+		RemoteAddress:   address,
+		Interval:        m.Interval.String(),
+		SyncWindowStart: models.FormatSyncWindowTime(m.SyncWindowStart),
+		SyncWindowEnd:   models.FormatSyncWindowTime(m.SyncWindowEnd),
+		IncludeRefs:     m.IncludeRefs,
+		ExcludeRefs:     m.ExcludeRefs,
+		Created:         m.CreatedUnix.AsTime(),
+		LastError:       m.LastError,
+
+		ConsecutiveFailures: m.ConsecutiveFailures,
+	}
+	if m.LastUpdateUnix > 0 {
+		mirror.LastUpdate = m.LastUpdateUnix.AsTimePtr()
+	}
+	if m.NextUpdateUnix > 0 {
+		mirror.NextUpdate = m.NextUpdateUnix.AsTimePtr()
+	}
+	return mirror
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToOrgProject converts a project of an organization to api format
+func ToOrgProject(p *models.Project, org *models.User) *api.Project {
+	apiProject := &api.Project{
+		ID:          p.ID,
+		Title:       p.Title,
+		Description: p.Description,
+		State:       api.StateOpen,
+		Swimlanes:   p.SwimlaneType.Name(),
+		HTMLURL:     fmt.Sprintf("%sorg/%s/projects/%d", setting.AppURL, org.Name, p.ID),
+		Created:     p.CreatedUnix.AsTime(),
+		Updated:     p.UpdatedUnix.AsTime(),
+	}
+	if p.IsClosed {
+		apiProject.State = api.StateClosed
+		closed := p.ClosedDateUnix.AsTime()
+		apiProject.Closed = &closed
+	}
+	return apiProject
+}
+
+// ToProjectBoard converts a board of a project to api format
+func ToProjectBoard(b *models.ProjectBoard) *api.ProjectBoard {
+	return &api.ProjectBoard{
+		ID:              b.ID,
+		Title:           b.Title,
+		Default:         b.Default,
+		Sorting:         b.Sorting,
+		WIPLimit:        b.WIPLimit,
+		AutoMoveOnClose: b.AutoMoveOnClose,
+		AutoMoveOnMerge: b.AutoMoveOnMerge,
+		RepoIDs:         b.FilterRepoIDs(),
+	}
+}
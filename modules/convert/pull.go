@@ -55,6 +55,7 @@ func ToAPIPullRequest(pr *models.PullRequest) *api.PullRequest {
 		State:     apiIssue.State,
 		IsLocked:  apiIssue.IsLocked,
 		Comments:  apiIssue.Comments,
+		Draft:     pr.IsWorkInProgress(),
 		HTMLURL:   pr.Issue.HTMLURL(),
 		DiffURL:   pr.Issue.DiffURL(),
 		PatchURL:  pr.Issue.PatchURL(),
@@ -0,0 +1,40 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToMergeQueueEntry converts a merge queue entry to api format
+func ToMergeQueueEntry(entry *models.MergeQueueEntry, position int64, doer *models.User) (*api.MergeQueueEntry, error) {
+	if err := entry.LoadAttributes(); err != nil {
+		return nil, err
+	}
+
+	auth := false
+	if doer != nil {
+		auth = doer.IsAdmin || doer.ID == entry.DoerID
+	}
+
+	result := &api.MergeQueueEntry{
+		Index:      entry.Pull.Index,
+		Position:   position,
+		BaseBranch: entry.BaseBranch,
+		Status:     entry.Status.String(),
+		MergeStyle: string(entry.MergeStyle),
+		HeadSHA:    entry.HeadCommitID,
+		BaseSHA:    entry.BaseCommitID,
+		TestSHA:    entry.TestCommitID,
+		AddedBy:    ToUser(entry.Doer, doer != nil, auth),
+		Added:      entry.CreatedUnix.AsTime(),
+		Updated:    entry.UpdatedUnix.AsTime(),
+	}
+	if entry.TestCommitID != "" {
+		result.TestBranch = entry.TestBranchName()
+	}
+	return result, nil
+}
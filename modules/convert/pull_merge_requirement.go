@@ -0,0 +1,28 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToPullMergeRequirement converts a merge requirement of a pull request to api format
+func ToPullMergeRequirement(r *models.PullMergeRequirement, doer *models.User) (*api.PullMergeRequirement, error) {
+	if err := r.LoadCreator(); err != nil {
+		return nil, err
+	}
+
+	return &api.PullMergeRequirement{
+		ID:          r.ID,
+		Name:        r.Name,
+		Satisfied:   r.IsSatisfied,
+		Description: r.Description,
+		TargetURL:   r.TargetURL,
+		Creator:     ToUser(r.Creator, doer != nil, doer != nil && (doer.IsAdmin || doer.ID == r.CreatorID)),
+		Created:     r.CreatedUnix.AsTime(),
+		Updated:     r.UpdatedUnix.AsTime(),
+	}, nil
+}
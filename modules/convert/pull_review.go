@@ -85,41 +85,73 @@ func ToPullReviewCommentList(review *models.Review, doer *models.User) ([]*api.P
 
 	apiComments := make([]*api.PullReviewComment, 0, len(review.CodeComments))
 
-	auth := false
-	if doer != nil {
-		auth = doer.IsAdmin || doer.ID == review.ReviewerID
-	}
-
 	for _, lines := range review.CodeComments {
 		for _, comments := range lines {
 			for _, comment := range comments {
-				apiComment := &api.PullReviewComment{
-					ID:           comment.ID,
-					Body:         comment.Content,
-					Reviewer:     ToUser(review.Reviewer, doer != nil, auth),
-					ReviewID:     review.ID,
-					Created:      comment.CreatedUnix.AsTime(),
-					Updated:      comment.UpdatedUnix.AsTime(),
-					Path:         comment.TreePath,
-					CommitID:     comment.CommitSHA,
-					OrigCommitID: comment.OldRef,
-					DiffHunk:     patch2diff(comment.Patch),
-					HTMLURL:      comment.HTMLURL(),
-					HTMLPullURL:  review.Issue.HTMLURL(),
-				}
-
-				if comment.Line < 0 {
-					apiComment.OldLineNum = comment.UnsignedLine()
-				} else {
-					apiComment.LineNum = comment.UnsignedLine()
-				}
-				apiComments = append(apiComments, apiComment)
+				apiComments = append(apiComments, toPullReviewComment(review, comment, doer))
 			}
 		}
 	}
 	return apiComments, nil
 }
 
+// ToPullReviewComment convert a code comment of a review to it's api format
+func ToPullReviewComment(review *models.Review, comment *models.Comment, doer *models.User) (*api.PullReviewComment, error) {
+	if err := review.LoadAttributes(); err != nil {
+		if !models.IsErrUserNotExist(err) {
+			return nil, err
+		}
+		review.Reviewer = models.NewGhostUser()
+	}
+	if err := comment.LoadAttachments(); err != nil {
+		return nil, err
+	}
+	return toPullReviewComment(review, comment, doer), nil
+}
+
+func toPullReviewComment(review *models.Review, comment *models.Comment, doer *models.User) *api.PullReviewComment {
+	auth := false
+	if doer != nil {
+		auth = doer.IsAdmin || doer.ID == review.ReviewerID
+	}
+
+	apiComment := &api.PullReviewComment{
+		ID:                 comment.ID,
+		Body:               comment.Content,
+		Reviewer:           ToUser(review.Reviewer, doer != nil, auth),
+		ReviewID:           review.ID,
+		Created:            comment.CreatedUnix.AsTime(),
+		Updated:            comment.UpdatedUnix.AsTime(),
+		Path:               comment.TreePath,
+		CommitID:           comment.CommitSHA,
+		OrigCommitID:       comment.OldRef,
+		DiffHunk:           patch2diff(comment.Patch),
+		SuggestionCommitID: comment.SuggestionCommitSHA,
+		HTMLURL:            comment.HTMLURL(),
+		HTMLPullURL:        review.Issue.HTMLURL(),
+	}
+
+	if comment.Line < 0 {
+		apiComment.OldLineNum = comment.UnsignedLine()
+		if comment.IsMultiLine() {
+			apiComment.OldStartLineNum = comment.UnsignedStartLine()
+		}
+	} else {
+		apiComment.LineNum = comment.UnsignedLine()
+		if comment.IsMultiLine() {
+			apiComment.StartLineNum = comment.UnsignedStartLine()
+		}
+	}
+	if suggestion, ok := comment.Suggestion(); ok {
+		apiComment.Suggestion = &suggestion
+	}
+	apiComment.Attachments = make([]*api.Attachment, 0, len(comment.Attachments))
+	for _, attach := range comment.Attachments {
+		apiComment.Attachments = append(apiComment.Attachments, ToReleaseAttachment(attach))
+	}
+	return apiComment
+}
+
 func patch2diff(patch string) string {
 	split := strings.Split(patch, "\n@@")
 	if len(split) == 2 {
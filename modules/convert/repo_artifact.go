@@ -0,0 +1,30 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToArtifact converts a models.RepoArtifact to api.Artifact
+func ToArtifact(a *models.RepoArtifact) *api.Artifact {
+	artifact := &api.Artifact{
+		ID:            a.ID,
+		Name:          a.Name,
+		Size:          a.Size,
+		DownloadCount: a.DownloadCount,
+		SHA:           a.CommitSHA,
+		Ref:           a.Ref,
+		Uploader:      ToUser(a.Uploader, false, false),
+		Created:       a.CreatedUnix.AsTime(),
+		DownloadURL:   a.HTMLURL(),
+	}
+	if a.ExpiredUnix > 0 {
+		expires := a.ExpiredUnix.AsTime()
+		artifact.Expires = &expires
+	}
+	return artifact
+}
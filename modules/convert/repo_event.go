@@ -0,0 +1,22 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToRepoEvent converts a models.RepoEvent to api.RepoEvent
+func ToRepoEvent(e *models.RepoEvent) *api.RepoEvent {
+	return &api.RepoEvent{
+		ID:      e.ID,
+		Type:    e.Type.Name(),
+		Doer:    ToUser(e.Doer, false, false),
+		Target:  e.Target,
+		Content: e.Content,
+		Created: e.CreatedUnix.AsTime(),
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToRepoWorkspace returns the workspace metadata of repo, devcontainerPath and gitpodConfigPath are the paths of its
+// development container and Gitpod configurations, if any
+func ToRepoWorkspace(repo *models.Repository, devcontainerPath, gitpodConfigPath string) *api.RepoWorkspace {
+	cloneLink := repo.CloneLink()
+	workspace := &api.RepoWorkspace{
+		CloneURL:         cloneLink.HTTPS,
+		SSHURL:           cloneLink.SSH,
+		DefaultBranch:    repo.DefaultBranch,
+		HasDevcontainer:  len(devcontainerPath) > 0,
+		DevcontainerPath: devcontainerPath,
+		HasGitpod:        len(gitpodConfigPath) > 0,
+		GitpodConfigPath: gitpodConfigPath,
+		IDELinks:         make([]*api.IDELink, 0, len(setting.IDE.AppList)),
+	}
+	if workspace.HasGitpod {
+		workspace.GitpodURL = setting.GitpodLink(repo.HTMLURL())
+	}
+
+	cloneURL := cloneLink.HTTPS
+	if setting.Repository.DisableHTTPGit {
+		cloneURL = cloneLink.SSH
+	}
+	for _, app := range setting.IDE.AppList {
+		workspace.IDELinks = append(workspace.IDELinks, &api.IDELink{
+			Name:        app.Name,
+			DisplayName: app.DisplayName,
+			URL:         app.Link(cloneURL),
+		})
+	}
+	return workspace
+}
@@ -5,8 +5,11 @@
 package convert
 
 import (
+	"time"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 )
 
@@ -34,6 +37,33 @@ func ToUser(user *models.User, signed, authed bool) *api.User {
 		result.IsAdmin = user.IsAdmin
 		result.LastLogin = user.LastLoginUnix.AsTime()
 		result.Language = user.Language
+		return result
+	}
+	// the others only get the personal fields the privacy settings expose
+	if !setting.Privacy.PublicUserFields["full_name"] {
+		result.FullName = ""
+	}
+	if !setting.Privacy.PublicUserFields["email"] {
+		result.Email = user.NoReplyEmail()
+	}
+	if !setting.Privacy.PublicUserFields["avatar_url"] {
+		result.AvatarURL = models.DefaultAvatarLink()
+	}
+	if !setting.Privacy.PublicUserFields["created"] {
+		result.Created = time.Time{}
 	}
 	return result
 }
+
+// ToUserProfileField convert models.UserProfileField to api.UserProfileField
+func ToUserProfileField(field *models.UserProfileField) *api.UserProfileField {
+	return &api.UserProfileField{
+		Name:        field.Name,
+		Label:       field.Label,
+		Description: field.Description,
+		Type:        field.Type.Name(),
+		Visibility:  field.Visibility.String(),
+		Required:    field.IsRequired,
+		Value:       field.Value,
+	}
+}
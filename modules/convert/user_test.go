@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -26,3 +28,22 @@ func TestUser_ToUser(t *testing.T) {
 	apiUser = ToUser(user1, false, false)
 	assert.False(t, apiUser.IsAdmin)
 }
+
+func TestUser_ToUserPublicFields(t *testing.T) {
+	defer func(fields map[string]bool) {
+		setting.Privacy.PublicUserFields = fields
+	}(setting.Privacy.PublicUserFields)
+	setting.Privacy.PublicUserFields = map[string]bool{"full_name": true}
+
+	user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	apiUser := ToUser(user2, true, false)
+	assert.NotEmpty(t, apiUser.FullName)
+	assert.Equal(t, user2.NoReplyEmail(), apiUser.Email)
+	assert.Equal(t, models.DefaultAvatarLink(), apiUser.AvatarURL)
+	assert.True(t, apiUser.Created.IsZero())
+
+	// the user and the site administrators still get all the fields
+	apiUser = ToUser(user2, true, true)
+	assert.Equal(t, user2.Email, apiUser.Email)
+	assert.False(t, apiUser.Created.IsZero())
+}
@@ -33,6 +33,8 @@ func ToGitServiceType(value string) structs.GitServiceType {
 		return structs.GitlabService
 	case "gogs":
 		return structs.GogsService
+	case "bitbucketserver":
+		return structs.BitbucketServerService
 	default:
 		return structs.PlainGitService
 	}
@@ -6,13 +6,20 @@ package cron
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/migrations"
 	repository_service "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/instancestats"
+	issue_service "code.gitea.io/gitea/services/issue"
 	mirror_service "code.gitea.io/gitea/services/mirror"
+	packages_service "code.gitea.io/gitea/services/packages"
+	pull_service "code.gitea.io/gitea/services/pull"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 func registerUpdateMirrorTask() {
@@ -29,8 +36,10 @@ func registerUpdateMirrorTask() {
 func registerRepoHealthCheck() {
 	type RepoHealthCheckConfig struct {
 		BaseConfig
-		Timeout time.Duration
-		Args    []string `delim:" "`
+		Timeout     time.Duration
+		Args        []string `delim:" "`
+		BatchSize   int
+		MaxDuration time.Duration
 	}
 	RegisterTaskFatal("repo_health_check", &RepoHealthCheckConfig{
 		BaseConfig: BaseConfig{
@@ -42,7 +51,17 @@ func registerRepoHealthCheck() {
 		Args:    []string{},
 	}, func(ctx context.Context, _ *models.User, config Config) error {
 		rhcConfig := config.(*RepoHealthCheckConfig)
-		return repository_service.GitFsck(ctx, rhcConfig.Timeout, rhcConfig.Args)
+		return repository_service.GitFsck(ctx, rhcConfig.Timeout, rhcConfig.Args, rhcConfig.BatchSize, rhcConfig.MaxDuration)
+	})
+}
+
+func registerRepoMaintenance() {
+	RegisterTaskFatal("repo_maintenance", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@every 24h",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return repo_service.QueueAllReposMaintenance(ctx)
 	})
 }
 
@@ -66,7 +85,7 @@ func registerArchiveCleanup() {
 		OlderThan: 24 * time.Hour,
 	}, func(ctx context.Context, _ *models.User, config Config) error {
 		acConfig := config.(*OlderThanConfig)
-		return models.DeleteOldRepositoryArchives(ctx, acConfig.OlderThan)
+		return models.DeleteOldRepositoryArchives(ctx, acConfig.OlderThan, setting.RepoArchive.MaxCacheSize)
 	})
 }
 
@@ -84,6 +103,36 @@ func registerSyncExternalUsers() {
 	})
 }
 
+func registerSyncLDAPGroupTeams() {
+	RegisterTaskFatal("sync_ldap_group_teams", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@every 1h",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return models.SyncLDAPGroupTeams(ctx)
+	})
+}
+
+func registerUpdateStorageStatistics() {
+	type UpdateStorageStatisticsConfig struct {
+		BaseConfig
+		OlderThan     time.Duration
+		NumTopEntries int
+	}
+	RegisterTaskFatal("update_storage_statistics", &UpdateStorageStatisticsConfig{
+		BaseConfig: BaseConfig{
+			Enabled:    true,
+			RunAtStart: true,
+			Schedule:   "@every 24h",
+		},
+		OlderThan:     365 * 24 * time.Hour,
+		NumTopEntries: 10,
+	}, func(ctx context.Context, _ *models.User, config Config) error {
+		ussConfig := config.(*UpdateStorageStatisticsConfig)
+		return models.UpdateStorageStatistics(ctx, ussConfig.NumTopEntries, ussConfig.OlderThan)
+	})
+}
+
 func registerDeletedBranchesCleanup() {
 	RegisterTaskFatal("deleted_branches_cleanup", &OlderThanConfig{
 		BaseConfig: BaseConfig{
@@ -99,6 +148,50 @@ func registerDeletedBranchesCleanup() {
 	})
 }
 
+func registerCheckMergeQueues() {
+	RegisterTaskFatal("check_merge_queues", &BaseConfig{
+		Enabled:         true,
+		RunAtStart:      false,
+		Schedule:        "@every 10m",
+		NoSuccessNotice: true,
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return pull_service.CheckMergeQueues(ctx)
+	})
+}
+
+func registerCreateRecurringIssues() {
+	RegisterTaskFatal("create_recurring_issues", &BaseConfig{
+		Enabled:         true,
+		RunAtStart:      false,
+		Schedule:        "@every 1m",
+		NoSuccessNotice: true,
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return issue_service.CreateDueRecurringIssues(ctx)
+	})
+}
+
+func registerSendDueDateReminders() {
+	RegisterTaskFatal("send_due_date_reminders", &BaseConfig{
+		Enabled:         true,
+		RunAtStart:      false,
+		Schedule:        "@every 1h",
+		NoSuccessNotice: true,
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return issue_service.SendDueDateReminders(ctx)
+	})
+}
+
+func registerCheckIssueSLAs() {
+	RegisterTaskFatal("check_issue_slas", &BaseConfig{
+		Enabled:         true,
+		RunAtStart:      false,
+		Schedule:        "@every 10m",
+		NoSuccessNotice: true,
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return issue_service.CheckIssueSLAs(ctx)
+	})
+}
+
 func registerUpdateMigrationPosterID() {
 	RegisterTaskFatal("update_migration_poster_id", &BaseConfig{
 		Enabled:    true,
@@ -125,15 +218,114 @@ func registerCleanupHookTaskTable() {
 	})
 }
 
+func registerDeleteOldAPIUsage() {
+	RegisterTaskFatal("delete_old_api_usage", &OlderThanConfig{
+		BaseConfig: BaseConfig{
+			Enabled:    true,
+			RunAtStart: false,
+			Schedule:   "@every 24h",
+		},
+		OlderThan: models.APIUsageDays * 24 * time.Hour,
+	}, func(ctx context.Context, _ *models.User, config Config) error {
+		olderThanConfig := config.(*OlderThanConfig)
+		return models.DeleteOldAPIUsage(ctx, olderThanConfig.OlderThan)
+	})
+}
+
+func registerDeleteExpiredArtifacts() {
+	RegisterTaskFatal("delete_expired_artifacts", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@every 24h",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return models.DeleteExpiredRepoArtifacts(ctx)
+	})
+}
+
+func registerCleanupPackages() {
+	RegisterTaskFatal("cleanup_packages", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@every 24h",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return packages_service.Cleanup(ctx)
+	})
+}
+
+func registerGarbageCollectPackages() {
+	type GarbageCollectPackagesConfig struct {
+		BaseConfig
+		EnforceQuotas bool
+	}
+	RegisterTaskFatal("gc_packages", &GarbageCollectPackagesConfig{
+		BaseConfig: BaseConfig{
+			Enabled:    true,
+			RunAtStart: false,
+			Schedule:   "@every 24h",
+		},
+		EnforceQuotas: false,
+	}, func(ctx context.Context, _ *models.User, config Config) error {
+		gcConfig := config.(*GarbageCollectPackagesConfig)
+		report, err := packages_service.GarbageCollect(ctx, packages_service.GarbageCollectOptions{
+			EnforceQuotas: gcConfig.EnforceQuotas,
+		})
+		if err != nil {
+			return err
+		}
+		for _, blob := range report.UnreferencedBlobs {
+			log.Info("Unreferenced container blob deleted: %s", blob)
+		}
+		for _, exceeded := range report.ExceededQuotas {
+			if len(exceeded.DeletedVersions) > 0 {
+				log.Info("Package versions of %s deleted to enforce the quota of %d bytes: %s", exceeded.Owner.Name, exceeded.Limit, strings.Join(exceeded.DeletedVersions, ", "))
+			} else {
+				log.Warn("The packages of %s take %d bytes, which exceeds the quota of %d bytes", exceeded.Owner.Name, exceeded.Size, exceeded.Limit)
+			}
+		}
+		return nil
+	})
+}
+
+func registerReportInstanceStats() {
+	RegisterTaskFatal("report_instance_stats", &BaseConfig{
+		Enabled:         true,
+		RunAtStart:      true,
+		Schedule:        "@every 24h",
+		NoSuccessNotice: true,
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return instancestats.Report(ctx)
+	})
+}
+
 func initBasicTasks() {
 	registerUpdateMirrorTask()
 	registerRepoHealthCheck()
+	if setting.Repository.Maintenance.Enabled {
+		registerRepoMaintenance()
+	}
 	registerCheckRepoStats()
 	registerArchiveCleanup()
 	registerSyncExternalUsers()
+	registerSyncLDAPGroupTeams()
+	registerUpdateStorageStatistics()
 	registerDeletedBranchesCleanup()
+	registerCheckMergeQueues()
+	registerCreateRecurringIssues()
+	registerSendDueDateReminders()
+	registerCheckIssueSLAs()
 	if !setting.Repository.DisableMigrations {
 		registerUpdateMigrationPosterID()
 	}
 	registerCleanupHookTaskTable()
+	registerDeleteOldAPIUsage()
+	if setting.Artifacts.Enabled {
+		registerDeleteExpiredArtifacts()
+	}
+	if setting.Packages.Enabled {
+		registerCleanupPackages()
+		registerGarbageCollectPackages()
+	}
+	if setting.InstanceStats.Enabled {
+		registerReportInstanceStats()
+	}
 }
@@ -9,8 +9,10 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/mailer"
 )
 
 func registerDeleteInactiveUsers() {
@@ -117,6 +119,40 @@ func registerRemoveRandomAvatars() {
 	})
 }
 
+func registerCheckStorageConsistency() {
+	type StorageConsistencyConfig struct {
+		BaseConfig
+		Cleanup      bool
+		NotifyAdmins bool
+	}
+	RegisterTaskFatal("check_storage_consistency", &StorageConsistencyConfig{
+		BaseConfig: BaseConfig{
+			Enabled:    false,
+			RunAtStart: false,
+			Schedule:   "@every 168h",
+		},
+		Cleanup:      false,
+		NotifyAdmins: true,
+	}, func(ctx context.Context, _ *models.User, config Config) error {
+		scConfig := config.(*StorageConsistencyConfig)
+		reports, err := models.CheckStorageConsistency(ctx, scConfig.Cleanup)
+		if err != nil {
+			return err
+		}
+		consistent := true
+		for _, report := range reports {
+			if !report.IsConsistent() {
+				log.Warn("Storage %s: %d objects without records, %d records of missing objects", report.Storage, len(report.OrphanedObjects), len(report.MissingObjects))
+				consistent = false
+			}
+		}
+		if consistent || !scConfig.NotifyAdmins {
+			return nil
+		}
+		return mailer.SendStorageConsistencyMail(reports)
+	})
+}
+
 func initExtendedTasks() {
 	registerDeleteInactiveUsers()
 	registerDeleteRepositoryArchives()
@@ -127,4 +163,5 @@ func initExtendedTasks() {
 	registerReinitMissingRepositories()
 	registerDeleteMissingRepositories()
 	registerRemoveRandomAvatars()
+	registerCheckStorageConsistency()
 }
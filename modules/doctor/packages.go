@@ -0,0 +1,76 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package doctor
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/migrations"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	packages_service "code.gitea.io/gitea/services/packages"
+)
+
+func garbageCollectPackages(logger log.Logger, autofix bool) error {
+	if !setting.Packages.Enabled {
+		logger.Info("The package registry is disabled")
+		return nil
+	}
+	if err := models.NewEngine(context.Background(), migrations.EnsureUpToDate); err != nil {
+		logger.Critical("Model version on the database does not match the current Gitea version. Packages will not be checked until the database is upgraded")
+		return err
+	}
+	if err := storage.Init(); err != nil {
+		logger.Critical("Error: %v whilst initializing the storages", err)
+		return err
+	}
+
+	report, err := packages_service.GarbageCollect(context.Background(), packages_service.GarbageCollectOptions{
+		DryRun:        !autofix,
+		EnforceQuotas: autofix,
+	})
+	if err != nil {
+		logger.Critical("Error: %v whilst collecting the garbage of the package registry", err)
+		return err
+	}
+
+	var size int64
+	for _, blob := range report.UnreferencedBlobs {
+		size += blob.Size
+		if autofix {
+			logger.Info("Unreferenced container blob %s deleted", blob)
+		} else {
+			logger.Warn("Container blob %s is referenced by no tag", blob)
+		}
+	}
+	if len(report.UnreferencedBlobs) > 0 {
+		logger.Info("%d unreferenced container blobs take %d bytes", len(report.UnreferencedBlobs), size)
+	}
+	for _, exceeded := range report.ExceededQuotas {
+		if !autofix {
+			logger.Warn("The packages of %s take %d bytes, which exceeds the quota of %d bytes", exceeded.Owner.Name, exceeded.Size, exceeded.Limit)
+			continue
+		}
+		for _, version := range exceeded.DeletedVersions {
+			logger.Info("Package version %s of %s deleted to enforce the quota", version, exceeded.Owner.Name)
+		}
+		if exceeded.Size > exceeded.Limit {
+			logger.Warn("The packages of %s still take %d bytes, which exceeds the quota of %d bytes", exceeded.Owner.Name, exceeded.Size, exceeded.Limit)
+		}
+	}
+	return nil
+}
+
+func init() {
+	Register(&Check{
+		Title:     "Garbage collect container images and check package quotas",
+		Name:      "packages-gc",
+		IsDefault: false,
+		Run:       garbageCollectPackages,
+		Priority:  9,
+	})
+}
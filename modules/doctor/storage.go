@@ -0,0 +1,62 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package doctor
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/migrations"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/storage"
+)
+
+func checkStorageConsistency(logger log.Logger, autofix bool) error {
+	if err := models.NewEngine(context.Background(), migrations.EnsureUpToDate); err != nil {
+		logger.Critical("Model version on the database does not match the current Gitea version. Storage consistency will not be checked until the database is upgraded")
+		return err
+	}
+	if err := storage.Init(); err != nil {
+		logger.Critical("Error: %v whilst initializing the storages", err)
+		return err
+	}
+
+	reports, err := models.CheckStorageConsistency(context.Background(), autofix)
+	if err != nil {
+		logger.Critical("Error: %v whilst checking the storage consistency", err)
+		return err
+	}
+	for _, report := range reports {
+		if report.IsConsistent() {
+			logger.Info("%s: %d records and %d objects are consistent", report.Storage, report.NumRecords, report.NumObjects)
+			continue
+		}
+		for _, p := range report.OrphanedObjects {
+			if autofix {
+				logger.Info("%s: object %s without record deleted", report.Storage, p)
+			} else {
+				logger.Warn("%s: object %s has no record", report.Storage, p)
+			}
+		}
+		for _, p := range report.MissingObjects {
+			if autofix {
+				logger.Info("%s: record of missing object %s removed", report.Storage, p)
+			} else {
+				logger.Warn("%s: object %s of a record is missing", report.Storage, p)
+			}
+		}
+	}
+	return nil
+}
+
+func init() {
+	Register(&Check{
+		Title:     "Check consistency of database records and storages",
+		Name:      "storage-consistency",
+		IsDefault: false,
+		Run:       checkStorageConsistency,
+		Priority:  8,
+	})
+}
@@ -6,6 +6,7 @@ package eventsource
 
 import (
 	"sync"
+	"time"
 )
 
 // Manager manages the eventsource Messengers
@@ -13,13 +14,23 @@ type Manager struct {
 	mutex sync.Mutex
 
 	messengers map[int64]*Messenger
+
+	topicMutex sync.Mutex
+	// subscriptions are the users subscribed to the topics and when their subscriptions expire
+	subscriptions map[string]map[int64]time.Time
 }
 
 var manager *Manager
 
 func init() {
-	manager = &Manager{
-		messengers: make(map[int64]*Messenger),
+	manager = NewManager()
+}
+
+// NewManager creates a Manager whose messengers are keyed by an ID other than the user ID, e.g. a repository ID
+func NewManager() *Manager {
+	return &Manager{
+		messengers:    make(map[int64]*Messenger),
+		subscriptions: make(map[string]map[int64]time.Time),
 	}
 }
 
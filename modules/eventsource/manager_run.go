@@ -47,6 +47,7 @@ loop:
 				})
 			}
 			then = now
+			m.pruneSubscriptions()
 		}
 	}
 	m.UnregisterAll()
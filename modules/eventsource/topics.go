@@ -0,0 +1,178 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package eventsource
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/git"
+)
+
+// LiveUpdateEventName is the name of the events which tell the subscribers of a topic that it has changed
+const LiveUpdateEventName = "live-update"
+
+// TopicSubscriptionTimeout is how long a subscription to a topic lasts unless it is renewed
+const TopicSubscriptionTimeout = 2 * time.Minute
+
+// The kinds of topics which can be subscribed to
+const (
+	// TopicIssue changes when a comment is added to an issue or pull request
+	TopicIssue = "issue"
+	// TopicPull changes when the state shown in the merge box of a pull request changes
+	TopicPull = "pull"
+	// TopicCommitStatus changes when a status is added to a commit of a repository
+	TopicCommitStatus = "commit-status"
+)
+
+// Topic represents something the users can subscribe to changes of
+type Topic struct {
+	Kind string
+	// ID is the ID of the issue for issues and pull requests, the ID of the repository for commit statuses
+	ID  int64
+	SHA string
+}
+
+// IssueTopic returns the topic of the comments of an issue or pull request
+func IssueTopic(issueID int64) Topic {
+	return Topic{Kind: TopicIssue, ID: issueID}
+}
+
+// PullTopic returns the topic of the merge box of a pull request
+func PullTopic(issueID int64) Topic {
+	return Topic{Kind: TopicPull, ID: issueID}
+}
+
+// CommitStatusTopic returns the topic of the statuses of a commit
+func CommitStatusTopic(repoID int64, sha string) Topic {
+	return Topic{Kind: TopicCommitStatus, ID: repoID, SHA: sha}
+}
+
+// String returns the topic in the form the clients subscribe to it, e.g. "issue:1" or "commit-status:1:<sha>"
+func (t Topic) String() string {
+	if t.Kind == TopicCommitStatus {
+		return fmt.Sprintf("%s:%d:%s", t.Kind, t.ID, t.SHA)
+	}
+	return fmt.Sprintf("%s:%d", t.Kind, t.ID)
+}
+
+// ParseTopic parses a topic returned by Topic.String, false if it is not valid
+func ParseTopic(s string) (Topic, bool) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 {
+		return Topic{}, false
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || id <= 0 {
+		return Topic{}, false
+	}
+
+	switch parts[0] {
+	case TopicIssue, TopicPull:
+		if len(parts) == 2 {
+			return Topic{Kind: parts[0], ID: id}, true
+		}
+	case TopicCommitStatus:
+		if len(parts) == 3 && git.SHAPattern.MatchString(parts[2]) {
+			return Topic{Kind: parts[0], ID: id, SHA: parts[2]}, true
+		}
+	}
+	return Topic{}, false
+}
+
+// LiveUpdate is the data of a live update event
+type LiveUpdate struct {
+	Topic string `json:"topic"`
+	// Type is what has changed, e.g. "comment" or "merged"
+	Type string `json:"type"`
+	// DoerID is the ID of the user who caused the change, 0 if it was not caused by a user
+	DoerID int64 `json:"doer_id"`
+}
+
+// Subscribe subscribes a user to the changes of a topic until TopicSubscriptionTimeout has passed without
+// the subscription being renewed. The changes are sent to all event streams of the user.
+func (m *Manager) Subscribe(uid int64, topic Topic) {
+	key := topic.String()
+
+	m.topicMutex.Lock()
+	defer m.topicMutex.Unlock()
+	subscribers, ok := m.subscriptions[key]
+	if !ok {
+		subscribers = make(map[int64]time.Time)
+		m.subscriptions[key] = subscribers
+	}
+	subscribers[uid] = time.Now().Add(TopicSubscriptionTimeout)
+}
+
+// Unsubscribe unsubscribes a user from the changes of a topic
+func (m *Manager) Unsubscribe(uid int64, topic Topic) {
+	key := topic.String()
+
+	m.topicMutex.Lock()
+	defer m.topicMutex.Unlock()
+	if subscribers, ok := m.subscriptions[key]; ok {
+		delete(subscribers, uid)
+		if len(subscribers) == 0 {
+			delete(m.subscriptions, key)
+		}
+	}
+}
+
+// Subscribers returns the IDs of the users subscribed to a topic
+func (m *Manager) Subscribers(topic Topic) []int64 {
+	key := topic.String()
+	now := time.Now()
+
+	m.topicMutex.Lock()
+	defer m.topicMutex.Unlock()
+	subscribers := m.subscriptions[key]
+	uids := make([]int64, 0, len(subscribers))
+	for uid, expires := range subscribers {
+		if expires.Before(now) {
+			delete(subscribers, uid)
+			continue
+		}
+		uids = append(uids, uid)
+	}
+	if len(subscribers) == 0 {
+		delete(m.subscriptions, key)
+	}
+	return uids
+}
+
+// pruneSubscriptions removes the subscriptions which were not renewed in time
+func (m *Manager) pruneSubscriptions() {
+	now := time.Now()
+
+	m.topicMutex.Lock()
+	defer m.topicMutex.Unlock()
+	for key, subscribers := range m.subscriptions {
+		for uid, expires := range subscribers {
+			if expires.Before(now) {
+				delete(subscribers, uid)
+			}
+		}
+		if len(subscribers) == 0 {
+			delete(m.subscriptions, key)
+		}
+	}
+}
+
+// Publish tells the users subscribed to a topic that it has changed
+func (m *Manager) Publish(topic Topic, typ string, doerID int64) {
+	event := &Event{
+		Name: LiveUpdateEventName,
+		Data: &LiveUpdate{
+			Topic:  topic.String(),
+			Type:   typ,
+			DoerID: doerID,
+		},
+	}
+	for _, uid := range m.Subscribers(topic) {
+		m.SendMessage(uid, event)
+	}
+}
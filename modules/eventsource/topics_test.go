@@ -0,0 +1,59 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package eventsource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTopic(t *testing.T) {
+	sha := "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+	for _, topic := range []Topic{IssueTopic(1), PullTopic(2), CommitStatusTopic(3, sha)} {
+		parsed, ok := ParseTopic(topic.String())
+		assert.True(t, ok)
+		assert.Equal(t, topic, parsed)
+	}
+	assert.Equal(t, "commit-status:3:"+sha, CommitStatusTopic(3, sha).String())
+
+	for _, s := range []string{"", "issue", "issue:0", "issue:a", "issue:1:" + sha, "repo:1", "commit-status:1", "commit-status:1:nosha"} {
+		_, ok := ParseTopic(s)
+		assert.False(t, ok, s)
+	}
+}
+
+func TestManager_Publish(t *testing.T) {
+	m := NewManager()
+	subscriber := m.Register(1)
+	other := m.Register(2)
+
+	m.Subscribe(1, IssueTopic(1))
+	m.Subscribe(2, IssueTopic(2))
+	assert.Equal(t, []int64{1}, m.Subscribers(IssueTopic(1)))
+
+	m.Publish(IssueTopic(1), "comment", 2)
+	select {
+	case event := <-subscriber:
+		assert.Equal(t, LiveUpdateEventName, event.Name)
+		assert.Equal(t, &LiveUpdate{Topic: "issue:1", Type: "comment", DoerID: 2}, event.Data)
+	case <-time.After(time.Second):
+		assert.Fail(t, "the subscriber has not been told about the update")
+	}
+	select {
+	case <-other:
+		assert.Fail(t, "a user who is not subscribed has been told about the update")
+	default:
+	}
+
+	m.Unsubscribe(1, IssueTopic(1))
+	assert.Empty(t, m.Subscribers(IssueTopic(1)))
+
+	// expired subscriptions are forgotten
+	m.subscriptions[IssueTopic(2).String()][2] = time.Now().Add(-time.Second)
+	m.pruneSubscriptions()
+	assert.Empty(t, m.subscriptions)
+}
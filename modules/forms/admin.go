@@ -68,3 +68,33 @@ func (f *AdminDashboardForm) Validate(req *http.Request, errs binding.Errors) bi
 	ctx := context.GetContext(req)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// AdminProfileFieldForm form for admin to create or edit a profile field
+type AdminProfileFieldForm struct {
+	Name        string `binding:"Required;AlphaDashDot;MaxSize(50)"`
+	Label       string `binding:"Required;MaxSize(100)"`
+	Description string `binding:"MaxSize(255)"`
+	Type        int    `binding:"Range(0,1)"`
+	Visibility  int    `binding:"Range(0,2)"`
+	IsRequired  bool
+	SortOrder   int
+}
+
+// Validate validates form fields
+func (f *AdminProfileFieldForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// AdminPolicyDocumentForm form for admin to publish a new version of a policy document
+type AdminPolicyDocumentForm struct {
+	Kind    int    `binding:"Range(0,1)"`
+	Title   string `binding:"Required;MaxSize(255)"`
+	Content string `binding:"Required"`
+}
+
+// Validate validates form fields
+func (f *AdminPolicyDocumentForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
@@ -16,7 +16,7 @@ import (
 // AuthenticationForm form for authentication
 type AuthenticationForm struct {
 	ID                            int64
-	Type                          int    `binding:"Range(2,7)"`
+	Type                          int    `binding:"Range(2,8)"`
 	Name                          string `binding:"Required;MaxSize(30)"`
 	Host                          string
 	Port                          int
@@ -37,8 +37,11 @@ type AuthenticationForm struct {
 	GroupsEnabled                 bool
 	GroupDN                       string
 	GroupFilter                   string
+	NestedGroupFilter             string
 	GroupMemberUID                string
 	UserUID                       string
+	GroupTeamMap                  string
+	GroupTeamMapRemoval           bool
 	RestrictedFilter              string
 	AllowDeactivateAll            bool
 	IsActive                      bool
@@ -66,6 +69,17 @@ type AuthenticationForm struct {
 	SSPIStripDomainNames          bool
 	SSPISeparatorReplacement      string `binding:"AlphaDashDot;MaxSize(5)"`
 	SSPIDefaultLanguage           string
+	SAMLIdentityProviderEntityID  string
+	SAMLIdentityProviderURL       string
+	SAMLIdentityProviderCert      string
+	SAMLServiceProviderEntityID   string
+	SAMLNameIDFormat              string
+	SAMLAttributeUsername         string
+	SAMLAttributeEmail            string
+	SAMLAttributeFullName         string
+	SAMLAttributeGroups           string
+	SAMLGroupTeamMap              string
+	SAMLGroupTeamMapRemoval       bool
 }
 
 // Validate validates fields
@@ -80,6 +80,7 @@ type MigrateRepoForm struct {
 	PullRequests   bool   `json:"pull_requests"`
 	Releases       bool   `json:"releases"`
 	MirrorInterval string `json:"mirror_interval"`
+	ConvertCI      bool   `json:"convert_ci"`
 }
 
 // Validate validates the fields
@@ -139,18 +140,42 @@ type RepoSettingForm struct {
 	PullsAllowRebase                      bool
 	PullsAllowRebaseMerge                 bool
 	PullsAllowSquash                      bool
+	PullsAllowFastForwardOnly             bool
 	PullsAllowManualMerge                 bool
+	PullsAutoAssignReviewersTeamID        int64
+	PullsAutoAssignReviewersCount         int
+	PullsAutoAssignReviewersStrategy      string
 	EnableAutodetectManualMerge           bool
 	EnableTimetracker                     bool
 	AllowOnlyContributorsToTrackTime      bool
 	EnableIssueDependencies               bool
+	EnableDueDateReminders                bool
+	DueDateReminderDays                   int
 	IsArchived                            bool
 
 	// Signing Settings
 	TrustModel string
 
+	// Maintenance settings
+	IsMaintenance      bool
+	MaintenanceEnd     string
+	MaintenanceMessage string `binding:"MaxSize(255)"`
+
 	// Admin settings
 	EnableHealthCheck bool
+
+	// LFSMigrateAbove is the size in MiB above which files are moved into LFS
+	LFSMigrateAbove int64
+
+	// Export settings
+	ExportService    int
+	ExportURL        string
+	ExportToken      string
+	ExportIssues     bool
+	ExportComments   bool
+	ExportLabels     bool
+	ExportMilestones bool
+	ExportReleases   bool
 }
 
 // Validate validates the fields
@@ -168,26 +193,30 @@ func (f *RepoSettingForm) Validate(req *http.Request, errs binding.Errors) bindi
 
 // ProtectBranchForm form for changing protected branch settings
 type ProtectBranchForm struct {
-	Protected                     bool
-	EnablePush                    string
-	WhitelistUsers                string
-	WhitelistTeams                string
-	WhitelistDeployKeys           bool
-	EnableMergeWhitelist          bool
-	MergeWhitelistUsers           string
-	MergeWhitelistTeams           string
-	EnableStatusCheck             bool
-	StatusCheckContexts           []string
-	RequiredApprovals             int64
-	EnableApprovalsWhitelist      bool
-	ApprovalsWhitelistUsers       string
-	ApprovalsWhitelistTeams       string
-	BlockOnRejectedReviews        bool
-	BlockOnOfficialReviewRequests bool
-	BlockOnOutdatedBranch         bool
-	DismissStaleApprovals         bool
-	RequireSignedCommits          bool
-	ProtectedFilePatterns         string
+	Protected                      bool
+	EnablePush                     string
+	WhitelistUsers                 string
+	WhitelistTeams                 string
+	WhitelistDeployKeys            bool
+	EnableMergeWhitelist           bool
+	MergeWhitelistUsers            string
+	MergeWhitelistTeams            string
+	EnableStatusCheck              bool
+	StatusCheckContexts            []string
+	StatusCheckPatterns            string
+	RequiredApprovals              int64
+	EnableApprovalsWhitelist       bool
+	ApprovalsWhitelistUsers        string
+	ApprovalsWhitelistTeams        string
+	BlockOnRejectedReviews         bool
+	BlockOnOfficialReviewRequests  bool
+	BlockOnOutdatedBranch          bool
+	BlockOnUnresolvedConversations bool
+	RequireCodeOwnerReviews        bool
+	DismissStaleApprovals          bool
+	RequireSignedCommits           bool
+	EnableMergeQueue               bool
+	ProtectedFilePatterns          string
 }
 
 // Validate validates the fields
@@ -386,9 +415,11 @@ type CreateIssueForm struct {
 	Ref         string `form:"ref"`
 	MilestoneID int64
 	ProjectID   int64
+	IssueTypeID int64
 	AssigneeID  int64
 	Content     string
 	Files       []string
+	Template    string `binding:"MaxSize(255)"`
 }
 
 // Validate validates the fields
@@ -457,9 +488,10 @@ func (i IssueLockForm) HasValidReason() bool {
 
 // CreateProjectForm form for creating a project
 type CreateProjectForm struct {
-	Title     string `binding:"Required;MaxSize(100)"`
-	Content   string
-	BoardType models.ProjectBoardType
+	Title        string `binding:"Required;MaxSize(100)"`
+	Content      string
+	BoardType    models.ProjectBoardType
+	SwimlaneType models.ProjectSwimlaneType
 }
 
 // UserCreateProjectForm is a from for creating an individual or organization
@@ -475,6 +507,13 @@ type UserCreateProjectForm struct {
 type EditProjectBoardForm struct {
 	Title   string `binding:"Required;MaxSize(100)"`
 	Sorting int8
+	// the settings are only changed when they are given
+	WIPLimit        *int  `json:"wip_limit"`
+	AutoMoveOnClose *bool `json:"auto_move_on_close"`
+	AutoMoveOnMerge *bool `json:"auto_move_on_merge"`
+	// RepoFilter are the comma separated IDs of the repositories whose issues the board of an organization project
+	// accepts
+	RepoFilter *string `json:"repo_filter"`
 }
 
 //    _____  .__.__                   __
@@ -529,6 +568,53 @@ func (f *InitializeLabelsForm) Validate(req *http.Request, errs binding.Errors)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// IssueTypeForm form for creating or editing an issue type
+type IssueTypeForm struct {
+	ID           int64
+	Name         string `binding:"Required;MaxSize(50)" locale:"repo.issue_types.name"`
+	Description  string `binding:"MaxSize(200)" locale:"repo.issue_types.description"`
+	Color        string `binding:"Required;Size(7)" locale:"repo.issue_types.color"`
+	TemplateFile string `binding:"MaxSize(255)" locale:"repo.issue_types.template_file"`
+}
+
+// Validate validates the fields
+func (f *IssueTypeForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// RecurringIssueForm form for creating or editing a recurring issue
+type RecurringIssueForm struct {
+	ID       int64
+	Title    string `binding:"Required;MaxSize(255)" locale:"repo.recurring_issues.title"`
+	Content  string
+	Labels   string `binding:"MaxSize(1024)"`
+	Schedule string `binding:"Required;MaxSize(100)" locale:"repo.recurring_issues.schedule"`
+	IsActive bool
+}
+
+// Validate validates the fields
+func (f *RecurringIssueForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// IssueSLAForm form for creating or editing an issue SLA
+type IssueSLAForm struct {
+	ID                 int64
+	Name               string `binding:"Required;MaxSize(255)" locale:"repo.slas.name"`
+	LabelID            int64
+	FirstResponseHours int64
+	ResolutionHours    int64
+	BreachLabelID      int64
+}
+
+// Validate validates the fields
+func (f *IssueSLAForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // __________      .__  .__    __________                                     __
 // \______   \__ __|  | |  |   \______   \ ____  ________ __   ____   _______/  |_
 //  |     ___/  |  \  | |  |    |       _// __ \/ ____/  |  \_/ __ \ /  ___/\   __\
@@ -540,12 +626,13 @@ func (f *InitializeLabelsForm) Validate(req *http.Request, errs binding.Errors)
 // swagger:model MergePullRequestOption
 type MergePullRequestForm struct {
 	// required: true
-	// enum: merge,rebase,rebase-merge,squash,manually-merged
-	Do                string `binding:"Required;In(merge,rebase,rebase-merge,squash,manually-merged)"`
-	MergeTitleField   string
-	MergeMessageField string
-	MergeCommitID     string // only used for manually-merged
-	ForceMerge        *bool  `json:"force_merge,omitempty"`
+	// enum: merge,rebase,rebase-merge,squash,fast-forward-only,manually-merged
+	Do                     string `binding:"Required;In(merge,rebase,rebase-merge,squash,fast-forward-only,manually-merged)"`
+	MergeTitleField        string
+	MergeMessageField      string
+	MergeCommitID          string // only used for manually-merged
+	ForceMerge             *bool  `json:"force_merge,omitempty"`
+	MergeWhenChecksSucceed bool   `json:"merge_when_checks_succeed,omitempty"`
 }
 
 // Validate validates the fields
@@ -560,6 +647,7 @@ type CodeCommentForm struct {
 	Content        string `binding:"Required"`
 	Side           string `binding:"Required;In(previous,proposed)"`
 	Line           int64
+	StartLine      int64
 	TreePath       string `form:"path" binding:"Required"`
 	IsReview       bool   `form:"is_review"`
 	Reply          int64  `form:"reply"`
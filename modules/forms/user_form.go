@@ -399,3 +399,46 @@ func (f *U2FDeleteForm) Validate(req *http.Request, errs binding.Errors) binding
 	ctx := context.GetContext(req)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// WebAuthnRegistrationForm for reserving a WebAuthn credential name
+type WebAuthnRegistrationForm struct {
+	Name string `binding:"Required;MaxSize(255)"`
+}
+
+// Validate validates the fields
+func (f *WebAuthnRegistrationForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// WebAuthnDeleteForm for deleting WebAuthn credentials
+type WebAuthnDeleteForm struct {
+	ID int64 `binding:"Required"`
+}
+
+// Validate validates the fields
+func (f *WebAuthnDeleteForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// SaveUserFilterForm form for saving the filters of an issue or pull request list
+type SaveUserFilterForm struct {
+	Name      string `binding:"Required;MaxSize(255)" locale:"repo.issues.save_filter.name"`
+	RepoID    int64
+	IsPull    bool
+	Type      string `binding:"MaxSize(255)"`
+	Sort      string `binding:"MaxSize(255)"`
+	State     string `binding:"OmitEmpty;In(open,closed)"`
+	Q         string `binding:"MaxSize(255)"`
+	Labels    string `binding:"MaxSize(1024)"`
+	Milestone int64
+	Assignee  int64
+	Repos     string `binding:"MaxSize(1024)"`
+}
+
+// Validate validates the fields
+func (f *SaveUserFilterForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
@@ -17,10 +17,10 @@ import (
 type Blob struct {
 	ID SHA1
 
-	gotSize  bool
-	size     int64
-	repoPath string
-	name     string
+	gotSize bool
+	size    int64
+	repo    *Repository
+	name    string
 }
 
 // DataAsync gets a ReadCloser for the contents of a blob without reading it all.
@@ -30,7 +30,7 @@ func (b *Blob) DataAsync() (io.ReadCloser, error) {
 
 	go func() {
 		stderr := &strings.Builder{}
-		err := NewCommand("cat-file", "--batch").RunInDirFullPipeline(b.repoPath, stdoutWriter, stderr, strings.NewReader(b.ID.String()+"\n"))
+		err := NewCommandContext(b.repo.Ctx, "cat-file", "--batch").RunInDirFullPipeline(b.repo.Path, stdoutWriter, stderr, strings.NewReader(b.ID.String()+"\n"))
 		if err != nil {
 			err = ConcatenateError(err, stderr.String())
 			_ = stdoutWriter.CloseWithError(err)
@@ -59,15 +59,15 @@ func (b *Blob) Size() int64 {
 		return b.size
 	}
 
-	size, err := NewCommand("cat-file", "-s", b.ID.String()).RunInDir(b.repoPath)
+	size, err := NewCommandContext(b.repo.Ctx, "cat-file", "-s", b.ID.String()).RunInDir(b.repo.Path)
 	if err != nil {
-		log("error whilst reading size for %s in %s. Error: %v", b.ID.String(), b.repoPath, err)
+		log("error whilst reading size for %s in %s. Error: %v", b.ID.String(), b.repo.Path, err)
 		return 0
 	}
 
 	b.size, err = strconv.ParseInt(size[:len(size)-1], 10, 64)
 	if err != nil {
-		log("error whilst parsing size %s for %s in %s. Error: %v", size, b.ID.String(), b.repoPath, err)
+		log("error whilst parsing size %s for %s in %s. Error: %v", size, b.ID.String(), b.repo.Path, err)
 		return 0
 	}
 	b.gotSize = true
@@ -15,7 +15,14 @@ import (
 	"strings"
 	"time"
 
+	gitealog "code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/util"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -44,6 +51,34 @@ func (c *Command) String() string {
 	return fmt.Sprintf("%s %s", c.name, strings.Join(c.args, " "))
 }
 
+// sanitizedCommand formats a command without the credentials of its URL arguments
+type sanitizedCommand Command
+
+func (c *sanitizedCommand) String() string {
+	args := make([]string, 0, len(c.args)+1)
+	args = append(args, c.name)
+	for _, arg := range c.args {
+		if strings.Contains(arg, "://") {
+			arg = util.SanitizeURLCredentials(arg, true)
+		}
+		args = append(args, arg)
+	}
+	return strings.Join(args, " ")
+}
+
+// subcommand returns the git subcommand, skipping the global options before it
+func (c *Command) subcommand() string {
+	for i := 0; i < len(c.args); i++ {
+		switch {
+		case c.args[i] == "-c" || c.args[i] == "-C":
+			i++
+		case !strings.HasPrefix(c.args[i], "-"):
+			return c.args[i]
+		}
+	}
+	return ""
+}
+
 // NewCommand creates and returns a new Git Command based on given command and arguments.
 func NewCommand(args ...string) *Command {
 	return NewCommandContext(DefaultContext, args...)
@@ -108,7 +143,7 @@ func (c *Command) RunInDirTimeoutEnvFullPipeline(env []string, timeout time.Dura
 
 // RunInDirTimeoutEnvFullPipelineFunc executes the command in given directory with given timeout,
 // it pipes stdout and stderr to given io.Writer and passes in an io.Reader as stdin. Between cmd.Start and cmd.Wait the passed in function is run.
-func (c *Command) RunInDirTimeoutEnvFullPipelineFunc(env []string, timeout time.Duration, dir string, stdout, stderr io.Writer, stdin io.Reader, fn func(context.Context, context.CancelFunc) error) error {
+func (c *Command) RunInDirTimeoutEnvFullPipelineFunc(env []string, timeout time.Duration, dir string, stdout, stderr io.Writer, stdin io.Reader, fn func(context.Context, context.CancelFunc) error) (err error) {
 	if timeout == -1 {
 		timeout = DefaultCommandExecutionTimeout
 	}
@@ -118,8 +153,21 @@ func (c *Command) RunInDirTimeoutEnvFullPipelineFunc(env []string, timeout time.
 	} else {
 		log("%s: %v", dir, c)
 	}
+	gitealog.LogWithContext(c.parentContext, 0, gitealog.TRACE, "Run %s [repo_path: %s]", (*sanitizedCommand)(c), dir)
+
+	ctx, span := otel.Tracer("code.gitea.io/gitea/modules/git").Start(c.parentContext, "git "+c.subcommand(), trace.WithAttributes(
+		label.String("git.command", (*sanitizedCommand)(c).String()),
+		label.String("git.repo_path", dir),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
 
-	ctx, cancel := context.WithTimeout(c.parentContext, timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, c.name, c.args...)
@@ -8,8 +8,11 @@ package git
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 // ArchiveType archive types
@@ -20,6 +23,10 @@ const (
 	ZIP ArchiveType = iota + 1
 	// TARGZ tar gz archive type
 	TARGZ
+	// TARZST tar zstd archive type
+	TARZST
+	// BUNDLE git bundle type, it is created by Repository.CreateBundle instead of Commit.CreateArchive
+	BUNDLE
 )
 
 // String converts an ArchiveType to string
@@ -29,6 +36,10 @@ func (a ArchiveType) String() string {
 		return "zip"
 	case TARGZ:
 		return "tar.gz"
+	case TARZST:
+		return "tar.zst"
+	case BUNDLE:
+		return "bundle"
 	}
 	return "unknown"
 }
@@ -41,7 +52,7 @@ type CreateArchiveOpts struct {
 
 // CreateArchive create archive content to the target path
 func (c *Commit) CreateArchive(ctx context.Context, target string, opts CreateArchiveOpts) error {
-	if opts.Format.String() == "unknown" {
+	if opts.Format.String() == "unknown" || opts.Format == BUNDLE {
 		return fmt.Errorf("unknown format: %v", opts.Format)
 	}
 
@@ -52,6 +63,11 @@ func (c *Commit) CreateArchive(ctx context.Context, target string, opts CreateAr
 		args = append(args, "--prefix="+filepath.Base(strings.TrimSuffix(c.repo.Path, ".git"))+"/")
 	}
 
+	if opts.Format == TARZST {
+		// git archive cannot compress with zstd itself
+		return c.createTarZstArchive(ctx, target, append(args, "--format=tar", c.ID.String()))
+	}
+
 	args = append(args,
 		"--format="+opts.Format.String(),
 		"-o",
@@ -62,3 +78,26 @@ func (c *Commit) CreateArchive(ctx context.Context, target string, opts CreateAr
 	_, err := NewCommandContext(ctx, args...).RunInDir(c.repo.Path)
 	return err
 }
+
+func (c *Commit) createTarZstArchive(ctx context.Context, target string, args []string) error {
+	file, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder, err := zstd.NewWriter(file)
+	if err != nil {
+		return err
+	}
+
+	stderr := new(strings.Builder)
+	if err := NewCommandContext(ctx, args...).RunInDirPipeline(c.repo.Path, encoder, stderr); err != nil {
+		_ = encoder.Close()
+		return ConcatenateError(err, stderr.String())
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -141,7 +141,7 @@ func GetLastCommitForPaths(commit *Commit, treePath string, paths []string) ([]*
 		}
 	}()
 
-	batchStdinWriter, batchReader, cancel := CatFileBatch(commit.repo.Path)
+	batchStdinWriter, batchReader, cancel := CatFileBatch(commit.repo.Ctx, commit.repo.Path)
 	defer cancel()
 
 	mapsize := 4096
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -20,6 +21,27 @@ func TestCommitsCount(t *testing.T) {
 	assert.Equal(t, int64(3), commitsCount)
 }
 
+func TestAuthorCommitsCount(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+
+	commitsCount, err := AuthorCommitsCount(bareRepo1Path, "master", []string{"Tris.Git@shoddynet.org"}, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), commitsCount)
+
+	since := time.Date(2018, 4, 19, 0, 0, 0, 0, time.UTC)
+	commitsCount, err = AuthorCommitsCount(bareRepo1Path, "master", []string{"tris.git@shoddynet.org", "me@silverwind.io"}, since, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), commitsCount)
+
+	commitsCount, err = AuthorCommitsCount(bareRepo1Path, "master", []string{"tris.git@shoddynet.org"}, time.Time{}, since)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), commitsCount)
+
+	commitsCount, err = AuthorCommitsCount(bareRepo1Path, "master", nil, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), commitsCount)
+}
+
 func TestGetFullCommitID(t *testing.T) {
 	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
 
@@ -224,3 +224,34 @@ func Fsck(ctx context.Context, repoPath string, timeout time.Duration, args ...s
 	_, err := NewCommandContext(ctx, "fsck").AddArguments(args...).RunInDirTimeout(timeout, repoPath)
 	return err
 }
+
+// Repack packs the loose objects of a repository into a new pack and removes the redundant objects
+func Repack(ctx context.Context, repoPath string, timeout time.Duration, args ...string) error {
+	if timeout <= 0 {
+		timeout = -1
+	}
+	_, err := NewCommandContext(ctx, "repack", "-d").AddArguments(args...).RunInDirTimeout(timeout, repoPath)
+	return err
+}
+
+// Prune removes the unreachable loose objects which are older than expire, e.g. "2.weeks.ago"
+func Prune(ctx context.Context, repoPath string, timeout time.Duration, expire string) error {
+	if timeout <= 0 {
+		timeout = -1
+	}
+	_, err := NewCommandContext(ctx, "prune", "--expire", expire).RunInDirTimeout(timeout, repoPath)
+	return err
+}
+
+// WriteCommitGraph writes the commit-graph file of the commits reachable from the refs of a repository,
+// which speeds up the walks through the history. It does nothing if the git version is too old.
+func WriteCommitGraph(ctx context.Context, repoPath string, timeout time.Duration) error {
+	if CheckGitVersionAtLeast("2.20") != nil {
+		return nil
+	}
+	if timeout <= 0 {
+		timeout = -1
+	}
+	_, err := NewCommandContext(ctx, "commit-graph", "write", "--reachable").RunInDirTimeout(timeout, repoPath)
+	return err
+}
@@ -7,8 +7,15 @@ package git
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func fatalTestError(fmtStr string, args ...interface{}) {
@@ -24,3 +31,41 @@ func TestMain(m *testing.M) {
 	exitStatus := m.Run()
 	os.Exit(exitStatus)
 }
+
+func TestRepackAndWriteCommitGraph(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "repo-maintenance")
+	assert.NoError(t, err)
+	defer util.RemoveAll(tmpDir)
+	repoPath, err := cloneRepo(filepath.Join(testReposDir, "repo1_bare"), tmpDir, "repo1")
+	assert.NoError(t, err)
+
+	err = NewCommand("hash-object", "-w", "--stdin").RunInDirFullPipeline(repoPath, ioutil.Discard, nil, strings.NewReader("loose"))
+	assert.NoError(t, err)
+	before, err := CountObjects(repoPath)
+	assert.NoError(t, err)
+
+	// the reachable loose objects are packed
+	assert.NoError(t, Repack(context.Background(), repoPath, 0))
+	after, err := CountObjects(repoPath)
+	assert.NoError(t, err)
+	assert.Less(t, after.Count, before.Count)
+	assert.Greater(t, after.InPack, before.InPack)
+
+	// the unreachable ones are pruned
+	assert.NoError(t, Prune(context.Background(), repoPath, 0, "2.weeks.ago"))
+	after, err = CountObjects(repoPath)
+	assert.NoError(t, err)
+	assert.Greater(t, after.Count, int64(0))
+	assert.NoError(t, Prune(context.Background(), repoPath, 0, "now"))
+	after, err = CountObjects(repoPath)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, after.Count)
+
+	if CheckGitVersionAtLeast("2.20") != nil {
+		return
+	}
+	assert.NoError(t, WriteCommitGraph(context.Background(), repoPath, 0))
+	exist, err := util.IsExist(filepath.Join(repoPath, ".git", "objects", "info", "commit-graph"))
+	assert.NoError(t, err)
+	assert.True(t, exist)
+}
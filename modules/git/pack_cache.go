@@ -0,0 +1,296 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultPackCache is the cache of the responses of upload-pack used by the HTTP smart protocol handler,
+// nil if it is disabled
+var DefaultPackCache *PackCache
+
+// PackCache caches the packs sent by upload-pack on disk, so that the identical fetches of popular
+// repositories, like the clones of the same commits, do not generate the same packfiles again and again.
+// The packs of a fetch only depend on the object IDs the client wants and has, so a cached pack can
+// be reused as long as the request is the same and the repository has not changed. The packs of a
+// repository are kept until its refs change, which has to be reported with Invalidate, because the
+// objects they contain may have been removed from the repository since.
+//
+// Like in the RefsCache, every invalidation increments the generation of the repository and a pack is
+// only cached if the generation has not changed since the miss it has been generated after.
+type PackCache struct {
+	dir     string
+	ttl     time.Duration
+	maxSize int64
+
+	mutex   sync.Mutex
+	entries map[string]*packCacheEntry
+	repos   map[string]map[string]struct{}
+	size    int64
+	// generations are kept for all the invalidated repositories, it is only a counter per repository
+	generations map[string]uint64
+
+	hits   int64
+	misses int64
+}
+
+type packCacheEntry struct {
+	repoPath string
+	size     int64
+	created  time.Time
+	lastUsed time.Time
+}
+
+// PackCacheStats are the statistics of a pack cache
+type PackCacheStats struct {
+	Hits    int64
+	Misses  int64
+	Entries int
+	Size    int64
+}
+
+// NewPackCache creates a pack cache in dir whose entries expire after ttl and which is kept below maxSize
+// bytes, -1 for no limit. The packs which were cached by earlier processes are removed.
+func NewPackCache(dir string, ttl time.Duration, maxSize int64) (*PackCache, error) {
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("unable to clear the pack cache %s: %v", dir, err)
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("unable to create the pack cache %s: %v", dir, err)
+	}
+	return &PackCache{
+		dir:     dir,
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: make(map[string]*packCacheEntry),
+		repos:   make(map[string]map[string]struct{}),
+
+		generations: make(map[string]uint64),
+	}, nil
+}
+
+// PackCacheKey returns the key of the response of upload-pack to a request in a repository.
+// The capabilities which identify the client do not change the response, so they are left out.
+func PackCacheKey(repoPath, protocol string, args []string, request []byte) string {
+	hash := sha256.New()
+	for _, part := range append([]string{repoPath, protocol}, args...) {
+		_, _ = fmt.Fprintf(hash, "%d:%s\n", len(part), part)
+	}
+	for len(request) >= 4 {
+		length, err := strconv.ParseUint(string(request[:4]), 16, 16)
+		if err != nil || (length > 2 && (length < 4 || int(length) > len(request))) {
+			break
+		}
+		if length <= 2 {
+			length = 4
+		}
+		if !bytes.HasPrefix(request[4:length], []byte("agent=")) && !bytes.HasPrefix(request[4:length], []byte("session-id=")) {
+			_, _ = hash.Write(request[:length])
+		}
+		request = request[length:]
+	}
+	// Whatever cannot be parsed as packet lines is used as it is
+	_, _ = hash.Write(request)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// IsCacheableFetchRequest returns whether the response of upload-pack to a request of the wire protocol
+// version 2 only depends on the request, which is the case for the last round of the negotiation of a
+// fetch as long as it does not refer to refs by name, which include-tag does implicitly.
+func IsCacheableFetchRequest(request []byte) bool {
+	return bytes.Contains(request, []byte("command=fetch")) &&
+		(bytes.Contains(request, []byte("0009done\n")) || bytes.Contains(request, []byte("0008done"))) &&
+		!bytes.Contains(request, []byte("want-ref ")) &&
+		!bytes.Contains(request, []byte("deepen-not ")) &&
+		!bytes.Contains(request, []byte("include-tag"))
+}
+
+func (c *PackCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key)
+}
+
+// Get returns the cached response for a key of a repository, false if there is none. The generation of the
+// repository has to be passed to Create the entry for the response generated after a miss.
+func (c *PackCache) Get(repoPath, key string) (io.ReadCloser, uint64, bool) {
+	c.mutex.Lock()
+	generation := c.generations[repoPath]
+	entry, ok := c.entries[key]
+	if ok && c.ttl > 0 && time.Since(entry.created) > c.ttl {
+		c.remove(key)
+		ok = false
+	}
+	if !ok {
+		c.mutex.Unlock()
+		atomic.AddInt64(&c.misses, 1)
+		return nil, generation, false
+	}
+	entry.lastUsed = time.Now()
+	// Open the file before unlocking, so that it cannot be evicted in the meantime
+	f, err := os.Open(c.path(key))
+	if err != nil {
+		c.remove(key)
+	}
+	c.mutex.Unlock()
+
+	if err != nil {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, generation, false
+	}
+	atomic.AddInt64(&c.hits, 1)
+	return f, generation, true
+}
+
+// PackCacheWriter writes a response to the pack cache, it has to be either committed or aborted
+type PackCacheWriter struct {
+	cache      *PackCache
+	repoPath   string
+	key        string
+	generation uint64
+	file       *os.File
+	size       int64
+}
+
+// Create returns a writer for the response of a key of a repository, generation is the one returned by Get
+func (c *PackCache) Create(repoPath, key string, generation uint64) (*PackCacheWriter, error) {
+	f, err := ioutil.TempFile(c.dir, "tmp-")
+	if err != nil {
+		return nil, err
+	}
+	return &PackCacheWriter{cache: c, repoPath: repoPath, key: key, generation: generation, file: f}, nil
+}
+
+// Write implements io.Writer
+func (w *PackCacheWriter) Write(p []byte) (int, error) {
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Abort discards the response
+func (w *PackCacheWriter) Abort() {
+	_ = w.file.Close()
+	_ = os.Remove(w.file.Name())
+}
+
+// Commit adds the response to the cache, evicting the least recently used ones if the cache has become too big.
+// It is not cached if the repository has been invalidated since the generation the writer was created with.
+func (w *PackCacheWriter) Commit() error {
+	c := w.cache
+	if err := w.file.Close(); err != nil {
+		_ = os.Remove(w.file.Name())
+		return err
+	}
+	if c.maxSize >= 0 && w.size > c.maxSize {
+		return os.Remove(w.file.Name())
+	}
+	p := c.path(w.key)
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		_ = os.Remove(w.file.Name())
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.generations[w.repoPath] != w.generation {
+		return os.Remove(w.file.Name())
+	}
+	if err := os.Rename(w.file.Name(), p); err != nil {
+		_ = os.Remove(w.file.Name())
+		return err
+	}
+	if old, ok := c.entries[w.key]; ok {
+		c.size -= old.size
+	}
+	now := time.Now()
+	c.entries[w.key] = &packCacheEntry{repoPath: w.repoPath, size: w.size, created: now, lastUsed: now}
+	if c.repos[w.repoPath] == nil {
+		c.repos[w.repoPath] = make(map[string]struct{})
+	}
+	c.repos[w.repoPath][w.key] = struct{}{}
+	c.size += w.size
+	c.evict()
+	return nil
+}
+
+// Invalidate removes the packs of a repository, it has to be called whenever its refs change
+func (c *PackCache) Invalidate(repoPath string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generations[repoPath]++
+	for key := range c.repos[repoPath] {
+		c.remove(key)
+	}
+}
+
+// remove removes an entry, c.mutex has to be held
+func (c *PackCache) remove(key string) {
+	if entry, ok := c.entries[key]; ok {
+		c.size -= entry.size
+		delete(c.entries, key)
+		delete(c.repos[entry.repoPath], key)
+		if len(c.repos[entry.repoPath]) == 0 {
+			delete(c.repos, entry.repoPath)
+		}
+	}
+	_ = os.Remove(c.path(key))
+}
+
+// evict removes the expired entries and then the least recently used ones until the cache is small enough,
+// c.mutex has to be held
+func (c *PackCache) evict() {
+	keys := make([]string, 0, len(c.entries))
+	for key, entry := range c.entries {
+		if c.ttl > 0 && time.Since(entry.created) > c.ttl {
+			c.remove(key)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if c.maxSize < 0 || c.size <= c.maxSize {
+		return
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.entries[keys[i]].lastUsed.Before(c.entries[keys[j]].lastUsed)
+	})
+	for _, key := range keys {
+		if c.size <= c.maxSize {
+			break
+		}
+		c.remove(key)
+	}
+}
+
+// Stats returns the statistics of the cache
+func (c *PackCache) Stats() PackCacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return PackCacheStats{
+		Hits:    atomic.LoadInt64(&c.hits),
+		Misses:  atomic.LoadInt64(&c.misses),
+		Entries: len(c.entries),
+		Size:    c.size,
+	}
+}
+
+// InvalidatePackCache removes the cached packs of a repository if the pack cache is enabled
+func InvalidatePackCache(repoPath string) {
+	if DefaultPackCache != nil {
+		DefaultPackCache.Invalidate(repoPath)
+	}
+}
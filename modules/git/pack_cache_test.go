@@ -0,0 +1,161 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func putPack(t *testing.T, c *PackCache, key, content string) {
+	w, err := c.Create("repo.git", key, c.generations["repo.git"])
+	assert.NoError(t, err)
+	_, err = w.Write([]byte(content))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Commit())
+}
+
+func getPack(t *testing.T, c *PackCache, key string) (string, bool) {
+	r, _, ok := c.Get("repo.git", key)
+	if !ok {
+		return "", false
+	}
+	defer r.Close()
+	content, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	return string(content), true
+}
+
+func TestPackCache(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "pack-cache")
+	assert.NoError(t, err)
+	defer util.RemoveAll(tmpDir)
+
+	c, err := NewPackCache(filepath.Join(tmpDir, "cache"), time.Hour, 10)
+	assert.NoError(t, err)
+
+	key1 := PackCacheKey("repo.git", "version=2", nil, []byte("request 1"))
+	key2 := PackCacheKey("repo.git", "version=2", nil, []byte("request 2"))
+	assert.NotEqual(t, key1, PackCacheKey("other.git", "version=2", nil, []byte("request 1")))
+	assert.Equal(t,
+		PackCacheKey("repo.git", "version=2", nil, []byte("0012command=fetch\n0016agent=git/2.30.0\n00010009done\n0000")),
+		PackCacheKey("repo.git", "version=2", nil, []byte("0012command=fetch\n0016agent=git/2.31.1\n00010009done\n0000")))
+	assert.NotEqual(t,
+		PackCacheKey("repo.git", "version=2", nil, []byte("0012command=fetch\n0001000dthin-pack0009done\n0000")),
+		PackCacheKey("repo.git", "version=2", nil, []byte("0012command=fetch\n00010009done\n0000")))
+
+	_, ok := getPack(t, c, key1)
+	assert.False(t, ok)
+
+	putPack(t, c, key1, "pack1")
+	content, ok := getPack(t, c, key1)
+	assert.True(t, ok)
+	assert.Equal(t, "pack1", content)
+
+	// An aborted response is not cached
+	w, err := c.Create("repo.git", key2, 0)
+	assert.NoError(t, err)
+	_, err = w.Write([]byte("pack2"))
+	assert.NoError(t, err)
+	w.Abort()
+	_, ok = getPack(t, c, key2)
+	assert.False(t, ok)
+
+	// The least recently used pack is evicted when the cache gets too big
+	putPack(t, c, key2, "pack2")
+	_, ok = getPack(t, c, key1)
+	assert.True(t, ok)
+	putPack(t, c, "third", "pack3")
+	_, ok = getPack(t, c, key2)
+	assert.False(t, ok)
+	_, ok = getPack(t, c, key1)
+	assert.True(t, ok)
+
+	// A pack bigger than the cache is not cached
+	putPack(t, c, key2, "a pack which is too big")
+	_, ok = getPack(t, c, key2)
+	assert.False(t, ok)
+
+	stats := c.Stats()
+	assert.EqualValues(t, 3, stats.Hits)
+	assert.EqualValues(t, 4, stats.Misses)
+	assert.Equal(t, 2, stats.Entries)
+	assert.EqualValues(t, 10, stats.Size)
+
+	// The packs expire
+	c.ttl = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	_, ok = getPack(t, c, key1)
+	assert.False(t, ok)
+}
+
+func TestIsCacheableFetchRequest(t *testing.T) {
+	fetch := "0011command=fetch0001000dthin-pack0032want 65f1bf27bc3bf70f64657658635e66094edbcb4d\n"
+	assert.True(t, IsCacheableFetchRequest([]byte(fetch+"0009done\n0000")))
+	// the negotiation is not done yet
+	assert.False(t, IsCacheableFetchRequest([]byte(fetch+"0000")))
+	// the refs can change
+	assert.False(t, IsCacheableFetchRequest([]byte("0014command=ls-refs\n0000")))
+	assert.False(t, IsCacheableFetchRequest([]byte(fetch+"001cwant-ref refs/heads/master\n0009done\n0000")))
+	assert.False(t, IsCacheableFetchRequest([]byte(fetch+"001edeepen-not refs/heads/master\n0009done\n0000")))
+	// the tags sent along depend on the refs
+	assert.False(t, IsCacheableFetchRequest([]byte(fetch+"0010include-tag0009done\n0000")))
+}
+
+func TestPackCacheInvalidate(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "pack-cache")
+	assert.NoError(t, err)
+	defer util.RemoveAll(tmpDir)
+
+	c, err := NewPackCache(filepath.Join(tmpDir, "cache"), time.Hour, -1)
+	assert.NoError(t, err)
+
+	key := PackCacheKey("repo.git", "version=2", nil, []byte("request"))
+	otherKey := PackCacheKey("other.git", "version=2", nil, []byte("request"))
+	putPack(t, c, key, "pack")
+	_, generation, _ := c.Get("other.git", otherKey)
+	w, err := c.Create("other.git", otherKey, generation)
+	assert.NoError(t, err)
+	_, err = w.Write([]byte("other pack"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Commit())
+
+	// the packs of the changed repository are removed, the ones of other repositories are kept
+	DefaultPackCache = c
+	defer func() {
+		DefaultPackCache = nil
+	}()
+	InvalidatePackCache("repo.git")
+	_, ok := getPack(t, c, key)
+	assert.False(t, ok)
+	r, _, ok := c.Get("other.git", otherKey)
+	assert.True(t, ok)
+	r.Close()
+	assert.Equal(t, 1, c.Stats().Entries)
+
+	// a pack generated before an invalidation is not cached
+	_, generation, ok = c.Get("repo.git", key)
+	assert.False(t, ok)
+	w, err = c.Create("repo.git", key, generation)
+	assert.NoError(t, err)
+	_, err = w.Write([]byte("stale pack"))
+	assert.NoError(t, err)
+	c.Invalidate("repo.git")
+	assert.NoError(t, w.Commit())
+	_, ok = getPack(t, c, key)
+	assert.False(t, ok)
+
+	// a pack generated after it is
+	putPack(t, c, key, "new pack")
+	content, ok := getPack(t, c, key)
+	assert.True(t, ok)
+	assert.Equal(t, "new pack", content)
+}
@@ -64,7 +64,7 @@ func FindLFSFile(repo *git.Repository, hash git.SHA1) ([]*LFSResult, error) {
 
 	// Next feed the commits in order into cat-file --batch, followed by their trees and sub trees as necessary.
 	// so let's create a batch stdin and stdout
-	batchStdinWriter, batchReader, cancel := git.CatFileBatch(repo.Path)
+	batchStdinWriter, batchReader, cancel := git.CatFileBatch(repo.Ctx, repo.Path)
 	defer cancel()
 
 	// We'll use a scanner for the revList because it's simpler than a bufio.Reader
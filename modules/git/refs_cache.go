@@ -0,0 +1,183 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultRefsCache is the cache of the ref advertisements used by the HTTP smart protocol handler, nil if it is
+// disabled
+var DefaultRefsCache *RefsCache
+
+// RefsCache caches the ref advertisements of upload-pack in memory, so that the clients polling the same repositories,
+// like CI systems, do not spawn a git process for each poll. The advertisements of a repository are kept until its refs
+// change, which has to be reported with Invalidate, or until they expire.
+//
+// Every invalidation increments the generation of the repository. An advertisement is only cached if the generation
+// has not changed since the miss it has been computed after, so that an advertisement computed before a change is not
+// cached after its invalidation.
+type RefsCache struct {
+	ttl     time.Duration
+	maxSize int64
+
+	mutex sync.Mutex
+	// entries are ordered from the most recently used to the least recently used
+	entries *list.List
+	keys    map[string]*list.Element
+	repos   map[string]map[string]struct{}
+	size    int64
+	// generations are kept for all the invalidated repositories, it is only a counter per repository
+	generations map[string]uint64
+
+	hits          int64
+	misses        int64
+	invalidations int64
+}
+
+type refsCacheEntry struct {
+	repoPath string
+	key      string
+	refs     []byte
+	created  time.Time
+}
+
+// RefsCacheStats are the statistics of a refs cache
+type RefsCacheStats struct {
+	Hits          int64
+	Misses        int64
+	Invalidations int64
+	Entries       int
+	Size          int64
+}
+
+// NewRefsCache creates a refs cache whose entries expire after ttl and which is kept below maxSize bytes, -1 for no
+// limit
+func NewRefsCache(ttl time.Duration, maxSize int64) *RefsCache {
+	return &RefsCache{
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: list.New(),
+		keys:    make(map[string]*list.Element),
+		repos:   make(map[string]map[string]struct{}),
+
+		generations: make(map[string]uint64),
+	}
+}
+
+// RefsCacheKey returns the key of the ref advertisement of a service in a repository
+func RefsCacheKey(repoPath, service, protocol string, args []string) string {
+	var b strings.Builder
+	for _, part := range append([]string{repoPath, service, protocol}, args...) {
+		_, _ = fmt.Fprintf(&b, "%d:%s\n", len(part), part)
+	}
+	return b.String()
+}
+
+// Get returns the cached advertisement for a key of a repository, false if there is none. The generation of the
+// repository has to be passed to Set the advertisement computed after a miss.
+func (c *RefsCache) Get(repoPath, key string) ([]byte, uint64, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.keys[key]
+	if ok && c.ttl > 0 && time.Since(elem.Value.(*refsCacheEntry).created) > c.ttl {
+		c.remove(elem)
+		ok = false
+	}
+	if !ok {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, c.generations[repoPath], false
+	}
+	c.entries.MoveToFront(elem)
+	atomic.AddInt64(&c.hits, 1)
+	return elem.Value.(*refsCacheEntry).refs, c.generations[repoPath], true
+}
+
+// Set caches the advertisement for a key of a repository, evicting the least recently used ones if the cache has
+// become too big. It is not cached if the repository has been invalidated since the generation returned by Get.
+func (c *RefsCache) Set(repoPath, key string, generation uint64, refs []byte) {
+	if c.maxSize >= 0 && int64(len(refs)) > c.maxSize {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.generations[repoPath] != generation {
+		return
+	}
+
+	if elem, ok := c.keys[key]; ok {
+		c.remove(elem)
+	}
+	c.keys[key] = c.entries.PushFront(&refsCacheEntry{
+		repoPath: repoPath,
+		key:      key,
+		refs:     refs,
+		created:  time.Now(),
+	})
+	if c.repos[repoPath] == nil {
+		c.repos[repoPath] = make(map[string]struct{})
+	}
+	c.repos[repoPath][key] = struct{}{}
+	c.size += int64(len(refs))
+
+	for c.maxSize >= 0 && c.size > c.maxSize {
+		c.remove(c.entries.Back())
+	}
+}
+
+// Invalidate removes the advertisements of a repository, it has to be called whenever its refs change
+func (c *RefsCache) Invalidate(repoPath string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generations[repoPath]++
+	keys, ok := c.repos[repoPath]
+	if !ok {
+		return
+	}
+	for key := range keys {
+		c.remove(c.keys[key])
+	}
+	atomic.AddInt64(&c.invalidations, 1)
+}
+
+// remove removes an entry, c.mutex has to be held
+func (c *RefsCache) remove(elem *list.Element) {
+	entry := c.entries.Remove(elem).(*refsCacheEntry)
+	delete(c.keys, entry.key)
+	delete(c.repos[entry.repoPath], entry.key)
+	if len(c.repos[entry.repoPath]) == 0 {
+		delete(c.repos, entry.repoPath)
+	}
+	c.size -= int64(len(entry.refs))
+}
+
+// Stats returns the statistics of the cache
+func (c *RefsCache) Stats() RefsCacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return RefsCacheStats{
+		Hits:          atomic.LoadInt64(&c.hits),
+		Misses:        atomic.LoadInt64(&c.misses),
+		Invalidations: atomic.LoadInt64(&c.invalidations),
+		Entries:       c.entries.Len(),
+		Size:          c.size,
+	}
+}
+
+// InvalidateRefsCache removes the cached advertisements of a repository if the refs cache is enabled
+func InvalidateRefsCache(repoPath string) {
+	if DefaultRefsCache != nil {
+		DefaultRefsCache.Invalidate(repoPath)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefsCache(t *testing.T) {
+	c := NewRefsCache(time.Hour, 10)
+	keyV0 := RefsCacheKey("/repos/a.git", "upload-pack", "", nil)
+	keyV2 := RefsCacheKey("/repos/a.git", "upload-pack", "version=2", nil)
+	keyB := RefsCacheKey("/repos/b.git", "upload-pack", "", nil)
+	assert.NotEqual(t, keyV0, keyV2)
+
+	_, _, ok := c.Get("/repos/a.git", keyV0)
+	assert.False(t, ok)
+	c.Set("/repos/a.git", keyV0, 0, []byte("aaaa"))
+	c.Set("/repos/a.git", keyV2, 0, []byte("a2"))
+	c.Set("/repos/b.git", keyB, 0, []byte("bbbb"))
+	refs, _, ok := c.Get("/repos/a.git", keyV0)
+	assert.True(t, ok)
+	assert.Equal(t, "aaaa", string(refs))
+
+	// the advertisements bigger than the cache are not cached
+	c.Set("/repos/b.git", keyB, 0, []byte("bbbbbbbbbbbb"))
+	refs, _, ok = c.Get("/repos/b.git", keyB)
+	assert.True(t, ok)
+	assert.Equal(t, "bbbb", string(refs))
+
+	// the least recently used advertisement is evicted
+	c.Set("/repos/b.git", keyB, 0, []byte("bbbbbb"))
+	_, _, ok = c.Get("/repos/a.git", keyV2)
+	assert.False(t, ok)
+	assert.Equal(t, RefsCacheStats{Hits: 2, Misses: 2, Entries: 2, Size: 10}, c.Stats())
+
+	// the invalidation removes all the advertisements of a repository
+	c.Set("/repos/a.git", keyV2, 0, []byte("a2"))
+	c.Invalidate("/repos/a.git")
+	c.Invalidate("/repos/c.git")
+	_, _, ok = c.Get("/repos/a.git", keyV2)
+	assert.False(t, ok)
+	_, _, ok = c.Get("/repos/b.git", keyB)
+	assert.True(t, ok)
+	assert.Equal(t, RefsCacheStats{Hits: 3, Misses: 3, Invalidations: 1, Entries: 1, Size: 6}, c.Stats())
+
+	// the advertisements expire
+	c = NewRefsCache(time.Nanosecond, -1)
+	c.Set("/repos/a.git", keyV0, 0, []byte("aaaa"))
+	time.Sleep(time.Millisecond)
+	_, _, ok = c.Get("/repos/a.git", keyV0)
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Stats().Entries)
+}
+
+func TestRefsCache_InvalidateWhileComputing(t *testing.T) {
+	c := NewRefsCache(time.Hour, -1)
+	key := RefsCacheKey("/repos/a.git", "upload-pack", "", nil)
+
+	// the advertisement computed before a push is not cached after the push has invalidated the repository
+	_, generation, ok := c.Get("/repos/a.git", key)
+	assert.False(t, ok)
+	c.Invalidate("/repos/a.git")
+	c.Set("/repos/a.git", key, generation, []byte("old"))
+	_, generation, ok = c.Get("/repos/a.git", key)
+	assert.False(t, ok)
+
+	// the next miss computes the new advertisement
+	c.Set("/repos/a.git", key, generation, []byte("new"))
+	refs, _, ok := c.Get("/repos/a.git", key)
+	assert.True(t, ok)
+	assert.Equal(t, "new", string(refs))
+
+	// the generations are per repository
+	keyB := RefsCacheKey("/repos/b.git", "upload-pack", "", nil)
+	_, generation, _ = c.Get("/repos/b.git", keyB)
+	c.Invalidate("/repos/a.git")
+	c.Set("/repos/b.git", keyB, generation, []byte("b"))
+	_, _, ok = c.Get("/repos/b.git", keyB)
+	assert.True(t, ok)
+}
+
+func TestRefsCache_Race(t *testing.T) {
+	c := NewRefsCache(time.Hour, -1)
+	key := RefsCacheKey("/repos/a.git", "upload-pack", "", nil)
+
+	// the refs of the repository, a push changes them and invalidates the cache under the mutex
+	var mutex sync.Mutex
+	current := 0
+	readRefs := func() []byte {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return []byte(strconv.Itoa(current))
+	}
+
+	var stale int32
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				mutex.Lock()
+				refs, generation, ok := c.Get("/repos/a.git", key)
+				if ok && string(refs) != strconv.Itoa(current) {
+					atomic.AddInt32(&stale, 1)
+				}
+				mutex.Unlock()
+				if !ok {
+					// upload-pack takes a while to advertise the refs
+					refs = readRefs()
+					time.Sleep(10 * time.Microsecond)
+					c.Set("/repos/a.git", key, generation, refs)
+				} else {
+					time.Sleep(time.Microsecond)
+				}
+			}
+		}()
+	}
+	for i := 1; i <= 100; i++ {
+		mutex.Lock()
+		current = i
+		c.Invalidate("/repos/a.git")
+		mutex.Unlock()
+		time.Sleep(5 * time.Microsecond)
+	}
+	time.Sleep(time.Millisecond)
+	close(stop)
+	wg.Wait()
+
+	// no advertisement computed before a push has been served after it
+	assert.EqualValues(t, 0, atomic.LoadInt32(&stale))
+}
@@ -76,7 +76,7 @@ func InitRepository(repoPath string, bare bool) error {
 // IsEmpty Check if repository is empty.
 func (repo *Repository) IsEmpty() (bool, error) {
 	var errbuf strings.Builder
-	if err := NewCommand("log", "-1").RunInDirPipeline(repo.Path, nil, &errbuf); err != nil {
+	if err := NewCommandContext(repo.Ctx, "log", "-1").RunInDirPipeline(repo.Path, nil, &errbuf); err != nil {
 		if strings.Contains(errbuf.String(), "fatal: bad default revision 'HEAD'") ||
 			strings.Contains(errbuf.String(), "fatal: your current branch 'master' does not have any commits yet") {
 			return true, nil
@@ -285,7 +285,7 @@ const (
 	statInpack       = "in-pack: "
 	statPacks        = "packs: "
 	statSizePack     = "size-pack: "
-	statPrunePackage = "prune-package: "
+	statPrunePackage = "prune-packable: "
 	statGarbage      = "garbage: "
 	statSizeGarbage  = "size-garbage: "
 )
@@ -316,8 +316,8 @@ func parseSize(objects string) *CountObject {
 		case strings.HasPrefix(line, statPacks):
 			repoSize.Packs, _ = strconv.ParseInt(line[7:], 10, 64)
 		case strings.HasPrefix(line, statSizePack):
-			repoSize.Count, _ = strconv.ParseInt(line[11:], 10, 64)
-			repoSize.Count *= 1024
+			repoSize.SizePack, _ = strconv.ParseInt(line[11:], 10, 64)
+			repoSize.SizePack *= 1024
 		case strings.HasPrefix(line, statPrunePackage):
 			repoSize.PrunePack, _ = strconv.ParseInt(line[16:], 10, 64)
 		case strings.HasPrefix(line, statGarbage):
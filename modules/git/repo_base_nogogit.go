@@ -8,6 +8,7 @@
 package git
 
 import (
+	"context"
 	"errors"
 	"path/filepath"
 )
@@ -15,6 +16,8 @@ import (
 // Repository represents a Git repository.
 type Repository struct {
 	Path string
+	// Ctx is the context the git commands on the repository are run in, e.g. the one of a request
+	Ctx context.Context
 
 	tagCache *ObjectCache
 
@@ -23,6 +26,11 @@ type Repository struct {
 
 // OpenRepository opens the repository at the given path.
 func OpenRepository(repoPath string) (*Repository, error) {
+	return OpenRepositoryCtx(DefaultContext, repoPath)
+}
+
+// OpenRepositoryCtx opens the repository at the given path, its git commands are run in the given context.
+func OpenRepositoryCtx(ctx context.Context, repoPath string) (*Repository, error) {
 	repoPath, err := filepath.Abs(repoPath)
 	if err != nil {
 		return nil, err
@@ -31,6 +39,7 @@ func OpenRepository(repoPath string) (*Repository, error) {
 	}
 	return &Repository{
 		Path:     repoPath,
+		Ctx:      ctx,
 		tagCache: newObjectCache(),
 	}, nil
 }
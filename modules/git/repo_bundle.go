@@ -0,0 +1,21 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+// CreateBundle writes a git bundle containing the given ref and its history to out.
+// The ref must be a full reference name like refs/heads/master, a bundle cannot be created for a bare commit.
+func (repo *Repository) CreateBundle(ctx context.Context, ref string, out io.Writer) error {
+	stderr := new(strings.Builder)
+	if err := NewCommandContext(ctx, "bundle", "create", "-", ref).RunInDirPipeline(repo.Path, out, stderr); err != nil {
+		return ConcatenateError(err, stderr.String())
+	}
+	return nil
+}
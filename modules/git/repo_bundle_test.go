@@ -0,0 +1,31 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_CreateBundle(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := OpenRepository(bareRepo1Path)
+	assert.NoError(t, err)
+	defer bareRepo1.Close()
+
+	commitID, err := bareRepo1.GetBranchCommitID("master")
+	assert.NoError(t, err)
+
+	var bundle bytes.Buffer
+	assert.NoError(t, bareRepo1.CreateBundle(context.Background(), BranchPrefix+"master", &bundle))
+	assert.Contains(t, bundle.String(), "# v2 git bundle\n"+commitID+" "+BranchPrefix+"master\n")
+
+	// a bundle needs a ref
+	assert.Error(t, bareRepo1.CreateBundle(context.Background(), commitID, &bytes.Buffer{}))
+}
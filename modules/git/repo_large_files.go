@@ -0,0 +1,79 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LargeFile represents a blob above a size threshold and the path it was found at
+type LargeFile struct {
+	Path string
+	ID   SHA1
+	Size int64
+}
+
+// FindLargeFiles returns the blobs reachable from revs but not from the excluded revs which are larger than threshold.
+// revs are passed to git rev-list as they are, e.g. "<new>", "--not", "--all" for the objects introduced by a push.
+// Files tracked by Git LFS are stored as small pointer files and are therefore never reported for thresholds above 1 KiB.
+func FindLargeFiles(repoPath string, env []string, threshold int64, revs ...string) ([]*LargeFile, error) {
+	revListReader, revListWriter := io.Pipe()
+	defer revListReader.Close()
+	go func() {
+		stderr := new(strings.Builder)
+		err := NewCommand(append([]string{"rev-list", "--objects"}, revs...)...).
+			RunInDirTimeoutEnvFullPipeline(env, -1, repoPath, revListWriter, stderr, nil)
+		if err != nil {
+			_ = revListWriter.CloseWithError(ConcatenateError(err, stderr.String()))
+			return
+		}
+		_ = revListWriter.Close()
+	}()
+
+	batchCheckReader, batchCheckWriter := io.Pipe()
+	defer batchCheckReader.Close()
+	go func() {
+		stderr := new(strings.Builder)
+		err := NewCommand("cat-file", "--batch-check=%(objecttype) %(objectname) %(objectsize) %(rest)").
+			RunInDirTimeoutEnvFullPipeline(env, -1, repoPath, batchCheckWriter, stderr, revListReader)
+		if err != nil {
+			_ = batchCheckWriter.CloseWithError(ConcatenateError(err, stderr.String()))
+			return
+		}
+		_ = batchCheckWriter.Close()
+	}()
+
+	var files []*LargeFile
+	scanner := bufio.NewScanner(batchCheckReader)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), " ", 4)
+		if len(fields) != 4 || fields[0] != "blob" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size <= threshold {
+			continue
+		}
+		id, err := NewIDFromString(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &LargeFile{
+			Path: fields[3],
+			ID:   id,
+			Size: size,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
@@ -0,0 +1,31 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindLargeFiles(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+
+	files, err := FindLargeFiles(bareRepo1Path, nil, 5, "branch2", "^master")
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		assert.EqualValues(t, "branch2/branch2.txt", files[0].Path)
+		assert.EqualValues(t, "34d1da713bf7de1c535e1d7d3ca985afd84bc7e5", files[0].ID.String())
+		assert.EqualValues(t, 8, files[0].Size)
+	}
+
+	files, err = FindLargeFiles(bareRepo1Path, nil, 8, "branch2", "^master")
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+
+	_, err = FindLargeFiles(bareRepo1Path, nil, 8, "unknown")
+	assert.Error(t, err)
+}
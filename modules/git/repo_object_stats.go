@@ -0,0 +1,144 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BlobSize represents the size of a blob
+type BlobSize struct {
+	ID       SHA1
+	Size     int64
+	DiskSize int64
+}
+
+// RefCounts represents the number of refs of a repository by kind
+type RefCounts struct {
+	Branches     int64
+	Tags         int64
+	PullRequests int64
+	Others       int64
+}
+
+// ObjectStats represents the object storage statistics of a repository
+type ObjectStats struct {
+	*CountObject
+	LargestBlobs []*BlobSize
+	Refs         *RefCounts
+}
+
+// GetObjectStats returns the object counts and pack sizes, the largest blobs and the number of refs of a repository.
+// It does not need to walk the history so it is fast even for large repositories.
+func GetObjectStats(ctx context.Context, repoPath string, largestBlobs int) (*ObjectStats, error) {
+	if err := CheckGitVersionAtLeast("2.6.0"); err != nil {
+		return nil, err
+	}
+
+	countObject, err := CountObjects(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("CountObjects: %v", err)
+	}
+	stats := &ObjectStats{
+		CountObject: countObject,
+	}
+
+	if stats.LargestBlobs, err = getLargestBlobs(ctx, repoPath, largestBlobs); err != nil {
+		return nil, fmt.Errorf("getLargestBlobs: %v", err)
+	}
+	if stats.Refs, err = getRefCounts(ctx, repoPath); err != nil {
+		return nil, fmt.Errorf("getRefCounts: %v", err)
+	}
+	return stats, nil
+}
+
+// getLargestBlobs returns the n largest blobs in the object database, largest first
+func getLargestBlobs(ctx context.Context, repoPath string, n int) ([]*BlobSize, error) {
+	blobs := make([]*BlobSize, 0, n+1)
+	if n <= 0 {
+		return blobs, nil
+	}
+
+	stdoutReader, stdoutWriter := io.Pipe()
+	defer stdoutReader.Close()
+	stderr := new(strings.Builder)
+	go func() {
+		err := NewCommandContext(ctx, "cat-file", "--batch-all-objects", "--batch-check=%(objecttype) %(objectname) %(objectsize) %(objectsize:disk)").
+			RunInDirPipeline(repoPath, stdoutWriter, stderr)
+		if err != nil {
+			_ = stdoutWriter.CloseWithError(ConcatenateError(err, stderr.String()))
+			return
+		}
+		_ = stdoutWriter.Close()
+	}()
+
+	scanner := bufio.NewScanner(stdoutReader)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 || fields[0] != "blob" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if len(blobs) == n && size <= blobs[n-1].Size {
+			continue
+		}
+		id, err := NewIDFromString(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		diskSize, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		// keep the blobs sorted by size so the smallest one can be dropped
+		idx := sort.Search(len(blobs), func(i int) bool {
+			return blobs[i].Size < size
+		})
+		blobs = append(blobs, nil)
+		copy(blobs[idx+1:], blobs[idx:])
+		blobs[idx] = &BlobSize{ID: id, Size: size, DiskSize: diskSize}
+		if len(blobs) > n {
+			blobs = blobs[:n]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return blobs, nil
+}
+
+// getRefCounts counts the refs of a repository by kind
+func getRefCounts(ctx context.Context, repoPath string) (*RefCounts, error) {
+	stdout, err := NewCommandContext(ctx, "for-each-ref", "--format=%(refname)").RunInDir(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := &RefCounts{}
+	for _, ref := range strings.Split(stdout, "\n") {
+		switch {
+		case ref == "":
+		case strings.HasPrefix(ref, BranchPrefix):
+			counts.Branches++
+		case strings.HasPrefix(ref, TagPrefix):
+			counts.Tags++
+		case strings.HasPrefix(ref, "refs/pull/"):
+			counts.PullRequests++
+		default:
+			counts.Others++
+		}
+	}
+	return counts, nil
+}
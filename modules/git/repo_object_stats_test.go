@@ -0,0 +1,49 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetObjectStats(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+
+	stats, err := GetObjectStats(context.Background(), bareRepo1Path, 2)
+	assert.NoError(t, err)
+
+	assert.EqualValues(t, 3, stats.Refs.Branches)
+	assert.EqualValues(t, 1, stats.Refs.Tags)
+	assert.EqualValues(t, 0, stats.Refs.PullRequests)
+	assert.EqualValues(t, 1, stats.Refs.Others)
+
+	assert.Len(t, stats.LargestBlobs, 2)
+	assert.GreaterOrEqual(t, stats.LargestBlobs[0].Size, stats.LargestBlobs[1].Size)
+	assert.Greater(t, stats.Count+stats.InPack, int64(0))
+}
+
+func TestParseSize(t *testing.T) {
+	countObject := parseSize(`count: 3
+size: 12
+in-pack: 40
+packs: 1
+size-pack: 8
+prune-packable: 2
+garbage: 0
+size-garbage: 0
+`)
+	assert.EqualValues(t, &CountObject{
+		Count:     3,
+		Size:      12 * 1024,
+		InPack:    40,
+		Packs:     1,
+		SizePack:  8 * 1024,
+		PrunePack: 2,
+	}, countObject)
+}
@@ -5,6 +5,7 @@
 package git
 
 import (
+	"context"
 	"path/filepath"
 	"testing"
 	"time"
@@ -35,3 +36,20 @@ func TestRepoIsEmpty(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, isEmpty)
 }
+
+func TestOpenRepositoryCtx(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	ctx, cancel := context.WithCancel(context.Background())
+	repo, err := OpenRepositoryCtx(ctx, bareRepo1Path)
+	assert.NoError(t, err)
+	defer repo.Close()
+	assert.Equal(t, ctx, repo.Ctx)
+
+	_, err = repo.GetTagCommitID("test")
+	assert.NoError(t, err)
+
+	// the git commands are not run once the context is done
+	cancel()
+	_, err = repo.GetTagCommitID("test")
+	assert.Error(t, err)
+}
@@ -46,7 +46,7 @@ func (te *TreeEntry) Size() int64 {
 		return te.size
 	}
 
-	stdout, err := NewCommand("cat-file", "-s", te.ID.String()).RunInDir(te.ptree.repo.Path)
+	stdout, err := NewCommandContext(te.ptree.repo.Ctx, "cat-file", "-s", te.ID.String()).RunInDir(te.ptree.repo.Path)
 	if err != nil {
 		return 0
 	}
@@ -84,10 +84,10 @@ func (te *TreeEntry) IsExecutable() bool {
 // Blob returns the blob object the entry
 func (te *TreeEntry) Blob() *Blob {
 	return &Blob{
-		ID:       te.ID,
-		repoPath: te.ptree.repo.Path,
-		name:     te.Name(),
-		size:     te.size,
-		gotSize:  te.sized,
+		ID:      te.ID,
+		repo:    te.ptree.repo,
+		name:    te.Name(),
+		size:    te.size,
+		gotSize: te.sized,
 	}
 }
@@ -0,0 +1,32 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+// BundleURIName is the name of the bundle which is advertised by BundleURIArgs
+const BundleURIName = "gitea"
+
+// PartialCloneArgs returns the global arguments which allow the clients of upload-pack to request a
+// partial clone, e.g. with --filter=blob:none, nil if the git version does not support it well enough.
+func PartialCloneArgs() []string {
+	if CheckGitVersionAtLeast("2.22") != nil {
+		return nil
+	}
+	return []string{"-c", "uploadpack.allowFilter=true", "-c", "uploadpack.allowAnySHA1InWant=true"}
+}
+
+// BundleURIArgs returns the global arguments which make upload-pack advertise the bundle at uri to the
+// clients using the wire protocol version 2, nil if the git version does not support bundle URIs.
+// The clients download the bundle before they fetch the rest of the objects from the repository.
+func BundleURIArgs(uri string) []string {
+	if CheckGitVersionAtLeast("2.40") != nil {
+		return nil
+	}
+	return []string{
+		"-c", "uploadpack.advertiseBundleURIs=true",
+		"-c", "bundle.version=1",
+		"-c", "bundle.mode=any",
+		"-c", "bundle." + BundleURIName + ".uri=" + uri,
+	}
+}
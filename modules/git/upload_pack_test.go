@@ -0,0 +1,46 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func advertiseUploadPack(t *testing.T, args []string) string {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	args = append(args, "upload-pack", "--stateless-rpc", "--advertise-refs", ".")
+	out, err := NewCommand(args...).RunInDirTimeoutEnv(append(os.Environ(), "GIT_PROTOCOL=version=2"), -1, bareRepo1Path)
+	assert.NoError(t, err)
+	return string(out)
+}
+
+func TestPartialCloneArgs(t *testing.T) {
+	if CheckGitVersionAtLeast("2.22") != nil {
+		assert.Nil(t, PartialCloneArgs())
+		return
+	}
+	out := advertiseUploadPack(t, PartialCloneArgs())
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "fetch=") {
+			assert.Contains(t, line, " filter")
+			return
+		}
+	}
+	assert.Fail(t, "fetch is not advertised", out)
+}
+
+func TestBundleURIArgs(t *testing.T) {
+	if CheckGitVersionAtLeast("2.40") != nil {
+		assert.Nil(t, BundleURIArgs("https://example.com/repo.bundle"))
+		return
+	}
+	assert.NotContains(t, advertiseUploadPack(t, nil), "bundle-uri")
+	assert.Contains(t, advertiseUploadPack(t, BundleURIArgs("https://example.com/repo.bundle")), "bundle-uri")
+}
@@ -23,15 +23,16 @@ const (
 	stateTerminate
 )
 
-// There are three places that could inherit sockets:
+// There are four places that could inherit sockets:
 //
 // * HTTP or HTTPS main listener
 // * HTTP redirection fallback
 // * SSH
+// * Internal API
 //
 // If you add an additional place you must increment this number
 // and add a function to call manager.InformCleanup if it's not going to be used
-const numberOfServersToCreate = 4
+const numberOfServersToCreate = 5
 
 // Manager represents the graceful server manager interface
 var manager *Manager
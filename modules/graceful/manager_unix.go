@@ -54,6 +54,7 @@ func (g *Manager) start(ctx context.Context) {
 	// Set the running state & handle signals
 	g.setState(stateRunning)
 	go g.handleSignals(ctx)
+	go g.notifyStopping()
 
 	// Handle clean up of unused provided listeners	and delayed start-up
 	startupDone := make(chan struct{})
@@ -65,6 +66,7 @@ func (g *Manager) start(ctx context.Context) {
 		// Ignore the error here there's not much we can do with it
 		// They're logged in the CloseProvidedListeners function
 		_ = CloseProvidedListeners()
+		go g.notifyReady()
 	}()
 	if setting.StartupTimeout > 0 {
 		go func() {
@@ -146,6 +148,7 @@ func (g *Manager) doFork() error {
 	}
 	g.forked = true
 	g.lock.Unlock()
+	notifyOrLog(sdNotifyReloading, "STATUS=Restarting")
 	// We need to move the file logs to append pids
 	setting.RestartLogsWithPIDSuffix()
 
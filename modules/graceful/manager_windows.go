@@ -89,9 +89,9 @@ func (g *Manager) start() {
 // Execute makes Manager implement svc.Handler
 func (g *Manager) Execute(args []string, changes <-chan svc.ChangeRequest, status chan<- svc.Status) (svcSpecificEC bool, exitCode uint32) {
 	if setting.StartupTimeout > 0 {
-		status <- svc.Status{State: svc.StartPending}
-	} else {
 		status <- svc.Status{State: svc.StartPending, WaitHint: uint32(setting.StartupTimeout / time.Millisecond)}
+	} else {
+		status <- svc.Status{State: svc.StartPending}
 	}
 
 	// Now need to wait for everything to start...
@@ -134,10 +134,15 @@ loop:
 			}
 		}
 	}
-	status <- svc.Status{
+	stopPending := svc.Status{
 		State:    svc.StopPending,
 		WaitHint: uint32(waitTime / time.Millisecond),
 	}
+	status <- stopPending
+
+	// Whilst the queues and git operations are finishing keep telling the SVC host that we are still making progress
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
 
 hammerLoop:
 	for {
@@ -145,13 +150,16 @@ hammerLoop:
 		case change := <-changes:
 			switch change.Cmd {
 			case svc.Interrogate:
-				status <- change.CurrentStatus
+				status <- stopPending
 			case svc.Stop, svc.Shutdown, hammerCmd:
 				g.DoImmediateHammer()
 				break hammerLoop
 			default:
 				log.Debug("Unexpected control request: %v", change.Cmd)
 			}
+		case <-ticker.C:
+			stopPending.CheckPoint++
+			status <- stopPending
 		case <-g.hammer:
 			break hammerLoop
 		}
@@ -184,6 +184,9 @@ func GetListenerUnix(network string, address *net.UnixAddr) (*net.UnixListener,
 	}
 
 	fileMode := os.FileMode(setting.UnixSocketPermission)
+	if setting.InternalAPI.Separate && setting.InternalAPI.Protocol == setting.UnixSocket && address.Name == setting.InternalAPI.HTTPAddr {
+		fileMode = os.FileMode(setting.InternalAPI.UnixSocketPermission)
+	}
 	if err = os.Chmod(address.Name, fileMode); err != nil {
 		return nil, fmt.Errorf("Failed to set permission of unix socket to %s: %v", fileMode.String(), err)
 	}
@@ -0,0 +1,128 @@
+// +build !windows
+
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package graceful
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+const (
+	notifySocket      = "NOTIFY_SOCKET"
+	watchdogUSec      = "WATCHDOG_USEC"
+	watchdogPID       = "WATCHDOG_PID"
+	sdNotifyReady     = "READY=1"
+	sdNotifyStopping  = "STOPPING=1"
+	sdNotifyReloading = "RELOADING=1"
+	sdNotifyWatchdog  = "WATCHDOG=1"
+)
+
+// notify sends the provided state lines to the service manager using the sd_notify protocol.
+// If NOTIFY_SOCKET is not set, i.e. we are not running under a notify aware service manager, this is a no-op.
+func notify(states ...string) error {
+	socketPath := os.Getenv(notifySocket)
+	if socketPath == "" {
+		return nil
+	}
+	// Abstract namespace sockets are provided with a leading '@'
+	if strings.HasPrefix(socketPath, "@") {
+		socketPath = "\x00" + socketPath[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{
+		Name: socketPath,
+		Net:  "unixgram",
+	})
+	if err != nil {
+		return fmt.Errorf("unable to connect to notify socket %s: %v", os.Getenv(notifySocket), err)
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(strings.Join(states, "\n")))
+	return err
+}
+
+// notifyOrLog sends the provided states to the service manager logging any error
+func notifyOrLog(states ...string) {
+	if err := notify(states...); err != nil {
+		log.Warn("PID: %d. Unable to notify service manager of %v: %v", os.Getpid(), states, err)
+	}
+}
+
+// watchdogInterval returns the interval at which the service manager expects to be pinged.
+// It returns 0 if no watchdog has been requested for this process.
+func watchdogInterval() time.Duration {
+	usecStr := os.Getenv(watchdogUSec)
+	if usecStr == "" {
+		return 0
+	}
+	usec, err := strconv.ParseInt(usecStr, 10, 64)
+	if err != nil || usec <= 0 {
+		log.Warn("Invalid %s value: %q", watchdogUSec, usecStr)
+		return 0
+	}
+
+	if pidStr := os.Getenv(watchdogPID); pidStr != "" {
+		pid, err := strconv.Atoi(pidStr)
+		if err != nil || pid != os.Getpid() {
+			// The watchdog is meant for a different process
+			return 0
+		}
+	}
+
+	return time.Duration(usec) * time.Microsecond
+}
+
+// notifyReady informs the service manager that we are ready and
+// then pings its watchdog (if one is requested) until the manager is done.
+func (g *Manager) notifyReady() {
+	pid := os.Getpid()
+	if g.isChild {
+		// We were started by a graceful restart so we must tell the service manager that we are the new main process
+		notifyOrLog(sdNotifyReady, "MAINPID="+strconv.Itoa(pid), "STATUS=Running")
+	} else {
+		notifyOrLog(sdNotifyReady, "STATUS=Running")
+	}
+
+	interval := watchdogInterval()
+	if interval == 0 {
+		return
+	}
+	// Ping at half the requested interval as recommended by sd_watchdog_enabled(3)
+	// We keep pinging whilst shutting down so that queues and git operations can finish.
+	log.Info("PID: %d. Pinging service manager watchdog every %v", pid, interval/2)
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			notifyOrLog(sdNotifyWatchdog)
+		case <-g.Done():
+			return
+		}
+	}
+}
+
+// notifyStopping informs the service manager that we are shutting down once shutdown has begun.
+// If we are shutting down because of a graceful restart our replacement will take over as main process
+// so the service manager must not be told that the service is stopping.
+func (g *Manager) notifyStopping() {
+	<-g.IsShutdown()
+	g.lock.RLock()
+	forked := g.forked
+	g.lock.RUnlock()
+	if forked {
+		return
+	}
+	notifyOrLog(sdNotifyStopping, "STATUS=Shutting down")
+}
@@ -0,0 +1,212 @@
+// +build !windows
+
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package graceful
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setEnv sets or, if value is nil, unsets an environment variable for the duration of the test
+func setEnv(t *testing.T, key string, value *string) {
+	old, had := os.LookupEnv(key)
+	if value == nil {
+		assert.NoError(t, os.Unsetenv(key))
+	} else {
+		assert.NoError(t, os.Setenv(key, *value))
+	}
+	t.Cleanup(func() {
+		if had {
+			_ = os.Setenv(key, old)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	})
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+// listenNotifySocket listens on a unixgram socket standing in for the one of the service manager
+func listenNotifySocket(t *testing.T, name string) *net.UnixConn {
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: name, Net: "unixgram"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+	return conn
+}
+
+// readNotification reads the next notification sent to the socket
+func readNotification(t *testing.T, conn *net.UnixConn) string {
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	assert.NoError(t, err)
+	return string(buf[:n])
+}
+
+func tempSocketPath(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "TestNotify")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	return filepath.Join(dir, "notify.sock")
+}
+
+func TestNotify(t *testing.T) {
+	socketPath := tempSocketPath(t)
+	conn := listenNotifySocket(t, socketPath)
+	setEnv(t, notifySocket, &socketPath)
+
+	assert.NoError(t, notify(sdNotifyReady, "STATUS=Running"))
+	assert.Equal(t, "READY=1\nSTATUS=Running", readNotification(t, conn))
+
+	assert.NoError(t, notify(sdNotifyWatchdog))
+	assert.Equal(t, "WATCHDOG=1", readNotification(t, conn))
+}
+
+func TestNotifyAbstractSocket(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("abstract namespace sockets are only supported on Linux")
+	}
+	name := fmt.Sprintf("gitea-test-notify-%d", os.Getpid())
+	conn := listenNotifySocket(t, "\x00"+name)
+	setEnv(t, notifySocket, strPtr("@"+name))
+
+	assert.NoError(t, notify(sdNotifyStopping))
+	assert.Equal(t, "STOPPING=1", readNotification(t, conn))
+}
+
+func TestNotifyWithoutSocket(t *testing.T) {
+	setEnv(t, notifySocket, nil)
+	assert.NoError(t, notify(sdNotifyReady))
+
+	setEnv(t, notifySocket, strPtr(tempSocketPath(t)))
+	assert.Error(t, notify(sdNotifyReady))
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+	kases := []struct {
+		usec     *string
+		pid      *string
+		expected time.Duration
+	}{
+		{usec: nil, pid: nil, expected: 0},
+		{usec: strPtr(""), pid: nil, expected: 0},
+		{usec: strPtr("30000000"), pid: nil, expected: 30 * time.Second},
+		{usec: strPtr("1500"), pid: nil, expected: 1500 * time.Microsecond},
+		{usec: strPtr("abc"), pid: nil, expected: 0},
+		{usec: strPtr("0"), pid: nil, expected: 0},
+		{usec: strPtr("-5"), pid: nil, expected: 0},
+		{usec: strPtr("30000000"), pid: &pid, expected: 30 * time.Second},
+		{usec: strPtr("30000000"), pid: strPtr(""), expected: 30 * time.Second},
+		{usec: strPtr("30000000"), pid: strPtr("1"), expected: 0},
+		{usec: strPtr("30000000"), pid: strPtr("abc"), expected: 0},
+		{usec: nil, pid: &pid, expected: 0},
+	}
+	for _, kase := range kases {
+		setEnv(t, watchdogUSec, kase.usec)
+		setEnv(t, watchdogPID, kase.pid)
+		assert.Equal(t, kase.expected, watchdogInterval(), "usec: %v, pid: %v", kase.usec, kase.pid)
+	}
+}
+
+func newTestManager() *Manager {
+	return &Manager{
+		lock:     &sync.RWMutex{},
+		shutdown: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func TestNotifyWithoutServiceManager(t *testing.T) {
+	setEnv(t, notifySocket, nil)
+	setEnv(t, watchdogUSec, nil)
+	setEnv(t, watchdogPID, nil)
+
+	g := newTestManager()
+	close(g.shutdown)
+	close(g.done)
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		g.notifyReady()
+		g.notifyStopping()
+	}()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "notifyReady and notifyStopping did not return without a service manager")
+	}
+}
+
+func TestNotifyReadyAndStopping(t *testing.T) {
+	socketPath := tempSocketPath(t)
+	conn := listenNotifySocket(t, socketPath)
+	setEnv(t, notifySocket, &socketPath)
+	setEnv(t, watchdogUSec, strPtr("20000"))
+	setEnv(t, watchdogPID, nil)
+
+	g := newTestManager()
+	go g.notifyStopping()
+	readyDone := make(chan struct{})
+	go func() {
+		defer close(readyDone)
+		g.notifyReady()
+	}()
+
+	assert.Equal(t, "READY=1\nSTATUS=Running", readNotification(t, conn))
+	assert.Equal(t, "WATCHDOG=1", readNotification(t, conn))
+
+	close(g.shutdown)
+	// the watchdog is still pinged whilst shutting down
+	for {
+		notification := readNotification(t, conn)
+		if notification != sdNotifyWatchdog {
+			assert.Equal(t, "STOPPING=1\nSTATUS=Shutting down", notification)
+			break
+		}
+	}
+
+	close(g.done)
+	<-readyDone
+}
+
+func TestNotifyStoppingForked(t *testing.T) {
+	socketPath := tempSocketPath(t)
+	conn := listenNotifySocket(t, socketPath)
+	setEnv(t, notifySocket, &socketPath)
+
+	// our replacement takes over as main process, the service is not stopping
+	g := newTestManager()
+	g.forked = true
+	close(g.shutdown)
+	g.notifyStopping()
+
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(100*time.Millisecond)))
+	_, err := conn.Read(make([]byte, 1024))
+	assert.Error(t, err)
+}
@@ -65,9 +65,10 @@ func RestartProcess() (int, error) {
 	}
 
 	// Pass on the environment and replace the old count key with the new one.
+	// The watchdog PID is dropped as the child will become the main process.
 	var env []string
 	for _, v := range os.Environ() {
-		if !strings.HasPrefix(v, listenFDs+"=") {
+		if !strings.HasPrefix(v, listenFDs+"=") && !strings.HasPrefix(v, watchdogPID+"=") {
 			env = append(env, v)
 		}
 	}
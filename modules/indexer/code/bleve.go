@@ -32,6 +32,7 @@ import (
 	"github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
 	"github.com/blevesearch/bleve/v2/index/upsidedown"
 	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search"
 	"github.com/blevesearch/bleve/v2/search/query"
 	"github.com/ethantkoenig/rupture"
 	"github.com/go-enry/go-enry/v2"
@@ -90,9 +91,14 @@ func openBleveIndexer(path string, latestVersion int) (bleve.Index, error) {
 
 // RepoIndexerData data stored in the repo indexer
 type RepoIndexerData struct {
-	RepoID    int64
+	RepoID int64
+	// RepoKey is the ID of the repository as a term, to count the results by repository
+	RepoKey   string
 	CommitID  string
+	Directory string
 	Content   string
+	// Symbols are the names of the symbols defined in the content
+	Symbols   string
 	Language  string
 	UpdatedAt time.Time
 }
@@ -105,7 +111,7 @@ func (d *RepoIndexerData) Type() string {
 const (
 	repoIndexerAnalyzer      = "repoIndexerAnalyzer"
 	repoIndexerDocType       = "repoIndexerDocType"
-	repoIndexerLatestVersion = 5
+	repoIndexerLatestVersion = 6
 )
 
 // createBleveIndexer create a bleve repo indexer if one does not already exist
@@ -119,11 +125,18 @@ func createBleveIndexer(path string, latestVersion int) (bleve.Index, error) {
 	textFieldMapping.IncludeInAll = false
 	docMapping.AddFieldMappingsAt("Content", textFieldMapping)
 
+	symbolsFieldMapping := bleve.NewTextFieldMapping()
+	symbolsFieldMapping.IncludeInAll = false
+	symbolsFieldMapping.Store = false
+	docMapping.AddFieldMappingsAt("Symbols", symbolsFieldMapping)
+
 	termFieldMapping := bleve.NewTextFieldMapping()
 	termFieldMapping.IncludeInAll = false
 	termFieldMapping.Analyzer = analyzer_keyword.Name
 	docMapping.AddFieldMappingsAt("Language", termFieldMapping)
 	docMapping.AddFieldMappingsAt("CommitID", termFieldMapping)
+	docMapping.AddFieldMappingsAt("RepoKey", termFieldMapping)
+	docMapping.AddFieldMappingsAt("Directory", termFieldMapping)
 
 	timeFieldMapping := bleve.NewDateTimeFieldMapping()
 	timeFieldMapping.IncludeInAll = false
@@ -217,10 +230,14 @@ func (b *BleveIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader *bufio.
 	}
 
 	id := filenameIndexerID(repo.ID, update.Filename)
+	content := string(charset.ToUTF8DropErrors(fileContents))
 	return batch.Index(id, &RepoIndexerData{
 		RepoID:    repo.ID,
+		RepoKey:   strconv.FormatInt(repo.ID, 10),
 		CommitID:  commitSha,
-		Content:   string(charset.ToUTF8DropErrors(fileContents)),
+		Directory: directoryOfFilename(update.Filename),
+		Content:   content,
+		Symbols:   extractSymbols(content),
 		Language:  analyze.GetCodeLanguage(update.Filename, fileContents),
 		UpdatedAt: time.Now().UTC(),
 	})
@@ -267,7 +284,7 @@ func (b *BleveIndexer) Index(repo *models.Repository, sha string, changes *repoC
 	batch := rupture.NewFlushingBatch(b.indexer, maxBatchSize)
 	if len(changes.Updates) > 0 {
 
-		batchWriter, batchReader, cancel := git.CatFileBatch(repo.RepoPath())
+		batchWriter, batchReader, cancel := git.CatFileBatch(git.DefaultContext, repo.RepoPath())
 		defer cancel()
 
 		for _, update := range changes.Updates {
@@ -302,43 +319,45 @@ func (b *BleveIndexer) Delete(repoID int64) error {
 	return batch.Flush()
 }
 
-// Search searches for files in the specified repo.
-// Returns the matching file-paths
-func (b *BleveIndexer) Search(repoIDs []int64, language, keyword string, page, pageSize int, isMatch bool) (int64, []*SearchResult, []*SearchResultLanguages, error) {
-	var (
-		indexerQuery query.Query
-		keywordQuery query.Query
-	)
-
-	if isMatch {
-		prefixQuery := bleve.NewPrefixQuery(keyword)
-		prefixQuery.FieldVal = "Content"
-		keywordQuery = prefixQuery
-	} else {
-		phraseQuery := bleve.NewMatchPhraseQuery(keyword)
-		phraseQuery.FieldVal = "Content"
+// keywordQuery returns the query of the keyword of a search in field
+func keywordQuery(opts *SearchOptions, field string) query.Query {
+	switch opts.Mode {
+	case SearchModeMatch:
+		prefixQuery := bleve.NewPrefixQuery(opts.Keyword)
+		prefixQuery.FieldVal = field
+		return prefixQuery
+	case SearchModeRegexp:
+		regexpQuery := bleve.NewRegexpQuery(opts.Keyword)
+		regexpQuery.FieldVal = field
+		return regexpQuery
+	default:
+		phraseQuery := bleve.NewMatchPhraseQuery(opts.Keyword)
+		phraseQuery.FieldVal = field
 		phraseQuery.Analyzer = repoIndexerAnalyzer
-		keywordQuery = phraseQuery
+		return phraseQuery
 	}
+}
 
-	if len(repoIDs) > 0 {
-		var repoQueries = make([]query.Query, 0, len(repoIDs))
-		for _, repoID := range repoIDs {
+// Search searches for files in the specified repo.
+// Returns the matching file-paths
+func (b *BleveIndexer) Search(opts *SearchOptions) (int64, []*SearchResult, *SearchResultFacets, error) {
+	indexerQuery := keywordQuery(opts, "Content")
+	if len(opts.RepoIDs) > 0 {
+		var repoQueries = make([]query.Query, 0, len(opts.RepoIDs))
+		for _, repoID := range opts.RepoIDs {
 			repoQueries = append(repoQueries, numericEqualityQuery(repoID, "RepoID"))
 		}
 
 		indexerQuery = bleve.NewConjunctionQuery(
 			bleve.NewDisjunctionQuery(repoQueries...),
-			keywordQuery,
+			indexerQuery,
 		)
-	} else {
-		indexerQuery = keywordQuery
 	}
 
-	// Save for reuse without language filter
+	// Save for reuse without language and path filters
 	facetQuery := indexerQuery
-	if len(language) > 0 {
-		languageQuery := bleve.NewMatchQuery(language)
+	if len(opts.Language) > 0 {
+		languageQuery := bleve.NewMatchQuery(opts.Language)
 		languageQuery.FieldVal = "Language"
 		languageQuery.Analyzer = analyzer_keyword.Name
 
@@ -347,14 +366,33 @@ func (b *BleveIndexer) Search(repoIDs []int64, language, keyword string, page, p
 			languageQuery,
 		)
 	}
+	if len(opts.Path) > 0 {
+		directoryQuery := bleve.NewTermQuery(opts.Path)
+		directoryQuery.FieldVal = "Directory"
+		subdirectoryQuery := bleve.NewPrefixQuery(opts.Path + "/")
+		subdirectoryQuery.FieldVal = "Directory"
 
-	from := (page - 1) * pageSize
-	searchRequest := bleve.NewSearchRequestOptions(indexerQuery, pageSize, from, false)
+		indexerQuery = bleve.NewConjunctionQuery(
+			indexerQuery,
+			bleve.NewDisjunctionQuery(directoryQuery, subdirectoryQuery),
+		)
+	}
+
+	// the files defining a symbol matching the keyword come first
+	symbolQuery := keywordQuery(opts, "Symbols")
+	symbolQuery.(query.BoostableQuery).SetBoost(symbolBoost)
+	rankedQuery := bleve.NewBooleanQuery()
+	rankedQuery.AddMust(indexerQuery)
+	rankedQuery.AddShould(symbolQuery)
+
+	from := (opts.Page - 1) * opts.PageSize
+	searchRequest := bleve.NewSearchRequestOptions(rankedQuery, opts.PageSize, from, false)
 	searchRequest.Fields = []string{"Content", "RepoID", "Language", "CommitID", "UpdatedAt"}
 	searchRequest.IncludeLocations = true
 
-	if len(language) == 0 {
-		searchRequest.AddFacet("languages", bleve.NewFacetRequest("Language", 10))
+	isFiltered := len(opts.Language) > 0 || len(opts.Path) > 0
+	if !isFiltered {
+		addFacetRequests(searchRequest)
 	}
 
 	result, err := b.indexer.Search(searchRequest)
@@ -396,29 +434,58 @@ func (b *BleveIndexer) Search(repoIDs []int64, language, keyword string, page, p
 		}
 	}
 
-	searchResultLanguages := make([]*SearchResultLanguages, 0, 10)
-	if len(language) > 0 {
-		// Use separate query to go get all language counts
-		facetRequest := bleve.NewSearchRequestOptions(facetQuery, 1, 0, false)
-		facetRequest.Fields = []string{"Content", "RepoID", "Language", "CommitID", "UpdatedAt"}
-		facetRequest.IncludeLocations = true
-		facetRequest.AddFacet("languages", bleve.NewFacetRequest("Language", 10))
+	if isFiltered {
+		// Use separate query to go get all counts
+		facetRequest := bleve.NewSearchRequestOptions(facetQuery, 0, 0, false)
+		addFacetRequests(facetRequest)
 
 		if result, err = b.indexer.Search(facetRequest); err != nil {
 			return 0, nil, nil, err
 		}
+	}
+	return total, searchResults, convertFacets(result.Facets), nil
+}
+
+func addFacetRequests(searchRequest *bleve.SearchRequest) {
+	searchRequest.AddFacet("languages", bleve.NewFacetRequest("Language", maxFacetSize))
+	searchRequest.AddFacet("directories", bleve.NewFacetRequest("Directory", maxFacetSize))
+	searchRequest.AddFacet("repos", bleve.NewFacetRequest("RepoKey", maxFacetSize))
+}
 
+func convertFacets(facets search.FacetResults) *SearchResultFacets {
+	result := &SearchResultFacets{
+		Languages:   make([]*SearchResultLanguages, 0, maxFacetSize),
+		Directories: make([]*SearchResultDirectory, 0, maxFacetSize),
+		Repos:       make([]*SearchResultRepo, 0, maxFacetSize),
 	}
-	languagesFacet := result.Facets["languages"]
-	for _, term := range languagesFacet.Terms {
+	for _, term := range facets["languages"].Terms {
 		if len(term.Term) == 0 {
 			continue
 		}
-		searchResultLanguages = append(searchResultLanguages, &SearchResultLanguages{
+		result.Languages = append(result.Languages, &SearchResultLanguages{
 			Language: term.Term,
 			Color:    enry.GetColor(term.Term),
 			Count:    term.Count,
 		})
 	}
-	return total, searchResults, searchResultLanguages, nil
+	for _, term := range facets["directories"].Terms {
+		if len(term.Term) == 0 {
+			continue
+		}
+		result.Directories = append(result.Directories, &SearchResultDirectory{
+			Directory: term.Term,
+			Count:     term.Count,
+		})
+	}
+	for _, term := range facets["repos"].Terms {
+		repoID, err := strconv.ParseInt(term.Term, 10, 64)
+		if err != nil {
+			continue
+		}
+		result.Repos = append(result.Repos, &SearchResultRepo{
+			RepoID: repoID,
+			Count:  term.Count,
+		})
+	}
+	return result
 }
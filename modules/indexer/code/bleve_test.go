@@ -36,4 +36,34 @@ func TestBleveIndexAndSearch(t *testing.T) {
 	defer idx.Close()
 
 	testIndexer("beleve", t, idx)
+
+	t.Run("regexp", func(t *testing.T) {
+		assert.NoError(t, index(idx, 1))
+		defer func() {
+			assert.NoError(t, idx.Delete(1))
+		}()
+
+		// the regular expressions match the lowercased words of the content
+		total, res, _, err := idx.Search(&SearchOptions{
+			Keyword:  "desc.*n",
+			Mode:     SearchModeRegexp,
+			Page:     1,
+			PageSize: 10,
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		if assert.Len(t, res, 1) {
+			assert.Equal(t, "README.md", res[0].Filename)
+			assert.Equal(t, "Description", res[0].Content[res[0].StartIndex:res[0].EndIndex])
+		}
+
+		total, _, _, err = idx.Search(&SearchOptions{
+			Keyword:  "repo[0-9]{2}",
+			Mode:     SearchModeRegexp,
+			Page:     1,
+			PageSize: 10,
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, total)
+	})
 }
@@ -29,7 +29,7 @@ import (
 )
 
 const (
-	esRepoIndexerLatestVersion = 1
+	esRepoIndexerLatestVersion = 2
 	// multi-match-types, currently only 2 types are used
 	// Reference: https://www.elastic.co/guide/en/elasticsearch/reference/7.0/query-dsl-multi-match-query.html#multi-match-types
 	esMultiMatchTypeBestFields   = "best_fields"
@@ -100,10 +100,18 @@ const (
 					"term_vector": "with_positions_offsets",
 					"index": true
 				},
+				"symbols": {
+					"type": "text",
+					"index": true
+				},
 				"commit_id": {
 					"type": "keyword",
 					"index": true
 				},
+				"directory": {
+					"type": "keyword",
+					"index": true
+				},
 				"language": {
 					"type": "keyword",
 					"index": true
@@ -216,6 +224,7 @@ func (b *ElasticSearchIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader
 	}
 
 	id := filenameIndexerID(repo.ID, update.Filename)
+	content := string(charset.ToUTF8DropErrors(fileContents))
 
 	return []elastic.BulkableRequest{
 		elastic.NewBulkIndexRequest().
@@ -223,8 +232,10 @@ func (b *ElasticSearchIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader
 			Id(id).
 			Doc(map[string]interface{}{
 				"repo_id":    repo.ID,
-				"content":    string(charset.ToUTF8DropErrors(fileContents)),
+				"content":    content,
+				"symbols":    extractSymbols(content),
 				"commit_id":  sha,
+				"directory":  directoryOfFilename(update.Filename),
 				"language":   analyze.GetCodeLanguage(update.Filename, fileContents),
 				"updated_at": timeutil.TimeStampNow(),
 			}),
@@ -243,7 +254,7 @@ func (b *ElasticSearchIndexer) Index(repo *models.Repository, sha string, change
 	reqs := make([]elastic.BulkableRequest, 0)
 	if len(changes.Updates) > 0 {
 
-		batchWriter, batchReader, cancel := git.CatFileBatch(repo.RepoPath())
+		batchWriter, batchReader, cancel := git.CatFileBatch(git.DefaultContext, repo.RepoPath())
 		defer cancel()
 
 		for _, update := range changes.Updates {
@@ -296,7 +307,7 @@ func indexPos(content, start, end string) (int, int) {
 	return startIdx, startIdx + len(start) + endIdx + len(end)
 }
 
-func convertResult(searchResult *elastic.SearchResult, kw string, pageSize int) (int64, []*SearchResult, []*SearchResultLanguages, error) {
+func convertResult(searchResult *elastic.SearchResult, kw string, pageSize int) (int64, []*SearchResult, error) {
 	hits := make([]*SearchResult, 0, pageSize)
 	for _, hit := range searchResult.Hits.Hits {
 		// FIXME: There is no way to get the position the keyword on the content currently on the same request.
@@ -320,7 +331,7 @@ func convertResult(searchResult *elastic.SearchResult, kw string, pageSize int)
 		var res = make(map[string]interface{})
 		json := jsoniter.ConfigCompatibleWithStandardLibrary
 		if err := json.Unmarshal(hit.Source, &res); err != nil {
-			return 0, nil, nil, err
+			return 0, nil, err
 		}
 
 		language := res["language"].(string)
@@ -338,89 +349,112 @@ func convertResult(searchResult *elastic.SearchResult, kw string, pageSize int)
 		})
 	}
 
-	return searchResult.TotalHits(), hits, extractAggs(searchResult), nil
+	return searchResult.TotalHits(), hits, nil
 }
 
-func extractAggs(searchResult *elastic.SearchResult) []*SearchResultLanguages {
-	var searchResultLanguages []*SearchResultLanguages
-	agg, found := searchResult.Aggregations.Terms("language")
-	if found {
-		searchResultLanguages = make([]*SearchResultLanguages, 0, 10)
-
+func extractAggs(searchResult *elastic.SearchResult) *SearchResultFacets {
+	facets := &SearchResultFacets{
+		Languages:   make([]*SearchResultLanguages, 0, maxFacetSize),
+		Directories: make([]*SearchResultDirectory, 0, maxFacetSize),
+		Repos:       make([]*SearchResultRepo, 0, maxFacetSize),
+	}
+	if agg, found := searchResult.Aggregations.Terms("language"); found {
 		for _, bucket := range agg.Buckets {
-			searchResultLanguages = append(searchResultLanguages, &SearchResultLanguages{
+			facets.Languages = append(facets.Languages, &SearchResultLanguages{
 				Language: bucket.Key.(string),
 				Color:    enry.GetColor(bucket.Key.(string)),
 				Count:    int(bucket.DocCount),
 			})
 		}
 	}
-	return searchResultLanguages
+	if agg, found := searchResult.Aggregations.Terms("directory"); found {
+		for _, bucket := range agg.Buckets {
+			if directory := bucket.Key.(string); len(directory) > 0 {
+				facets.Directories = append(facets.Directories, &SearchResultDirectory{
+					Directory: directory,
+					Count:     int(bucket.DocCount),
+				})
+			}
+		}
+	}
+	if agg, found := searchResult.Aggregations.Terms("repo_id"); found {
+		for _, bucket := range agg.Buckets {
+			facets.Repos = append(facets.Repos, &SearchResultRepo{
+				RepoID: int64(bucket.Key.(float64)),
+				Count:  int(bucket.DocCount),
+			})
+		}
+	}
+	return facets
 }
 
 // Search searches for codes and language stats by given conditions.
-func (b *ElasticSearchIndexer) Search(repoIDs []int64, language, keyword string, page, pageSize int, isMatch bool) (int64, []*SearchResult, []*SearchResultLanguages, error) {
+func (b *ElasticSearchIndexer) Search(opts *SearchOptions) (int64, []*SearchResult, *SearchResultFacets, error) {
+	if opts.Mode == SearchModeRegexp {
+		return 0, nil, nil, ErrRegexpSearchUnsupported
+	}
+
 	searchType := esMultiMatchTypeBestFields
-	if isMatch {
+	if opts.Mode == SearchModeMatch {
 		searchType = esMultiMatchTypePhrasePrefix
 	}
 
-	kwQuery := elastic.NewMultiMatchQuery(keyword, "content").Type(searchType)
+	kwQuery := elastic.NewMultiMatchQuery(opts.Keyword, "content").Type(searchType)
 	query := elastic.NewBoolQuery()
 	query = query.Must(kwQuery)
-	if len(repoIDs) > 0 {
-		var repoStrs = make([]interface{}, 0, len(repoIDs))
-		for _, repoID := range repoIDs {
+	if len(opts.RepoIDs) > 0 {
+		var repoStrs = make([]interface{}, 0, len(opts.RepoIDs))
+		for _, repoID := range opts.RepoIDs {
 			repoStrs = append(repoStrs, repoID)
 		}
 		repoQuery := elastic.NewTermsQuery("repo_id", repoStrs...)
 		query = query.Must(repoQuery)
 	}
+	// the files defining a symbol matching the keyword come first
+	query = query.Should(elastic.NewMultiMatchQuery(opts.Keyword, "symbols").Type(searchType).Boost(symbolBoost))
 
 	var (
-		start       int
-		kw          = "<em>" + keyword + "</em>"
-		aggregation = elastic.NewTermsAggregation().Field("language").Size(10).OrderByCountDesc()
+		start = 0
+		kw    = "<em>" + opts.Keyword + "</em>"
+		aggs  = map[string]elastic.Aggregation{
+			"language":  elastic.NewTermsAggregation().Field("language").Size(maxFacetSize).OrderByCountDesc(),
+			"directory": elastic.NewTermsAggregation().Field("directory").Size(maxFacetSize).OrderByCountDesc(),
+			"repo_id":   elastic.NewTermsAggregation().Field("repo_id").Size(maxFacetSize).OrderByCountDesc(),
+		}
 	)
 
-	if page > 0 {
-		start = (page - 1) * pageSize
+	if opts.Page > 0 {
+		start = (opts.Page - 1) * opts.PageSize
 	}
 
-	if len(language) == 0 {
-		searchResult, err := b.client.Search().
+	isFiltered := len(opts.Language) > 0 || len(opts.Path) > 0
+	var facets *SearchResultFacets
+	if isFiltered {
+		countService := b.client.Search().
 			Index(b.indexerAliasName).
-			Aggregation("language", aggregation).
 			Query(query).
-			Highlight(
-				elastic.NewHighlight().
-					Field("content").
-					NumOfFragments(0). // return all highting content on fragments
-					HighlighterType("fvh"),
-			).
-			Sort("repo_id", true).
-			From(start).Size(pageSize).
-			Do(context.Background())
+			Size(0) // We only needs stats information
+		for name, agg := range aggs {
+			countService = countService.Aggregation(name, agg)
+		}
+		countResult, err := countService.Do(context.Background())
 		if err != nil {
 			return 0, nil, nil, err
 		}
+		facets = extractAggs(countResult)
 
-		return convertResult(searchResult, kw, pageSize)
-	}
-
-	langQuery := elastic.NewMatchQuery("language", language)
-	countResult, err := b.client.Search().
-		Index(b.indexerAliasName).
-		Aggregation("language", aggregation).
-		Query(query).
-		Size(0). // We only needs stats information
-		Do(context.Background())
-	if err != nil {
-		return 0, nil, nil, err
+		if len(opts.Language) > 0 {
+			query = query.Filter(elastic.NewMatchQuery("language", opts.Language))
+		}
+		if len(opts.Path) > 0 {
+			query = query.Filter(elastic.NewBoolQuery().Should(
+				elastic.NewTermQuery("directory", opts.Path),
+				elastic.NewPrefixQuery("directory", opts.Path+"/"),
+			).MinimumNumberShouldMatch(1))
+		}
 	}
 
-	query = query.Must(langQuery)
-	searchResult, err := b.client.Search().
+	searchService := b.client.Search().
 		Index(b.indexerAliasName).
 		Query(query).
 		Highlight(
@@ -429,16 +463,26 @@ func (b *ElasticSearchIndexer) Search(repoIDs []int64, language, keyword string,
 				NumOfFragments(0). // return all highting content on fragments
 				HighlighterType("fvh"),
 		).
-		Sort("repo_id", true).
-		From(start).Size(pageSize).
-		Do(context.Background())
+		SortBy(elastic.NewScoreSort(), elastic.NewFieldSort("repo_id").Asc()).
+		From(start).Size(opts.PageSize)
+	if !isFiltered {
+		for name, agg := range aggs {
+			searchService = searchService.Aggregation(name, agg)
+		}
+	}
+	searchResult, err := searchService.Do(context.Background())
 	if err != nil {
 		return 0, nil, nil, err
 	}
 
-	total, hits, _, err := convertResult(searchResult, kw, pageSize)
-
-	return total, hits, extractAggs(countResult), err
+	total, hits, err := convertResult(searchResult, kw, opts.PageSize)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	if !isFiltered {
+		facets = extractAggs(searchResult)
+	}
+	return total, hits, facets, nil
 }
 
 // Close implements indexer
@@ -6,7 +6,10 @@ package code
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
+	"path"
 	"strconv"
 	"strings"
 	"time"
@@ -39,14 +42,103 @@ type SearchResultLanguages struct {
 	Count    int
 }
 
+// SearchResultDirectory result of top directories count in search results
+type SearchResultDirectory struct {
+	Directory string
+	Count     int
+}
+
+// SearchResultRepo result of top repositories count in search results
+type SearchResultRepo struct {
+	RepoID int64
+	Count  int
+}
+
+// SearchResultFacets counts of the search results by language, directory and repository, regardless of the
+// language and the path the search is restricted to
+type SearchResultFacets struct {
+	Languages   []*SearchResultLanguages
+	Directories []*SearchResultDirectory
+	Repos       []*SearchResultRepo
+}
+
+// SearchMode how the keyword of a search is matched
+type SearchMode int
+
+const (
+	// SearchModeFuzzy matches the words of the keyword
+	SearchModeFuzzy SearchMode = iota
+	// SearchModeMatch matches the keyword exactly
+	SearchModeMatch
+	// SearchModeRegexp matches the keyword as a regular expression against the words of the code, only supported by
+	// the bleve indexer
+	SearchModeRegexp
+)
+
+// ParseSearchMode returns the mode of the query type of a search page, which is "match", "regexp" or empty
+func ParseSearchMode(queryType string) SearchMode {
+	switch queryType {
+	case "match":
+		return SearchModeMatch
+	case "regexp":
+		return SearchModeRegexp
+	default:
+		return SearchModeFuzzy
+	}
+}
+
+// maxFacetSize is the number of languages, directories and repositories the search results are counted by
+const maxFacetSize = 10
+
+// SearchOptions options of a code search
+type SearchOptions struct {
+	// RepoIDs restricts the search to these repositories if it is not empty
+	RepoIDs  []int64
+	Language string
+	// Path restricts the search to the files in this directory and its subdirectories
+	Path     string
+	Keyword  string
+	Mode     SearchMode
+	Page     int
+	PageSize int
+}
+
 // Indexer defines an interface to index and search code contents
 type Indexer interface {
 	Index(repo *models.Repository, sha string, changes *repoChanges) error
 	Delete(repoID int64) error
-	Search(repoIDs []int64, language, keyword string, page, pageSize int, isMatch bool) (int64, []*SearchResult, []*SearchResultLanguages, error)
+	Search(opts *SearchOptions) (int64, []*SearchResult, *SearchResultFacets, error)
 	Close()
 }
 
+// ErrRegexpSearchUnsupported is the error returned if the indexer does not support regular expression searches
+var ErrRegexpSearchUnsupported = errors.New("regular expression searches are only supported by the bleve code indexer")
+
+// SupportsRegexpSearch returns if the configured indexer supports regular expression searches
+func SupportsRegexpSearch() bool {
+	return setting.Indexer.RepoType == "bleve"
+}
+
+// ErrInvalidSearchRegexp represents an invalid regular expression of a search
+type ErrInvalidSearchRegexp struct {
+	Regexp string
+	Err    error
+}
+
+// IsErrInvalidSearchRegexp checks if an error is a ErrInvalidSearchRegexp.
+func IsErrInvalidSearchRegexp(err error) bool {
+	_, ok := err.(ErrInvalidSearchRegexp)
+	return ok
+}
+
+func (err ErrInvalidSearchRegexp) Error() string {
+	return fmt.Sprintf("invalid regular expression [regexp: %s]: %v", err.Regexp, err.Err)
+}
+
+// symbolBoost is how much more a match of the keyword in the names of the symbols defined in a file counts than in its
+// content, so that the definitions come first
+const symbolBoost = 5.0
+
 func filenameIndexerID(repoID int64, filename string) string {
 	return indexerID(repoID) + "_" + filename
 }
@@ -64,6 +156,14 @@ func parseIndexerID(indexerID string) (int64, string) {
 	return repoID, indexerID[index+1:]
 }
 
+// directoryOfFilename returns the directory of a file, empty for the files at the root of the repository
+func directoryOfFilename(filename string) string {
+	if dir := path.Dir(filename); dir != "." {
+		return dir
+	}
+	return ""
+}
+
 func filenameOfIndexerID(indexerID string) string {
 	index := strings.IndexByte(indexerID, '_')
 	if index == -1 {
@@ -64,10 +64,18 @@ func testIndexer(name string, t *testing.T, indexer Indexer) {
 
 		for _, kw := range keywords {
 			t.Run(kw.Keyword, func(t *testing.T) {
-				total, res, langs, err := indexer.Search(kw.RepoIDs, "", kw.Keyword, 1, 10, false)
+				total, res, facets, err := indexer.Search(&SearchOptions{
+					RepoIDs:  kw.RepoIDs,
+					Keyword:  kw.Keyword,
+					Page:     1,
+					PageSize: 10,
+				})
 				assert.NoError(t, err)
 				assert.EqualValues(t, len(kw.IDs), total)
-				assert.EqualValues(t, kw.Langs, len(langs))
+				assert.EqualValues(t, kw.Langs, len(facets.Languages))
+				assert.EqualValues(t, len(kw.IDs), len(facets.Repos))
+				// the README is at the root of the repository
+				assert.Empty(t, facets.Directories)
 
 				var ids = make([]int64, 0, len(res))
 				for _, hit := range res {
@@ -78,6 +86,23 @@ func testIndexer(name string, t *testing.T, indexer Indexer) {
 			})
 		}
 
+		t.Run("path", func(t *testing.T) {
+			// the facets are counted regardless of the path the search is restricted to
+			total, res, facets, err := indexer.Search(&SearchOptions{
+				Keyword:  "Description",
+				Path:     "docs",
+				Page:     1,
+				PageSize: 10,
+			})
+			assert.NoError(t, err)
+			assert.EqualValues(t, 0, total)
+			assert.Empty(t, res)
+			if assert.Len(t, facets.Repos, 1) {
+				assert.EqualValues(t, repoID, facets.Repos[0].RepoID)
+				assert.EqualValues(t, 1, facets.Repos[0].Count)
+			}
+		})
+
 		assert.NoError(t, indexer.Delete(repoID))
 	})
 }
@@ -6,6 +6,7 @@ package code
 
 import (
 	"bytes"
+	"regexp"
 	"strings"
 
 	"code.gitea.io/gitea/modules/highlight"
@@ -22,6 +23,7 @@ type Result struct {
 	Language       string
 	Color          string
 	LineNumbers    []int
+	Content        string
 	FormattedLines string
 }
 
@@ -101,17 +103,28 @@ func searchResult(result *SearchResult, startIndex, endIndex int) (*Result, erro
 		Language:       result.Language,
 		Color:          result.Color,
 		LineNumbers:    lineNumbers,
+		Content:        formattedLinesBuffer.String(),
 		FormattedLines: highlight.Code(result.Filename, formattedLinesBuffer.String()),
 	}, nil
 }
 
 // PerformSearch perform a search on a repository
-func PerformSearch(repoIDs []int64, language, keyword string, page, pageSize int, isMatch bool) (int, []*Result, []*SearchResultLanguages, error) {
-	if len(keyword) == 0 {
+func PerformSearch(opts *SearchOptions) (int, []*Result, *SearchResultFacets, error) {
+	if len(opts.Keyword) == 0 {
 		return 0, nil, nil, nil
 	}
+	if opts.Mode == SearchModeRegexp {
+		if !SupportsRegexpSearch() {
+			return 0, nil, nil, ErrRegexpSearchUnsupported
+		}
+		if _, err := regexp.Compile(opts.Keyword); err != nil {
+			return 0, nil, nil, ErrInvalidSearchRegexp{Regexp: opts.Keyword, Err: err}
+		}
+	}
+	searchOpts := *opts
+	searchOpts.Path = strings.Trim(opts.Path, "/")
 
-	total, results, resultLanguages, err := indexer.Search(repoIDs, language, keyword, page, pageSize, isMatch)
+	total, results, facets, err := indexer.Search(&searchOpts)
 	if err != nil {
 		return 0, nil, nil, err
 	}
@@ -125,5 +138,5 @@ func PerformSearch(repoIDs []int64, language, keyword string, page, pageSize int
 			return 0, nil, nil, err
 		}
 	}
-	return int(total), displayResults, resultLanguages, nil
+	return int(total), displayResults, facets, nil
 }
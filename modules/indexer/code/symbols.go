@@ -0,0 +1,40 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package code
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxSymbols is the maximum number of symbols indexed for a file
+const maxSymbols = 1000
+
+// symbolDefinitionPattern matches the definitions of functions, methods, types, classes and the like in most languages,
+// the name of the symbol being the only group
+var symbolDefinitionPattern = regexp.MustCompile(`(?m)^[ \t]*` +
+	`(?:(?:export|public|private|protected|internal|static|abstract|final|sealed|async|pub(?:\([a-z]+\))?|unsafe|inline|virtual|override|default|data|case)[ \t]+)*` +
+	`(?:func|function|def|class|struct|interface|enum|trait|type|fn|fun|module|macro|impl|record|object|protocol|sub)[ \t]+` +
+	`(?:\([^)]*\)[ \t]*)?` + // the receiver of a Go method
+	`([A-Za-z_$][A-Za-z0-9_$]*)`)
+
+// extractSymbols returns the names of the symbols defined in content, separated by spaces. The definitions are found
+// with a heuristic which works for the syntax of most languages rather than by parsing them.
+func extractSymbols(content string) string {
+	matches := symbolDefinitionPattern.FindAllStringSubmatch(content, -1)
+	symbols := make([]string, 0, len(matches))
+	seen := make(map[string]bool, len(matches))
+	for _, match := range matches {
+		if seen[match[1]] {
+			continue
+		}
+		seen[match[1]] = true
+		symbols = append(symbols, match[1])
+		if len(symbols) == maxSymbols {
+			break
+		}
+	}
+	return strings.Join(symbols, " ")
+}
@@ -0,0 +1,26 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package code
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractSymbols(t *testing.T) {
+	for _, c := range []struct {
+		content, symbols string
+	}{
+		{"package main\n\nfunc main() {\n\tfoo()\n}\n\nfunc (r *Repo) Name() string {}\n\ntype Repo struct {}\n", "main Name Repo"},
+		{"class Parser:\n    def parse(self):\n        pass\n\n    async def fetch(self):\n        pass\n", "Parser parse fetch"},
+		{"export default class App {}\nexport function render() {}\n", "App render"},
+		{"pub(crate) fn new() -> Self {}\npub struct Config {}\nimpl Config {}\n", "new Config"},
+		{"public abstract class Base {}\n// function commented out\nlet x = function() {}\n", "Base"},
+		{"# Title\n\nSome text defining nothing.\n", ""},
+	} {
+		assert.Equal(t, c.symbols, extractSymbols(c.content), c.content)
+	}
+}
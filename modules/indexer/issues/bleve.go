@@ -10,6 +10,7 @@ import (
 	"strconv"
 
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 	"github.com/blevesearch/bleve/v2"
 	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
@@ -18,6 +19,11 @@ import (
 	"github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
 	"github.com/blevesearch/bleve/v2/index/upsidedown"
 	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/registry"
+	"github.com/blevesearch/bleve/v2/search/highlight"
+	html_format "github.com/blevesearch/bleve/v2/search/highlight/format/html"
+	simple_fragmenter "github.com/blevesearch/bleve/v2/search/highlight/fragmenter/simple"
+	simple_highlighter "github.com/blevesearch/bleve/v2/search/highlight/highlighter/simple"
 	"github.com/blevesearch/bleve/v2/search/query"
 	"github.com/ethantkoenig/rupture"
 )
@@ -25,9 +31,21 @@ import (
 const (
 	issueIndexerAnalyzer      = "issueIndexer"
 	issueIndexerDocType       = "issueIndexerDocType"
-	issueIndexerLatestVersion = 1
+	issueIndexerHighlighter   = "issueIndexerHighlighter"
+	issueIndexerLatestVersion = 2
 )
 
+func init() {
+	// the fragments are escaped, the matches being marked like the ones of the other indexers
+	registry.RegisterHighlighter(issueIndexerHighlighter, func(config map[string]interface{}, cache *registry.Cache) (highlight.Highlighter, error) {
+		return simple_highlighter.NewHighlighter(
+			simple_fragmenter.NewFragmenter(setting.Indexer.IssueHighlightFragmentSize),
+			html_format.NewFragmentFormatter(highlightPreTag, highlightPostTag),
+			simple_highlighter.DefaultSeparator,
+		), nil
+	})
+}
+
 // indexerID a bleve-compatible unique identifier for an integer id
 func indexerID(id int64) string {
 	return strconv.FormatInt(id, 36)
@@ -119,8 +137,8 @@ func createIssueIndexer(path string, latestVersion int) (bleve.Index, error) {
 	numericFieldMapping.IncludeInAll = false
 	docMapping.AddFieldMappingsAt("RepoID", numericFieldMapping)
 
+	// the text fields are stored to be highlighted
 	textFieldMapping := bleve.NewTextFieldMapping()
-	textFieldMapping.Store = false
 	textFieldMapping.IncludeInAll = false
 	docMapping.AddFieldMappingsAt("Title", textFieldMapping)
 	docMapping.AddFieldMappingsAt("Content", textFieldMapping)
@@ -227,9 +245,16 @@ func (b *BleveIndexer) Delete(ids ...int64) error {
 	return batch.Flush()
 }
 
+// bleveFields are the names of the fields of the indexer data
+var bleveFields = map[string]string{
+	FieldTitle:    "Title",
+	FieldContent:  "Content",
+	FieldComments: "Comments",
+}
+
 // Search searches for issues by given conditions.
 // Returns the matching issue IDs
-func (b *BleveIndexer) Search(keyword string, repoIDs []int64, limit, start int) (*SearchResult, error) {
+func (b *BleveIndexer) Search(keyword string, fields []string, repoIDs []int64, limit, start int) (*SearchResult, error) {
 	var repoQueriesP []*query.NumericRangeQuery
 	for _, repoID := range repoIDs {
 		repoQueriesP = append(repoQueriesP, numericEqualityQuery(repoID, "RepoID"))
@@ -239,15 +264,30 @@ func (b *BleveIndexer) Search(keyword string, repoIDs []int64, limit, start int)
 		repoQueries[i] = query.Query(v)
 	}
 
+	if len(fields) == 0 {
+		fields = []string{FieldTitle, FieldContent, FieldComments}
+	}
+	keywordQueries := make([]query.Query, 0, len(fields))
+	for _, field := range fields {
+		if bleveField, ok := bleveFields[field]; ok {
+			keywordQueries = append(keywordQueries, newMatchPhraseQuery(keyword, bleveField, issueIndexerAnalyzer))
+		}
+	}
+
 	indexerQuery := bleve.NewConjunctionQuery(
 		bleve.NewDisjunctionQuery(repoQueries...),
-		bleve.NewDisjunctionQuery(
-			newMatchPhraseQuery(keyword, "Title", issueIndexerAnalyzer),
-			newMatchPhraseQuery(keyword, "Content", issueIndexerAnalyzer),
-			newMatchPhraseQuery(keyword, "Comments", issueIndexerAnalyzer),
-		))
+		bleve.NewDisjunctionQuery(keywordQueries...),
+	)
 	search := bleve.NewSearchRequestOptions(indexerQuery, limit, start, false)
 	search.SortBy([]string{"-_score"})
+	if setting.Indexer.IssueHighlight {
+		search.Highlight = bleve.NewHighlightWithStyle(issueIndexerHighlighter)
+		for _, field := range fields {
+			if bleveField, ok := bleveFields[field]; ok {
+				search.Highlight.AddField(bleveField)
+			}
+		}
+	}
 
 	result, err := b.indexer.Search(search)
 	if err != nil {
@@ -255,16 +295,22 @@ func (b *BleveIndexer) Search(keyword string, repoIDs []int64, limit, start int)
 	}
 
 	var ret = SearchResult{
-		Hits: make([]Match, 0, len(result.Hits)),
+		Total: int64(result.Total),
+		Hits:  make([]Match, 0, len(result.Hits)),
 	}
 	for _, hit := range result.Hits {
 		id, err := idOfIndexerID(hit.ID)
 		if err != nil {
 			return nil, err
 		}
-		ret.Hits = append(ret.Hits, Match{
-			ID: id,
-		})
+		match := Match{
+			ID:    id,
+			Score: hit.Score,
+		}
+		for field, bleveField := range bleveFields {
+			match.addHighlight(field, hit.Fragments[bleveField]...)
+		}
+		ret.Hits = append(ret.Hits, match)
 	}
 	return &ret, nil
 }
@@ -85,7 +85,7 @@ func TestBleveIndexAndSearch(t *testing.T) {
 	)
 
 	for _, kw := range keywords {
-		res, err := indexer.Search(kw.Keyword, []int64{2}, 10, 0)
+		res, err := indexer.Search(kw.Keyword, nil, []int64{2}, 10, 0)
 		assert.NoError(t, err)
 
 		var ids = make([]int64, 0, len(res.Hits))
@@ -94,4 +94,12 @@ func TestBleveIndexAndSearch(t *testing.T) {
 		}
 		assert.ElementsMatch(t, kw.IDs, ids)
 	}
+
+	res, err := indexer.Search("japanese", nil, []int64{2}, 10, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, res.Hits, 1) {
+		assert.Equal(t, map[string][]string{
+			FieldContent: {"Chinese Korean and <mark>Japanese</mark> should be supported but I would like it&#39;s not enabled by default"},
+		}, res.Hits[0].Highlights)
+	}
 }
@@ -30,8 +30,8 @@ func (db *DBIndexer) Close() {
 }
 
 // Search dummy function
-func (db *DBIndexer) Search(kw string, repoIDs []int64, limit, start int) (*SearchResult, error) {
-	total, ids, err := models.SearchIssueIDsByKeyword(kw, repoIDs, limit, start)
+func (db *DBIndexer) Search(kw string, fields []string, repoIDs []int64, limit, start int) (*SearchResult, error) {
+	total, ids, err := models.SearchIssueIDsByKeywordIn(kw, fields, repoIDs, limit, start)
 	if err != nil {
 		return nil, err
 	}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/olivere/elastic/v7"
 )
@@ -190,8 +191,11 @@ func (b *ElasticSearchIndexer) Delete(ids ...int64) error {
 
 // Search searches for issues by given conditions.
 // Returns the matching issue IDs
-func (b *ElasticSearchIndexer) Search(keyword string, repoIDs []int64, limit, start int) (*SearchResult, error) {
-	kwQuery := elastic.NewMultiMatchQuery(keyword, "title", "content", "comments")
+func (b *ElasticSearchIndexer) Search(keyword string, fields []string, repoIDs []int64, limit, start int) (*SearchResult, error) {
+	if len(fields) == 0 {
+		fields = []string{FieldTitle, FieldContent, FieldComments}
+	}
+	kwQuery := elastic.NewMultiMatchQuery(keyword, fields...)
 	query := elastic.NewBoolQuery()
 	query = query.Must(kwQuery)
 	if len(repoIDs) > 0 {
@@ -202,12 +206,25 @@ func (b *ElasticSearchIndexer) Search(keyword string, repoIDs []int64, limit, st
 		repoQuery := elastic.NewTermsQuery("repo_id", repoStrs...)
 		query = query.Must(repoQuery)
 	}
-	searchResult, err := b.client.Search().
+	search := b.client.Search().
 		Index(b.indexerName).
 		Query(query).
 		Sort("_score", false).
-		From(start).Size(limit).
-		Do(context.Background())
+		From(start).Size(limit)
+	if setting.Indexer.IssueHighlight {
+		highlightFields := make([]*elastic.HighlighterField, 0, len(fields))
+		for _, field := range fields {
+			highlightFields = append(highlightFields, elastic.NewHighlighterField(field))
+		}
+		search = search.Highlight(elastic.NewHighlight().
+			Fields(highlightFields...).
+			Encoder("html").
+			PreTags(highlightPreTag).
+			PostTags(highlightPostTag).
+			FragmentSize(setting.Indexer.IssueHighlightFragmentSize).
+			NumOfFragments(setting.Indexer.IssueHighlightFragmentCount))
+	}
+	searchResult, err := search.Do(context.Background())
 	if err != nil {
 		return nil, err
 	}
@@ -215,9 +232,16 @@ func (b *ElasticSearchIndexer) Search(keyword string, repoIDs []int64, limit, st
 	hits := make([]Match, 0, limit)
 	for _, hit := range searchResult.Hits.Hits {
 		id, _ := strconv.ParseInt(hit.Id, 10, 64)
-		hits = append(hits, Match{
+		match := Match{
 			ID: id,
-		})
+		}
+		if hit.Score != nil {
+			match.Score = *hit.Score
+		}
+		for field, snippets := range hit.Highlight {
+			match.addHighlight(field, snippets...)
+		}
+		hits = append(hits, match)
 	}
 
 	return &SearchResult{
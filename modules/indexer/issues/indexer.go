@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -28,12 +29,46 @@ type IndexerData struct {
 	Comments []string `json:"comments"`
 	IsDelete bool     `json:"is_delete"`
 	IDs      []int64  `json:"ids"`
+	queue.TraceContext
 }
 
+// The fields of the issues a keyword is searched in
+const (
+	FieldTitle    = "title"
+	FieldContent  = "content"
+	FieldComments = "comments"
+)
+
 // Match represents on search result
 type Match struct {
 	ID    int64   `json:"id"`
 	Score float64 `json:"score"`
+	// Highlights are the HTML snippets of the fields matching the keyword, by field, the matches being marked with
+	// highlightPreTag and highlightPostTag
+	Highlights map[string][]string `json:"highlights,omitempty"`
+}
+
+// The tags marking the matches in the highlights
+const (
+	highlightPreTag  = "<mark>"
+	highlightPostTag = "</mark>"
+)
+
+// addHighlight adds the snippets of a field which contain a match to the highlights of a match, up to the number of
+// snippets of a field of the settings
+func (m *Match) addHighlight(field string, snippets ...string) {
+	for _, snippet := range snippets {
+		if len(m.Highlights[field]) >= setting.Indexer.IssueHighlightFragmentCount {
+			return
+		}
+		if !strings.Contains(snippet, highlightPreTag) {
+			continue
+		}
+		if m.Highlights == nil {
+			m.Highlights = make(map[string][]string)
+		}
+		m.Highlights[field] = append(m.Highlights[field], snippet)
+	}
 }
 
 // SearchResult represents search results
@@ -47,7 +82,8 @@ type Indexer interface {
 	Init() (bool, error)
 	Index(issue []*IndexerData) error
 	Delete(ids ...int64) error
-	Search(kw string, repoIDs []int64, limit, start int) (*SearchResult, error)
+	// Search searches kw in fields, or in all fields if it is empty
+	Search(kw string, fields []string, repoIDs []int64, limit, start int) (*SearchResult, error)
 	Close()
 }
 
@@ -100,7 +136,7 @@ func InitIssueIndexer(syncReindex bool) {
 
 	// Create the Queue
 	switch setting.Indexer.IssueType {
-	case "bleve", "elasticsearch":
+	case "bleve", "elasticsearch", "meilisearch":
 		handler := func(data ...queue.Data) {
 			indexer := holder.get()
 			if indexer == nil {
@@ -182,6 +218,19 @@ func InitIssueIndexer(syncReindex bool) {
 				populate = !exist
 				holder.set(issueIndexer)
 			})
+		case "meilisearch":
+			graceful.GetManager().RunWithShutdownFns(func(_, atTerminate func(context.Context, func())) {
+				issueIndexer, err := NewMeilisearchIndexer(setting.Indexer.IssueConnStr, setting.Indexer.IssueIndexerName)
+				if err != nil {
+					log.Fatal("Unable to initialize Meilisearch Issue Indexer at connection: %s Error: %v", setting.Indexer.IssueConnStr, err)
+				}
+				exist, err := issueIndexer.Init()
+				if err != nil {
+					log.Fatal("Unable to issueIndexer.Init with connection %s Error: %v", setting.Indexer.IssueConnStr, err)
+				}
+				populate = !exist
+				holder.set(issueIndexer)
+			})
 		case "db":
 			issueIndexer := &DBIndexer{}
 			holder.set(issueIndexer)
@@ -262,13 +311,13 @@ func populateIssueIndexer(ctx context.Context) {
 				return
 			default:
 			}
-			UpdateRepoIndexer(repo)
+			UpdateRepoIndexer(ctx, repo)
 		}
 	}
 }
 
 // UpdateRepoIndexer add/update all issues of the repositories
-func UpdateRepoIndexer(repo *models.Repository) {
+func UpdateRepoIndexer(ctx context.Context, repo *models.Repository) {
 	is, err := models.Issues(&models.IssuesOptions{
 		RepoIDs:  []int64{repo.ID},
 		IsClosed: util.OptionalBoolNone,
@@ -283,12 +332,12 @@ func UpdateRepoIndexer(repo *models.Repository) {
 		return
 	}
 	for _, issue := range is {
-		UpdateIssueIndexer(issue)
+		UpdateIssueIndexer(ctx, issue)
 	}
 }
 
 // UpdateIssueIndexer add/update an issue to the issue indexer
-func UpdateIssueIndexer(issue *models.Issue) {
+func UpdateIssueIndexer(ctx context.Context, issue *models.Issue) {
 	var comments []string
 	for _, comment := range issue.Comments {
 		if comment.Type == models.CommentTypeComment {
@@ -302,6 +351,7 @@ func UpdateIssueIndexer(issue *models.Issue) {
 		Content:  issue.Content,
 		Comments: comments,
 	}
+	indexerData.SetSpanContext(ctx)
 	log.Debug("Adding to channel: %v", indexerData)
 	if err := issueIndexerQueue.Push(indexerData); err != nil {
 		log.Error("Unable to push to issue indexer: %v: Error: %v", indexerData, err)
@@ -309,7 +359,7 @@ func UpdateIssueIndexer(issue *models.Issue) {
 }
 
 // DeleteRepoIssueIndexer deletes repo's all issues indexes
-func DeleteRepoIssueIndexer(repo *models.Repository) {
+func DeleteRepoIssueIndexer(ctx context.Context, repo *models.Repository) {
 	var ids []int64
 	ids, err := models.GetIssueIDsByRepoID(repo.ID)
 	if err != nil {
@@ -324,6 +374,7 @@ func DeleteRepoIssueIndexer(repo *models.Repository) {
 		IDs:      ids,
 		IsDelete: true,
 	}
+	indexerData.SetSpanContext(ctx)
 	if err := issueIndexerQueue.Push(indexerData); err != nil {
 		log.Error("Unable to push to issue indexer: %v: Error: %v", indexerData, err)
 	}
@@ -332,19 +383,36 @@ func DeleteRepoIssueIndexer(repo *models.Repository) {
 // SearchIssuesByKeyword search issue ids by keywords and repo id
 // WARNNING: You have to ensure user have permission to visit repoIDs' issues
 func SearchIssuesByKeyword(repoIDs []int64, keyword string) ([]int64, error) {
-	var issueIDs []int64
+	return SearchIssuesByKeywordIn(repoIDs, keyword, nil)
+}
+
+// SearchIssuesByKeywordIn search issue ids by keywords in fields, or in all fields if it is empty, and repo id
+// WARNNING: You have to ensure user have permission to visit repoIDs' issues
+func SearchIssuesByKeywordIn(repoIDs []int64, keyword string, fields []string) ([]int64, error) {
+	matches, err := SearchIssueMatches(repoIDs, keyword, fields)
+	if err != nil {
+		return nil, err
+	}
+	issueIDs := make([]int64, 0, len(matches))
+	for _, match := range matches {
+		issueIDs = append(issueIDs, match.ID)
+	}
+	return issueIDs, nil
+}
+
+// SearchIssueMatches search issues by keywords in fields, or in all fields if it is empty, and repo id, returning
+// their highlights if they are enabled
+// WARNNING: You have to ensure user have permission to visit repoIDs' issues
+func SearchIssueMatches(repoIDs []int64, keyword string, fields []string) ([]Match, error) {
 	indexer := holder.get()
 
 	if indexer == nil {
 		log.Error("SearchIssuesByKeyword(): unable to get indexer!")
 		return nil, fmt.Errorf("unable to get issue indexer")
 	}
-	res, err := indexer.Search(keyword, repoIDs, 50, 0)
+	res, err := indexer.Search(keyword, fields, repoIDs, 50, 0)
 	if err != nil {
 		return nil, err
 	}
-	for _, r := range res.Hits {
-		issueIDs = append(issueIDs, r.ID)
-	}
-	return issueIDs, nil
+	return res.Hits, nil
 }
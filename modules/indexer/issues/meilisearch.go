@@ -0,0 +1,314 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+var (
+	_ Indexer = &MeilisearchIndexer{}
+)
+
+const (
+	// meilisearchTaskTimeout is how long the indexer waits for Meilisearch to process a task
+	meilisearchTaskTimeout = 30 * time.Second
+	// meilisearchWordSize is the number of characters a word counts for when cropping the highlights
+	meilisearchWordSize = 6
+	// the tags marking the matches in the highlights of Meilisearch, which does not escape them, replaced with the
+	// tags of the other indexers once escaped
+	meilisearchPreTag  = "\ue000"
+	meilisearchPostTag = "\ue001"
+)
+
+// MeilisearchIndexer implements Indexer interface with the REST API of Meilisearch
+type MeilisearchIndexer struct {
+	client    *http.Client
+	url       string
+	apiKey    string
+	indexName string
+}
+
+// NewMeilisearchIndexer creates a new Meilisearch indexer. The API key is the password, or the user, of the
+// connection string, e.g. http://:apikey@localhost:7700.
+func NewMeilisearchIndexer(connStr, indexName string) (*MeilisearchIndexer, error) {
+	u, err := url.Parse(connStr)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid Meilisearch URL %q", connStr)
+	}
+
+	var apiKey string
+	if u.User != nil {
+		var ok bool
+		if apiKey, ok = u.User.Password(); !ok {
+			apiKey = u.User.Username()
+		}
+		u.User = nil
+	}
+	return &MeilisearchIndexer{
+		client:    &http.Client{Timeout: meilisearchTaskTimeout},
+		url:       strings.TrimSuffix(u.String(), "/"),
+		apiKey:    apiKey,
+		indexName: indexName,
+	}, nil
+}
+
+type meilisearchError struct {
+	Message string `json:"message"`
+	Code    string `json:"code"`
+}
+
+func (err *meilisearchError) Error() string {
+	return fmt.Sprintf("meilisearch: %s (%s)", err.Message, err.Code)
+}
+
+// request sends a request to the API, decoding the response into result if it is not nil
+func (m *MeilisearchIndexer) request(method, path string, body, result interface{}) error {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	var reader io.Reader
+	if body != nil {
+		bs, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(bs)
+	}
+
+	req, err := http.NewRequest(method, m.url+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if len(m.apiKey) > 0 {
+		req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		apiErr := &meilisearchError{}
+		if err := json.NewDecoder(resp.Body).Decode(apiErr); err != nil || len(apiErr.Message) == 0 {
+			return fmt.Errorf("meilisearch: unexpected status %s for %s %s", resp.Status, method, path)
+		}
+		return apiErr
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+type meilisearchTask struct {
+	TaskUID int64             `json:"taskUid"`
+	Status  string            `json:"status"`
+	Error   *meilisearchError `json:"error"`
+}
+
+// requestTask sends a request creating a task and waits for it to be processed
+func (m *MeilisearchIndexer) requestTask(method, path string, body interface{}) error {
+	task := &meilisearchTask{}
+	if err := m.request(method, path, body, task); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(meilisearchTaskTimeout)
+	for {
+		if err := m.request(http.MethodGet, fmt.Sprintf("/tasks/%d", task.TaskUID), nil, task); err != nil {
+			return err
+		}
+		switch task.Status {
+		case "succeeded":
+			return nil
+		case "failed", "canceled":
+			if task.Error != nil {
+				return task.Error
+			}
+			return fmt.Errorf("meilisearch: task %d %s", task.TaskUID, task.Status)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("meilisearch: task %d not processed after %v", task.TaskUID, meilisearchTaskTimeout)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Init will initialize the indexer
+func (m *MeilisearchIndexer) Init() (bool, error) {
+	exists := true
+	if err := m.request(http.MethodGet, "/indexes/"+url.PathEscape(m.indexName), nil, nil); err != nil {
+		if apiErr, ok := err.(*meilisearchError); !ok || apiErr.Code != "index_not_found" {
+			return false, err
+		}
+		exists = false
+		if err := m.requestTask(http.MethodPost, "/indexes", struct {
+			UID        string `json:"uid"`
+			PrimaryKey string `json:"primaryKey"`
+		}{m.indexName, "id"}); err != nil {
+			return false, err
+		}
+	}
+
+	// the settings are updated on every start so that the index follows the changes of the indexer
+	return exists, m.requestTask(http.MethodPatch, "/indexes/"+url.PathEscape(m.indexName)+"/settings", struct {
+		SearchableAttributes []string `json:"searchableAttributes"`
+		FilterableAttributes []string `json:"filterableAttributes"`
+	}{
+		SearchableAttributes: []string{FieldTitle, FieldContent, FieldComments},
+		FilterableAttributes: []string{"repo_id"},
+	})
+}
+
+type meilisearchDocument struct {
+	ID       int64    `json:"id"`
+	RepoID   int64    `json:"repo_id"`
+	Title    string   `json:"title"`
+	Content  string   `json:"content"`
+	Comments []string `json:"comments"`
+}
+
+// Index will save the index data
+func (m *MeilisearchIndexer) Index(issues []*IndexerData) error {
+	if len(issues) == 0 {
+		return nil
+	}
+	docs := make([]*meilisearchDocument, 0, len(issues))
+	for _, issue := range issues {
+		docs = append(docs, &meilisearchDocument{
+			ID:       issue.ID,
+			RepoID:   issue.RepoID,
+			Title:    issue.Title,
+			Content:  issue.Content,
+			Comments: issue.Comments,
+		})
+	}
+	return m.requestTask(http.MethodPost, "/indexes/"+url.PathEscape(m.indexName)+"/documents", docs)
+}
+
+// Delete deletes indexes by ids
+func (m *MeilisearchIndexer) Delete(ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return m.requestTask(http.MethodPost, "/indexes/"+url.PathEscape(m.indexName)+"/documents/delete-batch", ids)
+}
+
+type meilisearchSearchQuery struct {
+	Q                     string   `json:"q"`
+	Filter                string   `json:"filter,omitempty"`
+	Offset                int      `json:"offset"`
+	Limit                 int      `json:"limit"`
+	AttributesToSearchOn  []string `json:"attributesToSearchOn"`
+	AttributesToRetrieve  []string `json:"attributesToRetrieve"`
+	ShowRankingScore      bool     `json:"showRankingScore"`
+	AttributesToHighlight []string `json:"attributesToHighlight,omitempty"`
+	AttributesToCrop      []string `json:"attributesToCrop,omitempty"`
+	CropLength            int      `json:"cropLength,omitempty"`
+	HighlightPreTag       string   `json:"highlightPreTag,omitempty"`
+	HighlightPostTag      string   `json:"highlightPostTag,omitempty"`
+}
+
+type meilisearchSearchResult struct {
+	Hits []struct {
+		ID           int64   `json:"id"`
+		RankingScore float64 `json:"_rankingScore"`
+		Formatted    struct {
+			Title    string   `json:"title"`
+			Content  string   `json:"content"`
+			Comments []string `json:"comments"`
+		} `json:"_formatted"`
+	} `json:"hits"`
+	EstimatedTotalHits int64 `json:"estimatedTotalHits"`
+}
+
+// meilisearchHighlights returns the escaped highlights of Meilisearch, with the matches marked like the ones of the
+// other indexers
+func meilisearchHighlights(formatted ...string) []string {
+	highlights := make([]string, 0, len(formatted))
+	for _, s := range formatted {
+		s = html.EscapeString(s)
+		s = strings.ReplaceAll(s, meilisearchPreTag, highlightPreTag)
+		s = strings.ReplaceAll(s, meilisearchPostTag, highlightPostTag)
+		highlights = append(highlights, s)
+	}
+	return highlights
+}
+
+// Search searches for issues by given conditions.
+// Returns the matching issue IDs
+func (m *MeilisearchIndexer) Search(keyword string, fields []string, repoIDs []int64, limit, start int) (*SearchResult, error) {
+	if len(fields) == 0 {
+		fields = []string{FieldTitle, FieldContent, FieldComments}
+	}
+	query := &meilisearchSearchQuery{
+		Q:                    keyword,
+		Offset:               start,
+		Limit:                limit,
+		AttributesToSearchOn: fields,
+		AttributesToRetrieve: []string{"id"},
+		ShowRankingScore:     true,
+	}
+	if len(repoIDs) > 0 {
+		ids := make([]string, 0, len(repoIDs))
+		for _, repoID := range repoIDs {
+			ids = append(ids, fmt.Sprint(repoID))
+		}
+		query.Filter = "repo_id IN [" + strings.Join(ids, ", ") + "]"
+	}
+	if setting.Indexer.IssueHighlight {
+		query.AttributesToHighlight = fields
+		query.AttributesToCrop = []string{FieldContent, FieldComments}
+		query.CropLength = setting.Indexer.IssueHighlightFragmentSize / meilisearchWordSize
+		if query.CropLength < 1 {
+			query.CropLength = 1
+		}
+		query.HighlightPreTag = meilisearchPreTag
+		query.HighlightPostTag = meilisearchPostTag
+		// the highlights are only formatted for the attributes which are retrieved
+		query.AttributesToRetrieve = append(query.AttributesToRetrieve, fields...)
+	}
+
+	result := &meilisearchSearchResult{}
+	if err := m.request(http.MethodPost, "/indexes/"+url.PathEscape(m.indexName)+"/search", query, result); err != nil {
+		return nil, err
+	}
+
+	hits := make([]Match, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		match := Match{
+			ID:    hit.ID,
+			Score: hit.RankingScore,
+		}
+		match.addHighlight(FieldTitle, meilisearchHighlights(hit.Formatted.Title)...)
+		match.addHighlight(FieldContent, meilisearchHighlights(hit.Formatted.Content)...)
+		match.addHighlight(FieldComments, meilisearchHighlights(hit.Formatted.Comments...)...)
+		hits = append(hits, match)
+	}
+	return &SearchResult{
+		Total: result.EstimatedTotalHits,
+		Hits:  hits,
+	}, nil
+}
+
+// Close implements indexer
+func (m *MeilisearchIndexer) Close() {}
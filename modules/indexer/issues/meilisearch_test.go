@@ -0,0 +1,51 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeilisearchSearch(t *testing.T) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	var query map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/indexes/gitea_issues/search", r.URL.Path)
+		assert.Equal(t, "Bearer apikey", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&query))
+		_, _ = w.Write([]byte(`{"hits": [{"id": 2, "_rankingScore": 0.5, "_formatted": {
+			"id": "2",
+			"title": "CJK support",
+			"content": "… Korean and Japanese <b>should</b> …",
+			"comments": ["LGTM", "Japanese too"]
+		}}], "estimatedTotalHits": 1}`))
+	}))
+	defer server.Close()
+
+	indexer, err := NewMeilisearchIndexer("http://:apikey@"+server.Listener.Addr().String(), "gitea_issues")
+	assert.NoError(t, err)
+	res, err := indexer.Search("japanese", nil, []int64{1, 2}, 10, 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "japanese", query["q"])
+	assert.Equal(t, "repo_id IN [1, 2]", query["filter"])
+	assert.EqualValues(t, []interface{}{FieldTitle, FieldContent, FieldComments}, query["attributesToHighlight"])
+	assert.EqualValues(t, 1, res.Total)
+	if assert.Len(t, res.Hits, 1) {
+		assert.EqualValues(t, 2, res.Hits[0].ID)
+		assert.Equal(t, map[string][]string{
+			FieldContent:  {"… Korean and <mark>Japanese</mark> &lt;b&gt;should&lt;/b&gt; …"},
+			FieldComments: {"<mark>Japanese</mark> too"},
+		}, res.Hits[0].Highlights)
+	}
+
+	_, err = NewMeilisearchIndexer("localhost:7700", "gitea_issues")
+	assert.Error(t, err)
+}
@@ -0,0 +1,298 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// QueryMe is the value of the user qualifiers of a query which stands for the user doing the search
+const QueryMe = "@me"
+
+// queryDateLayout is the layout of the dates of the date qualifiers of a query
+const queryDateLayout = "2006-01-02"
+
+// Query is an issue search query, made of a keyword and of qualifiers like
+// `label:bug -label:wontfix assignee:@me updated:>2020-01-01 in:title`
+type Query struct {
+	Keyword        string
+	Labels         []string
+	ExcludedLabels []string
+	// Assignee, Author and Mentions are user names or QueryMe
+	Assignee          string
+	Author            string
+	Mentions          string
+	Milestone         string
+	IsClosed          util.OptionalBool
+	IsPull            util.OptionalBool
+	UpdatedAfterUnix  int64
+	UpdatedBeforeUnix int64
+	CreatedAfterUnix  int64
+	CreatedBeforeUnix int64
+	// In are the fields the keyword is searched in, all of them if it is empty
+	In []string
+}
+
+// ErrInvalidQuery represents an invalid qualifier of an issue search query
+type ErrInvalidQuery struct {
+	Qualifier string
+	Value     string
+}
+
+// IsErrInvalidQuery checks if an error is a ErrInvalidQuery.
+func IsErrInvalidQuery(err error) bool {
+	_, ok := err.(ErrInvalidQuery)
+	return ok
+}
+
+func (err ErrInvalidQuery) Error() string {
+	return fmt.Sprintf("invalid issue search qualifier [%s: %s]", err.Qualifier, err.Value)
+}
+
+// splitQuery splits a query into its terms, which are separated by spaces unless they are quoted
+func splitQuery(q string) []string {
+	var (
+		terms    []string
+		term     strings.Builder
+		inQuotes bool
+	)
+	for _, r := range q {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case unicode.IsSpace(r) && !inQuotes:
+			if term.Len() > 0 {
+				terms = append(terms, term.String())
+				term.Reset()
+			}
+		default:
+			term.WriteRune(r)
+		}
+	}
+	if term.Len() > 0 {
+		terms = append(terms, term.String())
+	}
+	return terms
+}
+
+// parseQueryDate returns the start of a day of a query and the start of the next day
+func parseQueryDate(qualifier, value string) (int64, int64, error) {
+	day, err := time.ParseInLocation(queryDateLayout, value, setting.DefaultUILocation)
+	if err != nil {
+		return 0, 0, ErrInvalidQuery{Qualifier: qualifier, Value: value}
+	}
+	return day.Unix(), day.AddDate(0, 0, 1).Unix(), nil
+}
+
+// parseQueryDateRange parses the value of a date qualifier, which is a day, a day prefixed by >, >=, < or <=, or two
+// days separated by .., into the inclusive bounds it matches, a bound being 0 if there is none
+func parseQueryDateRange(qualifier, value string) (after, before int64, err error) {
+	switch {
+	case strings.HasPrefix(value, ">="):
+		after, _, err = parseQueryDate(qualifier, value[2:])
+	case strings.HasPrefix(value, ">"):
+		_, after, err = parseQueryDate(qualifier, value[1:])
+	case strings.HasPrefix(value, "<="):
+		_, before, err = parseQueryDate(qualifier, value[2:])
+		before--
+	case strings.HasPrefix(value, "<"):
+		before, _, err = parseQueryDate(qualifier, value[1:])
+		before--
+	case strings.Contains(value, ".."):
+		days := strings.SplitN(value, "..", 2)
+		if after, _, err = parseQueryDate(qualifier, days[0]); err == nil {
+			_, before, err = parseQueryDate(qualifier, days[1])
+			before--
+		}
+	default:
+		after, before, err = parseQueryDate(qualifier, value)
+		before--
+	}
+	return after, before, err
+}
+
+// ParseQuery parses an issue search query. The terms which are not qualifiers make up the keyword, as do the ones
+// with an unknown qualifier.
+func ParseQuery(q string) (*Query, error) {
+	query := &Query{}
+	var keywords []string
+	for _, term := range splitQuery(q) {
+		qualifier, value := "", ""
+		if i := strings.IndexByte(term, ':'); i > 0 && i < len(term)-1 {
+			qualifier, value = strings.ToLower(term[:i]), term[i+1:]
+		}
+
+		var err error
+		switch qualifier {
+		case "label":
+			query.Labels = append(query.Labels, value)
+		case "-label":
+			query.ExcludedLabels = append(query.ExcludedLabels, value)
+		case "assignee":
+			query.Assignee = value
+		case "author":
+			query.Author = value
+		case "mentions":
+			query.Mentions = value
+		case "milestone":
+			query.Milestone = value
+		case "is":
+			switch strings.ToLower(value) {
+			case "open":
+				query.IsClosed = util.OptionalBoolFalse
+			case "closed":
+				query.IsClosed = util.OptionalBoolTrue
+			case "issue":
+				query.IsPull = util.OptionalBoolFalse
+			case "pr", "pull":
+				query.IsPull = util.OptionalBoolTrue
+			default:
+				return nil, ErrInvalidQuery{Qualifier: qualifier, Value: value}
+			}
+		case "updated":
+			query.UpdatedAfterUnix, query.UpdatedBeforeUnix, err = parseQueryDateRange(qualifier, value)
+		case "created":
+			query.CreatedAfterUnix, query.CreatedBeforeUnix, err = parseQueryDateRange(qualifier, value)
+		case "in":
+			for _, field := range strings.Split(strings.ToLower(value), ",") {
+				switch field {
+				case "title":
+					query.In = append(query.In, FieldTitle)
+				case "body":
+					query.In = append(query.In, FieldContent)
+				case "comments":
+					query.In = append(query.In, FieldComments)
+				default:
+					return nil, ErrInvalidQuery{Qualifier: qualifier, Value: value}
+				}
+			}
+		default:
+			keywords = append(keywords, term)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	query.Keyword = strings.Join(keywords, " ")
+	return query, nil
+}
+
+// QueryFilter represents the conditions of the qualifiers of a query on the issues of a repository
+type QueryFilter struct {
+	// LabelIDs are the labels the issues have, or do not have if they are negative
+	LabelIDs    []int64
+	AssigneeID  int64
+	PosterID    int64
+	MentionedID int64
+	MilestoneID int64
+	// MatchesNothing is true if a qualifier cannot match any issue, e.g. because its label does not exist
+	MatchesNothing bool
+}
+
+// queryUserID returns the ID of the user of a user qualifier, or 0 if there is no such user
+func queryUserID(name string, doer *models.User) (int64, error) {
+	if name == QueryMe {
+		if doer == nil {
+			return 0, nil
+		}
+		return doer.ID, nil
+	}
+	u, err := models.GetUserByName(name)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+// queryLabelID returns the ID of a label of a repository or of its organization, or 0 if there is no such label
+func queryLabelID(repo *models.Repository, name string) (int64, error) {
+	label, err := models.GetLabelInRepoByName(repo.ID, name)
+	if err == nil {
+		return label.ID, nil
+	} else if !models.IsErrRepoLabelNotExist(err) {
+		return 0, err
+	}
+
+	if err := repo.GetOwner(); err != nil {
+		return 0, err
+	}
+	if !repo.Owner.IsOrganization() {
+		return 0, nil
+	}
+	label, err = models.GetLabelInOrgByName(repo.OwnerID, name)
+	if err != nil {
+		if models.IsErrOrgLabelNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return label.ID, nil
+}
+
+// Filter resolves the label, user and milestone qualifiers of a query on the issues of a repository. QueryMe is
+// resolved to doer, which may be nil.
+func (q *Query) Filter(repo *models.Repository, doer *models.User) (*QueryFilter, error) {
+	filter := &QueryFilter{}
+	for _, name := range q.Labels {
+		labelID, err := queryLabelID(repo, name)
+		if err != nil {
+			return nil, err
+		} else if labelID == 0 {
+			filter.MatchesNothing = true
+		}
+		filter.LabelIDs = append(filter.LabelIDs, labelID)
+	}
+	for _, name := range q.ExcludedLabels {
+		labelID, err := queryLabelID(repo, name)
+		if err != nil {
+			return nil, err
+		} else if labelID != 0 {
+			filter.LabelIDs = append(filter.LabelIDs, -labelID)
+		}
+	}
+
+	for _, user := range []struct {
+		name string
+		id   *int64
+	}{
+		{q.Assignee, &filter.AssigneeID},
+		{q.Author, &filter.PosterID},
+		{q.Mentions, &filter.MentionedID},
+	} {
+		if len(user.name) == 0 {
+			continue
+		}
+		userID, err := queryUserID(user.name, doer)
+		if err != nil {
+			return nil, err
+		} else if userID == 0 {
+			filter.MatchesNothing = true
+		}
+		*user.id = userID
+	}
+
+	if len(q.Milestone) > 0 {
+		milestone, err := models.GetMilestoneByRepoIDANDName(repo.ID, q.Milestone)
+		if err != nil {
+			if !models.IsErrMilestoneNotExist(err) {
+				return nil, err
+			}
+			filter.MatchesNothing = true
+		} else {
+			filter.MilestoneID = milestone.ID
+		}
+	}
+	return filter, nil
+}
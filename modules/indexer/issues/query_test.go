@@ -0,0 +1,93 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	setting.DefaultUILocation = time.UTC
+	day := func(s string) int64 {
+		d, _ := time.Parse(queryDateLayout, s)
+		return d.Unix()
+	}
+
+	query, err := ParseQuery(`fix label:bug -label:wontfix label:"good first issue" assignee:@me author:user2 is:closed is:pr updated:>2020-01-01 in:title,body crash`)
+	assert.NoError(t, err)
+	assert.EqualValues(t, &Query{
+		Keyword:          "fix crash",
+		Labels:           []string{"bug", "good first issue"},
+		ExcludedLabels:   []string{"wontfix"},
+		Assignee:         QueryMe,
+		Author:           "user2",
+		IsClosed:         util.OptionalBoolTrue,
+		IsPull:           util.OptionalBoolTrue,
+		UpdatedAfterUnix: day("2020-01-02"),
+		In:               []string{FieldTitle, FieldContent},
+	}, query)
+
+	for value, bounds := range map[string][2]int64{
+		">=2020-01-01":           {day("2020-01-01"), 0},
+		"<2020-01-01":            {0, day("2020-01-01") - 1},
+		"<=2020-01-01":           {0, day("2020-01-02") - 1},
+		"2020-01-01":             {day("2020-01-01"), day("2020-01-02") - 1},
+		"2020-01-01..2020-01-31": {day("2020-01-01"), day("2020-02-01") - 1},
+	} {
+		query, err = ParseQuery("created:" + value)
+		assert.NoError(t, err)
+		assert.EqualValues(t, bounds, [2]int64{query.CreatedAfterUnix, query.CreatedBeforeUnix}, value)
+	}
+
+	query, err = ParseQuery("foo:bar http://example.com is:")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "foo:bar http://example.com is:", query.Keyword)
+
+	for _, q := range []string{"is:draft", "in:labels", "updated:>yesterday", "created:2020-01-01.."} {
+		_, err = ParseQuery(q)
+		assert.True(t, IsErrInvalidQuery(err), q)
+	}
+}
+
+func TestQueryFilter(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+	query, err := ParseQuery("label:label1 -label:label2 -label:unknown assignee:@me author:user1 milestone:milestone1")
+	assert.NoError(t, err)
+	filter, err := query.Filter(repo, doer)
+	assert.NoError(t, err)
+	assert.EqualValues(t, &QueryFilter{
+		LabelIDs:    []int64{1, -2},
+		AssigneeID:  2,
+		PosterID:    1,
+		MilestoneID: 1,
+	}, filter)
+
+	for _, q := range []string{"label:unknown", "author:unknown", "milestone:unknown", "mentions:@me"} {
+		query, err = ParseQuery(q)
+		assert.NoError(t, err)
+		filter, err = query.Filter(repo, nil)
+		assert.NoError(t, err)
+		assert.True(t, filter.MatchesNothing, q)
+	}
+
+	// the labels of the organization of a repository
+	repo = models.AssertExistsAndLoadBean(t, &models.Repository{ID: 3}).(*models.Repository)
+	query, err = ParseQuery("label:orglabel3")
+	assert.NoError(t, err)
+	filter, err = query.Filter(repo, doer)
+	assert.NoError(t, err)
+	assert.False(t, filter.MatchesNothing)
+	assert.EqualValues(t, []int64{3}, filter.LabelIDs)
+}
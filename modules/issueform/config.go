@@ -0,0 +1,76 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issueform
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"gopkg.in/yaml.v2"
+)
+
+// IsConfigFile returns true if a file of an issue template directory is the configuration of the template chooser
+func IsConfigFile(name string) bool {
+	base := strings.ToLower(path.Base(name))
+	return base == "config.yaml" || base == "config.yml"
+}
+
+// DefaultConfig returns the configuration of the template chooser of the repositories which have none
+func DefaultConfig() *api.IssueConfig {
+	return &api.IssueConfig{
+		BlankIssuesEnabled: true,
+		ContactLinks:       []api.IssueConfigContactLink{},
+	}
+}
+
+// ErrInvalidConfig represents a "InvalidConfig" kind of error.
+type ErrInvalidConfig struct {
+	Reason string
+}
+
+// IsErrInvalidConfig checks if an error is a ErrInvalidConfig.
+func IsErrInvalidConfig(err error) bool {
+	_, ok := err.(ErrInvalidConfig)
+	return ok
+}
+
+func (err ErrInvalidConfig) Error() string {
+	return fmt.Sprintf("invalid issue config: %s", err.Reason)
+}
+
+// ParseConfig parses and validates the YAML configuration of the template chooser, the blank issues being enabled
+// unless disabled explicitly
+func ParseConfig(content []byte) (*api.IssueConfig, error) {
+	var config struct {
+		BlankIssuesEnabled *bool                        `yaml:"blank_issues_enabled"`
+		ContactLinks       []api.IssueConfigContactLink `yaml:"contact_links"`
+	}
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, ErrInvalidConfig{err.Error()}
+	}
+
+	c := DefaultConfig()
+	if config.BlankIssuesEnabled != nil {
+		c.BlankIssuesEnabled = *config.BlankIssuesEnabled
+	}
+	for i, link := range config.ContactLinks {
+		link.Name = strings.TrimSpace(link.Name)
+		link.URL = strings.TrimSpace(link.URL)
+		link.About = strings.TrimSpace(link.About)
+		if len(link.Name) == 0 {
+			return nil, ErrInvalidConfig{fmt.Sprintf("contact link %d has no name", i+1)}
+		}
+		u, err := url.Parse(link.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "mailto") || (u.Host == "" && u.Scheme != "mailto") {
+			return nil, ErrInvalidConfig{fmt.Sprintf("contact link %d has an invalid url %q", i+1, link.URL)}
+		}
+		c.ContactLinks = append(c.ContactLinks, link)
+	}
+	return c, nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issueform
+
+import (
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig(t *testing.T) {
+	assert.True(t, IsConfigFile(".gitea/ISSUE_TEMPLATE/config.yml"))
+	assert.True(t, IsConfigFile("Config.YAML"))
+	assert.False(t, IsConfigFile("bug_report.yml"))
+
+	c, err := ParseConfig([]byte(""))
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultConfig(), c)
+
+	c, err = ParseConfig([]byte(`
+blank_issues_enabled: false
+contact_links:
+  - name: Forum
+    url: https://forum.example.com
+    about: " Ask questions there "
+  - name: Security
+    url: mailto:security@example.com
+`))
+	assert.NoError(t, err)
+	assert.False(t, c.BlankIssuesEnabled)
+	assert.Equal(t, []api.IssueConfigContactLink{
+		{Name: "Forum", URL: "https://forum.example.com", About: "Ask questions there"},
+		{Name: "Security", URL: "mailto:security@example.com"},
+	}, c.ContactLinks)
+
+	for _, content := range []string{
+		"blank_issues_enabled: maybe",
+		"contact_links:\n  - url: https://example.com",
+		"contact_links:\n  - name: Forum\n    url: javascript:alert(1)",
+		"contact_links:\n  - name: Forum\n    url: /relative",
+	} {
+		_, err = ParseConfig([]byte(content))
+		assert.True(t, IsErrInvalidConfig(err), content)
+	}
+}
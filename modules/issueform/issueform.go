@@ -0,0 +1,268 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issueform
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"gopkg.in/yaml.v2"
+)
+
+var fieldIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// IsFormFile returns true if the file of an issue template is an issue form
+func IsFormFile(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// ErrInvalidForm represents a "InvalidForm" kind of error.
+type ErrInvalidForm struct {
+	Reason string
+}
+
+// IsErrInvalidForm checks if an error is a ErrInvalidForm.
+func IsErrInvalidForm(err error) bool {
+	_, ok := err.(ErrInvalidForm)
+	return ok
+}
+
+func (err ErrInvalidForm) Error() string {
+	return fmt.Sprintf("invalid issue form: %s", err.Reason)
+}
+
+func invalid(format string, args ...interface{}) error {
+	return ErrInvalidForm{fmt.Sprintf(format, args...)}
+}
+
+// Parse parses and validates the YAML definition of an issue form
+func Parse(content []byte) (*api.IssueTemplate, error) {
+	// "description" is accepted for "about", as in the issue forms of GitHub
+	var form struct {
+		api.IssueTemplate `yaml:",inline"`
+		Description       string `yaml:"description"`
+	}
+	if err := yaml.Unmarshal(content, &form); err != nil {
+		return nil, invalid("%v", err)
+	}
+	it := &form.IssueTemplate
+	if len(strings.TrimSpace(it.About)) == 0 {
+		it.About = form.Description
+	}
+	if err := Validate(it); err != nil {
+		return nil, err
+	}
+	return it, nil
+}
+
+// Validate checks that an issue form is well defined
+func Validate(it *api.IssueTemplate) error {
+	if !it.Valid() {
+		return invalid("the name and the description are required")
+	}
+	if len(it.Fields) == 0 {
+		return invalid("the body has no field")
+	}
+
+	ids := make(map[string]bool, len(it.Fields))
+	for i, field := range it.Fields {
+		if field == nil {
+			return invalid("field %d is empty", i+1)
+		}
+		attrs := field.Attributes
+		switch field.Type {
+		case api.IssueFormFieldTypeMarkdown:
+			if len(strings.TrimSpace(attrs.Value)) == 0 {
+				return invalid("markdown field %d has no value", i+1)
+			}
+			if field.Validations.Required {
+				return invalid("markdown field %d can not be required", i+1)
+			}
+			continue
+		case api.IssueFormFieldTypeTextarea, api.IssueFormFieldTypeInput:
+			if len(attrs.Options) > 0 {
+				return invalid("field %d of type %s can not have options", i+1, field.Type)
+			}
+		case api.IssueFormFieldTypeDropdown, api.IssueFormFieldTypeCheckboxes:
+			if len(attrs.Options) == 0 {
+				return invalid("field %d of type %s has no option", i+1, field.Type)
+			}
+			for j, option := range attrs.Options {
+				if option == nil || len(strings.TrimSpace(option.Label)) == 0 {
+					return invalid("option %d of field %d has no label", j+1, i+1)
+				}
+				if option.Required && field.Type != api.IssueFormFieldTypeCheckboxes {
+					return invalid("option %d of field %d can not be required", j+1, i+1)
+				}
+			}
+		default:
+			return invalid("field %d has an unknown type %q", i+1, field.Type)
+		}
+
+		if !fieldIDPattern.MatchString(field.ID) {
+			return invalid("field %d has an invalid id %q", i+1, field.ID)
+		}
+		if ids[field.ID] {
+			return invalid("the id %q of field %d is not unique", field.ID, i+1)
+		}
+		ids[field.ID] = true
+		if len(strings.TrimSpace(attrs.Label)) == 0 {
+			return invalid("field %d has no label", i+1)
+		}
+		if attrs.Multiple && field.Type != api.IssueFormFieldTypeDropdown {
+			return invalid("field %d of type %s can not be multiple", i+1, field.Type)
+		}
+	}
+	return nil
+}
+
+// Values are the submitted values of the fields of an issue form. The value of a field is named "form-field-{id}",
+// the selected options of a dropdown having the indexes of the options as values, and the checkbox of an option being
+// named "form-field-{id}-{index}".
+type Values url.Values
+
+func (v Values) get(name string) string {
+	if values := v[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Value returns the value of an input or of a textarea
+func (v Values) Value(field *api.IssueFormField) string {
+	return v.get("form-field-" + field.ID)
+}
+
+// IsSelected returns true if an option of a dropdown is selected
+func (v Values) IsSelected(field *api.IssueFormField, index int) bool {
+	for _, value := range v["form-field-"+field.ID] {
+		if value == strconv.Itoa(index) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsChecked returns true if the checkbox of an option is checked
+func (v Values) IsChecked(field *api.IssueFormField, index int) bool {
+	value := v.get(fmt.Sprintf("form-field-%s-%d", field.ID, index))
+	return value == "on" || value == "true"
+}
+
+// selected returns the labels of the selected options of a dropdown
+func (v Values) selected(field *api.IssueFormField) []string {
+	labels := make([]string, 0, len(field.Attributes.Options))
+	for i, option := range field.Attributes.Options {
+		if v.IsSelected(field, i) {
+			labels = append(labels, option.Label)
+			if !field.Attributes.Multiple {
+				break
+			}
+		}
+	}
+	return labels
+}
+
+// ErrFieldRequired represents a "FieldRequired" kind of error.
+type ErrFieldRequired struct {
+	Label string
+}
+
+// IsErrFieldRequired checks if an error is a ErrFieldRequired.
+func IsErrFieldRequired(err error) bool {
+	_, ok := err.(ErrFieldRequired)
+	return ok
+}
+
+func (err ErrFieldRequired) Error() string {
+	return fmt.Sprintf("issue form field is required [label: %s]", err.Label)
+}
+
+// ValidateValues checks that the required fields of an issue form have values, and that the required checkboxes are
+// checked
+func ValidateValues(it *api.IssueTemplate, values Values) error {
+	for _, field := range it.Fields {
+		switch field.Type {
+		case api.IssueFormFieldTypeTextarea, api.IssueFormFieldTypeInput:
+			if field.Validations.Required && len(strings.TrimSpace(values.Value(field))) == 0 {
+				return ErrFieldRequired{field.Attributes.Label}
+			}
+		case api.IssueFormFieldTypeDropdown:
+			if field.Validations.Required && len(values.selected(field)) == 0 {
+				return ErrFieldRequired{field.Attributes.Label}
+			}
+		case api.IssueFormFieldTypeCheckboxes:
+			for i, option := range field.Attributes.Options {
+				if option.Required && !values.IsChecked(field, i) {
+					return ErrFieldRequired{option.Label}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// noResponse is the content of the fields without value
+const noResponse = "_No response_"
+
+// RenderToMarkdown renders the values of the fields of an issue form to the markdown content of the issue, a section
+// per field. The markdown fields only help filling the form and are not rendered.
+func RenderToMarkdown(it *api.IssueTemplate, values Values) string {
+	var sb strings.Builder
+	for _, field := range it.Fields {
+		if field.Type == api.IssueFormFieldTypeMarkdown {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString("### ")
+		sb.WriteString(strings.TrimSpace(field.Attributes.Label))
+		sb.WriteString("\n\n")
+
+		switch field.Type {
+		case api.IssueFormFieldTypeTextarea, api.IssueFormFieldTypeInput:
+			value := strings.TrimSpace(values.Value(field))
+			switch {
+			case len(value) == 0:
+				sb.WriteString(noResponse)
+			case field.Type == api.IssueFormFieldTypeTextarea && len(field.Attributes.Render) > 0:
+				fence := "```"
+				for strings.Contains(value, fence) {
+					fence += "`"
+				}
+				sb.WriteString(fence + field.Attributes.Render + "\n" + value + "\n" + fence)
+			default:
+				sb.WriteString(value)
+			}
+		case api.IssueFormFieldTypeDropdown:
+			if selected := values.selected(field); len(selected) > 0 {
+				sb.WriteString(strings.Join(selected, ", "))
+			} else {
+				sb.WriteString(noResponse)
+			}
+		case api.IssueFormFieldTypeCheckboxes:
+			for i, option := range field.Attributes.Options {
+				if i > 0 {
+					sb.WriteString("\n")
+				}
+				if values.IsChecked(field, i) {
+					sb.WriteString("- [x] ")
+				} else {
+					sb.WriteString("- [ ] ")
+				}
+				sb.WriteString(strings.TrimSpace(option.Label))
+			}
+		}
+	}
+	return sb.String()
+}
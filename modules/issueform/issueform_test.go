@@ -0,0 +1,116 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issueform
+
+import (
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const bugReport = `
+name: Bug report
+description: Report a bug
+title: "[Bug]: "
+labels: [bug]
+body:
+  - type: markdown
+    attributes:
+      value: Thanks for taking the time to fill out this bug report!
+  - type: input
+    id: version
+    attributes:
+      label: Version
+      placeholder: "1.14.0"
+    validations:
+      required: true
+  - type: textarea
+    id: logs
+    attributes:
+      label: Logs
+      render: shell
+  - type: dropdown
+    id: databases
+    attributes:
+      label: Databases
+      multiple: true
+      options:
+        - SQLite
+        - PostgreSQL
+        - MySQL
+  - type: checkboxes
+    id: terms
+    attributes:
+      label: Code of Conduct
+      options:
+        - label: I agree to follow the Code of Conduct
+          required: true
+        - label: I searched the existing issues
+`
+
+func TestParse(t *testing.T) {
+	it, err := Parse([]byte(bugReport))
+	assert.NoError(t, err)
+	assert.Equal(t, "Bug report", it.Name)
+	assert.Equal(t, "Report a bug", it.About)
+	assert.Equal(t, []string{"bug"}, it.Labels)
+	if assert.Len(t, it.Fields, 5) {
+		assert.Equal(t, api.IssueFormFieldTypeMarkdown, it.Fields[0].Type)
+		assert.True(t, it.Fields[1].Validations.Required)
+		assert.Equal(t, "PostgreSQL", it.Fields[3].Attributes.Options[1].Label)
+		assert.True(t, it.Fields[4].Attributes.Options[0].Required)
+		assert.False(t, it.Fields[4].Attributes.Options[1].Required)
+	}
+
+	for name, content := range map[string]string{
+		"no body":        "name: a\nabout: b\n",
+		"unknown type":   "name: a\nabout: b\nbody:\n  - type: radio\n    id: a\n    attributes:\n      label: a\n",
+		"no id":          "name: a\nabout: b\nbody:\n  - type: input\n    attributes:\n      label: a\n",
+		"duplicate id":   "name: a\nabout: b\nbody:\n  - type: input\n    id: a\n    attributes:\n      label: a\n  - type: textarea\n    id: a\n    attributes:\n      label: b\n",
+		"no label":       "name: a\nabout: b\nbody:\n  - type: input\n    id: a\n",
+		"no options":     "name: a\nabout: b\nbody:\n  - type: dropdown\n    id: a\n    attributes:\n      label: a\n",
+		"empty markdown": "name: a\nabout: b\nbody:\n  - type: markdown\n",
+		"multiple input": "name: a\nabout: b\nbody:\n  - type: input\n    id: a\n    attributes:\n      label: a\n      multiple: true\n",
+		"invalid yaml":   "name: [a\n",
+	} {
+		_, err := Parse([]byte(content))
+		assert.True(t, IsErrInvalidForm(err), name)
+	}
+}
+
+func TestValidateValues(t *testing.T) {
+	it, err := Parse([]byte(bugReport))
+	assert.NoError(t, err)
+
+	err = ValidateValues(it, Values{"form-field-version": {" "}, "form-field-terms-0": {"on"}})
+	assert.Equal(t, ErrFieldRequired{"Version"}, err)
+	err = ValidateValues(it, Values{"form-field-version": {"1.14.0"}})
+	assert.Equal(t, ErrFieldRequired{"I agree to follow the Code of Conduct"}, err)
+	assert.NoError(t, ValidateValues(it, Values{"form-field-version": {"1.14.0"}, "form-field-terms-0": {"on"}}))
+}
+
+func TestRenderToMarkdown(t *testing.T) {
+	it, err := Parse([]byte(bugReport))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "### Version\n\n1.14.0\n\n"+
+		"### Logs\n\n````shell\nerror ``` here\n````\n\n"+
+		"### Databases\n\nSQLite, MySQL\n\n"+
+		"### Code of Conduct\n\n- [x] I agree to follow the Code of Conduct\n- [ ] I searched the existing issues",
+		RenderToMarkdown(it, Values{
+			"form-field-version":   {"1.14.0"},
+			"form-field-logs":      {"error ``` here"},
+			"form-field-databases": {"0", "2", "5"},
+			"form-field-terms-0":   {"on"},
+		}))
+
+	assert.Equal(t, "### Version\n\n_No response_\n\n"+
+		"### Logs\n\n_No response_\n\n"+
+		"### Databases\n\n_No response_\n\n"+
+		"### Code of Conduct\n\n- [ ] I agree to follow the Code of Conduct\n- [ ] I searched the existing issues",
+		RenderToMarkdown(it, Values{}))
+}
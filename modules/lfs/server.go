@@ -18,8 +18,10 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/maintenance"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/virusscan"
 
 	"github.com/dgrijalva/jwt-go"
 	jsoniter "github.com/json-iterator/go"
@@ -416,7 +418,33 @@ func PutHandler(ctx *context.Context) {
 
 	contentStore := &ContentStore{ObjectStorage: storage.LFS}
 	defer ctx.Req.Body.Close()
-	if err := contentStore.Put(meta, ctx.Req.Body); err != nil {
+
+	upload := &virusscan.Upload{
+		Kind:    models.VirusDetectionKindLFS,
+		Name:    meta.Oid,
+		RepoID:  repository.ID,
+		Storage: storage.LFS,
+		Path:    meta.RelativePath(),
+		Remove: func() error {
+			if err := contentStore.Delete(meta.RelativePath()); err != nil {
+				return err
+			}
+			_, err := repository.RemoveLFSMetaObjectByOid(meta.Oid)
+			return err
+		},
+	}
+	if ctx.User != nil {
+		upload.UploaderID = ctx.User.ID
+	}
+	if err := virusscan.Store(ctx.Req.Context(), upload, ctx.Req.Body, func(r io.Reader) error {
+		return contentStore.Put(meta, r)
+	}); err != nil {
+		if virusscan.IsErrVirusFound(err) {
+			ctx.Resp.WriteHeader(422)
+			fmt.Fprintf(ctx.Resp, `{"message":"%s"}`, "virus found")
+			logRequest(ctx.Req, 422)
+			return
+		}
 		// Put will log the error itself
 		ctx.Resp.WriteHeader(500)
 		if err == errSizeMismatch || err == errHashMismatch {
@@ -596,7 +624,14 @@ func logRequest(r *http.Request, status int) {
 
 // authenticate uses the authorization string to determine whether
 // or not to proceed. This server assumes an HTTP Basic auth format.
-func authenticate(ctx *context.Context, repository *models.Repository, authorization string, requireWrite bool) bool {
+func authenticate(ctx *context.Context, repository *models.Repository, authorization string, requireWrite bool) (ok bool) {
+	if requireWrite && maintenance.IsEnabled() {
+		// only site administrators may upload objects while the instance is in maintenance mode
+		defer func() {
+			ok = ok && ctx.User != nil && ctx.User.IsAdmin
+		}()
+	}
+
 	accessMode := models.AccessModeRead
 	if requireWrite {
 		accessMode = models.AccessModeWrite
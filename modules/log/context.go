@@ -0,0 +1,62 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package log
+
+import "context"
+
+type requestIDContextKey struct{}
+
+// WithRequestID returns a copy of the context which carries the given request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID carried by the context or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// requestLogger is a LevelLogger which adds a request ID to all events
+type requestLogger struct {
+	*MultiChannelledLogger
+	requestID string
+}
+
+// Log msg at the provided level with the provided caller defined by skip (0 being the function that calls this function)
+func (l *requestLogger) Log(skip int, level Level, format string, v ...interface{}) error {
+	return l.MultiChannelledLogger.log(skip+1, level, l.requestID, format, v...)
+}
+
+// WithRequestID returns a Logger which logs to this logger and adds the request ID to all events
+func (l *MultiChannelledLogger) WithRequestID(requestID string) Logger {
+	if requestID == "" {
+		return l
+	}
+	return &LevelLoggerLogger{
+		LevelLogger: &requestLogger{
+			MultiChannelledLogger: l,
+			requestID:             requestID,
+		},
+	}
+}
+
+// LogWithContext logs a message to the default logger with the request ID carried by the context.
+// A skip of 0 refers to the caller of this function.
+func LogWithContext(ctx context.Context, skip int, level Level, format string, v ...interface{}) {
+	LogWithRequestID(RequestIDFromContext(ctx), skip+1, level, format, v...)
+}
+
+// LogWithRequestID logs a message to the default logger with the given request ID.
+// A skip of 0 refers to the caller of this function.
+func LogWithRequestID(requestID string, skip int, level Level, format string, v ...interface{}) {
+	l, ok := NamedLoggers.Load(DEFAULT)
+	if ok {
+		_ = l.log(skip+1, level, requestID, format, v...)
+	}
+}
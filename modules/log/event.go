@@ -19,6 +19,9 @@ type Event struct {
 	line       int
 	time       time.Time
 	stacktrace string
+	requestID  string
+	// forced events were let through by a module level and are logged regardless of the level of the logger
+	forced bool
 }
 
 // EventLogger represents the behaviours of a logger
@@ -0,0 +1,96 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"strings"
+	"sync"
+)
+
+// moduleLevels holds the levels which override the level of the loggers for
+// the events logged from within a module. A module is a path of the source
+// tree relative to its root, e.g. "modules/git" or "routers/api".
+var moduleLevels = struct {
+	sync.RWMutex
+	levels map[string]Level
+	// min is the lowest of the levels, NONE if there are no levels
+	min Level
+}{
+	levels: map[string]Level{},
+	min:    NONE,
+}
+
+func normalizeModule(module string) string {
+	return strings.Trim(strings.TrimSpace(module), "/")
+}
+
+// SetModuleLevel overrides the level of all loggers for the events logged from within the given module.
+// Events at or above the level are logged even if the level of the logger is higher, events below it are dropped.
+func SetModuleLevel(module string, level Level) {
+	module = normalizeModule(module)
+	if module == "" {
+		return
+	}
+	moduleLevels.Lock()
+	defer moduleLevels.Unlock()
+	moduleLevels.levels[module] = level
+	updateMinModuleLevel()
+}
+
+// RemoveModuleLevel removes the level override of the given module
+func RemoveModuleLevel(module string) {
+	moduleLevels.Lock()
+	defer moduleLevels.Unlock()
+	delete(moduleLevels.levels, normalizeModule(module))
+	updateMinModuleLevel()
+}
+
+// GetModuleLevels returns a copy of the current module levels
+func GetModuleLevels() map[string]Level {
+	moduleLevels.RLock()
+	defer moduleLevels.RUnlock()
+	levels := make(map[string]Level, len(moduleLevels.levels))
+	for module, level := range moduleLevels.levels {
+		levels[module] = level
+	}
+	return levels
+}
+
+func updateMinModuleLevel() {
+	moduleLevels.min = NONE
+	for _, level := range moduleLevels.levels {
+		if level < moduleLevels.min {
+			moduleLevels.min = level
+		}
+	}
+}
+
+// minModuleLevel returns the lowest module level so that the caller of an
+// event only has to be looked up if the event could be logged at all
+func minModuleLevel() Level {
+	moduleLevels.RLock()
+	defer moduleLevels.RUnlock()
+	return moduleLevels.min
+}
+
+// moduleLevel returns the level of the most specific module containing the given file
+func moduleLevel(filename string) (Level, bool) {
+	moduleLevels.RLock()
+	defer moduleLevels.RUnlock()
+	if len(moduleLevels.levels) == 0 {
+		return NONE, false
+	}
+	for module := filename; module != ""; {
+		idx := strings.LastIndexByte(module, '/')
+		if idx < 0 {
+			break
+		}
+		module = module[:idx]
+		if level, ok := moduleLevels.levels[module]; ok {
+			return level, true
+		}
+	}
+	return NONE, false
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModuleLevel(t *testing.T) {
+	defer RemoveModuleLevel("modules/log")
+
+	SetModuleLevel("/modules/log/", DEBUG)
+	SetModuleLevel("modules", ERROR)
+	assert.Equal(t, map[string]Level{"modules/log": DEBUG, "modules": ERROR}, GetModuleLevels())
+	assert.Equal(t, DEBUG, minModuleLevel())
+
+	level, ok := moduleLevel("modules/log/writer.go")
+	assert.True(t, ok)
+	assert.Equal(t, DEBUG, level)
+	level, ok = moduleLevel("modules/git/command.go")
+	assert.True(t, ok)
+	assert.Equal(t, ERROR, level)
+	_, ok = moduleLevel("routers/repo/http.go")
+	assert.False(t, ok)
+
+	RemoveModuleLevel("modules")
+	assert.Equal(t, map[string]Level{"modules/log": DEBUG}, GetModuleLevels())
+	RemoveModuleLevel("modules/log")
+	assert.Empty(t, GetModuleLevels())
+	assert.Equal(t, NONE, minModuleLevel())
+}
+
+func TestModuleLevelLogger(t *testing.T) {
+	defer RemoveModuleLevel("modules/log")
+
+	logger := newLogger("MODULES", 0)
+	assert.NoError(t, logger.SetLogger("console", "console", `{"level":"info","flags":-1,"colorize":false}`))
+	written, closed := baseConsoleTest(t, logger)
+
+	// the events of the module are logged below the level of the logger
+	SetModuleLevel("modules/log", TRACE)
+	logger.Trace("trace message")
+	assert.Contains(t, string(<-written), "trace message")
+	assert.False(t, <-closed)
+
+	// and dropped below the level of the module
+	SetModuleLevel("modules/log", ERROR)
+	logger.Warn("warn message")
+	logger.WithRequestID("abc123").Error("error message")
+	line := string(<-written)
+	assert.Contains(t, line, "error message")
+	assert.NotContains(t, line, "warn message")
+	assert.False(t, <-closed)
+}
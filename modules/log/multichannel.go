@@ -56,7 +56,11 @@ func (l *MultiChannelledLogger) DelLogger(name string) (bool, error) {
 
 // Log msg at the provided level with the provided caller defined by skip (0 being the function that calls this function)
 func (l *MultiChannelledLogger) Log(skip int, level Level, format string, v ...interface{}) error {
-	if l.GetLevel() > level {
+	return l.log(skip+1, level, "", format, v...)
+}
+
+func (l *MultiChannelledLogger) log(skip int, level Level, requestID, format string, v ...interface{}) error {
+	if l.GetLevel() > level && minModuleLevel() > level {
 		return nil
 	}
 	caller := "?()"
@@ -68,6 +72,18 @@ func (l *MultiChannelledLogger) Log(skip int, level Level, format string, v ...i
 			caller = fn.Name() + "()"
 		}
 	}
+	filename = strings.TrimPrefix(filename, prefix)
+
+	forced := false
+	if modLevel, ok := moduleLevel(filename); ok {
+		if modLevel > level {
+			return nil
+		}
+		forced = true
+	} else if l.GetLevel() > level {
+		return nil
+	}
+
 	msg := format
 	if len(v) > 0 {
 		msg = ColorSprintf(format, v...)
@@ -76,15 +92,31 @@ func (l *MultiChannelledLogger) Log(skip int, level Level, format string, v ...i
 	if l.GetStacktraceLevel() <= level {
 		stack = Stack(skip + 1)
 	}
-	return l.SendLog(level, caller, strings.TrimPrefix(filename, prefix), line, msg, stack)
+	l.LogEvent(&Event{
+		level:      level,
+		caller:     caller,
+		filename:   filename,
+		line:       line,
+		msg:        msg,
+		time:       time.Now(),
+		stacktrace: stack,
+		requestID:  requestID,
+		forced:     forced,
+	})
+	return nil
 }
 
 // SendLog sends a log event at the provided level with the information given
 func (l *MultiChannelledLogger) SendLog(level Level, caller, filename string, line int, msg string, stack string) error {
+	return l.SendLogWithRequestID(level, "", caller, filename, line, msg, stack)
+}
+
+// SendLogWithRequestID sends a log event at the provided level for the given request with the information given
+func (l *MultiChannelledLogger) SendLogWithRequestID(level Level, requestID, caller, filename string, line int, msg string, stack string) error {
 	if l.GetLevel() > level {
 		return nil
 	}
-	event := &Event{
+	l.LogEvent(&Event{
 		level:      level,
 		caller:     caller,
 		filename:   filename,
@@ -92,7 +124,7 @@ func (l *MultiChannelledLogger) SendLog(level Level, caller, filename string, li
 		msg:        msg,
 		time:       time.Now(),
 		stacktrace: stack,
-	}
-	l.LogEvent(event)
+		requestID:  requestID,
+	})
 	return nil
 }
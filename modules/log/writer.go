@@ -11,6 +11,9 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
 )
 
 type byteArrayWriter []byte
@@ -31,7 +34,9 @@ type WriterLogger struct {
 	Prefix          string `json:"prefix"`
 	Colorize        bool   `json:"colorize"`
 	Expression      string `json:"expression"`
-	regexp          *regexp.Regexp
+	// Format is either "text" or "json"
+	Format string `json:"format"`
+	regexp *regexp.Regexp
 }
 
 // NewWriterLogger creates a new WriterLogger from the provided WriteCloser.
@@ -189,6 +194,12 @@ func (logger *WriterLogger) createMsg(buf *[]byte, event *Event) {
 		*buf = append(*buf, ' ')
 	}
 
+	if logger.Flags&Lrequestid != 0 && event.requestID != "" {
+		*buf = append(*buf, '[')
+		*buf = append(*buf, event.requestID...)
+		*buf = append(*buf, "] "...)
+	}
+
 	var msg = []byte(event.msg)
 	if len(msg) > 0 && msg[len(msg)-1] == '\n' {
 		msg = msg[:len(msg)-1]
@@ -219,9 +230,60 @@ func (logger *WriterLogger) createMsg(buf *[]byte, event *Event) {
 	*buf = append(*buf, '\n')
 }
 
+// jsonEvent is the structure of an event logged in the json format
+type jsonEvent struct {
+	Time       string `json:"time"`
+	Level      string `json:"level"`
+	Prefix     string `json:"prefix,omitempty"`
+	Source     string `json:"source,omitempty"`
+	Func       string `json:"func,omitempty"`
+	RequestID  string `json:"request_id,omitempty"`
+	Msg        string `json:"msg"`
+	Stacktrace string `json:"stacktrace,omitempty"`
+}
+
+func (logger *WriterLogger) createJSONMsg(buf *[]byte, event *Event) error {
+	t := event.time
+	if logger.Flags&LUTC != 0 {
+		t = t.UTC()
+	}
+
+	// colors make no sense in structured logs
+	var msg []byte
+	baw := byteArrayWriter(msg)
+	(&protectedANSIWriter{
+		w:    &baw,
+		mode: removeColor,
+	}).Write([]byte(strings.TrimSuffix(event.msg, "\n")))
+
+	e := jsonEvent{
+		Time:      t.Format(time.RFC3339Nano),
+		Level:     event.level.String(),
+		Prefix:    logger.Prefix,
+		Func:      event.caller,
+		RequestID: event.requestID,
+		Msg:       string(baw),
+	}
+	if event.filename != "" {
+		e.Source = fmt.Sprintf("%s:%d", event.filename, event.line)
+	}
+	if event.stacktrace != "" && logger.StacktraceLevel <= event.level {
+		e.Stacktrace = event.stacktrace
+	}
+
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	data, err := json.Marshal(&e)
+	if err != nil {
+		return err
+	}
+	*buf = append(*buf, data...)
+	*buf = append(*buf, '\n')
+	return nil
+}
+
 // LogEvent logs the event to the internal writer
 func (logger *WriterLogger) LogEvent(event *Event) error {
-	if logger.Level > event.level {
+	if logger.Level > event.level && !event.forced {
 		return nil
 	}
 
@@ -231,7 +293,13 @@ func (logger *WriterLogger) LogEvent(event *Event) error {
 		return nil
 	}
 	var buf []byte
-	logger.createMsg(&buf, event)
+	if logger.Format == "json" {
+		if err := logger.createJSONMsg(&buf, event); err != nil {
+			return err
+		}
+	} else {
+		logger.createMsg(&buf, event)
+	}
 	_, err := logger.out.Write(buf)
 	return err
 }
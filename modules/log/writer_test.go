@@ -275,3 +275,73 @@ func TestBrokenRegexp(t *testing.T) {
 	b.Close()
 	assert.Equal(t, true, closed)
 }
+
+func TestBaseLoggerJSON(t *testing.T) {
+	var written []byte
+
+	c := CallbackWriteCloser{
+		callback: func(p []byte, close bool) {
+			written = p
+		},
+	}
+
+	b := WriterLogger{
+		Level:    INFO,
+		Flags:    LstdFlags | LUTC,
+		Colorize: true,
+		Format:   "json",
+	}
+	b.NewWriterLogger(c)
+
+	date := time.Date(2019, time.January, 13, 22, 3, 30, 15, time.UTC)
+	event := Event{
+		level:     WARN,
+		msg:       "TEST " + string(ColorBytes(FgRed)) + "MSG" + string(resetBytes) + "\n",
+		caller:    "CALLER",
+		filename:  "FULL/FILENAME",
+		line:      1,
+		time:      date,
+		requestID: "abc123",
+	}
+	assert.NoError(t, b.LogEvent(&event))
+	assert.Equal(t, `{"time":"2019-01-13T22:03:30.000000015Z","level":"warn","source":"FULL/FILENAME:1","func":"CALLER","request_id":"abc123","msg":"TEST MSG"}`+"\n", string(written))
+
+	written = written[:0]
+	event.level = DEBUG
+	assert.NoError(t, b.LogEvent(&event))
+	assert.Empty(t, written)
+
+	// forced events are logged regardless of the level
+	event.forced = true
+	assert.NoError(t, b.LogEvent(&event))
+	assert.Contains(t, string(written), `"level":"debug"`)
+}
+
+func TestBaseLoggerRequestID(t *testing.T) {
+	var written []byte
+
+	c := CallbackWriteCloser{
+		callback: func(p []byte, close bool) {
+			written = p
+		},
+	}
+
+	b := WriterLogger{
+		Level: INFO,
+		Flags: Llevelinitial | Lrequestid,
+	}
+	b.NewWriterLogger(c)
+
+	event := Event{
+		level:     INFO,
+		msg:       "TEST MSG",
+		time:      time.Now(),
+		requestID: "abc123",
+	}
+	assert.NoError(t, b.LogEvent(&event))
+	assert.Equal(t, "[I] [abc123] TEST MSG\n", string(written))
+
+	b.Flags = Llevelinitial
+	assert.NoError(t, b.LogEvent(&event))
+	assert.Equal(t, "[I] TEST MSG\n", string(written))
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package maintenance implements the instance maintenance mode, during which
+// only site administrators can use Gitea, everyone else has read-only git access
+// and the queues are paused.
+//
+// The mode is kept in the memory of the running process only. It is not
+// persisted: a restarted process starts in the mode configured in the
+// [maintenance] section, and when several Gitea processes serve the same
+// instance, the mode has to be enabled on each of them.
+package maintenance
+
+import (
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// State represents the maintenance mode of the instance
+type State struct {
+	Enabled bool
+	Message string
+	Since   time.Time
+}
+
+var (
+	lock  sync.RWMutex
+	state State
+)
+
+// Init enables the maintenance mode if it is configured
+func Init() {
+	if setting.Maintenance.Enabled {
+		Enable(setting.Maintenance.Message)
+	}
+}
+
+// Enable enables the maintenance mode of this process with an optional message shown to the users and pauses the queues
+func Enable(message string) {
+	lock.Lock()
+	defer lock.Unlock()
+	if !state.Enabled {
+		log.Info("Enabling maintenance mode")
+		state.Since = time.Now()
+	}
+	state.Enabled = true
+	state.Message = message
+	queue.Pause()
+}
+
+// Disable disables the maintenance mode of this process and resumes the queues
+func Disable() {
+	lock.Lock()
+	defer lock.Unlock()
+	if state.Enabled {
+		log.Info("Disabling maintenance mode")
+	}
+	state = State{}
+	queue.Resume()
+}
+
+// IsEnabled returns true if the instance is in maintenance mode
+func IsEnabled() bool {
+	lock.RLock()
+	defer lock.RUnlock()
+	return state.Enabled
+}
+
+// GetState returns the current maintenance mode of the instance
+func GetState() State {
+	lock.RLock()
+	defer lock.RUnlock()
+	return state
+}
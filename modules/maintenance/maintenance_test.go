@@ -0,0 +1,33 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package maintenance
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/queue"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnableDisable(t *testing.T) {
+	assert.False(t, IsEnabled())
+
+	Enable("upgrading the database")
+	assert.True(t, IsEnabled())
+	assert.True(t, queue.IsPaused())
+	state := GetState()
+	assert.Equal(t, "upgrading the database", state.Message)
+	assert.False(t, state.Since.IsZero())
+
+	Enable("")
+	assert.Equal(t, state.Since, GetState().Since)
+	assert.Empty(t, GetState().Message)
+
+	Disable()
+	assert.False(t, IsEnabled())
+	assert.False(t, queue.IsPaused())
+	assert.Equal(t, State{}, GetState())
+}
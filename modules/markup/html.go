@@ -420,7 +420,7 @@ func (ctx *postProcessCtx) visitNode(node *html.Node, visitText bool) {
 					lnk = util.URLJoin(prefix, lnk)
 					link = []byte(lnk)
 				}
-				node.Attr[idx].Val = string(link)
+				node.Attr[idx].Val = MediaProxyLink(string(link))
 			}
 		} else if node.Data == "a" {
 			visitText = false
@@ -769,7 +769,7 @@ func shortLinkProcessorFull(ctx *postProcessCtx, node *html.Node, noLink bool) {
 		childNode.Data = "img"
 		childNode.DataAtom = atom.Img
 		childNode.Attr = []html.Attribute{
-			{Key: "src", Val: link},
+			{Key: "src", Val: MediaProxyLink(link)},
 			{Key: "title", Val: title},
 			{Key: "alt", Val: alt},
 		}
@@ -0,0 +1,214 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mdtext
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"sync"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/markup/common"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/jaytaylor/html2text"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	gutil "github.com/yuin/goldmark/util"
+)
+
+var (
+	textParser parser.Parser
+	once       = sync.Once{}
+)
+
+// textRenderer renders the nodes of a markdown document as plain text
+type textRenderer struct {
+	source    []byte
+	urlPrefix string
+}
+
+// RenderText converts markdown to the plain text a human would write: the markup is removed, the links are followed
+// by their destination, resolved against urlPrefix, the lists keep their markers, the quotes are prefixed with > and
+// the code blocks are indented.
+func RenderText(rawBytes []byte, urlPrefix string) string {
+	once.Do(func() {
+		textParser = goldmark.New(
+			goldmark.WithExtensions(extension.Table,
+				extension.Strikethrough,
+				extension.TaskList,
+				common.Linkify,
+			),
+		).Parser()
+	})
+
+	rawBytes = util.NormalizeEOL(rawBytes)
+	r := &textRenderer{source: rawBytes, urlPrefix: urlPrefix}
+	doc := textParser.Parse(text.NewReader(rawBytes))
+	return strings.TrimSpace(r.blocks(doc, "\n\n"))
+}
+
+// blocks renders the block children of a node, separated by sep
+func (r *textRenderer) blocks(n ast.Node, sep string) string {
+	parts := make([]string, 0, n.ChildCount())
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if s := r.block(c); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+func (r *textRenderer) block(n ast.Node) string {
+	switch v := n.(type) {
+	case *ast.Paragraph, *ast.TextBlock, *ast.Heading:
+		return strings.TrimSpace(r.inlines(v))
+	case *ast.ThematicBreak:
+		return "---"
+	case *ast.CodeBlock, *ast.FencedCodeBlock:
+		return prefixLines(strings.TrimRight(r.lines(v), "\n"), "    ", "    ")
+	case *ast.HTMLBlock:
+		s, err := html2text.FromString(r.lines(v))
+		if err != nil {
+			log.Error("html2text.FromString: %v", err)
+			return ""
+		}
+		return s
+	case *ast.Blockquote:
+		return prefixLines(r.blocks(v, "\n\n"), "> ", "> ")
+	case *ast.List:
+		sep := "\n\n"
+		if v.IsTight {
+			sep = "\n"
+		}
+		items := make([]string, 0, v.ChildCount())
+		number := v.Start
+		for item := v.FirstChild(); item != nil; item = item.NextSibling() {
+			marker := "- "
+			if v.IsOrdered() {
+				marker = strconv.Itoa(number) + ". "
+				number++
+			}
+			items = append(items, prefixLines(r.blocks(item, sep), marker, strings.Repeat(" ", len(marker))))
+		}
+		return strings.Join(items, sep)
+	case *east.Table:
+		rows := make([]string, 0, v.ChildCount())
+		for row := v.FirstChild(); row != nil; row = row.NextSibling() {
+			cells := make([]string, 0, row.ChildCount())
+			for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+				cells = append(cells, strings.TrimSpace(r.inlines(cell)))
+			}
+			rows = append(rows, strings.Join(cells, " | "))
+		}
+		return strings.Join(rows, "\n")
+	}
+	return r.blocks(n, "\n\n")
+}
+
+// lines returns the raw lines of a block, like a code block
+func (r *textRenderer) lines(n ast.Node) string {
+	var buf bytes.Buffer
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		buf.Write(segment.Value(r.source))
+	}
+	return buf.String()
+}
+
+// inlines renders the inline children of a node
+func (r *textRenderer) inlines(n ast.Node) string {
+	var buf strings.Builder
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		r.inline(&buf, c)
+	}
+	return buf.String()
+}
+
+func (r *textRenderer) inline(buf *strings.Builder, n ast.Node) {
+	switch v := n.(type) {
+	case *ast.Text:
+		value := v.Segment.Value(r.source)
+		if !v.IsRaw() {
+			value = gutil.ResolveNumericReferences(gutil.ResolveEntityNames(gutil.UnescapePunctuations(value)))
+		}
+		buf.Write(value)
+		if v.SoftLineBreak() || v.HardLineBreak() {
+			buf.WriteByte('\n')
+		}
+	case *ast.CodeSpan:
+		for c := v.FirstChild(); c != nil; c = c.NextSibling() {
+			value := c.(*ast.Text).Segment.Value(r.source)
+			if bytes.HasSuffix(value, []byte("\n")) {
+				buf.Write(value[:len(value)-1])
+				if c != v.LastChild() {
+					buf.WriteByte(' ')
+				}
+			} else {
+				buf.Write(value)
+			}
+		}
+	case *ast.String:
+		buf.Write(v.Value)
+	case *ast.RawHTML:
+		// the inline tags are dropped, their content is in the sibling nodes
+	case *ast.AutoLink:
+		buf.Write(v.URL(r.source))
+	case *ast.Link:
+		r.inlineWithDestination(buf, v, string(v.Destination))
+	case *ast.Image:
+		r.inlineWithDestination(buf, v, string(v.Destination))
+	case *east.TaskCheckBox:
+		if v.IsChecked {
+			buf.WriteString("[x] ")
+		} else {
+			buf.WriteString("[ ] ")
+		}
+	default:
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			r.inline(buf, c)
+		}
+	}
+}
+
+// inlineWithDestination renders the text of a link or an image followed by its destination, unless they are the same
+func (r *textRenderer) inlineWithDestination(buf *strings.Builder, n ast.Node, destination string) {
+	label := r.inlines(n)
+	buf.WriteString(label)
+	if destination == "" || destination[0] == '#' {
+		return
+	}
+	if !strings.Contains(destination, ":") && r.urlPrefix != "" {
+		destination = util.URLJoin(r.urlPrefix, destination)
+	}
+	if destination != label {
+		if label != "" {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString("<" + destination + ">")
+	}
+}
+
+// prefixLines prefixes the first line of s with first and the other ones with rest
+func prefixLines(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		if line == "" {
+			prefix = strings.TrimRight(prefix, " ")
+		}
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
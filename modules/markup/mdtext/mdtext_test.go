@@ -0,0 +1,47 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mdtext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderText(t *testing.T) {
+	cases := []struct {
+		markdown string
+		text     string
+	}{
+		{
+			"## A title\n\nSome **bold** and _emphasized_ text,\nwith a `code span` and an escaped \\*star\\* &amp; entity.",
+			"A title\n\nSome bold and emphasized text,\nwith a code span and an escaped *star* & entity.",
+		},
+		{
+			"See [the docs](docs/README.md), [an anchor](#top), <https://gitea.io> and https://example.com.",
+			"See the docs <https://try.gitea.io/user2/repo1/src/branch/master/docs/README.md>, an anchor, https://gitea.io and https://example.com.",
+		},
+		{
+			"![logo](https://gitea.io/logo.png)",
+			"logo <https://gitea.io/logo.png>",
+		},
+		{
+			"* one\n* two\n  continued\n\n3. three\n4. four\n\n- [x] done\n- [ ] todo",
+			"- one\n- two\n  continued\n\n3. three\n4. four\n\n- [x] done\n- [ ] todo",
+		},
+		{
+			"> quoted\n>\n> - item\n\n```go\nfunc main() {\n\n}\n```",
+			"> quoted\n>\n> - item\n\n    func main() {\n\n    }",
+		},
+		{
+			"| a | b |\n|---|---|\n| 1 | 2 |\n\n---\n\n<div>\n<b>html</b> block\n</div>",
+			"a | b\n1 | 2\n\n---\n\n*html* block",
+		},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.text, RenderText([]byte(c.markdown), "https://try.gitea.io/user2/repo1/src/branch/master"), c.markdown)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package markup
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// signMediaProxyLink returns the HMAC of a link proxied by the media proxy, as computed by camo
+func signMediaProxyLink(link string) []byte {
+	mac := hmac.New(sha1.New, []byte(setting.MediaProxy.Key))
+	_, _ = mac.Write([]byte(link))
+	return mac.Sum(nil)
+}
+
+// MediaProxyLink returns the link of the media proxy an external image is loaded through, the link itself if the
+// media proxy is disabled or the image is not external. The link has the format of camo:
+// {server}/{hex encoded signature}/{hex encoded link}.
+func MediaProxyLink(link string) string {
+	if !setting.MediaProxy.Enabled || IsSameDomain(link) {
+		return link
+	}
+	lower := strings.ToLower(link)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+		return link
+	}
+	if strings.HasPrefix(link, setting.MediaProxy.ServerURL+"/") {
+		return link
+	}
+	return setting.MediaProxy.ServerURL + "/" + hex.EncodeToString(signMediaProxyLink(link)) + "/" + hex.EncodeToString([]byte(link))
+}
+
+// VerifyMediaProxyLink returns the link a signed link of the media proxy loads, false if the signature is invalid
+func VerifyMediaProxyLink(signature, encodedLink string) (string, bool) {
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return "", false
+	}
+	link, err := hex.DecodeString(encodedLink)
+	if err != nil {
+		return "", false
+	}
+	if !hmac.Equal(sig, signMediaProxyLink(string(link))) {
+		return "", false
+	}
+	return string(link), true
+}
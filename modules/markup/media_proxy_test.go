@@ -0,0 +1,53 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package markup_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	. "code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMediaProxyLink(t *testing.T) {
+	setting.AppURL = AppURL
+	setting.AppSubURL = AppSubURL
+	defer func() {
+		setting.MediaProxy.Enabled = false
+	}()
+
+	link := "https://example.com/image.png"
+	assert.Equal(t, link, MediaProxyLink(link))
+
+	setting.MediaProxy.Enabled = true
+	setting.MediaProxy.ServerURL = "https://camo.example.org"
+	setting.MediaProxy.Key = "secret"
+
+	// the signature is the one computed by camo
+	proxied := MediaProxyLink(link)
+	assert.Equal(t, "https://camo.example.org/ca5fd6368c33df6ec30959beec538cea8c5d1afe/"+hex.EncodeToString([]byte(link)), proxied)
+	parts := strings.Split(strings.TrimPrefix(proxied, setting.MediaProxy.ServerURL+"/"), "/")
+	if assert.Len(t, parts, 2) {
+		verified, ok := VerifyMediaProxyLink(parts[0], parts[1])
+		assert.True(t, ok)
+		assert.Equal(t, link, verified)
+		_, ok = VerifyMediaProxyLink(parts[0], hex.EncodeToString([]byte("https://example.com/other.png")))
+		assert.False(t, ok)
+		_, ok = VerifyMediaProxyLink("zz", parts[1])
+		assert.False(t, ok)
+	}
+
+	// the images of the instance, the links which are not http nor https and the proxied links are kept
+	for _, kept := range []string{AppURL + "user/repo/raw/branch/master/image.png", "/image.png", "data:image/png;base64,AA==", proxied} {
+		assert.Equal(t, kept, MediaProxyLink(kept))
+	}
+
+	rendered := RenderString(".md", "![image]("+link+")", AppSubURL, localMetas)
+	assert.Contains(t, rendered, `src="`+proxied+`"`)
+}
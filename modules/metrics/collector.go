@@ -6,6 +6,7 @@ package metrics
 
 import (
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -15,28 +16,35 @@ const namespace = "gitea_"
 // Collector implements the prometheus.Collector interface and
 // exposes gitea metrics for prometheus
 type Collector struct {
-	Accesses      *prometheus.Desc
-	Actions       *prometheus.Desc
-	Attachments   *prometheus.Desc
-	Comments      *prometheus.Desc
-	Follows       *prometheus.Desc
-	HookTasks     *prometheus.Desc
-	Issues        *prometheus.Desc
-	Labels        *prometheus.Desc
-	LoginSources  *prometheus.Desc
-	Milestones    *prometheus.Desc
-	Mirrors       *prometheus.Desc
-	Oauths        *prometheus.Desc
-	Organizations *prometheus.Desc
-	PublicKeys    *prometheus.Desc
-	Releases      *prometheus.Desc
-	Repositories  *prometheus.Desc
-	Stars         *prometheus.Desc
-	Teams         *prometheus.Desc
-	UpdateTasks   *prometheus.Desc
-	Users         *prometheus.Desc
-	Watches       *prometheus.Desc
-	Webhooks      *prometheus.Desc
+	Accesses                  *prometheus.Desc
+	Actions                   *prometheus.Desc
+	Attachments               *prometheus.Desc
+	Comments                  *prometheus.Desc
+	Follows                   *prometheus.Desc
+	GitPackCacheHits          *prometheus.Desc
+	GitPackCacheMisses        *prometheus.Desc
+	GitPackCacheSize          *prometheus.Desc
+	GitRefsCacheHits          *prometheus.Desc
+	GitRefsCacheMisses        *prometheus.Desc
+	GitRefsCacheInvalidations *prometheus.Desc
+	GitRefsCacheSize          *prometheus.Desc
+	HookTasks                 *prometheus.Desc
+	Issues                    *prometheus.Desc
+	Labels                    *prometheus.Desc
+	LoginSources              *prometheus.Desc
+	Milestones                *prometheus.Desc
+	Mirrors                   *prometheus.Desc
+	Oauths                    *prometheus.Desc
+	Organizations             *prometheus.Desc
+	PublicKeys                *prometheus.Desc
+	Releases                  *prometheus.Desc
+	Repositories              *prometheus.Desc
+	Stars                     *prometheus.Desc
+	Teams                     *prometheus.Desc
+	UpdateTasks               *prometheus.Desc
+	Users                     *prometheus.Desc
+	Watches                   *prometheus.Desc
+	Webhooks                  *prometheus.Desc
 }
 
 // NewCollector returns a new Collector with all prometheus.Desc initialized
@@ -67,6 +75,41 @@ func NewCollector() Collector {
 			"Number of Follows",
 			nil, nil,
 		),
+		GitPackCacheHits: prometheus.NewDesc(
+			namespace+"git_pack_cache_hits",
+			"Number of fetches served from the pack cache",
+			nil, nil,
+		),
+		GitPackCacheMisses: prometheus.NewDesc(
+			namespace+"git_pack_cache_misses",
+			"Number of cacheable fetches not found in the pack cache",
+			nil, nil,
+		),
+		GitPackCacheSize: prometheus.NewDesc(
+			namespace+"git_pack_cache_size_bytes",
+			"Size of the packs in the pack cache",
+			nil, nil,
+		),
+		GitRefsCacheHits: prometheus.NewDesc(
+			namespace+"git_refs_cache_hits",
+			"Number of ref advertisements served from the refs cache",
+			nil, nil,
+		),
+		GitRefsCacheMisses: prometheus.NewDesc(
+			namespace+"git_refs_cache_misses",
+			"Number of ref advertisements not found in the refs cache",
+			nil, nil,
+		),
+		GitRefsCacheInvalidations: prometheus.NewDesc(
+			namespace+"git_refs_cache_invalidations",
+			"Number of repositories whose cached ref advertisements were removed as their refs changed",
+			nil, nil,
+		),
+		GitRefsCacheSize: prometheus.NewDesc(
+			namespace+"git_refs_cache_size_bytes",
+			"Size of the ref advertisements in the refs cache",
+			nil, nil,
+		),
 		HookTasks: prometheus.NewDesc(
 			namespace+"hooktasks",
 			"Number of HookTasks",
@@ -163,6 +206,13 @@ func (c Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.Attachments
 	ch <- c.Comments
 	ch <- c.Follows
+	ch <- c.GitPackCacheHits
+	ch <- c.GitPackCacheMisses
+	ch <- c.GitPackCacheSize
+	ch <- c.GitRefsCacheHits
+	ch <- c.GitRefsCacheMisses
+	ch <- c.GitRefsCacheInvalidations
+	ch <- c.GitRefsCacheSize
 	ch <- c.HookTasks
 	ch <- c.Issues
 	ch <- c.Labels
@@ -296,4 +346,47 @@ func (c Collector) Collect(ch chan<- prometheus.Metric) {
 		prometheus.GaugeValue,
 		float64(stats.Counter.Webhook),
 	)
+
+	if git.DefaultPackCache != nil {
+		packCacheStats := git.DefaultPackCache.Stats()
+		ch <- prometheus.MustNewConstMetric(
+			c.GitPackCacheHits,
+			prometheus.CounterValue,
+			float64(packCacheStats.Hits),
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.GitPackCacheMisses,
+			prometheus.CounterValue,
+			float64(packCacheStats.Misses),
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.GitPackCacheSize,
+			prometheus.GaugeValue,
+			float64(packCacheStats.Size),
+		)
+	}
+
+	if git.DefaultRefsCache != nil {
+		refsCacheStats := git.DefaultRefsCache.Stats()
+		ch <- prometheus.MustNewConstMetric(
+			c.GitRefsCacheHits,
+			prometheus.CounterValue,
+			float64(refsCacheStats.Hits),
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.GitRefsCacheMisses,
+			prometheus.CounterValue,
+			float64(refsCacheStats.Misses),
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.GitRefsCacheInvalidations,
+			prometheus.CounterValue,
+			float64(refsCacheStats.Invalidations),
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.GitRefsCacheSize,
+			prometheus.GaugeValue,
+			float64(refsCacheStats.Size),
+		)
+	}
 }
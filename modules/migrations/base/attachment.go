@@ -0,0 +1,22 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package base
+
+import (
+	"io"
+	"time"
+)
+
+// Attachment is a file attached to an issue, a pull request or a comment
+type Attachment struct {
+	Name    string
+	Size    *int
+	Created time.Time
+	// Reference is the text of the content linking to the attachment, it is replaced by the link to the migrated one
+	Reference   string
+	DownloadURL *string `yaml:"download_url"`
+	// if DownloadURL is nil, the function should be invoked
+	DownloadFunc func() (io.ReadCloser, error) `yaml:"-"`
+}
@@ -17,4 +17,5 @@ type Comment struct {
 	Updated     time.Time
 	Content     string
 	Reactions   []*Reaction
+	Attachments []*Attachment
 }
@@ -34,4 +34,6 @@ type MigrateOptions struct {
 	ReleaseAssets   bool
 	MigrateToRepoID int64
 	MirrorInterval  string `json:"mirror_interval"`
+	// ConvertCI converts the GitLab CI configuration of the default branch into a workflow
+	ConvertCI bool `json:"convert_ci"`
 }
@@ -34,6 +34,7 @@ type PullRequest struct {
 	Assignees      []string
 	IsLocked       bool `yaml:"is_locked"`
 	Reactions      []*Reaction
+	Attachments    []*Attachment
 }
 
 // IsForkPullRequest returns true if the pull request from a forked repository but not the same repository
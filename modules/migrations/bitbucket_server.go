@@ -0,0 +1,512 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+var (
+	_ base.Downloader        = &BitbucketServerDownloader{}
+	_ base.DownloaderFactory = &BitbucketServerDownloaderFactory{}
+)
+
+func init() {
+	RegisterDownloaderFactory(&BitbucketServerDownloaderFactory{})
+}
+
+// BitbucketServerDownloaderFactory defines a Bitbucket Server downloader factory
+type BitbucketServerDownloaderFactory struct {
+}
+
+// New returns a Downloader related to this factory according MigrateOptions
+func (f *BitbucketServerDownloaderFactory) New(ctx context.Context, opts base.MigrateOptions) (base.Downloader, error) {
+	u, err := url.Parse(opts.CloneAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	contextPath, projectKey, repoSlug, err := parseBitbucketServerPath(u.Path)
+	if err != nil {
+		return nil, err
+	}
+	baseURL := u.Scheme + "://" + u.Host + contextPath
+
+	log.Trace("Create Bitbucket Server downloader. BaseURL: %s ProjectKey: %s RepoSlug: %s", baseURL, projectKey, repoSlug)
+	return NewBitbucketServerDownloader(ctx, baseURL, opts.AuthUsername, opts.AuthPassword, opts.AuthToken, projectKey, repoSlug), nil
+}
+
+// GitServiceType returns the type of git service
+func (f *BitbucketServerDownloaderFactory) GitServiceType() structs.GitServiceType {
+	return structs.BitbucketServerService
+}
+
+// parseBitbucketServerPath splits the path of the web or clone URL of a Bitbucket Server repository into the
+// context path of the server, the key of the project and the slug of the repository. The repositories of users
+// belong to a project whose key is the name of the user prefixed by a tilde.
+func parseBitbucketServerPath(p string) (contextPath, projectKey, repoSlug string, err error) {
+	fields := strings.Split(strings.Trim(p, "/"), "/")
+	for i := len(fields) - 1; i >= 0; i-- {
+		switch fields[i] {
+		case "scm":
+			// the clone URLs: /scm/KEY/SLUG.git
+			if i+3 == len(fields) {
+				return contextPathOf(fields[:i]), fields[i+1], strings.TrimSuffix(fields[i+2], ".git"), nil
+			}
+		case "projects", "users":
+			// the web URLs: /projects/KEY/repos/SLUG/browse and /users/NAME/repos/SLUG/browse
+			if i+3 < len(fields) && fields[i+2] == "repos" {
+				projectKey = fields[i+1]
+				if fields[i] == "users" {
+					projectKey = "~" + projectKey
+				}
+				return contextPathOf(fields[:i]), projectKey, strings.TrimSuffix(fields[i+3], ".git"), nil
+			}
+		}
+	}
+	return "", "", "", fmt.Errorf("invalid path: %s", p)
+}
+
+func contextPathOf(fields []string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	return "/" + strings.Join(fields, "/")
+}
+
+// BitbucketServerDownloader implements a Downloader interface to get repository information
+// from Bitbucket Server via its REST API
+type BitbucketServerDownloader struct {
+	base.NullDownloader
+	ctx        context.Context
+	client     *http.Client
+	baseURL    string
+	projectKey string
+	repoSlug   string
+	userName   string
+	password   string
+	token      string
+}
+
+// NewBitbucketServerDownloader creates a Bitbucket Server Downloader
+func NewBitbucketServerDownloader(ctx context.Context, baseURL, userName, password, token, projectKey, repoSlug string) *BitbucketServerDownloader {
+	return &BitbucketServerDownloader{
+		ctx:        ctx,
+		client:     &http.Client{},
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		projectKey: projectKey,
+		repoSlug:   repoSlug,
+		userName:   userName,
+		password:   password,
+		token:      token,
+	}
+}
+
+// SetContext set context
+func (d *BitbucketServerDownloader) SetContext(ctx context.Context) {
+	d.ctx = ctx
+}
+
+// get requests the given URL of the server with the credentials of the downloader
+func (d *BitbucketServerDownloader) get(link string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(d.ctx, "GET", link, nil)
+	if err != nil {
+		return nil, err
+	}
+	if d.token != "" {
+		req.Header.Set("Authorization", "Bearer "+d.token)
+	} else if d.userName != "" {
+		req.SetBasicAuth(d.userName, d.password)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %d requesting %s", resp.StatusCode, link)
+	}
+	return resp, nil
+}
+
+// getJSON decodes the response of the REST API of the repository to the given path into v
+func (d *BitbucketServerDownloader) getJSON(path string, v interface{}) error {
+	resp, err := d.get(fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/%s", d.baseURL, url.PathEscape(d.projectKey), url.PathEscape(d.repoSlug), path))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+type bitbucketServerPage struct {
+	IsLastPage    bool `json:"isLastPage"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+type bitbucketServerUser struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+type bitbucketServerLink struct {
+	Href string `json:"href"`
+	Name string `json:"name"`
+}
+
+type bitbucketServerRepository struct {
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+	Public      bool   `json:"public"`
+	Project     struct {
+		Key string `json:"key"`
+	} `json:"project"`
+	Links struct {
+		Clone []bitbucketServerLink `json:"clone"`
+		Self  []bitbucketServerLink `json:"self"`
+	} `json:"links"`
+}
+
+func (r *bitbucketServerRepository) cloneURL() string {
+	for _, link := range r.Links.Clone {
+		if link.Name == "http" {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+type bitbucketServerRef struct {
+	DisplayID    string                    `json:"displayId"`
+	LatestCommit string                    `json:"latestCommit"`
+	Repository   bitbucketServerRepository `json:"repository"`
+}
+
+type bitbucketServerPullRequest struct {
+	ID          int64              `json:"id"`
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	State       string             `json:"state"`
+	Locked      bool               `json:"locked"`
+	CreatedDate int64              `json:"createdDate"`
+	UpdatedDate int64              `json:"updatedDate"`
+	ClosedDate  int64              `json:"closedDate"`
+	FromRef     bitbucketServerRef `json:"fromRef"`
+	ToRef       bitbucketServerRef `json:"toRef"`
+	Author      struct {
+		User bitbucketServerUser `json:"user"`
+	} `json:"author"`
+	Properties struct {
+		MergeCommit struct {
+			ID string `json:"id"`
+		} `json:"mergeCommit"`
+	} `json:"properties"`
+}
+
+type bitbucketServerComment struct {
+	ID          int64                     `json:"id"`
+	Text        string                    `json:"text"`
+	Author      bitbucketServerUser       `json:"author"`
+	CreatedDate int64                     `json:"createdDate"`
+	UpdatedDate int64                     `json:"updatedDate"`
+	Comments    []*bitbucketServerComment `json:"comments"`
+}
+
+type bitbucketServerActivity struct {
+	ID            int64                   `json:"id"`
+	CreatedDate   int64                   `json:"createdDate"`
+	User          bitbucketServerUser     `json:"user"`
+	Action        string                  `json:"action"`
+	CommentAction string                  `json:"commentAction"`
+	Comment       *bitbucketServerComment `json:"comment"`
+	CommentAnchor *struct {
+		FromHash string `json:"fromHash"`
+		ToHash   string `json:"toHash"`
+		Line     int    `json:"line"`
+		Path     string `json:"path"`
+		FileType string `json:"fileType"`
+	} `json:"commentAnchor"`
+}
+
+func bitbucketServerTime(millis int64) time.Time {
+	return time.Unix(0, millis*int64(time.Millisecond))
+}
+
+// GetRepoInfo returns a repository information
+func (d *BitbucketServerDownloader) GetRepoInfo() (*base.Repository, error) {
+	var repo bitbucketServerRepository
+	if err := d.getJSON("", &repo); err != nil {
+		return nil, err
+	}
+
+	var defaultBranch struct {
+		DisplayID string `json:"displayId"`
+	}
+	if err := d.getJSON("branches/default", &defaultBranch); err != nil {
+		// empty repositories have no default branch
+		log.Warn("Unable to get the default branch of %s/%s: %v", d.projectKey, d.repoSlug, err)
+	}
+
+	var originalURL string
+	if len(repo.Links.Self) > 0 {
+		originalURL = repo.Links.Self[0].Href
+	}
+
+	return &base.Repository{
+		Owner:         d.projectKey,
+		Name:          d.repoSlug,
+		IsPrivate:     !repo.Public,
+		Description:   repo.Description,
+		CloneURL:      repo.cloneURL(),
+		OriginalURL:   originalURL,
+		DefaultBranch: defaultBranch.DisplayID,
+	}, nil
+}
+
+// GetTopics returns no topics, Bitbucket Server doesn't support them
+func (d *BitbucketServerDownloader) GetTopics() ([]string, error) {
+	return []string{}, nil
+}
+
+// bitbucketServerAttachmentPattern matches the markdown links to the attachments of a repository, like
+// ![screenshot](attachment:12/1a2b3c)
+var bitbucketServerAttachmentPattern = regexp.MustCompile(`\[([^\]]*)\]\((attachment:\d+/([^)\s]+))\)`)
+
+// convertAttachments returns the attachments referenced in the given text
+func (d *BitbucketServerDownloader) convertAttachments(text string, created time.Time) []*base.Attachment {
+	var attachments []*base.Attachment
+	seen := make(map[string]bool)
+	for _, match := range bitbucketServerAttachmentPattern.FindAllStringSubmatch(text, -1) {
+		name, reference, id := match[1], match[2], match[3]
+		if seen[reference] {
+			continue
+		}
+		seen[reference] = true
+		if name == "" {
+			name = id
+		}
+
+		link := fmt.Sprintf("%s/projects/%s/repos/%s/attachments/%s", d.baseURL, url.PathEscape(d.projectKey), url.PathEscape(d.repoSlug), id)
+		attachments = append(attachments, &base.Attachment{
+			Name:      name,
+			Created:   created,
+			Reference: reference,
+			DownloadFunc: func() (io.ReadCloser, error) {
+				resp, err := d.get(link)
+				if err != nil {
+					return nil, err
+				}
+				// resp.Body is closed by the uploader
+				return resp.Body, nil
+			},
+		})
+	}
+	return attachments
+}
+
+// GetPullRequests returns pull requests according page and perPage
+func (d *BitbucketServerDownloader) GetPullRequests(page, perPage int) ([]*base.PullRequest, bool, error) {
+	var result struct {
+		bitbucketServerPage
+		Values []*bitbucketServerPullRequest `json:"values"`
+	}
+	if err := d.getJSON(fmt.Sprintf("pull-requests?state=ALL&order=OLDEST&start=%d&limit=%d", (page-1)*perPage, perPage), &result); err != nil {
+		return nil, false, fmt.Errorf("error while listing pull requests: %v", err)
+	}
+
+	prs := make([]*base.PullRequest, 0, len(result.Values))
+	for _, pr := range result.Values {
+		created := bitbucketServerTime(pr.CreatedDate)
+		state := "open"
+		var closed, mergedTime *time.Time
+		if pr.State != "OPEN" {
+			state = "closed"
+			closedTime := bitbucketServerTime(pr.ClosedDate)
+			if pr.ClosedDate == 0 {
+				closedTime = bitbucketServerTime(pr.UpdatedDate)
+			}
+			closed = &closedTime
+			if pr.State == "MERGED" {
+				mergedTime = closed
+			}
+		}
+
+		prs = append(prs, &base.PullRequest{
+			Number:         pr.ID,
+			Title:          pr.Title,
+			PosterName:     pr.Author.User.Name,
+			PosterID:       pr.Author.User.ID,
+			PosterEmail:    pr.Author.User.EmailAddress,
+			Content:        pr.Description,
+			State:          state,
+			Created:        created,
+			Updated:        bitbucketServerTime(pr.UpdatedDate),
+			Closed:         closed,
+			Merged:         mergedTime != nil,
+			MergedTime:     mergedTime,
+			MergeCommitSHA: pr.Properties.MergeCommit.ID,
+			IsLocked:       pr.Locked,
+			Head: base.PullRequestBranch{
+				CloneURL:  pr.FromRef.Repository.cloneURL(),
+				Ref:       pr.FromRef.DisplayID,
+				SHA:       pr.FromRef.LatestCommit,
+				RepoName:  pr.FromRef.Repository.Slug,
+				OwnerName: pr.FromRef.Repository.Project.Key,
+			},
+			Base: base.PullRequestBranch{
+				Ref:       pr.ToRef.DisplayID,
+				SHA:       pr.ToRef.LatestCommit,
+				RepoName:  pr.ToRef.Repository.Slug,
+				OwnerName: pr.ToRef.Repository.Project.Key,
+			},
+			Attachments: d.convertAttachments(pr.Description, created),
+		})
+	}
+
+	return prs, result.IsLastPage, nil
+}
+
+// getActivities returns all the activities of a pull request, the most recent first
+func (d *BitbucketServerDownloader) getActivities(pullRequestNumber int64) ([]*bitbucketServerActivity, error) {
+	var activities []*bitbucketServerActivity
+	start := 0
+	for {
+		var result struct {
+			bitbucketServerPage
+			Values []*bitbucketServerActivity `json:"values"`
+		}
+		if err := d.getJSON(fmt.Sprintf("pull-requests/%d/activities?start=%d&limit=100", pullRequestNumber, start), &result); err != nil {
+			return nil, fmt.Errorf("error while listing the activities of pull request %d: %v", pullRequestNumber, err)
+		}
+		activities = append(activities, result.Values...)
+		if result.IsLastPage || len(result.Values) == 0 {
+			return activities, nil
+		}
+		start = result.NextPageStart
+	}
+}
+
+// flattenComments returns the comment followed by all its replies
+func flattenComments(comment *bitbucketServerComment) []*bitbucketServerComment {
+	comments := []*bitbucketServerComment{comment}
+	for _, reply := range comment.Comments {
+		comments = append(comments, flattenComments(reply)...)
+	}
+	return comments
+}
+
+// GetComments returns the general comments of a pull request and their replies, the ones attached to a line of
+// its diff are returned as reviews
+func (d *BitbucketServerDownloader) GetComments(pullRequestNumber int64) ([]*base.Comment, error) {
+	activities, err := d.getActivities(pullRequestNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []*base.Comment
+	// the activities are listed from the most recent one
+	for i := len(activities) - 1; i >= 0; i-- {
+		activity := activities[i]
+		if activity.Action != "COMMENTED" || activity.CommentAction != "ADDED" || activity.Comment == nil || activity.CommentAnchor != nil {
+			continue
+		}
+		for _, comment := range flattenComments(activity.Comment) {
+			created := bitbucketServerTime(comment.CreatedDate)
+			comments = append(comments, &base.Comment{
+				IssueIndex:  pullRequestNumber,
+				PosterID:    comment.Author.ID,
+				PosterName:  comment.Author.Name,
+				PosterEmail: comment.Author.EmailAddress,
+				Created:     created,
+				Updated:     bitbucketServerTime(comment.UpdatedDate),
+				Content:     comment.Text,
+				Attachments: d.convertAttachments(comment.Text, created),
+			})
+		}
+	}
+	return comments, nil
+}
+
+// GetReviews returns the approvals, the requests for changes and the comments attached to the diff of a pull request
+func (d *BitbucketServerDownloader) GetReviews(pullRequestNumber int64) ([]*base.Review, error) {
+	activities, err := d.getActivities(pullRequestNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var reviews []*base.Review
+	for i := len(activities) - 1; i >= 0; i-- {
+		activity := activities[i]
+		review := &base.Review{
+			ID:           activity.ID,
+			IssueIndex:   pullRequestNumber,
+			ReviewerID:   activity.User.ID,
+			ReviewerName: activity.User.Name,
+			CreatedAt:    bitbucketServerTime(activity.CreatedDate),
+		}
+		switch {
+		case activity.Action == "APPROVED":
+			review.State = base.ReviewStateApproved
+		case activity.Action == "REVIEWED":
+			// the reviewer marked the pull request as needing work
+			review.State = base.ReviewStateChangesRequested
+		case activity.Action == "COMMENTED" && activity.CommentAction == "ADDED" && activity.Comment != nil && activity.CommentAnchor != nil:
+			anchor := activity.CommentAnchor
+			review.State = base.ReviewStateCommented
+			review.CommitID = anchor.ToHash
+			line := anchor.Line
+			if anchor.FileType == "FROM" {
+				// the lines of the original file are negative
+				line = -line
+			}
+			var parentID int64
+			for _, comment := range flattenComments(activity.Comment) {
+				review.Comments = append(review.Comments, &base.ReviewComment{
+					ID:        comment.ID,
+					InReplyTo: parentID,
+					Content:   comment.Text,
+					TreePath:  anchor.Path,
+					Line:      line,
+					CommitID:  anchor.ToHash,
+					PosterID:  comment.Author.ID,
+					CreatedAt: bitbucketServerTime(comment.CreatedDate),
+					UpdatedAt: bitbucketServerTime(comment.UpdatedDate),
+				})
+				parentID = activity.Comment.ID
+			}
+		default:
+			continue
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, nil
+}
+
+// FormatCloneURL add authentication into remote URLs
+func (d *BitbucketServerDownloader) FormatCloneURL(opts base.MigrateOptions, remoteAddr string) (string, error) {
+	if len(opts.AuthToken) > 0 {
+		u, err := url.Parse(remoteAddr)
+		if err != nil {
+			return "", err
+		}
+		u.User = url.UserPassword("x-token-auth", opts.AuthToken)
+		return u.String(), nil
+	}
+	return d.NullDownloader.FormatCloneURL(opts, remoteAddr)
+}
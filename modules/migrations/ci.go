@@ -0,0 +1,403 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/repofiles"
+	"code.gitea.io/gitea/services/actions"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	gitlabCIPath = ".gitlab-ci.yml"
+
+	// maxCIConfigSize is the size above which a CI configuration is not converted
+	maxCIConfigSize = 1024 * 1024
+)
+
+// gitlabDefaultStages are the stages of a GitLab CI configuration which doesn't list them
+var gitlabDefaultStages = []string{".pre", "build", "test", "deploy", ".post"}
+
+// gitlabGlobalKeywords are the top-level keys of a GitLab CI configuration which are not jobs
+var gitlabGlobalKeywords = map[string]bool{
+	"default":       true,
+	"image":         true,
+	"services":      true,
+	"stages":        true,
+	"types":         true,
+	"before_script": true,
+	"after_script":  true,
+	"variables":     true,
+	"cache":         true,
+	"include":       true,
+	"workflow":      true,
+}
+
+// gitlabCIJob is a job of a GitLab CI configuration
+type gitlabCIJob struct {
+	id           string
+	stage        string
+	image        string
+	needs        []string
+	hasNeeds     bool
+	beforeScript []string
+	script       []string
+	afterScript  []string
+	variables    yaml.MapSlice
+}
+
+// ConvertGitlabCI converts a simple GitLab CI configuration into a workflow of the built-in CI. Every job runs on
+// push and pull request events on a runner with the linux label, waiting for the jobs of the previous stage. The
+// settings which can't be converted are listed in a comment at the top of the workflow.
+func ConvertGitlabCI(content []byte) ([]byte, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+
+	defaults := &gitlabCIJob{}
+	stages := gitlabDefaultStages
+	var jobs []*gitlabCIJob
+	var unsupported []string
+	for _, item := range doc {
+		key := fmt.Sprint(item.Key)
+		switch {
+		case key == "stages" || key == "types":
+			list, err := toStringSlice(item.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", key, err)
+			}
+			stages = append(append([]string{".pre"}, list...), ".post")
+		case key == "default":
+			def, ok := item.Value.(yaml.MapSlice)
+			if !ok {
+				return nil, fmt.Errorf("default: must be a mapping")
+			}
+			unsupported = append(unsupported, parseGitlabCIJob(defaults, "default", def)...)
+		case key == "image" || key == "before_script" || key == "after_script" || key == "variables":
+			unsupported = append(unsupported, parseGitlabCIJob(defaults, "", yaml.MapSlice{item})...)
+		case gitlabGlobalKeywords[key]:
+			unsupported = append(unsupported, key)
+		case strings.HasPrefix(key, "."):
+			// hidden jobs are only templates for the other jobs
+			unsupported = append(unsupported, key)
+		default:
+			def, ok := item.Value.(yaml.MapSlice)
+			if !ok {
+				return nil, fmt.Errorf("%s: must be a mapping", key)
+			}
+			job := &gitlabCIJob{id: key, stage: "test"}
+			unsupported = append(unsupported, parseGitlabCIJob(job, key, def)...)
+			jobs = append(jobs, job)
+		}
+	}
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("the configuration has no jobs")
+	}
+
+	// without needs, the jobs of a stage wait for all the jobs of the previous stage which has jobs
+	jobsByStage := make(map[string][]string)
+	for _, job := range jobs {
+		jobsByStage[job.stage] = append(jobsByStage[job.stage], job.id)
+	}
+	stageIndexes := make(map[string]int, len(stages))
+	for i, stage := range stages {
+		stageIndexes[stage] = i
+	}
+	for _, job := range jobs {
+		index, ok := stageIndexes[job.stage]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown stage %q", job.id, job.stage)
+		}
+		if job.hasNeeds {
+			continue
+		}
+		for i := index - 1; i >= 0; i-- {
+			if previous := jobsByStage[stages[i]]; len(previous) > 0 {
+				job.needs = previous
+				break
+			}
+		}
+	}
+
+	workflowJobs := make(yaml.MapSlice, 0, len(jobs))
+	for _, job := range jobs {
+		workflowJobs = append(workflowJobs, yaml.MapItem{Key: job.id, Value: job.toWorkflowJob(defaults)})
+	}
+	workflow := yaml.MapSlice{
+		{Key: "name", Value: "GitLab CI"},
+		{Key: "on", Value: []string{actions.EventPush, actions.EventPullRequest}},
+		{Key: "jobs", Value: workflowJobs},
+	}
+	converted, err := yaml.Marshal(workflow)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := actions.ParseWorkflow(converted); err != nil {
+		return nil, fmt.Errorf("invalid converted workflow: %v", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# Converted from " + gitlabCIPath + " when the repository was migrated.\n")
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		buf.WriteString("# These settings were not converted:\n")
+		for _, key := range unsupported {
+			buf.WriteString("#   " + key + "\n")
+		}
+	}
+	buf.Write(converted)
+	return buf.Bytes(), nil
+}
+
+// parseGitlabCIJob sets the fields of job from its definition and returns the keys which can't be converted,
+// prefixed by prefix
+func parseGitlabCIJob(job *gitlabCIJob, prefix string, def yaml.MapSlice) []string {
+	var unsupported []string
+	for _, field := range def {
+		key := fmt.Sprint(field.Key)
+		var err error
+		switch key {
+		case "stage":
+			job.stage = fmt.Sprint(field.Value)
+		case "image":
+			job.image, err = gitlabCIImage(field.Value)
+		case "needs":
+			job.hasNeeds = true
+			job.needs, err = gitlabCINeeds(field.Value)
+		case "before_script":
+			job.beforeScript, err = toStringSlice(field.Value)
+		case "script":
+			job.script, err = toStringSlice(field.Value)
+		case "after_script":
+			job.afterScript, err = toStringSlice(field.Value)
+		case "variables":
+			job.variables, err = gitlabCIVariables(field.Value)
+		default:
+			err = fmt.Errorf("not supported")
+		}
+		if err != nil {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			unsupported = append(unsupported, key)
+		}
+	}
+	return unsupported
+}
+
+// toWorkflowJob returns the definition of the job in a workflow, falling back to the given defaults
+func (job *gitlabCIJob) toWorkflowJob(defaults *gitlabCIJob) yaml.MapSlice {
+	def := yaml.MapSlice{{Key: "runs-on", Value: "linux"}}
+	if len(job.needs) > 0 {
+		def = append(def, yaml.MapItem{Key: "needs", Value: job.needs})
+	}
+
+	image := job.image
+	if image == "" {
+		image = defaults.image
+	}
+	if image != "" {
+		def = append(def, yaml.MapItem{Key: "container", Value: image})
+	}
+
+	env := append(yaml.MapSlice{}, defaults.variables...)
+	env = append(env, job.variables...)
+	if len(env) > 0 {
+		def = append(def, yaml.MapItem{Key: "env", Value: env})
+	}
+
+	beforeScript := job.beforeScript
+	if beforeScript == nil {
+		beforeScript = defaults.beforeScript
+	}
+	afterScript := job.afterScript
+	if afterScript == nil {
+		afterScript = defaults.afterScript
+	}
+	steps := make([]yaml.MapSlice, 0, len(beforeScript)+len(job.script)+len(afterScript))
+	for _, script := range [][]string{beforeScript, job.script} {
+		for _, command := range script {
+			steps = append(steps, yaml.MapSlice{{Key: "run", Value: command}})
+		}
+	}
+	for _, command := range afterScript {
+		// the after_script of GitLab CI runs even if the job failed
+		steps = append(steps, yaml.MapSlice{{Key: "if", Value: "always()"}, {Key: "run", Value: command}})
+	}
+	return append(def, yaml.MapItem{Key: "steps", Value: steps})
+}
+
+func gitlabCIImage(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case yaml.MapSlice:
+		for _, item := range v {
+			if item.Key == "name" {
+				return fmt.Sprint(item.Value), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("invalid image")
+}
+
+func gitlabCINeeds(value interface{}) ([]string, error) {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("needs must be a list")
+	}
+	needs := make([]string, 0, len(list))
+	for _, need := range list {
+		switch v := need.(type) {
+		case string:
+			needs = append(needs, v)
+		case yaml.MapSlice:
+			// needs of other projects or pipelines are not supported
+			var job string
+			for _, item := range v {
+				switch item.Key {
+				case "job":
+					job = fmt.Sprint(item.Value)
+				case "project", "pipeline":
+					return nil, fmt.Errorf("needs of other pipelines are not supported")
+				}
+			}
+			if job == "" {
+				return nil, fmt.Errorf("invalid need")
+			}
+			needs = append(needs, job)
+		default:
+			return nil, fmt.Errorf("invalid need")
+		}
+	}
+	return needs, nil
+}
+
+func gitlabCIVariables(value interface{}) (yaml.MapSlice, error) {
+	vars, ok := value.(yaml.MapSlice)
+	if !ok {
+		return nil, fmt.Errorf("variables must be a mapping")
+	}
+	env := make(yaml.MapSlice, 0, len(vars))
+	for _, item := range vars {
+		val := item.Value
+		if def, ok := val.(yaml.MapSlice); ok {
+			// variables with a description are mappings
+			val = nil
+			for _, field := range def {
+				if field.Key == "value" {
+					val = field.Value
+				}
+			}
+		}
+		if val == nil {
+			val = ""
+		}
+		env = append(env, yaml.MapItem{Key: fmt.Sprint(item.Key), Value: fmt.Sprint(val)})
+	}
+	return env, nil
+}
+
+// toStringSlice converts a string or a list of strings, possibly nested, into a list of strings
+func toStringSlice(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			items, err := toStringSlice(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, items...)
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("must be a string or a list of strings")
+}
+
+// convertCIConfig commits a workflow converted from the GitLab CI configuration of the default branch of a migrated
+// repository. Failures are logged and reported as a notice since the repository itself was migrated.
+func convertCIConfig(doer *models.User, repo *models.Repository) {
+	if repo.IsEmpty {
+		return
+	}
+	if err := commitConvertedCIConfig(doer, repo); err != nil {
+		log.Error("Unable to convert the CI configuration of %s: %v", repo.FullName(), err)
+		if err := models.CreateRepositoryNotice(fmt.Sprintf("Convert the CI configuration of %s failed: %v", repo.FullName(), err)); err != nil {
+			log.Error("CreateRepositoryNotice: %v", err)
+		}
+	}
+}
+
+func commitConvertedCIConfig(doer *models.User, repo *models.Repository) error {
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	commit, err := gitRepo.GetBranchCommit(repo.DefaultBranch)
+	if err != nil {
+		return err
+	}
+	entry, err := commit.GetTreeEntryByPath(gitlabCIPath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if !entry.IsRegular() || entry.Size() > maxCIConfigSize {
+		return nil
+	}
+
+	workflowPath := path.Join(actions.WorkflowsDir, "gitlab-ci.yml")
+	if _, err := commit.GetTreeEntryByPath(workflowPath); err == nil {
+		// the workflow was already converted
+		return nil
+	} else if !git.IsErrNotExist(err) {
+		return err
+	}
+
+	reader, err := entry.Blob().DataAsync()
+	if err != nil {
+		return err
+	}
+	content, err := ioutil.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return err
+	}
+
+	workflow, err := ConvertGitlabCI(content)
+	if err != nil {
+		return err
+	}
+
+	_, err = repofiles.CreateOrUpdateRepoFile(repo, doer, &repofiles.UpdateRepoFileOptions{
+		LastCommitID: commit.ID.String(),
+		OldBranch:    repo.DefaultBranch,
+		NewBranch:    repo.DefaultBranch,
+		TreePath:     workflowPath,
+		Message:      "Convert " + gitlabCIPath + " to a workflow",
+		Content:      string(workflow),
+		IsNewFile:    true,
+	})
+	return err
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/services/actions"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertGitlabCI(t *testing.T) {
+	converted, err := ConvertGitlabCI([]byte(`image: golang:1.16
+stages:
+  - build
+  - test
+variables:
+  GOFLAGS: -mod=vendor
+before_script:
+  - go version
+.template:
+  tags: [docker]
+build:
+  stage: build
+  script: make build
+  artifacts:
+    paths: [gitea]
+unit:
+  stage: test
+  image:
+    name: golang:1.17
+  variables:
+    TAGS:
+      value: sqlite
+      description: The build tags
+  script:
+    - make test
+  after_script:
+    - make clean
+lint:
+  script: make lint
+  cache: {}
+`))
+	assert.NoError(t, err)
+	content := string(converted)
+	assert.Contains(t, content, "#   .template\n")
+	assert.Contains(t, content, "#   build.artifacts\n")
+	assert.Contains(t, content, "#   lint.cache\n")
+
+	wf, err := actions.ParseWorkflow(converted)
+	assert.NoError(t, err)
+	assert.True(t, wf.Match(actions.EventPush, "main", false))
+	assert.True(t, wf.Match(actions.EventPullRequest, "main", false))
+	if assert.Len(t, wf.Jobs, 3) {
+		assert.Equal(t, "build", wf.Jobs[0].ID)
+		assert.Empty(t, wf.Jobs[0].Needs)
+		assert.Equal(t, []string{"linux"}, wf.Jobs[0].RunsOn)
+		assert.Contains(t, wf.Jobs[0].Payload, "container: golang:1.16")
+		assert.Contains(t, wf.Jobs[0].Payload, "- run: go version\n")
+		assert.Contains(t, wf.Jobs[0].Payload, "- run: make build\n")
+		assert.Contains(t, wf.Jobs[0].Payload, "GOFLAGS: -mod=vendor")
+
+		assert.Equal(t, "unit", wf.Jobs[1].ID)
+		assert.Equal(t, []string{"build"}, wf.Jobs[1].Needs)
+		assert.Contains(t, wf.Jobs[1].Payload, "container: golang:1.17")
+		assert.Contains(t, wf.Jobs[1].Payload, "TAGS: sqlite")
+		assert.Contains(t, wf.Jobs[1].Payload, "run: make clean")
+
+		assert.Equal(t, "lint", wf.Jobs[2].ID)
+		assert.Equal(t, []string{"build"}, wf.Jobs[2].Needs)
+	}
+
+	converted, err = ConvertGitlabCI([]byte(`test:
+  script: make test
+deploy:
+  stage: deploy
+  needs: [{job: test}]
+  script: make deploy
+`))
+	assert.NoError(t, err)
+	wf, err = actions.ParseWorkflow(converted)
+	assert.NoError(t, err)
+	if assert.Len(t, wf.Jobs, 2) {
+		assert.Equal(t, []string{"test"}, wf.Jobs[1].Needs)
+	}
+
+	for name, content := range map[string]string{
+		"no jobs":       "stages: [build]\n",
+		"unknown stage": "build:\n  stage: package\n  script: make\n",
+		"invalid yaml":  "build: [\n",
+	} {
+		_, err := ConvertGitlabCI([]byte(content))
+		assert.Error(t, err, name)
+	}
+}
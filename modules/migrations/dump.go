@@ -44,8 +44,9 @@ type RepositoryDumper struct {
 	pullrequestFile *os.File
 	reviewFiles     map[int64]*os.File
 
-	gitRepo     *git.Repository
-	prHeadCache map[string]struct{}
+	gitRepo         *git.Repository
+	prHeadCache     map[string]struct{}
+	attachmentCount int
 }
 
 // NewRepositoryDumper creates an gitea Uploader
@@ -350,6 +351,12 @@ func (g *RepositoryDumper) SyncTags() error {
 
 // CreateIssues creates issues
 func (g *RepositoryDumper) CreateIssues(issues ...*base.Issue) error {
+	for _, issue := range issues {
+		if err := g.saveAttachments(issue.Number, issue.Attachments); err != nil {
+			return err
+		}
+	}
+
 	var err error
 	if g.issueFile == nil {
 		g.issueFile, err = os.Create(filepath.Join(g.baseDir, "issue.yml"))
@@ -399,10 +406,62 @@ func (g *RepositoryDumper) createItems(dir string, itemFiles map[int64]*os.File,
 	return nil
 }
 
+// saveAttachments downloads the attachments of an issue, a pull request or one of their comments next to the dump
+// and points them to the downloaded files
+func (g *RepositoryDumper) saveAttachments(issueIndex int64, attachments []*base.Attachment) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+	attachDir := filepath.Join("attachments", fmt.Sprintf("%d", issueIndex))
+	if err := os.MkdirAll(filepath.Join(g.baseDir, attachDir), os.ModePerm); err != nil {
+		return err
+	}
+	for _, attachment := range attachments {
+		// the names are not unique, so they are prefixed by a counter
+		g.attachmentCount++
+		attachLocalPath := filepath.Join(attachDir, fmt.Sprintf("%d_%s", g.attachmentCount, filepath.Base(attachment.Name)))
+
+		err := func(attachPath string) error {
+			var rc io.ReadCloser
+			var err error
+			if attachment.DownloadURL == nil {
+				rc, err = attachment.DownloadFunc()
+				if err != nil {
+					return err
+				}
+			} else {
+				resp, err := http.Get(*attachment.DownloadURL)
+				if err != nil {
+					return err
+				}
+				rc = resp.Body
+			}
+			defer rc.Close()
+
+			fw, err := os.Create(attachPath)
+			if err != nil {
+				return fmt.Errorf("Create: %v", err)
+			}
+			defer fw.Close()
+
+			_, err = io.Copy(fw, rc)
+			return err
+		}(filepath.Join(g.baseDir, attachLocalPath))
+		if err != nil {
+			return err
+		}
+		attachment.DownloadURL = &attachLocalPath // to save the filepath on the yml file, change the source
+	}
+	return nil
+}
+
 // CreateComments creates comments of issues
 func (g *RepositoryDumper) CreateComments(comments ...*base.Comment) error {
 	var commentsMap = make(map[int64][]interface{}, len(comments))
 	for _, comment := range comments {
+		if err := g.saveAttachments(comment.IssueIndex, comment.Attachments); err != nil {
+			return err
+		}
 		commentsMap[comment.IssueIndex] = append(commentsMap[comment.IssueIndex], comment)
 	}
 
@@ -412,8 +471,15 @@ func (g *RepositoryDumper) CreateComments(comments ...*base.Comment) error {
 // CreatePullRequests creates pull requests
 func (g *RepositoryDumper) CreatePullRequests(prs ...*base.PullRequest) error {
 	for _, pr := range prs {
-		// download patch file
+		if err := g.saveAttachments(pr.Number, pr.Attachments); err != nil {
+			return err
+		}
+
+		// download patch file, the downloaders which can't provide it leave PatchURL empty
 		err := func() error {
+			if pr.PatchURL == "" {
+				return nil
+			}
 			u, err := g.setURLToken(pr.PatchURL)
 			if err != nil {
 				return err
@@ -563,6 +629,36 @@ func DumpRepository(ctx context.Context, baseDir, ownerName string, opts base.Mi
 	return nil
 }
 
+// DumpLocalRepository dumps a repository of this instance with its wiki, issues, comments, labels, milestones and
+// releases to baseDir/repoName, in the format read by RestoreRepository. The pull requests are not dumped.
+func DumpLocalRepository(ctx context.Context, repo *models.Repository, baseDir, repoName string) error {
+	opts := base.MigrateOptions{
+		CloneAddr:      repo.HTMLURL(),
+		RepoName:       repoName,
+		GitServiceType: structs.GiteaService,
+		Private:        repo.IsPrivate,
+		Wiki:           true,
+		Issues:         true,
+		Milestones:     true,
+		Labels:         true,
+		Releases:       true,
+		Comments:       true,
+		ReleaseAssets:  true,
+	}
+	uploader, err := NewRepositoryDumper(ctx, baseDir, "", repoName, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := migrateRepository(NewGiteaLocalDownloader(ctx, repo), uploader, opts); err != nil {
+		if err1 := uploader.Rollback(); err1 != nil {
+			log.Error("rollback failed: %v", err1)
+		}
+		return err
+	}
+	return nil
+}
+
 // RestoreRepository restore a repository from the disk directory
 func RestoreRepository(ctx context.Context, baseDir string, ownerName, repoName string) error {
 	doer, err := models.GetAdminUser()
@@ -579,6 +675,7 @@ func RestoreRepository(ctx context.Context, baseDir string, ownerName, repoName
 		return err
 	}
 	tp, _ := strconv.Atoi(opts["service_type"])
+	isPrivate, _ := strconv.ParseBool(opts["is_private"])
 
 	if err = migrateRepository(downloader, uploader, base.MigrateOptions{
 		Wiki:           true,
@@ -590,6 +687,7 @@ func RestoreRepository(ctx context.Context, baseDir string, ownerName, repoName
 		PullRequests:   true,
 		ReleaseAssets:  true,
 		GitServiceType: structs.GitServiceType(tp),
+		Private:        isPrivate,
 	}); err != nil {
 		if err1 := uploader.Rollback(); err1 != nil {
 			log.Error("rollback failed: %v", err1)
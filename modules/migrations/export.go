@@ -0,0 +1,176 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// ExportOptions represents the options to export a repository to a GitHub or GitLab project
+type ExportOptions struct {
+	GitServiceType structs.GitServiceType `json:"service"`
+	// RemoteURL is the web URL of the project, which is created if it does not exist
+	RemoteURL  string `json:"remote_url"`
+	AuthToken  string `json:"auth_token"`
+	Issues     bool   `json:"issues"`
+	Comments   bool   `json:"comments"`
+	Labels     bool   `json:"labels"`
+	Milestones bool   `json:"milestones"`
+	Releases   bool   `json:"releases"`
+}
+
+// IsExportSupported returns true if a repository can be exported to another service
+func IsExportSupported(repo *models.Repository) bool {
+	return !repo.IsEmpty && !repo.IsBeingCreated()
+}
+
+// parseExportURL returns the base URL of the service and the path of the project of the web URL of a project
+func parseExportURL(remoteURL string) (baseURL, projectPath string, err error) {
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" || u.User != nil {
+		return "", "", fmt.Errorf("the URL of the project must be an http or https URL without credentials")
+	}
+	projectPath = strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if strings.Count(projectPath, "/") < 1 {
+		return "", "", fmt.Errorf("the URL of the project must contain its owner and its name")
+	}
+	return u.Scheme + "://" + u.Host, projectPath, nil
+}
+
+// CheckExportOptions checks the service, the URL and the token of an export, to a service allowed for migrations
+func CheckExportOptions(doer *models.User, opts ExportOptions) error {
+	if opts.GitServiceType != structs.GithubService && opts.GitServiceType != structs.GitlabService {
+		return fmt.Errorf("exporting to %s is not supported", opts.GitServiceType.Name())
+	}
+	if opts.AuthToken == "" {
+		return fmt.Errorf("the access token is required")
+	}
+	_, projectPath, err := parseExportURL(opts.RemoteURL)
+	if err != nil {
+		return err
+	}
+	if opts.GitServiceType == structs.GithubService && strings.Count(projectPath, "/") != 1 {
+		return fmt.Errorf("the URL of a GitHub repository must be made of its owner and its name")
+	}
+	return IsMigrateURLAllowed(opts.RemoteURL, doer)
+}
+
+// ExportRepository pushes the git data of a repository to a GitHub or GitLab project, which is created if it does not
+// exist, and creates there the issues, labels, milestones and releases according opts. The issues and comments are
+// created by the owner of the token, with the name of their original author in their content. The pull requests,
+// the wiki and the LFS objects are not exported.
+func ExportRepository(ctx context.Context, doer *models.User, repo *models.Repository, opts ExportOptions) error {
+	if !IsExportSupported(repo) {
+		return fmt.Errorf("%s cannot be exported", repo.FullName())
+	}
+	if err := CheckExportOptions(doer, opts); err != nil {
+		return err
+	}
+	baseURL, projectPath, err := parseExportURL(opts.RemoteURL)
+	if err != nil {
+		return err
+	}
+
+	var uploader base.Uploader
+	if opts.GitServiceType == structs.GithubService {
+		fields := strings.SplitN(projectPath, "/", 2)
+		uploader = NewGithubUploader(ctx, baseURL, opts.AuthToken, fields[0], fields[1])
+	} else if uploader, err = NewGitlabUploader(ctx, baseURL, opts.AuthToken, projectPath); err != nil {
+		return err
+	}
+
+	log.Trace("exporting %s to %s", repo.FullName(), opts.RemoteURL)
+	return migrateRepository(NewGiteaLocalDownloader(ctx, repo), uploader, base.MigrateOptions{
+		CloneAddr:      opts.RemoteURL,
+		GitServiceType: opts.GitServiceType,
+		Private:        repo.IsPrivate,
+		Issues:         opts.Issues,
+		Comments:       opts.Issues && opts.Comments,
+		Labels:         opts.Labels,
+		Milestones:     opts.Milestones,
+		Releases:       opts.Releases,
+		ReleaseAssets:  opts.Releases,
+	})
+}
+
+// pushGitData pushes the branches and the tags of the repository at repoPath to remoteURL, with the token as
+// credentials. The refs which already exist there must be ancestors of the pushed ones.
+func pushGitData(ctx context.Context, repoPath, remoteURL, token string) error {
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return err
+	}
+	u.User = url.UserPassword("oauth2", token)
+	pushURL := u.String()
+
+	if _, err := git.NewCommandContext(ctx, "push", "--quiet", pushURL, "refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*").
+		RunInDirTimeout(time.Duration(setting.Git.Timeout.Migrate)*time.Second, repoPath); err != nil {
+		return util.URLSanitizedError(fmt.Errorf("git push: %v", err), pushURL)
+	}
+	return nil
+}
+
+// exportedContent returns the content of an exported issue or comment, which mentions its original author and date
+// as it is created by the owner of the token
+func exportedContent(posterName string, created time.Time, content string) string {
+	return fmt.Sprintf("*Originally created by %s on %s*\n\n%s", posterName, created.UTC().Format("2006-01-02 15:04:05 MST"), content)
+}
+
+// writeReleaseAsset writes the content of a release asset to a temporary file named after it, which cleanup closes
+// and removes
+func writeReleaseAsset(asset *base.ReleaseAsset) (f *os.File, cleanup func(), err error) {
+	if asset.DownloadFunc == nil {
+		return nil, nil, fmt.Errorf("release asset %s cannot be downloaded", asset.Name)
+	}
+	rc, err := asset.DownloadFunc()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rc.Close()
+
+	tmpDir, err := ioutil.TempDir("", "gitea-export-asset")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup = func() {
+		if f != nil {
+			f.Close()
+		}
+		if err := util.RemoveAll(tmpDir); err != nil {
+			log.Error("Unable to remove %s: %v", tmpDir, err)
+		}
+	}
+
+	f, err = os.Create(filepath.Join(tmpDir, filepath.Base(asset.Name)))
+	if err == nil {
+		if _, err = io.Copy(f, rc); err == nil {
+			_, err = f.Seek(0, io.SeekStart)
+		}
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return f, cleanup, nil
+}
@@ -0,0 +1,261 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"io"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/storage"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+)
+
+var (
+	_ base.Downloader = &GiteaLocalDownloader{}
+)
+
+// GiteaLocalDownloader implements a Downloader interface to get the informations of a repository of this instance
+// from the database, to export it to another service. The pull requests are not supported.
+type GiteaLocalDownloader struct {
+	base.NullDownloader
+	ctx  context.Context
+	repo *models.Repository
+}
+
+// NewGiteaLocalDownloader creates a Downloader of a repository of this instance
+func NewGiteaLocalDownloader(ctx context.Context, repo *models.Repository) *GiteaLocalDownloader {
+	return &GiteaLocalDownloader{
+		ctx:  ctx,
+		repo: repo,
+	}
+}
+
+// SetContext set context
+func (g *GiteaLocalDownloader) SetContext(ctx context.Context) {
+	g.ctx = ctx
+}
+
+// GetRepoInfo returns a repository information, whose clone URL is the path of the repository on the disk
+func (g *GiteaLocalDownloader) GetRepoInfo() (*base.Repository, error) {
+	if err := g.repo.GetOwner(); err != nil {
+		return nil, err
+	}
+	return &base.Repository{
+		Name:          g.repo.Name,
+		Owner:         g.repo.OwnerName,
+		IsPrivate:     g.repo.IsPrivate,
+		Description:   g.repo.Description,
+		CloneURL:      g.repo.RepoPath(),
+		OriginalURL:   g.repo.HTMLURL(),
+		DefaultBranch: g.repo.DefaultBranch,
+	}, nil
+}
+
+// GetTopics return repository topics
+func (g *GiteaLocalDownloader) GetTopics() ([]string, error) {
+	return g.repo.Topics, nil
+}
+
+// GetMilestones returns milestones
+func (g *GiteaLocalDownloader) GetMilestones() ([]*base.Milestone, error) {
+	milestones, err := models.GetMilestones(models.GetMilestonesOption{
+		RepoID: g.repo.ID,
+		State:  api.StateAll,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var results = make([]*base.Milestone, 0, len(milestones))
+	for _, m := range milestones {
+		milestone := &base.Milestone{
+			Title:       m.Name,
+			Description: m.Content,
+			Created:     m.CreatedUnix.AsTime(),
+			State:       "open",
+		}
+		updated := m.UpdatedUnix.AsTime()
+		milestone.Updated = &updated
+		// milestones without a deadline have one in 9999
+		if m.DeadlineUnix.Year() != 9999 {
+			deadline := m.DeadlineUnix.AsTime()
+			milestone.Deadline = &deadline
+		}
+		if m.IsClosed {
+			milestone.State = "closed"
+			closed := m.ClosedDateUnix.AsTime()
+			milestone.Closed = &closed
+		}
+		results = append(results, milestone)
+	}
+	return results, nil
+}
+
+// GetReleases returns releases, drafts included, from the oldest to the newest
+func (g *GiteaLocalDownloader) GetReleases() ([]*base.Release, error) {
+	releases, err := models.GetReleasesByRepoID(g.repo.ID, models.FindReleasesOptions{IncludeDrafts: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var results = make([]*base.Release, 0, len(releases))
+	for i := len(releases) - 1; i >= 0; i-- {
+		rel := releases[i]
+		if err := rel.LoadAttributes(); err != nil {
+			return nil, err
+		}
+		release := &base.Release{
+			TagName:         rel.TagName,
+			TargetCommitish: rel.Target,
+			Name:            rel.Title,
+			Body:            rel.Note,
+			Draft:           rel.IsDraft,
+			Prerelease:      rel.IsPrerelease,
+			PublisherID:     rel.Publisher.ID,
+			PublisherName:   rel.Publisher.Name,
+			PublisherEmail:  rel.Publisher.Email,
+			Created:         rel.CreatedUnix.AsTime(),
+			Published:       rel.CreatedUnix.AsTime(),
+		}
+		for _, attach := range rel.Attachments {
+			attach := attach
+			size := int(attach.Size)
+			downloadCount := int(attach.DownloadCount)
+			release.Assets = append(release.Assets, &base.ReleaseAsset{
+				ID:            attach.ID,
+				Name:          attach.Name,
+				Size:          &size,
+				DownloadCount: &downloadCount,
+				Created:       attach.CreatedUnix.AsTime(),
+				Updated:       attach.CreatedUnix.AsTime(),
+				DownloadFunc: func() (io.ReadCloser, error) {
+					return storage.Attachments.Open(attach.RelativePath())
+				},
+			})
+		}
+		results = append(results, release)
+	}
+	return results, nil
+}
+
+// GetLabels returns labels of the repository, the labels of the organization are not included
+func (g *GiteaLocalDownloader) GetLabels() ([]*base.Label, error) {
+	labels, err := models.GetLabelsByRepoID(g.repo.ID, "", models.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var results = make([]*base.Label, 0, len(labels))
+	for _, label := range labels {
+		results = append(results, &base.Label{
+			Name:        label.Name,
+			Color:       label.Color,
+			Description: label.Description,
+		})
+	}
+	return results, nil
+}
+
+// GetIssues returns issues according start and limit, from the oldest to the newest
+func (g *GiteaLocalDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	issues, err := models.Issues(&models.IssuesOptions{
+		ListOptions: models.ListOptions{Page: page, PageSize: perPage},
+		RepoIDs:     []int64{g.repo.ID},
+		IsPull:      util.OptionalBoolFalse,
+		SortType:    "oldest",
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	var results = make([]*base.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if err := issue.LoadPoster(); err != nil {
+			return nil, false, err
+		}
+		if err := issue.LoadLabels(); err != nil {
+			return nil, false, err
+		}
+		if err := issue.LoadMilestone(); err != nil {
+			return nil, false, err
+		}
+
+		result := &base.Issue{
+			Number:     issue.Index,
+			PosterID:   issue.Poster.ID,
+			PosterName: issue.Poster.Name,
+			Title:      issue.Title,
+			Content:    issue.Content,
+			State:      "open",
+			IsLocked:   issue.IsLocked,
+			Created:    issue.CreatedUnix.AsTime(),
+			Updated:    issue.UpdatedUnix.AsTime(),
+		}
+		// the issues migrated from another service keep the name of their author there
+		if issue.OriginalAuthor != "" {
+			result.PosterName = issue.OriginalAuthor
+		}
+		if issue.Milestone != nil {
+			result.Milestone = issue.Milestone.Name
+		}
+		if issue.IsClosed {
+			result.State = "closed"
+			closed := issue.ClosedUnix.AsTime()
+			result.Closed = &closed
+		}
+		for _, label := range issue.Labels {
+			result.Labels = append(result.Labels, &base.Label{
+				Name:        label.Name,
+				Color:       label.Color,
+				Description: label.Description,
+			})
+		}
+		results = append(results, result)
+	}
+	return results, len(issues) < perPage, nil
+}
+
+// GetComments returns the plain comments of an issue, without the events
+func (g *GiteaLocalDownloader) GetComments(issueNumber int64) ([]*base.Comment, error) {
+	issue, err := models.GetIssueByIndex(g.repo.ID, issueNumber)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := models.FindComments(models.FindCommentsOptions{
+		IssueID: issue.ID,
+		Type:    models.CommentTypeComment,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var results = make([]*base.Comment, 0, len(comments))
+	for _, comment := range comments {
+		if err := comment.LoadPoster(); err != nil {
+			return nil, err
+		}
+		result := &base.Comment{
+			IssueIndex: issueNumber,
+			PosterID:   comment.Poster.ID,
+			PosterName: comment.Poster.Name,
+			Created:    comment.CreatedUnix.AsTime(),
+			Updated:    comment.UpdatedUnix.AsTime(),
+			Content:    comment.Content,
+		}
+		if comment.OriginalAuthor != "" {
+			result.PosterName = comment.OriginalAuthor
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// FormatCloneURL returns the path of the repository on the disk
+func (g *GiteaLocalDownloader) FormatCloneURL(opts base.MigrateOptions, remoteAddr string) (string, error) {
+	return remoteAddr, nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/migrations/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGiteaLocalDownloader(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	downloader := NewGiteaLocalDownloader(context.Background(), repo)
+
+	info, err := downloader.GetRepoInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "repo1", info.Name)
+	assert.Equal(t, "user2", info.Owner)
+	assert.Equal(t, repo.RepoPath(), info.CloneURL)
+
+	labels, err := downloader.GetLabels()
+	assert.NoError(t, err)
+	assert.EqualValues(t, []*base.Label{
+		{Name: "label1", Color: "#abcdef"},
+		{Name: "label2", Color: "#000000"},
+	}, labels)
+
+	milestones, err := downloader.GetMilestones()
+	assert.NoError(t, err)
+	assert.Len(t, milestones, 3)
+	states := make(map[string]string, len(milestones))
+	for _, milestone := range milestones {
+		states[milestone.Title] = milestone.State
+	}
+	assert.Equal(t, map[string]string{"milestone1": "open", "milestone2": "open", "milestone3": "closed"}, states)
+
+	releases, err := downloader.GetReleases()
+	assert.NoError(t, err)
+	if assert.Len(t, releases, 1) {
+		assert.Equal(t, "v1.1", releases[0].TagName)
+		assert.Equal(t, "testing-release", releases[0].Name)
+		assert.Equal(t, "user2", releases[0].PublisherName)
+		if assert.Len(t, releases[0].Assets, 1) {
+			assert.NotNil(t, releases[0].Assets[0].DownloadFunc)
+		}
+	}
+
+	// the pull requests are not listed
+	issues, isEnd, err := downloader.GetIssues(1, 10)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	if assert.Len(t, issues, 2) {
+		assert.EqualValues(t, 1, issues[0].Number)
+		assert.Equal(t, "issue1", issues[0].Title)
+		assert.Equal(t, "user1", issues[0].PosterName)
+		assert.Equal(t, "open", issues[0].State)
+		if assert.Len(t, issues[0].Labels, 1) {
+			assert.Equal(t, "label1", issues[0].Labels[0].Name)
+		}
+		assert.EqualValues(t, 4, issues[1].Number)
+		assert.Equal(t, "closed", issues[1].State)
+	}
+
+	// only the plain comments are listed
+	comments, err := downloader.GetComments(1)
+	assert.NoError(t, err)
+	if assert.Len(t, comments, 2) {
+		assert.Equal(t, "good work!", comments[0].Content)
+		assert.Equal(t, "user3", comments[0].PosterName)
+		assert.Equal(t, "meh...", comments[1].Content)
+	}
+
+	_, _, err = downloader.GetPullRequests(1, 10)
+	assert.Error(t, err)
+}
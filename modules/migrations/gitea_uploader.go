@@ -131,6 +131,29 @@ func (g *GiteaLocalUploader) CreateRepo(repo *base.Repository, opts base.Migrate
 	return err
 }
 
+// loadRepo makes the uploader create the data in an existing repository and loads its labels and milestones, which
+// the issues are matched with by name
+func (g *GiteaLocalUploader) loadRepo(repo *models.Repository) error {
+	labels, err := models.GetLabelsByRepoID(repo.ID, "", models.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, label := range labels {
+		g.labels.Store(label.Name, label)
+	}
+	milestones, err := models.GetMilestones(models.GetMilestonesOption{RepoID: repo.ID, State: structs.StateAll})
+	if err != nil {
+		return err
+	}
+	for _, milestone := range milestones {
+		g.milestones.Store(milestone.Name, milestone.ID)
+	}
+
+	g.repo = repo
+	g.gitRepo, err = git.OpenRepository(repo.RepoPath())
+	return err
+}
+
 // Close closes this uploader
 func (g *GiteaLocalUploader) Close() {
 	if g.gitRepo != nil {
@@ -299,6 +322,43 @@ func (g *GiteaLocalUploader) CreateReleases(releases ...*base.Release) error {
 	return models.InsertReleases(rels...)
 }
 
+// saveAttachments stores the attachments of an issue, a pull request or a comment and replaces the references to
+// them in its content by links to the stored ones
+func (g *GiteaLocalUploader) saveAttachments(content string, attachments []*base.Attachment) (string, []*models.Attachment, error) {
+	attachs := make([]*models.Attachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		var attach = models.Attachment{
+			UUID:        gouuid.New().String(),
+			Name:        attachment.Name,
+			UploaderID:  g.doer.ID,
+			CreatedUnix: timeutil.TimeStamp(attachment.Created.Unix()),
+		}
+
+		// attachment.DownloadURL maybe a local file
+		var rc io.ReadCloser
+		var err error
+		if attachment.DownloadURL == nil {
+			rc, err = attachment.DownloadFunc()
+		} else {
+			rc, err = uri.Open(*attachment.DownloadURL)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		attach.Size, err = storage.Attachments.Save(attach.RelativePath(), rc)
+		rc.Close()
+		if err != nil {
+			return "", nil, err
+		}
+
+		if attachment.Reference != "" {
+			content = strings.ReplaceAll(content, attachment.Reference, attach.DownloadURL())
+		}
+		attachs = append(attachs, &attach)
+	}
+	return content, attachs, nil
+}
+
 // SyncTags syncs releases with tags in the database
 func (g *GiteaLocalUploader) SyncTags() error {
 	return repository.SyncReleasesWithTags(g.repo, g.gitRepo)
@@ -363,6 +423,12 @@ func (g *GiteaLocalUploader) CreateIssues(issues ...*base.Issue) error {
 		if issue.Closed != nil {
 			is.ClosedUnix = timeutil.TimeStamp(issue.Closed.Unix())
 		}
+
+		var err error
+		if is.Content, is.Attachments, err = g.saveAttachments(is.Content, issue.Attachments); err != nil {
+			return err
+		}
+
 		// add reactions
 		for _, reaction := range issue.Reactions {
 			userid, ok := g.userMap[reaction.UserID]
@@ -450,6 +516,11 @@ func (g *GiteaLocalUploader) CreateComments(comments ...*base.Comment) error {
 			cm.OriginalAuthorID = comment.PosterID
 		}
 
+		var err error
+		if cm.Content, cm.Attachments, err = g.saveAttachments(cm.Content, comment.Attachments); err != nil {
+			return err
+		}
+
 		// add reactions
 		for _, reaction := range comment.Reactions {
 			userid, ok := g.userMap[reaction.UserID]
@@ -545,8 +616,11 @@ func (g *GiteaLocalUploader) newPullRequest(pr *base.PullRequest) (*models.PullR
 		}
 	}
 
-	// download patch file
+	// download patch file, the downloaders which can't provide it leave PatchURL empty
 	err := func() error {
+		if pr.PatchURL == "" {
+			return nil
+		}
 		// pr.PatchURL maybe a local file
 		ret, err := uri.Open(pr.PatchURL)
 		if err != nil {
@@ -626,12 +700,18 @@ func (g *GiteaLocalUploader) newPullRequest(pr *base.PullRequest) (*models.PullR
 		head = pr.Head.Ref
 	}
 
+	content, attachments, err := g.saveAttachments(pr.Content, pr.Attachments)
+	if err != nil {
+		return nil, err
+	}
+
 	var issue = models.Issue{
 		RepoID:      g.repo.ID,
 		Repo:        g.repo,
 		Title:       pr.Title,
 		Index:       pr.Number,
-		Content:     pr.Content,
+		Content:     content,
+		Attachments: attachments,
 		MilestoneID: milestoneID,
 		IsPull:      true,
 		IsClosed:    pr.State == "closed",
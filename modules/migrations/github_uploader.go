@@ -0,0 +1,261 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations/base"
+
+	"github.com/google/go-github/v32/github"
+	"golang.org/x/oauth2"
+)
+
+var (
+	_ base.Uploader = &GithubUploader{}
+)
+
+// GithubUploader implements an Uploader to a GitHub repository via APIv3, to export a repository of this instance
+type GithubUploader struct {
+	ctx        context.Context
+	client     *github.Client
+	token      string
+	repoOwner  string
+	repoName   string
+	milestones map[string]int
+	labels     map[string]bool
+	// issues are the numbers of the created issues by original number
+	issues map[int64]int
+}
+
+// NewGithubUploader creates an Uploader to a GitHub repository, authenticated with a personal access token
+func NewGithubUploader(ctx context.Context, baseURL, token, repoOwner, repoName string) *GithubUploader {
+	uploader := &GithubUploader{
+		ctx:        ctx,
+		token:      token,
+		repoOwner:  repoOwner,
+		repoName:   repoName,
+		milestones: make(map[string]int),
+		labels:     make(map[string]bool),
+		issues:     make(map[int64]int),
+	}
+
+	client := oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+	uploader.client = github.NewClient(client)
+	if baseURL != "https://github.com" {
+		uploader.client, _ = github.NewEnterpriseClient(baseURL, baseURL, client)
+	}
+	return uploader
+}
+
+// MaxBatchInsertSize returns the table's max batch insert size
+func (g *GithubUploader) MaxBatchInsertSize(tp string) int {
+	return 100
+}
+
+// CreateRepo creates the repository if it does not exist, under the owner of the token or the organization of the
+// same name, and pushes the git data to it
+func (g *GithubUploader) CreateRepo(repo *base.Repository, opts base.MigrateOptions) error {
+	remote, resp, err := g.client.Repositories.Get(g.ctx, g.repoOwner, g.repoName)
+	if err != nil {
+		if resp == nil || resp.StatusCode != http.StatusNotFound {
+			return err
+		}
+		user, _, err := g.client.Users.Get(g.ctx, "")
+		if err != nil {
+			return err
+		}
+		org := g.repoOwner
+		if strings.EqualFold(user.GetLogin(), g.repoOwner) {
+			org = ""
+		}
+		remote, _, err = g.client.Repositories.Create(g.ctx, org, &github.Repository{
+			Name:        &g.repoName,
+			Description: &repo.Description,
+			Private:     &opts.Private,
+		})
+		if err != nil {
+			return fmt.Errorf("create repository: %v", err)
+		}
+	}
+
+	if err := pushGitData(g.ctx, repo.CloneURL, remote.GetCloneURL(), g.token); err != nil {
+		return err
+	}
+	if repo.DefaultBranch != "" && repo.DefaultBranch != remote.GetDefaultBranch() {
+		if _, _, err := g.client.Repositories.Edit(g.ctx, g.repoOwner, g.repoName, &github.Repository{DefaultBranch: &repo.DefaultBranch}); err != nil {
+			return fmt.Errorf("set default branch: %v", err)
+		}
+	}
+	return nil
+}
+
+// CreateTopics replaces the topics of the repository
+func (g *GithubUploader) CreateTopics(topics ...string) error {
+	_, _, err := g.client.Repositories.ReplaceAllTopics(g.ctx, g.repoOwner, g.repoName, topics)
+	return err
+}
+
+// CreateMilestones creates the milestones which have not been created yet
+func (g *GithubUploader) CreateMilestones(milestones ...*base.Milestone) error {
+	for _, milestone := range milestones {
+		if _, ok := g.milestones[milestone.Title]; ok {
+			continue
+		}
+		state := milestone.State
+		created, _, err := g.client.Issues.CreateMilestone(g.ctx, g.repoOwner, g.repoName, &github.Milestone{
+			Title:       &milestone.Title,
+			Description: &milestone.Description,
+			State:       &state,
+			DueOn:       milestone.Deadline,
+		})
+		if err != nil {
+			return fmt.Errorf("create milestone %s: %v", milestone.Title, err)
+		}
+		g.milestones[milestone.Title] = created.GetNumber()
+	}
+	return nil
+}
+
+// CreateLabels creates the labels which have not been created yet, the ones which already exist in the repository
+// are kept
+func (g *GithubUploader) CreateLabels(labels ...*base.Label) error {
+	for _, label := range labels {
+		if g.labels[label.Name] {
+			continue
+		}
+		color := strings.TrimPrefix(label.Color, "#")
+		_, resp, err := g.client.Issues.CreateLabel(g.ctx, g.repoOwner, g.repoName, &github.Label{
+			Name:        &label.Name,
+			Color:       &color,
+			Description: &label.Description,
+		})
+		if err != nil {
+			if resp == nil || resp.StatusCode != http.StatusUnprocessableEntity {
+				return fmt.Errorf("create label %s: %v", label.Name, err)
+			}
+			log.Warn("Label %s already exists in %s/%s, kept", label.Name, g.repoOwner, g.repoName)
+		}
+		g.labels[label.Name] = true
+	}
+	return nil
+}
+
+// CreateReleases creates the releases with their assets
+func (g *GithubUploader) CreateReleases(releases ...*base.Release) error {
+	for _, release := range releases {
+		created, _, err := g.client.Repositories.CreateRelease(g.ctx, g.repoOwner, g.repoName, &github.RepositoryRelease{
+			TagName:         &release.TagName,
+			TargetCommitish: &release.TargetCommitish,
+			Name:            &release.Name,
+			Body:            &release.Body,
+			Draft:           &release.Draft,
+			Prerelease:      &release.Prerelease,
+		})
+		if err != nil {
+			return fmt.Errorf("create release %s: %v", release.TagName, err)
+		}
+
+		for _, asset := range release.Assets {
+			if err := g.uploadReleaseAsset(created.GetID(), asset); err != nil {
+				return fmt.Errorf("upload asset %s of release %s: %v", asset.Name, release.TagName, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (g *GithubUploader) uploadReleaseAsset(releaseID int64, asset *base.ReleaseAsset) error {
+	f, cleanup, err := writeReleaseAsset(asset)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	_, _, err = g.client.Repositories.UploadReleaseAsset(g.ctx, g.repoOwner, g.repoName, releaseID, &github.UploadOptions{Name: asset.Name}, f)
+	return err
+}
+
+// SyncTags does nothing as the tags are pushed with the git data
+func (g *GithubUploader) SyncTags() error {
+	return nil
+}
+
+// CreateIssues creates the issues, closed if they are, and records their numbers for their comments
+func (g *GithubUploader) CreateIssues(issues ...*base.Issue) error {
+	for _, issue := range issues {
+		body := exportedContent(issue.PosterName, issue.Created, issue.Content)
+		labels := make([]string, 0, len(issue.Labels))
+		for _, label := range issue.Labels {
+			labels = append(labels, label.Name)
+		}
+		req := &github.IssueRequest{
+			Title:  &issue.Title,
+			Body:   &body,
+			Labels: &labels,
+		}
+		if number, ok := g.milestones[issue.Milestone]; ok {
+			req.Milestone = &number
+		}
+
+		created, _, err := g.client.Issues.Create(g.ctx, g.repoOwner, g.repoName, req)
+		if err != nil {
+			return fmt.Errorf("create issue #%d: %v", issue.Number, err)
+		}
+		g.issues[issue.Number] = created.GetNumber()
+
+		if issue.State == "closed" {
+			state := "closed"
+			if _, _, err := g.client.Issues.Edit(g.ctx, g.repoOwner, g.repoName, created.GetNumber(), &github.IssueRequest{State: &state}); err != nil {
+				return fmt.Errorf("close issue #%d: %v", issue.Number, err)
+			}
+		}
+	}
+	return nil
+}
+
+// CreateComments creates the comments of the created issues
+func (g *GithubUploader) CreateComments(comments ...*base.Comment) error {
+	for _, comment := range comments {
+		number, ok := g.issues[comment.IssueIndex]
+		if !ok {
+			log.Warn("Issue #%d was not exported, its comment is skipped", comment.IssueIndex)
+			continue
+		}
+		body := exportedContent(comment.PosterName, comment.Created, comment.Content)
+		if _, _, err := g.client.Issues.CreateComment(g.ctx, g.repoOwner, g.repoName, number, &github.IssueComment{Body: &body}); err != nil {
+			return fmt.Errorf("create comment of issue #%d: %v", comment.IssueIndex, err)
+		}
+	}
+	return nil
+}
+
+// CreatePullRequests is not supported
+func (g *GithubUploader) CreatePullRequests(prs ...*base.PullRequest) error {
+	return base.ErrNotSupported{Entity: "PullRequests"}
+}
+
+// CreateReviews is not supported
+func (g *GithubUploader) CreateReviews(reviews ...*base.Review) error {
+	return base.ErrNotSupported{Entity: "Reviews"}
+}
+
+// Rollback does nothing, what was exported is left in the repository
+func (g *GithubUploader) Rollback() error {
+	return nil
+}
+
+// Finish does nothing
+func (g *GithubUploader) Finish() error {
+	return nil
+}
+
+// Close does nothing
+func (g *GithubUploader) Close() {
+}
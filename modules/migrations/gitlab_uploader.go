@@ -0,0 +1,290 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations/base"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+var (
+	_ base.Uploader = &GitlabUploader{}
+)
+
+// GitlabUploader implements an Uploader to a GitLab project via go-gitlab, to export a repository of this instance
+type GitlabUploader struct {
+	ctx         context.Context
+	client      *gitlab.Client
+	token       string
+	projectPath string
+	projectID   int
+	webURL      string
+	milestones  map[string]int
+	labels      map[string]bool
+	// issues are the IIDs of the created issues by original number
+	issues map[int64]int
+}
+
+// NewGitlabUploader creates an Uploader to a GitLab project, authenticated with a personal access token
+func NewGitlabUploader(ctx context.Context, baseURL, token, projectPath string) (*GitlabUploader, error) {
+	client, err := gitlab.NewClient(token, gitlab.WithBaseURL(baseURL))
+	if err != nil {
+		return nil, err
+	}
+	return &GitlabUploader{
+		ctx:         ctx,
+		client:      client,
+		token:       token,
+		projectPath: projectPath,
+		milestones:  make(map[string]int),
+		labels:      make(map[string]bool),
+		issues:      make(map[int64]int),
+	}, nil
+}
+
+// MaxBatchInsertSize returns the table's max batch insert size
+func (g *GitlabUploader) MaxBatchInsertSize(tp string) int {
+	return 100
+}
+
+// CreateRepo creates the project in its namespace if it does not exist and pushes the git data to it
+func (g *GitlabUploader) CreateRepo(repo *base.Repository, opts base.MigrateOptions) error {
+	project, resp, err := g.client.Projects.GetProject(g.projectPath, nil, gitlab.WithContext(g.ctx))
+	if err != nil {
+		if resp == nil || resp.StatusCode != http.StatusNotFound {
+			return err
+		}
+		namespace, _, err := g.client.Namespaces.GetNamespace(path.Dir(g.projectPath), gitlab.WithContext(g.ctx))
+		if err != nil {
+			return fmt.Errorf("get namespace: %v", err)
+		}
+		name := path.Base(g.projectPath)
+		visibility := gitlab.PublicVisibility
+		if opts.Private {
+			visibility = gitlab.PrivateVisibility
+		}
+		project, _, err = g.client.Projects.CreateProject(&gitlab.CreateProjectOptions{
+			Name:        &name,
+			Path:        &name,
+			NamespaceID: &namespace.ID,
+			Description: &repo.Description,
+			Visibility:  &visibility,
+		}, gitlab.WithContext(g.ctx))
+		if err != nil {
+			return fmt.Errorf("create project: %v", err)
+		}
+	}
+	g.projectID = project.ID
+	g.webURL = project.WebURL
+
+	if err := pushGitData(g.ctx, repo.CloneURL, project.HTTPURLToRepo, g.token); err != nil {
+		return err
+	}
+	if repo.DefaultBranch != "" && repo.DefaultBranch != project.DefaultBranch {
+		if _, _, err := g.client.Projects.EditProject(g.projectID, &gitlab.EditProjectOptions{DefaultBranch: &repo.DefaultBranch}, gitlab.WithContext(g.ctx)); err != nil {
+			return fmt.Errorf("set default branch: %v", err)
+		}
+	}
+	return nil
+}
+
+// CreateTopics replaces the topics of the project
+func (g *GitlabUploader) CreateTopics(topics ...string) error {
+	_, _, err := g.client.Projects.EditProject(g.projectID, &gitlab.EditProjectOptions{TagList: &topics}, gitlab.WithContext(g.ctx))
+	return err
+}
+
+// CreateMilestones creates the milestones which have not been created yet
+func (g *GitlabUploader) CreateMilestones(milestones ...*base.Milestone) error {
+	for _, milestone := range milestones {
+		if _, ok := g.milestones[milestone.Title]; ok {
+			continue
+		}
+		opts := &gitlab.CreateMilestoneOptions{
+			Title:       &milestone.Title,
+			Description: &milestone.Description,
+		}
+		if milestone.Deadline != nil {
+			deadline := gitlab.ISOTime(*milestone.Deadline)
+			opts.DueDate = &deadline
+		}
+		created, _, err := g.client.Milestones.CreateMilestone(g.projectID, opts, gitlab.WithContext(g.ctx))
+		if err != nil {
+			return fmt.Errorf("create milestone %s: %v", milestone.Title, err)
+		}
+		g.milestones[milestone.Title] = created.ID
+
+		if milestone.State == "closed" {
+			stateEvent := "close"
+			if _, _, err := g.client.Milestones.UpdateMilestone(g.projectID, created.ID, &gitlab.UpdateMilestoneOptions{StateEvent: &stateEvent}, gitlab.WithContext(g.ctx)); err != nil {
+				return fmt.Errorf("close milestone %s: %v", milestone.Title, err)
+			}
+		}
+	}
+	return nil
+}
+
+// CreateLabels creates the labels which have not been created yet, the ones which already exist in the project
+// are kept
+func (g *GitlabUploader) CreateLabels(labels ...*base.Label) error {
+	for _, label := range labels {
+		if g.labels[label.Name] {
+			continue
+		}
+		color := "#" + strings.TrimPrefix(label.Color, "#")
+		_, resp, err := g.client.Labels.CreateLabel(g.projectID, &gitlab.CreateLabelOptions{
+			Name:        &label.Name,
+			Color:       &color,
+			Description: &label.Description,
+		}, gitlab.WithContext(g.ctx))
+		if err != nil {
+			if resp == nil || resp.StatusCode != http.StatusConflict {
+				return fmt.Errorf("create label %s: %v", label.Name, err)
+			}
+			log.Warn("Label %s already exists in %s, kept", label.Name, g.projectPath)
+		}
+		g.labels[label.Name] = true
+	}
+	return nil
+}
+
+// CreateReleases creates the releases with links to their assets, which are uploaded to the project. GitLab has no
+// draft releases, so the drafts are skipped.
+func (g *GitlabUploader) CreateReleases(releases ...*base.Release) error {
+	for _, release := range releases {
+		if release.Draft {
+			log.Warn("Release %s is a draft, which GitLab does not support, skipped", release.TagName)
+			continue
+		}
+		opts := &gitlab.CreateReleaseOptions{
+			Name:        &release.Name,
+			TagName:     &release.TagName,
+			Description: &release.Body,
+		}
+		if release.TargetCommitish != "" {
+			opts.Ref = &release.TargetCommitish
+		}
+		if _, _, err := g.client.Releases.CreateRelease(g.projectID, opts, gitlab.WithContext(g.ctx)); err != nil {
+			return fmt.Errorf("create release %s: %v", release.TagName, err)
+		}
+
+		for _, asset := range release.Assets {
+			if err := g.uploadReleaseAsset(release.TagName, asset); err != nil {
+				return fmt.Errorf("upload asset %s of release %s: %v", asset.Name, release.TagName, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (g *GitlabUploader) uploadReleaseAsset(tagName string, asset *base.ReleaseAsset) error {
+	f, cleanup, err := writeReleaseAsset(asset)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	uploaded, _, err := g.client.Projects.UploadFile(g.projectID, f.Name(), gitlab.WithContext(g.ctx))
+	if err != nil {
+		return err
+	}
+	link := strings.TrimSuffix(g.webURL, "/") + uploaded.URL
+	_, _, err = g.client.ReleaseLinks.CreateReleaseLink(g.projectID, tagName, &gitlab.CreateReleaseLinkOptions{
+		Name: &asset.Name,
+		URL:  &link,
+	}, gitlab.WithContext(g.ctx))
+	return err
+}
+
+// SyncTags does nothing as the tags are pushed with the git data
+func (g *GitlabUploader) SyncTags() error {
+	return nil
+}
+
+// CreateIssues creates the issues, closed if they are, and records their IIDs for their comments
+func (g *GitlabUploader) CreateIssues(issues ...*base.Issue) error {
+	for _, issue := range issues {
+		description := exportedContent(issue.PosterName, issue.Created, issue.Content)
+		labels := make(gitlab.Labels, 0, len(issue.Labels))
+		for _, label := range issue.Labels {
+			labels = append(labels, label.Name)
+		}
+		opts := &gitlab.CreateIssueOptions{
+			Title:       &issue.Title,
+			Description: &description,
+			Labels:      labels,
+			// only honored for the administrators and the owners of the project
+			CreatedAt: &issue.Created,
+		}
+		if id, ok := g.milestones[issue.Milestone]; ok {
+			opts.MilestoneID = &id
+		}
+
+		created, _, err := g.client.Issues.CreateIssue(g.projectID, opts, gitlab.WithContext(g.ctx))
+		if err != nil {
+			return fmt.Errorf("create issue #%d: %v", issue.Number, err)
+		}
+		g.issues[issue.Number] = created.IID
+
+		if issue.State == "closed" {
+			stateEvent := "close"
+			if _, _, err := g.client.Issues.UpdateIssue(g.projectID, created.IID, &gitlab.UpdateIssueOptions{StateEvent: &stateEvent}, gitlab.WithContext(g.ctx)); err != nil {
+				return fmt.Errorf("close issue #%d: %v", issue.Number, err)
+			}
+		}
+	}
+	return nil
+}
+
+// CreateComments creates the comments of the created issues as notes
+func (g *GitlabUploader) CreateComments(comments ...*base.Comment) error {
+	for _, comment := range comments {
+		iid, ok := g.issues[comment.IssueIndex]
+		if !ok {
+			log.Warn("Issue #%d was not exported, its comment is skipped", comment.IssueIndex)
+			continue
+		}
+		body := exportedContent(comment.PosterName, comment.Created, comment.Content)
+		if _, _, err := g.client.Notes.CreateIssueNote(g.projectID, iid, &gitlab.CreateIssueNoteOptions{
+			Body:      &body,
+			CreatedAt: &comment.Created,
+		}, gitlab.WithContext(g.ctx)); err != nil {
+			return fmt.Errorf("create comment of issue #%d: %v", comment.IssueIndex, err)
+		}
+	}
+	return nil
+}
+
+// CreatePullRequests is not supported
+func (g *GitlabUploader) CreatePullRequests(prs ...*base.PullRequest) error {
+	return base.ErrNotSupported{Entity: "PullRequests"}
+}
+
+// CreateReviews is not supported
+func (g *GitlabUploader) CreateReviews(reviews ...*base.Review) error {
+	return base.ErrNotSupported{Entity: "Reviews"}
+}
+
+// Rollback does nothing, what was exported is left in the project
+func (g *GitlabUploader) Rollback() error {
+	return nil
+}
+
+// Finish does nothing
+func (g *GitlabUploader) Finish() error {
+	return nil
+}
+
+// Close does nothing
+func (g *GitlabUploader) Close() {
+}
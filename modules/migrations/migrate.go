@@ -121,6 +121,9 @@ func MigrateRepository(ctx context.Context, doer *models.User, ownerName string,
 		}
 		return nil, err
 	}
+	if opts.ConvertCI && !opts.Mirror {
+		convertCIConfig(doer, uploader.repo)
+	}
 	return uploader.repo, nil
 }
 
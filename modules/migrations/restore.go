@@ -13,6 +13,7 @@ import (
 	"strconv"
 
 	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/util"
 
 	"gopkg.in/yaml.v2"
 )
@@ -77,13 +78,22 @@ func (r *RepositoryRestorer) GetRepoInfo() (*base.Repository, error) {
 
 	isPrivate, _ := strconv.ParseBool(opts["is_private"])
 
+	// the git data is cloned from the dump when it contains it, rather than from its original remote
+	cloneURL := opts["clone_addr"]
+	gitPath := filepath.Join(r.baseDir, "git")
+	if isDir, err := util.IsDir(gitPath); err != nil {
+		return nil, err
+	} else if isDir {
+		cloneURL = gitPath
+	}
+
 	return &base.Repository{
 		Owner:         r.repoOwner,
 		Name:          r.repoName,
 		IsPrivate:     isPrivate,
 		Description:   opts["description"],
 		OriginalURL:   opts["original_url"],
-		CloneURL:      opts["clone_addr"],
+		CloneURL:      cloneURL,
 		DefaultBranch: opts["default_branch"],
 	}, nil
 }
@@ -98,6 +108,10 @@ func (r *RepositoryRestorer) GetTopics() ([]string, error) {
 
 	bs, err := ioutil.ReadFile(p)
 	if err != nil {
+		// the dumps of the repositories without topics have no topic file
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
 		return nil, err
 	}
 
@@ -185,6 +199,15 @@ func (r *RepositoryRestorer) GetLabels() ([]*base.Label, error) {
 	return labels, nil
 }
 
+// localizeAttachments points the attachments to the files of the dump
+func (r *RepositoryRestorer) localizeAttachments(attachments []*base.Attachment) {
+	for _, attachment := range attachments {
+		if attachment.DownloadURL != nil {
+			*attachment.DownloadURL = "file://" + filepath.Join(r.baseDir, *attachment.DownloadURL)
+		}
+	}
+}
+
 // GetIssues returns issues according start and limit
 func (r *RepositoryRestorer) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
 	var issues = make([]*base.Issue, 0, 10)
@@ -206,6 +229,9 @@ func (r *RepositoryRestorer) GetIssues(page, perPage int) ([]*base.Issue, bool,
 	if err != nil {
 		return nil, false, err
 	}
+	for _, issue := range issues {
+		r.localizeAttachments(issue.Attachments)
+	}
 	return issues, true, nil
 }
 
@@ -230,6 +256,9 @@ func (r *RepositoryRestorer) GetComments(issueNumber int64) ([]*base.Comment, er
 	if err != nil {
 		return nil, err
 	}
+	for _, comment := range comments {
+		r.localizeAttachments(comment.Attachments)
+	}
 	return comments, nil
 }
 
@@ -255,7 +284,10 @@ func (r *RepositoryRestorer) GetPullRequests(page, perPage int) ([]*base.PullReq
 		return nil, false, err
 	}
 	for _, pr := range pulls {
-		pr.PatchURL = "file://" + filepath.Join(r.baseDir, pr.PatchURL)
+		if pr.PatchURL != "" {
+			pr.PatchURL = "file://" + filepath.Join(r.baseDir, pr.PatchURL)
+		}
+		r.localizeAttachments(pr.Attachments)
 	}
 	return pulls, true, nil
 }
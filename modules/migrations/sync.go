@@ -0,0 +1,281 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// IsSyncSupported returns true if what was created on the upstream of a repository since its migration can be
+// pulled in, which needs a downloader listing the issues by creation time
+func IsSyncSupported(repo *models.Repository) bool {
+	if repo.IsMirror || repo.IsArchived {
+		return false
+	}
+	return repo.OriginalServiceType == structs.GithubService || repo.OriginalServiceType == structs.GitlabService
+}
+
+// SyncMigratedRepository pulls in the issues, comments and releases created on the upstream of a repository migrated
+// from GitHub or GitLab since the last sync, or since its migration for the first one, with the options of the
+// migration. The progress is recorded per entity in the migration states, which an interrupted sync resumes from.
+func SyncMigratedRepository(ctx context.Context, doer *models.User, repo *models.Repository) error {
+	if !IsSyncSupported(repo) {
+		return fmt.Errorf("%s cannot be synced with its upstream", repo.FullName())
+	}
+	t, err := models.GetMigratingTask(repo.ID)
+	if err != nil {
+		return err
+	}
+	if t.Status != structs.TaskStatusFinished {
+		return fmt.Errorf("the migration of %s is not finished", repo.FullName())
+	}
+	opts, err := t.MigrateConfig()
+	if err != nil {
+		return err
+	}
+	if err := IsMigrateURLAllowed(opts.CloneAddr, doer); err != nil {
+		return err
+	}
+	if err := repo.GetOwner(); err != nil {
+		return err
+	}
+
+	downloader, err := newDownloader(ctx, repo.OwnerName, *opts)
+	if err != nil {
+		return err
+	}
+	uploader := NewGiteaLocalUploader(ctx, doer, repo.OwnerName, repo.Name)
+	uploader.gitServiceType = opts.GitServiceType
+	if err := uploader.loadRepo(repo); err != nil {
+		return err
+	}
+	defer uploader.Close()
+
+	states, err := models.GetMigrationStates(repo.ID)
+	if err != nil {
+		return err
+	}
+	// whatever was created before the migration started was migrated
+	cursor := func(entity string) timeutil.TimeStamp {
+		if state, ok := states[entity]; ok {
+			return state.CursorUnix
+		}
+		return t.StartTime
+	}
+	// the entities created during the sync are left to the next one
+	until := timeutil.TimeStampNow()
+
+	if opts.Releases {
+		log.Trace("syncing releases of %s", repo.FullName())
+		if err := syncReleases(ctx, downloader, uploader, *opts, cursor(models.MigrationStateRelease), until); err != nil {
+			return util.URLSanitizedError(err, opts.CloneAddr)
+		}
+	}
+	if opts.Issues {
+		log.Trace("syncing issues of %s", repo.FullName())
+		if err := syncIssues(downloader, uploader, opts.Comments, cursor(models.MigrationStateIssue), cursor(models.MigrationStateComment), until); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncReleases fetches the tags of the upstream and creates the releases created after cursor whose tag has none yet
+func syncReleases(ctx context.Context, downloader base.Downloader, uploader *GiteaLocalUploader, opts base.MigrateOptions, cursor, until timeutil.TimeStamp) error {
+	releases, err := downloader.GetReleases()
+	if err != nil {
+		if !base.IsErrNotSupported(err) {
+			return err
+		}
+		log.Warn("syncing releases is not supported, ignored")
+		return nil
+	}
+
+	info, err := downloader.GetRepoInfo()
+	if err != nil {
+		return err
+	}
+	cloneURL, err := downloader.FormatCloneURL(opts, info.CloneURL)
+	if err != nil {
+		return err
+	}
+	if _, err := git.NewCommandContext(ctx, "fetch", "--quiet", "--tags", cloneURL).
+		RunInDirTimeout(time.Duration(setting.Git.Timeout.Migrate)*time.Second, uploader.repo.RepoPath()); err != nil {
+		return fmt.Errorf("git fetch: %v", err)
+	}
+
+	newReleases := make([]*base.Release, 0, len(releases))
+	for _, release := range releases {
+		created := timeutil.TimeStamp(release.Created.Unix())
+		if created <= cursor || created > until {
+			continue
+		}
+		if _, err := models.GetRelease(uploader.repo.ID, release.TagName); err == nil {
+			continue
+		} else if !models.IsErrReleaseNotExist(err) {
+			return err
+		}
+		newReleases = append(newReleases, release)
+	}
+
+	relBatchSize := uploader.MaxBatchInsertSize("release")
+	for len(newReleases) > 0 {
+		if len(newReleases) < relBatchSize {
+			relBatchSize = len(newReleases)
+		}
+		if err := uploader.CreateReleases(newReleases[:relBatchSize]...); err != nil {
+			return err
+		}
+		newReleases = newReleases[relBatchSize:]
+	}
+	if err := uploader.SyncTags(); err != nil {
+		return err
+	}
+	return models.UpdateMigrationState(uploader.repo.ID, models.MigrationStateRelease, until)
+}
+
+// syncIssues creates the issues created after issueCursor and, if withComments is true, the comments created after
+// commentCursor of the issues updated since then. The upstream issues whose number is already used are skipped.
+func syncIssues(downloader base.Downloader, uploader *GiteaLocalUploader, withComments bool, issueCursor, commentCursor, until timeutil.TimeStamp) error {
+	repoID := uploader.repo.ID
+	issueBatchSize := uploader.MaxBatchInsertSize("issue")
+	commentBatchSize := uploader.MaxBatchInsertSize("comment")
+	for i := 1; ; i++ {
+		issues, isEnd, err := downloader.GetIssues(i, issueBatchSize)
+		if err != nil {
+			if !base.IsErrNotSupported(err) {
+				return err
+			}
+			log.Warn("syncing issues is not supported, ignored")
+			return nil
+		}
+
+		newIssues := make([]*base.Issue, 0, len(issues))
+		// the issues whose number is used by another one, whose comments must not be added to it
+		conflicts := make(map[int64]bool)
+		for _, issue := range issues {
+			created := timeutil.TimeStamp(issue.Created.Unix())
+			if created <= issueCursor || created > until {
+				continue
+			}
+			if _, err := models.GetIssueByIndex(repoID, issue.Number); err == nil {
+				log.Warn("Issue #%d of %s already exists, the upstream one is skipped", issue.Number, uploader.repo.FullName())
+				conflicts[issue.Number] = true
+				continue
+			} else if !models.IsErrIssueNotExist(err) {
+				return err
+			}
+			newIssues = append(newIssues, issue)
+		}
+		if len(newIssues) > 0 {
+			if err := uploader.CreateIssues(newIssues...); err != nil {
+				return err
+			}
+		}
+
+		if withComments {
+			var allComments = make([]*base.Comment, 0, commentBatchSize)
+			for _, issue := range issues {
+				if conflicts[issue.Number] || timeutil.TimeStamp(issue.Updated.Unix()) <= commentCursor ||
+					timeutil.TimeStamp(issue.Created.Unix()) > until {
+					continue
+				}
+				comments, err := newComments(downloader, repoID, issue.Number, commentCursor, until)
+				if err != nil {
+					if !base.IsErrNotSupported(err) {
+						return err
+					}
+					log.Warn("syncing comments is not supported, ignored")
+					withComments = false
+					break
+				}
+				allComments = append(allComments, comments...)
+
+				if len(allComments) >= commentBatchSize {
+					if err := uploader.CreateComments(allComments[:commentBatchSize]...); err != nil {
+						return err
+					}
+					allComments = allComments[commentBatchSize:]
+				}
+			}
+			if len(allComments) > 0 {
+				if err := uploader.CreateComments(allComments...); err != nil {
+					return err
+				}
+			}
+		}
+
+		// the issues are listed by creation time, so the ones up to the last of the page are synced
+		if len(issues) > 0 {
+			last := timeutil.TimeStamp(issues[len(issues)-1].Created.Unix())
+			if last > until {
+				last = until
+			}
+			if last > issueCursor {
+				if err := models.UpdateMigrationState(repoID, models.MigrationStateIssue, last); err != nil {
+					return err
+				}
+			}
+		}
+
+		if isEnd {
+			break
+		}
+	}
+
+	if err := models.UpdateMigrationState(repoID, models.MigrationStateIssue, until); err != nil {
+		return err
+	}
+	if withComments {
+		return models.UpdateMigrationState(repoID, models.MigrationStateComment, until)
+	}
+	return nil
+}
+
+// newComments returns the comments of an upstream issue created after cursor which are not in the issue yet, an
+// interrupted sync may have added some of them already. Nothing is returned for the issues deleted since the migration.
+func newComments(downloader base.Downloader, repoID, issueNumber int64, cursor, until timeutil.TimeStamp) ([]*base.Comment, error) {
+	issue, err := models.GetIssueByIndex(repoID, issueNumber)
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	comments, err := downloader.GetComments(issueNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	cms, err := models.FindComments(models.FindCommentsOptions{IssueID: issue.ID, Type: models.CommentTypeComment})
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[timeutil.TimeStamp]bool, len(cms))
+	for _, cm := range cms {
+		existing[cm.CreatedUnix] = true
+	}
+
+	newComments := make([]*base.Comment, 0, len(comments))
+	for _, comment := range comments {
+		created := timeutil.TimeStamp(comment.Created.Unix())
+		if created <= cursor || created > until || existing[created] {
+			continue
+		}
+		newComments = append(newComments, comment)
+	}
+	return newComments, nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// syncTestDownloader lists fixed issues and comments
+type syncTestDownloader struct {
+	base.NullDownloader
+	issues   []*base.Issue
+	comments map[int64][]*base.Comment
+}
+
+func (d *syncTestDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	if page > 1 {
+		return nil, true, nil
+	}
+	return d.issues, true, nil
+}
+
+func (d *syncTestDownloader) GetComments(issueNumber int64) ([]*base.Comment, error) {
+	return d.comments[issueNumber], nil
+}
+
+func TestSyncIssues(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 1}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	uploader := NewGiteaLocalUploader(context.Background(), doer, "user2", repo.Name)
+	uploader.repo = repo
+
+	at := func(unix int64) time.Time { return time.Unix(unix, 0) }
+	downloader := &syncTestDownloader{
+		issues: []*base.Issue{
+			{Number: 1, Title: "migrated", PosterName: "upstream", Created: at(946684800), Updated: at(1600000200)},
+			{Number: 4, Title: "conflicting", PosterName: "upstream", Created: at(1600000100), Updated: at(1600000100)},
+			{Number: 20, Title: "new", PosterName: "upstream", State: "open", Created: at(1600000300), Updated: at(1600000400)},
+		},
+		comments: map[int64][]*base.Comment{
+			1: {
+				{IssueIndex: 1, PosterName: "upstream", Content: "old", Created: at(1500000000)},
+				{IssueIndex: 1, PosterName: "upstream", Content: "new on migrated", Created: at(1600000200)},
+			},
+			4:  {{IssueIndex: 4, PosterName: "upstream", Content: "on conflicting", Created: at(1600000100)}},
+			20: {{IssueIndex: 20, PosterName: "upstream", Content: "new on new", Created: at(1600000400)}},
+		},
+	}
+
+	until := timeutil.TimeStamp(1700000000)
+	for i := 0; i < 2; i++ {
+		// a sync interrupted before its cursors were updated adds nothing twice when repeated
+		assert.NoError(t, syncIssues(downloader, uploader, true, 1600000000, 1600000000, until))
+
+		issue, err := models.GetIssueByIndex(repo.ID, 20)
+		assert.NoError(t, err)
+		assert.Equal(t, "new", issue.Title)
+		assert.Equal(t, "upstream", issue.OriginalAuthor)
+
+		models.AssertCount(t, &models.Comment{IssueID: issue.ID, Content: "new on new"}, 1)
+		models.AssertCount(t, &models.Comment{IssueID: 1, Content: "new on migrated"}, 1)
+		models.AssertNotExistsBean(t, &models.Comment{IssueID: 1, Content: "old"})
+		models.AssertNotExistsBean(t, &models.Comment{Content: "on conflicting"})
+		models.AssertNotExistsBean(t, &models.Issue{RepoID: repo.ID, Title: "conflicting"})
+	}
+
+	states, err := models.GetMigrationStates(repo.ID)
+	assert.NoError(t, err)
+	assert.Len(t, states, 2)
+	assert.Equal(t, until, states[models.MigrationStateIssue].CursorUnix)
+	assert.Equal(t, until, states[models.MigrationStateComment].CursorUnix)
+}
+
+func TestIsSyncSupported(t *testing.T) {
+	assert.True(t, IsSyncSupported(&models.Repository{OriginalServiceType: structs.GithubService}))
+	assert.True(t, IsSyncSupported(&models.Repository{OriginalServiceType: structs.GitlabService}))
+	assert.False(t, IsSyncSupported(&models.Repository{OriginalServiceType: structs.GithubService, IsMirror: true}))
+	assert.False(t, IsSyncSupported(&models.Repository{OriginalServiceType: structs.PlainGitService}))
+}
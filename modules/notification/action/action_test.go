@@ -5,6 +5,7 @@
 package action
 
 import (
+	"context"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -40,7 +41,7 @@ func TestRenameRepoAction(t *testing.T) {
 	}
 	models.AssertNotExistsBean(t, actionBean)
 
-	NewNotifier().NotifyRenameRepository(user, repo, oldRepoName)
+	NewNotifier().NotifyRenameRepository(context.Background(), user, repo, oldRepoName)
 
 	models.AssertExistsAndLoadBean(t, actionBean)
 	models.CheckConsistencyFor(t, &models.Action{})
@@ -0,0 +1,102 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"context"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/repository"
+	actions_service "code.gitea.io/gitea/services/actions"
+)
+
+type actionsNotifier struct {
+	base.NullNotifier
+}
+
+var (
+	_ base.Notifier = &actionsNotifier{}
+)
+
+// NewNotifier create a new actionsNotifier notifier
+func NewNotifier() base.Notifier {
+	return &actionsNotifier{}
+}
+
+func (a *actionsNotifier) NotifyPushCommits(ctx context.Context, pusher *models.User, repo *models.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
+	if opts.IsDelRef() || !(opts.IsBranch() || opts.IsTag()) {
+		return
+	}
+
+	title := opts.RefName()
+	if len(commits.Commits) > 0 {
+		title = strings.SplitN(strings.TrimSpace(commits.Commits[0].Message), "\n", 2)[0]
+	}
+	if err := actions_service.CreateRuns(&actions_service.TriggerOptions{
+		Repo:      repo,
+		Doer:      pusher,
+		Event:     actions_service.EventPush,
+		Ref:       opts.RefFullName,
+		CommitSHA: opts.NewCommitID,
+		Title:     title,
+		MatchRef:  opts.RefName(),
+		IsTag:     opts.IsTag(),
+	}); err != nil {
+		log.Error("CreateRuns [repo_id: %d, ref: %s]: %v", repo.ID, opts.RefFullName, err)
+	}
+}
+
+func (a *actionsNotifier) NotifyNewPullRequest(ctx context.Context, pr *models.PullRequest, mentions []*models.User) {
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("pr.LoadIssue: %v", err)
+		return
+	}
+	if err := pr.Issue.LoadPoster(); err != nil {
+		log.Error("pr.Issue.LoadPoster: %v", err)
+		return
+	}
+	createPullRequestRuns(pr.Issue.Poster, pr)
+}
+
+func (a *actionsNotifier) NotifyPullRequestSynchronized(ctx context.Context, doer *models.User, pr *models.PullRequest) {
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("pr.LoadIssue: %v", err)
+		return
+	}
+	createPullRequestRuns(doer, pr)
+}
+
+func createPullRequestRuns(doer *models.User, pr *models.PullRequest) {
+	if err := pr.LoadBaseRepo(); err != nil {
+		log.Error("pr.LoadBaseRepo: %v", err)
+		return
+	}
+	if err := pr.LoadHeadRepo(); err != nil {
+		log.Error("pr.LoadHeadRepo: %v", err)
+		return
+	}
+	if pr.HeadRepo == nil {
+		// the head repository was deleted
+		return
+	}
+
+	// the head branch is read from the head repository because it may not have been pushed to the base repository yet
+	if err := actions_service.CreateRuns(&actions_service.TriggerOptions{
+		Repo:      pr.BaseRepo,
+		HeadRepo:  pr.HeadRepo,
+		Doer:      doer,
+		Event:     actions_service.EventPullRequest,
+		Ref:       pr.GetGitRefName(),
+		CommitSHA: git.BranchPrefix + pr.HeadBranch,
+		Title:     pr.Issue.Title,
+		MatchRef:  pr.BaseBranch,
+	}); err != nil {
+		log.Error("CreateRuns [pr_id: %d]: %v", pr.ID, err)
+	}
+}
@@ -5,7 +5,10 @@
 package base
 
 import (
+	"context"
+
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/repository"
 )
 
@@ -13,50 +16,56 @@ import (
 type Notifier interface {
 	Run()
 
-	NotifyCreateRepository(doer *models.User, u *models.User, repo *models.Repository)
-	NotifyMigrateRepository(doer *models.User, u *models.User, repo *models.Repository)
-	NotifyDeleteRepository(doer *models.User, repo *models.Repository)
-	NotifyForkRepository(doer *models.User, oldRepo, repo *models.Repository)
-	NotifyRenameRepository(doer *models.User, repo *models.Repository, oldRepoName string)
-	NotifyTransferRepository(doer *models.User, repo *models.Repository, oldOwnerName string)
-
-	NotifyNewIssue(issue *models.Issue, mentions []*models.User)
-	NotifyIssueChangeStatus(*models.User, *models.Issue, *models.Comment, bool)
-	NotifyIssueChangeMilestone(doer *models.User, issue *models.Issue, oldMilestoneID int64)
-	NotifyIssueChangeAssignee(doer *models.User, issue *models.Issue, assignee *models.User, removed bool, comment *models.Comment)
-	NotifyPullReviewRequest(doer *models.User, issue *models.Issue, reviewer *models.User, isRequest bool, comment *models.Comment)
-	NotifyIssueChangeContent(doer *models.User, issue *models.Issue, oldContent string)
-	NotifyIssueClearLabels(doer *models.User, issue *models.Issue)
-	NotifyIssueChangeTitle(doer *models.User, issue *models.Issue, oldTitle string)
-	NotifyIssueChangeRef(doer *models.User, issue *models.Issue, oldRef string)
-	NotifyIssueChangeLabels(doer *models.User, issue *models.Issue,
+	NotifyCreateRepository(ctx context.Context, doer *models.User, u *models.User, repo *models.Repository)
+	NotifyMigrateRepository(ctx context.Context, doer *models.User, u *models.User, repo *models.Repository)
+	NotifyDeleteRepository(ctx context.Context, doer *models.User, repo *models.Repository)
+	NotifyForkRepository(ctx context.Context, doer *models.User, oldRepo, repo *models.Repository)
+	NotifyRenameRepository(ctx context.Context, doer *models.User, repo *models.Repository, oldRepoName string)
+	NotifyTransferRepository(ctx context.Context, doer *models.User, repo *models.Repository, oldOwnerName string)
+
+	NotifyNewIssue(ctx context.Context, issue *models.Issue, mentions []*models.User)
+	NotifyIssueChangeStatus(ctx context.Context, doer *models.User, issue *models.Issue, actionComment *models.Comment, closeOrReopen bool)
+	NotifyIssueChangeMilestone(ctx context.Context, doer *models.User, issue *models.Issue, oldMilestoneID int64)
+	NotifyIssueChangeAssignee(ctx context.Context, doer *models.User, issue *models.Issue, assignee *models.User, removed bool, comment *models.Comment)
+	NotifyPullReviewRequest(ctx context.Context, doer *models.User, issue *models.Issue, reviewer *models.User, isRequest bool, comment *models.Comment)
+	NotifyIssueChangeContent(ctx context.Context, doer *models.User, issue *models.Issue, oldContent string)
+	NotifyIssueClearLabels(ctx context.Context, doer *models.User, issue *models.Issue)
+	NotifyIssueChangeTitle(ctx context.Context, doer *models.User, issue *models.Issue, oldTitle string)
+	NotifyIssueChangeRef(ctx context.Context, doer *models.User, issue *models.Issue, oldRef string)
+	NotifyIssueChangeLabels(ctx context.Context, doer *models.User, issue *models.Issue,
 		addedLabels []*models.Label, removedLabels []*models.Label)
 
-	NotifyNewPullRequest(pr *models.PullRequest, mentions []*models.User)
-	NotifyMergePullRequest(*models.PullRequest, *models.User)
-	NotifyPullRequestSynchronized(doer *models.User, pr *models.PullRequest)
-	NotifyPullRequestReview(pr *models.PullRequest, review *models.Review, comment *models.Comment, mentions []*models.User)
-	NotifyPullRequestCodeComment(pr *models.PullRequest, comment *models.Comment, mentions []*models.User)
-	NotifyPullRequestChangeTargetBranch(doer *models.User, pr *models.PullRequest, oldBranch string)
-	NotifyPullRequestPushCommits(doer *models.User, pr *models.PullRequest, comment *models.Comment)
-	NotifyPullRevieweDismiss(doer *models.User, review *models.Review, comment *models.Comment)
+	NotifyNewPullRequest(ctx context.Context, pr *models.PullRequest, mentions []*models.User)
+	NotifyMergePullRequest(ctx context.Context, pr *models.PullRequest, doer *models.User)
+	NotifyPullRequestSynchronized(ctx context.Context, doer *models.User, pr *models.PullRequest)
+	NotifyPullRequestReview(ctx context.Context, pr *models.PullRequest, review *models.Review, comment *models.Comment, mentions []*models.User)
+	NotifyPullRequestCodeComment(ctx context.Context, pr *models.PullRequest, comment *models.Comment, mentions []*models.User)
+	NotifyPullRequestChangeTargetBranch(ctx context.Context, doer *models.User, pr *models.PullRequest, oldBranch string)
+	NotifyPullRequestPushCommits(ctx context.Context, doer *models.User, pr *models.PullRequest, comment *models.Comment)
+	NotifyPullRevieweDismiss(ctx context.Context, doer *models.User, review *models.Review, comment *models.Comment)
+	NotifyPullRequestChecked(ctx context.Context, pr *models.PullRequest)
 
-	NotifyCreateIssueComment(doer *models.User, repo *models.Repository,
+	NotifyCreateIssueComment(ctx context.Context, doer *models.User, repo *models.Repository,
 		issue *models.Issue, comment *models.Comment, mentions []*models.User)
-	NotifyUpdateComment(*models.User, *models.Comment, string)
-	NotifyDeleteComment(*models.User, *models.Comment)
+	NotifyUpdateComment(ctx context.Context, doer *models.User, c *models.Comment, oldContent string)
+	NotifyDeleteComment(ctx context.Context, doer *models.User, c *models.Comment)
+
+	NotifyNewRelease(ctx context.Context, rel *models.Release)
+	NotifyUpdateRelease(ctx context.Context, doer *models.User, rel *models.Release)
+	NotifyDeleteRelease(ctx context.Context, doer *models.User, rel *models.Release)
+
+	NotifyPushCommits(ctx context.Context, pusher *models.User, repo *models.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits)
+	NotifyCreateRef(ctx context.Context, doer *models.User, repo *models.Repository, refType, refFullName string)
+	NotifyDeleteRef(ctx context.Context, doer *models.User, repo *models.Repository, refType, refFullName string)
 
-	NotifyNewRelease(rel *models.Release)
-	NotifyUpdateRelease(doer *models.User, rel *models.Release)
-	NotifyDeleteRelease(doer *models.User, rel *models.Release)
+	NotifySyncPushCommits(ctx context.Context, pusher *models.User, repo *models.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits)
+	NotifySyncCreateRef(ctx context.Context, doer *models.User, repo *models.Repository, refType, refFullName string)
+	NotifySyncDeleteRef(ctx context.Context, doer *models.User, repo *models.Repository, refType, refFullName string)
 
-	NotifyPushCommits(pusher *models.User, repo *models.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits)
-	NotifyCreateRef(doer *models.User, repo *models.Repository, refType, refFullName string)
-	NotifyDeleteRef(doer *models.User, repo *models.Repository, refType, refFullName string)
+	NotifyRepoPendingTransfer(ctx context.Context, doer, newOwner *models.User, repo *models.Repository)
 
-	NotifySyncPushCommits(pusher *models.User, repo *models.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits)
-	NotifySyncCreateRef(doer *models.User, repo *models.Repository, refType, refFullName string)
-	NotifySyncDeleteRef(doer *models.User, repo *models.Repository, refType, refFullName string)
+	NotifyCreateCommitStatus(ctx context.Context, creator *models.User, repo *models.Repository, commit *git.Commit, status *models.CommitStatus)
 
-	NotifyRepoPendingTransfer(doer, newOwner *models.User, repo *models.Repository)
+	NotifyIssueDueDateReminder(ctx context.Context, issue *models.Issue, assignee *models.User)
+	NotifyMilestoneDueDateReminder(ctx context.Context, milestone *models.Milestone, issues []*models.Issue, assignee *models.User)
 }
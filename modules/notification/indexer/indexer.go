@@ -5,6 +5,8 @@
 package indexer
 
 import (
+	"context"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
 	code_indexer "code.gitea.io/gitea/modules/indexer/code"
@@ -29,7 +31,7 @@ func NewNotifier() base.Notifier {
 	return &indexerNotifier{}
 }
 
-func (r *indexerNotifier) NotifyCreateIssueComment(doer *models.User, repo *models.Repository,
+func (r *indexerNotifier) NotifyCreateIssueComment(ctx context.Context, doer *models.User, repo *models.Repository,
 	issue *models.Issue, comment *models.Comment, mentions []*models.User) {
 	if comment.Type == models.CommentTypeComment {
 		if issue.Comments == nil {
@@ -41,19 +43,19 @@ func (r *indexerNotifier) NotifyCreateIssueComment(doer *models.User, repo *mode
 			issue.Comments = append(issue.Comments, comment)
 		}
 
-		issue_indexer.UpdateIssueIndexer(issue)
+		issue_indexer.UpdateIssueIndexer(ctx, issue)
 	}
 }
 
-func (r *indexerNotifier) NotifyNewIssue(issue *models.Issue, mentions []*models.User) {
-	issue_indexer.UpdateIssueIndexer(issue)
+func (r *indexerNotifier) NotifyNewIssue(ctx context.Context, issue *models.Issue, mentions []*models.User) {
+	issue_indexer.UpdateIssueIndexer(ctx, issue)
 }
 
-func (r *indexerNotifier) NotifyNewPullRequest(pr *models.PullRequest, mentions []*models.User) {
-	issue_indexer.UpdateIssueIndexer(pr.Issue)
+func (r *indexerNotifier) NotifyNewPullRequest(ctx context.Context, pr *models.PullRequest, mentions []*models.User) {
+	issue_indexer.UpdateIssueIndexer(ctx, pr.Issue)
 }
 
-func (r *indexerNotifier) NotifyUpdateComment(doer *models.User, c *models.Comment, oldContent string) {
+func (r *indexerNotifier) NotifyUpdateComment(ctx context.Context, doer *models.User, c *models.Comment, oldContent string) {
 	if c.Type == models.CommentTypeComment {
 		var found bool
 		if c.Issue.Comments != nil {
@@ -73,11 +75,11 @@ func (r *indexerNotifier) NotifyUpdateComment(doer *models.User, c *models.Comme
 			}
 		}
 
-		issue_indexer.UpdateIssueIndexer(c.Issue)
+		issue_indexer.UpdateIssueIndexer(ctx, c.Issue)
 	}
 }
 
-func (r *indexerNotifier) NotifyDeleteComment(doer *models.User, comment *models.Comment) {
+func (r *indexerNotifier) NotifyDeleteComment(ctx context.Context, doer *models.User, comment *models.Comment) {
 	if comment.Type == models.CommentTypeComment {
 		if err := comment.LoadIssue(); err != nil {
 			log.Error("LoadIssue: %v", err)
@@ -102,19 +104,19 @@ func (r *indexerNotifier) NotifyDeleteComment(doer *models.User, comment *models
 			}
 		}
 		// reload comments to delete the old comment
-		issue_indexer.UpdateIssueIndexer(comment.Issue)
+		issue_indexer.UpdateIssueIndexer(ctx, comment.Issue)
 	}
 }
 
-func (r *indexerNotifier) NotifyDeleteRepository(doer *models.User, repo *models.Repository) {
-	issue_indexer.DeleteRepoIssueIndexer(repo)
+func (r *indexerNotifier) NotifyDeleteRepository(ctx context.Context, doer *models.User, repo *models.Repository) {
+	issue_indexer.DeleteRepoIssueIndexer(ctx, repo)
 	if setting.Indexer.RepoIndexerEnabled {
 		code_indexer.DeleteRepoFromIndexer(repo)
 	}
 }
 
-func (r *indexerNotifier) NotifyMigrateRepository(doer *models.User, u *models.User, repo *models.Repository) {
-	issue_indexer.UpdateRepoIndexer(repo)
+func (r *indexerNotifier) NotifyMigrateRepository(ctx context.Context, doer *models.User, u *models.User, repo *models.Repository) {
+	issue_indexer.UpdateRepoIndexer(ctx, repo)
 	if setting.Indexer.RepoIndexerEnabled && !repo.IsEmpty {
 		code_indexer.UpdateRepoIndexer(repo)
 	}
@@ -123,7 +125,7 @@ func (r *indexerNotifier) NotifyMigrateRepository(doer *models.User, u *models.U
 	}
 }
 
-func (r *indexerNotifier) NotifyPushCommits(pusher *models.User, repo *models.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
+func (r *indexerNotifier) NotifyPushCommits(ctx context.Context, pusher *models.User, repo *models.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
 	if setting.Indexer.RepoIndexerEnabled && opts.RefFullName == git.BranchPrefix+repo.DefaultBranch {
 		code_indexer.UpdateRepoIndexer(repo)
 	}
@@ -132,7 +134,7 @@ func (r *indexerNotifier) NotifyPushCommits(pusher *models.User, repo *models.Re
 	}
 }
 
-func (r *indexerNotifier) NotifySyncPushCommits(pusher *models.User, repo *models.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
+func (r *indexerNotifier) NotifySyncPushCommits(ctx context.Context, pusher *models.User, repo *models.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
 	if setting.Indexer.RepoIndexerEnabled && opts.RefFullName == git.BranchPrefix+repo.DefaultBranch {
 		code_indexer.UpdateRepoIndexer(repo)
 	}
@@ -141,14 +143,14 @@ func (r *indexerNotifier) NotifySyncPushCommits(pusher *models.User, repo *model
 	}
 }
 
-func (r *indexerNotifier) NotifyIssueChangeContent(doer *models.User, issue *models.Issue, oldContent string) {
-	issue_indexer.UpdateIssueIndexer(issue)
+func (r *indexerNotifier) NotifyIssueChangeContent(ctx context.Context, doer *models.User, issue *models.Issue, oldContent string) {
+	issue_indexer.UpdateIssueIndexer(ctx, issue)
 }
 
-func (r *indexerNotifier) NotifyIssueChangeTitle(doer *models.User, issue *models.Issue, oldTitle string) {
-	issue_indexer.UpdateIssueIndexer(issue)
+func (r *indexerNotifier) NotifyIssueChangeTitle(ctx context.Context, doer *models.User, issue *models.Issue, oldTitle string) {
+	issue_indexer.UpdateIssueIndexer(ctx, issue)
 }
 
-func (r *indexerNotifier) NotifyIssueChangeRef(doer *models.User, issue *models.Issue, oldRef string) {
-	issue_indexer.UpdateIssueIndexer(issue)
+func (r *indexerNotifier) NotifyIssueChangeRef(ctx context.Context, doer *models.User, issue *models.Issue, oldRef string) {
+	issue_indexer.UpdateIssueIndexer(ctx, issue)
 }
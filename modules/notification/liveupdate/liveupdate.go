@@ -0,0 +1,79 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package liveupdate
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/eventsource"
+	"code.gitea.io/gitea/modules/notification/base"
+)
+
+type liveUpdateNotifier struct {
+	base.NullNotifier
+}
+
+var (
+	_ base.Notifier = &liveUpdateNotifier{}
+)
+
+// NewNotifier create a new liveUpdateNotifier notifier, which tells the users viewing an issue
+// or pull request about its changes through their event streams
+func NewNotifier() base.Notifier {
+	return &liveUpdateNotifier{}
+}
+
+func publish(topic eventsource.Topic, typ string, doer *models.User) {
+	var doerID int64
+	if doer != nil {
+		doerID = doer.ID
+	}
+	eventsource.GetManager().Publish(topic, typ, doerID)
+}
+
+func (*liveUpdateNotifier) NotifyCreateIssueComment(ctx context.Context, doer *models.User, repo *models.Repository,
+	issue *models.Issue, comment *models.Comment, mentions []*models.User) {
+	publish(eventsource.IssueTopic(issue.ID), "comment", doer)
+}
+
+func (*liveUpdateNotifier) NotifyIssueChangeStatus(ctx context.Context, doer *models.User, issue *models.Issue, actionComment *models.Comment, isClosed bool) {
+	publish(eventsource.IssueTopic(issue.ID), "comment", doer)
+	if issue.IsPull {
+		publish(eventsource.PullTopic(issue.ID), "status", doer)
+	}
+}
+
+func (*liveUpdateNotifier) NotifyMergePullRequest(ctx context.Context, pr *models.PullRequest, doer *models.User) {
+	publish(eventsource.IssueTopic(pr.IssueID), "comment", doer)
+	publish(eventsource.PullTopic(pr.IssueID), "merged", doer)
+}
+
+func (*liveUpdateNotifier) NotifyPullRequestSynchronized(ctx context.Context, doer *models.User, pr *models.PullRequest) {
+	publish(eventsource.PullTopic(pr.IssueID), "push", doer)
+}
+
+func (*liveUpdateNotifier) NotifyPullRequestReview(ctx context.Context, pr *models.PullRequest, review *models.Review, comment *models.Comment, mentions []*models.User) {
+	publish(eventsource.IssueTopic(pr.IssueID), "comment", review.Reviewer)
+	publish(eventsource.PullTopic(pr.IssueID), "review", review.Reviewer)
+}
+
+func (*liveUpdateNotifier) NotifyPullRequestCodeComment(ctx context.Context, pr *models.PullRequest, comment *models.Comment, mentions []*models.User) {
+	publish(eventsource.IssueTopic(pr.IssueID), "comment", comment.Poster)
+}
+
+func (*liveUpdateNotifier) NotifyPullRevieweDismiss(ctx context.Context, doer *models.User, review *models.Review, comment *models.Comment) {
+	publish(eventsource.IssueTopic(review.IssueID), "comment", doer)
+	publish(eventsource.PullTopic(review.IssueID), "review", doer)
+}
+
+func (*liveUpdateNotifier) NotifyPullRequestChangeTargetBranch(ctx context.Context, doer *models.User, pr *models.PullRequest, oldBranch string) {
+	publish(eventsource.IssueTopic(pr.IssueID), "comment", doer)
+	publish(eventsource.PullTopic(pr.IssueID), "target-branch", doer)
+}
+
+func (*liveUpdateNotifier) NotifyPullRequestChecked(ctx context.Context, pr *models.PullRequest) {
+	publish(eventsource.PullTopic(pr.IssueID), "checked", nil)
+}
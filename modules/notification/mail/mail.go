@@ -5,9 +5,11 @@
 package mail
 
 import (
+	"context"
 	"fmt"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification/base"
 	"code.gitea.io/gitea/services/mailer"
@@ -26,7 +28,7 @@ func NewNotifier() base.Notifier {
 	return &mailNotifier{}
 }
 
-func (m *mailNotifier) NotifyCreateIssueComment(doer *models.User, repo *models.Repository,
+func (m *mailNotifier) NotifyCreateIssueComment(ctx context.Context, doer *models.User, repo *models.Repository,
 	issue *models.Issue, comment *models.Comment, mentions []*models.User) {
 	var act models.ActionType
 	if comment.Type == models.CommentTypeClose {
@@ -46,13 +48,13 @@ func (m *mailNotifier) NotifyCreateIssueComment(doer *models.User, repo *models.
 	}
 }
 
-func (m *mailNotifier) NotifyNewIssue(issue *models.Issue, mentions []*models.User) {
+func (m *mailNotifier) NotifyNewIssue(ctx context.Context, issue *models.Issue, mentions []*models.User) {
 	if err := mailer.MailParticipants(issue, issue.Poster, models.ActionCreateIssue, mentions); err != nil {
 		log.Error("MailParticipants: %v", err)
 	}
 }
 
-func (m *mailNotifier) NotifyIssueChangeStatus(doer *models.User, issue *models.Issue, actionComment *models.Comment, isClosed bool) {
+func (m *mailNotifier) NotifyIssueChangeStatus(ctx context.Context, doer *models.User, issue *models.Issue, actionComment *models.Comment, isClosed bool) {
 	var actionType models.ActionType
 	issue.Content = ""
 	if issue.IsPull {
@@ -74,13 +76,13 @@ func (m *mailNotifier) NotifyIssueChangeStatus(doer *models.User, issue *models.
 	}
 }
 
-func (m *mailNotifier) NotifyNewPullRequest(pr *models.PullRequest, mentions []*models.User) {
+func (m *mailNotifier) NotifyNewPullRequest(ctx context.Context, pr *models.PullRequest, mentions []*models.User) {
 	if err := mailer.MailParticipants(pr.Issue, pr.Issue.Poster, models.ActionCreatePullRequest, mentions); err != nil {
 		log.Error("MailParticipants: %v", err)
 	}
 }
 
-func (m *mailNotifier) NotifyPullRequestReview(pr *models.PullRequest, r *models.Review, comment *models.Comment, mentions []*models.User) {
+func (m *mailNotifier) NotifyPullRequestReview(ctx context.Context, pr *models.PullRequest, r *models.Review, comment *models.Comment, mentions []*models.User) {
 	var act models.ActionType
 	if comment.Type == models.CommentTypeClose {
 		act = models.ActionCloseIssue
@@ -94,13 +96,13 @@ func (m *mailNotifier) NotifyPullRequestReview(pr *models.PullRequest, r *models
 	}
 }
 
-func (m *mailNotifier) NotifyPullRequestCodeComment(pr *models.PullRequest, comment *models.Comment, mentions []*models.User) {
+func (m *mailNotifier) NotifyPullRequestCodeComment(ctx context.Context, pr *models.PullRequest, comment *models.Comment, mentions []*models.User) {
 	if err := mailer.MailMentionsComment(pr, comment, mentions); err != nil {
 		log.Error("MailMentionsComment: %v", err)
 	}
 }
 
-func (m *mailNotifier) NotifyIssueChangeAssignee(doer *models.User, issue *models.Issue, assignee *models.User, removed bool, comment *models.Comment) {
+func (m *mailNotifier) NotifyIssueChangeAssignee(ctx context.Context, doer *models.User, issue *models.Issue, assignee *models.User, removed bool, comment *models.Comment) {
 	// mail only sent to added assignees and not self-assignee
 	if !removed && doer.ID != assignee.ID && assignee.EmailNotifications() == models.EmailNotificationsEnabled {
 		ct := fmt.Sprintf("Assigned #%d.", issue.Index)
@@ -108,14 +110,22 @@ func (m *mailNotifier) NotifyIssueChangeAssignee(doer *models.User, issue *model
 	}
 }
 
-func (m *mailNotifier) NotifyPullReviewRequest(doer *models.User, issue *models.Issue, reviewer *models.User, isRequest bool, comment *models.Comment) {
-	if isRequest && doer.ID != reviewer.ID && reviewer.EmailNotifications() == models.EmailNotificationsEnabled {
+func (m *mailNotifier) NotifyPullReviewRequest(ctx context.Context, doer *models.User, issue *models.Issue, reviewer *models.User, isRequest bool, comment *models.Comment) {
+	if !isRequest || doer.ID == reviewer.ID || reviewer.EmailNotifications() != models.EmailNotificationsEnabled {
+		return
+	}
+	enabled, err := reviewer.IsEmailEventEnabled(models.EmailEventReviewRequest)
+	if err != nil {
+		log.Error("IsEmailEventEnabled: %v", err)
+		return
+	}
+	if enabled {
 		ct := fmt.Sprintf("Requested to review %s.", issue.HTMLURL())
 		mailer.SendIssueAssignedMail(issue, doer, ct, comment, []string{reviewer.Email})
 	}
 }
 
-func (m *mailNotifier) NotifyMergePullRequest(pr *models.PullRequest, doer *models.User) {
+func (m *mailNotifier) NotifyMergePullRequest(ctx context.Context, pr *models.PullRequest, doer *models.User) {
 	if err := pr.LoadIssue(); err != nil {
 		log.Error("pr.LoadIssue: %v", err)
 		return
@@ -126,7 +136,7 @@ func (m *mailNotifier) NotifyMergePullRequest(pr *models.PullRequest, doer *mode
 	}
 }
 
-func (m *mailNotifier) NotifyPullRequestPushCommits(doer *models.User, pr *models.PullRequest, comment *models.Comment) {
+func (m *mailNotifier) NotifyPullRequestPushCommits(ctx context.Context, doer *models.User, pr *models.PullRequest, comment *models.Comment) {
 	var err error
 	if err = comment.LoadIssue(); err != nil {
 		log.Error("comment.LoadIssue: %v", err)
@@ -149,16 +159,16 @@ func (m *mailNotifier) NotifyPullRequestPushCommits(doer *models.User, pr *model
 	}
 	comment.Content = ""
 
-	m.NotifyCreateIssueComment(doer, comment.Issue.Repo, comment.Issue, comment, nil)
+	m.NotifyCreateIssueComment(ctx, doer, comment.Issue.Repo, comment.Issue, comment, nil)
 }
 
-func (m *mailNotifier) NotifyPullRevieweDismiss(doer *models.User, review *models.Review, comment *models.Comment) {
+func (m *mailNotifier) NotifyPullRevieweDismiss(ctx context.Context, doer *models.User, review *models.Review, comment *models.Comment) {
 	if err := mailer.MailParticipantsComment(comment, models.ActionPullReviewDismissed, review.Issue, []*models.User{}); err != nil {
 		log.Error("MailParticipantsComment: %v", err)
 	}
 }
 
-func (m *mailNotifier) NotifyNewRelease(rel *models.Release) {
+func (m *mailNotifier) NotifyNewRelease(ctx context.Context, rel *models.Release) {
 	if err := rel.LoadAttributes(); err != nil {
 		log.Error("NotifyNewRelease: %v", err)
 		return
@@ -171,8 +181,29 @@ func (m *mailNotifier) NotifyNewRelease(rel *models.Release) {
 	mailer.MailNewRelease(rel)
 }
 
-func (m *mailNotifier) NotifyRepoPendingTransfer(doer, newOwner *models.User, repo *models.Repository) {
+func (m *mailNotifier) NotifyRepoPendingTransfer(ctx context.Context, doer, newOwner *models.User, repo *models.Repository) {
 	if err := mailer.SendRepoTransferNotifyMail(doer, newOwner, repo); err != nil {
 		log.Error("NotifyRepoPendingTransfer: %v", err)
 	}
 }
+
+func (m *mailNotifier) NotifyCreateCommitStatus(ctx context.Context, creator *models.User, repo *models.Repository, commit *git.Commit, status *models.CommitStatus) {
+	if !status.State.IsFailure() && !status.State.IsError() {
+		return
+	}
+	if err := mailer.MailCommitStatusFailure(repo, commit, status); err != nil {
+		log.Error("MailCommitStatusFailure: %v", err)
+	}
+}
+
+func (m *mailNotifier) NotifyIssueDueDateReminder(ctx context.Context, issue *models.Issue, assignee *models.User) {
+	if err := mailer.MailDueDateReminder(assignee, issue.Repo, nil, []*models.Issue{issue}); err != nil {
+		log.Error("MailDueDateReminder: %v", err)
+	}
+}
+
+func (m *mailNotifier) NotifyMilestoneDueDateReminder(ctx context.Context, milestone *models.Milestone, issues []*models.Issue, assignee *models.User) {
+	if err := mailer.MailDueDateReminder(assignee, milestone.Repo, milestone, issues); err != nil {
+		log.Error("MailDueDateReminder: %v", err)
+	}
+}
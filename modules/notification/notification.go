@@ -5,10 +5,15 @@
 package notification
 
 import (
+	"context"
+
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/notification/action"
+	"code.gitea.io/gitea/modules/notification/actions"
 	"code.gitea.io/gitea/modules/notification/base"
 	"code.gitea.io/gitea/modules/notification/indexer"
+	"code.gitea.io/gitea/modules/notification/liveupdate"
 	"code.gitea.io/gitea/modules/notification/mail"
 	"code.gitea.io/gitea/modules/notification/ui"
 	"code.gitea.io/gitea/modules/notification/webhook"
@@ -35,265 +40,300 @@ func NewContext() {
 	RegisterNotifier(indexer.NewNotifier())
 	RegisterNotifier(webhook.NewNotifier())
 	RegisterNotifier(action.NewNotifier())
+	if setting.Actions.Enabled {
+		RegisterNotifier(actions.NewNotifier())
+	}
+	if setting.UI.Notification.EventSourceUpdateTime > 0 {
+		RegisterNotifier(liveupdate.NewNotifier())
+	}
 }
 
 // NotifyCreateIssueComment notifies issue comment related message to notifiers
-func NotifyCreateIssueComment(doer *models.User, repo *models.Repository,
+func NotifyCreateIssueComment(ctx context.Context, doer *models.User, repo *models.Repository,
 	issue *models.Issue, comment *models.Comment, mentions []*models.User) {
 	for _, notifier := range notifiers {
-		notifier.NotifyCreateIssueComment(doer, repo, issue, comment, mentions)
+		notifier.NotifyCreateIssueComment(ctx, doer, repo, issue, comment, mentions)
 	}
 }
 
 // NotifyNewIssue notifies new issue to notifiers
-func NotifyNewIssue(issue *models.Issue, mentions []*models.User) {
+func NotifyNewIssue(ctx context.Context, issue *models.Issue, mentions []*models.User) {
 	for _, notifier := range notifiers {
-		notifier.NotifyNewIssue(issue, mentions)
+		notifier.NotifyNewIssue(ctx, issue, mentions)
 	}
 }
 
 // NotifyIssueChangeStatus notifies close or reopen issue to notifiers
-func NotifyIssueChangeStatus(doer *models.User, issue *models.Issue, actionComment *models.Comment, closeOrReopen bool) {
+func NotifyIssueChangeStatus(ctx context.Context, doer *models.User, issue *models.Issue, actionComment *models.Comment, closeOrReopen bool) {
 	for _, notifier := range notifiers {
-		notifier.NotifyIssueChangeStatus(doer, issue, actionComment, closeOrReopen)
+		notifier.NotifyIssueChangeStatus(ctx, doer, issue, actionComment, closeOrReopen)
 	}
 }
 
 // NotifyMergePullRequest notifies merge pull request to notifiers
-func NotifyMergePullRequest(pr *models.PullRequest, doer *models.User) {
+func NotifyMergePullRequest(ctx context.Context, pr *models.PullRequest, doer *models.User) {
 	for _, notifier := range notifiers {
-		notifier.NotifyMergePullRequest(pr, doer)
+		notifier.NotifyMergePullRequest(ctx, pr, doer)
 	}
 }
 
 // NotifyNewPullRequest notifies new pull request to notifiers
-func NotifyNewPullRequest(pr *models.PullRequest, mentions []*models.User) {
+func NotifyNewPullRequest(ctx context.Context, pr *models.PullRequest, mentions []*models.User) {
 	for _, notifier := range notifiers {
-		notifier.NotifyNewPullRequest(pr, mentions)
+		notifier.NotifyNewPullRequest(ctx, pr, mentions)
 	}
 }
 
 // NotifyPullRequestSynchronized notifies Synchronized pull request
-func NotifyPullRequestSynchronized(doer *models.User, pr *models.PullRequest) {
+func NotifyPullRequestSynchronized(ctx context.Context, doer *models.User, pr *models.PullRequest) {
 	for _, notifier := range notifiers {
-		notifier.NotifyPullRequestSynchronized(doer, pr)
+		notifier.NotifyPullRequestSynchronized(ctx, doer, pr)
 	}
 }
 
 // NotifyPullRequestReview notifies new pull request review
-func NotifyPullRequestReview(pr *models.PullRequest, review *models.Review, comment *models.Comment, mentions []*models.User) {
+func NotifyPullRequestReview(ctx context.Context, pr *models.PullRequest, review *models.Review, comment *models.Comment, mentions []*models.User) {
 	for _, notifier := range notifiers {
-		notifier.NotifyPullRequestReview(pr, review, comment, mentions)
+		notifier.NotifyPullRequestReview(ctx, pr, review, comment, mentions)
 	}
 }
 
 // NotifyPullRequestCodeComment notifies new pull request code comment
-func NotifyPullRequestCodeComment(pr *models.PullRequest, comment *models.Comment, mentions []*models.User) {
+func NotifyPullRequestCodeComment(ctx context.Context, pr *models.PullRequest, comment *models.Comment, mentions []*models.User) {
 	for _, notifier := range notifiers {
-		notifier.NotifyPullRequestCodeComment(pr, comment, mentions)
+		notifier.NotifyPullRequestCodeComment(ctx, pr, comment, mentions)
 	}
 }
 
 // NotifyPullRequestChangeTargetBranch notifies when a pull request's target branch was changed
-func NotifyPullRequestChangeTargetBranch(doer *models.User, pr *models.PullRequest, oldBranch string) {
+func NotifyPullRequestChangeTargetBranch(ctx context.Context, doer *models.User, pr *models.PullRequest, oldBranch string) {
 	for _, notifier := range notifiers {
-		notifier.NotifyPullRequestChangeTargetBranch(doer, pr, oldBranch)
+		notifier.NotifyPullRequestChangeTargetBranch(ctx, doer, pr, oldBranch)
 	}
 }
 
 // NotifyPullRequestPushCommits notifies when push commits to pull request's head branch
-func NotifyPullRequestPushCommits(doer *models.User, pr *models.PullRequest, comment *models.Comment) {
+func NotifyPullRequestPushCommits(ctx context.Context, doer *models.User, pr *models.PullRequest, comment *models.Comment) {
 	for _, notifier := range notifiers {
-		notifier.NotifyPullRequestPushCommits(doer, pr, comment)
+		notifier.NotifyPullRequestPushCommits(ctx, doer, pr, comment)
 	}
 }
 
 // NotifyPullRevieweDismiss notifies when a review was dismissed by repo admin
-func NotifyPullRevieweDismiss(doer *models.User, review *models.Review, comment *models.Comment) {
+func NotifyPullRevieweDismiss(ctx context.Context, doer *models.User, review *models.Review, comment *models.Comment) {
 	for _, notifier := range notifiers {
-		notifier.NotifyPullRevieweDismiss(doer, review, comment)
+		notifier.NotifyPullRevieweDismiss(ctx, doer, review, comment)
 	}
 }
 
 // NotifyUpdateComment notifies update comment to notifiers
-func NotifyUpdateComment(doer *models.User, c *models.Comment, oldContent string) {
+func NotifyUpdateComment(ctx context.Context, doer *models.User, c *models.Comment, oldContent string) {
 	for _, notifier := range notifiers {
-		notifier.NotifyUpdateComment(doer, c, oldContent)
+		notifier.NotifyUpdateComment(ctx, doer, c, oldContent)
 	}
 }
 
 // NotifyDeleteComment notifies delete comment to notifiers
-func NotifyDeleteComment(doer *models.User, c *models.Comment) {
+func NotifyDeleteComment(ctx context.Context, doer *models.User, c *models.Comment) {
 	for _, notifier := range notifiers {
-		notifier.NotifyDeleteComment(doer, c)
+		notifier.NotifyDeleteComment(ctx, doer, c)
 	}
 }
 
 // NotifyNewRelease notifies new release to notifiers
-func NotifyNewRelease(rel *models.Release) {
+func NotifyNewRelease(ctx context.Context, rel *models.Release) {
 	for _, notifier := range notifiers {
-		notifier.NotifyNewRelease(rel)
+		notifier.NotifyNewRelease(ctx, rel)
 	}
 }
 
 // NotifyUpdateRelease notifies update release to notifiers
-func NotifyUpdateRelease(doer *models.User, rel *models.Release) {
+func NotifyUpdateRelease(ctx context.Context, doer *models.User, rel *models.Release) {
 	for _, notifier := range notifiers {
-		notifier.NotifyUpdateRelease(doer, rel)
+		notifier.NotifyUpdateRelease(ctx, doer, rel)
 	}
 }
 
 // NotifyDeleteRelease notifies delete release to notifiers
-func NotifyDeleteRelease(doer *models.User, rel *models.Release) {
+func NotifyDeleteRelease(ctx context.Context, doer *models.User, rel *models.Release) {
 	for _, notifier := range notifiers {
-		notifier.NotifyDeleteRelease(doer, rel)
+		notifier.NotifyDeleteRelease(ctx, doer, rel)
 	}
 }
 
 // NotifyIssueChangeMilestone notifies change milestone to notifiers
-func NotifyIssueChangeMilestone(doer *models.User, issue *models.Issue, oldMilestoneID int64) {
+func NotifyIssueChangeMilestone(ctx context.Context, doer *models.User, issue *models.Issue, oldMilestoneID int64) {
 	for _, notifier := range notifiers {
-		notifier.NotifyIssueChangeMilestone(doer, issue, oldMilestoneID)
+		notifier.NotifyIssueChangeMilestone(ctx, doer, issue, oldMilestoneID)
 	}
 }
 
 // NotifyIssueChangeContent notifies change content to notifiers
-func NotifyIssueChangeContent(doer *models.User, issue *models.Issue, oldContent string) {
+func NotifyIssueChangeContent(ctx context.Context, doer *models.User, issue *models.Issue, oldContent string) {
 	for _, notifier := range notifiers {
-		notifier.NotifyIssueChangeContent(doer, issue, oldContent)
+		notifier.NotifyIssueChangeContent(ctx, doer, issue, oldContent)
 	}
 }
 
 // NotifyIssueChangeAssignee notifies change content to notifiers
-func NotifyIssueChangeAssignee(doer *models.User, issue *models.Issue, assignee *models.User, removed bool, comment *models.Comment) {
+func NotifyIssueChangeAssignee(ctx context.Context, doer *models.User, issue *models.Issue, assignee *models.User, removed bool, comment *models.Comment) {
 	for _, notifier := range notifiers {
-		notifier.NotifyIssueChangeAssignee(doer, issue, assignee, removed, comment)
+		notifier.NotifyIssueChangeAssignee(ctx, doer, issue, assignee, removed, comment)
 	}
 }
 
 // NotifyPullReviewRequest notifies Request Review change
-func NotifyPullReviewRequest(doer *models.User, issue *models.Issue, reviewer *models.User, isRequest bool, comment *models.Comment) {
+func NotifyPullReviewRequest(ctx context.Context, doer *models.User, issue *models.Issue, reviewer *models.User, isRequest bool, comment *models.Comment) {
 	for _, notifier := range notifiers {
-		notifier.NotifyPullReviewRequest(doer, issue, reviewer, isRequest, comment)
+		notifier.NotifyPullReviewRequest(ctx, doer, issue, reviewer, isRequest, comment)
 	}
 }
 
 // NotifyIssueClearLabels notifies clear labels to notifiers
-func NotifyIssueClearLabels(doer *models.User, issue *models.Issue) {
+func NotifyIssueClearLabels(ctx context.Context, doer *models.User, issue *models.Issue) {
 	for _, notifier := range notifiers {
-		notifier.NotifyIssueClearLabels(doer, issue)
+		notifier.NotifyIssueClearLabels(ctx, doer, issue)
 	}
 }
 
 // NotifyIssueChangeTitle notifies change title to notifiers
-func NotifyIssueChangeTitle(doer *models.User, issue *models.Issue, oldTitle string) {
+func NotifyIssueChangeTitle(ctx context.Context, doer *models.User, issue *models.Issue, oldTitle string) {
 	for _, notifier := range notifiers {
-		notifier.NotifyIssueChangeTitle(doer, issue, oldTitle)
+		notifier.NotifyIssueChangeTitle(ctx, doer, issue, oldTitle)
 	}
 }
 
 // NotifyIssueChangeRef notifies change reference to notifiers
-func NotifyIssueChangeRef(doer *models.User, issue *models.Issue, oldRef string) {
+func NotifyIssueChangeRef(ctx context.Context, doer *models.User, issue *models.Issue, oldRef string) {
 	for _, notifier := range notifiers {
-		notifier.NotifyIssueChangeRef(doer, issue, oldRef)
+		notifier.NotifyIssueChangeRef(ctx, doer, issue, oldRef)
 	}
 }
 
 // NotifyIssueChangeLabels notifies change labels to notifiers
-func NotifyIssueChangeLabels(doer *models.User, issue *models.Issue,
+func NotifyIssueChangeLabels(ctx context.Context, doer *models.User, issue *models.Issue,
 	addedLabels []*models.Label, removedLabels []*models.Label) {
 	for _, notifier := range notifiers {
-		notifier.NotifyIssueChangeLabels(doer, issue, addedLabels, removedLabels)
+		notifier.NotifyIssueChangeLabels(ctx, doer, issue, addedLabels, removedLabels)
 	}
 }
 
 // NotifyCreateRepository notifies create repository to notifiers
-func NotifyCreateRepository(doer *models.User, u *models.User, repo *models.Repository) {
+func NotifyCreateRepository(ctx context.Context, doer *models.User, u *models.User, repo *models.Repository) {
 	for _, notifier := range notifiers {
-		notifier.NotifyCreateRepository(doer, u, repo)
+		notifier.NotifyCreateRepository(ctx, doer, u, repo)
 	}
 }
 
 // NotifyMigrateRepository notifies create repository to notifiers
-func NotifyMigrateRepository(doer *models.User, u *models.User, repo *models.Repository) {
+func NotifyMigrateRepository(ctx context.Context, doer *models.User, u *models.User, repo *models.Repository) {
 	for _, notifier := range notifiers {
-		notifier.NotifyMigrateRepository(doer, u, repo)
+		notifier.NotifyMigrateRepository(ctx, doer, u, repo)
 	}
 }
 
 // NotifyTransferRepository notifies create repository to notifiers
-func NotifyTransferRepository(doer *models.User, repo *models.Repository, newOwnerName string) {
+func NotifyTransferRepository(ctx context.Context, doer *models.User, repo *models.Repository, newOwnerName string) {
 	for _, notifier := range notifiers {
-		notifier.NotifyTransferRepository(doer, repo, newOwnerName)
+		notifier.NotifyTransferRepository(ctx, doer, repo, newOwnerName)
 	}
 }
 
 // NotifyDeleteRepository notifies delete repository to notifiers
-func NotifyDeleteRepository(doer *models.User, repo *models.Repository) {
+func NotifyDeleteRepository(ctx context.Context, doer *models.User, repo *models.Repository) {
 	for _, notifier := range notifiers {
-		notifier.NotifyDeleteRepository(doer, repo)
+		notifier.NotifyDeleteRepository(ctx, doer, repo)
 	}
 }
 
 // NotifyForkRepository notifies fork repository to notifiers
-func NotifyForkRepository(doer *models.User, oldRepo, repo *models.Repository) {
+func NotifyForkRepository(ctx context.Context, doer *models.User, oldRepo, repo *models.Repository) {
 	for _, notifier := range notifiers {
-		notifier.NotifyForkRepository(doer, oldRepo, repo)
+		notifier.NotifyForkRepository(ctx, doer, oldRepo, repo)
 	}
 }
 
 // NotifyRenameRepository notifies repository renamed
-func NotifyRenameRepository(doer *models.User, repo *models.Repository, oldName string) {
+func NotifyRenameRepository(ctx context.Context, doer *models.User, repo *models.Repository, oldName string) {
 	for _, notifier := range notifiers {
-		notifier.NotifyRenameRepository(doer, repo, oldName)
+		notifier.NotifyRenameRepository(ctx, doer, repo, oldName)
 	}
 }
 
 // NotifyPushCommits notifies commits pushed to notifiers
-func NotifyPushCommits(pusher *models.User, repo *models.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
+func NotifyPushCommits(ctx context.Context, pusher *models.User, repo *models.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
 	for _, notifier := range notifiers {
-		notifier.NotifyPushCommits(pusher, repo, opts, commits)
+		notifier.NotifyPushCommits(ctx, pusher, repo, opts, commits)
 	}
 }
 
 // NotifyCreateRef notifies branch or tag creation to notifiers
-func NotifyCreateRef(pusher *models.User, repo *models.Repository, refType, refFullName string) {
+func NotifyCreateRef(ctx context.Context, pusher *models.User, repo *models.Repository, refType, refFullName string) {
 	for _, notifier := range notifiers {
-		notifier.NotifyCreateRef(pusher, repo, refType, refFullName)
+		notifier.NotifyCreateRef(ctx, pusher, repo, refType, refFullName)
 	}
 }
 
 // NotifyDeleteRef notifies branch or tag deletion to notifiers
-func NotifyDeleteRef(pusher *models.User, repo *models.Repository, refType, refFullName string) {
+func NotifyDeleteRef(ctx context.Context, pusher *models.User, repo *models.Repository, refType, refFullName string) {
 	for _, notifier := range notifiers {
-		notifier.NotifyDeleteRef(pusher, repo, refType, refFullName)
+		notifier.NotifyDeleteRef(ctx, pusher, repo, refType, refFullName)
 	}
 }
 
 // NotifySyncPushCommits notifies commits pushed to notifiers
-func NotifySyncPushCommits(pusher *models.User, repo *models.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
+func NotifySyncPushCommits(ctx context.Context, pusher *models.User, repo *models.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
 	for _, notifier := range notifiers {
-		notifier.NotifySyncPushCommits(pusher, repo, opts, commits)
+		notifier.NotifySyncPushCommits(ctx, pusher, repo, opts, commits)
 	}
 }
 
 // NotifySyncCreateRef notifies branch or tag creation to notifiers
-func NotifySyncCreateRef(pusher *models.User, repo *models.Repository, refType, refFullName string) {
+func NotifySyncCreateRef(ctx context.Context, pusher *models.User, repo *models.Repository, refType, refFullName string) {
 	for _, notifier := range notifiers {
-		notifier.NotifySyncCreateRef(pusher, repo, refType, refFullName)
+		notifier.NotifySyncCreateRef(ctx, pusher, repo, refType, refFullName)
 	}
 }
 
 // NotifySyncDeleteRef notifies branch or tag deletion to notifiers
-func NotifySyncDeleteRef(pusher *models.User, repo *models.Repository, refType, refFullName string) {
+func NotifySyncDeleteRef(ctx context.Context, pusher *models.User, repo *models.Repository, refType, refFullName string) {
 	for _, notifier := range notifiers {
-		notifier.NotifySyncDeleteRef(pusher, repo, refType, refFullName)
+		notifier.NotifySyncDeleteRef(ctx, pusher, repo, refType, refFullName)
 	}
 }
 
 // NotifyRepoPendingTransfer notifies creation of pending transfer to notifiers
-func NotifyRepoPendingTransfer(doer, newOwner *models.User, repo *models.Repository) {
+func NotifyRepoPendingTransfer(ctx context.Context, doer, newOwner *models.User, repo *models.Repository) {
+	for _, notifier := range notifiers {
+		notifier.NotifyRepoPendingTransfer(ctx, doer, newOwner, repo)
+	}
+}
+
+// NotifyPullRequestChecked notifies the end of the conflict check of a pull request to notifiers
+func NotifyPullRequestChecked(ctx context.Context, pr *models.PullRequest) {
+	for _, notifier := range notifiers {
+		notifier.NotifyPullRequestChecked(ctx, pr)
+	}
+}
+
+// NotifyCreateCommitStatus notifies a new status of a commit to notifiers
+func NotifyCreateCommitStatus(ctx context.Context, creator *models.User, repo *models.Repository, commit *git.Commit, status *models.CommitStatus) {
+	for _, notifier := range notifiers {
+		notifier.NotifyCreateCommitStatus(ctx, creator, repo, commit, status)
+	}
+}
+
+// NotifyIssueDueDateReminder notifies notifiers that an assignee of an issue is reminded of its due date
+func NotifyIssueDueDateReminder(ctx context.Context, issue *models.Issue, assignee *models.User) {
+	for _, notifier := range notifiers {
+		notifier.NotifyIssueDueDateReminder(ctx, issue, assignee)
+	}
+}
+
+// NotifyMilestoneDueDateReminder notifies notifiers that an assignee of open issues of a milestone is reminded of the
+// due date of the milestone
+func NotifyMilestoneDueDateReminder(ctx context.Context, milestone *models.Milestone, issues []*models.Issue, assignee *models.User) {
 	for _, notifier := range notifiers {
-		notifier.NotifyRepoPendingTransfer(doer, newOwner, repo)
+		notifier.NotifyMilestoneDueDateReminder(ctx, milestone, issues, assignee)
 	}
 }
@@ -5,11 +5,15 @@
 package ui
 
 import (
+	"context"
+	"strings"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification/base"
 	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/references"
 )
 
 type (
@@ -23,6 +27,10 @@ type (
 		CommentID            int64
 		NotificationAuthorID int64
 		ReceiverID           int64 // 0 -- ALL Watcher
+		// WatchTeamMentions subscribes the members of the teams mentioned by the issue or the comment before notifying
+		// the watchers
+		WatchTeamMentions bool
+		queue.TraceContext
 	}
 )
 
@@ -40,17 +48,62 @@ func NewNotifier() base.Notifier {
 func (ns *notificationService) handle(data ...queue.Data) {
 	for _, datum := range data {
 		opts := datum.(issueNotificationOpts)
+		if opts.WatchTeamMentions {
+			watchTeamMentions(opts)
+		}
 		if err := models.CreateOrUpdateIssueNotifications(opts.IssueID, opts.CommentID, opts.NotificationAuthorID, opts.ReceiverID); err != nil {
 			log.Error("Was unable to create issue notification: %v", err)
 		}
 	}
 }
 
+// push queues the notifications of an issue in the trace of the context
+func (ns *notificationService) push(ctx context.Context, opts issueNotificationOpts) {
+	opts.SetSpanContext(ctx)
+	_ = ns.issueQueue.Push(opts)
+}
+
+// watchTeamMentions subscribes to an issue the members of the teams mentioned by the issue or the comment
+func watchTeamMentions(opts issueNotificationOpts) {
+	issue, err := models.GetIssueByID(opts.IssueID)
+	if err != nil {
+		log.Error("GetIssueByID[%d]: %v", opts.IssueID, err)
+		return
+	}
+	content := issue.Content
+	if opts.CommentID > 0 {
+		comment, err := models.GetCommentByID(opts.CommentID)
+		if err != nil {
+			log.Error("GetCommentByID[%d]: %v", opts.CommentID, err)
+			return
+		}
+		content = comment.Content
+	}
+	doer, err := models.GetUserByID(opts.NotificationAuthorID)
+	if err != nil {
+		log.Error("GetUserByID[%d]: %v", opts.NotificationAuthorID, err)
+		return
+	}
+	if _, err := models.WatchIssueForTeamMentions(issue, doer, content); err != nil {
+		log.Error("WatchIssueForTeamMentions[%d]: %v", issue.ID, err)
+	}
+}
+
+// mentionsTeam returns true if a content mentions a team, i.e. has a mention of the form @org/team
+func mentionsTeam(content string) bool {
+	for _, mention := range references.FindAllMentionsMarkdown(content) {
+		if strings.Contains(mention, "/") {
+			return true
+		}
+	}
+	return false
+}
+
 func (ns *notificationService) Run() {
 	graceful.GetManager().RunWithShutdownFns(ns.issueQueue.Run)
 }
 
-func (ns *notificationService) NotifyCreateIssueComment(doer *models.User, repo *models.Repository,
+func (ns *notificationService) NotifyCreateIssueComment(ctx context.Context, doer *models.User, repo *models.Repository,
 	issue *models.Issue, comment *models.Comment, mentions []*models.User) {
 	var opts = issueNotificationOpts{
 		IssueID:              issue.ID,
@@ -58,8 +111,9 @@ func (ns *notificationService) NotifyCreateIssueComment(doer *models.User, repo
 	}
 	if comment != nil {
 		opts.CommentID = comment.ID
+		opts.WatchTeamMentions = mentionsTeam(comment.Content)
 	}
-	_ = ns.issueQueue.Push(opts)
+	ns.push(ctx, opts)
 	for _, mention := range mentions {
 		var opts = issueNotificationOpts{
 			IssueID:              issue.ID,
@@ -69,17 +123,18 @@ func (ns *notificationService) NotifyCreateIssueComment(doer *models.User, repo
 		if comment != nil {
 			opts.CommentID = comment.ID
 		}
-		_ = ns.issueQueue.Push(opts)
+		ns.push(ctx, opts)
 	}
 }
 
-func (ns *notificationService) NotifyNewIssue(issue *models.Issue, mentions []*models.User) {
-	_ = ns.issueQueue.Push(issueNotificationOpts{
+func (ns *notificationService) NotifyNewIssue(ctx context.Context, issue *models.Issue, mentions []*models.User) {
+	ns.push(ctx, issueNotificationOpts{
 		IssueID:              issue.ID,
 		NotificationAuthorID: issue.Poster.ID,
+		WatchTeamMentions:    mentionsTeam(issue.Content),
 	})
 	for _, mention := range mentions {
-		_ = ns.issueQueue.Push(issueNotificationOpts{
+		ns.push(ctx, issueNotificationOpts{
 			IssueID:              issue.ID,
 			NotificationAuthorID: issue.Poster.ID,
 			ReceiverID:           mention.ID,
@@ -87,31 +142,32 @@ func (ns *notificationService) NotifyNewIssue(issue *models.Issue, mentions []*m
 	}
 }
 
-func (ns *notificationService) NotifyIssueChangeStatus(doer *models.User, issue *models.Issue, actionComment *models.Comment, isClosed bool) {
-	_ = ns.issueQueue.Push(issueNotificationOpts{
+func (ns *notificationService) NotifyIssueChangeStatus(ctx context.Context, doer *models.User, issue *models.Issue, actionComment *models.Comment, isClosed bool) {
+	ns.push(ctx, issueNotificationOpts{
 		IssueID:              issue.ID,
 		NotificationAuthorID: doer.ID,
 	})
 }
 
-func (ns *notificationService) NotifyMergePullRequest(pr *models.PullRequest, doer *models.User) {
-	_ = ns.issueQueue.Push(issueNotificationOpts{
+func (ns *notificationService) NotifyMergePullRequest(ctx context.Context, pr *models.PullRequest, doer *models.User) {
+	ns.push(ctx, issueNotificationOpts{
 		IssueID:              pr.Issue.ID,
 		NotificationAuthorID: doer.ID,
 	})
 }
 
-func (ns *notificationService) NotifyNewPullRequest(pr *models.PullRequest, mentions []*models.User) {
+func (ns *notificationService) NotifyNewPullRequest(ctx context.Context, pr *models.PullRequest, mentions []*models.User) {
 	if err := pr.LoadIssue(); err != nil {
 		log.Error("Unable to load issue: %d for pr: %d: Error: %v", pr.IssueID, pr.ID, err)
 		return
 	}
-	_ = ns.issueQueue.Push(issueNotificationOpts{
+	ns.push(ctx, issueNotificationOpts{
 		IssueID:              pr.Issue.ID,
 		NotificationAuthorID: pr.Issue.PosterID,
+		WatchTeamMentions:    mentionsTeam(pr.Issue.Content),
 	})
 	for _, mention := range mentions {
-		_ = ns.issueQueue.Push(issueNotificationOpts{
+		ns.push(ctx, issueNotificationOpts{
 			IssueID:              pr.Issue.ID,
 			NotificationAuthorID: pr.Issue.PosterID,
 			ReceiverID:           mention.ID,
@@ -119,7 +175,7 @@ func (ns *notificationService) NotifyNewPullRequest(pr *models.PullRequest, ment
 	}
 }
 
-func (ns *notificationService) NotifyPullRequestReview(pr *models.PullRequest, r *models.Review, c *models.Comment, mentions []*models.User) {
+func (ns *notificationService) NotifyPullRequestReview(ctx context.Context, pr *models.PullRequest, r *models.Review, c *models.Comment, mentions []*models.User) {
 	var opts = issueNotificationOpts{
 		IssueID:              pr.Issue.ID,
 		NotificationAuthorID: r.Reviewer.ID,
@@ -127,7 +183,7 @@ func (ns *notificationService) NotifyPullRequestReview(pr *models.PullRequest, r
 	if c != nil {
 		opts.CommentID = c.ID
 	}
-	_ = ns.issueQueue.Push(opts)
+	ns.push(ctx, opts)
 	for _, mention := range mentions {
 		var opts = issueNotificationOpts{
 			IssueID:              pr.Issue.ID,
@@ -137,13 +193,13 @@ func (ns *notificationService) NotifyPullRequestReview(pr *models.PullRequest, r
 		if c != nil {
 			opts.CommentID = c.ID
 		}
-		_ = ns.issueQueue.Push(opts)
+		ns.push(ctx, opts)
 	}
 }
 
-func (ns *notificationService) NotifyPullRequestCodeComment(pr *models.PullRequest, c *models.Comment, mentions []*models.User) {
+func (ns *notificationService) NotifyPullRequestCodeComment(ctx context.Context, pr *models.PullRequest, c *models.Comment, mentions []*models.User) {
 	for _, mention := range mentions {
-		_ = ns.issueQueue.Push(issueNotificationOpts{
+		ns.push(ctx, issueNotificationOpts{
 			IssueID:              pr.Issue.ID,
 			NotificationAuthorID: c.Poster.ID,
 			CommentID:            c.ID,
@@ -152,25 +208,25 @@ func (ns *notificationService) NotifyPullRequestCodeComment(pr *models.PullReque
 	}
 }
 
-func (ns *notificationService) NotifyPullRequestPushCommits(doer *models.User, pr *models.PullRequest, comment *models.Comment) {
+func (ns *notificationService) NotifyPullRequestPushCommits(ctx context.Context, doer *models.User, pr *models.PullRequest, comment *models.Comment) {
 	var opts = issueNotificationOpts{
 		IssueID:              pr.IssueID,
 		NotificationAuthorID: doer.ID,
 		CommentID:            comment.ID,
 	}
-	_ = ns.issueQueue.Push(opts)
+	ns.push(ctx, opts)
 }
 
-func (ns *notificationService) NotifyPullRevieweDismiss(doer *models.User, review *models.Review, comment *models.Comment) {
+func (ns *notificationService) NotifyPullRevieweDismiss(ctx context.Context, doer *models.User, review *models.Review, comment *models.Comment) {
 	var opts = issueNotificationOpts{
 		IssueID:              review.IssueID,
 		NotificationAuthorID: doer.ID,
 		CommentID:            comment.ID,
 	}
-	_ = ns.issueQueue.Push(opts)
+	ns.push(ctx, opts)
 }
 
-func (ns *notificationService) NotifyIssueChangeAssignee(doer *models.User, issue *models.Issue, assignee *models.User, removed bool, comment *models.Comment) {
+func (ns *notificationService) NotifyIssueChangeAssignee(ctx context.Context, doer *models.User, issue *models.Issue, assignee *models.User, removed bool, comment *models.Comment) {
 	if !removed {
 		var opts = issueNotificationOpts{
 			IssueID:              issue.ID,
@@ -182,11 +238,11 @@ func (ns *notificationService) NotifyIssueChangeAssignee(doer *models.User, issu
 			opts.CommentID = comment.ID
 		}
 
-		_ = ns.issueQueue.Push(opts)
+		ns.push(ctx, opts)
 	}
 }
 
-func (ns *notificationService) NotifyPullReviewRequest(doer *models.User, issue *models.Issue, reviewer *models.User, isRequest bool, comment *models.Comment) {
+func (ns *notificationService) NotifyPullReviewRequest(ctx context.Context, doer *models.User, issue *models.Issue, reviewer *models.User, isRequest bool, comment *models.Comment) {
 	if isRequest {
 		var opts = issueNotificationOpts{
 			IssueID:              issue.ID,
@@ -198,12 +254,25 @@ func (ns *notificationService) NotifyPullReviewRequest(doer *models.User, issue
 			opts.CommentID = comment.ID
 		}
 
-		_ = ns.issueQueue.Push(opts)
+		ns.push(ctx, opts)
 	}
 }
 
-func (ns *notificationService) NotifyRepoPendingTransfer(doer, newOwner *models.User, repo *models.Repository) {
+func (ns *notificationService) NotifyRepoPendingTransfer(ctx context.Context, doer, newOwner *models.User, repo *models.Repository) {
 	if err := models.CreateRepoTransferNotification(doer, newOwner, repo); err != nil {
 		log.Error("NotifyRepoPendingTransfer: %v", err)
 	}
 }
+
+func (ns *notificationService) NotifyIssueDueDateReminder(ctx context.Context, issue *models.Issue, assignee *models.User) {
+	ns.push(ctx, issueNotificationOpts{
+		IssueID:    issue.ID,
+		ReceiverID: assignee.ID,
+	})
+}
+
+func (ns *notificationService) NotifyMilestoneDueDateReminder(ctx context.Context, milestone *models.Milestone, issues []*models.Issue, assignee *models.User) {
+	for _, issue := range issues {
+		ns.NotifyIssueDueDateReminder(ctx, issue, assignee)
+	}
+}
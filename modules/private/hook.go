@@ -55,6 +55,12 @@ type HookOptions struct {
 	GitPushOptions                  GitPushOptions
 	ProtectedBranchID               int64
 	IsDeployKey                     bool
+	IsWiki                          bool
+}
+
+// HookPreReceiveResult represents the result of a successful PreReceive
+type HookPreReceiveResult struct {
+	Warnings []string
 }
 
 // HookPostReceiveResult represents an individual result from PostReceive
@@ -73,8 +79,8 @@ type HookPostReceiveBranchResult struct {
 }
 
 // HookPreReceive check whether the provided commits are allowed
-func HookPreReceive(ownerName, repoName string, opts HookOptions) (int, string) {
-	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/hook/pre-receive/%s/%s",
+func HookPreReceive(ownerName, repoName string, opts HookOptions) (int, string, []string) {
+	reqURL := setting.InternalAPI.LocalURL + fmt.Sprintf("api/internal/hook/pre-receive/%s/%s",
 		url.PathEscape(ownerName),
 		url.PathEscape(repoName),
 	)
@@ -86,20 +92,22 @@ func HookPreReceive(ownerName, repoName string, opts HookOptions) (int, string)
 	req.SetTimeout(60*time.Second, time.Duration(60+len(opts.OldCommitIDs))*time.Second)
 	resp, err := req.Response()
 	if err != nil {
-		return http.StatusInternalServerError, fmt.Sprintf("Unable to contact gitea: %v", err.Error())
+		return http.StatusInternalServerError, fmt.Sprintf("Unable to contact gitea: %v", err.Error()), nil
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return resp.StatusCode, decodeJSONError(resp).Err
+		return resp.StatusCode, decodeJSONError(resp).Err, nil
 	}
+	res := &HookPreReceiveResult{}
+	_ = json.NewDecoder(resp.Body).Decode(res)
 
-	return http.StatusOK, ""
+	return http.StatusOK, "", res.Warnings
 }
 
 // HookPostReceive updates services and users
 func HookPostReceive(ownerName, repoName string, opts HookOptions) (*HookPostReceiveResult, string) {
-	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/hook/post-receive/%s/%s",
+	reqURL := setting.InternalAPI.LocalURL + fmt.Sprintf("api/internal/hook/post-receive/%s/%s",
 		url.PathEscape(ownerName),
 		url.PathEscape(repoName),
 	)
@@ -127,7 +135,7 @@ func HookPostReceive(ownerName, repoName string, opts HookOptions) (*HookPostRec
 
 // SetDefaultBranch will set the default branch to the provided branch for the provided repository
 func SetDefaultBranch(ownerName, repoName, branch string) error {
-	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/hook/set-default-branch/%s/%s/%s",
+	reqURL := setting.InternalAPI.LocalURL + fmt.Sprintf("api/internal/hook/set-default-branch/%s/%s/%s",
 		url.PathEscape(ownerName),
 		url.PathEscape(repoName),
 		url.PathEscape(branch),
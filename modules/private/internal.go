@@ -6,18 +6,28 @@ package private
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
+	"os"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/httplib"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	jsoniter "github.com/json-iterator/go"
 )
 
 func newRequest(url, method string) *httplib.Request {
-	return httplib.NewRequest(url, method).Header("Authorization",
-		fmt.Sprintf("Bearer %s", setting.InternalToken))
+	req := httplib.NewRequest(url, method).Header("Authorization",
+		fmt.Sprintf("Bearer %s", internalToken()))
+	// the hooks pass on the ID of the request which caused the push
+	if requestID := os.Getenv(models.EnvRequestID); requestID != "" {
+		req.Header("X-Request-ID", requestID)
+	}
+	return req
 }
 
 // Response internal request response
@@ -36,16 +46,59 @@ func decodeJSONError(resp *http.Response) *Response {
 }
 
 func newInternalRequest(url, method string) *httplib.Request {
-	req := newRequest(url, method).SetTLSClientConfig(&tls.Config{
-		InsecureSkipVerify: true,
-		ServerName:         setting.Domain,
-	})
-	if setting.Protocol == setting.UnixSocket {
+	req := newRequest(url, method).SetTLSClientConfig(internalTLSConfig())
+	if setting.InternalAPI.Protocol == setting.UnixSocket {
 		req.SetTransport(&http.Transport{
 			Dial: func(_, _ string) (net.Conn, error) {
-				return net.Dial("unix", setting.HTTPAddr)
+				return net.Dial("unix", setting.InternalAPI.HTTPAddr)
 			},
 		})
 	}
 	return req
 }
+
+// internalTLSConfig returns the TLS configuration used to connect to the internal API.
+// When the internal API has its own https listener the configured client certificate is presented
+// and, if a CA is configured, the server certificate is verified.
+func internalTLSConfig() *tls.Config {
+	if !setting.InternalAPI.Separate || setting.InternalAPI.Protocol != setting.HTTPS {
+		return &tls.Config{
+			InsecureSkipVerify: true,
+			ServerName:         setting.Domain,
+		}
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: true,
+	}
+	if setting.InternalAPI.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(setting.InternalAPI.ClientCertFile, setting.InternalAPI.ClientKeyFile)
+		if err != nil {
+			log.Error("Unable to load internal API client certificate: %v", err)
+		} else {
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+	if setting.InternalAPI.CAFile != "" {
+		pool, err := loadCertPool(setting.InternalAPI.CAFile)
+		if err != nil {
+			log.Error("Unable to load internal API CA: %v", err)
+		} else {
+			tlsConfig.RootCAs = pool
+			tlsConfig.InsecureSkipVerify = false
+		}
+	}
+	return tlsConfig
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	return pool, nil
+}
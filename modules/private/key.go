@@ -15,7 +15,7 @@ import (
 // UpdatePublicKeyInRepo update public key and if necessary deploy key updates
 func UpdatePublicKeyInRepo(keyID, repoID int64) error {
 	// Ask for running deliver hook and test pull request tasks.
-	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/ssh/%d/update/%d", keyID, repoID)
+	reqURL := setting.InternalAPI.LocalURL + fmt.Sprintf("api/internal/ssh/%d/update/%d", keyID, repoID)
 	resp, err := newInternalRequest(reqURL, "POST").Response()
 	if err != nil {
 		return err
@@ -34,7 +34,7 @@ func UpdatePublicKeyInRepo(keyID, repoID int64) error {
 // and returns public key found.
 func AuthorizedPublicKeyByContent(content string) (string, error) {
 	// Ask for running deliver hook and test pull request tasks.
-	reqURL := setting.LocalURL + "api/internal/ssh/authorized_keys"
+	reqURL := setting.InternalAPI.LocalURL + "api/internal/ssh/authorized_keys"
 	req := newInternalRequest(reqURL, "POST")
 	req.Param("content", content)
 	resp, err := req.Response()
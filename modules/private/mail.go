@@ -28,7 +28,7 @@ type Email struct {
 // If to list == nil its supposed to send an email to every
 // user present in DB
 func SendEmail(subject, message string, to []string) (int, string) {
-	reqURL := setting.LocalURL + "api/internal/mail/send"
+	reqURL := setting.InternalAPI.LocalURL + "api/internal/mail/send"
 
 	req := newInternalRequest(reqURL, "POST")
 	req = req.Header("Content-Type", "application/json")
@@ -16,7 +16,7 @@ import (
 
 // Shutdown calls the internal shutdown function
 func Shutdown() (int, string) {
-	reqURL := setting.LocalURL + "api/internal/manager/shutdown"
+	reqURL := setting.InternalAPI.LocalURL + "api/internal/manager/shutdown"
 
 	req := newInternalRequest(reqURL, "POST")
 	resp, err := req.Response()
@@ -34,7 +34,7 @@ func Shutdown() (int, string) {
 
 // Restart calls the internal restart function
 func Restart() (int, string) {
-	reqURL := setting.LocalURL + "api/internal/manager/restart"
+	reqURL := setting.InternalAPI.LocalURL + "api/internal/manager/restart"
 
 	req := newInternalRequest(reqURL, "POST")
 	resp, err := req.Response()
@@ -58,7 +58,7 @@ type FlushOptions struct {
 
 // FlushQueues calls the internal flush-queues function
 func FlushQueues(timeout time.Duration, nonBlocking bool) (int, string) {
-	reqURL := setting.LocalURL + "api/internal/manager/flush-queues"
+	reqURL := setting.InternalAPI.LocalURL + "api/internal/manager/flush-queues"
 
 	req := newInternalRequest(reqURL, "POST")
 	if timeout > 0 {
@@ -84,9 +84,41 @@ func FlushQueues(timeout time.Duration, nonBlocking bool) (int, string) {
 	return http.StatusOK, "Flushed"
 }
 
+// ReindexOptions represents the options for the reindex call
+type ReindexOptions struct {
+	Type  string
+	Repos []string
+}
+
+// Reindex asks the running gitea to queue the issues or code of the given repositories for reindexing.
+// If no repositories are given all repositories are reindexed.
+func Reindex(indexerType string, repos []string) (int, string) {
+	reqURL := setting.InternalAPI.LocalURL + "api/internal/manager/reindex"
+
+	req := newInternalRequest(reqURL, "POST")
+	req = req.Header("Content-Type", "application/json")
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	jsonBytes, _ := json.Marshal(ReindexOptions{
+		Type:  indexerType,
+		Repos: repos,
+	})
+	req.Body(jsonBytes)
+	resp, err := req.Response()
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Sprintf("Unable to contact gitea: %v", err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, decodeJSONError(resp).Err
+	}
+
+	return http.StatusOK, "Reindexing queued"
+}
+
 // PauseLogging pauses logging
 func PauseLogging() (int, string) {
-	reqURL := setting.LocalURL + "api/internal/manager/pause-logging"
+	reqURL := setting.InternalAPI.LocalURL + "api/internal/manager/pause-logging"
 
 	req := newInternalRequest(reqURL, "POST")
 	resp, err := req.Response()
@@ -104,7 +136,7 @@ func PauseLogging() (int, string) {
 
 // ResumeLogging resumes logging
 func ResumeLogging() (int, string) {
-	reqURL := setting.LocalURL + "api/internal/manager/resume-logging"
+	reqURL := setting.InternalAPI.LocalURL + "api/internal/manager/resume-logging"
 
 	req := newInternalRequest(reqURL, "POST")
 	resp, err := req.Response()
@@ -122,7 +154,7 @@ func ResumeLogging() (int, string) {
 
 // ReleaseReopenLogging releases and reopens logging files
 func ReleaseReopenLogging() (int, string) {
-	reqURL := setting.LocalURL + "api/internal/manager/release-and-reopen-logging"
+	reqURL := setting.InternalAPI.LocalURL + "api/internal/manager/release-and-reopen-logging"
 
 	req := newInternalRequest(reqURL, "POST")
 	resp, err := req.Response()
@@ -138,6 +170,40 @@ func ReleaseReopenLogging() (int, string) {
 	return http.StatusOK, "Logging Restarted"
 }
 
+// MaintenanceOptions represents the options for the maintenance call
+type MaintenanceOptions struct {
+	Enabled bool
+	Message string
+}
+
+// SetMaintenanceMode enables or disables the maintenance mode of the running gitea
+func SetMaintenanceMode(enabled bool, message string) (int, string) {
+	reqURL := setting.InternalAPI.LocalURL + "api/internal/manager/maintenance"
+
+	req := newInternalRequest(reqURL, "POST")
+	req = req.Header("Content-Type", "application/json")
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	jsonBytes, _ := json.Marshal(MaintenanceOptions{
+		Enabled: enabled,
+		Message: message,
+	})
+	req.Body(jsonBytes)
+	resp, err := req.Response()
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Sprintf("Unable to contact gitea: %v", err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, decodeJSONError(resp).Err
+	}
+
+	if enabled {
+		return http.StatusOK, "Maintenance mode enabled"
+	}
+	return http.StatusOK, "Maintenance mode disabled"
+}
+
 // LoggerOptions represents the options for the add logger call
 type LoggerOptions struct {
 	Group  string
@@ -148,7 +214,7 @@ type LoggerOptions struct {
 
 // AddLogger adds a logger
 func AddLogger(group, name, mode string, config map[string]interface{}) (int, string) {
-	reqURL := setting.LocalURL + "api/internal/manager/add-logger"
+	reqURL := setting.InternalAPI.LocalURL + "api/internal/manager/add-logger"
 
 	req := newInternalRequest(reqURL, "POST")
 	req = req.Header("Content-Type", "application/json")
@@ -176,7 +242,7 @@ func AddLogger(group, name, mode string, config map[string]interface{}) (int, st
 
 // RemoveLogger removes a logger
 func RemoveLogger(group, name string) (int, string) {
-	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/manager/remove-logger/%s/%s", url.PathEscape(group), url.PathEscape(name))
+	reqURL := setting.InternalAPI.LocalURL + fmt.Sprintf("api/internal/manager/remove-logger/%s/%s", url.PathEscape(group), url.PathEscape(name))
 
 	req := newInternalRequest(reqURL, "POST")
 	resp, err := req.Response()
@@ -0,0 +1,78 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package private
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const signedTokenPrefix = "v1."
+
+// SignInternalToken returns a token for the internal API signed with secret at time t.
+// The token is only valid for [internal_api] TOKEN_LIFETIME around t.
+func SignInternalToken(secret string, t time.Time) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	return signedTokenPrefix + timestamp + "." + signTimestamp(secret, timestamp)
+}
+
+func signTimestamp(secret, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(timestamp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// internalToken returns the token to send to the internal API
+func internalToken() string {
+	if setting.InternalAPI.TokenLifetime > 0 {
+		return SignInternalToken(setting.InternalToken, time.Now())
+	}
+	return setting.InternalToken
+}
+
+// VerifyInternalToken checks whether the token provided to the internal API is valid at time now.
+// Tokens signed with (or equal to) one of the [internal_api] PREVIOUS_TOKENS are also accepted.
+func VerifyInternalToken(token string, now time.Time) bool {
+	secrets := append([]string{setting.InternalToken}, setting.InternalAPI.PreviousTokens...)
+
+	if setting.InternalAPI.TokenLifetime <= 0 {
+		for _, secret := range secrets {
+			if secret != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1 {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !strings.HasPrefix(token, signedTokenPrefix) {
+		return false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(token, signedTokenPrefix), ".", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	unix, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return false
+	}
+	// Allow for the same amount of clock skew between hosts as the lifetime of the token
+	age := now.Sub(time.Unix(unix, 0))
+	if age > setting.InternalAPI.TokenLifetime || age < -setting.InternalAPI.TokenLifetime {
+		return false
+	}
+	for _, secret := range secrets {
+		if secret != "" && hmac.Equal([]byte(parts[1]), []byte(signTimestamp(secret, parts[0]))) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package private
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyInternalToken(t *testing.T) {
+	oldToken, oldAPI := setting.InternalToken, setting.InternalAPI
+	defer func() {
+		setting.InternalToken, setting.InternalAPI = oldToken, oldAPI
+	}()
+
+	setting.InternalToken = "current"
+	setting.InternalAPI.PreviousTokens = []string{"previous"}
+	now := time.Unix(1600000000, 0)
+
+	setting.InternalAPI.TokenLifetime = 0
+	assert.True(t, VerifyInternalToken("current", now))
+	assert.True(t, VerifyInternalToken("previous", now))
+	assert.False(t, VerifyInternalToken("other", now))
+	assert.False(t, VerifyInternalToken("", now))
+	assert.False(t, VerifyInternalToken(SignInternalToken("current", now), now))
+
+	setting.InternalAPI.TokenLifetime = time.Minute
+	assert.True(t, VerifyInternalToken(SignInternalToken("current", now), now))
+	assert.True(t, VerifyInternalToken(SignInternalToken("previous", now), now))
+	assert.True(t, VerifyInternalToken(SignInternalToken("current", now.Add(-30*time.Second)), now))
+	assert.True(t, VerifyInternalToken(SignInternalToken("current", now.Add(30*time.Second)), now))
+	assert.False(t, VerifyInternalToken(SignInternalToken("current", now.Add(-2*time.Minute)), now))
+	assert.False(t, VerifyInternalToken(SignInternalToken("current", now.Add(2*time.Minute)), now))
+	assert.False(t, VerifyInternalToken(SignInternalToken("other", now), now))
+	assert.False(t, VerifyInternalToken("current", now))
+	assert.False(t, VerifyInternalToken("v1.1600000000.deadbeef", now))
+}
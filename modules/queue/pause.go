@@ -0,0 +1,78 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package queue
+
+import (
+	"sync"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+var (
+	pauseLock sync.RWMutex
+	// resumeChan is closed while the queues are not paused
+	resumeChan = newClosedChan()
+	// pauseChan is closed while the queues are paused
+	pauseChan = make(chan struct{})
+)
+
+func newClosedChan() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}
+
+// Pause stops the workers of all queues from handling further data until Resume is called.
+// Data which is already being handled is finished. Pushing data to the queues does not
+// block while they are paused: data which does not fit into a queue is held back until
+// the queues are resumed.
+func Pause() {
+	pauseLock.Lock()
+	defer pauseLock.Unlock()
+	select {
+	case <-resumeChan:
+		log.Info("Pausing queues")
+		resumeChan = make(chan struct{})
+		close(pauseChan)
+	default:
+	}
+}
+
+// Resume lets the workers of all queues handle data again
+func Resume() {
+	pauseLock.Lock()
+	defer pauseLock.Unlock()
+	select {
+	case <-resumeChan:
+	default:
+		log.Info("Resuming queues")
+		pauseChan = make(chan struct{})
+		close(resumeChan)
+	}
+}
+
+// IsPaused returns true if the queues are paused
+func IsPaused() bool {
+	select {
+	case <-resumed():
+		return false
+	default:
+		return true
+	}
+}
+
+// resumed returns a channel which is closed once the queues are not paused
+func resumed() <-chan struct{} {
+	pauseLock.RLock()
+	defer pauseLock.RUnlock()
+	return resumeChan
+}
+
+// paused returns a channel which is closed once the queues are paused
+func paused() <-chan struct{} {
+	pauseLock.RLock()
+	defer pauseLock.RUnlock()
+	return pauseChan
+}
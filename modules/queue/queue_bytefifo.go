@@ -115,6 +115,11 @@ func (q *ByteFIFOQueue) Run(atShutdown, atTerminate func(context.Context, func()
 
 func (q *ByteFIFOQueue) readToChan() {
 	for {
+		// leave the data in the byte fifo while the queues are paused
+		select {
+		case <-resumed():
+		case <-q.closed:
+		}
 		select {
 		case <-q.closed:
 			// tell the pool to shutdown.
@@ -96,3 +96,110 @@ func TestChannelQueue_Batch(t *testing.T) {
 	err = queue.Push(test1)
 	assert.Error(t, err)
 }
+
+func TestChannelQueue_Pause(t *testing.T) {
+	handleChan := make(chan *testData, 1)
+	handle := func(data ...Data) {
+		for _, datum := range data {
+			handleChan <- datum.(*testData)
+		}
+	}
+
+	nilFn := func(_ context.Context, _ func()) {}
+
+	queue, err := NewChannelQueue(handle,
+		ChannelQueueConfiguration{
+			WorkerPoolConfiguration: WorkerPoolConfiguration{
+				QueueLength:  10,
+				BatchLength:  1,
+				MaxWorkers:   1,
+				BlockTimeout: 0,
+				BoostTimeout: 0,
+				BoostWorkers: 0,
+			},
+			Workers: 1,
+			Name:    "TestChannelQueue_Pause",
+		}, &testData{})
+	assert.NoError(t, err)
+
+	Pause()
+	defer Resume()
+	assert.True(t, IsPaused())
+
+	go queue.Run(nilFn, nilFn)
+
+	test1 := testData{"A", 1}
+	assert.NoError(t, queue.Push(&test1))
+	select {
+	case <-handleChan:
+		assert.Fail(t, "data handled while the queues are paused")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	Resume()
+	assert.False(t, IsPaused())
+	select {
+	case result1 := <-handleChan:
+		assert.Equal(t, test1.TestString, result1.TestString)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "data not handled after resuming the queues")
+	}
+}
+
+func TestChannelQueue_PausePushFull(t *testing.T) {
+	handleChan := make(chan *testData, 25)
+	handle := func(data ...Data) {
+		for _, datum := range data {
+			handleChan <- datum.(*testData)
+		}
+	}
+
+	nilFn := func(_ context.Context, _ func()) {}
+
+	queue, err := NewChannelQueue(handle,
+		ChannelQueueConfiguration{
+			WorkerPoolConfiguration: WorkerPoolConfiguration{
+				QueueLength:  10,
+				BatchLength:  1,
+				MaxWorkers:   10,
+				BlockTimeout: 1 * time.Second,
+				BoostTimeout: 5 * time.Minute,
+				BoostWorkers: 5,
+			},
+			Workers: 1,
+			Name:    "TestChannelQueue_PausePushFull",
+		}, &testData{})
+	assert.NoError(t, err)
+
+	Pause()
+	defer Resume()
+
+	go queue.Run(nilFn, nilFn)
+
+	// pushing more data than fits into the channel must not block while paused
+	pushed := make(chan struct{})
+	go func() {
+		for i := 0; i < 25; i++ {
+			assert.NoError(t, queue.Push(&testData{"A", i}))
+		}
+		close(pushed)
+	}()
+	select {
+	case <-pushed:
+	case <-time.After(5 * time.Second):
+		assert.FailNow(t, "pushing blocked while the queues are paused")
+	}
+	assert.Len(t, handleChan, 0)
+	assert.False(t, queue.(*ChannelQueue).IsEmpty())
+
+	Resume()
+	handled := map[int]bool{}
+	for len(handled) < 25 {
+		select {
+		case result := <-handleChan:
+			handled[result.TestInt] = true
+		case <-time.After(5 * time.Second):
+			assert.FailNow(t, "held back data not handled after resuming the queues", "%d of 25 handled", len(handled))
+		}
+	}
+}
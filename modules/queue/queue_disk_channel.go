@@ -165,18 +165,51 @@ func (q *PersistableChannelQueue) Run(atShutdown, atTerminate func(context.Conte
 	log.Trace("PersistableChannelQueue: %s Waiting til done", q.delayedStarter.name)
 	q.channelQueue.Wait()
 	q.internal.(*LevelQueue).Wait()
-	// Redirect all remaining data in the chan to the internal channel
+	// Redirect all remaining data in the chan to the internal channel so it is persisted before the terminate
+	log.Trace("PersistableChannelQueue: %s Redirecting remaining data", q.delayedStarter.name)
+	q.redirectRemaining()
+	log.Trace("PersistableChannelQueue: %s Done Redirecting remaining data", q.delayedStarter.name)
+
+	// Data pushed whilst shutting down is redirected until the internal queue is terminated
 	go func() {
-		log.Trace("PersistableChannelQueue: %s Redirecting remaining data", q.delayedStarter.name)
-		for data := range q.channelQueue.dataChan {
-			_ = q.internal.Push(data)
-			atomic.AddInt64(&q.channelQueue.numInQueue, -1)
+		terminated := q.internal.(*LevelQueue).IsTerminated()
+		for {
+			select {
+			case data := <-q.channelQueue.dataChan:
+				if err := q.internal.Push(data); err != nil {
+					log.Error("PersistableChannelQueue: %s Unable to redirect data: %v", q.delayedStarter.name, err)
+				}
+				atomic.AddInt64(&q.channelQueue.numInQueue, -1)
+			case <-terminated:
+				return
+			}
 		}
-		log.Trace("PersistableChannelQueue: %s Done Redirecting remaining data", q.delayedStarter.name)
 	}()
 	log.Trace("PersistableChannelQueue: %s Done main loop", q.delayedStarter.name)
 }
 
+// redirectRemaining pushes the data left in the channel and the data held back while the
+// queues are paused to the internal queue without waiting for more
+func (q *PersistableChannelQueue) redirectRemaining() {
+	for _, data := range q.channelQueue.takeHeldBack() {
+		if err := q.internal.Push(data); err != nil {
+			log.Error("PersistableChannelQueue: %s Unable to redirect data: %v", q.delayedStarter.name, err)
+		}
+		atomic.AddInt64(&q.channelQueue.numInQueue, -1)
+	}
+	for {
+		select {
+		case data := <-q.channelQueue.dataChan:
+			if err := q.internal.Push(data); err != nil {
+				log.Error("PersistableChannelQueue: %s Unable to redirect data: %v", q.delayedStarter.name, err)
+			}
+			atomic.AddInt64(&q.channelQueue.numInQueue, -1)
+		default:
+			return
+		}
+	}
+}
+
 // Flush flushes the queue and blocks till the queue is empty
 func (q *PersistableChannelQueue) Flush(timeout time.Duration) error {
 	var ctx context.Context
@@ -115,3 +115,87 @@ func TestPersistableChannelQueue(t *testing.T) {
 	}
 
 }
+
+func TestPersistableChannelQueue_PushBackAtShutdown(t *testing.T) {
+	var queue Queue
+	started := make(chan struct{})
+	release := make(chan struct{})
+	// the handler pushes the data back once the shutdown has begun, as it is then persisted
+	handle := func(data ...Data) {
+		close(started)
+		<-release
+		for _, datum := range data {
+			assert.NoError(t, queue.Push(datum))
+		}
+	}
+
+	queueShutdown := []func(){}
+	queueTerminate := []func(){}
+
+	tmpDir, err := ioutil.TempDir("", "persistable-channel-queue-test-data")
+	assert.NoError(t, err)
+	defer util.RemoveAll(tmpDir)
+
+	cfg := PersistableChannelQueueConfiguration{
+		DataDir:     tmpDir,
+		BatchLength: 1,
+		QueueLength: 20,
+		Workers:     1,
+		MaxWorkers:  10,
+	}
+	queue, err = NewPersistableChannelQueue(handle, cfg, &testData{})
+	assert.NoError(t, err)
+
+	runDone := make(chan struct{})
+	go func() {
+		queue.Run(func(_ context.Context, shutdown func()) {
+			queueShutdown = append(queueShutdown, shutdown)
+		}, func(_ context.Context, terminate func()) {
+			queueTerminate = append(queueTerminate, terminate)
+		})
+		close(runDone)
+	}()
+
+	test1 := testData{"A", 1}
+	assert.NoError(t, queue.Push(&test1))
+	<-started
+	for _, callback := range queueShutdown {
+		callback()
+	}
+	close(release)
+	<-runDone
+	for _, callback := range queueTerminate {
+		callback()
+	}
+
+	// Reopen queue
+	handleChan := make(chan *testData, 1)
+	queue, err = NewPersistableChannelQueue(func(data ...Data) {
+		for _, datum := range data {
+			handleChan <- datum.(*testData)
+		}
+	}, cfg, &testData{})
+	assert.NoError(t, err)
+
+	queueShutdown = []func(){}
+	queueTerminate = []func(){}
+	go queue.Run(func(_ context.Context, shutdown func()) {
+		queueShutdown = append(queueShutdown, shutdown)
+	}, func(_ context.Context, terminate func()) {
+		queueTerminate = append(queueTerminate, terminate)
+	})
+
+	select {
+	case result := <-handleChan:
+		assert.Equal(t, test1.TestString, result.TestString)
+		assert.Equal(t, test1.TestInt, result.TestInt)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "The data pushed back at shutdown should have been handled after the restart")
+	}
+	for _, callback := range queueShutdown {
+		callback()
+	}
+	for _, callback := range queueTerminate {
+		callback()
+	}
+}
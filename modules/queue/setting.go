@@ -10,6 +10,7 @@ import (
 
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+
 	jsoniter "github.com/json-iterator/go"
 )
 
@@ -40,6 +41,7 @@ func getQueueSettings(name string) (setting.QueueSettings, []byte) {
 
 // CreateQueue for name with provided handler and exemplar
 func CreateQueue(name string, handle HandlerFunc, exemplar interface{}) Queue {
+	handle = tracedHandler(name, handle)
 	q, cfg := getQueueSettings(name)
 	if len(cfg) == 0 {
 		return nil
@@ -72,6 +74,7 @@ func CreateQueue(name string, handle HandlerFunc, exemplar interface{}) Queue {
 
 // CreateUniqueQueue for name with provided handler and exemplar
 func CreateUniqueQueue(name string, handle HandlerFunc, exemplar interface{}) UniqueQueue {
+	handle = tracedHandler(name, handle)
 	q, cfg := getQueueSettings(name)
 	if len(cfg) == 0 {
 		return nil
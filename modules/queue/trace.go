@@ -0,0 +1,118 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package queue
+
+import (
+	"context"
+	"reflect"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const traceParentHeader = "traceparent"
+
+// TraceContext is embedded in the items of a queue to continue the trace of the span they are pushed in
+type TraceContext struct {
+	TraceParent string `json:",omitempty"`
+}
+
+// traceParentCarrier carries the traceparent of a W3C trace context, the tracestate is dropped
+type traceParentCarrier struct {
+	traceParent *string
+}
+
+// Get implements propagation.TextMapCarrier
+func (c traceParentCarrier) Get(key string) string {
+	if key != traceParentHeader {
+		return ""
+	}
+	return *c.traceParent
+}
+
+// Set implements propagation.TextMapCarrier
+func (c traceParentCarrier) Set(key, value string) {
+	if key == traceParentHeader {
+		*c.traceParent = value
+	}
+}
+
+// SetSpanContext records the span of the context, it is left empty if the context has no valid span
+func (t *TraceContext) SetSpanContext(ctx context.Context) {
+	t.TraceParent = ""
+	propagation.TraceContext{}.Inject(ctx, traceParentCarrier{&t.TraceParent})
+}
+
+// WithSpanContext returns a copy of the context in the trace of the recorded span, e.g. for the handler of the item
+func (t TraceContext) WithSpanContext(ctx context.Context) context.Context {
+	if sc := t.spanContext(); sc.IsValid() {
+		return trace.ContextWithRemoteSpanContext(ctx, sc)
+	}
+	return ctx
+}
+
+// spanContext returns the recorded span, it is invalid if none has been recorded
+func (t TraceContext) spanContext() trace.SpanContext {
+	if t.TraceParent == "" {
+		return trace.SpanContext{}
+	}
+	ctx := propagation.TraceContext{}.Extract(context.Background(), traceParentCarrier{&t.TraceParent})
+	return trace.RemoteSpanContextFromContext(ctx)
+}
+
+// tracedData is implemented by the items which embed a TraceContext
+type tracedData interface {
+	spanContext() trace.SpanContext
+}
+
+// dataSpanContext returns the span recorded in the datum, a slice is traced by its first element
+func dataSpanContext(datum Data) trace.SpanContext {
+	if traced, ok := datum.(tracedData); ok {
+		return traced.spanContext()
+	}
+	if value := reflect.ValueOf(datum); value.Kind() == reflect.Slice && value.Len() > 0 {
+		if traced, ok := value.Index(0).Interface().(tracedData); ok {
+			return traced.spanContext()
+		}
+	}
+	return trace.SpanContext{}
+}
+
+// tracedHandler wraps the handler of a queue to create a span for every handled batch.
+// The span of a single item continues the trace the item has been pushed in,
+// the span of a batch is the root of a new trace linked to the traces of its items.
+func tracedHandler(name string, handle HandlerFunc) HandlerFunc {
+	return func(data ...Data) {
+		ctx := context.Background()
+		opts := []trace.SpanOption{
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(
+				label.String("queue.name", name),
+				label.Int("queue.batch_length", len(data)),
+			),
+		}
+		if len(data) == 1 {
+			if sc := dataSpanContext(data[0]); sc.IsValid() {
+				ctx = trace.ContextWithRemoteSpanContext(ctx, sc)
+			}
+		} else {
+			links := make([]trace.Link, 0, len(data))
+			for _, datum := range data {
+				if sc := dataSpanContext(datum); sc.IsValid() {
+					links = append(links, trace.Link{SpanContext: sc})
+				}
+			}
+			if len(links) > 0 {
+				opts = append(opts, trace.WithLinks(links...))
+			}
+		}
+
+		_, span := otel.Tracer("code.gitea.io/gitea/modules/queue").Start(ctx, "queue "+name, opts...)
+		defer span.End()
+		handle(data...)
+	}
+}
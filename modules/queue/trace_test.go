@@ -0,0 +1,98 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type testTracedData struct {
+	Name string
+	TraceContext
+}
+
+type recordingExporter struct {
+	spans []*exporttrace.SpanSnapshot
+}
+
+func (e *recordingExporter) ExportSpans(_ context.Context, spans []*exporttrace.SpanSnapshot) error {
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestTracedHandler(t *testing.T) {
+	exporter := &recordingExporter{}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	old := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(old)
+
+	ctx, request := provider.Tracer("test").Start(context.Background(), "GET /{username}/{reponame}")
+	first := &testTracedData{Name: "first"}
+	first.SetSpanContext(ctx)
+	second := testTracedData{Name: "second"}
+	second.SetSpanContext(ctx)
+	request.End()
+	assert.NotEmpty(t, first.TraceParent)
+	assert.Equal(t, first.TraceParent, second.TraceParent)
+
+	var handled []Data
+	handle := tracedHandler("test", func(data ...Data) {
+		handled = append(handled, data...)
+	})
+
+	// a single item, a slice of items or an item pushed by value continue the trace of the request
+	for _, datum := range []Data{first, []*testTracedData{first}, second} {
+		exporter.spans = nil
+		handle(datum)
+		if assert.Len(t, exporter.spans, 1) {
+			span := exporter.spans[0]
+			assert.Equal(t, "queue test", span.Name)
+			assert.Equal(t, trace.SpanKindConsumer, span.SpanKind)
+			assert.Equal(t, request.SpanContext().TraceID, span.SpanContext.TraceID)
+			assert.Equal(t, request.SpanContext().SpanID, span.ParentSpanID)
+			assert.True(t, span.HasRemoteParent)
+		}
+	}
+
+	// a batch is a new trace linked to the traces of its items
+	exporter.spans = nil
+	handle(first, &testTracedData{Name: "untraced"})
+	if assert.Len(t, exporter.spans, 1) {
+		span := exporter.spans[0]
+		assert.NotEqual(t, request.SpanContext().TraceID, span.SpanContext.TraceID)
+		assert.False(t, span.ParentSpanID.IsValid())
+		if assert.Len(t, span.Links, 1) {
+			assert.Equal(t, request.SpanContext().TraceID, span.Links[0].SpanContext.TraceID)
+			assert.Equal(t, request.SpanContext().SpanID, span.Links[0].SpanContext.SpanID)
+		}
+	}
+
+	// an untraced item is a new trace
+	exporter.spans = nil
+	handle(&testTracedData{Name: "untraced"})
+	if assert.Len(t, exporter.spans, 1) {
+		assert.False(t, exporter.spans[0].ParentSpanID.IsValid())
+		assert.Empty(t, exporter.spans[0].Links)
+	}
+	assert.Len(t, handled, 6)
+
+	// the handler of an item continues its trace
+	handlerCtx := first.WithSpanContext(context.Background())
+	assert.Equal(t, request.SpanContext().TraceID, trace.RemoteSpanContextFromContext(handlerCtx).TraceID)
+	handlerCtx = (&testTracedData{}).WithSpanContext(context.Background())
+	assert.False(t, trace.SpanContextFromContext(handlerCtx).IsValid())
+}
@@ -197,17 +197,42 @@ func (q *PersistableChannelUniqueQueue) Run(atShutdown, atTerminate func(context
 	log.Trace("PersistableChannelUniqueQueue: %s Waiting til done", q.delayedStarter.name)
 	q.ChannelUniqueQueue.Wait()
 	q.internal.(*LevelUniqueQueue).Wait()
-	// Redirect all remaining data in the chan to the internal channel
+	// Redirect all remaining data in the chan to the internal channel so it is persisted before the terminate
+	log.Trace("PersistableChannelUniqueQueue: %s Redirecting remaining data", q.delayedStarter.name)
+	q.redirectRemaining()
+	log.Trace("PersistableChannelUniqueQueue: %s Done Redirecting remaining data", q.delayedStarter.name)
+
+	// Data pushed whilst shutting down is redirected until the internal queue is terminated
 	go func() {
-		log.Trace("PersistableChannelUniqueQueue: %s Redirecting remaining data", q.delayedStarter.name)
-		for data := range q.ChannelUniqueQueue.dataChan {
-			_ = q.internal.Push(data)
+		terminated := q.internal.(*LevelUniqueQueue).IsTerminated()
+		for {
+			select {
+			case data := <-q.ChannelUniqueQueue.dataChan:
+				if err := q.internal.Push(data); err != nil && err != ErrAlreadyInQueue {
+					log.Error("PersistableChannelUniqueQueue: %s Unable to redirect data: %v", q.delayedStarter.name, err)
+				}
+			case <-terminated:
+				return
+			}
 		}
-		log.Trace("PersistableChannelUniqueQueue: %s Done Redirecting remaining data", q.delayedStarter.name)
 	}()
 	log.Trace("PersistableChannelUniqueQueue: %s Done main loop", q.delayedStarter.name)
 }
 
+// redirectRemaining pushes the data left in the channel to the internal queue without waiting for more
+func (q *PersistableChannelUniqueQueue) redirectRemaining() {
+	for {
+		select {
+		case data := <-q.ChannelUniqueQueue.dataChan:
+			if err := q.internal.Push(data); err != nil && err != ErrAlreadyInQueue {
+				log.Error("PersistableChannelUniqueQueue: %s Unable to redirect data: %v", q.delayedStarter.name, err)
+			}
+		default:
+			return
+		}
+	}
+}
+
 // Flush flushes the queue
 func (q *PersistableChannelUniqueQueue) Flush(timeout time.Duration) error {
 	return q.ChannelUniqueQueue.Flush(timeout)
@@ -33,6 +33,8 @@ type WorkerPool struct {
 	boostTimeout       time.Duration
 	boostWorkers       int
 	numInQueue         int64
+	heldLock           sync.Mutex
+	held               []Data
 }
 
 // WorkerPoolConfiguration is the basic configuration for a WorkerPool
@@ -65,27 +67,38 @@ func NewWorkerPool(handle HandlerFunc, config WorkerPoolConfiguration) *WorkerPo
 	return pool
 }
 
-// Push pushes the data to the internal channel
+// Push pushes the data to the internal channel. While the queues are paused
+// data which does not fit into the channel is held back instead of blocking.
 func (p *WorkerPool) Push(data Data) {
 	atomic.AddInt64(&p.numInQueue, 1)
+	if IsPaused() {
+		select {
+		case p.dataChan <- data:
+		default:
+			p.holdBack(data)
+		}
+		return
+	}
 	p.lock.Lock()
 	if p.blockTimeout > 0 && p.boostTimeout > 0 && (p.numberOfWorkers <= p.maxNumberOfWorkers || p.maxNumberOfWorkers < 0) {
 		p.lock.Unlock()
 		p.pushBoost(data)
 	} else {
 		p.lock.Unlock()
-		p.dataChan <- data
+		p.send(data)
 	}
 }
 
 func (p *WorkerPool) pushBoost(data Data) {
 	select {
 	case p.dataChan <- data:
+	case <-paused():
+		p.holdBack(data)
 	default:
 		p.lock.Lock()
 		if p.blockTimeout <= 0 {
 			p.lock.Unlock()
-			p.dataChan <- data
+			p.send(data)
 			return
 		}
 		ourTimeout := p.blockTimeout
@@ -94,11 +107,14 @@ func (p *WorkerPool) pushBoost(data Data) {
 		select {
 		case p.dataChan <- data:
 			util.StopTimer(timer)
+		case <-paused():
+			util.StopTimer(timer)
+			p.holdBack(data)
 		case <-timer.C:
 			p.lock.Lock()
 			if p.blockTimeout > ourTimeout || (p.numberOfWorkers > p.maxNumberOfWorkers && p.maxNumberOfWorkers >= 0) {
 				p.lock.Unlock()
-				p.dataChan <- data
+				p.send(data)
 				return
 			}
 			p.blockTimeout *= 2
@@ -130,11 +146,57 @@ func (p *WorkerPool) pushBoost(data Data) {
 			}()
 			p.lock.Unlock()
 			p.addWorkers(ctx, boost)
-			p.dataChan <- data
+			p.send(data)
 		}
 	}
 }
 
+// send sends the data to the internal channel, or holds it back if the queues are paused while waiting
+func (p *WorkerPool) send(data Data) {
+	select {
+	case p.dataChan <- data:
+	case <-paused():
+		p.holdBack(data)
+	}
+}
+
+// holdBack keeps data aside until the queues are resumed
+func (p *WorkerPool) holdBack(data Data) {
+	p.heldLock.Lock()
+	defer p.heldLock.Unlock()
+	p.held = append(p.held, data)
+	if len(p.held) == 1 {
+		go p.pushHeldBack()
+	}
+}
+
+// pushHeldBack sends the held back data to the internal channel once the queues are resumed
+func (p *WorkerPool) pushHeldBack() {
+	select {
+	case <-resumed():
+	case <-p.baseCtx.Done():
+		return
+	}
+	for _, data := range p.takeHeldBack() {
+		select {
+		case p.dataChan <- data:
+		case <-paused():
+			p.holdBack(data)
+		case <-p.baseCtx.Done():
+			p.holdBack(data)
+		}
+	}
+}
+
+// takeHeldBack removes the held back data and returns it
+func (p *WorkerPool) takeHeldBack() []Data {
+	p.heldLock.Lock()
+	defer p.heldLock.Unlock()
+	held := p.held
+	p.held = nil
+	return held
+}
+
 // NumberOfWorkers returns the number of current workers in the pool
 func (p *WorkerPool) NumberOfWorkers() int {
 	p.lock.Lock()
@@ -280,6 +342,16 @@ func (p *WorkerPool) CleanUp(ctx context.Context) {
 		default:
 		}
 	}
+	for _, data := range p.takeHeldBack() {
+		p.handle(data)
+		atomic.AddInt64(&p.numInQueue, -1)
+		select {
+		case <-ctx.Done():
+			log.Warn("WorkerPool: %d Cleanup context closed before finishing clean-up", p.qid)
+			return
+		default:
+		}
+	}
 	log.Trace("WorkerPool: %d CleanUp Done", p.qid)
 }
 
@@ -318,6 +390,11 @@ func (p *WorkerPool) doWork(ctx context.Context) {
 	delay := time.Millisecond * 300
 	var data = make([]Data, 0, p.batchLength)
 	for {
+		// wait while the queues are paused
+		select {
+		case <-resumed():
+		case <-ctx.Done():
+		}
 		select {
 		case <-ctx.Done():
 			if len(data) > 0 {
@@ -5,6 +5,7 @@
 package repofiles
 
 import (
+	"context"
 	"fmt"
 	"html"
 	"regexp"
@@ -95,7 +96,7 @@ func issueAddTime(issue *models.Issue, doer *models.User, time time.Time, timeLo
 	return err
 }
 
-func changeIssueStatus(repo *models.Repository, issue *models.Issue, doer *models.User, closed bool) error {
+func changeIssueStatus(ctx context.Context, repo *models.Repository, issue *models.Issue, doer *models.User, closed bool) error {
 	stopTimerIfAvailable := func(doer *models.User, issue *models.Issue) error {
 
 		if models.StopwatchExists(doer.ID, issue.ID) {
@@ -117,13 +118,13 @@ func changeIssueStatus(repo *models.Repository, issue *models.Issue, doer *model
 		return err
 	}
 
-	notification.NotifyIssueChangeStatus(doer, issue, comment, closed)
+	notification.NotifyIssueChangeStatus(ctx, doer, issue, comment, closed)
 
 	return stopTimerIfAvailable(doer, issue)
 }
 
 // UpdateIssuesCommit checks if issues are manipulated by commit message.
-func UpdateIssuesCommit(doer *models.User, repo *models.Repository, commits []*repository.PushCommit, branchName string) error {
+func UpdateIssuesCommit(ctx context.Context, doer *models.User, repo *models.Repository, commits []*repository.PushCommit, branchName string) error {
 	// Commits are appended in the reverse order.
 	for i := len(commits) - 1; i >= 0; i-- {
 		c := commits[i]
@@ -208,7 +209,7 @@ func UpdateIssuesCommit(doer *models.User, repo *models.Repository, commits []*r
 				}
 			}
 			if close != refIssue.IsClosed {
-				if err := changeIssueStatus(refRepo, refIssue, doer, close); err != nil {
+				if err := changeIssueStatus(ctx, refRepo, refIssue, doer, close); err != nil {
 					return err
 				}
 			}
@@ -5,6 +5,7 @@
 package repofiles
 
 import (
+	"context"
 	"testing"
 
 	"code.gitea.io/gitea/models"
@@ -57,7 +58,7 @@ func TestUpdateIssuesCommit(t *testing.T) {
 
 	models.AssertNotExistsBean(t, commentBean)
 	models.AssertNotExistsBean(t, &models.Issue{RepoID: repo.ID, Index: 2}, "is_closed=1")
-	assert.NoError(t, UpdateIssuesCommit(user, repo, pushCommits, repo.DefaultBranch))
+	assert.NoError(t, UpdateIssuesCommit(context.Background(), user, repo, pushCommits, repo.DefaultBranch))
 	models.AssertExistsAndLoadBean(t, commentBean)
 	models.AssertExistsAndLoadBean(t, issueBean, "is_closed=1")
 	models.CheckConsistencyFor(t, &models.Action{})
@@ -84,7 +85,7 @@ func TestUpdateIssuesCommit(t *testing.T) {
 
 	models.AssertNotExistsBean(t, commentBean)
 	models.AssertNotExistsBean(t, &models.Issue{RepoID: repo.ID, Index: 1}, "is_closed=1")
-	assert.NoError(t, UpdateIssuesCommit(user, repo, pushCommits, "non-existing-branch"))
+	assert.NoError(t, UpdateIssuesCommit(context.Background(), user, repo, pushCommits, "non-existing-branch"))
 	models.AssertExistsAndLoadBean(t, commentBean)
 	models.AssertNotExistsBean(t, issueBean, "is_closed=1")
 	models.CheckConsistencyFor(t, &models.Action{})
@@ -110,7 +111,7 @@ func TestUpdateIssuesCommit(t *testing.T) {
 
 	models.AssertNotExistsBean(t, commentBean)
 	models.AssertNotExistsBean(t, &models.Issue{RepoID: repo.ID, Index: 1}, "is_closed=1")
-	assert.NoError(t, UpdateIssuesCommit(user, repo, pushCommits, repo.DefaultBranch))
+	assert.NoError(t, UpdateIssuesCommit(context.Background(), user, repo, pushCommits, repo.DefaultBranch))
 	models.AssertExistsAndLoadBean(t, commentBean)
 	models.AssertExistsAndLoadBean(t, issueBean, "is_closed=1")
 	models.CheckConsistencyFor(t, &models.Action{})
@@ -136,7 +137,7 @@ func TestUpdateIssuesCommit_Colon(t *testing.T) {
 	issueBean := &models.Issue{RepoID: repo.ID, Index: 4}
 
 	models.AssertNotExistsBean(t, &models.Issue{RepoID: repo.ID, Index: 2}, "is_closed=1")
-	assert.NoError(t, UpdateIssuesCommit(user, repo, pushCommits, repo.DefaultBranch))
+	assert.NoError(t, UpdateIssuesCommit(context.Background(), user, repo, pushCommits, repo.DefaultBranch))
 	models.AssertExistsAndLoadBean(t, issueBean, "is_closed=1")
 	models.CheckConsistencyFor(t, &models.Action{})
 }
@@ -169,7 +170,7 @@ func TestUpdateIssuesCommit_Issue5957(t *testing.T) {
 
 	models.AssertNotExistsBean(t, commentBean)
 	models.AssertNotExistsBean(t, issueBean, "is_closed=1")
-	assert.NoError(t, UpdateIssuesCommit(user, repo, pushCommits, "non-existing-branch"))
+	assert.NoError(t, UpdateIssuesCommit(context.Background(), user, repo, pushCommits, "non-existing-branch"))
 	models.AssertExistsAndLoadBean(t, commentBean)
 	models.AssertExistsAndLoadBean(t, issueBean, "is_closed=1")
 	models.CheckConsistencyFor(t, &models.Action{})
@@ -204,7 +205,7 @@ func TestUpdateIssuesCommit_AnotherRepo(t *testing.T) {
 
 	models.AssertNotExistsBean(t, commentBean)
 	models.AssertNotExistsBean(t, issueBean, "is_closed=1")
-	assert.NoError(t, UpdateIssuesCommit(user, repo, pushCommits, repo.DefaultBranch))
+	assert.NoError(t, UpdateIssuesCommit(context.Background(), user, repo, pushCommits, repo.DefaultBranch))
 	models.AssertExistsAndLoadBean(t, commentBean)
 	models.AssertExistsAndLoadBean(t, issueBean, "is_closed=1")
 	models.CheckConsistencyFor(t, &models.Action{})
@@ -239,7 +240,7 @@ func TestUpdateIssuesCommit_AnotherRepo_FullAddress(t *testing.T) {
 
 	models.AssertNotExistsBean(t, commentBean)
 	models.AssertNotExistsBean(t, issueBean, "is_closed=1")
-	assert.NoError(t, UpdateIssuesCommit(user, repo, pushCommits, repo.DefaultBranch))
+	assert.NoError(t, UpdateIssuesCommit(context.Background(), user, repo, pushCommits, repo.DefaultBranch))
 	models.AssertExistsAndLoadBean(t, commentBean)
 	models.AssertExistsAndLoadBean(t, issueBean, "is_closed=1")
 	models.CheckConsistencyFor(t, &models.Action{})
@@ -289,7 +290,7 @@ func TestUpdateIssuesCommit_AnotherRepoNoPermission(t *testing.T) {
 	models.AssertNotExistsBean(t, commentBean)
 	models.AssertNotExistsBean(t, commentBean2)
 	models.AssertNotExistsBean(t, issueBean, "is_closed=1")
-	assert.NoError(t, UpdateIssuesCommit(user, repo, pushCommits, repo.DefaultBranch))
+	assert.NoError(t, UpdateIssuesCommit(context.Background(), user, repo, pushCommits, repo.DefaultBranch))
 	models.AssertNotExistsBean(t, commentBean)
 	models.AssertNotExistsBean(t, commentBean2)
 	models.AssertNotExistsBean(t, issueBean, "is_closed=1")
@@ -5,16 +5,19 @@
 package repofiles
 
 import (
+	"context"
 	"fmt"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/eventsource"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/notification"
 )
 
 // CreateCommitStatus creates a new CommitStatus given a bunch of parameters
 // NOTE: All text-values will be trimmed from whitespaces.
 // Requires: Repo, Creator, SHA
-func CreateCommitStatus(repo *models.Repository, creator *models.User, sha string, status *models.CommitStatus) error {
+func CreateCommitStatus(ctx context.Context, repo *models.Repository, creator *models.User, sha string, status *models.CommitStatus) error {
 	repoPath := repo.RepoPath()
 
 	// confirm that commit is exist
@@ -22,7 +25,8 @@ func CreateCommitStatus(repo *models.Repository, creator *models.User, sha strin
 	if err != nil {
 		return fmt.Errorf("OpenRepository[%s]: %v", repoPath, err)
 	}
-	if _, err := gitRepo.GetCommit(sha); err != nil {
+	commit, err := gitRepo.GetCommit(sha)
+	if err != nil {
 		gitRepo.Close()
 		return fmt.Errorf("GetCommit[%s]: %v", sha, err)
 	}
@@ -37,5 +41,7 @@ func CreateCommitStatus(repo *models.Repository, creator *models.User, sha strin
 		return fmt.Errorf("NewCommitStatus[repo_id: %d, user_id: %d, sha: %s]: %v", repo.ID, creator.ID, sha, err)
 	}
 
+	eventsource.GetManager().Publish(eventsource.CommitStatusTopic(repo.ID, sha), "status", creator.ID)
+	notification.NotifyCreateCommitStatus(ctx, creator, repo, commit, status)
 	return nil
 }
@@ -0,0 +1,284 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+// ApplyDiffPatchOptions holds the repository diff patch update options
+type ApplyDiffPatchOptions struct {
+	LastCommitID string
+	OldBranch    string
+	NewBranch    string
+	Message      string
+	Content      string
+	Author       *IdentityOptions
+	Committer    *IdentityOptions
+	Dates        *CommitDateOptions
+	Signoff      bool
+}
+
+// ApplyDiffPatch applies a patch in the unified diff format to the given repository
+// and commits the result
+func ApplyDiffPatch(repo *models.Repository, doer *models.User, opts *ApplyDiffPatchOptions) (*structs.FileResponse, error) {
+	// If no branch name is set, assume the repo's default branch
+	if opts.OldBranch == "" {
+		opts.OldBranch = repo.DefaultBranch
+	}
+	if opts.NewBranch == "" {
+		opts.NewBranch = opts.OldBranch
+	}
+
+	// oldBranch must exist for this operation
+	if _, err := repo_module.GetBranch(repo, opts.OldBranch); err != nil {
+		return nil, err
+	}
+
+	// A NewBranch can be specified for the patch to be applied in a new branch.
+	// Check to make sure the branch does not already exist, otherwise we can't proceed.
+	// If we aren't branching to a new branch, make sure user can commit to the given branch
+	var protectedBranch *models.ProtectedBranch
+	if opts.NewBranch != opts.OldBranch {
+		existingBranch, err := repo_module.GetBranch(repo, opts.NewBranch)
+		if existingBranch != nil {
+			return nil, models.ErrBranchAlreadyExists{
+				BranchName: opts.NewBranch,
+			}
+		}
+		if err != nil && !git.IsErrBranchNotExist(err) {
+			return nil, err
+		}
+	} else {
+		var err error
+		protectedBranch, err = repo.GetBranchProtection(opts.OldBranch)
+		if err != nil {
+			return nil, err
+		}
+		if protectedBranch != nil {
+			if !protectedBranch.CanUserPush(doer.ID) {
+				return nil, models.ErrUserCannotCommit{
+					UserName: doer.LowerName,
+				}
+			}
+			if protectedBranch.RequireSignedCommits {
+				_, _, _, err := repo.SignCRUDAction(doer, repo.RepoPath(), opts.OldBranch)
+				if err != nil {
+					if !models.IsErrWontSign(err) {
+						return nil, err
+					}
+					return nil, models.ErrUserCannotCommit{
+						UserName: doer.LowerName,
+					}
+				}
+			}
+		}
+	}
+
+	message := strings.TrimSpace(opts.Message)
+
+	author, committer := GetAuthorAndCommitterUsers(opts.Author, opts.Committer, doer)
+
+	t, err := NewTemporaryUploadRepository(repo)
+	if err != nil {
+		log.Error("%v", err)
+	}
+	defer t.Close()
+	if err := t.Clone(opts.OldBranch); err != nil {
+		return nil, err
+	}
+	if err := t.SetDefaultIndex(); err != nil {
+		return nil, err
+	}
+
+	// Get the commit of the original branch
+	commit, err := t.GetBranchCommit(opts.OldBranch)
+	if err != nil {
+		return nil, err // Couldn't get a commit for the branch
+	}
+
+	// Assigned LastCommitID in opts if it hasn't been set
+	if opts.LastCommitID == "" {
+		opts.LastCommitID = commit.ID.String()
+	} else {
+		lastCommitID, err := t.gitRepo.ConvertToSHA1(opts.LastCommitID)
+		if err != nil {
+			return nil, fmt.Errorf("ApplyDiffPatch: Invalid last commit ID: %v", err)
+		}
+		opts.LastCommitID = lastCommitID.String()
+		if commit.ID.String() != opts.LastCommitID {
+			return nil, models.ErrCommitIDDoesNotMatch{
+				GivenCommitID:   opts.LastCommitID,
+				CurrentCommitID: commit.ID.String(),
+			}
+		}
+	}
+
+	stdout := &strings.Builder{}
+	stderr := &strings.Builder{}
+	if err := git.NewCommand("apply", "--index", "--recount", "--cached", "--ignore-whitespace", "--whitespace=fix", "--binary").
+		RunInDirFullPipeline(t.basePath, stdout, stderr, strings.NewReader(opts.Content)); err != nil {
+		return nil, models.ErrPatchDoesNotApply{
+			Reason: strings.TrimSpace(stderr.String()),
+		}
+	}
+
+	// Changes to protected files are rejected like pushes of them
+	if protectedBranch != nil {
+		changed, err := git.NewCommand("diff-index", "--cached", "--name-only", "-z", "HEAD").RunInDir(t.basePath)
+		if err != nil {
+			return nil, fmt.Errorf("ApplyDiffPatch: unable to list changed files: %v", err)
+		}
+		patterns := protectedBranch.GetProtectedFilePatterns()
+		for _, treePath := range strings.Split(changed, "\x00") {
+			for _, pat := range patterns {
+				if treePath != "" && pat.Match(strings.ToLower(treePath)) {
+					return nil, models.ErrFilePathProtected{
+						Path: treePath,
+					}
+				}
+			}
+		}
+	}
+
+	// Now write the tree
+	treeHash, err := t.WriteTree()
+	if err != nil {
+		return nil, err
+	}
+
+	// Now commit the tree
+	var commitHash string
+	if opts.Dates != nil {
+		commitHash, err = t.CommitTreeWithDate(author, committer, treeHash, message, opts.Signoff, opts.Dates.Author, opts.Dates.Committer)
+	} else {
+		commitHash, err = t.CommitTree(author, committer, treeHash, message, opts.Signoff)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Then push this tree to NewBranch
+	if err := t.Push(doer, commitHash, opts.NewBranch); err != nil {
+		return nil, err
+	}
+
+	commit, err = t.GetCommit(commitHash)
+	if err != nil {
+		return nil, err
+	}
+
+	fileCommitResponse, err := GetFileCommitResponse(repo, commit)
+	if err != nil {
+		return nil, err
+	}
+	verification := GetPayloadCommitVerification(commit)
+	return &structs.FileResponse{
+		Commit:       fileCommitResponse,
+		Verification: verification,
+	}, nil
+}
+
+// lineReplacementPatch returns a patch in the unified diff format which replaces the lines
+// from start to end, counted from 1, of the file at treePath with replacement. The patch
+// includes up to three lines of context around the replaced lines.
+func lineReplacementPatch(treePath string, content []byte, start, end int, replacement string) (string, error) {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if start < 1 || end < start || end > len(lines) {
+		return "", fmt.Errorf("lines %d-%d are out of range of %s with %d lines", start, end, treePath, len(lines))
+	}
+
+	newLines := strings.SplitAfter(replacement, "\n")
+	if newLines[len(newLines)-1] == "" {
+		newLines = newLines[:len(newLines)-1]
+	}
+	// the replacement keeps whether the replaced lines end with a newline
+	if last := len(newLines) - 1; last >= 0 {
+		if strings.HasSuffix(lines[end-1], "\n") {
+			if !strings.HasSuffix(newLines[last], "\n") {
+				newLines[last] += "\n"
+			}
+		} else {
+			newLines[last] = strings.TrimSuffix(newLines[last], "\n")
+		}
+	}
+
+	const contextLines = 3
+	before := start - 1 - contextLines
+	if before < 0 {
+		before = 0
+	}
+	after := end + contextLines
+	if after > len(lines) {
+		after = len(lines)
+	}
+
+	var buf bytes.Buffer
+	writeLine := func(prefix string, line string) {
+		buf.WriteString(prefix)
+		buf.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			buf.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+
+	fmt.Fprintf(&buf, "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n", treePath, treePath, treePath, treePath)
+	oldCount := after - before
+	newCount := oldCount - (end - start + 1) + len(newLines)
+	oldStart, newStart := before+1, before+1
+	if newCount == 0 {
+		newStart = before
+	}
+	fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, line := range lines[before : start-1] {
+		writeLine(" ", line)
+	}
+	for _, line := range lines[start-1 : end] {
+		writeLine("-", line)
+	}
+	for _, line := range newLines {
+		writeLine("+", line)
+	}
+	for _, line := range lines[end:after] {
+		writeLine(" ", line)
+	}
+	return buf.String(), nil
+}
+
+// LineReplacementPatch returns a patch which replaces the lines from start to end of the
+// file at treePath in commit with replacement, to be applied with ApplyDiffPatch.
+func LineReplacementPatch(commit *git.Commit, treePath string, start, end int, replacement string) (string, error) {
+	entry, err := commit.GetTreeEntryByPath(treePath)
+	if err != nil {
+		return "", err
+	}
+	if entry.IsDir() || entry.IsSubModule() {
+		return "", models.ErrFilePathInvalid{
+			Message: fmt.Sprintf("%s is not a file", treePath),
+			Path:    treePath,
+		}
+	}
+	reader, err := entry.Blob().DataAsync()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	var content bytes.Buffer
+	if _, err := content.ReadFrom(reader); err != nil {
+		return "", err
+	}
+	return lineReplacementPatch(treePath, content.Bytes(), start, end, replacement)
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineReplacementPatch(t *testing.T) {
+	content := []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n")
+
+	patch, err := lineReplacementPatch("a.txt", content, 5, 5, "five")
+	assert.NoError(t, err)
+	assert.Equal(t, `diff --git a/a.txt b/a.txt
+--- a/a.txt
++++ b/a.txt
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`, patch)
+
+	patch, err = lineReplacementPatch("a.txt", content, 1, 2, "one\nand\ntwo\n")
+	assert.NoError(t, err)
+	assert.Equal(t, `diff --git a/a.txt b/a.txt
+--- a/a.txt
++++ b/a.txt
+@@ -1,5 +1,6 @@
+-1
+-2
++one
++and
++two
+ 3
+ 4
+ 5
+`, patch)
+
+	// an empty replacement deletes the lines
+	patch, err = lineReplacementPatch("a.txt", content, 9, 9, "")
+	assert.NoError(t, err)
+	assert.Equal(t, `diff --git a/a.txt b/a.txt
+--- a/a.txt
++++ b/a.txt
+@@ -6,4 +6,3 @@
+ 6
+ 7
+ 8
+-9
+`, patch)
+
+	patch, err = lineReplacementPatch("a.txt", []byte("1\n2"), 2, 2, "two\n")
+	assert.NoError(t, err)
+	assert.Equal(t, `diff --git a/a.txt b/a.txt
+--- a/a.txt
++++ b/a.txt
+@@ -1,2 +1,2 @@
+ 1
+-2
+\ No newline at end of file
++two
+\ No newline at end of file
+`, patch)
+
+	_, err = lineReplacementPatch("a.txt", content, 10, 10, "ten")
+	assert.Error(t, err)
+	_, err = lineReplacementPatch("a.txt", content, 3, 2, "")
+	assert.Error(t, err)
+}
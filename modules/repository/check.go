@@ -6,6 +6,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -18,32 +19,72 @@ import (
 	"xorm.io/builder"
 )
 
+// GitFsckRepo calls 'git fsck' to check the health of a single repository and records the result.
+// A system notice with the details is created if the check fails.
+func GitFsckRepo(ctx context.Context, repo *models.Repository, timeout time.Duration, args []string) error {
+	log.Trace("Running health check on repository %v", repo)
+	start := time.Now()
+	fsckErr := git.Fsck(ctx, repo.RepoPath(), timeout, args...)
+	if err := models.UpdateRepoFsckResult(repo.ID, start, fsckErr); err != nil {
+		log.Error("UpdateRepoFsckResult: %v", err)
+	}
+	if fsckErr != nil {
+		log.Warn("Failed to health check repository (%v): %v", repo, fsckErr)
+		if err := models.CreateRepositoryNotice("Repository %s failed the health check (git fsck) at %s and may be corrupted: %v\n"+
+			"Check it with 'git fsck --full' in %s and restore missing or damaged objects from a backup or an up-to-date clone. "+
+			"Health checks of this repository can be disabled in its advanced settings.",
+			repo.FullName(), start.Format(time.RFC3339), fsckErr, repo.RepoPath()); err != nil {
+			log.Error("CreateRepositoryNotice: %v", err)
+		}
+	}
+	return fsckErr
+}
+
 // GitFsck calls 'git fsck' to check repository health.
-func GitFsck(ctx context.Context, timeout time.Duration, args []string) error {
+// If batchSize is positive, only that many repositories are checked, the ones which
+// have not been checked for the longest time first, so that large instances are checked
+// in rolling windows over several runs. If maxDuration is positive, no more checks are
+// started after that duration.
+func GitFsck(ctx context.Context, timeout time.Duration, args []string, batchSize int, maxDuration time.Duration) error {
 	log.Trace("Doing: GitFsck")
 
-	if err := models.Iterate(
-		models.DefaultDBContext(),
-		new(models.Repository),
-		builder.Expr("id>0 AND is_fsck_enabled=?", true),
-		func(idx int, bean interface{}) error {
-			repo := bean.(*models.Repository)
-			select {
-			case <-ctx.Done():
-				return models.ErrCancelledf("before fsck of %s", repo.FullName())
-			default:
-			}
-			log.Trace("Running health check on repository %v", repo)
-			repoPath := repo.RepoPath()
-			if err := git.Fsck(ctx, repoPath, timeout, args...); err != nil {
-				log.Warn("Failed to health check repository (%v): %v", repo, err)
-				if err = models.CreateRepositoryNotice("Failed to health check repository (%s): %v", repo.FullName(), err); err != nil {
-					log.Error("CreateRepositoryNotice: %v", err)
+	start := time.Now()
+	check := func(repo *models.Repository) error {
+		select {
+		case <-ctx.Done():
+			return models.ErrCancelledf("before fsck of %s", repo.FullName())
+		default:
+		}
+		if maxDuration > 0 && time.Since(start) > maxDuration {
+			return errFsckWindowExceeded
+		}
+		_ = GitFsckRepo(ctx, repo, timeout, args)
+		return nil
+	}
+
+	var err error
+	if batchSize > 0 {
+		var repos []*models.Repository
+		if repos, err = models.GetRepositoriesForFsck(batchSize); err == nil {
+			for _, repo := range repos {
+				if err = check(repo); err != nil {
+					break
 				}
 			}
-			return nil
-		},
-	); err != nil {
+		}
+	} else {
+		err = models.Iterate(
+			models.DefaultDBContext(),
+			new(models.Repository),
+			builder.Expr("id>0 AND is_fsck_enabled=?", true),
+			func(idx int, bean interface{}) error {
+				return check(bean.(*models.Repository))
+			},
+		)
+	}
+	if err == errFsckWindowExceeded {
+		log.Trace("GitFsck: stopped after %v, remaining repositories are checked in the next run", maxDuration)
+	} else if err != nil {
 		log.Trace("Error: GitFsck: %v", err)
 		return err
 	}
@@ -52,10 +93,43 @@ func GitFsck(ctx context.Context, timeout time.Duration, args []string) error {
 	return nil
 }
 
+var errFsckWindowExceeded = errors.New("fsck window exceeded")
+
+// GitGcRepo calls 'git gc' on a single repository and records the result.
+// A system notice with the details is created if the garbage collection fails.
+func GitGcRepo(ctx context.Context, repo *models.Repository, timeout time.Duration, args ...string) error {
+	log.Trace("Running git gc on %v", repo)
+	command := git.NewCommandContext(ctx, append([]string{"gc"}, args...)...).
+		SetDescription(fmt.Sprintf("Repository Garbage Collection: %s", repo.FullName()))
+	var stdout string
+	var err error
+	start := time.Now()
+	if timeout > 0 {
+		var stdoutBytes []byte
+		stdoutBytes, err = command.RunInDirTimeout(
+			timeout,
+			repo.RepoPath())
+		stdout = string(stdoutBytes)
+	} else {
+		stdout, err = command.RunInDir(repo.RepoPath())
+	}
+	if err2 := models.UpdateRepoGCResult(repo.ID, start, err); err2 != nil {
+		log.Error("UpdateRepoGCResult: %v", err2)
+	}
+
+	if err != nil {
+		log.Error("Repository garbage collection failed for %v. Stdout: %s\nError: %v", repo, stdout, err)
+		if err2 := models.CreateRepositoryNotice("Repository garbage collection failed for %s. Stdout: %s\nError: %v", repo.RepoPath(), stdout, err); err2 != nil {
+			log.Error("CreateRepositoryNotice: %v", err2)
+		}
+		return fmt.Errorf("Repository garbage collection failed in repo: %s: Error: %v", repo.FullName(), err)
+	}
+	return nil
+}
+
 // GitGcRepos calls 'git gc' to remove unnecessary files and optimize the local repository
 func GitGcRepos(ctx context.Context, timeout time.Duration, args ...string) error {
 	log.Trace("Doing: GitGcRepos")
-	args = append([]string{"gc"}, args...)
 
 	if err := models.Iterate(
 		models.DefaultDBContext(),
@@ -68,30 +142,7 @@ func GitGcRepos(ctx context.Context, timeout time.Duration, args ...string) erro
 				return models.ErrCancelledf("before GC of %s", repo.FullName())
 			default:
 			}
-			log.Trace("Running git gc on %v", repo)
-			command := git.NewCommandContext(ctx, args...).
-				SetDescription(fmt.Sprintf("Repository Garbage Collection: %s", repo.FullName()))
-			var stdout string
-			var err error
-			if timeout > 0 {
-				var stdoutBytes []byte
-				stdoutBytes, err = command.RunInDirTimeout(
-					timeout,
-					repo.RepoPath())
-				stdout = string(stdoutBytes)
-			} else {
-				stdout, err = command.RunInDir(repo.RepoPath())
-			}
-
-			if err != nil {
-				log.Error("Repository garbage collection failed for %v. Stdout: %s\nError: %v", repo, stdout, err)
-				desc := fmt.Sprintf("Repository garbage collection failed for %s. Stdout: %s\nError: %v", repo.RepoPath(), stdout, err)
-				if err = models.CreateRepositoryNotice(desc); err != nil {
-					log.Error("CreateRepositoryNotice: %v", err)
-				}
-				return fmt.Errorf("Repository garbage collection failed in repo: %s: Error: %v", repo.FullName(), err)
-			}
-			return nil
+			return GitGcRepo(ctx, repo, timeout, args...)
 		},
 	); err != nil {
 		return err
@@ -9,6 +9,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/queue"
 )
 
 // PushUpdateOptions defines the push update options
@@ -20,6 +21,8 @@ type PushUpdateOptions struct {
 	RefFullName  string // branch, tag or other name to push
 	OldCommitID  string
 	NewCommitID  string
+	RequestID    string // ID of the request of the push, the push is handled in a queue
+	queue.TraceContext
 }
 
 // IsNewRef return true if it's a first-time push to a branch, tag or etc.
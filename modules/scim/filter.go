@@ -0,0 +1,178 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"fmt"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Comparison is an equality comparison of an attribute with a value
+type Comparison struct {
+	Attribute string // lower cased attribute path, e.g. "emails.value"
+	Value     string
+}
+
+// Filter is a conjunction of equality comparisons, which is the subset of
+// the SCIM filter syntax identity providers use to look up resources
+type Filter []Comparison
+
+// Get returns the value the attribute is compared to
+func (f Filter) Get(attribute string) (string, bool) {
+	for _, c := range f {
+		if c.Attribute == attribute {
+			return c.Value, true
+		}
+	}
+	return "", false
+}
+
+// filterTokens splits a filter into attribute paths, operators and values,
+// keeping quoted strings together
+func filterTokens(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == ' ':
+			i++
+		case s[i] == '"':
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string in filter")
+			}
+			tokens = append(tokens, s[i:j+1])
+			i = j + 1
+		default:
+			j := i
+			for j < len(s) && s[j] != ' ' && s[j] != '"' {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+func parseFilterValue(token string) (string, error) {
+	if strings.HasPrefix(token, `"`) {
+		var value string
+		json := jsoniter.ConfigCompatibleWithStandardLibrary
+		if err := json.Unmarshal([]byte(token), &value); err != nil {
+			return "", fmt.Errorf("invalid string %s in filter", token)
+		}
+		return value, nil
+	}
+	switch strings.ToLower(token) {
+	case "true", "false", "null":
+		return strings.ToLower(token), nil
+	}
+	for _, c := range token {
+		if (c < '0' || c > '9') && c != '.' && c != '-' {
+			return "", fmt.Errorf("invalid value %s in filter", token)
+		}
+	}
+	return token, nil
+}
+
+// ParseFilter parses a filter of the form `attribute eq "value" [and ...]`
+func ParseFilter(s string) (Filter, error) {
+	tokens, err := filterTokens(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	var filter Filter
+	for i := 0; ; i += 4 {
+		if len(tokens) < i+3 {
+			return nil, fmt.Errorf("incomplete filter expression")
+		}
+		if !strings.EqualFold(tokens[i+1], "eq") {
+			return nil, fmt.Errorf("unsupported filter operator %s", tokens[i+1])
+		}
+		value, err := parseFilterValue(tokens[i+2])
+		if err != nil {
+			return nil, err
+		}
+		filter = append(filter, Comparison{
+			Attribute: attributeName(tokens[i]),
+			Value:     value,
+		})
+
+		if len(tokens) == i+3 {
+			return filter, nil
+		}
+		if !strings.EqualFold(tokens[i+3], "and") {
+			return nil, fmt.Errorf("unsupported filter operator %s", tokens[i+3])
+		}
+	}
+}
+
+// attributeName lower cases an attribute path and strips the schema URI prefix
+func attributeName(s string) string {
+	s = strings.ToLower(s)
+	for _, schema := range []string{SchemaUser, SchemaGroup} {
+		if prefix := strings.ToLower(schema) + ":"; strings.HasPrefix(s, prefix) {
+			return s[len(prefix):]
+		}
+	}
+	return s
+}
+
+// Path represents the attribute path of a PATCH operation, e.g.
+// `members[value eq "2"]` or `emails[type eq "work"].value`
+type Path struct {
+	Attribute    string // lower cased
+	Filter       Filter
+	SubAttribute string // lower cased
+}
+
+// ParsePath parses the attribute path of a PATCH operation
+func ParsePath(s string) (*Path, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	p := &Path{}
+	if i := strings.IndexByte(s, '['); i >= 0 {
+		j := strings.LastIndexByte(s, ']')
+		if j < i {
+			return nil, fmt.Errorf("invalid path %s", s)
+		}
+		filter, err := ParseFilter(s[i+1 : j])
+		if err != nil {
+			return nil, err
+		}
+		p.Attribute = attributeName(s[:i])
+		p.Filter = filter
+		rest := s[j+1:]
+		if rest != "" {
+			if !strings.HasPrefix(rest, ".") || len(rest) == 1 {
+				return nil, fmt.Errorf("invalid path %s", s)
+			}
+			p.SubAttribute = strings.ToLower(rest[1:])
+		}
+		return p, nil
+	}
+
+	name := attributeName(s)
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		p.Attribute, p.SubAttribute = name[:i], name[i+1:]
+	} else {
+		p.Attribute = name
+	}
+	return p, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilter(t *testing.T) {
+	kases := []struct {
+		filter   string
+		expected Filter
+	}{
+		{``, nil},
+		{`userName eq "bjensen"`, Filter{{"username", "bjensen"}}},
+		{`userName Eq "a \"quoted\" name"`, Filter{{"username", `a "quoted" name`}}},
+		{`externalId eq "00u1" and active eq true`, Filter{{"externalid", "00u1"}, {"active", "true"}}},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:emails.value eq "a@b.c"`, Filter{{"emails.value", "a@b.c"}}},
+	}
+	for _, kase := range kases {
+		filter, err := ParseFilter(kase.filter)
+		assert.NoError(t, err, kase.filter)
+		assert.Equal(t, kase.expected, filter, kase.filter)
+	}
+
+	for _, invalid := range []string{
+		`userName`,
+		`userName eq`,
+		`userName co "bj"`,
+		`userName eq "bjensen`,
+		`userName eq bjensen`,
+		`userName eq "a" or userName eq "b"`,
+		`userName eq "a" and`,
+	} {
+		_, err := ParseFilter(invalid)
+		assert.Error(t, err, invalid)
+	}
+
+	filter, _ := ParseFilter(`displayName eq "Engineering"`)
+	value, ok := filter.Get("displayname")
+	assert.True(t, ok)
+	assert.Equal(t, "Engineering", value)
+	_, ok = filter.Get("externalid")
+	assert.False(t, ok)
+}
+
+func TestParsePath(t *testing.T) {
+	kases := []struct {
+		path     string
+		expected *Path
+	}{
+		{`active`, &Path{Attribute: "active"}},
+		{`name.givenName`, &Path{Attribute: "name", SubAttribute: "givenname"}},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:name.familyName`, &Path{Attribute: "name", SubAttribute: "familyname"}},
+		{`members[value eq "2"]`, &Path{Attribute: "members", Filter: Filter{{"value", "2"}}}},
+		{`emails[type eq "work"].value`, &Path{Attribute: "emails", Filter: Filter{{"type", "work"}}, SubAttribute: "value"}},
+	}
+	for _, kase := range kases {
+		path, err := ParsePath(kase.path)
+		assert.NoError(t, err, kase.path)
+		assert.Equal(t, kase.expected, path, kase.path)
+	}
+
+	for _, invalid := range []string{``, `members[value eq "2"`, `members[value eq "2"]value`, `members[value co "2"]`} {
+		_, err := ParsePath(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestUserNames(t *testing.T) {
+	u := &User{
+		DisplayName: "Babs",
+		Emails: []Email{
+			{Value: "work@example.com", Type: "work"},
+			{Value: "home@example.com", Type: "home", Primary: true},
+		},
+	}
+	assert.Equal(t, "home@example.com", u.PrimaryEmail())
+	assert.Equal(t, "Babs", u.FullName())
+
+	u.Name = &Name{GivenName: "Barbara", FamilyName: "Jensen"}
+	assert.Equal(t, "Barbara Jensen", u.FullName())
+	u.Name.Formatted = "Ms. Barbara J Jensen"
+	assert.Equal(t, "Ms. Barbara J Jensen", u.FullName())
+}
@@ -0,0 +1,262 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"fmt"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// PatchError represents an invalid PATCH operation
+type PatchError struct {
+	ScimType string
+	Detail   string
+}
+
+func (err *PatchError) Error() string {
+	return err.Detail
+}
+
+func patchErrorf(scimType, format string, args ...interface{}) *PatchError {
+	return &PatchError{ScimType: scimType, Detail: fmt.Sprintf(format, args...)}
+}
+
+func decodeString(attr string, value jsoniter.RawMessage) (string, error) {
+	var s string
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.Unmarshal(value, &s); err != nil {
+		return "", patchErrorf(ErrorInvalidValue, "%s must be a string", attr)
+	}
+	return s, nil
+}
+
+// decodeBool decodes a boolean, which some identity providers send as a string
+func decodeBool(attr string, value jsoniter.RawMessage) (bool, error) {
+	var b bool
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		switch strings.ToLower(s) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	return false, patchErrorf(ErrorInvalidValue, "%s must be a boolean", attr)
+}
+
+func decodeValue(attr string, value jsoniter.RawMessage, obj interface{}) error {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.Unmarshal(value, obj); err != nil {
+		return patchErrorf(ErrorInvalidValue, "invalid value of %s", attr)
+	}
+	return nil
+}
+
+// forEachOperation calls fn with the parsed path and the value of every add, replace
+// and remove operation. Operations without a path are split into one operation for
+// every attribute of their value.
+func forEachOperation(ops []PatchOperation, fn func(op string, path *Path, value jsoniter.RawMessage) error) error {
+	for _, operation := range ops {
+		op := strings.ToLower(operation.Op)
+		switch op {
+		case "add", "replace", "remove":
+		default:
+			return patchErrorf(ErrorInvalidSyntax, "unsupported operation %q", operation.Op)
+		}
+
+		if operation.Path != "" {
+			path, err := ParsePath(operation.Path)
+			if err != nil {
+				return patchErrorf(ErrorInvalidPath, "%v", err)
+			}
+			if err := fn(op, path, operation.Value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if op == "remove" {
+			return patchErrorf(ErrorNoTarget, "remove operations require a path")
+		}
+		var values map[string]jsoniter.RawMessage
+		if err := decodeValue("operation", operation.Value, &values); err != nil {
+			return err
+		}
+		for attr, value := range values {
+			path, err := ParsePath(attr)
+			if err != nil {
+				return patchErrorf(ErrorInvalidPath, "%v", err)
+			}
+			if err := fn(op, path, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyPatch applies the operations of a PATCH request to the user.
+// Unknown attributes are ignored.
+func (u *User) ApplyPatch(ops []PatchOperation) error {
+	return forEachOperation(ops, func(op string, path *Path, value jsoniter.RawMessage) error {
+		if op == "remove" {
+			u.removeAttribute(path)
+			return nil
+		}
+		return u.setAttribute(path, value)
+	})
+}
+
+func (u *User) setAttribute(path *Path, value jsoniter.RawMessage) (err error) {
+	switch path.Attribute {
+	case "username":
+		u.UserName, err = decodeString("userName", value)
+	case "displayname":
+		u.DisplayName, err = decodeString("displayName", value)
+	case "externalid":
+		u.ExternalID, err = decodeString("externalId", value)
+	case "password":
+		u.Password, err = decodeString("password", value)
+	case "active":
+		var active bool
+		active, err = decodeBool("active", value)
+		u.Active = &active
+	case "name":
+		if u.Name == nil {
+			u.Name = &Name{}
+		}
+		switch path.SubAttribute {
+		case "":
+			err = decodeValue("name", value, u.Name)
+		case "formatted":
+			u.Name.Formatted, err = decodeString("name.formatted", value)
+		case "givenname":
+			u.Name.GivenName, err = decodeString("name.givenName", value)
+			u.Name.Formatted = ""
+		case "familyname":
+			u.Name.FamilyName, err = decodeString("name.familyName", value)
+			u.Name.Formatted = ""
+		}
+	case "emails":
+		if path.SubAttribute == "" && len(path.Filter) == 0 {
+			var emails []Email
+			if err = decodeValue("emails", value, &emails); err == nil {
+				u.Emails = emails
+			}
+			return err
+		}
+		if path.SubAttribute != "value" {
+			return nil
+		}
+		var email string
+		if email, err = decodeString("emails.value", value); err != nil {
+			return err
+		}
+		emailType, _ := path.Filter.Get("type")
+		for i := range u.Emails {
+			if emailType == "" || strings.EqualFold(u.Emails[i].Type, emailType) {
+				u.Emails[i].Value = email
+				return nil
+			}
+		}
+		u.Emails = append(u.Emails, Email{Value: email, Type: emailType, Primary: len(u.Emails) == 0})
+	}
+	return err
+}
+
+func (u *User) removeAttribute(path *Path) {
+	switch path.Attribute {
+	case "displayname":
+		u.DisplayName = ""
+	case "externalid":
+		u.ExternalID = ""
+	case "name":
+		if path.SubAttribute == "" {
+			u.Name = nil
+		}
+	case "emails":
+		if path.SubAttribute == "" && len(path.Filter) == 0 {
+			u.Emails = nil
+		}
+	}
+}
+
+// ApplyPatch applies the operations of a PATCH request to the group.
+// Unknown attributes are ignored.
+func (g *Group) ApplyPatch(ops []PatchOperation) error {
+	return forEachOperation(ops, func(op string, path *Path, value jsoniter.RawMessage) (err error) {
+		switch path.Attribute {
+		case "displayname":
+			if op == "remove" {
+				return patchErrorf(ErrorMutability, "displayName is required")
+			}
+			g.DisplayName, err = decodeString("displayName", value)
+		case "externalid":
+			if op == "remove" {
+				g.ExternalID = ""
+				return nil
+			}
+			g.ExternalID, err = decodeString("externalId", value)
+		case "members":
+			return g.patchMembers(op, path, value)
+		}
+		return err
+	})
+}
+
+func (g *Group) patchMembers(op string, path *Path, value jsoniter.RawMessage) error {
+	var members []Reference
+	if len(value) > 0 && string(value) != "null" {
+		if err := decodeValue("members", value, &members); err != nil {
+			return err
+		}
+	}
+
+	switch op {
+	case "replace":
+		g.Members = members
+	case "add":
+		for _, member := range members {
+			if !g.hasMember(member.Value) {
+				g.Members = append(g.Members, member)
+			}
+		}
+	case "remove":
+		remove := make(map[string]bool, len(members))
+		for _, member := range members {
+			remove[member.Value] = true
+		}
+		if id, ok := path.Filter.Get("value"); ok {
+			remove[id] = true
+		} else if len(path.Filter) > 0 {
+			return patchErrorf(ErrorInvalidFilter, "members can only be filtered by value")
+		}
+		kept := make([]Reference, 0, len(g.Members))
+		for _, member := range g.Members {
+			// removing without a filter or a value removes all members
+			if len(remove) > 0 && !remove[member.Value] {
+				kept = append(kept, member)
+			}
+		}
+		g.Members = kept
+	}
+	return nil
+}
+
+func (g *Group) hasMember(id string) bool {
+	for _, member := range g.Members {
+		if member.Value == id {
+			return true
+		}
+	}
+	return false
+}
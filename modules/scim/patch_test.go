@@ -0,0 +1,91 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func patchRequest(t *testing.T, body string) []PatchOperation {
+	var req PatchRequest
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	assert.NoError(t, json.Unmarshal([]byte(body), &req))
+	return req.Operations
+}
+
+func TestUserApplyPatch(t *testing.T) {
+	active := true
+	u := &User{
+		UserName: "bjensen",
+		Name:     &Name{Formatted: "Barbara Jensen"},
+		Emails:   []Email{{Value: "bjensen@example.com", Type: "work", Primary: true}},
+		Active:   &active,
+	}
+
+	// Okta deactivation
+	assert.NoError(t, u.ApplyPatch(patchRequest(t, `{"Operations":[{"op":"replace","value":{"active":false}}]}`)))
+	assert.False(t, *u.Active)
+
+	// Azure AD style operations
+	assert.NoError(t, u.ApplyPatch(patchRequest(t, `{"Operations":[
+		{"op":"Replace","path":"active","value":"True"},
+		{"op":"Replace","path":"emails[type eq \"work\"].value","value":"babs@example.com"},
+		{"op":"Replace","path":"name.givenName","value":"Babs"},
+		{"op":"Replace","path":"name.familyName","value":"Jensen"},
+		{"op":"Add","path":"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department","value":"Tour"}
+	]}`)))
+	assert.True(t, *u.Active)
+	assert.Equal(t, "babs@example.com", u.PrimaryEmail())
+	assert.Equal(t, "Babs Jensen", u.FullName())
+
+	assert.NoError(t, u.ApplyPatch(patchRequest(t, `{"Operations":[{"op":"replace","path":"userName","value":"babs"}]}`)))
+	assert.Equal(t, "babs", u.UserName)
+
+	err := u.ApplyPatch(patchRequest(t, `{"Operations":[{"op":"replace","path":"active","value":"maybe"}]}`))
+	assert.Error(t, err)
+	assert.Equal(t, ErrorInvalidValue, err.(*PatchError).ScimType)
+
+	err = u.ApplyPatch(patchRequest(t, `{"Operations":[{"op":"remove"}]}`))
+	assert.Error(t, err)
+	assert.Equal(t, ErrorNoTarget, err.(*PatchError).ScimType)
+
+	err = u.ApplyPatch(patchRequest(t, `{"Operations":[{"op":"move","path":"userName"}]}`))
+	assert.Error(t, err)
+	assert.Equal(t, ErrorInvalidSyntax, err.(*PatchError).ScimType)
+}
+
+func TestGroupApplyPatch(t *testing.T) {
+	g := &Group{DisplayName: "Engineering", Members: []Reference{{Value: "1"}}}
+
+	assert.NoError(t, g.ApplyPatch(patchRequest(t, `{"Operations":[
+		{"op":"add","path":"members","value":[{"value":"1"},{"value":"2"},{"value":"3"}]}
+	]}`)))
+	assert.Equal(t, []Reference{{Value: "1"}, {Value: "2"}, {Value: "3"}}, g.Members)
+
+	// Okta removes members by filter, Azure AD by value
+	assert.NoError(t, g.ApplyPatch(patchRequest(t, `{"Operations":[
+		{"op":"remove","path":"members[value eq \"1\"]"},
+		{"op":"Remove","path":"members","value":[{"value":"3"}]}
+	]}`)))
+	assert.Equal(t, []Reference{{Value: "2"}}, g.Members)
+
+	assert.NoError(t, g.ApplyPatch(patchRequest(t, `{"Operations":[
+		{"op":"replace","value":{"displayName":"Developers","externalId":"abc","members":[{"value":"4"}]}}
+	]}`)))
+	assert.Equal(t, "Developers", g.DisplayName)
+	assert.Equal(t, "abc", g.ExternalID)
+	assert.Equal(t, []Reference{{Value: "4"}}, g.Members)
+
+	assert.NoError(t, g.ApplyPatch(patchRequest(t, `{"Operations":[{"op":"remove","path":"members"}]}`)))
+	assert.Empty(t, g.Members)
+
+	err := g.ApplyPatch(patchRequest(t, `{"Operations":[{"op":"remove","path":"displayName"}]}`))
+	assert.Error(t, err)
+	err = g.ApplyPatch(patchRequest(t, `{"Operations":[{"op":"remove","path":"members[display eq \"x\"]"}]}`))
+	assert.Error(t, err)
+}
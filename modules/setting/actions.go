@@ -0,0 +1,48 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"path"
+	"path/filepath"
+	"time"
+)
+
+var (
+	// Actions defines the settings of the built-in CI
+	Actions = struct {
+		Storage
+		Enabled bool
+		// LogPath is the local directory job logs are appended to
+		LogPath string
+		// RunnerOfflineTimeout is the time after which a runner that did not poll for jobs is shown as offline
+		RunnerOfflineTimeout time.Duration
+		// JobTokenLifetime is the time after which the token of a job expires even if the job is still running
+		JobTokenLifetime time.Duration
+		// MaxLogChunkSize is the maximum size in bytes of the output a runner appends to a log in one request
+		MaxLogChunkSize int64
+	}{
+		Enabled:              false,
+		RunnerOfflineTimeout: time.Minute,
+		JobTokenLifetime:     3 * time.Hour,
+		MaxLogChunkSize:      1 << 20,
+	}
+)
+
+func newActionsService() {
+	sec := Cfg.Section("actions")
+	Actions.Enabled = sec.Key("ENABLED").MustBool(false)
+	Actions.RunnerOfflineTimeout = sec.Key("RUNNER_OFFLINE_TIMEOUT").MustDuration(time.Minute)
+	Actions.JobTokenLifetime = sec.Key("JOB_TOKEN_LIFETIME").MustDuration(3 * time.Hour)
+	Actions.MaxLogChunkSize = sec.Key("MAX_LOG_CHUNK_SIZE").MustInt64(1024) << 10
+
+	Actions.LogPath = sec.Key("LOG_PATH").MustString(path.Join(AppDataPath, "actions_log"))
+	if !filepath.IsAbs(Actions.LogPath) {
+		Actions.LogPath = path.Join(AppWorkPath, Actions.LogPath)
+	}
+
+	storageType := sec.Key("STORAGE_TYPE").MustString("")
+	Actions.Storage = getStorage("actions_artifacts", storageType, sec)
+}
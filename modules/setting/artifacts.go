@@ -0,0 +1,38 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+var (
+	// Artifacts defines the settings of the build artifacts uploaded by external CI systems
+	Artifacts = struct {
+		Storage
+		Enabled bool
+		// MaxSize is the maximum size of an artifact in MB
+		MaxSize int64
+		// DefaultRetentionDays is the number of days an artifact is kept if the upload does not specify it, 0 keeps it forever
+		DefaultRetentionDays int
+		// MaxRetentionDays is the maximum number of days an artifact can be kept, 0 means no limit
+		MaxRetentionDays int
+	}{
+		Enabled:              true,
+		MaxSize:              1024,
+		DefaultRetentionDays: 90,
+		MaxRetentionDays:     0,
+	}
+)
+
+func newArtifactsService() {
+	sec := Cfg.Section("artifacts")
+	Artifacts.Enabled = sec.Key("ENABLED").MustBool(true)
+	Artifacts.MaxSize = sec.Key("MAX_SIZE").MustInt64(1024)
+	Artifacts.DefaultRetentionDays = sec.Key("DEFAULT_RETENTION_DAYS").MustInt(90)
+	Artifacts.MaxRetentionDays = sec.Key("MAX_RETENTION_DAYS").MustInt(0)
+	if Artifacts.MaxRetentionDays > 0 && (Artifacts.DefaultRetentionDays == 0 || Artifacts.DefaultRetentionDays > Artifacts.MaxRetentionDays) {
+		Artifacts.DefaultRetentionDays = Artifacts.MaxRetentionDays
+	}
+
+	storageType := sec.Key("STORAGE_TYPE").MustString("")
+	Artifacts.Storage = getStorage("artifacts", storageType, sec)
+}
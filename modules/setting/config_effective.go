@@ -0,0 +1,173 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+
+	ini "gopkg.in/ini.v1"
+)
+
+// ConfigSource is where the effective value of a setting comes from
+type ConfigSource string
+
+// The sources of the values of settings
+const (
+	ConfigSourceDefault     ConfigSource = "default"
+	ConfigSourceFile        ConfigSource = "file"
+	ConfigSourceEnvironment ConfigSource = "environment"
+)
+
+// EffectiveConfigValue is the value of a setting used by the running instance
+type EffectiveConfigValue struct {
+	Section string
+	Key     string
+	Value   string
+	Source  ConfigSource
+	// Masked is true if Value has been masked because it contains a secret
+	Masked bool
+}
+
+// environmentConfigKeys are the sections and keys whose values were set from
+// environment variables instead of the configuration file
+var environmentConfigKeys = map[string]map[string]bool{}
+
+var (
+	secretConfigKeyPattern  = regexp.MustCompile(`SECRET|(PASSWD|PASSWORD|TOKEN|PRIVATE_KEY|ACCESS_KEY_ID)$`)
+	configUserinfoPattern   = regexp.MustCompile(`^([^:/@\s]+):([^@\s]+)@`)
+	configKVPasswordPattern = regexp.MustCompile(`(?i)\b(password|passwd|pass)=([^\s,;&]+)`)
+)
+
+// secretConfigKeys are the keys holding secrets whose names do not match secretConfigKeyPattern
+var secretConfigKeys = map[string]bool{
+	"AZURE_BLOB_ACCOUNT_KEY": true,
+	"MINIO_SSE_C_KEY":        true,
+	"MEDIA_PROXY_KEY":        true,
+	"PREVIOUS_TOKENS":        true,
+}
+
+const maskedConfigValue = "******"
+
+// maskConfigValue hides the secrets in the value of a setting. Keys which are
+// secrets themselves are masked completely, the values of HTTP headers and
+// passwords embedded in connection strings are replaced.
+func maskConfigValue(key, value string) (string, bool) {
+	if value == "" {
+		return value, false
+	}
+	key = strings.ToUpper(key)
+	if secretConfigKeys[key] || secretConfigKeyPattern.MatchString(key) {
+		return maskedConfigValue, true
+	}
+	if key == "HEADERS" {
+		return maskConfigHeaders(value), true
+	}
+
+	masked := value
+	if strings.Contains(masked, "://") {
+		if u, err := url.Parse(masked); err == nil && u.User != nil {
+			if _, has := u.User.Password(); has {
+				atIdx := strings.Index(masked, "@")
+				colonIdx := strings.LastIndex(masked[:atIdx], ":")
+				masked = masked[:colonIdx+1] + maskedConfigValue + masked[atIdx:]
+			}
+		}
+	} else {
+		// mysql style "user:password@tcp(localhost:3306)/gitea"
+		masked = configUserinfoPattern.ReplaceAllString(masked, "$1:"+maskedConfigValue+"@")
+	}
+	// key-value style "network=tcp,addr=:6379,password=secret" or "user=gitea password=secret"
+	masked = configKVPasswordPattern.ReplaceAllString(masked, "$1="+maskedConfigValue)
+	return masked, masked != value
+}
+
+// maskConfigHeaders replaces the values of a comma separated name=value list of
+// HTTP headers, as they usually carry credentials such as Authorization
+func maskConfigHeaders(value string) string {
+	headers := strings.Split(value, ",")
+	for i, header := range headers {
+		if idx := strings.Index(header, "="); idx >= 0 {
+			headers[i] = header[:idx+1] + maskedConfigValue
+		} else if strings.TrimSpace(header) != "" {
+			headers[i] = maskedConfigValue
+		}
+	}
+	return strings.Join(headers, ",")
+}
+
+// EffectiveConfig returns the values of all settings used by the running instance
+// together with where each value comes from, with secrets masked. Keys which are
+// not set in the configuration file report the default Gitea used for them.
+// NewContext must have been called.
+func EffectiveConfig() []EffectiveConfigValue {
+	file := ini.Empty()
+	if isFile, _ := util.IsFile(CustomConf); isFile {
+		if err := file.Append(CustomConf); err != nil {
+			log.Error("Unable to load %s: %v", CustomConf, err)
+		}
+	}
+	return effectiveConfig(Cfg, file)
+}
+
+func effectiveConfig(cfg, file *ini.File) []EffectiveConfigValue {
+	values := make([]EffectiveConfigValue, 0, 500)
+	for _, sec := range cfg.Sections() {
+		section := sec.Name()
+		if section == ini.DefaultSection {
+			section = "DEFAULT"
+		}
+		for _, key := range sec.Keys() {
+			source := ConfigSourceDefault
+			if environmentConfigKeys[section][key.Name()] {
+				source = ConfigSourceEnvironment
+			} else if fileSec, err := file.GetSection(sec.Name()); err == nil && fileSec.HasKey(key.Name()) {
+				source = ConfigSourceFile
+			}
+			value, masked := maskConfigValue(key.Name(), key.Value())
+			values = append(values, EffectiveConfigValue{
+				Section: section,
+				Key:     key.Name(),
+				Value:   value,
+				Source:  source,
+				Masked:  masked,
+			})
+		}
+	}
+
+	sort.SliceStable(values, func(i, j int) bool {
+		if values[i].Section != values[j].Section {
+			if values[i].Section == "DEFAULT" || values[j].Section == "DEFAULT" {
+				return values[i].Section == "DEFAULT"
+			}
+			return values[i].Section < values[j].Section
+		}
+		return values[i].Key < values[j].Key
+	})
+	return values
+}
+
+// FilterEffectiveConfig returns the values from source whose section or key contains
+// keyword. An empty source or keyword matches all values.
+func FilterEffectiveConfig(values []EffectiveConfigValue, source ConfigSource, keyword string) []EffectiveConfigValue {
+	keyword = strings.ToLower(keyword)
+	filtered := make([]EffectiveConfigValue, 0, len(values))
+	for _, value := range values {
+		if source != "" && value.Source != source {
+			continue
+		}
+		if keyword != "" && !strings.Contains(strings.ToLower(value.Section), keyword) &&
+			!strings.Contains(strings.ToLower(value.Key), keyword) {
+			continue
+		}
+		filtered = append(filtered, value)
+	}
+	return filtered
+}
@@ -0,0 +1,192 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	ini "gopkg.in/ini.v1"
+)
+
+// EnvConfigKeyPrefix is the prefix of the environment variables which set configuration values.
+// It is followed by the section and the key separated by "__", e.g. GITEA__server__ROOT_URL.
+const EnvConfigKeyPrefix = "GITEA__"
+
+// The types of configuration values which are not strings
+const (
+	configTypeBool     = "bool"
+	configTypeInt      = "int"
+	configTypeFloat    = "float"
+	configTypeDuration = "duration"
+)
+
+var escapeRegex = regexp.MustCompile("_0[xX](([0-9a-fA-F][0-9a-fA-F])+)_")
+
+// DecodeEnvSectionKey will decode a portable string encoded Section__Key pair
+// Portable strings are considered to be of the form [A-Z0-9_]*
+// We will encode a disallowed value as the UTF8 byte string preceded by _0X and
+// followed by _. E.g. _0X2C_ for a '-' and _0X2E_ for '.'
+// Section and Key are separated by a plain '__'.
+// The entire section can be encoded as a UTF8 byte string
+func DecodeEnvSectionKey(encoded string) (string, string) {
+	section := ""
+	key := ""
+
+	inKey := false
+	last := 0
+	escapeStringIndices := escapeRegex.FindAllStringIndex(encoded, -1)
+	for _, unescapeIdx := range escapeStringIndices {
+		preceding := encoded[last:unescapeIdx[0]]
+		if !inKey {
+			if splitter := strings.Index(preceding, "__"); splitter > -1 {
+				section += preceding[:splitter]
+				inKey = true
+				key += preceding[splitter+2:]
+			} else {
+				section += preceding
+			}
+		} else {
+			key += preceding
+		}
+		toDecode := encoded[unescapeIdx[0]+3 : unescapeIdx[1]-1]
+		decodedBytes := make([]byte, len(toDecode)/2)
+		for i := 0; i < len(toDecode)/2; i++ {
+			// Can ignore error here as we know these should be hexadecimal from the regexp
+			byteInt, _ := strconv.ParseInt(toDecode[2*i:2*i+2], 16, 0)
+			decodedBytes[i] = byte(byteInt)
+		}
+		if inKey {
+			key += string(decodedBytes)
+		} else {
+			section += string(decodedBytes)
+		}
+		last = unescapeIdx[1]
+	}
+	remaining := encoded[last:]
+	if !inKey {
+		if splitter := strings.Index(remaining, "__"); splitter > -1 {
+			section += remaining[:splitter]
+			inKey = true
+			key += remaining[splitter+2:]
+		} else {
+			section += remaining
+		}
+	} else {
+		key += remaining
+	}
+	section = strings.ToLower(section)
+	return section, key
+}
+
+// unquoteEnvConfigValue removes the quotes the ini format allows around values,
+// so that values are read the same from the environment and the configuration file
+func unquoteEnvConfigValue(value string) string {
+	if len(value) >= 6 && strings.HasPrefix(value, `"""`) && strings.HasSuffix(value, `"""`) {
+		return value[3 : len(value)-3]
+	}
+	if len(value) >= 2 && value[0] == '`' && value[len(value)-1] == '`' {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// configKeyType returns the type a key is read as, or an empty string for strings
+func configKeyType(section, key string) string {
+	if typ, ok := configKeyTypes[section][key]; ok {
+		return typ
+	}
+	if storageConfigSections[section] {
+		return configKeyTypes["storage.*"][key]
+	}
+	for _, prefix := range configKeyGroups {
+		if strings.HasPrefix(section, prefix) {
+			return configKeyTypes[prefix+"*"][key]
+		}
+	}
+	return ""
+}
+
+// checkConfigValueType returns an error if the value of key cannot be parsed as the
+// type the setting is read as
+func checkConfigValueType(section string, key *ini.Key) error {
+	if key.Value() == "" {
+		return nil
+	}
+	var err error
+	typ := configKeyType(section, key.Name())
+	switch typ {
+	case configTypeBool:
+		_, err = key.Bool()
+	case configTypeInt:
+		_, err = key.Int64()
+	case configTypeFloat:
+		_, err = key.Float64()
+	case configTypeDuration:
+		_, err = key.Duration()
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%q is not a valid %s", key.Value(), typ)
+	}
+	return nil
+}
+
+// envSectionName returns the name of the section which matches the lower case
+// section name of an environment variable, e.g. U2F for u2f
+func envSectionName(cfg *ini.File, name string) string {
+	for _, section := range cfg.SectionStrings() {
+		if strings.EqualFold(section, name) {
+			return section
+		}
+	}
+	for section := range knownConfigKeys {
+		if strings.EqualFold(section, name) {
+			return section
+		}
+	}
+	return name
+}
+
+// loadConfigFromEnvironment sets the configuration values given by environment variables
+// of the form GITEA__SECTION_NAME__KEY_NAME in cfg, overriding the configuration file.
+// The keys which have been set are recorded for the effective configuration.
+func loadConfigFromEnvironment(cfg *ini.File, environ []string) error {
+	environmentConfigKeys = map[string]map[string]bool{}
+	for _, kv := range environ {
+		idx := strings.IndexByte(kv, '=')
+		if idx < 0 || !strings.HasPrefix(kv[:idx], EnvConfigKeyPrefix) {
+			continue
+		}
+		sectionName, keyName := DecodeEnvSectionKey(kv[len(EnvConfigKeyPrefix):idx])
+		if len(keyName) == 0 {
+			continue
+		}
+		if sectionName == "" || sectionName == "default" {
+			sectionName = ini.DefaultSection
+		} else {
+			sectionName = envSectionName(cfg, sectionName)
+		}
+
+		key := cfg.Section(sectionName).Key(keyName)
+		key.SetValue(unquoteEnvConfigValue(kv[idx+1:]))
+
+		section := sectionName
+		if section == ini.DefaultSection {
+			section = "DEFAULT"
+		}
+		if err := checkConfigValueType(section, key); err != nil {
+			return fmt.Errorf("invalid value of environment variable %s: %v", kv[:idx], err)
+		}
+		if environmentConfigKeys[section] == nil {
+			environmentConfigKeys[section] = map[string]bool{}
+		}
+		environmentConfigKeys[section][keyName] = true
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ini "gopkg.in/ini.v1"
+)
+
+func TestDecodeEnvSectionKey(t *testing.T) {
+	kases := []struct {
+		encoded, section, key string
+	}{
+		{"SERVER__ROOT_URL", "server", "ROOT_URL"},
+		{"LOG_0X2E_CONSOLE__COLORIZE", "log.console", "COLORIZE"},
+		{"REPOSITORY_0x2E_PULL_0X2D_REQUEST__WORK_IN_PROGRESS_PREFIXES", "repository.pull-request", "WORK_IN_PROGRESS_PREFIXES"},
+		{"__APP_NAME", "", "APP_NAME"},
+		{"SERVER", "server", ""},
+	}
+	for _, kase := range kases {
+		section, key := DecodeEnvSectionKey(kase.encoded)
+		assert.Equal(t, kase.section, section, kase.encoded)
+		assert.Equal(t, kase.key, key, kase.encoded)
+	}
+}
+
+func TestLoadConfigFromEnvironment(t *testing.T) {
+	defer func() {
+		environmentConfigKeys = map[string]map[string]bool{}
+	}()
+
+	cfg, err := ini.Load([]byte(`
+APP_NAME = Gitea
+
+[server]
+ROOT_URL = http://localhost:3000/
+
+[U2F]
+APP_ID = http://localhost:3000/
+`))
+	assert.NoError(t, err)
+
+	assert.NoError(t, loadConfigFromEnvironment(cfg, []string{
+		"PATH=/usr/bin",
+		"GITEA_CUSTOM=/data/gitea",
+		"GITEA__DEFAULT__APP_NAME=My Gitea",
+		"GITEA__server__ROOT_URL=https://git.example.com/",
+		"GITEA__SERVER__DISABLE_SSH=true",
+		"GITEA__LOG_0X2E_CONSOLE__COLORIZE=false",
+		"GITEA__U2F__TRUSTED_FACETS=https://git.example.com",
+		"GITEA__mailer__PASSWD=\"\"\"secret;#\"\"\"",
+	}))
+	assert.Equal(t, "My Gitea", cfg.Section("").Key("APP_NAME").String())
+	assert.Equal(t, "https://git.example.com/", cfg.Section("server").Key("ROOT_URL").String())
+	assert.True(t, cfg.Section("server").Key("DISABLE_SSH").MustBool())
+	assert.Equal(t, "false", cfg.Section("log.console").Key("COLORIZE").String())
+	assert.Equal(t, "https://git.example.com", cfg.Section("U2F").Key("TRUSTED_FACETS").String())
+	assert.Equal(t, "secret;#", cfg.Section("mailer").Key("PASSWD").String())
+
+	assert.True(t, environmentConfigKeys["DEFAULT"]["APP_NAME"])
+	assert.True(t, environmentConfigKeys["server"]["DISABLE_SSH"])
+	assert.True(t, environmentConfigKeys["U2F"]["TRUSTED_FACETS"])
+	assert.False(t, environmentConfigKeys["U2F"]["APP_ID"])
+
+	err = loadConfigFromEnvironment(cfg, []string{"GITEA__server__DISABLE_SSH=maybe"})
+	assert.EqualError(t, err, `invalid value of environment variable GITEA__server__DISABLE_SSH: "maybe" is not a valid bool`)
+	err = loadConfigFromEnvironment(cfg, []string{"GITEA__queue_0X2E_issue_indexer__BLOCK_TIMEOUT=1 second"})
+	assert.EqualError(t, err, `invalid value of environment variable GITEA__queue_0X2E_issue_indexer__BLOCK_TIMEOUT: "1 second" is not a valid duration`)
+	assert.NoError(t, loadConfigFromEnvironment(cfg, []string{"GITEA__queue_0X2E_issue_indexer__BLOCK_TIMEOUT=1s"}))
+}
+
+func TestValidateConfigTypesAndEnvironment(t *testing.T) {
+	defer func() {
+		environmentConfigKeys = map[string]map[string]bool{}
+	}()
+
+	file, err := ini.Load([]byte(`
+[server]
+SSH_PORT = twenty-two
+HTTP_PORT = 3000
+`))
+	assert.NoError(t, err)
+	assert.NoError(t, loadConfigFromEnvironment(ini.Empty(), []string{"GITEA__server__ROOT_URI=https://git.example.com/"}))
+
+	warnings := make(configWarnings, 0)
+	validateConfigKeys(file, &warnings)
+	validateEnvironmentConfigKeys(&warnings)
+	if assert.Len(t, warnings, 2) {
+		assert.Equal(t, ConfigWarningInvalid, warnings[0].Type)
+		assert.Equal(t, "[server] SSH_PORT", warnings[0].Location())
+		assert.Equal(t, ConfigWarningUnknownKey, warnings[1].Type)
+		assert.Equal(t, "[server] ROOT_URI", warnings[1].Location())
+	}
+}
@@ -0,0 +1,508 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Code generated by build/generate-config-keys.go. DO NOT EDIT.
+
+package setting
+
+// knownConfigKeys are the keys of the sections documented in the example configuration and the cheat sheet
+var knownConfigKeys = map[string][]string{
+	"DEFAULT":                        {"APP_NAME", "RUN_MODE", "RUN_USER"},
+	"U2F":                            {"APP_ID", "TRUSTED_FACETS"},
+	"actions":                        {"ENABLED", "JOB_TOKEN_LIFETIME", "LOG_PATH", "MAX_LOG_CHUNK_SIZE", "RUNNER_OFFLINE_TIMEOUT", "STORAGE_TYPE"},
+	"admin":                          {"DEFAULT_EMAIL_NOTIFICATIONS", "DISABLE_REGULAR_ORG_CREATION"},
+	"api":                            {"DEFAULT_GIT_TREES_PER_PAGE", "DEFAULT_MAX_BLOB_SIZE", "DEFAULT_PAGING_NUM", "ENABLE_SWAGGER", "MAX_RESPONSE_ITEMS"},
+	"artifacts":                      {"DEFAULT_RETENTION_DAYS", "ENABLED", "MAX_RETENTION_DAYS", "MAX_SIZE", "STORAGE_TYPE"},
+	"attachment":                     {"ALLOWED_TYPES", "AZURE_BLOB_ACCOUNT_KEY", "AZURE_BLOB_ACCOUNT_NAME", "AZURE_BLOB_BASE_PATH", "AZURE_BLOB_CONTAINER", "AZURE_BLOB_ENCRYPTION_SCOPE", "AZURE_BLOB_ENDPOINT", "ENABLED", "MAX_FILES", "MAX_SIZE", "MINIO_ACCESS_KEY_ID", "MINIO_BASE_PATH", "MINIO_BUCKET", "MINIO_ENDPOINT", "MINIO_EXPIRATION_DAYS", "MINIO_LOCATION", "MINIO_SECRET_ACCESS_KEY", "MINIO_SSE", "MINIO_SSE_C_KEY", "MINIO_SSE_KMS_KEY_ID", "MINIO_TRANSITION_DAYS", "MINIO_TRANSITION_STORAGE_CLASS", "MINIO_USE_SSL", "PATH", "SERVE_DIRECT", "STORAGE_TYPE"},
+	"cache":                          {"ADAPTER", "ENABLED", "HOST", "INTERVAL", "ITEM_TTL"},
+	"cache.last_commit":              {"COMMITS_COUNT", "ENABLED", "ITEM_TTL"},
+	"cors":                           {"ALLOW_CREDENTIALS", "ALLOW_DOMAIN", "ALLOW_SUBDOMAIN", "ENABLED", "MAX_AGE", "METHODS", "SCHEME"},
+	"cron":                           {"ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START"},
+	"cron.*":                         {"ARGS", "BATCH_SIZE", "CLEANUP", "CLEANUP_TYPE", "ENABLED", "ENFORCE_QUOTAS", "MAX_DURATION", "NOTIFY_ADMINS", "NO_SUCCESS_NOTICE", "NUMBER_TO_KEEP", "NUM_TOP_ENTRIES", "OLDER_THAN", "RUN_AT_START", "SCHEDULE", "TIMEOUT", "UPDATE_EXISTING"},
+	"cron.archive_cleanup":           {"ENABLED", "NO_SUCCESS_NOTICE", "OLDER_THAN", "RUN_AT_START", "SCHEDULE"},
+	"cron.check_issue_slas":          {"ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE"},
+	"cron.check_merge_queues":        {"ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE"},
+	"cron.check_repo_stats":          {"ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE"},
+	"cron.check_storage_consistency": {"CLEANUP", "ENABLED", "NOTIFY_ADMINS", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE"},
+	"cron.cleanup_hook_task_table":   {"CLEANUP_TYPE", "ENABLED", "NUMBER_TO_KEEP", "OLDER_THAN", "RUN_AT_START", "SCHEDULE"},
+	"cron.cleanup_packages":          {"ENABLED", "RUN_AT_START", "SCHEDULE"},
+	"cron.create_recurring_issues":   {"ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE"},
+	"cron.delete_expired_artifacts":  {"ENABLED", "RUN_AT_START", "SCHEDULE"},
+	"cron.delete_generated_repository_avatars": {"ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE"},
+	"cron.delete_inactive_accounts":            {"ENABLED", "NO_SUCCESS_NOTICE", "OLDER_THAN", "RUN_AT_START", "SCHEDULE"},
+	"cron.delete_missing_repos":                {"ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE"},
+	"cron.delete_old_api_usage":                {"ENABLED", "OLDER_THAN", "RUN_AT_START", "SCHEDULE"},
+	"cron.delete_repo_archives":                {"ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE"},
+	"cron.deleted_branches_cleanup":            {"ENABLED", "NO_SUCCESS_NOTICE", "OLDER_THAN", "RUN_AT_START", "SCHEDULE"},
+	"cron.gc_packages":                         {"ENABLED", "ENFORCE_QUOTAS", "RUN_AT_START", "SCHEDULE"},
+	"cron.git_gc_repos":                        {"ARGS", "ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE", "TIMEOUT"},
+	"cron.reinit_missing_repos":                {"ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE"},
+	"cron.repo_health_check":                   {"ARGS", "BATCH_SIZE", "ENABLED", "MAX_DURATION", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE", "TIMEOUT"},
+	"cron.repo_maintenance":                    {"ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE"},
+	"cron.report_instance_stats":               {"ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE"},
+	"cron.resync_all_hooks":                    {"ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE"},
+	"cron.resync_all_sshkeys":                  {"ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE"},
+	"cron.send_due_date_reminders":             {"ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE"},
+	"cron.sync_external_users":                 {"ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE", "UPDATE_EXISTING"},
+	"cron.sync_ldap_group_teams":               {"ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE"},
+	"cron.update_migration_poster_id":          {"ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE"},
+	"cron.update_mirrors":                      {"ENABLED", "NO_SUCCESS_NOTICE", "RUN_AT_START", "SCHEDULE"},
+	"cron.update_storage_statistics":           {"ENABLED", "NO_SUCCESS_NOTICE", "NUM_TOP_ENTRIES", "OLDER_THAN", "RUN_AT_START", "SCHEDULE"},
+	"database":                                 {"CHARSET", "CONN_MAX_LIFETIME", "DB_RETRIES", "DB_RETRY_BACKOFF", "DB_TYPE", "HOST", "ITERATE_BUFFER_SIZE", "LOG_SQL", "MAX_IDLE_CONNS", "MAX_OPEN_CONNS", "NAME", "PASSWD", "PATH", "SCHEMA", "SQLITE_TIMEOUT", "SSL_MODE", "USER"},
+	"git":                                      {"BRANCHES_RANGE_SIZE", "COMMITS_RANGE_SIZE", "DISABLE_DIFF_HIGHLIGHT", "DISABLE_PARTIAL_CLONE", "ENABLE_AUTO_GIT_WIRE_PROTOCOL", "ENABLE_BUNDLE_URI", "GC_ARGS", "MAX_GIT_DIFF_FILES", "MAX_GIT_DIFF_LINES", "MAX_GIT_DIFF_LINE_CHARACTERS", "PATH", "PULL_REQUEST_PUSH_MESSAGE", "REQUIRE_WIRE_PROTOCOL_V2", "VERBOSE_PUSH", "VERBOSE_PUSH_DELAY"},
+	"git.pack_cache":                           {"ENABLED", "MAX_SIZE", "PATH", "TTL"},
+	"git.refs_cache":                           {"ENABLED", "MAX_SIZE", "TTL"},
+	"git.timeout":                              {"CLONE", "DEFAULT", "GC", "MIGRATE", "MIRROR", "PULL"},
+	"i18n":                                     {"LANGS", "NAMES"},
+	"ide":                                      {"APPS", "ENABLED", "GITPOD_URL"},
+	"ide.*":                                    {"APPS", "DISPLAY_NAME", "ENABLED", "GITPOD_URL", "URL"},
+	"indexer":                                  {"ISSUE_INDEXER_CONN_STR", "ISSUE_INDEXER_HIGHLIGHT", "ISSUE_INDEXER_HIGHLIGHT_FRAGMENTS", "ISSUE_INDEXER_HIGHLIGHT_FRAGMENT_SIZE", "ISSUE_INDEXER_NAME", "ISSUE_INDEXER_PATH", "ISSUE_INDEXER_QUEUE_BATCH_NUMBER", "ISSUE_INDEXER_QUEUE_CONN_STR", "ISSUE_INDEXER_QUEUE_DIR", "ISSUE_INDEXER_QUEUE_TYPE", "ISSUE_INDEXER_TYPE", "MAX_FILE_SIZE", "REPO_INDEXER_CONN_STR", "REPO_INDEXER_ENABLED", "REPO_INDEXER_EXCLUDE", "REPO_INDEXER_EXCLUDE_VENDORED", "REPO_INDEXER_INCLUDE", "REPO_INDEXER_NAME", "REPO_INDEXER_PATH", "REPO_INDEXER_TYPE", "STARTUP_TIMEOUT", "UPDATE_BUFFER_LEN"},
+	"instance_stats":                           {"ENABLED", "ENDPOINT", "HEADERS", "INSTANCE_NAME", "TIMEOUT"},
+	"internal_api":                             {"CA_FILE", "CERT_FILE", "CLIENT_CA_FILE", "CLIENT_CERT_FILE", "CLIENT_KEY_FILE", "HTTP_ADDR", "HTTP_PORT", "KEY_FILE", "LOCAL_ROOT_URL", "PREVIOUS_TOKENS", "PROTOCOL", "TOKEN_LIFETIME", "UNIX_SOCKET_PERMISSION"},
+	"lfs":                                      {"AZURE_BLOB_ACCOUNT_KEY", "AZURE_BLOB_ACCOUNT_NAME", "AZURE_BLOB_BASE_PATH", "AZURE_BLOB_CONTAINER", "AZURE_BLOB_ENCRYPTION_SCOPE", "AZURE_BLOB_ENDPOINT", "MINIO_ACCESS_KEY_ID", "MINIO_BASE_PATH", "MINIO_BUCKET", "MINIO_ENDPOINT", "MINIO_EXPIRATION_DAYS", "MINIO_LOCATION", "MINIO_SECRET_ACCESS_KEY", "MINIO_SSE", "MINIO_SSE_C_KEY", "MINIO_SSE_KMS_KEY_ID", "MINIO_TRANSITION_DAYS", "MINIO_TRANSITION_STORAGE_CLASS", "MINIO_USE_SSL", "PATH", "SERVE_DIRECT", "STORAGE_TYPE"},
+	"log":                                      {"ACCESS", "ACCESS_LOG_TEMPLATE", "BUFFER_LEN", "COLORIZE", "ENABLE_ACCESS_LOG", "ENABLE_XORM_LOG", "EXPRESSION", "FLAGS", "FORMAT", "LEVEL", "MODE", "MODULE_LEVELS", "PREFIX", "ROOT_PATH", "ROUTER", "ROUTER_LOG_LEVEL", "STACKTRACE_LEVEL"},
+	"log.*":                                    {"ACCESS", "ACCESS_LOG_TEMPLATE", "ADDR", "BUFFER_LEN", "COLORIZE", "COMPRESS", "COMPRESSION_LEVEL", "DAILY_ROTATE", "ENABLE_ACCESS_LOG", "ENABLE_XORM_LOG", "EXPRESSION", "FILE_NAME", "FLAGS", "FORMAT", "HOST", "LEVEL", "LOG_ROTATE", "MAX_DAYS", "MAX_SIZE_SHIFT", "MODE", "MODULE_LEVELS", "PASSWD", "PREFIX", "PROTOCOL", "RECEIVERS", "RECONNECT", "RECONNECT_ON_MSG", "ROOT_PATH", "ROUTER", "ROUTER_LOG_LEVEL", "STACKTRACE_LEVEL", "STDERR", "SUBJECT", "USER"},
+	"log.conn":                                 {"ADDR", "LEVEL", "PROTOCOL", "RECONNECT", "RECONNECT_ON_MSG"},
+	"log.console":                              {"LEVEL", "STDERR"},
+	"log.file":                                 {"COMPRESS", "COMPRESSION_LEVEL", "DAILY_ROTATE", "FILE_NAME", "LEVEL", "LOG_ROTATE", "MAX_DAYS", "MAX_SIZE_SHIFT"},
+	"log.smtp":                                 {"HOST", "LEVEL", "PASSWD", "RECEIVERS", "SUBJECT", "USER"},
+	"mailer":                                   {"CERT_FILE", "DISABLE_HELO", "ENABLED", "FROM", "HELO_HOSTNAME", "HOST", "IS_TLS_ENABLED", "KEY_FILE", "MAILER_TYPE", "PASSWD", "SENDMAIL_ARGS", "SENDMAIL_PATH", "SENDMAIL_TIMEOUT", "SEND_AS_PLAIN_TEXT", "SEND_BUFFER_LEN", "SKIP_VERIFY", "SUBJECT_PREFIX", "USER", "USE_CERTIFICATE"},
+	"maintenance":                              {"ENABLED", "MESSAGE"},
+	"markdown":                                 {"CUSTOM_URL_SCHEMES", "ENABLE_HARD_LINE_BREAK_IN_COMMENTS", "ENABLE_HARD_LINE_BREAK_IN_DOCUMENTS", "FILE_EXTENSIONS"},
+	"markup":                                   {"ALLOW_ATTR", "ELEMENT", "GITEA_PREFIX_RAW", "GITEA_PREFIX_SRC", "MEDIA_PROXY_ENABLED", "MEDIA_PROXY_KEY", "MEDIA_PROXY_MAX_SIZE", "MEDIA_PROXY_SERVER_URL", "REGEXP"},
+	"markup.*":                                 {"ALLOW_ATTR", "ELEMENT", "ENABLED", "FILE_EXTENSIONS", "GITEA_PREFIX_RAW", "GITEA_PREFIX_SRC", "IS_INPUT_FILE", "MEDIA_PROXY_ENABLED", "MEDIA_PROXY_KEY", "MEDIA_PROXY_MAX_SIZE", "MEDIA_PROXY_SERVER_URL", "REGEXP", "RENDER_COMMAND"},
+	"markup.asciidoc":                          {"ENABLED", "FILE_EXTENSIONS", "IS_INPUT_FILE", "RENDER_COMMAND"},
+	"markup.sanitizer.1":                       {"ALLOW_ATTR", "ELEMENT", "REGEXP"},
+	"metrics":                                  {"ENABLED", "TOKEN"},
+	"migrations":                               {"ALLOWED_DOMAINS", "ALLOW_LOCALNETWORKS", "BLOCKED_DOMAINS", "MAX_ATTEMPTS", "RETRY_BACKOFF"},
+	"mirror":                                   {"DEFAULT_INTERVAL", "FAILURE_THRESHOLD", "MIN_INTERVAL"},
+	"oauth2":                                   {"ACCESS_TOKEN_EXPIRATION_TIME", "ENABLE", "INVALIDATE_REFRESH_TOKENS", "JWT_SECRET", "MAX_TOKEN_LENGTH", "REFRESH_TOKEN_EXPIRATION_TIME"},
+	"openid":                                   {"BLACKLISTED_URIS", "ENABLE_OPENID_SIGNIN", "ENABLE_OPENID_SIGNUP", "WHITELISTED_URIS"},
+	"other":                                    {"SHOW_FOOTER_BRANDING", "SHOW_FOOTER_TEMPLATE_LOAD_TIME", "SHOW_FOOTER_VERSION"},
+	"packages":                                 {"CHUNKED_UPLOAD_PATH", "ENABLED", "LIMIT_SIZE_CONTAINER", "LIMIT_SIZE_MAVEN", "LIMIT_SIZE_NPM", "LIMIT_SIZE_NUGET", "LIMIT_SIZE_PYPI", "LIMIT_TOTAL_OWNER_SIZE", "STORAGE_TYPE"},
+	"picture":                                  {"AVATAR_MAX_FILE_SIZE", "AVATAR_MAX_HEIGHT", "AVATAR_MAX_WIDTH", "AVATAR_STORAGE_TYPE", "AVATAR_UPLOAD_PATH", "DISABLE_GRAVATAR", "ENABLE_FEDERATED_AVATAR", "GRAVATAR_SOURCE", "REPOSITORY_AVATAR_FALLBACK", "REPOSITORY_AVATAR_FALLBACK_IMAGE", "REPOSITORY_AVATAR_STORAGE_TYPE", "REPOSITORY_AVATAR_UPLOAD_PATH"},
+	"privacy":                                  {"IP_LOGGING", "MAIL_AVATARS", "PUBLIC_USER_FIELDS"},
+	"project":                                  {"PROJECT_BOARD_BASIC_KANBAN_TYPE", "PROJECT_BOARD_BUG_TRIAGE_TYPE"},
+	"queue":                                    {"BATCH_LENGTH", "BLOCK_TIMEOUT", "BOOST_TIMEOUT", "BOOST_WORKERS", "CONN_STR", "DATADIR", "LENGTH", "MAX_ATTEMPTS", "MAX_WORKERS", "QUEUE_NAME", "SET_NAME", "TIMEOUT", "TYPE", "WORKERS", "WRAP_IF_NECESSARY"},
+	"queue.*":                                  {"BATCH_LENGTH", "BLOCK_TIMEOUT", "BOOST_TIMEOUT", "BOOST_WORKERS", "CONN_STR", "DATADIR", "LENGTH", "MAX_ATTEMPTS", "MAX_WORKERS", "QUEUE_NAME", "SET_NAME", "TIMEOUT", "TYPE", "WORKERS", "WRAP_IF_NECESSARY"},
+	"repo-archive":                             {"AZURE_BLOB_ACCOUNT_KEY", "AZURE_BLOB_ACCOUNT_NAME", "AZURE_BLOB_BASE_PATH", "AZURE_BLOB_CONTAINER", "AZURE_BLOB_ENCRYPTION_SCOPE", "AZURE_BLOB_ENDPOINT", "MAX_CACHE_SIZE", "MINIO_ACCESS_KEY_ID", "MINIO_BASE_PATH", "MINIO_BUCKET", "MINIO_ENDPOINT", "MINIO_EXPIRATION_DAYS", "MINIO_LOCATION", "MINIO_SECRET_ACCESS_KEY", "MINIO_SSE", "MINIO_SSE_C_KEY", "MINIO_SSE_KMS_KEY_ID", "MINIO_TRANSITION_DAYS", "MINIO_TRANSITION_STORAGE_CLASS", "MINIO_USE_SSL", "PATH", "SERVE_DIRECT", "STORAGE_TYPE"},
+	"repository":                               {"ACCESS_CONTROL_ALLOW_ORIGIN", "ALLOW_ADOPTION_OF_UNADOPTED_REPOSITORIES", "ALLOW_DELETION_OF_UNADOPTED_REPOSITORIES", "ANSI_CHARSET", "DEFAULT_BRANCH", "DEFAULT_CLOSE_ISSUES_VIA_COMMITS_IN_ANY_BRANCH", "DEFAULT_PRIVATE", "DEFAULT_PUSH_CREATE_PRIVATE", "DEFAULT_REPO_UNITS", "DETECTED_CHARSETS_ORDER", "DISABLED_REPO_UNITS", "DISABLE_HTTP_GIT", "DISABLE_MIGRATIONS", "DISABLE_MIRRORS", "ENABLE_PUSH_CREATE_ORG", "ENABLE_PUSH_CREATE_USER", "FORCE_PRIVATE", "MAX_CREATION_LIMIT", "MIRROR_QUEUE_LENGTH", "PREFERRED_LICENSES", "PREFIX_ARCHIVE_FILES", "PULL_REQUEST_QUEUE_LENGTH", "ROOT", "SCRIPT_TYPE", "USE_COMPAT_SSH_URI"},
+	"repository.editor":                        {"LINE_WRAP_EXTENSIONS", "PREVIEWABLE_FILE_MODES"},
+	"repository.issue":                         {"ALLOW_PURGE_CONTENT_HISTORY", "LOCK_REASONS"},
+	"repository.large-file":                    {"BLOCK", "MAX_SIZE"},
+	"repository.local":                         {"LOCAL_COPY_PATH"},
+	"repository.maintenance":                   {"ENABLED", "MAX_GROWTH_PERCENT", "MAX_LOOSE_OBJECTS", "MAX_PACKS", "PRUNE_EXPIRE", "TIMEOUT"},
+	"repository.pull-request":                  {"CLOSE_KEYWORDS", "DEFAULT_MERGE_MESSAGE_ALL_AUTHORS", "DEFAULT_MERGE_MESSAGE_COMMITS_LIMIT", "DEFAULT_MERGE_MESSAGE_MAX_APPROVERS", "DEFAULT_MERGE_MESSAGE_OFFICIAL_APPROVERS_ONLY", "DEFAULT_MERGE_MESSAGE_SIZE", "MERGE_QUEUE_CHECK_TIMEOUT", "REOPEN_KEYWORDS", "WORK_IN_PROGRESS_PREFIXES"},
+	"repository.release":                       {"ALLOWED_TYPES"},
+	"repository.signing":                       {"CRUD_ACTIONS", "DEFAULT_TRUST_MODEL", "INITIAL_COMMIT", "MERGES", "SIGNING_EMAIL", "SIGNING_KEY", "SIGNING_NAME", "WIKI"},
+	"repository.upload":                        {"ALLOWED_TYPES", "ENABLED", "FILE_MAX_SIZE", "MAX_FILES", "TEMP_PATH"},
+	"scim":                                     {"DEFAULT_PAGING_NUM", "ENABLED", "GROUP_TEAM_MAP", "GROUP_TEAM_MAP_REMOVAL", "LOGIN_SOURCE", "MAX_RESULTS"},
+	"security":                                 {"COOKIE_REMEMBER_NAME", "COOKIE_USERNAME", "CSRF_COOKIE_HTTP_ONLY", "DISABLE_GIT_HOOKS", "DISABLE_WEBHOOKS", "IMPORT_LOCAL_PATHS", "INSTALL_LOCK", "INTERNAL_TOKEN", "INTERNAL_TOKEN_URI", "LOGIN_REMEMBER_DAYS", "MIN_PASSWORD_LENGTH", "ONLY_ALLOW_PUSH_IF_GITEA_ENVIRONMENT_SET", "PASSWORD_CHECK_PWN", "PASSWORD_COMPLEXITY", "PASSWORD_HASH_ALGO", "REVERSE_PROXY_AUTHENTICATION_EMAIL", "REVERSE_PROXY_AUTHENTICATION_USER", "REVERSE_PROXY_LIMIT", "REVERSE_PROXY_TRUSTED_PROXIES", "SECRET_KEY"},
+	"server":                                   {"ALLOW_GRACEFUL_RESTARTS", "APP_DATA_PATH", "BUILTIN_SSH_SERVER_USER", "CERT_FILE", "DISABLE_ROUTER_LOG", "DISABLE_SSH", "DOMAIN", "ENABLE_GZIP", "ENABLE_LETSENCRYPT", "ENABLE_PPROF", "GRACEFUL_HAMMER_TIME", "HTTP_ADDR", "HTTP_PORT", "KEY_FILE", "LANDING_PAGE", "LETSENCRYPT_ACCEPTTOS", "LETSENCRYPT_DIRECTORY", "LETSENCRYPT_EMAIL", "LFS_CONTENT_PATH", "LFS_HTTP_AUTH_EXPIRY", "LFS_JWT_SECRET", "LFS_LOCKS_PAGING_NUM", "LFS_MAX_FILE_SIZE", "LFS_START_SERVER", "LOCAL_ROOT_URL", "MINIMUM_KEY_SIZE_CHECK", "OFFLINE_MODE", "PORT_TO_REDIRECT", "PPROF_DATA_PATH", "PROTOCOL", "REDIRECT_OTHER_PORT", "ROOT_URL", "SSH_AUTHORIZED_KEYS_BACKUP", "SSH_AUTHORIZED_PRINCIPALS_ALLOW", "SSH_AUTHORIZED_PRINCIPALS_BACKUP", "SSH_CREATE_AUTHORIZED_KEYS_FILE", "SSH_CREATE_AUTHORIZED_PRINCIPALS_FILE", "SSH_DOMAIN", "SSH_EXPOSE_ANONYMOUS", "SSH_KEYGEN_PATH", "SSH_KEY_TEST_PATH", "SSH_LISTEN_HOST", "SSH_LISTEN_PORT", "SSH_PORT", "SSH_ROOT_PATH", "SSH_SERVER_CIPHERS", "SSH_SERVER_HOST_KEYS", "SSH_SERVER_KEY_EXCHANGES", "SSH_SERVER_MACS", "SSH_TRUSTED_USER_CA_KEYS", "SSH_TRUSTED_USER_CA_KEYS_FILENAME", "STARTUP_TIMEOUT", "START_SSH_SERVER", "STATIC_CACHE_TIME", "STATIC_ROOT_PATH", "STATIC_URL_PREFIX", "UNIX_SOCKET_PERMISSION"},
+	"service":                                  {"ACTIVE_CODE_LIVE_MINUTES", "ALLOW_CROSS_REPOSITORY_DEPENDENCIES", "ALLOW_ONLY_EXTERNAL_REGISTRATION", "AUTO_WATCH_NEW_REPOS", "AUTO_WATCH_ON_CHANGES", "CAPTCHA_TYPE", "DEFAULT_ALLOW_CREATE_ORGANIZATION", "DEFAULT_ALLOW_ONLY_CONTRIBUTORS_TO_TRACK_TIME", "DEFAULT_ENABLE_DEPENDENCIES", "DEFAULT_ENABLE_TIMETRACKING", "DEFAULT_KEEP_EMAIL_PRIVATE", "DEFAULT_ORG_MEMBER_VISIBLE", "DEFAULT_ORG_VISIBILITY", "DISABLE_REGISTRATION", "EMAIL_DOMAIN_BLOCKLIST", "EMAIL_DOMAIN_WHITELIST", "ENABLE_BASIC_AUTHENTICATION", "ENABLE_CAPTCHA", "ENABLE_NOTIFY_MAIL", "ENABLE_REVERSE_PROXY_AUTHENTICATION", "ENABLE_REVERSE_PROXY_AUTO_REGISTRATION", "ENABLE_REVERSE_PROXY_EMAIL", "ENABLE_TIMETRACKING", "ENABLE_USER_HEATMAP", "HCAPTCHA_SECRET", "HCAPTCHA_SITEKEY", "NO_REPLY_ADDRESS", "RECAPTCHA_SECRET", "RECAPTCHA_SITEKEY", "RECAPTCHA_URL", "REGISTER_EMAIL_CONFIRM", "REGISTER_MANUAL_CONFIRM", "REQUIRE_EXTERNAL_REGISTRATION_CAPTCHA", "REQUIRE_EXTERNAL_REGISTRATION_PASSWORD", "REQUIRE_SIGNIN_VIEW", "RESET_PASSWD_CODE_LIVE_MINUTES", "SHOW_MILESTONES_DASHBOARD_PAGE", "SHOW_REGISTRATION_BUTTON", "USER_DELETE_WITH_COMMENTS_MAX_TIME"},
+	"service.explore":                          {"DISABLE_USERS_PAGE", "REQUIRE_SIGNIN_VIEW"},
+	"session":                                  {"COOKIE_NAME", "COOKIE_SECURE", "DOMAIN", "GC_INTERVAL_TIME", "PROVIDER", "PROVIDER_CONFIG", "SAME_SITE", "SESSION_LIFE_TIME"},
+	"ssh.minimum_key_sizes":                    {"DSA", "ECDSA", "ED25519", "RSA"},
+	"storage":                                  {"AZURE_BLOB_ACCOUNT_KEY", "AZURE_BLOB_ACCOUNT_NAME", "AZURE_BLOB_BASE_PATH", "AZURE_BLOB_CONTAINER", "AZURE_BLOB_ENCRYPTION_SCOPE", "AZURE_BLOB_ENDPOINT", "MINIO_ACCESS_KEY_ID", "MINIO_BUCKET", "MINIO_ENDPOINT", "MINIO_EXPIRATION_DAYS", "MINIO_LOCATION", "MINIO_SECRET_ACCESS_KEY", "MINIO_SSE", "MINIO_SSE_C_KEY", "MINIO_SSE_KMS_KEY_ID", "MINIO_TRANSITION_DAYS", "MINIO_TRANSITION_STORAGE_CLASS", "MINIO_USE_SSL", "SERVE_DIRECT", "STORAGE_TYPE"},
+	"storage.*":                                {"AZURE_BLOB_ACCOUNT_KEY", "AZURE_BLOB_ACCOUNT_NAME", "AZURE_BLOB_BASE_PATH", "AZURE_BLOB_CONTAINER", "AZURE_BLOB_ENCRYPTION_SCOPE", "AZURE_BLOB_ENDPOINT", "MINIO_ACCESS_KEY_ID", "MINIO_BUCKET", "MINIO_ENDPOINT", "MINIO_EXPIRATION_DAYS", "MINIO_LOCATION", "MINIO_SECRET_ACCESS_KEY", "MINIO_SSE", "MINIO_SSE_C_KEY", "MINIO_SSE_KMS_KEY_ID", "MINIO_TRANSITION_DAYS", "MINIO_TRANSITION_STORAGE_CLASS", "MINIO_USE_SSL", "SERVE_DIRECT", "STORAGE_TYPE"},
+	"task":                                     {"QUEUE_CONN_STR", "QUEUE_LENGTH", "QUEUE_TYPE"},
+	"time":                                     {"DEFAULT_UI_LOCATION", "FORMAT"},
+	"tracing":                                  {"BATCH_SIZE", "ENABLED", "ENDPOINT", "EXPORT_INTERVAL", "EXPORT_TIMEOUT", "HEADERS", "QUEUE_LENGTH", "SAMPLE_RATIO", "SERVICE_NAME"},
+	"ui":                                       {"CODE_COMMENT_LINES", "DEFAULT_SHOW_FULL_NAME", "DEFAULT_THEME", "EXPLORE_PAGING_NUM", "FEED_MAX_COMMIT_NUM", "FEED_PAGING_NUM", "GRAPH_MAX_COMMIT_NUM", "ISSUE_PAGING_NUM", "MAX_DISPLAY_FILE_SIZE", "MEMBERS_PAGING_NUM", "REACTIONS", "SEARCH_REPO_DESCRIPTION", "SHOW_USER_EMAIL", "THEMES", "THEME_COLOR_META_TAG", "TIMELINE_PAGING_NUM", "USE_SERVICE_WORKER"},
+	"ui.admin":                                 {"NOTICE_PAGING_NUM", "ORG_PAGING_NUM", "REPO_PAGING_NUM", "USER_PAGING_NUM"},
+	"ui.meta":                                  {"AUTHOR", "DESCRIPTION", "KEYWORDS"},
+	"ui.notification":                          {"EVENT_SOURCE_UPDATE_TIME", "MAX_TIMEOUT", "MIN_TIMEOUT", "TIMEOUT_STEP"},
+	"ui.svg":                                   {"ENABLE_RENDER"},
+	"ui.user":                                  {"REPO_PAGING_NUM"},
+	"virus_scan":                               {"ACTION", "BACKEND", "CLAMAV_ADDR", "ENABLED", "ICAP_URL", "QUARANTINE_PATH", "REJECT_ON_ERROR", "TIMEOUT"},
+	"webhook":                                  {"DELIVER_TIMEOUT", "PAGING_NUM", "PROXY_HOSTS", "PROXY_URL", "QUEUE_LENGTH", "SKIP_TLS_VERIFY"},
+}
+
+// configKeyTypes are the types of the keys which are not read as strings, collected from
+// the setting structs and the calls reading the keys
+var configKeyTypes = map[string]map[string]string{
+	"actions": {
+		"ENABLED":                "bool",
+		"JOB_TOKEN_LIFETIME":     "duration",
+		"MAX_LOG_CHUNK_SIZE":     "int",
+		"RUNNER_OFFLINE_TIMEOUT": "duration",
+	},
+	"admin": {
+		"DISABLE_REGULAR_ORG_CREATION": "bool",
+	},
+	"api": {
+		"DEFAULT_GIT_TREES_PER_PAGE": "int",
+		"DEFAULT_MAX_BLOB_SIZE":      "int",
+		"DEFAULT_PAGING_NUM":         "int",
+		"ENABLE_SWAGGER":             "bool",
+		"MAX_RESPONSE_ITEMS":         "int",
+	},
+	"artifacts": {
+		"DEFAULT_RETENTION_DAYS": "int",
+		"ENABLED":                "bool",
+		"MAX_RETENTION_DAYS":     "int",
+		"MAX_SIZE":               "int",
+	},
+	"attachment": {
+		"ENABLED":   "bool",
+		"MAX_FILES": "int",
+		"MAX_SIZE":  "int",
+	},
+	"cache": {
+		"ENABLED":  "bool",
+		"INTERVAL": "int",
+		"ITEM_TTL": "duration",
+	},
+	"cache.last_commit": {
+		"COMMITS_COUNT": "int",
+		"ENABLED":       "bool",
+		"ITEM_TTL":      "duration",
+	},
+	"cors": {
+		"ALLOW_CREDENTIALS": "bool",
+		"ALLOW_SUBDOMAIN":   "bool",
+		"ENABLED":           "bool",
+		"MAX_AGE":           "duration",
+	},
+	"database": {
+		"DB_RETRIES":          "int",
+		"DB_RETRY_BACKOFF":    "duration",
+		"ITERATE_BUFFER_SIZE": "int",
+		"LOG_SQL":             "bool",
+		"MAX_IDLE_CONNS":      "int",
+		"MAX_OPEN_CONNS":      "int",
+		"SQLITE_TIMEOUT":      "int",
+	},
+	"git": {
+		"BRANCHES_RANGE_SIZE":           "int",
+		"COMMITS_RANGE_SIZE":            "int",
+		"DISABLE_DIFF_HIGHLIGHT":        "bool",
+		"DISABLE_PARTIAL_CLONE":         "bool",
+		"ENABLE_AUTO_GIT_WIRE_PROTOCOL": "bool",
+		"ENABLE_BUNDLE_URI":             "bool",
+		"MAX_GIT_DIFF_FILES":            "int",
+		"MAX_GIT_DIFF_LINES":            "int",
+		"MAX_GIT_DIFF_LINE_CHARACTERS":  "int",
+		"PULL_REQUEST_PUSH_MESSAGE":     "bool",
+		"REQUIRE_WIRE_PROTOCOL_V2":      "bool",
+		"VERBOSE_PUSH":                  "bool",
+		"VERBOSE_PUSH_DELAY":            "duration",
+	},
+	"git.pack_cache": {
+		"ENABLED": "bool",
+		"TTL":     "duration",
+	},
+	"git.refs_cache": {
+		"ENABLED": "bool",
+		"TTL":     "duration",
+	},
+	"git.timeout": {
+		"CLONE":   "int",
+		"DEFAULT": "int",
+		"GC":      "int",
+		"MIGRATE": "int",
+		"MIRROR":  "int",
+		"PULL":    "int",
+	},
+	"ide": {
+		"ENABLED": "bool",
+	},
+	"ide.*": {
+		"ENABLED": "bool",
+	},
+	"indexer": {
+		"ISSUE_INDEXER_HIGHLIGHT":               "bool",
+		"ISSUE_INDEXER_HIGHLIGHT_FRAGMENTS":     "int",
+		"ISSUE_INDEXER_HIGHLIGHT_FRAGMENT_SIZE": "int",
+		"ISSUE_INDEXER_QUEUE_BATCH_NUMBER":      "int",
+		"MAX_FILE_SIZE":                         "int",
+		"REPO_INDEXER_ENABLED":                  "bool",
+		"REPO_INDEXER_EXCLUDE_VENDORED":         "bool",
+		"STARTUP_TIMEOUT":                       "duration",
+		"UPDATE_BUFFER_LEN":                     "int",
+	},
+	"instance_stats": {
+		"ENABLED": "bool",
+		"TIMEOUT": "duration",
+	},
+	"internal_api": {
+		"TOKEN_LIFETIME": "duration",
+	},
+	"log": {
+		"BUFFER_LEN":        "int",
+		"ENABLE_ACCESS_LOG": "bool",
+		"ENABLE_XORM_LOG":   "bool",
+	},
+	"log.*": {
+		"BUFFER_LEN":        "int",
+		"ENABLE_ACCESS_LOG": "bool",
+		"ENABLE_XORM_LOG":   "bool",
+	},
+	"mailer": {
+		"DISABLE_HELO":       "bool",
+		"ENABLED":            "bool",
+		"IS_TLS_ENABLED":     "bool",
+		"SENDMAIL_TIMEOUT":   "duration",
+		"SEND_AS_PLAIN_TEXT": "bool",
+		"SEND_BUFFER_LEN":    "int",
+		"SKIP_VERIFY":        "bool",
+		"USE_CERTIFICATE":    "bool",
+	},
+	"maintenance": {
+		"ENABLED": "bool",
+	},
+	"markdown": {
+		"ENABLE_HARD_LINE_BREAK_IN_COMMENTS":  "bool",
+		"ENABLE_HARD_LINE_BREAK_IN_DOCUMENTS": "bool",
+	},
+	"metrics": {
+		"ENABLED": "bool",
+	},
+	"migrations": {
+		"ALLOW_LOCALNETWORKS": "bool",
+		"MAX_ATTEMPTS":        "int",
+		"RETRY_BACKOFF":       "int",
+	},
+	"mirror": {
+		"DEFAULT_INTERVAL":  "duration",
+		"FAILURE_THRESHOLD": "int",
+		"MIN_INTERVAL":      "duration",
+	},
+	"oauth2": {
+		"ACCESS_TOKEN_EXPIRATION_TIME":  "int",
+		"ENABLE":                        "bool",
+		"INVALIDATE_REFRESH_TOKENS":     "bool",
+		"MAX_TOKEN_LENGTH":              "int",
+		"REFRESH_TOKEN_EXPIRATION_TIME": "int",
+	},
+	"openid": {
+		"ENABLE_OPENID_SIGNIN": "bool",
+		"ENABLE_OPENID_SIGNUP": "bool",
+	},
+	"other": {
+		"SHOW_FOOTER_BRANDING":           "bool",
+		"SHOW_FOOTER_TEMPLATE_LOAD_TIME": "bool",
+		"SHOW_FOOTER_VERSION":            "bool",
+	},
+	"packages": {
+		"ENABLED": "bool",
+	},
+	"picture": {
+		"AVATAR_MAX_FILE_SIZE":    "int",
+		"AVATAR_MAX_HEIGHT":       "int",
+		"AVATAR_MAX_WIDTH":        "int",
+		"DISABLE_GRAVATAR":        "bool",
+		"ENABLE_FEDERATED_AVATAR": "bool",
+	},
+	"privacy": {
+		"MAIL_AVATARS": "bool",
+	},
+	"queue": {
+		"BATCH_LENGTH":      "int",
+		"BLOCK_TIMEOUT":     "duration",
+		"BOOST_TIMEOUT":     "duration",
+		"BOOST_WORKERS":     "int",
+		"LENGTH":            "int",
+		"MAX_ATTEMPTS":      "int",
+		"MAX_WORKERS":       "int",
+		"TIMEOUT":           "duration",
+		"WORKERS":           "int",
+		"WRAP_IF_NECESSARY": "bool",
+	},
+	"queue.*": {
+		"BATCH_LENGTH":      "int",
+		"BLOCK_TIMEOUT":     "duration",
+		"BOOST_TIMEOUT":     "duration",
+		"BOOST_WORKERS":     "int",
+		"LENGTH":            "int",
+		"MAX_ATTEMPTS":      "int",
+		"MAX_WORKERS":       "int",
+		"TIMEOUT":           "duration",
+		"WORKERS":           "int",
+		"WRAP_IF_NECESSARY": "bool",
+	},
+	"repository": {
+		"ALLOW_ADOPTION_OF_UNADOPTED_REPOSITORIES":       "bool",
+		"DEFAULT_CLOSE_ISSUES_VIA_COMMITS_IN_ANY_BRANCH": "bool",
+		"DEFAULT_PUSH_CREATE_PRIVATE":                    "bool",
+		"DISABLE_HTTP_GIT":                               "bool",
+		"DISABLE_MIGRATIONS":                             "bool",
+		"DISABLE_MIRRORS":                                "bool",
+		"ENABLE_PUSH_CREATE_ORG":                         "bool",
+		"ENABLE_PUSH_CREATE_USER":                        "bool",
+		"FORCE_PRIVATE":                                  "bool",
+		"MAX_CREATION_LIMIT":                             "int",
+		"MIRROR_QUEUE_LENGTH":                            "int",
+		"PREFIX_ARCHIVE_FILES":                           "bool",
+		"PULL_REQUEST_QUEUE_LENGTH":                      "int",
+		"USE_COMPAT_SSH_URI":                             "bool",
+	},
+	"repository.issue": {
+		"ALLOW_PURGE_CONTENT_HISTORY": "bool",
+	},
+	"repository.large-file": {
+		"BLOCK":    "bool",
+		"MAX_SIZE": "int",
+	},
+	"repository.maintenance": {
+		"ENABLED":            "bool",
+		"MAX_GROWTH_PERCENT": "int",
+		"MAX_LOOSE_OBJECTS":  "int",
+		"MAX_PACKS":          "int",
+		"TIMEOUT":            "duration",
+	},
+	"repository.pull-request": {
+		"DEFAULT_MERGE_MESSAGE_ALL_AUTHORS":             "bool",
+		"DEFAULT_MERGE_MESSAGE_COMMITS_LIMIT":           "int",
+		"DEFAULT_MERGE_MESSAGE_MAX_APPROVERS":           "int",
+		"DEFAULT_MERGE_MESSAGE_OFFICIAL_APPROVERS_ONLY": "bool",
+		"DEFAULT_MERGE_MESSAGE_SIZE":                    "int",
+		"MERGE_QUEUE_CHECK_TIMEOUT":                     "duration",
+	},
+	"repository.upload": {
+		"ENABLED":       "bool",
+		"FILE_MAX_SIZE": "int",
+		"MAX_FILES":     "int",
+	},
+	"scim": {
+		"DEFAULT_PAGING_NUM":     "int",
+		"ENABLED":                "bool",
+		"GROUP_TEAM_MAP_REMOVAL": "bool",
+		"MAX_RESULTS":            "int",
+	},
+	"security": {
+		"CSRF_COOKIE_HTTP_ONLY":                    "bool",
+		"DISABLE_GIT_HOOKS":                        "bool",
+		"DISABLE_WEBHOOKS":                         "bool",
+		"IMPORT_LOCAL_PATHS":                       "bool",
+		"INSTALL_LOCK":                             "bool",
+		"LOGIN_REMEMBER_DAYS":                      "int",
+		"MIN_PASSWORD_LENGTH":                      "int",
+		"ONLY_ALLOW_PUSH_IF_GITEA_ENVIRONMENT_SET": "bool",
+		"PASSWORD_CHECK_PWN":                       "bool",
+		"REVERSE_PROXY_LIMIT":                      "int",
+	},
+	"server": {
+		"ALLOW_GRACEFUL_RESTARTS":               "bool",
+		"DISABLE_ROUTER_LOG":                    "bool",
+		"DISABLE_SSH":                           "bool",
+		"ENABLE_GZIP":                           "bool",
+		"ENABLE_LETSENCRYPT":                    "bool",
+		"ENABLE_PPROF":                          "bool",
+		"GRACEFUL_HAMMER_TIME":                  "duration",
+		"LETSENCRYPT_ACCEPTTOS":                 "bool",
+		"LFS_HTTP_AUTH_EXPIRY":                  "duration",
+		"LFS_LOCKS_PAGING_NUM":                  "int",
+		"LFS_MAX_FILE_SIZE":                     "int",
+		"LFS_START_SERVER":                      "bool",
+		"MINIMUM_KEY_SIZE_CHECK":                "bool",
+		"OFFLINE_MODE":                          "bool",
+		"REDIRECT_OTHER_PORT":                   "bool",
+		"SSH_AUTHORIZED_KEYS_BACKUP":            "bool",
+		"SSH_AUTHORIZED_PRINCIPALS_BACKUP":      "bool",
+		"SSH_CREATE_AUTHORIZED_KEYS_FILE":       "bool",
+		"SSH_CREATE_AUTHORIZED_PRINCIPALS_FILE": "bool",
+		"SSH_EXPOSE_ANONYMOUS":                  "bool",
+		"SSH_LISTEN_PORT":                       "int",
+		"SSH_PORT":                              "int",
+		"STARTUP_TIMEOUT":                       "duration",
+		"START_SSH_SERVER":                      "bool",
+		"STATIC_CACHE_TIME":                     "duration",
+	},
+	"service": {
+		"ACTIVE_CODE_LIVE_MINUTES":                      "int",
+		"ALLOW_CROSS_REPOSITORY_DEPENDENCIES":           "bool",
+		"ALLOW_ONLY_EXTERNAL_REGISTRATION":              "bool",
+		"AUTO_WATCH_NEW_REPOS":                          "bool",
+		"AUTO_WATCH_ON_CHANGES":                         "bool",
+		"DEFAULT_ALLOW_CREATE_ORGANIZATION":             "bool",
+		"DEFAULT_ALLOW_ONLY_CONTRIBUTORS_TO_TRACK_TIME": "bool",
+		"DEFAULT_ENABLE_DEPENDENCIES":                   "bool",
+		"DEFAULT_ENABLE_TIMETRACKING":                   "bool",
+		"DEFAULT_KEEP_EMAIL_PRIVATE":                    "bool",
+		"DEFAULT_ORG_MEMBER_VISIBLE":                    "bool",
+		"DISABLE_REGISTRATION":                          "bool",
+		"ENABLE_BASIC_AUTHENTICATION":                   "bool",
+		"ENABLE_CAPTCHA":                                "bool",
+		"ENABLE_NOTIFY_MAIL":                            "bool",
+		"ENABLE_REVERSE_PROXY_AUTHENTICATION":           "bool",
+		"ENABLE_REVERSE_PROXY_AUTO_REGISTRATION":        "bool",
+		"ENABLE_REVERSE_PROXY_EMAIL":                    "bool",
+		"ENABLE_TIMETRACKING":                           "bool",
+		"ENABLE_USER_HEATMAP":                           "bool",
+		"REGISTER_EMAIL_CONFIRM":                        "bool",
+		"REGISTER_MANUAL_CONFIRM":                       "bool",
+		"REQUIRE_EXTERNAL_REGISTRATION_CAPTCHA":         "bool",
+		"REQUIRE_EXTERNAL_REGISTRATION_PASSWORD":        "bool",
+		"REQUIRE_SIGNIN_VIEW":                           "bool",
+		"RESET_PASSWD_CODE_LIVE_MINUTES":                "int",
+		"SHOW_MILESTONES_DASHBOARD_PAGE":                "bool",
+		"SHOW_REGISTRATION_BUTTON":                      "bool",
+		"USER_DELETE_WITH_COMMENTS_MAX_TIME":            "duration",
+	},
+	"service.explore": {
+		"DISABLE_USERS_PAGE":  "bool",
+		"REQUIRE_SIGNIN_VIEW": "bool",
+	},
+	"session": {
+		"COOKIE_SECURE":     "bool",
+		"GC_INTERVAL_TIME":  "int",
+		"SESSION_LIFE_TIME": "int",
+	},
+	"task": {
+		"QUEUE_LENGTH": "int",
+	},
+	"tracing": {
+		"BATCH_SIZE":      "int",
+		"ENABLED":         "bool",
+		"EXPORT_INTERVAL": "duration",
+		"EXPORT_TIMEOUT":  "duration",
+		"QUEUE_LENGTH":    "int",
+		"SAMPLE_RATIO":    "float",
+	},
+	"ui": {
+		"CODE_COMMENT_LINES":      "int",
+		"DEFAULT_SHOW_FULL_NAME":  "bool",
+		"EXPLORE_PAGING_NUM":      "int",
+		"FEED_MAX_COMMIT_NUM":     "int",
+		"FEED_PAGING_NUM":         "int",
+		"GRAPH_MAX_COMMIT_NUM":    "int",
+		"ISSUE_PAGING_NUM":        "int",
+		"MAX_DISPLAY_FILE_SIZE":   "int",
+		"MEMBERS_PAGING_NUM":      "int",
+		"SEARCH_REPO_DESCRIPTION": "bool",
+		"SHOW_USER_EMAIL":         "bool",
+		"TIMELINE_PAGING_NUM":     "int",
+		"USE_SERVICE_WORKER":      "bool",
+	},
+	"ui.admin": {
+		"NOTICE_PAGING_NUM": "int",
+		"ORG_PAGING_NUM":    "int",
+		"REPO_PAGING_NUM":   "int",
+		"USER_PAGING_NUM":   "int",
+	},
+	"ui.notification": {
+		"EVENT_SOURCE_UPDATE_TIME": "duration",
+		"MAX_TIMEOUT":              "duration",
+		"MIN_TIMEOUT":              "duration",
+		"TIMEOUT_STEP":             "duration",
+	},
+	"ui.svg": {
+		"ENABLE_RENDER": "bool",
+	},
+	"ui.user": {
+		"REPO_PAGING_NUM": "int",
+	},
+	"virus_scan": {
+		"ENABLED":         "bool",
+		"REJECT_ON_ERROR": "bool",
+		"TIMEOUT":         "duration",
+	},
+	"webhook": {
+		"DELIVER_TIMEOUT": "int",
+		"PAGING_NUM":      "int",
+		"QUEUE_LENGTH":    "int",
+		"SKIP_TLS_VERIFY": "bool",
+	},
+}
@@ -0,0 +1,281 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+//go:generate go run -mod=vendor ../../build/generate-config-keys.go -ini ../../custom/conf/app.example.ini -docs ../../docs/content/doc/advanced/config-cheat-sheet.en-us.md -src . -env-docs ../../docs/content/doc/advanced/environment-variables.en-us.md -o config_keys.go
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/modules/util"
+
+	ini "gopkg.in/ini.v1"
+)
+
+// ConfigWarningType is the kind of a problem of the configuration
+type ConfigWarningType string
+
+// The kinds of problems found by ValidateConfig
+const (
+	ConfigWarningUnknownKey  ConfigWarningType = "unknown_key"
+	ConfigWarningConflict    ConfigWarningType = "conflict"
+	ConfigWarningInsecure    ConfigWarningType = "insecure"
+	ConfigWarningMissingPath ConfigWarningType = "missing_path"
+	ConfigWarningInvalid     ConfigWarningType = "invalid_value"
+)
+
+// ConfigWarning is a problem of the configuration
+type ConfigWarning struct {
+	Type    ConfigWarningType
+	Section string
+	Key     string
+	Message string
+}
+
+// Location returns section and key of the warning in the notation of the configuration file
+func (w ConfigWarning) Location() string {
+	section := w.Section
+	if section == "" || section == ini.DefaultSection {
+		section = "DEFAULT"
+	}
+	if w.Key == "" {
+		return "[" + section + "]"
+	}
+	return "[" + section + "] " + w.Key
+}
+
+// configKeyGroups are the prefixes of sections with user defined names
+var configKeyGroups = []string{"cron.", "ide.", "log.", "markup.", "queue.", "storage."}
+
+// freeformConfigSections are the sections whose keys are defined by the user
+var freeformConfigSections = map[string]bool{
+	"highlight.mapping":     true,
+	"packages.owner_quotas": true,
+}
+
+// storageConfigSections are the sections which can override the keys of the storage section
+var storageConfigSections = map[string]bool{
+	"actions":      true,
+	"artifacts":    true,
+	"attachment":   true,
+	"avatar":       true,
+	"lfs":          true,
+	"packages":     true,
+	"repo-archive": true,
+	"repo-avatar":  true,
+}
+
+var knownConfigKeySet map[string]map[string]bool
+
+func isKnownConfigKey(section, key string) (knownSection, knownKey bool) {
+	if knownConfigKeySet == nil {
+		knownConfigKeySet = make(map[string]map[string]bool, len(knownConfigKeys))
+		for s, keys := range knownConfigKeys {
+			knownConfigKeySet[s] = make(map[string]bool, len(keys))
+			for _, k := range keys {
+				knownConfigKeySet[s][k] = true
+			}
+		}
+	}
+
+	if section == ini.DefaultSection || section == "" {
+		section = "DEFAULT"
+	}
+	if freeformConfigSections[section] {
+		return true, true
+	}
+	if keys, ok := knownConfigKeySet[section]; ok {
+		knownSection = true
+		if keys[key] {
+			return true, true
+		}
+	}
+	if storageConfigSections[section] {
+		return true, knownConfigKeySet["storage.*"][key]
+	}
+	for _, prefix := range configKeyGroups {
+		if strings.HasPrefix(section, prefix) {
+			keys := knownConfigKeySet[prefix+"*"]
+			return true, keys[key]
+		}
+	}
+	return knownSection, false
+}
+
+type configWarnings []ConfigWarning
+
+func (ws *configWarnings) add(typ ConfigWarningType, section, key, format string, args ...interface{}) {
+	*ws = append(*ws, ConfigWarning{
+		Type:    typ,
+		Section: section,
+		Key:     key,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// ValidateConfig checks the configuration for unknown keys, conflicting settings,
+// insecure values and paths which do not exist. NewContext must have been called.
+func ValidateConfig() []ConfigWarning {
+	warnings := make(configWarnings, 0, 10)
+
+	// Cfg also contains all keys read with their defaults, so the keys are
+	// checked on the configuration file itself
+	file := ini.Empty()
+	if isFile, _ := util.IsFile(CustomConf); isFile {
+		if err := file.Append(CustomConf); err != nil {
+			warnings.add(ConfigWarningMissingPath, "", "", "Unable to load %s: %v", CustomConf, err)
+		}
+	}
+	validateConfigKeys(file, &warnings)
+	validateEnvironmentConfigKeys(&warnings)
+	validateConfigValues(&warnings)
+
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return warnings[i].Type < warnings[j].Type
+	})
+	return warnings
+}
+
+// validateConfigKeys warns about unknown sections and keys, which are mostly typos
+// or settings of other versions
+func validateConfigKeys(file *ini.File, warnings *configWarnings) {
+	for _, sec := range file.Sections() {
+		if sec.Name() == ini.DefaultSection && len(sec.Keys()) == 0 {
+			continue
+		}
+		knownSection, _ := isKnownConfigKey(sec.Name(), "")
+		if !knownSection {
+			warnings.add(ConfigWarningUnknownKey, sec.Name(), "", "Unknown section")
+			continue
+		}
+		for _, key := range sec.Keys() {
+			if _, known := isKnownConfigKey(sec.Name(), key.Name()); !known {
+				warnings.add(ConfigWarningUnknownKey, sec.Name(), key.Name(), "Unknown key")
+			} else if err := checkConfigValueType(sec.Name(), key); err != nil {
+				warnings.add(ConfigWarningInvalid, sec.Name(), key.Name(), "%v", err)
+			}
+		}
+	}
+}
+
+// validateEnvironmentConfigKeys warns about the environment variables which set unknown keys
+func validateEnvironmentConfigKeys(warnings *configWarnings) {
+	sections := make([]string, 0, len(environmentConfigKeys))
+	for section := range environmentConfigKeys {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	for _, section := range sections {
+		keys := make([]string, 0, len(environmentConfigKeys[section]))
+		for key := range environmentConfigKeys[section] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if _, known := isKnownConfigKey(section, key); !known {
+				warnings.add(ConfigWarningUnknownKey, section, key, "Unknown key set from the environment")
+			}
+		}
+	}
+}
+
+func validateConfigValues(warnings *configWarnings) {
+	warn := warnings.add
+
+	// Conflicting settings
+	if EnableLetsEncrypt && Protocol != HTTPS {
+		warn(ConfigWarningConflict, "server", "ENABLE_LETSENCRYPT", "Let's Encrypt is only used with PROTOCOL = https")
+	}
+	if SSH.Disabled && SSH.StartBuiltinServer {
+		warn(ConfigWarningConflict, "server", "START_SSH_SERVER", "The built-in SSH server is not started because DISABLE_SSH is set")
+	}
+	if OfflineMode && !Cfg.Section("picture").Key("DISABLE_GRAVATAR").MustBool(true) {
+		warn(ConfigWarningConflict, "picture", "DISABLE_GRAVATAR", "Gravatar is always disabled in OFFLINE_MODE")
+	}
+	service := Cfg.Section("service")
+	if service.Key("DISABLE_REGISTRATION").MustBool() && service.Key("ALLOW_ONLY_EXTERNAL_REGISTRATION").MustBool() {
+		warn(ConfigWarningConflict, "service", "ALLOW_ONLY_EXTERNAL_REGISTRATION", "External registration is not possible because DISABLE_REGISTRATION is set")
+	}
+	if !Cfg.Section("mailer").Key("ENABLED").MustBool() {
+		for _, key := range []string{"REGISTER_EMAIL_CONFIRM", "ENABLE_NOTIFY_MAIL"} {
+			if service.Key(key).MustBool() {
+				warn(ConfigWarningConflict, "service", key, "No emails are sent because the mailer is not enabled")
+			}
+		}
+	}
+
+	// Insecure values
+	if RunMode != "prod" {
+		warn(ConfigWarningInsecure, ini.DefaultSection, "RUN_MODE", "RUN_MODE %q shows internal details to users, use prod", RunMode)
+	}
+	if SecretKey == "" || SecretKey == "!#@FDEWREWR&*(" {
+		warn(ConfigWarningInsecure, "security", "SECRET_KEY", "SECRET_KEY is empty or the publicly known default value, generate one with `gitea generate secret SECRET_KEY`")
+	}
+	if InternalToken == "" {
+		warn(ConfigWarningInsecure, "security", "INTERNAL_TOKEN", "INTERNAL_TOKEN is empty")
+	}
+	if MinPasswordLength < 8 {
+		warn(ConfigWarningInsecure, "security", "MIN_PASSWORD_LENGTH", "Passwords shorter than 8 characters are allowed")
+	}
+	if !DisableGitHooks {
+		warn(ConfigWarningInsecure, "security", "DISABLE_GIT_HOOKS", "Users allowed to edit git hooks can run arbitrary code on the server")
+	}
+	if ImportLocalPaths {
+		warn(ConfigWarningInsecure, "security", "IMPORT_LOCAL_PATHS", "Users allowed to import local paths can read repositories from the server's file system")
+	}
+	if EnablePprof {
+		warn(ConfigWarningInsecure, "server", "ENABLE_PPROF", "The profiling endpoints are exposed")
+	}
+	if strings.HasPrefix(AppURL, "https://") && !Cfg.Section("session").Key("COOKIE_SECURE").MustBool(false) {
+		warn(ConfigWarningInsecure, "session", "COOKIE_SECURE", "The session cookie is sent over unencrypted connections although ROOT_URL uses https")
+	}
+	if Cfg.Section("mailer").Key("SKIP_VERIFY").MustBool() {
+		warn(ConfigWarningInsecure, "mailer", "SKIP_VERIFY", "The certificate of the mail server is not verified")
+	}
+	if Cfg.Section("webhook").Key("SKIP_TLS_VERIFY").MustBool() {
+		warn(ConfigWarningInsecure, "webhook", "SKIP_TLS_VERIFY", "The certificates of webhook targets are not verified")
+	}
+
+	// Missing paths
+	checkFile := func(section, key, path string) {
+		if path == "" {
+			return
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			warn(ConfigWarningMissingPath, section, key, "%s does not exist", path)
+		}
+	}
+	// directories are created on start, but a missing parent most likely is a typo
+	checkDir := func(section, key, path string) {
+		if path == "" {
+			return
+		}
+		fi, err := os.Stat(path)
+		if err == nil && !fi.IsDir() {
+			warn(ConfigWarningMissingPath, section, key, "%s is not a directory", path)
+		} else if os.IsNotExist(err) {
+			if _, err := os.Stat(filepath.Dir(path)); os.IsNotExist(err) {
+				warn(ConfigWarningMissingPath, section, key, "Neither %s nor its parent directory exist", path)
+			}
+		}
+	}
+	if Protocol == HTTPS && !EnableLetsEncrypt {
+		checkFile("server", "CERT_FILE", CertFile)
+		checkFile("server", "KEY_FILE", KeyFile)
+	}
+	if Cfg.Section("server").HasKey("STATIC_ROOT_PATH") {
+		checkFile("server", "STATIC_ROOT_PATH", StaticRootPath)
+	}
+	checkDir("server", "APP_DATA_PATH", AppDataPath)
+	checkDir("repository", "ROOT", RepoRootPath)
+	checkDir("log", "ROOT_PATH", LogRootPath)
+	if LFS.StartServer && (LFS.Storage.Type == "" || LFS.Storage.Type == "local") {
+		checkDir("lfs", "PATH", LFS.Storage.Path)
+	}
+}
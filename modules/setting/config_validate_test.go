@@ -0,0 +1,101 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ini "gopkg.in/ini.v1"
+)
+
+func TestValidateConfigKeys(t *testing.T) {
+	iniStr := `
+APP_NAME = Gitea
+RUN_MODES = prod
+
+[server]
+PROTOCOL = http
+PROTOKOL = https
+
+[log.console]
+LEVEL = Info
+
+[log.file.router]
+FILE_NAME = router.log
+
+[queue.issue_indexer]
+LENGTH = 20
+
+[storage.my_minio]
+MINIO_BUCKET = gitea
+
+[avatar]
+STORAGE_TYPE = my_minio
+
+[repo-archive]
+MINIO_EXPIRATION_DAYS = 30
+
+[packages.owner_quotas]
+some-org = 50 GB
+
+[highlight.mapping]
+.toml = ini
+
+[unknown]
+KEY = value
+`
+	file, err := ini.Load([]byte(iniStr))
+	assert.NoError(t, err)
+
+	warnings := make(configWarnings, 0)
+	validateConfigKeys(file, &warnings)
+
+	locations := make([]string, 0, len(warnings))
+	for _, warning := range warnings {
+		assert.Equal(t, ConfigWarningUnknownKey, warning.Type)
+		locations = append(locations, warning.Location())
+	}
+	assert.Equal(t, []string{"[DEFAULT] RUN_MODES", "[server] PROTOKOL", "[unknown]"}, locations)
+}
+
+func TestValidateExampleConfigKeys(t *testing.T) {
+	// config_keys.go is generated from the example configuration, which must not have unknown keys
+	file, err := ini.Load("../../custom/conf/app.example.ini")
+	assert.NoError(t, err)
+
+	warnings := make(configWarnings, 0)
+	validateConfigKeys(file, &warnings)
+	assert.Empty(t, warnings)
+}
+
+func TestValidateConfigValues(t *testing.T) {
+	oldSecretKey, oldMinPasswordLength := SecretKey, MinPasswordLength
+	defer func() {
+		SecretKey, MinPasswordLength = oldSecretKey, oldMinPasswordLength
+	}()
+	Cfg = ini.Empty()
+
+	SecretKey = "!#@FDEWREWR&*("
+	MinPasswordLength = 6
+	warnings := make(configWarnings, 0)
+	validateConfigValues(&warnings)
+
+	found := map[string]ConfigWarningType{}
+	for _, warning := range warnings {
+		found[warning.Location()] = warning.Type
+	}
+	assert.Equal(t, ConfigWarningInsecure, found["[security] SECRET_KEY"])
+	assert.Equal(t, ConfigWarningInsecure, found["[security] MIN_PASSWORD_LENGTH"])
+
+	SecretKey = "a-generated-secret"
+	MinPasswordLength = 10
+	warnings = warnings[:0]
+	validateConfigValues(&warnings)
+	for _, warning := range warnings {
+		assert.NotEqual(t, "[security] SECRET_KEY", warning.Location())
+		assert.NotEqual(t, "[security] MIN_PASSWORD_LENGTH", warning.Location())
+	}
+}
@@ -5,6 +5,7 @@
 package setting
 
 import (
+	"path/filepath"
 	"time"
 
 	"code.gitea.io/gitea/modules/git"
@@ -26,7 +27,21 @@ var (
 		GCArgs                    []string `ini:"GC_ARGS" delim:" "`
 		EnableAutoGitWireProtocol bool
 		PullRequestPushMessage    bool
-		Timeout                   struct {
+		DisablePartialClone       bool
+		EnableBundleURI           bool `ini:"ENABLE_BUNDLE_URI"`
+		RequireWireProtocolV2     bool `ini:"REQUIRE_WIRE_PROTOCOL_V2"`
+		PackCache                 struct {
+			Enabled bool
+			Path    string
+			TTL     time.Duration `ini:"TTL"`
+			MaxSize int64         `ini:"-"`
+		} `ini:"git.pack_cache"`
+		RefsCache struct {
+			Enabled bool
+			TTL     time.Duration `ini:"TTL"`
+			MaxSize int64         `ini:"-"`
+		} `ini:"git.refs_cache"`
+		Timeout struct {
 			Default int
 			Migrate int
 			Mirror  int
@@ -46,6 +61,26 @@ var (
 		GCArgs:                    []string{},
 		EnableAutoGitWireProtocol: true,
 		PullRequestPushMessage:    true,
+		DisablePartialClone:       false,
+		EnableBundleURI:           false,
+		RequireWireProtocolV2:     false,
+		PackCache: struct {
+			Enabled bool
+			Path    string
+			TTL     time.Duration `ini:"TTL"`
+			MaxSize int64         `ini:"-"`
+		}{
+			Enabled: false,
+			TTL:     time.Hour,
+		},
+		RefsCache: struct {
+			Enabled bool
+			TTL     time.Duration `ini:"TTL"`
+			MaxSize int64         `ini:"-"`
+		}{
+			Enabled: false,
+			TTL:     10 * time.Minute,
+		},
 		Timeout: struct {
 			Default int
 			Migrate int
@@ -73,6 +108,18 @@ func newGit() {
 	}
 	git.DefaultCommandExecutionTimeout = time.Duration(Git.Timeout.Default) * time.Second
 
+	sec := Cfg.Section("git.pack_cache")
+	Git.PackCache.Path = sec.Key("PATH").MustString(filepath.Join(AppDataPath, "pack-cache"))
+	if !filepath.IsAbs(Git.PackCache.Path) {
+		Git.PackCache.Path = filepath.Join(AppWorkPath, Git.PackCache.Path)
+	}
+	sec.Key("MAX_SIZE").MustString("1 GiB")
+	Git.PackCache.MaxSize = mustBytes(sec, "MAX_SIZE")
+
+	sec = Cfg.Section("git.refs_cache")
+	sec.Key("MAX_SIZE").MustString("64 MiB")
+	Git.RefsCache.MaxSize = mustBytes(sec, "MAX_SIZE")
+
 	version, err := git.LocalVersion()
 	if err != nil {
 		log.Fatal("Error retrieving git version: %v", err)
@@ -0,0 +1,112 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"net/url"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// IDEApp is an IDE in which the repositories can be opened through its protocol handler
+type IDEApp struct {
+	Name        string `ini:"-"`
+	DisplayName string
+	// URL opens the IDE, {url} is replaced by the escaped clone URL of the repository
+	URL string `ini:"URL"`
+}
+
+// Link returns the link opening the repository cloned from cloneURL in the IDE
+func (app *IDEApp) Link(cloneURL string) string {
+	return strings.ReplaceAll(app.URL, "{url}", url.QueryEscape(cloneURL))
+}
+
+var (
+	// IDE defines the IDEs in which the repositories can be opened
+	IDE = struct {
+		Enabled bool
+		Apps    []string
+		// AppList are the IDEs named by Apps
+		AppList []*IDEApp `ini:"-"`
+		// GitpodURL is the Gitpod instance in which the repositories with a Gitpod configuration can be opened
+		GitpodURL string `ini:"-"`
+	}{
+		Enabled: true,
+		Apps:    []string{"vscode", "vscodium", "jetbrains"},
+	}
+
+	defaultIDEApps = map[string]IDEApp{
+		"vscode": {
+			DisplayName: "VS Code",
+			URL:         "vscode://vscode.git/clone?url={url}",
+		},
+		"vscodium": {
+			DisplayName: "VSCodium",
+			URL:         "vscodium://vscode.git/clone?url={url}",
+		},
+		"jetbrains": {
+			DisplayName: "JetBrains IDE",
+			URL:         "jetbrains://idea/checkout/git?idea.required.plugins.id=Git4Idea&checkout.repo={url}",
+		},
+	}
+)
+
+// GitpodLink returns the link opening the repository whose web page is repoURL in Gitpod, empty if Gitpod is disabled
+func GitpodLink(repoURL string) string {
+	if !IDE.Enabled || IDE.GitpodURL == "" {
+		return ""
+	}
+	return IDE.GitpodURL + "/#" + repoURL
+}
+
+func newIDEService() {
+	sec := Cfg.Section("ide")
+	if err := sec.MapTo(&IDE); err != nil {
+		log.Fatal("Failed to map IDE settings: %v", err)
+	}
+	// an empty value disables Gitpod, so it can't be mapped with a default
+	IDE.GitpodURL = "https://gitpod.io"
+	if sec.HasKey("GITPOD_URL") {
+		IDE.GitpodURL = sec.Key("GITPOD_URL").String()
+	}
+	IDE.AppList = nil
+	if !IDE.Enabled {
+		return
+	}
+
+	if IDE.GitpodURL != "" {
+		IDE.GitpodURL = strings.TrimSuffix(IDE.GitpodURL, "/")
+		u, err := url.Parse(IDE.GitpodURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			log.Fatal("Invalid [ide] GITPOD_URL %q: it must be an absolute http(s) URL", IDE.GitpodURL)
+		}
+	}
+
+	for _, name := range IDE.Apps {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		app := defaultIDEApps[name]
+		if err := Cfg.Section("ide." + name).MapTo(&app); err != nil {
+			log.Fatal("Failed to map IDE settings of %q: %v", name, err)
+		}
+		app.Name = name
+		if app.DisplayName == "" {
+			app.DisplayName = name
+		}
+
+		u, err := url.Parse(app.URL)
+		if err != nil || u.Scheme == "" || !strings.Contains(app.URL, "{url}") {
+			log.Fatal("Invalid [ide.%s] URL %q: it must be an absolute URL containing {url}", name, app.URL)
+		}
+		switch strings.ToLower(u.Scheme) {
+		case "javascript", "data", "vbscript":
+			log.Fatal("Invalid [ide.%s] URL %q: unsupported scheme %s", name, app.URL, u.Scheme)
+		}
+		IDE.AppList = append(IDE.AppList, &app)
+	}
+}
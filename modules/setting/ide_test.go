@@ -0,0 +1,57 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ini "gopkg.in/ini.v1"
+)
+
+func Test_newIDEService(t *testing.T) {
+	iniStr := `
+[ide]
+APPS = vscode, theia
+
+[ide.vscode]
+DISPLAY_NAME = Code
+
+[ide.theia]
+URL = theia://clone?repo={url}
+`
+	Cfg, _ = ini.Load([]byte(iniStr))
+	Cfg.NameMapper = ini.SnackCase
+	newIDEService()
+
+	if assert.Len(t, IDE.AppList, 2) {
+		assert.Equal(t, "vscode", IDE.AppList[0].Name)
+		assert.Equal(t, "Code", IDE.AppList[0].DisplayName)
+		assert.Equal(t, "vscode://vscode.git/clone?url=https%3A%2F%2Fexample.com%2Fowner%2Frepo.git",
+			IDE.AppList[0].Link("https://example.com/owner/repo.git"))
+		assert.Equal(t, "theia", IDE.AppList[1].Name)
+		assert.Equal(t, "theia", IDE.AppList[1].DisplayName)
+		assert.Equal(t, "theia://clone?repo=ssh%3A%2F%2Fgit%40example.com%2Fowner%2Frepo.git",
+			IDE.AppList[1].Link("ssh://git@example.com/owner/repo.git"))
+	}
+
+	assert.Equal(t, "https://gitpod.io/#https://example.com/owner/repo", GitpodLink("https://example.com/owner/repo"))
+
+	Cfg, _ = ini.Load([]byte("[ide]\nAPPS = vscode\nGITPOD_URL = https://gitpod.example.com/\n"))
+	Cfg.NameMapper = ini.SnackCase
+	newIDEService()
+	assert.Equal(t, "https://gitpod.example.com/#https://example.com/owner/repo", GitpodLink("https://example.com/owner/repo"))
+
+	Cfg, _ = ini.Load([]byte("[ide]\nAPPS = vscode\nGITPOD_URL =\n"))
+	Cfg.NameMapper = ini.SnackCase
+	newIDEService()
+	assert.Empty(t, GitpodLink("https://example.com/owner/repo"))
+
+	Cfg, _ = ini.Load([]byte("[ide]\nENABLED = false"))
+	Cfg.NameMapper = ini.SnackCase
+	newIDEService()
+	assert.Empty(t, IDE.AppList)
+	assert.Empty(t, GitpodLink("https://example.com/owner/repo"))
+}
@@ -33,7 +33,11 @@ var (
 		IssueQueueDir         string
 		IssueQueueConnStr     string
 		IssueQueueBatchNumber int
-		StartupTimeout        time.Duration
+		// IssueHighlight enables the highlighted snippets of the issue search results
+		IssueHighlight              bool
+		IssueHighlightFragmentSize  int
+		IssueHighlightFragmentCount int
+		StartupTimeout              time.Duration
 
 		RepoIndexerEnabled bool
 		RepoType           string
@@ -55,6 +59,10 @@ var (
 		IssueQueueConnStr:     "",
 		IssueQueueBatchNumber: 20,
 
+		IssueHighlight:              true,
+		IssueHighlightFragmentSize:  100,
+		IssueHighlightFragmentCount: 3,
+
 		RepoIndexerEnabled: false,
 		RepoType:           "bleve",
 		RepoPath:           "indexers/repos.bleve",
@@ -79,6 +87,9 @@ func newIndexerService() {
 	Indexer.IssueQueueDir = sec.Key("ISSUE_INDEXER_QUEUE_DIR").MustString(path.Join(AppDataPath, "indexers/issues.queue"))
 	Indexer.IssueQueueConnStr = sec.Key("ISSUE_INDEXER_QUEUE_CONN_STR").MustString("")
 	Indexer.IssueQueueBatchNumber = sec.Key("ISSUE_INDEXER_QUEUE_BATCH_NUMBER").MustInt(20)
+	Indexer.IssueHighlight = sec.Key("ISSUE_INDEXER_HIGHLIGHT").MustBool(Indexer.IssueHighlight)
+	Indexer.IssueHighlightFragmentSize = sec.Key("ISSUE_INDEXER_HIGHLIGHT_FRAGMENT_SIZE").MustInt(Indexer.IssueHighlightFragmentSize)
+	Indexer.IssueHighlightFragmentCount = sec.Key("ISSUE_INDEXER_HIGHLIGHT_FRAGMENTS").MustInt(Indexer.IssueHighlightFragmentCount)
 
 	Indexer.RepoIndexerEnabled = sec.Key("REPO_INDEXER_ENABLED").MustBool(false)
 	Indexer.RepoType = sec.Key("REPO_INDEXER_TYPE").MustString("bleve")
@@ -0,0 +1,50 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"net/url"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+var (
+	// InstanceStats defines the settings of the reporting of the anonymized statistics of the instance
+	InstanceStats = struct {
+		Enabled  bool
+		Endpoint string
+		Headers  string
+		Timeout  time.Duration
+		// InstanceName is sent with the statistics to tell the instances apart, they are only identified by an
+		// anonymous ID if it is empty
+		InstanceName string
+		// HeaderMap is the parsed Headers
+		HeaderMap map[string]string `ini:"-"`
+	}{
+		Enabled: false,
+		Timeout: 30 * time.Second,
+	}
+)
+
+func newInstanceStatsService() {
+	sec := Cfg.Section("instance_stats")
+	if err := sec.MapTo(&InstanceStats); err != nil {
+		log.Fatal("Failed to map InstanceStats settings: %v", err)
+	}
+	if !InstanceStats.Enabled {
+		return
+	}
+
+	if len(InstanceStats.Endpoint) == 0 {
+		log.Fatal("[instance_stats] ENDPOINT must be set to report the statistics of the instance")
+	}
+	if u, err := url.Parse(InstanceStats.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		log.Fatal("Invalid [instance_stats] ENDPOINT %q: must be an http or https URL", InstanceStats.Endpoint)
+	}
+	InstanceStats.HeaderMap = parseHeaders("instance_stats", InstanceStats.Headers)
+
+	log.Info("Instance Statistics Reporting Enabled")
+}
@@ -0,0 +1,101 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+var (
+	// InternalAPI describes how the internal API used by the git hooks, `gitea serv`
+	// and `gitea manager` is served and reached
+	InternalAPI = struct {
+		// Separate is true if the internal API is served on its own listener
+		// instead of being mounted on the main web listener
+		Separate             bool
+		Protocol             Scheme
+		HTTPAddr             string
+		HTTPPort             string
+		UnixSocketPermission uint32
+		// CertFile and KeyFile are the server certificate for the https protocol
+		CertFile string
+		KeyFile  string
+		// ClientCAFile enables mutual TLS: clients must present a certificate signed by this CA
+		ClientCAFile string
+		// ClientCertFile and ClientKeyFile are presented by the internal clients
+		ClientCertFile string
+		ClientKeyFile  string
+		// CAFile is used by the internal clients to verify the server certificate
+		CAFile   string
+		LocalURL string
+		// TokenLifetime is the validity of the signed internal tokens. If it is 0
+		// the static INTERNAL_TOKEN is sent and accepted as is.
+		TokenLifetime time.Duration
+		// PreviousTokens are still accepted to sign tokens whilst INTERNAL_TOKEN is being rotated
+		PreviousTokens []string
+	}{}
+)
+
+func newInternalAPI() {
+	sec := Cfg.Section("internal_api")
+
+	InternalAPI.Separate = true
+	switch sec.Key("PROTOCOL").String() {
+	case "":
+		InternalAPI.Separate = false
+		InternalAPI.Protocol = Protocol
+		InternalAPI.HTTPAddr = HTTPAddr
+		InternalAPI.HTTPPort = HTTPPort
+		InternalAPI.LocalURL = LocalURL
+	case "unix":
+		InternalAPI.Protocol = UnixSocket
+		InternalAPI.HTTPAddr = sec.Key("HTTP_ADDR").MustString(filepath.Join(AppDataPath, "internal.sock"))
+		permissionRaw := sec.Key("UNIX_SOCKET_PERMISSION").MustString("600")
+		permission, err := strconv.ParseUint(permissionRaw, 8, 32)
+		if err != nil || permission > 0777 {
+			log.Fatal("Failed to parse [internal_api] UNIX_SOCKET_PERMISSION: %s", permissionRaw)
+		}
+		InternalAPI.UnixSocketPermission = uint32(permission)
+		InternalAPI.LocalURL = sec.Key("LOCAL_ROOT_URL").MustString("http://unix/")
+	case "https":
+		InternalAPI.Protocol = HTTPS
+		InternalAPI.HTTPAddr = sec.Key("HTTP_ADDR").MustString("127.0.0.1")
+		InternalAPI.HTTPPort = sec.Key("HTTP_PORT").MustString("3001")
+		InternalAPI.CertFile = customFilePath(sec.Key("CERT_FILE").String())
+		InternalAPI.KeyFile = customFilePath(sec.Key("KEY_FILE").String())
+		if InternalAPI.CertFile == "" || InternalAPI.KeyFile == "" {
+			log.Fatal("[internal_api] CERT_FILE and KEY_FILE must be set when PROTOCOL is https")
+		}
+		InternalAPI.ClientCAFile = customFilePath(sec.Key("CLIENT_CA_FILE").String())
+		InternalAPI.ClientCertFile = customFilePath(sec.Key("CLIENT_CERT_FILE").String())
+		InternalAPI.ClientKeyFile = customFilePath(sec.Key("CLIENT_KEY_FILE").String())
+		InternalAPI.CAFile = customFilePath(sec.Key("CA_FILE").String())
+
+		host := InternalAPI.HTTPAddr
+		if host == "0.0.0.0" || host == "::" {
+			host = "localhost"
+		}
+		InternalAPI.LocalURL = sec.Key("LOCAL_ROOT_URL").MustString("https://" + net.JoinHostPort(host, InternalAPI.HTTPPort) + "/")
+	default:
+		log.Fatal("Unsupported [internal_api] PROTOCOL: %s", sec.Key("PROTOCOL").String())
+	}
+	InternalAPI.LocalURL = strings.TrimRight(InternalAPI.LocalURL, "/") + "/"
+
+	InternalAPI.TokenLifetime = sec.Key("TOKEN_LIFETIME").MustDuration(0)
+	InternalAPI.PreviousTokens = sec.Key("PREVIOUS_TOKENS").Strings(",")
+}
+
+func customFilePath(file string) string {
+	if len(file) > 0 && !filepath.IsAbs(file) {
+		return filepath.Join(CustomPath, file)
+	}
+	return file
+}
@@ -205,6 +205,13 @@ func generateLogConfig(sec *ini.Section, name string, defaults defaultLogOptions
 
 	logConfig["colorize"] = sec.Key("COLORIZE").MustBool(false)
 
+	logFormats := []string{"text", "json"}
+	format := sec.Key("FORMAT").In(Cfg.Section("log").Key("FORMAT").In("text", logFormats), logFormats)
+	logConfig["format"] = format
+	if format == "json" {
+		logConfig["colorize"] = false
+	}
+
 	json := jsoniter.ConfigCompatibleWithStandardLibrary
 	byteConfig, err := json.Marshal(logConfig)
 	if err != nil {
@@ -277,7 +284,7 @@ func newRouterLogService() {
 	if !DisableRouterLog {
 		options := newDefaultLogOptions()
 		options.filename = filepath.Join(LogRootPath, "router.log")
-		options.flags = "date,time" // For the router we don't want any prefixed flags
+		options.flags = "date,time,requestid" // For the router we don't want any prefixed flags
 		options.bufferLength = Cfg.Section("log").Key("BUFFER_LEN").MustInt64(10000)
 		generateNamedLogger("router", options)
 	}
@@ -335,6 +342,14 @@ func newLogService() {
 
 	AddLogDescription(log.DEFAULT, &description)
 
+	for _, moduleLevel := range strings.Split(Cfg.Section("log").Key("MODULE_LEVELS").String(), ",") {
+		fields := strings.SplitN(strings.TrimSpace(moduleLevel), ":", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		log.SetModuleLevel(fields[0], log.FromString(strings.TrimSpace(fields[1])))
+	}
+
 	// Finally redirect the default golog to here
 	golog.SetFlags(0)
 	golog.SetPrefix("")
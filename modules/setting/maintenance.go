@@ -0,0 +1,19 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+var (
+	// Maintenance defines the instance maintenance mode the server starts in
+	Maintenance = struct {
+		Enabled bool
+		Message string
+	}{}
+)
+
+func newMaintenanceService() {
+	sec := Cfg.Section("maintenance")
+	Maintenance.Enabled = sec.Key("ENABLED").MustBool(false)
+	Maintenance.Message = sec.Key("MESSAGE").MustString("")
+}
@@ -5,6 +5,9 @@
 package setting
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"regexp"
 	"strings"
 
@@ -19,6 +22,22 @@ var (
 	ExternalSanitizerRules []MarkupSanitizerRule
 )
 
+// MediaProxy represents the settings of the proxy the external images of the rendered markup are loaded through, to
+// avoid mixed content and to not disclose the addresses of the users to the hosts of the images
+var MediaProxy = struct {
+	Enabled bool
+	// ServerURL is the URL of a camo server, the built-in proxy of Gitea being used if it is empty
+	ServerURL string
+	// Key is the key the proxied URLs are signed with
+	Key string
+	// MaxSize is the maximum size of the images loaded by the built-in proxy
+	MaxSize int64
+	// BuiltIn is true if the images are loaded through the built-in proxy
+	BuiltIn bool
+}{
+	MaxSize: 5 * 1024 * 1024,
+}
+
 // MarkupParser defines the external parser configured in ini
 type MarkupParser struct {
 	Enabled        bool
@@ -37,6 +56,8 @@ type MarkupSanitizerRule struct {
 }
 
 func newMarkup() {
+	newMediaProxy(Cfg.Section("markup"))
+
 	for _, sec := range Cfg.Section("markup").ChildSections() {
 		name := strings.TrimPrefix(sec.Name(), "markup.")
 		if name == "" {
@@ -131,3 +152,31 @@ func newMarkupRenderer(name string, sec *ini.Section) {
 		IsInputFile:    sec.Key("IS_INPUT_FILE").MustBool(false),
 	})
 }
+
+// deriveMediaProxyKey derives the key of the built-in media proxy from the secret key, so that the signatures of the
+// proxied links, which are public, are not computed with the secret key itself
+func deriveMediaProxyKey(secretKey string) string {
+	mac := hmac.New(sha256.New, []byte(secretKey))
+	_, _ = mac.Write([]byte("media-proxy"))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newMediaProxy(sec *ini.Section) {
+	MediaProxy.Enabled = sec.Key("MEDIA_PROXY_ENABLED").MustBool(false)
+	MediaProxy.ServerURL = strings.TrimSuffix(sec.Key("MEDIA_PROXY_SERVER_URL").String(), "/")
+	MediaProxy.Key = sec.Key("MEDIA_PROXY_KEY").String()
+	MediaProxy.MaxSize = sec.Key("MEDIA_PROXY_MAX_SIZE").MustInt64(MediaProxy.MaxSize)
+	if !MediaProxy.Enabled {
+		return
+	}
+
+	MediaProxy.BuiltIn = MediaProxy.ServerURL == ""
+	if MediaProxy.BuiltIn {
+		MediaProxy.ServerURL = AppURL + "media-proxy"
+		if MediaProxy.Key == "" {
+			MediaProxy.Key = deriveMediaProxyKey(SecretKey)
+		}
+	} else if MediaProxy.Key == "" {
+		log.Fatal("[markup] MEDIA_PROXY_KEY must be set to the key of the camo server %s", MediaProxy.ServerURL)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ini "gopkg.in/ini.v1"
+)
+
+func Test_newMediaProxy(t *testing.T) {
+	oldMediaProxy, oldAppURL, oldSecretKey := MediaProxy, AppURL, SecretKey
+	defer func() {
+		MediaProxy, AppURL, SecretKey = oldMediaProxy, oldAppURL, oldSecretKey
+	}()
+	AppURL = "https://try.gitea.io/"
+	SecretKey = "secret"
+
+	// the built-in proxy does not sign the links with the secret key itself
+	cfg, _ := ini.Load([]byte(`
+[markup]
+MEDIA_PROXY_ENABLED = true
+`))
+	newMediaProxy(cfg.Section("markup"))
+	assert.True(t, MediaProxy.BuiltIn)
+	assert.Equal(t, "https://try.gitea.io/media-proxy", MediaProxy.ServerURL)
+	assert.Len(t, MediaProxy.Key, 64)
+	assert.NotEqual(t, SecretKey, MediaProxy.Key)
+	assert.Equal(t, deriveMediaProxyKey(SecretKey), MediaProxy.Key)
+	assert.NotEqual(t, deriveMediaProxyKey("other"), MediaProxy.Key)
+
+	cfg, _ = ini.Load([]byte(`
+[markup]
+MEDIA_PROXY_ENABLED = true
+MEDIA_PROXY_KEY = media
+`))
+	newMediaProxy(cfg.Section("markup"))
+	assert.Equal(t, "media", MediaProxy.Key)
+}
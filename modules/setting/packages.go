@@ -0,0 +1,87 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"math"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/dustin/go-humanize"
+	ini "gopkg.in/ini.v1"
+)
+
+var (
+	// Packages defines the settings of the package registry
+	Packages = struct {
+		Storage
+		Enabled bool
+		// ChunkedUploadPath is the local directory unfinished container blob uploads are stored in
+		ChunkedUploadPath string
+
+		// LimitTotalOwnerSize is the maximum size of all packages of an owner in bytes, -1 means no limit
+		LimitTotalOwnerSize int64
+		// LimitTotalOwnerSizes overrides LimitTotalOwnerSize for the owners with these lower names
+		LimitTotalOwnerSizes map[string]int64
+		LimitSizeContainer   int64
+		LimitSizeNpm         int64
+		LimitSizePyPI        int64
+		LimitSizeMaven       int64
+		LimitSizeNuGet       int64
+	}{
+		Enabled:              true,
+		LimitTotalOwnerSize:  -1,
+		LimitTotalOwnerSizes: map[string]int64{},
+		LimitSizeContainer:   -1,
+		LimitSizeNpm:         -1,
+		LimitSizePyPI:        -1,
+		LimitSizeMaven:       -1,
+		LimitSizeNuGet:       -1,
+	}
+)
+
+func newPackagesService() {
+	sec := Cfg.Section("packages")
+	Packages.Enabled = sec.Key("ENABLED").MustBool(true)
+
+	Packages.ChunkedUploadPath = sec.Key("CHUNKED_UPLOAD_PATH").MustString(path.Join(AppDataPath, "tmp/package-upload"))
+	if !filepath.IsAbs(Packages.ChunkedUploadPath) {
+		Packages.ChunkedUploadPath = path.Join(AppWorkPath, Packages.ChunkedUploadPath)
+	}
+
+	Packages.LimitTotalOwnerSize = mustBytes(sec, "LIMIT_TOTAL_OWNER_SIZE")
+	ownerSec := Cfg.Section("packages.owner_quotas")
+	Packages.LimitTotalOwnerSizes = make(map[string]int64, len(ownerSec.Keys()))
+	for _, key := range ownerSec.Keys() {
+		Packages.LimitTotalOwnerSizes[strings.ToLower(key.Name())] = mustBytes(ownerSec, key.Name())
+	}
+	Packages.LimitSizeContainer = mustBytes(sec, "LIMIT_SIZE_CONTAINER")
+	Packages.LimitSizeNpm = mustBytes(sec, "LIMIT_SIZE_NPM")
+	Packages.LimitSizePyPI = mustBytes(sec, "LIMIT_SIZE_PYPI")
+	Packages.LimitSizeMaven = mustBytes(sec, "LIMIT_SIZE_MAVEN")
+	Packages.LimitSizeNuGet = mustBytes(sec, "LIMIT_SIZE_NUGET")
+
+	storageType := sec.Key("STORAGE_TYPE").MustString("")
+	Packages.Storage = getStorage("packages", storageType, sec)
+}
+
+// mustBytes parses a size like "500 MB" with -1 meaning no limit
+func mustBytes(section *ini.Section, key string) int64 {
+	const noLimit = "-1"
+
+	value := section.Key(key).MustString(noLimit)
+	if value == noLimit {
+		return -1
+	}
+	bytes, err := humanize.ParseBytes(value)
+	if err != nil || bytes > math.MaxInt64 {
+		log.Error("Invalid value %q for %s.%s, the size is not limited", value, section.Name(), key)
+		return -1
+	}
+	return int64(bytes)
+}
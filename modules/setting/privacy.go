@@ -0,0 +1,72 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// How the IP addresses of the clients are written in the logs
+const (
+	IPLoggingFull       = "full"
+	IPLoggingAnonymized = "anonymized"
+	IPLoggingNone       = "none"
+)
+
+// PublicUserFields are the personal fields of the users the API can expose to the other users
+var PublicUserFields = []string{"full_name", "email", "avatar_url", "created"}
+
+var (
+	// Privacy defines how much personal data is logged and exposed
+	Privacy = struct {
+		IPLogging string
+		// PublicUserFields are the fields of PublicUserFields the API exposes to the other users than the user
+		// and the site administrators
+		PublicUserFields map[string]bool
+		// MailAvatars is whether the avatars of the users are shown in the notification mails, the mail clients
+		// loading them from the server when the mails are read
+		MailAvatars bool
+	}{
+		IPLogging:        IPLoggingFull,
+		PublicUserFields: map[string]bool{"full_name": true, "email": true, "avatar_url": true, "created": true},
+		MailAvatars:      true,
+	}
+)
+
+func newPrivacyService() {
+	sec := Cfg.Section("privacy")
+	Privacy.IPLogging = sec.Key("IP_LOGGING").In(IPLoggingFull, []string{IPLoggingFull, IPLoggingAnonymized, IPLoggingNone})
+
+	fields := PublicUserFields
+	if sec.HasKey("PUBLIC_USER_FIELDS") {
+		fields = sec.Key("PUBLIC_USER_FIELDS").Strings(",")
+	}
+	Privacy.PublicUserFields = make(map[string]bool, len(fields))
+	for _, field := range fields {
+		field = strings.ToLower(field)
+		if !util.IsStringInSlice(field, PublicUserFields) {
+			log.Warn("Unknown field %q in [privacy] PUBLIC_USER_FIELDS, the fields are: %s", field, strings.Join(PublicUserFields, ", "))
+			continue
+		}
+		Privacy.PublicUserFields[field] = true
+	}
+
+	Privacy.MailAvatars = sec.Key("MAIL_AVATARS").MustBool(true)
+}
+
+// LoggedRemoteAddr returns the address of a client as it is written in the logs, according to IP_LOGGING
+func LoggedRemoteAddr(addr string) string {
+	switch Privacy.IPLogging {
+	case IPLoggingAnonymized:
+		return util.AnonymizeIP(addr)
+	case IPLoggingNone:
+		return "-"
+	default:
+		return addr
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ini "gopkg.in/ini.v1"
+)
+
+func Test_newPrivacyService(t *testing.T) {
+	defer func(ipLogging string, fields map[string]bool) {
+		Privacy.IPLogging = ipLogging
+		Privacy.PublicUserFields = fields
+	}(Privacy.IPLogging, Privacy.PublicUserFields)
+
+	Cfg, _ = ini.Load([]byte(""))
+	newPrivacyService()
+	assert.Equal(t, IPLoggingFull, Privacy.IPLogging)
+	assert.Len(t, Privacy.PublicUserFields, len(PublicUserFields))
+	assert.Equal(t, "192.168.1.42:1234", LoggedRemoteAddr("192.168.1.42:1234"))
+
+	Cfg, _ = ini.Load([]byte(`
+[privacy]
+IP_LOGGING = anonymized
+PUBLIC_USER_FIELDS = Full_Name, unknown, avatar_url
+`))
+	newPrivacyService()
+	assert.Equal(t, map[string]bool{"full_name": true, "avatar_url": true}, Privacy.PublicUserFields)
+	assert.Equal(t, "192.168.1.0", LoggedRemoteAddr("192.168.1.42:1234"))
+
+	Cfg, _ = ini.Load([]byte(`
+[privacy]
+IP_LOGGING = none
+PUBLIC_USER_FIELDS =
+`))
+	newPrivacyService()
+	assert.Empty(t, Privacy.PublicUserFields)
+	assert.Equal(t, "-", LoggedRemoteAddr("192.168.1.42:1234"))
+}
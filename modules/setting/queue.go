@@ -153,6 +153,17 @@ func NewQueueService() {
 		_, _ = section.NewKey("LENGTH", fmt.Sprintf("%d", Cfg.Section("mailer").Key("SEND_BUFFER_LEN").MustInt(100)))
 	}
 
+	// Handle the old webhook configuration
+	// Please note this will be a unique queue
+	section = Cfg.Section("queue.webhook_sender")
+	sectionMap = map[string]bool{}
+	for _, key := range section.Keys() {
+		sectionMap[key.Name()] = true
+	}
+	if _, ok := sectionMap["LENGTH"]; !ok {
+		_, _ = section.NewKey("LENGTH", fmt.Sprintf("%d", Webhook.QueueLength))
+	}
+
 	// Handle the old test pull requests configuration
 	// Please note this will be a unique queue
 	section = Cfg.Section("queue.pr_patch_checker")
@@ -0,0 +1,22 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+var (
+	// RepoArchive defines the settings of the storage of the generated repository archives
+	RepoArchive = struct {
+		Storage
+		// MaxCacheSize is the size the stored archives are reduced to by the archive cleanup, -1 for no limit
+		MaxCacheSize int64
+	}{}
+)
+
+func newRepoArchiveService() {
+	sec := Cfg.Section("repo-archive")
+	storageType := sec.Key("STORAGE_TYPE").MustString("")
+
+	RepoArchive.Storage = getStorage("repo-archive", storageType, sec)
+	RepoArchive.MaxCacheSize = mustBytes(sec, "MAX_CACHE_SIZE")
+}
@@ -8,6 +8,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/modules/log"
 )
@@ -77,17 +78,34 @@ var (
 			DefaultMergeMessageAllAuthors            bool
 			DefaultMergeMessageMaxApprovers          int
 			DefaultMergeMessageOfficialApproversOnly bool
+			MergeQueueCheckTimeout                   time.Duration
 		} `ini:"repository.pull-request"`
 
 		// Issue Setting
 		Issue struct {
-			LockReasons []string
+			LockReasons              []string
+			AllowPurgeContentHistory bool
 		} `ini:"repository.issue"`
 
 		Release struct {
 			AllowedTypes string
 		} `ini:"repository.release"`
 
+		LargeFile struct {
+			MaxSize int64
+			Block   bool
+		} `ini:"repository.large-file"`
+
+		// Maintenance settings
+		Maintenance struct {
+			Enabled          bool
+			MaxLooseObjects  int64
+			MaxPacks         int64
+			MaxGrowthPercent int64
+			PruneExpire      string
+			Timeout          time.Duration
+		} `ini:"repository.maintenance"`
+
 		Signing struct {
 			SigningKey        string
 			SigningName       string
@@ -197,6 +215,7 @@ var (
 			DefaultMergeMessageAllAuthors            bool
 			DefaultMergeMessageMaxApprovers          int
 			DefaultMergeMessageOfficialApproversOnly bool
+			MergeQueueCheckTimeout                   time.Duration
 		}{
 			WorkInProgressPrefixes: []string{"WIP:", "[WIP]"},
 			// Same as GitHub. See
@@ -208,13 +227,16 @@ var (
 			DefaultMergeMessageAllAuthors:            false,
 			DefaultMergeMessageMaxApprovers:          10,
 			DefaultMergeMessageOfficialApproversOnly: true,
+			MergeQueueCheckTimeout:                   2 * time.Hour,
 		},
 
 		// Issue settings
 		Issue: struct {
-			LockReasons []string
+			LockReasons              []string
+			AllowPurgeContentHistory bool
 		}{
-			LockReasons: strings.Split("Too heated,Off-topic,Spam,Resolved", ","),
+			LockReasons:              strings.Split("Too heated,Off-topic,Spam,Resolved", ","),
+			AllowPurgeContentHistory: false,
 		},
 
 		Release: struct {
@@ -223,6 +245,32 @@ var (
 			AllowedTypes: "",
 		},
 
+		// Large file detection settings
+		LargeFile: struct {
+			MaxSize int64
+			Block   bool
+		}{
+			MaxSize: 0,
+			Block:   false,
+		},
+
+		// Maintenance settings
+		Maintenance: struct {
+			Enabled          bool
+			MaxLooseObjects  int64
+			MaxPacks         int64
+			MaxGrowthPercent int64
+			PruneExpire      string
+			Timeout          time.Duration
+		}{
+			Enabled:          false,
+			MaxLooseObjects:  1000,
+			MaxPacks:         50,
+			MaxGrowthPercent: 100,
+			PruneExpire:      "2.weeks.ago",
+			Timeout:          time.Hour,
+		},
+
 		// Signing settings
 		Signing: struct {
 			SigningKey        string
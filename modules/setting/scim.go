@@ -0,0 +1,54 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+var (
+	// SCIM defines the settings of the SCIM 2.0 provisioning API
+	SCIM = struct {
+		Enabled             bool
+		DefaultPagingNum    int
+		MaxResults          int
+		LoginSource         string
+		GroupTeamMap        string
+		GroupTeamMapRemoval bool
+		// TeamMapping is the parsed GroupTeamMap with lower cased group names
+		TeamMapping map[string]map[string][]string `ini:"-"`
+	}{
+		Enabled:          false,
+		DefaultPagingNum: 100,
+		MaxResults:       1000,
+	}
+)
+
+func newSCIMService() {
+	sec := Cfg.Section("scim")
+	if err := sec.MapTo(&SCIM); err != nil {
+		log.Fatal("Failed to map SCIM settings: %v", err)
+	}
+
+	mapping := map[string]map[string][]string{}
+	if len(strings.TrimSpace(SCIM.GroupTeamMap)) > 0 {
+		json := jsoniter.ConfigCompatibleWithStandardLibrary
+		if err := json.Unmarshal([]byte(SCIM.GroupTeamMap), &mapping); err != nil {
+			log.Fatal("Invalid [scim] GROUP_TEAM_MAP: %v", err)
+		}
+	}
+	SCIM.TeamMapping = make(map[string]map[string][]string, len(mapping))
+	for group, teams := range mapping {
+		SCIM.TeamMapping[strings.ToLower(group)] = teams
+	}
+
+	if SCIM.Enabled {
+		log.Info("SCIM Service Enabled")
+	}
+}
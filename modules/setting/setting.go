@@ -184,6 +184,7 @@ var (
 	UI = struct {
 		ExplorePagingNum      int
 		IssuePagingNum        int
+		TimelinePagingNum     int
 		RepoSearchPagingNum   int
 		MembersPagingNum      int
 		FeedMaxCommitNum      int
@@ -230,6 +231,7 @@ var (
 	}{
 		ExplorePagingNum:    20,
 		IssuePagingNum:      10,
+		TimelinePagingNum:   100,
 		RepoSearchPagingNum: 10,
 		MembersPagingNum:    20,
 		FeedMaxCommitNum:    5,
@@ -326,8 +328,9 @@ var (
 
 	// Mirror settings
 	Mirror struct {
-		DefaultInterval time.Duration
-		MinInterval     time.Duration
+		DefaultInterval  time.Duration
+		MinInterval      time.Duration
+		FailureThreshold int
 	}
 
 	// API settings
@@ -545,6 +548,9 @@ func NewContext() {
 	} else {
 		log.Warn("Custom config '%s' not found, ignore this if you're running first time", CustomConf)
 	}
+	if err := loadConfigFromEnvironment(Cfg, os.Environ()); err != nil {
+		log.Fatal("Failed to load configuration from the environment: %v", err)
+	}
 	Cfg.NameMapper = ini.SnackCase
 
 	homeDir, err := com.HomeDir()
@@ -841,6 +847,7 @@ func NewContext() {
 	PasswordCheckPwn = sec.Key("PASSWORD_CHECK_PWN").MustBool(false)
 
 	InternalToken = loadInternalToken(sec)
+	newInternalAPI()
 
 	cfgdata := sec.Key("PASSWORD_COMPLEXITY").Strings(",")
 	if len(cfgdata) == 0 {
@@ -856,6 +863,12 @@ func NewContext() {
 
 	newAttachmentService()
 	newLFSService()
+	newActionsService()
+	newArtifactsService()
+	newPackagesService()
+	newRepoArchiveService()
+	newMaintenanceService()
+	newPrivacyService()
 
 	timeFormatKey := Cfg.Section("time").Key("FORMAT").MustString("")
 	if timeFormatKey != "" {
@@ -938,6 +951,7 @@ func NewContext() {
 	sec = Cfg.Section("mirror")
 	Mirror.MinInterval = sec.Key("MIN_INTERVAL").MustDuration(10 * time.Minute)
 	Mirror.DefaultInterval = sec.Key("DEFAULT_INTERVAL").MustDuration(8 * time.Hour)
+	Mirror.FailureThreshold = sec.Key("FAILURE_THRESHOLD").MustInt(3)
 	if Mirror.MinInterval.Minutes() < 1 {
 		log.Warn("Mirror.MinInterval is too low")
 		Mirror.MinInterval = 1 * time.Minute
@@ -1163,6 +1177,10 @@ func NewServices() {
 	newCacheService()
 	newSessionService()
 	newCORSService()
+	newSCIMService()
+	newVirusScanService()
+	newTracingService()
+	newInstanceStatsService()
 	newMailService()
 	newRegisterMailService()
 	newNotifyMailService()
@@ -1172,4 +1190,5 @@ func NewServices() {
 	newTaskService()
 	NewQueueService()
 	newProject()
+	newIDEService()
 }
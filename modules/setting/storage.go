@@ -42,6 +42,10 @@ func getStorage(name, typ string, targetSec *ini.Section) Storage {
 	sec.Key("MINIO_BUCKET").MustString("gitea")
 	sec.Key("MINIO_LOCATION").MustString("us-east-1")
 	sec.Key("MINIO_USE_SSL").MustBool(false)
+	sec.Key("AZURE_BLOB_ENDPOINT").MustString("")
+	sec.Key("AZURE_BLOB_ACCOUNT_NAME").MustString("")
+	sec.Key("AZURE_BLOB_ACCOUNT_KEY").MustString("")
+	sec.Key("AZURE_BLOB_CONTAINER").MustString("gitea")
 
 	var storage Storage
 	storage.Section = targetSec
@@ -82,6 +86,7 @@ func getStorage(name, typ string, targetSec *ini.Section) Storage {
 		storage.Section.Key("PATH").SetValue(storage.Path)
 	}
 	storage.Section.Key("MINIO_BASE_PATH").MustString(name + "/")
+	storage.Section.Key("AZURE_BLOB_BASE_PATH").MustString(name + "/")
 
 	return storage
 }
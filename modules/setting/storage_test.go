@@ -195,3 +195,24 @@ STORAGE_TYPE = minio
 
 	assert.EqualValues(t, "minio", storage.Type)
 }
+
+func Test_getStorageAzureBlob(t *testing.T) {
+	iniStr := `
+[repo-archive]
+STORAGE_TYPE = my_azure
+
+[storage.my_azure]
+STORAGE_TYPE = azureblob
+AZURE_BLOB_ACCOUNT_NAME = account
+`
+	Cfg, _ = ini.Load([]byte(iniStr))
+
+	sec := Cfg.Section("repo-archive")
+	storageType := sec.Key("STORAGE_TYPE").MustString("")
+	storage := getStorage("repo-archive", storageType, sec)
+
+	assert.EqualValues(t, "azureblob", storage.Type)
+	assert.EqualValues(t, "account", storage.Section.Key("AZURE_BLOB_ACCOUNT_NAME").String())
+	assert.EqualValues(t, "gitea", storage.Section.Key("AZURE_BLOB_CONTAINER").String())
+	assert.EqualValues(t, "repo-archive/", storage.Section.Key("AZURE_BLOB_BASE_PATH").String())
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+var (
+	// Tracing defines the settings of the OpenTelemetry tracing
+	Tracing = struct {
+		Enabled        bool
+		ServiceName    string
+		Endpoint       string
+		Headers        string
+		SampleRatio    float64
+		BatchSize      int
+		QueueLength    int
+		ExportInterval time.Duration
+		ExportTimeout  time.Duration
+		// HeaderMap is the parsed Headers
+		HeaderMap map[string]string `ini:"-"`
+	}{
+		Enabled:        false,
+		ServiceName:    "gitea",
+		Endpoint:       "http://localhost:4318/v1/traces",
+		SampleRatio:    1,
+		BatchSize:      512,
+		QueueLength:    2048,
+		ExportInterval: 5 * time.Second,
+		ExportTimeout:  10 * time.Second,
+	}
+)
+
+func newTracingService() {
+	sec := Cfg.Section("tracing")
+	if err := sec.MapTo(&Tracing); err != nil {
+		log.Fatal("Failed to map Tracing settings: %v", err)
+	}
+	if !Tracing.Enabled {
+		return
+	}
+
+	if _, err := url.Parse(Tracing.Endpoint); err != nil {
+		log.Fatal("Invalid [tracing] ENDPOINT %q: %v", Tracing.Endpoint, err)
+	}
+	if Tracing.SampleRatio < 0 || Tracing.SampleRatio > 1 {
+		log.Fatal("Invalid [tracing] SAMPLE_RATIO %v: must be between 0 and 1", Tracing.SampleRatio)
+	}
+	if Tracing.BatchSize <= 0 {
+		Tracing.BatchSize = 512
+	}
+	if Tracing.QueueLength < Tracing.BatchSize {
+		Tracing.QueueLength = Tracing.BatchSize
+	}
+
+	Tracing.HeaderMap = parseHeaders("tracing", Tracing.Headers)
+
+	log.Info("Tracing Service Enabled")
+}
+
+// parseHeaders parses the comma separated name=value list of HTTP headers of the HEADERS key of a section
+func parseHeaders(section, headers string) map[string]string {
+	headerMap := make(map[string]string)
+	for _, header := range strings.Split(headers, ",") {
+		header = strings.TrimSpace(header)
+		if len(header) == 0 {
+			continue
+		}
+		fields := strings.SplitN(header, "=", 2)
+		if len(fields) != 2 {
+			log.Fatal("Invalid [%s] HEADERS entry %q: must be name=value", section, header)
+		}
+		headerMap[strings.TrimSpace(fields[0])] = strings.TrimSpace(fields[1])
+	}
+	return headerMap
+}
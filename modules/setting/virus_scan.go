@@ -0,0 +1,83 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"net/url"
+	"path"
+	"path/filepath"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// Virus scanning backends and actions
+const (
+	VirusScanBackendClamAV = "clamav"
+	VirusScanBackendICAP   = "icap"
+
+	VirusScanActionReject     = "reject"
+	VirusScanActionQuarantine = "quarantine"
+	VirusScanActionFlag       = "flag"
+)
+
+var (
+	// VirusScan defines the settings of the virus scanning of uploads
+	VirusScan = struct {
+		Enabled bool
+		Backend string
+		// ClamAVAddr is the address of clamd, e.g. tcp://localhost:3310 or unix:///run/clamav/clamd.ctl
+		ClamAVAddr     string `ini:"CLAMAV_ADDR"`
+		ICAPURL        string `ini:"ICAP_URL"`
+		Timeout        time.Duration
+		Action         string
+		QuarantinePath string
+		RejectOnError  bool
+	}{
+		Enabled:    false,
+		Backend:    VirusScanBackendClamAV,
+		ClamAVAddr: "tcp://localhost:3310",
+		ICAPURL:    "icap://localhost:1344/avscan",
+		Timeout:    time.Minute,
+		Action:     VirusScanActionReject,
+	}
+)
+
+func newVirusScanService() {
+	sec := Cfg.Section("virus_scan")
+	if err := sec.MapTo(&VirusScan); err != nil {
+		log.Fatal("Failed to map VirusScan settings: %v", err)
+	}
+
+	VirusScan.QuarantinePath = sec.Key("QUARANTINE_PATH").MustString(path.Join(AppDataPath, "quarantine"))
+	if !filepath.IsAbs(VirusScan.QuarantinePath) {
+		VirusScan.QuarantinePath = path.Join(AppWorkPath, VirusScan.QuarantinePath)
+	}
+
+	if !VirusScan.Enabled {
+		return
+	}
+
+	switch VirusScan.Backend {
+	case VirusScanBackendClamAV:
+		if _, err := url.Parse(VirusScan.ClamAVAddr); err != nil {
+			log.Fatal("Invalid [virus_scan] CLAMAV_ADDR %q: %v", VirusScan.ClamAVAddr, err)
+		}
+	case VirusScanBackendICAP:
+		if _, err := url.Parse(VirusScan.ICAPURL); err != nil {
+			log.Fatal("Invalid [virus_scan] ICAP_URL %q: %v", VirusScan.ICAPURL, err)
+		}
+	default:
+		log.Fatal("Unsupported [virus_scan] BACKEND %q", VirusScan.Backend)
+	}
+
+	switch VirusScan.Action {
+	case VirusScanActionReject, VirusScanActionQuarantine, VirusScanActionFlag:
+	default:
+		log.Fatal("Unsupported [virus_scan] ACTION %q", VirusScan.Action)
+	}
+
+	log.Info("Virus scanning of uploads enabled using %s", VirusScan.Backend)
+}
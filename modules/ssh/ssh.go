@@ -134,24 +134,24 @@ func sessionHandler(session ssh.Session) {
 
 func publicKeyHandler(ctx ssh.Context, key ssh.PublicKey) bool {
 	if log.IsDebug() { // <- FingerprintSHA256 is kinda expensive so only calculate it if necessary
-		log.Debug("Handle Public Key: Fingerprint: %s from %s", gossh.FingerprintSHA256(key), ctx.RemoteAddr())
+		log.Debug("Handle Public Key: Fingerprint: %s from %s", gossh.FingerprintSHA256(key), setting.LoggedRemoteAddr(ctx.RemoteAddr().String()))
 	}
 
 	if ctx.User() != setting.SSH.BuiltinServerUser {
 		log.Warn("Invalid SSH username %s - must use %s for all git operations via ssh", ctx.User(), setting.SSH.BuiltinServerUser)
-		log.Warn("Failed authentication attempt from %s", ctx.RemoteAddr())
+		log.Warn("Failed authentication attempt from %s", setting.LoggedRemoteAddr(ctx.RemoteAddr().String()))
 		return false
 	}
 
 	// check if we have a certificate
 	if cert, ok := key.(*gossh.Certificate); ok {
 		if log.IsDebug() { // <- FingerprintSHA256 is kinda expensive so only calculate it if necessary
-			log.Debug("Handle Certificate: %s Fingerprint: %s is a certificate", ctx.RemoteAddr(), gossh.FingerprintSHA256(key))
+			log.Debug("Handle Certificate: %s Fingerprint: %s is a certificate", setting.LoggedRemoteAddr(ctx.RemoteAddr().String()), gossh.FingerprintSHA256(key))
 		}
 
 		if len(setting.SSH.TrustedUserCAKeys) == 0 {
 			log.Warn("Certificate Rejected: No trusted certificate authorities for this server")
-			log.Warn("Failed authentication attempt from %s", ctx.RemoteAddr())
+			log.Warn("Failed authentication attempt from %s", setting.LoggedRemoteAddr(ctx.RemoteAddr().String()))
 			return false
 		}
 
@@ -161,7 +161,7 @@ func publicKeyHandler(ctx ssh.Context, key ssh.PublicKey) bool {
 			pkey, err := models.SearchPublicKeyByContentExact(principal)
 			if err != nil {
 				if models.IsErrKeyNotExist(err) {
-					log.Debug("Principal Rejected: %s Unknown Principal: %s", ctx.RemoteAddr(), principal)
+					log.Debug("Principal Rejected: %s Unknown Principal: %s", setting.LoggedRemoteAddr(ctx.RemoteAddr().String()), principal)
 					continue principalLoop
 				}
 				log.Error("SearchPublicKeyByContentExact: %v", err)
@@ -183,7 +183,7 @@ func publicKeyHandler(ctx ssh.Context, key ssh.PublicKey) bool {
 			// check the CA of the cert
 			if !c.IsUserAuthority(cert.SignatureKey) {
 				if log.IsDebug() {
-					log.Debug("Principal Rejected: %s Untrusted Authority Signature Fingerprint %s for Principal: %s", ctx.RemoteAddr(), gossh.FingerprintSHA256(cert.SignatureKey), principal)
+					log.Debug("Principal Rejected: %s Untrusted Authority Signature Fingerprint %s for Principal: %s", setting.LoggedRemoteAddr(ctx.RemoteAddr().String()), gossh.FingerprintSHA256(cert.SignatureKey), principal)
 				}
 				continue principalLoop
 			}
@@ -192,15 +192,15 @@ func publicKeyHandler(ctx ssh.Context, key ssh.PublicKey) bool {
 			if err := c.CheckCert(principal, cert); err != nil {
 				// User is presenting an invalid certificate - STOP any further processing
 				if log.IsError() {
-					log.Error("Invalid Certificate KeyID %s with Signature Fingerprint %s presented for Principal: %s from %s", cert.KeyId, gossh.FingerprintSHA256(cert.SignatureKey), principal, ctx.RemoteAddr())
+					log.Error("Invalid Certificate KeyID %s with Signature Fingerprint %s presented for Principal: %s from %s", cert.KeyId, gossh.FingerprintSHA256(cert.SignatureKey), principal, setting.LoggedRemoteAddr(ctx.RemoteAddr().String()))
 				}
-				log.Warn("Failed authentication attempt from %s", ctx.RemoteAddr())
+				log.Warn("Failed authentication attempt from %s", setting.LoggedRemoteAddr(ctx.RemoteAddr().String()))
 
 				return false
 			}
 
 			if log.IsDebug() { // <- FingerprintSHA256 is kinda expensive so only calculate it if necessary
-				log.Debug("Successfully authenticated: %s Certificate Fingerprint: %s Principal: %s", ctx.RemoteAddr(), gossh.FingerprintSHA256(key), principal)
+				log.Debug("Successfully authenticated: %s Certificate Fingerprint: %s Principal: %s", setting.LoggedRemoteAddr(ctx.RemoteAddr().String()), gossh.FingerprintSHA256(key), principal)
 			}
 			ctx.SetValue(giteaKeyID, pkey.ID)
 
@@ -208,22 +208,22 @@ func publicKeyHandler(ctx ssh.Context, key ssh.PublicKey) bool {
 		}
 
 		if log.IsWarn() {
-			log.Warn("From %s Fingerprint: %s is a certificate, but no valid principals found", ctx.RemoteAddr(), gossh.FingerprintSHA256(key))
-			log.Warn("Failed authentication attempt from %s", ctx.RemoteAddr())
+			log.Warn("From %s Fingerprint: %s is a certificate, but no valid principals found", setting.LoggedRemoteAddr(ctx.RemoteAddr().String()), gossh.FingerprintSHA256(key))
+			log.Warn("Failed authentication attempt from %s", setting.LoggedRemoteAddr(ctx.RemoteAddr().String()))
 		}
 		return false
 	}
 
 	if log.IsDebug() { // <- FingerprintSHA256 is kinda expensive so only calculate it if necessary
-		log.Debug("Handle Public Key: %s Fingerprint: %s is not a certificate", ctx.RemoteAddr(), gossh.FingerprintSHA256(key))
+		log.Debug("Handle Public Key: %s Fingerprint: %s is not a certificate", setting.LoggedRemoteAddr(ctx.RemoteAddr().String()), gossh.FingerprintSHA256(key))
 	}
 
 	pkey, err := models.SearchPublicKeyByContent(strings.TrimSpace(string(gossh.MarshalAuthorizedKey(key))))
 	if err != nil {
 		if models.IsErrKeyNotExist(err) {
 			if log.IsWarn() {
-				log.Warn("Unknown public key: %s from %s", gossh.FingerprintSHA256(key), ctx.RemoteAddr())
-				log.Warn("Failed authentication attempt from %s", ctx.RemoteAddr())
+				log.Warn("Unknown public key: %s from %s", gossh.FingerprintSHA256(key), setting.LoggedRemoteAddr(ctx.RemoteAddr().String()))
+				log.Warn("Failed authentication attempt from %s", setting.LoggedRemoteAddr(ctx.RemoteAddr().String()))
 			}
 			return false
 		}
@@ -232,7 +232,7 @@ func publicKeyHandler(ctx ssh.Context, key ssh.PublicKey) bool {
 	}
 
 	if log.IsDebug() { // <- FingerprintSHA256 is kinda expensive so only calculate it if necessary
-		log.Debug("Successfully authenticated: %s Public Key Fingerprint: %s", ctx.RemoteAddr(), gossh.FingerprintSHA256(key))
+		log.Debug("Successfully authenticated: %s Public Key Fingerprint: %s", setting.LoggedRemoteAddr(ctx.RemoteAddr().String()), gossh.FingerprintSHA256(key))
 	}
 	ctx.SetValue(giteaKeyID, pkey.ID)
 
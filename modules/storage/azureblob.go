@@ -0,0 +1,344 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+)
+
+var _ ObjectStorage = &AzureBlobStorage{}
+
+// AzureBlobStorageType is the type descriptor for azure blob storage
+const AzureBlobStorageType Type = "azureblob"
+
+// azureBlobBlockSize is the size of the blocks files are uploaded in
+var azureBlobBlockSize = 4 * 1024 * 1024
+
+// AzureBlobStorageConfig represents the configuration for an azure blob storage
+type AzureBlobStorageConfig struct {
+	// Endpoint is the URL of the blob service, https://<account>.blob.core.windows.net if empty
+	Endpoint    string `ini:"AZURE_BLOB_ENDPOINT"`
+	AccountName string `ini:"AZURE_BLOB_ACCOUNT_NAME"`
+	AccountKey  string `ini:"AZURE_BLOB_ACCOUNT_KEY"`
+	Container   string `ini:"AZURE_BLOB_CONTAINER"`
+	BasePath    string `ini:"AZURE_BLOB_BASE_PATH"`
+	// EncryptionScope is the encryption scope new blobs are encrypted with, the default of the container if empty
+	EncryptionScope string `ini:"AZURE_BLOB_ENCRYPTION_SCOPE"`
+}
+
+// AzureBlobStorage represents an azure blob storage container
+type AzureBlobStorage struct {
+	ctx           context.Context
+	credential    *azblob.SharedKeyCredential
+	container     azblob.ContainerURL
+	containerName string
+	basePath      string
+	// cpkOptions holds the encryption scope new blobs are encrypted with
+	cpkOptions azblob.ClientProvidedKeyOptions
+}
+
+func convertAzureBlobErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	var storageErr azblob.StorageError
+	if !errors.As(err, &storageErr) || storageErr.Response() == nil {
+		return err
+	}
+	switch storageErr.Response().StatusCode {
+	case http.StatusNotFound:
+		return os.ErrNotExist
+	case http.StatusForbidden:
+		return os.ErrPermission
+	}
+	return err
+}
+
+// NewAzureBlobStorage returns an azure blob storage
+func NewAzureBlobStorage(ctx context.Context, cfg interface{}) (ObjectStorage, error) {
+	configInterface, err := toConfig(AzureBlobStorageConfig{}, cfg)
+	if err != nil {
+		return nil, err
+	}
+	config := configInterface.(AzureBlobStorageConfig)
+
+	if config.AccountName == "" || config.Container == "" {
+		return nil, ErrInvalidConfiguration{cfg: cfg, err: errors.New("the account name and the container must be set")}
+	}
+	credential, err := azblob.NewSharedKeyCredential(config.AccountName, config.AccountKey)
+	if err != nil {
+		return nil, ErrInvalidConfiguration{cfg: cfg, err: fmt.Errorf("the account key must be base64 encoded: %v", err)}
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://" + config.AccountName + ".blob.core.windows.net"
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil {
+		return nil, ErrInvalidConfiguration{cfg: cfg, err: err}
+	}
+
+	log.Info("Creating Azure Blob storage at %s:%s with base path %s", endpoint, config.Container, config.BasePath)
+
+	pipeline := azblob.NewPipeline(credential, azblob.PipelineOptions{
+		// failed requests are returned as errors and logged by the callers
+		RequestLog: azblob.RequestLogOptions{SyslogDisabled: true},
+	})
+	container := azblob.NewServiceURL(*endpoint, pipeline).NewContainerURL(config.Container)
+
+	if _, err := container.Create(ctx, azblob.Metadata{}, azblob.PublicAccessNone); err != nil {
+		// Check to see if the container already exists or we are just not allowed to create it
+		if _, errExists := container.GetProperties(ctx, azblob.LeaseAccessConditions{}); errExists != nil {
+			return nil, convertAzureBlobErr(err)
+		}
+	}
+
+	a := &AzureBlobStorage{
+		ctx:           ctx,
+		credential:    credential,
+		container:     container,
+		containerName: config.Container,
+		basePath:      config.BasePath,
+	}
+	if config.EncryptionScope != "" {
+		a.cpkOptions.EncryptionScope = &config.EncryptionScope
+	}
+	return a, nil
+}
+
+func (a *AzureBlobStorage) buildAzureBlobPath(p string) string {
+	return strings.TrimPrefix(path.Join(a.basePath, p), "/")
+}
+
+type azureBlobFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (a azureBlobFileInfo) Name() string {
+	return path.Base(a.name)
+}
+
+func (a azureBlobFileInfo) Size() int64 {
+	return a.size
+}
+
+func (a azureBlobFileInfo) ModTime() time.Time {
+	return a.modTime
+}
+
+func (a azureBlobFileInfo) IsDir() bool {
+	return false
+}
+
+func (a azureBlobFileInfo) Mode() os.FileMode {
+	return os.ModePerm
+}
+
+func (a azureBlobFileInfo) Sys() interface{} {
+	return nil
+}
+
+// azureBlobObject reads a blob with ranged downloads, so it can be seeked without reading the skipped data
+type azureBlobObject struct {
+	ctx    context.Context
+	blob   azblob.BlobURL
+	info   azureBlobFileInfo
+	offset int64
+	body   io.ReadCloser
+}
+
+func (o *azureBlobObject) Read(p []byte) (int, error) {
+	if o.offset >= o.info.size {
+		return 0, io.EOF
+	}
+	if o.body == nil {
+		resp, err := o.blob.Download(o.ctx, o.offset, azblob.CountToEnd, azblob.BlobAccessConditions{}, false, azblob.ClientProvidedKeyOptions{})
+		if err != nil {
+			return 0, convertAzureBlobErr(err)
+		}
+		// an interrupted download is resumed from where it stopped
+		o.body = resp.Body(azblob.RetryReaderOptions{MaxRetryRequests: 3})
+	}
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+	return n, err
+}
+
+func (o *azureBlobObject) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += o.offset
+	case io.SeekEnd:
+		offset += o.info.size
+	default:
+		return 0, errors.New("Seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("Seek: invalid offset")
+	}
+	if offset != o.offset && o.body != nil {
+		_ = o.body.Close()
+		o.body = nil
+	}
+	o.offset = offset
+	return offset, nil
+}
+
+func (o *azureBlobObject) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	err := o.body.Close()
+	o.body = nil
+	return err
+}
+
+func (o *azureBlobObject) Stat() (os.FileInfo, error) {
+	return o.info, nil
+}
+
+// Open opens a file
+func (a *AzureBlobStorage) Open(path string) (Object, error) {
+	info, err := a.stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &azureBlobObject{ctx: a.ctx, blob: a.container.NewBlobURL(a.buildAzureBlobPath(path)), info: info}, nil
+}
+
+// azureBlobCountingReader counts the bytes read from a reader
+type azureBlobCountingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *azureBlobCountingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Save saves a file to the container. The file is uploaded in blocks which are committed at the end.
+func (a *AzureBlobStorage) Save(path string, r io.Reader) (int64, error) {
+	counter := &azureBlobCountingReader{r: r}
+	_, err := azblob.UploadStreamToBlockBlob(a.ctx, counter, a.container.NewBlockBlobURL(a.buildAzureBlobPath(path)), azblob.UploadStreamToBlockBlobOptions{
+		BufferSize:               azureBlobBlockSize,
+		MaxBuffers:               1,
+		BlobHTTPHeaders:          azblob.BlobHTTPHeaders{ContentType: "application/octet-stream"},
+		ClientProvidedKeyOptions: a.cpkOptions,
+	})
+	if err != nil {
+		return 0, convertAzureBlobErr(err)
+	}
+	return counter.n, nil
+}
+
+func (a *AzureBlobStorage) stat(path string) (azureBlobFileInfo, error) {
+	props, err := a.container.NewBlobURL(a.buildAzureBlobPath(path)).GetProperties(a.ctx, azblob.BlobAccessConditions{}, azblob.ClientProvidedKeyOptions{})
+	if err != nil {
+		return azureBlobFileInfo{}, convertAzureBlobErr(err)
+	}
+	return azureBlobFileInfo{
+		name:    path,
+		size:    props.ContentLength(),
+		modTime: props.LastModified(),
+	}, nil
+}
+
+// Stat returns the stat information of the object
+func (a *AzureBlobStorage) Stat(path string) (os.FileInfo, error) {
+	info, err := a.stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Delete deletes a file, deleting a file which does not exist is no error
+func (a *AzureBlobStorage) Delete(path string) error {
+	_, err := a.container.NewBlobURL(a.buildAzureBlobPath(path)).Delete(a.ctx, azblob.DeleteSnapshotsOptionInclude, azblob.BlobAccessConditions{})
+	err = convertAzureBlobErr(err)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// URL gets the redirect URL to a file. The shared access signature of the link is valid for 5 minutes.
+func (a *AzureBlobStorage) URL(path, name string) (*url.URL, error) {
+	if _, err := a.stat(path); err != nil {
+		return nil, err
+	}
+
+	blob := a.buildAzureBlobPath(path)
+	sas, err := azblob.BlobSASSignatureValues{
+		ExpiryTime:         time.Now().UTC().Add(5 * time.Minute),
+		Permissions:        azblob.BlobSASPermissions{Read: true}.String(),
+		ContainerName:      a.containerName,
+		BlobName:           blob,
+		ContentDisposition: "attachment; filename=\"" + quoteEscaper.Replace(name) + "\"",
+	}.NewSASQueryParameters(a.credential)
+	if err != nil {
+		return nil, err
+	}
+
+	u := a.container.NewBlobURL(blob).URL()
+	u.RawQuery = sas.Encode()
+	return &u, nil
+}
+
+// IterateObjects iterates across the objects in the azure blob storage
+func (a *AzureBlobStorage) IterateObjects(fn func(path string, obj Object) error) error {
+	prefix := a.buildAzureBlobPath("")
+	if prefix != "" {
+		prefix += "/"
+	}
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		list, err := a.container.ListBlobsFlatSegment(a.ctx, marker, azblob.ListBlobsSegmentOptions{Prefix: prefix})
+		if err != nil {
+			return convertAzureBlobErr(err)
+		}
+		marker = list.NextMarker
+
+		for _, blob := range list.Segment.BlobItems {
+			select {
+			case <-a.ctx.Done():
+				return a.ctx.Err()
+			default:
+			}
+			relPath := strings.TrimPrefix(blob.Name, prefix)
+			info := azureBlobFileInfo{name: relPath, modTime: blob.Properties.LastModified}
+			if blob.Properties.ContentLength != nil {
+				info.size = *blob.Properties.ContentLength
+			}
+			obj := &azureBlobObject{ctx: a.ctx, blob: a.container.NewBlobURL(blob.Name), info: info}
+			err := fn(relPath, obj)
+			obj.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func init() {
+	RegisterStorageType(AzureBlobStorageType, NewAzureBlobStorage)
+}
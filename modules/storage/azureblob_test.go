@@ -0,0 +1,188 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAzureBlobService implements the parts of the Blob service REST API used by AzureBlobStorage
+type fakeAzureBlobService struct {
+	mu     sync.Mutex
+	blobs  map[string][]byte
+	blocks map[string][]byte
+	scopes map[string]string
+}
+
+func (f *fakeAzureBlobService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey devaccount:") || r.Header.Get("x-ms-version") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/gitea")
+	name = strings.TrimPrefix(name, "/")
+	query := r.URL.Query()
+
+	switch {
+	case query.Get("restype") == "container" && query.Get("comp") == "list":
+		var names []string
+		for blob := range f.blobs {
+			if strings.HasPrefix(blob, query.Get("prefix")) {
+				names = append(names, blob)
+			}
+		}
+		sort.Strings(names)
+		fmt.Fprint(w, "<EnumerationResults><Blobs>")
+		for _, blob := range names {
+			fmt.Fprintf(w, "<Blob><Name>%s</Name><Properties><Content-Length>%d</Content-Length></Properties></Blob>", blob, len(f.blobs[blob]))
+		}
+		fmt.Fprint(w, "</Blobs><NextMarker /></EnumerationResults>")
+	case query.Get("restype") == "container":
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && query.Get("comp") == "block":
+		data, _ := ioutil.ReadAll(r.Body)
+		f.blocks[name+"/"+query.Get("blockid")] = data
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && query.Get("comp") == "blocklist":
+		var list struct {
+			Latest []string `xml:"Latest"`
+		}
+		if err := xml.NewDecoder(r.Body).Decode(&list); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var data []byte
+		for _, id := range list.Latest {
+			data = append(data, f.blocks[name+"/"+id]...)
+		}
+		f.blobs[name] = data
+		f.scopes[name] = r.Header.Get("x-ms-encryption-scope")
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut:
+		data, _ := ioutil.ReadAll(r.Body)
+		f.blobs[name] = data
+		f.scopes[name] = r.Header.Get("x-ms-encryption-scope")
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		data, ok := f.blobs[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		status := http.StatusOK
+		var offset int
+		if _, err := fmt.Sscanf(r.Header.Get("x-ms-range"), "bytes=%d-", &offset); err == nil {
+			data = data[offset:]
+			status = http.StatusPartialContent
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		w.WriteHeader(status)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	case r.Method == http.MethodDelete:
+		if _, ok := f.blobs[name]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "<Error><Code>BlobNotFound</Code><Message>The specified blob does not exist.</Message></Error>")
+			return
+		}
+		delete(f.blobs, name)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestAzureBlobStorage(t *testing.T) {
+	service := &fakeAzureBlobService{blobs: map[string][]byte{}, blocks: map[string][]byte{}, scopes: map[string]string{}}
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	// the smallest block size of the client
+	oldBlockSize := azureBlobBlockSize
+	azureBlobBlockSize = 1024 * 1024
+	defer func() {
+		azureBlobBlockSize = oldBlockSize
+	}()
+
+	s, err := NewStorage(string(AzureBlobStorageType), AzureBlobStorageConfig{
+		Endpoint:        server.URL,
+		AccountName:     "devaccount",
+		AccountKey:      base64.StdEncoding.EncodeToString([]byte("secret")),
+		Container:       "gitea",
+		BasePath:        "attachments/",
+		EncryptionScope: "scope",
+	})
+	assert.NoError(t, err)
+
+	// A single block and several blocks
+	large := strings.Repeat("x", azureBlobBlockSize) + "0123456789"
+	for name, content := range map[string]string{"a/small": "abc", "b/large": large} {
+		n, err := s.Save(name, strings.NewReader(content))
+		assert.NoError(t, err)
+		assert.EqualValues(t, len(content), n)
+		assert.Equal(t, content, string(service.blobs["attachments/"+name]))
+		assert.Equal(t, "scope", service.scopes["attachments/"+name])
+	}
+
+	info, err := s.Stat("b/large")
+	assert.NoError(t, err)
+	assert.EqualValues(t, len(large), info.Size())
+	assert.Equal(t, "large", info.Name())
+
+	obj, err := s.Open("b/large")
+	assert.NoError(t, err)
+	buf := make([]byte, 3)
+	_, err = io.ReadFull(obj, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "xxx", string(buf))
+	_, err = obj.Seek(-2, io.SeekEnd)
+	assert.NoError(t, err)
+	rest, err := ioutil.ReadAll(obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "89", string(rest))
+	assert.NoError(t, obj.Close())
+
+	_, err = s.Open("missing")
+	assert.True(t, os.IsNotExist(err))
+
+	u, err := s.URL("a/small", "small.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "/gitea/attachments/a/small", u.Path)
+	assert.Equal(t, "r", u.Query().Get("sp"))
+	assert.Equal(t, `attachment; filename="small.txt"`, u.Query().Get("rscd"))
+	assert.NotEmpty(t, u.Query().Get("sig"))
+
+	var paths []string
+	assert.NoError(t, s.IterateObjects(func(path string, obj Object) error {
+		content, err := ioutil.ReadAll(obj)
+		assert.NoError(t, err)
+		paths = append(paths, path+"="+string(content))
+		return nil
+	}))
+	assert.Equal(t, []string{"a/small=abc", "b/large=" + large}, paths)
+
+	assert.NoError(t, s.Delete("a/small"))
+	assert.NoError(t, s.Delete("a/small"))
+	_, err = s.Stat("a/small")
+	assert.True(t, os.IsNotExist(err))
+}
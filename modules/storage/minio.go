@@ -6,6 +6,9 @@ package storage
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"io"
 	"net/url"
 	"os"
@@ -17,6 +20,8 @@ import (
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
 )
 
 var (
@@ -50,6 +55,15 @@ type MinioStorageConfig struct {
 	Location        string `ini:"MINIO_LOCATION"`
 	BasePath        string `ini:"MINIO_BASE_PATH"`
 	UseSSL          bool   `ini:"MINIO_USE_SSL"`
+	// SSE is the server-side encryption of new objects: "s3", "kms" or "c", none if empty
+	SSE         string `ini:"MINIO_SSE"`
+	SSEKMSKeyID string `ini:"MINIO_SSE_KMS_KEY_ID"`
+	// SSECKey is the base64 encoded 256 bit key of SSE-C
+	SSECKey string `ini:"MINIO_SSE_C_KEY"`
+	// ExpirationDays and TransitionDays add a lifecycle rule for the objects below the base path if not zero
+	ExpirationDays         int    `ini:"MINIO_EXPIRATION_DAYS"`
+	TransitionDays         int    `ini:"MINIO_TRANSITION_DAYS"`
+	TransitionStorageClass string `ini:"MINIO_TRANSITION_STORAGE_CLASS"`
 }
 
 // MinioStorage returns a minio bucket storage
@@ -58,6 +72,7 @@ type MinioStorage struct {
 	client   *minio.Client
 	bucket   string
 	basePath string
+	sse      encrypt.ServerSide
 }
 
 func convertMinioErr(err error) error {
@@ -80,6 +95,71 @@ func convertMinioErr(err error) error {
 	return err
 }
 
+// newMinioSSE returns the server-side encryption configured for a minio storage, nil if there is none
+func newMinioSSE(config MinioStorageConfig) (encrypt.ServerSide, error) {
+	switch strings.ToLower(config.SSE) {
+	case "":
+		return nil, nil
+	case "s3":
+		return encrypt.NewSSE(), nil
+	case "kms":
+		if config.SSEKMSKeyID == "" {
+			return nil, errors.New("MINIO_SSE_KMS_KEY_ID must be set for SSE-KMS")
+		}
+		return encrypt.NewSSEKMS(config.SSEKMSKeyID, nil)
+	case "c":
+		key, err := base64.StdEncoding.DecodeString(config.SSECKey)
+		if err != nil {
+			return nil, fmt.Errorf("MINIO_SSE_C_KEY must be base64 encoded: %v", err)
+		}
+		return encrypt.NewSSEC(key)
+	}
+	return nil, fmt.Errorf("unknown server-side encryption %q", config.SSE)
+}
+
+// minioLifecycleRuleID returns the ID of the lifecycle rule Gitea manages for the objects below a base path
+func minioLifecycleRuleID(basePath string) string {
+	return "gitea-" + strings.Trim(basePath, "/")
+}
+
+// setMinioLifecycle adds or replaces the lifecycle rule of the base path in the lifecycle configuration
+// of the bucket. The rules of other base paths and those not managed by Gitea are kept.
+func setMinioLifecycle(ctx context.Context, client *minio.Client, config MinioStorageConfig) error {
+	if config.ExpirationDays <= 0 && config.TransitionDays <= 0 {
+		return nil
+	}
+
+	lifecycleConfig, err := client.GetBucketLifecycle(ctx, config.Bucket)
+	if err != nil {
+		if errResp, ok := err.(minio.ErrorResponse); !ok || errResp.Code != "NoSuchLifecycleConfiguration" {
+			return err
+		}
+		lifecycleConfig = lifecycle.NewConfiguration()
+	}
+
+	rule := lifecycle.Rule{
+		ID:         minioLifecycleRuleID(config.BasePath),
+		Status:     "Enabled",
+		RuleFilter: lifecycle.Filter{Prefix: strings.TrimPrefix(config.BasePath, "/")},
+	}
+	if config.ExpirationDays > 0 {
+		rule.Expiration.Days = lifecycle.ExpirationDays(config.ExpirationDays)
+	}
+	if config.TransitionDays > 0 {
+		rule.Transition.Days = lifecycle.ExpirationDays(config.TransitionDays)
+		rule.Transition.StorageClass = config.TransitionStorageClass
+	}
+
+	rules := make([]lifecycle.Rule, 0, len(lifecycleConfig.Rules)+1)
+	for _, existing := range lifecycleConfig.Rules {
+		if existing.ID != rule.ID {
+			rules = append(rules, existing)
+		}
+	}
+	lifecycleConfig.Rules = append(rules, rule)
+	return client.SetBucketLifecycle(ctx, config.Bucket, lifecycleConfig)
+}
+
 // NewMinioStorage returns a minio storage
 func NewMinioStorage(ctx context.Context, cfg interface{}) (ObjectStorage, error) {
 	configInterface, err := toConfig(MinioStorageConfig{}, cfg)
@@ -88,6 +168,11 @@ func NewMinioStorage(ctx context.Context, cfg interface{}) (ObjectStorage, error
 	}
 	config := configInterface.(MinioStorageConfig)
 
+	sse, err := newMinioSSE(config)
+	if err != nil {
+		return nil, ErrInvalidConfiguration{cfg: cfg, err: err}
+	}
+
 	log.Info("Creating Minio storage at %s:%s with base path %s", config.Endpoint, config.Bucket, config.BasePath)
 
 	minioClient, err := minio.New(config.Endpoint, &minio.Options{
@@ -108,11 +193,16 @@ func NewMinioStorage(ctx context.Context, cfg interface{}) (ObjectStorage, error
 		}
 	}
 
+	if err := setMinioLifecycle(ctx, minioClient, config); err != nil {
+		return nil, fmt.Errorf("unable to set the lifecycle of bucket %s: %w", config.Bucket, convertMinioErr(err))
+	}
+
 	return &MinioStorage{
 		ctx:      ctx,
 		client:   minioClient,
 		bucket:   config.Bucket,
 		basePath: config.BasePath,
+		sse:      sse,
 	}, nil
 }
 
@@ -120,9 +210,18 @@ func (m *MinioStorage) buildMinioPath(p string) string {
 	return strings.TrimPrefix(path.Join(m.basePath, p), "/")
 }
 
+// getObjectOptions returns the options to read an object with, which must include the key of SSE-C
+func (m *MinioStorage) getObjectOptions() minio.GetObjectOptions {
+	opts := minio.GetObjectOptions{}
+	if m.sse != nil && m.sse.Type() == encrypt.SSEC {
+		opts.ServerSideEncryption = m.sse
+	}
+	return opts
+}
+
 // Open open a file
 func (m *MinioStorage) Open(path string) (Object, error) {
-	var opts = minio.GetObjectOptions{}
+	var opts = m.getObjectOptions()
 	object, err := m.client.GetObject(m.ctx, m.bucket, m.buildMinioPath(path), opts)
 	if err != nil {
 		return nil, convertMinioErr(err)
@@ -138,7 +237,7 @@ func (m *MinioStorage) Save(path string, r io.Reader) (int64, error) {
 		m.buildMinioPath(path),
 		r,
 		-1,
-		minio.PutObjectOptions{ContentType: "application/octet-stream"},
+		minio.PutObjectOptions{ContentType: "application/octet-stream", ServerSideEncryption: m.sse},
 	)
 	if err != nil {
 		return 0, convertMinioErr(err)
@@ -180,7 +279,7 @@ func (m *MinioStorage) Stat(path string) (os.FileInfo, error) {
 		m.ctx,
 		m.bucket,
 		m.buildMinioPath(path),
-		minio.StatObjectOptions{},
+		m.getObjectOptions(),
 	)
 	if err != nil {
 		return nil, convertMinioErr(err)
@@ -196,7 +295,11 @@ func (m *MinioStorage) Delete(path string) error {
 }
 
 // URL gets the redirect URL to a file. The presigned link is valid for 5 minutes.
+// Objects encrypted with SSE-C can not be downloaded without the key, so they have no URL.
 func (m *MinioStorage) URL(path, name string) (*url.URL, error) {
+	if m.sse != nil && m.sse.Type() == encrypt.SSEC {
+		return nil, ErrURLNotSupported
+	}
 	reqParams := make(url.Values)
 	// TODO it may be good to embed images with 'inline' like ServeData does, but we don't want to have to read the file, do we?
 	reqParams.Set("response-content-disposition", "attachment; filename=\""+quoteEscaper.Replace(name)+"\"")
@@ -206,7 +309,7 @@ func (m *MinioStorage) URL(path, name string) (*url.URL, error) {
 
 // IterateObjects iterates across the objects in the miniostorage
 func (m *MinioStorage) IterateObjects(fn func(path string, obj Object) error) error {
-	var opts = minio.GetObjectOptions{}
+	var opts = m.getObjectOptions()
 	lobjectCtx, cancel := context.WithCancel(m.ctx)
 	defer cancel()
 	for mObjInfo := range m.client.ListObjects(lobjectCtx, m.bucket, minio.ListObjectsOptions{
@@ -219,7 +322,7 @@ func (m *MinioStorage) IterateObjects(fn func(path string, obj Object) error) er
 		}
 		if err := func(object *minio.Object, fn func(path string, obj Object) error) error {
 			defer object.Close()
-			return fn(strings.TrimPrefix(m.basePath, mObjInfo.Key), &minioObject{object})
+			return fn(strings.TrimPrefix(strings.TrimPrefix(mObjInfo.Key, m.buildMinioPath("")), "/"), &minioObject{object})
 		}(object, fn); err != nil {
 			return convertMinioErr(err)
 		}
@@ -109,6 +109,18 @@ var (
 	Avatars ObjectStorage
 	// RepoAvatars represents repository avatars storage
 	RepoAvatars ObjectStorage
+
+	// Actions represents the storage of the artifacts of actions jobs
+	Actions ObjectStorage
+
+	// Artifacts represents the storage of the build artifacts uploaded by external CI systems
+	Artifacts ObjectStorage
+
+	// Packages represents the storage of the package registry
+	Packages ObjectStorage
+
+	// RepoArchives represents the storage of the generated repository archives
+	RepoArchives ObjectStorage
 )
 
 // Init init the stoarge
@@ -125,6 +137,22 @@ func Init() error {
 		return err
 	}
 
+	if err := initActions(); err != nil {
+		return err
+	}
+
+	if err := initArtifacts(); err != nil {
+		return err
+	}
+
+	if err := initPackages(); err != nil {
+		return err
+	}
+
+	if err := initRepoArchives(); err != nil {
+		return err
+	}
+
 	return initLFS()
 }
 
@@ -164,3 +192,27 @@ func initRepoAvatars() (err error) {
 	RepoAvatars, err = NewStorage(setting.RepoAvatar.Storage.Type, &setting.RepoAvatar.Storage)
 	return
 }
+
+func initActions() (err error) {
+	log.Info("Initialising Actions artifact storage with type: %s", setting.Actions.Storage.Type)
+	Actions, err = NewStorage(setting.Actions.Storage.Type, &setting.Actions.Storage)
+	return
+}
+
+func initArtifacts() (err error) {
+	log.Info("Initialising Artifact storage with type: %s", setting.Artifacts.Storage.Type)
+	Artifacts, err = NewStorage(setting.Artifacts.Storage.Type, &setting.Artifacts.Storage)
+	return
+}
+
+func initPackages() (err error) {
+	log.Info("Initialising Packages storage with type: %s", setting.Packages.Storage.Type)
+	Packages, err = NewStorage(setting.Packages.Storage.Type, &setting.Packages.Storage)
+	return
+}
+
+func initRepoArchives() (err error) {
+	log.Info("Initialising Repository Archive storage with type: %s", setting.RepoArchive.Storage.Type)
+	RepoArchives, err = NewStorage(setting.RepoArchive.Storage.Type, &setting.RepoArchive.Storage)
+	return
+}
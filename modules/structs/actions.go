@@ -0,0 +1,51 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// RegisterActionRunnerOption options to register an actions runner
+type RegisterActionRunnerOption struct {
+	// the registration token shown in the site administration
+	Token  string   `json:"token" binding:"Required"`
+	Name   string   `json:"name" binding:"Required;MaxSize(255)"`
+	Labels []string `json:"labels"`
+}
+
+// ActionRunner represents a registered actions runner
+type ActionRunner struct {
+	ID     int64    `json:"id"`
+	UUID   string   `json:"uuid"`
+	Name   string   `json:"name"`
+	Labels []string `json:"labels"`
+	// the token the runner authenticates with, only returned on registration
+	Token string `json:"token,omitempty"`
+}
+
+// ActionTask represents a job assigned to a runner
+type ActionTask struct {
+	JobID int64  `json:"job_id"`
+	RunID int64  `json:"run_id"`
+	Name  string `json:"name"`
+	// the YAML definition of the job
+	Workflow   string `json:"workflow"`
+	Repository string `json:"repository"`
+	CloneURL   string `json:"clone_url"`
+	Ref        string `json:"ref"`
+	SHA        string `json:"sha"`
+	Event      string `json:"event"`
+	// the token to clone the repository over HTTP with, valid until the job finishes
+	Token string `json:"token"`
+}
+
+// UpdateActionTaskOption options to report the status of a job
+type UpdateActionTaskOption struct {
+	// running, success or failure
+	Status string `json:"status" binding:"Required"`
+}
+
+// ActionTaskState represents the current status of a job
+type ActionTaskState struct {
+	// a runner should stop a job once its status is cancelled
+	Status string `json:"status"`
+}
@@ -0,0 +1,17 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// ConfigValue represents the value of a setting used by the instance
+type ConfigValue struct {
+	// section of the configuration file, DEFAULT for the keys before the first section
+	Section string `json:"section"`
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	// where the value comes from, one of default, file or environment
+	Source string `json:"source"`
+	// true if the value has been masked because it contains a secret
+	Masked bool `json:"masked"`
+}
@@ -0,0 +1,19 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// LogModuleLevel represents the log level of a module which overrides the level of the loggers
+type LogModuleLevel struct {
+	// path of the module relative to the source root, e.g. modules/git
+	Module string `json:"module"`
+	// level of the events logged from within the module, empty to remove the override
+	Level string `json:"level"`
+}
+
+// EditLogModuleLevelsOption options for setting the log levels of modules
+type EditLogModuleLevelsOption struct {
+	// required: true
+	Modules []LogModuleLevel `json:"modules" binding:"Required"`
+}
@@ -0,0 +1,38 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// MigrateLoginSourceUsersOption options for migrating the users of an authentication source to another one
+type MigrateLoginSourceUsersOption struct {
+	// ID of the authentication source the users are migrated from, 0 for the local users
+	FromSourceID int64 `json:"from_source_id"`
+	// ID of the authentication source the users are migrated to, 0 for the local users
+	ToSourceID int64 `json:"to_source_id"`
+	// identity used as login name in the target source, which must be email for an OAuth2 source
+	// enum: username,email
+	MapBy string `json:"map_by" binding:"In(,username,email)"`
+	// only migrate these users if it is not empty
+	Usernames []string `json:"usernames"`
+	// only return the users which would be migrated and the conflicts
+	DryRun bool `json:"dry_run"`
+}
+
+// LoginSourceMigrationUser represents a user migrated to another authentication source
+type LoginSourceMigrationUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// login name of the user in the target source, empty for the local users
+	LoginName string `json:"login_name"`
+	// reason the user cannot be migrated for, login_name_taken or duplicate, empty if it can be
+	Conflict string `json:"conflict"`
+}
+
+// LoginSourceMigration represents a migration of users between authentication sources
+type LoginSourceMigration struct {
+	DryRun bool `json:"dry_run"`
+	// number of users migrated, or which would be migrated by a dry run
+	Migrated int                         `json:"migrated"`
+	Users    []*LoginSourceMigrationUser `json:"users"`
+}
@@ -0,0 +1,24 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// MaintenanceMode represents the maintenance mode of the instance
+type MaintenanceMode struct {
+	Enabled bool `json:"enabled"`
+	// message shown to the users during the maintenance
+	Message string `json:"message"`
+	// swagger:strfmt date-time
+	Since *time.Time `json:"since,omitempty"`
+}
+
+// EnableMaintenanceModeOption options for enabling the maintenance mode
+type EnableMaintenanceModeOption struct {
+	// message shown to the users during the maintenance
+	Message string `json:"message"`
+}
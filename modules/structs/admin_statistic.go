@@ -0,0 +1,91 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// StorageUsage represents the storage used by a single repository or user
+type StorageUsage struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// size in bytes
+	Size int64 `json:"size"`
+}
+
+// StorageStatistic represents a snapshot of the disk usage and growth of the instance
+type StorageStatistic struct {
+	RepositorySize int64 `json:"repository_size"`
+	LFSSize        int64 `json:"lfs_size"`
+	AttachmentSize int64 `json:"attachment_size"`
+	AvatarSize     int64 `json:"avatar_size"`
+	RepoAvatarSize int64 `json:"repo_avatar_size"`
+	TotalSize      int64 `json:"total_size"`
+
+	NumUsers int64 `json:"num_users"`
+	NumOrgs  int64 `json:"num_orgs"`
+	NumRepos int64 `json:"num_repos"`
+
+	LargestRepositories        []*StorageUsage `json:"largest_repositories"`
+	LargestLFSOwners           []*StorageUsage `json:"largest_lfs_owners"`
+	LargestAttachmentUploaders []*StorageUsage `json:"largest_attachment_uploaders"`
+
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// InstanceStats represents the anonymized statistics of the instance, which contain no names, addresses or contents
+type InstanceStats struct {
+	// anonymous identifier of the instance, which is stable as long as its secret key is
+	InstanceID string `json:"instance_id"`
+	// name of the instance set by its administrators, empty if the instance only reports its anonymous identifier
+	InstanceName string            `json:"instance_name"`
+	Version      string            `json:"version"`
+	GoVersion    string            `json:"go_version"`
+	OS           string            `json:"os"`
+	Arch         string            `json:"arch"`
+	Database     string            `json:"database"`
+	Counts       *InstanceCounts   `json:"counts"`
+	Features     *InstanceFeatures `json:"features"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// InstanceCounts represents the numbers of objects of the instance
+type InstanceCounts struct {
+	Users        int64 `json:"users"`
+	Orgs         int64 `json:"orgs"`
+	Teams        int64 `json:"teams"`
+	Repos        int64 `json:"repos"`
+	Mirrors      int64 `json:"mirrors"`
+	Issues       int64 `json:"issues"`
+	Comments     int64 `json:"comments"`
+	Milestones   int64 `json:"milestones"`
+	Labels       int64 `json:"labels"`
+	Releases     int64 `json:"releases"`
+	Attachments  int64 `json:"attachments"`
+	Webhooks     int64 `json:"webhooks"`
+	PublicKeys   int64 `json:"public_keys"`
+	LoginSources int64 `json:"login_sources"`
+}
+
+// InstanceFeatures represents which optional features are enabled on the instance
+type InstanceFeatures struct {
+	Registration bool `json:"registration"`
+	OpenIDSignIn bool `json:"openid_signin"`
+	Mailer       bool `json:"mailer"`
+	LFS          bool `json:"lfs"`
+	Mirrors      bool `json:"mirrors"`
+	Migrations   bool `json:"migrations"`
+	RepoIndexer  bool `json:"repo_indexer"`
+	TimeTracking bool `json:"time_tracking"`
+	Packages     bool `json:"packages"`
+	Actions      bool `json:"actions"`
+	Artifacts    bool `json:"artifacts"`
+	SCIM         bool `json:"scim"`
+	VirusScan    bool `json:"virus_scan"`
+	Metrics      bool `json:"metrics"`
+	Tracing      bool `json:"tracing"`
+	Swagger      bool `json:"swagger"`
+}
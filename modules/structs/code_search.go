@@ -0,0 +1,56 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// CodeSearchResult represents the lines of a file matching a code search
+type CodeSearchResult struct {
+	RepoID       int64  `json:"repo_id"`
+	RepoFullName string `json:"repo_full_name"`
+	Filename     string `json:"filename"`
+	CommitID     string `json:"commit_id"`
+	// link to the first line of content in the file at the indexed commit
+	HTMLURL  string `json:"html_url"`
+	Language string `json:"language"`
+	// numbers of the lines of content
+	LineNumbers []int  `json:"line_numbers"`
+	Content     string `json:"content"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated"`
+}
+
+// CodeSearchLanguage represents the number of results of a code search in a language
+type CodeSearchLanguage struct {
+	Language string `json:"language"`
+	Color    string `json:"color"`
+	Count    int    `json:"count"`
+}
+
+// CodeSearchDirectory represents the number of results of a code search in a directory
+type CodeSearchDirectory struct {
+	Directory string `json:"directory"`
+	Count     int    `json:"count"`
+}
+
+// CodeSearchRepository represents the number of results of a code search in a repository
+type CodeSearchRepository struct {
+	RepoID       int64  `json:"repo_id"`
+	RepoFullName string `json:"repo_full_name"`
+	Count        int    `json:"count"`
+}
+
+// CodeSearchResults represents a page of the results of a code search
+type CodeSearchResults struct {
+	TotalCount int                 `json:"total_count"`
+	Results    []*CodeSearchResult `json:"results"`
+	// the numbers of results by language, directory and repository, regardless of the language and the path the
+	// search is restricted to
+	Languages    []*CodeSearchLanguage   `json:"languages"`
+	Directories  []*CodeSearchDirectory  `json:"directories"`
+	Repositories []*CodeSearchRepository `json:"repositories"`
+}
@@ -50,6 +50,11 @@ type Issue struct {
 	Ref              string     `json:"ref"`
 	Labels           []*Label   `json:"labels"`
 	Milestone        *Milestone `json:"milestone"`
+	Type             *IssueType `json:"type"`
+	// number of the parent issue, 0 if the issue is not a sub-issue
+	ParentIndex int64 `json:"parent_number"`
+	// estimated effort of the issue, 0 if it has not been estimated
+	Weight int64 `json:"weight"`
 	// deprecated
 	Assignee  *User   `json:"assignee"`
 	Assignees []*User `json:"assignees"`
@@ -71,6 +76,9 @@ type Issue struct {
 
 	PullRequest *PullRequestMeta `json:"pull_request"`
 	Repo        *RepositoryMeta  `json:"repository"`
+	// HTML snippets of the title, the body and the comments matching the keyword of an issue search, by field, the
+	// matches being marked with <mark>
+	Highlights map[string][]string `json:"highlights,omitempty"`
 }
 
 // CreateIssueOption options to create one issue
@@ -88,7 +96,11 @@ type CreateIssueOption struct {
 	Milestone int64 `json:"milestone"`
 	// list of label ids
 	Labels []int64 `json:"labels"`
-	Closed bool    `json:"closed"`
+	// issue type id
+	Type int64 `json:"type"`
+	// estimated effort of the issue
+	Weight int64 `json:"weight"`
+	Closed bool  `json:"closed"`
 }
 
 // EditIssueOption options for editing an issue
@@ -100,7 +112,11 @@ type EditIssueOption struct {
 	Assignee  *string  `json:"assignee"`
 	Assignees []string `json:"assignees"`
 	Milestone *int64   `json:"milestone"`
-	State     *string  `json:"state"`
+	// issue type id, 0 removing the type
+	Type *int64 `json:"type"`
+	// estimated effort of the issue, 0 removing the weight
+	Weight *int64  `json:"weight"`
+	State  *string `json:"state"`
 	// swagger:strfmt date-time
 	Deadline       *time.Time `json:"due_date"`
 	RemoveDeadline *bool      `json:"unset_due_date"`
@@ -120,15 +136,88 @@ type IssueDeadline struct {
 	Deadline *time.Time `json:"due_date"`
 }
 
+// IssueConfigContactLink is a link of the issue template chooser to a place outside of the issues, e.g. a forum
+type IssueConfigContactLink struct {
+	Name  string `json:"name" yaml:"name"`
+	URL   string `json:"url" yaml:"url"`
+	About string `json:"about" yaml:"about"`
+}
+
+// IssueConfig is the configuration of the issue template chooser of a repository
+// swagger:model
+type IssueConfig struct {
+	// whether the issues can be created without a template
+	BlankIssuesEnabled bool                     `json:"blank_issues_enabled" yaml:"blank_issues_enabled"`
+	ContactLinks       []IssueConfigContactLink `json:"contact_links" yaml:"contact_links"`
+}
+
 // IssueTemplate represents an issue template for a repository
 // swagger:model
 type IssueTemplate struct {
-	Name     string   `json:"name" yaml:"name"`
-	Title    string   `json:"title" yaml:"title"`
-	About    string   `json:"about" yaml:"about"`
-	Labels   []string `json:"labels" yaml:"labels"`
-	Content  string   `json:"content" yaml:"-"`
-	FileName string   `json:"file_name" yaml:"-"`
+	Name    string   `json:"name" yaml:"name"`
+	Title   string   `json:"title" yaml:"title"`
+	About   string   `json:"about" yaml:"about"`
+	Labels  []string `json:"labels" yaml:"labels"`
+	Content string   `json:"content" yaml:"-"`
+	// the fields of the issue forms, which are defined in YAML files
+	Fields   []*IssueFormField `json:"body,omitempty" yaml:"body"`
+	FileName string            `json:"file_name" yaml:"-"`
+}
+
+// IssueFormFieldType is the type of a field of an issue form
+type IssueFormFieldType string
+
+// the types of the fields of the issue forms
+const (
+	IssueFormFieldTypeMarkdown   IssueFormFieldType = "markdown"
+	IssueFormFieldTypeTextarea   IssueFormFieldType = "textarea"
+	IssueFormFieldTypeInput      IssueFormFieldType = "input"
+	IssueFormFieldTypeDropdown   IssueFormFieldType = "dropdown"
+	IssueFormFieldTypeCheckboxes IssueFormFieldType = "checkboxes"
+)
+
+// IssueFormField is a field of an issue form
+type IssueFormField struct {
+	// enum: markdown,textarea,input,dropdown,checkboxes
+	Type        IssueFormFieldType        `json:"type" yaml:"type"`
+	ID          string                    `json:"id,omitempty" yaml:"id"`
+	Attributes  IssueFormFieldAttributes  `json:"attributes" yaml:"attributes"`
+	Validations IssueFormFieldValidations `json:"validations" yaml:"validations"`
+}
+
+// IssueFormFieldAttributes are the attributes of a field of an issue form
+type IssueFormFieldAttributes struct {
+	Label       string `json:"label,omitempty" yaml:"label"`
+	Description string `json:"description,omitempty" yaml:"description"`
+	Placeholder string `json:"placeholder,omitempty" yaml:"placeholder"`
+	// the default value of the inputs and textareas, or the content of the markdown fields
+	Value string `json:"value,omitempty" yaml:"value"`
+	// the language of the code block the value of a textarea is rendered in
+	Render string `json:"render,omitempty" yaml:"render"`
+	// whether several options of a dropdown can be selected
+	Multiple bool                    `json:"multiple,omitempty" yaml:"multiple"`
+	Options  []*IssueFormFieldOption `json:"options,omitempty" yaml:"options"`
+}
+
+// IssueFormFieldOption is an option of a dropdown or of checkboxes
+type IssueFormFieldOption struct {
+	Label string `json:"label" yaml:"label"`
+	// whether a checkbox must be checked
+	Required bool `json:"required,omitempty" yaml:"required"`
+}
+
+// UnmarshalYAML unmarshals an option, which is only given by its label for the dropdowns
+func (o *IssueFormFieldOption) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&o.Label); err == nil {
+		return nil
+	}
+	type option IssueFormFieldOption
+	return unmarshal((*option)(o))
+}
+
+// IssueFormFieldValidations are the validations of a field of an issue form
+type IssueFormFieldValidations struct {
+	Required bool `json:"required,omitempty" yaml:"required"`
 }
 
 // Valid checks whether an IssueTemplate is considered valid, e.g. at least name and about
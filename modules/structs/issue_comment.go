@@ -35,3 +35,49 @@ type EditIssueCommentOption struct {
 	// required: true
 	Body string `json:"body" binding:"Required"`
 }
+
+// TimelineComment represents an event of any type in the timeline of an issue or pull request
+type TimelineComment struct {
+	ID int64 `json:"id"`
+	// Type is the kind of the event, e.g. "comment", "label" or "review"
+	Type     string `json:"type"`
+	HTMLURL  string `json:"html_url"`
+	PRURL    string `json:"pull_request_url"`
+	IssueURL string `json:"issue_url"`
+	Poster   *User  `json:"user"`
+	Body     string `json:"body"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+
+	OldProjectID int64        `json:"old_project_id"`
+	ProjectID    int64        `json:"project_id"`
+	OldMilestone *Milestone   `json:"old_milestone"`
+	Milestone    *Milestone   `json:"milestone"`
+	TrackedTime  *TrackedTime `json:"tracked_time"`
+	OldTitle     string       `json:"old_title"`
+	NewTitle     string       `json:"new_title"`
+	OldRef       string       `json:"old_ref"`
+	NewRef       string       `json:"new_ref"`
+
+	RefIssue   *Issue   `json:"ref_issue"`
+	RefComment *Comment `json:"ref_comment"`
+	// RefAction is what happens to the referenced issue, "none", "closes", "reopens" or "neutered"
+	RefAction string `json:"ref_action"`
+	// RefCommitSHA is the commit which referenced the issue
+	RefCommitSHA string `json:"ref_commit_sha"`
+
+	ReviewID int64 `json:"review_id"`
+
+	Label *Label `json:"label"`
+
+	Assignee     *User `json:"assignee"`
+	AssigneeTeam *Team `json:"assignee_team"`
+	// RemovedAssignee is whether the assignee was removed rather than added
+	RemovedAssignee bool `json:"removed_assignee"`
+
+	ResolveDoer *User `json:"resolve_doer"`
+
+	DependentIssue *Issue `json:"dependent_issue"`
+}
@@ -0,0 +1,21 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// ContentHistory is a version of the content of an issue, a pull request or a comment
+type ContentHistory struct {
+	ID      int64  `json:"id"`
+	Editor  *User  `json:"editor"`
+	Content string `json:"content"`
+	// DiffHTML is the HTML of the differences from the previous version, empty for the content as first posted
+	DiffHTML       string `json:"diff_html"`
+	IsFirstCreated bool   `json:"is_first_created"`
+	// swagger:strfmt date-time
+	Edited time.Time `json:"edited_at"`
+}
@@ -0,0 +1,22 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// SubIssueNode represents an issue with the tree of its sub-issues
+type SubIssueNode struct {
+	Issue     *Issue          `json:"issue"`
+	SubIssues []*SubIssueNode `json:"sub_issues"`
+	// number of all the issues below the issue
+	Total int `json:"total"`
+	// number of the closed issues below the issue
+	Closed int `json:"closed"`
+}
+
+// AddSubIssueOption options for adding a sub-issue to an issue
+type AddSubIssueOption struct {
+	// number of the issue of the same repository to add as a sub-issue
+	// required: true
+	SubIssueIndex int64 `json:"sub_issue_number" binding:"Required"`
+}
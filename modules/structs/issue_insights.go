@@ -0,0 +1,42 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// IssueInsights represents the usage of issue templates and labels and the response times of the issues of a repository
+type IssueInsights struct {
+	// swagger:strfmt date-time
+	From time.Time `json:"from"`
+	// swagger:strfmt date-time
+	Until time.Time `json:"until"`
+	// number of issues created within the period
+	Issues    int64                 `json:"issues"`
+	Templates []*IssueTemplateUsage `json:"templates"`
+	Labels    []*LabelUsage         `json:"labels"`
+	// number of issues created within the period which were commented on by someone other than their poster
+	IssuesResponded int64 `json:"issues_responded"`
+	// average number of seconds until the first comment of someone other than the poster
+	AvgFirstResponseSeconds int64 `json:"avg_first_response_seconds"`
+	// number of issues closed within the period
+	IssuesClosed int64 `json:"issues_closed"`
+	// average number of seconds between the creation and the closing of the closed issues
+	AvgTimeToCloseSeconds int64 `json:"avg_time_to_close_seconds"`
+}
+
+// IssueTemplateUsage represents the number of issues created from an issue template
+type IssueTemplateUsage struct {
+	// file name of the template, empty for issues created without a template
+	Template string `json:"template"`
+	Count    int64  `json:"count"`
+}
+
+// LabelUsage represents how often a label was added to issues
+type LabelUsage struct {
+	Label *Label `json:"label"`
+	Total int64  `json:"total"`
+	// numbers of additions per week, the first week starts at the beginning of the period
+	Weekly []int64 `json:"weekly"`
+}
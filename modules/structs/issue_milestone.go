@@ -16,6 +16,10 @@ type Milestone struct {
 	State        StateType `json:"state"`
 	OpenIssues   int       `json:"open_issues"`
 	ClosedIssues int       `json:"closed_issues"`
+	// sum of the weights of the open issues, only set when listing or getting milestones
+	OpenWeight int64 `json:"open_weight"`
+	// sum of the weights of the closed issues, only set when listing or getting milestones
+	ClosedWeight int64 `json:"closed_weight"`
 	// swagger:strfmt date-time
 	Created time.Time `json:"created_at"`
 	// swagger:strfmt date-time
@@ -43,3 +47,30 @@ type EditMilestoneOption struct {
 	State       *string    `json:"state"`
 	Deadline    *time.Time `json:"due_on"`
 }
+
+// MilestoneStats represents the burndown and the velocity of a milestone
+type MilestoneStats struct {
+	Milestone *Milestone `json:"milestone"`
+	// swagger:strfmt date-time
+	From time.Time `json:"from"`
+	// end of the statistics, now or when the milestone was closed
+	// swagger:strfmt date-time
+	Until time.Time `json:"until"`
+	// numbers of open and closed issues at the end of each day from the day of `from` to the day of `until`
+	Burndown []*MilestoneBurndownPoint `json:"burndown"`
+	// numbers of issues closed minus the numbers of issues reopened per week, the first week starts at `from`
+	Weekly []int64 `json:"weekly"`
+	// average number of issues closed per week
+	Velocity float64 `json:"velocity"`
+	// when the open issues will be closed at the current velocity, null if it can't be projected
+	// swagger:strfmt date-time
+	ProjectedCompletion *time.Time `json:"projected_completion"`
+}
+
+// MilestoneBurndownPoint represents the numbers of open and closed issues of a milestone at the end of a day
+type MilestoneBurndownPoint struct {
+	// swagger:strfmt date
+	Date   string `json:"date"`
+	Open   int64  `json:"open"`
+	Closed int64  `json:"closed"`
+}
@@ -20,3 +20,14 @@ type Reaction struct {
 	// swagger:strfmt date-time
 	Created time.Time `json:"created_at"`
 }
+
+// ReactionCount contains the number of reactions of a type on an issue or a pull request and on its comments
+type ReactionCount struct {
+	Reaction string `json:"content"`
+	Total    int64  `json:"total"`
+	// Issue counts the reactions on the issue or the pull request itself
+	Issue int64 `json:"issue"`
+	// Comments counts the reactions on the comments other than the code review comments and the review summaries
+	Comments       int64 `json:"comments"`
+	ReviewComments int64 `json:"review_comments"`
+}
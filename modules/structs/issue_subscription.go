@@ -0,0 +1,11 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// IssueSubscribersOption are options to subscribe users to an issue or to unsubscribe them from it
+type IssueSubscribersOption struct {
+	// required: true
+	Usernames []string `json:"usernames" binding:"Required"`
+}
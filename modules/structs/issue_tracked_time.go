@@ -36,3 +36,16 @@ type TrackedTime struct {
 
 // TrackedTimeList represents a list of tracked times
 type TrackedTimeList []*TrackedTime
+
+// TrackedTimeSum represents the sum of the times tracked on an issue, on the issues with a label or on the issues of a
+// milestone
+type TrackedTimeSum struct {
+	// id of the issue, the label or the milestone, 0 for the issues without a label or a milestone
+	ID int64 `json:"id"`
+	// number of the issue, only set when summing by issue
+	IssueIndex int64 `json:"issue_number,omitempty"`
+	// title of the issue or the milestone or name of the label, empty for the issues without a label or a milestone
+	Name string `json:"name"`
+	// sum of the times in seconds
+	Time int64 `json:"time"`
+}
@@ -0,0 +1,38 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// IssueType the type of the work item an issue represents
+// swagger:model
+type IssueType struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// example: 00aabb
+	Color       string `json:"color"`
+	Description string `json:"description"`
+	// file name of the issue template used by default for the new issues of the type
+	TemplateFile string `json:"template_file"`
+	// whether the type is defined for the organization of the repository
+	IsOrgType bool `json:"is_org_type"`
+}
+
+// CreateIssueTypeOption options for creating an issue type
+type CreateIssueTypeOption struct {
+	// required:true
+	Name string `json:"name" binding:"Required;MaxSize(50)"`
+	// required:true
+	// example: #00aabb
+	Color        string `json:"color" binding:"Required"`
+	Description  string `json:"description" binding:"MaxSize(200)"`
+	TemplateFile string `json:"template_file" binding:"MaxSize(255)"`
+}
+
+// EditIssueTypeOption options for editing an issue type
+type EditIssueTypeOption struct {
+	Name         *string `json:"name" binding:"OmitEmpty;MaxSize(50)"`
+	Color        *string `json:"color"`
+	Description  *string `json:"description" binding:"OmitEmpty;MaxSize(200)"`
+	TemplateFile *string `json:"template_file" binding:"OmitEmpty;MaxSize(255)"`
+}
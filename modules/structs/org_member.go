@@ -4,7 +4,31 @@
 
 package structs
 
+import "time"
+
 // AddOrgMembershipOption add user to organization options
 type AddOrgMembershipOption struct {
 	Role string `json:"role" binding:"Required"`
 }
+
+// OrgMemberContributions summarizes the contributions of a member to the repositories of an organization
+type OrgMemberContributions struct {
+	// swagger:strfmt date-time
+	Since time.Time `json:"since"`
+	// swagger:strfmt date-time
+	Before time.Time `json:"before"`
+	// commits of the default branches, merges excluded
+	Commits int64 `json:"commits"`
+	// pull requests opened during the time range
+	PullRequestsOpened int64 `json:"pull_requests_opened"`
+	// pull requests opened by the member and merged during the time range
+	PullRequestsMerged int64 `json:"pull_requests_merged"`
+	// published reviews
+	Reviews int64 `json:"reviews"`
+	// issues opened during the time range
+	IssuesOpened int64 `json:"issues_opened"`
+	// number of repositories the contributions were counted in
+	Repositories int `json:"repositories"`
+	// swagger:strfmt date-time
+	ComputedAt time.Time `json:"computed_at"`
+}
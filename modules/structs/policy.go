@@ -0,0 +1,45 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// PolicyDocument is a version of the terms of service or of the privacy policy of the instance
+type PolicyDocument struct {
+	ID int64 `json:"id"`
+	// enum: terms,privacy
+	Kind    string `json:"kind"`
+	Version int    `json:"version"`
+	Title   string `json:"title"`
+	// markdown content of the document
+	Content string `json:"content"`
+	// number of users who accepted the document
+	Consents int64 `json:"consents"`
+	// swagger:strfmt date-time
+	Published time.Time `json:"published_at"`
+}
+
+// PolicyConsentState is the consent of a user to the current version of a kind of policy document
+type PolicyConsentState struct {
+	// enum: terms,privacy
+	Kind           string `json:"kind"`
+	DocumentID     int64  `json:"document_id"`
+	CurrentVersion int    `json:"current_version"`
+	// last version the user accepted, 0 if none
+	AcceptedVersion int `json:"accepted_version"`
+	// swagger:strfmt date-time
+	Accepted *time.Time `json:"accepted_at"`
+	// true if the user has not accepted the current version
+	Pending bool `json:"pending"`
+}
+
+// PolicyConsent is the acceptance of a policy document by a user
+type PolicyConsent struct {
+	User *User `json:"user"`
+	// swagger:strfmt date-time
+	Accepted time.Time `json:"accepted_at"`
+}
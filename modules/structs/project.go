@@ -0,0 +1,99 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// Project represents a project of an organization, whose boards contain the issues and pull requests of its
+// repositories
+type Project struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	State       StateType `json:"state"`
+	// how the issues of the boards are grouped in horizontal lanes
+	// enum: none,assignee,label,milestone
+	Swimlanes string `json:"swimlanes"`
+	HTMLURL   string `json:"html_url"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+	// swagger:strfmt date-time
+	Closed *time.Time `json:"closed_at"`
+}
+
+// CreateProjectOption options for creating a project of an organization
+type CreateProjectOption struct {
+	// required: true
+	Title       string `json:"title" binding:"Required;MaxSize(100)"`
+	Description string `json:"description"`
+	// the boards the project is created with
+	// enum: none,basic_kanban,bug_triage
+	Template string `json:"template"`
+	// enum: none,assignee,label,milestone
+	Swimlanes string `json:"swimlanes"`
+}
+
+// EditProjectOption options for editing a project of an organization
+type EditProjectOption struct {
+	Title       *string `json:"title" binding:"OmitEmpty;MaxSize(100)"`
+	Description *string `json:"description"`
+	// enum: open,closed
+	State *string `json:"state"`
+	// enum: none,assignee,label,milestone
+	Swimlanes *string `json:"swimlanes"`
+}
+
+// ProjectBoard represents a board, or column, of a project
+type ProjectBoard struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	// whether the board receives the issues of the project which are not in any board
+	Default bool `json:"default"`
+	Sorting int8 `json:"sorting"`
+	// number of issues above which the board is shown with a warning, 0 for no limit
+	WIPLimit int `json:"wip_limit"`
+	// whether the issues of the project are moved to the board when they are closed
+	AutoMoveOnClose bool `json:"auto_move_on_close"`
+	// whether the pull requests of the project are moved to the board when they are merged
+	AutoMoveOnMerge bool `json:"auto_move_on_merge"`
+	// IDs of the repositories whose issues the board accepts, all the repositories of the organization if empty
+	RepoIDs []int64 `json:"repo_ids"`
+}
+
+// CreateProjectBoardOption options for creating a board of a project
+type CreateProjectBoardOption struct {
+	// required: true
+	Title           string  `json:"title" binding:"Required;MaxSize(100)"`
+	WIPLimit        int     `json:"wip_limit"`
+	AutoMoveOnClose bool    `json:"auto_move_on_close"`
+	AutoMoveOnMerge bool    `json:"auto_move_on_merge"`
+	RepoIDs         []int64 `json:"repo_ids"`
+}
+
+// EditProjectBoardOption options for editing a board of a project
+type EditProjectBoardOption struct {
+	Title           *string `json:"title" binding:"OmitEmpty;MaxSize(100)"`
+	Sorting         *int8   `json:"sorting"`
+	Default         *bool   `json:"default"`
+	WIPLimit        *int    `json:"wip_limit"`
+	AutoMoveOnClose *bool   `json:"auto_move_on_close"`
+	AutoMoveOnMerge *bool   `json:"auto_move_on_merge"`
+	// the repository filter is left unchanged if it is not given, and removed if it is empty
+	RepoIDs []int64 `json:"repo_ids"`
+}
+
+// MoveProjectIssueOption options for adding an issue or a pull request to a board of a project
+type MoveProjectIssueOption struct {
+	// name of the repository of the issue in the organization
+	// required: true
+	Repo string `json:"repo" binding:"Required"`
+	// index of the issue in its repository
+	// required: true
+	Index int64 `json:"index" binding:"Required"`
+}
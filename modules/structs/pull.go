@@ -24,6 +24,9 @@ type PullRequest struct {
 	IsLocked  bool       `json:"is_locked"`
 	Comments  int        `json:"comments"`
 
+	// whether the pull request is a draft, i.e. its title starts with a work in progress prefix
+	Draft bool `json:"draft"`
+
 	HTMLURL  string `json:"html_url"`
 	DiffURL  string `json:"diff_url"`
 	PatchURL string `json:"patch_url"`
@@ -92,4 +95,7 @@ type EditPullRequestOption struct {
 	// swagger:strfmt date-time
 	Deadline       *time.Time `json:"due_date"`
 	RemoveDeadline *bool      `json:"unset_due_date"`
+	// either `true` to convert the pull request to a draft by adding a work in progress prefix to its title,
+	// or `false` to mark it as ready for review by removing the prefix
+	Draft *bool `json:"draft"`
 }
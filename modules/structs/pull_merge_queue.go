@@ -0,0 +1,33 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// MergeQueueEntry represents a pull request waiting in the merge queue of a protected branch
+type MergeQueueEntry struct {
+	// index of the pull request
+	Index int64 `json:"number"`
+	// 1-based position in the merge queue of the base branch
+	Position   int64  `json:"position"`
+	BaseBranch string `json:"base_branch"`
+	// "waiting" for the pull requests before it or "testing" the test commit
+	Status     string `json:"status"`
+	MergeStyle string `json:"merge_style"`
+	// head commit of the pull request when it was added to the queue
+	HeadSHA string `json:"head_sha"`
+	// commit of the base branch the test commit was built on
+	BaseSHA string `json:"base_sha"`
+	// the merge result whose status checks have to pass
+	TestSHA    string `json:"test_sha"`
+	TestBranch string `json:"test_branch"`
+	AddedBy    *User  `json:"added_by"`
+	// swagger:strfmt date-time
+	Added time.Time `json:"added_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
@@ -0,0 +1,34 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// PullMergeRequirement represents a named requirement which has to be satisfied for a pull request to be merged
+type PullMergeRequirement struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Satisfied   bool   `json:"satisfied"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+	Creator     *User  `json:"creator"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// SetPullMergeRequirementOption options to register a merge requirement on a pull request or to change its state
+type SetPullMergeRequirementOption struct {
+	// the requirement of the pull request with the same name is changed if there is one
+	// required: true
+	Name string `json:"name" binding:"Required;MaxSize(255)"`
+	// whether the pull request can be merged as far as the requirement is concerned
+	Satisfied   bool   `json:"satisfied"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+}
@@ -63,9 +63,20 @@ type PullReviewComment struct {
 	DiffHunk     string `json:"diff_hunk"`
 	LineNum      uint64 `json:"position"`
 	OldLineNum   uint64 `json:"original_position"`
+	// first line of a multi-line comment on the new file, 0 for a single line
+	StartLineNum uint64 `json:"start_position"`
+	// first line of a multi-line comment on the old file, 0 for a single line
+	OldStartLineNum uint64 `json:"original_start_position"`
+
+	// the replacement of the commented line suggested in a ```suggestion block of the body
+	Suggestion *string `json:"suggestion,omitempty"`
+	// the commit which applied the suggestion, empty if it has not been applied
+	SuggestionCommitID string `json:"suggestion_commit_id"`
 
 	HTMLURL     string `json:"html_url"`
 	HTMLPullURL string `json:"pull_request_url"`
+
+	Attachments []*Attachment `json:"assets"`
 }
 
 // CreatePullReviewOptions are options to create a pull review
@@ -85,6 +96,12 @@ type CreatePullReviewComment struct {
 	OldLineNum int64 `json:"old_position"`
 	// if comment to new file line or 0
 	NewLineNum int64 `json:"new_position"`
+	// first old file line of a comment on a range of lines ending at old_position, or 0
+	OldStartLineNum int64 `json:"old_start_position"`
+	// first new file line of a comment on a range of lines ending at new_position, or 0
+	NewStartLineNum int64 `json:"new_start_position"`
+	// replacement of the new file line or range of lines, added to the body as a ```suggestion block
+	Suggestion *string `json:"suggestion,omitempty"`
 }
 
 // SubmitPullReviewOptions are options to submit a pending pull review
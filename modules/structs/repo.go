@@ -25,6 +25,10 @@ type InternalTracker struct {
 	AllowOnlyContributorsToTrackTime bool `json:"allow_only_contributors_to_track_time"`
 	// Enable dependencies for issues and pull requests (Built-in issue tracker)
 	EnableIssueDependencies bool `json:"enable_issue_dependencies"`
+	// Remind the assignees of the open issues and milestones due soon (Built-in issue tracker)
+	EnableDueDateReminders bool `json:"enable_due_date_reminders"`
+	// Number of days before the due dates the assignees are reminded (Built-in issue tracker)
+	DueDateReminderDays int `json:"due_date_reminder_days"`
 }
 
 // ExternalTracker represents settings for external tracker
@@ -89,9 +93,25 @@ type Repository struct {
 	AllowRebase               bool             `json:"allow_rebase"`
 	AllowRebaseMerge          bool             `json:"allow_rebase_explicit"`
 	AllowSquash               bool             `json:"allow_squash_merge"`
+	AllowFastForwardOnly      bool             `json:"allow_fast_forward_only_merge"`
 	AvatarURL                 string           `json:"avatar_url"`
 	Internal                  bool             `json:"internal"`
 	MirrorInterval            string           `json:"mirror_interval"`
+
+	// AutoAssignReviewers is the automatic assignment of reviewers to new pull requests, if enabled
+	AutoAssignReviewers *AutoAssignReviewers `json:"auto_assign_reviewers,omitempty"`
+}
+
+// AutoAssignReviewers represents the settings for requesting reviews of new pull requests
+// from the members of a team
+type AutoAssignReviewers struct {
+	// ID of the team of the owner organization the reviewers are picked from
+	TeamID int64 `json:"team_id"`
+	// number of reviewers to request
+	Count int `json:"count"`
+	// how reviewers are picked from the team
+	// enum: round-robin,least-busy
+	Strategy string `json:"strategy"`
 }
 
 // CreateRepoOption options when creating repository
@@ -167,10 +187,14 @@ type EditRepoOption struct {
 	AllowRebaseMerge *bool `json:"allow_rebase_explicit,omitempty"`
 	// either `true` to allow squash-merging pull requests, or `false` to prevent squash-merging. `has_pull_requests` must be `true`.
 	AllowSquash *bool `json:"allow_squash_merge,omitempty"`
+	// either `true` to allow fast-forward-only merging pull requests, or `false` to prevent fast-forward-only merging. `has_pull_requests` must be `true`.
+	AllowFastForwardOnly *bool `json:"allow_fast_forward_only_merge,omitempty"`
 	// either `true` to allow mark pr as merged manually, or `false` to prevent it. `has_pull_requests` must be `true`.
 	AllowManualMerge *bool `json:"allow_manual_merge,omitempty"`
 	// either `true` to enable AutodetectManualMerge, or `false` to prevent it. `has_pull_requests` must be `true`, Note: In some special cases, misjudgments can occur.
 	AutodetectManualMerge *bool `json:"autodetect_manual_merge,omitempty"`
+	// automatically request reviews of new pull requests from members of a team, set `count` to 0 to disable it. `has_pull_requests` must be `true`.
+	AutoAssignReviewers *AutoAssignReviewers `json:"auto_assign_reviewers,omitempty"`
 	// set to `true` to archive this repository.
 	Archived *bool `json:"archived,omitempty"`
 	// set to a string like `8h30m0s` to set the mirror interval time
@@ -207,18 +231,19 @@ type GitServiceType int
 
 // enumerate all GitServiceType
 const (
-	NotMigrated     GitServiceType = iota // 0 not migrated from external sites
-	PlainGitService                       // 1 plain git service
-	GithubService                         // 2 github.com
-	GiteaService                          // 3 gitea service
-	GitlabService                         // 4 gitlab service
-	GogsService                           // 5 gogs service
+	NotMigrated            GitServiceType = iota // 0 not migrated from external sites
+	PlainGitService                              // 1 plain git service
+	GithubService                                // 2 github.com
+	GiteaService                                 // 3 gitea service
+	GitlabService                                // 4 gitlab service
+	GogsService                                  // 5 gogs service
+	BitbucketServerService                       // 6 bitbucket server (stash)
 )
 
 // Name represents the service type's name
 // WARNNING: the name have to be equal to that on goth's library
 func (gt GitServiceType) Name() string {
-	return strings.ToLower(gt.Title())
+	return strings.ToLower(strings.ReplaceAll(gt.Title(), " ", ""))
 }
 
 // Title represents the service type's proper title
@@ -232,6 +257,8 @@ func (gt GitServiceType) Title() string {
 		return "GitLab"
 	case GogsService:
 		return "Gogs"
+	case BitbucketServerService:
+		return "Bitbucket Server"
 	case PlainGitService:
 		return "Git"
 	}
@@ -250,7 +277,7 @@ type MigrateRepoOptions struct {
 	// required: true
 	RepoName string `json:"repo_name" binding:"Required;AlphaDashDot;MaxSize(100)"`
 
-	// enum: git,github,gitea,gitlab
+	// enum: git,github,gitea,gitlab,gogs,bitbucketserver
 	Service      string `json:"service"`
 	AuthUsername string `json:"auth_username"`
 	AuthPassword string `json:"auth_password"`
@@ -266,12 +293,14 @@ type MigrateRepoOptions struct {
 	PullRequests   bool   `json:"pull_requests"`
 	Releases       bool   `json:"releases"`
 	MirrorInterval string `json:"mirror_interval"`
+	// convert the GitLab CI configuration of the default branch into a workflow, ignored for mirrors
+	ConvertCI bool `json:"convert_ci"`
 }
 
 // TokenAuth represents whether a service type supports token-based auth
 func (gt GitServiceType) TokenAuth() bool {
 	switch gt {
-	case GithubService, GiteaService, GitlabService:
+	case GithubService, GiteaService, GitlabService, BitbucketServerService:
 		return true
 	}
 	return false
@@ -285,5 +314,6 @@ var (
 		GitlabService,
 		GiteaService,
 		GogsService,
+		BitbucketServerService,
 	}
 )
@@ -0,0 +1,27 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// Artifact a build artifact uploaded for a commit of a repository
+// swagger:model
+type Artifact struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	Size          int64  `json:"size"`
+	DownloadCount int64  `json:"download_count"`
+	SHA           string `json:"sha"`
+	Ref           string `json:"ref"`
+	Uploader      *User  `json:"uploader"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// the time the artifact is deleted at, empty if it is kept forever
+	// swagger:strfmt date-time
+	Expires     *time.Time `json:"expires_at"`
+	DownloadURL string     `json:"browser_download_url"`
+}
@@ -0,0 +1,24 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// RepoEvent an administrative event of a repository
+// swagger:model
+type RepoEvent struct {
+	ID int64 `json:"id"`
+	// enum: protected_branch_update,protected_branch_delete,webhook_create,webhook_update,webhook_delete,collaborator_add,collaborator_access_change,collaborator_remove,transfer_start,transfer_cancel,transfer
+	Type string `json:"type"`
+	Doer *User  `json:"doer"`
+	// what the event applies to: the name of a branch or of a user, or the host of a webhook
+	Target string `json:"target"`
+	// the details of the event: the access mode of a collaborator, the type of a webhook or the previous owner
+	Content string `json:"content"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
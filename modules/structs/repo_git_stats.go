@@ -0,0 +1,35 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// GitStats represents the object storage statistics of a repository
+type GitStats struct {
+	LooseObjects  int64          `json:"loose_objects"`
+	LooseSize     int64          `json:"loose_size"`
+	PackedObjects int64          `json:"packed_objects"`
+	Packs         int64          `json:"packs"`
+	PackSize      int64          `json:"pack_size"`
+	PrunePackable int64          `json:"prune_packable"`
+	Garbage       int64          `json:"garbage"`
+	GarbageSize   int64          `json:"garbage_size"`
+	LargestBlobs  []*GitBlobSize `json:"largest_blobs"`
+	Refs          *GitRefCounts  `json:"refs"`
+}
+
+// GitBlobSize represents the size of a blob
+type GitBlobSize struct {
+	SHA      string `json:"sha"`
+	URL      string `json:"url"`
+	Size     int64  `json:"size"`
+	DiskSize int64  `json:"disk_size"`
+}
+
+// GitRefCounts represents the number of refs of a repository by kind
+type GitRefCounts struct {
+	Branches     int64 `json:"branches"`
+	Tags         int64 `json:"tags"`
+	PullRequests int64 `json:"pull_requests"`
+	Others       int64 `json:"others"`
+}
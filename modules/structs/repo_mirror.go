@@ -0,0 +1,95 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// Mirror represents the settings of a repository which mirrors another one
+type Mirror struct {
+	RemoteAddress string `json:"remote_address"`
+	Interval      string `json:"interval"`
+	EnablePrune   bool   `json:"enable_prune"`
+	// time of the day, formatted as HH:MM in the time zone of the server, from which the mirror is synced,
+	// it is synced at any time if it is equal to sync_window_end
+	SyncWindowStart string `json:"sync_window_start"`
+	// time of the day until which the mirror is synced
+	SyncWindowEnd string `json:"sync_window_end"`
+	// refspec patterns, like refs/tags/v*, of the refs which are synced, all of them if both lists are empty
+	IncludeRefs []string `json:"include_refs"`
+	// refspec patterns of the refs which are not synced, excluding refs requires git 2.29
+	ExcludeRefs []string `json:"exclude_refs"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated"`
+	// swagger:strfmt date-time
+	NextUpdate *time.Time `json:"next_update"`
+	// error of the last sync, empty if it succeeded
+	LastError string `json:"last_error"`
+	// number of the last syncs which failed in a row
+	ConsecutiveFailures int `json:"consecutive_failures"`
+}
+
+// EditMirrorOption options when editing the settings of a mirror
+type EditMirrorOption struct {
+	// interval between the syncs, like 8h, 0 to disable the periodic syncs
+	Interval        *string `json:"interval"`
+	EnablePrune     *bool   `json:"enable_prune"`
+	SyncWindowStart *string `json:"sync_window_start"`
+	SyncWindowEnd   *string `json:"sync_window_end"`
+	// the refs are left unchanged if omitted
+	IncludeRefs []string `json:"include_refs"`
+	ExcludeRefs []string `json:"exclude_refs"`
+}
+
+// PushMirror represents a remote repository to which a repository is pushed periodically
+type PushMirror struct {
+	ID            int64  `json:"id"`
+	RemoteName    string `json:"remote_name"`
+	RemoteAddress string `json:"remote_address"`
+	Interval      string `json:"interval"`
+	// time of the day, formatted as HH:MM in the time zone of the server, from which the mirror is pushed,
+	// it is pushed at any time if it is equal to sync_window_end
+	SyncWindowStart string `json:"sync_window_start"`
+	// time of the day until which the mirror is pushed
+	SyncWindowEnd string `json:"sync_window_end"`
+	// refspec patterns, like refs/tags/v*, of the refs which are pushed, all of them if both lists are empty
+	IncludeRefs []string `json:"include_refs"`
+	// refspec patterns of the refs which are not pushed, excluding refs requires git 2.29
+	ExcludeRefs []string `json:"exclude_refs"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created"`
+	// swagger:strfmt date-time
+	LastUpdate *time.Time `json:"last_update"`
+	// swagger:strfmt date-time
+	NextUpdate *time.Time `json:"next_update"`
+	LastError  string     `json:"last_error"`
+	// number of the last pushes which failed in a row
+	ConsecutiveFailures int `json:"consecutive_failures"`
+}
+
+// CreatePushMirrorOption options when creating a push mirror
+type CreatePushMirrorOption struct {
+	// required: true
+	RemoteAddress  string `json:"remote_address" binding:"Required"`
+	RemoteUsername string `json:"remote_username"`
+	RemotePassword string `json:"remote_password"`
+	// interval between the pushes, like 8h, 0 to only push the mirror on demand
+	Interval        string   `json:"interval"`
+	SyncWindowStart string   `json:"sync_window_start"`
+	SyncWindowEnd   string   `json:"sync_window_end"`
+	IncludeRefs     []string `json:"include_refs"`
+	ExcludeRefs     []string `json:"exclude_refs"`
+}
+
+// EditPushMirrorOption options when editing a push mirror
+type EditPushMirrorOption struct {
+	Interval        *string `json:"interval"`
+	SyncWindowStart *string `json:"sync_window_start"`
+	SyncWindowEnd   *string `json:"sync_window_end"`
+	// the refs are left unchanged if omitted
+	IncludeRefs []string `json:"include_refs"`
+	ExcludeRefs []string `json:"exclude_refs"`
+}
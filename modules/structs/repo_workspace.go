@@ -0,0 +1,30 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// RepoWorkspace represents the metadata an IDE needs to open a repository
+type RepoWorkspace struct {
+	CloneURL      string `json:"clone_url"`
+	SSHURL        string `json:"ssh_url"`
+	DefaultBranch string `json:"default_branch"`
+	// whether the default branch contains a development container configuration
+	HasDevcontainer bool `json:"has_devcontainer"`
+	// path of the development container configuration, empty if there is none
+	DevcontainerPath string `json:"devcontainer_path"`
+	// whether the default branch contains a Gitpod configuration
+	HasGitpod bool `json:"has_gitpod"`
+	// path of the Gitpod configuration, empty if there is none
+	GitpodConfigPath string `json:"gitpod_config_path"`
+	// link opening the repository in Gitpod, empty if it has no Gitpod configuration or Gitpod is disabled
+	GitpodURL string     `json:"gitpod_url"`
+	IDELinks  []*IDELink `json:"ide_links"`
+}
+
+// IDELink represents a link opening a repository in an IDE through its protocol handler
+type IDELink struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	URL         string `json:"url"`
+}
@@ -9,7 +9,10 @@ type TaskType int
 
 // all kinds of task types
 const (
-	TaskTypeMigrateRepo TaskType = iota // migrate repository from external or local disk
+	TaskTypeMigrateRepo   TaskType = iota // migrate repository from external or local disk
+	TaskTypeMigrateLFS                    // move the large files of a repository into LFS
+	TaskTypeSyncMigration                 // pull in what was created on the upstream of a migrated repository
+	TaskTypeExportRepo                    // export repository to GitHub or GitLab
 )
 
 // Name returns the task type name
@@ -17,6 +20,12 @@ func (taskType TaskType) Name() string {
 	switch taskType {
 	case TaskTypeMigrateRepo:
 		return "Migrate Repository"
+	case TaskTypeMigrateLFS:
+		return "Migrate Files to LFS"
+	case TaskTypeSyncMigration:
+		return "Sync Migrated Repository"
+	case TaskTypeExportRepo:
+		return "Export Repository"
 	}
 	return ""
 }
@@ -0,0 +1,59 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// UserFilter represents the named filters of an issue or pull request list, saved by a user
+type UserFilter struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// the filtered list, either "issues" or "pulls"
+	Type string `json:"type"`
+	// the repository whose list is filtered, null for the list of the dashboard
+	Repository *RepositoryMeta `json:"repository"`
+	// the issues and pull requests of the list related to the user, e.g. "assigned" or "created_by"
+	ViewType string `json:"view_type"`
+	Sort     string `json:"sort"`
+	State    string `json:"state"`
+	Keyword  string `json:"keyword"`
+	// the IDs of the labels, negative for the excluded ones
+	Labels    []int64 `json:"labels"`
+	Milestone int64   `json:"milestone"`
+	Assignee  int64   `json:"assignee"`
+	// the IDs of the repositories the list of the dashboard is restricted to
+	Repos   []int64 `json:"repos"`
+	HTMLURL string  `json:"html_url"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateUserFilterOption options for saving the filters of an issue or pull request list
+type CreateUserFilterOption struct {
+	// required: true
+	Name string `json:"name" binding:"Required;MaxSize(255)"`
+	// the filtered list
+	// enum: issues,pulls
+	Type string `json:"type" binding:"OmitEmpty;In(issues,pulls)"`
+	// the ID of the repository whose list is filtered, 0 for the list of the dashboard
+	RepositoryID int64 `json:"repository_id"`
+	// the issues and pull requests of the list related to the user
+	// enum: all,your_repositories,assigned,created_by,mentioned,review_requested
+	ViewType string `json:"view_type" binding:"OmitEmpty;In(all,your_repositories,assigned,created_by,mentioned,review_requested)"`
+	Sort     string `json:"sort" binding:"MaxSize(255)"`
+	// enum: open,closed
+	State   string `json:"state" binding:"OmitEmpty;In(open,closed)"`
+	Keyword string `json:"keyword" binding:"MaxSize(255)"`
+	// the IDs of the labels, negative for the excluded ones
+	Labels    []int64 `json:"labels"`
+	Milestone int64   `json:"milestone"`
+	Assignee  int64   `json:"assignee"`
+	// the IDs of the repositories the list of the dashboard is restricted to
+	Repos []int64 `json:"repos"`
+}
@@ -0,0 +1,25 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// UserProfileField a profile field defined by the site administrators with the value of a user
+type UserProfileField struct {
+	Name        string `json:"name"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	// enum: text,link
+	Type string `json:"type"`
+	// who can see the value besides the user and the site administrators
+	// enum: public,limited,private
+	Visibility string `json:"visibility"`
+	Required   bool   `json:"required"`
+	Value      string `json:"value"`
+}
+
+// EditUserProfileFieldsOption options when setting the values of the profile fields of a user
+type EditUserProfileFieldsOption struct {
+	// values by field name, an empty value removes it and the fields not listed are left unchanged
+	Values map[string]string `json:"values"`
+}
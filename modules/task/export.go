@@ -0,0 +1,124 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package task
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// IsExportingRepository returns true if a task exporting the repository is queued or running
+func IsExportingRepository(repoID int64) (bool, error) {
+	t, err := models.GetLastRepoTask(repoID, structs.TaskTypeExportRepo)
+	if err != nil {
+		if models.IsErrTaskDoesNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return t.Status == structs.TaskStatusQueue || t.Status == structs.TaskStatusRunning, nil
+}
+
+// CreateExportTask creates a task exporting a repository to GitHub or GitLab
+func CreateExportTask(doer *models.User, repo *models.Repository, opts migrations.ExportOptions) (*models.Task, error) {
+	bs, err := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(&opts)
+	if err != nil {
+		return nil, err
+	}
+	t := &models.Task{
+		DoerID:         doer.ID,
+		OwnerID:        repo.OwnerID,
+		RepoID:         repo.ID,
+		Type:           structs.TaskTypeExportRepo,
+		Status:         structs.TaskStatusQueue,
+		PayloadContent: string(bs),
+	}
+	if err := models.CreateTask(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ExportRepository adds a task exporting a repository to GitHub or GitLab to the queue
+func ExportRepository(doer *models.User, repo *models.Repository, opts migrations.ExportOptions) (*models.Task, error) {
+	t, err := CreateExportTask(doer, repo, opts)
+	if err != nil {
+		return nil, err
+	}
+	return t, taskQueue.Push(t)
+}
+
+// GetExportOptions returns the options of a task exporting a repository, whose token is removed once it has run
+func GetExportOptions(t *models.Task) (*migrations.ExportOptions, error) {
+	if t.Type != structs.TaskTypeExportRepo {
+		return nil, fmt.Errorf("Task type is %s, not Export Repository", t.Type.Name())
+	}
+	var opts migrations.ExportOptions
+	if err := jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal([]byte(t.PayloadContent), &opts); err != nil {
+		return nil, err
+	}
+	return &opts, nil
+}
+
+// RunExportTask runs a task exporting a repository to GitHub or GitLab. The token is removed from the task once it
+// has run.
+func RunExportTask(t *models.Task) (err error) {
+	var opts migrations.ExportOptions
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("PANIC whilst trying to do export task: %v", e)
+			log.Critical("PANIC during RunExportTask[%d] of RepoID[%d]: %v\nStacktrace: %v", t.ID, t.RepoID, e, log.Stack(2))
+		}
+
+		t.EndTime = timeutil.TimeStampNow()
+		if err == nil {
+			t.Status = structs.TaskStatusFinished
+		} else {
+			t.Status = structs.TaskStatusFailed
+			t.Errors = err.Error()
+		}
+		opts.AuthToken = ""
+		if bs, errMarshal := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(&opts); errMarshal == nil {
+			t.PayloadContent = string(bs)
+		}
+		if errUpdate := t.UpdateCols("status", "errors", "end_time", "payload_content"); errUpdate != nil {
+			log.Error("Task UpdateCols failed: %v", errUpdate)
+		}
+	}()
+
+	if err = jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal([]byte(t.PayloadContent), &opts); err != nil {
+		return
+	}
+	if err = t.LoadRepo(); err != nil {
+		return
+	}
+	if err = t.LoadDoer(); err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(graceful.GetManager().ShutdownContext())
+	defer cancel()
+	pm := process.GetManager()
+	pid := pm.Add(fmt.Sprintf("ExportTask: %s", t.Repo.FullName()), cancel)
+	defer pm.Remove(pid)
+
+	t.StartTime = timeutil.TimeStampNow()
+	t.Status = structs.TaskStatusRunning
+	if err = t.UpdateCols("start_time", "status"); err != nil {
+		return
+	}
+
+	return migrations.ExportRepository(ctx, t.Doer, t.Repo, opts)
+}
@@ -0,0 +1,266 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+var lfsOidRegexp = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+// MigrateToLFSOptions represents the options to move the large files of a repository into LFS
+type MigrateToLFSOptions struct {
+	// Above is the size in bytes a file must exceed to be moved into LFS
+	Above int64 `json:"above"`
+}
+
+// MigrateToLFS rewrites the history of all refs of a repository with `git lfs migrate import`, which replaces
+// the files larger than opts.Above with LFS pointers and tracks their paths in .gitattributes, and stores
+// the content of the files in the LFS store. The repository is in maintenance mode during the migration,
+// which fails if the refs are changed in the meantime. progress is called with a description of every step.
+func MigrateToLFS(ctx context.Context, repo *models.Repository, opts MigrateToLFSOptions, progress func(string)) (err error) {
+	if !setting.LFS.StartServer {
+		return fmt.Errorf("LFS is disabled")
+	}
+	if repo.IsMirror || repo.IsArchived {
+		return fmt.Errorf("%s is a mirror or archived", repo.FullName())
+	}
+	if opts.Above <= 0 {
+		return fmt.Errorf("the size threshold must be positive")
+	}
+	if _, err := git.NewCommandContext(ctx, "lfs", "version").RunInDir(repo.RepoPath()); err != nil {
+		return fmt.Errorf("git-lfs is not installed on the server: %v", err)
+	}
+
+	timeout := time.Duration(setting.Git.Timeout.Migrate) * time.Second
+	repoPath := repo.RepoPath()
+	refs, err := git.NewCommandContext(ctx, "show-ref").RunInDir(repoPath)
+	if err != nil {
+		return fmt.Errorf("the repository has no refs: %v", err)
+	}
+
+	if !repo.IsUnderMaintenance() {
+		if err := repo.SetMaintenanceMode(true, 0, "Large files are being moved into LFS"); err != nil {
+			return fmt.Errorf("SetMaintenanceMode: %v", err)
+		}
+		defer func() {
+			if errMaintenance := repo.SetMaintenanceMode(false, 0, ""); errMaintenance != nil && err == nil {
+				err = fmt.Errorf("SetMaintenanceMode: %v", errMaintenance)
+			}
+		}()
+	}
+
+	tmpPath, err := models.CreateTemporaryPath("lfs-migrate")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := models.RemoveTemporaryPath(tmpPath); err != nil {
+			log.Error("RemoveTemporaryPath: %v", err)
+		}
+	}()
+
+	progress("Cloning the repository")
+	if _, err := git.NewCommandContext(ctx, "clone", "--mirror", "--quiet", repoPath, tmpPath).
+		RunInDirTimeout(timeout, ""); err != nil {
+		return fmt.Errorf("git clone: %v", err)
+	}
+	// without a remote only the refs of the clone are rewritten
+	if _, err := git.NewCommandContext(ctx, "remote", "remove", "origin").RunInDir(tmpPath); err != nil {
+		return fmt.Errorf("git remote remove: %v", err)
+	}
+
+	progress(fmt.Sprintf("Rewriting the history to move files larger than %d bytes into LFS", opts.Above))
+	if _, err := git.NewCommandContext(ctx, "lfs", "migrate", "import", "--everything", fmt.Sprintf("--above=%db", opts.Above)).
+		RunInDirTimeout(timeout, tmpPath); err != nil {
+		return fmt.Errorf("git lfs migrate: %v", err)
+	}
+
+	progress("Storing the LFS objects")
+	contentStore := &lfs.ContentStore{ObjectStorage: storage.LFS}
+	var numObjects int
+	if err := filepath.Walk(filepath.Join(tmpPath, "lfs", "objects"), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.Mode().IsRegular() || !lfsOidRegexp.MatchString(info.Name()) {
+			return nil
+		}
+		meta := &models.LFSMetaObject{Oid: info.Name(), Size: info.Size(), RepositoryID: repo.ID}
+		exists, err := contentStore.Exists(meta)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if err := contentStore.Put(meta, f); err != nil {
+				return fmt.Errorf("Put [%s]: %v", meta.Oid, err)
+			}
+		}
+		if _, err := models.NewLFSMetaObject(meta); err != nil {
+			return fmt.Errorf("NewLFSMetaObject [%s]: %v", meta.Oid, err)
+		}
+		numObjects++
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	progress(fmt.Sprintf("Updating the repository with %d LFS objects", numObjects))
+	current, err := git.NewCommandContext(ctx, "show-ref").RunInDir(repoPath)
+	if err != nil {
+		return fmt.Errorf("git show-ref: %v", err)
+	}
+	if current != refs {
+		return fmt.Errorf("the refs of %s were changed during the migration", repo.FullName())
+	}
+	if _, err := git.NewCommandContext(ctx, "fetch", "--quiet", "--force", "--update-head-ok", tmpPath, "+refs/*:refs/*").
+		RunInDirTimeout(timeout, repoPath); err != nil {
+		return fmt.Errorf("git fetch: %v", err)
+	}
+
+	progress("Removing the old history")
+	if _, err := git.NewCommandContext(ctx, "reflog", "expire", "--expire=now", "--all").RunInDir(repoPath); err != nil {
+		return fmt.Errorf("git reflog expire: %v", err)
+	}
+	if _, err := git.NewCommandContext(ctx, "gc", "--prune=now", "--quiet").
+		RunInDirTimeout(time.Duration(setting.Git.Timeout.GC)*time.Second, repoPath); err != nil {
+		return fmt.Errorf("git gc: %v", err)
+	}
+
+	gitRepo, err := git.OpenRepository(repoPath)
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+	if err := repo_module.SyncReleasesWithTags(repo, gitRepo); err != nil {
+		return fmt.Errorf("SyncReleasesWithTags: %v", err)
+	}
+	if err := repo.UpdateSize(models.DefaultDBContext()); err != nil {
+		return fmt.Errorf("UpdateSize: %v", err)
+	}
+	log.Trace("Files larger than %d bytes of %s moved into %d LFS objects", opts.Above, repo.FullName(), numObjects)
+	return nil
+}
+
+// IsMigratingToLFS returns true if a task moving the large files of the repository into LFS is queued or running
+func IsMigratingToLFS(repoID int64) (bool, error) {
+	t, err := models.GetLastRepoTask(repoID, structs.TaskTypeMigrateLFS)
+	if err != nil {
+		if models.IsErrTaskDoesNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return t.Status == structs.TaskStatusQueue || t.Status == structs.TaskStatusRunning, nil
+}
+
+// CreateMigrateLFSTask creates a task moving the large files of a repository into LFS
+func CreateMigrateLFSTask(doer *models.User, repo *models.Repository, opts MigrateToLFSOptions) (*models.Task, error) {
+	bs, err := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(&opts)
+	if err != nil {
+		return nil, err
+	}
+	t := &models.Task{
+		DoerID:         doer.ID,
+		OwnerID:        repo.OwnerID,
+		RepoID:         repo.ID,
+		Type:           structs.TaskTypeMigrateLFS,
+		Status:         structs.TaskStatusQueue,
+		PayloadContent: string(bs),
+	}
+	if err := models.CreateTask(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// MigrateRepositoryToLFS adds a task moving the large files of a repository into LFS to the queue
+func MigrateRepositoryToLFS(doer *models.User, repo *models.Repository, opts MigrateToLFSOptions) (*models.Task, error) {
+	t, err := CreateMigrateLFSTask(doer, repo, opts)
+	if err != nil {
+		return nil, err
+	}
+	return t, taskQueue.Push(t)
+}
+
+// RunMigrateLFSTask runs a task moving the large files of a repository into LFS. Every step is stored
+// as the message of the task and passed to progress if it is not nil.
+func RunMigrateLFSTask(t *models.Task, progress func(string)) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("PANIC whilst trying to do migrate LFS task: %v", e)
+			log.Critical("PANIC during RunMigrateLFSTask[%d] of RepoID[%d]: %v\nStacktrace: %v", t.ID, t.RepoID, e, log.Stack(2))
+		}
+
+		t.EndTime = timeutil.TimeStampNow()
+		if err == nil {
+			t.Status = structs.TaskStatusFinished
+			t.Message = "Finished"
+		} else {
+			t.Status = structs.TaskStatusFailed
+			t.Errors = err.Error()
+		}
+		if errUpdate := t.UpdateCols("status", "errors", "message", "end_time"); errUpdate != nil {
+			log.Error("Task UpdateCols failed: %v", errUpdate)
+		}
+	}()
+
+	if err = t.LoadRepo(); err != nil {
+		return
+	}
+	var opts MigrateToLFSOptions
+	if err = jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal([]byte(t.PayloadContent), &opts); err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(graceful.GetManager().ShutdownContext())
+	defer cancel()
+	pm := process.GetManager()
+	pid := pm.Add(fmt.Sprintf("MigrateLFSTask: %s", t.Repo.FullName()), cancel)
+	defer pm.Remove(pid)
+
+	t.StartTime = timeutil.TimeStampNow()
+	t.Status = structs.TaskStatusRunning
+	if err = t.UpdateCols("start_time", "status"); err != nil {
+		return
+	}
+
+	return MigrateToLFS(ctx, t.Repo, opts, func(message string) {
+		t.Message = message
+		if err := t.UpdateCols("message"); err != nil {
+			log.Error("Task UpdateCols failed: %v", err)
+		}
+		if progress != nil {
+			progress(message)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package task
+
+import (
+	"context"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateLFSTask(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	migrating, err := IsMigratingToLFS(repo.ID)
+	assert.NoError(t, err)
+	assert.False(t, migrating)
+
+	task, err := CreateMigrateLFSTask(doer, repo, MigrateToLFSOptions{Above: 1024})
+	assert.NoError(t, err)
+	assert.Equal(t, structs.TaskTypeMigrateLFS, task.Type)
+	assert.JSONEq(t, `{"above":1024}`, task.PayloadContent)
+
+	migrating, err = IsMigratingToLFS(repo.ID)
+	assert.NoError(t, err)
+	assert.True(t, migrating)
+
+	task.Status = structs.TaskStatusFailed
+	assert.NoError(t, task.UpdateCols("status"))
+	migrating, err = IsMigratingToLFS(repo.ID)
+	assert.NoError(t, err)
+	assert.False(t, migrating)
+}
+
+func TestMigrateToLFSValidation(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	defer func(enabled bool) {
+		setting.LFS.StartServer = enabled
+	}(setting.LFS.StartServer)
+
+	setting.LFS.StartServer = false
+	assert.Error(t, MigrateToLFS(context.Background(), repo, MigrateToLFSOptions{Above: 1024}, nil))
+
+	setting.LFS.StartServer = true
+	assert.Error(t, MigrateToLFS(context.Background(), repo, MigrateToLFSOptions{Above: 0}, nil))
+
+	mirror := models.AssertExistsAndLoadBean(t, &models.Repository{IsMirror: true}).(*models.Repository)
+	assert.Error(t, MigrateToLFS(context.Background(), mirror, MigrateToLFSOptions{Above: 1024}, nil))
+}
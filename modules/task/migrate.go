@@ -47,7 +47,7 @@ func runMigrateTask(t *models.Task) (err error) {
 		if err == nil {
 			err = models.FinishMigrateTask(t)
 			if err == nil {
-				notification.NotifyMigrateRepository(t.Doer, t.Owner, t.Repo)
+				notification.NotifyMigrateRepository(graceful.GetManager().ShutdownContext(), t.Doer, t.Owner, t.Repo)
 				return
 			}
 
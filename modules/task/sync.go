@@ -0,0 +1,97 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package task
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// IsSyncingMigration returns true if a task pulling in what was created on the upstream of the repository is queued
+// or running
+func IsSyncingMigration(repoID int64) (bool, error) {
+	t, err := models.GetLastRepoTask(repoID, structs.TaskTypeSyncMigration)
+	if err != nil {
+		if models.IsErrTaskDoesNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return t.Status == structs.TaskStatusQueue || t.Status == structs.TaskStatusRunning, nil
+}
+
+// CreateSyncMigrationTask creates a task pulling in what was created on the upstream of a migrated repository
+func CreateSyncMigrationTask(doer *models.User, repo *models.Repository) (*models.Task, error) {
+	t := &models.Task{
+		DoerID:  doer.ID,
+		OwnerID: repo.OwnerID,
+		RepoID:  repo.ID,
+		Type:    structs.TaskTypeSyncMigration,
+		Status:  structs.TaskStatusQueue,
+	}
+	if err := models.CreateTask(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SyncMigratedRepository adds a task pulling in what was created on the upstream of a migrated repository to the queue
+func SyncMigratedRepository(doer *models.User, repo *models.Repository) (*models.Task, error) {
+	t, err := CreateSyncMigrationTask(doer, repo)
+	if err != nil {
+		return nil, err
+	}
+	return t, taskQueue.Push(t)
+}
+
+// RunSyncMigrationTask runs a task pulling in what was created on the upstream of a migrated repository
+func RunSyncMigrationTask(t *models.Task) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("PANIC whilst trying to do sync migration task: %v", e)
+			log.Critical("PANIC during RunSyncMigrationTask[%d] of RepoID[%d]: %v\nStacktrace: %v", t.ID, t.RepoID, e, log.Stack(2))
+		}
+
+		t.EndTime = timeutil.TimeStampNow()
+		if err == nil {
+			t.Status = structs.TaskStatusFinished
+		} else {
+			t.Status = structs.TaskStatusFailed
+			t.Errors = err.Error()
+		}
+		if errUpdate := t.UpdateCols("status", "errors", "end_time"); errUpdate != nil {
+			log.Error("Task UpdateCols failed: %v", errUpdate)
+		}
+	}()
+
+	if err = t.LoadRepo(); err != nil {
+		return
+	}
+	if err = t.LoadDoer(); err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(graceful.GetManager().ShutdownContext())
+	defer cancel()
+	pm := process.GetManager()
+	pid := pm.Add(fmt.Sprintf("SyncMigrationTask: %s", t.Repo.FullName()), cancel)
+	defer pm.Remove(pid)
+
+	t.StartTime = timeutil.TimeStampNow()
+	t.Status = structs.TaskStatusRunning
+	if err = t.UpdateCols("start_time", "status"); err != nil {
+		return
+	}
+
+	return migrations.SyncMigratedRepository(ctx, t.Doer, t.Repo)
+}
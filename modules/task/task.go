@@ -26,6 +26,12 @@ func Run(t *models.Task) error {
 	switch t.Type {
 	case structs.TaskTypeMigrateRepo:
 		return runMigrateTask(t)
+	case structs.TaskTypeMigrateLFS:
+		return RunMigrateLFSTask(t, nil)
+	case structs.TaskTypeSyncMigration:
+		return RunSyncMigrationTask(t)
+	case structs.TaskTypeExportRepo:
+		return RunExportTask(t)
 	default:
 		return fmt.Errorf("Unknown task type: %d", t.Type)
 	}
@@ -0,0 +1,57 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package templates
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/modules/util"
+)
+
+// PathBreadcrumb is the breadcrumb of a path in a repository, linking to each of its directories at a ref
+type PathBreadcrumb struct {
+	// RootLink is the escaped link to the root of the path
+	RootLink string
+	// Path is the path without its empty segments, e.g. to be copied
+	Path     string
+	Segments []*PathBreadcrumbSegment
+}
+
+// PathBreadcrumbSegment is a segment of a path breadcrumb
+type PathBreadcrumbSegment struct {
+	Name string
+	// Path is the path from the root up to and including the segment
+	Path string
+	// Link is the escaped link to the segment
+	Link   string
+	IsLast bool
+}
+
+// NewPathBreadcrumb returns the breadcrumb of a path relative to the unescaped link to its root, usually the link to
+// the root of a repository at a ref, e.g. /user/repo/src/branch/main
+func NewPathBreadcrumb(rootLink, treePath string) *PathBreadcrumb {
+	names := make([]string, 0, strings.Count(treePath, "/")+1)
+	for _, name := range strings.Split(treePath, "/") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	b := &PathBreadcrumb{
+		RootLink: util.PathEscapeSegments(strings.TrimSuffix(rootLink, "/")),
+		Path:     strings.Join(names, "/"),
+		Segments: make([]*PathBreadcrumbSegment, len(names)),
+	}
+	for i, name := range names {
+		segmentPath := strings.Join(names[:i+1], "/")
+		b.Segments[i] = &PathBreadcrumbSegment{
+			Name:   name,
+			Path:   segmentPath,
+			Link:   b.RootLink + "/" + util.PathEscapeSegments(segmentPath),
+			IsLast: i == len(names)-1,
+		}
+	}
+	return b
+}
@@ -0,0 +1,35 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package templates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPathBreadcrumb(t *testing.T) {
+	b := NewPathBreadcrumb("/user2/repo1/src/branch/feature#1", "docs/a dir/file?.md")
+	assert.Equal(t, "/user2/repo1/src/branch/feature%231", b.RootLink)
+	assert.Equal(t, "docs/a dir/file?.md", b.Path)
+	assert.Equal(t, []*PathBreadcrumbSegment{
+		{Name: "docs", Path: "docs", Link: "/user2/repo1/src/branch/feature%231/docs"},
+		{Name: "a dir", Path: "docs/a dir", Link: "/user2/repo1/src/branch/feature%231/docs/a%20dir"},
+		{Name: "file?.md", Path: "docs/a dir/file?.md", Link: "/user2/repo1/src/branch/feature%231/docs/a%20dir/file%3F.md", IsLast: true},
+	}, b.Segments)
+
+	// the empty segments are skipped
+	b = NewPathBreadcrumb("/user2/repo1/src/commit/65f1bf27bc/", "/a//b/")
+	assert.Equal(t, "/user2/repo1/src/commit/65f1bf27bc", b.RootLink)
+	assert.Equal(t, "a/b", b.Path)
+	if assert.Len(t, b.Segments, 2) {
+		assert.Equal(t, "/user2/repo1/src/commit/65f1bf27bc/a/b", b.Segments[1].Link)
+	}
+
+	b = NewPathBreadcrumb("/user2/repo1/src/branch/master", "")
+	assert.Equal(t, "/user2/repo1/src/branch/master", b.RootLink)
+	assert.Empty(t, b.Path)
+	assert.Empty(t, b.Segments)
+}
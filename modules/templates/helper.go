@@ -204,16 +204,7 @@ func NewFuncMap() []template.FuncMap {
 			}
 			return "tab-size-8"
 		},
-		"SubJumpablePath": func(str string) []string {
-			var path []string
-			index := strings.LastIndex(str, "/")
-			if index != -1 && index != len(str) {
-				path = append(path, str[0:index+1], str[index+1:])
-			} else {
-				path = append(path, str)
-			}
-			return path
-		},
+		"PathBreadcrumb": NewPathBreadcrumb,
 		"DiffStatsWidth": func(adds int, dels int) string {
 			return fmt.Sprintf("%f", float64(adds)/(float64(adds)+float64(dels))*100)
 		},
@@ -536,13 +527,27 @@ func parseOthers(defaultSize int, defaultClass string, others ...interface{}) (i
 
 // AvatarHTML creates the HTML for an avatar
 func AvatarHTML(src string, size int, class string, name string) template.HTML {
-	sizeStr := fmt.Sprintf(`%d`, size)
+	return avatarHTML(src, "", size, class, name)
+}
 
+// avatarHTML creates the HTML for an avatar which is loaded lazily, src2x is the source for displays
+// with twice the pixel density if it differs from src
+func avatarHTML(src, src2x string, size int, class, name string) template.HTML {
+	if src == "" {
+		return template.HTML("")
+	}
 	if name == "" {
 		name = "avatar"
 	}
+	name = html.EscapeString(name)
+	sizeStr := fmt.Sprintf(`%d`, size)
+
+	var srcset string
+	if src2x != "" && src2x != src {
+		srcset = ` srcset="` + html.EscapeString(src) + ` 1x, ` + html.EscapeString(src2x) + ` 2x"`
+	}
 
-	return template.HTML(`<img class="` + class + `" src="` + src + `" title="` + html.EscapeString(name) + `" width="` + sizeStr + `" height="` + sizeStr + `"/>`)
+	return template.HTML(`<img class="` + class + `" src="` + html.EscapeString(src) + `"` + srcset + ` title="` + name + `" alt="` + name + `" width="` + sizeStr + `" height="` + sizeStr + `" loading="lazy"/>`)
 }
 
 // SVG render icons - arguments icon name (string), size (int), class (string)
@@ -562,21 +567,19 @@ func SVG(icon string, others ...interface{}) template.HTML {
 	return template.HTML("")
 }
 
-// Avatar renders user avatars. args: user, size (int), class (string)
+// Avatar renders user avatars. args: user, size (int), class (string).
+// The user can also be the name of an author without an account, like the original author of
+// a migrated comment, who gets the default avatar.
 func Avatar(item interface{}, others ...interface{}) template.HTML {
 	size, class := parseOthers(models.DefaultAvatarPixelSize, "ui avatar image", others...)
 
-	if user, ok := item.(*models.User); ok {
-		src := user.RealSizedAvatarLink(size * models.AvatarRenderedSizeFactor)
-		if src != "" {
-			return AvatarHTML(src, size, class, user.DisplayName())
-		}
-	}
-	if user, ok := item.(*models.Collaborator); ok {
-		src := user.RealSizedAvatarLink(size * models.AvatarRenderedSizeFactor)
-		if src != "" {
-			return AvatarHTML(src, size, class, user.DisplayName())
-		}
+	switch user := item.(type) {
+	case *models.User:
+		return avatarHTML(user.RealSizedAvatarLink(size), user.RealSizedAvatarLink(size*models.AvatarRenderedSizeFactor), size, class, user.DisplayName())
+	case *models.Collaborator:
+		return avatarHTML(user.RealSizedAvatarLink(size), user.RealSizedAvatarLink(size*models.AvatarRenderedSizeFactor), size, class, user.DisplayName())
+	case string:
+		return avatarHTML(models.DefaultAvatarLink(), "", size, class, user)
 	}
 	return template.HTML("")
 }
@@ -591,23 +594,13 @@ func AvatarByAction(action *models.Action, others ...interface{}) template.HTML
 func RepoAvatar(repo *models.Repository, others ...interface{}) template.HTML {
 	size, class := parseOthers(models.DefaultAvatarPixelSize, "ui avatar image", others...)
 
-	src := repo.RelAvatarLink()
-	if src != "" {
-		return AvatarHTML(src, size, class, repo.FullName())
-	}
-	return template.HTML("")
+	return avatarHTML(repo.RelAvatarLink(), "", size, class, repo.FullName())
 }
 
 // AvatarByEmail renders avatars by email address. args: email, name, size (int), class (string)
 func AvatarByEmail(email string, name string, others ...interface{}) template.HTML {
 	size, class := parseOthers(models.DefaultAvatarPixelSize, "ui avatar image", others...)
-	src := models.SizedAvatarLink(email, size*models.AvatarRenderedSizeFactor)
-
-	if src != "" {
-		return AvatarHTML(src, size, class, name)
-	}
-
-	return template.HTML("")
+	return avatarHTML(models.SizedAvatarLink(email, size), models.SizedAvatarLink(email, size*models.AvatarRenderedSizeFactor), size, class, name)
 }
 
 // Safe render raw as HTML
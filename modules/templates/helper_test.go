@@ -5,8 +5,13 @@
 package templates
 
 import (
+	"html/template"
+	"net/url"
 	"testing"
 
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -53,3 +58,29 @@ func TestSubjectBodySeparator(t *testing.T) {
 		"",
 		"Insuficient\n--\nSeparators")
 }
+
+func TestAvatar(t *testing.T) {
+	defer func(gravatarSourceURL *url.URL, disableGravatar bool) {
+		setting.GravatarSourceURL = gravatarSourceURL
+		setting.DisableGravatar = disableGravatar
+	}(setting.GravatarSourceURL, setting.DisableGravatar)
+	setting.GravatarSourceURL, _ = url.Parse("https://gravatar.example.com/avatar")
+	setting.DisableGravatar = false
+
+	user := &models.User{ID: 2, Name: "user2", FullName: "User <Two>", AvatarEmail: "user2@example.com"}
+	assert.Equal(t, template.HTML(`<img class="ui avatar image mr-2" `+
+		`src="https://gravatar.example.com/avatar/ab53a2911ddf9b4817ac01ddcd3d975f?d=identicon&amp;s=20" `+
+		`srcset="https://gravatar.example.com/avatar/ab53a2911ddf9b4817ac01ddcd3d975f?d=identicon&amp;s=20 1x, `+
+		`https://gravatar.example.com/avatar/ab53a2911ddf9b4817ac01ddcd3d975f?d=identicon&amp;s=40 2x" `+
+		`title="User &lt;Two&gt;" alt="User &lt;Two&gt;" width="20" height="20" loading="lazy"/>`), Avatar(user, 20, "mr-2"))
+
+	// the custom avatars are not sized
+	user.UseCustomAvatar = true
+	user.Avatar = "avatar2"
+	assert.Equal(t, template.HTML(`<img class="ui avatar image" src="/avatars/avatar2" title="User &lt;Two&gt;" alt="User &lt;Two&gt;" width="28" height="28" loading="lazy"/>`), Avatar(user))
+
+	// the authors without an account get the default avatar
+	assert.Equal(t, template.HTML(`<img class="ui avatar image" src="/img/avatar_default.png" title="original" alt="original" width="28" height="28" loading="lazy"/>`), Avatar("original"))
+
+	assert.Empty(t, Avatar(nil))
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package tracing exports the spans created through the OpenTelemetry API to an
+// OTLP/HTTP endpoint. Instrumented packages create their spans with otel.Tracer,
+// which does nothing until Init installs the tracer provider of this package.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlphttp"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/propagation"
+	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Init installs the tracer provider if tracing is enabled
+func Init() {
+	if !setting.Tracing.Enabled {
+		return
+	}
+
+	provider, err := newTracerProvider(context.Background())
+	if err != nil {
+		log.Fatal("Unable to create the tracer provider: %v", err)
+	}
+	graceful.GetManager().RunAtTerminate(context.Background(), func() {
+		// Export the spans left in the queue
+		if err := provider.Shutdown(context.Background()); err != nil {
+			log.Error("Unable to shut down the tracer provider: %v", err)
+		}
+	})
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	log.Info("Exporting traces to %s with a sample ratio of %v", setting.Tracing.Endpoint, setting.Tracing.SampleRatio)
+}
+
+// newTracerProvider creates a tracer provider exporting the spans in batches to the endpoint. New traces
+// are sampled by the ratio of their ID, the children of a span follow the decision of their parent.
+func newTracerProvider(ctx context.Context) (*sdktrace.TracerProvider, error) {
+	endpoint, err := url.Parse(setting.Tracing.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint %q: %v", setting.Tracing.Endpoint, err)
+	}
+	opts := []otlphttp.Option{
+		otlphttp.WithEndpoint(endpoint.Host),
+		otlphttp.WithTracesURLPath(endpoint.Path),
+		otlphttp.WithHeaders(setting.Tracing.HeaderMap),
+	}
+	if endpoint.Scheme != "https" {
+		opts = append(opts, otlphttp.WithInsecure())
+	}
+	exporter, err := otlp.NewExporter(ctx, otlphttp.NewDriver(opts...))
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := []label.KeyValue{
+		label.String("service.name", setting.Tracing.ServiceName),
+		label.String("service.version", setting.AppVer),
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		attributes = append(attributes, label.String("host.name", hostname))
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithConfig(sdktrace.Config{
+			DefaultSampler: sdktrace.ParentBased(sdktrace.TraceIDRatioBased(setting.Tracing.SampleRatio)),
+		}),
+		sdktrace.WithResource(resource.NewWithAttributes(attributes...)),
+		sdktrace.WithBatcher(&timeoutExporter{SpanExporter: exporter, timeout: setting.Tracing.ExportTimeout},
+			sdktrace.WithMaxExportBatchSize(setting.Tracing.BatchSize),
+			sdktrace.WithMaxQueueSize(setting.Tracing.QueueLength),
+			sdktrace.WithBatchTimeout(setting.Tracing.ExportInterval),
+		),
+	), nil
+}
+
+// timeoutExporter bounds the duration of each export, the batch span processor exports without a deadline
+type timeoutExporter struct {
+	exporttrace.SpanExporter
+	timeout time.Duration
+}
+
+// ExportSpans exports a batch of spans within the timeout
+func (e *timeoutExporter) ExportSpans(ctx context.Context, spans []*exporttrace.SpanSnapshot) error {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	return e.SpanExporter.ExportSpans(ctx, spans)
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// setTracing replaces the tracing settings for the duration of the test
+func setTracing(t *testing.T, endpoint string, sampleRatio float64) {
+	old := setting.Tracing
+	t.Cleanup(func() {
+		setting.Tracing = old
+	})
+	setting.Tracing.Endpoint = endpoint
+	setting.Tracing.SampleRatio = sampleRatio
+	setting.Tracing.HeaderMap = map[string]string{"Authorization": "Bearer token"}
+}
+
+func TestTracerProvider_Sampling(t *testing.T) {
+	setTracing(t, "http://localhost:4318/v1/traces", 1)
+	provider, err := newTracerProvider(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	tracer := provider.Tracer("test")
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	assert.True(t, parent.IsRecording())
+	assert.True(t, parent.SpanContext().IsSampled())
+	_, child := tracer.Start(ctx, "child")
+	assert.Equal(t, parent.SpanContext().TraceID, child.SpanContext().TraceID)
+	assert.NotEqual(t, parent.SpanContext().SpanID, child.SpanContext().SpanID)
+
+	// nothing is sampled with a ratio of 0, the children follow their parent
+	setting.Tracing.SampleRatio = 0
+	provider, err = newTracerProvider(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	tracer = provider.Tracer("test")
+	ctx, parent = tracer.Start(context.Background(), "parent")
+	assert.False(t, parent.SpanContext().IsSampled())
+	assert.True(t, parent.SpanContext().IsValid())
+	_, child = tracer.Start(ctx, "child")
+	assert.False(t, child.SpanContext().IsSampled())
+	assert.Equal(t, parent.SpanContext().TraceID, child.SpanContext().TraceID)
+
+	// a sampled remote parent is followed regardless of the ratio
+	remote := trace.SpanContext{
+		TraceID:    trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		SpanID:     trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+		TraceFlags: trace.FlagsSampled,
+	}
+	_, child = tracer.Start(trace.ContextWithRemoteSpanContext(context.Background(), remote), "child")
+	assert.True(t, child.SpanContext().IsSampled())
+	assert.Equal(t, remote.TraceID, child.SpanContext().TraceID)
+}
+
+func TestTracerProvider_Export(t *testing.T) {
+	var lock sync.Mutex
+	var path, contentType, authorization string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		path = r.URL.Path
+		contentType = r.Header.Get("Content-Type")
+		authorization = r.Header.Get("Authorization")
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	setTracing(t, server.URL+"/v1/traces", 1)
+	setting.Tracing.ExportInterval = time.Hour
+	provider, err := newTracerProvider(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, span := provider.Tracer("test").Start(context.Background(), "GET /{username}", trace.WithSpanKind(trace.SpanKindServer))
+	span.End()
+	// the spans left in the queue are exported on shutdown
+	assert.NoError(t, provider.Shutdown(context.Background()))
+
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, "/v1/traces", path)
+	assert.Equal(t, "application/x-protobuf", contentType)
+	assert.Equal(t, "Bearer token", authorization)
+	assert.True(t, bytes.Contains(body, []byte("GET /{username}")))
+	traceID := span.SpanContext().TraceID
+	assert.True(t, bytes.Contains(body, traceID[:]))
+}
@@ -0,0 +1,33 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"net"
+)
+
+var (
+	// anonymizedIPv4Mask keeps the network of an IPv4 address, zeroing its last byte
+	anonymizedIPv4Mask = net.CIDRMask(24, 32)
+	// anonymizedIPv6Mask keeps the site prefix of an IPv6 address, zeroing its last 10 bytes
+	anonymizedIPv6Mask = net.CIDRMask(48, 128)
+)
+
+// AnonymizeIP zeroes the host part of an IP address, optionally followed by a port which is removed, so that it no
+// longer identifies a client. Addresses which are not IP addresses, like the ones of unix sockets, are returned as is.
+func AnonymizeIP(addr string) string {
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return addr
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(anonymizedIPv4Mask).String()
+	}
+	return ip.Mask(anonymizedIPv6Mask).String()
+}
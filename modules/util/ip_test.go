@@ -0,0 +1,25 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonymizeIP(t *testing.T) {
+	for addr, expected := range map[string]string{
+		"192.168.1.42":                       "192.168.1.0",
+		"192.168.1.42:54321":                 "192.168.1.0",
+		"[2001:db8:85a3::8a2e:370:7334]:443": "2001:db8:85a3::",
+		"2001:db8:85a3::8a2e:370:7334":       "2001:db8:85a3::",
+		"::ffff:10.0.0.1":                    "10.0.0.0",
+		"@":                                  "@",
+		"":                                   "",
+	} {
+		assert.Equal(t, expected, AnonymizeIP(addr), addr)
+	}
+}
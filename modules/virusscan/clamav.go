@@ -0,0 +1,114 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package virusscan
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+)
+
+const clamAVChunkSize = 32 * 1024
+
+// ClamAV scans files using the INSTREAM command of clamd
+type ClamAV struct {
+	Network string
+	Address string
+}
+
+// NewClamAV creates a scanner for the clamd listening on addr,
+// e.g. tcp://localhost:3310 or unix:///run/clamav/clamd.ctl
+func NewClamAV(addr string) (*ClamAV, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "tcp":
+		return &ClamAV{Network: "tcp", Address: u.Host}, nil
+	case "unix":
+		return &ClamAV{Network: "unix", Address: u.Path}, nil
+	default:
+		return nil, fmt.Errorf("unsupported clamd address %q", addr)
+	}
+}
+
+// Scan streams the content of r to clamd and returns its verdict
+func (c *ClamAV) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, c.Network, c.Address)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to clamd: %v", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, clamAVChunkSize+4)
+	for {
+		n, readErr := r.Read(buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf[:4], uint32(n))
+			if _, err := conn.Write(buf[:n+4]); err != nil {
+				// clamd closes the connection once the size limit is exceeded, its reply tells us why
+				if reply, replyErr := readClamAVReply(conn); replyErr == nil {
+					return nil, fmt.Errorf("clamd: %s", reply)
+				}
+				return nil, err
+			}
+		}
+		if readErr == io.EOF {
+			break
+		} else if readErr != nil {
+			return nil, readErr
+		}
+	}
+	// a zero length chunk marks the end of the stream
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return nil, err
+	}
+
+	reply, err := readClamAVReply(conn)
+	if err != nil {
+		return nil, err
+	}
+	return parseClamAVReply(reply)
+}
+
+func readClamAVReply(conn net.Conn) (string, error) {
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(reply, "\x00\n"), nil
+}
+
+// parseClamAVReply parses replies like "stream: OK" or "stream: Eicar-Signature FOUND"
+func parseClamAVReply(reply string) (*Result, error) {
+	reply = strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case reply == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return &Result{
+			Infected:  true,
+			Signature: strings.TrimSuffix(reply, " FOUND"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("clamd: %s", reply)
+	}
+}
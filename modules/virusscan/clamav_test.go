@@ -0,0 +1,101 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package virusscan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClamd accepts a single INSTREAM session and replies with reply if the received content contains a virus
+func fakeClamd(t *testing.T, virus string) (string, <-chan []byte) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	received := make(chan []byte, 1)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		br := bufio.NewReader(conn)
+		command, _ := br.ReadString(0)
+		assert.Equal(t, "zINSTREAM\x00", command)
+
+		var content bytes.Buffer
+		for {
+			var size uint32
+			if err := binary.Read(br, binary.BigEndian, &size); err != nil {
+				return
+			}
+			if size == 0 {
+				break
+			}
+			if _, err := io.CopyN(&content, br, int64(size)); err != nil {
+				return
+			}
+		}
+		received <- content.Bytes()
+		if strings.Contains(content.String(), virus) {
+			_, _ = conn.Write([]byte("stream: " + virus + " FOUND\x00"))
+		} else {
+			_, _ = conn.Write([]byte("stream: OK\x00"))
+		}
+	}()
+	return "tcp://" + listener.Addr().String(), received
+}
+
+func TestClamAV_Scan(t *testing.T) {
+	addr, received := fakeClamd(t, "Eicar-Signature")
+	scanner, err := NewClamAV(addr)
+	assert.NoError(t, err)
+
+	content := strings.Repeat("clean content ", clamAVChunkSize/4)
+	result, err := scanner.Scan(context.Background(), strings.NewReader(content))
+	assert.NoError(t, err)
+	assert.False(t, result.Infected)
+	assert.Equal(t, content, string(<-received))
+
+	addr, _ = fakeClamd(t, "Eicar-Signature")
+	scanner, err = NewClamAV(addr)
+	assert.NoError(t, err)
+	result, err = scanner.Scan(context.Background(), strings.NewReader("some Eicar-Signature content"))
+	assert.NoError(t, err)
+	assert.True(t, result.Infected)
+	assert.Equal(t, "Eicar-Signature", result.Signature)
+}
+
+func TestNewClamAV(t *testing.T) {
+	scanner, err := NewClamAV("unix:///run/clamav/clamd.ctl")
+	assert.NoError(t, err)
+	assert.Equal(t, "unix", scanner.Network)
+	assert.Equal(t, "/run/clamav/clamd.ctl", scanner.Address)
+
+	_, err = NewClamAV("http://localhost:3310")
+	assert.Error(t, err)
+}
+
+func TestParseClamAVReply(t *testing.T) {
+	result, err := parseClamAVReply("stream: OK")
+	assert.NoError(t, err)
+	assert.False(t, result.Infected)
+
+	result, err = parseClamAVReply("stream: Win.Test.EICAR_HDB-1 FOUND")
+	assert.NoError(t, err)
+	assert.True(t, result.Infected)
+	assert.Equal(t, "Win.Test.EICAR_HDB-1", result.Signature)
+
+	_, err = parseClamAVReply("INSTREAM size limit exceeded. ERROR")
+	assert.Error(t, err)
+}
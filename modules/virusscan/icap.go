@@ -0,0 +1,126 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package virusscan
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http/httputil"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// encapsulatedHTTPHeader is the response header which is sent to the ICAP server in front of the file
+const encapsulatedHTTPHeader = "HTTP/1.1 200 OK\r\nContent-Type: application/octet-stream\r\nTransfer-Encoding: chunked\r\n\r\n"
+
+// ICAP scans files using a RESPMOD request to an ICAP (RFC 3507) service
+type ICAP struct {
+	URL *url.URL
+}
+
+// NewICAP creates a scanner for the ICAP service at rawURL, e.g. icap://localhost:1344/avscan
+func NewICAP(rawURL string) (*ICAP, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "icap" {
+		return nil, fmt.Errorf("unsupported ICAP URL %q", rawURL)
+	}
+	if u.Port() == "" {
+		u.Host = net.JoinHostPort(u.Hostname(), "1344")
+	}
+	return &ICAP{URL: u}, nil
+}
+
+// Scan sends the content of r to the ICAP service and returns its verdict
+func (c *ICAP) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", c.URL.Host)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to ICAP service: %v", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	w := bufio.NewWriter(conn)
+	fmt.Fprintf(w, "RESPMOD %s ICAP/1.0\r\n", c.URL.String())
+	fmt.Fprintf(w, "Host: %s\r\n", c.URL.Host)
+	fmt.Fprintf(w, "Allow: 204\r\n")
+	fmt.Fprintf(w, "Encapsulated: res-hdr=0, res-body=%d\r\n\r\n", len(encapsulatedHTTPHeader))
+	_, _ = w.WriteString(encapsulatedHTTPHeader)
+
+	chunked := httputil.NewChunkedWriter(w)
+	if _, err := io.Copy(chunked, r); err != nil {
+		return nil, err
+	}
+	if err := chunked.Close(); err != nil {
+		return nil, err
+	}
+	_, _ = w.WriteString("\r\n")
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return readICAPResponse(bufio.NewReader(conn))
+}
+
+func readICAPResponse(br *bufio.Reader) (*Result, error) {
+	tp := textproto.NewReader(br)
+	statusLine, err := tp.ReadLine()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read ICAP response: %v", err)
+	}
+	parts := strings.SplitN(statusLine, " ", 3)
+	if len(parts) < 2 || !strings.HasPrefix(parts[0], "ICAP/") {
+		return nil, fmt.Errorf("invalid ICAP response: %q", statusLine)
+	}
+	status, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid ICAP response: %q", statusLine)
+	}
+	header, err := tp.ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("unable to read ICAP response headers: %v", err)
+	}
+
+	switch status {
+	case 204:
+		// the content was not modified, i.e. it is clean
+		return &Result{}, nil
+	case 200:
+		// the service replaced the content, which it only does if it found something
+		return &Result{
+			Infected:  true,
+			Signature: icapSignature(header),
+		}, nil
+	default:
+		return nil, fmt.Errorf("ICAP service: %s", statusLine)
+	}
+}
+
+// icapSignature extracts the name of the virus from the headers commonly sent by ICAP services
+func icapSignature(header textproto.MIMEHeader) string {
+	if id := strings.TrimSpace(header.Get("X-Virus-ID")); id != "" {
+		return id
+	}
+	// X-Infection-Found: Type=0; Resolution=2; Threat=Eicar-Signature;
+	for _, field := range strings.Split(header.Get("X-Infection-Found"), ";") {
+		field = strings.TrimSpace(field)
+		if strings.HasPrefix(field, "Threat=") {
+			return strings.TrimPrefix(field, "Threat=")
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package virusscan
+
+import (
+	"bufio"
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http/httputil"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeICAP accepts a single RESPMOD request and replies with response
+func fakeICAP(t *testing.T, response string) (string, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	received := make(chan string, 1)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewReader(bufio.NewReader(conn))
+		requestLine, _ := tp.ReadLine()
+		assert.True(t, strings.HasPrefix(requestLine, "RESPMOD icap://"))
+		header, _ := tp.ReadMIMEHeader()
+		assert.Contains(t, header.Get("Encapsulated"), "res-body=")
+		// the encapsulated HTTP response header
+		_, _ = tp.ReadLine()
+		_, _ = tp.ReadMIMEHeader()
+		body, _ := ioutil.ReadAll(httputil.NewChunkedReader(tp.R))
+		received <- string(body)
+		_, _ = conn.Write([]byte(response))
+	}()
+	return "icap://" + listener.Addr().String() + "/avscan", received
+}
+
+func TestICAP_Scan(t *testing.T) {
+	addr, received := fakeICAP(t, "ICAP/1.0 204 No Content\r\nISTag: \"1\"\r\n\r\n")
+	scanner, err := NewICAP(addr)
+	assert.NoError(t, err)
+	result, err := scanner.Scan(context.Background(), strings.NewReader("clean content"))
+	assert.NoError(t, err)
+	assert.False(t, result.Infected)
+	assert.Equal(t, "clean content", <-received)
+
+	addr, _ = fakeICAP(t, "ICAP/1.0 200 OK\r\nX-Infection-Found: Type=0; Resolution=2; Threat=Eicar-Signature;\r\nEncapsulated: null-body=0\r\n\r\n")
+	scanner, err = NewICAP(addr)
+	assert.NoError(t, err)
+	result, err = scanner.Scan(context.Background(), strings.NewReader("infected content"))
+	assert.NoError(t, err)
+	assert.True(t, result.Infected)
+	assert.Equal(t, "Eicar-Signature", result.Signature)
+
+	addr, _ = fakeICAP(t, "ICAP/1.0 500 Server Error\r\n\r\n")
+	scanner, err = NewICAP(addr)
+	assert.NoError(t, err)
+	_, err = scanner.Scan(context.Background(), strings.NewReader("content"))
+	assert.Error(t, err)
+}
+
+func TestNewICAP(t *testing.T) {
+	scanner, err := NewICAP("icap://scanner/avscan")
+	assert.NoError(t, err)
+	assert.Equal(t, "scanner:1344", scanner.URL.Host)
+
+	_, err = NewICAP("http://scanner/avscan")
+	assert.Error(t, err)
+}
@@ -0,0 +1,235 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package virusscan
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+)
+
+// Result is the verdict of a virus scanner
+type Result struct {
+	Infected  bool
+	Signature string
+}
+
+// Scanner scans the content read from a reader for viruses
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (*Result, error)
+}
+
+// ErrVirusFound represents a "VirusFound" kind of error.
+type ErrVirusFound struct {
+	Name      string
+	Signature string
+}
+
+// IsErrVirusFound checks if an error is a ErrVirusFound.
+func IsErrVirusFound(err error) bool {
+	_, ok := err.(ErrVirusFound)
+	return ok
+}
+
+func (err ErrVirusFound) Error() string {
+	return fmt.Sprintf("virus found in %s: %s", err.Name, err.Signature)
+}
+
+// Upload describes a file uploaded by a user
+type Upload struct {
+	Kind       models.VirusDetectionKind
+	Name       string
+	UploaderID int64
+	RepoID     int64
+
+	// Storage and Path locate the stored file so that it can be quarantined
+	Storage storage.ObjectStorage
+	Path    string
+	// Remove deletes the stored file and everything referencing it
+	Remove func() error
+}
+
+func newScanner() (Scanner, error) {
+	switch setting.VirusScan.Backend {
+	case setting.VirusScanBackendClamAV:
+		return NewClamAV(setting.VirusScan.ClamAVAddr)
+	case setting.VirusScanBackendICAP:
+		return NewICAP(setting.VirusScan.ICAPURL)
+	default:
+		return nil, fmt.Errorf("unsupported virus scan backend %q", setting.VirusScan.Backend)
+	}
+}
+
+// Store saves the content of r using store whilst scanning it for viruses.
+// store may fill in the Path and Remove of the upload once it is stored.
+// If a virus is found the detection is recorded and depending on [virus_scan] ACTION the upload is
+// removed (reject), moved to the quarantine directory (quarantine) or kept (flag).
+// ErrVirusFound is returned if the upload has been removed.
+func Store(ctx context.Context, upload *Upload, r io.Reader, store func(io.Reader) error) error {
+	if !setting.VirusScan.Enabled {
+		return store(r)
+	}
+
+	scanner, err := newScanner()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, setting.VirusScan.Timeout)
+	defer cancel()
+
+	result, scanErr, err := scanWhile(ctx, scanner, r, store)
+	if err != nil {
+		return err
+	}
+	if scanErr != nil {
+		log.Error("Unable to scan %s %q for viruses: %v", upload.Kind, upload.Name, scanErr)
+		if !setting.VirusScan.RejectOnError {
+			return nil
+		}
+		removeUpload(upload)
+		return fmt.Errorf("unable to scan %s for viruses", upload.Name)
+	}
+	if !result.Infected {
+		return nil
+	}
+	return handleDetection(upload, result)
+}
+
+// scanWhile stores r using store whilst scanner reads the same content.
+// It returns the result and error of the scan and the error of store.
+func scanWhile(ctx context.Context, scanner Scanner, r io.Reader, store func(io.Reader) error) (*Result, error, error) {
+	pr, pw := io.Pipe()
+
+	type outcome struct {
+		result *Result
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := scanner.Scan(ctx, pr)
+		// keep reading so that storing is never blocked by a scanner which gave up
+		_, _ = io.Copy(ioutil.Discard, pr)
+		done <- outcome{result, err}
+	}()
+
+	storeErr := store(io.TeeReader(r, pw))
+	// a nil error signals EOF to the scanner
+	_ = pw.CloseWithError(storeErr)
+	scanned := <-done
+	if storeErr == nil && scanned.err == nil && scanned.result == nil {
+		scanned.err = fmt.Errorf("scanner returned no result")
+	}
+	return scanned.result, scanned.err, storeErr
+}
+
+func handleDetection(upload *Upload, result *Result) error {
+	log.Warn("Virus %q found in %s %q uploaded by user %d", result.Signature, upload.Kind, upload.Name, upload.UploaderID)
+
+	detection := &models.VirusDetection{
+		Kind:       upload.Kind,
+		Name:       upload.Name,
+		Signature:  result.Signature,
+		Action:     setting.VirusScan.Action,
+		UploaderID: upload.UploaderID,
+		RepoID:     upload.RepoID,
+		Path:       upload.Path,
+	}
+	if err := models.CreateVirusDetection(detection); err != nil {
+		log.Error("CreateVirusDetection: %v", err)
+	}
+
+	switch setting.VirusScan.Action {
+	case setting.VirusScanActionFlag:
+		return nil
+	case setting.VirusScanActionQuarantine:
+		quarantinePath, err := quarantine(upload, detection.ID)
+		if err != nil {
+			log.Error("Unable to quarantine %s %q: %v", upload.Kind, upload.Name, err)
+		} else if detection.ID > 0 {
+			detection.Path = quarantinePath
+			if err := models.UpdateVirusDetectionPath(detection); err != nil {
+				log.Error("UpdateVirusDetectionPath: %v", err)
+			}
+		}
+	}
+	removeUpload(upload)
+
+	return ErrVirusFound{
+		Name:      upload.Name,
+		Signature: result.Signature,
+	}
+}
+
+// quarantine copies the stored upload into the quarantine directory.
+// The file is named after the detection so that no user provided name ends up in the path.
+func quarantine(upload *Upload, detectionID int64) (string, error) {
+	if upload.Storage == nil || upload.Path == "" {
+		return "", fmt.Errorf("the location of the upload is unknown")
+	}
+	if err := os.MkdirAll(setting.VirusScan.QuarantinePath, 0700); err != nil {
+		return "", err
+	}
+
+	src, err := upload.Storage.Open(upload.Path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	quarantinePath := filepath.Join(setting.VirusScan.QuarantinePath, fmt.Sprintf("%d-%s", detectionID, upload.Kind))
+	dst, err := os.OpenFile(quarantinePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return "", err
+	}
+	return quarantinePath, dst.Close()
+}
+
+func removeUpload(upload *Upload) {
+	if upload.Remove == nil {
+		return
+	}
+	if err := upload.Remove(); err != nil {
+		log.Error("Unable to remove %s %q: %v", upload.Kind, upload.Name, err)
+	}
+}
+
+// NewAttachment creates an attachment like models.NewAttachment whilst scanning it for viruses
+func NewAttachment(ctx context.Context, kind models.VirusDetectionKind, repoID int64, attach *models.Attachment, buf []byte, file io.Reader) (*models.Attachment, error) {
+	upload := &Upload{
+		Kind:       kind,
+		Name:       attach.Name,
+		UploaderID: attach.UploaderID,
+		RepoID:     repoID,
+		Storage:    storage.Attachments,
+	}
+	err := Store(ctx, upload, io.MultiReader(bytes.NewReader(buf), file), func(r io.Reader) error {
+		var err error
+		if attach, err = models.NewAttachment(attach, nil, r); err != nil {
+			return err
+		}
+		upload.Path = attach.RelativePath()
+		upload.Remove = func() error {
+			return models.DeleteAttachment(attach, true)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attach, nil
+}
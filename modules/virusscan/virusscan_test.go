@@ -0,0 +1,61 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package virusscan
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type scannerFunc func(ctx context.Context, r io.Reader) (*Result, error)
+
+func (f scannerFunc) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	return f(ctx, r)
+}
+
+func TestScanWhile(t *testing.T) {
+	content := strings.Repeat("content", 10000)
+
+	var scanned, stored bytes.Buffer
+	store := func(r io.Reader) error {
+		_, err := io.Copy(&stored, r)
+		return err
+	}
+	result, scanErr, storeErr := scanWhile(context.Background(), scannerFunc(func(ctx context.Context, r io.Reader) (*Result, error) {
+		_, err := io.Copy(&scanned, r)
+		return &Result{Infected: true, Signature: "test"}, err
+	}), strings.NewReader(content), store)
+	assert.NoError(t, scanErr)
+	assert.NoError(t, storeErr)
+	assert.True(t, result.Infected)
+	assert.Equal(t, content, scanned.String())
+	assert.Equal(t, content, stored.String())
+
+	// a scanner which gives up must not block storing
+	stored.Reset()
+	_, scanErr, storeErr = scanWhile(context.Background(), scannerFunc(func(ctx context.Context, r io.Reader) (*Result, error) {
+		return nil, errors.New("unavailable")
+	}), strings.NewReader(content), store)
+	assert.Error(t, scanErr)
+	assert.NoError(t, storeErr)
+	assert.Equal(t, content, stored.String())
+
+	// a failed store is reported to the scanner
+	_, scanErr, storeErr = scanWhile(context.Background(), scannerFunc(func(ctx context.Context, r io.Reader) (*Result, error) {
+		_, err := io.Copy(ioutil.Discard, r)
+		return &Result{}, err
+	}), strings.NewReader(content), func(r io.Reader) error {
+		return errors.New("disk full")
+	})
+	assert.Error(t, scanErr)
+	assert.EqualError(t, storeErr, "disk full")
+}
@@ -33,10 +33,11 @@ import (
 )
 
 const (
-	tplDashboard base.TplName = "admin/dashboard"
-	tplConfig    base.TplName = "admin/config"
-	tplMonitor   base.TplName = "admin/monitor"
-	tplQueue     base.TplName = "admin/queue"
+	tplDashboard       base.TplName = "admin/dashboard"
+	tplConfig          base.TplName = "admin/config"
+	tplConfigEffective base.TplName = "admin/config_effective"
+	tplMonitor         base.TplName = "admin/monitor"
+	tplQueue           base.TplName = "admin/queue"
 )
 
 var sysStatus struct {
@@ -128,6 +129,7 @@ func Dashboard(ctx *context.Context) {
 	updateSystemStatus()
 	ctx.Data["SysStatus"] = sysStatus
 	ctx.Data["SSH"] = setting.SSH
+	ctx.Data["ConfigWarnings"] = setting.ValidateConfig()
 	ctx.HTML(200, tplDashboard)
 }
 
@@ -318,6 +320,22 @@ func Config(ctx *context.Context) {
 	ctx.HTML(200, tplConfig)
 }
 
+// EffectiveConfig shows the values of all settings used by the instance and where they come from
+func EffectiveConfig(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.config.effective")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminConfig"] = true
+
+	source := setting.ConfigSource(ctx.Query("source"))
+	keyword := strings.TrimSpace(ctx.Query("q"))
+	ctx.Data["Source"] = source
+	ctx.Data["Keyword"] = keyword
+	ctx.Data["CustomConf"] = setting.CustomConf
+	ctx.Data["Values"] = setting.FilterEffectiveConfig(setting.EffectiveConfig(), source, keyword)
+
+	ctx.HTML(200, tplConfigEffective)
+}
+
 // Monitor show admin monitor page
 func Monitor(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("admin.monitor")
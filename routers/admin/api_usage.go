@@ -0,0 +1,35 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+)
+
+const (
+	tplAPIUsage base.TplName = "admin/api_usage"
+
+	// apiUsageTopConsumers is the number of access tokens and OAuth2 applications listed by the API usage page
+	apiUsageTopConsumers = 50
+)
+
+// APIUsage shows the access tokens and OAuth2 applications which made the most API requests recently
+func APIUsage(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.api_usage")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminAPIUsage"] = true
+
+	consumers, err := models.GetTopAPIConsumers(apiUsageTopConsumers)
+	if err != nil {
+		ctx.ServerError("GetTopAPIConsumers", err)
+		return
+	}
+	ctx.Data["Consumers"] = consumers
+	ctx.Data["APIUsageDays"] = models.APIUsageDays
+
+	ctx.HTML(200, tplAPIUsage)
+}
@@ -7,14 +7,18 @@ package admin
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
+	"strings"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/auth/ldap"
 	"code.gitea.io/gitea/modules/auth/oauth2"
 	"code.gitea.io/gitea/modules/auth/pam"
+	"code.gitea.io/gitea/modules/auth/saml"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/cron"
 	auth "code.gitea.io/gitea/modules/forms"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -25,9 +29,10 @@ import (
 )
 
 const (
-	tplAuths    base.TplName = "admin/auth/list"
-	tplAuthNew  base.TplName = "admin/auth/new"
-	tplAuthEdit base.TplName = "admin/auth/edit"
+	tplAuths           base.TplName = "admin/auth/list"
+	tplAuthNew         base.TplName = "admin/auth/new"
+	tplAuthEdit        base.TplName = "admin/auth/edit"
+	tplAuthSyncPreview base.TplName = "admin/auth/sync_preview"
 )
 
 var (
@@ -65,6 +70,7 @@ var (
 			{models.LoginNames[models.LoginSMTP], models.LoginSMTP},
 			{models.LoginNames[models.LoginOAuth2], models.LoginOAuth2},
 			{models.LoginNames[models.LoginSSPI], models.LoginSSPI},
+			{models.LoginNames[models.LoginSAML], models.LoginSAML},
 		}
 		if pam.Supported {
 			items = append(items, dropdownItem{models.LoginNames[models.LoginPAM], models.LoginPAM})
@@ -72,6 +78,12 @@ var (
 		return items
 	}()
 
+	samlNameIDFormats = []string{
+		saml.NameIDFormatUnspecified,
+		saml.NameIDFormatPersistent,
+		saml.NameIDFormatEmail,
+	}
+
 	securityProtocols = []dropdownItem{
 		{models.SecurityProtocolNames[ldap.SecurityProtocolUnencrypted], ldap.SecurityProtocolUnencrypted},
 		{models.SecurityProtocolNames[ldap.SecurityProtocolLDAPS], ldap.SecurityProtocolLDAPS},
@@ -102,6 +114,7 @@ func NewAuthSource(ctx *context.Context) {
 	ctx.Data["SSPIStripDomainNames"] = true
 	ctx.Data["SSPISeparatorReplacement"] = "_"
 	ctx.Data["SSPIDefaultLanguage"] = ""
+	ctx.Data["SAMLNameIDFormats"] = samlNameIDFormats
 
 	// only the first as default
 	for key := range models.OAuth2Providers {
@@ -112,12 +125,12 @@ func NewAuthSource(ctx *context.Context) {
 	ctx.HTML(200, tplAuthNew)
 }
 
-func parseLDAPConfig(form auth.AuthenticationForm) *models.LDAPConfig {
+func parseLDAPConfig(ctx *context.Context, form auth.AuthenticationForm) (*models.LDAPConfig, error) {
 	var pageSize uint32
 	if form.UsePagedSearch {
 		pageSize = uint32(form.SearchPageSize)
 	}
-	return &models.LDAPConfig{
+	config := &models.LDAPConfig{
 		Source: &ldap.Source{
 			Name:                  form.Name,
 			Host:                  form.Host,
@@ -139,14 +152,26 @@ func parseLDAPConfig(form auth.AuthenticationForm) *models.LDAPConfig {
 			GroupsEnabled:         form.GroupsEnabled,
 			GroupDN:               form.GroupDN,
 			GroupFilter:           form.GroupFilter,
+			NestedGroupFilter:     strings.TrimSpace(form.NestedGroupFilter),
 			GroupMemberUID:        form.GroupMemberUID,
 			UserUID:               form.UserUID,
+			GroupTeamMap:          strings.TrimSpace(form.GroupTeamMap),
+			GroupTeamMapRemoval:   form.GroupTeamMapRemoval,
 			AdminFilter:           form.AdminFilter,
 			RestrictedFilter:      form.RestrictedFilter,
 			AllowDeactivateAll:    form.AllowDeactivateAll,
 			Enabled:               true,
 		},
 	}
+	if _, err := config.TeamMapping(); err != nil {
+		ctx.Data["Err_GroupTeamMap"] = true
+		return nil, errors.New(ctx.Tr("admin.auths.group_team_map_invalid", err.Error()))
+	}
+	if config.NestedGroupFilter != "" && !strings.Contains(config.NestedGroupFilter, "%s") {
+		ctx.Data["Err_NestedGroupFilter"] = true
+		return nil, errors.New(ctx.Tr("admin.auths.nested_group_filter_invalid"))
+	}
+	return config, nil
 }
 
 func parseSMTPConfig(form auth.AuthenticationForm) *models.SMTPConfig {
@@ -206,6 +231,31 @@ func parseSSPIConfig(ctx *context.Context, form auth.AuthenticationForm) (*model
 	}, nil
 }
 
+func parseSAMLConfig(ctx *context.Context, form auth.AuthenticationForm) (*models.SAMLConfig, error) {
+	if form.IsActive && !saml.Available(setting.AppURL) {
+		return nil, errors.New(ctx.Tr("admin.auths.saml_requires_https"))
+	}
+	config := &models.SAMLConfig{
+		Source: &saml.Source{
+			IdentityProviderEntityID:    strings.TrimSpace(form.SAMLIdentityProviderEntityID),
+			IdentityProviderSSOURL:      strings.TrimSpace(form.SAMLIdentityProviderURL),
+			IdentityProviderCertificate: strings.TrimSpace(form.SAMLIdentityProviderCert),
+			ServiceProviderEntityID:     strings.TrimSpace(form.SAMLServiceProviderEntityID),
+			NameIDFormat:                form.SAMLNameIDFormat,
+			AttributeUsername:           strings.TrimSpace(form.SAMLAttributeUsername),
+			AttributeEmail:              strings.TrimSpace(form.SAMLAttributeEmail),
+			AttributeFullName:           strings.TrimSpace(form.SAMLAttributeFullName),
+			AttributeGroups:             strings.TrimSpace(form.SAMLAttributeGroups),
+			GroupTeamMap:                strings.TrimSpace(form.SAMLGroupTeamMap),
+			GroupTeamMapRemoval:         form.SAMLGroupTeamMapRemoval,
+		},
+	}
+	if err := config.Validate(); err != nil {
+		return nil, errors.New(ctx.Tr("admin.auths.saml_invalid_config", err.Error()))
+	}
+	return config, nil
+}
+
 // NewAuthSourcePost response for adding an auth source
 func NewAuthSourcePost(ctx *context.Context) {
 	form := *web.GetForm(ctx).(*auth.AuthenticationForm)
@@ -226,12 +276,18 @@ func NewAuthSourcePost(ctx *context.Context) {
 	ctx.Data["SSPIStripDomainNames"] = true
 	ctx.Data["SSPISeparatorReplacement"] = "_"
 	ctx.Data["SSPIDefaultLanguage"] = ""
+	ctx.Data["SAMLNameIDFormats"] = samlNameIDFormats
 
 	hasTLS := false
 	var config convert.Conversion
 	switch models.LoginType(form.Type) {
 	case models.LoginLDAP, models.LoginDLDAP:
-		config = parseLDAPConfig(form)
+		var err error
+		config, err = parseLDAPConfig(ctx, form)
+		if err != nil {
+			ctx.RenderWithErr(err.Error(), tplAuthNew, form)
+			return
+		}
 		hasTLS = ldap.SecurityProtocol(form.SecurityProtocol) > ldap.SecurityProtocolUnencrypted
 	case models.LoginSMTP:
 		config = parseSMTPConfig(form)
@@ -255,6 +311,13 @@ func NewAuthSourcePost(ctx *context.Context) {
 			ctx.RenderWithErr(ctx.Tr("admin.auths.login_source_of_type_exist"), tplAuthNew, form)
 			return
 		}
+	case models.LoginSAML:
+		var err error
+		config, err = parseSAMLConfig(ctx, form)
+		if err != nil {
+			ctx.RenderWithErr(err.Error(), tplAuthNew, form)
+			return
+		}
 	default:
 		ctx.Error(400)
 		return
@@ -298,6 +361,7 @@ func EditAuthSource(ctx *context.Context) {
 	ctx.Data["SMTPAuths"] = models.SMTPAuths
 	ctx.Data["OAuth2Providers"] = models.OAuth2Providers
 	ctx.Data["OAuth2DefaultCustomURLMappings"] = models.OAuth2DefaultCustomURLMappings
+	ctx.Data["SAMLNameIDFormats"] = samlNameIDFormats
 
 	source, err := models.GetLoginSourceByID(ctx.ParamsInt64(":authid"))
 	if err != nil {
@@ -306,6 +370,9 @@ func EditAuthSource(ctx *context.Context) {
 	}
 	ctx.Data["Source"] = source
 	ctx.Data["HasTLS"] = source.HasTLS()
+	if source.IsSAML() {
+		ctx.Data["SAMLServiceProviderURL"] = setting.AppURL + "user/saml/" + url.PathEscape(source.Name)
+	}
 
 	if source.IsOAuth2() {
 		ctx.Data["CurrentOAuth2Provider"] = models.OAuth2Providers[source.OAuth2().Provider]
@@ -323,6 +390,7 @@ func EditAuthSourcePost(ctx *context.Context) {
 	ctx.Data["SMTPAuths"] = models.SMTPAuths
 	ctx.Data["OAuth2Providers"] = models.OAuth2Providers
 	ctx.Data["OAuth2DefaultCustomURLMappings"] = models.OAuth2DefaultCustomURLMappings
+	ctx.Data["SAMLNameIDFormats"] = samlNameIDFormats
 
 	source, err := models.GetLoginSourceByID(ctx.ParamsInt64(":authid"))
 	if err != nil {
@@ -331,6 +399,9 @@ func EditAuthSourcePost(ctx *context.Context) {
 	}
 	ctx.Data["Source"] = source
 	ctx.Data["HasTLS"] = source.HasTLS()
+	if source.IsSAML() {
+		ctx.Data["SAMLServiceProviderURL"] = setting.AppURL + "user/saml/" + url.PathEscape(source.Name)
+	}
 
 	if ctx.HasError() {
 		ctx.HTML(200, tplAuthEdit)
@@ -340,7 +411,11 @@ func EditAuthSourcePost(ctx *context.Context) {
 	var config convert.Conversion
 	switch models.LoginType(form.Type) {
 	case models.LoginLDAP, models.LoginDLDAP:
-		config = parseLDAPConfig(form)
+		config, err = parseLDAPConfig(ctx, form)
+		if err != nil {
+			ctx.RenderWithErr(err.Error(), tplAuthEdit, form)
+			return
+		}
 	case models.LoginSMTP:
 		config = parseSMTPConfig(form)
 	case models.LoginPAM:
@@ -355,6 +430,12 @@ func EditAuthSourcePost(ctx *context.Context) {
 			ctx.RenderWithErr(err.Error(), tplAuthEdit, form)
 			return
 		}
+	case models.LoginSAML:
+		config, err = parseSAMLConfig(ctx, form)
+		if err != nil {
+			ctx.RenderWithErr(err.Error(), tplAuthEdit, form)
+			return
+		}
 	default:
 		ctx.Error(400)
 		return
@@ -379,6 +460,45 @@ func EditAuthSourcePost(ctx *context.Context) {
 	ctx.Redirect(setting.AppSubURL + "/admin/auths/" + fmt.Sprint(form.ID))
 }
 
+// SyncPreviewAuthSource renders the changes the synchronization of the users of an LDAP source would make
+func SyncPreviewAuthSource(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.auths.sync_preview")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminAuthentications"] = true
+
+	source, err := models.GetLoginSourceByID(ctx.ParamsInt64(":authid"))
+	if err != nil {
+		if models.IsErrLoginSourceNotExist(err) {
+			ctx.NotFound("GetLoginSourceByID", err)
+		} else {
+			ctx.ServerError("GetLoginSourceByID", err)
+		}
+		return
+	}
+	if !source.IsLDAP() {
+		ctx.NotFound("SyncPreviewAuthSource", nil)
+		return
+	}
+	ctx.Data["Source"] = source
+
+	// the existing users are updated unless the synchronization task is configured not to
+	updateExisting := true
+	if task := cron.GetTask("sync_external_users"); task != nil {
+		if config, ok := task.GetConfig().(*cron.UpdateExistingConfig); ok {
+			updateExisting = config.UpdateExisting
+		}
+	}
+
+	preview, err := models.PreviewSyncExternalUsers(source, updateExisting)
+	if err != nil {
+		log.Warn("PreviewSyncExternalUsers[%s]: %v", source.Name, err)
+		ctx.Data["PreviewError"] = err.Error()
+	} else {
+		ctx.Data["Preview"] = preview
+	}
+	ctx.HTML(200, tplAuthSyncPreview)
+}
+
 // DeleteAuthSource response for deleting an auth source
 func DeleteAuthSource(ctx *context.Context) {
 	source, err := models.GetLoginSourceByID(ctx.ParamsInt64(":authid"))
@@ -0,0 +1,62 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplMirrors base.TplName = "admin/mirrors"
+)
+
+// Mirrors shows the health of the pull mirrors, or of the push mirrors with type=push, the failing ones first
+func Mirrors(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.mirrors")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminMirrors"] = true
+
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	isPush := ctx.Query("type") == "push"
+	onlyFailing := ctx.QueryBool("failing")
+	opts := &models.SearchMirrorsOptions{
+		ListOptions: models.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.Admin.RepoPagingNum,
+		},
+		OnlyFailing: onlyFailing,
+	}
+
+	var total int64
+	var err error
+	if isPush {
+		ctx.Data["MirrorType"] = "push"
+		ctx.Data["PushMirrors"], total, err = models.SearchPushMirrors(opts)
+	} else {
+		ctx.Data["MirrorType"] = "pull"
+		ctx.Data["Mirrors"], total, err = models.SearchMirrors(opts)
+	}
+	if err != nil {
+		ctx.ServerError("SearchMirrors", err)
+		return
+	}
+	ctx.Data["IsPushMirrors"] = isPush
+	ctx.Data["OnlyFailing"] = onlyFailing
+	ctx.Data["FailureThreshold"] = setting.Mirror.FailureThreshold
+	ctx.Data["Total"] = total
+
+	pager := context.NewPagination(int(total), setting.UI.Admin.RepoPagingNum, page, 5)
+	pager.AddParam(ctx, "type", "MirrorType")
+	pager.AddParam(ctx, "failing", "OnlyFailing")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(200, tplMirrors)
+}
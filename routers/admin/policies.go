@@ -0,0 +1,128 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+)
+
+const (
+	tplPolicies   base.TplName = "admin/policy/list"
+	tplPolicyNew  base.TplName = "admin/policy/new"
+	tplPolicyView base.TplName = "admin/policy/view"
+)
+
+// Policies shows the versions of the terms of service and of the privacy policy
+func Policies(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.policies")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminPolicies"] = true
+
+	docs, err := models.GetPolicyDocuments()
+	if err != nil {
+		ctx.ServerError("GetPolicyDocuments", err)
+		return
+	}
+	ctx.Data["PolicyDocuments"] = docs
+
+	ctx.HTML(http.StatusOK, tplPolicies)
+}
+
+// NewPolicy renders the page to publish a new version of a policy document, prefilled with the current one
+func NewPolicy(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.policies.new")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminPolicies"] = true
+
+	kind, _ := models.PolicyDocumentKindFromName(ctx.Query("kind"))
+	doc := &models.PolicyDocument{Kind: kind}
+	docs, err := models.GetCurrentPolicyDocuments()
+	if err != nil {
+		ctx.ServerError("GetCurrentPolicyDocuments", err)
+		return
+	}
+	for _, current := range docs {
+		if current.Kind == kind {
+			doc.Title = current.Title
+			doc.Content = current.Content
+		}
+	}
+	ctx.Data["PolicyDocument"] = doc
+
+	ctx.HTML(http.StatusOK, tplPolicyNew)
+}
+
+// NewPolicyPost publishes a new version of a policy document
+func NewPolicyPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.AdminPolicyDocumentForm)
+	ctx.Data["Title"] = ctx.Tr("admin.policies.new")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminPolicies"] = true
+
+	doc := &models.PolicyDocument{
+		Kind:        models.PolicyDocumentKind(form.Kind),
+		Title:       form.Title,
+		Content:     form.Content,
+		PublisherID: ctx.User.ID,
+	}
+	ctx.Data["PolicyDocument"] = doc
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplPolicyNew)
+		return
+	}
+	if err := models.PublishPolicyDocument(doc); err != nil {
+		ctx.ServerError("PublishPolicyDocument", err)
+		return
+	}
+	log.Trace("Policy document published by admin(%s): %s version %d", ctx.User.Name, doc.Kind.Name(), doc.Version)
+
+	ctx.Flash.Success(ctx.Tr("admin.policies.publish_success", doc.Title, doc.Version))
+	ctx.Redirect(setting.AppSubURL + "/admin/policies")
+}
+
+// ViewPolicy shows a version of a policy document and the users who accepted it
+func ViewPolicy(ctx *context.Context) {
+	doc, err := models.GetPolicyDocumentByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrPolicyDocumentNotExist(err) {
+			ctx.NotFound("GetPolicyDocumentByID", err)
+		} else {
+			ctx.ServerError("GetPolicyDocumentByID", err)
+		}
+		return
+	}
+	ctx.Data["Title"] = doc.Title
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminPolicies"] = true
+
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	consents, total, err := models.GetPolicyDocumentConsents(doc.ID, models.ListOptions{
+		Page:     page,
+		PageSize: setting.UI.Admin.UserPagingNum,
+	})
+	if err != nil {
+		ctx.ServerError("GetPolicyDocumentConsents", err)
+		return
+	}
+	doc.RenderedContent = string(markdown.Render([]byte(doc.Content), setting.AppSubURL, map[string]string{"mode": "document"}))
+	ctx.Data["PolicyDocument"] = doc
+	ctx.Data["PolicyDocumentConsents"] = consents
+	ctx.Data["Total"] = total
+	ctx.Data["Page"] = context.NewPagination(int(total), setting.UI.Admin.UserPagingNum, page, 5)
+
+	ctx.HTML(http.StatusOK, tplPolicyView)
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+const (
+	tplProfileFields    base.TplName = "admin/profile_field/list"
+	tplProfileFieldEdit base.TplName = "admin/profile_field/edit"
+)
+
+// ProfileFields shows the profile fields of the users
+func ProfileFields(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.profile_fields")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminProfileFields"] = true
+
+	fields, err := models.GetProfileFields()
+	if err != nil {
+		ctx.ServerError("GetProfileFields", err)
+		return
+	}
+	ctx.Data["ProfileFields"] = fields
+
+	ctx.HTML(http.StatusOK, tplProfileFields)
+}
+
+// NewProfileField renders the page to add a profile field
+func NewProfileField(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.profile_fields.new")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminProfileFields"] = true
+	ctx.Data["PageIsNew"] = true
+	ctx.Data["ProfileField"] = &models.ProfileField{Visibility: structs.VisibleTypePublic}
+
+	ctx.HTML(http.StatusOK, tplProfileFieldEdit)
+}
+
+// NewProfileFieldPost adds a profile field
+func NewProfileFieldPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.AdminProfileFieldForm)
+	ctx.Data["Title"] = ctx.Tr("admin.profile_fields.new")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminProfileFields"] = true
+	ctx.Data["PageIsNew"] = true
+
+	field := &models.ProfileField{}
+	saveProfileField(ctx, form, field, models.CreateProfileField)
+}
+
+// EditProfileField renders the page to edit a profile field
+func EditProfileField(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.profile_fields.edit")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminProfileFields"] = true
+
+	field := getProfileField(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["ProfileField"] = field
+
+	ctx.HTML(http.StatusOK, tplProfileFieldEdit)
+}
+
+// EditProfileFieldPost updates a profile field
+func EditProfileFieldPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.AdminProfileFieldForm)
+	ctx.Data["Title"] = ctx.Tr("admin.profile_fields.edit")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminProfileFields"] = true
+
+	field := getProfileField(ctx)
+	if ctx.Written() {
+		return
+	}
+	saveProfileField(ctx, form, field, models.UpdateProfileField)
+}
+
+// DeleteProfileField deletes a profile field and the values of the users
+func DeleteProfileField(ctx *context.Context) {
+	field := getProfileField(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := models.DeleteProfileField(field.ID); err != nil {
+		ctx.ServerError("DeleteProfileField", err)
+		return
+	}
+	log.Trace("Profile field deleted by admin(%s): %s", ctx.User.Name, field.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.profile_fields.deletion_success"))
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/admin/profile-fields",
+	})
+}
+
+func getProfileField(ctx *context.Context) *models.ProfileField {
+	field, err := models.GetProfileFieldByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrProfileFieldNotExist(err) {
+			ctx.NotFound("GetProfileFieldByID", err)
+		} else {
+			ctx.ServerError("GetProfileFieldByID", err)
+		}
+		return nil
+	}
+	return field
+}
+
+// saveProfileField sets the form values to the field and creates or updates it with save
+func saveProfileField(ctx *context.Context, form *auth.AdminProfileFieldForm, field *models.ProfileField, save func(*models.ProfileField) error) {
+	field.Name = form.Name
+	field.Label = form.Label
+	field.Description = form.Description
+	field.Type = models.ProfileFieldType(form.Type)
+	field.Visibility = structs.VisibleType(form.Visibility)
+	field.IsRequired = form.IsRequired
+	field.SortOrder = form.SortOrder
+	ctx.Data["ProfileField"] = field
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplProfileFieldEdit)
+		return
+	}
+	if err := save(field); err != nil {
+		if models.IsErrProfileFieldAlreadyExist(err) {
+			ctx.Data["Err_Name"] = true
+			ctx.RenderWithErr(ctx.Tr("admin.profile_fields.name_been_taken", field.Name), tplProfileFieldEdit, form)
+			return
+		}
+		ctx.ServerError("SaveProfileField", err)
+		return
+	}
+	log.Trace("Profile field saved by admin(%s): %s", ctx.User.Name, field.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.profile_fields.update_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/profile-fields")
+}
@@ -32,9 +32,10 @@ func Repos(ctx *context.Context) {
 	ctx.Data["PageIsAdminRepositories"] = true
 
 	routers.RenderRepoSearch(ctx, &routers.RepoSearchOptions{
-		Private:  true,
-		PageSize: setting.UI.Admin.RepoPagingNum,
-		TplName:  tplRepos,
+		Private:    true,
+		PageSize:   setting.UI.Admin.RepoPagingNum,
+		TplName:    tplRepos,
+		LoadHealth: true,
 	})
 }
 
@@ -46,7 +47,7 @@ func DeleteRepo(ctx *context.Context) {
 		return
 	}
 
-	if err := repo_service.DeleteRepository(ctx.User, repo); err != nil {
+	if err := repo_service.DeleteRepository(ctx.Req.Context(), ctx.User, repo); err != nil {
 		ctx.ServerError("DeleteRepository", err)
 		return
 	}
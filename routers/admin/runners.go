@@ -0,0 +1,67 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplRunners base.TplName = "admin/runners"
+)
+
+// MustEnableActions check if actions are enabled in settings
+func MustEnableActions(ctx *context.Context) {
+	if !setting.Actions.Enabled {
+		ctx.NotFound("MustEnableActions", nil)
+	}
+}
+
+// Runners shows the registered actions runners and the token to register new ones
+func Runners(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.runners")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminRunners"] = true
+
+	runners, err := models.GetActionRunners()
+	if err != nil {
+		ctx.ServerError("GetActionRunners", err)
+		return
+	}
+	token, err := models.GetActionRunnerToken()
+	if err != nil {
+		ctx.ServerError("GetActionRunnerToken", err)
+		return
+	}
+
+	ctx.Data["Runners"] = runners
+	ctx.Data["RegistrationToken"] = token.Token
+	ctx.Data["RunnerOfflineTimeout"] = setting.Actions.RunnerOfflineTimeout
+	ctx.Data["RunnerAPIURL"] = setting.AppURL + "api/actions"
+	ctx.HTML(200, tplRunners)
+}
+
+// ResetRunnerToken replaces the registration token of runners
+func ResetRunnerToken(ctx *context.Context) {
+	if _, err := models.ResetActionRunnerToken(); err != nil {
+		ctx.ServerError("ResetActionRunnerToken", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("admin.runners.reset_token_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/runners")
+}
+
+// DeleteRunner deletes a runner
+func DeleteRunner(ctx *context.Context) {
+	if err := models.DeleteActionRunner(ctx.ParamsInt64("id")); err != nil {
+		ctx.ServerError("DeleteActionRunner", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("admin.runners.delete_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/runners")
+}
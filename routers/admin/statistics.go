@@ -0,0 +1,52 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+)
+
+const (
+	tplStatistics base.TplName = "admin/statistics"
+
+	statisticsPagingNum = 30
+)
+
+// Statistics show the storage and growth statistics of the instance
+func Statistics(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.statistics")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminStatistics"] = true
+
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	stats, total, err := models.GetStorageStatistics(models.ListOptions{
+		Page:     page,
+		PageSize: statisticsPagingNum,
+	})
+	if err != nil {
+		ctx.ServerError("GetStorageStatistics", err)
+		return
+	}
+	latest, err := models.GetLatestStorageStatistic()
+	if err != nil {
+		ctx.ServerError("GetLatestStorageStatistic", err)
+		return
+	}
+
+	ctx.Data["Latest"] = latest
+	ctx.Data["Statistics"] = stats
+	ctx.Data["Total"] = total
+	ctx.Data["Page"] = context.NewPagination(int(total), statisticsPagingNum, page, 5)
+
+	ctx.HTML(http.StatusOK, tplStatistics)
+}
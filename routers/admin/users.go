@@ -40,7 +40,8 @@ func Users(ctx *context.Context) {
 		ListOptions: models.ListOptions{
 			PageSize: setting.UI.Admin.UserPagingNum,
 		},
-		SearchByEmail: true,
+		SearchByEmail:         true,
+		SearchByProfileFields: true,
 	}, tplUsers)
 }
 
@@ -0,0 +1,68 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"strconv"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplVirusDetections base.TplName = "admin/virus_detections"
+)
+
+// VirusDetections shows the uploads in which the virus scanner found a virus
+func VirusDetections(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.virus_detections")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminVirusDetections"] = true
+
+	total, err := models.CountVirusDetections()
+	if err != nil {
+		ctx.ServerError("CountVirusDetections", err)
+		return
+	}
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	detections, err := models.VirusDetections(page, setting.UI.Admin.NoticePagingNum)
+	if err != nil {
+		ctx.ServerError("VirusDetections", err)
+		return
+	}
+	ctx.Data["VirusDetections"] = detections
+
+	ctx.Data["Total"] = total
+
+	ctx.Data["Page"] = context.NewPagination(int(total), setting.UI.Admin.NoticePagingNum, page, 5)
+
+	ctx.HTML(200, tplVirusDetections)
+}
+
+// DeleteVirusDetections deletes the specific virus detection records
+func DeleteVirusDetections(ctx *context.Context) {
+	strs := ctx.QueryStrings("ids[]")
+	ids := make([]int64, 0, len(strs))
+	for i := range strs {
+		id, _ := strconv.ParseInt(strs[i], 10, 64)
+		if id > 0 {
+			ids = append(ids, id)
+		}
+	}
+
+	if err := models.DeleteVirusDetectionsByIDs(ids); err != nil {
+		ctx.Flash.Error("DeleteVirusDetectionsByIDs: " + err.Error())
+		ctx.Status(500)
+	} else {
+		ctx.Flash.Success(ctx.Tr("admin.virus_detections.delete_success"))
+		ctx.Status(200)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package actions implements the HTTP API actions runners use to register,
+// fetch jobs and report their logs, artifacts and results.
+package actions
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/web"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// respondError writes an error message
+func respondError(ctx *context.PrivateContext, status int, message string) {
+	ctx.JSON(status, context.APIError{Message: message})
+}
+
+// serverError logs an internal error and writes an error message without details
+func serverError(ctx *context.PrivateContext, title string, err error) {
+	log.ErrorWithSkip(1, "%s: %v", title, err)
+	respondError(ctx, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+}
+
+// decode reads the JSON body of the request into obj
+func decode(ctx *context.PrivateContext, obj interface{}) bool {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.NewDecoder(ctx.Req.Body).Decode(obj); err != nil {
+		respondError(ctx, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+func getRunner(ctx *context.PrivateContext) *models.ActionRunner {
+	return ctx.Data["ActionRunner"].(*models.ActionRunner)
+}
+
+func getJob(ctx *context.PrivateContext) *models.ActionRunJob {
+	return ctx.Data["ActionRunJob"].(*models.ActionRunJob)
+}
+
+// reqRunner requires the token of a registered runner
+func reqRunner() func(ctx *context.PrivateContext) {
+	return func(ctx *context.PrivateContext) {
+		fields := strings.Fields(ctx.Req.Header.Get("Authorization"))
+		if len(fields) != 2 || fields[0] != "Bearer" {
+			ctx.Resp.Header().Set("WWW-Authenticate", `Bearer realm="actions"`)
+			respondError(ctx, http.StatusUnauthorized, "a runner token is required")
+			return
+		}
+		runner, err := models.GetActionRunnerByToken(fields[1])
+		if err != nil {
+			if models.IsErrActionRunnerNotExist(err) {
+				respondError(ctx, http.StatusUnauthorized, "invalid runner token")
+			} else {
+				serverError(ctx, "GetActionRunnerByToken", err)
+			}
+			return
+		}
+		ctx.Data["ActionRunner"] = runner
+	}
+}
+
+// reqRunnerJob requires the job in the path to be assigned to the runner
+func reqRunnerJob() func(ctx *context.PrivateContext) {
+	return func(ctx *context.PrivateContext) {
+		job, err := models.GetActionRunJobByID(ctx.ParamsInt64("id"))
+		if err != nil {
+			if models.IsErrActionRunJobNotExist(err) {
+				respondError(ctx, http.StatusNotFound, "job not found")
+			} else {
+				serverError(ctx, "GetActionRunJobByID", err)
+			}
+			return
+		}
+		if job.RunnerID != getRunner(ctx).ID {
+			respondError(ctx, http.StatusForbidden, "the job is not assigned to this runner")
+			return
+		}
+		ctx.Data["ActionRunJob"] = job
+	}
+}
+
+// Routes registers all routes of the actions runner API to web application.
+func Routes() *web.Route {
+	var m = web.NewRoute()
+	m.Use(context.PrivateContexter())
+
+	m.Post("/runner/register", Register)
+	m.Group("/runner", func() {
+		m.Post("/fetch", FetchTask)
+		m.Group("/jobs/{id}", func() {
+			m.Post("/logs", AppendLog)
+			m.Post("/status", UpdateStatus)
+			m.Put("/artifacts/{name}", UploadArtifact)
+		}, reqRunnerJob())
+	}, reqRunner())
+
+	return m
+}
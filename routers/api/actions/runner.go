@@ -0,0 +1,136 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	actions_service "code.gitea.io/gitea/services/actions"
+)
+
+// Register registers a runner with the registration token shown in the site administration
+func Register(ctx *context.PrivateContext) {
+	form := new(api.RegisterActionRunnerOption)
+	if !decode(ctx, form) {
+		return
+	}
+	form.Name = strings.TrimSpace(form.Name)
+	if form.Token == "" || form.Name == "" || len(form.Name) > 255 {
+		respondError(ctx, http.StatusUnprocessableEntity, "token and name are required")
+		return
+	}
+
+	runner, token, err := models.RegisterActionRunner(form.Token, form.Name, form.Labels)
+	if err != nil {
+		if models.IsErrActionRunnerNotExist(err) {
+			respondError(ctx, http.StatusUnauthorized, "invalid registration token")
+		} else {
+			serverError(ctx, "RegisterActionRunner", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, &api.ActionRunner{
+		ID:     runner.ID,
+		UUID:   runner.UUID,
+		Name:   runner.Name,
+		Labels: runner.Labels,
+		Token:  token,
+	})
+}
+
+// FetchTask assigns a waiting job to the runner.
+// It responds with 204 No Content if there is no job the runner can run.
+func FetchTask(ctx *context.PrivateContext) {
+	job, err := actions_service.FetchJob(getRunner(ctx))
+	if err != nil {
+		serverError(ctx, "FetchJob", err)
+		return
+	}
+	if job == nil {
+		ctx.Status(http.StatusNoContent)
+		return
+	}
+
+	run := job.Run
+	ctx.JSON(http.StatusOK, &api.ActionTask{
+		JobID:      job.ID,
+		RunID:      run.ID,
+		Name:       job.Name,
+		Workflow:   job.WorkflowPayload,
+		Repository: run.Repo.FullName(),
+		CloneURL:   run.Repo.CloneLink().HTTPS,
+		Ref:        run.Ref,
+		SHA:        run.CommitSHA,
+		Event:      run.Event,
+		Token:      job.Token,
+	})
+}
+
+// AppendLog appends the request body to the log of the job
+func AppendLog(ctx *context.PrivateContext) {
+	// the chunk is read first so that a rejected chunk is not appended in part
+	data, err := ioutil.ReadAll(http.MaxBytesReader(ctx.Resp, ctx.Req.Body, setting.Actions.MaxLogChunkSize))
+	if err != nil {
+		respondError(ctx, http.StatusRequestEntityTooLarge, fmt.Sprintf("the log chunk must not exceed %d bytes", setting.Actions.MaxLogChunkSize))
+		return
+	}
+	if err := actions_service.AppendJobLog(getJob(ctx), bytes.NewReader(data)); err != nil {
+		serverError(ctx, "AppendJobLog", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// UpdateStatus records the status of the job and responds with its current status
+func UpdateStatus(ctx *context.PrivateContext) {
+	form := new(api.UpdateActionTaskOption)
+	if !decode(ctx, form) {
+		return
+	}
+	status := models.ActionStatusFromString(form.Status)
+	switch status {
+	case models.ActionStatusRunning, models.ActionStatusSuccess, models.ActionStatusFailure:
+	default:
+		respondError(ctx, http.StatusUnprocessableEntity, "status must be running, success or failure")
+		return
+	}
+
+	job := getJob(ctx)
+	if err := actions_service.UpdateJobStatus(job, status); err != nil {
+		serverError(ctx, "UpdateJobStatus", err)
+		return
+	}
+	// the job may have been cancelled in the meantime
+	job, err := models.GetActionRunJobByID(job.ID)
+	if err != nil {
+		serverError(ctx, "GetActionRunJobByID", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &api.ActionTaskState{
+		Status: job.Status.String(),
+	})
+}
+
+// UploadArtifact stores the request body as an artifact of the run of the job
+func UploadArtifact(ctx *context.PrivateContext) {
+	name := ctx.Params("name")
+	if name == "" || len(name) > 255 || strings.ContainsAny(name, `/\`) {
+		respondError(ctx, http.StatusUnprocessableEntity, "invalid artifact name")
+		return
+	}
+	if _, err := actions_service.UploadArtifact(getJob(ctx), name, ctx.Req.Body); err != nil {
+		serverError(ctx, "UploadArtifact", err)
+		return
+	}
+	ctx.Status(http.StatusCreated)
+}
@@ -0,0 +1,143 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package packages implements the package registry endpoints which speak the native protocols of the package managers.
+package packages
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/maintenance"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/packages/container"
+	"code.gitea.io/gitea/routers/api/packages/maven"
+	"code.gitea.io/gitea/routers/api/packages/npm"
+	"code.gitea.io/gitea/routers/api/packages/nuget"
+	"code.gitea.io/gitea/routers/api/packages/pypi"
+
+	"gitea.com/go-chi/session"
+)
+
+func newRoute() *web.Route {
+	var m = web.NewRoute()
+
+	m.Use(session.Sessioner(session.Options{
+		Provider:       setting.SessionConfig.Provider,
+		ProviderConfig: setting.SessionConfig.ProviderConfig,
+		CookieName:     setting.SessionConfig.CookieName,
+		CookiePath:     setting.SessionConfig.CookiePath,
+		Gclifetime:     setting.SessionConfig.Gclifetime,
+		Maxlifetime:    setting.SessionConfig.Maxlifetime,
+		Secure:         setting.SessionConfig.Secure,
+		Domain:         setting.SessionConfig.Domain,
+	}))
+	m.Use(context.APIContexter())
+
+	if setting.EnableAccessLog {
+		m.Use(context.AccessLogger())
+	}
+	m.Use(checkCredentials)
+	m.Use(checkMaintenanceMode)
+	return m
+}
+
+// checkCredentials applies the rules of the API to the credentials: API tokens are accepted as they are,
+// a password sent with basic authentication requires the OTP of users with two-factor authentication,
+// and requests authenticated by the session cookie have to carry a CSRF token unless they only read.
+func checkCredentials(ctx *context.APIContext) {
+	if !ctx.IsSigned || true == ctx.Data["IsApiToken"] {
+		return
+	}
+	if ctx.Context.IsBasicAuth {
+		ctx.CheckForOTP()
+		return
+	}
+	if ctx.Req.Method != http.MethodGet && ctx.Req.Method != http.MethodHead {
+		ctx.RequireCSRF()
+	}
+}
+
+// checkMaintenanceMode rejects the requests of users who are no administrators while the instance is in maintenance mode
+func checkMaintenanceMode(ctx *context.APIContext) {
+	if !maintenance.IsEnabled() || (ctx.IsSigned && ctx.User.IsAdmin) {
+		return
+	}
+	message := "Gitea is in maintenance mode"
+	if msg := maintenance.GetState().Message; len(msg) > 0 {
+		message += ": " + msg
+	}
+	ctx.Resp.Header().Set("Retry-After", "300")
+	ctx.PlainText(http.StatusServiceUnavailable, []byte(message))
+}
+
+// Routes registers the routes of the npm, PyPI, Maven and NuGet registries to web application.
+func Routes() *web.Route {
+	m := newRoute()
+
+	m.Group("/{owner}", func() {
+		m.Group("/npm", func() {
+			m.Get("/{id}", npm.PackageMetadata)
+			m.Put("/{id}", npm.RequireWrite, npm.UploadPackage)
+			m.Get("/{id}/-/{filename}", npm.DownloadPackageFile)
+		}, npm.AssignOwner)
+
+		m.Group("/pypi", func() {
+			m.Post("/", pypi.RequireWrite, pypi.UploadPackageFile)
+			m.Get("/simple/{id}", pypi.PackageMetadata)
+			m.Get("/simple/{id}/", pypi.PackageMetadata)
+			m.Get("/files/{id}/{version}/{filename}", pypi.DownloadPackageFile)
+		}, pypi.AssignOwner)
+
+		m.Group("/maven", func() {
+			m.Get("/*", maven.DownloadPackageFile)
+			m.Head("/*", maven.DownloadPackageFile)
+			m.Put("/*", maven.RequireWrite, maven.UploadPackageFile)
+		}, maven.AssignOwner)
+
+		m.Group("/nuget", func() {
+			m.Get("/index.json", nuget.ServiceIndex)
+			m.Get("/query", nuget.SearchService)
+			m.Get("/registration/{id}/index.json", nuget.RegistrationIndex)
+			m.Get("/package/{id}/index.json", nuget.EnumeratePackageVersions)
+			m.Get("/package/{id}/{version}/{filename}", nuget.DownloadPackageFile)
+			m.Put("/", nuget.RequireWrite, nuget.UploadPackage)
+			m.Delete("/{id}/{version}", nuget.RequireWrite, nuget.DeletePackage)
+		}, nuget.AssignOwner)
+	})
+
+	return m
+}
+
+// ContainerRoutes registers the routes of the container registry to web application, it must be mounted at /v2.
+func ContainerRoutes() *web.Route {
+	m := newRoute()
+	m.Use(container.SetAPIVersion)
+
+	m.Get("/", container.ReqSignIn)
+	m.Group("/{owner}/{image}", func() {
+		m.Group("/blobs", func() {
+			m.Group("/uploads", func() {
+				m.Post("/", container.InitiateUpload)
+				m.Get("/{uuid}", container.GetUploadStatus)
+				m.Patch("/{uuid}", container.UploadChunk)
+				m.Put("/{uuid}", container.CompleteUpload)
+				m.Delete("/{uuid}", container.CancelUpload)
+			}, container.RequireWrite)
+			m.Head("/{digest}", container.GetBlob)
+			m.Get("/{digest}", container.GetBlob)
+			m.Delete("/{digest}", container.RequireWrite, container.DeleteBlob)
+		})
+		m.Group("/manifests/{reference}", func() {
+			m.Head("", container.GetManifest)
+			m.Get("", container.GetManifest)
+			m.Put("", container.RequireWrite, container.UploadManifest)
+			m.Delete("", container.RequireWrite, container.DeleteManifest)
+		})
+		m.Get("/tags/list", container.ListTags)
+	}, container.AssignOwner)
+
+	return m
+}
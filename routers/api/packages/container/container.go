@@ -0,0 +1,649 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package container implements the Docker Registry HTTP API V2 used by docker and OCI clients
+// to push and pull images.
+package container
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	defaultManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+
+	maxManifestSize = 4 * 1024 * 1024
+)
+
+var (
+	imageNameRegexp = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*$`)
+	tagRegexp       = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	digestRegexp    = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+	uuidRegexp      = regexp.MustCompile(`^[a-f0-9-]{36}$`)
+)
+
+// apiError writes an error in the format of the registry API
+func apiError(ctx *context.APIContext, status int, code, message string) {
+	ctx.JSON(status, map[string]interface{}{
+		"errors": []map[string]string{
+			{"code": code, "message": message},
+		},
+	})
+}
+
+func responder(ctx *context.APIContext) func(status int, message string) {
+	return func(status int, message string) {
+		code := "UNKNOWN"
+		switch status {
+		case http.StatusUnauthorized:
+			code = "UNAUTHORIZED"
+		case http.StatusForbidden:
+			code = "DENIED"
+		case http.StatusNotFound:
+			code = "NAME_UNKNOWN"
+		case http.StatusRequestEntityTooLarge:
+			code = "SIZE_INVALID"
+		}
+		apiError(ctx, status, code, message)
+	}
+}
+
+func serverError(ctx *context.APIContext, title string, err error) {
+	helper.ServerError(ctx, responder(ctx), title, err)
+}
+
+// SetAPIVersion is the middleware announcing the version of the registry API
+func SetAPIVersion(ctx *context.APIContext) {
+	ctx.Resp.Header().Set("Docker-Distribution-Api-Version", "registry/2.0")
+}
+
+// ReqSignIn checks the authentication, the clients use it to find out how to authenticate
+func ReqSignIn(ctx *context.APIContext) {
+	if !ctx.IsSigned {
+		helper.Unauthorized(ctx, responder(ctx))
+		return
+	}
+	ctx.JSON(http.StatusOK, map[string]string{})
+}
+
+// AssignOwner is the middleware assigning the owner of the image in the path and checking the image name
+func AssignOwner(ctx *context.APIContext) {
+	helper.AssignOwner(ctx, responder(ctx))
+	if ctx.Written() {
+		return
+	}
+	if !imageNameRegexp.MatchString(ctx.Params("image")) {
+		apiError(ctx, http.StatusBadRequest, "NAME_INVALID", "invalid image name")
+	}
+}
+
+// RequireWrite is the middleware checking that the user can push images of the owner
+func RequireWrite(ctx *context.APIContext) {
+	helper.RequireWrite(ctx, responder(ctx))
+}
+
+func imageName(ctx *context.APIContext) string {
+	return ctx.Params("image")
+}
+
+func imageURL(ctx *context.APIContext) string {
+	return fmt.Sprintf("/v2/%s/%s", helper.GetOwner(ctx).LowerName, imageName(ctx))
+}
+
+// getImage returns the package of the image, nil if it does not exist
+func getImage(ctx *context.APIContext) *models.Package {
+	pkg, err := models.GetPackageByName(helper.GetOwner(ctx).ID, models.PackageTypeContainer, imageName(ctx))
+	if err != nil {
+		if models.IsErrPackageNotExist(err) {
+			apiError(ctx, http.StatusNotFound, "NAME_UNKNOWN", "image not found")
+		} else {
+			serverError(ctx, "GetPackageByName", err)
+		}
+		return nil
+	}
+	return pkg
+}
+
+// getBlobFile returns the file of the image holding the content with the digest, it is nil
+// if the image or the content do not exist
+func getBlobFile(ctx *context.APIContext, digest string) (*models.PackageVersion, *models.PackageFile, error) {
+	pkg, err := models.GetPackageByName(helper.GetOwner(ctx).ID, models.PackageTypeContainer, imageName(ctx))
+	if err != nil {
+		if models.IsErrPackageNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	pv, err := models.GetPackageVersionByName(pkg.ID, packages_service.ContainerBlobsVersion)
+	if err != nil {
+		if models.IsErrPackageVersionNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	pf, err := models.GetPackageFileByName(pv.ID, digest)
+	if err != nil {
+		if models.IsErrPackageFileNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	return pv, pf, nil
+}
+
+// addBlob stores a blob with the expected digest in the image
+func addBlob(ctx *context.APIContext, content io.Reader, digest string) bool {
+	if !digestRegexp.MatchString(digest) {
+		apiError(ctx, http.StatusBadRequest, "DIGEST_INVALID", "invalid digest")
+		return false
+	}
+	blob, err := packages_service.CreateBlob(content)
+	if err != nil {
+		serverError(ctx, "CreateBlob", err)
+		return false
+	}
+	if "sha256:"+blob.HashSHA256 != digest {
+		apiError(ctx, http.StatusBadRequest, "DIGEST_INVALID", "the digest does not match the content")
+		return false
+	}
+
+	owner := helper.GetOwner(ctx)
+	if err := packages_service.CheckQuota(owner, models.PackageTypeContainer, blob.Size); err != nil {
+		helper.UploadError(ctx, responder(ctx), err)
+		return false
+	}
+	_, pv, err := packages_service.GetOrCreateVersion(&packages_service.AddFileOptions{
+		Owner:      owner,
+		Creator:    ctx.User,
+		Type:       models.PackageTypeContainer,
+		Name:       imageName(ctx),
+		Version:    packages_service.ContainerBlobsVersion,
+		IsInternal: true,
+	})
+	if err == nil {
+		_, err = packages_service.AddBlobToVersion(pv, blob, digest, true)
+	}
+	if err != nil {
+		helper.UploadError(ctx, responder(ctx), err)
+		return false
+	}
+
+	ctx.Resp.Header().Set("Location", fmt.Sprintf("%s/blobs/%s", imageURL(ctx), digest))
+	ctx.Resp.Header().Set("Docker-Content-Digest", digest)
+	ctx.Status(http.StatusCreated)
+	return true
+}
+
+func uploadPath(id string) string {
+	return filepath.Join(setting.Packages.ChunkedUploadPath, id)
+}
+
+// getUploadPath returns the path of the upload session in the path, empty if it does not exist
+func getUploadPath(ctx *context.APIContext) string {
+	id := ctx.Params("uuid")
+	if uuidRegexp.MatchString(id) {
+		if _, err := os.Stat(uploadPath(id)); err == nil {
+			return uploadPath(id)
+		} else if !os.IsNotExist(err) {
+			serverError(ctx, "Stat", err)
+			return ""
+		}
+	}
+	apiError(ctx, http.StatusNotFound, "BLOB_UPLOAD_UNKNOWN", "upload not found")
+	return ""
+}
+
+// writeUploadStatus writes the headers which describe the state of an upload session
+func writeUploadStatus(ctx *context.APIContext, id string, status int) {
+	fi, err := os.Stat(uploadPath(id))
+	if err != nil {
+		serverError(ctx, "Stat", err)
+		return
+	}
+	end := fi.Size() - 1
+	if end < 0 {
+		end = 0
+	}
+	ctx.Resp.Header().Set("Location", fmt.Sprintf("%s/blobs/uploads/%s", imageURL(ctx), id))
+	ctx.Resp.Header().Set("Range", fmt.Sprintf("0-%d", end))
+	ctx.Resp.Header().Set("Docker-Upload-UUID", id)
+	ctx.Resp.Header().Set("Content-Length", "0")
+	ctx.Status(status)
+}
+
+// appendToUpload appends the body of the request to the upload session
+func appendToUpload(ctx *context.APIContext, p string) bool {
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		serverError(ctx, "OpenFile", err)
+		return false
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, ctx.Req.Body); err != nil {
+		serverError(ctx, "Copy", err)
+		return false
+	}
+	return true
+}
+
+// InitiateUpload starts the upload of a blob. The blob is stored at once if its digest is given,
+// otherwise an upload session is created which receives the content in chunks.
+func InitiateUpload(ctx *context.APIContext) {
+	if digest := ctx.Query("digest"); len(digest) > 0 {
+		addBlob(ctx, ctx.Req.Body, digest)
+		return
+	}
+
+	if err := os.MkdirAll(setting.Packages.ChunkedUploadPath, os.ModePerm); err != nil {
+		serverError(ctx, "MkdirAll", err)
+		return
+	}
+	id := uuid.New().String()
+	f, err := os.Create(uploadPath(id))
+	if err != nil {
+		serverError(ctx, "Create", err)
+		return
+	}
+	if err := f.Close(); err != nil {
+		serverError(ctx, "Close", err)
+		return
+	}
+	writeUploadStatus(ctx, id, http.StatusAccepted)
+}
+
+// GetUploadStatus returns the state of an upload session
+func GetUploadStatus(ctx *context.APIContext) {
+	if p := getUploadPath(ctx); len(p) > 0 {
+		writeUploadStatus(ctx, filepath.Base(p), http.StatusNoContent)
+	}
+}
+
+// UploadChunk appends a chunk to an upload session
+func UploadChunk(ctx *context.APIContext) {
+	p := getUploadPath(ctx)
+	if len(p) == 0 || !appendToUpload(ctx, p) {
+		return
+	}
+	writeUploadStatus(ctx, filepath.Base(p), http.StatusAccepted)
+}
+
+// CompleteUpload appends the last chunk to an upload session and stores the uploaded blob
+func CompleteUpload(ctx *context.APIContext) {
+	p := getUploadPath(ctx)
+	if len(p) == 0 || !appendToUpload(ctx, p) {
+		return
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		serverError(ctx, "Open", err)
+		return
+	}
+	defer func() {
+		_ = f.Close()
+		if err := os.Remove(p); err != nil {
+			log.Error("Remove [%s]: %v", p, err)
+		}
+	}()
+	addBlob(ctx, f, ctx.Query("digest"))
+}
+
+// CancelUpload deletes an upload session
+func CancelUpload(ctx *context.APIContext) {
+	p := getUploadPath(ctx)
+	if len(p) == 0 {
+		return
+	}
+	if err := os.Remove(p); err != nil {
+		serverError(ctx, "Remove", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetBlob serves a blob of the image, HEAD requests only check its existence
+func GetBlob(ctx *context.APIContext) {
+	digest := ctx.Params("digest")
+	pv, pf, err := getBlobFile(ctx, digest)
+	if err != nil {
+		serverError(ctx, "getBlobFile", err)
+		return
+	}
+	if pf == nil {
+		apiError(ctx, http.StatusNotFound, "BLOB_UNKNOWN", "blob not found")
+		return
+	}
+	if err := pf.LoadBlob(); err != nil {
+		serverError(ctx, "LoadBlob", err)
+		return
+	}
+
+	ctx.Resp.Header().Set("Docker-Content-Digest", digest)
+	if ctx.Req.Method == http.MethodHead {
+		ctx.Resp.Header().Set("Content-Length", strconv.FormatInt(pf.Blob.Size, 10))
+		ctx.Status(http.StatusOK)
+		return
+	}
+	helper.ServeFile(ctx, pv, pf)
+}
+
+// DeleteBlob deletes a blob of the image
+func DeleteBlob(ctx *context.APIContext) {
+	_, pf, err := getBlobFile(ctx, ctx.Params("digest"))
+	if err != nil {
+		serverError(ctx, "getBlobFile", err)
+		return
+	}
+	if pf == nil {
+		apiError(ctx, http.StatusNotFound, "BLOB_UNKNOWN", "blob not found")
+		return
+	}
+	if err := models.DeletePackageFile(pf); err != nil {
+		serverError(ctx, "DeletePackageFile", err)
+		return
+	}
+	ctx.Status(http.StatusAccepted)
+}
+
+// mediaTypeOf returns the media type declared in a manifest
+func mediaTypeOf(content []byte) string {
+	m, err := packages_service.ParseContainerManifest(content)
+	if err != nil || len(m.MediaType) == 0 {
+		return defaultManifestMediaType
+	}
+	return m.MediaType
+}
+
+// getTagVersion returns the version of a tag of the image
+func getTagVersion(ctx *context.APIContext, pkg *models.Package, tag string) (*models.PackageVersion, *packages_service.ContainerTagMetadata) {
+	pv, err := models.GetPackageVersionByName(pkg.ID, tag)
+	if err == nil && pv.IsInternal {
+		err = models.ErrPackageVersionNotExist{Version: tag}
+	}
+	if err != nil {
+		if models.IsErrPackageVersionNotExist(err) {
+			apiError(ctx, http.StatusNotFound, "MANIFEST_UNKNOWN", "manifest not found")
+		} else {
+			serverError(ctx, "GetPackageVersionByName", err)
+		}
+		return nil, nil
+	}
+	meta, err := packages_service.ParseContainerTagMetadata(pv)
+	if err != nil {
+		serverError(ctx, "ParseContainerTagMetadata", err)
+		return nil, nil
+	}
+	return pv, meta
+}
+
+// GetManifest serves a manifest of the image referenced by a tag or a digest, HEAD requests only check its existence
+func GetManifest(ctx *context.APIContext) {
+	pkg := getImage(ctx)
+	if pkg == nil {
+		return
+	}
+
+	reference := ctx.Params("reference")
+	digest := reference
+	var mediaType string
+	var pv *models.PackageVersion
+	if !digestRegexp.MatchString(reference) {
+		var meta *packages_service.ContainerTagMetadata
+		if pv, meta = getTagVersion(ctx, pkg, reference); pv == nil {
+			return
+		}
+		digest, mediaType = meta.Digest, meta.MediaType
+	}
+
+	blobsPv, pf, err := getBlobFile(ctx, digest)
+	if err != nil {
+		serverError(ctx, "getBlobFile", err)
+		return
+	}
+	if pf == nil {
+		apiError(ctx, http.StatusNotFound, "MANIFEST_UNKNOWN", "manifest not found")
+		return
+	}
+	if pv == nil {
+		pv = blobsPv
+	}
+	content, err := packages_service.ReadFile(pf)
+	if err != nil {
+		serverError(ctx, "ReadFile", err)
+		return
+	}
+	if len(mediaType) == 0 {
+		mediaType = mediaTypeOf(content)
+	}
+
+	ctx.Resp.Header().Set("Content-Type", mediaType)
+	ctx.Resp.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	ctx.Resp.Header().Set("Docker-Content-Digest", digest)
+	if ctx.Req.Method == http.MethodHead {
+		ctx.Status(http.StatusOK)
+		return
+	}
+	if err := pv.IncreaseDownloadCount(); err != nil {
+		serverError(ctx, "IncreaseDownloadCount", err)
+		return
+	}
+	ctx.Status(http.StatusOK)
+	if _, err := ctx.Resp.Write(content); err != nil {
+		log.Error("Write: %v", err)
+	}
+}
+
+// UploadManifest stores a manifest of the image. The content it references must have been uploaded,
+// a tag in the path is pointed to the manifest.
+func UploadManifest(ctx *context.APIContext) {
+	reference := ctx.Params("reference")
+	isDigest := digestRegexp.MatchString(reference)
+	if !isDigest && !tagRegexp.MatchString(reference) {
+		apiError(ctx, http.StatusBadRequest, "TAG_INVALID", "invalid tag")
+		return
+	}
+
+	content, err := ioutil.ReadAll(io.LimitReader(ctx.Req.Body, maxManifestSize+1))
+	if err != nil {
+		serverError(ctx, "ReadAll", err)
+		return
+	}
+	if len(content) > maxManifestSize {
+		apiError(ctx, http.StatusRequestEntityTooLarge, "SIZE_INVALID", "the manifest is too large")
+		return
+	}
+
+	m, err := packages_service.ParseContainerManifest(content)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, "MANIFEST_INVALID", err.Error())
+		return
+	}
+	for _, digest := range m.References() {
+		_, pf, err := getBlobFile(ctx, digest)
+		if err != nil {
+			serverError(ctx, "getBlobFile", err)
+			return
+		}
+		if pf == nil {
+			apiError(ctx, http.StatusBadRequest, "MANIFEST_BLOB_UNKNOWN", fmt.Sprintf("blob %s is unknown", digest))
+			return
+		}
+	}
+
+	mediaType := ctx.Req.Header.Get("Content-Type")
+	if i := strings.IndexByte(mediaType, ';'); i >= 0 {
+		mediaType = mediaType[:i]
+	}
+	if len(mediaType) == 0 || mediaType == "application/octet-stream" {
+		mediaType = mediaTypeOf(content)
+	}
+
+	blob, err := packages_service.CreateBlob(bytes.NewReader(content))
+	if err != nil {
+		serverError(ctx, "CreateBlob", err)
+		return
+	}
+	digest := "sha256:" + blob.HashSHA256
+	if isDigest && digest != reference {
+		apiError(ctx, http.StatusBadRequest, "DIGEST_INVALID", "the digest does not match the manifest")
+		return
+	}
+
+	owner := helper.GetOwner(ctx)
+	if err := packages_service.CheckQuota(owner, models.PackageTypeContainer, blob.Size); err != nil {
+		helper.UploadError(ctx, responder(ctx), err)
+		return
+	}
+	_, blobsPv, err := packages_service.GetOrCreateVersion(&packages_service.AddFileOptions{
+		Owner:      owner,
+		Creator:    ctx.User,
+		Type:       models.PackageTypeContainer,
+		Name:       imageName(ctx),
+		Version:    packages_service.ContainerBlobsVersion,
+		IsInternal: true,
+	})
+	if err == nil {
+		_, err = packages_service.AddBlobToVersion(blobsPv, blob, digest, true)
+	}
+	if err != nil {
+		helper.UploadError(ctx, responder(ctx), err)
+		return
+	}
+
+	if !isDigest && !tagManifest(ctx, reference, blob, &packages_service.ContainerTagMetadata{Digest: digest, MediaType: mediaType}) {
+		return
+	}
+
+	ctx.Resp.Header().Set("Location", fmt.Sprintf("%s/manifests/%s", imageURL(ctx), digest))
+	ctx.Resp.Header().Set("Docker-Content-Digest", digest)
+	ctx.Status(http.StatusCreated)
+}
+
+// tagManifest points a tag of the image to a manifest, creating the version of the tag if needed
+func tagManifest(ctx *context.APIContext, tag string, blob *models.PackageBlob, meta *packages_service.ContainerTagMetadata) bool {
+	metaJSON, err := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(meta)
+	if err != nil {
+		serverError(ctx, "Marshal", err)
+		return false
+	}
+	_, pv, err := packages_service.GetOrCreateVersion(&packages_service.AddFileOptions{
+		Owner:    helper.GetOwner(ctx),
+		Creator:  ctx.User,
+		Type:     models.PackageTypeContainer,
+		Name:     imageName(ctx),
+		Version:  tag,
+		Metadata: string(metaJSON),
+	})
+	if err != nil {
+		helper.UploadError(ctx, responder(ctx), err)
+		return false
+	}
+	if pv.MetadataJSON != string(metaJSON) {
+		pv.MetadataJSON = string(metaJSON)
+		if err := models.UpdatePackageVersionMetadata(pv); err != nil {
+			serverError(ctx, "UpdatePackageVersionMetadata", err)
+			return false
+		}
+	}
+	if _, err := packages_service.AddBlobToVersion(pv, blob, packages_service.ContainerManifestFilename, true); err != nil {
+		helper.UploadError(ctx, responder(ctx), err)
+		return false
+	}
+	return true
+}
+
+// DeleteManifest deletes a tag of the image, or a manifest and all tags pointing to it
+func DeleteManifest(ctx *context.APIContext) {
+	pkg := getImage(ctx)
+	if pkg == nil {
+		return
+	}
+
+	reference := ctx.Params("reference")
+	if !digestRegexp.MatchString(reference) {
+		pv, _ := getTagVersion(ctx, pkg, reference)
+		if pv == nil {
+			return
+		}
+		if err := models.DeletePackageVersion(pv); err != nil {
+			serverError(ctx, "DeletePackageVersion", err)
+			return
+		}
+		ctx.Status(http.StatusAccepted)
+		return
+	}
+
+	_, pf, err := getBlobFile(ctx, reference)
+	if err != nil {
+		serverError(ctx, "getBlobFile", err)
+		return
+	}
+	if pf == nil {
+		apiError(ctx, http.StatusNotFound, "MANIFEST_UNKNOWN", "manifest not found")
+		return
+	}
+	versions, err := models.GetPackageVersions(pkg.ID)
+	if err != nil {
+		serverError(ctx, "GetPackageVersions", err)
+		return
+	}
+	for _, pv := range versions {
+		meta, err := packages_service.ParseContainerTagMetadata(pv)
+		if err != nil {
+			serverError(ctx, "ParseContainerTagMetadata", err)
+			return
+		}
+		if meta.Digest != reference {
+			continue
+		}
+		if err := models.DeletePackageVersion(pv); err != nil {
+			serverError(ctx, "DeletePackageVersion", err)
+			return
+		}
+	}
+	if err := models.DeletePackageFile(pf); err != nil {
+		serverError(ctx, "DeletePackageFile", err)
+		return
+	}
+	ctx.Status(http.StatusAccepted)
+}
+
+// ListTags lists the tags of the image
+func ListTags(ctx *context.APIContext) {
+	pkg := getImage(ctx)
+	if pkg == nil {
+		return
+	}
+	versions, err := models.GetPackageVersions(pkg.ID)
+	if err != nil {
+		serverError(ctx, "GetPackageVersions", err)
+		return
+	}
+	tags := make([]string, 0, len(versions))
+	for _, pv := range versions {
+		tags = append(tags, pv.Version)
+	}
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"name": fmt.Sprintf("%s/%s", helper.GetOwner(ctx).LowerName, pkg.Name),
+		"tags": tags,
+	})
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package helper contains the functions shared by the package registry endpoints.
+package helper
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	packages_service "code.gitea.io/gitea/services/packages"
+)
+
+// GetOwner returns the owner of the packages in the path
+func GetOwner(ctx *context.APIContext) *models.User {
+	return ctx.Data["PackageOwner"].(*models.User)
+}
+
+// AssignOwner loads the owner in the path and checks that the user can read its packages.
+// respond is used to write the errors.
+func AssignOwner(ctx *context.APIContext, respond func(status int, message string)) {
+	owner, err := models.GetUserByName(ctx.Params("owner"))
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			respond(http.StatusNotFound, "owner not found")
+		} else {
+			ServerError(ctx, respond, "GetUserByName", err)
+		}
+		return
+	}
+	if !packages_service.CanRead(owner, ctx.User) {
+		if ctx.IsSigned {
+			respond(http.StatusNotFound, "owner not found")
+		} else {
+			Unauthorized(ctx, respond)
+		}
+		return
+	}
+	ctx.Data["PackageOwner"] = owner
+}
+
+// Unauthorized asks the client to authenticate with HTTP basic authentication
+func Unauthorized(ctx *context.APIContext, respond func(status int, message string)) {
+	ctx.Resp.Header().Set("WWW-Authenticate", `Basic realm="Gitea Package Registry"`)
+	respond(http.StatusUnauthorized, "authentication required")
+}
+
+// RequireWrite checks that the user can publish packages of the owner
+func RequireWrite(ctx *context.APIContext, respond func(status int, message string)) {
+	if !ctx.IsSigned {
+		Unauthorized(ctx, respond)
+		return
+	}
+	canWrite, err := packages_service.CanWrite(GetOwner(ctx), ctx.User)
+	if err != nil {
+		ServerError(ctx, respond, "CanWrite", err)
+		return
+	}
+	if !canWrite {
+		respond(http.StatusForbidden, "you are not allowed to publish packages of this owner")
+	}
+}
+
+// ServerError logs an internal error and responds without details
+func ServerError(ctx *context.APIContext, respond func(status int, message string), title string, err error) {
+	log.ErrorWithSkip(1, "%s: %v", title, err)
+	respond(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+}
+
+// UploadError responds to an error of adding a file to a package with the matching status
+func UploadError(ctx *context.APIContext, respond func(status int, message string), err error) {
+	switch {
+	case models.IsErrPackageVersionAlreadyExist(err), models.IsErrPackageFileAlreadyExist(err):
+		respond(http.StatusConflict, err.Error())
+	case models.IsErrPackageQuotaExceeded(err):
+		respond(http.StatusRequestEntityTooLarge, err.Error())
+	default:
+		log.ErrorWithSkip(1, "AddFile: %v", err)
+		respond(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+}
+
+// ServeFile serves the content of a package file as a download and counts the download of its version
+func ServeFile(ctx *context.APIContext, pv *models.PackageVersion, pf *models.PackageFile) {
+	obj, err := packages_service.OpenFile(pf)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	defer obj.Close()
+
+	if ctx.Req.Method == http.MethodGet {
+		if err := pv.IncreaseDownloadCount(); err != nil {
+			ctx.InternalServerError(err)
+			return
+		}
+	}
+	ctx.ServeContent(pf.Name, obj, pf.CreatedUnix.AsTime())
+}
@@ -0,0 +1,263 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package maven implements the repository layout used by Maven to deploy and resolve artifacts.
+package maven
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/xml"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+)
+
+const metadataFilename = "maven-metadata.xml"
+
+var (
+	// segmentRegexp matches the valid segments of a path, which are the parts of the group id, the artifact id and the version
+	segmentRegexp = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._+-]*$`)
+
+	checksums = map[string]func() hash.Hash{
+		".md5":    md5.New,
+		".sha1":   sha1.New,
+		".sha256": sha256.New,
+		".sha512": sha512.New,
+	}
+)
+
+// artifactMetadata is the maven-metadata.xml of an artifact which lists its versions
+type artifactMetadata struct {
+	XMLName    xml.Name `xml:"metadata"`
+	GroupID    string   `xml:"groupId"`
+	ArtifactID string   `xml:"artifactId"`
+	Versioning struct {
+		Latest      string   `xml:"latest"`
+		Release     string   `xml:"release"`
+		Versions    []string `xml:"versions>version"`
+		LastUpdated string   `xml:"lastUpdated"`
+	} `xml:"versioning"`
+}
+
+// filePath is a parsed path of the repository
+type filePath struct {
+	GroupID    string
+	ArtifactID string
+	// Version is empty for the files of an artifact
+	Version  string
+	Filename string
+	// Checksum is the extension of the checksum requested for the file, empty if the file itself is requested
+	Checksum string
+}
+
+func (p *filePath) packageName() string {
+	return p.GroupID + ":" + p.ArtifactID
+}
+
+func apiError(ctx *context.APIContext, status int, message string) {
+	ctx.PlainText(status, []byte(message))
+}
+
+func responder(ctx *context.APIContext) func(status int, message string) {
+	return func(status int, message string) {
+		apiError(ctx, status, message)
+	}
+}
+
+func serverError(ctx *context.APIContext, title string, err error) {
+	helper.ServerError(ctx, responder(ctx), title, err)
+}
+
+// AssignOwner is the middleware assigning the owner of the packages in the path
+func AssignOwner(ctx *context.APIContext) {
+	helper.AssignOwner(ctx, responder(ctx))
+}
+
+// RequireWrite is the middleware checking that the user can publish packages of the owner
+func RequireWrite(ctx *context.APIContext) {
+	helper.RequireWrite(ctx, responder(ctx))
+}
+
+// parsePath parses a path of the repository. The maven-metadata.xml of an artifact has no version,
+// only snapshot versions have their own maven-metadata.xml.
+func parsePath(p string) (*filePath, bool) {
+	parts := strings.Split(strings.Trim(p, "/"), "/")
+	if len(parts) < 3 {
+		return nil, false
+	}
+	for _, part := range parts {
+		if !segmentRegexp.MatchString(part) {
+			return nil, false
+		}
+	}
+
+	fp := &filePath{Filename: parts[len(parts)-1]}
+	for ext := range checksums {
+		if strings.HasSuffix(fp.Filename, ext) {
+			fp.Checksum = ext
+			fp.Filename = strings.TrimSuffix(fp.Filename, ext)
+			break
+		}
+	}
+
+	rest := parts[:len(parts)-1]
+	if fp.Filename != metadataFilename || strings.HasSuffix(rest[len(rest)-1], "-SNAPSHOT") {
+		if len(rest) < 3 {
+			return nil, false
+		}
+		fp.Version = rest[len(rest)-1]
+		rest = rest[:len(rest)-1]
+	}
+	fp.ArtifactID = rest[len(rest)-1]
+	fp.GroupID = strings.Join(rest[:len(rest)-1], ".")
+	return fp, true
+}
+
+func serveContent(ctx *context.APIContext, fp *filePath, content []byte) {
+	if len(fp.Checksum) > 0 {
+		h := checksums[fp.Checksum]()
+		_, _ = h.Write(content)
+		content = []byte(hex.EncodeToString(h.Sum(nil)))
+	}
+	ctx.ServeContent(fp.Filename+fp.Checksum, bytes.NewReader(content))
+}
+
+// DownloadPackageFile serves a file of the repository. The metadata of artifacts and the checksums are generated.
+func DownloadPackageFile(ctx *context.APIContext) {
+	fp, ok := parsePath(ctx.Params("*"))
+	if !ok {
+		apiError(ctx, http.StatusNotFound, "file not found")
+		return
+	}
+
+	pkg, err := models.GetPackageByName(helper.GetOwner(ctx).ID, models.PackageTypeMaven, fp.packageName())
+	if err != nil {
+		if models.IsErrPackageNotExist(err) {
+			apiError(ctx, http.StatusNotFound, "package not found")
+		} else {
+			serverError(ctx, "GetPackageByName", err)
+		}
+		return
+	}
+
+	if len(fp.Version) == 0 {
+		serveArtifactMetadata(ctx, fp, pkg)
+		return
+	}
+
+	pv, err := models.GetPackageVersionByName(pkg.ID, fp.Version)
+	if err != nil {
+		if models.IsErrPackageVersionNotExist(err) {
+			apiError(ctx, http.StatusNotFound, "version not found")
+		} else {
+			serverError(ctx, "GetPackageVersionByName", err)
+		}
+		return
+	}
+	pf, err := models.GetPackageFileByName(pv.ID, fp.Filename)
+	if err != nil {
+		if models.IsErrPackageFileNotExist(err) {
+			apiError(ctx, http.StatusNotFound, "file not found")
+		} else {
+			serverError(ctx, "GetPackageFileByName", err)
+		}
+		return
+	}
+
+	if len(fp.Checksum) > 0 {
+		if err := pf.LoadBlob(); err != nil {
+			serverError(ctx, "LoadBlob", err)
+			return
+		}
+		var sum string
+		switch fp.Checksum {
+		case ".md5":
+			sum = pf.Blob.HashMD5
+		case ".sha1":
+			sum = pf.Blob.HashSHA1
+		case ".sha256":
+			sum = pf.Blob.HashSHA256
+		case ".sha512":
+			sum = pf.Blob.HashSHA512
+		}
+		ctx.ServeContent(pf.Name+fp.Checksum, strings.NewReader(sum), pf.CreatedUnix.AsTime())
+		return
+	}
+	helper.ServeFile(ctx, pv, pf)
+}
+
+func serveArtifactMetadata(ctx *context.APIContext, fp *filePath, pkg *models.Package) {
+	versions, err := models.GetPackageVersions(pkg.ID)
+	if err != nil {
+		serverError(ctx, "GetPackageVersions", err)
+		return
+	}
+
+	meta := &artifactMetadata{
+		GroupID:    fp.GroupID,
+		ArtifactID: fp.ArtifactID,
+	}
+	for _, pv := range versions {
+		meta.Versioning.Versions = append(meta.Versioning.Versions, pv.Version)
+		meta.Versioning.Latest = pv.Version
+		if !strings.HasSuffix(pv.Version, "-SNAPSHOT") {
+			meta.Versioning.Release = pv.Version
+		}
+	}
+	meta.Versioning.LastUpdated = pkg.UpdatedUnix.AsTime().UTC().Format("20060102150405")
+
+	content, err := xml.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		serverError(ctx, "MarshalIndent", err)
+		return
+	}
+	serveContent(ctx, fp, append([]byte(xml.Header), content...))
+}
+
+// UploadPackageFile stores a file deployed by Maven. The uploaded metadata of artifacts and
+// the uploaded checksums are ignored because they are generated.
+func UploadPackageFile(ctx *context.APIContext) {
+	fp, ok := parsePath(ctx.Params("*"))
+	if !ok {
+		apiError(ctx, http.StatusBadRequest, "invalid path")
+		return
+	}
+	if len(fp.Checksum) > 0 || len(fp.Version) == 0 {
+		if _, err := ioutil.ReadAll(ctx.Req.Body); err != nil {
+			serverError(ctx, "ReadAll", err)
+			return
+		}
+		ctx.Status(http.StatusOK)
+		return
+	}
+
+	isSnapshot := strings.HasSuffix(fp.Version, "-SNAPSHOT")
+	_, _, err := packages_service.AddFile(&packages_service.AddFileOptions{
+		Owner:    helper.GetOwner(ctx),
+		Creator:  ctx.User,
+		Type:     models.PackageTypeMaven,
+		Name:     fp.packageName(),
+		Version:  fp.Version,
+		Filename: fp.Filename,
+		// files of snapshots are deployed again with every build
+		OverwriteFile: isSnapshot || fp.Filename == metadataFilename,
+	}, ctx.Req.Body)
+	if err != nil {
+		helper.UploadError(ctx, responder(ctx), err)
+		return
+	}
+	ctx.Status(http.StatusCreated)
+}
@@ -0,0 +1,33 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package maven
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePath(t *testing.T) {
+	kases := []struct {
+		Path     string
+		Expected *filePath
+	}{
+		{"org/example/app/1.0/app-1.0.jar", &filePath{GroupID: "org.example", ArtifactID: "app", Version: "1.0", Filename: "app-1.0.jar"}},
+		{"org/example/app/1.0/app-1.0.pom.sha1", &filePath{GroupID: "org.example", ArtifactID: "app", Version: "1.0", Filename: "app-1.0.pom", Checksum: ".sha1"}},
+		{"org/example/app/maven-metadata.xml", &filePath{GroupID: "org.example", ArtifactID: "app", Filename: "maven-metadata.xml"}},
+		{"org/example/app/maven-metadata.xml.md5", &filePath{GroupID: "org.example", ArtifactID: "app", Filename: "maven-metadata.xml", Checksum: ".md5"}},
+		{"org/example/app/1.0-SNAPSHOT/maven-metadata.xml", &filePath{GroupID: "org.example", ArtifactID: "app", Version: "1.0-SNAPSHOT", Filename: "maven-metadata.xml"}},
+		{"app/1.0/app-1.0.jar", nil},
+		{"org/../app/1.0/app-1.0.jar", nil},
+	}
+	for _, kase := range kases {
+		fp, ok := parsePath(kase.Path)
+		assert.Equal(t, kase.Expected != nil, ok, kase.Path)
+		if ok {
+			assert.Equal(t, kase.Expected, fp, kase.Path)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package npm implements the npm registry API used by the npm client to install and publish packages.
+package npm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// nameRegexp matches the valid names of npm packages, optionally with a scope
+var nameRegexp = regexp.MustCompile(`^(@[a-z0-9-~][a-z0-9-._~]*/)?[a-z0-9-~][a-z0-9-._~]*$`)
+
+// metadata is stored as the metadata of a version
+type metadata struct {
+	// DistTags are the tags assigned to the version when it was published
+	DistTags []string `json:"dist_tags"`
+	// Manifest is the package.json of the version as sent by the client
+	Manifest jsoniter.RawMessage `json:"manifest"`
+}
+
+// publishRequest is the document sent by npm publish
+type publishRequest struct {
+	Name        string                         `json:"name"`
+	DistTags    map[string]string              `json:"dist-tags"`
+	Versions    map[string]jsoniter.RawMessage `json:"versions"`
+	Attachments map[string]*struct {
+		Data string `json:"data"`
+	} `json:"_attachments"`
+}
+
+// apiError writes an error in the format of the npm registry
+func apiError(ctx *context.APIContext, status int, message string) {
+	ctx.JSON(status, map[string]string{
+		"error": message,
+	})
+}
+
+func responder(ctx *context.APIContext) func(status int, message string) {
+	return func(status int, message string) {
+		apiError(ctx, status, message)
+	}
+}
+
+func serverError(ctx *context.APIContext, title string, err error) {
+	helper.ServerError(ctx, responder(ctx), title, err)
+}
+
+// AssignOwner is the middleware assigning the owner of the packages in the path
+func AssignOwner(ctx *context.APIContext) {
+	helper.AssignOwner(ctx, responder(ctx))
+}
+
+// RequireWrite is the middleware checking that the user can publish packages of the owner
+func RequireWrite(ctx *context.APIContext) {
+	helper.RequireWrite(ctx, responder(ctx))
+}
+
+func packageName(ctx *context.APIContext) string {
+	return ctx.Params("id")
+}
+
+func registryURL(ctx *context.APIContext) string {
+	return fmt.Sprintf("%sapi/packages/%s/npm", setting.AppURL, url.PathEscape(helper.GetOwner(ctx).Name))
+}
+
+func tarballName(name, version string) string {
+	if i := strings.IndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return fmt.Sprintf("%s-%s.tgz", name, version)
+}
+
+// PackageMetadata returns the metadata of a package with all its versions
+func PackageMetadata(ctx *context.APIContext) {
+	name := packageName(ctx)
+	pkg, err := models.GetPackageByName(helper.GetOwner(ctx).ID, models.PackageTypeNpm, name)
+	if err != nil {
+		if models.IsErrPackageNotExist(err) {
+			apiError(ctx, http.StatusNotFound, "package not found")
+		} else {
+			serverError(ctx, "GetPackageByName", err)
+		}
+		return
+	}
+	versions, err := models.GetPackageVersions(pkg.ID)
+	if err != nil {
+		serverError(ctx, "GetPackageVersions", err)
+		return
+	}
+
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	distTags := map[string]string{}
+	manifests := map[string]interface{}{}
+	times := map[string]string{
+		"created":  pkg.CreatedUnix.AsTime().Format(time.RFC3339),
+		"modified": pkg.UpdatedUnix.AsTime().Format(time.RFC3339),
+	}
+	for _, pv := range versions {
+		var meta metadata
+		if err := json.Unmarshal([]byte(pv.MetadataJSON), &meta); err != nil {
+			serverError(ctx, "Unmarshal", err)
+			return
+		}
+		manifest := map[string]interface{}{}
+		if err := json.Unmarshal(meta.Manifest, &manifest); err != nil {
+			serverError(ctx, "Unmarshal", err)
+			return
+		}
+
+		filename := tarballName(pkg.Name, pv.Version)
+		pf, err := models.GetPackageFileByName(pv.ID, filename)
+		if err == nil {
+			err = pf.LoadBlob()
+		}
+		if err != nil {
+			serverError(ctx, "GetPackageFileByName", err)
+			return
+		}
+		sha512, err := hex.DecodeString(pf.Blob.HashSHA512)
+		if err != nil {
+			serverError(ctx, "DecodeString", err)
+			return
+		}
+		manifest["dist"] = map[string]string{
+			"shasum":    pf.Blob.HashSHA1,
+			"integrity": "sha512-" + base64.StdEncoding.EncodeToString(sha512),
+			"tarball":   fmt.Sprintf("%s/%s/-/%s", registryURL(ctx), url.PathEscape(pkg.Name), url.PathEscape(filename)),
+		}
+		manifests[pv.Version] = manifest
+		times[pv.Version] = pv.CreatedUnix.AsTime().Format(time.RFC3339)
+
+		// versions are sorted from old to new, so a tag ends up at the newest version it was assigned to
+		for _, tag := range meta.DistTags {
+			distTags[tag] = pv.Version
+		}
+	}
+	if _, ok := distTags["latest"]; !ok && len(versions) > 0 {
+		distTags["latest"] = versions[len(versions)-1].Version
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"_id":       pkg.Name,
+		"name":      pkg.Name,
+		"dist-tags": distTags,
+		"versions":  manifests,
+		"time":      times,
+	})
+}
+
+// DownloadPackageFile serves the tarball of a package version
+func DownloadPackageFile(ctx *context.APIContext) {
+	pkg, err := models.GetPackageByName(helper.GetOwner(ctx).ID, models.PackageTypeNpm, packageName(ctx))
+	if err != nil {
+		if models.IsErrPackageNotExist(err) {
+			apiError(ctx, http.StatusNotFound, "package not found")
+		} else {
+			serverError(ctx, "GetPackageByName", err)
+		}
+		return
+	}
+
+	filename := ctx.Params("filename")
+	pf, pv, err := models.GetPackageFileInPackage(pkg.ID, filename)
+	if err != nil {
+		if models.IsErrPackageFileNotExist(err) {
+			apiError(ctx, http.StatusNotFound, "file not found")
+		} else {
+			serverError(ctx, "GetPackageFileInPackage", err)
+		}
+		return
+	}
+	helper.ServeFile(ctx, pv, pf)
+}
+
+// UploadPackage publishes a new version of a package
+func UploadPackage(ctx *context.APIContext) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	var req publishRequest
+	if err := json.NewDecoder(ctx.Req.Body).Decode(&req); err != nil {
+		apiError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	name := packageName(ctx)
+	if req.Name != name || !nameRegexp.MatchString(name) {
+		apiError(ctx, http.StatusBadRequest, "invalid package name")
+		return
+	}
+	if len(req.Versions) != 1 || len(req.Attachments) != 1 {
+		apiError(ctx, http.StatusBadRequest, "exactly one version must be published")
+		return
+	}
+
+	var version string
+	var manifest jsoniter.RawMessage
+	for v, m := range req.Versions {
+		version, manifest = v, m
+	}
+	if len(version) == 0 || strings.ContainsAny(version, "/\\") {
+		apiError(ctx, http.StatusBadRequest, "invalid version")
+		return
+	}
+	filename := tarballName(name, version)
+	attachment, ok := req.Attachments[filename]
+	if !ok || attachment == nil {
+		apiError(ctx, http.StatusBadRequest, fmt.Sprintf("the tarball %s is missing", filename))
+		return
+	}
+	content, err := base64.StdEncoding.DecodeString(attachment.Data)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	meta := metadata{Manifest: manifest}
+	for tag, v := range req.DistTags {
+		if v == version {
+			meta.DistTags = append(meta.DistTags, tag)
+		}
+	}
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		serverError(ctx, "Marshal", err)
+		return
+	}
+
+	_, _, err = packages_service.AddFile(&packages_service.AddFileOptions{
+		Owner:             helper.GetOwner(ctx),
+		Creator:           ctx.User,
+		Type:              models.PackageTypeNpm,
+		Name:              name,
+		Version:           version,
+		Metadata:          string(metaJSON),
+		MustCreateVersion: true,
+		Filename:          filename,
+	}, bytes.NewReader(content))
+	if err != nil {
+		helper.UploadError(ctx, responder(ctx), err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, map[string]bool{
+		"ok": true,
+	})
+}
@@ -0,0 +1,411 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package nuget implements the NuGet V3 server API used by the nuget and dotnet clients.
+package nuget
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+var (
+	idRegexp      = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]*$`)
+	versionRegexp = regexp.MustCompile(`^[0-9]+(\.[0-9]+){1,3}(-[A-Za-z0-9.-]+)?(\+[A-Za-z0-9.-]+)?$`)
+)
+
+// metadata is stored as the metadata of a version
+type metadata struct {
+	Description string `json:"description"`
+	Authors     string `json:"authors"`
+	ProjectURL  string `json:"project_url"`
+}
+
+// nuspec is the manifest contained in a package
+type nuspec struct {
+	Metadata struct {
+		ID          string `xml:"id"`
+		Version     string `xml:"version"`
+		Authors     string `xml:"authors"`
+		Description string `xml:"description"`
+		ProjectURL  string `xml:"projectUrl"`
+	} `xml:"metadata"`
+}
+
+func apiError(ctx *context.APIContext, status int, message string) {
+	ctx.JSON(status, map[string]string{
+		"message": message,
+	})
+}
+
+func responder(ctx *context.APIContext) func(status int, message string) {
+	return func(status int, message string) {
+		apiError(ctx, status, message)
+	}
+}
+
+func serverError(ctx *context.APIContext, title string, err error) {
+	helper.ServerError(ctx, responder(ctx), title, err)
+}
+
+// AssignOwner is the middleware assigning the owner of the packages in the path.
+// The nuget client sends the API key in its own header, which is accepted as an access token.
+func AssignOwner(ctx *context.APIContext) {
+	if apiKey := ctx.Req.Header.Get("X-NuGet-ApiKey"); !ctx.IsSigned && len(apiKey) > 0 {
+		token, err := models.GetAccessTokenBySHA(apiKey)
+		if err != nil {
+			if models.IsErrAccessTokenNotExist(err) || models.IsErrAccessTokenEmpty(err) {
+				apiError(ctx, http.StatusUnauthorized, "invalid API key")
+			} else {
+				serverError(ctx, "GetAccessTokenBySHA", err)
+			}
+			return
+		}
+		user, err := models.GetUserByID(token.UID)
+		if err != nil {
+			serverError(ctx, "GetUserByID", err)
+			return
+		}
+		ctx.User = user
+		ctx.IsSigned = true
+	}
+	helper.AssignOwner(ctx, responder(ctx))
+}
+
+// RequireWrite is the middleware checking that the user can publish packages of the owner
+func RequireWrite(ctx *context.APIContext) {
+	helper.RequireWrite(ctx, responder(ctx))
+}
+
+func baseURL(ctx *context.APIContext) string {
+	return fmt.Sprintf("%sapi/packages/%s/nuget", setting.AppURL, url.PathEscape(helper.GetOwner(ctx).Name))
+}
+
+func registrationIndexURL(ctx *context.APIContext, id string) string {
+	return fmt.Sprintf("%s/registration/%s/index.json", baseURL(ctx), url.PathEscape(strings.ToLower(id)))
+}
+
+func packageContentURL(ctx *context.APIContext, id, version string) string {
+	id, version = strings.ToLower(id), strings.ToLower(version)
+	return fmt.Sprintf("%s/package/%s/%s/%s", baseURL(ctx), url.PathEscape(id), url.PathEscape(version), url.PathEscape(packageFilename(id, version)))
+}
+
+func packageFilename(id, version string) string {
+	return strings.ToLower(fmt.Sprintf("%s.%s.nupkg", id, version))
+}
+
+// ServiceIndex returns the resources provided by the server
+func ServiceIndex(ctx *context.APIContext) {
+	base := baseURL(ctx)
+	resources := []map[string]string{
+		{"@id": base + "/package", "@type": "PackageBaseAddress/3.0.0"},
+		{"@id": base + "/", "@type": "PackagePublish/2.0.0"},
+		{"@id": base + "/registration", "@type": "RegistrationsBaseUrl"},
+		{"@id": base + "/registration", "@type": "RegistrationsBaseUrl/3.0.0-rc"},
+		{"@id": base + "/query", "@type": "SearchQueryService"},
+		{"@id": base + "/query", "@type": "SearchQueryService/3.0.0-rc"},
+	}
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"version":   "3.0.0",
+		"resources": resources,
+	})
+}
+
+type versionInfo struct {
+	Version *models.PackageVersion
+	Meta    metadata
+}
+
+// getPackage returns the package in the path and its versions, oldest first
+func getPackage(ctx *context.APIContext) (*models.Package, []*versionInfo) {
+	pkg, err := models.GetPackageByName(helper.GetOwner(ctx).ID, models.PackageTypeNuGet, ctx.Params("id"))
+	if err != nil {
+		if models.IsErrPackageNotExist(err) {
+			apiError(ctx, http.StatusNotFound, "package not found")
+		} else {
+			serverError(ctx, "GetPackageByName", err)
+		}
+		return nil, nil
+	}
+	versions, err := getVersions(pkg)
+	if err != nil {
+		serverError(ctx, "getVersions", err)
+		return nil, nil
+	}
+	return pkg, versions
+}
+
+func getVersions(pkg *models.Package) ([]*versionInfo, error) {
+	versions, err := models.GetPackageVersions(pkg.ID)
+	if err != nil {
+		return nil, err
+	}
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	infos := make([]*versionInfo, 0, len(versions))
+	for _, pv := range versions {
+		info := &versionInfo{Version: pv}
+		if err := json.Unmarshal([]byte(pv.MetadataJSON), &info.Meta); err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// RegistrationIndex returns the registration index of a package, which lists the metadata of all its versions
+func RegistrationIndex(ctx *context.APIContext) {
+	pkg, versions := getPackage(ctx)
+	if ctx.Written() {
+		return
+	}
+	if len(versions) == 0 {
+		apiError(ctx, http.StatusNotFound, "package not found")
+		return
+	}
+
+	indexURL := registrationIndexURL(ctx, pkg.Name)
+	leaves := make([]map[string]interface{}, 0, len(versions))
+	for _, v := range versions {
+		leaves = append(leaves, map[string]interface{}{
+			"@id": fmt.Sprintf("%s#%s", indexURL, v.Version.LowerVersion),
+			"catalogEntry": map[string]interface{}{
+				"@id":         fmt.Sprintf("%s#%s", indexURL, v.Version.LowerVersion),
+				"id":          pkg.Name,
+				"version":     v.Version.Version,
+				"description": v.Meta.Description,
+				"authors":     v.Meta.Authors,
+				"projectUrl":  v.Meta.ProjectURL,
+				"listed":      true,
+			},
+			"packageContent": packageContentURL(ctx, pkg.Name, v.Version.Version),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"@id":   indexURL,
+		"count": 1,
+		"items": []map[string]interface{}{
+			{
+				"@id":   indexURL + "#page",
+				"count": len(leaves),
+				"lower": versions[0].Version.Version,
+				"upper": versions[len(versions)-1].Version.Version,
+				"items": leaves,
+			},
+		},
+	})
+}
+
+// EnumeratePackageVersions lists the versions of a package for the package base address resource
+func EnumeratePackageVersions(ctx *context.APIContext) {
+	_, versions := getPackage(ctx)
+	if ctx.Written() {
+		return
+	}
+	lowerVersions := make([]string, 0, len(versions))
+	for _, v := range versions {
+		lowerVersions = append(lowerVersions, v.Version.LowerVersion)
+	}
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"versions": lowerVersions,
+	})
+}
+
+// DownloadPackageFile serves the content of a package version
+func DownloadPackageFile(ctx *context.APIContext) {
+	pkg, err := models.GetPackageByName(helper.GetOwner(ctx).ID, models.PackageTypeNuGet, ctx.Params("id"))
+	if err == nil {
+		var pv *models.PackageVersion
+		if pv, err = models.GetPackageVersionByName(pkg.ID, ctx.Params("version")); err == nil {
+			var pf *models.PackageFile
+			if pf, err = models.GetPackageFileByName(pv.ID, ctx.Params("filename")); err == nil {
+				helper.ServeFile(ctx, pv, pf)
+				return
+			}
+		}
+	}
+	if models.IsErrPackageNotExist(err) || models.IsErrPackageVersionNotExist(err) || models.IsErrPackageFileNotExist(err) {
+		apiError(ctx, http.StatusNotFound, "package not found")
+	} else {
+		serverError(ctx, "GetPackageFile", err)
+	}
+}
+
+// SearchService searches the packages of the owner
+func SearchService(ctx *context.APIContext) {
+	pkgs, _, err := models.FindPackages(&models.FindPackagesOptions{
+		OwnerID: helper.GetOwner(ctx).ID,
+		Type:    models.PackageTypeNuGet,
+		Keyword: ctx.QueryTrim("q"),
+	})
+	if err != nil {
+		serverError(ctx, "FindPackages", err)
+		return
+	}
+
+	skip, take := ctx.QueryInt("skip"), ctx.QueryInt("take")
+	if take <= 0 {
+		take = 20
+	}
+	total := len(pkgs)
+	if skip > len(pkgs) {
+		skip = len(pkgs)
+	}
+	pkgs = pkgs[skip:]
+	if len(pkgs) > take {
+		pkgs = pkgs[:take]
+	}
+
+	data := make([]map[string]interface{}, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		versions, err := getVersions(pkg)
+		if err != nil {
+			serverError(ctx, "getVersions", err)
+			return
+		}
+		if len(versions) == 0 {
+			continue
+		}
+		var downloads int64
+		versionList := make([]map[string]interface{}, 0, len(versions))
+		for _, v := range versions {
+			downloads += v.Version.DownloadCount
+			versionList = append(versionList, map[string]interface{}{
+				"@id":       fmt.Sprintf("%s#%s", registrationIndexURL(ctx, pkg.Name), v.Version.LowerVersion),
+				"version":   v.Version.Version,
+				"downloads": v.Version.DownloadCount,
+			})
+		}
+		latest := versions[len(versions)-1]
+		data = append(data, map[string]interface{}{
+			"@id":            registrationIndexURL(ctx, pkg.Name),
+			"id":             pkg.Name,
+			"version":        latest.Version.Version,
+			"description":    latest.Meta.Description,
+			"authors":        latest.Meta.Authors,
+			"projectUrl":     latest.Meta.ProjectURL,
+			"registration":   registrationIndexURL(ctx, pkg.Name),
+			"totalDownloads": downloads,
+			"versions":       versionList,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"totalHits": total,
+		"data":      data,
+	})
+}
+
+// readNuspec reads the manifest of a package
+func readNuspec(r io.ReaderAt, size int64) (*nuspec, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range archive.File {
+		if path.Dir(file.Name) != "." || !strings.HasSuffix(strings.ToLower(file.Name), ".nuspec") {
+			continue
+		}
+		f, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		var spec nuspec
+		if err := xml.NewDecoder(f).Decode(&spec); err != nil {
+			return nil, err
+		}
+		return &spec, nil
+	}
+	return nil, fmt.Errorf("the package contains no .nuspec file")
+}
+
+// UploadPackage publishes a package pushed by the nuget client
+func UploadPackage(ctx *context.APIContext) {
+	file, header, err := ctx.Req.FormFile("package")
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+
+	spec, err := readNuspec(file, header.Size)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !idRegexp.MatchString(spec.Metadata.ID) || !versionRegexp.MatchString(spec.Metadata.Version) {
+		apiError(ctx, http.StatusBadRequest, "invalid id or version in the .nuspec file")
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		serverError(ctx, "Seek", err)
+		return
+	}
+
+	metaJSON, err := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(metadata{
+		Description: spec.Metadata.Description,
+		Authors:     spec.Metadata.Authors,
+		ProjectURL:  spec.Metadata.ProjectURL,
+	})
+	if err != nil {
+		serverError(ctx, "Marshal", err)
+		return
+	}
+
+	_, _, err = packages_service.AddFile(&packages_service.AddFileOptions{
+		Owner:             helper.GetOwner(ctx),
+		Creator:           ctx.User,
+		Type:              models.PackageTypeNuGet,
+		Name:              spec.Metadata.ID,
+		Version:           spec.Metadata.Version,
+		Metadata:          string(metaJSON),
+		MustCreateVersion: true,
+		Filename:          packageFilename(spec.Metadata.ID, spec.Metadata.Version),
+	}, file)
+	if err != nil {
+		helper.UploadError(ctx, responder(ctx), err)
+		return
+	}
+	log.Trace("NuGet package %s %s published by %s", spec.Metadata.ID, spec.Metadata.Version, ctx.User.Name)
+	ctx.Status(http.StatusCreated)
+}
+
+// DeletePackage deletes a version of a package
+func DeletePackage(ctx *context.APIContext) {
+	pkg, err := models.GetPackageByName(helper.GetOwner(ctx).ID, models.PackageTypeNuGet, ctx.Params("id"))
+	if err == nil {
+		var pv *models.PackageVersion
+		if pv, err = models.GetPackageVersionByName(pkg.ID, ctx.Params("version")); err == nil {
+			if err := models.DeletePackageVersion(pv); err != nil {
+				serverError(ctx, "DeletePackageVersion", err)
+				return
+			}
+			ctx.Status(http.StatusNoContent)
+			return
+		}
+	}
+	if models.IsErrPackageNotExist(err) || models.IsErrPackageVersionNotExist(err) {
+		apiError(ctx, http.StatusNotFound, "package not found")
+	} else {
+		serverError(ctx, "GetPackageVersionByName", err)
+	}
+}
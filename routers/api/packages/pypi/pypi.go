@@ -0,0 +1,246 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package pypi implements the upload API used by twine and the simple repository API used by pip.
+package pypi
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+var (
+	// normalizer replaces the separators of a name as defined in PEP 503
+	normalizer = regexp.MustCompile(`[-_.]+`)
+	// nameRegexp matches the valid project names as defined in PEP 508
+	nameRegexp = regexp.MustCompile(`(?i)^[a-z0-9]([a-z0-9._-]*[a-z0-9])?$`)
+	// versionRegexp matches the versions of PEP 440 loosely
+	versionRegexp = regexp.MustCompile(`(?i)^[a-z0-9][a-z0-9.+!_-]*$`)
+	// validExtensions are the file extensions of the distributions which can be uploaded
+	validExtensions = []string{".whl", ".tar.gz", ".zip", ".tar.bz2", ".egg"}
+
+	simpleTemplate = template.Must(template.New("simple").Parse(`<!DOCTYPE html>
+<html>
+	<head>
+		<title>Links for {{.Name}}</title>
+	</head>
+	<body>
+		<h1>Links for {{.Name}}</h1>
+		{{range .Files}}<a href="{{.URL}}#sha256={{.SHA256}}"{{if .RequiresPython}} data-requires-python="{{.RequiresPython}}"{{end}}>{{.Name}}</a><br/>
+		{{end}}
+	</body>
+</html>
+`))
+)
+
+// metadata is stored as the metadata of a version
+type metadata struct {
+	Summary        string `json:"summary"`
+	Author         string `json:"author"`
+	HomePage       string `json:"home_page"`
+	RequiresPython string `json:"requires_python"`
+}
+
+func apiError(ctx *context.APIContext, status int, message string) {
+	ctx.PlainText(status, []byte(message))
+}
+
+func responder(ctx *context.APIContext) func(status int, message string) {
+	return func(status int, message string) {
+		apiError(ctx, status, message)
+	}
+}
+
+func serverError(ctx *context.APIContext, title string, err error) {
+	helper.ServerError(ctx, responder(ctx), title, err)
+}
+
+// AssignOwner is the middleware assigning the owner of the packages in the path
+func AssignOwner(ctx *context.APIContext) {
+	helper.AssignOwner(ctx, responder(ctx))
+}
+
+// RequireWrite is the middleware checking that the user can publish packages of the owner
+func RequireWrite(ctx *context.APIContext) {
+	helper.RequireWrite(ctx, responder(ctx))
+}
+
+// normalize returns the normalized form of a project name as defined in PEP 503
+func normalize(name string) string {
+	return strings.ToLower(normalizer.ReplaceAllString(name, "-"))
+}
+
+type simpleFile struct {
+	Name           string
+	URL            string
+	SHA256         string
+	RequiresPython string
+}
+
+// PackageMetadata renders the page of the simple repository API which lists the files of a project
+func PackageMetadata(ctx *context.APIContext) {
+	pkg, err := models.GetPackageByName(helper.GetOwner(ctx).ID, models.PackageTypePyPI, normalize(ctx.Params("id")))
+	if err != nil {
+		if models.IsErrPackageNotExist(err) {
+			apiError(ctx, http.StatusNotFound, "package not found")
+		} else {
+			serverError(ctx, "GetPackageByName", err)
+		}
+		return
+	}
+	versions, err := models.GetPackageVersions(pkg.ID)
+	if err != nil {
+		serverError(ctx, "GetPackageVersions", err)
+		return
+	}
+
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	baseURL := fmt.Sprintf("%sapi/packages/%s/pypi/files/%s", setting.AppURL, url.PathEscape(helper.GetOwner(ctx).Name), url.PathEscape(pkg.Name))
+	files := make([]*simpleFile, 0, len(versions))
+	for _, pv := range versions {
+		var meta metadata
+		if err := json.Unmarshal([]byte(pv.MetadataJSON), &meta); err != nil {
+			serverError(ctx, "Unmarshal", err)
+			return
+		}
+		pfs, err := models.GetPackageFiles(pv.ID)
+		if err != nil {
+			serverError(ctx, "GetPackageFiles", err)
+			return
+		}
+		for _, pf := range pfs {
+			if err := pf.LoadBlob(); err != nil {
+				serverError(ctx, "LoadBlob", err)
+				return
+			}
+			files = append(files, &simpleFile{
+				Name:           pf.Name,
+				URL:            fmt.Sprintf("%s/%s/%s", baseURL, url.PathEscape(pv.Version), url.PathEscape(pf.Name)),
+				SHA256:         pf.Blob.HashSHA256,
+				RequiresPython: meta.RequiresPython,
+			})
+		}
+	}
+
+	ctx.Resp.Header().Set("Content-Type", "text/html; charset=utf-8")
+	ctx.Resp.WriteHeader(http.StatusOK)
+	if err := simpleTemplate.Execute(ctx.Resp, map[string]interface{}{
+		"Name":  pkg.Name,
+		"Files": files,
+	}); err != nil {
+		serverError(ctx, "Execute", err)
+	}
+}
+
+// DownloadPackageFile serves a distribution of a project
+func DownloadPackageFile(ctx *context.APIContext) {
+	pkg, err := models.GetPackageByName(helper.GetOwner(ctx).ID, models.PackageTypePyPI, normalize(ctx.Params("id")))
+	if err == nil {
+		var pv *models.PackageVersion
+		pv, err = models.GetPackageVersionByName(pkg.ID, ctx.Params("version"))
+		if err == nil && !pv.IsInternal {
+			var pf *models.PackageFile
+			if pf, err = models.GetPackageFileByName(pv.ID, ctx.Params("filename")); err == nil {
+				helper.ServeFile(ctx, pv, pf)
+				return
+			}
+		}
+	}
+	if err == nil || models.IsErrPackageNotExist(err) || models.IsErrPackageVersionNotExist(err) || models.IsErrPackageFileNotExist(err) {
+		apiError(ctx, http.StatusNotFound, "file not found")
+	} else {
+		serverError(ctx, "GetPackageFile", err)
+	}
+}
+
+// UploadPackageFile adds a distribution uploaded by twine to a version of a project
+func UploadPackageFile(ctx *context.APIContext) {
+	file, header, err := ctx.Req.FormFile("content")
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+
+	name := ctx.Req.FormValue("name")
+	version := ctx.Req.FormValue("version")
+	if !nameRegexp.MatchString(name) {
+		apiError(ctx, http.StatusBadRequest, "invalid project name")
+		return
+	}
+	if !versionRegexp.MatchString(version) {
+		apiError(ctx, http.StatusBadRequest, "invalid version")
+		return
+	}
+
+	filename := path.Base(header.Filename)
+	valid := false
+	for _, ext := range validExtensions {
+		if strings.HasSuffix(strings.ToLower(filename), ext) {
+			valid = true
+			break
+		}
+	}
+	if !valid || filename != header.Filename {
+		apiError(ctx, http.StatusBadRequest, "invalid file name")
+		return
+	}
+
+	metaJSON, err := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(metadata{
+		Summary:        ctx.Req.FormValue("summary"),
+		Author:         ctx.Req.FormValue("author"),
+		HomePage:       ctx.Req.FormValue("home_page"),
+		RequiresPython: ctx.Req.FormValue("requires_python"),
+	})
+	if err != nil {
+		serverError(ctx, "Marshal", err)
+		return
+	}
+
+	blob, err := packages_service.CreateBlob(file)
+	if err != nil {
+		serverError(ctx, "CreateBlob", err)
+		return
+	}
+	if digest := ctx.Req.FormValue("sha256_digest"); len(digest) > 0 && !strings.EqualFold(digest, blob.HashSHA256) {
+		apiError(ctx, http.StatusBadRequest, "the SHA256 digest does not match the file")
+		return
+	}
+
+	owner := helper.GetOwner(ctx)
+	if err := packages_service.CheckQuota(owner, models.PackageTypePyPI, blob.Size); err != nil {
+		helper.UploadError(ctx, responder(ctx), err)
+		return
+	}
+	_, pv, err := packages_service.GetOrCreateVersion(&packages_service.AddFileOptions{
+		Owner:    owner,
+		Creator:  ctx.User,
+		Type:     models.PackageTypePyPI,
+		Name:     normalize(name),
+		Version:  version,
+		Metadata: string(metaJSON),
+	})
+	if err == nil {
+		_, err = packages_service.AddBlobToVersion(pv, blob, filename, false)
+	}
+	if err != nil {
+		helper.UploadError(ctx, responder(ctx), err)
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
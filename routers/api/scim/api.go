@@ -0,0 +1,145 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package scim implements the SCIM 2.0 provisioning API, which lets identity
+// providers create, update and deactivate users and manage group memberships.
+package scim
+
+import (
+	"net/http"
+	"strconv"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/scim"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+
+	"gitea.com/go-chi/session"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// respond writes a SCIM resource or message
+func respond(ctx *context.APIContext, status int, obj interface{}) {
+	ctx.Resp.Header().Set("Content-Type", scim.ContentType+";charset=utf-8")
+	ctx.Resp.WriteHeader(status)
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.NewEncoder(ctx.Resp).Encode(obj); err != nil {
+		log.Error("Render SCIM response failed: %v", err)
+	}
+}
+
+// respondError writes a SCIM error message
+func respondError(ctx *context.APIContext, status int, scimType, detail string) {
+	respond(ctx, status, &scim.Error{
+		Schemas:  []string{scim.SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+// serverError logs an internal error and writes a SCIM error message without details
+func serverError(ctx *context.APIContext, title string, err error) {
+	log.ErrorWithSkip(1, "%s: %v", title, err)
+	respondError(ctx, http.StatusInternalServerError, "", http.StatusText(http.StatusInternalServerError))
+}
+
+// decode reads the JSON body of the request into obj
+func decode(ctx *context.APIContext, obj interface{}) bool {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.NewDecoder(ctx.Req.Body).Decode(obj); err != nil {
+		respondError(ctx, http.StatusBadRequest, scim.ErrorInvalidSyntax, err.Error())
+		return false
+	}
+	return true
+}
+
+// location returns the URL of a resource
+func location(resourceType string, id int64) string {
+	return setting.AppURL + "api/scim/v2/" + resourceType + "/" + strconv.FormatInt(id, 10)
+}
+
+// paging returns the 1-based start index and the count of a query
+func paging(ctx *context.APIContext) (startIndex, count int) {
+	startIndex = ctx.QueryInt("startIndex")
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	count = setting.SCIM.DefaultPagingNum
+	if len(ctx.Query("count")) > 0 {
+		count = ctx.QueryInt("count")
+	}
+	if count < 0 {
+		count = 0
+	} else if count > setting.SCIM.MaxResults {
+		count = setting.SCIM.MaxResults
+	}
+	return startIndex, count
+}
+
+// reqSiteAdminToken requires an access token of a site administrator
+func reqSiteAdminToken() func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		if !ctx.IsSigned || true != ctx.Data["IsApiToken"] {
+			ctx.Resp.Header().Set("WWW-Authenticate", `Bearer realm="SCIM"`)
+			respondError(ctx, http.StatusUnauthorized, "", "an access token is required")
+			return
+		}
+		if !ctx.User.IsAdmin {
+			respondError(ctx, http.StatusForbidden, "", "the access token must belong to a site administrator")
+			return
+		}
+	}
+}
+
+// Routes registers all SCIM 2.0 routes to web application.
+func Routes() *web.Route {
+	var m = web.NewRoute()
+
+	m.Use(session.Sessioner(session.Options{
+		Provider:       setting.SessionConfig.Provider,
+		ProviderConfig: setting.SessionConfig.ProviderConfig,
+		CookieName:     setting.SessionConfig.CookieName,
+		CookiePath:     setting.SessionConfig.CookiePath,
+		Gclifetime:     setting.SessionConfig.Gclifetime,
+		Maxlifetime:    setting.SessionConfig.Maxlifetime,
+		Secure:         setting.SessionConfig.Secure,
+		Domain:         setting.SessionConfig.Domain,
+	}))
+	m.Use(context.APIContexter())
+
+	if setting.EnableAccessLog {
+		m.Use(context.AccessLogger())
+	}
+
+	m.Group("", func() {
+		m.Get("/ServiceProviderConfig", ServiceProviderConfig)
+		m.Get("/ResourceTypes", ResourceTypes)
+
+		m.Group("/Users", func() {
+			m.Get("", ListUsers)
+			m.Post("", CreateUser)
+			m.Group("/{id}", func() {
+				m.Get("", GetUser)
+				m.Put("", ReplaceUser)
+				m.Patch("", PatchUser)
+				m.Delete("", DeleteUser)
+			})
+		})
+
+		m.Group("/Groups", func() {
+			m.Get("", ListGroups)
+			m.Post("", CreateGroup)
+			m.Group("/{id}", func() {
+				m.Get("", GetGroup)
+				m.Put("", ReplaceGroup)
+				m.Patch("", PatchGroup)
+				m.Delete("", DeleteGroup)
+			})
+		})
+	}, reqSiteAdminToken())
+
+	return m
+}
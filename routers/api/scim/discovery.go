@@ -0,0 +1,63 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/scim"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ServiceProviderConfig describes the supported SCIM features
+func ServiceProviderConfig(ctx *context.APIContext) {
+	respond(ctx, http.StatusOK, &scim.ServiceProviderConfig{
+		Schemas:          []string{scim.SchemaServiceProviderConfig},
+		DocumentationURI: "https://docs.gitea.io/en-us/authentication/",
+		Patch:            scim.Supported{Supported: true},
+		Bulk:             scim.BulkSupport{Supported: false},
+		Filter:           scim.FilterSupport{Supported: true, MaxResults: setting.SCIM.MaxResults},
+		ChangePassword:   scim.Supported{Supported: true},
+		Sort:             scim.Supported{Supported: false},
+		ETag:             scim.Supported{Supported: false},
+		AuthenticationSchemes: []scim.AuthenticationScheme{
+			{
+				Type:        "oauthbearertoken",
+				Name:        "OAuth Bearer Token",
+				Description: "Access token of a site administrator sent in the Authorization header",
+				Primary:     true,
+			},
+		},
+	})
+}
+
+var resourceTypes = []*scim.ResourceType{
+	{
+		Schemas:     []string{scim.SchemaResourceType},
+		ID:          "User",
+		Name:        "User",
+		Endpoint:    "/Users",
+		Description: "User Account",
+		Schema:      scim.SchemaUser,
+	},
+	{
+		Schemas:     []string{scim.SchemaResourceType},
+		ID:          "Group",
+		Name:        "Group",
+		Endpoint:    "/Groups",
+		Description: "Group mapped to organization teams",
+		Schema:      scim.SchemaGroup,
+	},
+}
+
+// ResourceTypes lists the supported resource types
+func ResourceTypes(ctx *context.APIContext) {
+	resources := make([]interface{}, 0, len(resourceTypes))
+	for _, rt := range resourceTypes {
+		resources = append(resources, rt)
+	}
+	respond(ctx, http.StatusOK, scim.NewListResponse(int64(len(resources)), 1, resources))
+}
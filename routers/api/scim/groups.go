@@ -0,0 +1,355 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/scim"
+)
+
+func toSCIMGroup(g *models.SCIMGroup, members []*models.User) *scim.Group {
+	sg := &scim.Group{
+		Schemas:     []string{scim.SchemaGroup},
+		ID:          strconv.FormatInt(g.ID, 10),
+		ExternalID:  g.ExternalID,
+		DisplayName: g.DisplayName,
+		Members:     make([]scim.Reference, 0, len(members)),
+		Meta: &scim.Meta{
+			ResourceType: "Group",
+			Created:      g.CreatedUnix.AsTime(),
+			LastModified: g.UpdatedUnix.AsTime(),
+			Location:     location("Groups", g.ID),
+		},
+	}
+	for _, u := range members {
+		sg.Members = append(sg.Members, scim.Reference{
+			Value:   strconv.FormatInt(u.ID, 10),
+			Ref:     location("Users", u.ID),
+			Display: u.Name,
+		})
+	}
+	return sg
+}
+
+// excludesMembers returns true if the members should not be returned,
+// which saves loading the members of large groups
+func excludesMembers(ctx *context.APIContext) bool {
+	for _, attr := range strings.Split(ctx.Query("excludedAttributes"), ",") {
+		if strings.EqualFold(strings.TrimSpace(attr), "members") {
+			return true
+		}
+	}
+	return false
+}
+
+// loadSCIMGroup converts the group with its members unless they are excluded
+func loadSCIMGroup(ctx *context.APIContext, g *models.SCIMGroup) (*scim.Group, error) {
+	if excludesMembers(ctx) {
+		return toSCIMGroup(g, nil), nil
+	}
+	ids, err := models.GetSCIMGroupMemberIDs(g.ID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := models.GetUsersByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	return toSCIMGroup(g, members), nil
+}
+
+// respondGroup writes the SCIM representation of the group
+func respondGroup(ctx *context.APIContext, status int, g *models.SCIMGroup) {
+	sg, err := loadSCIMGroup(ctx, g)
+	if err != nil {
+		serverError(ctx, "loadSCIMGroup", err)
+		return
+	}
+	if status == http.StatusCreated {
+		ctx.Resp.Header().Set("Location", location("Groups", g.ID))
+	}
+	respond(ctx, status, sg)
+}
+
+// getGroup returns the group of the id parameter
+func getGroup(ctx *context.APIContext) *models.SCIMGroup {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusNotFound, "", "group does not exist")
+		return nil
+	}
+	g, err := models.GetSCIMGroupByID(id)
+	if err != nil {
+		if models.IsErrSCIMGroupNotExist(err) {
+			respondError(ctx, http.StatusNotFound, "", "group does not exist")
+		} else {
+			serverError(ctx, "GetSCIMGroupByID", err)
+		}
+		return nil
+	}
+	return g
+}
+
+// resolveMembers returns the IDs of the users referenced as members
+func resolveMembers(ctx *context.APIContext, members []scim.Reference) ([]int64, bool) {
+	ids := make([]int64, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseInt(member.Value, 10, 64)
+		if err != nil {
+			respondError(ctx, http.StatusBadRequest, scim.ErrorInvalidValue, "member "+member.Value+" does not exist")
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	users, err := models.GetUsersByIDs(ids)
+	if err != nil {
+		serverError(ctx, "GetUsersByIDs", err)
+		return nil, false
+	}
+	exists := make(map[int64]bool, len(users))
+	for _, u := range users {
+		exists[u.ID] = !u.IsOrganization()
+	}
+	for _, id := range ids {
+		if !exists[id] {
+			respondError(ctx, http.StatusBadRequest, scim.ErrorInvalidValue, "member "+strconv.FormatInt(id, 10)+" does not exist")
+			return nil, false
+		}
+	}
+	return ids, true
+}
+
+// syncTeams synchronizes the team memberships of the users whose groups changed
+func syncTeams(userIDs []int64) {
+	for _, id := range userIDs {
+		u, err := models.GetUserByID(id)
+		if err != nil {
+			log.Error("GetUserByID[%d]: %v", id, err)
+			continue
+		}
+		if err := models.SyncSCIMUserTeams(u); err != nil {
+			log.Error("SyncSCIMUserTeams[%s]: %v", u.Name, err)
+		}
+	}
+}
+
+// changedMembers returns the users which are only in one of both lists
+func changedMembers(oldIDs, newIDs []int64) []int64 {
+	count := make(map[int64]int, len(oldIDs)+len(newIDs))
+	for _, id := range oldIDs {
+		count[id]++
+	}
+	for _, id := range newIDs {
+		count[id]--
+	}
+	changed := make([]int64, 0, len(count))
+	for _, id := range append(oldIDs, newIDs...) {
+		if count[id] != 0 {
+			changed = append(changed, id)
+			count[id] = 0
+		}
+	}
+	return changed
+}
+
+// ListGroups lists the groups matching a filter on displayName or externalId
+func ListGroups(ctx *context.APIContext) {
+	filter, err := scim.ParseFilter(ctx.Query("filter"))
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, scim.ErrorInvalidFilter, err.Error())
+		return
+	}
+	startIndex, count := paging(ctx)
+	opts := &models.FindSCIMGroupsOptions{
+		Offset: startIndex - 1,
+		Limit:  count,
+	}
+	for _, c := range filter {
+		switch c.Attribute {
+		case "displayname":
+			opts.DisplayName = c.Value
+		case "externalid":
+			opts.ExternalID = c.Value
+		default:
+			respondError(ctx, http.StatusBadRequest, scim.ErrorInvalidFilter, "unsupported filter attribute "+c.Attribute)
+			return
+		}
+	}
+
+	groups, total, err := models.FindSCIMGroups(opts)
+	if err != nil {
+		serverError(ctx, "FindSCIMGroups", err)
+		return
+	}
+	resources := make([]interface{}, 0, len(groups))
+	for _, g := range groups {
+		sg, err := loadSCIMGroup(ctx, g)
+		if err != nil {
+			serverError(ctx, "loadSCIMGroup", err)
+			return
+		}
+		resources = append(resources, sg)
+	}
+	respond(ctx, http.StatusOK, scim.NewListResponse(total, startIndex, resources))
+}
+
+// GetGroup returns a group
+func GetGroup(ctx *context.APIContext) {
+	g := getGroup(ctx)
+	if g == nil {
+		return
+	}
+	respondGroup(ctx, http.StatusOK, g)
+}
+
+// CreateGroup creates a group with its members and adds them to the mapped teams
+func CreateGroup(ctx *context.APIContext) {
+	sg := new(scim.Group)
+	if !decode(ctx, sg) {
+		return
+	}
+	if strings.TrimSpace(sg.DisplayName) == "" {
+		respondError(ctx, http.StatusBadRequest, scim.ErrorInvalidValue, "displayName is required")
+		return
+	}
+	memberIDs, ok := resolveMembers(ctx, sg.Members)
+	if !ok {
+		return
+	}
+
+	g := &models.SCIMGroup{
+		DisplayName: sg.DisplayName,
+		ExternalID:  sg.ExternalID,
+	}
+	if err := models.NewSCIMGroup(g, memberIDs); err != nil {
+		if models.IsErrSCIMGroupAlreadyExist(err) {
+			respondError(ctx, http.StatusConflict, scim.ErrorUniqueness, err.Error())
+		} else {
+			serverError(ctx, "NewSCIMGroup", err)
+		}
+		return
+	}
+	log.Trace("SCIM group created by %s: %s", ctx.User.Name, g.DisplayName)
+
+	syncTeams(memberIDs)
+	respondGroup(ctx, http.StatusCreated, g)
+}
+
+// updateGroup applies the full representation of a group, which is the result of
+// a replacement or a patch, synchronizes the teams of the affected users and
+// writes the updated group
+func updateGroup(ctx *context.APIContext, g *models.SCIMGroup, oldMemberIDs []int64, sg *scim.Group) {
+	if strings.TrimSpace(sg.DisplayName) == "" {
+		respondError(ctx, http.StatusBadRequest, scim.ErrorInvalidValue, "displayName is required")
+		return
+	}
+	memberIDs, ok := resolveMembers(ctx, sg.Members)
+	if !ok {
+		return
+	}
+
+	renamed := !strings.EqualFold(g.DisplayName, sg.DisplayName)
+	if g.DisplayName != sg.DisplayName || g.ExternalID != sg.ExternalID {
+		g.DisplayName = sg.DisplayName
+		g.ExternalID = sg.ExternalID
+		if err := models.UpdateSCIMGroup(g); err != nil {
+			if models.IsErrSCIMGroupAlreadyExist(err) {
+				respondError(ctx, http.StatusConflict, scim.ErrorUniqueness, err.Error())
+			} else {
+				serverError(ctx, "UpdateSCIMGroup", err)
+			}
+			return
+		}
+	}
+	if err := models.ReplaceSCIMGroupMembers(g.ID, memberIDs); err != nil {
+		serverError(ctx, "ReplaceSCIMGroupMembers", err)
+		return
+	}
+
+	// a renamed group may be mapped to other teams
+	if renamed {
+		syncTeams(changedMembers(nil, append(oldMemberIDs, memberIDs...)))
+	} else {
+		syncTeams(changedMembers(oldMemberIDs, memberIDs))
+	}
+	respondGroup(ctx, http.StatusOK, g)
+}
+
+// ReplaceGroup replaces the display name and the members of a group
+func ReplaceGroup(ctx *context.APIContext) {
+	g := getGroup(ctx)
+	if g == nil {
+		return
+	}
+	oldMemberIDs, err := models.GetSCIMGroupMemberIDs(g.ID)
+	if err != nil {
+		serverError(ctx, "GetSCIMGroupMemberIDs", err)
+		return
+	}
+	sg := new(scim.Group)
+	if !decode(ctx, sg) {
+		return
+	}
+	updateGroup(ctx, g, oldMemberIDs, sg)
+}
+
+// PatchGroup modifies a group, e.g. adds or removes members
+func PatchGroup(ctx *context.APIContext) {
+	g := getGroup(ctx)
+	if g == nil {
+		return
+	}
+	oldMemberIDs, err := models.GetSCIMGroupMemberIDs(g.ID)
+	if err != nil {
+		serverError(ctx, "GetSCIMGroupMemberIDs", err)
+		return
+	}
+	req := new(scim.PatchRequest)
+	if !decode(ctx, req) {
+		return
+	}
+
+	sg := &scim.Group{
+		DisplayName: g.DisplayName,
+		ExternalID:  g.ExternalID,
+		Members:     make([]scim.Reference, 0, len(oldMemberIDs)),
+	}
+	for _, id := range oldMemberIDs {
+		sg.Members = append(sg.Members, scim.Reference{Value: strconv.FormatInt(id, 10)})
+	}
+	if err := sg.ApplyPatch(req.Operations); err != nil {
+		patchErr := err.(*scim.PatchError)
+		respondError(ctx, http.StatusBadRequest, patchErr.ScimType, patchErr.Detail)
+		return
+	}
+	updateGroup(ctx, g, oldMemberIDs, sg)
+}
+
+// DeleteGroup deletes a group and removes its members from the mapped teams
+func DeleteGroup(ctx *context.APIContext) {
+	g := getGroup(ctx)
+	if g == nil {
+		return
+	}
+	memberIDs, err := models.GetSCIMGroupMemberIDs(g.ID)
+	if err != nil {
+		serverError(ctx, "GetSCIMGroupMemberIDs", err)
+		return
+	}
+	if err := models.DeleteSCIMGroup(g); err != nil {
+		serverError(ctx, "DeleteSCIMGroup", err)
+		return
+	}
+	log.Trace("SCIM group deleted by %s: %s", ctx.User.Name, g.DisplayName)
+
+	syncTeams(memberIDs)
+	ctx.Status(http.StatusNoContent)
+}
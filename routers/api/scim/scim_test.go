@@ -0,0 +1,104 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mockSCIMContext(t *testing.T, path, body string) *context.APIContext {
+	ctx := test.MockContext(t, path)
+	test.LoadUser(t, ctx, 1)
+	ctx.Req.Body = ioutil.NopCloser(bytes.NewBufferString(body))
+	return &context.APIContext{Context: ctx}
+}
+
+func TestSCIMUserAndGroup(t *testing.T) {
+	models.PrepareTestEnv(t)
+	defer func(mapping map[string]map[string][]string, removal bool) {
+		setting.SCIM.TeamMapping, setting.SCIM.GroupTeamMapRemoval = mapping, removal
+	}(setting.SCIM.TeamMapping, setting.SCIM.GroupTeamMapRemoval)
+	setting.SCIM.TeamMapping = map[string]map[string][]string{"developers": {"user3": {"team1"}}}
+	setting.SCIM.GroupTeamMapRemoval = true
+
+	ctx := mockSCIMContext(t, "/Users", `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"userName": "scimuser",
+		"name": {"givenName": "Scim", "familyName": "User"},
+		"emails": [{"value": "scimuser@example.com", "primary": true}]
+	}`)
+	CreateUser(ctx)
+	assert.EqualValues(t, http.StatusCreated, ctx.Resp.Status())
+	user := models.AssertExistsAndLoadBean(t, &models.User{Name: "scimuser"}).(*models.User)
+	assert.Equal(t, "Scim User", user.FullName)
+	assert.Equal(t, "scimuser@example.com", user.Email)
+	assert.False(t, user.ProhibitLogin)
+
+	ctx = mockSCIMContext(t, "/Users", `{"userName": "scimuser", "emails": [{"value": "other@example.com"}]}`)
+	CreateUser(ctx)
+	assert.EqualValues(t, http.StatusConflict, ctx.Resp.Status())
+
+	userID := strconv.FormatInt(user.ID, 10)
+	ctx = mockSCIMContext(t, "/Groups", `{"displayName": "Developers", "members": [{"value": "`+userID+`"}]}`)
+	CreateGroup(ctx)
+	assert.EqualValues(t, http.StatusCreated, ctx.Resp.Status())
+	group := models.AssertExistsAndLoadBean(t, &models.SCIMGroup{LowerName: "developers"}).(*models.SCIMGroup)
+	models.AssertExistsAndLoadBean(t, &models.SCIMGroupMember{GroupID: group.ID, UserID: user.ID})
+	models.AssertExistsAndLoadBean(t, &models.TeamUser{TeamID: 2, UID: user.ID})
+
+	ctx = mockSCIMContext(t, "/Groups", `{"displayName": "developers"}`)
+	CreateGroup(ctx)
+	assert.EqualValues(t, http.StatusConflict, ctx.Resp.Status())
+
+	ctx = mockSCIMContext(t, "/Groups", `{"displayName": "Testers", "members": [{"value": "3"}]}`)
+	CreateGroup(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, ctx.Resp.Status(), "organizations cannot be members")
+
+	ctx = mockSCIMContext(t, "/Groups/"+strconv.FormatInt(group.ID, 10), `{"Operations": [
+		{"op": "remove", "path": "members[value eq \"`+userID+`\"]"}
+	]}`)
+	ctx.SetParams("id", strconv.FormatInt(group.ID, 10))
+	PatchGroup(ctx)
+	assert.EqualValues(t, http.StatusOK, ctx.Resp.Status())
+	models.AssertNotExistsBean(t, &models.SCIMGroupMember{GroupID: group.ID, UserID: user.ID})
+	models.AssertNotExistsBean(t, &models.TeamUser{TeamID: 2, UID: user.ID})
+
+	ctx = mockSCIMContext(t, "/Users/"+userID, `{"Operations": [{"op": "Replace", "path": "active", "value": "False"}]}`)
+	ctx.SetParams("id", userID)
+	PatchUser(ctx)
+	assert.EqualValues(t, http.StatusOK, ctx.Resp.Status())
+	user = models.AssertExistsAndLoadBean(t, &models.User{ID: user.ID}).(*models.User)
+	assert.True(t, user.ProhibitLogin)
+	assert.Equal(t, "Scim User", user.FullName)
+
+	// the user of the token cannot deactivate themselves
+	ctx = mockSCIMContext(t, "/Users/1", `{"Operations": [{"op": "replace", "value": {"active": false}}]}`)
+	ctx.SetParams("id", "1")
+	PatchUser(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, ctx.Resp.Status())
+
+	ctx = mockSCIMContext(t, "/Groups/"+strconv.FormatInt(group.ID, 10), "")
+	ctx.SetParams("id", strconv.FormatInt(group.ID, 10))
+	DeleteGroup(ctx)
+	assert.EqualValues(t, http.StatusNoContent, ctx.Resp.Status())
+	models.AssertNotExistsBean(t, &models.SCIMGroup{ID: group.ID})
+
+	ctx = mockSCIMContext(t, "/Users/"+userID, "")
+	ctx.SetParams("id", userID)
+	DeleteUser(ctx)
+	assert.EqualValues(t, http.StatusNoContent, ctx.Resp.Status())
+	models.AssertNotExistsBean(t, &models.User{ID: user.ID})
+}
@@ -0,0 +1,340 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/generate"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/password"
+	"code.gitea.io/gitea/modules/scim"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+func toSCIMUser(u *models.User, groups []*models.SCIMGroup) *scim.User {
+	active := !u.ProhibitLogin
+	su := &scim.User{
+		Schemas:     []string{scim.SchemaUser},
+		ID:          strconv.FormatInt(u.ID, 10),
+		UserName:    u.Name,
+		DisplayName: u.FullName,
+		Active:      &active,
+		Meta: &scim.Meta{
+			ResourceType: "User",
+			Created:      u.CreatedUnix.AsTime(),
+			LastModified: u.UpdatedUnix.AsTime(),
+			Location:     location("Users", u.ID),
+		},
+	}
+	if u.FullName != "" {
+		su.Name = &scim.Name{Formatted: u.FullName}
+	}
+	if u.Email != "" {
+		su.Emails = []scim.Email{{Value: u.Email, Type: "work", Primary: true}}
+	}
+	for _, g := range groups {
+		su.Groups = append(su.Groups, scim.Reference{
+			Value:   strconv.FormatInt(g.ID, 10),
+			Ref:     location("Groups", g.ID),
+			Display: g.DisplayName,
+		})
+	}
+	return su
+}
+
+// getUser returns the individual user of the id parameter
+func getUser(ctx *context.APIContext) *models.User {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusNotFound, "", "user does not exist")
+		return nil
+	}
+	u, err := models.GetUserByID(id)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			respondError(ctx, http.StatusNotFound, "", "user does not exist")
+		} else {
+			serverError(ctx, "GetUserByID", err)
+		}
+		return nil
+	}
+	if u.IsOrganization() {
+		respondError(ctx, http.StatusNotFound, "", "user does not exist")
+		return nil
+	}
+	return u
+}
+
+// respondUser writes the SCIM representation of the user
+func respondUser(ctx *context.APIContext, status int, u *models.User) {
+	groups, err := models.GetUserSCIMGroups(u.ID)
+	if err != nil {
+		serverError(ctx, "GetUserSCIMGroups", err)
+		return
+	}
+	if status == http.StatusCreated {
+		ctx.Resp.Header().Set("Location", location("Users", u.ID))
+	}
+	respond(ctx, status, toSCIMUser(u, groups))
+}
+
+// checkPassword verifies that a password provided by the identity provider is acceptable
+func checkPassword(ctx *context.APIContext, passwd string) bool {
+	if !password.IsComplexEnough(passwd) {
+		respondError(ctx, http.StatusBadRequest, scim.ErrorInvalidValue, "password does not meet the complexity requirements")
+		return false
+	}
+	pwned, err := password.IsPwned(ctx.Req.Context(), passwd)
+	if err != nil {
+		log.Error(err.Error())
+	}
+	if pwned {
+		respondError(ctx, http.StatusBadRequest, scim.ErrorInvalidValue, "password has been exposed in a data breach")
+		return false
+	}
+	return true
+}
+
+// respondUserError writes the SCIM error of a failed user creation or update
+func respondUserError(ctx *context.APIContext, title string, err error) {
+	switch {
+	case models.IsErrUserAlreadyExist(err), models.IsErrEmailAlreadyUsed(err):
+		respondError(ctx, http.StatusConflict, scim.ErrorUniqueness, err.Error())
+	case models.IsErrNameReserved(err),
+		models.IsErrNamePatternNotAllowed(err),
+		models.IsErrNameCharsNotAllowed(err),
+		models.IsErrEmailInvalid(err):
+		respondError(ctx, http.StatusBadRequest, scim.ErrorInvalidValue, err.Error())
+	default:
+		serverError(ctx, title, err)
+	}
+}
+
+// ListUsers lists the users matching a filter on userName or emails
+func ListUsers(ctx *context.APIContext) {
+	filter, err := scim.ParseFilter(ctx.Query("filter"))
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, scim.ErrorInvalidFilter, err.Error())
+		return
+	}
+	startIndex, count := paging(ctx)
+	opts := &models.FindSCIMUsersOptions{
+		Offset: startIndex - 1,
+		Limit:  count,
+	}
+	for _, c := range filter {
+		switch c.Attribute {
+		case "username":
+			opts.UserName = c.Value
+		case "emails", "emails.value":
+			opts.Email = c.Value
+		default:
+			respondError(ctx, http.StatusBadRequest, scim.ErrorInvalidFilter, "unsupported filter attribute "+c.Attribute)
+			return
+		}
+	}
+
+	users, total, err := models.FindSCIMUsers(opts)
+	if err != nil {
+		serverError(ctx, "FindSCIMUsers", err)
+		return
+	}
+	resources := make([]interface{}, 0, len(users))
+	for _, u := range users {
+		groups, err := models.GetUserSCIMGroups(u.ID)
+		if err != nil {
+			serverError(ctx, "GetUserSCIMGroups", err)
+			return
+		}
+		resources = append(resources, toSCIMUser(u, groups))
+	}
+	respond(ctx, http.StatusOK, scim.NewListResponse(total, startIndex, resources))
+}
+
+// GetUser returns a user
+func GetUser(ctx *context.APIContext) {
+	u := getUser(ctx)
+	if u == nil {
+		return
+	}
+	respondUser(ctx, http.StatusOK, u)
+}
+
+// CreateUser provisions a new user
+func CreateUser(ctx *context.APIContext) {
+	su := new(scim.User)
+	if !decode(ctx, su) {
+		return
+	}
+	if su.UserName == "" || su.PrimaryEmail() == "" {
+		respondError(ctx, http.StatusBadRequest, scim.ErrorInvalidValue, "userName and an email address are required")
+		return
+	}
+
+	u := &models.User{
+		Name:      su.UserName,
+		FullName:  su.FullName(),
+		Email:     su.PrimaryEmail(),
+		IsActive:  true,
+		LoginType: models.LoginPlain,
+	}
+	if su.Active != nil {
+		u.ProhibitLogin = !*su.Active
+	}
+
+	if setting.SCIM.LoginSource != "" {
+		source, err := models.GetLoginSourceByName(setting.SCIM.LoginSource)
+		if err != nil {
+			serverError(ctx, "GetLoginSourceByName", err)
+			return
+		}
+		u.LoginType = source.Type
+		u.LoginSource = source.ID
+		u.LoginName = su.UserName
+	}
+
+	if su.Password != "" && u.IsLocal() {
+		if !checkPassword(ctx, su.Password) {
+			return
+		}
+		u.Passwd = su.Password
+	} else {
+		// users without a password sign in through the identity provider or reset their password
+		passwd, err := generate.GetRandomString(32)
+		if err != nil {
+			serverError(ctx, "GetRandomString", err)
+			return
+		}
+		u.Passwd = passwd
+	}
+
+	if err := models.CreateUser(u); err != nil {
+		respondUserError(ctx, "CreateUser", err)
+		return
+	}
+	log.Trace("Account provisioned through SCIM by %s: %s", ctx.User.Name, u.Name)
+
+	respondUser(ctx, http.StatusCreated, u)
+}
+
+// updateUser applies the full representation of a user, which is the result of
+// a replacement or a patch, and writes the updated user
+func updateUser(ctx *context.APIContext, u *models.User, su *scim.User) {
+	if su.UserName == "" || su.PrimaryEmail() == "" {
+		respondError(ctx, http.StatusBadRequest, scim.ErrorInvalidValue, "userName and an email address are required")
+		return
+	}
+
+	if su.Active != nil && !*su.Active && u.ID == ctx.User.ID {
+		respondError(ctx, http.StatusBadRequest, scim.ErrorMutability, "the user of the access token cannot be deactivated")
+		return
+	}
+
+	changePassword := su.Password != "" && u.IsLocal()
+	if changePassword && !checkPassword(ctx, su.Password) {
+		return
+	}
+
+	// renames which only change the case are ignored
+	if !strings.EqualFold(su.UserName, u.Name) {
+		if err := models.ChangeUserName(u, su.UserName); err != nil {
+			respondUserError(ctx, "ChangeUserName", err)
+			return
+		}
+		u.Name = su.UserName
+		u.LowerName = strings.ToLower(su.UserName)
+		if u.LoginSource > 0 {
+			u.LoginName = su.UserName
+		}
+	}
+
+	if changePassword {
+		var err error
+		if u.Salt, err = models.GetUserSalt(); err != nil {
+			serverError(ctx, "GetUserSalt", err)
+			return
+		}
+		if err = u.SetPassword(su.Password); err != nil {
+			serverError(ctx, "SetPassword", err)
+			return
+		}
+	}
+
+	u.FullName = su.FullName()
+	u.Email = su.PrimaryEmail()
+	if su.Active != nil {
+		u.ProhibitLogin = !*su.Active
+	}
+
+	if err := models.UpdateUserSetting(u); err != nil {
+		respondUserError(ctx, "UpdateUserSetting", err)
+		return
+	}
+	log.Trace("Account updated through SCIM by %s: %s", ctx.User.Name, u.Name)
+
+	respondUser(ctx, http.StatusOK, u)
+}
+
+// ReplaceUser replaces the attributes of a user
+func ReplaceUser(ctx *context.APIContext) {
+	u := getUser(ctx)
+	if u == nil {
+		return
+	}
+	su := new(scim.User)
+	if !decode(ctx, su) {
+		return
+	}
+	updateUser(ctx, u, su)
+}
+
+// PatchUser modifies some attributes of a user, e.g. deactivates them
+func PatchUser(ctx *context.APIContext) {
+	u := getUser(ctx)
+	if u == nil {
+		return
+	}
+	req := new(scim.PatchRequest)
+	if !decode(ctx, req) {
+		return
+	}
+
+	su := toSCIMUser(u, nil)
+	if err := su.ApplyPatch(req.Operations); err != nil {
+		patchErr := err.(*scim.PatchError)
+		respondError(ctx, http.StatusBadRequest, patchErr.ScimType, patchErr.Detail)
+		return
+	}
+	updateUser(ctx, u, su)
+}
+
+// DeleteUser deletes a user. Users who still own repositories or organizations
+// cannot be deleted and should be deactivated instead.
+func DeleteUser(ctx *context.APIContext) {
+	u := getUser(ctx)
+	if u == nil {
+		return
+	}
+	if u.ID == ctx.User.ID {
+		respondError(ctx, http.StatusConflict, "", "the user of the access token cannot be deleted")
+		return
+	}
+	if err := models.DeleteUser(u); err != nil {
+		if models.IsErrUserOwnRepos(err) || models.IsErrUserHasOrgs(err) {
+			respondError(ctx, http.StatusConflict, "", err.Error())
+		} else {
+			serverError(ctx, "DeleteUser", err)
+		}
+		return
+	}
+	log.Trace("Account deleted through SCIM by %s: %s", ctx.User.Name, u.Name)
+
+	ctx.Status(http.StatusNoContent)
+}
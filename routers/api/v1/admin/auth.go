@@ -0,0 +1,81 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// MigrateLoginSourceUsers api for migrating the users of an authentication source to another one
+func MigrateLoginSourceUsers(ctx *context.APIContext) {
+	// swagger:operation POST /admin/auths/migrate admin adminMigrateAuthUsers
+	// ---
+	// summary: Migrate the users of an authentication source to another one
+	// description: The users keep their SSH keys, access tokens and memberships. The users whose login name in the
+	//   target source is already taken, or shared with another migrated user, are not migrated.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/MigrateLoginSourceUsersOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/LoginSourceMigration"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	form := web.GetForm(ctx).(*api.MigrateLoginSourceUsersOption)
+	opts := &models.MigrateLoginSourceOptions{
+		FromSourceID: form.FromSourceID,
+		ToSourceID:   form.ToSourceID,
+		Mapping:      models.LoginSourceMapping(form.MapBy),
+		Usernames:    form.Usernames,
+	}
+	if len(opts.Mapping) == 0 {
+		opts.Mapping = models.LoginSourceMappingUsername
+	}
+
+	var migration *models.LoginSourceMigration
+	var err error
+	if form.DryRun {
+		migration, err = models.PreviewLoginSourceMigration(opts)
+	} else {
+		migration, err = models.MigrateLoginSource(opts)
+	}
+	if err != nil {
+		if models.IsErrLoginSourceNotExist(err) || models.IsErrLoginSourceMigrationInvalid(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "MigrateLoginSource", err)
+		}
+		return
+	}
+
+	result := &api.LoginSourceMigration{
+		DryRun:   form.DryRun,
+		Migrated: len(migration.Users) - len(migration.Conflicts()),
+		Users:    make([]*api.LoginSourceMigrationUser, 0, len(migration.Users)),
+	}
+	for _, mu := range migration.Users {
+		result.Users = append(result.Users, &api.LoginSourceMigrationUser{
+			ID:        mu.User.ID,
+			Username:  mu.User.Name,
+			LoginName: mu.LoginName,
+			Conflict:  mu.Conflict,
+		})
+	}
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// GetEffectiveConfig api for getting the values of all settings used by the instance
+func GetEffectiveConfig(ctx *context.APIContext) {
+	// swagger:operation GET /admin/config admin adminGetEffectiveConfig
+	// ---
+	// summary: Get the effective value and source of all settings, with secrets masked
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: source
+	//   in: query
+	//   description: only return the settings from this source
+	//   type: string
+	//   enum: [default, file, environment]
+	// - name: q
+	//   in: query
+	//   description: only return the settings whose section or key contains this keyword
+	//   type: string
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ConfigValueList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	values := setting.FilterEffectiveConfig(setting.EffectiveConfig(), setting.ConfigSource(ctx.Query("source")), ctx.Query("q"))
+
+	res := make([]*api.ConfigValue, len(values))
+	for i, value := range values {
+		res[i] = &api.ConfigValue{
+			Section: value.Section,
+			Key:     value.Key,
+			Value:   value.Value,
+			Source:  string(value.Source),
+			Masked:  value.Masked,
+		}
+	}
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+func listLogModuleLevels() []*api.LogModuleLevel {
+	levels := log.GetModuleLevels()
+	res := make([]*api.LogModuleLevel, 0, len(levels))
+	for module, level := range levels {
+		res = append(res, &api.LogModuleLevel{
+			Module: module,
+			Level:  level.String(),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Module < res[j].Module
+	})
+	return res
+}
+
+// ListLogModuleLevels api for getting the log levels of modules
+func ListLogModuleLevels(ctx *context.APIContext) {
+	// swagger:operation GET /admin/logging/modules admin adminListLogModuleLevels
+	// ---
+	// summary: List the log levels of modules
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/LogModuleLevelList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	ctx.JSON(http.StatusOK, listLogModuleLevels())
+}
+
+// EditLogModuleLevels api for setting the log levels of modules at runtime
+func EditLogModuleLevels(ctx *context.APIContext) {
+	// swagger:operation PATCH /admin/logging/modules admin adminEditLogModuleLevels
+	// ---
+	// summary: Set or remove the log levels of modules
+	// description: The levels are not persisted and are reset to the MODULE_LEVELS of the configuration on restart.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/EditLogModuleLevelsOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/LogModuleLevelList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	form := web.GetForm(ctx).(*api.EditLogModuleLevelsOption)
+
+	validLevels := log.Levels()
+	for _, m := range form.Modules {
+		if strings.Trim(m.Module, "/ ") == "" {
+			ctx.Error(http.StatusUnprocessableEntity, "", "module must not be empty")
+			return
+		}
+		if m.Level == "" {
+			continue
+		}
+		valid := false
+		for _, level := range validLevels {
+			if strings.EqualFold(m.Level, level) {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("invalid level %q of module %s", m.Level, m.Module))
+			return
+		}
+	}
+
+	for _, m := range form.Modules {
+		if m.Level == "" {
+			log.RemoveModuleLevel(m.Module)
+			log.Info("Log level of module %s removed by admin(%s)", m.Module, ctx.User.Name)
+			continue
+		}
+		log.SetModuleLevel(m.Module, log.FromString(m.Level))
+		log.Info("Log level of module %s set to %s by admin(%s)", m.Module, strings.ToLower(m.Level), ctx.User.Name)
+	}
+
+	ctx.JSON(http.StatusOK, listLogModuleLevels())
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/maintenance"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+func toMaintenanceMode(state maintenance.State) *api.MaintenanceMode {
+	mode := &api.MaintenanceMode{
+		Enabled: state.Enabled,
+		Message: state.Message,
+	}
+	if state.Enabled {
+		mode.Since = &state.Since
+	}
+	return mode
+}
+
+// GetMaintenanceMode api for getting the maintenance mode of the instance
+func GetMaintenanceMode(ctx *context.APIContext) {
+	// swagger:operation GET /admin/maintenance admin adminGetMaintenanceMode
+	// ---
+	// summary: Get the maintenance mode of the instance
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MaintenanceMode"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	ctx.JSON(http.StatusOK, toMaintenanceMode(maintenance.GetState()))
+}
+
+// EnableMaintenanceMode api for enabling the maintenance mode of the instance
+func EnableMaintenanceMode(ctx *context.APIContext) {
+	// swagger:operation PUT /admin/maintenance admin adminEnableMaintenanceMode
+	// ---
+	// summary: Enable the maintenance mode of the instance
+	// description: Only site administrators can use Gitea during the maintenance, everyone else has read-only git access.
+	//   The queues are paused until the maintenance mode is disabled. The mode is not persisted and is reset to the
+	//   configuration on restart.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EnableMaintenanceModeOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MaintenanceMode"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	form := web.GetForm(ctx).(*api.EnableMaintenanceModeOption)
+	maintenance.Enable(form.Message)
+	ctx.JSON(http.StatusOK, toMaintenanceMode(maintenance.GetState()))
+}
+
+// DisableMaintenanceMode api for disabling the maintenance mode of the instance
+func DisableMaintenanceMode(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/maintenance admin adminDisableMaintenanceMode
+	// ---
+	// summary: Disable the maintenance mode of the instance
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	maintenance.Disable()
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/user"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListPolicyDocuments api for listing the versions of the terms of service and of the privacy policy
+func ListPolicyDocuments(ctx *context.APIContext) {
+	// swagger:operation GET /admin/policies admin adminListPolicyDocuments
+	// ---
+	// summary: List all the versions of the terms of service and of the privacy policy, most recent first
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PolicyDocumentList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	docs, err := models.GetPolicyDocuments()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPolicyDocuments", err)
+		return
+	}
+
+	apiDocs := make([]*api.PolicyDocument, len(docs))
+	for i := range docs {
+		apiDocs[i] = convert.ToPolicyDocument(docs[i])
+	}
+	ctx.JSON(http.StatusOK, &apiDocs)
+}
+
+// ListPolicyDocumentConsents api for listing the users who accepted a version of a policy document
+func ListPolicyDocumentConsents(ctx *context.APIContext) {
+	// swagger:operation GET /admin/policies/{id}/consents admin adminListPolicyDocumentConsents
+	// ---
+	// summary: List the users who accepted a version of a policy document, in acceptance order
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the policy document
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PolicyConsentList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	doc, err := models.GetPolicyDocumentByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrPolicyDocumentNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPolicyDocumentByID", err)
+		}
+		return
+	}
+
+	listOptions := utils.GetListOptions(ctx)
+	consents, count, err := models.GetPolicyDocumentConsents(doc.ID, listOptions)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPolicyDocumentConsents", err)
+		return
+	}
+
+	apiConsents := make([]*api.PolicyConsent, len(consents))
+	for i := range consents {
+		apiConsents[i] = &api.PolicyConsent{
+			User:     convert.ToUser(consents[i].User, ctx.IsSigned, ctx.User.IsAdmin),
+			Accepted: consents[i].AcceptedUnix.AsTime(),
+		}
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, Link")
+	ctx.JSON(http.StatusOK, &apiConsents)
+}
+
+// ListUserPolicyConsents api for getting the consents of a user to the current policy documents
+func ListUserPolicyConsents(ctx *context.APIContext) {
+	// swagger:operation GET /admin/users/{username}/policies admin adminListUserPolicyConsents
+	// ---
+	// summary: Get the consents of a user to the current terms of service and privacy policy
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of the user
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PolicyConsentStateList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	u := user.GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	states, err := models.GetPolicyConsentStates(u.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPolicyConsentStates", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToPolicyConsentStates(states))
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/instancestats"
+)
+
+// GetStorageStatistic api for getting the latest storage statistics
+func GetStorageStatistic(ctx *context.APIContext) {
+	// swagger:operation GET /admin/statistics admin adminGetStorageStatistic
+	// ---
+	// summary: Get the latest storage and growth statistics of the instance
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/StorageStatistic"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	stat, err := models.GetLatestStorageStatistic()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetLatestStorageStatistic", err)
+		return
+	}
+	if stat == nil {
+		ctx.NotFound()
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToStorageStatistic(stat))
+}
+
+// ListStorageStatistics api for getting the history of the storage statistics
+func ListStorageStatistics(ctx *context.APIContext) {
+	// swagger:operation GET /admin/statistics/history admin adminListStorageStatistics
+	// ---
+	// summary: List the storage and growth statistics of the instance, most recent first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/StorageStatisticList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	listOptions := utils.GetListOptions(ctx)
+
+	stats, count, err := models.GetStorageStatistics(listOptions)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetStorageStatistics", err)
+		return
+	}
+
+	apiStats := make([]*api.StorageStatistic, len(stats))
+	for i := range stats {
+		apiStats[i] = convert.ToStorageStatistic(stats[i])
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, Link")
+	ctx.JSON(http.StatusOK, &apiStats)
+}
+
+// GetInstanceStats api for getting the anonymized statistics of the instance
+func GetInstanceStats(ctx *context.APIContext) {
+	// swagger:operation GET /admin/stats admin adminGetInstanceStats
+	// ---
+	// summary: Get the anonymized statistics of the instance, as reported to the statistics endpoint
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/InstanceStats"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	ctx.JSON(http.StatusOK, instancestats.Collect())
+}
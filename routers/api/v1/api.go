@@ -73,6 +73,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	auth "code.gitea.io/gitea/modules/forms"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/maintenance"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
@@ -521,11 +522,29 @@ func mustEnableIssuesOrPulls(ctx *context.APIContext) {
 	}
 }
 
+func mustEnableArtifacts(ctx *context.APIContext) {
+	if !setting.Artifacts.Enabled {
+		ctx.NotFound()
+		return
+	}
+}
+
+func mustEnableProjects(ctx *context.APIContext) {
+	if models.UnitTypeProjects.UnitGlobalDisabled() {
+		ctx.NotFound()
+		return
+	}
+}
+
 func mustNotBeArchived(ctx *context.APIContext) {
 	if ctx.Repo.Repository.IsArchived {
 		ctx.NotFound()
 		return
 	}
+	if ctx.Repo.Repository.IsUnderMaintenance() && !ctx.Repo.IsAdmin() {
+		ctx.NotFound()
+		return
+	}
 }
 
 // bind binding an obj to a func(ctx *context.APIContext)
@@ -579,6 +598,7 @@ func Routes() *web.Route {
 	m.Use(context.ToggleAPI(&context.ToggleOptions{
 		SignInRequired: setting.Service.RequireSignInView,
 	}))
+	m.Use(checkMaintenanceMode)
 
 	m.Group("", func() {
 		// Miscellaneous
@@ -621,6 +641,7 @@ func Routes() *web.Route {
 				}
 
 				m.Get("/repos", reqExploreSignIn(), user.ListUserRepos)
+				m.Get("/profile_fields", reqExploreSignIn(), user.ListProfileFields)
 				m.Group("/tokens", func() {
 					m.Combo("").Get(user.ListAccessTokens).
 						Post(bind(api.CreateAccessTokenOption{}), user.CreateAccessToken)
@@ -651,6 +672,15 @@ func Routes() *web.Route {
 			m.Combo("/emails").Get(user.ListEmails).
 				Post(bind(api.CreateEmailOption{}), user.AddEmail).
 				Delete(bind(api.DeleteEmailOption{}), user.DeleteEmail)
+			m.Group("/filters", func() {
+				m.Combo("").Get(user.ListMyFilters).
+					Post(bind(api.CreateUserFilterOption{}), user.CreateMyFilter)
+				m.Combo("/{id}").Get(user.GetMyFilter).
+					Delete(user.DeleteMyFilter)
+			})
+			m.Get("/policies", user.ListMyPolicyConsents)
+			m.Combo("/profile_fields").Get(user.ListMyProfileFields).
+				Patch(bind(api.EditUserProfileFieldsOption{}), user.EditMyProfileFields)
 
 			m.Get("/followers", user.ListMyFollowers)
 			m.Group("/following", func() {
@@ -780,6 +810,8 @@ func Routes() *web.Route {
 					m.Combo("/{id}").Get(repo.GetDeployKey).
 						Delete(repo.DeleteDeploykey)
 				}, reqToken(), reqAdmin())
+				m.Get("/insights/issues", mustEnableIssues, reqToken(), reqRepoWriter(models.UnitTypeIssues), repo.GetIssueInsights)
+				m.Get("/insights/times", mustEnableIssues, reqToken(), repo.SumTrackedTimesByRepository)
 				m.Group("/times", func() {
 					m.Combo("").Get(repo.ListTrackedTimesByRepository)
 					m.Combo("/{timetrackingusername}").Get(repo.ListTrackedTimesByUser)
@@ -798,6 +830,7 @@ func Routes() *web.Route {
 								Get(repo.GetIssueCommentReactions).
 								Post(reqToken(), bind(api.EditReactionOption{}), repo.PostIssueCommentReaction).
 								Delete(reqToken(), bind(api.EditReactionOption{}), repo.DeleteIssueCommentReaction)
+							m.Get("/content_history", repo.GetIssueCommentContentHistory)
 						})
 					})
 					m.Group("/{index}", func() {
@@ -809,6 +842,7 @@ func Routes() *web.Route {
 							m.Combo("/{id}", reqToken()).Patch(bind(api.EditIssueCommentOption{}), repo.EditIssueCommentDeprecated).
 								Delete(repo.DeleteIssueCommentDeprecated)
 						})
+						m.Get("/timeline", repo.ListIssueCommentsAndTimeline)
 						m.Group("/labels", func() {
 							m.Combo("").Get(repo.ListIssueLabels).
 								Post(reqToken(), bind(api.IssueLabelsOption{}), repo.AddIssueLabels).
@@ -830,15 +864,24 @@ func Routes() *web.Route {
 							m.Delete("/delete", reqToken(), repo.DeleteIssueStopwatch)
 						})
 						m.Group("/subscriptions", func() {
-							m.Get("", repo.GetIssueSubscribers)
+							m.Combo("").Get(repo.GetIssueSubscribers).
+								Post(reqToken(), bind(api.IssueSubscribersOption{}), repo.AddIssueSubscribers).
+								Delete(reqToken(), bind(api.IssueSubscribersOption{}), repo.DelIssueSubscribers)
 							m.Get("/check", reqToken(), repo.CheckIssueSubscription)
 							m.Put("/{user}", reqToken(), repo.AddIssueSubscription)
 							m.Delete("/{user}", reqToken(), repo.DelIssueSubscription)
 						})
+						m.Group("/sub_issues", func() {
+							m.Combo("").Get(repo.GetSubIssueTree).
+								Post(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeIssues), bind(api.AddSubIssueOption{}), repo.AddSubIssue)
+							m.Delete("/{sub_index}", reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeIssues), repo.RemoveSubIssue)
+						})
 						m.Combo("/reactions").
 							Get(repo.GetIssueReactions).
 							Post(reqToken(), bind(api.EditReactionOption{}), repo.PostIssueReaction).
 							Delete(reqToken(), bind(api.EditReactionOption{}), repo.DeleteIssueReaction)
+						m.Get("/reactions/counts", repo.GetIssueReactionCounts)
+						m.Get("/content_history", repo.GetIssueContentHistory)
 					})
 				}, mustEnableIssuesOrPulls)
 				m.Group("/labels", func() {
@@ -848,6 +891,13 @@ func Routes() *web.Route {
 						Patch(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.EditLabelOption{}), repo.EditLabel).
 						Delete(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), repo.DeleteLabel)
 				})
+				m.Group("/issue_types", func() {
+					m.Combo("").Get(repo.ListIssueTypes).
+						Post(reqToken(), reqAdmin(), bind(api.CreateIssueTypeOption{}), repo.CreateIssueType)
+					m.Combo("/{id}").Get(repo.GetIssueType).
+						Patch(reqToken(), reqAdmin(), bind(api.EditIssueTypeOption{}), repo.EditIssueType).
+						Delete(reqToken(), reqAdmin(), repo.DeleteIssueType)
+				}, mustEnableIssues)
 				m.Post("/markdown", bind(api.MarkdownOption{}), misc.Markdown)
 				m.Post("/markdown/raw", misc.MarkdownRaw)
 				m.Group("/milestones", func() {
@@ -856,6 +906,7 @@ func Routes() *web.Route {
 					m.Combo("/{id}").Get(repo.GetMilestone).
 						Patch(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.EditMilestoneOption{}), repo.EditMilestone).
 						Delete(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), repo.DeleteMilestone)
+					m.Get("/{id}/stats", repo.GetMilestoneStats)
 				})
 				m.Get("/stargazers", repo.ListStargazers)
 				m.Get("/subscribers", repo.ListSubscribers)
@@ -886,6 +937,19 @@ func Routes() *web.Route {
 					})
 				}, reqRepoReader(models.UnitTypeReleases))
 				m.Post("/mirror-sync", reqToken(), reqRepoWriter(models.UnitTypeCode), repo.MirrorSync)
+				m.Combo("/mirror", reqToken(), reqAdmin()).Get(repo.GetMirror).
+					Patch(bind(api.EditMirrorOption{}), repo.EditMirror).
+					Delete(reqOwner(), repo.DeleteMirror)
+				m.Group("/push_mirrors", func() {
+					m.Combo("").Get(repo.ListPushMirrors).
+						Post(mustNotBeArchived, bind(api.CreatePushMirrorOption{}), repo.CreatePushMirror)
+					m.Group("/{id}", func() {
+						m.Combo("").Get(repo.GetPushMirror).
+							Patch(bind(api.EditPushMirrorOption{}), repo.EditPushMirror).
+							Delete(repo.DeletePushMirror)
+						m.Post("/sync", repo.SyncPushMirror)
+					})
+				}, reqToken(), reqAdmin())
 				m.Get("/editorconfig/{filename}", context.RepoRefForAPI, reqRepoReader(models.UnitTypeCode), repo.GetEditorconfig)
 				m.Group("/pulls", func() {
 					m.Combo("").Get(repo.ListPullRequests).
@@ -896,8 +960,15 @@ func Routes() *web.Route {
 						m.Get(".diff", repo.DownloadPullDiff)
 						m.Get(".patch", repo.DownloadPullPatch)
 						m.Post("/update", reqToken(), repo.UpdatePullRequest)
+						m.Post("/convert", reqToken(), mustNotBeArchived, repo.ConvertPullRequestToIssue)
 						m.Combo("/merge").Get(repo.IsPullRequestMerged).
-							Post(reqToken(), mustNotBeArchived, bind(auth.MergePullRequestForm{}), repo.MergePullRequest)
+							Post(reqToken(), mustNotBeArchived, bind(auth.MergePullRequestForm{}), repo.MergePullRequest).
+							Delete(reqToken(), mustNotBeArchived, repo.CancelScheduledAutoMerge)
+						m.Combo("/merge_queue").Get(repo.GetPullMergeQueueEntry).
+							Delete(reqToken(), mustNotBeArchived, repo.RemovePullFromMergeQueue)
+						m.Combo("/requirements").Get(repo.ListPullMergeRequirements).
+							Post(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeCode), bind(api.SetPullMergeRequirementOption{}), repo.SetPullMergeRequirement)
+						m.Delete("/requirements/{id}", reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeCode), repo.DeletePullMergeRequirement)
 						m.Group("/reviews", func() {
 							m.Combo("").
 								Get(repo.ListPullReviews).
@@ -908,7 +979,16 @@ func Routes() *web.Route {
 									Delete(reqToken(), repo.DeletePullReview).
 									Post(reqToken(), bind(api.SubmitPullReviewOptions{}), repo.SubmitPullReview)
 								m.Combo("/comments").
-									Get(repo.GetPullReviewComments)
+									Get(repo.GetPullReviewComments).
+									Post(reqToken(), bind(api.CreatePullReviewComment{}), repo.CreatePullReviewComment)
+								m.Group("/comments/{comment}", func() {
+									m.Delete("", reqToken(), repo.DeletePullReviewComment)
+									m.Post("/apply_suggestion", reqToken(), repo.ApplyPullReviewSuggestion)
+									m.Combo("/assets").
+										Get(repo.ListPullReviewCommentAttachments).
+										Post(reqToken(), repo.CreatePullReviewCommentAttachment)
+									m.Delete("/assets/{asset}", reqToken(), repo.DeletePullReviewCommentAttachment)
+								})
 								m.Post("/dismissals", reqToken(), bind(api.DismissPullReviewOptions{}), repo.DismissPullReview)
 								m.Post("/undismissals", reqToken(), repo.UnDismissPullReview)
 							})
@@ -918,10 +998,21 @@ func Routes() *web.Route {
 							Post(reqToken(), bind(api.PullReviewRequestOptions{}), repo.CreateReviewRequests)
 					})
 				}, mustAllowPulls, reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(false))
+				m.Get("/merge_queue", mustAllowPulls, reqRepoReader(models.UnitTypeCode), repo.ListMergeQueue)
 				m.Group("/statuses", func() {
 					m.Combo("/{sha}").Get(repo.GetCommitStatuses).
 						Post(reqToken(), bind(api.CreateStatusOption{}), repo.NewCommitStatus)
 				}, reqRepoReader(models.UnitTypeCode))
+				m.Get("/events", reqToken(), reqAdmin(), repo.ListRepoEvents)
+				m.Group("/artifacts", func() {
+					m.Combo("").Get(repo.ListArtifacts).
+						Post(reqToken(), reqRepoWriter(models.UnitTypeCode), mustNotBeArchived, context.ReferencesGitRepo(false), repo.CreateArtifact)
+					m.Group("/{id}", func() {
+						m.Combo("").Get(repo.GetArtifact).
+							Delete(reqToken(), reqRepoWriter(models.UnitTypeCode), mustNotBeArchived, repo.DeleteArtifact)
+						m.Get("/download", repo.DownloadArtifact)
+					})
+				}, mustEnableArtifacts, reqRepoReader(models.UnitTypeCode))
 				m.Group("/commits", func() {
 					m.Get("", repo.GetAllCommits)
 					m.Group("/{ref}", func() {
@@ -938,6 +1029,7 @@ func Routes() *web.Route {
 					m.Get("/trees/{sha}", context.RepoRefForAPI, repo.GetTree)
 					m.Get("/blobs/{sha}", context.RepoRefForAPI, repo.GetBlob)
 					m.Get("/tags/{sha}", context.RepoRefForAPI, repo.GetTag)
+					m.Get("/stats", repo.GetGitStats)
 				}, reqRepoReader(models.UnitTypeCode))
 				m.Group("/contents", func() {
 					m.Get("", repo.GetContentsList)
@@ -958,7 +1050,9 @@ func Routes() *web.Route {
 					}, reqAdmin())
 				}, reqAnyRepoReader())
 				m.Get("/issue_templates", context.ReferencesGitRepo(false), repo.GetIssueTemplates)
+				m.Get("/issue_config", context.ReferencesGitRepo(false), repo.GetIssueConfig)
 				m.Get("/languages", reqRepoReader(models.UnitTypeCode), repo.GetLanguages)
+				m.Get("/workspace", reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(false), repo.GetWorkspace)
 			}, repoAssignment())
 		})
 
@@ -977,6 +1071,7 @@ func Routes() *web.Route {
 				m.Get("", org.ListMembers)
 				m.Combo("/{username}").Get(org.IsMember).
 					Delete(reqToken(), reqOrgOwnership(), org.DeleteMember)
+				m.Get("/{username}/contributions", reqToken(), reqOrgMembership(), org.GetMemberContributions)
 			})
 			m.Group("/public_members", func() {
 				m.Get("", org.ListPublicMembers)
@@ -996,6 +1091,30 @@ func Routes() *web.Route {
 					Patch(reqToken(), reqOrgOwnership(), bind(api.EditLabelOption{}), org.EditLabel).
 					Delete(reqToken(), reqOrgOwnership(), org.DeleteLabel)
 			})
+			m.Group("/issue_types", func() {
+				m.Combo("").Get(org.ListIssueTypes).
+					Post(reqToken(), reqOrgOwnership(), bind(api.CreateIssueTypeOption{}), org.CreateIssueType)
+				m.Combo("/{id}").Get(org.GetIssueType).
+					Patch(reqToken(), reqOrgOwnership(), bind(api.EditIssueTypeOption{}), org.EditIssueType).
+					Delete(reqToken(), reqOrgOwnership(), org.DeleteIssueType)
+			})
+			m.Group("/projects", func() {
+				m.Combo("").Get(org.ListProjects).
+					Post(reqOrgOwnership(), bind(api.CreateProjectOption{}), org.CreateProject)
+				m.Group("/{id}", func() {
+					m.Combo("").Get(org.GetProject).
+						Patch(reqOrgOwnership(), bind(api.EditProjectOption{}), org.EditProject).
+						Delete(reqOrgOwnership(), org.DeleteProject)
+					m.Combo("/boards").Get(org.ListProjectBoards).
+						Post(reqOrgOwnership(), bind(api.CreateProjectBoardOption{}), org.CreateProjectBoard)
+					m.Group("/boards/{board}", func() {
+						m.Combo("").Patch(reqOrgOwnership(), bind(api.EditProjectBoardOption{}), org.EditProjectBoard).
+							Delete(reqOrgOwnership(), org.DeleteProjectBoard)
+						m.Combo("/issues").Get(org.ListProjectBoardIssues).
+							Post(bind(api.MoveProjectIssueOption{}), org.MoveProjectIssue)
+					})
+				})
+			}, reqToken(), reqOrgMembership(), mustEnableProjects)
 			m.Group("/hooks", func() {
 				m.Combo("").Get(org.ListHooks).
 					Post(bind(api.CreateHookOption{}), org.CreateHook)
@@ -1024,11 +1143,27 @@ func Routes() *web.Route {
 		}, orgAssignment(false, true), reqToken(), reqTeamMembership())
 
 		m.Group("/admin", func() {
+			m.Post("/auths/migrate", bind(api.MigrateLoginSourceUsersOption{}), admin.MigrateLoginSourceUsers)
+			m.Get("/config", admin.GetEffectiveConfig)
 			m.Group("/cron", func() {
 				m.Get("", admin.ListCronTasks)
 				m.Post("/{task}", admin.PostCronTask)
 			})
+			m.Combo("/logging/modules").Get(admin.ListLogModuleLevels).
+				Patch(bind(api.EditLogModuleLevelsOption{}), admin.EditLogModuleLevels)
+			m.Combo("/maintenance").Get(admin.GetMaintenanceMode).
+				Put(bind(api.EnableMaintenanceModeOption{}), admin.EnableMaintenanceMode).
+				Delete(admin.DisableMaintenanceMode)
 			m.Get("/orgs", admin.GetAllOrgs)
+			m.Group("/policies", func() {
+				m.Get("", admin.ListPolicyDocuments)
+				m.Get("/{id}/consents", admin.ListPolicyDocumentConsents)
+			})
+			m.Get("/stats", admin.GetInstanceStats)
+			m.Group("/statistics", func() {
+				m.Get("", admin.GetStorageStatistic)
+				m.Get("/history", admin.ListStorageStatistics)
+			})
 			m.Group("/users", func() {
 				m.Get("", admin.GetAllUsers)
 				m.Post("", bind(api.CreateUserOption{}), admin.CreateUser)
@@ -1040,6 +1175,7 @@ func Routes() *web.Route {
 						m.Delete("/{id}", admin.DeleteUserPublicKey)
 					})
 					m.Get("/orgs", org.ListUserOrgs)
+					m.Get("/policies", admin.ListUserPolicyConsents)
 					m.Post("/orgs", bind(api.CreateOrgOption{}), admin.CreateOrg)
 					m.Post("/repos", bind(api.CreateRepoOption{}), admin.CreateRepo)
 				})
@@ -1054,11 +1190,26 @@ func Routes() *web.Route {
 		m.Group("/topics", func() {
 			m.Get("/search", repo.TopicSearch)
 		})
+
+		m.Get("/code/search", reqExploreSignIn(), repo.SearchCode)
 	}, sudo())
 
 	return m
 }
 
+// checkMaintenanceMode responds with 503 to everyone but site administrators while the instance is in maintenance mode
+func checkMaintenanceMode(ctx *context.APIContext) {
+	if !maintenance.IsEnabled() || (ctx.IsSigned && ctx.User.IsAdmin) {
+		return
+	}
+	message := "Gitea is in maintenance mode"
+	if msg := maintenance.GetState().Message; len(msg) > 0 {
+		message += ": " + msg
+	}
+	ctx.Resp.Header().Set("Retry-After", "300")
+	ctx.Error(http.StatusServiceUnavailable, "", message)
+}
+
 func securityHeaders() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
@@ -0,0 +1,234 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// ListIssueTypes list the issue types of an organization
+func ListIssueTypes(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/issue_types organization orgListIssueTypes
+	// ---
+	// summary: List an organization's issue types
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueTypeList"
+
+	types, err := models.GetIssueTypesByOrgID(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetIssueTypesByOrgID", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToIssueTypeList(types))
+}
+
+// GetIssueType get an issue type of an organization
+func GetIssueType(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/issue_types/{id} organization orgGetIssueType
+	// ---
+	// summary: Get a single issue type
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the issue type to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueType"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	t, err := models.GetIssueTypeInOrgByID(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrIssueTypeNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueTypeInOrgByID", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToIssueType(t))
+}
+
+// CreateIssueType create an issue type for an organization
+func CreateIssueType(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/issue_types organization orgCreateIssueType
+	// ---
+	// summary: Create an issue type for an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateIssueTypeOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/IssueType"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateIssueTypeOption)
+	t := &models.IssueType{
+		OrgID:        ctx.Org.Organization.ID,
+		Name:         form.Name,
+		Description:  form.Description,
+		Color:        form.Color,
+		TemplateFile: form.TemplateFile,
+	}
+	if err := models.NewIssueType(t); err != nil {
+		issueTypeError(ctx, "NewIssueType", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToIssueType(t))
+}
+
+// EditIssueType modify an issue type of an organization
+func EditIssueType(ctx *context.APIContext) {
+	// swagger:operation PATCH /orgs/{org}/issue_types/{id} organization orgEditIssueType
+	// ---
+	// summary: Update an issue type
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the issue type to edit
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditIssueTypeOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueType"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditIssueTypeOption)
+	t, err := models.GetIssueTypeInOrgByID(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrIssueTypeNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueTypeInOrgByID", err)
+		}
+		return
+	}
+
+	if form.Name != nil {
+		t.Name = *form.Name
+	}
+	if form.Color != nil {
+		t.Color = *form.Color
+	}
+	if form.Description != nil {
+		t.Description = *form.Description
+	}
+	if form.TemplateFile != nil {
+		t.TemplateFile = *form.TemplateFile
+	}
+	if err := models.UpdateIssueType(t); err != nil {
+		issueTypeError(ctx, "UpdateIssueType", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToIssueType(t))
+}
+
+// DeleteIssueType delete an issue type of an organization
+func DeleteIssueType(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/issue_types/{id} organization orgDeleteIssueType
+	// ---
+	// summary: Delete an issue type, the issues of the type no longer having one
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the issue type to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	t, err := models.GetIssueTypeInOrgByID(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrIssueTypeNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueTypeInOrgByID", err)
+		}
+		return
+	}
+	if err := models.DeleteIssueType(t); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteIssueType", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func issueTypeError(ctx *context.APIContext, title string, err error) {
+	switch {
+	case models.IsErrIssueTypeAlreadyExist(err):
+		ctx.Error(http.StatusConflict, title, err)
+	case models.IsErrInvalidIssueType(err):
+		ctx.Error(http.StatusUnprocessableEntity, title, err)
+	default:
+		ctx.Error(http.StatusInternalServerError, title, err)
+	}
+}
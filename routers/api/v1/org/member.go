@@ -14,6 +14,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/routers/api/v1/user"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	org_service "code.gitea.io/gitea/services/org"
 )
 
 // listMembers list an organization's members
@@ -156,6 +157,97 @@ func IsMember(ctx *context.APIContext) {
 	ctx.Redirect(redirectURL, 302)
 }
 
+// GetMemberContributions returns the contributions of a member to the repositories of an organization
+func GetMemberContributions(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/members/{username}/contributions organization orgGetMemberContributions
+	// ---
+	// summary: Get the contributions of a member to the repositories of an organization
+	// description: The contributions are counted in the repositories the requester can access, in the background.
+	//   Until they are counted, the response is 202 Accepted and the request should be repeated later.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: username
+	//   in: path
+	//   description: username of the member
+	//   type: string
+	//   required: true
+	// - name: since
+	//   in: query
+	//   description: Only count the contributions after the given time, a year before the end of the time range by default. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	//   required: false
+	// - name: before
+	//   in: query
+	//   description: Only count the contributions before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	//   required: false
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/OrgMemberContributions"
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	member := user.GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	isMember, err := ctx.Org.Organization.IsOrgMember(member.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "IsOrgMember", err)
+		return
+	} else if !isMember {
+		ctx.NotFound()
+		return
+	}
+
+	before, since, err := utils.GetQueryBeforeSince(ctx)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetQueryBeforeSince", err)
+		return
+	}
+	if since > 0 && before > 0 && since >= before {
+		ctx.Error(http.StatusUnprocessableEntity, "GetQueryBeforeSince", "since must be earlier than before")
+		return
+	}
+
+	repoIDs, err := models.FindOrgRepoIDsAccessibleBy(ctx.Org.Organization.ID, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindOrgRepoIDsAccessibleBy", err)
+		return
+	}
+
+	contributions, err := org_service.GetContributions(&org_service.ContributionsRequest{
+		OrgID:   ctx.Org.Organization.ID,
+		UserID:  member.ID,
+		RepoIDs: repoIDs,
+		Since:   since,
+		Before:  before,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetContributions", err)
+		return
+	}
+	if contributions == nil {
+		ctx.Status(http.StatusAccepted)
+		return
+	}
+	ctx.JSON(http.StatusOK, contributions)
+}
+
 // IsPublicMember check if a user is a public member of an organization
 func IsPublicMember(ctx *context.APIContext) {
 	// swagger:operation GET /orgs/{org}/public_members/{username} organization orgIsPublicMember
@@ -0,0 +1,752 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// ListProjects list the projects of an organization
+func ListProjects(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/projects organization orgListProjects
+	// ---
+	// summary: List an organization's projects
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: state
+	//   in: query
+	//   description: whether the projects are open or closed
+	//   type: string
+	//   enum: [open, closed, all]
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	page := ctx.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	opts := models.ProjectSearchOptions{
+		OwnerID:  ctx.Org.Organization.ID,
+		Page:     page,
+		IsClosed: util.OptionalBoolFalse,
+		Type:     models.ProjectTypeOrganization,
+	}
+	switch ctx.Query("state") {
+	case "closed":
+		opts.IsClosed = util.OptionalBoolTrue
+	case "all":
+		opts.IsClosed = util.OptionalBoolNone
+	}
+
+	projects, count, err := models.GetProjects(opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetProjects", err)
+		return
+	}
+
+	apiProjects := make([]*api.Project, len(projects))
+	for i := range projects {
+		apiProjects[i] = convert.ToOrgProject(projects[i], ctx.Org.Organization)
+	}
+	ctx.SetLinkHeader(int(count), setting.UI.IssuePagingNum)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, Link")
+	ctx.JSON(http.StatusOK, apiProjects)
+}
+
+// getOrgProject returns the project of the organization given in the URL, it writes an error if there is none
+func getOrgProject(ctx *context.APIContext) *models.Project {
+	p, err := models.GetProjectInOrgByID(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrProjectNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetProjectInOrgByID", err)
+		}
+		return nil
+	}
+	return p
+}
+
+// GetProject get a project of an organization
+func GetProject(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/projects/{id} organization orgGetProject
+	// ---
+	// summary: Get a project of an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Project"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getOrgProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToOrgProject(p, ctx.Org.Organization))
+}
+
+// CreateProject create a project for an organization
+func CreateProject(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/projects organization orgCreateProject
+	// ---
+	// summary: Create a project for an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Project"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateProjectOption)
+	p := &models.Project{
+		OwnerID:     ctx.Org.Organization.ID,
+		Title:       form.Title,
+		Description: form.Description,
+		CreatorID:   ctx.User.ID,
+		Type:        models.ProjectTypeOrganization,
+	}
+
+	var ok bool
+	if form.Template != "" {
+		if p.BoardType, ok = models.ProjectBoardTypeFromName(form.Template); !ok {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("unknown template: %s", form.Template))
+			return
+		}
+	}
+	if form.Swimlanes != "" {
+		if p.SwimlaneType, ok = models.ProjectSwimlaneTypeFromName(form.Swimlanes); !ok {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("unknown swimlanes: %s", form.Swimlanes))
+			return
+		}
+	}
+
+	if err := models.NewProject(p); err != nil {
+		ctx.Error(http.StatusInternalServerError, "NewProject", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToOrgProject(p, ctx.Org.Organization))
+}
+
+// EditProject modify a project of an organization
+func EditProject(ctx *context.APIContext) {
+	// swagger:operation PATCH /orgs/{org}/projects/{id} organization orgEditProject
+	// ---
+	// summary: Update a project of an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditProjectOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Project"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditProjectOption)
+	p := getOrgProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Title != nil {
+		p.Title = *form.Title
+	}
+	if form.Description != nil {
+		p.Description = *form.Description
+	}
+	if form.Swimlanes != nil {
+		swimlaneType, ok := models.ProjectSwimlaneTypeFromName(*form.Swimlanes)
+		if !ok {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("unknown swimlanes: %s", *form.Swimlanes))
+			return
+		}
+		p.SwimlaneType = swimlaneType
+	}
+	if form.State != nil && *form.State != string(api.StateOpen) && *form.State != string(api.StateClosed) {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("unknown state: %s", *form.State))
+		return
+	}
+
+	if err := models.UpdateProject(p); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateProject", err)
+		return
+	}
+	if form.State != nil {
+		if isClosed := *form.State == string(api.StateClosed); isClosed != p.IsClosed {
+			if err := models.ChangeProjectStatus(p, isClosed); err != nil {
+				ctx.Error(http.StatusInternalServerError, "ChangeProjectStatus", err)
+				return
+			}
+		}
+	}
+	ctx.JSON(http.StatusOK, convert.ToOrgProject(p, ctx.Org.Organization))
+}
+
+// DeleteProject delete a project of an organization
+func DeleteProject(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/projects/{id} organization orgDeleteProject
+	// ---
+	// summary: Delete a project of an organization, its issues are kept
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getOrgProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := models.DeleteProjectByID(p.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteProjectByID", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListProjectBoards list the boards of a project of an organization
+func ListProjectBoards(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/projects/{id}/boards organization orgListProjectBoards
+	// ---
+	// summary: List the boards of a project of an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectBoardList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getOrgProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	boards, err := models.GetProjectBoards(p.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetProjectBoards", err)
+		return
+	}
+	apiBoards := make([]*api.ProjectBoard, 0, len(boards))
+	for _, b := range boards {
+		// the board of the issues not in any board only exists in the UI if no board is the default one
+		if b.ID != 0 {
+			apiBoards = append(apiBoards, convert.ToProjectBoard(b))
+		}
+	}
+	ctx.JSON(http.StatusOK, apiBoards)
+}
+
+// getOrgProjectBoard returns the board given in the URL of a project, it writes an error if there is none
+func getOrgProjectBoard(ctx *context.APIContext, p *models.Project) *models.ProjectBoard {
+	board, err := models.GetProjectBoard(ctx.ParamsInt64(":board"))
+	if err != nil {
+		if models.IsErrProjectBoardNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetProjectBoard", err)
+		}
+		return nil
+	}
+	if board.ProjectID != p.ID {
+		ctx.NotFound()
+		return nil
+	}
+	return board
+}
+
+// checkProjectBoardRepoIDs checks that the repositories of the filter of a board belong to the organization, it writes
+// an error if they do not
+func checkProjectBoardRepoIDs(ctx *context.APIContext, repoIDs []int64) bool {
+	for _, id := range repoIDs {
+		repo, err := models.GetRepositoryByID(id)
+		if err != nil && !models.IsErrRepoNotExist(err) {
+			ctx.Error(http.StatusInternalServerError, "GetRepositoryByID", err)
+			return false
+		}
+		if err != nil || repo.OwnerID != ctx.Org.Organization.ID {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("repository %d is not in the organization", id))
+			return false
+		}
+	}
+	return true
+}
+
+// CreateProjectBoard create a board for a project of an organization
+func CreateProjectBoard(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/projects/{id}/boards organization orgCreateProjectBoard
+	// ---
+	// summary: Create a board for a project of an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectBoardOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/ProjectBoard"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateProjectBoardOption)
+	p := getOrgProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !checkProjectBoardRepoIDs(ctx, form.RepoIDs) {
+		return
+	}
+
+	board := &models.ProjectBoard{
+		ProjectID: p.ID,
+		Title:     form.Title,
+		CreatorID: ctx.User.ID,
+	}
+	if err := models.NewProjectBoard(board); err != nil {
+		ctx.Error(http.StatusInternalServerError, "NewProjectBoard", err)
+		return
+	}
+
+	board.WIPLimit = form.WIPLimit
+	board.AutoMoveOnClose = form.AutoMoveOnClose
+	board.AutoMoveOnMerge = form.AutoMoveOnMerge
+	board.RepoFilter = models.JoinUserFilterIDs(form.RepoIDs)
+	if err := models.UpdateProjectBoardSettings(board); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateProjectBoardSettings", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToProjectBoard(board))
+}
+
+// EditProjectBoard modify a board of a project of an organization
+func EditProjectBoard(ctx *context.APIContext) {
+	// swagger:operation PATCH /orgs/{org}/projects/{id}/boards/{board} organization orgEditProjectBoard
+	// ---
+	// summary: Update a board of a project of an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: board
+	//   in: path
+	//   description: id of the board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditProjectBoardOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectBoard"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditProjectBoardOption)
+	p := getOrgProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	board := getOrgProjectBoard(ctx, p)
+	if ctx.Written() {
+		return
+	}
+	if !checkProjectBoardRepoIDs(ctx, form.RepoIDs) {
+		return
+	}
+
+	if form.Title != nil {
+		board.Title = *form.Title
+	}
+	if form.Sorting != nil {
+		board.Sorting = *form.Sorting
+	}
+	if err := models.UpdateProjectBoard(board); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateProjectBoard", err)
+		return
+	}
+
+	if form.WIPLimit != nil {
+		board.WIPLimit = *form.WIPLimit
+	}
+	if form.AutoMoveOnClose != nil {
+		board.AutoMoveOnClose = *form.AutoMoveOnClose
+	}
+	if form.AutoMoveOnMerge != nil {
+		board.AutoMoveOnMerge = *form.AutoMoveOnMerge
+	}
+	if form.RepoIDs != nil {
+		board.RepoFilter = models.JoinUserFilterIDs(form.RepoIDs)
+	}
+	if err := models.UpdateProjectBoardSettings(board); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateProjectBoardSettings", err)
+		return
+	}
+
+	if form.Default != nil && *form.Default != board.Default {
+		defaultBoardID := board.ID
+		if !*form.Default {
+			defaultBoardID = 0
+		}
+		if err := models.SetDefaultBoard(p.ID, defaultBoardID); err != nil {
+			ctx.Error(http.StatusInternalServerError, "SetDefaultBoard", err)
+			return
+		}
+		board.Default = *form.Default
+	}
+	ctx.JSON(http.StatusOK, convert.ToProjectBoard(board))
+}
+
+// DeleteProjectBoard delete a board of a project of an organization
+func DeleteProjectBoard(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/projects/{id}/boards/{board} organization orgDeleteProjectBoard
+	// ---
+	// summary: Delete a board of a project of an organization, its issues are no longer in any board
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: board
+	//   in: path
+	//   description: id of the board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getOrgProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	board := getOrgProjectBoard(ctx, p)
+	if ctx.Written() {
+		return
+	}
+	if err := models.DeleteProjectBoardByID(board.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteProjectBoardByID", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListProjectBoardIssues list the issues of a board of a project of an organization
+func ListProjectBoardIssues(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/projects/{id}/boards/{board}/issues organization orgListProjectBoardIssues
+	// ---
+	// summary: List the issues and pull requests of a board of a project of an organization which the user can read
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: board
+	//   in: path
+	//   description: id of the board, 0 for the issues not in any board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getOrgProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	opts := &models.IssuesOptions{
+		ProjectID:      p.ID,
+		ProjectBoardID: -1,
+	}
+	if ctx.ParamsInt64(":board") != 0 {
+		board := getOrgProjectBoard(ctx, p)
+		if ctx.Written() {
+			return
+		}
+		opts.ProjectBoardID = board.ID
+	}
+
+	issues, err := models.Issues(opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Issues", err)
+		return
+	}
+	visible, err := models.IssueList(issues).FilterVisibleTo(ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FilterVisibleTo", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToAPIIssueList(visible))
+}
+
+// MoveProjectIssue add an issue to a board of a project of an organization
+func MoveProjectIssue(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/projects/{id}/boards/{board}/issues organization orgMoveProjectIssue
+	// ---
+	// summary: Add an issue or a pull request of the organization to a board of a project
+	// description: The issue is moved from its current board, or from its current project.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: board
+	//   in: path
+	//   description: id of the board, 0 for no board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/MoveProjectIssueOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Issue"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.MoveProjectIssueOption)
+	p := getOrgProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	board := &models.ProjectBoard{ProjectID: p.ID}
+	if ctx.ParamsInt64(":board") != 0 {
+		board = getOrgProjectBoard(ctx, p)
+		if ctx.Written() {
+			return
+		}
+	}
+
+	repo, err := models.GetRepositoryByName(ctx.Org.Organization.ID, form.Repo)
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRepositoryByName", err)
+		}
+		return
+	}
+	issue, err := models.GetIssueByIndex(repo.ID, form.Index)
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByIndex", err)
+		}
+		return
+	}
+	issue.Repo = repo
+
+	perm, err := models.GetUserRepoPermission(repo, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
+		return
+	}
+	if !perm.CanReadIssuesOrPulls(issue.IsPull) {
+		ctx.NotFound()
+		return
+	}
+	if !perm.CanWriteIssuesOrPulls(issue.IsPull) || repo.IsArchived {
+		ctx.Error(http.StatusForbidden, "", "user cannot change the issues of the repository")
+		return
+	}
+	if !board.CanContainIssuesOf(repo.ID) {
+		ctx.Error(http.StatusUnprocessableEntity, "", models.ErrProjectBoardRepoNotAllowed{BoardID: board.ID, RepoID: repo.ID})
+		return
+	}
+
+	if issue.ProjectID() != p.ID {
+		if err := models.ChangeProjectAssign(issue, ctx.User, p.ID); err != nil {
+			ctx.Error(http.StatusInternalServerError, "ChangeProjectAssign", err)
+			return
+		}
+	}
+	if err := models.MoveIssueAcrossProjectBoards(issue, board); err != nil {
+		ctx.Error(http.StatusInternalServerError, "MoveIssueAcrossProjectBoards", err)
+		return
+	}
+
+	if err := issue.LoadAttributes(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToAPIIssue(issue))
+}
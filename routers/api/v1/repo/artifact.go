@@ -0,0 +1,333 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/routers/repo"
+)
+
+// ListArtifacts lists the artifacts of a repository
+func ListArtifacts(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/artifacts repository repoListArtifacts
+	// ---
+	// summary: List a repository's artifacts
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: sha
+	//   in: query
+	//   description: only list the artifacts of this commit
+	//   type: string
+	// - name: ref
+	//   in: query
+	//   description: only list the artifacts uploaded for this ref
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ArtifactList"
+
+	listOptions := utils.GetListOptions(ctx)
+	opts := &models.FindRepoArtifactsOptions{
+		ListOptions: listOptions,
+		RepoID:      ctx.Repo.Repository.ID,
+		Ref:         ctx.QueryTrim("ref"),
+	}
+	if sha := ctx.QueryTrim("sha"); len(sha) > 0 {
+		opts.CommitSHAs = []string{sha}
+	}
+
+	artifacts, count, err := models.FindRepoArtifacts(opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindRepoArtifacts", err)
+		return
+	}
+
+	apiArtifacts := make([]*api.Artifact, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		artifact.Repo = ctx.Repo.Repository
+		if err := artifact.LoadAttributes(); err != nil {
+			ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+			return
+		}
+		apiArtifacts = append(apiArtifacts, convert.ToArtifact(artifact))
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, Link")
+	ctx.JSON(http.StatusOK, apiArtifacts)
+}
+
+// getArtifact returns the artifact in the path or writes an error
+func getArtifact(ctx *context.APIContext) *models.RepoArtifact {
+	artifact, err := models.GetRepoArtifactByID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrRepoArtifactNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRepoArtifactByID", err)
+		}
+		return nil
+	}
+	artifact.Repo = ctx.Repo.Repository
+	if err := artifact.LoadAttributes(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return nil
+	}
+	return artifact
+}
+
+// GetArtifact gets an artifact of a repository
+func GetArtifact(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/artifacts/{id} repository repoGetArtifact
+	// ---
+	// summary: Get an artifact
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the artifact
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Artifact"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	artifact := getArtifact(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToArtifact(artifact))
+}
+
+// DownloadArtifact serves the content of an artifact
+func DownloadArtifact(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/artifacts/{id}/download repository repoDownloadArtifact
+	// ---
+	// summary: Download an artifact
+	// produces:
+	// - application/octet-stream
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the artifact
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   200:
+	//     description: success
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	artifact := getArtifact(ctx)
+	if ctx.Written() {
+		return
+	}
+	repo.ServeArtifact(ctx.Context, artifact)
+}
+
+// CreateArtifact uploads an artifact for a commit of a repository
+func CreateArtifact(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/artifacts repository repoCreateArtifact
+	// ---
+	// summary: Upload an artifact for a commit
+	// produces:
+	// - application/json
+	// consumes:
+	// - multipart/form-data
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: artifact
+	//   in: formData
+	//   description: artifact to upload
+	//   type: file
+	//   required: true
+	// - name: sha
+	//   in: formData
+	//   description: commit SHA, branch or tag the artifact was built from
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: formData
+	//   description: ref the artifact was built for, defaults to sha if it is a branch or tag
+	//   type: string
+	// - name: name
+	//   in: formData
+	//   description: name of the artifact, defaults to the name of the uploaded file
+	//   type: string
+	// - name: retention_days
+	//   in: formData
+	//   description: number of days the artifact is kept, defaults to the retention configured on the server
+	//   type: integer
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Artifact"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "413":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	file, header, err := ctx.Req.FormFile("artifact")
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "FormFile", err)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > setting.Artifacts.MaxSize*1024*1024 {
+		ctx.Error(http.StatusRequestEntityTooLarge, "", fmt.Sprintf("artifact is larger than %d MB", setting.Artifacts.MaxSize))
+		return
+	}
+
+	sha := ctx.QueryTrim("sha")
+	if len(sha) == 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "sha is required")
+		return
+	}
+	commit, err := ctx.Repo.GitRepo.GetCommit(sha)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		}
+		return
+	}
+	ref := ctx.QueryTrim("ref")
+	if len(ref) == 0 && commit.ID.String() != sha {
+		ref = sha
+	}
+
+	name := ctx.QueryTrim("name")
+	if len(name) == 0 {
+		name = header.Filename
+	}
+	if len(name) == 0 || len(name) > 255 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "invalid artifact name")
+		return
+	}
+
+	artifact, err := models.NewRepoArtifact(&models.RepoArtifact{
+		RepoID:      ctx.Repo.Repository.ID,
+		UploaderID:  ctx.User.ID,
+		Name:        name,
+		CommitSHA:   commit.ID.String(),
+		Ref:         ref,
+		ExpiredUnix: models.ArtifactExpiry(ctx.QueryInt("retention_days")),
+	}, file)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "NewRepoArtifact", err)
+		return
+	}
+	log.Trace("Artifact %d uploaded for %s in %-v", artifact.ID, artifact.CommitSHA, ctx.Repo.Repository)
+
+	artifact.Repo = ctx.Repo.Repository
+	artifact.Uploader = ctx.User
+	ctx.JSON(http.StatusCreated, convert.ToArtifact(artifact))
+}
+
+// DeleteArtifact deletes an artifact of a repository
+func DeleteArtifact(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/artifacts/{id} repository repoDeleteArtifact
+	// ---
+	// summary: Delete an artifact
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the artifact
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	artifact := getArtifact(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := models.DeleteRepoArtifact(artifact); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteRepoArtifact", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
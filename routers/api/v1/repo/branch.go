@@ -156,7 +156,7 @@ func DeleteBranch(ctx *context.APIContext) {
 	}
 
 	// Don't return error below this
-	if err := repo_service.PushUpdate(
+	if err := repo_service.PushUpdate(ctx.Req.Context(),
 		&repo_module.PushUpdateOptions{
 			RefFullName:  git.BranchPrefix + branchName,
 			OldCommitID:  c.ID.String(),
@@ -526,22 +526,25 @@ func CreateBranchProtection(ctx *context.APIContext) {
 	}
 
 	protectBranch = &models.ProtectedBranch{
-		RepoID:                        ctx.Repo.Repository.ID,
-		BranchName:                    form.BranchName,
-		CanPush:                       form.EnablePush,
-		EnableWhitelist:               form.EnablePush && form.EnablePushWhitelist,
-		EnableMergeWhitelist:          form.EnableMergeWhitelist,
-		WhitelistDeployKeys:           form.EnablePush && form.EnablePushWhitelist && form.PushWhitelistDeployKeys,
-		EnableStatusCheck:             form.EnableStatusCheck,
-		StatusCheckContexts:           form.StatusCheckContexts,
-		EnableApprovalsWhitelist:      form.EnableApprovalsWhitelist,
-		RequiredApprovals:             requiredApprovals,
-		BlockOnRejectedReviews:        form.BlockOnRejectedReviews,
-		BlockOnOfficialReviewRequests: form.BlockOnOfficialReviewRequests,
-		DismissStaleApprovals:         form.DismissStaleApprovals,
-		RequireSignedCommits:          form.RequireSignedCommits,
-		ProtectedFilePatterns:         form.ProtectedFilePatterns,
-		BlockOnOutdatedBranch:         form.BlockOnOutdatedBranch,
+		RepoID:                         ctx.Repo.Repository.ID,
+		BranchName:                     form.BranchName,
+		CanPush:                        form.EnablePush,
+		EnableWhitelist:                form.EnablePush && form.EnablePushWhitelist,
+		EnableMergeWhitelist:           form.EnableMergeWhitelist,
+		WhitelistDeployKeys:            form.EnablePush && form.EnablePushWhitelist && form.PushWhitelistDeployKeys,
+		EnableStatusCheck:              form.EnableStatusCheck,
+		StatusCheckContexts:            form.StatusCheckContexts,
+		EnableApprovalsWhitelist:       form.EnableApprovalsWhitelist,
+		RequiredApprovals:              requiredApprovals,
+		BlockOnRejectedReviews:         form.BlockOnRejectedReviews,
+		BlockOnOfficialReviewRequests:  form.BlockOnOfficialReviewRequests,
+		DismissStaleApprovals:          form.DismissStaleApprovals,
+		RequireSignedCommits:           form.RequireSignedCommits,
+		ProtectedFilePatterns:          form.ProtectedFilePatterns,
+		BlockOnOutdatedBranch:          form.BlockOnOutdatedBranch,
+		BlockOnUnresolvedConversations: form.BlockOnUnresolvedConversations,
+		RequireCodeOwnerReviews:        form.RequireCodeOwnerReviews,
+		EnableMergeQueue:               form.EnableMergeQueue,
 	}
 
 	err = models.UpdateProtectBranch(ctx.Repo.Repository, protectBranch, models.WhitelistOptions{
@@ -556,6 +559,7 @@ func CreateBranchProtection(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "UpdateProtectBranch", err)
 		return
 	}
+	repo_service.RecordEvent(ctx.User, ctx.Repo.Repository, models.RepoEventProtectedBranchUpdate, protectBranch.BranchName, "")
 
 	if err = pull_service.CheckPrsForBaseBranch(ctx.Repo.Repository, protectBranch.BranchName); err != nil {
 		ctx.Error(http.StatusInternalServerError, "CheckPrsForBaseBranch", err)
@@ -674,6 +678,10 @@ func EditBranchProtection(ctx *context.APIContext) {
 		protectBranch.BlockOnOfficialReviewRequests = *form.BlockOnOfficialReviewRequests
 	}
 
+	if form.RequireCodeOwnerReviews != nil {
+		protectBranch.RequireCodeOwnerReviews = *form.RequireCodeOwnerReviews
+	}
+
 	if form.DismissStaleApprovals != nil {
 		protectBranch.DismissStaleApprovals = *form.DismissStaleApprovals
 	}
@@ -682,6 +690,10 @@ func EditBranchProtection(ctx *context.APIContext) {
 		protectBranch.RequireSignedCommits = *form.RequireSignedCommits
 	}
 
+	if form.EnableMergeQueue != nil {
+		protectBranch.EnableMergeQueue = *form.EnableMergeQueue
+	}
+
 	if form.ProtectedFilePatterns != nil {
 		protectBranch.ProtectedFilePatterns = *form.ProtectedFilePatterns
 	}
@@ -690,6 +702,10 @@ func EditBranchProtection(ctx *context.APIContext) {
 		protectBranch.BlockOnOutdatedBranch = *form.BlockOnOutdatedBranch
 	}
 
+	if form.BlockOnUnresolvedConversations != nil {
+		protectBranch.BlockOnUnresolvedConversations = *form.BlockOnUnresolvedConversations
+	}
+
 	var whitelistUsers []int64
 	if form.PushWhitelistUsernames != nil {
 		whitelistUsers, err = models.GetUserIDsByNames(form.PushWhitelistUsernames, false)
@@ -788,6 +804,7 @@ func EditBranchProtection(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "UpdateProtectBranch", err)
 		return
 	}
+	repo_service.RecordEvent(ctx.User, ctx.Repo.Repository, models.RepoEventProtectedBranchUpdate, protectBranch.BranchName, "")
 
 	if err = pull_service.CheckPrsForBaseBranch(ctx.Repo.Repository, protectBranch.BranchName); err != nil {
 		ctx.Error(http.StatusInternalServerError, "CheckPrsForBaseBranch", err)
@@ -853,6 +870,7 @@ func DeleteBranchProtection(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "DeleteProtectedBranch", err)
 		return
 	}
+	repo_service.RecordEvent(ctx.User, ctx.Repo.Repository, models.RepoEventProtectedBranchDelete, bp.BranchName, "")
 
 	ctx.Status(http.StatusNoContent)
 }
@@ -0,0 +1,182 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// SearchCode searches the code of the repositories the user can read
+func SearchCode(ctx *context.APIContext) {
+	// swagger:operation GET /code/search repository codeSearch
+	// ---
+	// summary: Search the code of the repositories
+	// produces:
+	//   - application/json
+	// parameters:
+	//   - name: q
+	//     in: query
+	//     description: keyword to search
+	//     required: true
+	//     type: string
+	//   - name: language
+	//     in: query
+	//     description: only return the results in this language
+	//     type: string
+	//   - name: match
+	//     in: query
+	//     description: match the exact keyword instead of a fuzzy search
+	//     type: boolean
+	//   - name: regexp
+	//     in: query
+	//     description: match the keyword as a regular expression against the words of the code, only supported by the bleve code indexer
+	//     type: boolean
+	//   - name: path
+	//     in: query
+	//     description: only search the files in this directory and its subdirectories
+	//     type: string
+	//   - name: org
+	//     in: query
+	//     description: only search the repositories of this owner
+	//     type: string
+	//   - name: repos
+	//     in: query
+	//     description: only search these repositories, named like owner/repo
+	//     type: array
+	//     collectionFormat: multi
+	//     items:
+	//       type: string
+	//   - name: page
+	//     in: query
+	//     description: page number of results to return (1-based)
+	//     type: integer
+	//   - name: limit
+	//     in: query
+	//     description: page size of results
+	//     type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CodeSearchResults"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	if !setting.Indexer.RepoIndexerEnabled {
+		ctx.NotFound()
+		return
+	}
+
+	keyword := strings.TrimSpace(ctx.Query("q"))
+	if len(keyword) == 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "the keyword q is required")
+		return
+	}
+	listOptions := utils.GetListOptions(ctx)
+	if listOptions.Page <= 0 {
+		listOptions.Page = 1
+	}
+
+	var repoFullNames []string
+	for _, fullName := range ctx.QueryStrings("repos") {
+		if fullName = strings.TrimSpace(fullName); len(fullName) > 0 {
+			repoFullNames = append(repoFullNames, fullName)
+		}
+	}
+	scope, found, err := models.GetCodeSearchScope(strings.TrimSpace(ctx.Query("org")), repoFullNames)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCodeSearchScope", err)
+		return
+	}
+	// repoIDs is nil if the search is not restricted to some repositories
+	repoIDs := []int64{}
+	if found {
+		repoIDs, err = models.FindCodeSearchRepoIDs(ctx.User, scope)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "FindCodeSearchRepoIDs", err)
+			return
+		}
+	}
+
+	mode := code_indexer.SearchModeFuzzy
+	if ctx.QueryBool("regexp") {
+		mode = code_indexer.SearchModeRegexp
+	} else if ctx.QueryBool("match") {
+		mode = code_indexer.SearchModeMatch
+	}
+
+	response := api.CodeSearchResults{
+		Results:      []*api.CodeSearchResult{},
+		Languages:    []*api.CodeSearchLanguage{},
+		Directories:  []*api.CodeSearchDirectory{},
+		Repositories: []*api.CodeSearchRepository{},
+	}
+	if repoIDs == nil || len(repoIDs) > 0 {
+		total, results, facets, err := code_indexer.PerformSearch(&code_indexer.SearchOptions{
+			RepoIDs:  repoIDs,
+			Language: strings.TrimSpace(ctx.Query("language")),
+			Path:     strings.TrimSpace(ctx.Query("path")),
+			Keyword:  keyword,
+			Mode:     mode,
+			Page:     listOptions.Page,
+			PageSize: listOptions.PageSize,
+		})
+		if err != nil {
+			if code_indexer.IsErrInvalidSearchRegexp(err) || err == code_indexer.ErrRegexpSearchUnsupported {
+				ctx.Error(http.StatusUnprocessableEntity, "", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "PerformSearch", err)
+			}
+			return
+		}
+
+		resultRepoIDs := make([]int64, 0, len(results)+len(facets.Repos))
+		for _, result := range results {
+			resultRepoIDs = append(resultRepoIDs, result.RepoID)
+		}
+		for _, repo := range facets.Repos {
+			resultRepoIDs = append(resultRepoIDs, repo.RepoID)
+		}
+		repos, err := models.GetRepositoriesMapByIDs(resultRepoIDs)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetRepositoriesMapByIDs", err)
+			return
+		}
+
+		response.TotalCount = total
+		for _, result := range results {
+			// the repository may have been deleted since it was indexed
+			if repo, ok := repos[result.RepoID]; ok {
+				response.Results = append(response.Results, convert.ToCodeSearchResult(result, repo))
+			}
+		}
+		for _, language := range facets.Languages {
+			response.Languages = append(response.Languages, convert.ToCodeSearchLanguage(language))
+		}
+		for _, directory := range facets.Directories {
+			response.Directories = append(response.Directories, convert.ToCodeSearchDirectory(directory))
+		}
+		for _, result := range facets.Repos {
+			if repo, ok := repos[result.RepoID]; ok {
+				response.Repositories = append(response.Repositories, convert.ToCodeSearchRepository(result, repo))
+			}
+		}
+	}
+
+	ctx.SetLinkHeader(response.TotalCount, listOptions.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", response.TotalCount))
+	ctx.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, Link")
+	ctx.JSON(http.StatusOK, response)
+}
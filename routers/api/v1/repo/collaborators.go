@@ -15,6 +15,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 // ListCollaborators list a repository's collaborators
@@ -166,12 +167,15 @@ func AddCollaborator(ctx *context.APIContext) {
 		return
 	}
 
+	mode := models.AccessModeWrite
 	if form.Permission != nil {
-		if err := ctx.Repo.Repository.ChangeCollaborationAccessMode(collaborator.ID, models.ParseAccessMode(*form.Permission)); err != nil {
+		mode = models.ParseAccessMode(*form.Permission)
+		if err := ctx.Repo.Repository.ChangeCollaborationAccessMode(collaborator.ID, mode); err != nil {
 			ctx.Error(http.StatusInternalServerError, "ChangeCollaborationAccessMode", err)
 			return
 		}
 	}
+	repo_service.RecordEvent(ctx.User, ctx.Repo.Repository, models.RepoEventCollaboratorAdd, collaborator.Name, mode.String())
 
 	ctx.Status(http.StatusNoContent)
 }
@@ -219,5 +223,6 @@ func DeleteCollaborator(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "DeleteCollaboration", err)
 		return
 	}
+	repo_service.RecordEvent(ctx.User, ctx.Repo.Repository, models.RepoEventCollaboratorRemove, collaborator.Name, "")
 	ctx.Status(http.StatusNoContent)
 }
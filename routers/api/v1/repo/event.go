@@ -0,0 +1,82 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListRepoEvents list the administrative events of a repository
+func ListRepoEvents(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/events repository repoListEvents
+	// ---
+	// summary: List the administrative events of a repository, the latest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: query
+	//   description: only list the events of this type
+	//   type: string
+	//   enum: [protected_branch_update, protected_branch_delete, webhook_create, webhook_update, webhook_delete, collaborator_add, collaborator_access_change, collaborator_remove, transfer_start, transfer_cancel, transfer]
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoEventList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	listOptions := utils.GetListOptions(ctx)
+	opts := &models.FindRepoEventsOptions{
+		ListOptions: listOptions,
+		RepoID:      ctx.Repo.Repository.ID,
+	}
+	if name := ctx.QueryTrim("type"); len(name) > 0 {
+		if opts.Type = models.RepoEventTypeFromName(name); opts.Type == 0 {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("unknown event type: %s", name))
+			return
+		}
+	}
+
+	events, count, err := models.FindRepoEvents(opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindRepoEvents", err)
+		return
+	}
+
+	apiEvents := make([]*api.RepoEvent, 0, len(events))
+	for _, e := range events {
+		apiEvents = append(apiEvents, convert.ToRepoEvent(e))
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, Link")
+	ctx.JSON(http.StatusOK, apiEvents)
+}
@@ -74,7 +74,7 @@ func GetGitRefs(ctx *context.APIContext) {
 }
 
 func getGitRefs(ctx *context.APIContext, filter string) ([]*git.Reference, string, error) {
-	gitRepo, err := git.OpenRepository(ctx.Repo.Repository.RepoPath())
+	gitRepo, err := git.OpenRepositoryCtx(ctx.Req.Context(), ctx.Repo.Repository.RepoPath())
 	if err != nil {
 		return nil, "OpenRepository", err
 	}
@@ -0,0 +1,61 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/git"
+)
+
+const (
+	defaultLargestBlobs = 10
+	maxLargestBlobs     = 100
+)
+
+// GetGitStats returns the object storage statistics of a repository
+func GetGitStats(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/git/stats repository repoGetGitStats
+	// ---
+	// summary: Get the object counts, pack sizes, largest blobs and ref counts of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: largest_blobs
+	//   in: query
+	//   description: number of largest blobs to return (default 10, max 100)
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/GitStats"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	largestBlobs := ctx.QueryInt("largest_blobs")
+	if largestBlobs <= 0 {
+		largestBlobs = defaultLargestBlobs
+	} else if largestBlobs > maxLargestBlobs {
+		largestBlobs = maxLargestBlobs
+	}
+
+	stats, err := git.GetObjectStats(ctx.Req.Context(), ctx.Repo.Repository.RepoPath(), largestBlobs)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetObjectStats", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToGitStats(ctx.Repo.Repository, stats))
+}
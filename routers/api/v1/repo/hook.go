@@ -15,6 +15,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	repo_service "code.gitea.io/gitea/services/repository"
 	"code.gitea.io/gitea/services/webhook"
 )
 
@@ -140,7 +141,7 @@ func TestHook(ctx *context.APIContext) {
 		return
 	}
 
-	if err := webhook.PrepareWebhook(hook, ctx.Repo.Repository, models.HookEventPush, &api.PushPayload{
+	if err := webhook.PrepareWebhook(ctx.Req.Context(), hook, ctx.Repo.Repository, models.HookEventPush, &api.PushPayload{
 		Ref:    git.BranchPrefix + ctx.Repo.Repository.DefaultBranch,
 		Before: ctx.Repo.Commit.ID.String(),
 		After:  ctx.Repo.Commit.ID.String(),
@@ -258,7 +259,11 @@ func DeleteHook(ctx *context.APIContext) {
 	//     "$ref": "#/responses/empty"
 	//   "404":
 	//     "$ref": "#/responses/notFound"
-	if err := models.DeleteWebhookByRepoID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id")); err != nil {
+	hook, err := utils.GetRepoHook(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	if err := models.DeleteWebhookByRepoID(ctx.Repo.Repository.ID, hook.ID); err != nil {
 		if models.IsErrWebhookNotExist(err) {
 			ctx.NotFound()
 		} else {
@@ -266,5 +271,6 @@ func DeleteHook(ctx *context.APIContext) {
 		}
 		return
 	}
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookDelete, hook)
 	ctx.Status(http.StatusNoContent)
 }
@@ -158,11 +158,15 @@ func SearchIssues(ctx *context.APIContext) {
 	}
 	var issueIDs []int64
 	var labelIDs []int64
+	var matches []issue_indexer.Match
 	if len(keyword) > 0 && len(repoIDs) > 0 {
-		if issueIDs, err = issue_indexer.SearchIssuesByKeyword(repoIDs, keyword); err != nil {
+		if matches, err = issue_indexer.SearchIssueMatches(repoIDs, keyword, nil); err != nil {
 			ctx.Error(http.StatusInternalServerError, "SearchIssuesByKeyword", err)
 			return
 		}
+		for _, match := range matches {
+			issueIDs = append(issueIDs, match.ID)
+		}
 	}
 
 	var isPull util.OptionalBool
@@ -240,7 +244,33 @@ func SearchIssues(ctx *context.APIContext) {
 	ctx.SetLinkHeader(int(filteredCount), setting.UI.IssuePagingNum)
 	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", filteredCount))
 	ctx.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, Link")
-	ctx.JSON(http.StatusOK, convert.ToAPIIssueList(issues))
+	ctx.JSON(http.StatusOK, toAPIIssueListWithHighlights(issues, matches))
+}
+
+// apiHighlightFields are the names of the fields of the issue indexer in the highlights of the API
+var apiHighlightFields = map[string]string{
+	issue_indexer.FieldTitle:    "title",
+	issue_indexer.FieldContent:  "body",
+	issue_indexer.FieldComments: "comments",
+}
+
+// toAPIIssueListWithHighlights converts issues like convert.ToAPIIssueList, adding the highlights of the issues
+// matching a keyword search
+func toAPIIssueListWithHighlights(issues []*models.Issue, matches []issue_indexer.Match) []*api.Issue {
+	apiIssues := convert.ToAPIIssueList(issues)
+	highlights := make(map[int64]map[string][]string, len(matches))
+	for _, match := range matches {
+		highlights[match.ID] = match.Highlights
+	}
+	for _, apiIssue := range apiIssues {
+		for field, snippets := range highlights[apiIssue.ID] {
+			if apiIssue.Highlights == nil {
+				apiIssue.Highlights = make(map[string][]string, len(snippets))
+			}
+			apiIssue.Highlights[apiHighlightFields[field]] = snippets
+		}
+	}
+	return apiIssues
 }
 
 // ListIssues list the issues of a repository
@@ -272,7 +302,7 @@ func ListIssues(ctx *context.APIContext) {
 	//   type: string
 	// - name: q
 	//   in: query
-	//   description: search string
+	//   description: "search string, which may contain the qualifiers label, -label, assignee, author, mentions, milestone, is, updated, created and in, e.g. `label:bug -label:wontfix assignee:@me updated:>2020-01-01 in:title`"
 	//   type: string
 	// - name: type
 	//   in: query
@@ -283,6 +313,11 @@ func ListIssues(ctx *context.APIContext) {
 	//   in: query
 	//   description: comma separated list of milestone names or ids. It uses names and fall back to ids. Fetch only issues that have any of this milestones. Non existent milestones are discarded
 	//   type: string
+	// - name: issue_type
+	//   in: query
+	//   description: id of an issue type. Fetch only issues of this type
+	//   type: integer
+	//   format: int64
 	// - name: page
 	//   in: query
 	//   description: page number of results to return (1-based)
@@ -294,6 +329,8 @@ func ListIssues(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/IssueList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	var isClosed util.OptionalBool
 	switch ctx.Query("state") {
@@ -312,15 +349,32 @@ func ListIssues(ctx *context.APIContext) {
 	if strings.IndexByte(keyword, 0) >= 0 {
 		keyword = ""
 	}
+	query, err := issue_indexer.ParseQuery(keyword)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+	filter, err := query.Filter(ctx.Repo.Repository, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Filter", err)
+		return
+	}
+	if query.IsClosed != util.OptionalBoolNone {
+		isClosed = query.IsClosed
+	}
+
 	var issueIDs []int64
 	var labelIDs []int64
-	var err error
-	if len(keyword) > 0 {
-		issueIDs, err = issue_indexer.SearchIssuesByKeyword([]int64{ctx.Repo.Repository.ID}, keyword)
+	var matches []issue_indexer.Match
+	if len(query.Keyword) > 0 {
+		matches, err = issue_indexer.SearchIssueMatches([]int64{ctx.Repo.Repository.ID}, query.Keyword, query.In)
 		if err != nil {
 			ctx.Error(http.StatusInternalServerError, "SearchIssuesByKeyword", err)
 			return
 		}
+		for _, match := range matches {
+			issueIDs = append(issueIDs, match.ID)
+		}
 	}
 
 	if splitted := strings.Split(ctx.Query("labels"), ","); len(splitted) > 0 {
@@ -373,17 +427,38 @@ func ListIssues(ctx *context.APIContext) {
 		isPull = util.OptionalBoolNone
 	}
 
+	// the qualifiers of the query narrow the other filters
+	matchesNothing := filter.MatchesNothing
+	if query.IsPull != util.OptionalBoolNone {
+		matchesNothing = matchesNothing || (isPull != util.OptionalBoolNone && isPull != query.IsPull)
+		isPull = query.IsPull
+	}
+	if filter.MilestoneID > 0 {
+		if len(ctx.Query("milestones")) > 0 && !util.IsInt64InSlice(filter.MilestoneID, mileIDs) {
+			matchesNothing = true
+		}
+		mileIDs = []int64{filter.MilestoneID}
+	}
+
 	// Only fetch the issues if we either don't have a keyword or the search returned issues
 	// This would otherwise return all issues if no issues were found by the search.
-	if len(keyword) == 0 || len(issueIDs) > 0 || len(labelIDs) > 0 {
+	if !matchesNothing && (len(query.Keyword) == 0 || len(issueIDs) > 0 || len(labelIDs) > 0) {
 		issuesOpt := &models.IssuesOptions{
-			ListOptions:  listOptions,
-			RepoIDs:      []int64{ctx.Repo.Repository.ID},
-			IsClosed:     isClosed,
-			IssueIDs:     issueIDs,
-			LabelIDs:     labelIDs,
-			MilestoneIDs: mileIDs,
-			IsPull:       isPull,
+			ListOptions:       listOptions,
+			RepoIDs:           []int64{ctx.Repo.Repository.ID},
+			IsClosed:          isClosed,
+			IssueIDs:          issueIDs,
+			LabelIDs:          append(labelIDs, filter.LabelIDs...),
+			MilestoneIDs:      mileIDs,
+			TypeID:            ctx.QueryInt64("issue_type"),
+			IsPull:            isPull,
+			AssigneeID:        filter.AssigneeID,
+			PosterID:          filter.PosterID,
+			MentionedID:       filter.MentionedID,
+			UpdatedAfterUnix:  query.UpdatedAfterUnix,
+			UpdatedBeforeUnix: query.UpdatedBeforeUnix,
+			CreatedAfterUnix:  query.CreatedAfterUnix,
+			CreatedBeforeUnix: query.CreatedBeforeUnix,
 		}
 
 		if issues, err = models.Issues(issuesOpt); err != nil {
@@ -403,7 +478,7 @@ func ListIssues(ctx *context.APIContext) {
 	ctx.SetLinkHeader(int(filteredCount), listOptions.PageSize)
 	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", filteredCount))
 	ctx.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, Link")
-	ctx.JSON(http.StatusOK, convert.ToAPIIssueList(issues))
+	ctx.JSON(http.StatusOK, toAPIIssueListWithHighlights(issues, matches))
 }
 
 // GetIssue get an issue of a repository
@@ -482,6 +557,10 @@ func CreateIssue(ctx *context.APIContext) {
 	//   "422":
 	//     "$ref": "#/responses/validationError"
 	form := web.GetForm(ctx).(*api.CreateIssueOption)
+	if form.Weight < 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "Issue weight must not be negative")
+		return
+	}
 	var deadlineUnix timeutil.TimeStamp
 	if form.Deadline != nil && ctx.Repo.CanWrite(models.UnitTypeIssues) {
 		deadlineUnix = timeutil.TimeStamp(form.Deadline.Unix())
@@ -502,6 +581,18 @@ func CreateIssue(ctx *context.APIContext) {
 	var err error
 	if ctx.Repo.CanWrite(models.UnitTypeIssues) {
 		issue.MilestoneID = form.Milestone
+		if form.Type > 0 {
+			if _, err := models.GetIssueTypeOfRepoByID(ctx.Repo.Repository, form.Type); err != nil {
+				if models.IsErrIssueTypeNotExist(err) {
+					ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("Issue type does not exist: [id: %d]", form.Type))
+				} else {
+					ctx.Error(http.StatusInternalServerError, "GetIssueTypeOfRepoByID", err)
+				}
+				return
+			}
+			issue.TypeID = form.Type
+		}
+		issue.Weight = form.Weight
 		assigneeIDs, err = models.MakeIDsFromAPIAssigneesToAdd(form.Assignee, form.Assignees)
 		if err != nil {
 			if models.IsErrUserNotExist(err) {
@@ -535,7 +626,7 @@ func CreateIssue(ctx *context.APIContext) {
 		form.Labels = make([]int64, 0)
 	}
 
-	if err := issue_service.NewIssue(ctx.Repo.Repository, issue, form.Labels, nil, assigneeIDs); err != nil {
+	if err := issue_service.NewIssue(ctx.Req.Context(), ctx.Repo.Repository, issue, form.Labels, nil, assigneeIDs); err != nil {
 		if models.IsErrUserDoesNotHaveAccessToRepo(err) {
 			ctx.Error(http.StatusBadRequest, "UserDoesNotHaveAccessToRepo", err)
 			return
@@ -545,7 +636,7 @@ func CreateIssue(ctx *context.APIContext) {
 	}
 
 	if form.Closed {
-		if err := issue_service.ChangeStatus(issue, ctx.User, true); err != nil {
+		if err := issue_service.ChangeStatus(ctx.Req.Context(), issue, ctx.User, true); err != nil {
 			if models.IsErrDependenciesLeft(err) {
 				ctx.Error(http.StatusPreconditionFailed, "DependenciesLeft", "cannot close this issue because it still has open dependencies")
 				return
@@ -603,6 +694,8 @@ func EditIssue(ctx *context.APIContext) {
 	//     "$ref": "#/responses/notFound"
 	//   "412":
 	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	form := web.GetForm(ctx).(*api.EditIssueOption)
 	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
@@ -636,7 +729,7 @@ func EditIssue(ctx *context.APIContext) {
 		issue.Content = *form.Body
 	}
 	if form.Ref != nil {
-		err = issue_service.ChangeIssueRef(issue, ctx.User, *form.Ref)
+		err = issue_service.ChangeIssueRef(ctx.Req.Context(), issue, ctx.User, *form.Ref)
 		if err != nil {
 			ctx.Error(http.StatusInternalServerError, "UpdateRef", err)
 			return
@@ -674,7 +767,7 @@ func EditIssue(ctx *context.APIContext) {
 			oneAssignee = *form.Assignee
 		}
 
-		err = issue_service.UpdateAssignees(issue, oneAssignee, form.Assignees, ctx.User)
+		err = issue_service.UpdateAssignees(ctx.Req.Context(), issue, oneAssignee, form.Assignees, ctx.User)
 		if err != nil {
 			ctx.Error(http.StatusInternalServerError, "UpdateAssignees", err)
 			return
@@ -685,11 +778,31 @@ func EditIssue(ctx *context.APIContext) {
 		issue.MilestoneID != *form.Milestone {
 		oldMilestoneID := issue.MilestoneID
 		issue.MilestoneID = *form.Milestone
-		if err = issue_service.ChangeMilestoneAssign(issue, ctx.User, oldMilestoneID); err != nil {
+		if err = issue_service.ChangeMilestoneAssign(ctx.Req.Context(), issue, ctx.User, oldMilestoneID); err != nil {
 			ctx.Error(http.StatusInternalServerError, "ChangeMilestoneAssign", err)
 			return
 		}
 	}
+	if canWrite && form.Type != nil && !issue.IsPull {
+		if err = models.ChangeIssueType(issue, ctx.User, *form.Type); err != nil {
+			if models.IsErrIssueTypeNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("Issue type does not exist: [id: %d]", *form.Type))
+				return
+			}
+			ctx.Error(http.StatusInternalServerError, "ChangeIssueType", err)
+			return
+		}
+	}
+	if canWrite && form.Weight != nil {
+		if *form.Weight < 0 {
+			ctx.Error(http.StatusUnprocessableEntity, "", "Issue weight must not be negative")
+			return
+		}
+		if err = models.ChangeIssueWeight(issue, ctx.User, *form.Weight); err != nil {
+			ctx.Error(http.StatusInternalServerError, "ChangeIssueWeight", err)
+			return
+		}
+	}
 	if form.State != nil {
 		issue.IsClosed = (api.StateClosed == api.StateType(*form.State))
 	}
@@ -704,11 +817,11 @@ func EditIssue(ctx *context.APIContext) {
 	}
 
 	if titleChanged {
-		notification.NotifyIssueChangeTitle(ctx.User, issue, oldTitle)
+		notification.NotifyIssueChangeTitle(ctx.Req.Context(), ctx.User, issue, oldTitle)
 	}
 
 	if statusChangeComment != nil {
-		notification.NotifyIssueChangeStatus(ctx.User, issue, statusChangeComment, issue.IsClosed)
+		notification.NotifyIssueChangeStatus(ctx.Req.Context(), ctx.User, issue, statusChangeComment, issue.IsClosed)
 	}
 
 	// Refetch from database to assign some automatic values
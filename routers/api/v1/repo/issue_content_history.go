@@ -0,0 +1,158 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"errors"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	issue_service "code.gitea.io/gitea/services/issue"
+)
+
+// GetIssueContentHistory list the versions of the content of an issue
+func GetIssueContentHistory(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issues/{index}/content_history issue issueGetContentHistory
+	// ---
+	// summary: List the versions of the content of an issue or a pull request with their differences, the latest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ContentHistoryList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByIndex", err)
+		}
+		return
+	}
+
+	if !ctx.Repo.CanReadIssuesOrPulls(issue.IsPull) {
+		ctx.Error(http.StatusForbidden, "GetIssueContentHistory", errors.New("no permission to get the content history"))
+		return
+	}
+
+	respondContentHistory(ctx, issue.ID, 0)
+}
+
+// GetIssueCommentContentHistory list the versions of the content of a comment
+func GetIssueCommentContentHistory(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issues/comments/{id}/content_history issue issueGetCommentContentHistory
+	// ---
+	// summary: List the versions of the content of a comment with their differences, the latest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ContentHistoryList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	comment, err := models.GetCommentByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrCommentNotExist(err) {
+			ctx.NotFound(err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCommentByID", err)
+		}
+		return
+	}
+
+	if err := comment.LoadIssue(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadIssue", err)
+		return
+	}
+	if comment.Issue.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return
+	}
+
+	if !ctx.Repo.CanReadIssuesOrPulls(comment.Issue.IsPull) {
+		ctx.Error(http.StatusForbidden, "GetIssueCommentContentHistory", errors.New("no permission to get the content history"))
+		return
+	}
+
+	// the comments of a pending review are only visible to their poster
+	if comment.ReviewID > 0 && (!ctx.IsSigned || ctx.User.ID != comment.PosterID) {
+		if err := comment.LoadReview(); err != nil && !models.IsErrReviewNotExist(err) {
+			ctx.Error(http.StatusInternalServerError, "LoadReview", err)
+			return
+		} else if err == nil && comment.Review.Type == models.ReviewTypePending {
+			ctx.NotFound()
+			return
+		}
+	}
+
+	respondContentHistory(ctx, comment.IssueID, comment.ID)
+}
+
+func respondContentHistory(ctx *context.APIContext, issueID, commentID int64) {
+	versions, err := issue_service.GetContentVersions(issueID, commentID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetContentVersions", err)
+		return
+	}
+
+	result := make([]*api.ContentHistory, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, &api.ContentHistory{
+			ID:             v.ID,
+			Editor:         convert.ToUser(v.Poster, ctx.IsSigned, false),
+			Content:        v.Content,
+			DiffHTML:       string(v.DiffHTML),
+			IsFirstCreated: v.IsFirstCreated,
+			Edited:         v.EditedUnix.AsTime(),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
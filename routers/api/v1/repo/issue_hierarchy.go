@@ -0,0 +1,198 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// GetSubIssueTree get the tree of the sub-issues of an issue
+func GetSubIssueTree(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issues/{index}/sub_issues issue issueGetSubIssues
+	// ---
+	// summary: Get the tree of the sub-issues of an issue, with the number of the issues below each issue which are closed
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/SubIssueNode"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	issue := getIssueForSubIssues(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	tree, err := models.GetSubIssueTree(issue)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetSubIssueTree", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToSubIssueNode(tree))
+}
+
+// AddSubIssue make an issue a sub-issue of another one
+func AddSubIssue(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/issues/{index}/sub_issues issue issueAddSubIssue
+	// ---
+	// summary: Add a sub-issue to an issue, moving it from its former parent if it has one
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/AddSubIssueOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Issue"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.AddSubIssueOption)
+	issue := getIssueForSubIssues(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	subIssue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, form.SubIssueIndex)
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "GetIssueByIndex", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByIndex", err)
+		}
+		return
+	}
+
+	if err := models.SetIssueParent(ctx.User, subIssue, issue); err != nil {
+		if models.IsErrInvalidSubIssue(err) || models.IsErrCircularSubIssue(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "SetIssueParent", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "SetIssueParent", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToAPIIssue(subIssue))
+}
+
+// RemoveSubIssue remove a sub-issue from an issue
+func RemoveSubIssue(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/issues/{index}/sub_issues/{sub_index} issue issueRemoveSubIssue
+	// ---
+	// summary: Remove a sub-issue from an issue
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: sub_index
+	//   in: path
+	//   description: index of the sub-issue to remove
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	issue := getIssueForSubIssues(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	subIssue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":sub_index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByIndex", err)
+		}
+		return
+	}
+	if subIssue.ParentID != issue.ID {
+		ctx.NotFound()
+		return
+	}
+
+	if err := models.SetIssueParent(ctx.User, subIssue, nil); err != nil {
+		ctx.Error(http.StatusInternalServerError, "SetIssueParent", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func getIssueForSubIssues(ctx *context.APIContext) *models.Issue {
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByIndex", err)
+		}
+		return nil
+	}
+	return issue
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+)
+
+// GetIssueInsights returns the usage of issue templates and labels and the response times of the issues of a repository
+func GetIssueInsights(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/insights/issues issue issueGetInsights
+	// ---
+	// summary: Get the usage of issue templates and labels and the response times of the issues of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: period
+	//   in: query
+	//   description: period ending now the insights are computed for
+	//   type: string
+	//   enum: [monthly, quarterly, semiyearly, yearly]
+	//   default: quarterly
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueInsights"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	period := ctx.Query("period")
+	if len(period) == 0 {
+		period = models.DefaultIssueInsightsPeriod
+	}
+	from, ok := models.IssueInsightsPeriodStart(period)
+	if !ok {
+		ctx.Error(http.StatusUnprocessableEntity, "", "invalid period")
+		return
+	}
+
+	insights, err := models.GetIssueInsights(ctx.Repo.Repository.ID, from)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetIssueInsights", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToIssueInsights(insights))
+}
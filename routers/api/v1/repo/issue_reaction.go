@@ -60,6 +60,11 @@ func GetIssueCommentReactions(ctx *context.APIContext) {
 
 	if err := comment.LoadIssue(); err != nil {
 		ctx.Error(http.StatusInternalServerError, "comment.LoadIssue", err)
+		return
+	}
+	if comment.Issue.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return
 	}
 
 	if !ctx.Repo.CanReadIssuesOrPulls(comment.Issue.IsPull) {
@@ -188,12 +193,21 @@ func changeIssueCommentReaction(ctx *context.APIContext, form api.EditReactionOp
 	err = comment.LoadIssue()
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "comment.LoadIssue() failed", err)
+		return
+	}
+	if comment.Issue.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return
 	}
 
 	if comment.Issue.IsLocked && !ctx.Repo.CanWriteIssuesOrPulls(comment.Issue.IsPull) {
 		ctx.Error(http.StatusForbidden, "ChangeIssueCommentReaction", errors.New("no permission to change reaction"))
 		return
 	}
+	if !comment.SupportsReactions() {
+		ctx.Error(http.StatusForbidden, "ChangeIssueCommentReaction", errors.New("the comment does not support reactions"))
+		return
+	}
 
 	if isCreateType {
 		// PostIssueCommentReaction part
@@ -308,6 +322,74 @@ func GetIssueReactions(ctx *context.APIContext) {
 	ctx.JSON(http.StatusOK, result)
 }
 
+// GetIssueReactionCounts count the reactions of each type on an issue and its comments
+func GetIssueReactionCounts(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issues/{index}/reactions/counts issue issueGetIssueReactionCounts
+	// ---
+	// summary: Count the reactions of each type on an issue or a pull request and on its comments
+	// description: The reactions on the code review comments and on the review summaries of a pull request are counted apart from the reactions on its other comments. The most used reaction comes first.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ReactionCountList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByIndex", err)
+		}
+		return
+	}
+
+	if !ctx.Repo.CanReadIssuesOrPulls(issue.IsPull) {
+		ctx.Error(http.StatusForbidden, "GetIssueReactionCounts", errors.New("no permission to get reactions"))
+		return
+	}
+
+	counts, err := models.GetIssueReactionCounts(issue.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetIssueReactionCounts", err)
+		return
+	}
+
+	result := make([]*api.ReactionCount, 0, len(counts))
+	for _, c := range counts {
+		result = append(result, &api.ReactionCount{
+			Reaction:       c.Type,
+			Total:          c.Total(),
+			Issue:          c.Issue,
+			Comments:       c.Comments,
+			ReviewComments: c.ReviewComments,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
 // PostIssueReaction add a reaction to an issue
 func PostIssueReaction(ctx *context.APIContext) {
 	// swagger:operation POST /repos/{owner}/{repo}/issues/{index}/reactions issue issuePostIssueReaction
@@ -5,12 +5,14 @@
 package repo
 
 import (
+	"fmt"
 	"net/http"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
@@ -277,10 +279,152 @@ func GetIssueSubscribers(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "GetUsersByIDs", err)
 		return
 	}
-	apiUsers := make([]*api.User, 0, len(users))
+	apiUsers := make([]*api.User, len(users))
 	for i := range users {
 		apiUsers[i] = convert.ToUser(users[i], ctx.IsSigned, false)
 	}
 
 	ctx.JSON(http.StatusOK, apiUsers)
 }
+
+// AddIssueSubscribers subscribe users to an issue
+func AddIssueSubscribers(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/issues/{index}/subscriptions issue issueAddSubscribers
+	// ---
+	// summary: Subscribe users to an issue
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/IssueSubscribersOption"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	setIssueSubscribers(ctx, *web.GetForm(ctx).(*api.IssueSubscribersOption), true)
+}
+
+// DelIssueSubscribers unsubscribe users from an issue
+func DelIssueSubscribers(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/issues/{index}/subscriptions issue issueDeleteSubscribers
+	// ---
+	// summary: Unsubscribe users from an issue
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/IssueSubscribersOption"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	setIssueSubscribers(ctx, *web.GetForm(ctx).(*api.IssueSubscribersOption), false)
+}
+
+// setIssueSubscribers changes the subscription of several users to an issue; nothing is changed unless all the users
+// exist and can read the issue
+func setIssueSubscribers(ctx *context.APIContext, opts api.IssueSubscribersOption, watch bool) {
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByIndex", err)
+		}
+		return
+	}
+
+	// only admins and the users who can write the issue can change the subscriptions of other users
+	if !ctx.User.IsAdmin && !ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+		ctx.Error(http.StatusForbidden, "CanWriteIssuesOrPulls", "user should have permission to write the issue")
+		return
+	}
+
+	users := make([]*models.User, 0, len(opts.Usernames))
+	for _, name := range opts.Usernames {
+		user, err := models.GetUserByName(name)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "GetUserByName", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			}
+			return
+		}
+		if watch {
+			perm, err := models.GetUserRepoPermission(ctx.Repo.Repository, user)
+			if err != nil {
+				ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
+				return
+			}
+			if !perm.CanReadIssuesOrPulls(issue.IsPull) {
+				ctx.Error(http.StatusUnprocessableEntity, "CanReadIssuesOrPulls", fmt.Errorf("user %s cannot read the issue", user.Name))
+				return
+			}
+		}
+		users = append(users, user)
+	}
+
+	for _, user := range users {
+		if err := models.CreateOrUpdateIssueWatch(user.ID, issue.ID, watch); err != nil {
+			ctx.Error(http.StatusInternalServerError, "CreateOrUpdateIssueWatch", err)
+			return
+		}
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -598,3 +598,104 @@ func ListMyTrackedTimes(ctx *context.APIContext) {
 
 	ctx.JSON(http.StatusOK, convert.ToTrackedTimeList(trackedTimes))
 }
+
+// SumTrackedTimesByRepository sums the times tracked on the issues of a repository by issue, label or milestone
+func SumTrackedTimesByRepository(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/insights/times repository repoSumTrackedTimes
+	// ---
+	// summary: Sum a repo's tracked times by issue, label or milestone
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: by
+	//   in: query
+	//   description: what to sum the times by, the times of an issue with several labels counting for each of them
+	//   type: string
+	//   enum: [issue, label, milestone]
+	//   default: issue
+	// - name: user
+	//   in: query
+	//   description: optional filter by user (available for issue managers)
+	//   type: string
+	// - name: since
+	//   in: query
+	//   description: Only sum times tracked after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: Only sum times tracked before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/TrackedTimeSumList"
+	//   "400":
+	//     "$ref": "#/responses/error"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	if !ctx.Repo.Repository.IsTimetrackerEnabled() {
+		ctx.Error(http.StatusBadRequest, "", "time tracking disabled")
+		return
+	}
+
+	by := ctx.Query("by")
+	switch by {
+	case "":
+		by = models.TrackedTimeSumByIssue
+	case models.TrackedTimeSumByIssue, models.TrackedTimeSumByLabel, models.TrackedTimeSumByMilestone:
+	default:
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("cannot sum tracked times by %q", by))
+		return
+	}
+
+	opts := models.FindTrackedTimesOptions{RepositoryID: ctx.Repo.Repository.ID}
+	if qUser := strings.TrimSpace(ctx.Query("user")); qUser != "" {
+		user, err := models.GetUserByName(qUser)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.NotFound()
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			}
+			return
+		}
+		opts.UserID = user.ID
+	}
+
+	var err error
+	if opts.CreatedBeforeUnix, opts.CreatedAfterUnix, err = utils.GetQueryBeforeSince(ctx); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetQueryBeforeSince", err)
+		return
+	}
+
+	if !ctx.User.IsAdmin && opts.UserID != ctx.User.ID && !ctx.IsUserRepoWriter([]models.UnitType{models.UnitTypeIssues}) {
+		if opts.UserID != 0 {
+			ctx.Error(http.StatusForbidden, "", fmt.Errorf("query user not allowed, not enough rights"))
+			return
+		}
+		opts.UserID = ctx.User.ID
+	}
+
+	sums, err := models.SumTrackedTimes(opts, by)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "SumTrackedTimes", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToTrackedTimeSums(sums))
+}
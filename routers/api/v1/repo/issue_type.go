@@ -0,0 +1,263 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// ListIssueTypes list the issue types the issues of a repository can have
+func ListIssueTypes(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issue_types issue issueListIssueTypes
+	// ---
+	// summary: Get the issue types of a repository, including the ones of its organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueTypeList"
+
+	types, err := models.GetIssueTypesOfRepo(ctx.Repo.Repository)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetIssueTypesOfRepo", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToIssueTypeList(types))
+}
+
+// GetIssueType get an issue type of a repository
+func GetIssueType(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issue_types/{id} issue issueGetIssueType
+	// ---
+	// summary: Get a single issue type
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the issue type to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueType"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	t, err := models.GetIssueTypeOfRepoByID(ctx.Repo.Repository, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrIssueTypeNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueTypeOfRepoByID", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToIssueType(t))
+}
+
+// CreateIssueType create an issue type for a repository
+func CreateIssueType(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/issue_types issue issueCreateIssueType
+	// ---
+	// summary: Create an issue type
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateIssueTypeOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/IssueType"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateIssueTypeOption)
+	t := &models.IssueType{
+		RepoID:       ctx.Repo.Repository.ID,
+		Name:         form.Name,
+		Description:  form.Description,
+		Color:        form.Color,
+		TemplateFile: form.TemplateFile,
+	}
+	if err := models.NewIssueType(t); err != nil {
+		handleIssueTypeError(ctx, "NewIssueType", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToIssueType(t))
+}
+
+// EditIssueType modify an issue type of a repository
+func EditIssueType(ctx *context.APIContext) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/issue_types/{id} issue issueEditIssueType
+	// ---
+	// summary: Update an issue type
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the issue type to edit
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditIssueTypeOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueType"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditIssueTypeOption)
+	t, err := models.GetIssueTypeInRepoByID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrIssueTypeNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueTypeInRepoByID", err)
+		}
+		return
+	}
+
+	applyEditIssueTypeOption(t, form)
+	if err := models.UpdateIssueType(t); err != nil {
+		handleIssueTypeError(ctx, "UpdateIssueType", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToIssueType(t))
+}
+
+// DeleteIssueType delete an issue type of a repository
+func DeleteIssueType(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/issue_types/{id} issue issueDeleteIssueType
+	// ---
+	// summary: Delete an issue type, the issues of the type no longer having one
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the issue type to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	t, err := models.GetIssueTypeInRepoByID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrIssueTypeNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueTypeInRepoByID", err)
+		}
+		return
+	}
+	if err := models.DeleteIssueType(t); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteIssueType", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func applyEditIssueTypeOption(t *models.IssueType, form *api.EditIssueTypeOption) {
+	if form.Name != nil {
+		t.Name = *form.Name
+	}
+	if form.Color != nil {
+		t.Color = *form.Color
+	}
+	if form.Description != nil {
+		t.Description = *form.Description
+	}
+	if form.TemplateFile != nil {
+		t.TemplateFile = *form.TemplateFile
+	}
+}
+
+func handleIssueTypeError(ctx *context.APIContext, title string, err error) {
+	switch {
+	case models.IsErrIssueTypeAlreadyExist(err):
+		ctx.Error(http.StatusConflict, title, err)
+	case models.IsErrInvalidIssueType(err):
+		ctx.Error(http.StatusUnprocessableEntity, title, err)
+	default:
+		ctx.Error(http.StatusInternalServerError, title, err)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	issue_service "code.gitea.io/gitea/services/issue"
 )
 
 // ListMilestones list milestones for a repository
@@ -67,6 +68,10 @@ func ListMilestones(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "GetMilestones", err)
 		return
 	}
+	if err := milestones.LoadTotalWeights(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadTotalWeights", err)
+		return
+	}
 
 	apiMilestones := make([]*api.Milestone, len(milestones))
 	for i := range milestones {
@@ -106,10 +111,56 @@ func GetMilestone(ctx *context.APIContext) {
 	if ctx.Written() {
 		return
 	}
+	if err := milestone.LoadTotalWeight(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadTotalWeight", err)
+		return
+	}
 
 	ctx.JSON(http.StatusOK, convert.ToAPIMilestone(milestone))
 }
 
+// GetMilestoneStats get the burndown and the velocity of a milestone
+func GetMilestoneStats(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/milestones/{id}/stats issue issueGetMilestoneStats
+	// ---
+	// summary: Get the burndown and the velocity of a milestone
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: the milestone to get, identified by ID and if not available by name
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MilestoneStats"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	milestone := getMilestoneByIDOrName(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	stats, err := issue_service.GetMilestoneStats(milestone)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetMilestoneStats", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToMilestoneStats(stats))
+}
+
 // CreateMilestone create a milestone for a repository
 func CreateMilestone(ctx *context.APIContext) {
 	// swagger:operation POST /repos/{owner}/{repo}/milestones issue issueCreateMilestone
@@ -5,10 +5,20 @@
 package repo
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
 	mirror_service "code.gitea.io/gitea/services/mirror"
 )
 
@@ -46,3 +56,518 @@ func MirrorSync(ctx *context.APIContext) {
 
 	ctx.Status(http.StatusOK)
 }
+
+// parseMirrorInterval parses the interval between the syncs of a mirror, it has to be 0 or at least the minimum interval
+func parseMirrorInterval(ctx *context.APIContext, s string) (time.Duration, bool) {
+	interval, err := time.ParseDuration(s)
+	if err != nil || interval < 0 || (interval != 0 && interval < setting.Mirror.MinInterval) {
+		ctx.Error(http.StatusUnprocessableEntity, "Interval", fmt.Errorf("invalid interval %q, it must be 0 or at least %s", s, setting.Mirror.MinInterval))
+		return 0, false
+	}
+	return interval, true
+}
+
+// parseSyncWindowTime parses a bound of the sync window of a mirror, an empty one is midnight
+func parseSyncWindowTime(ctx *context.APIContext, s string) (int, bool) {
+	if len(s) == 0 {
+		return 0, true
+	}
+	minutes, err := models.ParseSyncWindowTime(s)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "SyncWindow", err)
+		return 0, false
+	}
+	return minutes, true
+}
+
+// validateRefPatterns checks the patterns of the refs a mirror is restricted to
+func validateRefPatterns(ctx *context.APIContext, patterns ...[]string) bool {
+	for _, p := range patterns {
+		if err := mirror_service.ValidateRefPatterns(p); err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "RefPatterns", err)
+			return false
+		}
+	}
+	return true
+}
+
+// GetMirror gets the settings of a mirrored repository
+func GetMirror(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/mirror repository repoGetMirror
+	// ---
+	// summary: Get the settings of a mirrored repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Mirror"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	m := getRepoMirror(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToMirror(m, mirror_service.Address(m)))
+}
+
+func getRepoMirror(ctx *context.APIContext) *models.Mirror {
+	repo := ctx.Repo.Repository
+	if !repo.IsMirror {
+		ctx.NotFound()
+		return nil
+	}
+	if err := repo.GetMirror(); err != nil {
+		if err == models.ErrMirrorNotExist {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetMirror", err)
+		}
+		return nil
+	}
+	return repo.Mirror
+}
+
+// EditMirror edits the settings of a mirrored repository
+func EditMirror(ctx *context.APIContext) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/mirror repository repoEditMirror
+	// ---
+	// summary: Edit the settings of a mirrored repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditMirrorOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Mirror"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditMirrorOption)
+	m := getRepoMirror(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	var ok bool
+	if form.Interval != nil {
+		if m.Interval, ok = parseMirrorInterval(ctx, *form.Interval); !ok {
+			return
+		}
+	}
+	if form.EnablePrune != nil {
+		m.EnablePrune = *form.EnablePrune
+	}
+	if form.SyncWindowStart != nil {
+		if m.SyncWindowStart, ok = parseSyncWindowTime(ctx, *form.SyncWindowStart); !ok {
+			return
+		}
+	}
+	if form.SyncWindowEnd != nil {
+		if m.SyncWindowEnd, ok = parseSyncWindowTime(ctx, *form.SyncWindowEnd); !ok {
+			return
+		}
+	}
+	if !validateRefPatterns(ctx, form.IncludeRefs, form.ExcludeRefs) {
+		return
+	}
+	if form.IncludeRefs != nil {
+		m.IncludeRefs = form.IncludeRefs
+	}
+	if form.ExcludeRefs != nil {
+		m.ExcludeRefs = form.ExcludeRefs
+	}
+	m.ScheduleNextUpdate()
+	if err := models.UpdateMirror(m); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateMirror", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToMirror(m, mirror_service.Address(m)))
+}
+
+// DeleteMirror converts a mirrored repository into a regular one
+func DeleteMirror(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/mirror repository repoDeleteMirror
+	// ---
+	// summary: Convert a mirrored repository into a regular repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	repo := ctx.Repo.Repository
+	if !repo.IsMirror {
+		ctx.NotFound()
+		return
+	}
+	repo.IsMirror = false
+	if _, err := repo_module.CleanUpMigrateInfo(repo); err != nil {
+		ctx.Error(http.StatusInternalServerError, "CleanUpMigrateInfo", err)
+		return
+	} else if err = models.DeleteMirrorByRepoID(repo.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteMirrorByRepoID", err)
+		return
+	}
+	log.Trace("Repository converted from mirror to regular: %s", repo.FullName())
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListPushMirrors lists the push mirrors of a repository
+func ListPushMirrors(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/push_mirrors repository repoListPushMirrors
+	// ---
+	// summary: List the push mirrors of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PushMirrorList"
+
+	mirrors, err := models.GetPushMirrorsByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPushMirrorsByRepoID", err)
+		return
+	}
+	apiMirrors := make([]*api.PushMirror, len(mirrors))
+	for i, m := range mirrors {
+		apiMirrors[i] = convert.ToPushMirror(m, mirror_service.PushMirrorAddress(m))
+	}
+	ctx.JSON(http.StatusOK, apiMirrors)
+}
+
+// CreatePushMirror adds a push mirror to a repository
+func CreatePushMirror(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/push_mirrors repository repoCreatePushMirror
+	// ---
+	// summary: Add a push mirror to a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreatePushMirrorOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/PushMirror"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreatePushMirrorOption)
+	if setting.Repository.DisableMirrors {
+		ctx.Error(http.StatusForbidden, "", "the site administrator has disabled the creation of mirrors")
+		return
+	}
+
+	if !validateRefPatterns(ctx, form.IncludeRefs, form.ExcludeRefs) {
+		return
+	}
+	m := &models.PushMirror{
+		RepoID:      ctx.Repo.Repository.ID,
+		Repo:        ctx.Repo.Repository,
+		IncludeRefs: form.IncludeRefs,
+		ExcludeRefs: form.ExcludeRefs,
+	}
+	var ok bool
+	if len(form.Interval) > 0 {
+		if m.Interval, ok = parseMirrorInterval(ctx, form.Interval); !ok {
+			return
+		}
+	}
+	if m.SyncWindowStart, ok = parseSyncWindowTime(ctx, form.SyncWindowStart); !ok {
+		return
+	}
+	if m.SyncWindowEnd, ok = parseSyncWindowTime(ctx, form.SyncWindowEnd); !ok {
+		return
+	}
+
+	address, err := auth.ParseRemoteAddr(form.RemoteAddress, form.RemoteUsername, form.RemotePassword)
+	if err == nil {
+		err = migrations.IsMigrateURLAllowed(address, ctx.User)
+	}
+	if err != nil {
+		handleRemoteAddrError(ctx, err)
+		return
+	}
+
+	if err := mirror_service.AddPushMirror(m, address); err != nil {
+		ctx.Error(http.StatusInternalServerError, "AddPushMirror", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToPushMirror(m, mirror_service.PushMirrorAddress(m)))
+}
+
+func getPushMirror(ctx *context.APIContext) *models.PushMirror {
+	m, err := models.GetPushMirror(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrPushMirrorNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPushMirror", err)
+		}
+		return nil
+	}
+	return m
+}
+
+// GetPushMirror gets a push mirror of a repository
+func GetPushMirror(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/push_mirrors/{id} repository repoGetPushMirror
+	// ---
+	// summary: Get a push mirror of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the push mirror
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PushMirror"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	m := getPushMirror(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToPushMirror(m, mirror_service.PushMirrorAddress(m)))
+}
+
+// EditPushMirror edits a push mirror of a repository
+func EditPushMirror(ctx *context.APIContext) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/push_mirrors/{id} repository repoEditPushMirror
+	// ---
+	// summary: Edit a push mirror of a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the push mirror
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditPushMirrorOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PushMirror"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditPushMirrorOption)
+	m := getPushMirror(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	var ok bool
+	if form.Interval != nil {
+		if m.Interval, ok = parseMirrorInterval(ctx, *form.Interval); !ok {
+			return
+		}
+	}
+	if form.SyncWindowStart != nil {
+		if m.SyncWindowStart, ok = parseSyncWindowTime(ctx, *form.SyncWindowStart); !ok {
+			return
+		}
+	}
+	if form.SyncWindowEnd != nil {
+		if m.SyncWindowEnd, ok = parseSyncWindowTime(ctx, *form.SyncWindowEnd); !ok {
+			return
+		}
+	}
+	if !validateRefPatterns(ctx, form.IncludeRefs, form.ExcludeRefs) {
+		return
+	}
+	if form.IncludeRefs != nil {
+		m.IncludeRefs = form.IncludeRefs
+	}
+	if form.ExcludeRefs != nil {
+		m.ExcludeRefs = form.ExcludeRefs
+	}
+	m.ScheduleNextUpdate()
+	if err := models.UpdatePushMirror(m); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdatePushMirror", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToPushMirror(m, mirror_service.PushMirrorAddress(m)))
+}
+
+// DeletePushMirror deletes a push mirror of a repository
+func DeletePushMirror(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/push_mirrors/{id} repository repoDeletePushMirror
+	// ---
+	// summary: Delete a push mirror of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the push mirror
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	m := getPushMirror(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := mirror_service.RemovePushMirror(m); err != nil {
+		ctx.Error(http.StatusInternalServerError, "RemovePushMirror", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// SyncPushMirror adds a push mirror to the sync queue
+func SyncPushMirror(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/push_mirrors/{id}/sync repository repoSyncPushMirror
+	// ---
+	// summary: Push a repository to one of its push mirrors
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the push mirror
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	m := getPushMirror(ctx)
+	if ctx.Written() {
+		return
+	}
+	mirror_service.StartToPushMirror(m.ID)
+	ctx.Status(http.StatusAccepted)
+}
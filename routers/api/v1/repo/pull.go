@@ -423,7 +423,7 @@ func CreatePullRequest(ctx *context.APIContext) {
 		}
 	}
 
-	if err := pull_service.NewPullRequest(repo, prIssue, labelIDs, []string{}, pr, assigneeIDs); err != nil {
+	if err := pull_service.NewPullRequest(ctx.Req.Context(), repo, prIssue, labelIDs, []string{}, pr, assigneeIDs); err != nil {
 		if models.IsErrUserDoesNotHaveAccessToRepo(err) {
 			ctx.Error(http.StatusBadRequest, "UserDoesNotHaveAccessToRepo", err)
 			return
@@ -509,6 +509,9 @@ func EditPullRequest(ctx *context.APIContext) {
 	if len(form.Body) > 0 {
 		issue.Content = form.Body
 	}
+	if form.Draft != nil {
+		pr.SetWorkInProgress(*form.Draft)
+	}
 
 	// Update or remove deadline if set
 	if form.Deadline != nil || form.RemoveDeadline != nil {
@@ -535,7 +538,7 @@ func EditPullRequest(ctx *context.APIContext) {
 	// Send an empty array ([]) to clear all assignees from the Issue.
 
 	if ctx.Repo.CanWrite(models.UnitTypePullRequests) && (form.Assignees != nil || len(form.Assignee) > 0) {
-		err = issue_service.UpdateAssignees(issue, form.Assignee, form.Assignees, ctx.User)
+		err = issue_service.UpdateAssignees(ctx.Req.Context(), issue, form.Assignee, form.Assignees, ctx.User)
 		if err != nil {
 			if models.IsErrUserNotExist(err) {
 				ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("Assignee does not exist: [name: %s]", err))
@@ -550,7 +553,7 @@ func EditPullRequest(ctx *context.APIContext) {
 		issue.MilestoneID != form.Milestone {
 		oldMilestoneID := issue.MilestoneID
 		issue.MilestoneID = form.Milestone
-		if err = issue_service.ChangeMilestoneAssign(issue, ctx.User, oldMilestoneID); err != nil {
+		if err = issue_service.ChangeMilestoneAssign(ctx.Req.Context(), issue, ctx.User, oldMilestoneID); err != nil {
 			ctx.Error(http.StatusInternalServerError, "ChangeMilestoneAssign", err)
 			return
 		}
@@ -593,11 +596,11 @@ func EditPullRequest(ctx *context.APIContext) {
 	}
 
 	if titleChanged {
-		notification.NotifyIssueChangeTitle(ctx.User, issue, oldTitle)
+		notification.NotifyIssueChangeTitle(ctx.Req.Context(), ctx.User, issue, oldTitle)
 	}
 
 	if statusChangeComment != nil {
-		notification.NotifyIssueChangeStatus(ctx.User, issue, statusChangeComment, issue.IsClosed)
+		notification.NotifyIssueChangeStatus(ctx.Req.Context(), ctx.User, issue, statusChangeComment, issue.IsClosed)
 	}
 
 	// change pull target branch
@@ -621,7 +624,7 @@ func EditPullRequest(ctx *context.APIContext) {
 			}
 			return
 		}
-		notification.NotifyPullRequestChangeTargetBranch(ctx.User, pr, form.Base)
+		notification.NotifyPullRequestChangeTargetBranch(ctx.Req.Context(), ctx.User, pr, form.Base)
 	}
 
 	// Refetch from database
@@ -685,11 +688,87 @@ func IsPullRequestMerged(ctx *context.APIContext) {
 	ctx.NotFound()
 }
 
+// CancelScheduledAutoMerge cancels the scheduled merge of a pull request
+func CancelScheduledAutoMerge(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/pulls/{index}/merge repository repoCancelScheduledAutoMerge
+	// ---
+	// summary: Cancel the scheduled auto merge for the given pull request
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request to merge
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPullRequestByIndex", err)
+		}
+		return
+	}
+
+	exist, scheduledPRM, err := models.GetScheduledMergeByPullID(pr.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetScheduledMergeByPullID", err)
+		return
+	}
+	if !exist {
+		ctx.NotFound()
+		return
+	}
+
+	if ctx.User.ID != scheduledPRM.DoerID {
+		allowed, err := pull_service.IsUserAllowedToMerge(pr, ctx.Repo.Permission, ctx.User)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "IsUserAllowedToMerge", err)
+			return
+		}
+		if !allowed {
+			ctx.Error(http.StatusForbidden, "CancelScheduledAutoMerge", "user has no permission to cancel the scheduled auto merge")
+			return
+		}
+	}
+
+	if err := pull_service.CancelScheduledAutoMerge(ctx.User, pr); err != nil {
+		if models.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CancelScheduledAutoMerge", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
 // MergePullRequest merges a PR given an index
 func MergePullRequest(ctx *context.APIContext) {
 	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/merge repository repoMergePullRequest
 	// ---
 	// summary: Merge a pull request
+	// description: If the base branch uses a merge queue the pull request is added to the queue instead and 202 is returned. If merge_when_checks_succeed is set and the required checks have not succeeded yet, the pull request is scheduled to be merged automatically and 202 is returned.
 	// produces:
 	// - application/json
 	// parameters:
@@ -716,6 +795,8 @@ func MergePullRequest(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/empty"
+	//   "202":
+	//     "$ref": "#/responses/empty"
 	//   "405":
 	//     "$ref": "#/responses/empty"
 	//   "409":
@@ -774,7 +855,7 @@ func MergePullRequest(ctx *context.APIContext) {
 
 	// handle manually-merged mark
 	if models.MergeStyle(form.Do) == models.MergeStyleManuallyMerged {
-		if err = pull_service.MergedManually(pr, ctx.User, ctx.Repo.GitRepo, form.MergeCommitID); err != nil {
+		if err = pull_service.MergedManually(ctx.Req.Context(), pr, ctx.User, ctx.Repo.GitRepo, form.MergeCommitID); err != nil {
 			if models.IsErrInvalidMergeStyle(err) {
 				ctx.Error(http.StatusMethodNotAllowed, "Invalid merge style", fmt.Errorf("%s is not allowed an allowed merge style for this repository", models.MergeStyle(form.Do)))
 				return
@@ -800,12 +881,15 @@ func MergePullRequest(ctx *context.APIContext) {
 		return
 	}
 
+	scheduleAutoMerge := false
 	if err := pull_service.CheckPRReadyToMerge(pr, false); err != nil {
 		if !models.IsErrNotAllowedToMerge(err) {
 			ctx.Error(http.StatusInternalServerError, "CheckPRReadyToMerge", err)
 			return
 		}
-		if form.ForceMerge != nil && *form.ForceMerge {
+		if form.MergeWhenChecksSucceed {
+			scheduleAutoMerge = true
+		} else if form.ForceMerge != nil && *form.ForceMerge {
 			if isRepoAdmin, err := models.IsUserRepoAdmin(pr.BaseRepo, ctx.User); err != nil {
 				ctx.Error(http.StatusInternalServerError, "IsUserRepoAdmin", err)
 				return
@@ -846,7 +930,43 @@ func MergePullRequest(ctx *context.APIContext) {
 		message += "\n\n" + form.MergeMessageField
 	}
 
-	if err := pull_service.Merge(pr, ctx.User, ctx.Repo.GitRepo, models.MergeStyle(form.Do), message); err != nil {
+	if scheduleAutoMerge {
+		if err := pull_service.ScheduleAutoMerge(ctx.User, pr, models.MergeStyle(form.Do), message); err != nil {
+			if models.IsErrInvalidMergeStyle(err) {
+				ctx.Error(http.StatusMethodNotAllowed, "Invalid merge style", fmt.Errorf("%s is not allowed an allowed merge style for this repository", models.MergeStyle(form.Do)))
+			} else if models.IsErrAlreadyScheduledToAutoMerge(err) {
+				ctx.Error(http.StatusConflict, "ScheduleAutoMerge", "The pull request is already scheduled to merge automatically")
+			} else {
+				ctx.Error(http.StatusInternalServerError, "ScheduleAutoMerge", err)
+			}
+			return
+		}
+		log.Trace("Pull request scheduled to merge automatically: %d", pr.ID)
+		ctx.Status(http.StatusAccepted)
+		return
+	}
+
+	if err := pr.LoadProtectedBranch(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadProtectedBranch", err)
+		return
+	}
+	if pr.ProtectedBranch != nil && pr.ProtectedBranch.EnableMergeQueue {
+		if err := pull_service.AddToMergeQueue(pr, ctx.User, models.MergeStyle(form.Do), message); err != nil {
+			if models.IsErrInvalidMergeStyle(err) {
+				ctx.Error(http.StatusMethodNotAllowed, "Invalid merge style", fmt.Errorf("%s is not allowed an allowed merge style for this repository", models.MergeStyle(form.Do)))
+			} else if models.IsErrPullAlreadyInMergeQueue(err) {
+				ctx.Error(http.StatusConflict, "AddToMergeQueue", "The pull request is already in the merge queue")
+			} else {
+				ctx.Error(http.StatusInternalServerError, "AddToMergeQueue", err)
+			}
+			return
+		}
+		log.Trace("Pull request added to the merge queue: %d", pr.ID)
+		ctx.Status(http.StatusAccepted)
+		return
+	}
+
+	if err := pull_service.Merge(ctx.Req.Context(), pr, ctx.User, ctx.Repo.GitRepo, models.MergeStyle(form.Do), message); err != nil {
 		if models.IsErrInvalidMergeStyle(err) {
 			ctx.Error(http.StatusMethodNotAllowed, "Invalid merge style", fmt.Errorf("%s is not allowed an allowed merge style for this repository", models.MergeStyle(form.Do)))
 			return
@@ -859,6 +979,9 @@ func MergePullRequest(ctx *context.APIContext) {
 		} else if models.IsErrMergeUnrelatedHistories(err) {
 			conflictError := err.(models.ErrMergeUnrelatedHistories)
 			ctx.JSON(http.StatusConflict, conflictError)
+		} else if models.IsErrMergeDivergingFastForwardOnly(err) {
+			ctx.Error(http.StatusConflict, "Merge", "the base branch can not be fast-forwarded to the head branch")
+			return
 		} else if git.IsErrPushOutOfDate(err) {
 			ctx.Error(http.StatusConflict, "Merge", "merge push out of date")
 			return
@@ -944,7 +1067,7 @@ func parseCompareInfo(ctx *context.APIContext, form api.CreatePullRequestOption)
 		headRepo = ctx.Repo.Repository
 		headGitRepo = ctx.Repo.GitRepo
 	} else {
-		headGitRepo, err = git.OpenRepository(models.RepoPath(headUser.Name, headRepo.Name))
+		headGitRepo, err = git.OpenRepositoryCtx(ctx.Req.Context(), models.RepoPath(headUser.Name, headRepo.Name))
 		if err != nil {
 			ctx.Error(http.StatusInternalServerError, "OpenRepository", err)
 			return nil, nil, nil, nil, "", ""
@@ -1006,11 +1129,11 @@ func parseCompareInfo(ctx *context.APIContext, form api.CreatePullRequestOption)
 	return headUser, headRepo, headGitRepo, compareInfo, baseBranch, headBranch
 }
 
-// UpdatePullRequest merge PR's baseBranch into headBranch
+// UpdatePullRequest merge PR's baseBranch into headBranch or rebase headBranch on to baseBranch
 func UpdatePullRequest(ctx *context.APIContext) {
 	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/update repository repoUpdatePullRequest
 	// ---
-	// summary: Merge PR's baseBranch into headBranch
+	// summary: Merge PR's baseBranch into headBranch or rebase headBranch on to baseBranch
 	// produces:
 	// - application/json
 	// parameters:
@@ -1030,6 +1153,11 @@ func UpdatePullRequest(ctx *context.APIContext) {
 	//   type: integer
 	//   format: int64
 	//   required: true
+	// - name: style
+	//   in: query
+	//   description: how to update the pull request, rebase requires the head branch to be unprotected
+	//   type: string
+	//   enum: [merge, rebase]
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/empty"
@@ -1076,7 +1204,14 @@ func UpdatePullRequest(ctx *context.APIContext) {
 		return
 	}
 
-	allowedUpdate, err := pull_service.IsUserAllowedToUpdate(pr, ctx.User)
+	rebase := ctx.Query("style") == "rebase"
+
+	var allowedUpdate bool
+	if rebase {
+		allowedUpdate, err = pull_service.IsUserAllowedToUpdateByRebase(pr, ctx.User)
+	} else {
+		allowedUpdate, err = pull_service.IsUserAllowedToUpdate(pr, ctx.User)
+	}
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "IsUserAllowedToMerge", err)
 		return
@@ -1090,14 +1225,83 @@ func UpdatePullRequest(ctx *context.APIContext) {
 	// default merge commit message
 	message := fmt.Sprintf("Merge branch '%s' into %s", pr.BaseBranch, pr.HeadBranch)
 
-	if err = pull_service.Update(pr, ctx.User, message); err != nil {
+	if err = pull_service.Update(ctx.Req.Context(), pr, ctx.User, message, rebase); err != nil {
 		if models.IsErrMergeConflicts(err) {
 			ctx.Error(http.StatusConflict, "Update", "merge failed because of conflict")
 			return
 		}
+		if models.IsErrRebaseConflicts(err) {
+			ctx.Error(http.StatusConflict, "Update", "rebase failed because of conflict")
+			return
+		}
 		ctx.Error(http.StatusInternalServerError, "pull_service.Update", err)
 		return
 	}
 
 	ctx.Status(http.StatusOK)
 }
+
+// ConvertPullRequestToIssue closes a pull request and opens an issue with its content
+func ConvertPullRequestToIssue(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/convert repository repoConvertPullRequestToIssue
+	// ---
+	// summary: Close a pull request and open an issue carrying over its content, labels and participants
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request to convert
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Issue"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPullRequestByIndex", err)
+		}
+		return
+	}
+
+	if !ctx.Repo.CanWrite(models.UnitTypePullRequests) || !ctx.Repo.CanWrite(models.UnitTypeIssues) {
+		ctx.Status(http.StatusForbidden)
+		return
+	}
+
+	issue, err := pull_service.ConvertToIssue(ctx.Req.Context(), pr, ctx.User)
+	if err != nil {
+		if models.IsErrPullWasClosed(err) || models.IsErrPullRequestHasMerged(err) {
+			ctx.Error(http.StatusConflict, "ConvertToIssue", "pull request is closed or has been merged")
+			return
+		} else if models.IsErrDependenciesLeft(err) {
+			ctx.Error(http.StatusConflict, "ConvertToIssue", "cannot close pull request because it still has open dependencies")
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "ConvertToIssue", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, convert.ToAPIIssue(issue))
+}
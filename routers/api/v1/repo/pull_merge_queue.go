@@ -0,0 +1,201 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+// ListMergeQueue lists the merge queues of a repository
+func ListMergeQueue(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/merge_queue repository repoListMergeQueue
+	// ---
+	// summary: List the pull requests in the merge queues of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: branch
+	//   in: query
+	//   description: only list the merge queue of this base branch
+	//   type: string
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MergeQueueEntryList"
+
+	entries, err := models.GetMergeQueue(ctx.Repo.Repository.ID, ctx.Query("branch"))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetMergeQueue", err)
+		return
+	}
+
+	apiEntries := make([]*api.MergeQueueEntry, 0, len(entries))
+	var position int64
+	for i, entry := range entries {
+		if i == 0 || entry.BaseBranch != entries[i-1].BaseBranch {
+			position = 0
+		}
+		position++
+
+		apiEntry, err := convert.ToMergeQueueEntry(entry, position, ctx.User)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "ToMergeQueueEntry", err)
+			return
+		}
+		apiEntries = append(apiEntries, apiEntry)
+	}
+
+	ctx.JSON(http.StatusOK, apiEntries)
+}
+
+func getPullMergeQueueEntry(ctx *context.APIContext) (*models.PullRequest, *models.MergeQueueEntry) {
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound("GetPullRequestByIndex", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPullRequestByIndex", err)
+		}
+		return nil, nil
+	}
+
+	entry, err := models.GetMergeQueueEntryByPullID(pr.ID)
+	if err != nil {
+		if models.IsErrMergeQueueEntryNotExist(err) {
+			ctx.NotFound("GetMergeQueueEntryByPullID", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetMergeQueueEntryByPullID", err)
+		}
+		return nil, nil
+	}
+	entry.Pull = pr
+	return pr, entry
+}
+
+// GetPullMergeQueueEntry gets the merge queue state of a pull request
+func GetPullMergeQueueEntry(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/merge_queue repository repoGetPullMergeQueueEntry
+	// ---
+	// summary: Get the merge queue state of a pull request
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MergeQueueEntry"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	_, entry := getPullMergeQueueEntry(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	position, err := entry.Position()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Position", err)
+		return
+	}
+
+	apiEntry, err := convert.ToMergeQueueEntry(entry, position, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToMergeQueueEntry", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, apiEntry)
+}
+
+// RemovePullFromMergeQueue removes a pull request from the merge queue
+func RemovePullFromMergeQueue(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/pulls/{index}/merge_queue repository repoRemovePullFromMergeQueue
+	// ---
+	// summary: Remove a pull request from the merge queue
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pr, _ := getPullMergeQueueEntry(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := pr.LoadIssue(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadIssue", err)
+		return
+	}
+	allowedMerge, err := pull_service.IsUserAllowedToMerge(pr, ctx.Repo.Permission, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "IsUserAllowedToMerge", err)
+		return
+	}
+	if !allowedMerge && ctx.User.ID != pr.Issue.PosterID {
+		ctx.Error(http.StatusForbidden, "RemovePullFromMergeQueue", "User not allowed to remove the pull request from the merge queue")
+		return
+	}
+
+	if err := pull_service.RemoveFromMergeQueue(pr, ctx.User); err != nil {
+		if models.IsErrMergeQueueEntryNotExist(err) {
+			ctx.NotFound()
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "RemoveFromMergeQueue", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
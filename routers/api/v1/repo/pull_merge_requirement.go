@@ -0,0 +1,214 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+func getPullRequestOfMergeRequirements(ctx *context.APIContext) *models.PullRequest {
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound("GetPullRequestByIndex", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPullRequestByIndex", err)
+		}
+		return nil
+	}
+	return pr
+}
+
+// ListPullMergeRequirements lists the merge requirements of a pull request
+func ListPullMergeRequirements(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/requirements repository repoListPullMergeRequirements
+	// ---
+	// summary: List the merge requirements external bots registered on a pull request
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullMergeRequirementList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pr := getPullRequestOfMergeRequirements(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	rs, err := models.GetPullMergeRequirements(pr.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPullMergeRequirements", err)
+		return
+	}
+
+	apiRequirements := make([]*api.PullMergeRequirement, 0, len(rs))
+	for _, r := range rs {
+		apiRequirement, err := convert.ToPullMergeRequirement(r, ctx.User)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "ToPullMergeRequirement", err)
+			return
+		}
+		apiRequirements = append(apiRequirements, apiRequirement)
+	}
+	ctx.JSON(http.StatusOK, apiRequirements)
+}
+
+// SetPullMergeRequirement registers a merge requirement on a pull request or changes its state
+func SetPullMergeRequirement(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/requirements repository repoSetPullMergeRequirement
+	// ---
+	// summary: Register a merge requirement on a pull request, or change the state of the requirement with the same name
+	// description: The pull request cannot be merged until all its merge requirements are satisfied.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/SetPullMergeRequirementOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullMergeRequirement"
+	//   "201":
+	//     "$ref": "#/responses/PullMergeRequirement"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.SetPullMergeRequirementOption)
+	pr := getPullRequestOfMergeRequirements(ctx)
+	if ctx.Written() {
+		return
+	}
+	if pr.HasMerged {
+		ctx.Error(http.StatusConflict, "HasMerged", "the pull request has already been merged")
+		return
+	}
+
+	r := &models.PullMergeRequirement{
+		Name:        form.Name,
+		IsSatisfied: form.Satisfied,
+		Description: form.Description,
+		TargetURL:   form.TargetURL,
+		CreatorID:   ctx.User.ID,
+		Creator:     ctx.User,
+	}
+	created, err := pull_service.SetMergeRequirement(pr, r)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "SetMergeRequirement", err)
+		return
+	}
+
+	apiRequirement, err := convert.ToPullMergeRequirement(r, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToPullMergeRequirement", err)
+		return
+	}
+	if created {
+		ctx.JSON(http.StatusCreated, apiRequirement)
+	} else {
+		ctx.JSON(http.StatusOK, apiRequirement)
+	}
+}
+
+// DeletePullMergeRequirement deletes a merge requirement of a pull request
+func DeletePullMergeRequirement(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/pulls/{index}/requirements/{id} repository repoDeletePullMergeRequirement
+	// ---
+	// summary: Delete a merge requirement of a pull request
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the merge requirement
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pr := getPullRequestOfMergeRequirements(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := pull_service.DeleteMergeRequirement(pr, ctx.ParamsInt64(":id")); err != nil {
+		if models.IsErrPullMergeRequirementNotExist(err) {
+			ctx.NotFound("DeleteMergeRequirement", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DeleteMergeRequirement", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
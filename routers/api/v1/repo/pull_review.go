@@ -13,7 +13,11 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/upload"
+	"code.gitea.io/gitea/modules/virusscan"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 	issue_service "code.gitea.io/gitea/services/issue"
@@ -198,6 +202,499 @@ func GetPullReviewComments(ctx *context.APIContext) {
 	ctx.JSON(http.StatusOK, apiComments)
 }
 
+// ApplyPullReviewSuggestion commits the change suggested by a review comment
+func ApplyPullReviewSuggestion(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/reviews/{id}/comments/{comment}/apply_suggestion repository repoApplyPullReviewSuggestion
+	// ---
+	// summary: Commit the change suggested by a review comment to the head branch of a pull request
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the review
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: comment
+	//   in: path
+	//   description: id of the review comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullReviewComment"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+
+	review, _, statusSet := prepareSingleReview(ctx)
+	if statusSet {
+		return
+	}
+
+	comment, statusSet := prepareReviewComment(ctx, review)
+	if statusSet {
+		return
+	}
+
+	if err := pull_service.ApplySuggestion(ctx.User, comment); err != nil {
+		if models.IsErrSuggestionNotApplicable(err) {
+			ctx.Error(http.StatusConflict, "ApplySuggestion", err)
+		} else if models.IsErrUserCannotCommit(err) {
+			ctx.Error(http.StatusForbidden, "ApplySuggestion", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "ApplySuggestion", err)
+		}
+		return
+	}
+
+	apiComment, err := convert.ToPullReviewComment(review, comment, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToPullReviewComment", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, apiComment)
+}
+
+// CreatePullReviewComment adds a code comment to a pending review
+func CreatePullReviewComment(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/reviews/{id}/comments repository repoCreatePullReviewComment
+	// ---
+	// summary: Add a comment to a pending review of a pull request
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the pending review
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CreatePullReviewComment"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/PullReviewComment"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	opts := web.GetForm(ctx).(*api.CreatePullReviewComment)
+	review, pr, statusSet := prepareSingleReview(ctx)
+	if statusSet {
+		return
+	}
+
+	// code comments are always added to the pending review of the doer
+	if review.Type != models.ReviewTypePending || review.ReviewerID != ctx.User.ID {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("comments can only be added to your own pending review"))
+		return
+	}
+
+	commitID := review.CommitID
+	if commitID == "" {
+		headCommitID, err := ctx.Repo.GitRepo.GetRefCommitID(pr.GetGitRefName())
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GitRepo: GetRefCommitID", err)
+			return
+		}
+		commitID = headCommitID
+	}
+
+	comment, statusSet := createPullReviewComment(ctx, pr, opts, commitID)
+	if statusSet {
+		return
+	}
+
+	apiComment, err := convert.ToPullReviewComment(review, comment, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToPullReviewComment", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, apiComment)
+}
+
+// DeletePullReviewComment removes a code comment from a pending review
+func DeletePullReviewComment(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/pulls/{index}/reviews/{id}/comments/{comment} repository repoDeletePullReviewComment
+	// ---
+	// summary: Delete a comment from a pending review of a pull request
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the pending review
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: comment
+	//   in: path
+	//   description: id of the review comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	review, _, statusSet := prepareSingleReview(ctx)
+	if statusSet {
+		return
+	}
+
+	if review.Type != models.ReviewTypePending || review.ReviewerID != ctx.User.ID {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("comments can only be removed from your own pending review"))
+		return
+	}
+
+	comment, statusSet := prepareReviewComment(ctx, review)
+	if statusSet {
+		return
+	}
+
+	if err := models.DeleteComment(comment); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteComment", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListPullReviewCommentAttachments lists the attachments of a review comment
+func ListPullReviewCommentAttachments(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/reviews/{id}/comments/{comment}/assets repository repoListPullReviewCommentAttachments
+	// ---
+	// summary: List the attachments of a review comment
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the review
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: comment
+	//   in: path
+	//   description: id of the review comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AttachmentList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	review, _, statusSet := prepareSingleReview(ctx)
+	if statusSet {
+		return
+	}
+
+	comment, statusSet := prepareReviewComment(ctx, review)
+	if statusSet {
+		return
+	}
+
+	if err := comment.LoadAttachments(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttachments", err)
+		return
+	}
+
+	apiAttachments := make([]*api.Attachment, 0, len(comment.Attachments))
+	for _, attach := range comment.Attachments {
+		apiAttachments = append(apiAttachments, convert.ToReleaseAttachment(attach))
+	}
+	ctx.JSON(http.StatusOK, apiAttachments)
+}
+
+// CreatePullReviewCommentAttachment attaches a file to a review comment
+func CreatePullReviewCommentAttachment(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/reviews/{id}/comments/{comment}/assets repository repoCreatePullReviewCommentAttachment
+	// ---
+	// summary: Attach a file to a review comment
+	// produces:
+	// - application/json
+	// consumes:
+	// - multipart/form-data
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the review
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: comment
+	//   in: path
+	//   description: id of the review comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: name
+	//   in: query
+	//   description: name of the attachment
+	//   type: string
+	//   required: false
+	// - name: attachment
+	//   in: formData
+	//   description: attachment to upload
+	//   type: file
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Attachment"
+	//   "400":
+	//     "$ref": "#/responses/error"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	if !setting.Attachment.Enabled {
+		ctx.NotFound("Attachment is not enabled")
+		return
+	}
+
+	review, _, statusSet := prepareSingleReview(ctx)
+	if statusSet {
+		return
+	}
+
+	comment, statusSet := prepareReviewComment(ctx, review)
+	if statusSet {
+		return
+	}
+
+	if comment.PosterID != ctx.User.ID && !ctx.User.IsAdmin {
+		ctx.Error(http.StatusForbidden, "", "only the poster of a comment can attach files to it")
+		return
+	}
+
+	file, header, err := ctx.Req.FormFile("attachment")
+	if err != nil {
+		ctx.Error(http.StatusBadRequest, "GetFile", err)
+		return
+	}
+	defer file.Close()
+
+	buf := make([]byte, 1024)
+	n, _ := file.Read(buf)
+	if n > 0 {
+		buf = buf[:n]
+	}
+
+	if err := upload.Verify(buf, header.Filename, setting.Attachment.AllowedTypes); err != nil {
+		ctx.Error(http.StatusBadRequest, "DetectContentType", err)
+		return
+	}
+
+	var filename = header.Filename
+	if query := ctx.Query("name"); query != "" {
+		filename = query
+	}
+
+	attach, err := virusscan.NewAttachment(ctx.Req.Context(), models.VirusDetectionKindAttachment, ctx.Repo.Repository.ID, &models.Attachment{
+		UploaderID: ctx.User.ID,
+		Name:       filename,
+		IssueID:    comment.IssueID,
+		CommentID:  comment.ID,
+	}, buf, file)
+	if err != nil {
+		if virusscan.IsErrVirusFound(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "NewAttachment", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, convert.ToReleaseAttachment(attach))
+}
+
+// DeletePullReviewCommentAttachment deletes an attachment of a review comment
+func DeletePullReviewCommentAttachment(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/pulls/{index}/reviews/{id}/comments/{comment}/assets/{attachment_id} repository repoDeletePullReviewCommentAttachment
+	// ---
+	// summary: Delete an attachment of a review comment
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the review
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: comment
+	//   in: path
+	//   description: id of the review comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: attachment_id
+	//   in: path
+	//   description: id of the attachment to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	review, _, statusSet := prepareSingleReview(ctx)
+	if statusSet {
+		return
+	}
+
+	comment, statusSet := prepareReviewComment(ctx, review)
+	if statusSet {
+		return
+	}
+
+	if comment.PosterID != ctx.User.ID && !ctx.User.IsAdmin {
+		ctx.Error(http.StatusForbidden, "", "only the poster of a comment can delete its attachments")
+		return
+	}
+
+	attach, err := models.GetAttachmentByID(ctx.ParamsInt64(":asset"))
+	if err != nil {
+		if models.IsErrAttachmentNotExist(err) {
+			ctx.NotFound("GetAttachmentByID", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetAttachmentByID", err)
+		}
+		return
+	}
+	if attach.CommentID != comment.ID {
+		ctx.NotFound("AttachmentNotInComment")
+		return
+	}
+
+	if err := models.DeleteAttachment(attach, true); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteAttachment", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
 // DeletePullReview delete a specific review from a pull request
 func DeletePullReview(ctx *context.APIContext) {
 	// swagger:operation DELETE /repos/{owner}/{repo}/pulls/{index}/reviews/{id} repository repoDeletePullReview
@@ -319,7 +816,7 @@ func CreatePullReview(ctx *context.APIContext) {
 
 	// if CommitID is empty, set it as lastCommitID
 	if opts.CommitID == "" {
-		gitRepo, err := git.OpenRepository(pr.Issue.Repo.RepoPath())
+		gitRepo, err := git.OpenRepositoryCtx(ctx.Req.Context(), pr.Issue.Repo.RepoPath())
 		if err != nil {
 			ctx.Error(http.StatusInternalServerError, "git.OpenRepository", err)
 			return
@@ -335,34 +832,53 @@ func CreatePullReview(ctx *context.APIContext) {
 		opts.CommitID = headCommitID
 	}
 
-	// create review comments
-	for _, c := range opts.Comments {
-		line := c.NewLineNum
-		if c.OldLineNum > 0 {
-			line = c.OldLineNum * -1
+	// a user can only have one pending review per pull request
+	existingReview, err := models.GetCurrentReview(ctx.User, pr.Issue)
+	if err != nil && !models.IsErrReviewNotExist(err) {
+		ctx.Error(http.StatusInternalServerError, "GetCurrentReview", err)
+		return
+	}
+	var pendingReview *models.Review
+	if reviewType == models.ReviewTypePending {
+		if existingReview != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("a pending review already exists, add comments to it or submit it"))
+			return
+		}
+		if pendingReview, err = models.CreateReview(models.CreateReviewOptions{
+			Type:     models.ReviewTypePending,
+			Issue:    pr.Issue,
+			Reviewer: ctx.User,
+			Content:  opts.Body,
+			CommitID: opts.CommitID,
+		}); err != nil {
+			ctx.Error(http.StatusInternalServerError, "CreateReview", err)
+			return
 		}
+	}
 
-		if _, err := pull_service.CreateCodeComment(
-			ctx.User,
-			ctx.Repo.GitRepo,
-			pr.Issue,
-			line,
-			c.Body,
-			c.Path,
-			true, // is review
-			0,    // no reply
-			opts.CommitID,
-		); err != nil {
-			ctx.Error(http.StatusInternalServerError, "CreateCodeComment", err)
+	// create review comments
+	created := make([]*models.Comment, 0, len(opts.Comments))
+	for i := range opts.Comments {
+		comment, statusSet := createPullReviewComment(ctx, pr, &opts.Comments[i], opts.CommitID)
+		if statusSet {
+			// do not leave part of the review behind
+			rollbackPullReviewComments(ctx.User, pr.Issue, existingReview, created)
 			return
 		}
+		created = append(created, comment)
 	}
 
-	// create review and associate all pending review comments
-	review, _, err := pull_service.SubmitReview(ctx.User, ctx.Repo.GitRepo, pr.Issue, reviewType, opts.Body, opts.CommitID)
-	if err != nil {
-		ctx.Error(http.StatusInternalServerError, "SubmitReview", err)
-		return
+	var review *models.Review
+	if reviewType == models.ReviewTypePending {
+		review = pendingReview
+	} else {
+		// create review and associate all pending review comments
+		review, _, err = pull_service.SubmitReview(ctx.Req.Context(), ctx.User, ctx.Repo.GitRepo, pr.Issue, reviewType, opts.Body, opts.CommitID)
+		if err != nil {
+			rollbackPullReviewComments(ctx.User, pr.Issue, existingReview, created)
+			ctx.Error(http.StatusInternalServerError, "SubmitReview", err)
+			return
+		}
 	}
 
 	// convert response
@@ -447,7 +963,7 @@ func SubmitPullReview(ctx *context.APIContext) {
 	}
 
 	// create review and associate all pending review comments
-	review, _, err = pull_service.SubmitReview(ctx.User, ctx.Repo.GitRepo, pr.Issue, reviewType, opts.Body, headCommitID)
+	review, _, err = pull_service.SubmitReview(ctx.Req.Context(), ctx.User, ctx.Repo.GitRepo, pr.Issue, reviewType, opts.Body, headCommitID)
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "SubmitReview", err)
 		return
@@ -493,8 +1009,8 @@ func preparePullReviewType(ctx *context.APIContext, pr *models.PullRequest, even
 		reviewType = models.ReviewTypePending
 	}
 
-	// reject reviews with empty body if not approve type
-	if reviewType != models.ReviewTypeApprove && len(strings.TrimSpace(body)) == 0 {
+	// reject reviews with empty body if not approve or pending type
+	if reviewType != models.ReviewTypeApprove && reviewType != models.ReviewTypePending && len(strings.TrimSpace(body)) == 0 {
 		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("review event %s need body", event))
 		return -1, true
 	}
@@ -544,6 +1060,86 @@ func prepareSingleReview(ctx *context.APIContext) (*models.Review, *models.PullR
 	return review, pr, false
 }
 
+// createPullReviewComment creates a code comment in the pending review of the doer.
+// It returns the comment and false or nil and true if an error happen
+func createPullReviewComment(ctx *context.APIContext, pr *models.PullRequest, c *api.CreatePullReviewComment, commitID string) (*models.Comment, bool) {
+	line, startLine := c.NewLineNum, c.NewStartLineNum
+	if c.OldLineNum > 0 {
+		line, startLine = c.OldLineNum*-1, c.OldStartLineNum*-1
+	}
+	body := c.Body
+	if c.Suggestion != nil {
+		if line <= 0 {
+			ctx.Error(http.StatusUnprocessableEntity, "", "suggestions can only be made for lines of the new file")
+			return nil, true
+		}
+		body = strings.TrimRight(body, "\n") + "\n\n" + models.SuggestionBlock(*c.Suggestion)
+	}
+
+	comment, err := pull_service.CreateCodeComment(ctx.Req.Context(),
+		ctx.User,
+		ctx.Repo.GitRepo,
+		pr.Issue,
+		line,
+		startLine,
+		body,
+		c.Path,
+		true, // is review
+		0,    // no reply
+		commitID,
+	)
+	if err != nil {
+		if models.IsErrInvalidCommentLineRange(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CreateCodeComment", err)
+		}
+		return nil, true
+	}
+	return comment, false
+}
+
+// rollbackPullReviewComments removes the comments created by a failed review creation.
+// If the pending review did not exist beforehand it is removed as well.
+func rollbackPullReviewComments(doer *models.User, issue *models.Issue, existingReview *models.Review, created []*models.Comment) {
+	if existingReview == nil {
+		review, err := models.GetCurrentReview(doer, issue)
+		if err != nil {
+			if !models.IsErrReviewNotExist(err) {
+				log.Error("GetCurrentReview: %v", err)
+			}
+			return
+		}
+		if err := models.DeleteReview(review); err != nil {
+			log.Error("DeleteReview[%d]: %v", review.ID, err)
+		}
+		return
+	}
+	for _, comment := range created {
+		if err := models.DeleteComment(comment); err != nil {
+			log.Error("DeleteComment[%d]: %v", comment.ID, err)
+		}
+	}
+}
+
+// prepareReviewComment return the code comment of the review given by the path and false or nil and true if an error happen
+func prepareReviewComment(ctx *context.APIContext, review *models.Review) (*models.Comment, bool) {
+	comment, err := models.GetCommentByID(ctx.ParamsInt64(":comment"))
+	if err != nil {
+		if models.IsErrCommentNotExist(err) {
+			ctx.NotFound("GetCommentByID", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCommentByID", err)
+		}
+		return nil, true
+	}
+	if comment.ReviewID != review.ID || comment.Type != models.CommentTypeCode {
+		ctx.NotFound("CommentNotInReview")
+		return nil, true
+	}
+	return comment, false
+}
+
 // CreateReviewRequests create review requests to an pull request
 func CreateReviewRequests(ctx *context.APIContext) {
 	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/requested_reviewers repository repoCreatePullReviewRequests
@@ -685,7 +1281,7 @@ func apiReviewRequest(ctx *context.APIContext, opts api.PullReviewRequestOptions
 	}
 
 	for _, reviewer := range reviewers {
-		comment, err := issue_service.ReviewRequest(pr.Issue, ctx.User, reviewer, isAdd)
+		comment, err := issue_service.ReviewRequest(ctx.Req.Context(), pr.Issue, ctx.User, reviewer, isAdd)
 		if err != nil {
 			ctx.Error(http.StatusInternalServerError, "ReviewRequest", err)
 			return
@@ -729,7 +1325,7 @@ func apiReviewRequest(ctx *context.APIContext, opts api.PullReviewRequestOptions
 		}
 
 		for _, teamReviewer := range teamReviewers {
-			comment, err := issue_service.TeamReviewRequest(pr.Issue, ctx.User, teamReviewer, isAdd)
+			comment, err := issue_service.TeamReviewRequest(ctx.Req.Context(), pr.Issue, ctx.User, teamReviewer, isAdd)
 			if err != nil {
 				ctx.ServerError("TeamReviewRequest", err)
 				return
@@ -864,7 +1460,7 @@ func dismissReview(ctx *context.APIContext, msg string, isDismiss bool) {
 		return
 	}
 
-	_, err := pull_service.DismissReview(review.ID, msg, ctx.User, isDismiss)
+	_, err := pull_service.DismissReview(ctx.Req.Context(), review.ID, msg, ctx.User, isDismiss)
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "pull_service.DismissReview", err)
 		return
@@ -14,6 +14,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/upload"
+	"code.gitea.io/gitea/modules/virusscan"
 	"code.gitea.io/gitea/modules/web"
 )
 
@@ -153,6 +154,8 @@ func CreateReleaseAttachment(ctx *context.APIContext) {
 	//     "$ref": "#/responses/Attachment"
 	//   "400":
 	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	// Check if attachments are enabled
 	if !setting.Attachment.Enabled {
@@ -195,12 +198,16 @@ func CreateReleaseAttachment(ctx *context.APIContext) {
 	}
 
 	// Create a new attachment and save the file
-	attach, err := models.NewAttachment(&models.Attachment{
+	attach, err := virusscan.NewAttachment(ctx.Req.Context(), models.VirusDetectionKindRelease, ctx.Repo.Repository.ID, &models.Attachment{
 		UploaderID: ctx.User.ID,
 		Name:       filename,
 		ReleaseID:  release.ID,
 	}, buf, file)
 	if err != nil {
+		if virusscan.IsErrVirusFound(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+			return
+		}
 		ctx.Error(http.StatusInternalServerError, "NewAttachment", err)
 		return
 	}
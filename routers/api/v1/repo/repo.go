@@ -15,6 +15,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/issueform"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
@@ -243,7 +244,7 @@ func CreateUserRepo(ctx *context.APIContext, owner *models.User, opt api.CreateR
 	if opt.AutoInit && opt.Readme == "" {
 		opt.Readme = "Default"
 	}
-	repo, err := repo_service.CreateRepository(ctx.User, owner, models.CreateRepoOptions{
+	repo, err := repo_service.CreateRepository(ctx.Req.Context(), ctx.User, owner, models.CreateRepoOptions{
 		Name:          opt.Name,
 		Description:   opt.Description,
 		IssueLabels:   opt.IssueLabels,
@@ -524,7 +525,7 @@ func updateBasicProperties(ctx *context.APIContext, opts api.EditRepoOption) err
 	}
 	// Check if repository name has been changed and not just a case change
 	if repo.LowerName != strings.ToLower(newRepoName) {
-		if err := repo_service.ChangeRepositoryName(ctx.User, repo, newRepoName); err != nil {
+		if err := repo_service.ChangeRepositoryName(ctx.Req.Context(), ctx.User, repo, newRepoName); err != nil {
 			switch {
 			case models.IsErrRepoAlreadyExist(err):
 				ctx.Error(http.StatusUnprocessableEntity, fmt.Sprintf("repo name is already taken [name: %s]", newRepoName), err)
@@ -580,7 +581,7 @@ func updateBasicProperties(ctx *context.APIContext, opts api.EditRepoOption) err
 
 	if ctx.Repo.GitRepo == nil {
 		var err error
-		ctx.Repo.GitRepo, err = git.OpenRepository(ctx.Repo.Repository.RepoPath())
+		ctx.Repo.GitRepo, err = git.OpenRepositoryCtx(ctx.Req.Context(), ctx.Repo.Repository.RepoPath())
 		if err != nil {
 			ctx.Error(http.StatusInternalServerError, "Unable to OpenRepository", err)
 			return err
@@ -647,10 +648,17 @@ func updateRepoUnits(ctx *context.APIContext, opts api.EditRepoOption) error {
 			var config *models.IssuesConfig
 
 			if opts.InternalTracker != nil {
+				if opts.InternalTracker.EnableDueDateReminders && (opts.InternalTracker.DueDateReminderDays < 1 || opts.InternalTracker.DueDateReminderDays > models.MaxDueDateReminderDays) {
+					err := fmt.Errorf("due date reminder days must be between 1 and %d", models.MaxDueDateReminderDays)
+					ctx.Error(http.StatusUnprocessableEntity, "Invalid due date reminder days", err)
+					return err
+				}
 				config = &models.IssuesConfig{
 					EnableTimetracker:                opts.InternalTracker.EnableTimeTracker,
 					AllowOnlyContributorsToTrackTime: opts.InternalTracker.AllowOnlyContributorsToTrackTime,
 					EnableDependencies:               opts.InternalTracker.EnableIssueDependencies,
+					EnableDueDateReminders:           opts.InternalTracker.EnableDueDateReminders,
+					DueDateReminderDays:              opts.InternalTracker.DueDateReminderDays,
 				}
 			} else if unit, err := repo.GetUnit(models.UnitTypeIssues); err != nil {
 				// Unit type doesn't exist so we make a new config file with default values
@@ -751,12 +759,46 @@ func updateRepoUnits(ctx *context.APIContext, opts api.EditRepoOption) error {
 			if opts.AllowSquash != nil {
 				config.AllowSquash = *opts.AllowSquash
 			}
+			if opts.AllowFastForwardOnly != nil {
+				config.AllowFastForwardOnly = *opts.AllowFastForwardOnly
+			}
 			if opts.AllowManualMerge != nil {
 				config.AllowManualMerge = *opts.AllowManualMerge
 			}
 			if opts.AutodetectManualMerge != nil {
 				config.AutodetectManualMerge = *opts.AutodetectManualMerge
 			}
+			if opts.AutoAssignReviewers != nil {
+				strategy := models.ReviewerAssignmentStrategy(opts.AutoAssignReviewers.Strategy)
+				if strategy == "" {
+					strategy = models.ReviewerAssignmentRoundRobin
+				}
+				if !strategy.IsValid() {
+					err := fmt.Errorf("unknown reviewer assignment strategy: %s", opts.AutoAssignReviewers.Strategy)
+					ctx.Error(http.StatusUnprocessableEntity, "Invalid reviewer assignment strategy", err)
+					return err
+				}
+				if opts.AutoAssignReviewers.Count < 0 {
+					err := fmt.Errorf("number of reviewers must not be negative")
+					ctx.Error(http.StatusUnprocessableEntity, "Invalid number of reviewers", err)
+					return err
+				}
+				if opts.AutoAssignReviewers.Count > 0 {
+					team, err := models.GetTeamByID(opts.AutoAssignReviewers.TeamID)
+					if err != nil && !models.IsErrTeamNotExist(err) {
+						ctx.Error(http.StatusInternalServerError, "GetTeamByID", err)
+						return err
+					}
+					if err != nil || team.OrgID != owner.ID {
+						err = fmt.Errorf("team %d does not exist in %s", opts.AutoAssignReviewers.TeamID, owner.Name)
+						ctx.Error(http.StatusUnprocessableEntity, "Invalid team", err)
+						return err
+					}
+				}
+				config.AutoAssignReviewersTeamID = opts.AutoAssignReviewers.TeamID
+				config.AutoAssignReviewersCount = opts.AutoAssignReviewers.Count
+				config.AutoAssignReviewersStrategy = strategy
+			}
 
 			units = append(units, models.RepoUnit{
 				RepoID: repo.ID,
@@ -885,7 +927,7 @@ func Delete(ctx *context.APIContext) {
 		return
 	}
 
-	if err := repo_service.DeleteRepository(ctx.User, repo); err != nil {
+	if err := repo_service.DeleteRepository(ctx.Req.Context(), ctx.User, repo); err != nil {
 		ctx.Error(http.StatusInternalServerError, "DeleteRepository", err)
 		return
 	}
@@ -918,3 +960,74 @@ func GetIssueTemplates(ctx *context.APIContext) {
 
 	ctx.JSON(http.StatusOK, ctx.IssueTemplatesFromDefaultBranch())
 }
+
+// GetIssueConfig returns the configuration of the issue template chooser of a repository
+func GetIssueConfig(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issue_config repository repoGetIssueConfig
+	// ---
+	// summary: Get the configuration of the issue template chooser of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueConfig"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	config, err := ctx.IssueConfigFromDefaultBranch()
+	if err != nil {
+		if issueform.IsErrInvalidConfig(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "IssueConfigFromDefaultBranch", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "IssueConfigFromDefaultBranch", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, config)
+}
+
+// GetWorkspace returns the metadata an IDE needs to open a repository
+func GetWorkspace(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/workspace repository repoGetWorkspace
+	// ---
+	// summary: Get the metadata an IDE needs to open a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoWorkspace"
+
+	devcontainerPath, err := ctx.Repo.GetDevcontainerPath()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDevcontainerPath", err)
+		return
+	}
+	gitpodConfigPath, err := ctx.Repo.GetGitpodConfigPath()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetGitpodConfigPath", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToRepoWorkspace(ctx.Repo.Repository, devcontainerPath, gitpodConfigPath))
+}
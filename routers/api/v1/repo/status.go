@@ -15,6 +15,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	pull_service "code.gitea.io/gitea/services/pull"
 )
 
 // NewCommitStatus creates a new CommitStatus
@@ -62,10 +63,12 @@ func NewCommitStatus(ctx *context.APIContext) {
 		Description: form.Description,
 		Context:     form.Context,
 	}
-	if err := repofiles.CreateCommitStatus(ctx.Repo.Repository, ctx.User, sha, status); err != nil {
+	if err := repofiles.CreateCommitStatus(ctx.Req.Context(), ctx.Repo.Repository, ctx.User, sha, status); err != nil {
 		ctx.Error(http.StatusInternalServerError, "CreateCommitStatus", err)
 		return
 	}
+	pull_service.CheckMergeQueueForCommit(ctx.Repo.Repository.ID, status.SHA)
+	pull_service.AutoMergeCheckByRepo(ctx.Repo.Repository.ID)
 
 	ctx.JSON(http.StatusCreated, convert.ToCommitStatus(status))
 }
@@ -0,0 +1,16 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package swagger
+
+import (
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ConfigValueList
+// swagger:response ConfigValueList
+type swaggerResponseConfigValueList struct {
+	// in:body
+	Body []api.ConfigValue `json:"body"`
+}
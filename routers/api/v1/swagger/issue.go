@@ -36,6 +36,13 @@ type swaggerResponseCommentList struct {
 	Body []api.Comment `json:"body"`
 }
 
+// TimelineList
+// swagger:response TimelineList
+type swaggerResponseTimelineList struct {
+	// in:body
+	Body []api.TimelineComment `json:"body"`
+}
+
 // Label
 // swagger:response Label
 type swaggerResponseLabel struct {
@@ -50,6 +57,27 @@ type swaggerResponseLabelList struct {
 	Body []api.Label `json:"body"`
 }
 
+// IssueType
+// swagger:response IssueType
+type swaggerResponseIssueType struct {
+	// in:body
+	Body api.IssueType `json:"body"`
+}
+
+// IssueTypeList
+// swagger:response IssueTypeList
+type swaggerResponseIssueTypeList struct {
+	// in:body
+	Body []api.IssueType `json:"body"`
+}
+
+// SubIssueNode
+// swagger:response SubIssueNode
+type swaggerResponseSubIssueNode struct {
+	// in:body
+	Body api.SubIssueNode `json:"body"`
+}
+
 // Milestone
 // swagger:response Milestone
 type swaggerResponseMilestone struct {
@@ -64,6 +92,13 @@ type swaggerResponseMilestoneList struct {
 	Body []api.Milestone `json:"body"`
 }
 
+// MilestoneStats
+// swagger:response MilestoneStats
+type swaggerResponseMilestoneStats struct {
+	// in:body
+	Body api.MilestoneStats `json:"body"`
+}
+
 // TrackedTime
 // swagger:response TrackedTime
 type swaggerResponseTrackedTime struct {
@@ -78,6 +113,13 @@ type swaggerResponseTrackedTimeList struct {
 	Body []api.TrackedTime `json:"body"`
 }
 
+// TrackedTimeSumList
+// swagger:response TrackedTimeSumList
+type swaggerResponseTrackedTimeSumList struct {
+	// in:body
+	Body []api.TrackedTimeSum `json:"body"`
+}
+
 // IssueDeadline
 // swagger:response IssueDeadline
 type swaggerIssueDeadline struct {
@@ -92,6 +134,13 @@ type swaggerIssueTemplates struct {
 	Body []api.IssueTemplate `json:"body"`
 }
 
+// IssueConfig
+// swagger:response IssueConfig
+type swaggerIssueConfig struct {
+	// in:body
+	Body api.IssueConfig `json:"body"`
+}
+
 // StopWatch
 // swagger:response StopWatch
 type swaggerResponseStopWatch struct {
@@ -119,3 +168,24 @@ type swaggerReactionList struct {
 	// in:body
 	Body []api.Reaction `json:"body"`
 }
+
+// ReactionCountList
+// swagger:response ReactionCountList
+type swaggerReactionCountList struct {
+	// in:body
+	Body []api.ReactionCount `json:"body"`
+}
+
+// ContentHistoryList
+// swagger:response ContentHistoryList
+type swaggerContentHistoryList struct {
+	// in:body
+	Body []api.ContentHistory `json:"body"`
+}
+
+// IssueInsights
+// swagger:response IssueInsights
+type swaggerIssueInsights struct {
+	// in:body
+	Body api.IssueInsights `json:"body"`
+}
@@ -21,3 +21,45 @@ type swaggerResponseStringSlice struct {
 	// in:body
 	Body []string `json:"body"`
 }
+
+// StorageStatistic
+// swagger:response StorageStatistic
+type swaggerResponseStorageStatistic struct {
+	// in:body
+	Body api.StorageStatistic `json:"body"`
+}
+
+// StorageStatisticList
+// swagger:response StorageStatisticList
+type swaggerResponseStorageStatisticList struct {
+	// in:body
+	Body []api.StorageStatistic `json:"body"`
+}
+
+// InstanceStats
+// swagger:response InstanceStats
+type swaggerResponseInstanceStats struct {
+	// in:body
+	Body api.InstanceStats `json:"body"`
+}
+
+// PolicyDocumentList
+// swagger:response PolicyDocumentList
+type swaggerResponsePolicyDocumentList struct {
+	// in:body
+	Body []api.PolicyDocument `json:"body"`
+}
+
+// PolicyConsentList
+// swagger:response PolicyConsentList
+type swaggerResponsePolicyConsentList struct {
+	// in:body
+	Body []api.PolicyConsent `json:"body"`
+}
+
+// PolicyConsentStateList
+// swagger:response PolicyConsentStateList
+type swaggerResponsePolicyConsentStateList struct {
+	// in:body
+	Body []api.PolicyConsentState `json:"body"`
+}
@@ -54,6 +54,14 @@ type swaggerParameterBodies struct {
 	// in:body
 	EditLabelOption api.EditLabelOption
 
+	// in:body
+	CreateIssueTypeOption api.CreateIssueTypeOption
+	// in:body
+	EditIssueTypeOption api.EditIssueTypeOption
+
+	// in:body
+	AddSubIssueOption api.AddSubIssueOption
+
 	// in:body
 	MarkdownOption api.MarkdownOption
 
@@ -74,6 +82,13 @@ type swaggerParameterBodies struct {
 	// in:body
 	MergePullRequestOption auth.MergePullRequestForm
 
+	// in:body
+	EditMirrorOption api.EditMirrorOption
+	// in:body
+	CreatePushMirrorOption api.CreatePushMirrorOption
+	// in:body
+	EditPushMirrorOption api.EditPushMirrorOption
+
 	// in:body
 	CreateReleaseOption api.CreateReleaseOption
 	// in:body
@@ -158,4 +173,36 @@ type swaggerParameterBodies struct {
 
 	// in:body
 	PullReviewRequestOptions api.PullReviewRequestOptions
+
+	// in:body
+	EditLogModuleLevelsOption api.EditLogModuleLevelsOption
+
+	// in:body
+	EnableMaintenanceModeOption api.EnableMaintenanceModeOption
+
+	// in:body
+	EditUserProfileFieldsOption api.EditUserProfileFieldsOption
+
+	// in:body
+	MigrateLoginSourceUsersOption api.MigrateLoginSourceUsersOption
+
+	// in:body
+	CreateUserFilterOption api.CreateUserFilterOption
+
+	// in:body
+	IssueSubscribersOption api.IssueSubscribersOption
+
+	// in:body
+	SetPullMergeRequirementOption api.SetPullMergeRequirementOption
+
+	// in:body
+	CreateProjectOption api.CreateProjectOption
+	// in:body
+	EditProjectOption api.EditProjectOption
+	// in:body
+	CreateProjectBoardOption api.CreateProjectBoardOption
+	// in:body
+	EditProjectBoardOption api.EditProjectBoardOption
+	// in:body
+	MoveProjectIssueOption api.MoveProjectIssueOption
 }
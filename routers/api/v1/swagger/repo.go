@@ -85,6 +85,55 @@ type swaggerResponseReferenceList struct {
 	Body []api.Reference `json:"body"`
 }
 
+// GitStats
+// swagger:response GitStats
+type swaggerResponseGitStats struct {
+	// in:body
+	Body api.GitStats `json:"body"`
+}
+
+// Artifact
+// swagger:response Artifact
+type swaggerResponseArtifact struct {
+	// in:body
+	Body api.Artifact `json:"body"`
+}
+
+// ArtifactList
+// swagger:response ArtifactList
+type swaggerResponseArtifactList struct {
+	// in:body
+	Body []api.Artifact `json:"body"`
+}
+
+// RepoEventList
+// swagger:response RepoEventList
+type swaggerResponseRepoEventList struct {
+	// in:body
+	Body []api.RepoEvent `json:"body"`
+}
+
+// Mirror
+// swagger:response Mirror
+type swaggerResponseMirror struct {
+	// in:body
+	Body api.Mirror `json:"body"`
+}
+
+// PushMirror
+// swagger:response PushMirror
+type swaggerResponsePushMirror struct {
+	// in:body
+	Body api.PushMirror `json:"body"`
+}
+
+// PushMirrorList
+// swagger:response PushMirrorList
+type swaggerResponsePushMirrorList struct {
+	// in:body
+	Body []api.PushMirror `json:"body"`
+}
+
 // Hook
 // swagger:response Hook
 type swaggerResponseHook struct {
@@ -289,6 +338,20 @@ type swaggerFileDeleteResponse struct {
 	Body api.FileDeleteResponse `json:"body"`
 }
 
+// CodeSearchResults
+// swagger:response CodeSearchResults
+type swaggerCodeSearchResults struct {
+	// in: body
+	Body api.CodeSearchResults `json:"body"`
+}
+
+// RepoWorkspace
+// swagger:response RepoWorkspace
+type swaggerRepoWorkspace struct {
+	// in: body
+	Body api.RepoWorkspace `json:"body"`
+}
+
 // TopicListResponse
 // swagger:response TopicListResponse
 type swaggerTopicListResponse struct {
@@ -316,3 +379,31 @@ type swaggerCombinedStatus struct {
 	// in: body
 	Body api.CombinedStatus `json:"body"`
 }
+
+// MergeQueueEntry
+// swagger:response MergeQueueEntry
+type swaggerResponseMergeQueueEntry struct {
+	// in:body
+	Body api.MergeQueueEntry `json:"body"`
+}
+
+// PullMergeRequirement
+// swagger:response PullMergeRequirement
+type swaggerResponsePullMergeRequirement struct {
+	// in:body
+	Body api.PullMergeRequirement `json:"body"`
+}
+
+// PullMergeRequirementList
+// swagger:response PullMergeRequirementList
+type swaggerResponsePullMergeRequirementList struct {
+	// in:body
+	Body []api.PullMergeRequirement `json:"body"`
+}
+
+// MergeQueueEntryList
+// swagger:response MergeQueueEntryList
+type swaggerResponseMergeQueueEntryList struct {
+	// in:body
+	Body []api.MergeQueueEntry `json:"body"`
+}
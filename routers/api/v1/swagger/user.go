@@ -30,6 +30,13 @@ type swaggerResponseEmailList struct {
 	Body []api.Email `json:"body"`
 }
 
+// UserProfileFieldList
+// swagger:response UserProfileFieldList
+type swaggerResponseUserProfileFieldList struct {
+	// in:body
+	Body []api.UserProfileField `json:"body"`
+}
+
 // swagger:model EditUserOption
 type swaggerModelEditUserOption struct {
 	// in:body
@@ -42,3 +49,24 @@ type swaggerResponseUserHeatmapData struct {
 	// in:body
 	Body []models.UserHeatmapData `json:"body"`
 }
+
+// LoginSourceMigration
+// swagger:response LoginSourceMigration
+type swaggerResponseLoginSourceMigration struct {
+	// in:body
+	Body api.LoginSourceMigration `json:"body"`
+}
+
+// UserFilter
+// swagger:response UserFilter
+type swaggerResponseUserFilter struct {
+	// in:body
+	Body api.UserFilter `json:"body"`
+}
+
+// UserFilterList
+// swagger:response UserFilterList
+type swaggerResponseUserFilterList struct {
+	// in:body
+	Body []api.UserFilter `json:"body"`
+}
@@ -0,0 +1,221 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListMyFilters api for listing the saved filters of the authenticated user
+func ListMyFilters(ctx *context.APIContext) {
+	// swagger:operation GET /user/filters user userListFilters
+	// ---
+	// summary: List the saved issue and pull request filters of the authenticated user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: type
+	//   in: query
+	//   description: filter by the type of the filtered lists
+	//   type: string
+	//   enum: [issues, pulls]
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/UserFilterList"
+	opts := &models.FindUserFiltersOptions{
+		ListOptions: utils.GetListOptions(ctx),
+		UserID:      ctx.User.ID,
+		RepoID:      models.UserFilterAnyRepo,
+	}
+	switch ctx.Query("type") {
+	case "issues":
+		opts.IsPull = util.OptionalBoolFalse
+	case "pulls":
+		opts.IsPull = util.OptionalBoolTrue
+	}
+
+	filters, count, err := models.FindUserFilters(opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindUserFilters", err)
+		return
+	}
+	// the filters of the repositories the user can no longer read are kept, but not shown
+	if filters, err = filters.Readable(ctx.User); err != nil {
+		ctx.Error(http.StatusInternalServerError, "Readable", err)
+		return
+	}
+
+	apiFilters := make([]*api.UserFilter, len(filters))
+	for i := range filters {
+		apiFilters[i] = convert.ToUserFilter(filters[i])
+	}
+
+	ctx.SetLinkHeader(int(count), opts.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, Link")
+	ctx.JSON(http.StatusOK, &apiFilters)
+}
+
+// getMyFilter returns a filter of the authenticated user, writing a 404 error if it does not exist or if its list can
+// no longer be read
+func getMyFilter(ctx *context.APIContext) *models.UserFilter {
+	filter, err := models.GetUserFilterByID(ctx.User.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrUserFilterNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetUserFilterByID", err)
+		}
+		return nil
+	}
+	if err := filter.LoadRepo(); err != nil && !models.IsErrRepoNotExist(err) {
+		ctx.Error(http.StatusInternalServerError, "LoadRepo", err)
+		return nil
+	}
+	if readable, err := filter.IsReadableBy(ctx.User); err != nil {
+		ctx.Error(http.StatusInternalServerError, "IsReadableBy", err)
+		return nil
+	} else if !readable {
+		ctx.NotFound()
+		return nil
+	}
+	return filter
+}
+
+// GetMyFilter api for getting a saved filter of the authenticated user
+func GetMyFilter(ctx *context.APIContext) {
+	// swagger:operation GET /user/filters/{id} user userGetFilter
+	// ---
+	// summary: Get a saved issue or pull request filter of the authenticated user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the filter to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/UserFilter"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	filter := getMyFilter(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToUserFilter(filter))
+}
+
+// CreateMyFilter api for saving a filter of the authenticated user
+func CreateMyFilter(ctx *context.APIContext) {
+	// swagger:operation POST /user/filters user userCreateFilter
+	// ---
+	// summary: Save an issue or pull request filter for the authenticated user
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateUserFilterOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/UserFilter"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	form := web.GetForm(ctx).(*api.CreateUserFilterOption)
+
+	filter := &models.UserFilter{
+		UserID:      ctx.User.ID,
+		RepoID:      form.RepositoryID,
+		IsPull:      form.Type == "pulls",
+		Name:        form.Name,
+		ViewType:    form.ViewType,
+		SortType:    form.Sort,
+		State:       form.State,
+		Keyword:     form.Keyword,
+		Labels:      models.JoinUserFilterIDs(form.Labels),
+		MilestoneID: form.Milestone,
+		AssigneeID:  form.Assignee,
+		Repos:       models.JoinUserFilterIDs(form.Repos),
+	}
+	if err := filter.LoadRepo(); err != nil && !models.IsErrRepoNotExist(err) {
+		ctx.Error(http.StatusInternalServerError, "LoadRepo", err)
+		return
+	}
+	if readable, err := filter.IsReadableBy(ctx.User); err != nil {
+		ctx.Error(http.StatusInternalServerError, "IsReadableBy", err)
+		return
+	} else if !readable {
+		ctx.Error(http.StatusUnprocessableEntity, "", "repository does not exist")
+		return
+	}
+
+	if err := models.CreateUserFilter(filter); err != nil {
+		switch {
+		case models.IsErrUserFilterAlreadyExist(err):
+			ctx.Error(http.StatusConflict, "", err)
+		case models.IsErrUserFiltersLimitReached(err):
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "CreateUserFilter", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToUserFilter(filter))
+}
+
+// DeleteMyFilter api for deleting a saved filter of the authenticated user
+func DeleteMyFilter(ctx *context.APIContext) {
+	// swagger:operation DELETE /user/filters/{id} user userDeleteFilter
+	// ---
+	// summary: Delete a saved issue or pull request filter of the authenticated user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the filter to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	if err := models.DeleteUserFilter(ctx.User.ID, ctx.ParamsInt64(":id")); err != nil {
+		if models.IsErrUserFilterNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DeleteUserFilter", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
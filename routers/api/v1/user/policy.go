@@ -0,0 +1,31 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+)
+
+// ListMyPolicyConsents api for getting the consents of the authenticated user to the current policy documents
+func ListMyPolicyConsents(ctx *context.APIContext) {
+	// swagger:operation GET /user/policies user userListPolicyConsents
+	// ---
+	// summary: Get the consents of the authenticated user to the current terms of service and privacy policy
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PolicyConsentStateList"
+	states, err := models.GetPolicyConsentStates(ctx.User.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPolicyConsentStates", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToPolicyConsentStates(states))
+}
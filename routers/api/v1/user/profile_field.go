@@ -0,0 +1,127 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+func toUserProfileFields(fields []*models.UserProfileField) []*api.UserProfileField {
+	apiFields := make([]*api.UserProfileField, len(fields))
+	for i := range fields {
+		apiFields[i] = convert.ToUserProfileField(fields[i])
+	}
+	return apiFields
+}
+
+// ListProfileFields list the filled in profile fields of a user
+func ListProfileFields(ctx *context.APIContext) {
+	// swagger:operation GET /users/{username}/profile_fields user userListProfileFields
+	// ---
+	// summary: List the profile fields a user filled in which are visible to the requester
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of user
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/UserProfileFieldList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	u := GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	fields, err := models.GetVisibleProfileFields(u, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetVisibleProfileFields", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, toUserProfileFields(fields))
+}
+
+// ListMyProfileFields list all profile fields with the values of the authenticated user
+func ListMyProfileFields(ctx *context.APIContext) {
+	// swagger:operation GET /user/profile_fields user userCurrentListProfileFields
+	// ---
+	// summary: List all profile fields with the values of the authenticated user
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/UserProfileFieldList"
+
+	fields, err := models.GetUserProfileFields(ctx.User.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUserProfileFields", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, toUserProfileFields(fields))
+}
+
+// EditMyProfileFields set the values of the profile fields of the authenticated user
+func EditMyProfileFields(ctx *context.APIContext) {
+	// swagger:operation PATCH /user/profile_fields user userCurrentEditProfileFields
+	// ---
+	// summary: Set the values of the profile fields of the authenticated user
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditUserProfileFieldsOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/UserProfileFieldList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditUserProfileFieldsOption)
+
+	fields, err := models.GetProfileFields()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetProfileFields", err)
+		return
+	}
+	fieldIDs := make(map[string]int64, len(fields))
+	for _, field := range fields {
+		fieldIDs[field.Name] = field.ID
+	}
+	values := make(map[int64]string, len(form.Values))
+	for name, value := range form.Values {
+		id, ok := fieldIDs[name]
+		if !ok {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("profile field %q does not exist", name))
+			return
+		}
+		values[id] = value
+	}
+
+	if err := models.UpdateUserProfileFields(ctx.User.ID, values); err != nil {
+		if models.IsErrInvalidProfileFieldValue(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "UpdateUserProfileFields", err)
+		return
+	}
+	ListMyProfileFields(ctx)
+}
@@ -15,6 +15,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/routers/utils"
+	repo_service "code.gitea.io/gitea/services/repository"
 	"code.gitea.io/gitea/services/webhook"
 	jsoniter "github.com/json-iterator/go"
 )
@@ -83,6 +84,7 @@ func AddRepoHook(ctx *context.APIContext, form *api.CreateHookOption) {
 	repo := ctx.Repo
 	hook, ok := addHook(ctx, form, 0, repo.Repository.ID)
 	if ok {
+		repo_service.RecordWebhookEvent(ctx.User, repo.Repository, models.RepoEventWebhookCreate, hook)
 		ctx.JSON(http.StatusCreated, convert.ToHook(repo.RepoLink, hook))
 	}
 }
@@ -202,6 +204,7 @@ func EditRepoHook(ctx *context.APIContext, form *api.EditHookOption, hookID int6
 	if err != nil {
 		return
 	}
+	repo_service.RecordWebhookEvent(ctx.User, repo.Repository, models.RepoEventWebhookUpdate, updated)
 	ctx.JSON(http.StatusOK, convert.ToHook(repo.RepoLink, updated))
 }
 
@@ -0,0 +1,102 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package events
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/eventsource"
+)
+
+// maxSubscribedTopics is the number of topics which can be subscribed to with one request
+const maxSubscribedTopics = 20
+
+// canReadTopic returns whether a user may read what a topic is about
+func canReadTopic(user *models.User, topic eventsource.Topic) (bool, error) {
+	var repo *models.Repository
+	var issue *models.Issue
+	var unitTypes []models.UnitType
+	switch topic.Kind {
+	case eventsource.TopicIssue, eventsource.TopicPull:
+		var err error
+		issue, err = models.GetIssueByID(topic.ID)
+		if models.IsErrIssueNotExist(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		if topic.Kind == eventsource.TopicPull && !issue.IsPull {
+			return false, nil
+		}
+		if err := issue.LoadRepo(); err != nil {
+			return false, err
+		}
+		repo = issue.Repo
+		unitTypes = []models.UnitType{models.UnitTypeIssues}
+		if issue.IsPull {
+			unitTypes = []models.UnitType{models.UnitTypePullRequests}
+		}
+	case eventsource.TopicCommitStatus:
+		var err error
+		repo, err = models.GetRepositoryByID(topic.ID)
+		if models.IsErrRepoNotExist(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		// The statuses are shown with the commits and in the merge boxes of the pull requests
+		unitTypes = []models.UnitType{models.UnitTypeCode, models.UnitTypePullRequests}
+	default:
+		return false, nil
+	}
+
+	perm, err := models.GetUserRepoPermission(repo, user)
+	if err != nil {
+		return false, err
+	}
+	if perm.CanReadAny(unitTypes...) {
+		return true, nil
+	}
+	// the users invited to an issue may read it whatever access they have to the repository
+	if issue == nil || !repo.UnitEnabled(unitTypes[0]) {
+		return false, nil
+	}
+	return models.IsIssueCollaborator(issue.ID, user.ID)
+}
+
+// Subscribe subscribes the signed in user to the changes of the requested topics it may read. The changes
+// are sent to the event streams of the user as live update events. The subscriptions expire unless they are
+// renewed by subscribing again, the topics which were subscribed to are returned.
+func Subscribe(ctx *context.Context) {
+	topics := ctx.QueryStrings("topic")
+	if len(topics) > maxSubscribedTopics {
+		ctx.Error(http.StatusBadRequest, "too many topics")
+		return
+	}
+
+	subscribed := make([]string, 0, len(topics))
+	for _, s := range topics {
+		topic, ok := eventsource.ParseTopic(s)
+		if !ok {
+			continue
+		}
+		canRead, err := canReadTopic(ctx.User, topic)
+		if err != nil {
+			ctx.ServerError("canReadTopic", err)
+			return
+		}
+		if canRead {
+			eventsource.GetManager().Subscribe(ctx.User.ID, topic)
+			subscribed = append(subscribed, topic.String())
+		}
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"topics":  subscribed,
+		"timeout": int64(eventsource.TopicSubscriptionTimeout.Seconds()),
+	})
+}
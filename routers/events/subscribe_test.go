@@ -0,0 +1,38 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package events
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/eventsource"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanReadIssueTopic(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	// issue7 is an issue of the private repo2 of user2
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 7}).(*models.Issue)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+
+	canRead := func(u *models.User, topic eventsource.Topic) bool {
+		ok, err := canReadTopic(u, topic)
+		assert.NoError(t, err)
+		return ok
+	}
+
+	assert.True(t, canRead(owner, eventsource.IssueTopic(issue.ID)))
+	assert.False(t, canRead(user, eventsource.IssueTopic(issue.ID)))
+
+	// the users invited to the issue may subscribe to it, but not to the other issues
+	assert.NoError(t, models.AddIssueCollaborator(issue, user, owner))
+	assert.True(t, canRead(user, eventsource.IssueTopic(issue.ID)))
+	assert.False(t, canRead(user, eventsource.PullTopic(issue.ID)))
+	assert.False(t, canRead(user, eventsource.IssueTopic(4)))
+}
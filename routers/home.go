@@ -78,6 +78,8 @@ type RepoSearchOptions struct {
 	Restricted bool
 	PageSize   int
 	TplName    base.TplName
+	// LoadHealth loads the results of the last garbage collection and health check into RepoHealth
+	LoadHealth bool
 }
 
 var (
@@ -161,6 +163,18 @@ func RenderRepoSearch(ctx *context.Context, opts *RepoSearchOptions) {
 	ctx.Data["Repos"] = repos
 	ctx.Data["IsRepoIndexerEnabled"] = setting.Indexer.RepoIndexerEnabled
 
+	if opts.LoadHealth {
+		repoIDs := make([]int64, 0, len(repos))
+		for _, repo := range repos {
+			repoIDs = append(repoIDs, repo.ID)
+		}
+		ctx.Data["RepoHealth"], err = models.GetRepoHealthMap(repoIDs)
+		if err != nil {
+			ctx.ServerError("GetRepoHealthMap", err)
+			return
+		}
+	}
+
 	pager := context.NewPagination(int(count), opts.PageSize, page, 5)
 	pager.SetDefaultParams(ctx)
 	pager.AddParam(ctx, "topic", "TopicOnly")
@@ -308,103 +322,166 @@ func ExploreCode(ctx *context.Context) {
 	}
 
 	queryType := strings.TrimSpace(ctx.Query("t"))
-	isMatch := queryType == "match"
-
-	var (
-		repoIDs []int64
-		err     error
-		isAdmin bool
-	)
-	if ctx.User != nil {
-		isAdmin = ctx.User.IsAdmin
-	}
-
-	// guest user or non-admin user
-	if ctx.User == nil || !isAdmin {
-		repoIDs, err = models.FindUserAccessibleRepoIDs(ctx.User)
-		if err != nil {
-			ctx.ServerError("SearchResults", err)
-			return
-		}
-	}
+	mode := code_indexer.ParseSearchMode(queryType)
+	treePath := strings.Trim(strings.TrimSpace(ctx.Query("path")), "/")
+	org := strings.TrimSpace(ctx.Query("org"))
+	repos := strings.Join(splitCodeSearchRepos(ctx.Query("repos")), ",")
 
 	var (
 		total                 int
 		searchResults         []*code_indexer.Result
 		searchResultLanguages []*code_indexer.SearchResultLanguages
+		searchResultDirs      []*code_indexer.SearchResultDirectory
+		searchResultRepos     []*code_indexer.SearchResultRepo
 	)
 
-	// if non-admin login user, we need check UnitTypeCode at first
-	if ctx.User != nil && len(repoIDs) > 0 {
-		repoMaps, err := models.GetRepositoriesMapByIDs(repoIDs)
+	scope, found, err := models.GetCodeSearchScope(org, splitCodeSearchRepos(repos))
+	if err != nil {
+		ctx.ServerError("GetCodeSearchScope", err)
+		return
+	}
+	// repoIDs is nil if the search is not restricted to some repositories
+	repoIDs := []int64{}
+	if found {
+		repoIDs, err = models.FindCodeSearchRepoIDs(ctx.User, scope)
 		if err != nil {
-			ctx.ServerError("SearchResults", err)
+			ctx.ServerError("FindCodeSearchRepoIDs", err)
 			return
 		}
+	}
 
-		var rightRepoMap = make(map[int64]*models.Repository, len(repoMaps))
-		repoIDs = make([]int64, 0, len(repoMaps))
-		for id, repo := range repoMaps {
-			if repo.CheckUnitUser(ctx.User, models.UnitTypeCode) {
-				rightRepoMap[id] = repo
-				repoIDs = append(repoIDs, id)
+	if repoIDs == nil || len(repoIDs) > 0 {
+		var facets *code_indexer.SearchResultFacets
+		total, searchResults, facets, err = code_indexer.PerformSearch(&code_indexer.SearchOptions{
+			RepoIDs:  repoIDs,
+			Language: language,
+			Path:     treePath,
+			Keyword:  keyword,
+			Mode:     mode,
+			Page:     page,
+			PageSize: setting.UI.RepoSearchPagingNum,
+		})
+		if err != nil {
+			if code_indexer.IsErrInvalidSearchRegexp(err) {
+				ctx.Flash.Error(ctx.Tr("explore.code_search_invalid_regexp", err.(code_indexer.ErrInvalidSearchRegexp).Err.Error()), true)
+			} else if err == code_indexer.ErrRegexpSearchUnsupported {
+				ctx.Flash.Error(ctx.Tr("explore.code_search_regexp_unsupported"), true)
+			} else {
+				ctx.ServerError("SearchResults", err)
+				return
 			}
 		}
 
-		ctx.Data["RepoMaps"] = rightRepoMap
-
-		total, searchResults, searchResultLanguages, err = code_indexer.PerformSearch(repoIDs, language, keyword, page, setting.UI.RepoSearchPagingNum, isMatch)
-		if err != nil {
-			ctx.ServerError("SearchResults", err)
-			return
+		var loadRepoIDs = make([]int64, 0, len(searchResults))
+		for _, result := range searchResults {
+			loadRepoIDs = appendUniqueRepoID(loadRepoIDs, result.RepoID)
 		}
-		// if non-login user or isAdmin, no need to check UnitTypeCode
-	} else if (ctx.User == nil && len(repoIDs) > 0) || isAdmin {
-		total, searchResults, searchResultLanguages, err = code_indexer.PerformSearch(repoIDs, language, keyword, page, setting.UI.RepoSearchPagingNum, isMatch)
+		if facets != nil {
+			searchResultLanguages = facets.Languages
+			searchResultDirs = facets.Directories
+			searchResultRepos = facets.Repos
+			for _, repo := range facets.Repos {
+				loadRepoIDs = appendUniqueRepoID(loadRepoIDs, repo.RepoID)
+			}
+		}
+
+		repoMaps, err := models.GetRepositoriesMapByIDs(loadRepoIDs)
 		if err != nil {
 			ctx.ServerError("SearchResults", err)
 			return
 		}
 
-		var loadRepoIDs = make([]int64, 0, len(searchResults))
-		for _, result := range searchResults {
-			var find bool
-			for _, id := range loadRepoIDs {
-				if id == result.RepoID {
-					find = true
-					break
-				}
+		// the repositories may have been deleted since they were indexed
+		existingRepos := make([]*code_indexer.SearchResultRepo, 0, len(searchResultRepos))
+		for _, repo := range searchResultRepos {
+			if _, ok := repoMaps[repo.RepoID]; ok {
+				existingRepos = append(existingRepos, repo)
 			}
-			if !find {
-				loadRepoIDs = append(loadRepoIDs, result.RepoID)
+		}
+		searchResultRepos = existingRepos
+
+		ctx.Data["RepoMaps"] = repoMaps
+	}
+
+	if ctx.IsSigned {
+		// the recent searches only keep the fuzzy and exact searches
+		if len(keyword) > 0 && page == 1 && len(repos) <= maxCodeSearchReposLength && mode != code_indexer.SearchModeRegexp {
+			if err := models.AddUserCodeSearch(&models.UserCodeSearch{
+				UserID:  ctx.User.ID,
+				Keyword: keyword,
+				IsMatch: mode == code_indexer.SearchModeMatch,
+				Org:     org,
+				Repos:   repos,
+			}); err != nil {
+				log.Error("AddUserCodeSearch: %v", err)
 			}
 		}
 
-		repoMaps, err := models.GetRepositoriesMapByIDs(loadRepoIDs)
+		recentSearches, err := models.GetUserCodeSearches(ctx.User.ID)
 		if err != nil {
-			ctx.ServerError("SearchResults", err)
+			ctx.ServerError("GetUserCodeSearches", err)
 			return
 		}
-
-		ctx.Data["RepoMaps"] = repoMaps
+		ctx.Data["RecentSearches"] = recentSearches
 	}
 
+	ctx.Data["Org"] = org
+	ctx.Data["Repos"] = repos
 	ctx.Data["Keyword"] = keyword
 	ctx.Data["Language"] = language
+	ctx.Data["Path"] = treePath
 	ctx.Data["queryType"] = queryType
+	ctx.Data["SupportsRegexpSearch"] = code_indexer.SupportsRegexpSearch()
 	ctx.Data["SearchResults"] = searchResults
 	ctx.Data["SearchResultLanguages"] = searchResultLanguages
+	ctx.Data["SearchResultDirectories"] = searchResultDirs
+	ctx.Data["SearchResultRepos"] = searchResultRepos
 	ctx.Data["RequireHighlightJS"] = true
 	ctx.Data["PageIsViewCode"] = true
 
 	pager := context.NewPagination(total, setting.UI.RepoSearchPagingNum, page, 5)
 	pager.SetDefaultParams(ctx)
 	pager.AddParam(ctx, "l", "Language")
+	pager.AddParam(ctx, "path", "Path")
+	pager.AddParam(ctx, "org", "Org")
+	pager.AddParam(ctx, "repos", "Repos")
 	ctx.Data["Page"] = pager
 
 	ctx.HTML(200, tplExploreCode)
 }
 
+func appendUniqueRepoID(repoIDs []int64, repoID int64) []int64 {
+	for _, id := range repoIDs {
+		if id == repoID {
+			return repoIDs
+		}
+	}
+	return append(repoIDs, repoID)
+}
+
+// maxCodeSearchReposLength is the maximum length of the repositories of the recent code searches
+const maxCodeSearchReposLength = 1024
+
+// splitCodeSearchRepos splits the comma separated full names of the repositories a code search is restricted to
+func splitCodeSearchRepos(repos string) []string {
+	fullNames := make([]string, 0, strings.Count(repos, ",")+1)
+	for _, fullName := range strings.Split(repos, ",") {
+		if fullName = strings.TrimSpace(fullName); len(fullName) > 0 {
+			fullNames = append(fullNames, fullName)
+		}
+	}
+	return fullNames
+}
+
+// ExploreCodeClearRecent forgets the recent code searches of the signed in user
+func ExploreCodeClearRecent(ctx *context.Context) {
+	if err := models.DeleteUserCodeSearches(ctx.User.ID); err != nil {
+		ctx.ServerError("DeleteUserCodeSearches", err)
+		return
+	}
+	ctx.Redirect(setting.AppSubURL + "/explore/code")
+}
+
 // NotFound render 404 page
 func NotFound(ctx *context.Context) {
 	ctx.Data["Title"] = "Page Not Found"
@@ -22,6 +22,7 @@ import (
 	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
 	stats_indexer "code.gitea.io/gitea/modules/indexer/stats"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/maintenance"
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/markup/external"
 	repo_migrations "code.gitea.io/gitea/modules/migrations"
@@ -31,9 +32,12 @@ import (
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/svg"
 	"code.gitea.io/gitea/modules/task"
+	"code.gitea.io/gitea/modules/tracing"
 	"code.gitea.io/gitea/modules/translation"
+	archiver_service "code.gitea.io/gitea/services/archiver"
 	"code.gitea.io/gitea/services/mailer"
 	mirror_service "code.gitea.io/gitea/services/mirror"
+	org_service "code.gitea.io/gitea/services/org"
 	pull_service "code.gitea.io/gitea/services/pull"
 	"code.gitea.io/gitea/services/repository"
 	"code.gitea.io/gitea/services/webhook"
@@ -130,6 +134,15 @@ func GlobalInit(ctx context.Context) {
 	if err := git.Init(ctx); err != nil {
 		log.Fatal("Git module init failed: %v", err)
 	}
+	if setting.Git.PackCache.Enabled {
+		var err error
+		if git.DefaultPackCache, err = git.NewPackCache(setting.Git.PackCache.Path, setting.Git.PackCache.TTL, setting.Git.PackCache.MaxSize); err != nil {
+			log.Fatal("Failed to initialize the pack cache: %v", err)
+		}
+	}
+	if setting.Git.RefsCache.Enabled {
+		git.DefaultRefsCache = git.NewRefsCache(setting.Git.RefsCache.TTL, setting.Git.RefsCache.MaxSize)
+	}
 	setting.CheckLFSVersion()
 	log.Trace("AppPath: %s", setting.AppPath)
 	log.Trace("AppWorkPath: %s", setting.AppWorkPath)
@@ -141,6 +154,11 @@ func GlobalInit(ctx context.Context) {
 	translation.InitLocales()
 
 	NewServices()
+	tracing.Init()
+
+	for _, warning := range setting.ValidateConfig() {
+		log.Warn("Configuration problem at %s: %s", warning.Location(), warning.Message)
+	}
 
 	highlight.NewContext()
 	external.RegisterParsers()
@@ -163,6 +181,8 @@ func GlobalInit(ctx context.Context) {
 
 	models.NewRepoContext()
 
+	maintenance.Init()
+
 	// Booting long running goroutines.
 	cron.NewContext()
 	issue_indexer.InitIssueIndexer(false)
@@ -181,6 +201,12 @@ func GlobalInit(ctx context.Context) {
 	if err := repo_migrations.Init(); err != nil {
 		log.Fatal("Failed to initialize repository migrations: %v", err)
 	}
+	if err := archiver_service.Init(); err != nil {
+		log.Fatal("Failed to initialize repository archiver queue: %v", err)
+	}
+	if err := org_service.InitContributions(); err != nil {
+		log.Fatal("Failed to initialize organization contributions queue: %v", err)
+	}
 	eventsource.GetManager().Init()
 
 	if setting.SSH.StartBuiltinServer {
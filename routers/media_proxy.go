@@ -0,0 +1,196 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routers
+
+import (
+	gocontext "context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const mediaProxyTimeout = 30 * time.Second
+
+var (
+	mediaProxyClient     *http.Client
+	mediaProxyClientOnce sync.Once
+
+	// mediaProxyBlockedNetworks are the networks the media proxy never connects to, so that it cannot be used to reach
+	// the services of the local network of the instance
+	mediaProxyBlockedNetworks []*net.IPNet
+)
+
+func init() {
+	for _, cidr := range []string{
+		"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16", "172.16.0.0/12",
+		"192.0.0.0/24", "192.168.0.0/16", "198.18.0.0/15", "224.0.0.0/4", "240.0.0.0/4",
+		"::/128", "::1/128", "64:ff9b::/96", "fc00::/7", "fe80::/10", "ff00::/8",
+	} {
+		_, network, _ := net.ParseCIDR(cidr)
+		mediaProxyBlockedNetworks = append(mediaProxyBlockedNetworks, network)
+	}
+}
+
+var errMediaProxyBlockedAddress = errors.New("the address is in a blocked network")
+
+// mediaProxyMaxRedirects is the maximum number of redirects followed to load an image
+const mediaProxyMaxRedirects = 5
+
+func isMediaProxyBlocked(ip net.IP) bool {
+	for _, blocked := range mediaProxyBlockedNetworks {
+		if blocked.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// mediaProxyDialContext resolves the hosts itself and connects to the resolved addresses, so that a host is refused if
+// any of its addresses is blocked and cannot resolve to another address between the check and the connection. It is
+// used for every connection, including the ones of the redirects.
+func mediaProxyDialContext(dialer *net.Dialer) func(ctx gocontext.Context, network, address string) (net.Conn, error) {
+	return func(ctx gocontext.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			if isMediaProxyBlocked(addr.IP) {
+				return nil, errMediaProxyBlockedAddress
+			}
+		}
+
+		err = fmt.Errorf("no address found for %s", host)
+		for _, addr := range addrs {
+			var conn net.Conn
+			if conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(addr.IP.String(), port)); err == nil {
+				return conn, nil
+			}
+		}
+		return nil, err
+	}
+}
+
+// mediaProxyDialControl checks the address a connection is made to, as a last resort
+func mediaProxyDialControl(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("invalid address %s", address)
+	}
+	if isMediaProxyBlocked(ip) {
+		return errMediaProxyBlockedAddress
+	}
+	return nil
+}
+
+// mediaProxyCheckRedirect only follows a few redirects to http and https links, their addresses being checked when
+// connecting to them
+func mediaProxyCheckRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= mediaProxyMaxRedirects {
+		return fmt.Errorf("stopped after %d redirects", mediaProxyMaxRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("redirect to an unsupported scheme %q", req.URL.Scheme)
+	}
+	return nil
+}
+
+func getMediaProxyClient() *http.Client {
+	mediaProxyClientOnce.Do(func() {
+		dialer := &net.Dialer{
+			Timeout: mediaProxyTimeout,
+			Control: mediaProxyDialControl,
+		}
+		mediaProxyClient = &http.Client{
+			Timeout:       mediaProxyTimeout,
+			CheckRedirect: mediaProxyCheckRedirect,
+			// no HTTP proxy is used, as the addresses of the images could not be checked
+			Transport: &http.Transport{
+				DialContext: mediaProxyDialContext(dialer),
+			},
+		}
+	})
+	return mediaProxyClient
+}
+
+// MediaProxy serves an external image of the rendered markup through the built-in media proxy, if the signature of its
+// link is valid. Only the images of public networks are loaded.
+func MediaProxy(ctx *context.Context) {
+	if !setting.MediaProxy.Enabled || !setting.MediaProxy.BuiltIn {
+		ctx.NotFound("MediaProxy", nil)
+		return
+	}
+	link, ok := markup.VerifyMediaProxyLink(ctx.Params("signature"), ctx.Params("link"))
+	if !ok {
+		ctx.NotFound("VerifyMediaProxyLink", nil)
+		return
+	}
+	if u, err := url.Parse(link); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		ctx.NotFound("MediaProxy", nil)
+		return
+	}
+
+	req, err := http.NewRequest("GET", link, nil)
+	if err != nil {
+		ctx.NotFound("MediaProxy", nil)
+		return
+	}
+	req.Header.Set("User-Agent", "Gitea "+setting.AppVer)
+	req.Header.Set("Accept", "image/*")
+	resp, err := getMediaProxyClient().Do(req)
+	if err != nil {
+		log.Debug("Unable to load %s through the media proxy: %v", link, err)
+		ctx.NotFound("MediaProxy", nil)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.ContentLength > setting.MediaProxy.MaxSize {
+		ctx.NotFound("MediaProxy", nil)
+		return
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, setting.MediaProxy.MaxSize+1))
+	if err != nil {
+		log.Debug("Unable to read %s through the media proxy: %v", link, err)
+		ctx.NotFound("MediaProxy", nil)
+		return
+	}
+	// the content is sniffed rather than trusting the type given by the host, the SVG images being sandboxed
+	if int64(len(data)) > setting.MediaProxy.MaxSize || !base.IsImageFile(data) {
+		ctx.NotFound("MediaProxy", nil)
+		return
+	}
+
+	ctx.Resp.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	ctx.Resp.Header().Set("X-Content-Type-Options", "nosniff")
+	ctx.Resp.Header().Set("Content-Type", base.DetectContentType(data))
+	ctx.Resp.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	ctx.Resp.Header().Set("Cache-Control", "public,max-age=86400")
+	if _, err := ctx.Resp.Write(data); err != nil {
+		log.Error("Write: %v", err)
+	}
+}
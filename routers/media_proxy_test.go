@@ -0,0 +1,70 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsMediaProxyBlocked(t *testing.T) {
+	for _, ip := range []string{
+		"0.0.0.0", "10.1.2.3", "100.64.0.1", "127.0.0.1", "169.254.169.254", "172.16.0.1", "192.0.0.8", "192.168.1.1",
+		"198.18.0.1", "224.0.0.1", "255.255.255.255", "::", "::1", "::ffff:127.0.0.1", "64:ff9b::7f00:1", "fd00::1",
+		"fe80::1", "ff02::1",
+	} {
+		assert.True(t, isMediaProxyBlocked(net.ParseIP(ip)), ip)
+	}
+	for _, ip := range []string{"1.1.1.1", "8.8.8.8", "192.0.2.1", "2001:4860:4860::8888", "::ffff:8.8.8.8"} {
+		assert.False(t, isMediaProxyBlocked(net.ParseIP(ip)), ip)
+	}
+}
+
+func TestMediaProxyDialContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	// the names are resolved before connecting, the local server is neither reachable by its address nor by its name
+	dial := mediaProxyDialContext(&net.Dialer{Control: mediaProxyDialControl})
+	for _, host := range []string{"127.0.0.1", "localhost"} {
+		_, err := dial(context.Background(), "tcp", net.JoinHostPort(host, port))
+		assert.Equal(t, errMediaProxyBlockedAddress, err, host)
+	}
+
+	// the connections made by the redirects are checked likewise, only the first one is let through here
+	redirect := httptest.NewServer(http.RedirectHandler(server.URL, http.StatusFound))
+	defer redirect.Close()
+	var dialed []string
+	client := &http.Client{
+		CheckRedirect: mediaProxyCheckRedirect,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				dialed = append(dialed, address)
+				if address == redirect.Listener.Addr().String() {
+					return (&net.Dialer{}).DialContext(ctx, network, address)
+				}
+				return dial(ctx, network, address)
+			},
+		},
+	}
+	_, err := client.Get(redirect.URL)
+	assert.Error(t, err)
+	assert.Equal(t, []string{redirect.Listener.Addr().String(), server.Listener.Addr().String()}, dialed)
+}
+
+func TestMediaProxyCheckRedirect(t *testing.T) {
+	req := &http.Request{URL: &url.URL{Scheme: "https", Host: "example.com"}}
+	assert.NoError(t, mediaProxyCheckRedirect(req, make([]*http.Request, mediaProxyMaxRedirects-1)))
+	assert.Error(t, mediaProxyCheckRedirect(req, make([]*http.Request, mediaProxyMaxRedirects)))
+	req.URL.Scheme = "file"
+	assert.Error(t, mediaProxyCheckRedirect(req, nil))
+}
@@ -62,6 +62,11 @@ func Members(ctx *context.Context) {
 	ctx.Data["MembersIsPublicMember"] = membersIsPublic
 	ctx.Data["MembersIsUserOrgOwner"] = members.IsUserOrgOwner(org.ID)
 	ctx.Data["MembersTwoFaStatus"] = members.GetTwoFaStatus()
+	ctx.Data["MembersProfileFields"], err = members.GetVisibleProfileFields(ctx.User)
+	if err != nil {
+		ctx.ServerError("GetVisibleProfileFields", err)
+		return
+	}
 
 	ctx.HTML(200, tplMembers)
 }
@@ -0,0 +1,129 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// IssueTypes render the issue types of an organization
+func IssueTypes(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.issue_types")
+	ctx.Data["PageIsOrgSettingsIssueTypes"] = true
+	ctx.Data["IssueTypesLink"] = ctx.Org.OrgLink + "/settings/issue_types"
+
+	var err error
+	ctx.Data["IssueTypes"], err = models.GetIssueTypesByOrgID(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetIssueTypesByOrgID", err)
+		return
+	}
+	ctx.HTML(200, tplSettingsIssueTypes)
+}
+
+// NewIssueType creates an issue type for an organization
+func NewIssueType(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.IssueTypeForm)
+	link := ctx.Org.OrgLink + "/settings/issue_types"
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(link)
+		return
+	}
+
+	t := &models.IssueType{
+		OrgID:        ctx.Org.Organization.ID,
+		Name:         form.Name,
+		Description:  form.Description,
+		Color:        form.Color,
+		TemplateFile: form.TemplateFile,
+	}
+	if err := models.NewIssueType(t); err != nil {
+		if flashIssueTypeError(ctx, err) {
+			ctx.Redirect(link)
+			return
+		}
+		ctx.ServerError("NewIssueType", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.issue_types.creation_success", t.Name))
+	ctx.Redirect(link)
+}
+
+// UpdateIssueType updates an issue type of an organization
+func UpdateIssueType(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.IssueTypeForm)
+	link := ctx.Org.OrgLink + "/settings/issue_types"
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(link)
+		return
+	}
+
+	t, err := models.GetIssueTypeInOrgByID(ctx.Org.Organization.ID, form.ID)
+	if err != nil {
+		if models.IsErrIssueTypeNotExist(err) {
+			ctx.NotFound("GetIssueTypeInOrgByID", err)
+		} else {
+			ctx.ServerError("GetIssueTypeInOrgByID", err)
+		}
+		return
+	}
+
+	t.Name = form.Name
+	t.Description = form.Description
+	t.Color = form.Color
+	t.TemplateFile = form.TemplateFile
+	if err := models.UpdateIssueType(t); err != nil {
+		if flashIssueTypeError(ctx, err) {
+			ctx.Redirect(link)
+			return
+		}
+		ctx.ServerError("UpdateIssueType", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.issue_types.update_success", t.Name))
+	ctx.Redirect(link)
+}
+
+// DeleteIssueType deletes an issue type of an organization
+func DeleteIssueType(ctx *context.Context) {
+	t, err := models.GetIssueTypeInOrgByID(ctx.Org.Organization.ID, ctx.QueryInt64("id"))
+	if err != nil {
+		ctx.Flash.Error("GetIssueTypeInOrgByID: " + err.Error())
+	} else if err := models.DeleteIssueType(t); err != nil {
+		ctx.Flash.Error("DeleteIssueType: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.issue_types.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Org.OrgLink + "/settings/issue_types",
+	})
+}
+
+// InitializeIssueTypes adds the default issue types to an organization
+func InitializeIssueTypes(ctx *context.Context) {
+	if err := models.InitializeIssueTypes(ctx.Org.Organization.ID, 0); err != nil {
+		ctx.ServerError("InitializeIssueTypes", err)
+		return
+	}
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/issue_types")
+}
+
+func flashIssueTypeError(ctx *context.Context, err error) bool {
+	switch {
+	case models.IsErrIssueTypeAlreadyExist(err):
+		ctx.Flash.Error(ctx.Tr("repo.issue_types.already_exist", err.(models.ErrIssueTypeAlreadyExist).Name))
+	case models.IsErrInvalidIssueType(err):
+		ctx.Flash.Error(ctx.Tr("repo.issue_types.invalid", err.(models.ErrInvalidIssueType).Reason))
+	default:
+		return false
+	}
+	return true
+}
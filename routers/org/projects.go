@@ -0,0 +1,542 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+)
+
+const (
+	tplProjects     base.TplName = "org/projects/list"
+	tplProjectsNew  base.TplName = "org/projects/new"
+	tplProjectsView base.TplName = "org/projects/view"
+)
+
+// Projects renders the projects of an organization
+func Projects(ctx *context.Context) {
+	org := ctx.Org.Organization
+	ctx.Data["Title"] = ctx.Tr("repo.project_board")
+	ctx.Data["PageIsOrgProjects"] = true
+
+	sortType := ctx.QueryTrim("sort")
+	isShowClosed := strings.ToLower(ctx.QueryTrim("state")) == "closed"
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	openCount, closedCount, err := models.CountOrgProjects(org.ID)
+	if err != nil {
+		ctx.ServerError("CountOrgProjects", err)
+		return
+	}
+	ctx.Data["OpenCount"] = openCount
+	ctx.Data["ClosedCount"] = closedCount
+
+	projects, count, err := models.GetProjects(models.ProjectSearchOptions{
+		OwnerID:  org.ID,
+		Page:     page,
+		IsClosed: util.OptionalBoolOf(isShowClosed),
+		SortType: sortType,
+		Type:     models.ProjectTypeOrganization,
+	})
+	if err != nil {
+		ctx.ServerError("GetProjects", err)
+		return
+	}
+	for i := range projects {
+		projects[i].RenderedContent = string(markdown.Render([]byte(projects[i].Description), org.HomeLink(), nil))
+	}
+	ctx.Data["Projects"] = projects
+
+	if isShowClosed {
+		ctx.Data["State"] = "closed"
+	} else {
+		ctx.Data["State"] = "open"
+	}
+
+	pager := context.NewPagination(int(count), setting.UI.IssuePagingNum, page, 5)
+	pager.AddParam(ctx, "state", "State")
+	ctx.Data["Page"] = pager
+
+	ctx.Data["CanWriteProjects"] = ctx.Org.IsOwner
+	ctx.Data["IsShowClosed"] = isShowClosed
+	ctx.Data["SortType"] = sortType
+
+	ctx.HTML(200, tplProjects)
+}
+
+// NewProject renders the page to create a project of an organization
+func NewProject(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.projects.new")
+	ctx.Data["PageIsOrgProjects"] = true
+	ctx.Data["ProjectTypes"] = models.GetProjectsConfig()
+	ctx.Data["SwimlaneTypes"] = models.GetProjectSwimlanesConfig()
+	ctx.HTML(200, tplProjectsNew)
+}
+
+// NewProjectPost creates a project of an organization
+func NewProjectPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.CreateProjectForm)
+	ctx.Data["Title"] = ctx.Tr("repo.projects.new")
+	ctx.Data["PageIsOrgProjects"] = true
+
+	if ctx.HasError() {
+		ctx.Data["ProjectTypes"] = models.GetProjectsConfig()
+		ctx.Data["SwimlaneTypes"] = models.GetProjectSwimlanesConfig()
+		ctx.HTML(200, tplProjectsNew)
+		return
+	}
+
+	if err := models.NewProject(&models.Project{
+		OwnerID:      ctx.Org.Organization.ID,
+		Title:        form.Title,
+		Description:  form.Content,
+		CreatorID:    ctx.User.ID,
+		BoardType:    form.BoardType,
+		SwimlaneType: form.SwimlaneType,
+		Type:         models.ProjectTypeOrganization,
+	}); err != nil {
+		ctx.ServerError("NewProject", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.projects.create_success", form.Title))
+	ctx.Redirect(ctx.Org.OrgLink + "/projects")
+}
+
+// getOrgProject returns the project of the organization given in the URL, it writes a not found page if there is none
+func getOrgProject(ctx *context.Context) *models.Project {
+	p, err := models.GetProjectInOrgByID(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrProjectNotExist(err) {
+			ctx.NotFound("", nil)
+		} else {
+			ctx.ServerError("GetProjectInOrgByID", err)
+		}
+		return nil
+	}
+	return p
+}
+
+// EditProject renders the page to edit a project of an organization
+func EditProject(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.projects.edit")
+	ctx.Data["PageIsOrgProjects"] = true
+	ctx.Data["PageIsEditProjects"] = true
+	ctx.Data["SwimlaneTypes"] = models.GetProjectSwimlanesConfig()
+
+	p := getOrgProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.Data["title"] = p.Title
+	ctx.Data["content"] = p.Description
+	ctx.Data["swimlane_type"] = p.SwimlaneType
+
+	ctx.HTML(200, tplProjectsNew)
+}
+
+// EditProjectPost updates a project of an organization
+func EditProjectPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.CreateProjectForm)
+	ctx.Data["Title"] = ctx.Tr("repo.projects.edit")
+	ctx.Data["PageIsOrgProjects"] = true
+	ctx.Data["PageIsEditProjects"] = true
+	ctx.Data["SwimlaneTypes"] = models.GetProjectSwimlanesConfig()
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplProjectsNew)
+		return
+	}
+
+	p := getOrgProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	p.Title = form.Title
+	p.Description = form.Content
+	p.SwimlaneType = form.SwimlaneType
+	if err := models.UpdateProject(p); err != nil {
+		ctx.ServerError("UpdateProject", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.projects.edit_success", p.Title))
+	ctx.Redirect(ctx.Org.OrgLink + "/projects")
+}
+
+// ChangeProjectStatus opens or closes a project of an organization
+func ChangeProjectStatus(ctx *context.Context) {
+	var toClose bool
+	switch ctx.Params(":action") {
+	case "open":
+		toClose = false
+	case "close":
+		toClose = true
+	default:
+		ctx.Redirect(ctx.Org.OrgLink + "/projects")
+		return
+	}
+
+	p := getOrgProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.ChangeProjectStatus(p, toClose); err != nil {
+		ctx.ServerError("ChangeProjectStatus", err)
+		return
+	}
+	ctx.Redirect(ctx.Org.OrgLink + "/projects?state=" + ctx.Params(":action"))
+}
+
+// DeleteProject deletes a project of an organization
+func DeleteProject(ctx *context.Context) {
+	p := getOrgProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteProjectByID(p.ID); err != nil {
+		ctx.Flash.Error("DeleteProjectByID: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.projects.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Org.OrgLink + "/projects",
+	})
+}
+
+// ViewProject renders the boards of a project of an organization, with the issues of the repositories the user can read
+func ViewProject(ctx *context.Context) {
+	project := getOrgProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	boards, err := models.GetProjectBoards(project.ID)
+	if err != nil {
+		ctx.ServerError("GetProjectBoards", err)
+		return
+	}
+	if boards[0].ID == 0 {
+		boards[0].Title = ctx.Tr("repo.projects.type.uncategorized")
+	}
+
+	if _, err := boards.LoadIssues(); err != nil {
+		ctx.ServerError("LoadIssuesOfBoards", err)
+		return
+	}
+	if err := boards.RemoveIssuesNotVisibleTo(ctx.User); err != nil {
+		ctx.ServerError("RemoveIssuesNotVisibleTo", err)
+		return
+	}
+
+	linkedPrsMap := make(map[int64][]*models.Issue)
+	for _, b := range boards {
+		for _, issue := range b.Issues {
+			var referencedIds []int64
+			for _, comment := range issue.Comments {
+				if comment.RefIssueID != 0 && comment.RefIsPull {
+					referencedIds = append(referencedIds, comment.RefIssueID)
+				}
+			}
+			if len(referencedIds) == 0 {
+				continue
+			}
+
+			linkedPrs, err := models.Issues(&models.IssuesOptions{
+				IssueIDs: referencedIds,
+				IsPull:   util.OptionalBoolTrue,
+			})
+			if err != nil {
+				continue
+			}
+			if linkedPrsMap[issue.ID], err = models.IssueList(linkedPrs).FilterVisibleTo(ctx.User); err != nil {
+				ctx.ServerError("FilterVisibleTo", err)
+				return
+			}
+		}
+	}
+	ctx.Data["LinkedPRs"] = linkedPrsMap
+
+	if ctx.Org.IsOwner {
+		repos, err := models.GetOrgRepositories(ctx.Org.Organization.ID)
+		if err != nil {
+			ctx.ServerError("GetOrgRepositories", err)
+			return
+		}
+		ctx.Data["OrgRepos"] = repos
+	}
+
+	project.RenderedContent = string(markdown.Render([]byte(project.Description), ctx.Org.Organization.HomeLink(), nil))
+
+	swimlanes := boards.Swimlanes(project.SwimlaneType)
+	for _, lane := range swimlanes {
+		if lane.ID != 0 {
+			continue
+		}
+		switch project.SwimlaneType {
+		case models.ProjectSwimlaneTypeAssignee:
+			lane.Title = ctx.Tr("repo.projects.swimlanes.no_assignee")
+		case models.ProjectSwimlaneTypeLabel:
+			lane.Title = ctx.Tr("repo.projects.swimlanes.no_label")
+		case models.ProjectSwimlaneTypeMilestone:
+			lane.Title = ctx.Tr("repo.projects.swimlanes.no_milestone")
+		}
+	}
+
+	ctx.Data["Title"] = project.Title
+	ctx.Data["CanWriteProjects"] = ctx.Org.IsOwner
+	ctx.Data["CanManageBoards"] = ctx.Org.IsOwner
+	ctx.Data["IsOrgProject"] = true
+	ctx.Data["Project"] = project
+	ctx.Data["ProjectLink"] = fmt.Sprintf("%s/projects/%d", ctx.Org.OrgLink, project.ID)
+	ctx.Data["Boards"] = boards
+	ctx.Data["Swimlanes"] = swimlanes
+	ctx.Data["PageIsOrgProjects"] = true
+	ctx.Data["PageIsProjects"] = true
+	ctx.Data["RequiresDraggable"] = true
+
+	ctx.HTML(200, tplProjectsView)
+}
+
+// getOrgProjectBoard returns the project of the organization and its board given in the URL, it writes an error if
+// there are none
+func getOrgProjectBoard(ctx *context.Context) (*models.Project, *models.ProjectBoard) {
+	project := getOrgProject(ctx)
+	if ctx.Written() {
+		return nil, nil
+	}
+
+	board, err := models.GetProjectBoard(ctx.ParamsInt64(":boardID"))
+	if err != nil {
+		if models.IsErrProjectBoardNotExist(err) {
+			ctx.NotFound("", nil)
+		} else {
+			ctx.ServerError("GetProjectBoard", err)
+		}
+		return nil, nil
+	}
+	if board.ProjectID != project.ID {
+		ctx.JSON(422, map[string]string{
+			"message": fmt.Sprintf("ProjectBoard[%d] is not in Project[%d] as expected", board.ID, project.ID),
+		})
+		return nil, nil
+	}
+	return project, board
+}
+
+// AddBoardToProjectPost adds a board to a project of an organization
+func AddBoardToProjectPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.EditProjectBoardForm)
+	project := getOrgProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.NewProjectBoard(&models.ProjectBoard{
+		ProjectID: project.ID,
+		Title:     form.Title,
+		CreatorID: ctx.User.ID,
+	}); err != nil {
+		ctx.ServerError("NewProjectBoard", err)
+		return
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// EditProjectBoard updates a board of a project of an organization
+func EditProjectBoard(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.EditProjectBoardForm)
+	_, board := getOrgProjectBoard(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Title != "" {
+		board.Title = form.Title
+	}
+	if form.Sorting != 0 {
+		board.Sorting = form.Sorting
+	}
+	if err := models.UpdateProjectBoard(board); err != nil {
+		ctx.ServerError("UpdateProjectBoard", err)
+		return
+	}
+
+	if form.WIPLimit != nil || form.AutoMoveOnClose != nil || form.AutoMoveOnMerge != nil || form.RepoFilter != nil {
+		if form.WIPLimit != nil {
+			board.WIPLimit = *form.WIPLimit
+		}
+		if form.AutoMoveOnClose != nil {
+			board.AutoMoveOnClose = *form.AutoMoveOnClose
+		}
+		if form.AutoMoveOnMerge != nil {
+			board.AutoMoveOnMerge = *form.AutoMoveOnMerge
+		}
+		if form.RepoFilter != nil {
+			board.RepoFilter = *form.RepoFilter
+			if !checkBoardRepoFilter(ctx, board) {
+				return
+			}
+		}
+		if err := models.UpdateProjectBoardSettings(board); err != nil {
+			ctx.ServerError("UpdateProjectBoardSettings", err)
+			return
+		}
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// checkBoardRepoFilter checks that the repositories of the filter of a board belong to the organization
+func checkBoardRepoFilter(ctx *context.Context, board *models.ProjectBoard) bool {
+	for _, id := range board.FilterRepoIDs() {
+		repo, err := models.GetRepositoryByID(id)
+		if err != nil && !models.IsErrRepoNotExist(err) {
+			ctx.ServerError("GetRepositoryByID", err)
+			return false
+		}
+		if err != nil || repo.OwnerID != ctx.Org.Organization.ID {
+			ctx.JSON(422, map[string]string{
+				"message": fmt.Sprintf("Repository[%d] is not in Organization[%d] as expected", id, ctx.Org.Organization.ID),
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// SetDefaultProjectBoard sets the board of a project of an organization receiving the issues not in any board
+func SetDefaultProjectBoard(ctx *context.Context) {
+	project, board := getOrgProjectBoard(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.SetDefaultBoard(project.ID, board.ID); err != nil {
+		ctx.ServerError("SetDefaultBoard", err)
+		return
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// DeleteProjectBoard deletes a board of a project of an organization, its issues are no longer in any board
+func DeleteProjectBoard(ctx *context.Context) {
+	_, board := getOrgProjectBoard(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteProjectBoardByID(board.ID); err != nil {
+		ctx.ServerError("DeleteProjectBoardByID", err)
+		return
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// MoveIssueAcrossBoards moves an issue to another board of a project of an organization, the user has to be able to
+// change the issues of its repository
+func MoveIssueAcrossBoards(ctx *context.Context) {
+	project := getOrgProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	var board *models.ProjectBoard
+	if ctx.ParamsInt64(":boardID") == 0 {
+		board = &models.ProjectBoard{
+			ID:        0,
+			ProjectID: project.ID,
+			Title:     ctx.Tr("repo.projects.type.uncategorized"),
+		}
+	} else {
+		var err error
+		board, err = models.GetProjectBoard(ctx.ParamsInt64(":boardID"))
+		if err != nil {
+			if models.IsErrProjectBoardNotExist(err) {
+				ctx.NotFound("", nil)
+			} else {
+				ctx.ServerError("GetProjectBoard", err)
+			}
+			return
+		}
+		if board.ProjectID != project.ID {
+			ctx.NotFound("", nil)
+			return
+		}
+	}
+
+	issue, err := models.GetIssueByID(ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound("", nil)
+		} else {
+			ctx.ServerError("GetIssueByID", err)
+		}
+		return
+	}
+	if issue.ProjectID() != project.ID {
+		ctx.NotFound("", nil)
+		return
+	}
+
+	if err := issue.LoadRepo(); err != nil {
+		ctx.ServerError("LoadRepo", err)
+		return
+	}
+	perm, err := models.GetUserRepoPermission(issue.Repo, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetUserRepoPermission", err)
+		return
+	}
+	if !perm.CanWriteIssuesOrPulls(issue.IsPull) || issue.Repo.IsArchived {
+		ctx.JSON(403, map[string]string{
+			"message": "Only authorized users are allowed to perform this action.",
+		})
+		return
+	}
+
+	if err := models.MoveIssueAcrossProjectBoards(issue, board); err != nil {
+		if models.IsErrProjectBoardRepoNotAllowed(err) {
+			ctx.JSON(422, map[string]string{
+				"message": err.Error(),
+			})
+			return
+		}
+		ctx.ServerError("MoveIssueAcrossProjectBoards", err)
+		return
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"ok": true,
+	})
+}
@@ -27,6 +27,8 @@ const (
 	tplSettingsHooks base.TplName = "org/settings/hooks"
 	// tplSettingsLabels template path for render labels settings
 	tplSettingsLabels base.TplName = "org/settings/labels"
+	// tplSettingsIssueTypes template path for render issue types settings
+	tplSettingsIssueTypes base.TplName = "org/settings/issue_types"
 )
 
 // Settings render the main settings page
@@ -15,9 +15,11 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
 	gitea_context "code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/maintenance"
 	"code.gitea.io/gitea/modules/private"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
@@ -39,7 +41,7 @@ func verifyCommits(oldCommitID, newCommitID string, repo *git.Repository, env []
 	}()
 
 	// This is safe as force pushes are already forbidden
-	err = git.NewCommand("rev-list", oldCommitID+"..."+newCommitID).
+	err = git.NewCommandContext(repo.Ctx, "rev-list", oldCommitID+"..."+newCommitID).
 		RunInDirTimeoutEnvFullPipelineFunc(env, -1, repo.Path,
 			stdoutWriter, nil, nil,
 			func(ctx context.Context, cancel context.CancelFunc) error {
@@ -83,7 +85,7 @@ func readAndVerifyCommit(sha string, repo *git.Repository, env []string) error {
 	}()
 	hash := git.MustIDFromString(sha)
 
-	return git.NewCommand("cat-file", "commit", sha).
+	return git.NewCommandContext(repo.Ctx, "cat-file", "commit", sha).
 		RunInDirTimeoutEnvFullPipelineFunc(env, -1, repo.Path,
 			stdoutWriter, nil, nil,
 			func(ctx context.Context, cancel context.CancelFunc) error {
@@ -130,7 +132,7 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 		return
 	}
 	repo.OwnerName = ownerName
-	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	gitRepo, err := git.OpenRepositoryCtx(ctx.Req.Context(), repo.RepoPath())
 	if err != nil {
 		log.Error("Unable to get git repository for: %s/%s Error: %v", ownerName, repoName, err)
 		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
@@ -140,6 +142,60 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 	}
 	defer gitRepo.Close()
 
+	// Only site administrators can push while the instance is in maintenance mode
+	if maintenance.IsEnabled() {
+		isAdmin := false
+		if !opts.IsDeployKey && opts.UserID > 0 {
+			user, err := models.GetUserByID(opts.UserID)
+			if err != nil {
+				log.Error("Unable to get User id %d Error: %v", opts.UserID, err)
+				ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+					"err": fmt.Sprintf("Unable to get User id %d Error: %v", opts.UserID, err),
+				})
+				return
+			}
+			isAdmin = user.IsAdmin
+		}
+		if !isAdmin {
+			log.Warn("Forbidden: Gitea is in maintenance mode, rejecting push to %-v", repo)
+			ctx.JSON(http.StatusForbidden, map[string]interface{}{
+				"err": "Gitea is in maintenance mode, repositories are read-only",
+			})
+			return
+		}
+	}
+
+	// A repository under maintenance only accepts pushes of its administrators
+	if repo.IsUnderMaintenance() {
+		canPush := false
+		if !opts.IsDeployKey && opts.UserID > 0 {
+			user, err := models.GetUserByID(opts.UserID)
+			if err != nil {
+				log.Error("Unable to get User id %d Error: %v", opts.UserID, err)
+				ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+					"err": fmt.Sprintf("Unable to get User id %d Error: %v", opts.UserID, err),
+				})
+				return
+			}
+			perm, err := models.GetUserRepoPermission(repo, user)
+			if err != nil {
+				log.Error("Unable to get Repo permission of repo %s/%s of User %s: %v", repo.OwnerName, repo.Name, user.Name, err)
+				ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+					"err": fmt.Sprintf("Unable to get Repo permission of repo %s/%s of User %s: %v", repo.OwnerName, repo.Name, user.Name, err),
+				})
+				return
+			}
+			canPush = perm.IsAdmin()
+		}
+		if !canPush {
+			log.Warn("Forbidden: %-v is under maintenance", repo)
+			ctx.JSON(http.StatusForbidden, map[string]interface{}{
+				"err": "repository is under maintenance and read-only",
+			})
+			return
+		}
+	}
+
 	// Generate git environment for checking commits
 	env := os.Environ()
 	if opts.GitAlternativeObjectDirectories != "" {
@@ -155,6 +211,9 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 			private.GitQuarantinePath+"="+opts.GitQuarantinePath)
 	}
 
+	var warnings []string
+	largeFilesSeen := make(map[string]bool)
+
 	// Iterate across the provided old commit IDs
 	for i := range opts.OldCommitIDs {
 		oldCommitID := opts.OldCommitIDs[i]
@@ -170,6 +229,37 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 			return
 		}
 
+		// Check for large files which are not tracked by LFS
+		if setting.Repository.LargeFile.MaxSize > 0 && newCommitID != git.EmptySHA {
+			threshold := setting.Repository.LargeFile.MaxSize * 1024 * 1024
+			files, err := git.FindLargeFiles(repo.RepoPath(), env, threshold, newCommitID, "--not", "--all")
+			if err != nil {
+				log.Error("Unable to check for large files in commits up to %s in %-v: %v", newCommitID, repo, err)
+				ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+					"err": fmt.Sprintf("Unable to check for large files in commits up to %s: %v", newCommitID, err),
+				})
+				return
+			}
+			if len(files) > 0 && setting.Repository.LargeFile.Block {
+				paths := make([]string, 0, len(files))
+				for _, file := range files {
+					paths = append(paths, file.Path)
+				}
+				log.Warn("Forbidden: Branch: %s in %-v would add files larger than %s: %s", branchName, repo, base.FileSize(threshold), strings.Join(paths, ", "))
+				ctx.JSON(http.StatusForbidden, map[string]interface{}{
+					"err": fmt.Sprintf("branch %s adds files larger than %s which are not tracked by Git LFS: %s", branchName, base.FileSize(threshold), strings.Join(paths, ", ")),
+				})
+				return
+			}
+			for _, file := range files {
+				if largeFilesSeen[file.ID.String()] {
+					continue
+				}
+				largeFilesSeen[file.ID.String()] = true
+				warnings = append(warnings, fmt.Sprintf("%s is %s, which is larger than %s. Consider tracking it with Git LFS.", file.Path, base.FileSize(file.Size), base.FileSize(threshold)))
+			}
+		}
+
 		protectBranch, err := models.GetProtectedBranchBy(repo.ID, branchName)
 		if err != nil {
 			log.Error("Unable to get protected branch: %s in %-v Error: %v", branchName, repo, err)
@@ -199,7 +289,7 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 
 		// 2. Disallow force pushes to protected branches
 		if git.EmptySHA != oldCommitID {
-			output, err := git.NewCommand("rev-list", "--max-count=1", oldCommitID, "^"+newCommitID).RunInDirWithEnv(repo.RepoPath(), env)
+			output, err := git.NewCommandContext(ctx.Req.Context(), "rev-list", "--max-count=1", oldCommitID, "^"+newCommitID).RunInDirWithEnv(repo.RepoPath(), env)
 			if err != nil {
 				log.Error("Unable to detect force push between: %s and %s in %-v Error: %v", oldCommitID, newCommitID, repo, err)
 				ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
@@ -367,7 +457,9 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 		}
 	}
 
-	ctx.PlainText(http.StatusOK, []byte("ok"))
+	ctx.JSON(http.StatusOK, private.HookPreReceiveResult{
+		Warnings: warnings,
+	})
 }
 
 // HookPostReceive updates services and users
@@ -376,6 +468,17 @@ func HookPostReceive(ctx *gitea_context.PrivateContext) {
 	ownerName := ctx.Params(":owner")
 	repoName := ctx.Params(":repo")
 
+	// any ref may have changed, including the ones which are not branches nor tags
+	if opts.IsWiki {
+		// TODO: support news feeds for wiki
+		git.InvalidateRefsCache(models.WikiPath(ownerName, strings.TrimSuffix(repoName, ".wiki")))
+		git.InvalidatePackCache(models.WikiPath(ownerName, strings.TrimSuffix(repoName, ".wiki")))
+		ctx.JSON(http.StatusOK, private.HookPostReceiveResult{})
+		return
+	}
+	git.InvalidateRefsCache(models.RepoPath(ownerName, repoName))
+	git.InvalidatePackCache(models.RepoPath(ownerName, repoName))
+
 	var repo *models.Repository
 	updates := make([]*repo_module.PushUpdateOptions, 0, len(opts.OldCommitIDs))
 	wasEmpty := false
@@ -423,7 +526,7 @@ func HookPostReceive(ctx *gitea_context.PrivateContext) {
 	}
 
 	if repo != nil && len(updates) > 0 {
-		if err := repo_service.PushUpdates(updates); err != nil {
+		if err := repo_service.PushUpdates(ctx.Req.Context(), updates); err != nil {
 			log.Error("Failed to Update: %s/%s Total Updates: %d", ownerName, repoName, len(updates))
 			for i, update := range updates {
 				log.Error("Failed to Update: %s/%s Update: %d/%d: Branch: %s", ownerName, repoName, i, len(updates), update.BranchName())
@@ -559,7 +662,7 @@ func SetDefaultBranch(ctx *gitea_context.PrivateContext) {
 	}
 
 	repo.DefaultBranch = branch
-	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	gitRepo, err := git.OpenRepositoryCtx(ctx.Req.Context(), repo.RepoPath())
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"Err": fmt.Sprintf("Failed to get git repository: %s/%s Error: %v", ownerName, repoName, err),
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"reflect"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
@@ -24,8 +25,8 @@ func CheckInternalToken(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		tokens := req.Header.Get("Authorization")
 		fields := strings.Fields(tokens)
-		if len(fields) != 2 || fields[0] != "Bearer" || fields[1] != setting.InternalToken {
-			log.Debug("Forbidden attempt to access internal url: Authorization header: %s", tokens)
+		if len(fields) != 2 || fields[0] != "Bearer" || !private.VerifyInternalToken(fields[1], time.Now()) {
+			log.Debug("Forbidden attempt to access internal url %s from %s", req.URL.Path, setting.LoggedRemoteAddr(req.RemoteAddr))
 			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 		} else {
 			next.ServeHTTP(w, req)
@@ -63,11 +64,13 @@ func Routes() *web.Route {
 	r.Post("/manager/shutdown", Shutdown)
 	r.Post("/manager/restart", Restart)
 	r.Post("/manager/flush-queues", bind(private.FlushOptions{}), FlushQueues)
+	r.Post("/manager/reindex", bind(private.ReindexOptions{}), Reindex)
 	r.Post("/manager/pause-logging", PauseLogging)
 	r.Post("/manager/resume-logging", ResumeLogging)
 	r.Post("/manager/release-and-reopen-logging", ReleaseReopenLogging)
 	r.Post("/manager/add-logger", bind(private.LoggerOptions{}), AddLogger)
 	r.Post("/manager/remove-logger/{group}/{name}", RemoveLogger)
+	r.Post("/manager/maintenance", bind(private.MaintenanceOptions{}), SetMaintenanceMode)
 	r.Post("/mail/send", SendEmail)
 
 	return r
@@ -7,15 +7,21 @@ package private
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/graceful"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
+	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/maintenance"
 	"code.gitea.io/gitea/modules/private"
 	"code.gitea.io/gitea/modules/queue"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
 	jsoniter "github.com/json-iterator/go"
+	"xorm.io/builder"
 )
 
 // FlushQueues flushes all the Queues
@@ -44,12 +50,105 @@ func FlushQueues(ctx *context.PrivateContext) {
 	ctx.PlainText(http.StatusOK, []byte("success"))
 }
 
+// Reindex queues the issues or code of the requested repositories, or of all repositories, for reindexing
+func Reindex(ctx *context.PrivateContext) {
+	opts := web.GetForm(ctx).(*private.ReindexOptions)
+
+	var update func(*models.Repository)
+	switch opts.Type {
+	case "issues":
+		update = func(repo *models.Repository) {
+			issue_indexer.UpdateRepoIndexer(ctx.Req.Context(), repo)
+		}
+	case "code":
+		if !setting.Indexer.RepoIndexerEnabled {
+			ctx.JSON(http.StatusBadRequest, map[string]interface{}{
+				"err": "The code indexer is not enabled",
+			})
+			return
+		}
+		update = code_indexer.UpdateRepoIndexer
+	default:
+		ctx.JSON(http.StatusBadRequest, map[string]interface{}{
+			"err": fmt.Sprintf("Unknown indexer type: %q", opts.Type),
+		})
+		return
+	}
+
+	repos := make([]*models.Repository, 0, len(opts.Repos))
+	for _, fullName := range opts.Repos {
+		parts := strings.SplitN(fullName, "/", 2)
+		if len(parts) != 2 {
+			ctx.JSON(http.StatusBadRequest, map[string]interface{}{
+				"err": fmt.Sprintf("Repository must be given as owner/name: %q", fullName),
+			})
+			return
+		}
+		repo, err := models.GetRepositoryByOwnerAndName(parts[0], parts[1])
+		if err != nil {
+			status := http.StatusInternalServerError
+			if models.IsErrRepoNotExist(err) {
+				status = http.StatusNotFound
+			}
+			ctx.JSON(status, map[string]interface{}{
+				"err": fmt.Sprintf("Unable to get repository %s: %v", fullName, err),
+			})
+			return
+		}
+		repos = append(repos, repo)
+	}
+
+	if len(repos) > 0 {
+		for _, repo := range repos {
+			log.Info("Queueing %s of %s for reindexing", opts.Type, repo.FullName())
+			update(repo)
+		}
+		ctx.PlainText(http.StatusOK, []byte("success"))
+		return
+	}
+
+	// Save the shutdown ctx here - as a new one is created each time you call this.
+	baseCtx := graceful.GetManager().ShutdownContext()
+	go func() {
+		log.Info("Queueing %s of all repositories for reindexing", opts.Type)
+		if err := models.Iterate(
+			models.DefaultDBContext(),
+			new(models.Repository),
+			builder.Gt{"id": 0},
+			func(idx int, bean interface{}) error {
+				repo := bean.(*models.Repository)
+				select {
+				case <-baseCtx.Done():
+					return models.ErrCancelledf("before reindexing %s", repo.FullName())
+				default:
+				}
+				update(repo)
+				return nil
+			},
+		); err != nil {
+			log.Error("Reindexing %s of all repositories failed: %v", opts.Type, err)
+		}
+	}()
+	ctx.PlainText(http.StatusOK, []byte("success"))
+}
+
 // PauseLogging pauses logging
 func PauseLogging(ctx *context.PrivateContext) {
 	log.Pause()
 	ctx.PlainText(http.StatusOK, []byte("success"))
 }
 
+// SetMaintenanceMode enables or disables the maintenance mode of the instance
+func SetMaintenanceMode(ctx *context.PrivateContext) {
+	opts := web.GetForm(ctx).(*private.MaintenanceOptions)
+	if opts.Enabled {
+		maintenance.Enable(opts.Message)
+	} else {
+		maintenance.Disable()
+	}
+	ctx.PlainText(http.StatusOK, []byte("success"))
+}
+
 // ResumeLogging resumes logging
 func ResumeLogging(ctx *context.PrivateContext) {
 	log.Resume()
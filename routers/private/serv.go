@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/maintenance"
 	"code.gitea.io/gitea/modules/private"
 	"code.gitea.io/gitea/modules/setting"
 	repo_service "code.gitea.io/gitea/services/repository"
@@ -291,6 +292,16 @@ func ServCommand(ctx *context.PrivateContext) {
 		return
 	}
 
+	// Only site administrators can push while the instance is in maintenance mode
+	if mode > models.AccessModeRead && maintenance.IsEnabled() && (user == nil || !user.IsAdmin) {
+		ctx.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+			"results": results,
+			"type":    "ErrMaintenanceMode",
+			"err":     "Gitea is in maintenance mode, repositories are read-only.",
+		})
+		return
+	}
+
 	// Permissions checking:
 	if repoExist && (mode > models.AccessModeRead || repo.IsPrivate || setting.Service.RequireSignInView) {
 		if key.Type == models.KeyTypeDeploy {
@@ -357,7 +368,7 @@ func ServCommand(ctx *context.PrivateContext) {
 			return
 		}
 
-		repo, err = repo_service.PushCreateRepo(user, owner, results.RepoName)
+		repo, err = repo_service.PushCreateRepo(ctx.Req.Context(), user, owner, results.RepoName)
 		if err != nil {
 			log.Error("pushCreateRepo: %v", err)
 			ctx.JSON(http.StatusNotFound, map[string]interface{}{
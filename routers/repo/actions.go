@@ -0,0 +1,240 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	actions_service "code.gitea.io/gitea/services/actions"
+)
+
+const (
+	tplActions    base.TplName = "repo/actions/list"
+	tplActionsRun base.TplName = "repo/actions/view"
+
+	// maxInlineJobLogSize is the size above which a job log is not shown on the page of the run
+	maxInlineJobLogSize = 1024 * 1024
+)
+
+// MustEnableActions check if actions are enabled in settings
+func MustEnableActions(ctx *context.Context) {
+	if !setting.Actions.Enabled {
+		ctx.NotFound("MustEnableActions", nil)
+		return
+	}
+	ctx.Data["PageIsActions"] = true
+}
+
+// Actions renders the runs of the workflows of a repository
+func Actions(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.actions")
+
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	opts := &models.FindActionRunsOptions{
+		ListOptions: models.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.IssuePagingNum,
+		},
+		RepoID:     ctx.Repo.Repository.ID,
+		WorkflowID: ctx.Query("workflow"),
+		Status:     models.ActionStatusFromString(ctx.Query("status")),
+	}
+
+	runs, count, err := models.FindActionRuns(opts)
+	if err != nil {
+		ctx.ServerError("FindActionRuns", err)
+		return
+	}
+	for _, run := range runs {
+		run.Repo = ctx.Repo.Repository
+		if err := run.LoadAttributes(); err != nil {
+			ctx.ServerError("LoadAttributes", err)
+			return
+		}
+	}
+	workflows, err := models.GetActionWorkflowIDs(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetActionWorkflowIDs", err)
+		return
+	}
+
+	ctx.Data["Runs"] = runs
+	ctx.Data["Workflows"] = workflows
+	ctx.Data["CurWorkflow"] = opts.WorkflowID
+	ctx.Data["WorkflowsDir"] = actions_service.WorkflowsDir
+
+	pager := context.NewPagination(int(count), opts.PageSize, page, 5)
+	pager.AddParam(ctx, "workflow", "CurWorkflow")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplActions)
+}
+
+// getActionRun returns the run in the path or writes an error
+func getActionRun(ctx *context.Context) *models.ActionRun {
+	run, err := models.GetActionRunByRepoAndID(ctx.Repo.Repository.ID, ctx.ParamsInt64("run"))
+	if err != nil {
+		if models.IsErrActionRunNotExist(err) {
+			ctx.NotFound("GetActionRunByRepoAndID", err)
+		} else {
+			ctx.ServerError("GetActionRunByRepoAndID", err)
+		}
+		return nil
+	}
+	run.Repo = ctx.Repo.Repository
+	if err := run.LoadAttributes(); err != nil {
+		ctx.ServerError("LoadAttributes", err)
+		return nil
+	}
+	return run
+}
+
+// ViewActionRun renders a run with its jobs, the log of the selected job and its artifacts
+func ViewActionRun(ctx *context.Context) {
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Title"] = run.Title + " - " + ctx.Tr("repo.actions")
+
+	jobs, err := models.GetActionRunJobs(run.ID)
+	if err != nil {
+		ctx.ServerError("GetActionRunJobs", err)
+		return
+	}
+	var curJob *models.ActionRunJob
+	if len(jobs) > 0 {
+		curJob = jobs[0]
+	}
+	for _, job := range jobs {
+		if job.ID == ctx.QueryInt64("job") {
+			curJob = job
+		}
+	}
+	if curJob != nil && curJob.LogSize <= maxInlineJobLogSize {
+		rc, err := actions_service.OpenJobLog(curJob)
+		if err != nil {
+			ctx.ServerError("OpenJobLog", err)
+			return
+		}
+		defer rc.Close()
+		content, err := ioutil.ReadAll(io.LimitReader(rc, maxInlineJobLogSize))
+		if err != nil {
+			ctx.ServerError("ReadAll", err)
+			return
+		}
+		ctx.Data["JobLog"] = string(content)
+	}
+
+	artifacts, err := models.GetActionArtifactsByRunID(run.ID)
+	if err != nil {
+		ctx.ServerError("GetActionArtifactsByRunID", err)
+		return
+	}
+
+	ctx.Data["Run"] = run
+	ctx.Data["Jobs"] = jobs
+	ctx.Data["CurJob"] = curJob
+	ctx.Data["Artifacts"] = artifacts
+	ctx.Data["CanCancel"] = !run.Status.IsDone() && ctx.Repo.CanWrite(models.UnitTypeCode)
+	ctx.Data["CanApprove"] = run.NeedApproval && !run.Status.IsDone() && ctx.Repo.CanWrite(models.UnitTypeCode)
+	ctx.HTML(http.StatusOK, tplActionsRun)
+}
+
+// ActionJobLogs serves the raw log of a job
+func ActionJobLogs(ctx *context.Context) {
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	job, err := models.GetActionRunJobByID(ctx.ParamsInt64("job"))
+	if err != nil || job.RunID != run.ID {
+		if err == nil || models.IsErrActionRunJobNotExist(err) {
+			ctx.NotFound("GetActionRunJobByID", err)
+		} else {
+			ctx.ServerError("GetActionRunJobByID", err)
+		}
+		return
+	}
+
+	rc, err := actions_service.OpenJobLog(job)
+	if err != nil {
+		ctx.ServerError("OpenJobLog", err)
+		return
+	}
+	defer rc.Close()
+	ctx.Resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	ctx.Resp.Header().Set("Content-Length", fmt.Sprintf("%d", job.LogSize))
+	if _, err := io.Copy(ctx.Resp, rc); err != nil {
+		ctx.ServerError("Copy", err)
+	}
+}
+
+// DownloadActionArtifact serves an artifact of a run
+func DownloadActionArtifact(ctx *context.Context) {
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	artifact, err := models.GetActionArtifactByRunAndID(run.ID, ctx.ParamsInt64("artifact"))
+	if err != nil {
+		if models.IsErrActionArtifactNotExist(err) {
+			ctx.NotFound("GetActionArtifactByRunAndID", err)
+		} else {
+			ctx.ServerError("GetActionArtifactByRunAndID", err)
+		}
+		return
+	}
+
+	fr, err := storage.Actions.Open(artifact.RelativePath())
+	if err != nil {
+		ctx.ServerError("Open", err)
+		return
+	}
+	defer fr.Close()
+
+	if err = ServeData(ctx, artifact.Name, artifact.Size, fr); err != nil {
+		ctx.ServerError("ServeData", err)
+	}
+}
+
+// CancelActionRun cancels the jobs of a run which have not finished yet
+func CancelActionRun(ctx *context.Context) {
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := actions_service.CancelRun(run); err != nil {
+		ctx.ServerError("CancelRun", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.actions.run_cancelled"))
+	ctx.Redirect(run.Link())
+}
+
+// ApproveActionRun approves a run of a pull request from a fork, so its jobs are run
+func ApproveActionRun(ctx *context.Context) {
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := actions_service.ApproveRun(run, ctx.User); err != nil {
+		ctx.ServerError("ApproveRun", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.actions.run_approved"))
+	ctx.Redirect(run.Link())
+}
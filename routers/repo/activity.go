@@ -5,6 +5,7 @@
 package repo
 
 import (
+	"net/http"
 	"time"
 
 	"code.gitea.io/gitea/models"
@@ -13,7 +14,8 @@ import (
 )
 
 const (
-	tplActivity base.TplName = "repo/activity"
+	tplActivity      base.TplName = "repo/activity"
+	tplIssueInsights base.TplName = "repo/issue_insights"
 )
 
 // Activity render the page to show repository latest changes
@@ -100,3 +102,52 @@ func ActivityAuthors(ctx *context.Context) {
 
 	ctx.JSON(200, authors)
 }
+
+// IssueInsights renders the usage of issue templates and labels and the response times of the issues
+func IssueInsights(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.insights.issues")
+	ctx.Data["PageIsActivity"] = true
+
+	period := ctx.Query("period")
+	timeFrom, ok := models.IssueInsightsPeriodStart(period)
+	if !ok {
+		period = models.DefaultIssueInsightsPeriod
+		timeFrom, _ = models.IssueInsightsPeriodStart(period)
+	}
+	ctx.Data["Period"] = period
+	ctx.Data["PeriodText"] = ctx.Tr("repo.activity.period." + period)
+
+	insights, err := models.GetIssueInsights(ctx.Repo.Repository.ID, timeFrom)
+	if err != nil {
+		ctx.ServerError("GetIssueInsights", err)
+		return
+	}
+	ctx.Data["Insights"] = insights
+	ctx.Data["DateFrom"] = insights.From.Format("January 2, 2006")
+	ctx.Data["DateUntil"] = insights.Until.Format("January 2, 2006")
+	ctx.Data["AvgFirstResponse"] = int64(insights.AvgFirstResponse.Seconds())
+	ctx.Data["AvgTimeToClose"] = int64(insights.AvgTimeToClose.Seconds())
+
+	// the heights of the bars which show the weekly label usage, in percent of the busiest week of all labels
+	var maxWeekly int64
+	for _, usage := range insights.Labels {
+		for _, n := range usage.Weekly {
+			if n > maxWeekly {
+				maxWeekly = n
+			}
+		}
+	}
+	labelBars := make(map[int64][]int64, len(insights.Labels))
+	for _, usage := range insights.Labels {
+		bars := make([]int64, len(usage.Weekly))
+		for i, n := range usage.Weekly {
+			if maxWeekly > 0 {
+				bars[i] = n * 100 / maxWeekly
+			}
+		}
+		labelBars[usage.Label.ID] = bars
+	}
+	ctx.Data["LabelBars"] = labelBars
+
+	ctx.HTML(http.StatusOK, tplIssueInsights)
+}
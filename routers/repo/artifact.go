@@ -0,0 +1,68 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+)
+
+// MustEnableArtifacts check if artifacts are enabled in settings
+func MustEnableArtifacts(ctx *context.Context) {
+	if !setting.Artifacts.Enabled {
+		ctx.NotFound("MustEnableArtifacts", nil)
+		return
+	}
+}
+
+// DownloadArtifact serves an artifact uploaded for a commit of the repository
+func DownloadArtifact(ctx *context.Context) {
+	artifact, err := models.GetRepoArtifactByID(ctx.Repo.Repository.ID, ctx.ParamsInt64("id"))
+	if err != nil {
+		if models.IsErrRepoArtifactNotExist(err) {
+			ctx.NotFound("GetRepoArtifactByID", err)
+		} else {
+			ctx.ServerError("GetRepoArtifactByID", err)
+		}
+		return
+	}
+	ServeArtifact(ctx, artifact)
+}
+
+// ServeArtifact serves the content of an artifact and counts the download
+func ServeArtifact(ctx *context.Context, artifact *models.RepoArtifact) {
+	if setting.Artifacts.ServeDirect {
+		//If we have a signed url (S3, object storage), redirect to this directly.
+		u, err := storage.Artifacts.URL(artifact.RelativePath(), artifact.Name)
+
+		if u != nil && err == nil {
+			if err := artifact.IncreaseDownloadCount(); err != nil {
+				ctx.ServerError("IncreaseDownloadCount", err)
+				return
+			}
+
+			ctx.Redirect(u.String())
+			return
+		}
+	}
+
+	fr, err := storage.Artifacts.Open(artifact.RelativePath())
+	if err != nil {
+		ctx.ServerError("Open", err)
+		return
+	}
+	defer fr.Close()
+
+	if err := artifact.IncreaseDownloadCount(); err != nil {
+		ctx.ServerError("IncreaseDownloadCount", err)
+		return
+	}
+
+	if err = ServeData(ctx, artifact.Name, artifact.Size, fr); err != nil {
+		ctx.ServerError("ServeData", err)
+	}
+}
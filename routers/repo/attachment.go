@@ -14,20 +14,21 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/upload"
+	"code.gitea.io/gitea/modules/virusscan"
 )
 
 // UploadIssueAttachment response for Issue/PR attachments
 func UploadIssueAttachment(ctx *context.Context) {
-	uploadAttachment(ctx, setting.Attachment.AllowedTypes)
+	uploadAttachment(ctx, models.VirusDetectionKindAttachment, setting.Attachment.AllowedTypes)
 }
 
 // UploadReleaseAttachment response for uploading release attachments
 func UploadReleaseAttachment(ctx *context.Context) {
-	uploadAttachment(ctx, setting.Repository.Release.AllowedTypes)
+	uploadAttachment(ctx, models.VirusDetectionKindRelease, setting.Repository.Release.AllowedTypes)
 }
 
 // UploadAttachment response for uploading attachments
-func uploadAttachment(ctx *context.Context, allowedTypes string) {
+func uploadAttachment(ctx *context.Context, kind models.VirusDetectionKind, allowedTypes string) {
 	if !setting.Attachment.Enabled {
 		ctx.Error(404, "attachment is not enabled")
 		return
@@ -52,11 +53,19 @@ func uploadAttachment(ctx *context.Context, allowedTypes string) {
 		return
 	}
 
-	attach, err := models.NewAttachment(&models.Attachment{
+	var repoID int64
+	if ctx.Repo != nil && ctx.Repo.Repository != nil {
+		repoID = ctx.Repo.Repository.ID
+	}
+	attach, err := virusscan.NewAttachment(ctx.Req.Context(), kind, repoID, &models.Attachment{
 		UploaderID: ctx.User.ID,
 		Name:       header.Filename,
 	}, buf, file)
 	if err != nil {
+		if virusscan.IsErrVirusFound(err) {
+			ctx.Error(400, ctx.Tr("upload_virus_found", header.Filename))
+			return
+		}
 		ctx.Error(500, fmt.Sprintf("NewAttachment: %v", err))
 		return
 	}
@@ -119,8 +128,18 @@ func GetAttachment(ctx *context.Context) {
 			return
 		}
 		if !perm.CanRead(unitType) {
-			ctx.Error(http.StatusNotFound)
-			return
+			isCollaborator := false
+			if attach.IssueID > 0 && ctx.IsSigned && repository.UnitEnabled(unitType) {
+				isCollaborator, err = models.IsIssueCollaborator(attach.IssueID, ctx.User.ID)
+				if err != nil {
+					ctx.ServerError("IsIssueCollaborator", err)
+					return
+				}
+			}
+			if !isCollaborator {
+				ctx.Error(http.StatusNotFound)
+				return
+			}
 		}
 	}
 
@@ -146,7 +146,7 @@ func RestoreBranchPost(ctx *context.Context) {
 	}
 
 	// Don't return error below this
-	if err := repo_service.PushUpdate(
+	if err := repo_service.PushUpdate(ctx.Req.Context(),
 		&repo_module.PushUpdateOptions{
 			RefFullName:  git.BranchPrefix + deletedBranch.Name,
 			OldCommitID:  git.EmptySHA,
@@ -183,7 +183,7 @@ func deleteBranch(ctx *context.Context, branchName string) error {
 	}
 
 	// Don't return error below this
-	if err := repo_service.PushUpdate(
+	if err := repo_service.PushUpdate(ctx.Req.Context(),
 		&repo_module.PushUpdateOptions{
 			RefFullName:  git.BranchPrefix + branchName,
 			OldCommitID:  commit.ID.String(),
@@ -316,7 +316,7 @@ func loadOneBranch(ctx *context.Context, rawBranch *git.Branch, protectedBranche
 		if pr.HasMerged {
 			baseGitRepo, ok := repoIDToGitRepo[pr.BaseRepoID]
 			if !ok {
-				baseGitRepo, err = git.OpenRepository(pr.BaseRepo.RepoPath())
+				baseGitRepo, err = git.OpenRepositoryCtx(ctx.Req.Context(), pr.BaseRepo.RepoPath())
 				if err != nil {
 					ctx.ServerError("OpenRepository", err)
 					return nil
@@ -387,9 +387,9 @@ func CreateBranch(ctx *context.Context) {
 
 	if form.CreateTag {
 		if ctx.Repo.IsViewTag {
-			err = release_service.CreateNewTag(ctx.User, ctx.Repo.Repository, ctx.Repo.CommitID, form.NewBranchName, "")
+			err = release_service.CreateNewTag(ctx.Req.Context(), ctx.User, ctx.Repo.Repository, ctx.Repo.CommitID, form.NewBranchName, "")
 		} else {
-			err = release_service.CreateNewTag(ctx.User, ctx.Repo.Repository, ctx.Repo.BranchName, form.NewBranchName, "")
+			err = release_service.CreateNewTag(ctx.Req.Context(), ctx.User, ctx.Repo.Repository, ctx.Repo.BranchName, form.NewBranchName, "")
 		}
 	} else if ctx.Repo.IsViewBranch {
 		err = repo_module.CreateNewBranch(ctx.User, ctx.Repo.Repository, ctx.Repo.BranchName, form.NewBranchName)
@@ -272,7 +272,7 @@ func Diff(ctx *context.Context) {
 	)
 
 	if ctx.Data["PageIsWiki"] != nil {
-		gitRepo, err = git.OpenRepository(ctx.Repo.Repository.WikiPath())
+		gitRepo, err = git.OpenRepositoryCtx(ctx.Req.Context(), ctx.Repo.Repository.WikiPath())
 		if err != nil {
 			ctx.ServerError("Repo.GitRepo.GetCommit", err)
 			return
@@ -304,6 +304,18 @@ func Diff(ctx *context.Context) {
 	ctx.Data["CommitStatus"] = models.CalcCommitStatus(statuses)
 	ctx.Data["CommitStatuses"] = statuses
 
+	if ctx.Data["PageIsWiki"] == nil && setting.Artifacts.Enabled {
+		artifacts, _, err := models.FindRepoArtifacts(&models.FindRepoArtifactsOptions{
+			RepoID:     ctx.Repo.Repository.ID,
+			CommitSHAs: []string{commitID},
+		})
+		if err != nil {
+			ctx.ServerError("FindRepoArtifacts", err)
+			return
+		}
+		ctx.Data["Artifacts"] = artifacts
+	}
+
 	diff, err := gitdiff.GetDiffCommitWithWhitespaceBehavior(repoPath,
 		commitID, setting.Git.MaxGitDiffLines,
 		setting.Git.MaxGitDiffLineCharacters, setting.Git.MaxGitDiffFiles,
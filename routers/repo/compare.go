@@ -259,7 +259,7 @@ func ParseCompareInfo(ctx *context.Context) (*models.User, *models.Repository, *
 		headRepo = ctx.Repo.Repository
 		headGitRepo = ctx.Repo.GitRepo
 	} else if has {
-		headGitRepo, err = git.OpenRepository(headRepo.RepoPath())
+		headGitRepo, err = git.OpenRepositoryCtx(ctx.Req.Context(), headRepo.RepoPath())
 		if err != nil {
 			ctx.ServerError("OpenRepository", err)
 			return nil, nil, nil, nil, "", ""
@@ -315,7 +315,7 @@ func ParseCompareInfo(ctx *context.Context) (*models.User, *models.Repository, *
 	if rootRepo != nil &&
 		rootRepo.ID != headRepo.ID &&
 		rootRepo.ID != baseRepo.ID {
-		perm, branches, err := getBranchesForRepo(ctx.User, rootRepo)
+		perm, branches, err := getBranchesForRepo(ctx, ctx.User, rootRepo)
 		if err != nil {
 			ctx.ServerError("GetBranchesForRepo", err)
 			return nil, nil, nil, nil, "", ""
@@ -335,7 +335,7 @@ func ParseCompareInfo(ctx *context.Context) (*models.User, *models.Repository, *
 		ownForkRepo.ID != headRepo.ID &&
 		ownForkRepo.ID != baseRepo.ID &&
 		(rootRepo == nil || ownForkRepo.ID != rootRepo.ID) {
-		perm, branches, err := getBranchesForRepo(ctx.User, ownForkRepo)
+		perm, branches, err := getBranchesForRepo(ctx, ctx.User, ownForkRepo)
 		if err != nil {
 			ctx.ServerError("GetBranchesForRepo", err)
 			return nil, nil, nil, nil, "", ""
@@ -493,7 +493,7 @@ func PrepareCompareDiff(
 	return false
 }
 
-func getBranchesForRepo(user *models.User, repo *models.Repository) (bool, []string, error) {
+func getBranchesForRepo(ctx *context.Context, user *models.User, repo *models.Repository) (bool, []string, error) {
 	perm, err := models.GetUserRepoPermission(repo, user)
 	if err != nil {
 		return false, nil, err
@@ -501,7 +501,7 @@ func getBranchesForRepo(user *models.User, repo *models.Repository) (bool, []str
 	if !perm.CanRead(models.UnitTypeCode) {
 		return false, nil, nil
 	}
-	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	gitRepo, err := git.OpenRepositoryCtx(ctx.Req.Context(), repo.RepoPath())
 	if err != nil {
 		return false, nil, err
 	}
@@ -568,7 +568,15 @@ func CompareDiff(ctx *context.Context) {
 	ctx.Data["RequireTribute"] = true
 	ctx.Data["RequireSimpleMDE"] = true
 	ctx.Data["PullRequestWorkInProgressPrefixes"] = setting.Repository.PullRequest.WorkInProgressPrefixes
-	setTemplateIfExists(ctx, pullRequestTemplateKey, nil, pullRequestTemplateCandidates)
+	templateName := ctx.Query("template")
+	setTemplateIfExists(ctx, pullRequestTemplateKey, templateName, pullRequestTemplateDirCandidates, pullRequestTemplateCandidates)
+	templates := pullRequestTemplatesFromDefaultBranch(ctx)
+	ctx.Data["PullRequestTemplates"] = templates
+	for _, name := range templates {
+		if name == templateName {
+			ctx.Data["PullRequestTemplateFile"] = name
+		}
+	}
 	ctx.Data["IsAttachmentEnabled"] = setting.Attachment.Enabled
 	upload.AddUploadContext(ctx, "comment")
 
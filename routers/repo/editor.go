@@ -47,19 +47,13 @@ func renderCommitRights(ctx *context.Context) bool {
 	return canCommitToBranch.CanCommitToBranch
 }
 
-// getParentTreeFields returns list of parent tree names and corresponding tree paths
-// based on given tree path.
-func getParentTreeFields(treePath string) (treeNames []string, treePaths []string) {
-	if len(treePath) == 0 {
-		return treeNames, treePaths
-	}
-
-	treeNames = strings.Split(treePath, "/")
-	treePaths = make([]string, len(treeNames))
-	for i := range treeNames {
-		treePaths[i] = strings.Join(treeNames[:i+1], "/")
+// splitTreePath splits a tree path into the path of its parent directory and its name, the part the user can edit in
+// the breadcrumb of the editor.
+func splitTreePath(treePath string) (parentTreePath, treeName string) {
+	if idx := strings.LastIndex(treePath, "/"); idx >= 0 {
+		return treePath[:idx], treePath[idx+1:]
 	}
-	return treeNames, treePaths
+	return "", treePath
 }
 
 func editFile(ctx *context.Context, isNewFile bool) {
@@ -79,7 +73,7 @@ func editFile(ctx *context.Context, isNewFile bool) {
 		return
 	}
 
-	treeNames, treePaths := getParentTreeFields(ctx.Repo.TreePath)
+	parentTreePath, treeName := splitTreePath(ctx.Repo.TreePath)
 
 	if !isNewFile {
 		entry, err := ctx.Repo.Commit.GetTreeEntryByPath(ctx.Repo.TreePath)
@@ -129,11 +123,12 @@ func editFile(ctx *context.Context, isNewFile bool) {
 			ctx.Data["FileContent"] = content
 		}
 	} else {
-		treeNames = append(treeNames, "") // Append empty string to allow user name the new file.
+		// the user names the new file in the directory
+		parentTreePath, treeName = ctx.Repo.TreePath, ""
 	}
 
-	ctx.Data["TreeNames"] = treeNames
-	ctx.Data["TreePaths"] = treePaths
+	ctx.Data["ParentTreePath"] = parentTreePath
+	ctx.Data["TreeName"] = treeName
 	ctx.Data["BranchLink"] = ctx.Repo.RepoLink + "/src/" + ctx.Repo.BranchNameSubURL()
 	ctx.Data["commit_summary"] = ""
 	ctx.Data["commit_message"] = ""
@@ -178,7 +173,7 @@ func NewFile(ctx *context.Context) {
 
 func editFilePost(ctx *context.Context, form auth.EditRepoFileForm, isNewFile bool) {
 	canCommit := renderCommitRights(ctx)
-	treeNames, treePaths := getParentTreeFields(form.TreePath)
+	parentTreePath, treeName := splitTreePath(form.TreePath)
 	branchName := ctx.Repo.BranchName
 	if form.CommitChoice == frmCommitChoiceNewBranch {
 		branchName = form.NewBranchName
@@ -190,8 +185,8 @@ func editFilePost(ctx *context.Context, form auth.EditRepoFileForm, isNewFile bo
 	ctx.Data["RequireHighlightJS"] = true
 	ctx.Data["RequireSimpleMDE"] = true
 	ctx.Data["TreePath"] = form.TreePath
-	ctx.Data["TreeNames"] = treeNames
-	ctx.Data["TreePaths"] = treePaths
+	ctx.Data["ParentTreePath"] = parentTreePath
+	ctx.Data["TreeName"] = treeName
 	ctx.Data["BranchLink"] = ctx.Repo.RepoLink + "/src/branch/" + ctx.Repo.BranchName
 	ctx.Data["FileContent"] = form.Content
 	ctx.Data["commit_summary"] = form.CommitSummary
@@ -542,14 +537,9 @@ func UploadFile(ctx *context.Context) {
 	}
 	ctx.Repo.TreePath = treePath
 
-	treeNames, treePaths := getParentTreeFields(ctx.Repo.TreePath)
-	if len(treeNames) == 0 {
-		// We must at least have one element for user to input.
-		treeNames = []string{""}
-	}
-
-	ctx.Data["TreeNames"] = treeNames
-	ctx.Data["TreePaths"] = treePaths
+	parentTreePath, treeName := splitTreePath(ctx.Repo.TreePath)
+	ctx.Data["ParentTreePath"] = parentTreePath
+	ctx.Data["TreeName"] = treeName
 	ctx.Data["BranchLink"] = ctx.Repo.RepoLink + "/src/" + ctx.Repo.BranchNameSubURL()
 	ctx.Data["commit_summary"] = ""
 	ctx.Data["commit_message"] = ""
@@ -581,15 +571,10 @@ func UploadFilePost(ctx *context.Context) {
 
 	form.TreePath = cleanUploadFileName(form.TreePath)
 
-	treeNames, treePaths := getParentTreeFields(form.TreePath)
-	if len(treeNames) == 0 {
-		// We must at least have one element for user to input.
-		treeNames = []string{""}
-	}
-
+	parentTreePath, treeName := splitTreePath(form.TreePath)
 	ctx.Data["TreePath"] = form.TreePath
-	ctx.Data["TreeNames"] = treeNames
-	ctx.Data["TreePaths"] = treePaths
+	ctx.Data["ParentTreePath"] = parentTreePath
+	ctx.Data["TreeName"] = treeName
 	ctx.Data["BranchLink"] = ctx.Repo.RepoLink + "/src/branch/" + branchName
 	ctx.Data["commit_summary"] = form.CommitSummary
 	ctx.Data["commit_message"] = form.CommitMessage
@@ -615,7 +600,7 @@ func UploadFilePost(ctx *context.Context) {
 	}
 
 	var newTreePath string
-	for _, part := range treeNames {
+	for _, part := range strings.Split(form.TreePath, "/") {
 		newTreePath = path.Join(newTreePath, part)
 		entry, err := ctx.Repo.Commit.GetTreeEntryByPath(newTreePath)
 		if err != nil {
@@ -711,6 +696,8 @@ func UploadFilePost(ctx *context.Context) {
 		return
 	}
 
+	flashLargeFiles(ctx, ctx.Repo.CommitID, branchName)
+
 	if form.CommitChoice == frmCommitChoiceNewBranch && ctx.Repo.Repository.UnitEnabled(models.UnitTypePullRequests) {
 		ctx.Redirect(ctx.Repo.RepoLink + "/compare/" + util.PathEscapeSegments(ctx.Repo.BranchName) + "..." + util.PathEscapeSegments(form.NewBranchName))
 	} else {
@@ -718,6 +705,39 @@ func UploadFilePost(ctx *context.Context) {
 	}
 }
 
+// flashLargeFiles warns about files above the configured size which were added to branch after oldCommitID
+func flashLargeFiles(ctx *context.Context, oldCommitID, branch string) {
+	if setting.Repository.LargeFile.MaxSize <= 0 {
+		return
+	}
+
+	newCommitID, err := ctx.Repo.GitRepo.GetBranchCommitID(branch)
+	if err != nil {
+		log.Error("GetBranchCommitID(%s): %v", branch, err)
+		return
+	}
+	revs := []string{newCommitID}
+	if oldCommitID != "" {
+		revs = append(revs, "^"+oldCommitID)
+	}
+
+	threshold := setting.Repository.LargeFile.MaxSize * 1024 * 1024
+	files, err := git.FindLargeFiles(ctx.Repo.Repository.RepoPath(), nil, threshold, revs...)
+	if err != nil {
+		log.Error("FindLargeFiles: %v", err)
+		return
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	ctx.Flash.Warning(ctx.Tr("repo.editor.upload_large_files", base.FileSize(threshold), strings.Join(paths, ", ")))
+}
+
 func cleanUploadFileName(name string) string {
 	// Rebase the filename
 	name = strings.Trim(path.Clean("/"+name), " /")
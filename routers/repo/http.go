@@ -10,6 +10,7 @@ import (
 	"compress/gzip"
 	gocontext "context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -32,6 +33,7 @@ import (
 	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
+	archiver_service "code.gitea.io/gitea/services/archiver"
 	repo_service "code.gitea.io/gitea/services/repository"
 )
 
@@ -94,6 +96,11 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 		isPull = (ctx.Req.Method == "GET")
 	}
 
+	if isPull && setting.Git.RequireWireProtocolV2 && !isWireProtocolV2(ctx.Req) {
+		ctx.HandleText(http.StatusForbidden, "Git wire protocol version 2 is required to fetch from this server, please use git 2.26 or newer.")
+		return
+	}
+
 	var accessMode models.AccessMode
 	if isPull {
 		accessMode = models.AccessModeRead
@@ -168,6 +175,11 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 		askAuth = askAuth || (repo.Owner.Visibility != structs.VisibleTypePublic)
 	}
 
+	// the token of a running actions job allows to clone the repository of the job
+	if askAuth && isPull && !isWiki && repoExist && isActionRunJobAuth(ctx, repo) {
+		askAuth = false
+	}
+
 	// check access
 	if askAuth {
 		authUsername = ctx.Req.Header.Get(setting.ReverseProxyAuthUser)
@@ -329,7 +341,7 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 			return
 		}
 
-		repo, err = repo_service.PushCreateRepo(authUser, owner, reponame)
+		repo, err = repo_service.PushCreateRepo(ctx.Req.Context(), authUser, owner, reponame)
 		if err != nil {
 			log.Error("pushCreateRepo: %v", err)
 			ctx.Status(http.StatusNotFound)
@@ -351,6 +363,10 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 	}
 
 	environ = append(environ, models.EnvRepoID+fmt.Sprintf("=%d", repo.ID))
+	if requestID := log.RequestIDFromContext(ctx.Req.Context()); requestID != "" {
+		// the hooks pass the request ID on to the internal API
+		environ = append(environ, models.EnvRequestID+"="+requestID)
+	}
 
 	w := ctx.Resp
 	r := ctx.Req
@@ -359,6 +375,9 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 		ReceivePack: true,
 		Env:         environ,
 	}
+	if service == "git-upload-pack" || strings.HasSuffix(ctx.Req.URL.Path, "git-upload-pack") {
+		cfg.UploadPackArgs = uploadPackArgs(ctx.Req.Context(), repo, isWiki)
+	}
 
 	r.URL.Path = strings.ToLower(r.URL.Path) // blue: In case some repo name has upper case name
 
@@ -367,6 +386,31 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 	return &serviceHandler{cfg, w, r, dir, cfg.Env}
 }
 
+// isActionRunJobAuth returns true if the request is authenticated with the token of a running
+// actions job of the repository, sent as the username or password of basic authentication
+func isActionRunJobAuth(ctx *context.Context, repo *models.Repository) bool {
+	auths := strings.Fields(ctx.Req.Header.Get("Authorization"))
+	if len(auths) != 2 || auths[0] != "Basic" {
+		return false
+	}
+	username, password, err := base.BasicAuthDecode(auths[1])
+	if err != nil {
+		return false
+	}
+	token := password
+	if len(password) == 0 || password == "x-oauth-basic" {
+		token = username
+	}
+	job, err := models.GetActionRunJobByToken(token)
+	if err != nil {
+		if !models.IsErrActionRunJobNotExist(err) {
+			log.Error("GetActionRunJobByToken: %v", err)
+		}
+		return false
+	}
+	return job.RepoID == repo.ID
+}
+
 var (
 	infoRefsCache []byte
 	infoRefsOnce  sync.Once
@@ -413,6 +457,51 @@ type serviceConfig struct {
 	UploadPack  bool
 	ReceivePack bool
 	Env         []string
+	// UploadPackArgs are the global git arguments of upload-pack
+	UploadPackArgs []string
+}
+
+// uploadPackArgs returns the global git arguments which enable partial clones and advertise the
+// bundle of the default branch of the repository to the clients of upload-pack, as configured
+func uploadPackArgs(ctx gocontext.Context, repo *models.Repository, isWiki bool) []string {
+	var args []string
+	if !setting.Git.DisablePartialClone {
+		args = append(args, git.PartialCloneArgs()...)
+	}
+	if setting.Git.EnableBundleURI && !isWiki {
+		if uri := bundleURI(ctx, repo); uri != "" {
+			args = append(args, git.BundleURIArgs(uri)...)
+		}
+	}
+	return args
+}
+
+// bundleURI returns the URL of the bundle of the default branch of a repository if it has been
+// generated in the repository archive storage. Otherwise the bundle is enqueued to be generated
+// for the later clones and an empty string is returned.
+func bundleURI(ctx gocontext.Context, repo *models.Repository) string {
+	if repo.IsEmpty || repo.DefaultBranch == "" || git.CheckGitVersionAtLeast("2.40") != nil {
+		return ""
+	}
+	gitRepo, err := git.OpenRepositoryCtx(ctx, repo.RepoPath())
+	if err != nil {
+		log.Error("OpenRepository[%s]: %v", repo.RepoPath(), err)
+		return ""
+	}
+	defer gitRepo.Close()
+
+	aReq, err := archiver_service.NewRequest(repo.ID, gitRepo, repo.DefaultBranch+".bundle")
+	if err != nil {
+		if !archiver_service.IsErrRefNotFound(err) {
+			log.Error("Unable to find the bundle of %-v: %v", repo, err)
+		}
+		return ""
+	}
+	if !aReq.IsComplete() {
+		archiver_service.ArchiveRepository(aReq)
+		return ""
+	}
+	return repo.HTMLURL() + "/archive/" + util.PathEscapeSegments(aReq.GetArchiveName())
 }
 
 type serviceHandler struct {
@@ -455,6 +544,19 @@ func (h *serviceHandler) sendFile(contentType, file string) {
 // one or more key=value pairs separated by colons
 var safeGitProtocolHeader = regexp.MustCompile(`^[0-9a-zA-Z]+=[0-9a-zA-Z]+(:[0-9a-zA-Z]+=[0-9a-zA-Z]+)*$`)
 
+// isWireProtocolV2 returns whether the client has asked for the wire protocol version 2
+func isWireProtocolV2(r *http.Request) bool {
+	for _, param := range strings.Split(r.Header.Get("Git-Protocol"), ":") {
+		if param == "version=2" {
+			return true
+		}
+	}
+	return false
+}
+
+// maxCachedRequestSize is the size of the biggest upload-pack request whose response is cached
+const maxCachedRequestSize = 1024 * 1024
+
 func getGitConfig(option, dir string) string {
 	out, err := git.NewCommand("config", option).RunInDir(dir)
 	if err != nil {
@@ -529,23 +631,67 @@ func serviceRPC(h serviceHandler, service string) {
 		h.environ = append(h.environ, "GIT_PROTOCOL="+protocol)
 	}
 
-	ctx, cancel := gocontext.WithCancel(git.DefaultContext)
+	ctx, cancel := gocontext.WithCancel(h.r.Context())
 	defer cancel()
 	var stderr bytes.Buffer
-	cmd := exec.CommandContext(ctx, git.GitExecutable, service, "--stateless-rpc", h.dir)
+	log.LogWithContext(ctx, 0, log.TRACE, "Serve RPC(%s) in %s", service, h.dir)
+	var args []string
+	if service == "upload-pack" {
+		args = append(args, h.cfg.UploadPackArgs...)
+	}
+	args = append(args, service, "--stateless-rpc", h.dir)
+	cmd := exec.CommandContext(ctx, git.GitExecutable, args...)
 	cmd.Dir = h.dir
 	cmd.Env = append(os.Environ(), h.environ...)
 	cmd.Stdout = h.w
 	cmd.Stdin = reqBody
 	cmd.Stderr = &stderr
 
+	// The packs sent for the final requests of fetches of the wire protocol version 2 only depend on the requests
+	var cacheWriter *git.PackCacheWriter
+	if service == "upload-pack" && git.DefaultPackCache != nil && isWireProtocolV2(h.r) {
+		request, err := ioutil.ReadAll(io.LimitReader(reqBody, maxCachedRequestSize+1))
+		if err != nil {
+			log.LogWithContext(ctx, 0, log.ERROR, "Fail to read the RPC(%s) request in %s: %v", service, h.dir, err)
+			h.w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		cmd.Stdin = io.MultiReader(bytes.NewReader(request), reqBody)
+
+		if len(request) <= maxCachedRequestSize && git.IsCacheableFetchRequest(request) {
+			key := git.PackCacheKey(h.dir, h.r.Header.Get("Git-Protocol"), h.cfg.UploadPackArgs, request)
+			cached, generation, ok := git.DefaultPackCache.Get(h.dir, key)
+			if ok {
+				defer cached.Close()
+				log.LogWithContext(ctx, 0, log.TRACE, "Serve RPC(%s) in %s from the pack cache", service, h.dir)
+				if _, err := io.Copy(h.w, cached); err != nil {
+					log.LogWithContext(ctx, 0, log.ERROR, "Fail to serve RPC(%s) in %s from the pack cache: %v", service, h.dir, err)
+				}
+				return
+			}
+			if cacheWriter, err = git.DefaultPackCache.Create(h.dir, key, generation); err != nil {
+				log.LogWithContext(ctx, 0, log.ERROR, "Fail to create a pack cache entry: %v", err)
+			} else {
+				cmd.Stdout = io.MultiWriter(h.w, cacheWriter)
+			}
+		}
+	}
+
 	pid := process.GetManager().Add(fmt.Sprintf("%s %s %s [repo_path: %s]", git.GitExecutable, service, "--stateless-rpc", h.dir), cancel)
 	defer process.GetManager().Remove(pid)
 
 	if err := cmd.Run(); err != nil {
-		log.Error("Fail to serve RPC(%s) in %s: %v - %s", service, h.dir, err, stderr.String())
+		if cacheWriter != nil {
+			cacheWriter.Abort()
+		}
+		log.LogWithContext(ctx, 0, log.ERROR, "Fail to serve RPC(%s) in %s: %v - %s", service, h.dir, err, stderr.String())
 		return
 	}
+	if cacheWriter != nil {
+		if err := cacheWriter.Commit(); err != nil {
+			log.LogWithContext(ctx, 0, log.ERROR, "Fail to add the pack to the pack cache: %v", err)
+		}
+	}
 }
 
 // ServiceUploadPack implements Git Smart HTTP protocol
@@ -572,8 +718,8 @@ func getServiceType(r *http.Request) string {
 	return strings.Replace(serviceType, "git-", "", 1)
 }
 
-func updateServerInfo(dir string) []byte {
-	out, err := git.NewCommand("update-server-info").RunInDirBytes(dir)
+func updateServerInfo(ctx gocontext.Context, dir string) []byte {
+	out, err := git.NewCommandContext(ctx, "update-server-info").RunInDirBytes(dir)
 	if err != nil {
 		log.Error(fmt.Sprintf("%v - %s", err, string(out)))
 	}
@@ -603,9 +749,30 @@ func GetInfoRefs(ctx *context.Context) {
 		}
 		h.environ = append(os.Environ(), h.environ...)
 
-		refs, err := git.NewCommand(service, "--stateless-rpc", "--advertise-refs", ".").RunInDirTimeoutEnv(h.environ, -1, h.dir)
-		if err != nil {
-			log.Error(fmt.Sprintf("%v - %s", err, string(refs)))
+		var args []string
+		if service == "upload-pack" {
+			args = append(args, h.cfg.UploadPackArgs...)
+		}
+		args = append(args, service, "--stateless-rpc", "--advertise-refs", ".")
+
+		// The advertisements of upload-pack do not depend on the user, only on the refs of the repository
+		var cacheKey string
+		if service == "upload-pack" && git.DefaultRefsCache != nil {
+			cacheKey = git.RefsCacheKey(h.dir, service, h.r.Header.Get("Git-Protocol"), h.cfg.UploadPackArgs)
+		}
+		refs, generation, ok := []byte(nil), uint64(0), false
+		if cacheKey != "" {
+			refs, generation, ok = git.DefaultRefsCache.Get(h.dir, cacheKey)
+		}
+		if !ok {
+			var err error
+			refs, err = git.NewCommandContext(h.r.Context(), args...).
+				RunInDirTimeoutEnv(h.environ, -1, h.dir)
+			if err != nil {
+				log.Error(fmt.Sprintf("%v - %s", err, string(refs)))
+			} else if cacheKey != "" {
+				git.DefaultRefsCache.Set(h.dir, cacheKey, generation, refs)
+			}
 		}
 
 		h.w.Header().Set("Content-Type", fmt.Sprintf("application/x-git-%s-advertisement", service))
@@ -614,7 +781,7 @@ func GetInfoRefs(ctx *context.Context) {
 		_, _ = h.w.Write([]byte("0000"))
 		_, _ = h.w.Write(refs)
 	} else {
-		updateServerInfo(h.dir)
+		updateServerInfo(h.r.Context(), h.dir)
 		h.sendFile("text/plain; charset=utf-8", "info/refs")
 	}
 }
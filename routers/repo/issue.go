@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"html/template"
 	"io/ioutil"
 	"net/http"
 	"path"
@@ -19,9 +20,11 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/eventsource"
 	auth "code.gitea.io/gitea/modules/forms"
 	"code.gitea.io/gitea/modules/git"
 	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
+	"code.gitea.io/gitea/modules/issueform"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/markup/markdown"
@@ -69,7 +72,7 @@ var (
 // If locked and user has permissions to write to the repository,
 // then the comment is allowed, else it is blocked
 func MustAllowUserComment(ctx *context.Context) {
-	issue := GetActionIssue(ctx)
+	issue := getCommentableIssue(ctx)
 	if ctx.Written() {
 		return
 	}
@@ -110,6 +113,22 @@ func MustAllowPulls(ctx *context.Context) {
 	}
 }
 
+// issueHighlights returns the highlighted snippets of the bodies and the comments of the issues matching a search, by
+// issue ID
+func issueHighlights(matches []issue_indexer.Match) map[int64]template.HTML {
+	highlights := make(map[int64]template.HTML, len(matches))
+	for _, match := range matches {
+		var snippets []string
+		snippets = append(snippets, match.Highlights[issue_indexer.FieldContent]...)
+		snippets = append(snippets, match.Highlights[issue_indexer.FieldComments]...)
+		if len(snippets) > 0 {
+			// the snippets are escaped by the indexers
+			highlights[match.ID] = template.HTML(strings.Join(snippets, " … "))
+		}
+	}
+	return highlights
+}
+
 func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption util.OptionalBool) {
 	var err error
 	viewType := ctx.Query("type")
@@ -121,6 +140,7 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 
 	var (
 		assigneeID        = ctx.QueryInt64("assignee")
+		issueTypeID       = ctx.QueryInt64("issue_type")
 		posterID          int64
 		mentionedID       int64
 		reviewRequestedID int64
@@ -156,16 +176,76 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 		keyword = ""
 	}
 
+	// the qualifiers of the query narrow the filters
+	query, err := issue_indexer.ParseQuery(keyword)
+	if err != nil {
+		if !issue_indexer.IsErrInvalidQuery(err) {
+			ctx.ServerError("ParseQuery", err)
+			return
+		}
+		invalid := err.(issue_indexer.ErrInvalidQuery)
+		ctx.Flash.Error(ctx.Tr("repo.issues.filter_query_invalid", invalid.Qualifier, invalid.Value), true)
+		query = &issue_indexer.Query{}
+		forceEmpty = true
+	}
+	filter, err := query.Filter(repo, ctx.User)
+	if err != nil {
+		ctx.ServerError("Filter", err)
+		return
+	}
+	forceEmpty = forceEmpty || filter.MatchesNothing
+
+	// the filters of the query are kept apart from the ones of the page, which are selected in the menus
+	var (
+		searchLabelIDs    = append(append([]int64{}, labelIDs...), filter.LabelIDs...)
+		searchLabels      = selectLabels
+		searchMilestoneID = milestoneID
+		searchAssigneeID  = assigneeID
+		searchPosterID    = posterID
+		searchMentionedID = mentionedID
+		searchIsPull      = isPullOption
+	)
+	if len(filter.LabelIDs) > 0 {
+		searchLabels = strings.Join(base.Int64sToStrings(searchLabelIDs), ",")
+	}
+	for _, qualified := range []struct {
+		queryID int64
+		id      *int64
+	}{
+		{filter.AssigneeID, &searchAssigneeID},
+		{filter.PosterID, &searchPosterID},
+		{filter.MentionedID, &searchMentionedID},
+		{filter.MilestoneID, &searchMilestoneID},
+	} {
+		if qualified.queryID == 0 {
+			continue
+		}
+		if *qualified.id > 0 && *qualified.id != qualified.queryID {
+			forceEmpty = true
+		}
+		*qualified.id = qualified.queryID
+	}
+	if query.IsPull != util.OptionalBoolNone {
+		if searchIsPull != util.OptionalBoolNone && searchIsPull != query.IsPull {
+			forceEmpty = true
+		}
+		searchIsPull = query.IsPull
+	}
+
 	var issueIDs []int64
-	if len(keyword) > 0 {
-		issueIDs, err = issue_indexer.SearchIssuesByKeyword([]int64{repo.ID}, keyword)
+	if len(query.Keyword) > 0 && !forceEmpty {
+		matches, err := issue_indexer.SearchIssueMatches([]int64{repo.ID}, query.Keyword, query.In)
 		if err != nil {
 			ctx.ServerError("issueIndexer.Search", err)
 			return
 		}
+		for _, match := range matches {
+			issueIDs = append(issueIDs, match.ID)
+		}
 		if len(issueIDs) == 0 {
 			forceEmpty = true
 		}
+		ctx.Data["IssueHighlights"] = issueHighlights(matches)
 	}
 
 	var issueStats *models.IssueStats
@@ -174,14 +254,19 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 	} else {
 		issueStats, err = models.GetIssueStats(&models.IssueStatsOptions{
 			RepoID:            repo.ID,
-			Labels:            selectLabels,
-			MilestoneID:       milestoneID,
-			AssigneeID:        assigneeID,
-			MentionedID:       mentionedID,
-			PosterID:          posterID,
+			Labels:            searchLabels,
+			MilestoneID:       searchMilestoneID,
+			TypeID:            issueTypeID,
+			AssigneeID:        searchAssigneeID,
+			MentionedID:       searchMentionedID,
+			PosterID:          searchPosterID,
 			ReviewRequestedID: reviewRequestedID,
-			IsPull:            isPullOption,
+			IsPull:            searchIsPull,
 			IssueIDs:          issueIDs,
+			UpdatedAfterUnix:  query.UpdatedAfterUnix,
+			UpdatedBeforeUnix: query.UpdatedBeforeUnix,
+			CreatedAfterUnix:  query.CreatedAfterUnix,
+			CreatedBeforeUnix: query.CreatedBeforeUnix,
 		})
 		if err != nil {
 			ctx.ServerError("GetIssueStats", err)
@@ -194,6 +279,15 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 	if len(ctx.Query("state")) == 0 && issueStats.OpenCount == 0 && issueStats.ClosedCount != 0 {
 		isShowClosed = true
 	}
+	// is:open and is:closed override the state, the other one having no issues
+	switch query.IsClosed {
+	case util.OptionalBoolTrue:
+		isShowClosed = true
+		issueStats.OpenCount = 0
+	case util.OptionalBoolFalse:
+		isShowClosed = false
+		issueStats.ClosedCount = 0
+	}
 
 	page := ctx.QueryInt("page")
 	if page <= 1 {
@@ -209,8 +303,8 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 	pager := context.NewPagination(total, setting.UI.IssuePagingNum, page, 5)
 
 	var mileIDs []int64
-	if milestoneID > 0 {
-		mileIDs = []int64{milestoneID}
+	if searchMilestoneID > 0 {
+		mileIDs = []int64{searchMilestoneID}
 	}
 
 	var issues []*models.Issue
@@ -223,17 +317,22 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 				PageSize: setting.UI.IssuePagingNum,
 			},
 			RepoIDs:           []int64{repo.ID},
-			AssigneeID:        assigneeID,
-			PosterID:          posterID,
-			MentionedID:       mentionedID,
+			AssigneeID:        searchAssigneeID,
+			PosterID:          searchPosterID,
+			MentionedID:       searchMentionedID,
 			ReviewRequestedID: reviewRequestedID,
 			MilestoneIDs:      mileIDs,
 			ProjectID:         projectID,
+			TypeID:            issueTypeID,
 			IsClosed:          util.OptionalBoolOf(isShowClosed),
-			IsPull:            isPullOption,
-			LabelIDs:          labelIDs,
+			IsPull:            searchIsPull,
+			LabelIDs:          searchLabelIDs,
 			SortType:          sortType,
 			IssueIDs:          issueIDs,
+			UpdatedAfterUnix:  query.UpdatedAfterUnix,
+			UpdatedBeforeUnix: query.UpdatedBeforeUnix,
+			CreatedAfterUnix:  query.CreatedAfterUnix,
+			CreatedBeforeUnix: query.CreatedBeforeUnix,
 		})
 		if err != nil {
 			ctx.ServerError("Issues", err)
@@ -308,6 +407,14 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 	ctx.Data["Labels"] = labels
 	ctx.Data["NumLabels"] = len(labels)
 
+	if !isPullOption.IsTrue() {
+		ctx.Data["IssueTypes"], err = models.GetIssueTypesOfRepo(repo)
+		if err != nil {
+			ctx.ServerError("GetIssueTypesOfRepo", err)
+			return
+		}
+	}
+
 	if ctx.QueryInt64("assignee") == 0 {
 		assigneeID = 0 // Reset ID to prevent unexpected selection of assignee.
 	}
@@ -340,6 +447,7 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 	ctx.Data["SortType"] = sortType
 	ctx.Data["MilestoneID"] = milestoneID
 	ctx.Data["AssigneeID"] = assigneeID
+	ctx.Data["IssueTypeID"] = issueTypeID
 	ctx.Data["IsShowClosed"] = isShowClosed
 	ctx.Data["Keyword"] = keyword
 	if isShowClosed {
@@ -355,6 +463,7 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 	pager.AddParam(ctx, "labels", "SelectLabels")
 	pager.AddParam(ctx, "milestone", "MilestoneID")
 	pager.AddParam(ctx, "assignee", "AssigneeID")
+	pager.AddParam(ctx, "issue_type", "IssueTypeID")
 	ctx.Data["Page"] = pager
 }
 
@@ -375,7 +484,7 @@ func Issues(ctx *context.Context) {
 		}
 		ctx.Data["Title"] = ctx.Tr("repo.issues")
 		ctx.Data["PageIsIssueList"] = true
-		ctx.Data["NewIssueChooseTemplate"] = len(ctx.IssueTemplatesFromDefaultBranch()) > 0
+		ctx.Data["NewIssueChooseTemplate"] = ctx.HasIssueTemplateChooser()
 	}
 
 	issues(ctx, ctx.QueryInt64("milestone"), ctx.QueryInt64("project"), util.OptionalBoolOf(isPullList))
@@ -391,6 +500,18 @@ func Issues(ctx *context.Context) {
 		return
 	}
 
+	if ctx.IsSigned {
+		ctx.Data["UserFilters"], _, err = models.FindUserFilters(&models.FindUserFiltersOptions{
+			UserID: ctx.User.ID,
+			RepoID: ctx.Repo.Repository.ID,
+			IsPull: util.OptionalBoolOf(isPullList),
+		})
+		if err != nil {
+			ctx.ServerError("FindUserFilters", err)
+			return
+		}
+	}
+
 	ctx.Data["CanWriteIssuesOrPulls"] = ctx.Repo.CanWriteIssuesOrPulls(isPullList)
 
 	ctx.HTML(200, tplIssues)
@@ -429,28 +550,47 @@ func RetrieveRepoMilestonesAndAssignees(ctx *context.Context, repo *models.Repos
 }
 
 func retrieveProjects(ctx *context.Context, repo *models.Repository) {
+	for _, isClosed := range []util.OptionalBool{util.OptionalBoolFalse, util.OptionalBoolTrue} {
+		projects, _, err := models.GetProjects(models.ProjectSearchOptions{
+			RepoID:   repo.ID,
+			Page:     -1,
+			IsClosed: isClosed,
+			Type:     models.ProjectTypeRepository,
+		})
+		if err != nil {
+			ctx.ServerError("GetProjects", err)
+			return
+		}
 
-	var err error
+		// the issues of the repositories of an organization can be added to its projects too
+		if repo.Owner.IsOrganization() {
+			orgProjects, _, err := models.GetProjects(models.ProjectSearchOptions{
+				OwnerID:  repo.OwnerID,
+				Page:     -1,
+				IsClosed: isClosed,
+				Type:     models.ProjectTypeOrganization,
+			})
+			if err != nil {
+				ctx.ServerError("GetProjects", err)
+				return
+			}
+			projects = append(projects, orgProjects...)
+			ctx.Data["OrgProjectsLink"] = setting.AppSubURL + "/org/" + repo.Owner.Name + "/projects"
+		}
 
-	ctx.Data["OpenProjects"], _, err = models.GetProjects(models.ProjectSearchOptions{
-		RepoID:   repo.ID,
-		Page:     -1,
-		IsClosed: util.OptionalBoolFalse,
-		Type:     models.ProjectTypeRepository,
-	})
-	if err != nil {
-		ctx.ServerError("GetProjects", err)
-		return
+		if isClosed.IsTrue() {
+			ctx.Data["ClosedProjects"] = projects
+		} else {
+			ctx.Data["OpenProjects"] = projects
+		}
 	}
+}
 
-	ctx.Data["ClosedProjects"], _, err = models.GetProjects(models.ProjectSearchOptions{
-		RepoID:   repo.ID,
-		Page:     -1,
-		IsClosed: util.OptionalBoolTrue,
-		Type:     models.ProjectTypeRepository,
-	})
+func retrieveIssueTypes(ctx *context.Context, repo *models.Repository) {
+	var err error
+	ctx.Data["IssueTypes"], err = models.GetIssueTypesOfRepo(repo)
 	if err != nil {
-		ctx.ServerError("GetProjects", err)
+		ctx.ServerError("GetIssueTypesOfRepo", err)
 		return
 	}
 }
@@ -678,6 +818,13 @@ func RetrieveRepoMetas(ctx *context.Context, repo *models.Repository, isPull boo
 		return nil
 	}
 
+	if !isPull {
+		retrieveIssueTypes(ctx, repo)
+		if ctx.Written() {
+			return nil
+		}
+	}
+
 	brs, _, err := ctx.Repo.GitRepo.GetBranches(0, 0)
 	if err != nil {
 		ctx.ServerError("GetBranches", err)
@@ -721,26 +868,41 @@ func getFileContentFromDefaultBranch(ctx *context.Context, filename string) (str
 	return string(bytes), true
 }
 
-func setTemplateIfExists(ctx *context.Context, ctxDataKey string, possibleDirs []string, possibleFiles []string) {
+func setTemplateIfExists(ctx *context.Context, ctxDataKey, templateName string, possibleDirs []string, possibleFiles []string) {
 	templateCandidates := make([]string, 0, len(possibleFiles))
-	if ctx.Query("template") != "" {
+	if templateName != "" {
 		for _, dirName := range possibleDirs {
-			templateCandidates = append(templateCandidates, path.Join(dirName, ctx.Query("template")))
+			templateCandidates = append(templateCandidates, path.Join(dirName, templateName))
 		}
 	}
 	templateCandidates = append(templateCandidates, possibleFiles...) // Append files to the end because they should be fallback
 	for _, filename := range templateCandidates {
 		templateContent, found := getFileContentFromDefaultBranch(ctx, filename)
 		if found {
+			if ctxDataKey == issueTemplateKey {
+				ctx.Data["IssueTemplateFile"] = path.Base(filename)
+			}
 			var meta api.IssueTemplate
-			templateBody, err := markdown.ExtractMetadata(templateContent, &meta)
-			if err != nil {
-				log.Debug("could not extract metadata from %s [%s]: %v", filename, ctx.Repo.Repository.FullName(), err)
-				ctx.Data[ctxDataKey] = templateContent
-				return
+			if ctxDataKey == issueTemplateKey && issueform.IsFormFile(filename) {
+				form, err := issueform.Parse([]byte(templateContent))
+				if err != nil {
+					log.Debug("could not parse issue form %s [%s]: %v", filename, ctx.Repo.Repository.FullName(), err)
+					ctx.Flash.Error(ctx.Tr("repo.issues.form.invalid", path.Base(filename), err.Error()), true)
+					ctx.Data["IssueTemplateFile"] = ""
+					return
+				}
+				meta = *form
+				setIssueFormData(ctx, form, nil)
+			} else {
+				templateBody, err := markdown.ExtractMetadata(templateContent, &meta)
+				if err != nil {
+					log.Debug("could not extract metadata from %s [%s]: %v", filename, ctx.Repo.Repository.FullName(), err)
+					ctx.Data[ctxDataKey] = templateContent
+					return
+				}
+				ctx.Data[ctxDataKey] = templateBody
 			}
 			ctx.Data[issueTemplateTitleKey] = meta.Title
-			ctx.Data[ctxDataKey] = templateBody
 			labelIDs := make([]string, 0, len(meta.Labels))
 			if repoLabels, err := models.GetLabelsByRepoID(ctx.Repo.Repository.ID, "", models.ListOptions{}); err == nil {
 				ctx.Data["Labels"] = repoLabels
@@ -768,11 +930,39 @@ func setTemplateIfExists(ctx *context.Context, ctxDataKey string, possibleDirs [
 	}
 }
 
+// setIssueFormData sets the data rendering the fields of an issue form, with the values submitted for them if any
+func setIssueFormData(ctx *context.Context, form *api.IssueTemplate, values issueform.Values) {
+	markdowns := make([]template.HTML, len(form.Fields))
+	for i, field := range form.Fields {
+		if field.Type == api.IssueFormFieldTypeMarkdown {
+			markdowns[i] = template.HTML(markdown.Render([]byte(field.Attributes.Value), ctx.Repo.RepoLink,
+				ctx.Repo.Repository.ComposeMetas()))
+		}
+	}
+	ctx.Data["IssueForm"] = form
+	ctx.Data["IssueFormValues"] = values
+	ctx.Data["IssueFormMarkdowns"] = markdowns
+}
+
+// getIssueFormFromDefaultBranch returns the issue form of a template file of the default branch, nil if the file does
+// not exist or is not an issue form
+func getIssueFormFromDefaultBranch(ctx *context.Context, name string) (*api.IssueTemplate, error) {
+	if !issueform.IsFormFile(name) {
+		return nil, nil
+	}
+	for _, dirName := range context.IssueTemplateDirCandidates {
+		if content, found := getFileContentFromDefaultBranch(ctx, path.Join(dirName, path.Base(name))); found {
+			return issueform.Parse([]byte(content))
+		}
+	}
+	return nil, nil
+}
+
 // NewIssue render creating issue page
 func NewIssue(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.issues.new")
 	ctx.Data["PageIsIssueList"] = true
-	ctx.Data["NewIssueChooseTemplate"] = len(ctx.IssueTemplatesFromDefaultBranch()) > 0
+	ctx.Data["NewIssueChooseTemplate"] = ctx.HasIssueTemplateChooser()
 	ctx.Data["RequireHighlightJS"] = true
 	ctx.Data["RequireSimpleMDE"] = true
 	ctx.Data["RequireTribute"] = true
@@ -802,7 +992,7 @@ func NewIssue(ctx *context.Context) {
 		project, err := models.GetProjectByID(projectID)
 		if err != nil {
 			log.Error("GetProjectByID: %d: %v", projectID, err)
-		} else if project.RepoID != ctx.Repo.Repository.ID {
+		} else if !project.CanContainIssuesOf(ctx.Repo.Repository) {
 			log.Error("GetProjectByID: %d: %v", projectID, fmt.Errorf("project[%d] not in repo [%d]", project.ID, ctx.Repo.Repository.ID))
 		} else {
 			ctx.Data["project_id"] = projectID
@@ -811,8 +1001,36 @@ func NewIssue(ctx *context.Context) {
 
 	}
 
+	// the default template of the type of the issue is used when no template is chosen
+	templateName := ctx.Query("template")
+	issueTypeID := ctx.QueryInt64("issue_type")
+	if issueTypeID > 0 {
+		issueType, err := models.GetIssueTypeOfRepoByID(ctx.Repo.Repository, issueTypeID)
+		if err != nil {
+			log.Error("GetIssueTypeOfRepoByID: %d: %v", issueTypeID, err)
+		} else {
+			ctx.Data["issue_type_id"] = issueTypeID
+			ctx.Data["IssueType"] = issueType
+			if templateName == "" {
+				templateName = issueType.TemplateFile
+			}
+		}
+	}
+
+	// the issues are created from one of the templates when the blank issues are disabled
+	if templateName == "" {
+		if config, _ := ctx.IssueConfigFromDefaultBranch(); !config.BlankIssuesEnabled && len(ctx.IssueTemplatesFromDefaultBranch()) > 0 {
+			link := ctx.Repo.RepoLink + "/issues/new/choose"
+			if ctx.Req.URL.RawQuery != "" {
+				link += "?" + ctx.Req.URL.RawQuery
+			}
+			ctx.Redirect(link)
+			return
+		}
+	}
+
 	RetrieveRepoMetas(ctx, ctx.Repo.Repository, false)
-	setTemplateIfExists(ctx, issueTemplateKey, context.IssueTemplateDirCandidates, IssueTemplateCandidates)
+	setTemplateIfExists(ctx, issueTemplateKey, templateName, context.IssueTemplateDirCandidates, IssueTemplateCandidates)
 	if ctx.Written() {
 		return
 	}
@@ -829,8 +1047,16 @@ func NewIssueChooseTemplate(ctx *context.Context) {
 	ctx.Data["milestone"] = ctx.QueryInt64("milestone")
 
 	issueTemplates := ctx.IssueTemplatesFromDefaultBranch()
-	ctx.Data["NewIssueChooseTemplate"] = len(issueTemplates) > 0
+	issueConfig, err := ctx.IssueConfigFromDefaultBranch()
+	if err != nil {
+		log.Debug("IssueConfigFromDefaultBranch [%s]: %v", ctx.Repo.Repository.FullName(), err)
+		ctx.Flash.Error(ctx.Tr("repo.issues.choose.invalid_config", err.Error()), true)
+	}
+	ctx.Data["NewIssueChooseTemplate"] = len(issueTemplates) > 0 || len(issueConfig.ContactLinks) > 0
 	ctx.Data["IssueTemplates"] = issueTemplates
+	// the blank issues cannot be disabled without templates
+	ctx.Data["IssueConfig"] = issueConfig
+	ctx.Data["BlankIssuesEnabled"] = issueConfig.BlankIssuesEnabled || len(issueTemplates) == 0
 
 	ctx.HTML(200, tplIssueChoose)
 }
@@ -886,7 +1112,7 @@ func ValidateRepoMetas(ctx *context.Context, form auth.CreateIssueForm, isPull b
 			ctx.ServerError("GetProjectByID", err)
 			return nil, nil, 0, 0
 		}
-		if p.RepoID != ctx.Repo.Repository.ID {
+		if !p.CanContainIssuesOf(ctx.Repo.Repository) {
 			ctx.NotFound("", nil)
 			return nil, nil, 0, 0
 		}
@@ -937,7 +1163,7 @@ func NewIssuePost(ctx *context.Context) {
 	form := web.GetForm(ctx).(*auth.CreateIssueForm)
 	ctx.Data["Title"] = ctx.Tr("repo.issues.new")
 	ctx.Data["PageIsIssueList"] = true
-	ctx.Data["NewIssueChooseTemplate"] = len(ctx.IssueTemplatesFromDefaultBranch()) > 0
+	ctx.Data["NewIssueChooseTemplate"] = ctx.HasIssueTemplateChooser()
 	ctx.Data["RequireHighlightJS"] = true
 	ctx.Data["RequireSimpleMDE"] = true
 	ctx.Data["ReadOnly"] = false
@@ -950,6 +1176,8 @@ func NewIssuePost(ctx *context.Context) {
 		attachments []string
 	)
 
+	ctx.Data["IssueTemplateFile"] = form.Template
+
 	labelIDs, assigneeIDs, milestoneID, projectID := ValidateRepoMetas(ctx, *form, false)
 	if ctx.Written() {
 		return
@@ -959,6 +1187,30 @@ func NewIssuePost(ctx *context.Context) {
 		attachments = form.Files
 	}
 
+	issueForm, err := getIssueFormFromDefaultBranch(ctx, form.Template)
+	if err != nil {
+		ctx.Data["IssueTemplateFile"] = ""
+		ctx.RenderWithErr(ctx.Tr("repo.issues.form.invalid", path.Base(form.Template), err.Error()), tplIssueNew, form)
+		return
+	}
+	if issueForm != nil {
+		setIssueFormData(ctx, issueForm, issueform.Values(ctx.Req.Form))
+	}
+
+	if form.IssueTypeID > 0 {
+		issueType, err := models.GetIssueTypeOfRepoByID(repo, form.IssueTypeID)
+		if err != nil {
+			if models.IsErrIssueTypeNotExist(err) {
+				ctx.NotFound("GetIssueTypeOfRepoByID", err)
+				return
+			}
+			ctx.ServerError("GetIssueTypeOfRepoByID", err)
+			return
+		}
+		ctx.Data["IssueType"] = issueType
+		ctx.Data["issue_type_id"] = form.IssueTypeID
+	}
+
 	if ctx.HasError() {
 		ctx.HTML(200, tplIssueNew)
 		return
@@ -969,17 +1221,33 @@ func NewIssuePost(ctx *context.Context) {
 		return
 	}
 
+	if issueForm != nil {
+		if err := issueform.ValidateValues(issueForm, issueform.Values(ctx.Req.Form)); err != nil {
+			if issueform.IsErrFieldRequired(err) {
+				ctx.RenderWithErr(ctx.Tr("repo.issues.form.field_required", err.(issueform.ErrFieldRequired).Label), tplIssueNew, form)
+				return
+			}
+			ctx.ServerError("ValidateValues", err)
+			return
+		}
+		form.Content = issueform.RenderToMarkdown(issueForm, issueform.Values(ctx.Req.Form))
+	}
+
 	issue := &models.Issue{
 		RepoID:      repo.ID,
 		Title:       form.Title,
 		PosterID:    ctx.User.ID,
 		Poster:      ctx.User,
 		MilestoneID: milestoneID,
+		TypeID:      form.IssueTypeID,
 		Content:     form.Content,
 		Ref:         form.Ref,
 	}
+	if len(form.Template) > 0 {
+		issue.Template = path.Base(form.Template)
+	}
 
-	if err := issue_service.NewIssue(repo, issue, labelIDs, attachments, assigneeIDs); err != nil {
+	if err := issue_service.NewIssue(ctx.Req.Context(), repo, issue, labelIDs, attachments, assigneeIDs); err != nil {
 		if models.IsErrUserDoesNotHaveAccessToRepo(err) {
 			ctx.Error(400, "UserDoesNotHaveAccessToRepo", err.Error())
 			return
@@ -1073,6 +1341,10 @@ func ViewIssue(ctx *context.Context) {
 		}
 		return
 	}
+	checkIssueCollaborator(ctx, issue)
+	if ctx.Written() {
+		return
+	}
 
 	// Make sure type and URL matches.
 	if ctx.Params(":type") == "issues" && issue.IsPull {
@@ -1084,19 +1356,23 @@ func ViewIssue(ctx *context.Context) {
 	}
 
 	if issue.IsPull {
-		MustAllowPulls(ctx)
-		if ctx.Written() {
-			return
+		if !isIssueCollaborator(ctx) {
+			MustAllowPulls(ctx)
+			if ctx.Written() {
+				return
+			}
 		}
 		ctx.Data["PageIsPullList"] = true
 		ctx.Data["PageIsPullConversation"] = true
 	} else {
-		MustEnableIssues(ctx)
-		if ctx.Written() {
-			return
+		if !isIssueCollaborator(ctx) {
+			MustEnableIssues(ctx)
+			if ctx.Written() {
+				return
+			}
 		}
 		ctx.Data["PageIsIssueList"] = true
-		ctx.Data["NewIssueChooseTemplate"] = len(ctx.IssueTemplatesFromDefaultBranch()) > 0
+		ctx.Data["NewIssueChooseTemplate"] = ctx.HasIssueTemplateChooser()
 	}
 
 	if issue.IsPull && !ctx.Repo.CanRead(models.UnitTypeIssues) {
@@ -1114,6 +1390,37 @@ func ViewIssue(ctx *context.Context) {
 	ctx.Data["IsAttachmentEnabled"] = setting.Attachment.Enabled
 	upload.AddUploadContext(ctx, "comment")
 
+	// Only one page of a long timeline is loaded, the newest events unless an older page is requested
+	numTimelinePages := 1
+	if pagingNum := setting.UI.TimelinePagingNum; pagingNum > 0 {
+		numComments, err := models.CountComments(models.FindCommentsOptions{
+			IssueID: issue.ID,
+			Type:    models.CommentTypeUnknown,
+		})
+		if err != nil {
+			ctx.ServerError("CountComments", err)
+			return
+		}
+		if numComments > int64(pagingNum) {
+			numTimelinePages = int((numComments + int64(pagingNum) - 1) / int64(pagingNum))
+			page := ctx.QueryInt("timeline_page")
+			if page <= 0 || page > numTimelinePages {
+				page = numTimelinePages
+			}
+			if err = issue.LoadCommentsPage(page, pagingNum); err != nil {
+				ctx.ServerError("LoadCommentsPage", err)
+				return
+			}
+			link := ctx.Data["Link"].(string)
+			if page > 1 {
+				ctx.Data["TimelineOlderLink"] = fmt.Sprintf("%s?timeline_page=%d", link, page-1)
+			}
+			if page < numTimelinePages {
+				ctx.Data["TimelineNewerLink"] = fmt.Sprintf("%s?timeline_page=%d", link, page+1)
+			}
+		}
+	}
+
 	if err = issue.LoadAttributes(); err != nil {
 		ctx.ServerError("LoadAttributes", err)
 		return
@@ -1194,6 +1501,9 @@ func ViewIssue(ctx *context.Context) {
 	if ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
 		RetrieveRepoMilestonesAndAssignees(ctx, repo)
 		retrieveProjects(ctx, repo)
+		if !issue.IsPull {
+			retrieveIssueTypes(ctx, repo)
+		}
 
 		if ctx.Written() {
 			return
@@ -1282,6 +1592,7 @@ func ViewIssue(ctx *context.Context) {
 
 	// Render comments and and fetch participants.
 	participants[0] = issue.Poster
+	canReadIssue := issueReadableChecker(ctx)
 	for _, comment = range issue.Comments {
 		comment.Issue = issue
 
@@ -1358,13 +1669,18 @@ func ViewIssue(ctx *context.Context) {
 				ctx.ServerError("LoadAssigneeUserAndTeam", err)
 				return
 			}
-		} else if comment.Type == models.CommentTypeRemoveDependency || comment.Type == models.CommentTypeAddDependency {
+		} else if comment.Type == models.CommentTypeRemoveDependency || comment.Type == models.CommentTypeAddDependency ||
+			comment.Type == models.CommentTypeAddParentIssue || comment.Type == models.CommentTypeRemoveParentIssue {
 			if err = comment.LoadDepIssueDetails(); err != nil {
 				if !models.IsErrIssueNotExist(err) {
 					ctx.ServerError("LoadDepIssueDetails", err)
 					return
 				}
 			}
+			// the issues of the repositories the user cannot see are not shown
+			if comment.DependentIssue != nil && !canReadIssue(comment.DependentIssue) {
+				comment.DependentIssue = nil
+			}
 		} else if comment.Type == models.CommentTypeCode || comment.Type == models.CommentTypeReview || comment.Type == models.CommentTypeDismissReview {
 			comment.RenderedContent = string(markdown.Render([]byte(comment.Content), ctx.Repo.RepoLink,
 				ctx.Repo.Repository.ComposeMetas()))
@@ -1424,6 +1740,23 @@ func ViewIssue(ctx *context.Context) {
 		}
 	}
 
+	if numTimelinePages > 1 {
+		// The participants who only took part on the other pages of the timeline are not known yet
+		participantIDs, err := models.GetParticipantsIDsByIssueID(issue.ID)
+		if err != nil {
+			ctx.ServerError("GetParticipantsIDsByIssueID", err)
+			return
+		}
+		users, err := models.GetUsersByIDs(participantIDs)
+		if err != nil {
+			ctx.ServerError("GetUsersByIDs", err)
+			return
+		}
+		for _, user := range users {
+			participants = addParticipant(user, participants)
+		}
+	}
+
 	// Combine multiple label assignments into a single comment
 	combineLabelComments(issue)
 
@@ -1492,6 +1825,8 @@ func ViewIssue(ctx *context.Context) {
 				ctx.Data["MergeStyle"] = models.MergeStyleRebaseMerge
 			} else if prConfig.AllowSquash {
 				ctx.Data["MergeStyle"] = models.MergeStyleSquash
+			} else if prConfig.AllowFastForwardOnly {
+				ctx.Data["MergeStyle"] = models.MergeStyleFastForwardOnly
 			} else if prConfig.AllowManualMerge {
 				ctx.Data["MergeStyle"] = models.MergeStyleManuallyMerged
 			} else {
@@ -1508,12 +1843,40 @@ func ViewIssue(ctx *context.Context) {
 			ctx.Data["IsBlockedByRejection"] = pull.ProtectedBranch.MergeBlockedByRejectedReview(pull)
 			ctx.Data["IsBlockedByOfficialReviewRequests"] = pull.ProtectedBranch.MergeBlockedByOfficialReviewRequests(pull)
 			ctx.Data["IsBlockedByOutdatedBranch"] = pull.ProtectedBranch.MergeBlockedByOutdatedBranch(pull)
+			ctx.Data["IsBlockedByUnresolvedConversations"] = pull.ProtectedBranch.MergeBlockedByUnresolvedConversations(pull)
+			unapprovedCodeOwners, err := pull_service.MergeBlockedByCodeOwners(pull)
+			if err != nil {
+				log.Error("MergeBlockedByCodeOwners[%d]: %v", pull.ID, err)
+			}
+			ctx.Data["IsBlockedByCodeOwners"] = len(unapprovedCodeOwners) > 0
+			ctx.Data["UnapprovedCodeOwnerPatterns"] = strings.Join(unapprovedCodeOwners, ", ")
 			ctx.Data["GrantedApprovals"] = cnt
 			ctx.Data["RequireSigned"] = pull.ProtectedBranch.RequireSignedCommits
 			ctx.Data["ChangedProtectedFiles"] = pull.ChangedProtectedFiles
 			ctx.Data["IsBlockedByChangedProtectedFiles"] = len(pull.ChangedProtectedFiles) != 0
 			ctx.Data["ChangedProtectedFilesNum"] = len(pull.ChangedProtectedFiles)
 		}
+		mergeRequirements, err := models.GetPullMergeRequirements(pull.ID)
+		if err != nil {
+			ctx.ServerError("GetPullMergeRequirements", err)
+			return
+		}
+		isBlockedByMergeRequirements := false
+		for _, r := range mergeRequirements {
+			if !r.IsSatisfied {
+				isBlockedByMergeRequirements = true
+			}
+		}
+		ctx.Data["MergeRequirements"] = mergeRequirements
+		ctx.Data["IsBlockedByMergeRequirements"] = isBlockedByMergeRequirements
+		if setting.Repository.LargeFile.MaxSize > 0 && !issue.IsClosed {
+			largeFiles, err := pull_service.GetLargeFiles(pull)
+			if err != nil {
+				log.Error("GetLargeFiles[%d]: %v", pull.ID, err)
+			}
+			ctx.Data["LargeFiles"] = largeFiles
+			ctx.Data["LargeFileMaxSize"] = setting.Repository.LargeFile.MaxSize * 1024 * 1024
+		}
 		ctx.Data["WillSign"] = false
 		if ctx.User != nil {
 			sign, key, _, err := pull.SignMerge(ctx.User, pull.BaseRepo.RepoPath(), pull.BaseBranch, pull.GetGitRefName())
@@ -1550,22 +1913,60 @@ func ViewIssue(ctx *context.Context) {
 		}
 
 		ctx.Data["StillCanManualMerge"] = stillCanManualMerge()
+		ctx.Data["CanConvertToIssue"] = ctx.IsSigned && !pull.HasMerged && !issue.IsClosed &&
+			ctx.Repo.CanWrite(models.UnitTypePullRequests) && ctx.Repo.CanWrite(models.UnitTypeIssues)
 	}
 
-	// Get Dependencies
-	ctx.Data["BlockedByDependencies"], err = issue.BlockedByDependencies()
+	// Get Dependencies, leaving out the issues of the repositories the user cannot see
+	blockedBy, err := issue.BlockedByDependencies()
 	if err != nil {
 		ctx.ServerError("BlockedByDependencies", err)
 		return
 	}
-	ctx.Data["BlockingDependencies"], err = issue.BlockingDependencies()
+	blockedBy = filterReadableDependencies(blockedBy, canReadIssue)
+	ctx.Data["BlockedByDependencies"] = blockedBy
+	blocking, err := issue.BlockingDependencies()
 	if err != nil {
 		ctx.ServerError("BlockingDependencies", err)
 		return
 	}
+	blocking = filterReadableDependencies(blocking, canReadIssue)
+	ctx.Data["BlockingDependencies"] = blocking
+	if repo.IsDependenciesEnabled() && (len(blockedBy) > 0 || len(blocking) > 0) {
+		ctx.Data["DependencyGraph"], err = issue_service.BuildDependencyGraph(issue, canReadIssue)
+		if err != nil {
+			ctx.ServerError("BuildDependencyGraph", err)
+			return
+		}
+	}
+
+	// Get the parent issue and the tree of sub-issues
+	if !issue.IsPull {
+		if err = issue.LoadParent(); err != nil {
+			ctx.ServerError("LoadParent", err)
+			return
+		}
+		ctx.Data["SubIssueTree"], err = models.GetSubIssueTree(issue)
+		if err != nil {
+			ctx.ServerError("GetSubIssueTree", err)
+			return
+		}
+	}
 
 	ctx.Data["Participants"] = participants
 	ctx.Data["NumParticipants"] = len(participants)
+	ctx.Data["CanManageIssueCollaborators"] = canManageIssueCollaborators(ctx, issue)
+	if ctx.Data["IssueCollaborators"], err = models.GetIssueCollaborators(issue.ID); err != nil {
+		ctx.ServerError("GetIssueCollaborators", err)
+		return
+	}
+	contentEdits, err := models.CountIssueContentEdits(issue.ID)
+	if err != nil {
+		ctx.ServerError("CountIssueContentEdits", err)
+		return
+	}
+	ctx.Data["ContentEdits"] = contentEdits
+	ctx.Data["IssueContentEdits"] = contentEdits[0]
 	ctx.Data["Issue"] = issue
 	ctx.Data["ReadOnly"] = false
 	ctx.Data["SignInLink"] = setting.AppSubURL + "/user/login?redirect_to=" + ctx.Data["Link"].(string)
@@ -1575,18 +1976,44 @@ func ViewIssue(ctx *context.Context) {
 	ctx.Data["IsRepoAdmin"] = ctx.IsSigned && (ctx.Repo.IsAdmin() || ctx.User.IsAdmin)
 	ctx.Data["LockReasons"] = setting.Repository.Issue.LockReasons
 	ctx.Data["RefEndName"] = git.RefEndName(issue.Ref)
+
+	// The topics the page subscribes to through the event stream to be told about changes
+	if ctx.IsSigned && setting.UI.Notification.EventSourceUpdateTime > 0 {
+		topics := []string{eventsource.IssueTopic(issue.ID).String()}
+		if issue.IsPull && !issue.PullRequest.HasMerged {
+			topics = append(topics, eventsource.PullTopic(issue.ID).String())
+			if sha, ok := ctx.Data["PullHeadCommitID"].(string); ok {
+				topics = append(topics, eventsource.CommitStatusTopic(repo.ID, sha).String())
+			}
+		}
+		ctx.Data["LiveUpdateTopics"] = strings.Join(topics, " ")
+	}
 	ctx.HTML(200, tplIssueView)
 }
 
 // GetActionIssue will return the issue which is used in the context.
 func GetActionIssue(ctx *context.Context) *models.Issue {
+	return getActionIssue(ctx, false)
+}
+
+// getCommentableIssue will return the issue which is used in the context, like GetActionIssue,
+// but it also lets through the users invited to the issue, who may comment on it.
+func getCommentableIssue(ctx *context.Context) *models.Issue {
+	return getActionIssue(ctx, true)
+}
+
+func getActionIssue(ctx *context.Context, allowIssueCollaborator bool) *models.Issue {
 	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
 		ctx.NotFoundOrServerError("GetIssueByIndex", models.IsErrIssueNotExist, err)
 		return nil
 	}
 	issue.Repo = ctx.Repo.Repository
-	checkIssueRights(ctx, issue)
+	if allowIssueCollaborator && !ctx.Repo.CanReadIssuesOrPulls(issue.IsPull) {
+		checkIssueCollaborator(ctx, issue)
+	} else {
+		checkIssueRights(ctx, issue)
+	}
 	if ctx.Written() {
 		return nil
 	}
@@ -1597,6 +2024,40 @@ func GetActionIssue(ctx *context.Context) *models.Issue {
 	return issue
 }
 
+// checkIssueCollaborator lets the signed in user through to the issue without read
+// access to the issues or pull requests of the repository only if they have been invited to it,
+// whatever access they have to the other units. Users invited to the issue may only view it and
+// comment on it, they get no permission on the repository.
+func checkIssueCollaborator(ctx *context.Context, issue *models.Issue) {
+	if ctx.Repo.CanReadIssuesOrPulls(issue.IsPull) {
+		return
+	}
+	unitType := models.UnitTypeIssues
+	if issue.IsPull {
+		unitType = models.UnitTypePullRequests
+	}
+	if !ctx.IsSigned || issue.RepoID != ctx.Repo.Repository.ID || !ctx.Repo.Repository.UnitEnabled(unitType) {
+		ctx.NotFound("IssueCollaborator", nil)
+		return
+	}
+	isCollaborator, err := models.IsIssueCollaborator(issue.ID, ctx.User.ID)
+	if err != nil {
+		ctx.ServerError("IsIssueCollaborator", err)
+		return
+	} else if !isCollaborator {
+		ctx.NotFound("IsIssueCollaborator", nil)
+		return
+	}
+	ctx.Data["IsIssueCollaborator"] = true
+}
+
+// isIssueCollaborator returns true if the signed in user accesses the issue of the context
+// only as one of the users invited to it
+func isIssueCollaborator(ctx *context.Context) bool {
+	isCollaborator, _ := ctx.Data["IsIssueCollaborator"].(bool)
+	return isCollaborator
+}
+
 func checkIssueRights(ctx *context.Context, issue *models.Issue) {
 	if issue.IsPull && !ctx.Repo.CanRead(models.UnitTypePullRequests) ||
 		!issue.IsPull && !ctx.Repo.CanRead(models.UnitTypeIssues) {
@@ -1657,7 +2118,7 @@ func UpdateIssueTitle(ctx *context.Context) {
 		return
 	}
 
-	if err := issue_service.ChangeTitle(issue, ctx.User, title); err != nil {
+	if err := issue_service.ChangeTitle(ctx.Req.Context(), issue, ctx.User, title); err != nil {
 		ctx.ServerError("ChangeTitle", err)
 		return
 	}
@@ -1681,7 +2142,7 @@ func UpdateIssueRef(ctx *context.Context) {
 
 	ref := ctx.QueryTrim("ref")
 
-	if err := issue_service.ChangeIssueRef(issue, ctx.User, ref); err != nil {
+	if err := issue_service.ChangeIssueRef(ctx.Req.Context(), issue, ctx.User, ref); err != nil {
 		ctx.ServerError("ChangeRef", err)
 		return
 	}
@@ -1704,7 +2165,7 @@ func UpdateIssueContent(ctx *context.Context) {
 	}
 
 	content := ctx.Query("content")
-	if err := issue_service.ChangeContent(issue, ctx.User, content); err != nil {
+	if err := issue_service.ChangeContent(ctx.Req.Context(), issue, ctx.User, content); err != nil {
 		ctx.ServerError("ChangeContent", err)
 		return
 	}
@@ -1734,7 +2195,7 @@ func UpdateIssueMilestone(ctx *context.Context) {
 			continue
 		}
 		issue.MilestoneID = milestoneID
-		if err := issue_service.ChangeMilestoneAssign(issue, ctx.User, oldMilestoneID); err != nil {
+		if err := issue_service.ChangeMilestoneAssign(ctx.Req.Context(), issue, ctx.User, oldMilestoneID); err != nil {
 			ctx.ServerError("ChangeMilestoneAssign", err)
 			return
 		}
@@ -1745,6 +2206,57 @@ func UpdateIssueMilestone(ctx *context.Context) {
 	})
 }
 
+// UpdateIssueType change issue's type
+func UpdateIssueType(ctx *context.Context) {
+	issues := getActionIssues(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	typeID := ctx.QueryInt64("id")
+	for _, issue := range issues {
+		if issue.IsPull {
+			continue
+		}
+		if err := models.ChangeIssueType(issue, ctx.User, typeID); err != nil {
+			if models.IsErrIssueTypeNotExist(err) {
+				ctx.NotFound("ChangeIssueType", err)
+				return
+			}
+			ctx.ServerError("ChangeIssueType", err)
+			return
+		}
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// UpdateIssueWeight changes the weight of an issue or a pull request
+func UpdateIssueWeight(ctx *context.Context) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+		ctx.Error(403)
+		return
+	}
+
+	weight := ctx.QueryInt64("weight")
+	if weight < 0 {
+		ctx.Flash.Error(ctx.Tr("repo.issues.weight_invalid"))
+		ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
+		return
+	}
+	if err := models.ChangeIssueWeight(issue, ctx.User, weight); err != nil {
+		ctx.ServerError("ChangeIssueWeight", err)
+		return
+	}
+	ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
+}
+
 // UpdateIssueAssignee change issue's or pull's assignee
 func UpdateIssueAssignee(ctx *context.Context) {
 	issues := getActionIssues(ctx)
@@ -1758,7 +2270,7 @@ func UpdateIssueAssignee(ctx *context.Context) {
 	for _, issue := range issues {
 		switch action {
 		case "clear":
-			if err := issue_service.DeleteNotPassedAssignee(issue, ctx.User, []*models.User{}); err != nil {
+			if err := issue_service.DeleteNotPassedAssignee(ctx.Req.Context(), issue, ctx.User, []*models.User{}); err != nil {
 				ctx.ServerError("ClearAssignees", err)
 				return
 			}
@@ -1779,7 +2291,7 @@ func UpdateIssueAssignee(ctx *context.Context) {
 				return
 			}
 
-			_, _, err = issue_service.ToggleAssignee(issue, ctx.User, assigneeID)
+			_, _, err = issue_service.ToggleAssignee(ctx.Req.Context(), issue, ctx.User, assigneeID)
 			if err != nil {
 				ctx.ServerError("ToggleAssignee", err)
 				return
@@ -1866,7 +2378,7 @@ func UpdatePullReviewRequest(ctx *context.Context) {
 				return
 			}
 
-			_, err = issue_service.TeamReviewRequest(issue, ctx.User, team, action == "attach")
+			_, err = issue_service.TeamReviewRequest(ctx.Req.Context(), issue, ctx.User, team, action == "attach")
 			if err != nil {
 				ctx.ServerError("TeamReviewRequest", err)
 				return
@@ -1904,7 +2416,7 @@ func UpdatePullReviewRequest(ctx *context.Context) {
 			return
 		}
 
-		_, err = issue_service.ReviewRequest(issue, ctx.User, reviewer, action == "attach")
+		_, err = issue_service.ReviewRequest(ctx.Req.Context(), issue, ctx.User, reviewer, action == "attach")
 		if err != nil {
 			ctx.ServerError("ReviewRequest", err)
 			return
@@ -1939,7 +2451,7 @@ func UpdateIssueStatus(ctx *context.Context) {
 	}
 	for _, issue := range issues {
 		if issue.IsClosed != isClosed {
-			if err := issue_service.ChangeStatus(issue, ctx.User, isClosed); err != nil {
+			if err := issue_service.ChangeStatus(ctx.Req.Context(), issue, ctx.User, isClosed); err != nil {
 				if models.IsErrDependenciesLeft(err) {
 					ctx.JSON(http.StatusPreconditionFailed, map[string]interface{}{
 						"error": "cannot close this issue because it still has open dependencies",
@@ -1959,12 +2471,12 @@ func UpdateIssueStatus(ctx *context.Context) {
 // NewComment create a comment for issue
 func NewComment(ctx *context.Context) {
 	form := web.GetForm(ctx).(*auth.CreateCommentForm)
-	issue := GetActionIssue(ctx)
+	issue := getCommentableIssue(ctx)
 	if ctx.Written() {
 		return
 	}
 
-	if !ctx.IsSigned || (ctx.User.ID != issue.PosterID && !ctx.Repo.CanReadIssuesOrPulls(issue.IsPull)) {
+	if !ctx.IsSigned || (ctx.User.ID != issue.PosterID && !ctx.Repo.CanReadIssuesOrPulls(issue.IsPull) && !isIssueCollaborator(ctx)) {
 		if log.IsTrace() {
 			if ctx.IsSigned {
 				issueType := "issues"
@@ -2035,7 +2547,7 @@ func NewComment(ctx *context.Context) {
 				ctx.Flash.Info(ctx.Tr("repo.pulls.open_unmerged_pull_exists", pr.Index))
 			} else {
 				isClosed := form.Status == "close"
-				if err := issue_service.ChangeStatus(issue, ctx.User, isClosed); err != nil {
+				if err := issue_service.ChangeStatus(ctx.Req.Context(), issue, ctx.User, isClosed); err != nil {
 					log.Error("ChangeStatus: %v", err)
 
 					if models.IsErrDependenciesLeft(err) {
@@ -2076,7 +2588,7 @@ func NewComment(ctx *context.Context) {
 		return
 	}
 
-	comment, err := comment_service.CreateIssueComment(ctx.User, ctx.Repo.Repository, issue, form.Content, attachments)
+	comment, err := comment_service.CreateIssueComment(ctx.Req.Context(), ctx.User, ctx.Repo.Repository, issue, form.Content, attachments)
 	if err != nil {
 		ctx.ServerError("CreateIssueComment", err)
 		return
@@ -2097,6 +2609,10 @@ func UpdateCommentContent(ctx *context.Context) {
 		ctx.NotFoundOrServerError("LoadIssue", models.IsErrIssueNotExist, err)
 		return
 	}
+	checkIssueCollaborator(ctx, comment.Issue)
+	if ctx.Written() {
+		return
+	}
 
 	if comment.Type == models.CommentTypeComment {
 		if err := comment.LoadAttachments(); err != nil {
@@ -2121,7 +2637,7 @@ func UpdateCommentContent(ctx *context.Context) {
 		})
 		return
 	}
-	if err = comment_service.UpdateComment(comment, ctx.User, oldContent); err != nil {
+	if err = comment_service.UpdateComment(ctx.Req.Context(), comment, ctx.User, oldContent); err != nil {
 		ctx.ServerError("UpdateComment", err)
 		return
 	}
@@ -2149,6 +2665,10 @@ func DeleteComment(ctx *context.Context) {
 		ctx.NotFoundOrServerError("LoadIssue", models.IsErrIssueNotExist, err)
 		return
 	}
+	checkIssueCollaborator(ctx, comment.Issue)
+	if ctx.Written() {
+		return
+	}
 
 	if !ctx.IsSigned || (ctx.User.ID != comment.PosterID && !ctx.Repo.CanWriteIssuesOrPulls(comment.Issue.IsPull)) {
 		ctx.Error(403)
@@ -2158,7 +2678,7 @@ func DeleteComment(ctx *context.Context) {
 		return
 	}
 
-	if err = comment_service.DeleteComment(ctx.User, comment); err != nil {
+	if err = comment_service.DeleteComment(ctx.Req.Context(), ctx.User, comment); err != nil {
 		ctx.ServerError("DeleteCommentByID", err)
 		return
 	}
@@ -2275,8 +2795,12 @@ func ChangeCommentReaction(ctx *context.Context) {
 		ctx.NotFoundOrServerError("LoadIssue", models.IsErrIssueNotExist, err)
 		return
 	}
+	checkIssueCollaborator(ctx, comment.Issue)
+	if ctx.Written() {
+		return
+	}
 
-	if !ctx.IsSigned || (ctx.User.ID != comment.PosterID && !ctx.Repo.CanReadIssuesOrPulls(comment.Issue.IsPull)) {
+	if !ctx.IsSigned || (ctx.User.ID != comment.PosterID && !ctx.Repo.CanReadIssuesOrPulls(comment.Issue.IsPull) && !isIssueCollaborator(ctx)) {
 		if log.IsTrace() {
 			if ctx.IsSigned {
 				issueType := "issues"
@@ -2297,7 +2821,7 @@ func ChangeCommentReaction(ctx *context.Context) {
 
 		ctx.Error(403)
 		return
-	} else if comment.Type != models.CommentTypeComment && comment.Type != models.CommentTypeCode {
+	} else if !comment.SupportsReactions() {
 		ctx.Error(204)
 		return
 	}
@@ -2398,7 +2922,10 @@ func filterXRefComments(ctx *context.Context, issue *models.Issue) error {
 
 // GetIssueAttachments returns attachments for the issue
 func GetIssueAttachments(ctx *context.Context) {
-	issue := GetActionIssue(ctx)
+	issue := getCommentableIssue(ctx)
+	if ctx.Written() {
+		return
+	}
 	var attachments = make([]*api.Attachment, len(issue.Attachments))
 	for i := 0; i < len(issue.Attachments); i++ {
 		attachments[i] = convert.ToReleaseAttachment(issue.Attachments[i])
@@ -2413,6 +2940,14 @@ func GetCommentAttachments(ctx *context.Context) {
 		ctx.NotFoundOrServerError("GetCommentByID", models.IsErrCommentNotExist, err)
 		return
 	}
+	if err := comment.LoadIssue(); err != nil {
+		ctx.NotFoundOrServerError("LoadIssue", models.IsErrIssueNotExist, err)
+		return
+	}
+	checkIssueCollaborator(ctx, comment.Issue)
+	if ctx.Written() {
+		return
+	}
 	var attachments = make([]*api.Attachment, 0)
 	if comment.Type == models.CommentTypeComment {
 		if err := comment.LoadAttachments(); err != nil {
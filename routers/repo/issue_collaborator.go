@@ -0,0 +1,108 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/utils"
+	"code.gitea.io/gitea/services/mailer"
+)
+
+// canManageIssueCollaborators returns true if the current user may invite outside users to the issue
+func canManageIssueCollaborators(ctx *context.Context, issue *models.Issue) bool {
+	return ctx.IsSigned && ctx.Repo.Repository.IsPrivate && ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull)
+}
+
+// AddIssueCollaborator invites a user to a single issue or pull request
+func AddIssueCollaborator(ctx *context.Context) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !canManageIssueCollaborators(ctx, issue) {
+		ctx.Error(http.StatusForbidden)
+		return
+	}
+
+	name := utils.RemoveUsernameParameterSuffix(strings.ToLower(ctx.Query("username")))
+	if len(name) == 0 {
+		ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
+		return
+	}
+
+	u, err := models.GetUserByName(name)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("form.user_not_exist"))
+			ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
+		} else {
+			ctx.ServerError("GetUserByName", err)
+		}
+		return
+	}
+
+	if !u.IsActive {
+		ctx.Flash.Error(ctx.Tr("repo.issues.collaborators.inactive_user"))
+		ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
+		return
+	}
+
+	perm, err := models.GetUserRepoPermission(ctx.Repo.Repository, u)
+	if err != nil {
+		ctx.ServerError("GetUserRepoPermission", err)
+		return
+	}
+	if perm.CanReadIssuesOrPulls(issue.IsPull) {
+		ctx.Flash.Error(ctx.Tr("repo.issues.collaborators.has_access", u.Name))
+		ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
+		return
+	}
+
+	if err := models.AddIssueCollaborator(issue, u, ctx.User); err != nil {
+		switch {
+		case models.IsErrIssueCollaboratorAlreadyExist(err):
+			ctx.Flash.Error(ctx.Tr("repo.issues.collaborators.duplicate"))
+		case models.IsErrIssueCollaboratorNotIndividual(err):
+			ctx.Flash.Error(ctx.Tr("repo.settings.org_not_allowed_to_be_collaborator"))
+		default:
+			ctx.ServerError("AddIssueCollaborator", err)
+			return
+		}
+		ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
+		return
+	}
+
+	if setting.Service.EnableNotifyMail {
+		mailer.SendIssueCollaboratorMail(u, ctx.User, issue)
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.issues.collaborators.add_success", u.Name))
+	ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
+}
+
+// RemoveIssueCollaborator revokes the invitation of a user to a single issue or pull request
+func RemoveIssueCollaborator(ctx *context.Context) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !canManageIssueCollaborators(ctx, issue) {
+		ctx.Error(http.StatusForbidden)
+		return
+	}
+
+	if err := models.RemoveIssueCollaborator(issue, ctx.ParamsInt64(":uid")); err != nil {
+		ctx.NotFoundOrServerError("RemoveIssueCollaborator", models.IsErrUserNotExist, err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.issues.collaborators.remove_success"))
+	ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	issue_service "code.gitea.io/gitea/services/issue"
+)
+
+const (
+	tplIssueContentHistory base.TplName = "repo/issue/content_history"
+)
+
+// contentHistoryPoster returns the comment of the issue whose content history is requested, 0 for the content of the
+// issue itself, and the user who posted it
+func contentHistoryPoster(ctx *context.Context, issue *models.Issue) (commentID, posterID int64) {
+	commentID = ctx.QueryInt64("comment_id")
+	if commentID == 0 {
+		return 0, issue.PosterID
+	}
+	comment, err := models.GetCommentByID(commentID)
+	if err != nil {
+		ctx.NotFoundOrServerError("GetCommentByID", models.IsErrCommentNotExist, err)
+		return 0, 0
+	}
+	if comment.IssueID != issue.ID {
+		ctx.NotFound("CompareCommentIssue", models.ErrCommentNotExist{ID: commentID, IssueID: issue.ID})
+		return 0, 0
+	}
+	// the comments of a pending review are only visible to their poster
+	if comment.ReviewID > 0 && (!ctx.IsSigned || ctx.User.ID != comment.PosterID) {
+		if err := comment.LoadReview(); err != nil {
+			ctx.NotFoundOrServerError("LoadReview", models.IsErrReviewNotExist, err)
+			return 0, 0
+		}
+		if comment.Review.Type == models.ReviewTypePending {
+			ctx.NotFound("LoadReview", models.ErrCommentNotExist{ID: commentID, IssueID: issue.ID})
+			return 0, 0
+		}
+	}
+	return comment.ID, comment.PosterID
+}
+
+// canPurgeContentHistory returns whether the signed in user can purge the content history of a content posted by a user
+func canPurgeContentHistory(ctx *context.Context, posterID int64) bool {
+	return setting.Repository.Issue.AllowPurgeContentHistory && ctx.IsSigned && ctx.User.ID == posterID
+}
+
+// IssueContentHistory render the versions of the content of an issue or of one of its comments with their differences
+func IssueContentHistory(ctx *context.Context) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+	commentID, posterID := contentHistoryPoster(ctx, issue)
+	if ctx.Written() {
+		return
+	}
+
+	versions, err := issue_service.GetContentVersions(issue.ID, commentID)
+	if err != nil {
+		ctx.ServerError("GetContentVersions", err)
+		return
+	}
+
+	ctx.Data["Title"] = ctx.Tr("repo.issues.content_history.title", issue.Title, issue.Index)
+	ctx.Data["PageIsIssueList"] = !issue.IsPull
+	ctx.Data["PageIsPullList"] = issue.IsPull
+	ctx.Data["Issue"] = issue
+	ctx.Data["CommentID"] = commentID
+	ctx.Data["Versions"] = versions
+	ctx.Data["CanPurgeContentHistory"] = len(versions) > 0 && canPurgeContentHistory(ctx, posterID)
+	ctx.HTML(http.StatusOK, tplIssueContentHistory)
+}
+
+// PurgeIssueContentHistoryPost deletes the versions of the content of an issue or of one of its comments, if the
+// signed in user posted it and the users are allowed to purge the history of their contents
+func PurgeIssueContentHistoryPost(ctx *context.Context) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+	commentID, posterID := contentHistoryPoster(ctx, issue)
+	if ctx.Written() {
+		return
+	}
+	if !canPurgeContentHistory(ctx, posterID) {
+		ctx.Error(http.StatusForbidden)
+		return
+	}
+
+	if err := models.PurgeIssueContentHistory(issue.ID, commentID); err != nil {
+		ctx.ServerError("PurgeIssueContentHistory", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.issues.content_history.purge_success"))
+	ctx.Redirect(issue.HTMLURL())
+}
@@ -9,6 +9,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 )
 
@@ -50,6 +51,12 @@ func AddDependency(ctx *context.Context) {
 		return
 	}
 
+	// Check if the user can see the dependency, as for an issue which does not exist
+	if !issueReadableChecker(ctx)(dep) {
+		ctx.Flash.Error(ctx.Tr("repo.issues.dependency.add_error_dep_issue_not_exist"))
+		return
+	}
+
 	// Check if issue and dependency is the same
 	if dep.ID == issue.ID {
 		ctx.Flash.Error(ctx.Tr("repo.issues.dependency.add_error_same_issue"))
@@ -127,3 +134,38 @@ func RemoveDependency(ctx *context.Context) {
 	// Redirect
 	ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
 }
+
+// issueReadableChecker returns a function telling if the signed in user can read an issue, the permissions of the
+// other repositories being cached
+func issueReadableChecker(ctx *context.Context) func(*models.Issue) bool {
+	perms := map[int64]*models.Permission{ctx.Repo.Repository.ID: &ctx.Repo.Permission}
+	return func(issue *models.Issue) bool {
+		perm, ok := perms[issue.RepoID]
+		if !ok {
+			if err := issue.LoadRepo(); err != nil {
+				log.Error("LoadRepo [issue_id: %d]: %v", issue.ID, err)
+				return false
+			}
+			p, err := models.GetUserRepoPermission(issue.Repo, ctx.User)
+			if err != nil {
+				log.Error("GetUserRepoPermission [repo_id: %d]: %v", issue.RepoID, err)
+				return false
+			}
+			perm = &p
+			perms[issue.RepoID] = perm
+		}
+		return perm.CanReadIssuesOrPulls(issue.IsPull)
+	}
+}
+
+// filterReadableDependencies returns the dependencies whose issue the user can read
+func filterReadableDependencies(deps []*models.DependencyInfo, canRead func(*models.Issue) bool) []*models.DependencyInfo {
+	readable := make([]*models.DependencyInfo, 0, len(deps))
+	for _, dep := range deps {
+		dep.Issue.Repo = &dep.Repository
+		if canRead(&dep.Issue) {
+			readable = append(readable, dep)
+		}
+	}
+	return readable
+}
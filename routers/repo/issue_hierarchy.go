@@ -0,0 +1,66 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+)
+
+// AddSubIssue makes an issue of the repository a sub-issue of the issue
+func AddSubIssue(ctx *context.Context) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+	defer ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
+
+	subIssue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.QueryInt64("sub_issue"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("repo.issues.sub_issues.add_error_not_exist"))
+			return
+		}
+		ctx.ServerError("GetIssueByIndex", err)
+		return
+	}
+
+	if err := models.SetIssueParent(ctx.User, subIssue, issue); err != nil {
+		if models.IsErrCircularSubIssue(err) {
+			ctx.Flash.Error(ctx.Tr("repo.issues.sub_issues.add_error_circular"))
+			return
+		} else if models.IsErrInvalidSubIssue(err) {
+			ctx.Flash.Error(ctx.Tr("repo.issues.sub_issues.add_error_invalid"))
+			return
+		}
+		ctx.ServerError("SetIssueParent", err)
+	}
+}
+
+// RemoveSubIssue removes a sub-issue from the issue
+func RemoveSubIssue(ctx *context.Context) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	subIssue, err := models.GetIssueByID(ctx.QueryInt64("sub_issue_id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetIssueByID", models.IsErrIssueNotExist, err)
+		return
+	}
+	if subIssue.ParentID != issue.ID {
+		ctx.NotFound("RemoveSubIssue", nil)
+		return
+	}
+
+	if err := models.SetIssueParent(ctx.User, subIssue, nil); err != nil {
+		ctx.ServerError("SetIssueParent", err)
+		return
+	}
+	ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
+}
@@ -167,7 +167,7 @@ func UpdateIssueLabel(ctx *context.Context) {
 	switch action := ctx.Query("action"); action {
 	case "clear":
 		for _, issue := range issues {
-			if err := issue_service.ClearLabels(issue, ctx.User); err != nil {
+			if err := issue_service.ClearLabels(ctx.Req.Context(), issue, ctx.User); err != nil {
 				ctx.ServerError("ClearLabels", err)
 				return
 			}
@@ -196,14 +196,14 @@ func UpdateIssueLabel(ctx *context.Context) {
 
 		if action == "attach" {
 			for _, issue := range issues {
-				if err = issue_service.AddLabel(issue, ctx.User, label); err != nil {
+				if err = issue_service.AddLabel(ctx.Req.Context(), issue, ctx.User, label); err != nil {
 					ctx.ServerError("AddLabel", err)
 					return
 				}
 			}
 		} else {
 			for _, issue := range issues {
-				if err = issue_service.RemoveLabel(issue, ctx.User, label); err != nil {
+				if err = issue_service.RemoveLabel(ctx.Req.Context(), issue, ctx.User, label); err != nil {
 					ctx.ServerError("RemoveLabel", err)
 					return
 				}
@@ -215,6 +215,12 @@ func UpdateIssueLabel(ctx *context.Context) {
 		return
 	}
 
+	// The forms which are used without JavaScript go back to the issue
+	if redirectTo := ctx.Query("redirect_to"); len(redirectTo) > 0 {
+		ctx.RedirectToFirst(redirectTo)
+		return
+	}
+
 	ctx.JSON(200, map[string]interface{}{
 		"ok": true,
 	})
@@ -166,3 +166,19 @@ func TestUpdateIssueLabel_Toggle(t *testing.T) {
 		models.CheckConsistencyFor(t, &models.Label{})
 	}
 }
+
+func TestUpdateIssueLabel_Redirect(t *testing.T) {
+	models.PrepareTestEnv(t)
+	ctx := test.MockContext(t, "user2/repo1/issues/labels")
+	test.LoadUser(t, ctx, 2)
+	test.LoadRepo(t, ctx, 1)
+	ctx.Req.Form.Set("issue_ids", "2")
+	ctx.Req.Form.Set("action", "toggle")
+	ctx.Req.Form.Set("id", "2")
+	ctx.Req.Form.Set("redirect_to", "/user2/repo1/issues/2")
+	UpdateIssueLabel(ctx)
+	assert.EqualValues(t, http.StatusFound, ctx.Resp.Status())
+	assert.Equal(t, "/user2/repo1/issues/2", test.RedirectURL(ctx.Resp))
+	models.AssertExistsAndLoadBean(t, &models.IssueLabel{IssueID: 2, LabelID: 2})
+	models.CheckConsistencyFor(t, &models.Label{})
+}
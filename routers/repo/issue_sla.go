@@ -0,0 +1,185 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/web"
+)
+
+const (
+	tplSettingsIssueSLAs base.TplName = "repo/settings/slas"
+	tplIssueSLA          base.TplName = "repo/issue_sla"
+)
+
+// SettingsIssueSLAs render the issue SLAs of a repository
+func SettingsIssueSLAs(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.slas")
+	ctx.Data["PageIsSettingsIssueSLAs"] = true
+	ctx.Data["IssueSLAsLink"] = ctx.Repo.RepoLink + "/settings/slas"
+
+	slas, err := models.GetIssueSLAsByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetIssueSLAsByRepoID", err)
+		return
+	}
+	for _, sla := range slas {
+		if err := sla.LoadAttributes(); err != nil {
+			ctx.ServerError("LoadAttributes", err)
+			return
+		}
+	}
+	ctx.Data["IssueSLAs"] = slas
+
+	labels, err := models.GetLabelsByRepoID(ctx.Repo.Repository.ID, "", models.ListOptions{})
+	if err != nil {
+		ctx.ServerError("GetLabelsByRepoID", err)
+		return
+	}
+	if ctx.Repo.Owner.IsOrganization() {
+		orgLabels, err := models.GetLabelsByOrgID(ctx.Repo.Owner.ID, "", models.ListOptions{})
+		if err != nil {
+			ctx.ServerError("GetLabelsByOrgID", err)
+			return
+		}
+		labels = append(labels, orgLabels...)
+	}
+	ctx.Data["Labels"] = labels
+
+	ctx.HTML(http.StatusOK, tplSettingsIssueSLAs)
+}
+
+// NewIssueSLAPost creates an issue SLA for a repository
+func NewIssueSLAPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.IssueSLAForm)
+	link := ctx.Repo.RepoLink + "/settings/slas"
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(link)
+		return
+	}
+
+	sla := &models.IssueSLA{
+		RepoID:             ctx.Repo.Repository.ID,
+		DoerID:             ctx.User.ID,
+		Name:               form.Name,
+		LabelID:            form.LabelID,
+		FirstResponseHours: form.FirstResponseHours,
+		ResolutionHours:    form.ResolutionHours,
+		BreachLabelID:      form.BreachLabelID,
+	}
+	if err := models.NewIssueSLA(sla); err != nil {
+		if models.IsErrInvalidIssueSLA(err) {
+			ctx.Flash.Error(ctx.Tr("repo.slas.invalid", err.(models.ErrInvalidIssueSLA).Reason))
+			ctx.Redirect(link)
+			return
+		}
+		ctx.ServerError("NewIssueSLA", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.slas.creation_success", sla.Name))
+	ctx.Redirect(link)
+}
+
+// EditIssueSLAPost updates an issue SLA of a repository, the breach label being added by the user from then on
+func EditIssueSLAPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.IssueSLAForm)
+	link := ctx.Repo.RepoLink + "/settings/slas"
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(link)
+		return
+	}
+
+	sla, err := models.GetIssueSLAInRepoByID(ctx.Repo.Repository.ID, form.ID)
+	if err != nil {
+		if models.IsErrIssueSLANotExist(err) {
+			ctx.NotFound("GetIssueSLAInRepoByID", err)
+		} else {
+			ctx.ServerError("GetIssueSLAInRepoByID", err)
+		}
+		return
+	}
+
+	sla.DoerID = ctx.User.ID
+	sla.Name = form.Name
+	sla.LabelID = form.LabelID
+	sla.FirstResponseHours = form.FirstResponseHours
+	sla.ResolutionHours = form.ResolutionHours
+	sla.BreachLabelID = form.BreachLabelID
+	if err := models.UpdateIssueSLA(sla); err != nil {
+		if models.IsErrInvalidIssueSLA(err) {
+			ctx.Flash.Error(ctx.Tr("repo.slas.invalid", err.(models.ErrInvalidIssueSLA).Reason))
+			ctx.Redirect(link)
+			return
+		}
+		ctx.ServerError("UpdateIssueSLA", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.slas.update_success", sla.Name))
+	ctx.Redirect(link)
+}
+
+// DeleteIssueSLAPost deletes an issue SLA of a repository
+func DeleteIssueSLAPost(ctx *context.Context) {
+	if err := models.DeleteIssueSLA(ctx.Repo.Repository.ID, ctx.QueryInt64("id")); err != nil {
+		ctx.Flash.Error("DeleteIssueSLA: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.slas.deletion_success"))
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/settings/slas",
+	})
+}
+
+// IssueSLACompliance render how the issues created within a period met the targets of the SLAs of a repository
+func IssueSLACompliance(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.insights.sla")
+	ctx.Data["PageIsActivity"] = true
+
+	period := ctx.Query("period")
+	timeFrom, ok := models.IssueInsightsPeriodStart(period)
+	if !ok {
+		period = models.DefaultIssueInsightsPeriod
+		timeFrom, _ = models.IssueInsightsPeriodStart(period)
+	}
+	ctx.Data["Period"] = period
+	ctx.Data["PeriodText"] = ctx.Tr("repo.activity.period." + period)
+	ctx.Data["DateFrom"] = timeFrom.Format("January 2, 2006")
+	ctx.Data["DateUntil"] = time.Now().Format("January 2, 2006")
+
+	slas, err := models.GetIssueSLAsByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetIssueSLAsByRepoID", err)
+		return
+	}
+	compliances := make([]*models.IssueSLACompliance, 0, len(slas))
+	for _, sla := range slas {
+		if err := sla.LoadAttributes(); err != nil {
+			ctx.ServerError("LoadAttributes", err)
+			return
+		}
+		compliance, err := sla.GetIssueSLACompliance(timeFrom)
+		if err != nil {
+			ctx.ServerError("GetIssueSLACompliance", err)
+			return
+		}
+		if err := models.IssueList(compliance.Breaching).LoadAttributes(); err != nil {
+			ctx.ServerError("LoadAttributes", err)
+			return
+		}
+		compliances = append(compliances, compliance)
+	}
+	ctx.Data["Compliances"] = compliances
+
+	ctx.HTML(http.StatusOK, tplIssueSLA)
+}
@@ -5,9 +5,16 @@
 package repo
 
 import (
+	"net/http"
+	"strconv"
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/test"
+	"code.gitea.io/gitea/modules/web"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -322,3 +329,96 @@ func TestCombineLabelComments(t *testing.T) {
 		})
 	}
 }
+
+func TestReviewSummaryNotEditable(t *testing.T) {
+	models.PrepareTestEnv(t)
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 2}).(*models.Issue)
+	assert.NoError(t, issue.LoadRepo())
+	summary, err := models.CreateComment(&models.CreateCommentOptions{
+		Type:    models.CommentTypeReview,
+		Doer:    doer,
+		Repo:    issue.Repo,
+		Issue:   issue,
+		Content: "review summary",
+	})
+	assert.NoError(t, err)
+
+	ctx := test.MockContext(t, "user2/repo1/comments/0")
+	test.LoadUser(t, ctx, 2)
+	test.LoadRepo(t, ctx, 1)
+	ctx.IsSigned = true
+	ctx.SetParams(":id", strconv.FormatInt(summary.ID, 10))
+	ctx.Req.Form.Set("content", "edited summary")
+	UpdateCommentContent(ctx)
+	assert.EqualValues(t, http.StatusNoContent, ctx.Resp.Status())
+	models.AssertExistsAndLoadBean(t, &models.Comment{ID: summary.ID, Content: "review summary"})
+
+	ctx = test.MockContext(t, "user2/repo1/comments/0/delete")
+	test.LoadUser(t, ctx, 2)
+	test.LoadRepo(t, ctx, 1)
+	ctx.IsSigned = true
+	ctx.SetParams(":id", strconv.FormatInt(summary.ID, 10))
+	DeleteComment(ctx)
+	assert.EqualValues(t, http.StatusNoContent, ctx.Resp.Status())
+	models.AssertExistsAndLoadBean(t, &models.Comment{ID: summary.ID})
+}
+
+func TestIssueCollaboratorComment(t *testing.T) {
+	models.PrepareTestEnv(t)
+
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 7}).(*models.Issue)
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	assert.NoError(t, models.AddIssueCollaborator(issue, user, doer))
+
+	newComment := func(index int64) *context.Context {
+		ctx := test.MockContext(t, "user2/repo2/issues/0/comments")
+		test.LoadUser(t, ctx, 4)
+		test.LoadRepo(t, ctx, 2)
+		ctx.IsSigned = true
+		ctx.SetParams(":index", strconv.FormatInt(index, 10))
+		web.SetForm(ctx, &auth.CreateCommentForm{Content: "comment from an issue collaborator"})
+		NewComment(ctx)
+		return ctx
+	}
+
+	// the user may comment on the issue they have been invited to
+	ctx := newComment(issue.Index)
+	assert.EqualValues(t, http.StatusFound, ctx.Resp.Status())
+	assert.False(t, ctx.Repo.Permission.HasAccess())
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: issue.ID, PosterID: user.ID, Content: "comment from an issue collaborator"})
+
+	// but not on the other issues of the repository
+	ctx = newComment(1)
+	assert.EqualValues(t, http.StatusNotFound, ctx.Resp.Status())
+	models.AssertNotExistsBean(t, &models.Comment{IssueID: 4, PosterID: user.ID})
+
+	// nor once the invitation has been revoked
+	assert.NoError(t, models.RemoveIssueCollaborator(issue, user.ID))
+	ctx = newComment(issue.Index)
+	assert.EqualValues(t, http.StatusNotFound, ctx.Resp.Status())
+}
+
+func TestIssueCollaboratorPartialAccess(t *testing.T) {
+	models.PrepareTestEnv(t)
+
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 7}).(*models.Issue)
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	assert.NoError(t, models.AddIssueCollaborator(issue, user, doer))
+
+	ctx := test.MockContext(t, "user2/repo2/issues/0/comments")
+	test.LoadUser(t, ctx, 4)
+	test.LoadRepo(t, ctx, 2)
+	// the user may read the code of the repository but not its issues
+	ctx.Repo.Permission.UnitsMode = map[models.UnitType]models.AccessMode{models.UnitTypeCode: models.AccessModeRead}
+	ctx.IsSigned = true
+	ctx.SetParams(":index", strconv.FormatInt(issue.Index, 10))
+	web.SetForm(ctx, &auth.CreateCommentForm{Content: "comment from an issue collaborator with partial access"})
+	NewComment(ctx)
+	assert.EqualValues(t, http.StatusFound, ctx.Resp.Status())
+	assert.True(t, isIssueCollaborator(ctx))
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: issue.ID, PosterID: user.ID, Content: "comment from an issue collaborator with partial access"})
+}
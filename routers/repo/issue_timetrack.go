@@ -5,15 +5,21 @@
 package repo
 
 import (
+	"fmt"
 	"net/http"
 	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/web"
+	issue_service "code.gitea.io/gitea/services/issue"
 )
 
+const tplTimeReport base.TplName = "repo/time_report"
+
 // AddTimeManually tracks time manually
 func AddTimeManually(c *context.Context) {
 	form := web.GetForm(c).(*forms.AddTimeManuallyForm)
@@ -84,3 +90,68 @@ func DeleteTime(c *context.Context) {
 	c.Flash.Success(c.Tr("repo.issues.del_time_history", models.SecToTime(t.Time)))
 	c.Redirect(issue.HTMLURL())
 }
+
+// getTimeReport returns the report of the times tracked on the issues of the repository within the requested date
+// range, only the times tracked by the current user if they cannot write the issues
+func getTimeReport(ctx *context.Context) *models.TrackedTimeReport {
+	if !ctx.Repo.Repository.IsTimetrackerEnabled() {
+		ctx.NotFound("IsTimetrackerEnabled", nil)
+		return nil
+	}
+
+	opts := models.FindTrackedTimesOptions{RepositoryID: ctx.Repo.Repository.ID}
+	canFilterUser := ctx.Repo.CanWrite(models.UnitTypeIssues)
+	if !canFilterUser {
+		opts.UserID = ctx.User.ID
+	} else if name := ctx.QueryTrim("user"); name != "" {
+		user, err := models.GetUserByName(name)
+		if err != nil {
+			ctx.NotFoundOrServerError("GetUserByName", models.IsErrUserNotExist, err)
+			return nil
+		}
+		opts.UserID = user.ID
+	}
+	ctx.Data["CanFilterUser"] = canFilterUser
+	ctx.Data["ReportUser"] = ctx.QueryTrim("user")
+
+	from, until := models.TrackedTimeReportRange(ctx.Query("from"), ctx.Query("until"))
+	group := ctx.Query("group")
+	if !models.IsValidTrackedTimeReportGroup(group) {
+		group = models.TrackedTimeReportWeekly
+	}
+	report, err := models.GetTrackedTimeReport(opts, from, until, group)
+	if err != nil {
+		ctx.ServerError("GetTrackedTimeReport", err)
+		return nil
+	}
+	return report
+}
+
+// TimeReport renders the times tracked on the issues of a repository within a date range, grouped by periods
+func TimeReport(ctx *context.Context) {
+	report := getTimeReport(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.Data["Title"] = ctx.Tr("repo.time_report")
+	ctx.Data["PageIsActivity"] = true
+	ctx.Data["Report"] = report
+	ctx.Data["ExportLink"] = ctx.Link + "/export?" + ctx.Req.URL.RawQuery
+	ctx.HTML(http.StatusOK, tplTimeReport)
+}
+
+// TimeReportExport exports the times tracked on the issues of a repository within a date range as CSV
+func TimeReportExport(ctx *context.Context) {
+	report := getTimeReport(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.Resp.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	ctx.Resp.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-times-%s-%s.csv"`,
+		ctx.Repo.Repository.Name, report.From.Format("20060102"), report.Until.Format("20060102")))
+	if err := issue_service.WriteTrackedTimesCSV(ctx.Resp, report.Times()); err != nil {
+		log.Error("WriteTrackedTimesCSV: %v", err)
+	}
+}
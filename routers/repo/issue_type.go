@@ -0,0 +1,150 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/web"
+)
+
+const (
+	tplSettingsIssueTypes base.TplName = "repo/settings/issue_types"
+)
+
+// SettingsIssueTypes render the issue types of a repository and of its organization
+func SettingsIssueTypes(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.issue_types")
+	ctx.Data["PageIsSettingsIssueTypes"] = true
+	ctx.Data["IssueTypesLink"] = ctx.Repo.RepoLink + "/settings/issue_types"
+
+	var err error
+	ctx.Data["IssueTypes"], err = models.GetIssueTypesByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetIssueTypesByRepoID", err)
+		return
+	}
+	if ctx.Repo.Owner.IsOrganization() {
+		ctx.Data["OrgIssueTypes"], err = models.GetIssueTypesByOrgID(ctx.Repo.Owner.ID)
+		if err != nil {
+			ctx.ServerError("GetIssueTypesByOrgID", err)
+			return
+		}
+	}
+
+	templates := ctx.IssueTemplatesFromDefaultBranch()
+	templateFiles := make([]string, 0, len(templates))
+	for _, template := range templates {
+		templateFiles = append(templateFiles, template.FileName)
+	}
+	ctx.Data["IssueTemplateFiles"] = templateFiles
+
+	ctx.HTML(200, tplSettingsIssueTypes)
+}
+
+// NewIssueTypePost creates an issue type for a repository
+func NewIssueTypePost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.IssueTypeForm)
+	link := ctx.Repo.RepoLink + "/settings/issue_types"
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(link)
+		return
+	}
+
+	t := &models.IssueType{
+		RepoID:       ctx.Repo.Repository.ID,
+		Name:         form.Name,
+		Description:  form.Description,
+		Color:        form.Color,
+		TemplateFile: form.TemplateFile,
+	}
+	if err := models.NewIssueType(t); err != nil {
+		if handleIssueTypeError(ctx, err) {
+			ctx.Redirect(link)
+			return
+		}
+		ctx.ServerError("NewIssueType", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.issue_types.creation_success", t.Name))
+	ctx.Redirect(link)
+}
+
+// EditIssueTypePost updates an issue type of a repository
+func EditIssueTypePost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.IssueTypeForm)
+	link := ctx.Repo.RepoLink + "/settings/issue_types"
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(link)
+		return
+	}
+
+	t, err := models.GetIssueTypeInRepoByID(ctx.Repo.Repository.ID, form.ID)
+	if err != nil {
+		if models.IsErrIssueTypeNotExist(err) {
+			ctx.NotFound("GetIssueTypeInRepoByID", err)
+		} else {
+			ctx.ServerError("GetIssueTypeInRepoByID", err)
+		}
+		return
+	}
+
+	t.Name = form.Name
+	t.Description = form.Description
+	t.Color = form.Color
+	t.TemplateFile = form.TemplateFile
+	if err := models.UpdateIssueType(t); err != nil {
+		if handleIssueTypeError(ctx, err) {
+			ctx.Redirect(link)
+			return
+		}
+		ctx.ServerError("UpdateIssueType", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.issue_types.update_success", t.Name))
+	ctx.Redirect(link)
+}
+
+// DeleteIssueTypePost deletes an issue type of a repository
+func DeleteIssueTypePost(ctx *context.Context) {
+	t, err := models.GetIssueTypeInRepoByID(ctx.Repo.Repository.ID, ctx.QueryInt64("id"))
+	if err != nil {
+		ctx.Flash.Error("GetIssueTypeInRepoByID: " + err.Error())
+	} else if err := models.DeleteIssueType(t); err != nil {
+		ctx.Flash.Error("DeleteIssueType: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.issue_types.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/settings/issue_types",
+	})
+}
+
+// InitializeIssueTypesPost adds the default issue types to a repository
+func InitializeIssueTypesPost(ctx *context.Context) {
+	if err := models.InitializeIssueTypes(0, ctx.Repo.Repository.ID); err != nil {
+		ctx.ServerError("InitializeIssueTypes", err)
+		return
+	}
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/issue_types")
+}
+
+// handleIssueTypeError flashes the errors of an issue type submitted by a user, returning false for the other errors
+func handleIssueTypeError(ctx *context.Context, err error) bool {
+	switch {
+	case models.IsErrIssueTypeAlreadyExist(err):
+		ctx.Flash.Error(ctx.Tr("repo.issue_types.already_exist", err.(models.ErrIssueTypeAlreadyExist).Name))
+	case models.IsErrInvalidIssueType(err):
+		ctx.Flash.Error(ctx.Tr("repo.issue_types.invalid", err.(models.ErrInvalidIssueType).Reason))
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/eventsource"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+const tplMergeTrain base.TplName = "repo/pulls/merge_train"
+
+// mergeTrainBranches returns the names of the branches of the repository which use a merge queue
+func mergeTrainBranches(ctx *context.Context) []string {
+	protectedBranches, err := ctx.Repo.Repository.GetProtectedBranches()
+	if err != nil {
+		ctx.ServerError("GetProtectedBranches", err)
+		return nil
+	}
+	branches := make([]string, 0, len(protectedBranches))
+	for _, protectedBranch := range protectedBranches {
+		if protectedBranch.EnableMergeQueue {
+			branches = append(branches, protectedBranch.BranchName)
+		}
+	}
+	return branches
+}
+
+// MergeTrain shows the pull requests waiting in the merge queue of a branch and its recent failures
+func MergeTrain(ctx *context.Context) {
+	branches := mergeTrainBranches(ctx)
+	if ctx.Written() {
+		return
+	}
+	if len(branches) == 0 {
+		ctx.NotFound("MergeTrain", nil)
+		return
+	}
+
+	branch := ctx.Params("*")
+	if branch == "" {
+		branch = branches[0]
+		if util.IsStringInSlice(ctx.Repo.Repository.DefaultBranch, branches) {
+			branch = ctx.Repo.Repository.DefaultBranch
+		}
+		ctx.Redirect(ctx.Repo.RepoLink + "/merge_train/branch/" + util.PathEscapeSegments(branch))
+		return
+	}
+	if !util.IsStringInSlice(branch, branches) {
+		ctx.NotFound("MergeTrain", nil)
+		return
+	}
+
+	train, err := pull_service.GetMergeTrain(ctx.Repo.Repository, branch)
+	if err != nil {
+		ctx.ServerError("GetMergeTrain", err)
+		return
+	}
+
+	ctx.Data["Title"] = ctx.Tr("repo.merge_train.title", branch)
+	ctx.Data["PageIsPullList"] = true
+	ctx.Data["MergeTrain"] = train
+	ctx.Data["MergeTrainBranches"] = branches
+	ctx.Data["AverageDuration"] = int64(train.AverageDuration / time.Second)
+	ctx.Data["EventsLink"] = ctx.Repo.RepoLink + "/merge_train/events/" + util.PathEscapeSegments(branch)
+
+	ctx.HTML(http.StatusOK, tplMergeTrain)
+}
+
+// MergeTrainEvents streams an event whenever the merge queue of a branch changes
+func MergeTrainEvents(ctx *context.Context) {
+	branch := ctx.Params("*")
+	protectedBranch, err := models.GetProtectedBranchBy(ctx.Repo.Repository.ID, branch)
+	if err != nil {
+		ctx.ServerError("GetProtectedBranchBy", err)
+		return
+	} else if protectedBranch == nil || !protectedBranch.EnableMergeQueue {
+		ctx.NotFound("MergeTrainEvents", nil)
+		return
+	}
+
+	ctx.Resp.Header().Set("Content-Type", "text/event-stream")
+	ctx.Resp.Header().Set("Cache-Control", "no-cache")
+	ctx.Resp.Header().Set("Connection", "keep-alive")
+	ctx.Resp.Header().Set("X-Accel-Buffering", "no")
+	ctx.Resp.WriteHeader(http.StatusOK)
+
+	repoID := ctx.Repo.Repository.ID
+	messageChan := pull_service.RegisterMergeTrainEvents(repoID)
+	defer func() {
+		go func() {
+			pull_service.UnregisterMergeTrainEvents(repoID, messageChan)
+			// ensure the messageChan is closed
+			for range messageChan {
+			}
+		}()
+	}()
+
+	if _, err := ctx.Resp.Write([]byte("\n")); err != nil {
+		log.Error("Unable to write to EventStream: %v", err)
+		return
+	}
+	ctx.Resp.Flush()
+
+	timer := time.NewTicker(30 * time.Second)
+	defer timer.Stop()
+	notify := ctx.Req.Context().Done()
+	shutdownCtx := graceful.GetManager().ShutdownContext()
+
+	for {
+		var event *eventsource.Event
+		select {
+		case <-timer.C:
+			event = &eventsource.Event{Name: "ping"}
+		case <-notify:
+			return
+		case <-shutdownCtx.Done():
+			return
+		case msg, ok := <-messageChan:
+			if !ok {
+				return
+			}
+			if msg.Data != branch {
+				continue
+			}
+			event = msg
+		}
+		if _, err := event.WriteTo(ctx.Resp); err != nil {
+			log.Error("Unable to write to EventStream: %v", err)
+			return
+		}
+		ctx.Resp.Flush()
+	}
+}
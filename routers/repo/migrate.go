@@ -53,6 +53,7 @@ func Migrate(ctx *context.Context) {
 	ctx.Data["issues"] = ctx.Query("issues") == "1"
 	ctx.Data["pull_requests"] = ctx.Query("pull_requests") == "1"
 	ctx.Data["releases"] = ctx.Query("releases") == "1"
+	ctx.Data["convert_ci"] = ctx.Query("convert_ci") == "1"
 
 	ctxUser := checkContextUser(ctx, ctx.QueryInt64("org"))
 	if ctx.Written() {
@@ -191,6 +192,7 @@ func MigratePost(ctx *context.Context) {
 		Comments:       form.Issues || form.PullRequests,
 		PullRequests:   form.PullRequests,
 		Releases:       form.Releases,
+		ConvertCI:      form.ConvertCI && !form.Mirror,
 	}
 	if opts.Mirror {
 		opts.Issues = false
@@ -5,6 +5,9 @@
 package repo
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models"
@@ -17,14 +20,16 @@ import (
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
+	issue_service "code.gitea.io/gitea/services/issue"
 
 	"xorm.io/builder"
 )
 
 const (
-	tplMilestone       base.TplName = "repo/issue/milestones"
-	tplMilestoneNew    base.TplName = "repo/issue/milestone_new"
-	tplMilestoneIssues base.TplName = "repo/issue/milestone_issues"
+	tplMilestone         base.TplName = "repo/issue/milestones"
+	tplMilestoneNew      base.TplName = "repo/issue/milestone_new"
+	tplMilestoneIssues   base.TplName = "repo/issue/milestone_issues"
+	tplMilestoneInsights base.TplName = "repo/issue/milestone_insights"
 )
 
 // Milestones render milestones page
@@ -77,6 +82,10 @@ func Milestones(ctx *context.Context) {
 			return
 		}
 	}
+	if err := miles.LoadTotalWeights(); err != nil {
+		ctx.ServerError("LoadTotalWeights", err)
+		return
+	}
 	for _, m := range miles {
 		m.RenderedContent = string(markdown.Render([]byte(m.Content), ctx.Repo.RepoLink, ctx.Repo.Repository.ComposeMetas()))
 	}
@@ -262,15 +271,113 @@ func MilestoneIssuesAndPulls(ctx *context.Context) {
 	}
 
 	milestone.RenderedContent = string(markdown.Render([]byte(milestone.Content), ctx.Repo.RepoLink, ctx.Repo.Repository.ComposeMetas()))
+	if err := milestone.LoadTotalWeight(); err != nil {
+		ctx.ServerError("LoadTotalWeight", err)
+		return
+	}
 
 	ctx.Data["Title"] = milestone.Name
 	ctx.Data["Milestone"] = milestone
 
 	issues(ctx, milestoneID, 0, util.OptionalBoolNone)
-	ctx.Data["NewIssueChooseTemplate"] = len(ctx.IssueTemplatesFromDefaultBranch()) > 0
+	ctx.Data["NewIssueChooseTemplate"] = ctx.HasIssueTemplateChooser()
 
 	ctx.Data["CanWriteIssues"] = ctx.Repo.CanWriteIssuesOrPulls(false)
 	ctx.Data["CanWritePulls"] = ctx.Repo.CanWriteIssuesOrPulls(true)
 
 	ctx.HTML(200, tplMilestoneIssues)
 }
+
+// the size of the burndown chart, in the units of its view box
+const (
+	burndownWidth  = 700
+	burndownHeight = 200
+)
+
+// MilestoneInsights renders the burndown and the velocity of a milestone
+func MilestoneInsights(ctx *context.Context) {
+	milestone, err := models.GetMilestoneByRepoID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrMilestoneNotExist(err) {
+			ctx.NotFound("GetMilestoneByRepoID", err)
+			return
+		}
+		ctx.ServerError("GetMilestoneByRepoID", err)
+		return
+	}
+
+	stats, err := issue_service.GetMilestoneStats(milestone)
+	if err != nil {
+		ctx.ServerError("GetMilestoneStats", err)
+		return
+	}
+
+	ctx.Data["Title"] = ctx.Tr("repo.milestones.insights_title") + " - " + milestone.Name
+	ctx.Data["PageIsIssueList"] = true
+	ctx.Data["PageIsMilestones"] = true
+	ctx.Data["Milestone"] = milestone
+	ctx.Data["Stats"] = stats
+	ctx.Data["DateFrom"] = stats.From.Format("January 2, 2006")
+	ctx.Data["DateUntil"] = stats.Until.Format("January 2, 2006")
+	ctx.Data["Velocity"] = fmt.Sprintf("%.1f", stats.Velocity)
+	if !stats.ProjectedCompletion.IsZero() {
+		ctx.Data["ProjectedCompletion"] = stats.ProjectedCompletion.Format("January 2, 2006")
+	}
+	last := stats.Burndown[len(stats.Burndown)-1]
+	ctx.Data["NumOpen"] = last.Open
+	ctx.Data["NumClosed"] = last.Closed
+
+	// the chart spans until the due date if the milestone is still open and due later
+	end := stats.Until
+	hasDeadline := milestone.DeadlineUnix > 0 && milestone.DeadlineUnix.Year() < 9999
+	if hasDeadline && !milestone.IsClosed && milestone.DeadlineUnix.AsTime().After(end) {
+		end = milestone.DeadlineUnix.AsTime()
+	}
+	span := end.Sub(stats.From)
+	if span <= 0 {
+		span = time.Hour
+	}
+	maxTotal := int64(1)
+	for _, point := range stats.Burndown {
+		if point.Total() > maxTotal {
+			maxTotal = point.Total()
+		}
+	}
+	x := func(t time.Time) string {
+		return fmt.Sprintf("%.1f", float64(t.Sub(stats.From))/float64(span)*burndownWidth)
+	}
+	y := func(n int64) string {
+		return fmt.Sprintf("%.1f", burndownHeight-float64(n)/float64(maxTotal)*burndownHeight)
+	}
+	openPoints := make([]string, 0, len(stats.Burndown))
+	totalPoints := make([]string, 0, len(stats.Burndown))
+	for _, point := range stats.Burndown {
+		openPoints = append(openPoints, x(point.Time)+","+y(point.Open))
+		totalPoints = append(totalPoints, x(point.Time)+","+y(point.Total()))
+	}
+	ctx.Data["BurndownWidth"] = burndownWidth
+	ctx.Data["BurndownHeight"] = burndownHeight
+	ctx.Data["BurndownOpen"] = strings.Join(openPoints, " ")
+	ctx.Data["BurndownTotal"] = strings.Join(totalPoints, " ")
+	if hasDeadline && milestone.DeadlineUnix.AsTime().After(stats.From) {
+		// the ideal burndown closes all the current issues by the due date
+		ctx.Data["BurndownIdeal"] = x(stats.From) + "," + y(last.Total()) + " " + x(milestone.DeadlineUnix.AsTime()) + "," + y(0)
+	}
+
+	// the heights of the bars which show the weekly velocity, in percent of the best week
+	var maxWeekly int64
+	for _, n := range stats.Weekly {
+		if n > maxWeekly {
+			maxWeekly = n
+		}
+	}
+	weeklyBars := make([]int64, len(stats.Weekly))
+	for i, n := range stats.Weekly {
+		if maxWeekly > 0 && n > 0 {
+			weeklyBars[i] = n * 100 / maxWeekly
+		}
+	}
+	ctx.Data["WeeklyBars"] = weeklyBars
+
+	ctx.HTML(http.StatusOK, tplMilestoneInsights)
+}
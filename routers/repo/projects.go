@@ -108,6 +108,7 @@ func Projects(ctx *context.Context) {
 func NewProject(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.projects.new")
 	ctx.Data["ProjectTypes"] = models.GetProjectsConfig()
+	ctx.Data["SwimlaneTypes"] = models.GetProjectSwimlanesConfig()
 	ctx.Data["CanWriteProjects"] = ctx.Repo.Permission.CanWrite(models.UnitTypeProjects)
 	ctx.HTML(200, tplProjectsNew)
 }
@@ -120,17 +121,19 @@ func NewProjectPost(ctx *context.Context) {
 	if ctx.HasError() {
 		ctx.Data["CanWriteProjects"] = ctx.Repo.Permission.CanWrite(models.UnitTypeProjects)
 		ctx.Data["ProjectTypes"] = models.GetProjectsConfig()
+		ctx.Data["SwimlaneTypes"] = models.GetProjectSwimlanesConfig()
 		ctx.HTML(200, tplProjectsNew)
 		return
 	}
 
 	if err := models.NewProject(&models.Project{
-		RepoID:      ctx.Repo.Repository.ID,
-		Title:       form.Title,
-		Description: form.Content,
-		CreatorID:   ctx.User.ID,
-		BoardType:   form.BoardType,
-		Type:        models.ProjectTypeRepository,
+		RepoID:       ctx.Repo.Repository.ID,
+		Title:        form.Title,
+		Description:  form.Content,
+		CreatorID:    ctx.User.ID,
+		BoardType:    form.BoardType,
+		SwimlaneType: form.SwimlaneType,
+		Type:         models.ProjectTypeRepository,
 	}); err != nil {
 		ctx.ServerError("NewProject", err)
 		return
@@ -197,6 +200,7 @@ func EditProject(ctx *context.Context) {
 	ctx.Data["PageIsProjects"] = true
 	ctx.Data["PageIsEditProjects"] = true
 	ctx.Data["CanWriteProjects"] = ctx.Repo.Permission.CanWrite(models.UnitTypeProjects)
+	ctx.Data["SwimlaneTypes"] = models.GetProjectSwimlanesConfig()
 
 	p, err := models.GetProjectByID(ctx.ParamsInt64(":id"))
 	if err != nil {
@@ -214,6 +218,7 @@ func EditProject(ctx *context.Context) {
 
 	ctx.Data["title"] = p.Title
 	ctx.Data["content"] = p.Description
+	ctx.Data["swimlane_type"] = p.SwimlaneType
 
 	ctx.HTML(200, tplProjectsNew)
 }
@@ -225,6 +230,7 @@ func EditProjectPost(ctx *context.Context) {
 	ctx.Data["PageIsProjects"] = true
 	ctx.Data["PageIsEditProjects"] = true
 	ctx.Data["CanWriteProjects"] = ctx.Repo.Permission.CanWrite(models.UnitTypeProjects)
+	ctx.Data["SwimlaneTypes"] = models.GetProjectSwimlanesConfig()
 
 	if ctx.HasError() {
 		ctx.HTML(200, tplProjectsNew)
@@ -247,6 +253,7 @@ func EditProjectPost(ctx *context.Context) {
 
 	p.Title = form.Title
 	p.Description = form.Content
+	p.SwimlaneType = form.SwimlaneType
 	if err = models.UpdateProject(p); err != nil {
 		ctx.ServerError("UpdateProjects", err)
 		return
@@ -313,8 +320,25 @@ func ViewProject(ctx *context.Context) {
 	project.RenderedContent = string(markdown.Render([]byte(project.Description), ctx.Repo.RepoLink, ctx.Repo.Repository.ComposeMetas()))
 
 	ctx.Data["CanWriteProjects"] = ctx.Repo.Permission.CanWrite(models.UnitTypeProjects)
+	ctx.Data["CanManageBoards"] = ctx.Repo.Permission.CanWrite(models.UnitTypeProjects) && !ctx.Repo.Repository.IsArchived
 	ctx.Data["Project"] = project
+	ctx.Data["ProjectLink"] = fmt.Sprintf("%s/projects/%d", ctx.Repo.RepoLink, project.ID)
 	ctx.Data["Boards"] = boards
+	swimlanes := boards.Swimlanes(project.SwimlaneType)
+	for _, lane := range swimlanes {
+		if lane.ID != 0 {
+			continue
+		}
+		switch project.SwimlaneType {
+		case models.ProjectSwimlaneTypeAssignee:
+			lane.Title = ctx.Tr("repo.projects.swimlanes.no_assignee")
+		case models.ProjectSwimlaneTypeLabel:
+			lane.Title = ctx.Tr("repo.projects.swimlanes.no_label")
+		case models.ProjectSwimlaneTypeMilestone:
+			lane.Title = ctx.Tr("repo.projects.swimlanes.no_milestone")
+		}
+	}
+	ctx.Data["Swimlanes"] = swimlanes
 	ctx.Data["PageIsProjects"] = true
 	ctx.Data["RequiresDraggable"] = true
 
@@ -329,6 +353,22 @@ func UpdateIssueProject(ctx *context.Context) {
 	}
 
 	projectID := ctx.QueryInt64("id")
+	if projectID > 0 {
+		p, err := models.GetProjectByID(projectID)
+		if err != nil {
+			if models.IsErrProjectNotExist(err) {
+				ctx.NotFound("", nil)
+			} else {
+				ctx.ServerError("GetProjectByID", err)
+			}
+			return
+		}
+		if !p.CanContainIssuesOf(ctx.Repo.Repository) {
+			ctx.NotFound("", nil)
+			return
+		}
+	}
+
 	for _, issue := range issues {
 		oldProjectID := issue.ProjectID()
 		if oldProjectID == projectID {
@@ -502,6 +542,22 @@ func EditProjectBoard(ctx *context.Context) {
 		return
 	}
 
+	if form.WIPLimit != nil || form.AutoMoveOnClose != nil || form.AutoMoveOnMerge != nil {
+		if form.WIPLimit != nil {
+			board.WIPLimit = *form.WIPLimit
+		}
+		if form.AutoMoveOnClose != nil {
+			board.AutoMoveOnClose = *form.AutoMoveOnClose
+		}
+		if form.AutoMoveOnMerge != nil {
+			board.AutoMoveOnMerge = *form.AutoMoveOnMerge
+		}
+		if err := models.UpdateProjectBoardSettings(board); err != nil {
+			ctx.ServerError("UpdateProjectBoardSettings", err)
+			return
+		}
+	}
+
 	ctx.JSON(200, map[string]interface{}{
 		"ok": true,
 	})
@@ -54,8 +54,50 @@ var (
 		".github/PULL_REQUEST_TEMPLATE.md",
 		".github/pull_request_template.md",
 	}
+	// the directories of the pull request templates chosen with the template parameter
+	pullRequestTemplateDirCandidates = []string{
+		"PULL_REQUEST_TEMPLATE",
+		"pull_request_template",
+		".gitea/PULL_REQUEST_TEMPLATE",
+		".gitea/pull_request_template",
+		".github/PULL_REQUEST_TEMPLATE",
+		".github/pull_request_template",
+	}
 )
 
+// pullRequestTemplatesFromDefaultBranch returns the file names of the pull request templates of the first template
+// directory of the default branch which has some
+func pullRequestTemplatesFromDefaultBranch(ctx *context.Context) []string {
+	if ctx.Repo.Commit == nil {
+		var err error
+		ctx.Repo.Commit, err = ctx.Repo.GitRepo.GetBranchCommit(ctx.Repo.Repository.DefaultBranch)
+		if err != nil {
+			return nil
+		}
+	}
+
+	for _, dirName := range pullRequestTemplateDirCandidates {
+		tree, err := ctx.Repo.Commit.SubTree(dirName)
+		if err != nil {
+			continue
+		}
+		entries, err := tree.ListEntries()
+		if err != nil {
+			return nil
+		}
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			if entry.IsRegular() && strings.HasSuffix(entry.Name(), ".md") {
+				names = append(names, entry.Name())
+			}
+		}
+		if len(names) > 0 {
+			return names
+		}
+	}
+	return nil
+}
+
 func getRepository(ctx *context.Context, repoID int64) *models.Repository {
 	repo, err := models.GetRepositoryByID(repoID)
 	if err != nil {
@@ -226,7 +268,7 @@ func ForkPost(ctx *context.Context) {
 		}
 	}
 
-	repo, err := repo_service.ForkRepository(ctx.User, ctxUser, forkRepo, form.RepoName, form.Description)
+	repo, err := repo_service.ForkRepository(ctx.Req.Context(), ctx.User, ctxUser, forkRepo, form.RepoName, form.Description)
 	if err != nil {
 		ctx.Data["Err_RepoName"] = true
 		switch {
@@ -332,6 +374,7 @@ func PrepareMergedViewPullInfo(ctx *context.Context, issue *models.Issue) *git.C
 
 	if compareInfo.Commits.Len() != 0 {
 		sha := compareInfo.Commits.Front().Value.(*git.Commit).ID.String()
+		ctx.Data["PullHeadCommitID"] = sha
 		commitStatuses, err := models.GetLatestCommitStatus(ctx.Repo.Repository.ID, sha, models.ListOptions{})
 		if err != nil {
 			ctx.ServerError("GetLatestCommitStatus", err)
@@ -368,8 +411,40 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 		return nil
 	}
 	ctx.Data["EnableStatusCheck"] = pull.ProtectedBranch != nil && pull.ProtectedBranch.EnableStatusCheck
+	ctx.Data["EnableMergeQueue"] = pull.ProtectedBranch != nil && pull.ProtectedBranch.EnableMergeQueue
 
-	baseGitRepo, err := git.OpenRepository(pull.BaseRepo.RepoPath())
+	exist, scheduledPRM, err := models.GetScheduledMergeByPullID(pull.ID)
+	if err != nil {
+		ctx.ServerError("GetScheduledMergeByPullID", err)
+		return nil
+	}
+	if exist {
+		if err := scheduledPRM.LoadDoer(); err != nil {
+			if !models.IsErrUserNotExist(err) {
+				ctx.ServerError("LoadDoer", err)
+				return nil
+			}
+			scheduledPRM.Doer = models.NewGhostUser()
+		}
+		ctx.Data["PullAutoMerge"] = scheduledPRM
+	}
+
+	mergeQueueEntry, err := models.GetMergeQueueEntryByPullID(pull.ID)
+	if err == nil {
+		mergeQueueEntry.Pull = pull
+		position, err := mergeQueueEntry.Position()
+		if err != nil {
+			ctx.ServerError("Position", err)
+			return nil
+		}
+		ctx.Data["MergeQueueEntry"] = mergeQueueEntry
+		ctx.Data["MergeQueuePosition"] = position
+	} else if !models.IsErrMergeQueueEntryNotExist(err) {
+		ctx.ServerError("GetMergeQueueEntryByPullID", err)
+		return nil
+	}
+
+	baseGitRepo, err := git.OpenRepositoryCtx(ctx.Req.Context(), pull.BaseRepo.RepoPath())
 	if err != nil {
 		ctx.ServerError("OpenRepository", err)
 		return nil
@@ -386,6 +461,7 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 			ctx.ServerError(fmt.Sprintf("GetRefCommitID(%s)", pull.GetGitRefName()), err)
 			return nil
 		}
+		ctx.Data["PullHeadCommitID"] = sha
 		commitStatuses, err := models.GetLatestCommitStatus(repo.ID, sha, models.ListOptions{})
 		if err != nil {
 			ctx.ServerError("GetLatestCommitStatus", err)
@@ -420,7 +496,7 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 	var headBranchSha string
 	// HeadRepo may be missing
 	if pull.HeadRepo != nil {
-		headGitRepo, err := git.OpenRepository(pull.HeadRepo.RepoPath())
+		headGitRepo, err := git.OpenRepositoryCtx(ctx.Req.Context(), pull.HeadRepo.RepoPath())
 		if err != nil {
 			ctx.ServerError("OpenRepository", err)
 			return nil
@@ -444,6 +520,11 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 			ctx.ServerError("IsUserAllowedToUpdate", err)
 			return nil
 		}
+		ctx.Data["UpdateByRebaseAllowed"], err = pull_service.IsUserAllowedToUpdateByRebase(pull, ctx.User)
+		if err != nil {
+			ctx.ServerError("IsUserAllowedToUpdateByRebase", err)
+			return nil
+		}
 		ctx.Data["GetCommitMessages"] = pull_service.GetSquashMergeCommitMessages(pull)
 	}
 
@@ -467,6 +548,7 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 		return nil
 	}
 
+	ctx.Data["PullHeadCommitID"] = sha
 	commitStatuses, err := models.GetLatestCommitStatus(repo.ID, sha, models.ListOptions{})
 	if err != nil {
 		ctx.ServerError("GetLatestCommitStatus", err)
@@ -478,14 +560,7 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 	}
 
 	if pull.ProtectedBranch != nil && pull.ProtectedBranch.EnableStatusCheck {
-		ctx.Data["is_context_required"] = func(context string) bool {
-			for _, c := range pull.ProtectedBranch.StatusCheckContexts {
-				if c == context {
-					return true
-				}
-			}
-			return false
-		}
+		ctx.Data["is_context_required"] = pull.ProtectedBranch.IsStatusCheckContextRequired
 		ctx.Data["RequiredStatusCheckState"] = pull_service.MergeRequiredContextsCommitStatus(commitStatuses, pull.ProtectedBranch.StatusCheckContexts)
 	}
 
@@ -697,7 +772,7 @@ func ViewPullFiles(ctx *context.Context) {
 	ctx.HTML(200, tplPullFiles)
 }
 
-// UpdatePullRequest merge PR's baseBranch into headBranch
+// UpdatePullRequest merge PR's baseBranch into headBranch or rebase headBranch on to baseBranch
 func UpdatePullRequest(ctx *context.Context) {
 	issue := checkPullInfo(ctx)
 	if ctx.Written() {
@@ -721,7 +796,15 @@ func UpdatePullRequest(ctx *context.Context) {
 		return
 	}
 
-	allowedUpdate, err := pull_service.IsUserAllowedToUpdate(issue.PullRequest, ctx.User)
+	rebase := ctx.Query("style") == "rebase"
+
+	var allowedUpdate bool
+	var err error
+	if rebase {
+		allowedUpdate, err = pull_service.IsUserAllowedToUpdateByRebase(issue.PullRequest, ctx.User)
+	} else {
+		allowedUpdate, err = pull_service.IsUserAllowedToUpdate(issue.PullRequest, ctx.User)
+	}
 	if err != nil {
 		ctx.ServerError("IsUserAllowedToMerge", err)
 		return
@@ -737,7 +820,22 @@ func UpdatePullRequest(ctx *context.Context) {
 	// default merge commit message
 	message := fmt.Sprintf("Merge branch '%s' into %s", issue.PullRequest.BaseBranch, issue.PullRequest.HeadBranch)
 
-	if err = pull_service.Update(issue.PullRequest, ctx.User, message); err != nil {
+	if err = pull_service.Update(ctx.Req.Context(), issue.PullRequest, ctx.User, message, rebase); err != nil {
+		if models.IsErrRebaseConflicts(err) {
+			conflictError := err.(models.ErrRebaseConflicts)
+			flashError, err := ctx.HTMLString(string(tplAlertDetails), map[string]interface{}{
+				"Message": ctx.Tr("repo.pulls.update_branch_rebase_conflict", utils.SanitizeFlashErrorString(conflictError.CommitSHA)),
+				"Summary": ctx.Tr("repo.pulls.rebase_conflict_summary"),
+				"Details": utils.SanitizeFlashErrorString(conflictError.StdErr) + "<br>" + utils.SanitizeFlashErrorString(conflictError.StdOut),
+			})
+			if err != nil {
+				ctx.ServerError("UpdatePullRequest.HTMLString", err)
+				return
+			}
+			ctx.Flash.Error(flashError)
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(issue.Index))
+			return
+		}
 		if models.IsErrMergeConflicts(err) {
 			conflictError := err.(models.ErrMergeConflicts)
 			flashError, err := ctx.HTMLString(string(tplAlertDetails), map[string]interface{}{
@@ -764,6 +862,36 @@ func UpdatePullRequest(ctx *context.Context) {
 	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(issue.Index))
 }
 
+// ConvertPullRequestToIssue closes a pull request and opens an issue with the same content
+func ConvertPullRequestToIssue(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !ctx.Repo.CanWrite(models.UnitTypePullRequests) || !ctx.Repo.CanWrite(models.UnitTypeIssues) {
+		ctx.NotFound("ConvertPullRequestToIssue", nil)
+		return
+	}
+
+	newIssue, err := pull_service.ConvertToIssue(ctx.Req.Context(), issue.PullRequest, ctx.User)
+	if err != nil {
+		if models.IsErrPullWasClosed(err) || models.IsErrPullRequestHasMerged(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.convert_to_issue_closed"))
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(issue.Index))
+			return
+		} else if models.IsErrDependenciesLeft(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.convert_to_issue_blocked"))
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(issue.Index))
+			return
+		}
+		ctx.ServerError("ConvertToIssue", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.pulls.convert_to_issue_success"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/issues/" + fmt.Sprint(newIssue.Index))
+}
+
 // MergePullRequest response for merging pull request
 func MergePullRequest(ctx *context.Context) {
 	form := web.GetForm(ctx).(*auth.MergePullRequestForm)
@@ -803,7 +931,7 @@ func MergePullRequest(ctx *context.Context) {
 
 	// handle manually-merged mark
 	if models.MergeStyle(form.Do) == models.MergeStyleManuallyMerged {
-		if err = pull_service.MergedManually(pr, ctx.User, ctx.Repo.GitRepo, form.MergeCommitID); err != nil {
+		if err = pull_service.MergedManually(ctx.Req.Context(), pr, ctx.User, ctx.Repo.GitRepo, form.MergeCommitID); err != nil {
 			if models.IsErrInvalidMergeStyle(err) {
 				ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
 				ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(issue.Index))
@@ -834,12 +962,15 @@ func MergePullRequest(ctx *context.Context) {
 		return
 	}
 
+	scheduleAutoMerge := false
 	if err := pull_service.CheckPRReadyToMerge(pr, false); err != nil {
 		if !models.IsErrNotAllowedToMerge(err) {
 			ctx.ServerError("Merge PR status", err)
 			return
 		}
-		if isRepoAdmin, err := models.IsUserRepoAdmin(pr.BaseRepo, ctx.User); err != nil {
+		if form.MergeWhenChecksSucceed {
+			scheduleAutoMerge = true
+		} else if isRepoAdmin, err := models.IsUserRepoAdmin(pr.BaseRepo, ctx.User); err != nil {
 			ctx.ServerError("IsUserRepoAdmin", err)
 			return
 		} else if !isRepoAdmin {
@@ -887,7 +1018,47 @@ func MergePullRequest(ctx *context.Context) {
 		return
 	}
 
-	if err = pull_service.Merge(pr, ctx.User, ctx.Repo.GitRepo, models.MergeStyle(form.Do), message); err != nil {
+	if scheduleAutoMerge {
+		if err := pull_service.ScheduleAutoMerge(ctx.User, pr, models.MergeStyle(form.Do), message); err != nil {
+			if models.IsErrInvalidMergeStyle(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
+			} else if models.IsErrAlreadyScheduledToAutoMerge(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.auto_merge_already_scheduled"))
+			} else {
+				ctx.ServerError("ScheduleAutoMerge", err)
+				return
+			}
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(pr.Index))
+			return
+		}
+		ctx.Flash.Success(ctx.Tr("repo.pulls.auto_merge_has_pending_schedule"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(pr.Index))
+		return
+	}
+
+	if err := pr.LoadProtectedBranch(); err != nil {
+		ctx.ServerError("LoadProtectedBranch", err)
+		return
+	}
+	if pr.ProtectedBranch != nil && pr.ProtectedBranch.EnableMergeQueue {
+		if err := pull_service.AddToMergeQueue(pr, ctx.User, models.MergeStyle(form.Do), message); err != nil {
+			if models.IsErrInvalidMergeStyle(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
+			} else if models.IsErrPullAlreadyInMergeQueue(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.merge_queue.already_added"))
+			} else {
+				ctx.ServerError("AddToMergeQueue", err)
+				return
+			}
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(pr.Index))
+			return
+		}
+		ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue.added"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(pr.Index))
+		return
+	}
+
+	if err = pull_service.Merge(ctx.Req.Context(), pr, ctx.User, ctx.Repo.GitRepo, models.MergeStyle(form.Do), message); err != nil {
 		if models.IsErrInvalidMergeStyle(err) {
 			ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
 			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(pr.Index))
@@ -925,6 +1096,11 @@ func MergePullRequest(ctx *context.Context) {
 			ctx.Flash.Error(ctx.Tr("repo.pulls.unrelated_histories"))
 			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(pr.Index))
 			return
+		} else if models.IsErrMergeDivergingFastForwardOnly(err) {
+			log.Debug("MergeDivergingFastForwardOnly error: %v", err)
+			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_fast_forward_only_diverging"))
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(pr.Index))
+			return
 		} else if git.IsErrPushOutOfDate(err) {
 			log.Debug("MergePushOutOfDate error: %v", err)
 			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_out_of_date"))
@@ -964,6 +1140,70 @@ func MergePullRequest(ctx *context.Context) {
 	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(pr.Index))
 }
 
+// CancelAutoMergePullRequest cancels a scheduled merge of a pull request
+func CancelAutoMergePullRequest(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+
+	exist, scheduledPRM, err := models.GetScheduledMergeByPullID(pr.ID)
+	if err != nil {
+		ctx.ServerError("GetScheduledMergeByPullID", err)
+		return
+	}
+	if exist {
+		if ctx.User.ID != scheduledPRM.DoerID {
+			allowedMerge, err := pull_service.IsUserAllowedToMerge(pr, ctx.Repo.Permission, ctx.User)
+			if err != nil {
+				ctx.ServerError("IsUserAllowedToMerge", err)
+				return
+			}
+			if !allowedMerge {
+				ctx.NotFound("CancelAutoMergePullRequest", nil)
+				return
+			}
+		}
+
+		if err := pull_service.CancelScheduledAutoMerge(ctx.User, pr); err != nil {
+			ctx.ServerError("CancelScheduledAutoMerge", err)
+			return
+		}
+		ctx.Flash.Success(ctx.Tr("repo.pulls.auto_merge_canceled_schedule"))
+	}
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(pr.Index))
+}
+
+// RemoveFromMergeQueue removes a pull request from the merge queue of its base branch
+func RemoveFromMergeQueue(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+
+	allowedMerge, err := pull_service.IsUserAllowedToMerge(pr, ctx.Repo.Permission, ctx.User)
+	if err != nil {
+		ctx.ServerError("IsUserAllowedToMerge", err)
+		return
+	}
+	if !allowedMerge && ctx.User.ID != issue.PosterID {
+		ctx.NotFound("RemoveFromMergeQueue", nil)
+		return
+	}
+
+	if err := pull_service.RemoveFromMergeQueue(pr, ctx.User); err != nil {
+		if !models.IsErrMergeQueueEntryNotExist(err) {
+			ctx.ServerError("RemoveFromMergeQueue", err)
+			return
+		}
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue.removed"))
+	}
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(pr.Index))
+}
+
 func stopTimerIfAvailable(user *models.User, issue *models.Issue) error {
 
 	if models.StopwatchExists(user.ID, issue.ID) {
@@ -1054,7 +1294,7 @@ func CompareAndPullRequestPost(ctx *context.Context) {
 	// FIXME: check error in the case two people send pull request at almost same time, give nice error prompt
 	// instead of 500.
 
-	if err := pull_service.NewPullRequest(repo, pullIssue, labelIDs, attachments, pullRequest, assigneeIDs); err != nil {
+	if err := pull_service.NewPullRequest(ctx.Req.Context(), repo, pullIssue, labelIDs, attachments, pullRequest, assigneeIDs); err != nil {
 		if models.IsErrUserDoesNotHaveAccessToRepo(err) {
 			ctx.Error(400, "UserDoesNotHaveAccessToRepo", err.Error())
 			return
@@ -1120,7 +1360,7 @@ func TriggerTask(ctx *context.Context) {
 
 	log.Trace("TriggerTask '%s/%s' by %s", repo.Name, branch, pusher.Name)
 
-	go pull_service.AddTestPullRequestTask(pusher, repo.ID, branch, true, "", "")
+	go pull_service.AddTestPullRequestTask(ctx.Req.Context(), pusher, repo.ID, branch, true, "", "")
 	ctx.Status(202)
 }
 
@@ -1166,14 +1406,14 @@ func CleanUpPullRequest(ctx *context.Context) {
 
 	fullBranchName := pr.HeadRepo.Owner.Name + "/" + pr.HeadBranch
 
-	gitRepo, err := git.OpenRepository(pr.HeadRepo.RepoPath())
+	gitRepo, err := git.OpenRepositoryCtx(ctx.Req.Context(), pr.HeadRepo.RepoPath())
 	if err != nil {
 		ctx.ServerError(fmt.Sprintf("OpenRepository[%s]", pr.HeadRepo.RepoPath()), err)
 		return
 	}
 	defer gitRepo.Close()
 
-	gitBaseRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	gitBaseRepo, err := git.OpenRepositoryCtx(ctx.Req.Context(), pr.BaseRepo.RepoPath())
 	if err != nil {
 		ctx.ServerError(fmt.Sprintf("OpenRepository[%s]", pr.BaseRepo.RepoPath()), err)
 		return
@@ -1226,7 +1466,7 @@ func CleanUpPullRequest(ctx *context.Context) {
 		return
 	}
 
-	if err := repo_service.PushUpdate(
+	if err := repo_service.PushUpdate(ctx.Req.Context(),
 		&repo_module.PushUpdateOptions{
 			RefFullName:  git.BranchPrefix + pr.HeadBranch,
 			OldCommitID:  branchCommitID,
@@ -1353,7 +1593,7 @@ func UpdatePullRequestTarget(ctx *context.Context) {
 		}
 		return
 	}
-	notification.NotifyPullRequestChangeTargetBranch(ctx.User, pr, targetBranch)
+	notification.NotifyPullRequestChangeTargetBranch(ctx.Req.Context(), ctx.User, pr, targetBranch)
 
 	ctx.JSON(http.StatusOK, map[string]interface{}{
 		"base_branch": pr.BaseBranch,
@@ -6,19 +6,23 @@ package repo
 
 import (
 	"fmt"
+	"net/http"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/gitdiff"
 	pull_service "code.gitea.io/gitea/services/pull"
 )
 
 const (
 	tplConversation base.TplName = "repo/diff/conversation"
 	tplNewComment   base.TplName = "repo/diff/new_comment"
+	tplCodeContext  base.TplName = "repo/diff/code_context"
 )
 
 // RenderNewCodeCommentForm will render the form for creating a new review comment
@@ -61,16 +65,18 @@ func CreateCodeComment(ctx *context.Context) {
 		return
 	}
 
-	signedLine := form.Line
+	signedLine, signedStartLine := form.Line, form.StartLine
 	if form.Side == "previous" {
 		signedLine *= -1
+		signedStartLine *= -1
 	}
 
-	comment, err := pull_service.CreateCodeComment(
+	comment, err := pull_service.CreateCodeComment(ctx.Req.Context(),
 		ctx.User,
 		ctx.Repo.GitRepo,
 		issue,
 		signedLine,
+		signedStartLine,
 		form.Content,
 		form.TreePath,
 		form.IsReview,
@@ -78,6 +84,11 @@ func CreateCodeComment(ctx *context.Context) {
 		form.LatestCommitID,
 	)
 	if err != nil {
+		if models.IsErrInvalidCommentLineRange(err) {
+			ctx.Flash.Error(ctx.Tr("repo.diff.comment.invalid_line_range"))
+			ctx.Redirect(fmt.Sprintf("%s/pulls/%d/files", ctx.Repo.RepoLink, issue.Index))
+			return
+		}
 		ctx.ServerError("CreateCodeComment", err)
 		return
 	}
@@ -169,9 +180,138 @@ func renderConversation(ctx *context.Context, comment *models.Comment) {
 		return
 	}
 	ctx.Data["AfterCommitID"] = pullHeadCommitID
+	if err = comment.Issue.PullRequest.LoadHeadRepo(); err != nil {
+		ctx.ServerError("LoadHeadRepo", err)
+		return
+	}
+	if comment.Issue.PullRequest.HeadRepo != nil {
+		ctx.Data["UpdateAllowed"], err = pull_service.IsUserAllowedToUpdate(comment.Issue.PullRequest, ctx.User)
+		if err != nil {
+			ctx.ServerError("IsUserAllowedToUpdate", err)
+			return
+		}
+	}
 	ctx.HTML(200, tplConversation)
 }
 
+// ApplySuggestion commits the change suggested by a code comment to the head branch of the pull request
+func ApplySuggestion(ctx *context.Context) {
+	comment, err := models.GetCommentByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetCommentByID", models.IsErrCommentNotExist, err)
+		return
+	}
+	if err := comment.LoadIssue(); err != nil {
+		ctx.NotFoundOrServerError("LoadIssue", models.IsErrIssueNotExist, err)
+		return
+	}
+	if comment.Issue.RepoID != ctx.Repo.Repository.ID || !comment.Issue.IsPull {
+		ctx.NotFound("ApplySuggestion", nil)
+		return
+	}
+
+	if err := pull_service.ApplySuggestion(ctx.User, comment); err != nil {
+		if models.IsErrSuggestionNotApplicable(err) {
+			ctx.Flash.Error(ctx.Tr("repo.issues.review.suggestion_not_applicable", err.(models.ErrSuggestionNotApplicable).Reason))
+		} else if models.IsErrUserCannotCommit(err) {
+			ctx.Flash.Error(ctx.Tr("repo.issues.review.suggestion_cannot_commit"))
+		} else {
+			ctx.ServerError("ApplySuggestion", err)
+			return
+		}
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.issues.review.suggestion_applied_success"))
+	}
+
+	ctx.Redirect(fmt.Sprintf("%s/pulls/%d#%s", ctx.Repo.RepoLink, comment.Issue.Index, comment.HashTag()))
+}
+
+// CodeCommentContext renders the lines of context above or below the excerpt of the file of a code
+// comment at the commit the comment was made on, one chunk at a time
+func CodeCommentContext(ctx *context.Context) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !issue.IsPull {
+		ctx.NotFound("CodeCommentContext", nil)
+		return
+	}
+	comment, err := models.GetCommentByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetCommentByID", models.IsErrCommentNotExist, err)
+		return
+	}
+	if comment.IssueID != issue.ID || comment.Type != models.CommentTypeCode || comment.CommitSHA == "" {
+		ctx.NotFound("CodeCommentContext", nil)
+		return
+	}
+	commit, err := ctx.Repo.GitRepo.GetCommit(comment.CommitSHA)
+	if err != nil {
+		ctx.NotFoundOrServerError("GetCommit", git.IsErrNotExist, err)
+		return
+	}
+
+	// left and right are the numbers of the first shown lines when expanding up, and of the last ones when expanding down
+	left := ctx.QueryInt("left")
+	right := ctx.QueryInt("right")
+	chunkSize := gitdiff.BlobExcerptChunkSize
+	var lines []*gitdiff.DiffLine
+	switch ctx.Query("direction") {
+	case "up":
+		if right-1 < chunkSize {
+			chunkSize = right - 1
+		}
+		if chunkSize > 0 {
+			lines, err = getExcerptLines(commit, comment.TreePath, left-chunkSize-1, right-chunkSize-1, chunkSize)
+		}
+		if right-chunkSize > 1 {
+			ctx.Data["ExpandUpQuery"] = fmt.Sprintf("direction=up&left=%d&right=%d", left-chunkSize, right-chunkSize)
+		}
+	case "down":
+		if right >= 0 {
+			// Load one line more to know whether there are more lines below
+			lines, err = getExcerptLines(commit, comment.TreePath, left, right, chunkSize+1)
+		}
+		if len(lines) > chunkSize {
+			lines = lines[:chunkSize]
+			nextLeft := left + chunkSize
+			if left < 0 {
+				// The file has been added, it has no lines in the previous version
+				nextLeft = left
+			}
+			ctx.Data["ExpandDownQuery"] = fmt.Sprintf("direction=down&left=%d&right=%d", nextLeft, right+chunkSize)
+		}
+		if left < 0 {
+			for _, line := range lines {
+				line.LeftIdx = 0
+			}
+		}
+	default:
+		ctx.Error(http.StatusBadRequest, "unknown direction")
+		return
+	}
+	if err != nil {
+		ctx.NotFoundOrServerError("getExcerptLines", git.IsErrNotExist, err)
+		return
+	}
+	for _, line := range lines {
+		// The lines above an added file have no number in the previous version
+		if line.LeftIdx <= 0 {
+			line.LeftIdx = 0
+		}
+	}
+
+	ctx.Data["ContextURL"] = fmt.Sprintf("%s/pulls/%d/comments/%d/context", ctx.Repo.RepoLink, issue.Index, comment.ID)
+	ctx.Data["section"] = &gitdiff.DiffSection{
+		FileName: comment.TreePath,
+		Name:     comment.TreePath,
+		Lines:    lines,
+	}
+	ctx.Data["fileName"] = comment.TreePath
+	ctx.HTML(http.StatusOK, tplCodeContext)
+}
+
 // SubmitReview creates a review out of the existing pending review or creates a new one if no pending review exist
 func SubmitReview(ctx *context.Context) {
 	form := web.GetForm(ctx).(*auth.SubmitReviewForm)
@@ -210,7 +350,7 @@ func SubmitReview(ctx *context.Context) {
 		}
 	}
 
-	_, comm, err := pull_service.SubmitReview(ctx.User, ctx.Repo.GitRepo, issue, reviewType, form.Content, form.CommitID)
+	_, comm, err := pull_service.SubmitReview(ctx.Req.Context(), ctx.User, ctx.Repo.GitRepo, issue, reviewType, form.Content, form.CommitID)
 	if err != nil {
 		if models.IsContentEmptyErr(err) {
 			ctx.Flash.Error(ctx.Tr("repo.issues.review.content.empty"))
@@ -227,7 +367,7 @@ func SubmitReview(ctx *context.Context) {
 // DismissReview dismissing stale review by repo admin
 func DismissReview(ctx *context.Context) {
 	form := web.GetForm(ctx).(*auth.DismissReviewForm)
-	comm, err := pull_service.DismissReview(form.ReviewID, form.Message, ctx.User, true)
+	comm, err := pull_service.DismissReview(ctx.Req.Context(), form.ReviewID, form.Message, ctx.User, true)
 	if err != nil {
 		ctx.ServerError("pull_service.DismissReview", err)
 		return
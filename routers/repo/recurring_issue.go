@@ -0,0 +1,154 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/web"
+)
+
+const (
+	tplSettingsRecurringIssues base.TplName = "repo/settings/recurring_issues"
+)
+
+// SettingsRecurringIssues render the recurring issues of a repository
+func SettingsRecurringIssues(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.recurring_issues")
+	ctx.Data["PageIsSettingsRecurringIssues"] = true
+	ctx.Data["RecurringIssuesLink"] = ctx.Repo.RepoLink + "/settings/recurring_issues"
+
+	rs, err := models.GetRecurringIssuesByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetRecurringIssuesByRepoID", err)
+		return
+	}
+	lastIssueIDs := make([]int64, 0, len(rs))
+	for _, r := range rs {
+		if err := r.LoadDoer(); err != nil {
+			ctx.ServerError("LoadDoer", err)
+			return
+		}
+		if r.LastIssueID > 0 {
+			lastIssueIDs = append(lastIssueIDs, r.LastIssueID)
+		}
+	}
+	lastIssueIndexes := make(map[int64]int64, len(lastIssueIDs))
+	if len(lastIssueIDs) > 0 {
+		lastIssues, err := models.GetIssuesByIDs(lastIssueIDs)
+		if err != nil {
+			ctx.ServerError("GetIssuesByIDs", err)
+			return
+		}
+		for _, issue := range lastIssues {
+			lastIssueIndexes[issue.ID] = issue.Index
+		}
+	}
+	ctx.Data["RecurringIssues"] = rs
+	ctx.Data["LastIssueIndexes"] = lastIssueIndexes
+
+	labels, err := models.GetLabelsByRepoID(ctx.Repo.Repository.ID, "", models.ListOptions{})
+	if err != nil {
+		ctx.ServerError("GetLabelsByRepoID", err)
+		return
+	}
+	if ctx.Repo.Owner.IsOrganization() {
+		orgLabels, err := models.GetLabelsByOrgID(ctx.Repo.Owner.ID, "", models.ListOptions{})
+		if err != nil {
+			ctx.ServerError("GetLabelsByOrgID", err)
+			return
+		}
+		labels = append(labels, orgLabels...)
+	}
+	ctx.Data["Labels"] = labels
+
+	ctx.HTML(200, tplSettingsRecurringIssues)
+}
+
+// NewRecurringIssuePost creates a recurring issue for a repository
+func NewRecurringIssuePost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.RecurringIssueForm)
+	link := ctx.Repo.RepoLink + "/settings/recurring_issues"
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(link)
+		return
+	}
+
+	r := &models.RecurringIssue{
+		RepoID:   ctx.Repo.Repository.ID,
+		DoerID:   ctx.User.ID,
+		Title:    form.Title,
+		Content:  form.Content,
+		Labels:   form.Labels,
+		Schedule: form.Schedule,
+		IsActive: form.IsActive,
+	}
+	if err := models.NewRecurringIssue(r); err != nil {
+		if models.IsErrInvalidRecurringIssue(err) {
+			ctx.Flash.Error(ctx.Tr("repo.recurring_issues.invalid", err.(models.ErrInvalidRecurringIssue).Reason))
+			ctx.Redirect(link)
+			return
+		}
+		ctx.ServerError("NewRecurringIssue", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.recurring_issues.creation_success", r.Title))
+	ctx.Redirect(link)
+}
+
+// EditRecurringIssuePost updates a recurring issue of a repository, the issues being posted by the user from then on
+func EditRecurringIssuePost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.RecurringIssueForm)
+	link := ctx.Repo.RepoLink + "/settings/recurring_issues"
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(link)
+		return
+	}
+
+	r, err := models.GetRecurringIssueInRepoByID(ctx.Repo.Repository.ID, form.ID)
+	if err != nil {
+		if models.IsErrRecurringIssueNotExist(err) {
+			ctx.NotFound("GetRecurringIssueInRepoByID", err)
+		} else {
+			ctx.ServerError("GetRecurringIssueInRepoByID", err)
+		}
+		return
+	}
+
+	r.DoerID = ctx.User.ID
+	r.Title = form.Title
+	r.Content = form.Content
+	r.Labels = form.Labels
+	r.Schedule = form.Schedule
+	r.IsActive = form.IsActive
+	if err := models.UpdateRecurringIssue(r); err != nil {
+		if models.IsErrInvalidRecurringIssue(err) {
+			ctx.Flash.Error(ctx.Tr("repo.recurring_issues.invalid", err.(models.ErrInvalidRecurringIssue).Reason))
+			ctx.Redirect(link)
+			return
+		}
+		ctx.ServerError("UpdateRecurringIssue", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.recurring_issues.update_success", r.Title))
+	ctx.Redirect(link)
+}
+
+// DeleteRecurringIssuePost deletes a recurring issue of a repository
+func DeleteRecurringIssuePost(ctx *context.Context) {
+	if err := models.DeleteRecurringIssue(ctx.Repo.Repository.ID, ctx.QueryInt64("id")); err != nil {
+		ctx.Flash.Error("DeleteRecurringIssue: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.recurring_issues.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/settings/recurring_issues",
+	})
+}
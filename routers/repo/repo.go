@@ -17,6 +17,7 @@ import (
 	auth "code.gitea.io/gitea/modules/forms"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/web"
 	archiver_service "code.gitea.io/gitea/services/archiver"
 	repo_service "code.gitea.io/gitea/services/repository"
@@ -233,14 +234,14 @@ func CreatePost(ctx *context.Context) {
 			return
 		}
 
-		repo, err = repo_service.GenerateRepository(ctx.User, ctxUser, templateRepo, opts)
+		repo, err = repo_service.GenerateRepository(ctx.Req.Context(), ctx.User, ctxUser, templateRepo, opts)
 		if err == nil {
 			log.Trace("Repository generated [%d]: %s/%s", repo.ID, ctxUser.Name, repo.Name)
 			ctx.Redirect(setting.AppSubURL + "/" + ctxUser.Name + "/" + repo.Name)
 			return
 		}
 	} else {
-		repo, err = repo_service.CreateRepository(ctx.User, ctxUser, models.CreateRepoOptions{
+		repo, err = repo_service.CreateRepository(ctx.Req.Context(), ctx.User, ctxUser, models.CreateRepoOptions{
 			Name:          form.RepoName,
 			Description:   form.Description,
 			Gitignores:    form.Gitignores,
@@ -313,7 +314,7 @@ func acceptOrRejectRepoTransfer(ctx *context.Context, accept bool) error {
 	}
 
 	if accept {
-		if err := repo_service.TransferOwnership(repoTransfer.Doer, repoTransfer.Recipient, ctx.Repo.Repository, repoTransfer.Teams); err != nil {
+		if err := repo_service.TransferOwnership(ctx.Req.Context(), repoTransfer.Doer, repoTransfer.Recipient, ctx.Repo.Repository, repoTransfer.Teams); err != nil {
 			return err
 		}
 		ctx.Flash.Success(ctx.Tr("repo.settings.transfer.success"))
@@ -321,6 +322,7 @@ func acceptOrRejectRepoTransfer(ctx *context.Context, accept bool) error {
 		if err := models.CancelRepositoryTransfer(ctx.Repo.Repository); err != nil {
 			return err
 		}
+		repo_service.RecordEvent(ctx.User, ctx.Repo.Repository, models.RepoEventTransferCancel, repoTransfer.Recipient.Name, "")
 		ctx.Flash.Success(ctx.Tr("repo.settings.transfer.rejected"))
 	}
 
@@ -377,16 +379,42 @@ func Download(ctx *context.Context) {
 		complete = aReq.WaitForCompletion(ctx)
 	}
 
-	if complete {
-		ctx.ServeFile(aReq.GetArchivePath(), downloadName)
-	} else {
+	if !complete {
+		if aReq.Status() == archiver_service.ArchiveFailed {
+			ctx.Error(500, "the archive could not be generated")
+			return
+		}
 		ctx.Error(404)
+		return
+	}
+
+	if setting.RepoArchive.ServeDirect {
+		// If we have a signed url (S3, object storage), redirect to this directly.
+		u, err := storage.RepoArchives.URL(aReq.GetArchivePath(), downloadName)
+		if u != nil && err == nil {
+			ctx.Redirect(u.String())
+			return
+		}
+	}
+
+	fr, err := storage.RepoArchives.Open(aReq.GetArchivePath())
+	if err != nil {
+		ctx.ServerError("Open", err)
+		return
+	}
+	defer fr.Close()
+
+	var modTime time.Time
+	if fi, err := fr.Stat(); err == nil {
+		modTime = fi.ModTime()
 	}
+	ctx.ServeContent(downloadName, fr, modTime)
 }
 
 // InitiateDownload will enqueue an archival request, as needed.  It may submit
 // a request that's already in-progress, but the archiver service will just
-// kind of drop it on the floor if this is the case.
+// kind of drop it on the floor if this is the case.  It is polled by the
+// clients to follow the progress of the archive until it is complete or failed.
 func InitiateDownload(ctx *context.Context) {
 	uri := ctx.Params("*")
 	aReq := archiver_service.DeriveRequestFrom(ctx, uri)
@@ -404,5 +432,6 @@ func InitiateDownload(ctx *context.Context) {
 
 	ctx.JSON(200, map[string]interface{}{
 		"complete": complete,
+		"status":   aReq.Status().String(),
 	})
 }
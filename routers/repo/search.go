@@ -24,31 +24,51 @@ func Search(ctx *context.Context) {
 	}
 	language := strings.TrimSpace(ctx.Query("l"))
 	keyword := strings.TrimSpace(ctx.Query("q"))
+	treePath := strings.Trim(strings.TrimSpace(ctx.Query("path")), "/")
 	page := ctx.QueryInt("page")
 	if page <= 0 {
 		page = 1
 	}
 	queryType := strings.TrimSpace(ctx.Query("t"))
-	isMatch := queryType == "match"
 
-	total, searchResults, searchResultLanguages, err := code_indexer.PerformSearch([]int64{ctx.Repo.Repository.ID},
-		language, keyword, page, setting.UI.RepoSearchPagingNum, isMatch)
-	if err != nil {
-		ctx.ServerError("SearchResults", err)
-		return
-	}
 	ctx.Data["Keyword"] = keyword
 	ctx.Data["Language"] = language
+	ctx.Data["Path"] = treePath
 	ctx.Data["queryType"] = queryType
+	ctx.Data["SupportsRegexpSearch"] = code_indexer.SupportsRegexpSearch()
 	ctx.Data["SourcePath"] = path.Join(setting.AppSubURL, ctx.Repo.Repository.Owner.Name, ctx.Repo.Repository.Name)
-	ctx.Data["SearchResults"] = searchResults
-	ctx.Data["SearchResultLanguages"] = searchResultLanguages
 	ctx.Data["RequireHighlightJS"] = true
 	ctx.Data["PageIsViewCode"] = true
 
+	total, searchResults, facets, err := code_indexer.PerformSearch(&code_indexer.SearchOptions{
+		RepoIDs:  []int64{ctx.Repo.Repository.ID},
+		Language: language,
+		Path:     treePath,
+		Keyword:  keyword,
+		Mode:     code_indexer.ParseSearchMode(queryType),
+		Page:     page,
+		PageSize: setting.UI.RepoSearchPagingNum,
+	})
+	if err != nil {
+		if code_indexer.IsErrInvalidSearchRegexp(err) {
+			ctx.Flash.Error(ctx.Tr("repo.search.invalid_regexp", err.(code_indexer.ErrInvalidSearchRegexp).Err.Error()), true)
+		} else if err == code_indexer.ErrRegexpSearchUnsupported {
+			ctx.Flash.Error(ctx.Tr("repo.search.regexp_unsupported"), true)
+		} else {
+			ctx.ServerError("SearchResults", err)
+			return
+		}
+	}
+	ctx.Data["SearchResults"] = searchResults
+	if facets != nil {
+		ctx.Data["SearchResultLanguages"] = facets.Languages
+		ctx.Data["SearchResultDirectories"] = facets.Directories
+	}
+
 	pager := context.NewPagination(total, setting.UI.RepoSearchPagingNum, page, 5)
 	pager.SetDefaultParams(ctx)
 	pager.AddParam(ctx, "l", "Language")
+	pager.AddParam(ctx, "path", "Path")
 	ctx.Data["Page"] = pager
 
 	ctx.HTML(200, tplSearch)
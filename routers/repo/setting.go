@@ -22,6 +22,7 @@ import (
 	"code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/task"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/modules/web"
@@ -51,9 +52,95 @@ func Settings(ctx *context.Context) {
 	ctx.Data["SigningKeyAvailable"] = len(signing) > 0
 	ctx.Data["SigningSettings"] = setting.Repository.Signing
 
+	sshSigningKey, err := models.GetRepoSigningKey(ctx.Repo.Repository.ID)
+	if err != nil && !models.IsErrRepoSigningKeyNotExist(err) {
+		ctx.ServerError("GetRepoSigningKey", err)
+		return
+	}
+	ctx.Data["SSHSigningKey"] = sshSigningKey
+
+	ctx.Data["RepoHealth"], err = models.GetRepoHealth(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetRepoHealth", err)
+		return
+	}
+
+	if ctx.Repo.Owner.IsOrganization() {
+		teams, err := ctx.Repo.Repository.GetRepoTeams()
+		if err != nil {
+			ctx.ServerError("GetRepoTeams", err)
+			return
+		}
+		ctx.Data["ReviewerTeams"] = teams
+	}
+
+	if canMigrateToLFS(ctx.Repo.Repository) {
+		lfsMigrateTask, err := models.GetLastRepoTask(ctx.Repo.Repository.ID, structs.TaskTypeMigrateLFS)
+		if err != nil && !models.IsErrTaskDoesNotExist(err) {
+			ctx.ServerError("GetLastRepoTask", err)
+			return
+		}
+		ctx.Data["CanMigrateLFS"] = true
+		ctx.Data["LFSMigrateTask"] = lfsMigrateTask
+	}
+
+	canSync, err := canSyncMigration(ctx.Repo.Repository)
+	if err != nil {
+		ctx.ServerError("canSyncMigration", err)
+		return
+	}
+	if canSync {
+		syncMigrationTask, err := models.GetLastRepoTask(ctx.Repo.Repository.ID, structs.TaskTypeSyncMigration)
+		if err != nil && !models.IsErrTaskDoesNotExist(err) {
+			ctx.ServerError("GetLastRepoTask", err)
+			return
+		}
+		ctx.Data["CanSyncMigration"] = true
+		ctx.Data["SyncMigrationTask"] = syncMigrationTask
+	}
+
+	if migrations.IsExportSupported(ctx.Repo.Repository) {
+		exportTask, err := models.GetLastRepoTask(ctx.Repo.Repository.ID, structs.TaskTypeExportRepo)
+		if err != nil && !models.IsErrTaskDoesNotExist(err) {
+			ctx.ServerError("GetLastRepoTask", err)
+			return
+		}
+		if exportTask != nil {
+			opts, err := task.GetExportOptions(exportTask)
+			if err != nil {
+				ctx.ServerError("GetExportOptions", err)
+				return
+			}
+			ctx.Data["ExportTaskURL"] = opts.RemoteURL
+		}
+		ctx.Data["CanExport"] = true
+		ctx.Data["ExportTask"] = exportTask
+	}
+
 	ctx.HTML(200, tplSettingsOptions)
 }
 
+// canSyncMigration returns true if what was created on the upstream of the repository since its migration can be
+// pulled in, which needs its finished migration task
+func canSyncMigration(repo *models.Repository) (bool, error) {
+	if !migrations.IsSyncSupported(repo) {
+		return false, nil
+	}
+	t, err := models.GetMigratingTask(repo.ID)
+	if err != nil {
+		if models.IsErrTaskDoesNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return t.Status == structs.TaskStatusFinished, nil
+}
+
+// canMigrateToLFS returns true if the large files of the repository can be moved into LFS
+func canMigrateToLFS(repo *models.Repository) bool {
+	return setting.LFS.StartServer && !repo.IsMirror && !repo.IsArchived && !repo.IsEmpty
+}
+
 // SettingsPost response for changes of a repository
 func SettingsPost(ctx *context.Context) {
 	form := web.GetForm(ctx).(*auth.RepoSettingForm)
@@ -77,7 +164,7 @@ func SettingsPost(ctx *context.Context) {
 				ctx.Repo.GitRepo.Close()
 				ctx.Repo.GitRepo = nil
 			}
-			if err := repo_service.ChangeRepositoryName(ctx.User, repo, newRepoName); err != nil {
+			if err := repo_service.ChangeRepositoryName(ctx.Req.Context(), ctx.User, repo, newRepoName); err != nil {
 				ctx.Data["Err_RepoName"] = true
 				switch {
 				case models.IsErrRepoAlreadyExist(err):
@@ -152,11 +239,7 @@ func SettingsPost(ctx *context.Context) {
 		} else {
 			ctx.Repo.Mirror.EnablePrune = form.EnablePrune
 			ctx.Repo.Mirror.Interval = interval
-			if interval != 0 {
-				ctx.Repo.Mirror.NextUpdateUnix = timeutil.TimeStampNow().AddDuration(interval)
-			} else {
-				ctx.Repo.Mirror.NextUpdateUnix = 0
-			}
+			ctx.Repo.Mirror.ScheduleNextUpdate()
 			if err := models.UpdateMirror(ctx.Repo.Mirror); err != nil {
 				ctx.Data["Err_Interval"] = true
 				ctx.RenderWithErr(ctx.Tr("repo.mirror_interval_invalid"), tplSettingsOptions, &form)
@@ -282,6 +365,11 @@ func SettingsPost(ctx *context.Context) {
 			})
 			deleteUnitTypes = append(deleteUnitTypes, models.UnitTypeIssues)
 		} else if form.EnableIssues && !form.EnableExternalTracker && !models.UnitTypeIssues.UnitGlobalDisabled() {
+			if form.EnableDueDateReminders && (form.DueDateReminderDays < 1 || form.DueDateReminderDays > models.MaxDueDateReminderDays) {
+				ctx.Flash.Error(ctx.Tr("repo.settings.due_date_reminder_days_error", models.MaxDueDateReminderDays))
+				ctx.Redirect(repo.Link() + "/settings")
+				return
+			}
 			units = append(units, models.RepoUnit{
 				RepoID: repo.ID,
 				Type:   models.UnitTypeIssues,
@@ -289,6 +377,8 @@ func SettingsPost(ctx *context.Context) {
 					EnableTimetracker:                form.EnableTimetracker,
 					AllowOnlyContributorsToTrackTime: form.AllowOnlyContributorsToTrackTime,
 					EnableDependencies:               form.EnableIssueDependencies,
+					EnableDueDateReminders:           form.EnableDueDateReminders,
+					DueDateReminderDays:              form.DueDateReminderDays,
 				},
 			})
 			deleteUnitTypes = append(deleteUnitTypes, models.UnitTypeExternalTracker)
@@ -311,6 +401,20 @@ func SettingsPost(ctx *context.Context) {
 		}
 
 		if form.EnablePulls && !models.UnitTypePullRequests.UnitGlobalDisabled() {
+			strategy := models.ReviewerAssignmentStrategy(form.PullsAutoAssignReviewersStrategy)
+			if !strategy.IsValid() {
+				strategy = models.ReviewerAssignmentRoundRobin
+			}
+			teamID := form.PullsAutoAssignReviewersTeamID
+			if teamID > 0 {
+				if team, err := models.GetTeamByID(teamID); err != nil || team.OrgID != repo.OwnerID {
+					ctx.NotFound("GetTeamByID", err)
+					return
+				}
+			}
+			if form.PullsAutoAssignReviewersCount < 0 {
+				form.PullsAutoAssignReviewersCount = 0
+			}
 			units = append(units, models.RepoUnit{
 				RepoID: repo.ID,
 				Type:   models.UnitTypePullRequests,
@@ -320,8 +424,13 @@ func SettingsPost(ctx *context.Context) {
 					AllowRebase:               form.PullsAllowRebase,
 					AllowRebaseMerge:          form.PullsAllowRebaseMerge,
 					AllowSquash:               form.PullsAllowSquash,
+					AllowFastForwardOnly:      form.PullsAllowFastForwardOnly,
 					AllowManualMerge:          form.PullsAllowManualMerge,
 					AutodetectManualMerge:     form.EnableAutodetectManualMerge,
+
+					AutoAssignReviewersTeamID:   teamID,
+					AutoAssignReviewersCount:    form.PullsAutoAssignReviewersCount,
+					AutoAssignReviewersStrategy: strategy,
 				},
 			})
 		} else if !models.UnitTypePullRequests.UnitGlobalDisabled() {
@@ -363,6 +472,120 @@ func SettingsPost(ctx *context.Context) {
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
 
+	case "maintenance":
+		var end timeutil.TimeStamp
+		if form.IsMaintenance && len(form.MaintenanceEnd) > 0 {
+			t, err := time.ParseInLocation("2006-01-02T15:04", form.MaintenanceEnd, time.Local)
+			if err != nil || t.Before(time.Now()) {
+				ctx.Flash.Error(ctx.Tr("repo.settings.maintenance.invalid_end"))
+				ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+				return
+			}
+			end = timeutil.TimeStamp(t.Unix())
+		}
+
+		if err := repo.SetMaintenanceMode(form.IsMaintenance, end, strings.TrimSpace(form.MaintenanceMessage)); err != nil {
+			ctx.ServerError("SetMaintenanceMode", err)
+			return
+		}
+		log.Trace("Repository maintenance mode updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+
+	case "git_maintenance":
+		if err := repo_service.QueueRepoMaintenance(repo.ID, true); err != nil {
+			ctx.ServerError("QueueRepoMaintenance", err)
+			return
+		}
+		log.Trace("Repository maintenance queued: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.git_maintenance.queued"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+
+	case "sync_migration":
+		if canSync, err := canSyncMigration(repo); err != nil {
+			ctx.ServerError("canSyncMigration", err)
+			return
+		} else if !canSync {
+			ctx.Error(404)
+			return
+		}
+		if syncing, err := task.IsSyncingMigration(repo.ID); err != nil {
+			ctx.ServerError("IsSyncingMigration", err)
+			return
+		} else if syncing {
+			ctx.Flash.Error(ctx.Tr("repo.settings.sync_migration.running"))
+			ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+			return
+		}
+		if _, err := task.SyncMigratedRepository(ctx.User, repo); err != nil {
+			ctx.ServerError("SyncMigratedRepository", err)
+			return
+		}
+		log.Trace("Repository sync with its upstream queued: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.sync_migration.queued"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+
+	case "export":
+		if !migrations.IsExportSupported(repo) {
+			ctx.Error(404)
+			return
+		}
+		opts := migrations.ExportOptions{
+			GitServiceType: structs.GitServiceType(form.ExportService),
+			RemoteURL:      strings.TrimSpace(form.ExportURL),
+			AuthToken:      strings.TrimSpace(form.ExportToken),
+			Issues:         form.ExportIssues,
+			Comments:       form.ExportComments,
+			Labels:         form.ExportLabels,
+			Milestones:     form.ExportMilestones,
+			Releases:       form.ExportReleases,
+		}
+		if err := migrations.CheckExportOptions(ctx.User, opts); err != nil {
+			log.Trace("Invalid export options of %s: %v", repo.FullName(), err)
+			ctx.Flash.Error(ctx.Tr("repo.settings.export.invalid"))
+			ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+			return
+		}
+		if exporting, err := task.IsExportingRepository(repo.ID); err != nil {
+			ctx.ServerError("IsExportingRepository", err)
+			return
+		} else if exporting {
+			ctx.Flash.Error(ctx.Tr("repo.settings.export.running"))
+			ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+			return
+		}
+		if _, err := task.ExportRepository(ctx.User, repo, opts); err != nil {
+			ctx.ServerError("ExportRepository", err)
+			return
+		}
+		log.Trace("Repository export to %s queued: %s/%s", opts.RemoteURL, ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.export.queued"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+
+	case "generate_ssh_signing_key":
+		if _, err := models.GenerateRepoSigningKey(repo); err != nil {
+			ctx.ServerError("GenerateRepoSigningKey", err)
+			return
+		}
+		log.Trace("Repository SSH signing key generated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.ssh_signing_key.generate_success"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+
+	case "delete_ssh_signing_key":
+		if err := models.DeleteRepoSigningKey(repo.ID); err != nil {
+			ctx.ServerError("DeleteRepoSigningKey", err)
+			return
+		}
+		log.Trace("Repository SSH signing key deleted: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.ssh_signing_key.delete_success"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+
 	case "admin":
 		if !ctx.User.IsAdmin {
 			ctx.Error(403)
@@ -410,6 +633,37 @@ func SettingsPost(ctx *context.Context) {
 		ctx.Flash.Success(ctx.Tr("repo.settings.convert_succeed"))
 		ctx.Redirect(repo.Link())
 
+	case "migrate-lfs":
+		if !ctx.Repo.IsOwner() || !canMigrateToLFS(repo) {
+			ctx.Error(404)
+			return
+		}
+		if repo.Name != form.RepoName {
+			ctx.RenderWithErr(ctx.Tr("form.enterred_invalid_repo_name"), tplSettingsOptions, nil)
+			return
+		}
+		if form.LFSMigrateAbove <= 0 {
+			ctx.Flash.Error(ctx.Tr("repo.settings.migrate_lfs_invalid_size"))
+			ctx.Redirect(repo.Link() + "/settings")
+			return
+		}
+
+		if migrating, err := task.IsMigratingToLFS(repo.ID); err != nil {
+			ctx.ServerError("IsMigratingToLFS", err)
+			return
+		} else if migrating {
+			ctx.Flash.Error(ctx.Tr("repo.settings.migrate_lfs_running"))
+			ctx.Redirect(repo.Link() + "/settings")
+			return
+		}
+		if _, err := task.MigrateRepositoryToLFS(ctx.User, repo, task.MigrateToLFSOptions{Above: form.LFSMigrateAbove * 1024 * 1024}); err != nil {
+			ctx.ServerError("MigrateRepositoryToLFS", err)
+			return
+		}
+		log.Trace("Repository files larger than %d MiB queued to be moved into LFS: %s", form.LFSMigrateAbove, repo.FullName())
+		ctx.Flash.Success(ctx.Tr("repo.settings.migrate_lfs_queued"))
+		ctx.Redirect(repo.Link() + "/settings")
+
 	case "convert_fork":
 		if !ctx.Repo.IsOwner() {
 			ctx.Error(404)
@@ -481,7 +735,7 @@ func SettingsPost(ctx *context.Context) {
 			ctx.Repo.GitRepo = nil
 		}
 
-		if err := repo_service.StartRepositoryTransfer(ctx.User, newOwner, repo, nil); err != nil {
+		if err := repo_service.StartRepositoryTransfer(ctx.Req.Context(), ctx.User, newOwner, repo, nil); err != nil {
 			if models.IsErrRepoAlreadyExist(err) {
 				ctx.RenderWithErr(ctx.Tr("repo.settings.new_owner_has_same_repo"), tplSettingsOptions, nil)
 			} else if models.IsErrRepoTransferInProgress(err) {
@@ -524,6 +778,7 @@ func SettingsPost(ctx *context.Context) {
 			ctx.ServerError("CancelRepositoryTransfer", err)
 			return
 		}
+		repo_service.RecordEvent(ctx.User, ctx.Repo.Repository, models.RepoEventTransferCancel, repoTransfer.Recipient.Name, "")
 
 		log.Trace("Repository transfer process was cancelled: %s/%s ", ctx.Repo.Owner.Name, repo.Name)
 		ctx.Flash.Success(ctx.Tr("repo.settings.transfer_abort_success", repoTransfer.Recipient.Name))
@@ -539,7 +794,7 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
-		if err := repo_service.DeleteRepository(ctx.User, ctx.Repo.Repository); err != nil {
+		if err := repo_service.DeleteRepository(ctx.Req.Context(), ctx.User, ctx.Repo.Repository); err != nil {
 			ctx.ServerError("DeleteRepository", err)
 			return
 		}
@@ -682,6 +937,7 @@ func CollaborationPost(ctx *context.Context) {
 		ctx.ServerError("AddCollaborator", err)
 		return
 	}
+	repo_service.RecordEvent(ctx.User, ctx.Repo.Repository, models.RepoEventCollaboratorAdd, u.Name, models.AccessModeWrite.String())
 
 	if setting.Service.EnableNotifyMail {
 		mailer.SendCollaboratorMail(u, ctx.User, ctx.Repo.Repository)
@@ -693,18 +949,29 @@ func CollaborationPost(ctx *context.Context) {
 
 // ChangeCollaborationAccessMode response for changing access of a collaboration
 func ChangeCollaborationAccessMode(ctx *context.Context) {
-	if err := ctx.Repo.Repository.ChangeCollaborationAccessMode(
-		ctx.QueryInt64("uid"),
-		models.AccessMode(ctx.QueryInt("mode"))); err != nil {
+	u, err := models.GetUserByID(ctx.QueryInt64("uid"))
+	if err != nil {
+		log.Error("GetUserByID: %v", err)
+		return
+	}
+	mode := models.AccessMode(ctx.QueryInt("mode"))
+	if err := ctx.Repo.Repository.ChangeCollaborationAccessMode(u.ID, mode); err != nil {
 		log.Error("ChangeCollaborationAccessMode: %v", err)
+		return
 	}
+	repo_service.RecordEvent(ctx.User, ctx.Repo.Repository, models.RepoEventCollaboratorAccessChange, u.Name, mode.String())
 }
 
 // DeleteCollaboration delete a collaboration for a repository
 func DeleteCollaboration(ctx *context.Context) {
-	if err := ctx.Repo.Repository.DeleteCollaboration(ctx.QueryInt64("id")); err != nil {
+	u, err := models.GetUserByID(ctx.QueryInt64("id"))
+	if err == nil {
+		err = ctx.Repo.Repository.DeleteCollaboration(u.ID)
+	}
+	if err != nil {
 		ctx.Flash.Error("DeleteCollaboration: " + err.Error())
 	} else {
+		repo_service.RecordEvent(ctx.User, ctx.Repo.Repository, models.RepoEventCollaboratorRemove, u.Name, "")
 		ctx.Flash.Success(ctx.Tr("repo.settings.remove_collaborator_success"))
 	}
 
@@ -0,0 +1,45 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplSettingsEvents base.TplName = "repo/settings/events"
+)
+
+// SettingsEvents render the timeline of the administrative events of a repository
+func SettingsEvents(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.events")
+	ctx.Data["PageIsSettingsEvents"] = true
+
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	events, count, err := models.FindRepoEvents(&models.FindRepoEventsOptions{
+		ListOptions: models.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.FeedPagingNum,
+		},
+		RepoID: ctx.Repo.Repository.ID,
+	})
+	if err != nil {
+		ctx.ServerError("FindRepoEvents", err)
+		return
+	}
+	ctx.Data["Events"] = events
+	ctx.Data["Total"] = count
+
+	pager := context.NewPagination(int(count), setting.UI.FeedPagingNum, page, 5)
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(200, tplSettingsEvents)
+}
@@ -16,8 +16,10 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	pull_service "code.gitea.io/gitea/services/pull"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 // ProtectedBranch render the page to protect the repository
@@ -118,7 +120,17 @@ func SettingsProtectedBranch(c *context.Context) {
 			BranchName: branch,
 		}
 	}
+	c.Data["ProtectionEnabled"] = protectBranch.IsProtected()
 
+	setProtectedBranchData(c, protectBranch)
+	if c.Written() {
+		return
+	}
+	c.HTML(200, tplProtectedBranch)
+}
+
+// setProtectedBranchData sets the data of the protected branch setting page
+func setProtectedBranchData(c *context.Context, protectBranch *models.ProtectedBranch) {
 	users, err := c.Repo.Repository.GetReaders()
 	if err != nil {
 		c.ServerError("Repo.Repository.GetReaders", err)
@@ -129,7 +141,12 @@ func SettingsProtectedBranch(c *context.Context) {
 	c.Data["merge_whitelist_users"] = strings.Join(base.Int64sToStrings(protectBranch.MergeWhitelistUserIDs), ",")
 	c.Data["approvals_whitelist_users"] = strings.Join(base.Int64sToStrings(protectBranch.ApprovalsWhitelistUserIDs), ",")
 	contexts, _ := models.FindRepoRecentCommitStatusContexts(c.Repo.Repository.ID, 7*24*time.Hour) // Find last week status check contexts
+	var statusCheckPatterns []string
 	for _, context := range protectBranch.StatusCheckContexts {
+		if models.IsStatusCheckPattern(context) {
+			statusCheckPatterns = append(statusCheckPatterns, context)
+			continue
+		}
 		var found bool
 		for _, ctx := range contexts {
 			if ctx == context {
@@ -143,6 +160,7 @@ func SettingsProtectedBranch(c *context.Context) {
 	}
 
 	c.Data["branch_status_check_contexts"] = contexts
+	c.Data["status_check_patterns"] = strings.Join(statusCheckPatterns, ";")
 	c.Data["is_context_required"] = func(context string) bool {
 		for _, c := range protectBranch.StatusCheckContexts {
 			if c == context {
@@ -165,7 +183,6 @@ func SettingsProtectedBranch(c *context.Context) {
 	}
 
 	c.Data["Branch"] = protectBranch
-	c.HTML(200, tplProtectedBranch)
 }
 
 // SettingsProtectedBranchPost updates the protected branch settings
@@ -185,6 +202,11 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 		}
 	}
 
+	if ctx.Query("action") == "preview" {
+		previewProtectedBranch(ctx, protectBranch, f)
+		return
+	}
+
 	if f.Protected {
 		if protectBranch == nil {
 			// No options found, create defaults.
@@ -198,74 +220,13 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 			ctx.Redirect(fmt.Sprintf("%s/settings/branches/%s", ctx.Repo.RepoLink, branch))
 		}
 
-		var whitelistUsers, whitelistTeams, mergeWhitelistUsers, mergeWhitelistTeams, approvalsWhitelistUsers, approvalsWhitelistTeams []int64
-		switch f.EnablePush {
-		case "all":
-			protectBranch.CanPush = true
-			protectBranch.EnableWhitelist = false
-			protectBranch.WhitelistDeployKeys = false
-		case "whitelist":
-			protectBranch.CanPush = true
-			protectBranch.EnableWhitelist = true
-			protectBranch.WhitelistDeployKeys = f.WhitelistDeployKeys
-			if strings.TrimSpace(f.WhitelistUsers) != "" {
-				whitelistUsers, _ = base.StringsToInt64s(strings.Split(f.WhitelistUsers, ","))
-			}
-			if strings.TrimSpace(f.WhitelistTeams) != "" {
-				whitelistTeams, _ = base.StringsToInt64s(strings.Split(f.WhitelistTeams, ","))
-			}
-		default:
-			protectBranch.CanPush = false
-			protectBranch.EnableWhitelist = false
-			protectBranch.WhitelistDeployKeys = false
-		}
-
-		protectBranch.EnableMergeWhitelist = f.EnableMergeWhitelist
-		if f.EnableMergeWhitelist {
-			if strings.TrimSpace(f.MergeWhitelistUsers) != "" {
-				mergeWhitelistUsers, _ = base.StringsToInt64s(strings.Split(f.MergeWhitelistUsers, ","))
-			}
-			if strings.TrimSpace(f.MergeWhitelistTeams) != "" {
-				mergeWhitelistTeams, _ = base.StringsToInt64s(strings.Split(f.MergeWhitelistTeams, ","))
-			}
-		}
-
-		protectBranch.EnableStatusCheck = f.EnableStatusCheck
-		if f.EnableStatusCheck {
-			protectBranch.StatusCheckContexts = f.StatusCheckContexts
-		} else {
-			protectBranch.StatusCheckContexts = nil
-		}
-
-		protectBranch.RequiredApprovals = f.RequiredApprovals
-		protectBranch.EnableApprovalsWhitelist = f.EnableApprovalsWhitelist
-		if f.EnableApprovalsWhitelist {
-			if strings.TrimSpace(f.ApprovalsWhitelistUsers) != "" {
-				approvalsWhitelistUsers, _ = base.StringsToInt64s(strings.Split(f.ApprovalsWhitelistUsers, ","))
-			}
-			if strings.TrimSpace(f.ApprovalsWhitelistTeams) != "" {
-				approvalsWhitelistTeams, _ = base.StringsToInt64s(strings.Split(f.ApprovalsWhitelistTeams, ","))
-			}
-		}
-		protectBranch.BlockOnRejectedReviews = f.BlockOnRejectedReviews
-		protectBranch.BlockOnOfficialReviewRequests = f.BlockOnOfficialReviewRequests
-		protectBranch.DismissStaleApprovals = f.DismissStaleApprovals
-		protectBranch.RequireSignedCommits = f.RequireSignedCommits
-		protectBranch.ProtectedFilePatterns = f.ProtectedFilePatterns
-		protectBranch.BlockOnOutdatedBranch = f.BlockOnOutdatedBranch
-
-		err = models.UpdateProtectBranch(ctx.Repo.Repository, protectBranch, models.WhitelistOptions{
-			UserIDs:          whitelistUsers,
-			TeamIDs:          whitelistTeams,
-			MergeUserIDs:     mergeWhitelistUsers,
-			MergeTeamIDs:     mergeWhitelistTeams,
-			ApprovalsUserIDs: approvalsWhitelistUsers,
-			ApprovalsTeamIDs: approvalsWhitelistTeams,
-		})
+		whitelists := protectBranchFromForm(protectBranch, f)
+		err = models.UpdateProtectBranch(ctx.Repo.Repository, protectBranch, whitelists)
 		if err != nil {
 			ctx.ServerError("UpdateProtectBranch", err)
 			return
 		}
+		repo_service.RecordEvent(ctx.User, ctx.Repo.Repository, models.RepoEventProtectedBranchUpdate, protectBranch.BranchName, "")
 		if err = pull_service.CheckPrsForBaseBranch(ctx.Repo.Repository, protectBranch.BranchName); err != nil {
 			ctx.ServerError("CheckPrsForBaseBranch", err)
 			return
@@ -278,8 +239,128 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 				ctx.ServerError("DeleteProtectedBranch", err)
 				return
 			}
+			repo_service.RecordEvent(ctx.User, ctx.Repo.Repository, models.RepoEventProtectedBranchDelete, protectBranch.BranchName, "")
 		}
 		ctx.Flash.Success(ctx.Tr("repo.settings.remove_protected_branch_success", branch))
 		ctx.Redirect(fmt.Sprintf("%s/settings/branches", ctx.Repo.RepoLink))
 	}
 }
+
+// previewProtectedBranch renders the protected branch setting page with the settings of the form, without saving them,
+// and the open pull requests and the recent pushes they would block
+func previewProtectedBranch(ctx *context.Context, protectBranch *models.ProtectedBranch, f *auth.ProtectBranchForm) {
+	branch := ctx.Params("*")
+	ctx.Data["Title"] = ctx.Tr("repo.settings.protected_branch") + " - " + branch
+	ctx.Data["PageIsSettingsBranches"] = true
+
+	proposed := &models.ProtectedBranch{
+		RepoID:     ctx.Repo.Repository.ID,
+		BranchName: branch,
+	}
+	if protectBranch != nil {
+		*proposed = *protectBranch
+	}
+	whitelists := protectBranchFromForm(proposed, f)
+	proposed.WhitelistUserIDs = whitelists.UserIDs
+	proposed.WhitelistTeamIDs = whitelists.TeamIDs
+	proposed.MergeWhitelistUserIDs = whitelists.MergeUserIDs
+	proposed.MergeWhitelistTeamIDs = whitelists.MergeTeamIDs
+	proposed.ApprovalsWhitelistUserIDs = whitelists.ApprovalsUserIDs
+	proposed.ApprovalsWhitelistTeamIDs = whitelists.ApprovalsTeamIDs
+
+	ctx.Data["ProtectionEnabled"] = f.Protected
+	setProtectedBranchData(ctx, proposed)
+	if ctx.Written() {
+		return
+	}
+
+	preview := &pull_service.ProtectionPreview{}
+	if f.Protected {
+		var err error
+		preview, err = pull_service.PreviewProtectedBranch(ctx.Repo.Repository, ctx.Repo.GitRepo, proposed)
+		if err != nil {
+			ctx.ServerError("PreviewProtectedBranch", err)
+			return
+		}
+	}
+	ctx.Data["ProtectionPreview"] = preview
+
+	ctx.HTML(200, tplProtectedBranch)
+}
+
+// protectBranchFromForm applies the settings of the form to a protected branch, returning its whitelists
+func protectBranchFromForm(protectBranch *models.ProtectedBranch, f *auth.ProtectBranchForm) models.WhitelistOptions {
+	var whitelistUsers, whitelistTeams, mergeWhitelistUsers, mergeWhitelistTeams, approvalsWhitelistUsers, approvalsWhitelistTeams []int64
+	switch f.EnablePush {
+	case "all":
+		protectBranch.CanPush = true
+		protectBranch.EnableWhitelist = false
+		protectBranch.WhitelistDeployKeys = false
+	case "whitelist":
+		protectBranch.CanPush = true
+		protectBranch.EnableWhitelist = true
+		protectBranch.WhitelistDeployKeys = f.WhitelistDeployKeys
+		if strings.TrimSpace(f.WhitelistUsers) != "" {
+			whitelistUsers, _ = base.StringsToInt64s(strings.Split(f.WhitelistUsers, ","))
+		}
+		if strings.TrimSpace(f.WhitelistTeams) != "" {
+			whitelistTeams, _ = base.StringsToInt64s(strings.Split(f.WhitelistTeams, ","))
+		}
+	default:
+		protectBranch.CanPush = false
+		protectBranch.EnableWhitelist = false
+		protectBranch.WhitelistDeployKeys = false
+	}
+
+	protectBranch.EnableMergeWhitelist = f.EnableMergeWhitelist
+	if f.EnableMergeWhitelist {
+		if strings.TrimSpace(f.MergeWhitelistUsers) != "" {
+			mergeWhitelistUsers, _ = base.StringsToInt64s(strings.Split(f.MergeWhitelistUsers, ","))
+		}
+		if strings.TrimSpace(f.MergeWhitelistTeams) != "" {
+			mergeWhitelistTeams, _ = base.StringsToInt64s(strings.Split(f.MergeWhitelistTeams, ","))
+		}
+	}
+
+	protectBranch.EnableStatusCheck = f.EnableStatusCheck
+	if f.EnableStatusCheck {
+		protectBranch.StatusCheckContexts = f.StatusCheckContexts
+		for _, pattern := range strings.Split(f.StatusCheckPatterns, ";") {
+			pattern = strings.TrimSpace(pattern)
+			if pattern != "" && !util.IsStringInSlice(pattern, protectBranch.StatusCheckContexts) {
+				protectBranch.StatusCheckContexts = append(protectBranch.StatusCheckContexts, pattern)
+			}
+		}
+	} else {
+		protectBranch.StatusCheckContexts = nil
+	}
+
+	protectBranch.RequiredApprovals = f.RequiredApprovals
+	protectBranch.EnableApprovalsWhitelist = f.EnableApprovalsWhitelist
+	if f.EnableApprovalsWhitelist {
+		if strings.TrimSpace(f.ApprovalsWhitelistUsers) != "" {
+			approvalsWhitelistUsers, _ = base.StringsToInt64s(strings.Split(f.ApprovalsWhitelistUsers, ","))
+		}
+		if strings.TrimSpace(f.ApprovalsWhitelistTeams) != "" {
+			approvalsWhitelistTeams, _ = base.StringsToInt64s(strings.Split(f.ApprovalsWhitelistTeams, ","))
+		}
+	}
+	protectBranch.BlockOnRejectedReviews = f.BlockOnRejectedReviews
+	protectBranch.BlockOnOfficialReviewRequests = f.BlockOnOfficialReviewRequests
+	protectBranch.DismissStaleApprovals = f.DismissStaleApprovals
+	protectBranch.RequireSignedCommits = f.RequireSignedCommits
+	protectBranch.ProtectedFilePatterns = f.ProtectedFilePatterns
+	protectBranch.BlockOnOutdatedBranch = f.BlockOnOutdatedBranch
+	protectBranch.BlockOnUnresolvedConversations = f.BlockOnUnresolvedConversations
+	protectBranch.RequireCodeOwnerReviews = f.RequireCodeOwnerReviews
+	protectBranch.EnableMergeQueue = f.EnableMergeQueue
+
+	return models.WhitelistOptions{
+		UserIDs:          whitelistUsers,
+		TeamIDs:          whitelistTeams,
+		MergeUserIDs:     mergeWhitelistUsers,
+		MergeTeamIDs:     mergeWhitelistTeams,
+		ApprovalsUserIDs: approvalsWhitelistUsers,
+		ApprovalsTeamIDs: approvalsWhitelistTeams,
+	}
+}
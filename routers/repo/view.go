@@ -686,27 +686,99 @@ func renderCode(ctx *context.Context) {
 		return
 	}
 
-	var treeNames []string
-	paths := make([]string, 0, 5)
 	if len(ctx.Repo.TreePath) > 0 {
-		treeNames = strings.Split(ctx.Repo.TreePath, "/")
-		for i := range treeNames {
-			paths = append(paths, strings.Join(treeNames[:i+1], "/"))
-		}
-
 		ctx.Data["HasParentPath"] = true
-		if len(paths)-2 >= 0 {
-			ctx.Data["ParentPath"] = "/" + paths[len(paths)-2]
+		if idx := strings.LastIndex(ctx.Repo.TreePath, "/"); idx >= 0 {
+			ctx.Data["ParentPath"] = "/" + ctx.Repo.TreePath[:idx]
 		}
 	}
 
-	ctx.Data["Paths"] = paths
+	renderIDELinks(ctx)
+	if len(ctx.Repo.TreePath) == 0 {
+		renderDevEnvironments(ctx)
+	}
+	if ctx.Written() {
+		return
+	}
+	renderMirrorFailures(ctx)
+	if ctx.Written() {
+		return
+	}
+
 	ctx.Data["TreeLink"] = treeLink
-	ctx.Data["TreeNames"] = treeNames
 	ctx.Data["BranchLink"] = branchLink
 	ctx.HTML(200, tplRepoHome)
 }
 
+// ideLink is a link opening the repository in an IDE
+type ideLink struct {
+	DisplayName string
+	URL         gotemplate.URL
+}
+
+func renderIDELinks(ctx *context.Context) {
+	cloneLink := ctx.Repo.Repository.CloneLink()
+	cloneURL := cloneLink.HTTPS
+	if setting.Repository.DisableHTTPGit {
+		if setting.SSH.Disabled || (!ctx.IsSigned && !setting.SSH.ExposeAnonymous) {
+			return
+		}
+		cloneURL = cloneLink.SSH
+	}
+
+	links := make([]*ideLink, 0, len(setting.IDE.AppList))
+	for _, app := range setting.IDE.AppList {
+		// the schemes of the IDE URLs are checked when the settings are loaded
+		links = append(links, &ideLink{
+			DisplayName: app.DisplayName,
+			URL:         gotemplate.URL(app.Link(cloneURL)),
+		})
+	}
+	ctx.Data["IDELinks"] = links
+}
+
+// renderDevEnvironments shows the development environments configured in the default branch
+func renderDevEnvironments(ctx *context.Context) {
+	devcontainerPath, err := ctx.Repo.GetDevcontainerPath()
+	if err != nil {
+		ctx.ServerError("GetDevcontainerPath", err)
+		return
+	}
+	gitpodConfigPath, err := ctx.Repo.GetGitpodConfigPath()
+	if err != nil {
+		ctx.ServerError("GetGitpodConfigPath", err)
+		return
+	}
+	ctx.Data["DevcontainerPath"] = devcontainerPath
+	ctx.Data["GitpodConfigPath"] = gitpodConfigPath
+	if len(gitpodConfigPath) > 0 {
+		ctx.Data["GitpodLink"] = setting.GitpodLink(ctx.Repo.Repository.HTMLURL())
+	}
+}
+
+// renderMirrorFailures warns the administrators of the repository about its mirrors which keep failing
+func renderMirrorFailures(ctx *context.Context) {
+	if !ctx.Repo.IsAdmin() {
+		return
+	}
+	if ctx.Repo.Mirror != nil && ctx.Repo.Mirror.IsFailing() {
+		ctx.Data["FailingMirror"] = ctx.Repo.Mirror
+	}
+
+	pushMirrors, err := models.GetPushMirrorsByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetPushMirrorsByRepoID", err)
+		return
+	}
+	failing := make([]*models.PushMirror, 0, len(pushMirrors))
+	for _, m := range pushMirrors {
+		if m.IsFailing() {
+			failing = append(failing, m)
+		}
+	}
+	ctx.Data["FailingPushMirrors"] = failing
+}
+
 // RenderUserCards render a page show users according the input templaet
 func RenderUserCards(ctx *context.Context, total int, getter func(opts models.ListOptions) ([]*models.User, error), tpl base.TplName) {
 	page := ctx.QueryInt("page")
@@ -21,6 +21,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
+	repo_service "code.gitea.io/gitea/services/repository"
 	"code.gitea.io/gitea/services/webhook"
 	jsoniter "github.com/json-iterator/go"
 )
@@ -227,6 +228,7 @@ func GiteaHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookCreate, w)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -281,6 +283,7 @@ func newGogsWebhookPost(ctx *context.Context, form auth.NewGogshookForm, kind mo
 		return
 	}
 
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookCreate, w)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -334,6 +337,7 @@ func DiscordHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookCreate, w)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -377,6 +381,7 @@ func DingtalkHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookCreate, w)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -430,6 +435,7 @@ func TelegramHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookCreate, w)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -486,6 +492,7 @@ func MatrixHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookCreate, w)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -529,6 +536,7 @@ func MSTeamsHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookCreate, w)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -590,6 +598,7 @@ func SlackHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookCreate, w)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -633,6 +642,7 @@ func FeishuHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookCreate, w)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -735,6 +745,7 @@ func WebHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookUpdate, w)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -775,6 +786,7 @@ func GogsHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookUpdate, w)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -827,6 +839,7 @@ func SlackHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookUpdate, w)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -871,6 +884,7 @@ func DiscordHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookUpdate, w)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -904,6 +918,7 @@ func DingtalkHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookUpdate, w)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -946,6 +961,7 @@ func TelegramHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookUpdate, w)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -991,6 +1007,7 @@ func MatrixHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookUpdate, w)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -1024,6 +1041,7 @@ func MSTeamsHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookUpdate, w)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -1057,6 +1075,7 @@ func FeishuHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookUpdate, w)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -1107,7 +1126,7 @@ func TestWebhook(ctx *context.Context) {
 		Pusher: apiUser,
 		Sender: apiUser,
 	}
-	if err := webhook.PrepareWebhook(w, ctx.Repo.Repository, models.HookEventPush, p); err != nil {
+	if err := webhook.PrepareWebhook(ctx.Req.Context(), w, ctx.Repo.Repository, models.HookEventPush, p); err != nil {
 		ctx.Flash.Error("PrepareWebhook: " + err.Error())
 		ctx.Status(500)
 	} else {
@@ -1118,9 +1137,14 @@ func TestWebhook(ctx *context.Context) {
 
 // DeleteWebhook delete a webhook
 func DeleteWebhook(ctx *context.Context) {
-	if err := models.DeleteWebhookByRepoID(ctx.Repo.Repository.ID, ctx.QueryInt64("id")); err != nil {
+	w, err := models.GetWebhookByRepoID(ctx.Repo.Repository.ID, ctx.QueryInt64("id"))
+	if err == nil {
+		err = models.DeleteWebhookByRepoID(ctx.Repo.Repository.ID, w.ID)
+	}
+	if err != nil {
 		ctx.Flash.Error("DeleteWebhookByRepoID: " + err.Error())
 	} else {
+		repo_service.RecordWebhookEvent(ctx.User, ctx.Repo.Repository, models.RepoEventWebhookDelete, w)
 		ctx.Flash.Success(ctx.Tr("repo.settings.webhook_deletion_success"))
 	}
 
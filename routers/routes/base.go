@@ -11,11 +11,13 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"regexp"
 	"strings"
 	"time"
 
 	"code.gitea.io/gitea/modules/auth/sso"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/generate"
 	"code.gitea.io/gitea/modules/httpcache"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -24,15 +26,84 @@ import (
 	"code.gitea.io/gitea/modules/web/middleware"
 
 	"gitea.com/go-chi/session"
+	"github.com/go-chi/chi"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// validRequestIDPattern restricts the request IDs taken over from the clients
+// to values which are safe to be logged and passed on
+var validRequestIDPattern = regexp.MustCompile(`^[-a-zA-Z0-9_.:]{1,64}$`)
+
+// RequestIDHandler assigns an ID to every request which is added to the logs of
+// the request. The ID of the X-Request-ID header of the request is kept if valid,
+// otherwise a new ID is generated. The ID is returned in the X-Request-ID header.
+func RequestIDHandler() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			requestID := req.Header.Get("X-Request-ID")
+			if !validRequestIDPattern.MatchString(requestID) {
+				var err error
+				requestID, err = generate.GetRandomString(16)
+				if err != nil {
+					log.Error("Unable to generate request ID: %v", err)
+					next.ServeHTTP(w, req)
+					return
+				}
+			}
+			w.Header().Set("X-Request-ID", requestID)
+			next.ServeHTTP(w, req.WithContext(log.WithRequestID(req.Context(), requestID)))
+		})
+	}
+}
+
+// TracingHandler creates a server span for every request. The trace context of
+// the traceparent header is continued. The span is named after the matched route.
+// Only the path of the request is recorded, its query may contain access tokens.
+func TracingHandler() func(next http.Handler) http.Handler {
+	tracer := otel.Tracer("code.gitea.io/gitea/routers")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(req.Context(), req.Header)
+			ctx, span := tracer.Start(ctx, req.Method, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+				label.String("http.method", req.Method),
+				label.String("http.target", req.URL.Path),
+				label.String("http.user_agent", req.UserAgent()),
+				label.String("net.peer.ip", setting.LoggedRemoteAddr(req.RemoteAddr)),
+				label.String("gitea.request_id", log.RequestIDFromContext(ctx)),
+			))
+			defer span.End()
+
+			next.ServeHTTP(w, req.WithContext(ctx))
+
+			if rctx := chi.RouteContext(req.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				span.SetName(req.Method + " " + rctx.RoutePattern())
+				span.SetAttributes(label.String("http.route", rctx.RoutePattern()))
+			}
+			if v, ok := w.(context.ResponseWriter); ok {
+				status := v.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+				span.SetAttributes(label.Int("http.status_code", status))
+				if status >= http.StatusInternalServerError {
+					span.SetStatus(codes.Error, http.StatusText(status))
+				}
+			}
+		})
+	}
+}
+
 // LoggerHandler is a handler that will log the routing to the default gitea log
 func LoggerHandler(level log.Level) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 			start := time.Now()
+			logger := log.GetLogger("router").WithRequestID(log.RequestIDFromContext(req.Context()))
 
-			_ = log.GetLogger("router").Log(0, level, "Started %s %s for %s", log.ColoredMethod(req.Method), req.URL.RequestURI(), req.RemoteAddr)
+			_ = logger.Log(0, level, "Started %s %s for %s", log.ColoredMethod(req.Method), req.URL.RequestURI(), setting.LoggedRemoteAddr(req.RemoteAddr))
 
 			next.ServeHTTP(w, req)
 
@@ -41,7 +112,7 @@ func LoggerHandler(level log.Level) func(next http.Handler) http.Handler {
 				status = v.Status()
 			}
 
-			_ = log.GetLogger("router").Log(0, level, "Completed %s %s %v %s in %v", log.ColoredMethod(req.Method), req.URL.RequestURI(), log.ColoredStatus(status), log.ColoredStatus(status, http.StatusText(status)), log.ColoredTime(time.Since(start)))
+			_ = logger.Log(0, level, "Completed %s %s %v %s in %v", log.ColoredMethod(req.Method), req.URL.RequestURI(), log.ColoredStatus(status), log.ColoredStatus(status, http.StatusText(status)), log.ColoredTime(time.Since(start)))
 		})
 	}
 }
@@ -11,14 +11,18 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"regexp"
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth/webauthn"
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	auth "code.gitea.io/gitea/modules/forms"
 	"code.gitea.io/gitea/modules/httpcache"
 	"code.gitea.io/gitea/modules/lfs"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/maintenance"
 	"code.gitea.io/gitea/modules/metrics"
 	"code.gitea.io/gitea/modules/public"
 	"code.gitea.io/gitea/modules/setting"
@@ -29,6 +33,9 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers"
 	"code.gitea.io/gitea/routers/admin"
+	"code.gitea.io/gitea/routers/api/actions"
+	"code.gitea.io/gitea/routers/api/packages"
+	"code.gitea.io/gitea/routers/api/scim"
 	apiv1 "code.gitea.io/gitea/routers/api/v1"
 	"code.gitea.io/gitea/routers/api/v1/misc"
 	"code.gitea.io/gitea/routers/dev"
@@ -57,6 +64,8 @@ import (
 const (
 	// GzipMinSize represents min size to compress for the body size of response
 	GzipMinSize = 1400
+
+	tplMaintenance base.TplName = "status/503"
 )
 
 func commonMiddlewares() []func(http.Handler) http.Handler {
@@ -66,6 +75,7 @@ func commonMiddlewares() []func(http.Handler) http.Handler {
 				next.ServeHTTP(context.NewResponse(resp), req)
 			})
 		},
+		RequestIDHandler(),
 	}
 
 	if setting.ReverseProxyLimit > 0 {
@@ -82,6 +92,11 @@ func commonMiddlewares() []func(http.Handler) http.Handler {
 		handlers = append(handlers, proxy.ForwardedHeaders(opt))
 	}
 
+	// The remote address of the span is the one of the client behind the reverse proxy
+	if setting.Tracing.Enabled {
+		handlers = append(handlers, TracingHandler())
+	}
+
 	handlers = append(handlers, middleware.StripSlashes)
 
 	if !setting.DisableRouterLog && setting.RouterLogLevel != log.NONE {
@@ -122,6 +137,29 @@ func NormalRoutes() *web.Route {
 
 	r.Mount("/", WebRoutes())
 	r.Mount("/api/v1", apiv1.Routes())
+	if setting.SCIM.Enabled {
+		r.Mount("/api/scim/v2", scim.Routes())
+	}
+	if setting.Actions.Enabled {
+		r.Mount("/api/actions", actions.Routes())
+	}
+	if setting.Packages.Enabled {
+		r.Mount("/api/packages", packages.Routes())
+		r.Mount("/v2", packages.ContainerRoutes())
+	}
+	if !setting.InternalAPI.Separate {
+		r.Mount("/api/internal", private.Routes())
+	}
+	return r
+}
+
+// InternalRoutes represents the routes served by the separate internal API listener
+func InternalRoutes() *web.Route {
+	r := web.NewRoute()
+	for _, middle := range commonMiddlewares() {
+		r.Use(middle)
+	}
+
 	r.Mount("/api/internal", private.Routes())
 	return r
 }
@@ -184,6 +222,7 @@ func WebRoutes() *web.Route {
 	}
 	// Removed: toolbox.Toolboxer middleware will provide debug informations which seems unnecessary
 	r.Use(context.Contexter())
+	r.Use(checkMaintenanceMode)
 	// GetHead allows a HEAD request redirect to GET if HEAD method is not defined for that route
 	r.Use(middleware.GetHead)
 
@@ -299,6 +338,46 @@ func goGet(ctx *context.Context) {
 	})))
 }
 
+// maintenanceAllowedPrefixes are the paths every user can access while the instance is in maintenance mode
+var maintenanceAllowedPrefixes = []string{
+	"/user/login",
+	"/user/logout",
+	"/user/two_factor",
+	"/user/u2f",
+	"/user/webauthn",
+	"/user/oauth2/",
+	"/user/saml/",
+	"/avatars/",
+	"/repo-avatars/",
+}
+
+// gitHTTPPathRegexp matches the paths of the git smart HTTP and LFS endpoints, which stay available
+// during maintenance: pushes are rejected by the pre-receive hook and LFS uploads by the LFS server.
+var gitHTTPPathRegexp = regexp.MustCompile(`^/[^/]+/[^/]+/(info/|objects/|HEAD$|git-upload-pack$|git-receive-pack$)`)
+
+// checkMaintenanceMode renders the maintenance page for everyone but site administrators
+// while the instance is in maintenance mode
+func checkMaintenanceMode(ctx *context.Context) {
+	if !maintenance.IsEnabled() || (ctx.IsSigned && ctx.User.IsAdmin) {
+		return
+	}
+
+	reqPath := strings.TrimPrefix(ctx.Req.URL.Path, setting.AppSubURL)
+	for _, prefix := range maintenanceAllowedPrefixes {
+		if strings.HasPrefix(reqPath, prefix) {
+			return
+		}
+	}
+	if gitHTTPPathRegexp.MatchString(reqPath) {
+		return
+	}
+
+	ctx.Data["Title"] = ctx.Tr("maintenance.title")
+	ctx.Data["MaintenanceMessage"] = maintenance.GetState().Message
+	ctx.Resp.Header().Set("Retry-After", "300")
+	ctx.HTML(http.StatusServiceUnavailable, tplMaintenance)
+}
+
 // RegisterRoutes register routes
 func RegisterRoutes(m *web.Route) {
 	reqSignIn := context.Toggle(&context.ToggleOptions{SignInRequired: true})
@@ -332,6 +411,14 @@ func RegisterRoutes(m *web.Route) {
 		}
 	}
 
+	// reqOrgOwner requires the user to be an owner of the organization
+	reqOrgOwner := func(ctx *context.Context) {
+		if !ctx.Org.IsOwner {
+			ctx.NotFound("", nil)
+			return
+		}
+	}
+
 	// webhooksEnabled requires webhooks to be enabled by admin.
 	webhooksEnabled := func(ctx *context.Context) {
 		if setting.DisableWebhooks {
@@ -353,6 +440,7 @@ func RegisterRoutes(m *web.Route) {
 		m.Get("/users", routers.ExploreUsers)
 		m.Get("/organizations", routers.ExploreOrganizations)
 		m.Get("/code", routers.ExploreCode)
+		m.Post("/code/recent/clear", reqSignIn, routers.ExploreCodeClearRecent)
 	}, ignExploreSignIn)
 	m.Get("/issues", reqSignIn, user.Issues)
 	m.Get("/pulls", reqSignIn, user.Pulls)
@@ -383,6 +471,10 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/{provider}", user.SignInOAuth)
 			m.Get("/{provider}/callback", user.SignInOAuthCallback)
 		})
+		m.Group("/saml", func() {
+			m.Get("/{provider}", user.SignInSAML)
+			m.Post("/{provider}/acs", user.SignInSAMLCallback)
+		})
 		m.Get("/link_account", user.LinkAccount)
 		m.Post("/link_account_signin", bindIgnErr(auth.SignInForm{}), user.LinkAccountPostSignIn)
 		m.Post("/link_account_signup", bindIgnErr(auth.RegisterForm{}), user.LinkAccountPostRegister)
@@ -398,9 +490,15 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/sign", bindIgnErr(u2f.SignResponse{}), user.U2FSign)
 
 		})
+		m.Group("/webauthn", func() {
+			m.Get("/assertion", user.WebAuthnAssertion)
+			m.Post("/passwordless", bindIgnErr(webauthn.AssertionResponse{}), user.WebAuthnPasswordless)
+		})
 	}, reqSignOut)
 
 	m.Any("/user/events", events.Events)
+	m.Post("/user/events/subscribe", reqSignIn, events.Subscribe)
+	m.Get("/user/saml/{provider}/metadata", user.SAMLMetadata)
 
 	m.Group("/login/oauth", func() {
 		m.Get("/authorize", bindIgnErr(auth.AuthorizationForm{}), user.AuthorizeOAuth)
@@ -433,6 +531,7 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/email", bindIgnErr(auth.AddEmailForm{}), userSetting.EmailPost)
 			m.Post("/email/delete", userSetting.DeleteEmail)
 			m.Post("/delete", userSetting.DeleteAccount)
+			m.Post("/takeout", userSetting.TakeoutPost)
 			m.Post("/theme", bindIgnErr(auth.UpdateThemeForm{}), userSetting.UpdateUIThemePost)
 		})
 		m.Group("/security", func() {
@@ -448,6 +547,11 @@ func RegisterRoutes(m *web.Route) {
 				m.Post("/register", bindIgnErr(u2f.RegisterResponse{}), userSetting.U2FRegisterPost)
 				m.Post("/delete", bindIgnErr(auth.U2FDeleteForm{}), userSetting.U2FDelete)
 			})
+			m.Group("/webauthn", func() {
+				m.Post("/request_register", bindIgnErr(auth.WebAuthnRegistrationForm{}), userSetting.WebAuthnRegister)
+				m.Post("/register", bindIgnErr(webauthn.CreationResponse{}), userSetting.WebAuthnRegisterPost)
+				m.Post("/delete", bindIgnErr(auth.WebAuthnDeleteForm{}), userSetting.WebAuthnDelete)
+			})
 			m.Group("/openid", func() {
 				m.Post("", bindIgnErr(auth.AddOpenIDForm{}), userSetting.OpenIDPost)
 				m.Post("/delete", userSetting.DeleteOpenID)
@@ -488,11 +592,22 @@ func RegisterRoutes(m *web.Route) {
 		m.Get("/forgot_password", user.ForgotPasswd)
 		m.Post("/forgot_password", user.ForgotPasswdPost)
 		m.Post("/logout", user.SignOut)
+		m.Combo("/policies", reqSignIn).Get(user.Policies).Post(user.PoliciesPost)
+		m.Group("/filters", func() {
+			m.Combo("/new").Get(user.NewFilter).
+				Post(bindIgnErr(auth.SaveUserFilterForm{}), user.NewFilterPost)
+			m.Post("/delete", user.DeleteFilter)
+		}, reqSignIn)
 		m.Get("/task/{task}", user.TaskStatus)
+		m.Group("/times", func() {
+			m.Get("", user.TimeReport)
+			m.Get("/export", user.TimeReportExport)
+		}, reqSignIn)
 	})
 	// ***** END: User *****
 
 	m.Get("/avatar/{hash}", user.AvatarByEmailHash)
+	m.Get("/media-proxy/{signature}/{link}", ignSignIn, routers.MediaProxy)
 
 	adminReq := context.Toggle(&context.ToggleOptions{SignInRequired: true, AdminRequired: true})
 
@@ -501,7 +616,9 @@ func RegisterRoutes(m *web.Route) {
 		m.Get("", adminReq, admin.Dashboard)
 		m.Post("", adminReq, bindIgnErr(auth.AdminDashboardForm{}), admin.DashboardPost)
 		m.Get("/config", admin.Config)
+		m.Get("/config/effective", admin.EffectiveConfig)
 		m.Post("/config/test_mail", admin.SendTestMail)
+		m.Get("/statistics", admin.Statistics)
 		m.Group("/monitor", func() {
 			m.Get("", admin.Monitor)
 			m.Post("/cancel/{pid}", admin.MonitorCancel)
@@ -526,6 +643,19 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/activate", admin.ActivateEmail)
 		})
 
+		m.Group("/profile-fields", func() {
+			m.Get("", admin.ProfileFields)
+			m.Combo("/new").Get(admin.NewProfileField).Post(bindIgnErr(auth.AdminProfileFieldForm{}), admin.NewProfileFieldPost)
+			m.Combo("/{id}").Get(admin.EditProfileField).Post(bindIgnErr(auth.AdminProfileFieldForm{}), admin.EditProfileFieldPost)
+			m.Post("/{id}/delete", admin.DeleteProfileField)
+		})
+
+		m.Group("/policies", func() {
+			m.Get("", admin.Policies)
+			m.Combo("/new").Get(admin.NewPolicy).Post(bindIgnErr(auth.AdminPolicyDocumentForm{}), admin.NewPolicyPost)
+			m.Get("/{id}", admin.ViewPolicy)
+		})
+
 		m.Group("/orgs", func() {
 			m.Get("", admin.Organizations)
 		})
@@ -569,6 +699,7 @@ func RegisterRoutes(m *web.Route) {
 			m.Combo("/new").Get(admin.NewAuthSource).Post(bindIgnErr(auth.AuthenticationForm{}), admin.NewAuthSourcePost)
 			m.Combo("/{authid}").Get(admin.EditAuthSource).
 				Post(bindIgnErr(auth.AuthenticationForm{}), admin.EditAuthSourcePost)
+			m.Get("/{authid}/sync_preview", admin.SyncPreviewAuthSource)
 			m.Post("/{authid}/delete", admin.DeleteAuthSource)
 		})
 
@@ -577,6 +708,20 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/delete", admin.DeleteNotices)
 			m.Post("/empty", admin.EmptyNotices)
 		})
+
+		m.Get("/mirrors", admin.Mirrors)
+		m.Get("/api-usage", admin.APIUsage)
+
+		m.Group("/virus-detections", func() {
+			m.Get("", admin.VirusDetections)
+			m.Post("/delete", admin.DeleteVirusDetections)
+		})
+
+		m.Group("/runners", func() {
+			m.Get("", admin.Runners)
+			m.Post("/reset_token", admin.ResetRunnerToken)
+			m.Post("/{id}/delete", admin.DeleteRunner)
+		}, admin.MustEnableActions)
 	}, adminReq)
 	// ***** END: Admin *****
 
@@ -589,6 +734,15 @@ func RegisterRoutes(m *web.Route) {
 		m.Post("/action/{action}", user.Action)
 	}, reqSignIn)
 
+	m.Group("/{username}/-/packages", func() {
+		m.Get("", user.Packages)
+		m.Group("/{type}/{name}", func() {
+			m.Get("", user.ViewPackage)
+			m.Get("/files/{filename}", user.DownloadPackageFile)
+			m.Post("/delete", reqSignIn, user.DeletePackageVersion)
+		})
+	}, ignSignIn, user.MustEnablePackages)
+
 	if !setting.IsProd() {
 		m.Get("/template/*", dev.TemplatePreview)
 	}
@@ -635,6 +789,31 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/teams/{team}/action/repo/{action}", org.TeamsRepoAction)
 		}, context.OrgAssignment(true, false, true))
 
+		m.Group("/{org}/projects", func() {
+			m.Get("", org.Projects)
+			m.Get("/{id}", org.ViewProject)
+			m.Group("", func() {
+				m.Get("/new", org.NewProject)
+				m.Post("/new", bindIgnErr(auth.CreateProjectForm{}), org.NewProjectPost)
+				m.Group("/{id}", func() {
+					m.Post("", bindIgnErr(auth.EditProjectBoardForm{}), org.AddBoardToProjectPost)
+					m.Post("/delete", org.DeleteProject)
+
+					m.Get("/edit", org.EditProject)
+					m.Post("/edit", bindIgnErr(auth.CreateProjectForm{}), org.EditProjectPost)
+					m.Post("/{action:open|close}", org.ChangeProjectStatus)
+
+					m.Group("/{boardID}", func() {
+						m.Put("", bindIgnErr(auth.EditProjectBoardForm{}), org.EditProjectBoard)
+						m.Delete("", org.DeleteProjectBoard)
+						m.Post("/default", org.SetDefaultProjectBoard)
+					})
+				})
+			}, reqOrgOwner)
+			// the permission to move an issue is checked against its repository
+			m.Post("/{id}/{boardID}/{index}", org.MoveIssueAcrossBoards)
+		}, context.OrgAssignment(true), repo.MustEnableProjects)
+
 		m.Group("/{org}", func() {
 			m.Get("/teams/new", org.NewTeam)
 			m.Post("/teams/new", bindIgnErr(auth.CreateTeamForm{}), org.NewTeamPost)
@@ -681,6 +860,14 @@ func RegisterRoutes(m *web.Route) {
 					m.Post("/initialize", bindIgnErr(auth.InitializeLabelsForm{}), org.InitializeLabels)
 				})
 
+				m.Group("/issue_types", func() {
+					m.Get("", org.IssueTypes)
+					m.Post("/new", bindIgnErr(auth.IssueTypeForm{}), org.NewIssueType)
+					m.Post("/edit", bindIgnErr(auth.IssueTypeForm{}), org.UpdateIssueType)
+					m.Post("/delete", org.DeleteIssueType)
+					m.Post("/initialize", org.InitializeIssueTypes)
+				})
+
 				m.Route("/delete", "GET,POST", org.SettingsDelete)
 			})
 		}, context.OrgAssignment(true, true))
@@ -762,6 +949,30 @@ func RegisterRoutes(m *web.Route) {
 				m.Post("/delete", repo.DeleteDeployKey)
 			})
 
+			m.Get("/events", repo.SettingsEvents)
+
+			m.Group("/issue_types", func() {
+				m.Get("", repo.SettingsIssueTypes)
+				m.Post("/new", bindIgnErr(auth.IssueTypeForm{}), repo.NewIssueTypePost)
+				m.Post("/edit", bindIgnErr(auth.IssueTypeForm{}), repo.EditIssueTypePost)
+				m.Post("/delete", repo.DeleteIssueTypePost)
+				m.Post("/initialize", repo.InitializeIssueTypesPost)
+			}, context.RepoRef())
+
+			m.Group("/recurring_issues", func() {
+				m.Get("", repo.SettingsRecurringIssues)
+				m.Post("/new", bindIgnErr(auth.RecurringIssueForm{}), repo.NewRecurringIssuePost)
+				m.Post("/edit", bindIgnErr(auth.RecurringIssueForm{}), repo.EditRecurringIssuePost)
+				m.Post("/delete", repo.DeleteRecurringIssuePost)
+			}, context.RepoMustNotBeArchived())
+
+			m.Group("/slas", func() {
+				m.Get("", repo.SettingsIssueSLAs)
+				m.Post("/new", bindIgnErr(auth.IssueSLAForm{}), repo.NewIssueSLAPost)
+				m.Post("/edit", bindIgnErr(auth.IssueSLAForm{}), repo.EditIssueSLAPost)
+				m.Post("/delete", repo.DeleteIssueSLAPost)
+			}, context.RepoMustNotBeArchived())
+
 			m.Group("/lfs", func() {
 				m.Get("/", repo.LFSFiles)
 				m.Get("/show/{oid}", repo.LFSFileGet)
@@ -788,6 +999,7 @@ func RegisterRoutes(m *web.Route) {
 	m.Group("/{username}/{reponame}", func() {
 		m.Group("/milestone", func() {
 			m.Get("/{id}", repo.MilestoneIssuesAndPulls)
+			m.Get("/{id}/insights", repo.MilestoneInsights)
 		}, reqRepoIssuesOrPullsReader, context.RepoRef())
 		m.Combo("/compare/*", repo.MustBeNotEmpty, reqRepoCodeReader, repo.SetEditorconfigIfExists).
 			Get(ignSignIn, repo.SetDiffViewStyle, repo.SetWhitespaceBehavior, repo.CompareDiff).
@@ -811,11 +1023,15 @@ func RegisterRoutes(m *web.Route) {
 				m.Post("/content", repo.UpdateIssueContent)
 				m.Post("/watch", repo.IssueWatch)
 				m.Post("/ref", repo.UpdateIssueRef)
+				m.Post("/weight", reqRepoIssuesOrPullsWriter, repo.UpdateIssueWeight)
 				m.Group("/dependency", func() {
 					m.Post("/add", repo.AddDependency)
 					m.Post("/delete", repo.RemoveDependency)
 				})
-				m.Combo("/comments").Post(repo.MustAllowUserComment, bindIgnErr(auth.CreateCommentForm{}), repo.NewComment)
+				m.Group("/sub_issues", func() {
+					m.Post("/add", repo.AddSubIssue)
+					m.Post("/remove", repo.RemoveSubIssue)
+				}, reqRepoIssueWriter)
 				m.Group("/times", func() {
 					m.Post("/add", bindIgnErr(auth.AddTimeManuallyForm{}), repo.AddTimeManually)
 					m.Post("/{timeid}/delete", repo.DeleteTime)
@@ -825,16 +1041,18 @@ func RegisterRoutes(m *web.Route) {
 					})
 				})
 				m.Post("/reactions/{action}", bindIgnErr(auth.ReactionForm{}), repo.ChangeIssueReaction)
+				m.Post("/content_history/purge", repo.PurgeIssueContentHistoryPost)
 				m.Post("/lock", reqRepoIssueWriter, bindIgnErr(auth.IssueLockForm{}), repo.LockIssue)
 				m.Post("/unlock", reqRepoIssueWriter, repo.UnlockIssue)
+				m.Group("/collaborators", func() {
+					m.Post("", repo.AddIssueCollaborator)
+					m.Post("/{uid}/delete", repo.RemoveIssueCollaborator)
+				})
 			}, context.RepoMustNotBeArchived())
-			m.Group("/{index}", func() {
-				m.Get("/attachments", repo.GetIssueAttachments)
-				m.Get("/attachments/{uuid}", repo.GetAttachment)
-			})
 
 			m.Post("/labels", reqRepoIssuesOrPullsWriter, repo.UpdateIssueLabel)
 			m.Post("/milestone", reqRepoIssuesOrPullsWriter, repo.UpdateIssueMilestone)
+			m.Post("/type", reqRepoIssuesOrPullsWriter, repo.UpdateIssueType)
 			m.Post("/projects", reqRepoIssuesOrPullsWriter, repo.UpdateIssueProject)
 			m.Post("/assignee", reqRepoIssuesOrPullsWriter, repo.UpdateIssueAssignee)
 			m.Post("/request_review", reqRepoIssuesOrPullsReader, repo.UpdatePullReviewRequest)
@@ -844,14 +1062,7 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/attachments", repo.UploadIssueAttachment)
 			m.Post("/attachments/remove", repo.DeleteAttachment)
 		}, context.RepoMustNotBeArchived())
-		m.Group("/comments/{id}", func() {
-			m.Post("", repo.UpdateCommentContent)
-			m.Post("/delete", repo.DeleteComment)
-			m.Post("/reactions/{action}", bindIgnErr(auth.ReactionForm{}), repo.ChangeCommentReaction)
-		}, context.RepoMustNotBeArchived())
-		m.Group("/comments/{id}", func() {
-			m.Get("/attachments", repo.GetCommentAttachments)
-		})
+		m.Post("/comments/{id}/apply_suggestion", context.RepoMustNotBeArchived(), reqRepoPullsReader, repo.ApplySuggestion)
 		m.Group("/labels", func() {
 			m.Post("/new", bindIgnErr(auth.CreateLabelForm{}), repo.NewLabel)
 			m.Post("/edit", bindIgnErr(auth.CreateLabelForm{}), repo.UpdateLabel)
@@ -901,6 +1112,29 @@ func RegisterRoutes(m *web.Route) {
 
 	}, reqSignIn, context.RepoAssignment(), context.UnitTypes())
 
+	// Issue endpoints the users invited to single issues may access as well,
+	// the handlers only let them view and comment on these issues
+	m.Group("/{username}/{reponame}", func() {
+		m.Get("/{type:issues|pulls}/{index}", context.RepoRef(), repo.ViewIssue)
+		m.Group("", func() {
+			m.Group("/issues/{index}", func() {
+				m.Combo("/comments").Post(repo.MustAllowUserComment, bindIgnErr(auth.CreateCommentForm{}), repo.NewComment)
+			}, context.RepoMustNotBeArchived())
+			m.Group("/issues/{index}", func() {
+				m.Get("/attachments", repo.GetIssueAttachments)
+				m.Get("/attachments/{uuid}", repo.GetAttachment)
+			})
+			m.Group("/comments/{id}", func() {
+				m.Post("", repo.UpdateCommentContent)
+				m.Post("/delete", repo.DeleteComment)
+				m.Post("/reactions/{action}", bindIgnErr(auth.ReactionForm{}), repo.ChangeCommentReaction)
+			}, context.RepoMustNotBeArchived())
+			m.Group("/comments/{id}", func() {
+				m.Get("/attachments", repo.GetCommentAttachments)
+			})
+		}, reqSignIn)
+	}, ignSignIn, context.IssueCollaboratorRepoAssignment(), context.UnitTypes())
+
 	// Releases
 	m.Group("/{username}/{reponame}", func() {
 		m.Get("/tags", repo.TagsList, repo.MustBeNotEmpty,
@@ -946,7 +1180,7 @@ func RegisterRoutes(m *web.Route) {
 	m.Group("/{username}/{reponame}", func() {
 		m.Group("", func() {
 			m.Get("/{type:issues|pulls}", repo.Issues)
-			m.Get("/{type:issues|pulls}/{index}", repo.ViewIssue)
+			m.Get("/{type:issues|pulls}/{index}/content_history", repo.IssueContentHistory)
 			m.Get("/labels", reqRepoIssuesOrPullsReader, repo.RetrieveLabels, repo.Labels)
 			m.Get("/milestones", reqRepoIssuesOrPullsReader, repo.Milestones)
 		}, context.RepoRef())
@@ -999,11 +1233,37 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/raw/*", repo.WikiRaw)
 		}, repo.MustEnableWiki)
 
+		m.Group("/actions", func() {
+			m.Get("", repo.Actions)
+			m.Group("/runs/{run}", func() {
+				m.Get("", repo.ViewActionRun)
+				m.Get("/jobs/{job}/logs", repo.ActionJobLogs)
+				m.Get("/artifacts/{artifact}", repo.DownloadActionArtifact)
+				m.Post("/cancel", reqSignIn, reqRepoCodeWriter, repo.CancelActionRun)
+				m.Post("/approve", reqSignIn, reqRepoCodeWriter, repo.ApproveActionRun)
+			})
+		}, repo.MustEnableActions, repo.MustBeNotEmpty, reqRepoCodeReader)
+
+		m.Get("/artifacts/{id}", repo.MustEnableArtifacts, reqRepoCodeReader, repo.DownloadArtifact)
+
 		m.Group("/activity", func() {
 			m.Get("", repo.Activity)
 			m.Get("/{period}", repo.Activity)
 		}, context.RepoRef(), repo.MustBeNotEmpty, context.RequireRepoReaderOr(models.UnitTypePullRequests, models.UnitTypeIssues, models.UnitTypeReleases))
 
+		m.Get("/insights/issues", repo.MustEnableIssues, reqRepoIssueWriter, repo.IssueInsights)
+		m.Get("/insights/sla", repo.MustEnableIssues, reqRepoIssueWriter, repo.IssueSLACompliance)
+		m.Group("/insights/times", func() {
+			m.Get("", repo.TimeReport)
+			m.Get("/export", repo.TimeReportExport)
+		}, reqSignIn, repo.MustEnableIssues)
+
+		m.Group("/merge_train", func() {
+			m.Get("", repo.MergeTrain)
+			m.Get("/branch/*", repo.MergeTrain)
+			m.Get("/events/*", repo.MergeTrainEvents)
+		}, repo.MustAllowPulls, reqRepoPullsReader)
+
 		m.Group("/activity_author_data", func() {
 			m.Get("", repo.ActivityAuthors)
 			m.Get("/{period}", repo.ActivityAuthors)
@@ -1026,9 +1286,13 @@ func RegisterRoutes(m *web.Route) {
 			m.Get(".diff", repo.DownloadPullDiff)
 			m.Get(".patch", repo.DownloadPullPatch)
 			m.Get("/commits", context.RepoRef(), repo.ViewPullCommits)
+			m.Get("/comments/{id}/context", repo.MustBeNotEmpty, reqRepoCodeReader, repo.CodeCommentContext)
 			m.Post("/merge", context.RepoMustNotBeArchived(), bindIgnErr(auth.MergePullRequestForm{}), repo.MergePullRequest)
+			m.Post("/merge_queue/remove", context.RepoMustNotBeArchived(), repo.RemoveFromMergeQueue)
+			m.Post("/cancel_auto_merge", context.RepoMustNotBeArchived(), repo.CancelAutoMergePullRequest)
 			m.Post("/update", repo.UpdatePullRequest)
 			m.Post("/cleanup", context.RepoMustNotBeArchived(), context.RepoRef(), repo.CleanUpPullRequest)
+			m.Post("/convert", context.RepoMustNotBeArchived(), repo.ConvertPullRequestToIssue)
 			m.Group("/files", func() {
 				m.Get("", context.RepoRef(), repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.SetWhitespaceBehavior, repo.ViewPullFiles)
 				m.Group("/reviews", func() {
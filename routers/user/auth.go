@@ -13,6 +13,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/auth/oauth2"
+	"code.gitea.io/gitea/modules/auth/saml"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/eventsource"
@@ -140,6 +141,14 @@ func SignIn(ctx *context.Context) {
 	}
 	ctx.Data["OrderedOAuth2Names"] = orderedOAuth2Names
 	ctx.Data["OAuth2Providers"] = oauth2Providers
+	if saml.Available(setting.AppURL) {
+		samlProviders, err := models.GetActiveSAMLLoginSourceNames()
+		if err != nil {
+			ctx.ServerError("UserSignIn", err)
+			return
+		}
+		ctx.Data["SAMLProviders"] = samlProviders
+	}
 	ctx.Data["Title"] = ctx.Tr("sign_in")
 	ctx.Data["SignInLink"] = setting.AppSubURL + "/user/login"
 	ctx.Data["PageIsSignIn"] = true
@@ -160,6 +169,14 @@ func SignInPost(ctx *context.Context) {
 	}
 	ctx.Data["OrderedOAuth2Names"] = orderedOAuth2Names
 	ctx.Data["OAuth2Providers"] = oauth2Providers
+	if saml.Available(setting.AppURL) {
+		samlProviders, err := models.GetActiveSAMLLoginSourceNames()
+		if err != nil {
+			ctx.ServerError("UserSignIn", err)
+			return
+		}
+		ctx.Data["SAMLProviders"] = samlProviders
+	}
 	ctx.Data["Title"] = ctx.Tr("sign_in")
 	ctx.Data["SignInLink"] = setting.AppSubURL + "/user/login"
 	ctx.Data["PageIsSignIn"] = true
@@ -737,6 +754,12 @@ func oAuth2UserLoginCallback(loginSource *models.LoginSource, request *http.Requ
 		return user, gothUser, err
 	}
 
+	// search in the users migrated to the source, which are known by their email until they sign in,
+	// users whose email the provider did not verify have to link their account instead
+	if user, err = models.ClaimMigratedOAuth2User(loginSource, gothUser.UserID, gothUser.Email, oauth2.IsEmailVerified(gothUser)); err != nil || user != nil {
+		return user, gothUser, err
+	}
+
 	// no user found to login
 	return nil, gothUser, nil
 
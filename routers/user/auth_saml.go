@@ -0,0 +1,174 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth/saml"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web/middleware"
+)
+
+const samlRequestIDCookie = "saml_request_id"
+
+// samlURLs returns the entity ID and assertion consumer service URL of Gitea for the given source.
+func samlURLs(source *models.LoginSource) (string, string) {
+	base := setting.AppURL + "user/saml/" + url.PathEscape(source.Name)
+	entityID := source.SAML().ServiceProviderEntityID
+	if len(entityID) == 0 {
+		entityID = base + "/metadata"
+	}
+	return entityID, base + "/acs"
+}
+
+// setSAMLRequestIDCookie remembers the pending authentication request. The response of the
+// identity provider is a cross-site POST, so the cookie must not be restricted to same-site
+// requests, which browsers only accept for secure cookies. SAML is therefore only available
+// if Gitea is served over HTTPS.
+func setSAMLRequestIDCookie(ctx *context.Context, value string, maxAge int) {
+	middleware.SetCookie(ctx.Resp, samlRequestIDCookie, value,
+		maxAge,
+		setting.AppSubURL+"/user/saml/",
+		setting.SessionConfig.Domain,
+		true,
+		true,
+		middleware.SameSite(http.SameSiteNoneMode))
+}
+
+func getActiveSAMLLoginSource(ctx *context.Context) *models.LoginSource {
+	if !saml.Available(setting.AppURL) {
+		ctx.NotFound("getActiveSAMLLoginSource", fmt.Errorf("SAML requires a HTTPS ROOT_URL, got %s", setting.AppURL))
+		return nil
+	}
+	source, err := models.GetActiveSAMLLoginSourceByName(ctx.Params(":provider"))
+	if err != nil {
+		if models.IsErrLoginSourceNotExist(err) {
+			ctx.NotFound("GetActiveSAMLLoginSourceByName", err)
+		} else {
+			ctx.ServerError("GetActiveSAMLLoginSourceByName", err)
+		}
+		return nil
+	}
+	return source
+}
+
+// SAMLMetadata returns the service provider metadata of a SAML login source
+func SAMLMetadata(ctx *context.Context) {
+	source := getActiveSAMLLoginSource(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	entityID, acsURL := samlURLs(source)
+	metadata, err := source.SAML().Metadata(entityID, acsURL)
+	if err != nil {
+		ctx.ServerError("Metadata", err)
+		return
+	}
+	ctx.Resp.Header().Set("Content-Type", "application/samlmetadata+xml")
+	ctx.Resp.WriteHeader(http.StatusOK)
+	if _, err := ctx.Resp.Write(metadata); err != nil {
+		log.Error("Failed to write SAML metadata: %v", err)
+	}
+}
+
+// SignInSAML redirects the user to the identity provider of a SAML login source
+func SignInSAML(ctx *context.Context) {
+	source := getActiveSAMLLoginSource(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	entityID, acsURL := samlURLs(source)
+	redirect, requestID, err := source.SAML().AuthnRequestURL(entityID, acsURL, "")
+	if err != nil {
+		ctx.ServerError("AuthnRequestURL", err)
+		return
+	}
+	setSAMLRequestIDCookie(ctx, requestID, 10*60)
+	ctx.Redirect(redirect)
+}
+
+// SignInSAMLCallback handles the response the identity provider posts to the assertion consumer service
+func SignInSAMLCallback(ctx *context.Context) {
+	source := getActiveSAMLLoginSource(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	requestID := ctx.GetCookie(samlRequestIDCookie)
+	setSAMLRequestIDCookie(ctx, "", -1)
+
+	entityID, acsURL := samlURLs(source)
+	assertion, err := source.SAML().ParseResponse(ctx.Req.FormValue("SAMLResponse"), entityID, acsURL, requestID)
+	if err != nil {
+		log.Warn("Invalid SAML response for %s from %s: %v", source.Name, ctx.RemoteAddr(), err)
+		ctx.Flash.Error(ctx.Tr("auth.saml_sign_in_failed"))
+		ctx.Redirect(setting.AppSubURL + "/user/login")
+		return
+	}
+
+	u, err := models.LoginViaSAML(source, assertion)
+	if err != nil {
+		switch {
+		case models.IsErrUserProhibitLogin(err):
+			log.Info("Failed authentication attempt for %s from %s: %v", assertion.NameID, ctx.RemoteAddr(), err)
+			ctx.Data["Title"] = ctx.Tr("auth.prohibit_login")
+			ctx.HTML(http.StatusOK, "user/auth/prohibit_login")
+		case models.IsErrUserAlreadyExist(err), models.IsErrEmailAlreadyUsed(err), models.IsErrNameReserved(err),
+			models.IsErrNamePatternNotAllowed(err), models.IsErrNameCharsNotAllowed(err):
+			log.Info("Unable to create user for %s via %s: %v", assertion.NameID, source.Name, err)
+			ctx.Flash.Error(ctx.Tr("auth.saml_account_conflict"))
+			ctx.Redirect(setting.AppSubURL + "/user/login")
+		case models.IsErrExternalRegistrationDisabled(err):
+			log.Info("Refused to register %s via %s: %v", assertion.NameID, source.Name, err)
+			ctx.Flash.Error(ctx.Tr("auth.saml_registration_disabled"))
+			ctx.Redirect(setting.AppSubURL + "/user/login")
+		default:
+			ctx.ServerError("LoginViaSAML", err)
+		}
+		return
+	}
+
+	// If this user is enrolled in 2FA, we can't sign the user in just yet.
+	// Instead, redirect them to the 2FA authentication page.
+	_, err = models.GetTwoFactorByUID(u.ID)
+	if err != nil {
+		if models.IsErrTwoFactorNotEnrolled(err) {
+			handleSignIn(ctx, u, false)
+		} else {
+			ctx.ServerError("UserSignIn", err)
+		}
+		return
+	}
+
+	// User needs to use 2FA, save data and redirect to 2FA page.
+	if err := ctx.Session.Set("twofaUid", u.ID); err != nil {
+		ctx.ServerError("UserSignIn: Unable to set twofaUid in session", err)
+		return
+	}
+	if err := ctx.Session.Set("twofaRemember", false); err != nil {
+		ctx.ServerError("UserSignIn: Unable to set twofaRemember in session", err)
+		return
+	}
+	if err := ctx.Session.Release(); err != nil {
+		ctx.ServerError("UserSignIn: Unable to save session", err)
+		return
+	}
+
+	regs, err := models.GetU2FRegistrationsByUID(u.ID)
+	if err == nil && len(regs) > 0 {
+		ctx.Redirect(setting.AppSubURL + "/user/u2f")
+		return
+	}
+
+	ctx.Redirect(setting.AppSubURL + "/user/two_factor")
+}
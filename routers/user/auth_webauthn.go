@@ -0,0 +1,120 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"bytes"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth/webauthn"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// WebAuthnAssertion submits a challenge for a passwordless sign in to the browser
+func WebAuthnAssertion(ctx *context.Context) {
+	rp, err := webauthn.NewRelyingParty(setting.AppURL, setting.AppName)
+	if err != nil {
+		ctx.ServerError("NewRelyingParty", err)
+		return
+	}
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		ctx.ServerError("NewChallenge", err)
+		return
+	}
+	if err := ctx.Session.Set("webauthnAssertionChallenge", challenge); err != nil {
+		ctx.ServerError("UserSignIn: unable to set webauthnAssertionChallenge in session", err)
+		return
+	}
+	if err := ctx.Session.Release(); err != nil {
+		ctx.ServerError("UserSignIn: unable to store session", err)
+		return
+	}
+
+	// No allowed credentials, the authenticator offers the discoverable credentials it holds for Gitea
+	ctx.JSON(http.StatusOK, rp.RequestOptions(challenge, nil))
+}
+
+// WebAuthnPasswordless signs the user in with the assertion of a discoverable credential
+func WebAuthnPasswordless(ctx *context.Context) {
+	resp := web.GetForm(ctx).(*webauthn.AssertionResponse)
+	challSess := ctx.Session.Get("webauthnAssertionChallenge")
+	if challSess == nil {
+		ctx.Error(http.StatusBadRequest, "not in WebAuthn session")
+		return
+	}
+	_ = ctx.Session.Delete("webauthnAssertionChallenge")
+
+	credID, err := webauthn.DecodeID(resp.ID)
+	if err != nil {
+		ctx.Error(http.StatusBadRequest, "invalid credential ID")
+		return
+	}
+	cred, err := models.GetWebAuthnCredentialByCredID(webauthn.EncodeID(credID))
+	if err != nil {
+		if models.IsErrWebAuthnCredentialNotExist(err) {
+			log.Info("Failed WebAuthn authentication attempt with unknown credential from %s", ctx.RemoteAddr())
+			ctx.Error(http.StatusUnauthorized)
+		} else {
+			ctx.ServerError("GetWebAuthnCredentialByCredID", err)
+		}
+		return
+	}
+	u, err := models.GetUserByID(cred.UserID)
+	if err != nil {
+		ctx.ServerError("GetUserByID", err)
+		return
+	}
+	// A discoverable credential always returns the handle of the user it was created for
+	if userHandle, err := webauthn.DecodeID(resp.Response.UserHandle); err != nil || !bytes.Equal(userHandle, models.WebAuthnUserHandle(u)) {
+		log.Info("Failed WebAuthn authentication attempt for %s from %s: user handle mismatch", u.Name, ctx.RemoteAddr())
+		ctx.Error(http.StatusUnauthorized)
+		return
+	}
+
+	rp, err := webauthn.NewRelyingParty(setting.AppURL, setting.AppName)
+	if err != nil {
+		ctx.ServerError("NewRelyingParty", err)
+		return
+	}
+	c, err := cred.ToCredential()
+	if err != nil {
+		ctx.ServerError("ToCredential", err)
+		return
+	}
+	signCount, err := rp.VerifyAssertion(resp, challSess.(string), c)
+	if err != nil {
+		log.Info("Failed WebAuthn authentication attempt for %s from %s: %v", u.Name, ctx.RemoteAddr(), err)
+		ctx.Error(http.StatusUnauthorized)
+		return
+	}
+	cred.SignCount = signCount
+	if err := cred.UpdateSignCount(); err != nil {
+		ctx.ServerError("UpdateSignCount", err)
+		return
+	}
+
+	if !u.IsActive || u.ProhibitLogin {
+		log.Info("Failed WebAuthn authentication attempt for %s from %s: user is inactive or prohibited from login", u.Name, ctx.RemoteAddr())
+		ctx.JSON(http.StatusForbidden, map[string]interface{}{
+			"redirect": setting.AppSubURL + "/user/login",
+		})
+		return
+	}
+
+	// The authenticator verified the user, which makes this a multi-factor
+	// authentication on its own: no need for another second factor
+	redirect := handleSignInFull(ctx, u, false, false)
+	if redirect == "" {
+		redirect = setting.AppSubURL + "/"
+	}
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": redirect,
+	})
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// tplNewFilter template for saving the filters of an issue or pull request list
+const tplNewFilter base.TplName = "user/filter/new"
+
+// loadFilterList loads the repository of a filter and the filters of its list saved by the user, rendering a 404 page
+// if the user can not read the list
+func loadFilterList(ctx *context.Context, filter *models.UserFilter) {
+	if err := filter.LoadRepo(); err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.NotFound("LoadRepo", err)
+		} else {
+			ctx.ServerError("LoadRepo", err)
+		}
+		return
+	}
+	readable, err := filter.IsReadableBy(ctx.User)
+	if err != nil {
+		ctx.ServerError("IsReadableBy", err)
+		return
+	} else if !readable {
+		ctx.NotFound("IsReadableBy", nil)
+		return
+	}
+
+	ctx.Data["UserFilters"], _, err = models.FindUserFilters(&models.FindUserFiltersOptions{
+		UserID: ctx.User.ID,
+		RepoID: filter.RepoID,
+		IsPull: util.OptionalBoolOf(filter.IsPull),
+	})
+	if err != nil {
+		ctx.ServerError("FindUserFilters", err)
+	}
+}
+
+// NewFilter renders the form naming the filters of a list before saving them, the filters being the query
+// parameters of the list
+func NewFilter(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.issues.save_filter.title")
+
+	filter := &models.UserFilter{
+		RepoID:      ctx.QueryInt64("repo_id"),
+		IsPull:      ctx.QueryBool("is_pull"),
+		ViewType:    ctx.Query("type"),
+		SortType:    ctx.Query("sort"),
+		State:       ctx.Query("state"),
+		Keyword:     strings.TrimSpace(ctx.Query("q")),
+		Labels:      ctx.Query("labels"),
+		MilestoneID: ctx.QueryInt64("milestone"),
+		AssigneeID:  ctx.QueryInt64("assignee"),
+		Repos:       strings.Trim(ctx.Query("repos"), "[]"),
+	}
+	filter.Labels = models.JoinUserFilterIDs(filter.LabelIDs())
+	filter.Repos = models.JoinUserFilterIDs(filter.RepoIDs())
+	loadFilterList(ctx, filter)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Filter"] = filter
+
+	ctx.HTML(http.StatusOK, tplNewFilter)
+}
+
+// NewFilterPost saves the filters of a list with a name
+func NewFilterPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.SaveUserFilterForm)
+	ctx.Data["Title"] = ctx.Tr("repo.issues.save_filter.title")
+
+	filter := &models.UserFilter{
+		UserID:      ctx.User.ID,
+		RepoID:      form.RepoID,
+		IsPull:      form.IsPull,
+		Name:        form.Name,
+		ViewType:    form.Type,
+		SortType:    form.Sort,
+		State:       form.State,
+		Keyword:     strings.TrimSpace(form.Q),
+		Labels:      form.Labels,
+		MilestoneID: form.Milestone,
+		AssigneeID:  form.Assignee,
+		Repos:       strings.Trim(form.Repos, "[]"),
+	}
+	loadFilterList(ctx, filter)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Filter"] = filter
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplNewFilter)
+		return
+	}
+
+	if err := models.SaveUserFilter(filter); err != nil {
+		if models.IsErrUserFiltersLimitReached(err) {
+			ctx.RenderWithErr(ctx.Tr("repo.issues.save_filter.limit_reached", models.MaxUserFilters), tplNewFilter, form)
+			return
+		}
+		ctx.ServerError("SaveUserFilter", err)
+		return
+	}
+	log.Trace("User saved a filter: %s %d", ctx.User.Name, filter.ID)
+
+	ctx.Flash.Success(ctx.Tr("repo.issues.save_filter.success", filter.Name))
+	ctx.Redirect(setting.AppSubURL + filter.Link())
+}
+
+// DeleteFilter deletes a saved filter of the user
+func DeleteFilter(ctx *context.Context) {
+	if err := models.DeleteUserFilter(ctx.User.ID, ctx.QueryInt64("id")); err != nil {
+		ctx.Flash.Error("DeleteUserFilter: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.issues.delete_filter.success"))
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{})
+}
@@ -275,6 +275,10 @@ func Milestones(ctx *context.Context) {
 		}
 		i++
 	}
+	if err := milestones.LoadTotalWeights(); err != nil {
+		ctx.ServerError("LoadTotalWeights", err)
+		return
+	}
 
 	milestoneStats, err := models.GetMilestonesStatsByRepoCond(repoCond)
 	if err != nil {
@@ -688,6 +692,23 @@ func buildIssueOverview(ctx *context.Context, unitType models.UnitType) {
 		ctx.Data["State"] = "open"
 	}
 
+	// the saved filters of the user, of its repositories and of its dashboard
+	userFilters, _, err := models.FindUserFilters(&models.FindUserFiltersOptions{
+		UserID: ctx.User.ID,
+		RepoID: models.UserFilterAnyRepo,
+		IsPull: util.OptionalBoolOf(isPullList),
+	})
+	if err != nil {
+		ctx.ServerError("FindUserFilters", err)
+		return
+	}
+	if ctx.Data["UserFilters"], err = userFilters.Readable(ctx.User); err != nil {
+		ctx.ServerError("Readable", err)
+		return
+	}
+	// the filters of the dashboard are only saved from the dashboard of the user
+	ctx.Data["CanSaveFilter"] = ctxUser.ID == ctx.User.ID
+
 	// Convert []int64 to string
 	json := jsoniter.ConfigCompatibleWithStandardLibrary
 	reposParam, _ := json.Marshal(repoIDs)
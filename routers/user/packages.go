@@ -0,0 +1,285 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	packages_service "code.gitea.io/gitea/services/packages"
+)
+
+const (
+	tplPackages    base.TplName = "user/packages/list"
+	tplPackageView base.TplName = "user/packages/view"
+)
+
+// MustEnablePackages checks if the package registry is enabled
+func MustEnablePackages(ctx *context.Context) {
+	if !setting.Packages.Enabled {
+		ctx.NotFound("MustEnablePackages", nil)
+	}
+}
+
+// packagesOwner returns the owner in the path if the doer can read its packages
+func packagesOwner(ctx *context.Context) *models.User {
+	owner := GetUserByParams(ctx)
+	if ctx.Written() {
+		return nil
+	}
+	if !packages_service.CanRead(owner, ctx.User) {
+		ctx.NotFound("CanRead", nil)
+		return nil
+	}
+	ctx.Data["Owner"] = owner
+	return owner
+}
+
+// Packages lists the packages of a user or an organization
+func Packages(ctx *context.Context) {
+	owner := packagesOwner(ctx)
+	if owner == nil {
+		return
+	}
+
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	keyword := ctx.QueryTrim("q")
+	pt := models.PackageTypeFromName(ctx.Query("type"))
+
+	pkgs, count, err := models.FindPackages(&models.FindPackagesOptions{
+		ListOptions: models.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.User.RepoPagingNum,
+		},
+		OwnerID: owner.ID,
+		Type:    pt,
+		Keyword: keyword,
+	})
+	if err != nil {
+		ctx.ServerError("FindPackages", err)
+		return
+	}
+	for _, pkg := range pkgs {
+		pkg.Owner = owner
+	}
+
+	ctx.Data["Title"] = ctx.Tr("packages.title")
+	ctx.Data["PageIsPackages"] = true
+	ctx.Data["Packages"] = pkgs
+	ctx.Data["PackageTypes"] = models.PackageTypes
+	ctx.Data["PackageType"] = pt.Name()
+	ctx.Data["Keyword"] = keyword
+
+	pager := context.NewPagination(int(count), setting.UI.User.RepoPagingNum, page, 5)
+	pager.SetDefaultParams(ctx)
+	pager.AddParamString("type", pt.Name())
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplPackages)
+}
+
+// getPackage returns the package in the path
+func getPackage(ctx *context.Context, owner *models.User) *models.Package {
+	pt := models.PackageTypeFromName(ctx.Params("type"))
+	if pt == 0 {
+		ctx.NotFound("PackageTypeFromName", nil)
+		return nil
+	}
+	pkg, err := models.GetPackageByName(owner.ID, pt, ctx.Params("name"))
+	if err != nil {
+		if models.IsErrPackageNotExist(err) {
+			ctx.NotFound("GetPackageByName", err)
+		} else {
+			ctx.ServerError("GetPackageByName", err)
+		}
+		return nil
+	}
+	pkg.Owner = owner
+	return pkg
+}
+
+// installCommand returns the command which installs a version of a package
+func installCommand(pkg *models.Package, version string) string {
+	registryURL := fmt.Sprintf("%sapi/packages/%s/%s", setting.AppURL, url.PathEscape(pkg.Owner.Name), pkg.Type.Name())
+	switch pkg.Type {
+	case models.PackageTypeContainer:
+		host := strings.TrimSuffix(strings.SplitN(setting.AppURL, "://", 2)[1], "/")
+		return fmt.Sprintf("docker pull %s/%s/%s:%s", host, pkg.Owner.LowerName, pkg.Name, version)
+	case models.PackageTypeNpm:
+		return fmt.Sprintf("npm install %s@%s --registry=%s/", pkg.Name, version, registryURL)
+	case models.PackageTypePyPI:
+		return fmt.Sprintf("pip install --index-url %s/simple/ %s==%s", registryURL, pkg.Name, version)
+	case models.PackageTypeMaven:
+		return fmt.Sprintf("mvn dependency:get -DremoteRepositories=%s -Dartifact=%s:%s", registryURL, pkg.Name, version)
+	case models.PackageTypeNuGet:
+		return fmt.Sprintf("dotnet add package %s --version %s --source %s/index.json", pkg.Name, version, registryURL)
+	}
+	return ""
+}
+
+// ViewPackage shows a package with its versions and the files of the selected version
+func ViewPackage(ctx *context.Context) {
+	owner := packagesOwner(ctx)
+	if owner == nil {
+		return
+	}
+	pkg := getPackage(ctx, owner)
+	if pkg == nil {
+		return
+	}
+
+	versions, err := models.GetPackageVersions(pkg.ID)
+	if err != nil {
+		ctx.ServerError("GetPackageVersions", err)
+		return
+	}
+	if len(versions) == 0 {
+		ctx.NotFound("GetPackageVersions", nil)
+		return
+	}
+	// show the newest versions first
+	for i, j := 0, len(versions)-1; i < j; i, j = i+1, j-1 {
+		versions[i], versions[j] = versions[j], versions[i]
+	}
+
+	current := versions[0]
+	if v := ctx.Query("version"); len(v) > 0 {
+		current = nil
+		for _, pv := range versions {
+			if strings.EqualFold(pv.Version, v) {
+				current = pv
+				break
+			}
+		}
+		if current == nil {
+			ctx.NotFound("GetPackageVersion", nil)
+			return
+		}
+	}
+	if err := current.LoadCreator(); err != nil {
+		ctx.ServerError("LoadCreator", err)
+		return
+	}
+	files, err := models.GetPackageFiles(current.ID)
+	if err != nil {
+		ctx.ServerError("GetPackageFiles", err)
+		return
+	}
+	for _, pf := range files {
+		if err := pf.LoadBlob(); err != nil {
+			ctx.ServerError("LoadBlob", err)
+			return
+		}
+	}
+
+	canWrite, err := packages_service.CanWrite(owner, ctx.User)
+	if err != nil {
+		ctx.ServerError("CanWrite", err)
+		return
+	}
+
+	ctx.Data["Title"] = pkg.Name
+	ctx.Data["PageIsPackages"] = true
+	ctx.Data["Package"] = pkg
+	ctx.Data["Versions"] = versions
+	ctx.Data["CurrentVersion"] = current
+	ctx.Data["Files"] = files
+	ctx.Data["InstallCommand"] = installCommand(pkg, current.Version)
+	ctx.Data["CanWritePackages"] = canWrite
+
+	ctx.HTML(http.StatusOK, tplPackageView)
+}
+
+// DownloadPackageFile serves a file of a package version
+func DownloadPackageFile(ctx *context.Context) {
+	owner := packagesOwner(ctx)
+	if owner == nil {
+		return
+	}
+	pkg := getPackage(ctx, owner)
+	if pkg == nil {
+		return
+	}
+
+	pf, pv, err := models.GetPackageFileInPackage(pkg.ID, ctx.Params("filename"))
+	if err == nil && pv.IsInternal {
+		err = models.ErrPackageFileNotExist{Name: ctx.Params("filename")}
+	}
+	if err != nil {
+		if models.IsErrPackageFileNotExist(err) {
+			ctx.NotFound("GetPackageFileInPackage", err)
+		} else {
+			ctx.ServerError("GetPackageFileInPackage", err)
+		}
+		return
+	}
+
+	obj, err := packages_service.OpenFile(pf)
+	if err != nil {
+		ctx.ServerError("OpenFile", err)
+		return
+	}
+	defer obj.Close()
+	if err := pv.IncreaseDownloadCount(); err != nil {
+		ctx.ServerError("IncreaseDownloadCount", err)
+		return
+	}
+	ctx.ServeContent(pf.Name, obj, pf.CreatedUnix.AsTime())
+}
+
+// DeletePackageVersion deletes a version of a package, the package is deleted with its last version
+func DeletePackageVersion(ctx *context.Context) {
+	owner := packagesOwner(ctx)
+	if owner == nil {
+		return
+	}
+	canWrite, err := packages_service.CanWrite(owner, ctx.User)
+	if err != nil {
+		ctx.ServerError("CanWrite", err)
+		return
+	} else if !canWrite {
+		ctx.Error(http.StatusForbidden)
+		return
+	}
+	pkg := getPackage(ctx, owner)
+	if pkg == nil {
+		return
+	}
+
+	pv, err := models.GetPackageVersionByName(pkg.ID, ctx.Query("version"))
+	if err == nil && pv.IsInternal {
+		err = models.ErrPackageVersionNotExist{Version: ctx.Query("version")}
+	}
+	if err != nil {
+		if models.IsErrPackageVersionNotExist(err) {
+			ctx.NotFound("GetPackageVersionByName", err)
+		} else {
+			ctx.ServerError("GetPackageVersionByName", err)
+		}
+		return
+	}
+	if err := models.DeletePackageVersion(pv); err != nil {
+		ctx.ServerError("DeletePackageVersion", err)
+		return
+	}
+	log.Trace("Package version deleted: %s %s %s", pkg.Type.Name(), pkg.Name, pv.Version)
+
+	ctx.Flash.Success(ctx.Tr("packages.version.deletion_success"))
+	if _, err := models.GetPackageByID(pkg.ID); err == nil {
+		ctx.Redirect(pkg.Link())
+		return
+	}
+	ctx.Redirect(owner.HomeLink() + "/-/packages")
+}
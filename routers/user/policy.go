@@ -0,0 +1,67 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web/middleware"
+	"code.gitea.io/gitea/routers/utils"
+)
+
+// tplPolicies template for accepting the terms of service and the privacy policy
+const tplPolicies base.TplName = "user/auth/policies"
+
+// Policies renders the current policy documents, which the user must accept if they have not yet
+func Policies(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("auth.policies")
+
+	states, err := models.GetPolicyConsentStates(ctx.User.ID)
+	if err != nil {
+		ctx.ServerError("GetPolicyConsentStates", err)
+		return
+	}
+	pending := false
+	for _, state := range states {
+		state.Document.RenderedContent = string(markdown.Render([]byte(state.Document.Content), setting.AppSubURL, map[string]string{"mode": "document"}))
+		pending = pending || state.IsPending()
+	}
+	ctx.Data["PolicyConsentStates"] = states
+	ctx.Data["HasPendingPolicies"] = pending
+
+	ctx.HTML(http.StatusOK, tplPolicies)
+}
+
+// PoliciesPost records the acceptance of the policy documents by the user
+func PoliciesPost(ctx *context.Context) {
+	documentIDs, err := base.StringsToInt64s(ctx.QueryStrings("document_id"))
+	if err != nil {
+		ctx.Error(http.StatusBadRequest)
+		return
+	}
+	if err := models.AcceptPolicyDocuments(ctx.User, documentIDs); err != nil {
+		ctx.ServerError("AcceptPolicyDocuments", err)
+		return
+	}
+	log.Trace("User accepted policy documents: %s %v", ctx.User.Name, documentIDs)
+
+	if ctx.User.MustAcceptPolicies {
+		// a new version has been published in the meantime
+		ctx.Redirect(setting.AppSubURL + "/user/policies")
+		return
+	}
+	if redirectTo := ctx.GetCookie("redirect_to"); len(redirectTo) > 0 && !utils.IsExternalURL(redirectTo) {
+		middleware.DeleteRedirectToCookie(ctx.Resp)
+		ctx.RedirectToFirst(redirectTo)
+		return
+	}
+	ctx.Redirect(setting.AppSubURL + "/")
+}
@@ -108,6 +108,13 @@ func Profile(ctx *context.Context) {
 		ctx.Data["HeatmapData"] = data
 	}
 
+	profileFields, err := models.GetVisibleProfileFields(ctxUser, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetVisibleProfileFields", err)
+		return
+	}
+	ctx.Data["ProfileFields"] = profileFields
+
 	if len(ctxUser.Description) != 0 {
 		ctx.Data["RenderedDescription"] = string(markdown.Render([]byte(ctxUser.Description), ctx.Repo.RepoLink, map[string]string{"mode": "document"}))
 	}
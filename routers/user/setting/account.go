@@ -7,6 +7,9 @@ package setting
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"time"
 
 	"code.gitea.io/gitea/models"
@@ -17,8 +20,10 @@ import (
 	"code.gitea.io/gitea/modules/password"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/services/mailer"
+	"code.gitea.io/gitea/services/takeout"
 )
 
 const (
@@ -158,6 +163,14 @@ func EmailPost(ctx *context.Context) {
 			ctx.ServerError("SetEmailNotifications", err)
 			return
 		}
+		events := make(map[models.EmailEvent]bool, len(models.EmailEvents))
+		for _, event := range models.EmailEvents {
+			events[event] = ctx.QueryBool("email_event_" + string(event))
+		}
+		if err := ctx.User.SetEmailPreferences(events); err != nil {
+			ctx.ServerError("SetEmailPreferences", err)
+			return
+		}
 		log.Trace("Email notifications preference made %s: %s", preference, ctx.User.Name)
 		ctx.Flash.Success(ctx.Tr("settings.email_preference_set_success"))
 		ctx.Redirect(setting.AppSubURL + "/user/settings/account")
@@ -254,6 +267,33 @@ func DeleteAccount(ctx *context.Context) {
 	}
 }
 
+// TakeoutPost responds with a zip archive of the data of the user, and of their repositories if requested
+func TakeoutPost(ctx *context.Context) {
+	f, err := ioutil.TempFile("", "gitea-takeout")
+	if err != nil {
+		ctx.ServerError("TempFile", err)
+		return
+	}
+	defer func() {
+		f.Close()
+		if err := util.Remove(f.Name()); err != nil {
+			log.Error("Unable to remove %s: %v", f.Name(), err)
+		}
+	}()
+
+	if err := takeout.WriteArchive(ctx.Req.Context(), ctx.User, f, ctx.QueryBool("repositories")); err != nil {
+		ctx.ServerError("WriteArchive", err)
+		return
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		ctx.ServerError("Seek", err)
+		return
+	}
+
+	log.Trace("Data of %s downloaded", ctx.User.Name)
+	ctx.ServeContent(fmt.Sprintf("%s-takeout-%s.zip", ctx.User.Name, time.Now().Format("20060102")), f)
+}
+
 // UpdateUIThemePost is used to update users' specific theme
 func UpdateUIThemePost(ctx *context.Context) {
 	form := web.GetForm(ctx).(*auth.UpdateThemeForm)
@@ -302,6 +342,12 @@ func loadAccountData(ctx *context.Context) {
 	}
 	ctx.Data["Emails"] = emails
 	ctx.Data["EmailNotificationsPreference"] = ctx.User.EmailNotifications()
+	ctx.Data["EmailEvents"] = models.EmailEvents
+	ctx.Data["EmailPreferences"], err = ctx.User.GetEmailPreferences()
+	if err != nil {
+		ctx.ServerError("GetEmailPreferences", err)
+		return
+	}
 	ctx.Data["ActivationsPending"] = pendingActivation
 	ctx.Data["CanAddEmails"] = !pendingActivation || !setting.Service.RegisterEmailConfirm
 
@@ -88,6 +88,25 @@ func loadApplicationsData(ctx *context.Context) {
 		return
 	}
 	ctx.Data["Tokens"] = tokens
+
+	usages, err := models.GetUserAPIUsage(ctx.User.ID)
+	if err != nil {
+		ctx.ServerError("GetUserAPIUsage", err)
+		return
+	}
+	tokenUsages := make(map[int64]*models.APIUsageSummary, len(usages))
+	appUsages := make(map[int64]*models.APIUsageSummary, len(usages))
+	for _, usage := range usages {
+		if usage.TokenID > 0 {
+			tokenUsages[usage.TokenID] = usage
+		} else {
+			appUsages[usage.AppID] = usage
+		}
+	}
+	ctx.Data["TokenUsages"] = tokenUsages
+	ctx.Data["AppUsages"] = appUsages
+	ctx.Data["APIUsageDays"] = models.APIUsageDays
+
 	ctx.Data["EnableOAuth2"] = setting.OAuth2.Enable
 	if setting.OAuth2.Enable {
 		ctx.Data["Applications"], err = models.GetOAuth2ApplicationsByUserID(ctx.User.ID)
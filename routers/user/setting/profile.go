@@ -37,9 +37,24 @@ func Profile(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("settings")
 	ctx.Data["PageIsSettingsProfile"] = true
 
+	loadProfileFields(ctx)
+	if ctx.Written() {
+		return
+	}
+
 	ctx.HTML(200, tplSettingsProfile)
 }
 
+// loadProfileFields loads the profile fields with the values of the signed in user
+func loadProfileFields(ctx *context.Context) {
+	fields, err := models.GetUserProfileFields(ctx.User.ID)
+	if err != nil {
+		ctx.ServerError("GetUserProfileFields", err)
+		return
+	}
+	ctx.Data["ProfileFields"] = fields
+}
+
 // HandleUsernameChange handle username changes from user settings and admin interface
 func HandleUsernameChange(ctx *context.Context, user *models.User, newName string) error {
 	// Non-local users are not allowed to change their username.
@@ -79,6 +94,10 @@ func ProfilePost(ctx *context.Context) {
 	ctx.Data["PageIsSettingsProfile"] = true
 
 	if ctx.HasError() {
+		loadProfileFields(ctx)
+		if ctx.Written() {
+			return
+		}
 		ctx.HTML(200, tplSettingsProfile)
 		return
 	}
@@ -106,6 +125,26 @@ func ProfilePost(ctx *context.Context) {
 	}
 	ctx.User.Description = form.Description
 	ctx.User.KeepActivityPrivate = form.KeepActivityPrivate
+
+	fields, err := models.GetProfileFields()
+	if err != nil {
+		ctx.ServerError("GetProfileFields", err)
+		return
+	}
+	values := make(map[int64]string, len(fields))
+	for _, field := range fields {
+		values[field.ID] = ctx.Req.FormValue(fmt.Sprintf("profile_field_%d", field.ID))
+	}
+	if err := models.UpdateUserProfileFields(ctx.User.ID, values); err != nil {
+		if models.IsErrInvalidProfileFieldValue(err) {
+			ctx.Flash.Error(ctx.Tr("settings.profile_field_invalid", err.(models.ErrInvalidProfileFieldValue).Label))
+			ctx.Redirect(setting.AppSubURL + "/user/settings")
+			return
+		}
+		ctx.ServerError("UpdateUserProfileFields", err)
+		return
+	}
+
 	if err := models.UpdateUserSetting(ctx.User); err != nil {
 		if _, ok := err.(models.ErrEmailAlreadyUsed); ok {
 			ctx.Flash.Error(ctx.Tr("form.email_been_used"))
@@ -0,0 +1,130 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"errors"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth/webauthn"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// WebAuthnRegister initializes the registration of a WebAuthn credential
+func WebAuthnRegister(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.WebAuthnRegistrationForm)
+	if ctx.HasError() {
+		ctx.Error(http.StatusConflict)
+		return
+	}
+	rp, err := webauthn.NewRelyingParty(setting.AppURL, setting.AppName)
+	if err != nil {
+		ctx.ServerError("NewRelyingParty", err)
+		return
+	}
+	creds, err := models.GetWebAuthnCredentialsByUID(ctx.User.ID)
+	if err != nil {
+		ctx.ServerError("GetWebAuthnCredentialsByUID", err)
+		return
+	}
+	for _, cred := range creds {
+		if cred.Name == form.Name {
+			ctx.Error(http.StatusConflict, "Name already taken")
+			return
+		}
+	}
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		ctx.ServerError("NewChallenge", err)
+		return
+	}
+	if err := ctx.Session.Set("webauthnChallenge", challenge); err != nil {
+		ctx.ServerError("Unable to set session key for webauthnChallenge", err)
+		return
+	}
+	if err := ctx.Session.Set("webauthnName", form.Name); err != nil {
+		ctx.ServerError("Unable to set session key for webauthnName", err)
+		return
+	}
+	// Here we're just going to try to release the session early
+	if err := ctx.Session.Release(); err != nil {
+		// we'll tolerate errors here as they *should* get saved elsewhere
+		log.Error("Unable to save changes to the session: %v", err)
+	}
+
+	ctx.JSON(http.StatusOK, rp.CreationOptions(challenge, &webauthn.User{
+		Handle:      models.WebAuthnUserHandle(ctx.User),
+		Name:        ctx.User.Name,
+		DisplayName: ctx.User.DisplayName(),
+	}, creds.CredentialIDs()))
+}
+
+// WebAuthnRegisterPost receives the credential created by the authenticator
+func WebAuthnRegisterPost(ctx *context.Context) {
+	response := web.GetForm(ctx).(*webauthn.CreationResponse)
+	challSess := ctx.Session.Get("webauthnChallenge")
+	nameSess := ctx.Session.Get("webauthnName")
+	if challSess == nil || nameSess == nil {
+		ctx.ServerError("WebAuthnRegisterPost", errors.New("not in WebAuthn session"))
+		return
+	}
+	_ = ctx.Session.Delete("webauthnChallenge")
+	_ = ctx.Session.Delete("webauthnName")
+
+	rp, err := webauthn.NewRelyingParty(setting.AppURL, setting.AppName)
+	if err != nil {
+		ctx.ServerError("NewRelyingParty", err)
+		return
+	}
+	cred, err := rp.VerifyCreation(response, challSess.(string))
+	if err != nil {
+		log.Info("Invalid WebAuthn credential of %s from %s: %v", ctx.User.Name, ctx.RemoteAddr(), err)
+		ctx.Error(http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := models.GetWebAuthnCredentialByCredID(webauthn.EncodeID(cred.ID)); err == nil {
+		ctx.Error(http.StatusConflict, "Credential already registered")
+		return
+	} else if !models.IsErrWebAuthnCredentialNotExist(err) {
+		ctx.ServerError("GetWebAuthnCredentialByCredID", err)
+		return
+	}
+	if _, err := models.CreateWebAuthnCredential(ctx.User, nameSess.(string), cred); err != nil {
+		ctx.ServerError("CreateWebAuthnCredential", err)
+		return
+	}
+	ctx.Status(http.StatusOK)
+}
+
+// WebAuthnDelete deletes a WebAuthn credential by id
+func WebAuthnDelete(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.WebAuthnDeleteForm)
+	cred, err := models.GetWebAuthnCredentialByID(form.ID)
+	if err != nil {
+		if models.IsErrWebAuthnCredentialNotExist(err) {
+			ctx.Status(http.StatusOK)
+			return
+		}
+		ctx.ServerError("GetWebAuthnCredentialByID", err)
+		return
+	}
+	if cred.UserID != ctx.User.ID {
+		ctx.Status(http.StatusUnauthorized)
+		return
+	}
+	if err := models.DeleteWebAuthnCredential(cred); err != nil {
+		ctx.ServerError("DeleteWebAuthnCredential", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/user/settings/security",
+	})
+}
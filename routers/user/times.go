@@ -0,0 +1,68 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	issue_service "code.gitea.io/gitea/services/issue"
+)
+
+// tplTimeReport template for the report of the times tracked by the user
+const tplTimeReport base.TplName = "user/times"
+
+// getTimeReport returns the report of the times tracked by the current user within the requested date range
+func getTimeReport(ctx *context.Context) *models.TrackedTimeReport {
+	if !setting.Service.EnableTimetracking {
+		ctx.NotFound("EnableTimetracking", nil)
+		return nil
+	}
+
+	from, until := models.TrackedTimeReportRange(ctx.Query("from"), ctx.Query("until"))
+	group := ctx.Query("group")
+	if !models.IsValidTrackedTimeReportGroup(group) {
+		group = models.TrackedTimeReportWeekly
+	}
+	report, err := models.GetTrackedTimeReport(models.FindTrackedTimesOptions{UserID: ctx.User.ID}, from, until, group)
+	if err != nil {
+		ctx.ServerError("GetTrackedTimeReport", err)
+		return nil
+	}
+	return report
+}
+
+// TimeReport renders the times tracked by the user within a date range, grouped by periods
+func TimeReport(ctx *context.Context) {
+	report := getTimeReport(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.Data["Title"] = ctx.Tr("repo.time_report")
+	ctx.Data["Report"] = report
+	ctx.Data["ExportLink"] = ctx.Link + "/export?" + ctx.Req.URL.RawQuery
+	ctx.HTML(http.StatusOK, tplTimeReport)
+}
+
+// TimeReportExport exports the times tracked by the user within a date range as CSV
+func TimeReportExport(ctx *context.Context) {
+	report := getTimeReport(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.Resp.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	ctx.Resp.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-times-%s-%s.csv"`,
+		ctx.User.Name, report.From.Format("20060102"), report.Until.Format("20060102")))
+	if err := issue_service.WriteTrackedTimesCSV(ctx.Resp, report.Times()); err != nil {
+		log.Error("WriteTrackedTimesCSV: %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package actions implements the built-in CI: it runs the workflows in the .gitea/workflows
+// directory of a repository on registered runners and reports their results as commit statuses.
+package actions
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+const (
+	// WorkflowsDir is the directory of a repository the workflows are read from
+	WorkflowsDir = ".gitea/workflows"
+
+	// maxWorkflowSize is the size above which a workflow file is ignored
+	maxWorkflowSize = 1024 * 1024
+)
+
+// TriggerOptions represents an event which may trigger workflows
+type TriggerOptions struct {
+	Repo *models.Repository
+	// HeadRepo is the repository the commit is read from if it is not Repo, e.g. the head repository of a pull request
+	HeadRepo *models.Repository
+	Doer     *models.User
+	Event    string
+	Ref      string
+	// CommitSHA is the commit the workflows are read from and run on.
+	// It may also be a ref which is resolved in the repository the commit is read from.
+	CommitSHA string
+	Title     string
+	// MatchRef is the name the branch and tag filters of the workflows are matched against:
+	// the pushed branch or tag or the base branch of a pull request
+	MatchRef string
+	IsTag    bool
+}
+
+// DetectedWorkflow represents a workflow file found in a commit
+type DetectedWorkflow struct {
+	// ID is the file name of the workflow
+	ID string
+	*Workflow
+}
+
+// DetectWorkflows returns the valid workflows in the workflows directory of a commit.
+// Invalid workflow files are logged and skipped.
+func DetectWorkflows(commit *git.Commit) ([]*DetectedWorkflow, error) {
+	tree, err := commit.SubTree(WorkflowsDir)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	entries, err := tree.ListEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	var workflows []*DetectedWorkflow
+	for _, entry := range entries {
+		ext := strings.ToLower(path.Ext(entry.Name()))
+		if !entry.IsRegular() || (ext != ".yml" && ext != ".yaml") || entry.Size() > maxWorkflowSize {
+			continue
+		}
+		content, err := readBlob(entry.Blob())
+		if err != nil {
+			return nil, err
+		}
+		wf, err := ParseWorkflow(content)
+		if err != nil {
+			log.Warn("Invalid workflow %s in commit %s: %v", entry.Name(), commit.ID, err)
+			continue
+		}
+		if wf.Name == "" {
+			wf.Name = entry.Name()
+		}
+		workflows = append(workflows, &DetectedWorkflow{
+			ID:       entry.Name(),
+			Workflow: wf,
+		})
+	}
+	return workflows, nil
+}
+
+func readBlob(blob *git.Blob) ([]byte, error) {
+	rd, err := blob.DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer rd.Close()
+	return ioutil.ReadAll(io.LimitReader(rd, maxWorkflowSize))
+}
+
+// CreateRuns creates a run for each workflow of the commit which is triggered by the event
+func CreateRuns(opts *TriggerOptions) error {
+	repo := opts.Repo
+	if opts.HeadRepo != nil {
+		repo = opts.HeadRepo
+	}
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	commit, err := gitRepo.GetCommit(opts.CommitSHA)
+	if err != nil {
+		return fmt.Errorf("GetCommit[%s]: %v", opts.CommitSHA, err)
+	}
+	opts.CommitSHA = commit.ID.String()
+	workflows, err := DetectWorkflows(commit)
+	if err != nil {
+		return fmt.Errorf("DetectWorkflows: %v", err)
+	}
+
+	needApproval, err := runsNeedApproval(opts)
+	if err != nil {
+		return err
+	}
+	for _, wf := range workflows {
+		if !wf.Match(opts.Event, opts.MatchRef, opts.IsTag) {
+			continue
+		}
+		if err := createRun(opts, wf, needApproval); err != nil {
+			return fmt.Errorf("createRun[%s]: %v", wf.ID, err)
+		}
+	}
+	return nil
+}
+
+// runsNeedApproval returns true if the runs have to be approved before their jobs are run. The workflows of a pull
+// request from a fork are written by whoever can push to the fork, so they only run once a user who can write
+// to the repository approved them, unless the user who triggered them can write to it.
+func runsNeedApproval(opts *TriggerOptions) (bool, error) {
+	if opts.HeadRepo == nil || opts.HeadRepo.ID == opts.Repo.ID {
+		return false, nil
+	}
+	perm, err := models.GetUserRepoPermission(opts.Repo, opts.Doer)
+	if err != nil {
+		return false, fmt.Errorf("GetUserRepoPermission: %v", err)
+	}
+	return !perm.CanWrite(models.UnitTypeCode), nil
+}
+
+func createRun(opts *TriggerOptions, wf *DetectedWorkflow, needApproval bool) error {
+	run := &models.ActionRun{
+		Title:         opts.Title,
+		RepoID:        opts.Repo.ID,
+		Repo:          opts.Repo,
+		WorkflowID:    wf.ID,
+		WorkflowName:  wf.Name,
+		TriggerUserID: opts.Doer.ID,
+		TriggerUser:   opts.Doer,
+		Ref:           opts.Ref,
+		CommitSHA:     opts.CommitSHA,
+		Event:         opts.Event,
+		Status:        models.ActionStatusWaiting,
+		NeedApproval:  needApproval,
+	}
+	if needApproval {
+		run.Status = models.ActionStatusBlocked
+	}
+
+	jobs := make([]*models.ActionRunJob, 0, len(wf.Jobs))
+	for _, j := range wf.Jobs {
+		status := models.ActionStatusWaiting
+		if len(j.Needs) > 0 || needApproval {
+			status = models.ActionStatusBlocked
+		}
+		jobs = append(jobs, &models.ActionRunJob{
+			JobID:           j.ID,
+			Name:            j.Name,
+			Needs:           j.Needs,
+			RunsOn:          j.RunsOn,
+			WorkflowPayload: j.Payload,
+			Status:          status,
+		})
+	}
+
+	if err := models.InsertActionRun(run, jobs); err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		job.Run = run
+		createCommitStatus(job)
+	}
+	return nil
+}
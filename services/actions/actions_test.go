@@ -0,0 +1,72 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForkPullRequestRunApproval(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 27}).(*models.Repository)
+	fork := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 29}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: 19}).(*models.User)
+	poster := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+
+	needApproval, err := runsNeedApproval(&TriggerOptions{Repo: repo, HeadRepo: repo, Doer: poster})
+	assert.NoError(t, err)
+	assert.False(t, needApproval)
+	needApproval, err = runsNeedApproval(&TriggerOptions{Repo: repo, HeadRepo: fork, Doer: owner})
+	assert.NoError(t, err)
+	assert.False(t, needApproval)
+	needApproval, err = runsNeedApproval(&TriggerOptions{Repo: repo, HeadRepo: fork, Doer: poster})
+	assert.NoError(t, err)
+	assert.True(t, needApproval)
+
+	wf, err := ParseWorkflow([]byte(`on: pull_request
+jobs:
+  test:
+    runs-on: linux
+  build:
+    needs: test
+    runs-on: linux
+`))
+	assert.NoError(t, err)
+	opts := &TriggerOptions{
+		Repo:      repo,
+		HeadRepo:  fork,
+		Doer:      poster,
+		Event:     "pull_request",
+		CommitSHA: "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+	}
+	assert.NoError(t, createRun(opts, &DetectedWorkflow{ID: "ci.yml", Workflow: wf}, true))
+	run := models.AssertExistsAndLoadBean(t, &models.ActionRun{RepoID: repo.ID, WorkflowID: "ci.yml"}).(*models.ActionRun)
+	assert.True(t, run.NeedApproval)
+	assert.Equal(t, models.ActionStatusBlocked, run.Status)
+
+	// the jobs of the run are not given to a runner before the run is approved
+	runner := &models.ActionRunner{ID: 1, Labels: []string{"linux"}}
+	job, err := FetchJob(runner)
+	assert.NoError(t, err)
+	assert.Nil(t, job)
+
+	assert.NoError(t, ApproveRun(run, owner))
+	run = models.AssertExistsAndLoadBean(t, &models.ActionRun{ID: run.ID}).(*models.ActionRun)
+	assert.False(t, run.NeedApproval)
+	assert.Equal(t, owner.ID, run.ApprovedBy)
+	assert.Equal(t, models.ActionStatusWaiting, run.Status)
+
+	job, err = FetchJob(runner)
+	assert.NoError(t, err)
+	if assert.NotNil(t, job) {
+		assert.Equal(t, "test", job.JobID)
+	}
+	models.AssertExistsAndLoadBean(t, &models.ActionRunJob{RunID: run.ID, JobID: "build", Status: models.ActionStatusBlocked})
+}
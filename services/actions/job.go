@@ -0,0 +1,270 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/eventsource"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+var commitStatusDescriptions = map[models.ActionStatus]string{
+	models.ActionStatusWaiting:   "Waiting for a runner",
+	models.ActionStatusRunning:   "In progress",
+	models.ActionStatusSuccess:   "Successful",
+	models.ActionStatusFailure:   "Failing",
+	models.ActionStatusCancelled: "Cancelled",
+	models.ActionStatusSkipped:   "Skipped",
+	models.ActionStatusBlocked:   "Waiting for the jobs it needs",
+}
+
+// createCommitStatus reports the status of a job as a commit status of the commit of the run
+func createCommitStatus(job *models.ActionRunJob) {
+	if err := job.LoadRun(); err != nil {
+		log.Error("LoadRun[%d]: %v", job.ID, err)
+		return
+	}
+	run := job.Run
+	if err := run.LoadAttributes(); err != nil {
+		log.Error("LoadAttributes[%d]: %v", run.ID, err)
+		return
+	}
+	description := commitStatusDescriptions[job.Status]
+	if run.NeedApproval && !job.Status.IsDone() {
+		description = "Waiting for approval"
+	}
+
+	if err := models.NewCommitStatus(models.NewCommitStatusOptions{
+		Repo:    run.Repo,
+		Creator: run.TriggerUser,
+		SHA:     run.CommitSHA,
+		CommitStatus: &models.CommitStatus{
+			State:       job.Status.CommitStatusState(),
+			TargetURL:   run.Repo.HTMLURL() + fmt.Sprintf("/actions/runs/%d", run.ID),
+			Description: description,
+			Context:     fmt.Sprintf("%s / %s (%s)", run.WorkflowName, job.Name, run.Event),
+		},
+	}); err != nil {
+		log.Error("NewCommitStatus[run: %d, job: %d]: %v", run.ID, job.ID, err)
+		return
+	}
+	eventsource.GetManager().Publish(eventsource.CommitStatusTopic(run.Repo.ID, run.CommitSHA), "status", 0)
+}
+
+// FetchJob assigns a waiting job the runner can run to the runner and returns it, or nil if there is none
+func FetchJob(runner *models.ActionRunner) (*models.ActionRunJob, error) {
+	if err := models.UpdateActionRunnerLastOnline(runner); err != nil {
+		return nil, err
+	}
+	job, err := models.PickActionRunJob(runner)
+	if err != nil || job == nil {
+		return nil, err
+	}
+	if err := job.LoadRun(); err != nil {
+		return nil, err
+	}
+	if err := job.Run.LoadAttributes(); err != nil {
+		return nil, err
+	}
+
+	createCommitStatus(job)
+	if err := updateRunStatus(job.Run); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// UpdateJobStatus records the status a runner reported for a running job and revokes
+// the token of a finished job. If the job is no longer running, e.g. because it was
+// cancelled, it is left unchanged.
+func UpdateJobStatus(job *models.ActionRunJob, status models.ActionStatus) error {
+	switch status {
+	case models.ActionStatusRunning:
+		return nil
+	case models.ActionStatusSuccess, models.ActionStatusFailure:
+	default:
+		return fmt.Errorf("runners cannot set the status of a job to %s", status)
+	}
+	if job.Status != models.ActionStatusRunning {
+		return nil
+	}
+
+	job.Status = status
+	job.StoppedUnix = timeutil.TimeStampNow()
+	job.TokenHash = ""
+	updated, err := models.UpdateActionRunJob(job, []models.ActionStatus{models.ActionStatusRunning}, "status", "stopped_unix", "token_hash")
+	if err != nil || !updated {
+		return err
+	}
+	createCommitStatus(job)
+
+	if err := job.LoadRun(); err != nil {
+		return err
+	}
+	if err := resolveBlockedJobs(job.Run); err != nil {
+		return err
+	}
+	return updateRunStatus(job.Run)
+}
+
+// CancelRun cancels all jobs of a run which have not finished yet and revokes their tokens
+func CancelRun(run *models.ActionRun) error {
+	jobs, err := models.GetActionRunJobs(run.ID)
+	if err != nil {
+		return err
+	}
+	now := timeutil.TimeStampNow()
+	for _, job := range jobs {
+		if job.Status.IsDone() {
+			continue
+		}
+		job.Run = run
+		job.Status = models.ActionStatusCancelled
+		job.StoppedUnix = now
+		job.TokenHash = ""
+		updated, err := models.UpdateActionRunJob(job, []models.ActionStatus{
+			models.ActionStatusWaiting, models.ActionStatusRunning, models.ActionStatusBlocked,
+		}, "status", "stopped_unix", "token_hash")
+		if err != nil {
+			return err
+		} else if updated {
+			createCommitStatus(job)
+		}
+	}
+	return updateRunStatus(run)
+}
+
+// ApproveRun approves a run which needs approval and starts its jobs which do not need other jobs
+func ApproveRun(run *models.ActionRun, doer *models.User) error {
+	if !run.NeedApproval {
+		return nil
+	}
+	run.NeedApproval = false
+	run.ApprovedBy = doer.ID
+	if err := models.UpdateActionRun(run, "need_approval", "approved_by"); err != nil {
+		return err
+	}
+	if err := resolveBlockedJobs(run); err != nil {
+		return err
+	}
+	return updateRunStatus(run)
+}
+
+// resolveBlockedJobs starts the blocked jobs whose needed jobs all succeeded
+// and skips the ones of which a needed job did not succeed, the jobs of a run
+// which needs approval stay blocked
+func resolveBlockedJobs(run *models.ActionRun) error {
+	if run.NeedApproval {
+		return nil
+	}
+	jobs, err := models.GetActionRunJobs(run.ID)
+	if err != nil {
+		return err
+	}
+	statuses := make(map[string]models.ActionStatus, len(jobs))
+	for _, job := range jobs {
+		statuses[job.JobID] = job.Status
+	}
+
+	// skipping a job can unblock or skip the jobs which need it
+	for changed := true; changed; {
+		changed = false
+		for _, job := range jobs {
+			if job.Status != models.ActionStatusBlocked {
+				continue
+			}
+			status := models.ActionStatusWaiting
+			for _, need := range job.Needs {
+				switch s := statuses[need]; {
+				case s == models.ActionStatusSuccess:
+				case s.IsDone():
+					status = models.ActionStatusSkipped
+				default:
+					if status == models.ActionStatusWaiting {
+						status = models.ActionStatusBlocked
+					}
+				}
+				if status == models.ActionStatusSkipped {
+					break
+				}
+			}
+			if status == models.ActionStatusBlocked {
+				continue
+			}
+
+			job.Run = run
+			job.Status = status
+			cols := []string{"status"}
+			if status == models.ActionStatusSkipped {
+				job.StoppedUnix = timeutil.TimeStampNow()
+				cols = append(cols, "stopped_unix")
+			}
+			updated, err := models.UpdateActionRunJob(job, []models.ActionStatus{models.ActionStatusBlocked}, cols...)
+			if err != nil {
+				return err
+			} else if updated {
+				createCommitStatus(job)
+			}
+			statuses[job.JobID] = job.Status
+			changed = true
+		}
+	}
+	return nil
+}
+
+// updateRunStatus sets the status of a run from the statuses of its jobs
+func updateRunStatus(run *models.ActionRun) error {
+	jobs, err := models.GetActionRunJobs(run.ID)
+	if err != nil {
+		return err
+	}
+
+	done := true
+	started := false
+	status := models.ActionStatusSuccess
+	for _, job := range jobs {
+		switch job.Status {
+		case models.ActionStatusFailure:
+			status = models.ActionStatusFailure
+		case models.ActionStatusCancelled:
+			if status != models.ActionStatusFailure {
+				status = models.ActionStatusCancelled
+			}
+		}
+		if !job.Status.IsDone() {
+			done = false
+		}
+		if job.StartedUnix > 0 {
+			started = true
+		}
+	}
+
+	cols := []string{"status"}
+	switch {
+	case done:
+		if run.StoppedUnix == 0 {
+			run.StoppedUnix = timeutil.TimeStampNow()
+			cols = append(cols, "stopped_unix")
+		}
+	case run.NeedApproval:
+		status = models.ActionStatusBlocked
+	case started:
+		status = models.ActionStatusRunning
+	default:
+		status = models.ActionStatusWaiting
+	}
+	if started && run.StartedUnix == 0 {
+		run.StartedUnix = timeutil.TimeStampNow()
+		cols = append(cols, "started_unix")
+	}
+	if status == run.Status && len(cols) == 1 {
+		return nil
+	}
+	run.Status = status
+	return models.UpdateActionRun(run, cols...)
+}
@@ -0,0 +1,48 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobTokenRevocation(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	run := &models.ActionRun{RepoID: 1, CommitSHA: "65f1bf27bc3bf70f64657658635e66094edbcb4d", Status: models.ActionStatusWaiting}
+	assert.NoError(t, models.InsertActionRun(run, []*models.ActionRunJob{
+		{JobID: "build", Status: models.ActionStatusWaiting},
+		{JobID: "test", Status: models.ActionStatusWaiting},
+	}))
+	runner := &models.ActionRunner{ID: 1}
+
+	finished, err := FetchJob(runner)
+	assert.NoError(t, err)
+	cancelled, err := FetchJob(runner)
+	assert.NoError(t, err)
+	if !assert.NotNil(t, finished) || !assert.NotNil(t, cancelled) {
+		return
+	}
+	_, err = models.GetActionRunJobByToken(finished.Token)
+	assert.NoError(t, err)
+
+	assert.NoError(t, UpdateJobStatus(finished, models.ActionStatusSuccess))
+	_, err = models.GetActionRunJobByToken(finished.Token)
+	assert.True(t, models.IsErrActionRunJobNotExist(err))
+	job := models.AssertExistsAndLoadBean(t, &models.ActionRunJob{ID: finished.ID}).(*models.ActionRunJob)
+	assert.Empty(t, job.TokenHash)
+
+	_, err = models.GetActionRunJobByToken(cancelled.Token)
+	assert.NoError(t, err)
+	assert.NoError(t, CancelRun(run))
+	_, err = models.GetActionRunJobByToken(cancelled.Token)
+	assert.True(t, models.IsErrActionRunJobNotExist(err))
+	job = models.AssertExistsAndLoadBean(t, &models.ActionRunJob{ID: cancelled.ID}).(*models.ActionRunJob)
+	assert.Empty(t, job.TokenHash)
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+)
+
+func logPath(job *models.ActionRunJob) string {
+	return filepath.Join(setting.Actions.LogPath, filepath.FromSlash(job.LogRelativePath()))
+}
+
+// AppendJobLog appends the output sent by a runner to the log of a job
+func AppendJobLog(job *models.ActionRunJob, r io.Reader) error {
+	p := logPath(job)
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	job.LogSize = fi.Size()
+	_, err = models.UpdateActionRunJob(job, nil, "log_size")
+	return err
+}
+
+// OpenJobLog opens the log of a job. A job without output has an empty log.
+func OpenJobLog(job *models.ActionRunJob) (io.ReadCloser, error) {
+	f, err := os.Open(logPath(job))
+	if os.IsNotExist(err) {
+		return ioutil.NopCloser(strings.NewReader("")), nil
+	}
+	return f, err
+}
+
+// UploadArtifact stores a file uploaded by a job as an artifact of its run.
+// An artifact with the same name uploaded before by a job of the run is replaced.
+func UploadArtifact(job *models.ActionRunJob, name string, r io.Reader) (*models.ActionArtifact, error) {
+	artifact, err := models.GetOrCreateActionArtifact(job, name)
+	if err != nil {
+		return nil, err
+	}
+	if artifact.Size, err = storage.Actions.Save(artifact.RelativePath(), r); err != nil {
+		return nil, err
+	}
+	artifact.JobID = job.ID
+	return artifact, models.UpdateActionArtifact(artifact)
+}
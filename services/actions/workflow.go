@@ -0,0 +1,271 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"gopkg.in/yaml.v2"
+)
+
+// supported workflow events
+const (
+	EventPush        = "push"
+	EventPullRequest = "pull_request"
+)
+
+// Workflow represents a parsed workflow file
+type Workflow struct {
+	Name     string
+	Triggers []*WorkflowTrigger
+	// Jobs are in the order they are defined in the file
+	Jobs []*WorkflowJob
+}
+
+// WorkflowTrigger represents an event which triggers a workflow
+type WorkflowTrigger struct {
+	Event string
+	// Branches and Tags are glob patterns the pushed ref or the base branch of a pull request must match.
+	// If both are empty every ref matches.
+	Branches []string
+	Tags     []string
+}
+
+// WorkflowJob represents a job of a workflow
+type WorkflowJob struct {
+	ID     string
+	Name   string
+	Needs  []string
+	RunsOn []string
+	// Payload is the YAML definition of the job passed to the runner
+	Payload string
+}
+
+// ParseWorkflow parses and validates the content of a workflow file
+func ParseWorkflow(content []byte) (*Workflow, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+
+	wf := &Workflow{}
+	var err error
+	for _, item := range doc {
+		// "on" is a boolean in YAML 1.1 so it is not necessarily a string key
+		key := fmt.Sprint(item.Key)
+		switch key {
+		case "name":
+			wf.Name = fmt.Sprint(item.Value)
+		case "on", "true":
+			if wf.Triggers, err = parseTriggers(item.Value); err != nil {
+				return nil, err
+			}
+		case "jobs":
+			if wf.Jobs, err = parseJobs(item.Value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(wf.Triggers) == 0 {
+		return nil, fmt.Errorf("the workflow has no supported events")
+	}
+	if len(wf.Jobs) == 0 {
+		return nil, fmt.Errorf("the workflow has no jobs")
+	}
+	if err := checkJobNeeds(wf.Jobs); err != nil {
+		return nil, err
+	}
+	return wf, nil
+}
+
+// Match returns true if the workflow is triggered by the event on the given ref.
+// For pull requests the ref is the base branch.
+func (wf *Workflow) Match(event, refName string, isTag bool) bool {
+	for _, t := range wf.Triggers {
+		if t.Event == event && t.match(refName, isTag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *WorkflowTrigger) match(refName string, isTag bool) bool {
+	if len(t.Branches) == 0 && len(t.Tags) == 0 {
+		return true
+	}
+	patterns := t.Branches
+	if isTag {
+		patterns = t.Tags
+	}
+	for _, pattern := range patterns {
+		g, err := glob.Compile(pattern, '/')
+		if err != nil {
+			continue
+		}
+		if g.Match(refName) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseTriggers(value interface{}) ([]*WorkflowTrigger, error) {
+	var triggers []*WorkflowTrigger
+	switch v := value.(type) {
+	case string, []interface{}:
+		events, err := toStringList(v)
+		if err != nil {
+			return nil, fmt.Errorf("on: %v", err)
+		}
+		for _, event := range events {
+			triggers = append(triggers, &WorkflowTrigger{Event: event})
+		}
+	case yaml.MapSlice:
+		for _, item := range v {
+			trigger := &WorkflowTrigger{Event: fmt.Sprint(item.Key)}
+			filters, ok := item.Value.(yaml.MapSlice)
+			if !ok && item.Value != nil {
+				return nil, fmt.Errorf("on.%s: must be a mapping", trigger.Event)
+			}
+			for _, filter := range filters {
+				list, err := toStringList(filter.Value)
+				if err != nil {
+					return nil, fmt.Errorf("on.%s.%v: %v", trigger.Event, filter.Key, err)
+				}
+				switch filter.Key {
+				case "branches":
+					trigger.Branches = list
+				case "tags":
+					trigger.Tags = list
+				}
+			}
+			triggers = append(triggers, trigger)
+		}
+	default:
+		return nil, fmt.Errorf("on: must be a string, a list or a mapping")
+	}
+
+	// ignore events we cannot trigger
+	supported := triggers[:0]
+	for _, t := range triggers {
+		if t.Event == EventPush || t.Event == EventPullRequest {
+			supported = append(supported, t)
+		}
+	}
+	return supported, nil
+}
+
+func parseJobs(value interface{}) ([]*WorkflowJob, error) {
+	items, ok := value.(yaml.MapSlice)
+	if !ok {
+		return nil, fmt.Errorf("jobs: must be a mapping")
+	}
+
+	jobs := make([]*WorkflowJob, 0, len(items))
+	for _, item := range items {
+		job := &WorkflowJob{ID: fmt.Sprint(item.Key)}
+		def, ok := item.Value.(yaml.MapSlice)
+		if !ok {
+			return nil, fmt.Errorf("jobs.%s: must be a mapping", job.ID)
+		}
+		for _, field := range def {
+			var err error
+			switch field.Key {
+			case "name":
+				job.Name = fmt.Sprint(field.Value)
+			case "needs":
+				job.Needs, err = toStringList(field.Value)
+			case "runs-on":
+				job.RunsOn, err = toStringList(field.Value)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("jobs.%s.%v: %v", job.ID, field.Key, err)
+			}
+		}
+		if len(job.RunsOn) == 0 {
+			return nil, fmt.Errorf("jobs.%s: runs-on is required", job.ID)
+		}
+		if job.Name == "" {
+			job.Name = job.ID
+		}
+
+		payload, err := yaml.Marshal(def)
+		if err != nil {
+			return nil, err
+		}
+		job.Payload = string(payload)
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// checkJobNeeds checks that the jobs only need jobs of the workflow and that there are no cycles
+func checkJobNeeds(jobs []*WorkflowJob) error {
+	byID := make(map[string]*WorkflowJob, len(jobs))
+	for _, job := range jobs {
+		byID[job.ID] = job
+	}
+	for _, job := range jobs {
+		for _, need := range job.Needs {
+			if _, ok := byID[need]; !ok {
+				return fmt.Errorf("jobs.%s.needs: unknown job %q", job.ID, need)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(jobs))
+	var visit func(job *WorkflowJob) error
+	visit = func(job *WorkflowJob) error {
+		switch state[job.ID] {
+		case visiting:
+			return fmt.Errorf("jobs.%s.needs: circular dependency", job.ID)
+		case visited:
+			return nil
+		}
+		state[job.ID] = visiting
+		for _, need := range job.Needs {
+			if err := visit(byID[need]); err != nil {
+				return err
+			}
+		}
+		state[job.ID] = visited
+		return nil
+	}
+	for _, job := range jobs {
+		if err := visit(job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toStringList converts a YAML value which is either a string or a list of strings
+func toStringList(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{strings.TrimSpace(v)}, nil
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("must be a list of strings")
+			}
+			list = append(list, strings.TrimSpace(s))
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("must be a string or a list of strings")
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWorkflow(t *testing.T) {
+	wf, err := ParseWorkflow([]byte(`name: CI
+on:
+  push:
+    branches: [main, "release/*"]
+  pull_request:
+jobs:
+  test:
+    name: Unit tests
+    runs-on: linux
+    steps:
+      - run: make test
+  build:
+    needs: test
+    runs-on: [linux, docker]
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, "CI", wf.Name)
+	assert.Len(t, wf.Triggers, 2)
+	if assert.Len(t, wf.Jobs, 2) {
+		assert.Equal(t, "test", wf.Jobs[0].ID)
+		assert.Equal(t, "Unit tests", wf.Jobs[0].Name)
+		assert.Equal(t, []string{"linux"}, wf.Jobs[0].RunsOn)
+		assert.Contains(t, wf.Jobs[0].Payload, "make test")
+		assert.Equal(t, "build", wf.Jobs[1].Name)
+		assert.Equal(t, []string{"test"}, wf.Jobs[1].Needs)
+		assert.Equal(t, []string{"linux", "docker"}, wf.Jobs[1].RunsOn)
+	}
+
+	assert.True(t, wf.Match(EventPush, "main", false))
+	assert.True(t, wf.Match(EventPush, "release/1.15", false))
+	assert.False(t, wf.Match(EventPush, "feature", false))
+	assert.False(t, wf.Match(EventPush, "v1.0", true))
+	assert.True(t, wf.Match(EventPullRequest, "feature", false))
+
+	wf, err = ParseWorkflow([]byte(`on: [push, schedule]
+jobs:
+  test:
+    runs-on: linux
+`))
+	assert.NoError(t, err)
+	if assert.Len(t, wf.Triggers, 1) {
+		assert.Equal(t, EventPush, wf.Triggers[0].Event)
+	}
+	assert.True(t, wf.Match(EventPush, "v1.0", true))
+	assert.False(t, wf.Match(EventPullRequest, "main", false))
+}
+
+func TestParseWorkflow_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"no events":      "on: schedule\njobs:\n  test:\n    runs-on: linux\n",
+		"no jobs":        "on: push\n",
+		"no runs-on":     "on: push\njobs:\n  test:\n    steps: []\n",
+		"unknown needs":  "on: push\njobs:\n  test:\n    runs-on: linux\n    needs: build\n",
+		"circular needs": "on: push\njobs:\n  a:\n    runs-on: linux\n    needs: b\n  b:\n    runs-on: linux\n    needs: a\n",
+		"invalid yaml":   "on: [push\n",
+	} {
+		_, err := ParseWorkflow([]byte(content))
+		assert.Error(t, err, name)
+	}
+}
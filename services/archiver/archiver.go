@@ -6,55 +6,101 @@
 package archiver
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
 	"code.gitea.io/gitea/modules/setting"
-	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/storage"
 )
 
+// ArchiveStatus is the state of the generation of an archive
+type ArchiveStatus int
+
+// The states of the generation of an archive
+const (
+	// ArchiveQueued means the archive waits in the archive queue to be generated
+	ArchiveQueued ArchiveStatus = iota
+	// ArchiveGenerating means the archive is being generated
+	ArchiveGenerating
+	// ArchiveComplete means the archive is stored in the repository archive storage
+	ArchiveComplete
+	// ArchiveFailed means the archive could not be generated
+	ArchiveFailed
+)
+
+// String returns the name of the status which is returned by the progress endpoint
+func (s ArchiveStatus) String() string {
+	switch s {
+	case ArchiveQueued:
+		return "queued"
+	case ArchiveGenerating:
+		return "generating"
+	case ArchiveComplete:
+		return "complete"
+	case ArchiveFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
 // ArchiveRequest defines the parameters of an archive request, which notably
 // includes the specific repository being archived as well as the commit, the
 // name by which it was requested, and the kind of archive being requested.
 // This is entirely opaque to external entities, though, and mostly used as a
 // handle elsewhere.
 type ArchiveRequest struct {
-	uri             string
-	repo            *git.Repository
-	refName         string
-	ext             string
-	archivePath     string
-	archiveType     git.ArchiveType
-	archiveComplete bool
-	commit          *git.Commit
-	cchan           chan struct{}
+	uri         string
+	repoID      int64
+	repo        *git.Repository
+	refName     string
+	ext         string
+	archivePath string
+	archiveType git.ArchiveType
+	bundleRef   string
+	commit      *git.Commit
+	// status is guarded by archiveMutex
+	status ArchiveStatus
+	cchan  chan struct{}
+}
+
+// archiveTask is what is pushed to the archive queue to generate an archive, the queue may
+// be persistent so it cannot refer to the opened repository of the request
+type archiveTask struct {
+	RepoID      int64
+	CommitID    string
+	ArchiveType git.ArchiveType
+	BundleRef   string
+	ArchivePath string
 }
 
-var archiveInProgress []*ArchiveRequest
+// archiveInProgress are the requests of this process which have not been completed yet by
+// their archive paths
+var archiveInProgress = make(map[string]*ArchiveRequest)
 var archiveMutex sync.Mutex
 
+var archiveQueue queue.UniqueQueue
+
 // SHA1 hashes will only go up to 40 characters, but SHA256 hashes will go all
 // the way to 64.
 var shaRegex = regexp.MustCompile(`^[0-9a-f]{4,64}$`)
 
-// These facilitate testing, by allowing the unit tests to control (to some extent)
-// the goroutine used for processing the queue.
-var archiveQueueMutex *sync.Mutex
-var archiveQueueStartCond *sync.Cond
-var archiveQueueReleaseCond *sync.Cond
-
-// GetArchivePath returns the path from which we can serve this archive.
+// GetArchivePath returns the path of this archive in the repository archive storage.
 func (aReq *ArchiveRequest) GetArchivePath() string {
 	return aReq.archivePath
 }
@@ -65,9 +111,29 @@ func (aReq *ArchiveRequest) GetArchiveName() string {
 	return aReq.refName + aReq.ext
 }
 
+// Status returns the state of the generation of the archive of this request.
+func (aReq *ArchiveRequest) Status() ArchiveStatus {
+	archiveMutex.Lock()
+	defer archiveMutex.Unlock()
+	return aReq.status
+}
+
 // IsComplete returns the completion status of this request.
 func (aReq *ArchiveRequest) IsComplete() bool {
-	return aReq.archiveComplete
+	return aReq.Status() == ArchiveComplete
+}
+
+// done returns a channel which is closed once the request has been processed
+func (aReq *ArchiveRequest) done() <-chan struct{} {
+	archiveMutex.Lock()
+	defer archiveMutex.Unlock()
+	if aReq.cchan == nil {
+		// The request has not been enqueued, there is nothing to wait for
+		cchan := make(chan struct{})
+		close(cchan)
+		return cchan
+	}
+	return aReq.cchan
 }
 
 // WaitForCompletion will wait for this request to complete, with no timeout.
@@ -75,7 +141,7 @@ func (aReq *ArchiveRequest) IsComplete() bool {
 // have also been closed due to an error.
 func (aReq *ArchiveRequest) WaitForCompletion(ctx *context.Context) bool {
 	select {
-	case <-aReq.cchan:
+	case <-aReq.done():
 	case <-ctx.Req.Context().Done():
 	}
 
@@ -91,166 +157,269 @@ func (aReq *ArchiveRequest) TimedWaitForCompletion(ctx *context.Context, dur tim
 	select {
 	case <-time.After(dur):
 		timeout = true
-	case <-aReq.cchan:
+	case <-aReq.done():
 	case <-ctx.Req.Context().Done():
 	}
 
 	return aReq.IsComplete(), timeout
 }
 
-// The caller must hold the archiveMutex across calls to getArchiveRequest.
-func getArchiveRequest(repo *git.Repository, commit *git.Commit, archiveType git.ArchiveType) *ArchiveRequest {
-	for _, r := range archiveInProgress {
-		// Need to be referring to the same repository.
-		if r.repo.Path == repo.Path && r.commit.ID == commit.ID && r.archiveType == archiveType {
-			return r
-		}
-	}
-	return nil
+// ErrUnknownArchiveFormat is returned by NewRequest if the URI does not end with the extension
+// of a supported archive format
+type ErrUnknownArchiveFormat struct {
+	URI string
+}
+
+// Error implements error
+func (err ErrUnknownArchiveFormat) Error() string {
+	return fmt.Sprintf("unknown archive format: %s", err.URI)
+}
+
+// IsErrUnknownArchiveFormat checks if an error is a ErrUnknownArchiveFormat
+func IsErrUnknownArchiveFormat(err error) bool {
+	_, ok := err.(ErrUnknownArchiveFormat)
+	return ok
+}
+
+// ErrRefNotFound is returned by NewRequest if the URI does not refer to a branch, tag or commit
+// the archive can be created for
+type ErrRefNotFound struct {
+	RefName string
+}
+
+// Error implements error
+func (err ErrRefNotFound) Error() string {
+	return fmt.Sprintf("ref not found: %s", err.RefName)
+}
+
+// IsErrRefNotFound checks if an error is a ErrRefNotFound
+func IsErrRefNotFound(err error) bool {
+	_, ok := err.(ErrRefNotFound)
+	return ok
 }
 
 // DeriveRequestFrom creates an archival request, based on the URI.  The
 // resulting ArchiveRequest is suitable for being passed to ArchiveRepository()
 // if it's determined that the request still needs to be satisfied.
 func DeriveRequestFrom(ctx *context.Context, uri string) *ArchiveRequest {
-	if ctx.Repo == nil || ctx.Repo.GitRepo == nil {
+	if ctx.Repo == nil || ctx.Repo.Repository == nil || ctx.Repo.GitRepo == nil {
 		log.Trace("Repo not initialized")
 		return nil
 	}
+	r, err := NewRequest(ctx.Repo.Repository.ID, ctx.Repo.GitRepo, uri)
+	if err != nil {
+		if IsErrUnknownArchiveFormat(err) {
+			log.Trace("Unknown format: %s", uri)
+		} else if IsErrRefNotFound(err) {
+			ctx.NotFound("DeriveRequestFrom", nil)
+		} else {
+			ctx.ServerError("NewRequest", err)
+		}
+		return nil
+	}
+	return r
+}
+
+// NewRequest creates an archival request of a repository, based on the URI like DeriveRequestFrom.
+func NewRequest(repoID int64, repo *git.Repository, uri string) (*ArchiveRequest, error) {
 	r := &ArchiveRequest{
-		uri:  uri,
-		repo: ctx.Repo.GitRepo,
+		uri:    uri,
+		repoID: repoID,
+		repo:   repo,
 	}
 
+	// The archives of a repository are stored below its ID in the repository archive storage
+	r.archivePath = strconv.FormatInt(r.repoID, 10)
 	switch {
 	case strings.HasSuffix(uri, ".zip"):
 		r.ext = ".zip"
-		r.archivePath = path.Join(r.repo.Path, "archives/zip")
+		r.archivePath = path.Join(r.archivePath, "zip")
 		r.archiveType = git.ZIP
 	case strings.HasSuffix(uri, ".tar.gz"):
 		r.ext = ".tar.gz"
-		r.archivePath = path.Join(r.repo.Path, "archives/targz")
+		r.archivePath = path.Join(r.archivePath, "targz")
 		r.archiveType = git.TARGZ
+	case strings.HasSuffix(uri, ".tar.zst"):
+		r.ext = ".tar.zst"
+		r.archivePath = path.Join(r.archivePath, "tarzst")
+		r.archiveType = git.TARZST
+	case strings.HasSuffix(uri, ".bundle"):
+		r.ext = ".bundle"
+		r.archivePath = path.Join(r.archivePath, "bundle")
+		r.archiveType = git.BUNDLE
 	default:
-		log.Trace("Unknown format: %s", uri)
-		return nil
+		return nil, ErrUnknownArchiveFormat{URI: uri}
 	}
 
 	r.refName = strings.TrimSuffix(r.uri, r.ext)
-	isDir, err := util.IsDir(r.archivePath)
-	if err != nil {
-		ctx.ServerError("Download -> util.IsDir(archivePath)", err)
-		return nil
-	}
-	if !isDir {
-		if err := os.MkdirAll(r.archivePath, os.ModePerm); err != nil {
-			ctx.ServerError("Download -> os.MkdirAll(archivePath)", err)
-			return nil
-		}
-	}
 
+	var err error
 	// Get corresponding commit.
 	if r.repo.IsBranchExist(r.refName) {
 		r.commit, err = r.repo.GetBranchCommit(r.refName)
 		if err != nil {
-			ctx.ServerError("GetBranchCommit", err)
-			return nil
+			return nil, fmt.Errorf("GetBranchCommit: %v", err)
+		}
+		if r.archiveType == git.BUNDLE {
+			r.bundleRef = git.BranchPrefix + r.refName
 		}
 	} else if r.repo.IsTagExist(r.refName) {
 		r.commit, err = r.repo.GetTagCommit(r.refName)
 		if err != nil {
-			ctx.ServerError("GetTagCommit", err)
-			return nil
+			return nil, fmt.Errorf("GetTagCommit: %v", err)
+		}
+		if r.archiveType == git.BUNDLE {
+			r.bundleRef = git.TagPrefix + r.refName
 		}
+	} else if r.archiveType == git.BUNDLE {
+		// A bundle contains refs, so it cannot be created for a bare commit
+		return nil, ErrRefNotFound{RefName: r.refName}
 	} else if shaRegex.MatchString(r.refName) {
 		r.commit, err = r.repo.GetCommit(r.refName)
 		if err != nil {
-			ctx.NotFound("GetCommit", nil)
-			return nil
+			return nil, ErrRefNotFound{RefName: r.refName}
 		}
 	} else {
-		ctx.NotFound("DeriveRequestFrom", nil)
-		return nil
+		return nil, ErrRefNotFound{RefName: r.refName}
+	}
+
+	if r.archiveType == git.BUNDLE {
+		// The bundle contains the name of the ref, so a branch and a tag of the same commit have different bundles
+		r.archivePath = path.Join(r.archivePath, base.ShortSha(r.commit.ID.String())+"-"+base.EncodeSha1(r.bundleRef)[:10]+r.ext)
+	} else {
+		r.archivePath = path.Join(r.archivePath, base.ShortSha(r.commit.ID.String())+r.ext)
 	}
 
 	archiveMutex.Lock()
-	defer archiveMutex.Unlock()
-	if rExisting := getArchiveRequest(r.repo, r.commit, r.archiveType); rExisting != nil {
-		return rExisting
+	rExisting := archiveInProgress[r.archivePath]
+	archiveMutex.Unlock()
+	if rExisting != nil {
+		return rExisting, nil
 	}
 
-	r.archivePath = path.Join(r.archivePath, base.ShortSha(r.commit.ID.String())+r.ext)
-	r.archiveComplete, err = util.IsFile(r.archivePath)
+	isStored, err := isArchiveStored(r.archivePath)
 	if err != nil {
-		ctx.ServerError("util.IsFile", err)
-		return nil
+		return nil, fmt.Errorf("isArchiveStored: %v", err)
 	}
-	return r
+	if isStored {
+		r.status = ArchiveComplete
+	}
+	return r, nil
 }
 
-func doArchive(r *ArchiveRequest) {
-	var (
-		err         error
-		tmpArchive  *os.File
-		destArchive *os.File
-	)
+// isArchiveStored returns whether an archive exists in the repository archive storage
+func isArchiveStored(archivePath string) (bool, error) {
+	_, err := storage.RepoArchives.Stat(archivePath)
+	if err == nil {
+		return true, nil
+	} else if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// setArchiveStatus updates the status of the request of this process for an archive, if there is one
+func setArchiveStatus(archivePath string, status ArchiveStatus) {
+	archiveMutex.Lock()
+	defer archiveMutex.Unlock()
+	if r, ok := archiveInProgress[archivePath]; ok {
+		r.status = status
+	}
+}
 
-	// Close the channel to indicate to potential waiters that this request
-	// has finished.
-	defer close(r.cchan)
+// finishArchiveRequest tells the waiters for the request of this process for an archive, if
+// there is one, that it has been processed
+func finishArchiveRequest(archivePath string, status ArchiveStatus) {
+	archiveMutex.Lock()
+	defer archiveMutex.Unlock()
+	r, ok := archiveInProgress[archivePath]
+	if !ok {
+		// The archive was enqueued by another process or before a restart
+		return
+	}
+	r.status = status
+	close(r.cchan)
+	delete(archiveInProgress, archivePath)
+}
 
+func doArchive(task archiveTask) error {
 	// It could have happened that we enqueued two archival requests, due to
 	// race conditions and difficulties in locking.  Do one last check that
-	// the archive we're referring to doesn't already exist.  If it does exist,
-	// then just mark the request as complete and move on.
-	isFile, err := util.IsFile(r.archivePath)
+	// the archive we're referring to doesn't already exist.
+	isStored, err := isArchiveStored(task.ArchivePath)
 	if err != nil {
-		log.Error("Unable to check if %s util.IsFile: %v. Will ignore and recreate.", r.archivePath, err)
+		log.Error("Unable to check if %s is stored: %v. Will ignore and recreate.", task.ArchivePath, err)
 	}
-	if isFile {
-		r.archiveComplete = true
-		return
+	if isStored {
+		return nil
 	}
 
+	setArchiveStatus(task.ArchivePath, ArchiveGenerating)
+
+	repo, err := models.GetRepositoryByID(task.RepoID)
+	if err != nil {
+		return fmt.Errorf("GetRepositoryByID: %v", err)
+	}
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
 	// Create a temporary file to use while the archive is being built.  We
-	// will then copy it into place (r.archivePath) once it's fully
+	// will then save it to the storage (task.ArchivePath) once it's fully
 	// constructed.
-	tmpArchive, err = ioutil.TempFile("", "archive")
+	tmpArchive, err := ioutil.TempFile("", "archive")
 	if err != nil {
-		log.Error("Unable to create a temporary archive file! Error: %v", err)
-		return
+		return fmt.Errorf("unable to create a temporary archive file: %v", err)
 	}
 	defer func() {
 		tmpArchive.Close()
 		os.Remove(tmpArchive.Name())
 	}()
 
-	if err = r.commit.CreateArchive(graceful.GetManager().ShutdownContext(), tmpArchive.Name(), git.CreateArchiveOpts{
-		Format: r.archiveType,
-		Prefix: setting.Repository.PrefixArchiveFiles,
-	}); err != nil {
-		log.Error("Download -> CreateArchive "+tmpArchive.Name(), err)
-		return
+	ctx := graceful.GetManager().ShutdownContext()
+	if task.ArchiveType == git.BUNDLE {
+		if err = gitRepo.CreateBundle(ctx, task.BundleRef, tmpArchive); err != nil {
+			return fmt.Errorf("CreateBundle %s: %v", tmpArchive.Name(), err)
+		}
+	} else {
+		commit, err := gitRepo.GetCommit(task.CommitID)
+		if err != nil {
+			return fmt.Errorf("GetCommit: %v", err)
+		}
+		if err = commit.CreateArchive(ctx, tmpArchive.Name(), git.CreateArchiveOpts{
+			Format: task.ArchiveType,
+			Prefix: setting.Repository.PrefixArchiveFiles,
+		}); err != nil {
+			return fmt.Errorf("CreateArchive %s: %v", tmpArchive.Name(), err)
+		}
 	}
-
-	// Now we copy it into place
-	if destArchive, err = os.Create(r.archivePath); err != nil {
-		log.Error("Unable to open archive " + r.archivePath)
-		return
+	if _, err = tmpArchive.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("unable to rewind %s: %v", tmpArchive.Name(), err)
 	}
-	_, err = io.Copy(destArchive, tmpArchive)
-	destArchive.Close()
-	if err != nil {
-		log.Error("Unable to write archive " + r.archivePath)
-		return
+
+	// Now we save it to the storage
+	if _, err = storage.RepoArchives.Save(task.ArchivePath, tmpArchive); err != nil {
+		return fmt.Errorf("unable to save archive %s: %v", task.ArchivePath, err)
 	}
+	return nil
+}
 
-	// Block any attempt to finalize creating a new request if we're marking
-	r.archiveComplete = true
+func handle(data ...queue.Data) {
+	for _, datum := range data {
+		task := datum.(archiveTask)
+		status := ArchiveComplete
+		if err := doArchive(task); err != nil {
+			log.Error("Unable to generate archive %s of repository %d: %v", task.ArchivePath, task.RepoID, err)
+			status = ArchiveFailed
+		}
+		finishArchiveRequest(task.ArchivePath, status)
+	}
 }
 
-// ArchiveRepository satisfies the ArchiveRequest being passed in.  Processing
-// will occur in a separate goroutine, as this phase may take a while to
+// ArchiveRepository satisfies the ArchiveRequest being passed in.  The archive
+// is generated by the workers of the archive queue, as this may take a while to
 // complete.  If the archive already exists, ArchiveRepository will not do
 // anything.  In all cases, the caller should be examining the *ArchiveRequest
 // being returned for completion, as it may be different than the one they passed
@@ -260,60 +429,40 @@ func ArchiveRepository(request *ArchiveRequest) *ArchiveRequest {
 	// enqueued, or we'll immediately enqueue it if it has not been enqueued
 	// and it is not marked complete.
 	archiveMutex.Lock()
-	defer archiveMutex.Unlock()
-	if rExisting := getArchiveRequest(request.repo, request.commit, request.archiveType); rExisting != nil {
+	if rExisting, ok := archiveInProgress[request.archivePath]; ok {
+		archiveMutex.Unlock()
 		return rExisting
 	}
-	if request.archiveComplete {
+	if request.status == ArchiveComplete {
+		archiveMutex.Unlock()
 		return request
 	}
-
+	request.status = ArchiveQueued
 	request.cchan = make(chan struct{})
-	archiveInProgress = append(archiveInProgress, request)
-	go func() {
-		// Wait to start, if we have the Cond for it.  This is currently only
-		// useful for testing, so that the start and release of queued entries
-		// can be controlled to examine the queue.
-		if archiveQueueStartCond != nil {
-			archiveQueueMutex.Lock()
-			archiveQueueStartCond.Wait()
-			archiveQueueMutex.Unlock()
-		}
-
-		// Drop the mutex while we process the request.  This may take a long
-		// time, and it's not necessary now that we've added the reequest to
-		// archiveInProgress.
-		doArchive(request)
-
-		if archiveQueueReleaseCond != nil {
-			archiveQueueMutex.Lock()
-			archiveQueueReleaseCond.Wait()
-			archiveQueueMutex.Unlock()
-		}
+	archiveInProgress[request.archivePath] = request
+	archiveMutex.Unlock()
+
+	err := archiveQueue.Push(archiveTask{
+		RepoID:      request.repoID,
+		CommitID:    request.commit.ID.String(),
+		ArchiveType: request.archiveType,
+		BundleRef:   request.bundleRef,
+		ArchivePath: request.archivePath,
+	})
+	if err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Unable to push archive %s to the queue: %v", request.archivePath, err)
+		finishArchiveRequest(request.archivePath, ArchiveFailed)
+	}
+	return request
+}
 
-		// Purge this request from the list.  To do so, we'll just take the
-		// index at which we ended up at and swap the final element into that
-		// position, then chop off the now-redundant final element.  The slice
-		// may have change in between these two segments and we may have moved,
-		// so we search for it here.  We could perhaps avoid this search
-		// entirely if len(archiveInProgress) == 1, but we should verify
-		// correctness.
-		archiveMutex.Lock()
-		defer archiveMutex.Unlock()
-
-		idx := -1
-		for _idx, req := range archiveInProgress {
-			if req == request {
-				idx = _idx
-				break
-			}
-		}
-		if idx == -1 {
-			log.Error("ArchiveRepository: Failed to find request for removal.")
-			return
-		}
-		archiveInProgress = append(archiveInProgress[:idx], archiveInProgress[idx+1:]...)
-	}()
+// Init starts the queue whose workers generate the repository archives
+func Init() error {
+	archiveQueue = queue.CreateUniqueQueue("repo-archive", handle, archiveTask{}).(queue.UniqueQueue)
+	if archiveQueue == nil {
+		return errors.New("unable to create repo-archive queue")
+	}
 
-	return request
+	go graceful.GetManager().RunWithShutdownFns(archiveQueue.Run)
+	return nil
 }
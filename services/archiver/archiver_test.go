@@ -5,74 +5,76 @@
 package archiver
 
 import (
+	"bytes"
+	gocontext "context"
+	"io/ioutil"
 	"path/filepath"
-	"sync"
+	"strings"
 	"testing"
 	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/test"
-	"code.gitea.io/gitea/modules/util"
 
 	"github.com/stretchr/testify/assert"
 )
 
-var queueMutex sync.Mutex
-
 func TestMain(m *testing.M) {
 	models.MainTest(m, filepath.Join("..", ".."))
 }
 
-func waitForCount(t *testing.T, num int) {
-	var numQueued int
-
-	// Wait for up to 10 seconds for the queue to be impacted.
-	timeout := time.Now().Add(10 * time.Second)
-	for {
-		numQueued = len(archiveInProgress)
-		if numQueued == num || time.Now().After(timeout) {
-			break
-		}
+// newTestQueue replaces the archive queue by a channel queue whose workers have to be started
+// with the returned function
+func newTestQueue(t *testing.T) (run func(), shutdown func()) {
+	q, err := queue.NewChannelUniqueQueue(handle, queue.ChannelUniqueQueueConfiguration{
+		WorkerPoolConfiguration: queue.WorkerPoolConfiguration{
+			QueueLength: 10,
+			BatchLength: 1,
+		},
+		Workers: 1,
+		Name:    "temporary-queue",
+	}, archiveTask{})
+	assert.NoError(t, err)
+	archiveQueue = q.(queue.UniqueQueue)
+
+	var queueShutdown, queueTerminate []func()
+	run = func() {
+		archiveQueue.Run(func(_ gocontext.Context, shutdown func()) {
+			queueShutdown = append(queueShutdown, shutdown)
+		}, func(_ gocontext.Context, terminate func()) {
+			queueTerminate = append(queueTerminate, terminate)
+		})
 	}
-
-	assert.Equal(t, num, len(archiveInProgress))
-}
-
-func releaseOneEntry(t *testing.T, inFlight []*ArchiveRequest) {
-	var nowQueued, numQueued int
-
-	numQueued = len(archiveInProgress)
-
-	// Release one, then wait up to 10 seconds for it to complete.
-	queueMutex.Lock()
-	archiveQueueReleaseCond.Signal()
-	queueMutex.Unlock()
-	timeout := time.Now().Add(10 * time.Second)
-	for {
-		nowQueued = len(archiveInProgress)
-		if nowQueued != numQueued || time.Now().After(timeout) {
-			break
+	shutdown = func() {
+		for _, callback := range queueShutdown {
+			callback()
+		}
+		for _, callback := range queueTerminate {
+			callback()
 		}
 	}
+	return run, shutdown
+}
 
-	// Make sure we didn't just timeout.
-	assert.NotEqual(t, numQueued, nowQueued)
-
-	// Also make sure that we released only one.
-	assert.Equal(t, numQueued-1, nowQueued)
+func readArchive(t *testing.T, archivePath string) []byte {
+	obj, err := storage.RepoArchives.Open(archivePath)
+	assert.NoError(t, err)
+	defer obj.Close()
+	content, err := ioutil.ReadAll(obj)
+	assert.NoError(t, err)
+	return content
 }
 
 func TestArchive_Basic(t *testing.T) {
 	assert.NoError(t, models.PrepareTestDatabase())
 
-	archiveQueueMutex = &queueMutex
-	archiveQueueStartCond = sync.NewCond(&queueMutex)
-	archiveQueueReleaseCond = sync.NewCond(&queueMutex)
-	defer func() {
-		archiveQueueMutex = nil
-		archiveQueueStartCond = nil
-		archiveQueueReleaseCond = nil
-	}()
+	// The archives stored by earlier runs would be complete from the start
+	assert.NoError(t, models.DeleteOldRepositoryArchives(gocontext.Background(), -time.Hour, -1))
+
+	run, shutdown := newTestQueue(t)
+	defer shutdown()
 
 	ctx := test.MockContext(t, "user27/repo49")
 	firstCommit, secondCommit := "51f84af23134", "aacbdfe9e1c4"
@@ -110,114 +112,108 @@ func TestArchive_Basic(t *testing.T) {
 	secondReq := DeriveRequestFrom(ctx, secondCommit+".zip")
 	assert.NotNil(t, secondReq)
 
-	inFlight := make([]*ArchiveRequest, 3)
-	inFlight[0] = zipReq
-	inFlight[1] = tgzReq
-	inFlight[2] = secondReq
-
-	ArchiveRepository(zipReq)
-	waitForCount(t, 1)
-	ArchiveRepository(tgzReq)
-	waitForCount(t, 2)
-	ArchiveRepository(secondReq)
-	waitForCount(t, 3)
-
-	// Make sure sending an unprocessed request through doesn't affect the queue
-	// count.
-	ArchiveRepository(zipReq)
-
-	// Sleep two seconds to make sure the queue doesn't change.
-	time.Sleep(2 * time.Second)
-	assert.Equal(t, 3, len(archiveInProgress))
-
-	// Release them all, they'll then stall at the archiveQueueReleaseCond while
-	// we examine the queue state.
-	queueMutex.Lock()
-	archiveQueueStartCond.Broadcast()
-	queueMutex.Unlock()
-
-	// Iterate through all of the in-flight requests and wait for their
-	// completion.
+	inFlight := []*ArchiveRequest{zipReq, tgzReq, secondReq}
 	for _, req := range inFlight {
-		req.WaitForCompletion(ctx)
+		assert.Equal(t, ArchiveQueued, req.Status())
+		assert.Equal(t, req, ArchiveRepository(req))
 	}
+	assert.Len(t, archiveInProgress, 3)
+
+	// The workers of the queue have not been started, so the requests stay queued and
+	// the requests for the same archive are handed the one which is in progress.
+	zipReq2 := DeriveRequestFrom(ctx, firstCommit+".zip")
+	assert.True(t, zipReq == zipReq2)
+	assert.True(t, zipReq == ArchiveRepository(zipReq2))
+	assert.Len(t, archiveInProgress, 3)
+	assert.Equal(t, ArchiveQueued, zipReq.Status())
+
+	completed, timedout := zipReq.TimedWaitForCompletion(ctx, 100*time.Millisecond)
+	assert.False(t, completed)
+	assert.True(t, timedout)
+
+	run()
 
 	for _, req := range inFlight {
-		assert.True(t, req.IsComplete())
-		exist, err := util.IsExist(req.GetArchivePath())
+		completed, timedout = req.TimedWaitForCompletion(ctx, 15*time.Second)
+		assert.True(t, completed)
+		assert.False(t, timedout)
+		assert.Equal(t, ArchiveComplete, req.Status())
+		exist, err := isArchiveStored(req.GetArchivePath())
 		assert.NoError(t, err)
 		assert.True(t, exist)
 	}
+	assert.Len(t, archiveInProgress, 0)
 
-	arbitraryReq := inFlight[0]
-	// Reopen the channel so we don't double-close, mark it incomplete.  We're
-	// going to run it back through the archiver, and it should get marked
-	// complete again.
-	arbitraryReq.cchan = make(chan struct{})
-	arbitraryReq.archiveComplete = false
-	doArchive(arbitraryReq)
-	assert.True(t, arbitraryReq.IsComplete())
-
-	// Queues should not have drained yet, because we haven't released them.
-	// Do so now.
-	assert.Equal(t, 3, len(archiveInProgress))
-
-	zipReq2 := DeriveRequestFrom(ctx, firstCommit+".zip")
-	// This zipReq should match what's sitting in the queue, as we haven't
-	// let it release yet.  From the consumer's point of view, this looks like
-	// a long-running archive task.
-	assert.Equal(t, zipReq, zipReq2)
-
-	// We still have the other three stalled at completion, waiting to remove
-	// from archiveInProgress.  Try to submit this new one before its
-	// predecessor has cleared out of the queue.
-	ArchiveRepository(zipReq2)
-
-	// Make sure the queue hasn't grown any.
-	assert.Equal(t, 3, len(archiveInProgress))
-
-	// Make sure the queue drains properly
-	releaseOneEntry(t, inFlight)
-	assert.Equal(t, 2, len(archiveInProgress))
-	releaseOneEntry(t, inFlight)
-	assert.Equal(t, 1, len(archiveInProgress))
-	releaseOneEntry(t, inFlight)
-	assert.Equal(t, 0, len(archiveInProgress))
-
-	// Now we'll submit a request and TimedWaitForCompletion twice, before and
-	// after we release it.  We should trigger both the timeout and non-timeout
-	// cases.
-	var completed, timedout bool
-	timedReq := DeriveRequestFrom(ctx, secondCommit+".tar.gz")
-	assert.NotNil(t, timedReq)
-	ArchiveRepository(timedReq)
-
-	// Guaranteed to timeout; we haven't signalled the request to start..
-	completed, timedout = timedReq.TimedWaitForCompletion(ctx, 2*time.Second)
-	assert.Equal(t, false, completed)
-	assert.Equal(t, true, timedout)
-
-	queueMutex.Lock()
-	archiveQueueStartCond.Broadcast()
-	queueMutex.Unlock()
-
-	// Shouldn't timeout, we've now signalled it and it's a small request.
-	completed, timedout = timedReq.TimedWaitForCompletion(ctx, 15*time.Second)
-	assert.Equal(t, true, completed)
-	assert.Equal(t, false, timedout)
-
+	// The stored archive is complete from the start and is not enqueued again
 	zipReq2 = DeriveRequestFrom(ctx, firstCommit+".zip")
-	// Now, we're guaranteed to have released the original zipReq from the queue.
-	// Ensure that we don't get handed back the released entry somehow, but they
-	// should remain functionally equivalent in all fields.  The exception here
-	// is zipReq.cchan, which will be non-nil because it's a completed request.
-	// It's fine to go ahead and set it to nil now.
-	zipReq.cchan = nil
-	assert.Equal(t, zipReq, zipReq2)
 	assert.False(t, zipReq == zipReq2)
+	assert.True(t, zipReq2.IsComplete())
+	assert.True(t, zipReq2 == ArchiveRepository(zipReq2))
+	assert.Len(t, archiveInProgress, 0)
+	assert.True(t, zipReq2.WaitForCompletion(ctx))
 
 	// Same commit, different compression formats should have different names.
 	// Ideally, the extension would match what we originally requested.
 	assert.NotEqual(t, zipReq.GetArchiveName(), tgzReq.GetArchiveName())
 	assert.NotEqual(t, zipReq.GetArchiveName(), secondReq.GetArchiveName())
+
+	// An archive which cannot be generated fails
+	failedReq := DeriveRequestFrom(ctx, secondCommit+".tar.gz")
+	assert.NotNil(t, failedReq)
+	failedReq.repoID = 9999
+	ArchiveRepository(failedReq)
+	assert.False(t, failedReq.WaitForCompletion(ctx))
+	assert.Equal(t, ArchiveFailed, failedReq.Status())
+	assert.Len(t, archiveInProgress, 0)
+}
+
+func TestArchiveStatus_String(t *testing.T) {
+	assert.Equal(t, "queued", ArchiveQueued.String())
+	assert.Equal(t, "generating", ArchiveGenerating.String())
+	assert.Equal(t, "complete", ArchiveComplete.String())
+	assert.Equal(t, "failed", ArchiveFailed.String())
+}
+
+func TestArchive_TarZstAndBundle(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	ctx := test.MockContext(t, "user27/repo49")
+	firstCommit := "51f84af23134"
+
+	test.LoadRepo(t, ctx, 49)
+	test.LoadGitRepo(t, ctx)
+	defer ctx.Repo.GitRepo.Close()
+
+	// A bundle needs a ref
+	bogusReq := DeriveRequestFrom(ctx, firstCommit+".bundle")
+	assert.Nil(t, bogusReq)
+	_, err := NewRequest(49, ctx.Repo.GitRepo, firstCommit+".bundle")
+	assert.True(t, IsErrRefNotFound(err))
+	_, err = NewRequest(49, ctx.Repo.GitRepo, "master.dilbert")
+	assert.True(t, IsErrUnknownArchiveFormat(err))
+
+	zstReq := DeriveRequestFrom(ctx, firstCommit+".tar.zst")
+	assert.NotNil(t, zstReq)
+	bundleReq := DeriveRequestFrom(ctx, "master.bundle")
+	assert.NotNil(t, bundleReq)
+	assert.Equal(t, "master.bundle", bundleReq.GetArchiveName())
+	assert.True(t, strings.HasPrefix(zstReq.GetArchivePath(), "49/tarzst/"))
+	assert.True(t, strings.HasPrefix(bundleReq.GetArchivePath(), "49/bundle/"))
+
+	for _, req := range []*ArchiveRequest{zstReq, bundleReq} {
+		assert.NoError(t, doArchive(archiveTask{
+			RepoID:      req.repoID,
+			CommitID:    req.commit.ID.String(),
+			ArchiveType: req.archiveType,
+			BundleRef:   req.bundleRef,
+			ArchivePath: req.archivePath,
+		}))
+	}
+
+	content := readArchive(t, zstReq.GetArchivePath())
+	assert.True(t, bytes.HasPrefix(content, []byte{0x28, 0xb5, 0x2f, 0xfd}), "not a zstd frame")
+
+	content = readArchive(t, bundleReq.GetArchivePath())
+	assert.True(t, bytes.HasPrefix(content, []byte("# v2 git bundle\n")), "not a git bundle")
+	assert.Contains(t, string(content), " refs/heads/master\n")
 }
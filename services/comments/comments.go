@@ -5,12 +5,14 @@
 package comments
 
 import (
+	"context"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/notification"
 )
 
 // CreateIssueComment creates a plain issue comment.
-func CreateIssueComment(doer *models.User, repo *models.Repository, issue *models.Issue, content string, attachments []string) (*models.Comment, error) {
+func CreateIssueComment(ctx context.Context, doer *models.User, repo *models.Repository, issue *models.Issue, content string, attachments []string) (*models.Comment, error) {
 	comment, err := models.CreateComment(&models.CreateCommentOptions{
 		Type:        models.CommentTypeComment,
 		Doer:        doer,
@@ -26,29 +28,29 @@ func CreateIssueComment(doer *models.User, repo *models.Repository, issue *model
 	if err != nil {
 		return nil, err
 	}
-	notification.NotifyCreateIssueComment(doer, repo, issue, comment, mentions)
+	notification.NotifyCreateIssueComment(ctx, doer, repo, issue, comment, mentions)
 
 	return comment, nil
 }
 
 // UpdateComment updates information of comment.
-func UpdateComment(c *models.Comment, doer *models.User, oldContent string) error {
+func UpdateComment(ctx context.Context, c *models.Comment, doer *models.User, oldContent string) error {
 	if err := models.UpdateComment(c, doer); err != nil {
 		return err
 	}
 
-	notification.NotifyUpdateComment(doer, c, oldContent)
+	notification.NotifyUpdateComment(ctx, doer, c, oldContent)
 
 	return nil
 }
 
 // DeleteComment deletes the comment
-func DeleteComment(doer *models.User, comment *models.Comment) error {
+func DeleteComment(ctx context.Context, doer *models.User, comment *models.Comment) error {
 	if err := models.DeleteComment(comment); err != nil {
 		return err
 	}
 
-	notification.NotifyDeleteComment(doer, comment)
+	notification.NotifyDeleteComment(ctx, doer, comment)
 
 	return nil
 }
@@ -77,6 +77,8 @@ type DiffLine struct {
 	Content     string
 	Comments    []*models.Comment
 	SectionInfo *DiffLineSectionInfo
+	// IsCommentedRange is true if the line is part of the range of lines of a multi-line code comment
+	IsCommentedRange bool
 }
 
 // DiffLineSectionInfo represents diff line section meta data
@@ -103,6 +105,19 @@ func (d *DiffLine) CanComment() bool {
 	return len(d.Comments) == 0 && d.Type != DiffLineSection
 }
 
+// markCommentedRange sets IsCommentedRange if the line is in the range of one of the multi-line comments
+func (d *DiffLine) markCommentedRange(comments []*models.Comment) {
+	for _, comment := range comments {
+		if !comment.IsMultiLine() {
+			continue
+		}
+		if comment.IsLineInRange(int64(d.LeftIdx*-1)) || comment.IsLineInRange(int64(d.RightIdx)) {
+			d.IsCommentedRange = true
+			return
+		}
+	}
+}
+
 // GetCommentSide returns the comment side of the first comment, if not set returns empty string
 func (d *DiffLine) GetCommentSide() string {
 	if len(d.Comments) == 0 {
@@ -630,6 +645,38 @@ func (diffFile *DiffFile) GetTailSection(gitRepo *git.Repository, leftCommitID,
 
 }
 
+// GetCodeContextQuery returns the query of the lines of context above (direction "up") or below
+// (direction "down") the sections of the file, like the excerpt of the file of a code comment.
+// It returns "" if there are no lines above the sections.
+func (diffFile *DiffFile) GetCodeContextQuery(direction string) string {
+	var info *DiffLineSectionInfo
+	for _, section := range diffFile.Sections {
+		for _, line := range section.Lines {
+			if line.Type == DiffLineSection && line.SectionInfo != nil && (info == nil || direction == "down") {
+				info = line.SectionInfo
+			}
+		}
+	}
+	if info == nil {
+		return ""
+	}
+
+	switch direction {
+	case "up":
+		if info.RightIdx <= 1 {
+			return ""
+		}
+		return fmt.Sprintf("direction=up&left=%d&right=%d", info.LeftIdx, info.RightIdx)
+	case "down":
+		if info.RightHunkSize <= 0 {
+			// The file has been deleted
+			return ""
+		}
+		return fmt.Sprintf("direction=down&left=%d&right=%d", info.LeftIdx+info.LeftHunkSize-1, info.RightIdx+info.RightHunkSize-1)
+	}
+	return ""
+}
+
 func getCommitFileLineCount(commit *git.Commit, filePath string) int {
 	blob, err := commit.GetBlobByPath(filePath)
 	if err != nil {
@@ -657,8 +704,19 @@ func (diff *Diff) LoadComments(issue *models.Issue, currentUser *models.User) er
 	}
 	for _, file := range diff.Files {
 		if lineCommits, ok := allComments[file.Name]; ok {
+			var multiLineComments []*models.Comment
+			for _, comments := range lineCommits {
+				for _, comment := range comments {
+					if comment.IsMultiLine() {
+						multiLineComments = append(multiLineComments, comment)
+					}
+				}
+			}
 			for _, section := range file.Sections {
 				for _, line := range section.Lines {
+					if line.Type != DiffLineSection {
+						line.markCommentedRange(multiLineComments)
+					}
 					if comments, ok := lineCommits[int64(line.LeftIdx*-1)]; ok {
 						line.Comments = append(line.Comments, comments...)
 					}
@@ -1283,6 +1341,15 @@ func CommentAsDiff(c *models.Comment) (*Diff, error) {
 	if len(secs) == 0 {
 		return nil, fmt.Errorf("no sections found for comment ID: %d", c.ID)
 	}
+	if c.IsMultiLine() {
+		for _, sec := range secs {
+			for _, line := range sec.Lines {
+				if line.Type != DiffLineSection {
+					line.markCommentedRange([]*models.Comment{c})
+				}
+			}
+		}
+	}
 	return diff, nil
 }
 
@@ -513,6 +513,52 @@ func TestDiffLine_GetCommentSide(t *testing.T) {
 	assert.Equal(t, "proposed", (&DiffLine{Comments: []*models.Comment{{Line: 3}}}).GetCommentSide())
 }
 
+func TestDiffLine_markCommentedRange(t *testing.T) {
+	comments := []*models.Comment{
+		{Line: 4},
+		{Line: 6, StartLine: 3},
+		{Line: -2, StartLine: -1},
+	}
+	kases := []struct {
+		line     DiffLine
+		expected bool
+	}{
+		{DiffLine{Type: DiffLineAdd, RightIdx: 3}, true},
+		{DiffLine{Type: DiffLinePlain, LeftIdx: 5, RightIdx: 6}, true},
+		{DiffLine{Type: DiffLineAdd, RightIdx: 7}, false},
+		{DiffLine{Type: DiffLineDel, LeftIdx: 2}, true},
+		{DiffLine{Type: DiffLineDel, LeftIdx: 3}, false},
+		{DiffLine{Type: DiffLinePlain, LeftIdx: 3, RightIdx: 1}, false},
+	}
+	for _, kase := range kases {
+		kase.line.markCommentedRange(comments)
+		assert.Equal(t, kase.expected, kase.line.IsCommentedRange, "left %d, right %d", kase.line.LeftIdx, kase.line.RightIdx)
+	}
+}
+
+func TestDiffFile_GetCodeContextQuery(t *testing.T) {
+	patch := `diff --git a/README.md b/README.md
+--- a/README.md
++++ b/README.md
+@@ -10,4 +12,5 @@ heading
+ context
+-removed
++added
++added
+ context
+`
+	diff, err := ParsePatch(setting.Git.MaxGitDiffLines, setting.Git.MaxGitDiffLineCharacters, setting.Git.MaxGitDiffFiles, strings.NewReader(patch))
+	assert.NoError(t, err)
+	file := diff.Files[0]
+	assert.Equal(t, "direction=up&left=10&right=12", file.GetCodeContextQuery("up"))
+	assert.Equal(t, "direction=down&left=13&right=16", file.GetCodeContextQuery("down"))
+	assert.Equal(t, "", file.GetCodeContextQuery(""))
+
+	// There is nothing above the first line
+	file.Sections[0].Lines[0].SectionInfo.RightIdx = 1
+	assert.Equal(t, "", file.GetCodeContextQuery("up"))
+}
+
 func TestGetDiffRangeWithWhitespaceBehavior(t *testing.T) {
 	git.Debug = true
 	for _, behavior := range []string{"-w", "--ignore-space-at-eol", "-b", ""} {
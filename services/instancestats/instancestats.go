@@ -0,0 +1,120 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package instancestats
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"runtime"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// InstanceID returns the anonymous identifier of the instance, derived from its secret key
+func InstanceID() string {
+	mac := hmac.New(sha256.New, []byte(setting.SecretKey))
+	_, _ = mac.Write([]byte("instance_stats"))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// Collect returns the anonymized statistics of the instance
+func Collect() *api.InstanceStats {
+	stats := models.GetStatistic()
+	return &api.InstanceStats{
+		InstanceID:   InstanceID(),
+		InstanceName: setting.InstanceStats.InstanceName,
+		Version:      setting.AppVer,
+		GoVersion:    runtime.Version(),
+		OS:           runtime.GOOS,
+		Arch:         runtime.GOARCH,
+		Database:     setting.Database.Type,
+		Counts: &api.InstanceCounts{
+			Users:        stats.Counter.User,
+			Orgs:         stats.Counter.Org,
+			Teams:        stats.Counter.Team,
+			Repos:        stats.Counter.Repo,
+			Mirrors:      stats.Counter.Mirror,
+			Issues:       stats.Counter.Issue,
+			Comments:     stats.Counter.Comment,
+			Milestones:   stats.Counter.Milestone,
+			Labels:       stats.Counter.Label,
+			Releases:     stats.Counter.Release,
+			Attachments:  stats.Counter.Attachment,
+			Webhooks:     stats.Counter.Webhook,
+			PublicKeys:   stats.Counter.PublicKey,
+			LoginSources: stats.Counter.LoginSource,
+		},
+		Features: &api.InstanceFeatures{
+			Registration: !setting.Service.DisableRegistration,
+			OpenIDSignIn: setting.Service.EnableOpenIDSignIn,
+			Mailer:       setting.MailService != nil,
+			LFS:          setting.LFS.StartServer,
+			Mirrors:      !setting.Repository.DisableMirrors,
+			Migrations:   !setting.Repository.DisableMigrations,
+			RepoIndexer:  setting.Indexer.RepoIndexerEnabled,
+			TimeTracking: setting.Service.EnableTimetracking,
+			Packages:     setting.Packages.Enabled,
+			Actions:      setting.Actions.Enabled,
+			Artifacts:    setting.Artifacts.Enabled,
+			SCIM:         setting.SCIM.Enabled,
+			VirusScan:    setting.VirusScan.Enabled,
+			Metrics:      setting.Metrics.Enabled,
+			Tracing:      setting.Tracing.Enabled,
+			Swagger:      setting.API.EnableSwagger,
+		},
+		Created: time.Now(),
+	}
+}
+
+// Report posts the anonymized statistics of the instance to the configured endpoint
+func Report(ctx context.Context) error {
+	if !setting.InstanceStats.Enabled {
+		return nil
+	}
+
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	body, err := json.Marshal(Collect())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, setting.InstanceStats.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, setting.InstanceStats.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Gitea/"+setting.AppVer)
+	for name, value := range setting.InstanceStats.HeaderMap {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %d from %s: %s", resp.StatusCode, setting.InstanceStats.Endpoint, bytes.TrimSpace(msg))
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	log.Trace("Reported the statistics of the instance to %s", setting.InstanceStats.Endpoint)
+	return nil
+}
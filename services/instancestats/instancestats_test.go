@@ -0,0 +1,91 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package instancestats
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceID(t *testing.T) {
+	defer func(secretKey string) {
+		setting.SecretKey = secretKey
+	}(setting.SecretKey)
+
+	setting.SecretKey = "first"
+	id := InstanceID()
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, InstanceID())
+
+	setting.SecretKey = "second"
+	assert.NotEqual(t, id, InstanceID())
+}
+
+func TestCollect(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	stats := Collect()
+	assert.Equal(t, InstanceID(), stats.InstanceID)
+	assert.Equal(t, setting.Database.Type, stats.Database)
+	assert.Equal(t, models.CountUsers(), stats.Counts.Users)
+	assert.Equal(t, models.CountRepositories(true), stats.Counts.Repos)
+	assert.Equal(t, !setting.Repository.DisableMigrations, stats.Features.Migrations)
+}
+
+func TestReport(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	var received *api.InstanceStats
+	var authorization string
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		authorization = r.Header.Get("Authorization")
+		received = new(api.InstanceStats)
+		assert.NoError(t, jsoniter.NewDecoder(r.Body).Decode(received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	defer func(enabled bool, endpoint, name string, headers map[string]string, timeout time.Duration) {
+		setting.InstanceStats.Enabled = enabled
+		setting.InstanceStats.Endpoint = endpoint
+		setting.InstanceStats.InstanceName = name
+		setting.InstanceStats.HeaderMap = headers
+		setting.InstanceStats.Timeout = timeout
+	}(setting.InstanceStats.Enabled, setting.InstanceStats.Endpoint, setting.InstanceStats.InstanceName, setting.InstanceStats.HeaderMap, setting.InstanceStats.Timeout)
+	setting.InstanceStats.Endpoint = server.URL
+	setting.InstanceStats.InstanceName = "eu-1"
+	setting.InstanceStats.HeaderMap = map[string]string{"Authorization": "Bearer token"}
+	setting.InstanceStats.Timeout = 10 * time.Second
+
+	// nothing is reported unless it is enabled
+	setting.InstanceStats.Enabled = false
+	assert.NoError(t, Report(context.Background()))
+	assert.Nil(t, received)
+
+	setting.InstanceStats.Enabled = true
+	assert.NoError(t, Report(context.Background()))
+	if assert.NotNil(t, received) {
+		assert.Equal(t, "Bearer token", authorization)
+		assert.Equal(t, InstanceID(), received.InstanceID)
+		assert.Equal(t, "eu-1", received.InstanceName)
+		assert.Equal(t, models.CountUsers(), received.Counts.Users)
+	}
+
+	status = http.StatusUnauthorized
+	assert.Error(t, Report(context.Background()))
+}
@@ -5,13 +5,15 @@
 package issue
 
 import (
+	"context"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
 )
 
 // DeleteNotPassedAssignee deletes all assignees who aren't passed via the "assignees" array
-func DeleteNotPassedAssignee(issue *models.Issue, doer *models.User, assignees []*models.User) (err error) {
+func DeleteNotPassedAssignee(ctx context.Context, issue *models.Issue, doer *models.User, assignees []*models.User) (err error) {
 	var found bool
 
 	for _, assignee := range issue.Assignees {
@@ -26,7 +28,7 @@ func DeleteNotPassedAssignee(issue *models.Issue, doer *models.User, assignees [
 
 		if !found {
 			// This function also does comments and hooks, which is why we call it seperatly instead of directly removing the assignees here
-			if _, _, err := ToggleAssignee(issue, doer, assignee.ID); err != nil {
+			if _, _, err := ToggleAssignee(ctx, issue, doer, assignee.ID); err != nil {
 				return err
 			}
 		}
@@ -36,7 +38,7 @@ func DeleteNotPassedAssignee(issue *models.Issue, doer *models.User, assignees [
 }
 
 // ToggleAssignee changes a user between assigned and not assigned for this issue, and make issue comment for it.
-func ToggleAssignee(issue *models.Issue, doer *models.User, assigneeID int64) (removed bool, comment *models.Comment, err error) {
+func ToggleAssignee(ctx context.Context, issue *models.Issue, doer *models.User, assigneeID int64) (removed bool, comment *models.Comment, err error) {
 	removed, comment, err = issue.ToggleAssignee(doer, assigneeID)
 	if err != nil {
 		return
@@ -48,13 +50,13 @@ func ToggleAssignee(issue *models.Issue, doer *models.User, assigneeID int64) (r
 		return
 	}
 
-	notification.NotifyIssueChangeAssignee(doer, issue, assignee, removed, comment)
+	notification.NotifyIssueChangeAssignee(ctx, doer, issue, assignee, removed, comment)
 
 	return
 }
 
 // ReviewRequest add or remove a review request from a user for this PR, and make comment for it.
-func ReviewRequest(issue *models.Issue, doer *models.User, reviewer *models.User, isAdd bool) (comment *models.Comment, err error) {
+func ReviewRequest(ctx context.Context, issue *models.Issue, doer *models.User, reviewer *models.User, isAdd bool) (comment *models.Comment, err error) {
 	if isAdd {
 		comment, err = models.AddReviewRequest(issue, reviewer, doer)
 	} else {
@@ -66,7 +68,7 @@ func ReviewRequest(issue *models.Issue, doer *models.User, reviewer *models.User
 	}
 
 	if comment != nil {
-		notification.NotifyPullReviewRequest(doer, issue, reviewer, isAdd, comment)
+		notification.NotifyPullReviewRequest(ctx, doer, issue, reviewer, isAdd, comment)
 	}
 
 	return
@@ -226,7 +228,7 @@ func IsValidTeamReviewRequest(reviewer *models.Team, doer *models.User, isAdd bo
 }
 
 // TeamReviewRequest add or remove a review request from a team for this PR, and make comment for it.
-func TeamReviewRequest(issue *models.Issue, doer *models.User, reviewer *models.Team, isAdd bool) (comment *models.Comment, err error) {
+func TeamReviewRequest(ctx context.Context, issue *models.Issue, doer *models.User, reviewer *models.Team, isAdd bool) (comment *models.Comment, err error) {
 	if isAdd {
 		comment, err = models.AddTeamReviewRequest(issue, reviewer, doer)
 	} else {
@@ -255,7 +257,7 @@ func TeamReviewRequest(issue *models.Issue, doer *models.User, reviewer *models.
 			continue
 		}
 		comment.AssigneeID = member.ID
-		notification.NotifyPullReviewRequest(doer, issue, member, isAdd, comment)
+		notification.NotifyPullReviewRequest(ctx, doer, issue, member, isAdd, comment)
 	}
 
 	return
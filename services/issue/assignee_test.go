@@ -5,6 +5,7 @@
 package issue
 
 import (
+	"context"
 	"testing"
 
 	"code.gitea.io/gitea/models"
@@ -27,7 +28,7 @@ func TestDeleteNotPassedAssignee(t *testing.T) {
 	assert.True(t, isAssigned)
 
 	// Clean everyone
-	err = DeleteNotPassedAssignee(issue, user1, []*models.User{})
+	err = DeleteNotPassedAssignee(context.Background(), issue, user1, []*models.User{})
 	assert.NoError(t, err)
 
 	// Check they're gone
@@ -5,19 +5,21 @@
 package issue
 
 import (
+	"context"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/notification"
 )
 
 // ChangeContent changes issue content, as the given user.
-func ChangeContent(issue *models.Issue, doer *models.User, content string) (err error) {
+func ChangeContent(ctx context.Context, issue *models.Issue, doer *models.User, content string) (err error) {
 	oldContent := issue.Content
 
 	if err := issue.ChangeContent(doer, content); err != nil {
 		return err
 	}
 
-	notification.NotifyIssueChangeContent(doer, issue, oldContent)
+	notification.NotifyIssueChangeContent(ctx, doer, issue, oldContent)
 
 	return nil
 }
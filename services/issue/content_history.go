@@ -0,0 +1,61 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// ContentVersion is a version of the content of an issue or of a comment with its differences from the previous one
+type ContentVersion struct {
+	*models.IssueContentHistory
+	// DiffHTML is empty for the content as it was first posted
+	DiffHTML template.HTML
+}
+
+// GetContentVersions returns the versions of the content of an issue, or of one of its comments if commentID is not
+// 0, with their differences from the previous versions, the latest first
+func GetContentVersions(issueID, commentID int64) ([]*ContentVersion, error) {
+	histories, err := models.GetIssueContentHistories(issueID, commentID)
+	if err != nil {
+		return nil, fmt.Errorf("GetIssueContentHistories: %v", err)
+	}
+	versions := make([]*ContentVersion, len(histories))
+	for i, h := range histories {
+		versions[i] = &ContentVersion{IssueContentHistory: h}
+		if i+1 < len(histories) {
+			versions[i].DiffHTML = renderContentDiff(histories[i+1].Content, h.Content)
+		}
+	}
+	return versions, nil
+}
+
+// renderContentDiff returns the HTML of the differences between two versions of a content, the removed text being
+// marked as removed code and the added text as added code
+func renderContentDiff(prev, cur string) template.HTML {
+	dmp := diffmatchpatch.New()
+	diffs := dmp.DiffCleanupSemantic(dmp.DiffMain(prev, cur, true))
+
+	var buf strings.Builder
+	for _, diff := range diffs {
+		text := html.EscapeString(diff.Text)
+		switch diff.Type {
+		case diffmatchpatch.DiffInsert:
+			buf.WriteString(`<span class="added-code">` + text + `</span>`)
+		case diffmatchpatch.DiffDelete:
+			buf.WriteString(`<span class="removed-code">` + text + `</span>`)
+		default:
+			buf.WriteString(text)
+		}
+	}
+	return template.HTML(buf.String())
+}
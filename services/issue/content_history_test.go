@@ -0,0 +1,32 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"html/template"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetContentVersions(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1}).(*models.Issue)
+	assert.NoError(t, issue.ChangeContent(doer, "a <b> c"))
+	assert.NoError(t, issue.ChangeContent(doer, "a <d> c"))
+
+	versions, err := GetContentVersions(issue.ID, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, versions, 3) {
+		assert.Equal(t, template.HTML(`a &lt;<span class="removed-code">b</span><span class="added-code">d</span>&gt; c`), versions[0].DiffHTML)
+		assert.NotEmpty(t, versions[1].DiffHTML)
+		assert.True(t, versions[2].IsFirstCreated)
+		assert.Empty(t, versions[2].DiffHTML)
+	}
+}
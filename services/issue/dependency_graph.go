@@ -0,0 +1,186 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+)
+
+const (
+	// dependencyGraphMaxDepth is how many levels of dependencies are walked on each side of an issue
+	dependencyGraphMaxDepth = 5
+	// dependencyGraphMaxNodes is the maximum number of issues of a dependency graph
+	dependencyGraphMaxNodes = 50
+
+	dependencyGraphNodeWidth  = 200
+	dependencyGraphNodeHeight = 32
+	dependencyGraphGapX       = 40
+	dependencyGraphGapY       = 12
+	dependencyGraphMargin     = 4
+	dependencyGraphLabelLen   = 28
+)
+
+// DependencyGraphNode is an issue of a dependency graph, placed at X and Y
+type DependencyGraphNode struct {
+	Issue *models.Issue
+	Label string
+	X, Y  int
+
+	column, row int
+}
+
+// DependencyGraphEdge is a dependency of a dependency graph: From blocks To. Path is the SVG path of the edge.
+type DependencyGraphEdge struct {
+	From, To *DependencyGraphNode
+	Path     string
+}
+
+// DependencyGraph is the graph of the issues blocking an issue, on its left, and of the issues it blocks, on its right,
+// walking the dependencies of the dependencies. The size is the one of the SVG drawing of the graph.
+type DependencyGraph struct {
+	Root       *DependencyGraphNode
+	Nodes      []*DependencyGraphNode
+	Edges      []*DependencyGraphEdge
+	Width      int
+	Height     int
+	NodeWidth  int
+	NodeHeight int
+	// Truncated is true if some issues were left out of the graph because it has too many issues
+	Truncated bool
+}
+
+// BuildDependencyGraph returns the dependency graph of an issue. The issues canRead returns false for are left out of
+// the graph, as well as the issues they lead to.
+func BuildDependencyGraph(issue *models.Issue, canRead func(*models.Issue) bool) (*DependencyGraph, error) {
+	if err := issue.LoadRepo(); err != nil {
+		return nil, err
+	}
+
+	g := &DependencyGraph{
+		NodeWidth:  dependencyGraphNodeWidth,
+		NodeHeight: dependencyGraphNodeHeight,
+	}
+	g.Root = g.addNode(issue, issue, 0)
+	nodes := map[int64]*DependencyGraphNode{issue.ID: g.Root}
+	edges := make(map[[2]int64]bool)
+
+	for _, blockedBy := range []bool{true, false} {
+		frontier := []int64{issue.ID}
+		for depth := 1; depth <= dependencyGraphMaxDepth && len(frontier) > 0; depth++ {
+			deps, err := models.FindIssueDependencies(frontier, blockedBy)
+			if err != nil {
+				return nil, fmt.Errorf("FindIssueDependencies: %v", err)
+			}
+
+			issueIDs := make([]int64, 0, len(deps))
+			for _, dep := range deps {
+				if id := dependencyOtherID(dep, blockedBy); nodes[id] == nil {
+					issueIDs = append(issueIDs, id)
+				}
+			}
+			found := make(map[int64]*models.Issue, len(issueIDs))
+			if len(issueIDs) > 0 {
+				issues, err := models.GetIssuesByIDs(issueIDs)
+				if err != nil {
+					return nil, fmt.Errorf("GetIssuesByIDs: %v", err)
+				}
+				if _, err := models.IssueList(issues).LoadRepositories(); err != nil {
+					return nil, fmt.Errorf("LoadRepositories: %v", err)
+				}
+				for _, other := range issues {
+					found[other.ID] = other
+				}
+			}
+
+			column := depth
+			if blockedBy {
+				column = -depth
+			}
+			frontier = frontier[:0]
+			for _, dep := range deps {
+				id := dependencyOtherID(dep, blockedBy)
+				if nodes[id] == nil {
+					other := found[id]
+					if other == nil || !canRead(other) {
+						continue
+					}
+					if len(g.Nodes) >= dependencyGraphMaxNodes {
+						g.Truncated = true
+						continue
+					}
+					nodes[id] = g.addNode(issue, other, column)
+					frontier = append(frontier, id)
+				}
+
+				key := [2]int64{dep.DependencyID, dep.IssueID}
+				if !edges[key] {
+					edges[key] = true
+					g.Edges = append(g.Edges, &DependencyGraphEdge{From: nodes[dep.DependencyID], To: nodes[dep.IssueID]})
+				}
+			}
+		}
+	}
+
+	g.layout()
+	return g, nil
+}
+
+// dependencyOtherID returns the issue of a dependency found from the other one
+func dependencyOtherID(dep *models.IssueDependency, blockedBy bool) int64 {
+	if blockedBy {
+		return dep.DependencyID
+	}
+	return dep.IssueID
+}
+
+func (g *DependencyGraph) addNode(root, issue *models.Issue, column int) *DependencyGraphNode {
+	label := fmt.Sprintf("#%d %s", issue.Index, issue.Title)
+	if issue.RepoID != root.RepoID {
+		label = fmt.Sprintf("%s#%d %s", issue.Repo.FullName(), issue.Index, issue.Title)
+	}
+	node := &DependencyGraphNode{
+		Issue:  issue,
+		Label:  base.EllipsisString(label, dependencyGraphLabelLen),
+		column: column,
+	}
+	g.Nodes = append(g.Nodes, node)
+	return node
+}
+
+// layout places the issues in a column by level of dependency, in the order they were found
+func (g *DependencyGraph) layout() {
+	minColumn, maxColumn := 0, 0
+	for _, node := range g.Nodes {
+		if node.column < minColumn {
+			minColumn = node.column
+		}
+		if node.column > maxColumn {
+			maxColumn = node.column
+		}
+	}
+
+	rows := make(map[int]int, maxColumn-minColumn+1)
+	maxRows := 0
+	for _, node := range g.Nodes {
+		node.row = rows[node.column]
+		rows[node.column]++
+		if rows[node.column] > maxRows {
+			maxRows = rows[node.column]
+		}
+		node.X = dependencyGraphMargin + (node.column-minColumn)*(dependencyGraphNodeWidth+dependencyGraphGapX)
+		node.Y = dependencyGraphMargin + node.row*(dependencyGraphNodeHeight+dependencyGraphGapY)
+	}
+	g.Width = 2*dependencyGraphMargin + (maxColumn-minColumn+1)*(dependencyGraphNodeWidth+dependencyGraphGapX) - dependencyGraphGapX
+	g.Height = 2*dependencyGraphMargin + maxRows*(dependencyGraphNodeHeight+dependencyGraphGapY) - dependencyGraphGapY
+
+	for _, edge := range g.Edges {
+		x1, y1 := edge.From.X+dependencyGraphNodeWidth, edge.From.Y+dependencyGraphNodeHeight/2
+		x2, y2 := edge.To.X, edge.To.Y+dependencyGraphNodeHeight/2
+		edge.Path = fmt.Sprintf("M%d %d C%d %d, %d %d, %d %d", x1, y1, x1+dependencyGraphGapX, y1, x2-dependencyGraphGapX, y2, x2, y2)
+	}
+}
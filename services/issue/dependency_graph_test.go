@@ -0,0 +1,58 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDependencyGraph(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	// issues 1 and 5 are issues of repo 1, issues 4 and 7 of repo 2
+	issue1 := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1}).(*models.Issue)
+	issue4 := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 4}).(*models.Issue)
+	issue5 := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 5}).(*models.Issue)
+	issue7 := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 7}).(*models.Issue)
+	// 7 blocks 5, which blocks 1, which blocks 4
+	assert.NoError(t, models.CreateIssueDependency(doer, issue1, issue5))
+	assert.NoError(t, models.CreateIssueDependency(doer, issue5, issue7))
+	assert.NoError(t, models.CreateIssueDependency(doer, issue4, issue1))
+
+	canRead := func(issue *models.Issue) bool {
+		return issue.ID != issue4.ID
+	}
+	g, err := BuildDependencyGraph(issue1, canRead)
+	assert.NoError(t, err)
+	assert.False(t, g.Truncated)
+	if assert.Len(t, g.Nodes, 3) && assert.Len(t, g.Edges, 2) {
+		assert.EqualValues(t, issue1.ID, g.Root.Issue.ID)
+		assert.EqualValues(t, issue5.ID, g.Nodes[1].Issue.ID)
+		assert.EqualValues(t, issue7.ID, g.Nodes[2].Issue.ID)
+
+		// the blocking issues are on the left of the issues they block
+		assert.Less(t, g.Nodes[2].X, g.Nodes[1].X)
+		assert.Less(t, g.Nodes[1].X, g.Root.X)
+		assert.Equal(t, g.Nodes[1], g.Edges[0].From)
+		assert.Equal(t, g.Root, g.Edges[0].To)
+		assert.Equal(t, g.Nodes[2], g.Edges[1].From)
+		assert.Equal(t, g.Nodes[1], g.Edges[1].To)
+
+		// the issues of other repositories are labelled with their repository
+		assert.Contains(t, g.Nodes[2].Label, g.Nodes[2].Issue.Repo.FullName())
+		assert.Equal(t, g.Root.X+g.NodeWidth+dependencyGraphMargin, g.Width)
+	}
+
+	g, err = BuildDependencyGraph(issue1, func(*models.Issue) bool { return true })
+	assert.NoError(t, err)
+	if assert.Len(t, g.Nodes, 4) {
+		assert.Greater(t, g.Nodes[3].X, g.Root.X)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// SendDueDateReminders reminds the assignees of the open issues and milestones due soon of the repositories which
+// enabled the due date reminders, once per due date
+func SendDueDateReminders(ctx context.Context) error {
+	return sendDueDateReminders(ctx, time.Now())
+}
+
+func sendDueDateReminders(ctx context.Context, now time.Time) error {
+	days, err := models.GetDueDateReminderDaysByRepo()
+	if err != nil {
+		return fmt.Errorf("GetDueDateReminderDaysByRepo: %v", err)
+	}
+	for repoID, leadDays := range days {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Aborted due to shutdown")
+		default:
+		}
+
+		if err := sendRepoDueDateReminders(ctx, repoID, now, now.AddDate(0, 0, leadDays)); err != nil {
+			log.Error("sendRepoDueDateReminders [repo_id: %d]: %v", repoID, err)
+		}
+	}
+	return nil
+}
+
+// sendRepoDueDateReminders reminds the assignees of the open issues and milestones of a repository due after a time
+// and until another one
+func sendRepoDueDateReminders(ctx context.Context, repoID int64, now, until time.Time) error {
+	repo, err := models.GetRepositoryByID(repoID)
+	if err != nil {
+		return fmt.Errorf("GetRepositoryByID: %v", err)
+	}
+	if repo.IsArchived || !repo.UnitEnabled(models.UnitTypeIssues) {
+		return nil
+	}
+	after, before := timeutil.TimeStamp(now.Unix()), timeutil.TimeStamp(until.Unix())
+
+	issues, err := models.GetIssuesToRemind(repo.ID, after, before)
+	if err != nil {
+		return fmt.Errorf("GetIssuesToRemind: %v", err)
+	}
+	for _, issue := range issues {
+		issue.Repo = repo
+		assignees, err := readableAssignees(issue)
+		if err != nil {
+			return err
+		}
+		for _, assignee := range assignees {
+			notification.NotifyIssueDueDateReminder(ctx, issue, assignee)
+		}
+		if err := models.SetIssueDueDateReminded(issue); err != nil {
+			return fmt.Errorf("SetIssueDueDateReminded [issue_id: %d]: %v", issue.ID, err)
+		}
+	}
+
+	milestones, err := models.GetMilestonesToRemind(repo.ID, after, before)
+	if err != nil {
+		return fmt.Errorf("GetMilestonesToRemind: %v", err)
+	}
+	for _, milestone := range milestones {
+		milestone.Repo = repo
+		issues, err := models.Issues(&models.IssuesOptions{
+			RepoIDs:      []int64{repo.ID},
+			MilestoneIDs: []int64{milestone.ID},
+			IsClosed:     util.OptionalBoolFalse,
+		})
+		if err != nil {
+			return fmt.Errorf("Issues [milestone_id: %d]: %v", milestone.ID, err)
+		}
+
+		// each assignee is reminded once of all the issues assigned to them
+		assignees := make([]*models.User, 0, 5)
+		assigned := make(map[int64][]*models.Issue, 5)
+		for _, issue := range issues {
+			issue.Repo = repo
+			issueAssignees, err := readableAssignees(issue)
+			if err != nil {
+				return err
+			}
+			for _, assignee := range issueAssignees {
+				if _, ok := assigned[assignee.ID]; !ok {
+					assignees = append(assignees, assignee)
+				}
+				assigned[assignee.ID] = append(assigned[assignee.ID], issue)
+			}
+		}
+		for _, assignee := range assignees {
+			notification.NotifyMilestoneDueDateReminder(ctx, milestone, assigned[assignee.ID], assignee)
+		}
+		if err := models.SetMilestoneDueDateReminded(milestone); err != nil {
+			return fmt.Errorf("SetMilestoneDueDateReminded [milestone_id: %d]: %v", milestone.ID, err)
+		}
+	}
+	return nil
+}
+
+// readableAssignees returns the assignees of an issue who can still sign in and read it
+func readableAssignees(issue *models.Issue) ([]*models.User, error) {
+	if err := issue.LoadAssignees(); err != nil {
+		return nil, fmt.Errorf("LoadAssignees [issue_id: %d]: %v", issue.ID, err)
+	}
+	assignees := make([]*models.User, 0, len(issue.Assignees))
+	for _, assignee := range issue.Assignees {
+		if !assignee.IsActive || assignee.ProhibitLogin {
+			continue
+		}
+		perm, err := models.GetUserRepoPermission(issue.Repo, assignee)
+		if err != nil {
+			return nil, fmt.Errorf("GetUserRepoPermission: %v", err)
+		}
+		if perm.CanReadIssuesOrPulls(issue.IsPull) {
+			assignees = append(assignees, assignee)
+		}
+	}
+	return assignees, nil
+}
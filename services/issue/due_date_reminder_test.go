@@ -0,0 +1,77 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// reminderNotifier records the due date reminders
+type reminderNotifier struct {
+	base.NullNotifier
+	reminders []string
+}
+
+func (n *reminderNotifier) NotifyIssueDueDateReminder(ctx context.Context, issue *models.Issue, assignee *models.User) {
+	n.reminders = append(n.reminders, fmt.Sprintf("%s: issue %d", assignee.Name, issue.ID))
+}
+
+func (n *reminderNotifier) NotifyMilestoneDueDateReminder(ctx context.Context, milestone *models.Milestone, issues []*models.Issue, assignee *models.User) {
+	for _, issue := range issues {
+		n.reminders = append(n.reminders, fmt.Sprintf("%s: milestone %d issue %d", assignee.Name, milestone.ID, issue.ID))
+	}
+}
+
+func TestSendDueDateReminders(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	notifier := &reminderNotifier{}
+	notification.RegisterNotifier(notifier)
+
+	now := time.Now()
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	unit, err := repo.GetUnit(models.UnitTypeIssues)
+	assert.NoError(t, err)
+
+	// issue 1 is assigned to user 1, issue 2 of milestone 1 is assigned to user 1 and to user 9 who is not active
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1}).(*models.Issue)
+	assert.NoError(t, models.UpdateIssueDeadline(issue, timeutil.TimeStamp(now.AddDate(0, 0, 2).Unix()), doer))
+	pull := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 2}).(*models.Issue)
+	_, _, err = pull.ToggleAssignee(doer, 1)
+	assert.NoError(t, err)
+	_, _, err = pull.ToggleAssignee(doer, 9)
+	assert.NoError(t, err)
+	milestone := models.AssertExistsAndLoadBean(t, &models.Milestone{ID: 1}).(*models.Milestone)
+	milestone.DeadlineUnix = timeutil.TimeStamp(now.AddDate(0, 0, 5).Unix())
+	assert.NoError(t, models.UpdateMilestone(milestone, false))
+
+	// the reminders are disabled
+	assert.NoError(t, sendDueDateReminders(context.Background(), now))
+	assert.Empty(t, notifier.reminders)
+
+	unit.IssuesConfig().EnableDueDateReminders = true
+	assert.NoError(t, models.UpdateRepositoryUnits(repo, []models.RepoUnit{*unit}, nil))
+	assert.NoError(t, sendDueDateReminders(context.Background(), now))
+	assert.Equal(t, []string{"user1: issue 1"}, notifier.reminders)
+
+	unit.IssuesConfig().DueDateReminderDays = 7
+	assert.NoError(t, models.UpdateRepositoryUnits(repo, []models.RepoUnit{*unit}, nil))
+	assert.NoError(t, sendDueDateReminders(context.Background(), now))
+	assert.Equal(t, []string{"user1: issue 1", "user1: milestone 1 issue 2"}, notifier.reminders)
+
+	// the assignees are reminded once per due date
+	assert.NoError(t, sendDueDateReminders(context.Background(), now))
+	assert.Len(t, notifier.reminders, 2)
+}
@@ -0,0 +1,192 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"sort"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+const historyWeek = 7 * 24 * time.Hour
+
+// milestoneChange is a change of the numbers of open and closed issues of a milestone
+type milestoneChange struct {
+	time   time.Time
+	open   int64
+	closed int64
+	// velocity is 1 if an issue of the milestone was closed, -1 if it was reopened
+	velocity int64
+}
+
+// GetMilestoneStats aggregates the histories of the issues of a milestone into its burndown and velocity,
+// until now or until the milestone was closed
+func GetMilestoneStats(milestone *models.Milestone) (*models.MilestoneStats, error) {
+	histories, err := models.GetMilestoneIssueHistories(milestone.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	until := time.Now().In(setting.DefaultUILocation)
+	if milestone.IsClosed && milestone.ClosedDateUnix > 0 {
+		until = milestone.ClosedDateUnix.AsTime()
+	}
+	return aggregateMilestoneStats(milestone, histories, until), nil
+}
+
+func aggregateMilestoneStats(milestone *models.Milestone, histories []*models.IssueHistory, until time.Time) *models.MilestoneStats {
+	changes := make([]*milestoneChange, 0, len(histories)*2)
+	for _, history := range histories {
+		changes = append(changes, issueMilestoneChanges(milestone.ID, history)...)
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].time.Before(changes[j].time)
+	})
+
+	// issues can have been added before the milestone was created, e.g. by a migration
+	from := startOfDay(milestone.CreatedUnix.AsTime())
+	if len(changes) > 0 && changes[0].time.Before(from) {
+		from = startOfDay(changes[0].time)
+	}
+	if until.Before(from) {
+		until = from
+	}
+	stats := &models.MilestoneStats{
+		Milestone: milestone,
+		From:      from,
+		Until:     until,
+		Burndown:  make([]*models.IssueHistoryPoint, 0, int(until.Sub(from)/(24*time.Hour))+1),
+		Weekly:    make([]int64, int((until.Sub(from)+historyWeek-1)/historyWeek)),
+	}
+	if len(stats.Weekly) == 0 {
+		stats.Weekly = make([]int64, 1)
+	}
+
+	var open, closed int64
+	next := 0
+	for day := from; !day.After(until); day = day.AddDate(0, 0, 1) {
+		end := day.AddDate(0, 0, 1)
+		for ; next < len(changes) && changes[next].time.Before(end); next++ {
+			change := changes[next]
+			open += change.open
+			closed += change.closed
+			if week := int(change.time.Sub(from) / historyWeek); week >= 0 && week < len(stats.Weekly) {
+				stats.Weekly[week] += change.velocity
+			}
+		}
+		stats.Burndown = append(stats.Burndown, &models.IssueHistoryPoint{
+			Time:   day,
+			Open:   open,
+			Closed: closed,
+		})
+	}
+
+	var total int64
+	for _, n := range stats.Weekly {
+		total += n
+	}
+	weeks := float64(until.Sub(from)) / float64(historyWeek)
+	if weeks < 1 {
+		weeks = 1
+	}
+	stats.Velocity = float64(total) / weeks
+	if !milestone.IsClosed && open > 0 && stats.Velocity > 0 {
+		stats.ProjectedCompletion = until.Add(time.Duration(float64(open) / stats.Velocity * float64(historyWeek)))
+	}
+	return stats
+}
+
+// issueMilestoneChanges replays the history of an issue and returns how it changed the numbers of open and closed
+// issues of a milestone
+func issueMilestoneChanges(milestoneID int64, history *models.IssueHistory) []*milestoneChange {
+	issue := history.Issue
+	comments := history.Comments
+
+	hasStateComment := false
+	for _, comment := range comments {
+		if comment.Type != models.CommentTypeMilestone {
+			hasStateComment = true
+			break
+		}
+	}
+	if !hasStateComment && issue.IsClosed {
+		// the issue was closed without a comment, e.g. by a migration
+		closedUnix := issue.ClosedUnix
+		if closedUnix == 0 {
+			closedUnix = issue.CreatedUnix
+		}
+		comments = append(make([]*models.Comment, 0, len(comments)+1), comments...)
+		comments = append(comments, &models.Comment{Type: models.CommentTypeClose, CreatedUnix: closedUnix})
+		sort.SliceStable(comments, func(i, j int) bool {
+			return comments[i].CreatedUnix < comments[j].CreatedUnix
+		})
+	}
+
+	// the state of the issue before the first comments
+	inMilestone := issue.MilestoneID == milestoneID
+	for _, comment := range comments {
+		if comment.Type == models.CommentTypeMilestone {
+			inMilestone = comment.OldMilestoneID == milestoneID
+			break
+		}
+	}
+	isOpen := true
+	for _, comment := range comments {
+		if comment.Type != models.CommentTypeMilestone {
+			isOpen = comment.Type != models.CommentTypeReopen
+			break
+		}
+	}
+
+	changes := make([]*milestoneChange, 0, len(comments)+1)
+	change := func(ts timeutil.TimeStamp, nowInMilestone, nowOpen bool) {
+		change := &milestoneChange{time: ts.AsTime()}
+		if inMilestone {
+			if isOpen {
+				change.open--
+			} else {
+				change.closed--
+			}
+		}
+		if nowInMilestone {
+			if nowOpen {
+				change.open++
+			} else {
+				change.closed++
+			}
+		}
+		if inMilestone && nowInMilestone && isOpen != nowOpen {
+			change.velocity = change.closed
+		}
+		inMilestone, isOpen = nowInMilestone, nowOpen
+		if change.open != 0 || change.closed != 0 {
+			changes = append(changes, change)
+		}
+	}
+
+	// the issue starts to count once it is created
+	wasInMilestone := inMilestone
+	inMilestone = false
+	change(issue.CreatedUnix, wasInMilestone, isOpen)
+	for _, comment := range comments {
+		switch comment.Type {
+		case models.CommentTypeMilestone:
+			change(comment.CreatedUnix, comment.MilestoneID == milestoneID, isOpen)
+		case models.CommentTypeClose, models.CommentTypeMergePull:
+			change(comment.CreatedUnix, inMilestone, false)
+		case models.CommentTypeReopen:
+			change(comment.CreatedUnix, inMilestone, true)
+		}
+	}
+	return changes
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.In(setting.DefaultUILocation)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, setting.DefaultUILocation)
+}
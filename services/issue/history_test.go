@@ -0,0 +1,88 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateMilestoneStats(t *testing.T) {
+	start := startOfDay(time.Now().AddDate(0, 0, -13)).Add(12 * time.Hour)
+	at := func(days int) timeutil.TimeStamp {
+		return timeutil.TimeStamp(start.AddDate(0, 0, days).Unix())
+	}
+	milestone := &models.Milestone{ID: 1, CreatedUnix: at(0)}
+	histories := []*models.IssueHistory{
+		// created in the milestone, closed on day 2
+		{
+			Issue: &models.Issue{ID: 1, MilestoneID: 1, IsClosed: true, CreatedUnix: at(0)},
+			Comments: []*models.Comment{
+				{Type: models.CommentTypeMilestone, MilestoneID: 1, CreatedUnix: at(0)},
+				{Type: models.CommentTypeClose, CreatedUnix: at(2)},
+			},
+		},
+		// created before the milestone, added on day 1, closed on day 3, reopened on day 8
+		{
+			Issue: &models.Issue{ID: 2, MilestoneID: 1, CreatedUnix: at(-5)},
+			Comments: []*models.Comment{
+				{Type: models.CommentTypeMilestone, MilestoneID: 1, CreatedUnix: at(1)},
+				{Type: models.CommentTypeClose, CreatedUnix: at(3)},
+				{Type: models.CommentTypeReopen, CreatedUnix: at(8)},
+			},
+		},
+		// moved to another milestone on day 4
+		{
+			Issue: &models.Issue{ID: 3, MilestoneID: 2, CreatedUnix: at(0)},
+			Comments: []*models.Comment{
+				{Type: models.CommentTypeMilestone, MilestoneID: 1, CreatedUnix: at(0)},
+				{Type: models.CommentTypeMilestone, OldMilestoneID: 1, MilestoneID: 2, CreatedUnix: at(4)},
+			},
+		},
+		// closed without a comment on day 9
+		{
+			Issue: &models.Issue{ID: 4, MilestoneID: 1, IsClosed: true, CreatedUnix: at(0), ClosedUnix: at(9)},
+		},
+	}
+
+	stats := aggregateMilestoneStats(milestone, histories, start.AddDate(0, 0, 13))
+	assert.Equal(t, startOfDay(start), stats.From)
+	if assert.Len(t, stats.Burndown, 14) {
+		expected := [][2]int64{{3, 0}, {4, 0}, {3, 1}, {2, 2}, {1, 2}, {1, 2}, {1, 2}, {1, 2}, {2, 1}, {1, 2}}
+		for day, counts := range expected {
+			assert.Equal(t, counts[0], stats.Burndown[day].Open, "open issues on day %d", day)
+			assert.Equal(t, counts[1], stats.Burndown[day].Closed, "closed issues on day %d", day)
+		}
+		assert.EqualValues(t, 3, stats.Burndown[13].Total())
+	}
+	assert.Equal(t, []int64{2, 0}, stats.Weekly)
+	assert.InDelta(t, 2.0/(13.5/7), stats.Velocity, 0.01)
+	assert.True(t, stats.ProjectedCompletion.After(stats.Until))
+
+	milestone.IsClosed = true
+	stats = aggregateMilestoneStats(milestone, histories, start.AddDate(0, 0, 13))
+	assert.True(t, stats.ProjectedCompletion.IsZero())
+
+	stats = aggregateMilestoneStats(milestone, nil, start)
+	assert.Len(t, stats.Burndown, 1)
+	assert.Equal(t, []int64{0}, stats.Weekly)
+	assert.Zero(t, stats.Velocity)
+}
+
+func TestGetMilestoneStats(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	milestone := models.AssertExistsAndLoadBean(t, &models.Milestone{ID: 1}).(*models.Milestone)
+	stats, err := GetMilestoneStats(milestone)
+	assert.NoError(t, err)
+	last := stats.Burndown[len(stats.Burndown)-1]
+	assert.EqualValues(t, 1, last.Open)
+	assert.EqualValues(t, 0, last.Closed)
+}
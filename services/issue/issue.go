@@ -5,6 +5,8 @@
 package issue
 
 import (
+	"context"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/notification"
@@ -12,13 +14,13 @@ import (
 )
 
 // NewIssue creates new issue with labels for repository.
-func NewIssue(repo *models.Repository, issue *models.Issue, labelIDs []int64, uuids []string, assigneeIDs []int64) error {
+func NewIssue(ctx context.Context, repo *models.Repository, issue *models.Issue, labelIDs []int64, uuids []string, assigneeIDs []int64) error {
 	if err := models.NewIssue(repo, issue, labelIDs, uuids); err != nil {
 		return err
 	}
 
 	for _, assigneeID := range assigneeIDs {
-		if err := AddAssigneeIfNotAssigned(issue, issue.Poster, assigneeID); err != nil {
+		if err := AddAssigneeIfNotAssigned(ctx, issue, issue.Poster, assigneeID); err != nil {
 			return err
 		}
 	}
@@ -28,19 +30,19 @@ func NewIssue(repo *models.Repository, issue *models.Issue, labelIDs []int64, uu
 		return err
 	}
 
-	notification.NotifyNewIssue(issue, mentions)
+	notification.NotifyNewIssue(ctx, issue, mentions)
 	if len(issue.Labels) > 0 {
-		notification.NotifyIssueChangeLabels(issue.Poster, issue, issue.Labels, nil)
+		notification.NotifyIssueChangeLabels(ctx, issue.Poster, issue, issue.Labels, nil)
 	}
 	if issue.Milestone != nil {
-		notification.NotifyIssueChangeMilestone(issue.Poster, issue, 0)
+		notification.NotifyIssueChangeMilestone(ctx, issue.Poster, issue, 0)
 	}
 
 	return nil
 }
 
 // ChangeTitle changes the title of this issue, as the given user.
-func ChangeTitle(issue *models.Issue, doer *models.User, title string) (err error) {
+func ChangeTitle(ctx context.Context, issue *models.Issue, doer *models.User, title string) (err error) {
 	oldTitle := issue.Title
 	issue.Title = title
 
@@ -48,13 +50,13 @@ func ChangeTitle(issue *models.Issue, doer *models.User, title string) (err erro
 		return
 	}
 
-	notification.NotifyIssueChangeTitle(doer, issue, oldTitle)
+	notification.NotifyIssueChangeTitle(ctx, doer, issue, oldTitle)
 
 	return nil
 }
 
 // ChangeIssueRef changes the branch of this issue, as the given user.
-func ChangeIssueRef(issue *models.Issue, doer *models.User, ref string) error {
+func ChangeIssueRef(ctx context.Context, issue *models.Issue, doer *models.User, ref string) error {
 	oldRef := issue.Ref
 	issue.Ref = ref
 
@@ -62,7 +64,7 @@ func ChangeIssueRef(issue *models.Issue, doer *models.User, ref string) error {
 		return err
 	}
 
-	notification.NotifyIssueChangeRef(doer, issue, oldRef)
+	notification.NotifyIssueChangeRef(ctx, doer, issue, oldRef)
 
 	return nil
 }
@@ -73,7 +75,7 @@ func ChangeIssueRef(issue *models.Issue, doer *models.User, ref string) error {
 // "assignees" (array): Logins for Users to assign to this issue.
 // Pass one or more user logins to replace the set of assignees on this Issue.
 // Send an empty array ([]) to clear all assignees from the Issue.
-func UpdateAssignees(issue *models.Issue, oneAssignee string, multipleAssignees []string, doer *models.User) (err error) {
+func UpdateAssignees(ctx context.Context, issue *models.Issue, oneAssignee string, multipleAssignees []string, doer *models.User) (err error) {
 	var allNewAssignees []*models.User
 
 	// Keep the old assignee thingy for compatibility reasons
@@ -103,7 +105,7 @@ func UpdateAssignees(issue *models.Issue, oneAssignee string, multipleAssignees
 	}
 
 	// Delete all old assignees not passed
-	if err = DeleteNotPassedAssignee(issue, doer, allNewAssignees); err != nil {
+	if err = DeleteNotPassedAssignee(ctx, issue, doer, allNewAssignees); err != nil {
 		return err
 	}
 
@@ -113,7 +115,7 @@ func UpdateAssignees(issue *models.Issue, oneAssignee string, multipleAssignees
 	// has access to the repo.
 	for _, assignee := range allNewAssignees {
 		// Extra method to prevent double adding (which would result in removing)
-		err = AddAssigneeIfNotAssigned(issue, doer, assignee.ID)
+		err = AddAssigneeIfNotAssigned(ctx, issue, doer, assignee.ID)
 		if err != nil {
 			return err
 		}
@@ -124,7 +126,7 @@ func UpdateAssignees(issue *models.Issue, oneAssignee string, multipleAssignees
 
 // AddAssigneeIfNotAssigned adds an assignee only if he isn't already assigned to the issue.
 // Also checks for access of assigned user
-func AddAssigneeIfNotAssigned(issue *models.Issue, doer *models.User, assigneeID int64) (err error) {
+func AddAssigneeIfNotAssigned(ctx context.Context, issue *models.Issue, doer *models.User, assigneeID int64) (err error) {
 	assignee, err := models.GetUserByID(assigneeID)
 	if err != nil {
 		return err
@@ -148,7 +150,7 @@ func AddAssigneeIfNotAssigned(issue *models.Issue, doer *models.User, assigneeID
 		return models.ErrUserDoesNotHaveAccessToRepo{UserID: assigneeID, RepoName: issue.Repo.Name}
 	}
 
-	_, _, err = ToggleAssignee(issue, doer, assigneeID)
+	_, _, err = ToggleAssignee(ctx, issue, doer, assigneeID)
 	if err != nil {
 		return err
 	}
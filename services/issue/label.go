@@ -5,43 +5,45 @@
 package issue
 
 import (
+	"context"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/notification"
 )
 
 // ClearLabels clears all of an issue's labels
-func ClearLabels(issue *models.Issue, doer *models.User) (err error) {
+func ClearLabels(ctx context.Context, issue *models.Issue, doer *models.User) (err error) {
 	if err = issue.ClearLabels(doer); err != nil {
 		return
 	}
 
-	notification.NotifyIssueClearLabels(doer, issue)
+	notification.NotifyIssueClearLabels(ctx, doer, issue)
 
 	return nil
 }
 
 // AddLabel adds a new label to the issue.
-func AddLabel(issue *models.Issue, doer *models.User, label *models.Label) error {
+func AddLabel(ctx context.Context, issue *models.Issue, doer *models.User, label *models.Label) error {
 	if err := models.NewIssueLabel(issue, label, doer); err != nil {
 		return err
 	}
 
-	notification.NotifyIssueChangeLabels(doer, issue, []*models.Label{label}, nil)
+	notification.NotifyIssueChangeLabels(ctx, doer, issue, []*models.Label{label}, nil)
 	return nil
 }
 
 // AddLabels adds a list of new labels to the issue.
-func AddLabels(issue *models.Issue, doer *models.User, labels []*models.Label) error {
+func AddLabels(ctx context.Context, issue *models.Issue, doer *models.User, labels []*models.Label) error {
 	if err := models.NewIssueLabels(issue, labels, doer); err != nil {
 		return err
 	}
 
-	notification.NotifyIssueChangeLabels(doer, issue, labels, nil)
+	notification.NotifyIssueChangeLabels(ctx, doer, issue, labels, nil)
 	return nil
 }
 
 // RemoveLabel removes a label from issue by given ID.
-func RemoveLabel(issue *models.Issue, doer *models.User, label *models.Label) error {
+func RemoveLabel(ctx context.Context, issue *models.Issue, doer *models.User, label *models.Label) error {
 	if err := issue.LoadRepo(); err != nil {
 		return err
 	}
@@ -61,12 +63,12 @@ func RemoveLabel(issue *models.Issue, doer *models.User, label *models.Label) er
 		return err
 	}
 
-	notification.NotifyIssueChangeLabels(doer, issue, nil, []*models.Label{label})
+	notification.NotifyIssueChangeLabels(ctx, doer, issue, nil, []*models.Label{label})
 	return nil
 }
 
 // ReplaceLabels removes all current labels and add new labels to the issue.
-func ReplaceLabels(issue *models.Issue, doer *models.User, labels []*models.Label) error {
+func ReplaceLabels(ctx context.Context, issue *models.Issue, doer *models.User, labels []*models.Label) error {
 	old, err := models.GetLabelsByIssueID(issue.ID)
 	if err != nil {
 		return err
@@ -76,6 +78,6 @@ func ReplaceLabels(issue *models.Issue, doer *models.User, labels []*models.Labe
 		return err
 	}
 
-	notification.NotifyIssueChangeLabels(doer, issue, labels, old)
+	notification.NotifyIssueChangeLabels(ctx, doer, issue, labels, old)
 	return nil
 }
@@ -5,6 +5,7 @@
 package issue
 
 import (
+	"context"
 	"testing"
 
 	"code.gitea.io/gitea/models"
@@ -30,7 +31,7 @@ func TestIssue_AddLabels(t *testing.T) {
 			labels[i] = models.AssertExistsAndLoadBean(t, &models.Label{ID: labelID}).(*models.Label)
 		}
 		doer := models.AssertExistsAndLoadBean(t, &models.User{ID: test.doerID}).(*models.User)
-		assert.NoError(t, AddLabels(issue, doer, labels))
+		assert.NoError(t, AddLabels(context.Background(), issue, doer, labels))
 		for _, labelID := range test.labelIDs {
 			models.AssertExistsAndLoadBean(t, &models.IssueLabel{IssueID: test.issueID, LabelID: labelID})
 		}
@@ -53,7 +54,7 @@ func TestIssue_AddLabel(t *testing.T) {
 		issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: test.issueID}).(*models.Issue)
 		label := models.AssertExistsAndLoadBean(t, &models.Label{ID: test.labelID}).(*models.Label)
 		doer := models.AssertExistsAndLoadBean(t, &models.User{ID: test.doerID}).(*models.User)
-		assert.NoError(t, AddLabel(issue, doer, label))
+		assert.NoError(t, AddLabel(context.Background(), issue, doer, label))
 		models.AssertExistsAndLoadBean(t, &models.IssueLabel{IssueID: test.issueID, LabelID: test.labelID})
 	}
 }
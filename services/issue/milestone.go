@@ -5,17 +5,19 @@
 package issue
 
 import (
+	"context"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/notification"
 )
 
 // ChangeMilestoneAssign changes assignment of milestone for issue.
-func ChangeMilestoneAssign(issue *models.Issue, doer *models.User, oldMilestoneID int64) (err error) {
+func ChangeMilestoneAssign(ctx context.Context, issue *models.Issue, doer *models.User, oldMilestoneID int64) (err error) {
 	if err = models.ChangeMilestoneAssign(issue, doer, oldMilestoneID); err != nil {
 		return
 	}
 
-	notification.NotifyIssueChangeMilestone(doer, issue, oldMilestoneID)
+	notification.NotifyIssueChangeMilestone(ctx, doer, issue, oldMilestoneID)
 
 	return nil
 }
@@ -0,0 +1,90 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// recurringIssueDateLayout is the layout of the date replacing {date} in the title and the content of the issues
+const recurringIssueDateLayout = "2006-01-02"
+
+// expandRecurringIssueText replaces {date} by the date an issue is created
+func expandRecurringIssueText(text string, now time.Time) string {
+	return strings.ReplaceAll(text, "{date}", now.Format(recurringIssueDateLayout))
+}
+
+// CreateDueRecurringIssues creates the issues of the recurring issues which are due and schedules their next issues
+func CreateDueRecurringIssues(ctx context.Context) error {
+	return createDueRecurringIssues(ctx, time.Now())
+}
+
+func createDueRecurringIssues(ctx context.Context, now time.Time) error {
+	rs, err := models.GetDueRecurringIssues(timeutil.TimeStamp(now.Unix()))
+	if err != nil {
+		return err
+	}
+	for _, r := range rs {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Aborted due to shutdown")
+		default:
+		}
+
+		issueID, err := createRecurringIssue(ctx, r, now)
+		if err != nil {
+			log.Error("createRecurringIssue [id: %d, repo_id: %d]: %v", r.ID, r.RepoID, err)
+		}
+		// a failed issue is not retried before the next run, for an issue not to be created every minute
+		if err := models.FinishRecurringIssueRun(r, issueID, now); err != nil {
+			return fmt.Errorf("FinishRecurringIssueRun [id: %d]: %v", r.ID, err)
+		}
+	}
+	return nil
+}
+
+// createRecurringIssue creates the issue of a recurring issue and returns its ID, 0 if the issue is skipped. A recurring
+// issue whose poster can no longer create it is deactivated.
+func createRecurringIssue(ctx context.Context, r *models.RecurringIssue, now time.Time) (int64, error) {
+	repo, err := models.GetRepositoryByID(r.RepoID)
+	if err != nil {
+		return 0, fmt.Errorf("GetRepositoryByID: %v", err)
+	}
+	if repo.IsArchived || !repo.UnitEnabled(models.UnitTypeIssues) {
+		return 0, nil
+	}
+
+	if err := r.LoadDoer(); err != nil {
+		return 0, fmt.Errorf("LoadDoer: %v", err)
+	}
+	perm, err := models.GetUserRepoPermission(repo, r.Doer)
+	if err != nil {
+		return 0, fmt.Errorf("GetUserRepoPermission: %v", err)
+	}
+	if r.Doer.IsGhost() || !r.Doer.IsActive || r.Doer.ProhibitLogin || !perm.CanWrite(models.UnitTypeIssues) {
+		log.Warn("Recurring issue %d of repository %d is deactivated, as %s can no longer create its issues", r.ID, repo.ID, r.Doer.Name)
+		return 0, models.DeactivateRecurringIssue(r)
+	}
+
+	issue := &models.Issue{
+		RepoID:   repo.ID,
+		Repo:     repo,
+		Title:    expandRecurringIssueText(r.Title, now),
+		Content:  expandRecurringIssueText(r.Content, now),
+		PosterID: r.Doer.ID,
+		Poster:   r.Doer,
+	}
+	if err := NewIssue(ctx, repo, issue, r.LabelIDs(), nil, nil); err != nil {
+		return 0, fmt.Errorf("NewIssue: %v", err)
+	}
+	return issue.ID, nil
+}
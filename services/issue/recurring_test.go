@@ -0,0 +1,45 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateDueRecurringIssues(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	// user 2 owns repo 1, user 4 can only read it
+	r := &models.RecurringIssue{RepoID: 1, DoerID: 2, Title: "Checklist {date}", Content: "Due {date}", Labels: "1", Schedule: "@daily", IsActive: true}
+	assert.NoError(t, models.NewRecurringIssue(r))
+	denied := &models.RecurringIssue{RepoID: 1, DoerID: 4, Title: "Denied", Schedule: "@daily", IsActive: true}
+	assert.NoError(t, models.NewRecurringIssue(denied))
+
+	// nothing is due yet
+	assert.NoError(t, createDueRecurringIssues(context.Background(), time.Now()))
+	r = models.AssertExistsAndLoadBean(t, &models.RecurringIssue{ID: r.ID}).(*models.RecurringIssue)
+	assert.EqualValues(t, 0, r.LastIssueID)
+
+	now := r.NextRunUnix.AsTime().Add(time.Minute)
+	assert.NoError(t, createDueRecurringIssues(context.Background(), now))
+	r = models.AssertExistsAndLoadBean(t, &models.RecurringIssue{ID: r.ID}).(*models.RecurringIssue)
+	date := now.Format("2006-01-02")
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: r.LastIssueID, RepoID: 1, PosterID: 2}).(*models.Issue)
+	assert.Equal(t, "Checklist "+date, issue.Title)
+	assert.Equal(t, "Due "+date, issue.Content)
+	models.AssertExistsAndLoadBean(t, &models.IssueLabel{IssueID: issue.ID, LabelID: 1})
+	assert.True(t, r.NextRunUnix.AsTime().After(now))
+
+	// the recurring issue of a user who cannot write the issues is deactivated
+	denied = models.AssertExistsAndLoadBean(t, &models.RecurringIssue{ID: denied.ID}).(*models.RecurringIssue)
+	assert.False(t, denied.IsActive)
+	assert.EqualValues(t, 0, denied.LastIssueID)
+}
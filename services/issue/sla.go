@@ -0,0 +1,85 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// CheckIssueSLAs flags the issues which breached a target of the SLA of their repository since the last check
+func CheckIssueSLAs(ctx context.Context) error {
+	return checkIssueSLAs(ctx, time.Now())
+}
+
+func checkIssueSLAs(ctx context.Context, now time.Time) error {
+	slas, err := models.GetAllIssueSLAs()
+	if err != nil {
+		return fmt.Errorf("GetAllIssueSLAs: %v", err)
+	}
+	for _, sla := range slas {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Aborted due to shutdown")
+		default:
+		}
+
+		if err := checkIssueSLA(ctx, sla, now); err != nil {
+			log.Error("checkIssueSLA [id: %d, repo_id: %d]: %v", sla.ID, sla.RepoID, err)
+		}
+	}
+	return nil
+}
+
+// checkIssueSLA records the new breaches of the targets of an SLA and adds its breach label to the breaching issues,
+// as long as the user who last saved the SLA can still write the issues
+func checkIssueSLA(ctx context.Context, sla *models.IssueSLA, now time.Time) error {
+	repo, err := models.GetRepositoryByID(sla.RepoID)
+	if err != nil {
+		return fmt.Errorf("GetRepositoryByID: %v", err)
+	}
+	if repo.IsArchived || !repo.UnitEnabled(models.UnitTypeIssues) {
+		return nil
+	}
+	if err := sla.LoadAttributes(); err != nil {
+		return fmt.Errorf("LoadAttributes: %v", err)
+	}
+
+	breachLabel := sla.BreachLabel
+	if breachLabel != nil {
+		perm, err := models.GetUserRepoPermission(repo, sla.Doer)
+		if err != nil {
+			return fmt.Errorf("GetUserRepoPermission: %v", err)
+		}
+		if sla.Doer.IsGhost() || !sla.Doer.IsActive || sla.Doer.ProhibitLogin || !perm.CanWrite(models.UnitTypeIssues) {
+			log.Warn("The breaches of SLA %d of repository %d are not labelled, as %s can no longer write its issues", sla.ID, repo.ID, sla.Doer.Name)
+			breachLabel = nil
+		}
+	}
+
+	for _, target := range []string{models.IssueSLATargetFirstResponse, models.IssueSLATargetResolution} {
+		issues, err := sla.FindNewIssueSLABreaches(target, now)
+		if err != nil {
+			return fmt.Errorf("FindNewIssueSLABreaches: %v", err)
+		}
+		for _, issue := range issues {
+			if err := sla.NewIssueSLABreach(issue, target); err != nil {
+				return fmt.Errorf("NewIssueSLABreach [issue_id: %d]: %v", issue.ID, err)
+			}
+			if breachLabel == nil || models.HasIssueLabel(issue.ID, breachLabel.ID) {
+				continue
+			}
+			issue.Repo = repo
+			if err := AddLabel(ctx, issue, sla.Doer, breachLabel); err != nil {
+				return fmt.Errorf("AddLabel [issue_id: %d]: %v", issue.ID, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckIssueSLAs(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	sla := &models.IssueSLA{RepoID: 1, DoerID: 2, Name: "Bugs", LabelID: 1, FirstResponseHours: 1, ResolutionHours: 24, BreachLabelID: 2}
+	assert.NoError(t, models.NewIssueSLA(sla))
+
+	issue := &models.Issue{RepoID: 1, PosterID: 2, Title: "Crash on start", Content: "It crashes"}
+	assert.NoError(t, NewIssue(context.Background(), repo, issue, []int64{1}, nil, nil))
+
+	// the targets are not breached yet
+	assert.NoError(t, checkIssueSLAs(context.Background(), time.Now()))
+	models.AssertNotExistsBean(t, &models.IssueSLABreach{IssueID: issue.ID})
+	assert.False(t, models.HasIssueLabel(issue.ID, 2))
+
+	later := time.Now().Add(2 * time.Hour)
+	assert.NoError(t, checkIssueSLAs(context.Background(), later))
+	models.AssertExistsAndLoadBean(t, &models.IssueSLABreach{SLAID: sla.ID, IssueID: issue.ID, Target: models.IssueSLATargetFirstResponse})
+	models.AssertNotExistsBean(t, &models.IssueSLABreach{IssueID: issue.ID, Target: models.IssueSLATargetResolution})
+	assert.True(t, models.HasIssueLabel(issue.ID, 2))
+
+	// the breaches are recorded once, even after the breach label was removed
+	assert.NoError(t, models.DeleteIssueLabel(issue, models.AssertExistsAndLoadBean(t, &models.Label{ID: 2}).(*models.Label),
+		models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)))
+	assert.NoError(t, checkIssueSLAs(context.Background(), later))
+	assert.False(t, models.HasIssueLabel(issue.ID, 2))
+
+	assert.NoError(t, checkIssueSLAs(context.Background(), later.Add(24*time.Hour)))
+	models.AssertExistsAndLoadBean(t, &models.IssueSLABreach{SLAID: sla.ID, IssueID: issue.ID, Target: models.IssueSLATargetResolution})
+	assert.True(t, models.HasIssueLabel(issue.ID, 2))
+}
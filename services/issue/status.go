@@ -5,17 +5,19 @@
 package issue
 
 import (
+	"context"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/notification"
 )
 
 // ChangeStatus changes issue status to open or closed.
-func ChangeStatus(issue *models.Issue, doer *models.User, isClosed bool) (err error) {
+func ChangeStatus(ctx context.Context, issue *models.Issue, doer *models.User, isClosed bool) (err error) {
 	comment, err := issue.ChangeStatus(doer, isClosed)
 	if err != nil {
 		return
 	}
 
-	notification.NotifyIssueChangeStatus(doer, issue, comment, isClosed)
+	notification.NotifyIssueChangeStatus(ctx, doer, issue, comment, isClosed)
 	return nil
 }
@@ -0,0 +1,36 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"code.gitea.io/gitea/models"
+)
+
+// WriteTrackedTimesCSV writes tracked times with their issues and users loaded as CSV, one time per line after a header
+func WriteTrackedTimesCSV(w io.Writer, times models.TrackedTimeList) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"date", "user", "repository", "issue", "title", "seconds", "time"}); err != nil {
+		return err
+	}
+	for _, t := range times {
+		if err := writer.Write([]string{
+			t.Created.Format("2006-01-02 15:04:05"),
+			t.User.Name,
+			t.Issue.Repo.FullName(),
+			strconv.FormatInt(t.Issue.Index, 10),
+			t.Issue.Title,
+			strconv.FormatInt(t.Time, 10),
+			models.SecToTime(t.Time),
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,32 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteTrackedTimesCSV(t *testing.T) {
+	repo := &models.Repository{OwnerName: "user2", Name: "repo1"}
+	times := models.TrackedTimeList{
+		{
+			Created: time.Date(2021, 3, 17, 15, 4, 5, 0, time.UTC),
+			User:    &models.User{Name: "user2"},
+			Issue:   &models.Issue{Index: 1, Title: "a title, with a comma", Repo: repo},
+			Time:    3661,
+		},
+	}
+
+	var b strings.Builder
+	assert.NoError(t, WriteTrackedTimesCSV(&b, times))
+	assert.Equal(t, "date,user,repository,issue,title,seconds,time\n"+
+		"2021-03-17 15:04:05,user2,user2/repo1,1,\"a title, with a comma\",3661,1h 1min 1s\n", b.String())
+}
@@ -20,9 +20,11 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/markup/mdtext"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 
+	"github.com/jaytaylor/html2text"
 	"gopkg.in/gomail.v2"
 )
 
@@ -32,12 +34,25 @@ const (
 	mailAuthResetPassword  base.TplName = "auth/reset_passwd"
 	mailAuthRegisterNotify base.TplName = "auth/register_notify"
 
-	mailNotifyCollaborator base.TplName = "notify/collaborator"
+	mailNotifyCollaborator      base.TplName = "notify/collaborator"
+	mailNotifyIssueCollaborator base.TplName = "notify/issue_collaborator"
 
 	mailRepoTransferNotify base.TplName = "notify/repo_transfer"
 
+	mailStorageConsistency base.TplName = "notify/storage_consistency"
+
+	mailMirrorFailure base.TplName = "notify/mirror_failure"
+
+	mailCommitStatusFailure base.TplName = "notify/commit_status_failure"
+
+	mailDueDateReminder base.TplName = "notify/due_date_reminder"
+
 	// There's no actual limit for subject in RFC 5322
 	mailMaxSubjectRunes = 256
+
+	// mailBodyPlaceholder stands for the body of the issue or comment while the plain text version of a mail is
+	// converted from its HTML, the body being converted from its markdown instead
+	mailBodyPlaceholder = "GiteaMailBodyPlaceholder"
 )
 
 var (
@@ -166,6 +181,31 @@ func SendCollaboratorMail(u, doer *models.User, repo *models.Repository) {
 	SendAsync(msg)
 }
 
+// SendIssueCollaboratorMail sends mail notification to a user invited to a single issue.
+func SendIssueCollaboratorMail(u, doer *models.User, issue *models.Issue) {
+	repoName := issue.Repo.FullName()
+	subject := fmt.Sprintf("%s invited you to %s#%d", doer.DisplayName(), repoName, issue.Index)
+
+	data := map[string]interface{}{
+		"Subject":  subject,
+		"RepoName": repoName,
+		"Issue":    issue,
+		"Link":     issue.HTMLURL(),
+	}
+
+	var content bytes.Buffer
+
+	if err := bodyTemplates.ExecuteTemplate(&content, string(mailNotifyIssueCollaborator), data); err != nil {
+		log.Error("Template: %v", err)
+		return
+	}
+
+	msg := NewMessage([]string{u.Email}, subject, content.String())
+	msg.Info = fmt.Sprintf("UID: %d, add issue collaborator", u.ID)
+
+	SendAsync(msg)
+}
+
 func composeIssueCommentMessages(ctx *mailCommentContext, tos []string, fromMention bool, info string) []*Message {
 
 	var (
@@ -224,6 +264,7 @@ func composeIssueCommentMessages(ctx *mailCommentContext, tos []string, fromMent
 		"ActionType":      actType,
 		"ActionName":      actName,
 		"ReviewComments":  reviewComments,
+		"ShowAvatars":     setting.Privacy.MailAvatars,
 	}
 
 	var mailSubject bytes.Buffer
@@ -247,10 +288,13 @@ func composeIssueCommentMessages(ctx *mailCommentContext, tos []string, fromMent
 		log.Error("ExecuteTemplate [%s]: %v", string(tplName)+"/body", err)
 	}
 
+	plainMailBody := renderPlainMailBody(tplName, mailMeta, ctx.Content, ctx.Issue.Repo.HTMLURL())
+
 	// Make sure to compose independent messages to avoid leaking user emails
 	msgs := make([]*Message, 0, len(tos))
 	for _, to := range tos {
 		msg := NewMessageFrom([]string{to}, ctx.Doer.DisplayName(), setting.MailService.FromEmail, subject, mailBody.String())
+		msg.PlainBody = plainMailBody
 		msg.Info = fmt.Sprintf("Subject: %s, %s", subject, info)
 
 		// Set Message-ID on first message so replies know what to reference
@@ -266,6 +310,37 @@ func composeIssueCommentMessages(ctx *mailCommentContext, tos []string, fromMent
 	return msgs
 }
 
+// renderPlainMailBody renders the plain text version of an issue or comment mail: the parts of the template are
+// converted from HTML and the body of the issue or comment from markdown, as rendering it to HTML and back would lose
+// its formatting. It returns an empty string, for the whole mail to be converted from HTML, if the template does not
+// show the body.
+func renderPlainMailBody(tplName string, mailMeta map[string]interface{}, content, urlPrefix string) string {
+	if mailMeta["Body"] == "" {
+		return ""
+	}
+
+	plainMeta := make(map[string]interface{}, len(mailMeta))
+	for k, v := range mailMeta {
+		plainMeta[k] = v
+	}
+	plainMeta["Body"] = mailBodyPlaceholder
+	var mailBody bytes.Buffer
+	if err := bodyTemplates.ExecuteTemplate(&mailBody, tplName, plainMeta); err != nil {
+		log.Error("ExecuteTemplate [%s]: %v", tplName+"/body", err)
+		return ""
+	}
+
+	plainBody, err := html2text.FromString(mailBody.String())
+	if err != nil {
+		log.Error("html2text.FromString: %v", err)
+		return ""
+	}
+	if strings.Count(plainBody, mailBodyPlaceholder) != 1 {
+		return ""
+	}
+	return strings.Replace(plainBody, mailBodyPlaceholder, emoji.ReplaceAliases(mdtext.RenderText([]byte(content), urlPrefix)), 1)
+}
+
 func sanitizeSubject(subject string) string {
 	runes := []rune(strings.TrimSpace(subjectRemoveSpaces.ReplaceAllLiteralString(subject, " ")))
 	if len(runes) > mailMaxSubjectRunes {
@@ -0,0 +1,68 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// MailCommitStatusFailure notifies the author of a commit that a status of the commit is failing, if the author is a
+// user who can read the repository and wants to be notified of CI failures
+func MailCommitStatusFailure(repo *models.Repository, commit *git.Commit, status *models.CommitStatus) error {
+	if setting.MailService == nil || commit.Author == nil {
+		return nil
+	}
+
+	author, err := models.GetUserByEmail(commit.Author.Email)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if !author.IsMailable() || author.EmailNotifications() != models.EmailNotificationsEnabled {
+		return nil
+	}
+	if enabled, err := author.IsEmailEventEnabled(models.EmailEventCIFailure); err != nil || !enabled {
+		return err
+	}
+	perm, err := models.GetUserRepoPermission(repo, author)
+	if err != nil {
+		return err
+	}
+	if !perm.CanRead(models.UnitTypeCode) {
+		return nil
+	}
+
+	sha := commit.ID.String()
+	subject := fmt.Sprintf("[%s] %s: %s failed for %s", repo.FullName(), status.Context, status.State, sha[:10])
+	data := map[string]interface{}{
+		"Subject":     subject,
+		"Repo":        repo.FullName(),
+		"SHA":         sha,
+		"Summary":     commit.Summary(),
+		"Context":     status.Context,
+		"State":       status.State,
+		"Description": status.Description,
+		"TargetURL":   status.TargetURL,
+		"Link":        repo.HTMLURL() + "/commit/" + sha,
+	}
+
+	var content bytes.Buffer
+	if err := bodyTemplates.ExecuteTemplate(&content, string(mailCommitStatusFailure), data); err != nil {
+		return err
+	}
+
+	msg := NewMessage([]string{author.Email}, subject, content.String())
+	msg.Info = fmt.Sprintf("RepoID: %d, commit status failure of %s", repo.ID, sha)
+
+	SendAsync(msg)
+	return nil
+}
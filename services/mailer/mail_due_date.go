@@ -0,0 +1,57 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// MailDueDateReminder reminds an assignee of the due date of an issue, or of a milestone with the open issues of the
+// milestone assigned to them, if the assignee wants to be reminded of due dates
+func MailDueDateReminder(assignee *models.User, repo *models.Repository, milestone *models.Milestone, issues []*models.Issue) error {
+	if setting.MailService == nil || len(issues) == 0 {
+		return nil
+	}
+	if !assignee.IsMailable() || assignee.EmailNotifications() != models.EmailNotificationsEnabled {
+		return nil
+	}
+	if enabled, err := assignee.IsEmailEventEnabled(models.EmailEventDueDate); err != nil || !enabled {
+		return err
+	}
+
+	var subject, link string
+	deadline := issues[0].DeadlineUnix
+	if milestone != nil {
+		deadline = milestone.DeadlineUnix
+		subject = fmt.Sprintf("[%s] Milestone %s is due on %s", repo.FullName(), milestone.Name, deadline.FormatInLocation("2006-01-02", setting.DefaultUILocation))
+		link = fmt.Sprintf("%s/milestone/%d", repo.HTMLURL(), milestone.ID)
+	} else {
+		subject = fmt.Sprintf("[%s] %s (#%d) is due on %s", repo.FullName(), issues[0].Title, issues[0].Index, deadline.FormatInLocation("2006-01-02", setting.DefaultUILocation))
+		link = issues[0].HTMLURL()
+	}
+	data := map[string]interface{}{
+		"Subject":   subject,
+		"Repo":      repo.FullName(),
+		"Milestone": milestone,
+		"Issues":    issues,
+		"Deadline":  deadline.FormatInLocation("2006-01-02", setting.DefaultUILocation),
+		"Link":      link,
+	}
+
+	var content bytes.Buffer
+	if err := bodyTemplates.ExecuteTemplate(&content, string(mailDueDateReminder), data); err != nil {
+		return err
+	}
+
+	msg := NewMessage([]string{assignee.Email}, subject, content.String())
+	msg.Info = fmt.Sprintf("RepoID: %d, due date reminder of %s", repo.ID, link)
+
+	SendAsync(msg)
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// SendMirrorFailureMail notifies the administrators of a repository that one of its mirrors, described by mirror,
+// repeatedly failed to sync
+func SendMirrorFailureMail(repo *models.Repository, mirror string, failures int, lastError string, nextRetry timeutil.TimeStamp) error {
+	if setting.MailService == nil {
+		return nil
+	}
+
+	admins, err := models.GetRepoAdmins(repo)
+	if err != nil {
+		return err
+	}
+	emails := make([]string, 0, len(admins))
+	for _, admin := range admins {
+		if admin.EmailNotifications() != models.EmailNotificationsDisabled {
+			emails = append(emails, admin.Email)
+		}
+	}
+	if len(emails) == 0 {
+		return nil
+	}
+
+	subject := fmt.Sprintf("[%s] The %s failed to sync %d times", repo.FullName(), mirror, failures)
+	data := map[string]interface{}{
+		"Subject":   subject,
+		"Repo":      repo.FullName(),
+		"Mirror":    mirror,
+		"Failures":  failures,
+		"LastError": lastError,
+		"Link":      repo.HTMLURL() + "/settings",
+	}
+	if nextRetry > 0 {
+		data["NextRetry"] = nextRetry.FormatLong()
+	}
+
+	var content bytes.Buffer
+	if err := bodyTemplates.ExecuteTemplate(&content, string(mailMirrorFailure), data); err != nil {
+		return err
+	}
+
+	msg := NewMessage(emails, subject, content.String())
+	msg.Info = fmt.Sprintf("RepoID: %d, mirror failure", repo.ID)
+
+	SendAsync(msg)
+	return nil
+}
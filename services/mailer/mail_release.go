@@ -27,9 +27,9 @@ func MailNewRelease(rel *models.Release) {
 		return
 	}
 
-	recipients, err := models.GetMaileableUsersByIDs(watcherIDList, false)
+	recipients, err := models.GetMaileableUsersByIDsForEvent(watcherIDList, models.EmailEventRelease)
 	if err != nil {
-		log.Error("models.GetMaileableUsersByIDs: %v", err)
+		log.Error("models.GetMaileableUsersByIDsForEvent: %v", err)
 		return
 	}
 
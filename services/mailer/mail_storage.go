@@ -0,0 +1,54 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// maxReportedStoragePaths limits the number of paths of every storage listed in the report
+const maxReportedStoragePaths = 20
+
+// SendStorageConsistencyMail sends the summary of a storage consistency check to the site administrators
+func SendStorageConsistencyMail(reports []*models.StorageConsistencyReport) error {
+	if setting.MailService == nil {
+		return nil
+	}
+
+	admins, err := models.GetActiveAdmins()
+	if err != nil {
+		return err
+	}
+	if len(admins) == 0 {
+		return nil
+	}
+	emails := make([]string, 0, len(admins))
+	for _, admin := range admins {
+		emails = append(emails, admin.Email)
+	}
+
+	subject := fmt.Sprintf("[%s] Storage consistency report", setting.AppName)
+	data := map[string]interface{}{
+		"Subject":  subject,
+		"Reports":  reports,
+		"MaxPaths": maxReportedStoragePaths,
+		"Link":     setting.AppURL + "admin/monitor",
+	}
+
+	var content bytes.Buffer
+	if err := bodyTemplates.ExecuteTemplate(&content, string(mailStorageConsistency), data); err != nil {
+		return err
+	}
+
+	msg := NewMessage(emails, subject, content.String())
+	msg.Info = "storage consistency report"
+
+	SendAsync(msg)
+	return nil
+}
@@ -108,6 +108,41 @@ func TestComposeIssueMessage(t *testing.T) {
 	assert.Equal(t, messageID[0], "<user2/repo1/issues/1@localhost>", "Message-ID header doesn't match")
 }
 
+func TestComposeIssueMessagePlainBody(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	var mailService = setting.Mailer{
+		From: "test@gitea.com",
+	}
+
+	setting.MailService = &mailService
+	setting.Domain = "localhost"
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1, Owner: doer}).(*models.Repository)
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, Repo: repo, Poster: doer}).(*models.Issue)
+
+	stpl := texttmpl.Must(texttmpl.New("issue/new").Parse(subjectTpl))
+	btpl := template.Must(template.New("issue/new").Parse(bodyTpl))
+	InitMailRender(stpl, btpl)
+
+	msgs := composeIssueCommentMessages(&mailCommentContext{Issue: issue, Doer: doer, ActionType: models.ActionCreateIssue,
+		Content: "Some **bold** [link](https://gitea.io) :smile:\n\n- one\n- two"}, []string{"test@gitea.com"}, false, "issue create")
+	if assert.Len(t, msgs, 1) {
+		// the body is converted from its markdown, the rest of the mail from its HTML
+		assert.Contains(t, msgs[0].PlainBody, "Some bold link <https://gitea.io> \U0001f604\n\n- one\n- two")
+		assert.Contains(t, msgs[0].PlainBody, "View it on Gitea")
+	}
+
+	// the whole mail is converted from its HTML if the template does not show the body
+	btpl = template.Must(template.New("issue/new").Parse(`<p><a href="{{.Link}}">View it on Gitea</a>.</p>`))
+	InitMailRender(stpl, btpl)
+	msgs = composeIssueCommentMessages(&mailCommentContext{Issue: issue, Doer: doer, ActionType: models.ActionCreateIssue,
+		Content: "Some **bold** text"}, []string{"test@gitea.com"}, false, "issue create")
+	if assert.Len(t, msgs, 1) {
+		assert.Empty(t, msgs[0].PlainBody)
+	}
+}
+
 func TestTemplateSelection(t *testing.T) {
 	assert.NoError(t, models.PrepareTestDatabase())
 	var mailService = setting.Mailer{
@@ -38,7 +38,9 @@ type Message struct {
 	Subject         string
 	Date            time.Time
 	Body            string
-	Headers         map[string][]string
+	// PlainBody is the plain text alternative of the body, converted from its HTML if empty
+	PlainBody string
+	Headers   map[string][]string
 }
 
 // ToMessage converts a Message to gomail.Message
@@ -58,9 +60,13 @@ func (m *Message) ToMessage() *gomail.Message {
 	msg.SetDateHeader("Date", m.Date)
 	msg.SetHeader("X-Auto-Response-Suppress", "All")
 
-	plainBody, err := html2text.FromString(m.Body)
+	plainBody := m.PlainBody
+	var err error
+	if plainBody == "" {
+		plainBody, err = html2text.FromString(m.Body)
+	}
 	if err != nil || setting.MailService.SendAsPlainText {
-		if strings.Contains(base.TruncateString(m.Body, 100), "<html>") {
+		if m.PlainBody == "" && strings.Contains(base.TruncateString(m.Body, 100), "<html>") {
 			log.Warn("Mail contains HTML but configured to send as plain text.")
 		}
 		msg.SetBody("text/plain", plainBody)
@@ -320,7 +326,19 @@ func NewContext() {
 	}
 
 	mailQueue = queue.CreateQueue("mail", func(data ...queue.Data) {
-		for _, datum := range data {
+		for i, datum := range data {
+			select {
+			case <-graceful.GetManager().IsShutdown():
+				// the messages left are pushed back to the queue, which persists them to be sent after the restart
+				for _, msg := range data[i:] {
+					if err := mailQueue.Push(msg); err != nil {
+						log.Error("Unable to queue again the email %s: %v", msg.(*Message).Info, err)
+					}
+				}
+				return
+			default:
+			}
+
 			msg := datum.(*Message)
 			gomailMsg := msg.ToMessage()
 			log.Trace("New e-mail sending request %s: %s", gomailMsg.GetHeader("To"), msg.Info)
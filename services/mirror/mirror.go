@@ -211,17 +211,40 @@ func runSync(m *models.Mirror) ([]*mirrorSyncResult, bool) {
 	wikiPath := m.Repo.WikiPath()
 	timeout := time.Duration(setting.Git.Timeout.Mirror) * time.Second
 
+	refspecs, err := mirrorRefspecs(m.IncludeRefs, m.ExcludeRefs)
+	if err != nil {
+		log.Error("Failed to update mirror repository %v: %v", m.Repo, err)
+		desc := fmt.Sprintf("Failed to update mirror repository '%s': %v", repoPath, err)
+		m.LastError = desc
+		if err = models.CreateRepositoryNotice(desc); err != nil {
+			log.Error("CreateRepositoryNotice: %v", err)
+		}
+		return nil, false
+	}
+
 	log.Trace("SyncMirrors [repo: %-v]: running git remote update...", m.Repo)
 	gitArgs := []string{"remote", "update"}
 	if m.EnablePrune {
 		gitArgs = append(gitArgs, "--prune")
 	}
+	if refspecs != nil {
+		// Only the filtered refs are fetched, the tags pointing to their commits are not followed
+		gitArgs = []string{"fetch", "--no-tags"}
+		if m.EnablePrune {
+			gitArgs = append(gitArgs, "--prune")
+		}
+		gitArgs = append(append(gitArgs, "origin"), refspecs...)
+	}
 
 	stdoutBuilder := strings.Builder{}
 	stderrBuilder := strings.Builder{}
-	if err := git.NewCommand(gitArgs...).
+	err = git.NewCommand(gitArgs...).
 		SetDescription(fmt.Sprintf("Mirror.runSync: %s", m.Repo.FullName())).
-		RunInDirTimeoutPipeline(timeout, repoPath, &stdoutBuilder, &stderrBuilder); err != nil {
+		RunInDirTimeoutPipeline(timeout, repoPath, &stdoutBuilder, &stderrBuilder)
+	// even a failed update may have changed some refs
+	git.InvalidateRefsCache(repoPath)
+	git.InvalidatePackCache(repoPath)
+	if err != nil {
 		stdout := stdoutBuilder.String()
 		stderr := stderrBuilder.String()
 		// sanitize the output, since it may contain the remote address, which may
@@ -241,6 +264,7 @@ func runSync(m *models.Mirror) ([]*mirrorSyncResult, bool) {
 
 		log.Error("Failed to update mirror repository %v:\nStdout: %s\nStderr: %s\nErr: %v", m.Repo, stdoutMessage, stderrMessage, err)
 		desc := fmt.Sprintf("Failed to update mirror repository '%s': %s", repoPath, stderrMessage)
+		m.LastError = desc
 		if err = models.CreateRepositoryNotice(desc); err != nil {
 			log.Error("CreateRepositoryNotice: %v", err)
 		}
@@ -270,9 +294,12 @@ func runSync(m *models.Mirror) ([]*mirrorSyncResult, bool) {
 		log.Trace("SyncMirrors [repo: %-v Wiki]: running git remote update...", m.Repo)
 		stderrBuilder.Reset()
 		stdoutBuilder.Reset()
-		if err := git.NewCommand("remote", "update", "--prune").
+		err := git.NewCommand("remote", "update", "--prune").
 			SetDescription(fmt.Sprintf("Mirror.runSync Wiki: %s ", m.Repo.FullName())).
-			RunInDirTimeoutPipeline(timeout, wikiPath, &stdoutBuilder, &stderrBuilder); err != nil {
+			RunInDirTimeoutPipeline(timeout, wikiPath, &stdoutBuilder, &stderrBuilder)
+		git.InvalidateRefsCache(wikiPath)
+		git.InvalidatePackCache(wikiPath)
+		if err != nil {
 			stdout := stdoutBuilder.String()
 			stderr := stderrBuilder.String()
 			// sanitize the output, since it may contain the remote address, which may
@@ -292,6 +319,7 @@ func runSync(m *models.Mirror) ([]*mirrorSyncResult, bool) {
 
 			log.Error("Failed to update mirror repository wiki %v:\nStdout: %s\nStderr: %s\nErr: %v", m.Repo, stdoutMessage, stderrMessage, err)
 			desc := fmt.Sprintf("Failed to update mirror repository wiki '%s': %s", wikiPath, stderrMessage)
+			m.LastError = desc
 			if err = models.CreateRepositoryNotice(desc); err != nil {
 				log.Error("CreateRepositoryNotice: %v", err)
 			}
@@ -364,6 +392,23 @@ func Update(ctx context.Context) error {
 		log.Trace("Update: %v", err)
 		return err
 	}
+	if err := models.PushMirrorsIterate(func(idx int, bean interface{}) error {
+		m := bean.(*models.PushMirror)
+		if m.Repo == nil {
+			log.Error("Disconnected push mirror found: %d", m.ID)
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Aborted")
+		default:
+			mirrorQueue.Add(pushMirrorPrefix + strconv.FormatInt(m.ID, 10))
+			return nil
+		}
+	}); err != nil {
+		log.Trace("Update: %v", err)
+		return err
+	}
 	log.Trace("Finished: Update")
 	return nil
 }
@@ -377,13 +422,17 @@ func SyncMirrors(ctx context.Context) {
 		case <-ctx.Done():
 			mirrorQueue.Close()
 			return
-		case repoID := <-mirrorQueue.Queue():
-			syncMirror(repoID)
+		case id := <-mirrorQueue.Queue():
+			if strings.HasPrefix(id, pushMirrorPrefix) {
+				syncPushMirror(id)
+			} else {
+				syncMirror(ctx, id)
+			}
 		}
 	}
 }
 
-func syncMirror(repoID string) {
+func syncMirror(ctx context.Context, repoID string) {
 	log.Trace("SyncMirrors [repo_id: %v]", repoID)
 	defer func() {
 		err := recover()
@@ -403,12 +452,15 @@ func syncMirror(repoID string) {
 	}
 
 	log.Trace("SyncMirrors [repo: %-v]: Running Sync", m.Repo)
+	m.LastError = ""
 	results, ok := runSync(m)
 	if !ok {
+		recordMirrorFailure(m)
 		return
 	}
 
 	log.Trace("SyncMirrors [repo: %-v]: Scheduling next update", m.Repo)
+	m.RecordSuccess()
 	m.ScheduleNextUpdate()
 	if err = models.UpdateMirror(m); err != nil {
 		log.Error("UpdateMirror [%s]: %v", repoID, err)
@@ -452,18 +504,18 @@ func syncMirror(repoID string) {
 				log.Error("gitRepo.GetRefCommitID [repo_id: %s, ref_name: %s]: %v", m.RepoID, result.refName, err)
 				continue
 			}
-			notification.NotifySyncPushCommits(m.Repo.MustOwner(), m.Repo, &repo_module.PushUpdateOptions{
+			notification.NotifySyncPushCommits(ctx, m.Repo.MustOwner(), m.Repo, &repo_module.PushUpdateOptions{
 				RefFullName: result.refName,
 				OldCommitID: git.EmptySHA,
 				NewCommitID: commitID,
 			}, repo_module.NewPushCommits())
-			notification.NotifySyncCreateRef(m.Repo.MustOwner(), m.Repo, tp, result.refName)
+			notification.NotifySyncCreateRef(ctx, m.Repo.MustOwner(), m.Repo, tp, result.refName)
 			continue
 		}
 
 		// Delete reference
 		if result.newCommitID == gitShortEmptySha {
-			notification.NotifySyncDeleteRef(m.Repo.MustOwner(), m.Repo, tp, result.refName)
+			notification.NotifySyncDeleteRef(ctx, m.Repo.MustOwner(), m.Repo, tp, result.refName)
 			continue
 		}
 
@@ -491,7 +543,7 @@ func syncMirror(repoID string) {
 
 		theCommits.CompareURL = m.Repo.ComposeCompareURL(oldCommitID, newCommitID)
 
-		notification.NotifySyncPushCommits(m.Repo.MustOwner(), m.Repo, &repo_module.PushUpdateOptions{
+		notification.NotifySyncPushCommits(ctx, m.Repo.MustOwner(), m.Repo, &repo_module.PushUpdateOptions{
 			RefFullName: result.refName,
 			OldCommitID: oldCommitID,
 			NewCommitID: newCommitID,
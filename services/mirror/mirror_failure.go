@@ -0,0 +1,54 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mirror
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/mailer"
+)
+
+// unknownMirrorError is recorded when a mirror failed to sync for a reason which is only logged
+const unknownMirrorError = "The mirror failed to sync, the error is in the log of the server."
+
+// recordMirrorFailure records a failed sync of a pull mirror, whose error is its LastError, schedules its retry and
+// notifies the administrators of its repository when it failed too many times in a row
+func recordMirrorFailure(m *models.Mirror) {
+	if m.LastError == "" {
+		m.LastError = unknownMirrorError
+	}
+	m.RecordFailure(m.LastError)
+	if err := models.UpdateMirror(m); err != nil {
+		log.Error("UpdateMirror [%d]: %v", m.RepoID, err)
+		return
+	}
+
+	// the administrators are only notified once while the mirror keeps failing
+	if m.ConsecutiveFailures == setting.Mirror.FailureThreshold {
+		if err := mailer.SendMirrorFailureMail(m.Repo, "pull mirror", m.ConsecutiveFailures, m.LastError, m.NextUpdateUnix); err != nil {
+			log.Error("SendMirrorFailureMail [%d]: %v", m.RepoID, err)
+		}
+	}
+}
+
+// recordPushMirrorFailure records a failed push to a push mirror, schedules its retry and notifies the
+// administrators of its repository when it failed too many times in a row
+func recordPushMirrorFailure(m *models.PushMirror, errMessage string) {
+	m.RecordFailure(errMessage)
+	if err := models.UpdatePushMirror(m); err != nil {
+		log.Error("UpdatePushMirror [%d]: %v", m.ID, err)
+		return
+	}
+
+	if m.ConsecutiveFailures == setting.Mirror.FailureThreshold {
+		mirror := fmt.Sprintf("push mirror to %s", PushMirrorAddress(m))
+		if err := mailer.SendMirrorFailureMail(m.Repo, mirror, m.ConsecutiveFailures, m.LastError, m.NextUpdateUnix); err != nil {
+			log.Error("SendMirrorFailureMail [%d]: %v", m.ID, err)
+		}
+	}
+}
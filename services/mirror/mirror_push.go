@@ -0,0 +1,141 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mirror
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// pushMirrorPrefix prefixes the push mirrors in the mirror queue, which otherwise holds the IDs of the pull mirrors
+const pushMirrorPrefix = "push-"
+
+// AddPushMirror inserts a push mirror to the address and adds its remote to the repository
+func AddPushMirror(m *models.PushMirror, addr string) error {
+	m.ScheduleNextUpdate()
+	if err := models.InsertPushMirror(m); err != nil {
+		return err
+	}
+	if _, err := git.NewCommand("remote", "add", m.RemoteName(), addr).RunInDir(m.Repo.RepoPath()); err != nil {
+		if err := models.DeletePushMirror(m.RepoID, m.ID); err != nil {
+			log.Error("DeletePushMirror: %v", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// RemovePushMirror deletes a push mirror and removes its remote from the repository
+func RemovePushMirror(m *models.PushMirror) error {
+	_, err := git.NewCommand("remote", "rm", m.RemoteName()).RunInDir(m.Repo.RepoPath())
+	if err != nil && !strings.HasPrefix(err.Error(), "exit status 128 - fatal: No such remote") &&
+		!strings.HasPrefix(err.Error(), "exit status 2 - error: No such remote") {
+		return err
+	}
+	return models.DeletePushMirror(m.RepoID, m.ID)
+}
+
+// PushMirrorAddress returns the address of a push mirror without its credentials
+func PushMirrorAddress(m *models.PushMirror) string {
+	addr, err := pushMirrorRemoteAddress(m)
+	if err != nil {
+		log.Error("pushMirrorRemoteAddress: %v", err)
+	}
+	return util.SanitizeURLCredentials(addr, false)
+}
+
+func pushMirrorRemoteAddress(m *models.PushMirror) (string, error) {
+	addr, err := git.NewCommand("config", "--get", "remote."+m.RemoteName()+".url").RunInDir(m.Repo.RepoPath())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(addr), nil
+}
+
+// runPushSync pushes the refs of the repository to a push mirror
+func runPushSync(m *models.PushMirror) error {
+	repoPath := m.Repo.RepoPath()
+	timeout := time.Duration(setting.Git.Timeout.Mirror) * time.Second
+
+	refspecs, err := mirrorRefspecs(m.IncludeRefs, m.ExcludeRefs)
+	if err != nil {
+		return err
+	}
+	gitArgs := []string{"push", "--mirror", m.RemoteName()}
+	if refspecs != nil {
+		gitArgs = append([]string{"push", "--force", "--prune", m.RemoteName()}, refspecs...)
+	}
+
+	log.Trace("SyncPushMirrors [repo: %-v, mirror: %d]: running git push...", m.Repo, m.ID)
+	stderrBuilder := strings.Builder{}
+	if err := git.NewCommand(gitArgs...).
+		SetDescription(fmt.Sprintf("Mirror.runPushSync: %s", m.Repo.FullName())).
+		RunInDirTimeoutPipeline(timeout, repoPath, nil, &stderrBuilder); err != nil {
+		// the output may contain the remote address, which may contain a password
+		addr, addrErr := pushMirrorRemoteAddress(m)
+		if addrErr != nil {
+			log.Error("pushMirrorRemoteAddress: %v", addrErr)
+			return fmt.Errorf("git push: %v", err)
+		}
+		return fmt.Errorf("git push: %v - %s", util.SanitizeMessage(err.Error(), addr), util.SanitizeMessage(stderrBuilder.String(), addr))
+	}
+	return nil
+}
+
+func syncPushMirror(id string) {
+	log.Trace("SyncPushMirrors [mirror_id: %v]", id)
+	defer func() {
+		err := recover()
+		if err == nil {
+			return
+		}
+		// There was a panic whilst syncPushMirror...
+		log.Error("PANIC whilst syncPushMirror[%s] Panic: %v\nStacktrace: %s", id, err, log.Stack(2))
+	}()
+	mirrorQueue.Remove(id)
+
+	mirrorID, _ := strconv.ParseInt(strings.TrimPrefix(id, pushMirrorPrefix), 10, 64)
+	m, err := models.GetPushMirrorByID(mirrorID)
+	if err != nil {
+		log.Error("GetPushMirrorByID [%d]: %v", mirrorID, err)
+		return
+	}
+	if m.Repo == nil {
+		log.Error("Disconnected push mirror found: %d", m.ID)
+		return
+	}
+
+	m.LastUpdateUnix = timeutil.TimeStampNow()
+	if err := runPushSync(m); err != nil {
+		log.Error("Failed to push mirror %d of repository %v: %v", m.ID, m.Repo, err)
+		desc := fmt.Sprintf("Failed to push repository '%s' to its mirror %d: %s", m.Repo.FullName(), m.ID, err.Error())
+		if err = models.CreateRepositoryNotice(desc); err != nil {
+			log.Error("CreateRepositoryNotice: %v", err)
+		}
+		recordPushMirrorFailure(m, err.Error())
+		return
+	}
+
+	log.Trace("SyncPushMirrors [repo: %-v, mirror: %d]: Scheduling next update", m.Repo, m.ID)
+	m.RecordSuccess()
+	m.ScheduleNextUpdate()
+	if err = models.UpdatePushMirror(m); err != nil {
+		log.Error("UpdatePushMirror [%d]: %v", m.ID, err)
+	}
+}
+
+// StartToPushMirror adds a push mirror to the mirror queue
+func StartToPushMirror(mirrorID int64) {
+	go mirrorQueue.Add(pushMirrorPrefix + strconv.FormatInt(mirrorID, 10))
+}
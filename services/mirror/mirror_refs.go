@@ -0,0 +1,56 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mirror
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/validation"
+)
+
+// ValidateRefPatterns checks that patterns can be used as refspec patterns to filter the refs of a mirror.
+// A pattern is the full name of a ref, which may contain one *, like refs/tags/v* or refs/heads/release/*.
+func ValidateRefPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		name := strings.Replace(pattern, "*", "x", 1)
+		if !strings.HasPrefix(pattern, "refs/") ||
+			validation.GitRefNamePatternInvalid.MatchString(name) ||
+			!validation.CheckGitRefAdditionalRulesValid(name) {
+			return fmt.Errorf("invalid ref pattern %q, it must be a ref name starting with refs/ which contains at most one *", pattern)
+		}
+	}
+	return nil
+}
+
+// mirrorRefspecs returns the refspecs which restrict a mirror to the refs matching include and not matching exclude,
+// nil if it mirrors all the refs.
+func mirrorRefspecs(include, exclude []string) ([]string, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+	if len(exclude) > 0 {
+		if err := git.LoadGitVersion(); err != nil {
+			return nil, err
+		}
+		// negative refspecs are supported since git 2.29
+		if err := git.CheckGitVersionAtLeast("2.29"); err != nil {
+			return nil, fmt.Errorf("excluding refs from a mirror: %v", err)
+		}
+	}
+	if len(include) == 0 {
+		include = []string{"refs/*"}
+	}
+
+	refspecs := make([]string, 0, len(include)+len(exclude))
+	for _, pattern := range include {
+		refspecs = append(refspecs, "+"+pattern+":"+pattern)
+	}
+	for _, pattern := range exclude {
+		refspecs = append(refspecs, "^"+pattern)
+	}
+	return refspecs, nil
+}
@@ -7,7 +7,9 @@ package mirror
 import (
 	"context"
 	"path/filepath"
+	"strconv"
 	"testing"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
@@ -59,7 +61,7 @@ func TestRelease_MirrorDelete(t *testing.T) {
 	initCount, err := models.GetReleaseCountByRepoID(mirror.ID, findOptions)
 	assert.NoError(t, err)
 
-	assert.NoError(t, release_service.CreateRelease(gitRepo, &models.Release{
+	assert.NoError(t, release_service.CreateRelease(context.Background(), gitRepo, &models.Release{
 		RepoID:       repo.ID,
 		PublisherID:  user.ID,
 		TagName:      "v0.2",
@@ -83,7 +85,7 @@ func TestRelease_MirrorDelete(t *testing.T) {
 
 	release, err := models.GetRelease(repo.ID, "v0.2")
 	assert.NoError(t, err)
-	assert.NoError(t, release_service.DeleteReleaseByID(release.ID, user, true))
+	assert.NoError(t, release_service.DeleteReleaseByID(context.Background(), release.ID, user, true))
 
 	_, ok = runSync(mirror.Mirror)
 	assert.True(t, ok)
@@ -92,3 +94,76 @@ func TestRelease_MirrorDelete(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, initCount, count)
 }
+
+func TestPushMirror(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	remotePath := filepath.Join(t.TempDir(), "remote.git")
+	assert.NoError(t, git.InitRepository(remotePath, true))
+
+	m := &models.PushMirror{RepoID: repo.ID, Repo: repo, Interval: time.Hour}
+	assert.NoError(t, AddPushMirror(m, remotePath))
+	assert.Equal(t, remotePath, PushMirrorAddress(m))
+
+	syncPushMirror(pushMirrorPrefix + strconv.FormatInt(m.ID, 10))
+	m = models.AssertExistsAndLoadBean(t, &models.PushMirror{ID: m.ID}).(*models.PushMirror)
+	assert.Empty(t, m.LastError)
+	assert.NotZero(t, m.LastUpdateUnix)
+	assert.NotZero(t, m.NextUpdateUnix)
+
+	remote, err := git.OpenRepository(remotePath)
+	assert.NoError(t, err)
+	defer remote.Close()
+	commitID, err := remote.GetBranchCommitID("master")
+	assert.NoError(t, err)
+	assert.Equal(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", commitID)
+
+	assert.NoError(t, RemovePushMirror(m))
+	models.AssertNotExistsBean(t, &models.PushMirror{ID: m.ID})
+	assert.Empty(t, PushMirrorAddress(m))
+}
+
+func TestPushMirror_FilteredRefs(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	remotePath := filepath.Join(t.TempDir(), "remote.git")
+	assert.NoError(t, git.InitRepository(remotePath, true))
+
+	m := &models.PushMirror{RepoID: repo.ID, Repo: repo, IncludeRefs: []string{"refs/tags/*", "refs/heads/feature/*"}}
+	assert.NoError(t, AddPushMirror(m, remotePath))
+	syncPushMirror(pushMirrorPrefix + strconv.FormatInt(m.ID, 10))
+	m = models.AssertExistsAndLoadBean(t, &models.PushMirror{ID: m.ID}).(*models.PushMirror)
+	assert.Empty(t, m.LastError)
+
+	stdout, err := git.NewCommand("for-each-ref", "--format=%(refname)").RunInDir(remotePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "refs/heads/feature/1\nrefs/tags/v1.1\n", stdout)
+}
+
+func TestValidateRefPatterns(t *testing.T) {
+	assert.NoError(t, ValidateRefPatterns([]string{"refs/tags/v*", "refs/heads/release/*", "refs/heads/main"}))
+	assert.Error(t, ValidateRefPatterns([]string{"v*"}))
+	assert.Error(t, ValidateRefPatterns([]string{"refs/heads/*/*"}))
+	assert.Error(t, ValidateRefPatterns([]string{"refs/heads/a b"}))
+	assert.Error(t, ValidateRefPatterns([]string{"refs/heads/a:b"}))
+}
+
+func TestMirrorRefspecs(t *testing.T) {
+	refspecs, err := mirrorRefspecs(nil, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, refspecs)
+
+	refspecs, err = mirrorRefspecs([]string{"refs/tags/v*"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"+refs/tags/v*:refs/tags/v*"}, refspecs)
+
+	assert.NoError(t, git.LoadGitVersion())
+	if git.CheckGitVersionAtLeast("2.29") != nil {
+		return
+	}
+	refspecs, err = mirrorRefspecs(nil, []string{"refs/pull/*"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"+refs/*:refs/*", "^refs/pull/*"}, refspecs)
+}
@@ -0,0 +1,182 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// DefaultContributionsRange is the time range the contributions are counted over if the start is not given
+const DefaultContributionsRange = 365 * 24 * time.Hour
+
+// contributionsQueue is the queue of the contributions to count in the background
+var contributionsQueue queue.UniqueQueue
+
+// ContributionsRequest identifies the contributions of a member to some repositories of an organization
+type ContributionsRequest struct {
+	OrgID  int64
+	UserID int64
+	// RepoIDs are the repositories to count the contributions in, usually the ones the requester can access
+	RepoIDs []int64
+	// Since and Before are unix times bounding the time range. If Since is 0, the range starts
+	// DefaultContributionsRange before its end. If Before is 0, the range ends when the contributions are counted.
+	Since  int64
+	Before int64
+}
+
+// cacheKey returns the key of the counted contributions in the cache, the repositories are hashed to keep it short
+func (req *ContributionsRequest) cacheKey() string {
+	h := sha1.New()
+	for _, repoID := range req.RepoIDs {
+		_, _ = fmt.Fprintf(h, "%d,", repoID)
+	}
+	return fmt.Sprintf("org_contributions:%d:%d:%d:%d:%s", req.OrgID, req.UserID, req.Since, req.Before, hex.EncodeToString(h.Sum(nil)))
+}
+
+func handleContributions(data ...queue.Data) {
+	for _, datum := range data {
+		req := datum.(ContributionsRequest)
+		contributions, err := countContributions(&req)
+		if err != nil {
+			log.Error("Unable to count the contributions of user %d to organization %d: %v", req.UserID, req.OrgID, err)
+			continue
+		}
+		if err := cacheContributions(&req, contributions); err != nil {
+			log.Error("Unable to cache the contributions of user %d to organization %d: %v", req.UserID, req.OrgID, err)
+		}
+	}
+}
+
+// InitContributions starts the queue whose workers count the contributions of the members of organizations
+func InitContributions() error {
+	contributionsQueue = queue.CreateUniqueQueue("org_contributions", handleContributions, ContributionsRequest{}).(queue.UniqueQueue)
+	if contributionsQueue == nil {
+		return errors.New("unable to create org_contributions queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(contributionsQueue.Run)
+	return nil
+}
+
+func isContributionsCacheEnabled() bool {
+	return cache.GetCache() != nil && setting.CacheService.TTL > 0
+}
+
+func cacheContributions(req *ContributionsRequest, contributions *api.OrgMemberContributions) error {
+	data, err := json.Marshal(contributions)
+	if err != nil {
+		return err
+	}
+	return cache.GetCache().Put(req.cacheKey(), string(data), setting.CacheService.TTLSeconds())
+}
+
+// GetContributions returns the contributions counted for a request, or nil if they are not cached yet, in which case
+// they are counted in the background. Without cache the contributions are counted immediately.
+func GetContributions(req *ContributionsRequest) (*api.OrgMemberContributions, error) {
+	if !isContributionsCacheEnabled() {
+		return countContributions(req)
+	}
+
+	if data, ok := cache.GetCache().Get(req.cacheKey()).(string); ok {
+		contributions := &api.OrgMemberContributions{}
+		if err := json.Unmarshal([]byte(data), contributions); err == nil {
+			return contributions, nil
+		}
+		log.Warn("Invalid cached contributions %s, counting them again", req.cacheKey())
+	}
+
+	if err := contributionsQueue.Push(*req); err != nil && err != queue.ErrAlreadyInQueue {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// countContributions counts the commits, pull requests, reviews and issues of a member in the repositories
+func countContributions(req *ContributionsRequest) (*api.OrgMemberContributions, error) {
+	now := time.Now()
+	before := now
+	if req.Before > 0 {
+		before = time.Unix(req.Before, 0)
+	}
+	since := before.Add(-DefaultContributionsRange)
+	if req.Since > 0 {
+		since = time.Unix(req.Since, 0)
+	}
+
+	counts, err := models.CountUserContributions(req.UserID, req.RepoIDs, timeutil.TimeStamp(since.Unix()), timeutil.TimeStamp(before.Unix()))
+	if err != nil {
+		return nil, err
+	}
+	if counts.Commits, err = countCommits(req.UserID, req.RepoIDs, since, before); err != nil {
+		return nil, err
+	}
+
+	return &api.OrgMemberContributions{
+		Since:              since.UTC(),
+		Before:             before.UTC(),
+		Commits:            counts.Commits,
+		PullRequestsOpened: counts.PullRequestsOpened,
+		PullRequestsMerged: counts.PullRequestsMerged,
+		Reviews:            counts.Reviews,
+		IssuesOpened:       counts.IssuesOpened,
+		Repositories:       len(req.RepoIDs),
+		ComputedAt:         now.UTC(),
+	}, nil
+}
+
+// countCommits counts the commits authored with the activated email addresses of a user in the default branches of
+// the repositories
+func countCommits(userID int64, repoIDs []int64, since, before time.Time) (int64, error) {
+	emailAddresses, err := models.GetEmailAddresses(userID)
+	if err != nil {
+		return 0, err
+	}
+	emails := make([]string, 0, len(emailAddresses))
+	for _, email := range emailAddresses {
+		if email.IsActivated {
+			emails = append(emails, email.Email)
+		}
+	}
+	if len(emails) == 0 {
+		return 0, nil
+	}
+
+	var commits int64
+	for _, repoID := range repoIDs {
+		repo, err := models.GetRepositoryByID(repoID)
+		if err != nil {
+			if models.IsErrRepoNotExist(err) {
+				continue
+			}
+			return 0, err
+		}
+		if repo.IsEmpty || repo.DefaultBranch == "" {
+			continue
+		}
+		count, err := git.AuthorCommitsCount(repo.RepoPath(), git.BranchPrefix+repo.DefaultBranch, emails, since, before)
+		if err != nil {
+			// the default branch may not exist anymore
+			log.Warn("Unable to count the commits of %s: %v", repo.FullName(), err)
+			continue
+		}
+		commits += count
+	}
+	return commits, nil
+}
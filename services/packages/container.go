@@ -0,0 +1,186 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	// ContainerBlobsVersion is the internal version holding the blobs and the manifests of a container image
+	ContainerBlobsVersion = "-blobs"
+	// ContainerManifestFilename is the name of the manifest file of a tag
+	ContainerManifestFilename = "manifest.json"
+)
+
+// ContainerTagMetadata is stored as the metadata of the version of a tag
+type ContainerTagMetadata struct {
+	Digest    string `json:"digest"`
+	MediaType string `json:"media_type"`
+}
+
+// ContainerManifest contains the fields of image manifests and image indexes which reference other content
+type ContainerManifest struct {
+	MediaType string `json:"mediaType"`
+	Config    *struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+	Layers []struct {
+		Digest string `json:"digest"`
+	} `json:"layers"`
+	Manifests []struct {
+		Digest string `json:"digest"`
+	} `json:"manifests"`
+}
+
+// ParseContainerManifest parses an image manifest or an image index
+func ParseContainerManifest(content []byte) (*ContainerManifest, error) {
+	m := new(ContainerManifest)
+	if err := jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(content, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// References returns the digests of the config, the layers and the child manifests
+func (m *ContainerManifest) References() []string {
+	references := make([]string, 0, len(m.Layers)+len(m.Manifests)+1)
+	if m.Config != nil {
+		references = append(references, m.Config.Digest)
+	}
+	for _, layer := range m.Layers {
+		references = append(references, layer.Digest)
+	}
+	for _, child := range m.Manifests {
+		references = append(references, child.Digest)
+	}
+	return references
+}
+
+// ParseContainerTagMetadata parses the metadata of the version of a tag
+func ParseContainerTagMetadata(pv *models.PackageVersion) (*ContainerTagMetadata, error) {
+	meta := new(ContainerTagMetadata)
+	if err := jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal([]byte(pv.MetadataJSON), meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// ReadFile reads the content of a package file
+func ReadFile(pf *models.PackageFile) ([]byte, error) {
+	obj, err := OpenFile(pf)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	return ioutil.ReadAll(obj)
+}
+
+// UnreferencedContainerBlob is a blob of a container image which is referenced by no tag
+type UnreferencedContainerBlob struct {
+	Image  *models.Package
+	Digest string
+	Size   int64
+}
+
+// String returns the image reference of the blob
+func (blob *UnreferencedContainerBlob) String() string {
+	return fmt.Sprintf("%s/%s@%s", blob.Image.Owner.LowerName, blob.Image.LowerName, blob.Digest)
+}
+
+// CollectContainerImageGarbage finds the blobs and the untagged manifests of a container image which are
+// referenced by no tag and were uploaded before olderThan, and deletes them unless dryRun is set.
+// The storage of the deleted blobs is freed by the cleanup task.
+func CollectContainerImageGarbage(image *models.Package, olderThan time.Time, dryRun bool) ([]*UnreferencedContainerBlob, error) {
+	pv, err := models.GetPackageVersionByName(image.ID, ContainerBlobsVersion)
+	if err != nil {
+		if models.IsErrPackageVersionNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	files, err := models.GetPackageFiles(pv.ID)
+	if err != nil {
+		return nil, err
+	}
+	filesByDigest := make(map[string]*models.PackageFile, len(files))
+	for _, pf := range files {
+		filesByDigest[pf.LowerName] = pf
+	}
+
+	versions, err := models.GetPackageVersions(image.ID)
+	if err != nil {
+		return nil, err
+	}
+	// the tags point to manifests, which reference configs, layers and the manifests of an index
+	referenced := make(map[string]bool, len(files))
+	manifests := make([]string, 0, len(versions))
+	for _, version := range versions {
+		meta, err := ParseContainerTagMetadata(version)
+		if err != nil {
+			return nil, fmt.Errorf("ParseContainerTagMetadata [%d]: %v", version.ID, err)
+		}
+		manifests = append(manifests, strings.ToLower(meta.Digest))
+	}
+	for len(manifests) > 0 {
+		digest := manifests[0]
+		manifests = manifests[1:]
+		if referenced[digest] {
+			continue
+		}
+		referenced[digest] = true
+
+		pf, ok := filesByDigest[digest]
+		if !ok {
+			continue
+		}
+		content, err := ReadFile(pf)
+		if err != nil {
+			return nil, fmt.Errorf("ReadFile [%s]: %v", digest, err)
+		}
+		m, err := ParseContainerManifest(content)
+		if err != nil {
+			// keep what the tag points to even if it can not be parsed
+			continue
+		}
+		for _, layer := range m.Layers {
+			referenced[strings.ToLower(layer.Digest)] = true
+		}
+		if m.Config != nil {
+			referenced[strings.ToLower(m.Config.Digest)] = true
+		}
+		for _, child := range m.Manifests {
+			manifests = append(manifests, strings.ToLower(child.Digest))
+		}
+	}
+
+	unreferenced := make([]*UnreferencedContainerBlob, 0, 10)
+	for _, pf := range files {
+		if referenced[pf.LowerName] || !pf.CreatedUnix.AsTime().Before(olderThan) {
+			continue
+		}
+		if err := pf.LoadBlob(); err != nil {
+			return nil, err
+		}
+		if !dryRun {
+			if err := models.DeletePackageFile(pf); err != nil {
+				return nil, fmt.Errorf("DeletePackageFile [%d]: %v", pf.ID, err)
+			}
+		}
+		unreferenced = append(unreferenced, &UnreferencedContainerBlob{
+			Image:  image,
+			Digest: pf.Name,
+			Size:   pf.Blob.Size,
+		})
+	}
+	return unreferenced, nil
+}
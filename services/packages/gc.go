@@ -0,0 +1,154 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// GarbageCollectOptions represents the options of a garbage collection of the package registry
+type GarbageCollectOptions struct {
+	// DryRun only reports what would be deleted
+	DryRun bool
+	// EnforceQuotas deletes the oldest versions of the owners whose packages exceed their quota
+	EnforceQuotas bool
+}
+
+// ExceededQuota represents an owner whose packages are larger than its quota
+type ExceededQuota struct {
+	Owner *models.User
+	// Size is the total size of the packages, after the deletions if the quota was enforced
+	Size  int64
+	Limit int64
+	// DeletedVersions are the versions deleted to enforce the quota, as "type/name@version"
+	DeletedVersions []string
+}
+
+// GarbageCollectReport represents the result of a garbage collection of the package registry
+type GarbageCollectReport struct {
+	UnreferencedBlobs []*UnreferencedContainerBlob
+	ExceededQuotas    []*ExceededQuota
+}
+
+// GarbageCollect deletes the blobs of container images which are referenced by no tag,
+// and checks the total size of the packages of every owner against its quota.
+func GarbageCollect(ctx context.Context, opts GarbageCollectOptions) (*GarbageCollectReport, error) {
+	log.Trace("Doing: GarbageCollectPackages")
+
+	report := &GarbageCollectReport{}
+	olderThan := time.Now().Add(-orphanedBlobMinAge)
+
+	imageIDs, err := models.GetPackageIDsByType(models.PackageTypeContainer)
+	if err != nil {
+		return nil, fmt.Errorf("GetPackageIDsByType: %v", err)
+	}
+	for _, id := range imageIDs {
+		select {
+		case <-ctx.Done():
+			return nil, models.ErrCancelledf("before collecting the garbage of container image %d", id)
+		default:
+		}
+		image, err := models.GetPackageByID(id)
+		if err != nil {
+			if models.IsErrPackageNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("GetPackageByID [%d]: %v", id, err)
+		}
+		if err := image.LoadOwner(); err != nil {
+			return nil, fmt.Errorf("LoadOwner [%d]: %v", id, err)
+		}
+		blobs, err := CollectContainerImageGarbage(image, olderThan, opts.DryRun)
+		if err != nil {
+			return nil, fmt.Errorf("CollectContainerImageGarbage [%d]: %v", id, err)
+		}
+		report.UnreferencedBlobs = append(report.UnreferencedBlobs, blobs...)
+	}
+
+	ownerIDs, err := models.GetPackageOwnerIDs()
+	if err != nil {
+		return nil, fmt.Errorf("GetPackageOwnerIDs: %v", err)
+	}
+	for _, id := range ownerIDs {
+		owner, err := models.GetUserByID(id)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("GetUserByID [%d]: %v", id, err)
+		}
+		limit := OwnerSizeLimit(owner)
+		if limit < 0 {
+			continue
+		}
+		size, err := models.GetPackagesTotalSize(owner.ID)
+		if err != nil {
+			return nil, fmt.Errorf("GetPackagesTotalSize [%d]: %v", owner.ID, err)
+		}
+		if size <= limit {
+			continue
+		}
+		exceeded := &ExceededQuota{Owner: owner, Size: size, Limit: limit}
+		report.ExceededQuotas = append(report.ExceededQuotas, exceeded)
+		if opts.DryRun || !opts.EnforceQuotas {
+			continue
+		}
+		if err := enforceQuota(ctx, exceeded, olderThan); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Trace("Finished: GarbageCollectPackages")
+	return report, nil
+}
+
+// enforceQuota deletes the oldest versions of the packages of an owner until they fit into the quota
+func enforceQuota(ctx context.Context, exceeded *ExceededQuota, olderThan time.Time) error {
+	for exceeded.Size > exceeded.Limit {
+		versions, err := models.GetOldestPackageVersions(exceeded.Owner.ID, 10)
+		if err != nil {
+			return fmt.Errorf("GetOldestPackageVersions [%d]: %v", exceeded.Owner.ID, err)
+		}
+		if len(versions) == 0 {
+			return nil
+		}
+		for _, pv := range versions {
+			select {
+			case <-ctx.Done():
+				return models.ErrCancelledf("before deleting package version %d", pv.ID)
+			default:
+			}
+			pkg, err := models.GetPackageByID(pv.PackageID)
+			if err != nil {
+				return fmt.Errorf("GetPackageByID [%d]: %v", pv.PackageID, err)
+			}
+			pkg.Owner = exceeded.Owner
+			if err := models.DeletePackageVersion(pv); err != nil {
+				return fmt.Errorf("DeletePackageVersion [%d]: %v", pv.ID, err)
+			}
+			exceeded.DeletedVersions = append(exceeded.DeletedVersions, fmt.Sprintf("%s/%s@%s", pkg.Type.Name(), pkg.Name, pv.Version))
+
+			// the blobs of a deleted tag only stop counting once they are collected
+			if pkg.Type == models.PackageTypeContainer {
+				if _, err := CollectContainerImageGarbage(pkg, olderThan, false); err != nil {
+					return fmt.Errorf("CollectContainerImageGarbage [%d]: %v", pkg.ID, err)
+				}
+			}
+
+			if exceeded.Size, err = models.GetPackagesTotalSize(exceeded.Owner.ID); err != nil {
+				return fmt.Errorf("GetPackagesTotalSize [%d]: %v", exceeded.Owner.ID, err)
+			}
+			if exceeded.Size <= exceeded.Limit {
+				return nil
+			}
+		}
+	}
+	return nil
+}
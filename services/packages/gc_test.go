@@ -0,0 +1,118 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func addTestContainerBlob(t *testing.T, owner *models.User, version, filename, content string) string {
+	sum := sha256.Sum256([]byte(content))
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	if len(filename) == 0 {
+		filename = digest
+	}
+	_, _, err := AddFile(&AddFileOptions{
+		Owner:         owner,
+		Creator:       owner,
+		Type:          models.PackageTypeContainer,
+		Name:          "app",
+		Version:       version,
+		Metadata:      fmt.Sprintf(`{"digest":%q}`, digest),
+		IsInternal:    version == ContainerBlobsVersion,
+		Filename:      filename,
+		OverwriteFile: true,
+	}, strings.NewReader(content))
+	assert.NoError(t, err)
+	return digest
+}
+
+func TestCollectContainerImageGarbage(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+	config := addTestContainerBlob(t, owner, ContainerBlobsVersion, "", "config")
+	layer := addTestContainerBlob(t, owner, ContainerBlobsVersion, "", "layer")
+	unreferenced := addTestContainerBlob(t, owner, ContainerBlobsVersion, "", "unreferenced")
+	manifest := fmt.Sprintf(`{"config":{"digest":%q},"layers":[{"digest":%q}]}`, config, layer)
+	addTestContainerBlob(t, owner, ContainerBlobsVersion, "", manifest)
+	addTestContainerBlob(t, owner, "latest", ContainerManifestFilename, manifest)
+
+	image, err := models.GetPackageByName(owner.ID, models.PackageTypeContainer, "app")
+	assert.NoError(t, err)
+	image.Owner = owner
+
+	// recently uploaded blobs may belong to a push which is not finished yet
+	blobs, err := CollectContainerImageGarbage(image, time.Now().Add(-time.Hour), false)
+	assert.NoError(t, err)
+	assert.Len(t, blobs, 0)
+
+	blobs, err = CollectContainerImageGarbage(image, time.Now().Add(time.Hour), true)
+	assert.NoError(t, err)
+	if assert.Len(t, blobs, 1) {
+		assert.Equal(t, unreferenced, blobs[0].Digest)
+		assert.Equal(t, "user2/app@"+unreferenced, blobs[0].String())
+	}
+
+	blobs, err = CollectContainerImageGarbage(image, time.Now().Add(time.Hour), false)
+	assert.NoError(t, err)
+	assert.Len(t, blobs, 1)
+	pf, _, err := models.GetPackageFileInPackage(image.ID, unreferenced)
+	assert.True(t, models.IsErrPackageFileNotExist(err), "%v", pf)
+	_, _, err = models.GetPackageFileInPackage(image.ID, layer)
+	assert.NoError(t, err)
+}
+
+func TestGarbageCollectQuotas(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+	for _, version := range []string{"1.0.0", "2.0.0"} {
+		_, _, err := AddFile(&AddFileOptions{
+			Owner:    owner,
+			Creator:  owner,
+			Type:     models.PackageTypeNpm,
+			Name:     "pkg",
+			Version:  version,
+			Filename: "pkg-" + version + ".tgz",
+		}, strings.NewReader(strings.Repeat(version, 10)))
+		assert.NoError(t, err)
+	}
+
+	defer func(sizes map[string]int64) {
+		setting.Packages.LimitTotalOwnerSizes = sizes
+	}(setting.Packages.LimitTotalOwnerSizes)
+	setting.Packages.LimitTotalOwnerSizes = map[string]int64{owner.LowerName: 60}
+	assert.EqualValues(t, 60, OwnerSizeLimit(owner))
+
+	report, err := GarbageCollect(context.Background(), GarbageCollectOptions{DryRun: true, EnforceQuotas: true})
+	assert.NoError(t, err)
+	if assert.Len(t, report.ExceededQuotas, 1) {
+		assert.EqualValues(t, 100, report.ExceededQuotas[0].Size)
+		assert.Len(t, report.ExceededQuotas[0].DeletedVersions, 0)
+	}
+
+	report, err = GarbageCollect(context.Background(), GarbageCollectOptions{EnforceQuotas: true})
+	assert.NoError(t, err)
+	if assert.Len(t, report.ExceededQuotas, 1) {
+		assert.EqualValues(t, 50, report.ExceededQuotas[0].Size)
+		assert.Equal(t, []string{"npm/pkg@1.0.0"}, report.ExceededQuotas[0].DeletedVersions)
+	}
+
+	report, err = GarbageCollect(context.Background(), GarbageCollectOptions{EnforceQuotas: true})
+	assert.NoError(t, err)
+	assert.Len(t, report.ExceededQuotas, 0)
+}
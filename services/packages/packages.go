@@ -0,0 +1,260 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package packages implements the storage of the package registry shared by all package types.
+package packages
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// orphanedBlobMinAge is the age a blob without files must have before it is deleted,
+// to not delete blobs of uploads which are not finished yet
+const orphanedBlobMinAge = 24 * time.Hour
+
+// CanRead returns true if the doer can read the packages of the owner
+func CanRead(owner, doer *models.User) bool {
+	if doer == nil {
+		return !setting.Service.RequireSignInView && models.HasOrgVisible(owner, doer)
+	}
+	return doer.ID == owner.ID || models.HasOrgVisible(owner, doer)
+}
+
+// CanWrite returns true if the doer can publish and delete packages of the owner.
+// Members of an organization can publish its packages if they can create repositories in it.
+func CanWrite(owner, doer *models.User) (bool, error) {
+	if doer == nil {
+		return false, nil
+	}
+	if doer.IsAdmin || doer.ID == owner.ID {
+		return true, nil
+	}
+	if owner.IsOrganization() {
+		return owner.CanCreateOrgRepo(doer.ID)
+	}
+	return false, nil
+}
+
+// CreateBlob stores content as a package blob, reusing the blob of an identical content
+func CreateBlob(content io.Reader) (*models.PackageBlob, error) {
+	tmp, err := ioutil.TempFile("", "gitea-package")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tmp.Close()
+		if err := util.Remove(tmp.Name()); err != nil {
+			log.Error("Unable to remove temporary file %s: %v", tmp.Name(), err)
+		}
+	}()
+
+	hashMD5, hashSHA1, hashSHA256, hashSHA512 := md5.New(), sha1.New(), sha256.New(), sha512.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hashMD5, hashSHA1, hashSHA256, hashSHA512), content)
+	if err != nil {
+		return nil, err
+	}
+
+	blob, exists, err := models.GetOrInsertPackageBlob(&models.PackageBlob{
+		Size:       size,
+		HashMD5:    hex.EncodeToString(hashMD5.Sum(nil)),
+		HashSHA1:   hex.EncodeToString(hashSHA1.Sum(nil)),
+		HashSHA256: hex.EncodeToString(hashSHA256.Sum(nil)),
+		HashSHA512: hex.EncodeToString(hashSHA512.Sum(nil)),
+	})
+	if err != nil || exists {
+		return blob, err
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if _, err := storage.Packages.Save(blob.RelativePath(), tmp); err != nil {
+		if err := models.DeletePackageBlob(blob); err != nil {
+			log.Error("Unable to delete package blob %d: %v", blob.ID, err)
+		}
+		return nil, err
+	}
+	return blob, nil
+}
+
+// OwnerSizeLimit returns the maximum total size of the packages of an owner, -1 means no limit
+func OwnerSizeLimit(owner *models.User) int64 {
+	if limit, ok := setting.Packages.LimitTotalOwnerSizes[owner.LowerName]; ok {
+		return limit
+	}
+	return setting.Packages.LimitTotalOwnerSize
+}
+
+// CheckQuota returns ErrPackageQuotaExceeded if a file of the given size cannot be added to a package of the owner
+func CheckQuota(owner *models.User, pt models.PackageType, size int64) error {
+	if limit := pt.SizeLimit(); limit >= 0 && size > limit {
+		return models.ErrPackageQuotaExceeded{Limit: limit}
+	}
+	if limit := OwnerSizeLimit(owner); limit >= 0 {
+		total, err := models.GetPackagesTotalSize(owner.ID)
+		if err != nil {
+			return err
+		}
+		if total+size > limit {
+			return models.ErrPackageQuotaExceeded{Limit: limit, IsTotal: true}
+		}
+	}
+	return nil
+}
+
+// AddFileOptions represents the options to add a file to a package version
+type AddFileOptions struct {
+	Owner   *models.User
+	Creator *models.User
+	Type    models.PackageType
+	Name    string
+	Version string
+	// Metadata is stored as JSON on the version when it is created
+	Metadata string
+	// IsInternal creates the version as an internal version
+	IsInternal bool
+	// MustCreateVersion returns ErrPackageVersionAlreadyExist if the version exists
+	MustCreateVersion bool
+	Filename          string
+	// OverwriteFile replaces an existing file with the same name instead of returning ErrPackageFileAlreadyExist
+	OverwriteFile bool
+}
+
+// GetOrCreateVersion returns the version of the package described by opts, creating the package and the version if needed
+func GetOrCreateVersion(opts *AddFileOptions) (*models.Package, *models.PackageVersion, error) {
+	pkg, err := models.GetOrCreatePackage(&models.Package{
+		OwnerID: opts.Owner.ID,
+		Type:    opts.Type,
+		Name:    opts.Name,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pv, err := models.GetPackageVersionByName(pkg.ID, opts.Version)
+	if err == nil {
+		if opts.MustCreateVersion {
+			return nil, nil, models.ErrPackageVersionAlreadyExist{Version: opts.Version}
+		}
+		return pkg, pv, nil
+	} else if !models.IsErrPackageVersionNotExist(err) {
+		return nil, nil, err
+	}
+
+	pv = &models.PackageVersion{
+		PackageID:    pkg.ID,
+		CreatorID:    opts.Creator.ID,
+		Version:      opts.Version,
+		IsInternal:   opts.IsInternal,
+		MetadataJSON: opts.Metadata,
+	}
+	if err := models.CreatePackageVersion(pv); err != nil {
+		return nil, nil, err
+	}
+	return pkg, pv, nil
+}
+
+// AddBlobToVersion adds an existing blob as a file to a version
+func AddBlobToVersion(pv *models.PackageVersion, blob *models.PackageBlob, filename string, overwrite bool) (*models.PackageFile, error) {
+	pf := &models.PackageFile{
+		VersionID: pv.ID,
+		BlobID:    blob.ID,
+		Name:      filename,
+		Blob:      blob,
+	}
+	if err := models.AddPackageFile(pf, overwrite); err != nil {
+		return nil, err
+	}
+	return pf, nil
+}
+
+// AddFile stores content as a file of a package version, creating the package and the version if needed
+func AddFile(opts *AddFileOptions, content io.Reader) (*models.PackageVersion, *models.PackageFile, error) {
+	blob, err := CreateBlob(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := CheckQuota(opts.Owner, opts.Type, blob.Size); err != nil {
+		return nil, nil, err
+	}
+
+	_, pv, err := GetOrCreateVersion(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	pf, err := AddBlobToVersion(pv, blob, opts.Filename, opts.OverwriteFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pv, pf, nil
+}
+
+// OpenFile opens the content of a package file
+func OpenFile(pf *models.PackageFile) (storage.Object, error) {
+	if err := pf.LoadBlob(); err != nil {
+		return nil, err
+	}
+	return storage.Packages.Open(pf.Blob.RelativePath())
+}
+
+// Cleanup deletes the blobs which belong to no package file and the abandoned chunked uploads
+func Cleanup(ctx context.Context) error {
+	log.Trace("Doing: CleanupPackages")
+
+	olderThan := time.Now().Add(-orphanedBlobMinAge)
+	for {
+		blobs, err := models.GetOrphanedPackageBlobs(olderThan, 100)
+		if err != nil {
+			return fmt.Errorf("GetOrphanedPackageBlobs: %v", err)
+		}
+		if len(blobs) == 0 {
+			break
+		}
+		for _, blob := range blobs {
+			select {
+			case <-ctx.Done():
+				return models.ErrCancelledf("before deleting package blob %d", blob.ID)
+			default:
+			}
+			if err := storage.Packages.Delete(blob.RelativePath()); err != nil {
+				return fmt.Errorf("Delete [%s]: %v", blob.RelativePath(), err)
+			}
+			if err := models.DeletePackageBlob(blob); err != nil {
+				return fmt.Errorf("DeletePackageBlob [%d]: %v", blob.ID, err)
+			}
+		}
+	}
+
+	entries, err := ioutil.ReadDir(setting.Packages.ChunkedUploadPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("ReadDir: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.ModTime().Before(olderThan) {
+			if err := util.Remove(filepath.Join(setting.Packages.ChunkedUploadPath, entry.Name())); err != nil {
+				return fmt.Errorf("Remove [%s]: %v", entry.Name(), err)
+			}
+		}
+	}
+
+	log.Trace("Finished: CleanupPackages")
+	return nil
+}
@@ -0,0 +1,188 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+)
+
+// autoMergeQueue represents a queue of pull request IDs whose scheduled merges have to be checked
+var autoMergeQueue queue.UniqueQueue
+
+// addToAutoMergeQueue schedules the scheduled merge of a pull request to be checked
+func addToAutoMergeQueue(pullID int64) {
+	if autoMergeQueue == nil {
+		return
+	}
+	if err := autoMergeQueue.Push(strconv.FormatInt(pullID, 10)); err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Unable to push pull request %d to the auto merge queue: %v", pullID, err)
+	}
+}
+
+// ScheduleAutoMerge schedules a pull request to be merged by doer when all checks succeed.
+// Caller should check doer is allowed to merge the pull request.
+func ScheduleAutoMerge(doer *models.User, pr *models.PullRequest, style models.MergeStyle, message string) error {
+	if err := pr.LoadBaseRepo(); err != nil {
+		return fmt.Errorf("LoadBaseRepo: %v", err)
+	}
+
+	prUnit, err := pr.BaseRepo.GetUnit(models.UnitTypePullRequests)
+	if err != nil {
+		return err
+	}
+	if !prUnit.PullRequestsConfig().IsMergeStyleAllowed(style) {
+		return models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: style}
+	}
+
+	if err := models.ScheduleAutoMerge(doer, pr, style, message); err != nil {
+		return err
+	}
+
+	// the checks might have succeeded in the meantime
+	addToAutoMergeQueue(pr.ID)
+	return nil
+}
+
+// CancelScheduledAutoMerge cancels the scheduled merge of a pull request
+func CancelScheduledAutoMerge(doer *models.User, pr *models.PullRequest) error {
+	return models.RemoveScheduledAutoMerge(doer, pr)
+}
+
+// AutoMergeCheckByRepo checks the scheduled merges of the pull requests from or to a repository.
+// It is called when the status of a commit in the repository changes.
+func AutoMergeCheckByRepo(repoID int64) {
+	pullIDs, err := models.GetScheduledMergePullIDsByRepo(repoID)
+	if err != nil {
+		log.Error("GetScheduledMergePullIDsByRepo[%d]: %v", repoID, err)
+		return
+	}
+	for _, pullID := range pullIDs {
+		addToAutoMergeQueue(pullID)
+	}
+}
+
+func handleAutoMerge(data ...queue.Data) {
+	ctx := graceful.GetManager().ShutdownContext()
+	for _, datum := range data {
+		pullID, _ := strconv.ParseInt(datum.(string), 10, 64)
+		if err := handlePullRequestAutoMerge(ctx, pullID); err != nil {
+			log.Error("Unable to auto merge pull request %d: %v", pullID, err)
+		}
+	}
+}
+
+// handlePullRequestAutoMerge merges a pull request if it is scheduled to auto merge and all checks succeed
+func handlePullRequestAutoMerge(ctx context.Context, pullID int64) error {
+	exist, scheduledPRM, err := models.GetScheduledMergeByPullID(pullID)
+	if err != nil {
+		return err
+	} else if !exist {
+		return nil
+	}
+
+	pr, err := models.GetPullRequestByID(pullID)
+	if err != nil {
+		return err
+	}
+	if err := pr.LoadIssue(); err != nil {
+		return err
+	}
+	if pr.HasMerged || pr.Issue.IsClosed {
+		return models.RemoveScheduledAutoMerge(nil, pr)
+	}
+
+	if err := scheduledPRM.LoadDoer(); err != nil {
+		if models.IsErrUserNotExist(err) {
+			return models.RemoveScheduledAutoMerge(nil, pr)
+		}
+		return err
+	}
+	doer := scheduledPRM.Doer
+
+	if err := pr.LoadBaseRepo(); err != nil {
+		return err
+	}
+	if err := pr.LoadHeadRepo(); err != nil {
+		return err
+	}
+
+	perm, err := models.GetUserRepoPermission(pr.BaseRepo, doer)
+	if err != nil {
+		return err
+	}
+	if allowed, err := IsUserAllowedToMerge(pr, perm, doer); err != nil {
+		return err
+	} else if !allowed {
+		log.Info("%-v is not allowed to merge pull request %d anymore, canceling the scheduled merge", doer, pr.ID)
+		return models.RemoveScheduledAutoMerge(doer, pr)
+	}
+
+	// Wait until the pull request can be merged
+	if !pr.CanAutoMerge() || pr.IsWorkInProgress() {
+		return nil
+	}
+	if err := CheckPRReadyToMerge(pr, false); err != nil {
+		if models.IsErrNotAllowedToMerge(err) {
+			return nil
+		}
+		return err
+	}
+	if _, err := IsSignedIfRequired(pr, doer); err != nil {
+		if models.IsErrWontSign(err) {
+			return nil
+		}
+		return err
+	}
+	if noDeps, err := models.IssueNoDependenciesLeft(pr.Issue); err != nil || !noDeps {
+		return err
+	}
+
+	if err := pr.LoadProtectedBranch(); err != nil {
+		return err
+	}
+	if pr.ProtectedBranch != nil && pr.ProtectedBranch.EnableMergeQueue {
+		if err := AddToMergeQueue(pr, doer, scheduledPRM.MergeStyle, scheduledPRM.Message); err != nil && !models.IsErrPullAlreadyInMergeQueue(err) {
+			return err
+		}
+		return models.RemoveScheduledAutoMerge(nil, pr)
+	}
+
+	baseGitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer baseGitRepo.Close()
+
+	if err := Merge(ctx, pr, doer, baseGitRepo, scheduledPRM.MergeStyle, scheduledPRM.Message); err != nil {
+		if models.IsErrInvalidMergeStyle(err) || models.IsErrMergeConflicts(err) || models.IsErrRebaseConflicts(err) ||
+			models.IsErrMergeUnrelatedHistories(err) || models.IsErrMergeDivergingFastForwardOnly(err) || git.IsErrPushRejected(err) {
+			log.Info("Scheduled merge of pull request %d failed, canceling it: %v", pr.ID, err)
+			if err := models.RemoveScheduledAutoMerge(doer, pr); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+
+	return models.RemoveScheduledAutoMerge(nil, pr)
+}
+
+func initAutoMerge() error {
+	autoMergeQueue = queue.CreateUniqueQueue("pr_auto_merge", handleAutoMerge, "").(queue.UniqueQueue)
+	if autoMergeQueue == nil {
+		return fmt.Errorf("Unable to create pr_auto_merge Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(autoMergeQueue.Run)
+	return nil
+}
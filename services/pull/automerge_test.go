@@ -0,0 +1,30 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleAutoMerge(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 2}).(*models.PullRequest)
+
+	err := ScheduleAutoMerge(doer, pr, models.MergeStyle("unknown"), "")
+	assert.True(t, models.IsErrInvalidMergeStyle(err))
+	models.AssertNotExistsBean(t, &models.PullAutoMerge{PullID: pr.ID})
+
+	assert.NoError(t, ScheduleAutoMerge(doer, pr, models.MergeStyleMerge, "Merge"))
+	models.AssertExistsAndLoadBean(t, &models.PullAutoMerge{PullID: pr.ID, MergeStyle: models.MergeStyleMerge})
+
+	assert.NoError(t, CancelScheduledAutoMerge(doer, pr))
+	models.AssertNotExistsBean(t, &models.PullAutoMerge{PullID: pr.ID})
+}
@@ -47,7 +47,7 @@ func AddToTaskQueue(pr *models.PullRequest) {
 
 // checkAndUpdateStatus checks if pull request is possible to leaving checking status,
 // and set to be either conflict or mergeable.
-func checkAndUpdateStatus(pr *models.PullRequest) {
+func checkAndUpdateStatus(ctx context.Context, pr *models.PullRequest) {
 	// Status is not changed to conflict means mergeable.
 	if pr.Status == models.PullRequestStatusChecking {
 		pr.Status = models.PullRequestStatusMergeable
@@ -62,7 +62,9 @@ func checkAndUpdateStatus(pr *models.PullRequest) {
 	if !has {
 		if err := pr.UpdateColsIfNotMerged("merge_base", "status", "conflicted_files", "changed_protected_files"); err != nil {
 			log.Error("Update[%d]: %v", pr.ID, err)
+			return
 		}
+		notification.NotifyPullRequestChecked(ctx, pr)
 	}
 }
 
@@ -136,7 +138,7 @@ func getMergeCommit(pr *models.PullRequest) (*git.Commit, error) {
 
 // manuallyMerged checks if a pull request got manually merged
 // When a pull request got manually merged mark the pull request as merged
-func manuallyMerged(pr *models.PullRequest) bool {
+func manuallyMerged(ctx context.Context, pr *models.PullRequest) bool {
 	if err := pr.LoadBaseRepo(); err != nil {
 		log.Error("PullRequest[%d].LoadBaseRepo: %v", pr.ID, err)
 		return false
@@ -183,7 +185,7 @@ func manuallyMerged(pr *models.PullRequest) bool {
 			return false
 		}
 
-		notification.NotifyMergePullRequest(pr, merger)
+		notification.NotifyMergePullRequest(ctx, pr, merger)
 
 		log.Info("manuallyMerged[%d]: Marked as manually merged into %s/%s by commit id: %s", pr.ID, pr.BaseRepo.Name, pr.BaseBranch, commit.ID.String())
 		return true
@@ -215,6 +217,7 @@ func InitializePullRequests(ctx context.Context) {
 
 // handle passed PR IDs and test the PRs
 func handle(data ...queue.Data) {
+	ctx := graceful.GetManager().ShutdownContext()
 	for _, datum := range data {
 		id, _ := strconv.ParseInt(datum.(string), 10, 64)
 
@@ -226,7 +229,7 @@ func handle(data ...queue.Data) {
 			continue
 		} else if pr.HasMerged {
 			continue
-		} else if manuallyMerged(pr) {
+		} else if manuallyMerged(ctx, pr) {
 			continue
 		} else if err = TestPatch(pr); err != nil {
 			log.Error("testPatch[%d]: %v", pr.ID, err)
@@ -236,7 +239,8 @@ func handle(data ...queue.Data) {
 			}
 			continue
 		}
-		checkAndUpdateStatus(pr)
+		checkAndUpdateStatus(ctx, pr)
+		addToAutoMergeQueue(pr.ID)
 	}
 }
 
@@ -264,5 +268,8 @@ func Init() error {
 
 	go graceful.GetManager().RunWithShutdownFns(prQueue.Run)
 	go graceful.GetManager().RunWithShutdownContext(InitializePullRequests)
-	return nil
+	if err := initAutoMerge(); err != nil {
+		return err
+	}
+	return initMergeQueue()
 }
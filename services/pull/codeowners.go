@@ -0,0 +1,460 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	issue_service "code.gitea.io/gitea/services/issue"
+
+	"github.com/gobwas/glob"
+)
+
+// CodeOwnersFiles are the locations of the CODEOWNERS file, the first one found is used
+var CodeOwnersFiles = []string{"CODEOWNERS", ".gitea/CODEOWNERS", ".github/CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeOwnersMaxSize is the maximum size of a CODEOWNERS file which is parsed
+const codeOwnersMaxSize = 3 * 1024 * 1024
+
+// CodeOwnerRule represents a line of a CODEOWNERS file
+type CodeOwnerRule struct {
+	Pattern string
+	// Owners are user names or teams prefixed with @, e.g. @user or @org/team, or email addresses.
+	// A rule without owners removes the owners of previous rules from the matching files.
+	Owners []string
+
+	globs []glob.Glob
+}
+
+// Match returns true if the rule applies to the file of the path
+func (rule *CodeOwnerRule) Match(path string) bool {
+	for _, g := range rule.globs {
+		if g.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileCodeOwnerPattern converts a gitignore style pattern into globs.
+// Patterns starting with or containing a slash are relative to the repository root,
+// other patterns match at any depth. Patterns which match a directory also match
+// all files in the directory, except patterns ending with /* which only match
+// the files directly in the directory.
+func compileCodeOwnerPattern(pattern string) ([]glob.Glob, error) {
+	p := strings.TrimPrefix(pattern, "/")
+	anchored := len(p) < len(pattern) || strings.Contains(strings.TrimSuffix(p, "/"), "/")
+	dirOnly := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	if p == "" {
+		p = "**"
+	}
+	// braces are no special characters in gitignore patterns
+	p = strings.NewReplacer("{", "\\{", "}", "\\}").Replace(p)
+
+	bases := []string{p}
+	if !anchored && !strings.HasPrefix(p, "**/") {
+		bases = append(bases, "**/"+p)
+	}
+	exprs := make([]string, 0, 2*len(bases))
+	for _, base := range bases {
+		if !dirOnly {
+			exprs = append(exprs, base)
+		}
+		if !strings.HasSuffix(base, "/*") {
+			exprs = append(exprs, base+"/**")
+		}
+	}
+
+	globs := make([]glob.Glob, 0, len(exprs))
+	for _, expr := range exprs {
+		g, err := glob.Compile(expr, '/')
+		if err != nil {
+			return nil, err
+		}
+		globs = append(globs, g)
+	}
+	return globs, nil
+}
+
+// ParseCodeOwners parses the content of a CODEOWNERS file. Lines which
+// cannot be parsed are skipped and returned as warnings.
+func ParseCodeOwners(r io.Reader) ([]*CodeOwnerRule, []string) {
+	var rules []*CodeOwnerRule
+	var warnings []string
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		rule := &CodeOwnerRule{
+			Pattern: strings.TrimPrefix(fields[0], "\\"),
+		}
+		valid := true
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break
+			}
+			if !strings.Contains(owner, "@") || strings.HasSuffix(owner, "@") {
+				warnings = append(warnings, fmt.Sprintf("line %d: invalid owner %q", lineNum, owner))
+				valid = false
+				break
+			}
+			rule.Owners = append(rule.Owners, owner)
+		}
+		if !valid {
+			continue
+		}
+
+		var err error
+		if rule.globs, err = compileCodeOwnerPattern(rule.Pattern); err != nil {
+			warnings = append(warnings, fmt.Sprintf("line %d: invalid pattern %q: %v", lineNum, rule.Pattern, err))
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, warnings
+}
+
+// MatchCodeOwnerRule returns the last rule matching the path, which takes precedence
+func MatchCodeOwnerRule(rules []*CodeOwnerRule, path string) *CodeOwnerRule {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].Match(path) {
+			return rules[i]
+		}
+	}
+	return nil
+}
+
+// GetCodeOwnerRules returns the rules of the CODEOWNERS file of the commit.
+// It returns no rules if the commit has no CODEOWNERS file.
+func GetCodeOwnerRules(commit *git.Commit) ([]*CodeOwnerRule, error) {
+	for _, name := range CodeOwnersFiles {
+		entry, err := commit.GetTreeEntryByPath(name)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if entry.IsDir() {
+			continue
+		}
+
+		r, err := entry.Blob().DataAsync()
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+
+		rules, warnings := ParseCodeOwners(io.LimitReader(r, codeOwnersMaxSize))
+		for _, warning := range warnings {
+			log.Debug("%s of commit %s: %s", name, commit.ID, warning)
+		}
+		return rules, nil
+	}
+	return nil, nil
+}
+
+// CodeOwnerGroup are the files changed by a pull request which are owned by the owners of the same rule
+type CodeOwnerGroup struct {
+	Rule  *CodeOwnerRule
+	Files []string
+	Users []*models.User
+	Teams []*models.Team
+}
+
+// codeOwner is the user or the team of an owner of a CODEOWNERS file
+type codeOwner struct {
+	user *models.User
+	team *models.Team
+}
+
+// codeOwnerResolver looks up the users and teams of the owners of a repository
+type codeOwnerResolver struct {
+	repo   *models.Repository
+	owners map[string]codeOwner
+}
+
+// resolve returns the user or the team of an owner, or neither if the owner does not exist
+func (r *codeOwnerResolver) resolve(owner string) (*models.User, *models.Team, error) {
+	key := strings.ToLower(owner)
+	if o, ok := r.owners[key]; ok {
+		return o.user, o.team, nil
+	}
+
+	var o codeOwner
+	var err error
+	switch {
+	case !strings.HasPrefix(owner, "@"):
+		o.user, err = models.GetUserByEmail(owner)
+	case strings.Contains(owner, "/"):
+		parts := strings.SplitN(owner[1:], "/", 2)
+		// only teams of the organization owning the repository can be code owners
+		if strings.EqualFold(parts[0], r.repo.OwnerName) && r.repo.Owner.IsOrganization() {
+			o.team, err = models.GetTeam(r.repo.OwnerID, parts[1])
+		}
+	default:
+		o.user, err = models.GetUserByName(owner[1:])
+	}
+	if err != nil {
+		if !models.IsErrUserNotExist(err) && !models.IsErrTeamNotExist(err) {
+			return nil, nil, err
+		}
+		o = codeOwner{}
+	}
+	if o.user != nil && (o.user.IsOrganization() || !o.user.IsActive || o.user.ProhibitLogin) {
+		o.user = nil
+	}
+
+	r.owners[key] = o
+	return o.user, o.team, nil
+}
+
+// getChangedFiles returns the paths of the files changed by the pull request since it branched off its base branch
+func getChangedFiles(gitRepo *git.Repository, pr *models.PullRequest) ([]string, error) {
+	stdout, err := git.NewCommand("diff", "--name-only", "-z", git.BranchPrefix+pr.BaseBranch+"..."+pr.GetGitRefName(), "--").
+		RunInDirBytes(gitRepo.Path)
+	if err != nil {
+		return nil, fmt.Errorf("git diff: %v", err)
+	}
+	files := make([]string, 0, 10)
+	for _, path := range strings.Split(string(stdout), "\x00") {
+		if path != "" {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// GetCodeOwnerGroups returns the owners of the files changed by the pull request,
+// grouped by the rule of the CODEOWNERS file of the base branch which matches them
+func GetCodeOwnerGroups(pr *models.PullRequest) ([]*CodeOwnerGroup, error) {
+	if err := pr.LoadBaseRepo(); err != nil {
+		return nil, fmt.Errorf("LoadBaseRepo: %v", err)
+	}
+	if err := pr.BaseRepo.GetOwner(); err != nil {
+		return nil, fmt.Errorf("GetOwner: %v", err)
+	}
+
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return nil, fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	baseCommit, err := gitRepo.GetBranchCommit(pr.BaseBranch)
+	if err != nil {
+		return nil, fmt.Errorf("GetBranchCommit: %v", err)
+	}
+	rules, err := GetCodeOwnerRules(baseCommit)
+	if err != nil {
+		return nil, fmt.Errorf("GetCodeOwnerRules: %v", err)
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	files, err := getChangedFiles(gitRepo, pr)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := &codeOwnerResolver{
+		repo:   pr.BaseRepo,
+		owners: make(map[string]codeOwner),
+	}
+	groups := make(map[*CodeOwnerRule]*CodeOwnerGroup)
+	var ordered []*CodeOwnerGroup
+	for _, path := range files {
+		rule := MatchCodeOwnerRule(rules, path)
+		if rule == nil || len(rule.Owners) == 0 {
+			continue
+		}
+		if group, ok := groups[rule]; ok {
+			group.Files = append(group.Files, path)
+			continue
+		}
+
+		group := &CodeOwnerGroup{Rule: rule, Files: []string{path}}
+		for _, owner := range rule.Owners {
+			u, t, err := resolver.resolve(owner)
+			if err != nil {
+				return nil, err
+			}
+			if u != nil {
+				group.Users = append(group.Users, u)
+			} else if t != nil {
+				group.Teams = append(group.Teams, t)
+			}
+		}
+		groups[rule] = group
+		ordered = append(ordered, group)
+	}
+	return ordered, nil
+}
+
+// RequestCodeOwnerReviews requests reviews from the code owners of the files changed by the pull request.
+// Owners who already reviewed or were already requested are skipped.
+func RequestCodeOwnerReviews(ctx context.Context, pr *models.PullRequest) error {
+	if err := pr.LoadIssue(); err != nil {
+		return fmt.Errorf("LoadIssue: %v", err)
+	}
+	if pr.Issue.IsClosed || pr.HasMerged {
+		return nil
+	}
+	groups, err := GetCodeOwnerGroups(pr)
+	if err != nil {
+		return err
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+	if err := pr.Issue.LoadPoster(); err != nil {
+		return fmt.Errorf("LoadPoster: %v", err)
+	}
+	pr.Issue.Repo = pr.BaseRepo
+
+	requested := make(map[string]bool)
+	for _, group := range groups {
+		for _, u := range group.Users {
+			key := fmt.Sprintf("user-%d", u.ID)
+			if requested[key] || u.ID == pr.Issue.PosterID {
+				continue
+			}
+			requested[key] = true
+
+			if _, err := models.GetReviewByIssueIDAndUserID(pr.IssueID, u.ID); err == nil {
+				continue
+			} else if !models.IsErrReviewNotExist(err) {
+				return err
+			}
+			perm, err := models.GetUserRepoPermission(pr.BaseRepo, u)
+			if err != nil {
+				return err
+			}
+			if !perm.CanAccessAny(models.AccessModeRead, models.UnitTypePullRequests) {
+				continue
+			}
+			if _, err := issue_service.ReviewRequest(ctx, pr.Issue, pr.Issue.Poster, u, true); err != nil {
+				return fmt.Errorf("ReviewRequest: %v", err)
+			}
+		}
+
+		for _, t := range group.Teams {
+			key := fmt.Sprintf("team-%d", t.ID)
+			if requested[key] {
+				continue
+			}
+			requested[key] = true
+
+			if _, err := models.GetTeamReviewerByIssueIDAndTeamID(pr.IssueID, t.ID); err == nil {
+				continue
+			} else if !models.IsErrReviewNotExist(err) {
+				return err
+			}
+			if pr.BaseRepo.IsPrivate && !models.HasTeamRepo(t.OrgID, t.ID, pr.BaseRepo.ID) {
+				continue
+			}
+			if _, err := issue_service.TeamReviewRequest(ctx, pr.Issue, pr.Issue.Poster, t, true); err != nil {
+				return fmt.Errorf("TeamReviewRequest: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// GetUnapprovedCodeOwnerGroups returns the groups of files changed by the pull request
+// which are not approved by any of their code owners yet
+func GetUnapprovedCodeOwnerGroups(pr *models.PullRequest) ([]*CodeOwnerGroup, error) {
+	if err := pr.LoadProtectedBranch(); err != nil {
+		return nil, fmt.Errorf("LoadProtectedBranch: %v", err)
+	}
+	groups, err := GetCodeOwnerGroups(pr)
+	if err != nil || len(groups) == 0 {
+		return nil, err
+	}
+
+	reviews, err := models.GetReviewersByIssueID(pr.IssueID)
+	if err != nil {
+		return nil, fmt.Errorf("GetReviewersByIssueID: %v", err)
+	}
+	approvers := make([]int64, 0, len(reviews))
+	for _, review := range reviews {
+		if review.Type != models.ReviewTypeApprove || review.ReviewerTeamID > 0 {
+			continue
+		}
+		if review.Stale && pr.ProtectedBranch != nil && pr.ProtectedBranch.DismissStaleApprovals {
+			continue
+		}
+		approvers = append(approvers, review.ReviewerID)
+	}
+
+	unapproved := make([]*CodeOwnerGroup, 0, len(groups))
+	for _, group := range groups {
+		approved, err := isCodeOwnerGroupApproved(group, approvers)
+		if err != nil {
+			return nil, err
+		}
+		if !approved {
+			unapproved = append(unapproved, group)
+		}
+	}
+	return unapproved, nil
+}
+
+// isCodeOwnerGroupApproved returns true if one of the approvers is an owner of the group
+func isCodeOwnerGroupApproved(group *CodeOwnerGroup, approvers []int64) (bool, error) {
+	for _, approver := range approvers {
+		for _, u := range group.Users {
+			if u.ID == approver {
+				return true, nil
+			}
+		}
+		for _, t := range group.Teams {
+			isMember, err := models.IsTeamMember(t.OrgID, t.ID, approver)
+			if err != nil {
+				return false, fmt.Errorf("IsTeamMember: %v", err)
+			}
+			if isMember {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// MergeBlockedByCodeOwners returns the patterns of the files which still need approval of their
+// code owners if the protected branch requires it
+func MergeBlockedByCodeOwners(pr *models.PullRequest) ([]string, error) {
+	if err := pr.LoadProtectedBranch(); err != nil {
+		return nil, fmt.Errorf("LoadProtectedBranch: %v", err)
+	}
+	if pr.ProtectedBranch == nil || !pr.ProtectedBranch.RequireCodeOwnerReviews {
+		return nil, nil
+	}
+	groups, err := GetUnapprovedCodeOwnerGroups(pr)
+	if err != nil {
+		return nil, err
+	}
+	patterns := make([]string, 0, len(groups))
+	for _, group := range groups {
+		patterns = append(patterns, group.Rule.Pattern)
+	}
+	return patterns, nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCodeOwners(t *testing.T) {
+	rules, warnings := ParseCodeOwners(strings.NewReader(`# default owners
+*       @user2 @org3/team1
+
+*.js    user4@example.com # inline comment
+/docs/  @user5
+docs/*  @user6
+apps/   @user7
+/build/ @user8
+**/logs @user9
+/vendor/
+invalid owner
+`))
+	assert.Len(t, warnings, 1)
+	assert.Len(t, rules, 8)
+	assert.Equal(t, []string{"@user2", "@org3/team1"}, rules[0].Owners)
+	assert.Equal(t, []string{"user4@example.com"}, rules[1].Owners)
+	assert.Empty(t, rules[7].Owners)
+
+	owner := func(path string) string {
+		rule := MatchCodeOwnerRule(rules, path)
+		if rule == nil {
+			return ""
+		}
+		return strings.Join(rule.Owners, " ")
+	}
+	assert.Equal(t, "@user2 @org3/team1", owner("README.md"))
+	assert.Equal(t, "user4@example.com", owner("web_src/js/index.js"))
+	assert.Equal(t, "user4@example.com", owner("index.js"))
+	assert.Equal(t, "@user5", owner("docs/content/index.md"))
+	assert.Equal(t, "@user6", owner("docs/index.md"))
+	assert.Equal(t, "@user7", owner("apps/app.go"))
+	assert.Equal(t, "@user7", owner("src/apps/app.go"))
+	// the last matching rule takes precedence
+	assert.Equal(t, "@user9", owner("build/logs/out.log"))
+	assert.Equal(t, "@user9", owner("src/logs/out.log"))
+	assert.Equal(t, "@user8", owner("build/Makefile"))
+	assert.Equal(t, "", owner("vendor/modules.txt"))
+}
+
+func TestCompileCodeOwnerPattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"*", "a/b/c", true},
+		{"/", "a/b/c", true},
+		{"Makefile", "Makefile", true},
+		{"Makefile", "sub/Makefile", true},
+		{"/Makefile", "sub/Makefile", false},
+		{"docs/*", "docs/a.md", true},
+		{"docs/*", "docs/sub/a.md", false},
+		{"docs/*", "sub/docs/a.md", false},
+		{"apps/", "apps", false},
+		{"apps/", "sub/apps/a.go", true},
+		{"/docs/**/*.md", "docs/a/b/c.md", true},
+		{"{a}.txt", "{a}.txt", true},
+		{"*.go", "main.go.orig", false},
+	}
+	for _, c := range cases {
+		globs, err := compileCodeOwnerPattern(c.pattern)
+		assert.NoError(t, err)
+		rule := &CodeOwnerRule{Pattern: c.pattern, globs: globs}
+		assert.Equal(t, c.match, rule.Match(c.path), "%s ~ %s", c.pattern, c.path)
+	}
+}
@@ -13,7 +13,9 @@ import (
 	"github.com/pkg/errors"
 )
 
-// MergeRequiredContextsCommitStatus returns a commit status state for given required contexts
+// MergeRequiredContextsCommitStatus returns a commit status state for given required contexts.
+// A required context may be a glob pattern, in which case all matching commit statuses are
+// taken into account and the check is pending until at least one of them has been reported.
 func MergeRequiredContextsCommitStatus(commitStatuses []*models.CommitStatus, requiredContexts []string) structs.CommitStatusState {
 	if len(requiredContexts) == 0 {
 		status := models.CalcCommitStatus(commitStatuses)
@@ -25,11 +27,13 @@ func MergeRequiredContextsCommitStatus(commitStatuses []*models.CommitStatus, re
 
 	var returnedStatus = structs.CommitStatusSuccess
 	for _, ctx := range requiredContexts {
+		pattern := models.CompileStatusCheckPattern(ctx)
 		var targetStatus structs.CommitStatusState
 		for _, commitStatus := range commitStatuses {
-			if commitStatus.Context == ctx {
-				targetStatus = commitStatus.State
-				break
+			if pattern.Match(commitStatus.Context) {
+				if targetStatus == "" || commitStatus.State.NoBetterThan(targetStatus) {
+					targetStatus = commitStatus.State
+				}
 			}
 		}
 
@@ -60,15 +64,14 @@ func IsCommitStatusContextSuccess(commitStatuses []*models.CommitStatus, require
 	}
 
 	for _, ctx := range requiredContexts {
+		pattern := models.CompileStatusCheckPattern(ctx)
 		var found bool
 		for _, commitStatus := range commitStatuses {
-			if commitStatus.Context == ctx {
+			if pattern.Match(commitStatus.Context) {
 				if commitStatus.State != structs.CommitStatusSuccess {
 					return false
 				}
-
 				found = true
-				break
 			}
 		}
 		if !found {
@@ -0,0 +1,41 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeRequiredContextsCommitStatus(t *testing.T) {
+	statuses := []*models.CommitStatus{
+		{Context: "ci/build-linux", State: structs.CommitStatusSuccess},
+		{Context: "ci/build-windows", State: structs.CommitStatusFailure},
+		{Context: "ci/test", State: structs.CommitStatusSuccess},
+		{Context: "lint", State: structs.CommitStatusPending},
+	}
+
+	cases := []struct {
+		contexts []string
+		state    structs.CommitStatusState
+		success  bool
+	}{
+		{[]string{"ci/test"}, structs.CommitStatusSuccess, true},
+		{[]string{"ci/build-linux", "ci/test"}, structs.CommitStatusSuccess, true},
+		{[]string{"ci/build-*"}, structs.CommitStatusFailure, false},
+		{[]string{"ci/*"}, structs.CommitStatusFailure, false},
+		{[]string{"ci/t*"}, structs.CommitStatusSuccess, true},
+		{[]string{"ci/test", "lint"}, structs.CommitStatusPending, false},
+		{[]string{"deploy/*"}, structs.CommitStatusPending, false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.state, MergeRequiredContextsCommitStatus(statuses, c.contexts), "contexts: %v", c.contexts)
+		assert.Equal(t, c.success, IsCommitStatusContextSuccess(statuses, c.contexts), "contexts: %v", c.contexts)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/notification"
+)
+
+// ConvertToIssue closes the given pull request and opens a new issue in the same
+// repository carrying over its title, content, labels, milestone, assignees and
+// participants. Both sides get a timeline event referencing the other one.
+// Nothing is changed if any of it fails.
+func ConvertToIssue(ctx context.Context, pr *models.PullRequest, doer *models.User) (*models.Issue, error) {
+	if pr.HasMerged {
+		return nil, models.ErrPullRequestHasMerged{
+			ID:         pr.ID,
+			IssueID:    pr.IssueID,
+			HeadRepoID: pr.HeadRepoID,
+			BaseRepoID: pr.BaseRepoID,
+			HeadBranch: pr.HeadBranch,
+			BaseBranch: pr.BaseBranch,
+		}
+	}
+
+	if err := pr.LoadIssue(); err != nil {
+		return nil, err
+	}
+	pull := pr.Issue
+	if pull.IsClosed {
+		return nil, models.ErrPullWasClosed{ID: pull.ID, Index: pull.Index}
+	}
+	if err := pull.LoadAttributes(); err != nil {
+		return nil, err
+	}
+
+	participantIDs, err := models.GetParticipantsIDsByIssueID(pull.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetParticipantsIDsByIssueID: %v", err)
+	}
+	watcherIDs, err := models.GetIssueWatchersIDs(pull.ID, true)
+	if err != nil {
+		return nil, fmt.Errorf("GetIssueWatchersIDs: %v", err)
+	}
+
+	labelIDs := make([]int64, 0, len(pull.Labels))
+	for _, label := range pull.Labels {
+		labelIDs = append(labelIDs, label.ID)
+	}
+	assigneeIDs := make([]int64, 0, len(pull.Assignees))
+	for _, assignee := range pull.Assignees {
+		assigneeIDs = append(assigneeIDs, assignee.ID)
+	}
+
+	issue := &models.Issue{
+		RepoID:      pull.RepoID,
+		Repo:        pull.Repo,
+		Title:       pull.Title,
+		PosterID:    pull.PosterID,
+		Poster:      pull.Poster,
+		MilestoneID: pull.MilestoneID,
+		Content:     pull.Content,
+	}
+	result, err := models.ConvertPullRequestToIssue(models.ConvertPullRequestOptions{
+		Doer:        doer,
+		Pull:        pull,
+		Issue:       issue,
+		LabelIDs:    labelIDs,
+		AssigneeIDs: assigneeIDs,
+		WatcherIDs:  append(participantIDs, watcherIDs...),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	notification.NotifyNewIssue(ctx, issue, result.Mentions)
+	if len(issue.Labels) > 0 {
+		notification.NotifyIssueChangeLabels(ctx, issue.Poster, issue, issue.Labels, nil)
+	}
+	if issue.Milestone != nil {
+		notification.NotifyIssueChangeMilestone(ctx, issue.Poster, issue, 0)
+	}
+	for _, comment := range result.AssigneeComments {
+		assignee, err := models.GetUserByID(comment.AssigneeID)
+		if err != nil {
+			return nil, err
+		}
+		notification.NotifyIssueChangeAssignee(ctx, issue.Poster, issue, assignee, false, comment)
+	}
+	notification.NotifyIssueChangeStatus(ctx, doer, pull, result.CloseComment, true)
+
+	return issue, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertToIssue(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+	// merged pull requests cannot be converted
+	pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 1}).(*models.PullRequest)
+	_, err := ConvertToIssue(context.Background(), pr, doer)
+	assert.True(t, models.IsErrPullRequestHasMerged(err))
+
+	pr = models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 2}).(*models.PullRequest)
+	assert.NoError(t, models.CreateOrUpdateIssueWatch(9, pr.IssueID, true))
+	issue, err := ConvertToIssue(context.Background(), pr, doer)
+	assert.NoError(t, err)
+
+	pull := models.AssertExistsAndLoadBean(t, &models.Issue{ID: pr.IssueID}).(*models.Issue)
+	assert.True(t, pull.IsClosed)
+
+	issue = models.AssertExistsAndLoadBean(t, &models.Issue{ID: issue.ID}).(*models.Issue)
+	assert.False(t, issue.IsPull)
+	assert.False(t, issue.IsClosed)
+	assert.EqualValues(t, pull.Title, issue.Title)
+	assert.EqualValues(t, pull.Content, issue.Content)
+	assert.EqualValues(t, pull.PosterID, issue.PosterID)
+	assert.EqualValues(t, pull.MilestoneID, issue.MilestoneID)
+
+	models.AssertExistsAndLoadBean(t, &models.IssueWatch{UserID: 9, IssueID: issue.ID, IsWatching: true})
+	models.AssertExistsAndLoadBean(t, &models.Comment{
+		Type:       models.CommentTypeConvertToIssue,
+		IssueID:    pull.ID,
+		RefIssueID: issue.ID,
+	})
+	models.AssertExistsAndLoadBean(t, &models.Comment{
+		Type:       models.CommentTypeConvertFromPull,
+		IssueID:    issue.ID,
+		RefIssueID: pull.ID,
+	})
+
+	// a closed pull request cannot be converted twice
+	_, err = ConvertToIssue(context.Background(), pr, doer)
+	assert.True(t, models.IsErrPullWasClosed(err))
+}
+
+func TestConvertToIssueRollback(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 2}).(*models.PullRequest)
+	assert.NoError(t, pr.LoadIssue())
+	// the assignee cannot be assigned to the new issue, since they have no access to the repository
+	_, _, err := pr.Issue.ToggleAssignee(doer, 4)
+	assert.NoError(t, err)
+	numIssues := models.GetCount(t, &models.Issue{RepoID: pr.Issue.RepoID})
+
+	_, err = ConvertToIssue(context.Background(), pr, doer)
+	assert.True(t, models.IsErrUserDoesNotHaveAccessToRepo(err))
+
+	// nothing has been created and the pull request is still open
+	assert.EqualValues(t, numIssues, models.GetCount(t, &models.Issue{RepoID: pr.Issue.RepoID}))
+	pull := models.AssertExistsAndLoadBean(t, &models.Issue{ID: pr.IssueID}).(*models.Issue)
+	assert.False(t, pull.IsClosed)
+	models.AssertNotExistsBean(t, &models.Comment{Type: models.CommentTypeConvertToIssue, IssueID: pr.IssueID})
+}
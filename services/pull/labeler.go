@@ -0,0 +1,282 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	issue_service "code.gitea.io/gitea/services/issue"
+
+	"github.com/gobwas/glob"
+	"gopkg.in/yaml.v2"
+)
+
+// LabelerFiles are the locations of the labeler file, the first one found is used
+var LabelerFiles = []string{".gitea/labeler.yml", ".gitea/labeler.yaml", ".github/labeler.yml", ".github/labeler.yaml"}
+
+// labelerMaxSize is the maximum size of a labeler file which is parsed
+const labelerMaxSize = 1024 * 1024
+
+// LabelerRule represents a label of a labeler file and the paths of the files it is applied for
+type LabelerRule struct {
+	Label string
+	// Patterns are globs relative to the repository root, patterns prefixed with ! exclude the matching files
+	Patterns []string
+
+	include []glob.Glob
+	exclude []glob.Glob
+}
+
+// Match returns true if the path matches one of the patterns and none of the excluding patterns
+func (rule *LabelerRule) Match(path string) bool {
+	for _, g := range rule.exclude {
+		if g.Match(path) {
+			return false
+		}
+	}
+	for _, g := range rule.include {
+		if g.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchAny returns true if the rule matches one of the paths
+func (rule *LabelerRule) MatchAny(paths []string) bool {
+	for _, path := range paths {
+		if rule.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileLabelerPattern compiles a pattern relative to the repository root,
+// a leading **/ also matches the files in the root
+func compileLabelerPattern(pattern string) ([]glob.Glob, error) {
+	pattern = strings.TrimPrefix(pattern, "/")
+	exprs := []string{pattern}
+	if strings.HasPrefix(pattern, "**/") {
+		exprs = append(exprs, strings.TrimPrefix(pattern, "**/"))
+	}
+	globs := make([]glob.Glob, 0, len(exprs))
+	for _, expr := range exprs {
+		g, err := glob.Compile(expr, '/')
+		if err != nil {
+			return nil, err
+		}
+		globs = append(globs, g)
+	}
+	return globs, nil
+}
+
+// ParseLabeler parses the content of a labeler file, which maps label names to a glob or a list
+// of globs. Labels which cannot be parsed are skipped and returned as warnings.
+func ParseLabeler(content []byte) ([]*LabelerRule, []string, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, nil, err
+	}
+
+	var rules []*LabelerRule
+	var warnings []string
+	for _, item := range doc {
+		rule := &LabelerRule{Label: fmt.Sprint(item.Key)}
+		switch v := item.Value.(type) {
+		case string:
+			rule.Patterns = []string{v}
+		case []interface{}:
+			for _, p := range v {
+				s, ok := p.(string)
+				if !ok {
+					rule.Patterns = nil
+					break
+				}
+				rule.Patterns = append(rule.Patterns, s)
+			}
+		}
+		if len(rule.Patterns) == 0 {
+			warnings = append(warnings, fmt.Sprintf("label %q: expected a glob or a list of globs", rule.Label))
+			continue
+		}
+
+		valid := true
+		for _, pattern := range rule.Patterns {
+			exclude := strings.HasPrefix(pattern, "!")
+			globs, err := compileLabelerPattern(strings.TrimPrefix(pattern, "!"))
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("label %q: invalid pattern %q: %v", rule.Label, pattern, err))
+				valid = false
+				break
+			}
+			if exclude {
+				rule.exclude = append(rule.exclude, globs...)
+			} else {
+				rule.include = append(rule.include, globs...)
+			}
+		}
+		if valid {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, warnings, nil
+}
+
+// GetLabelerRules returns the rules of the labeler file of the commit.
+// It returns no rules if the commit has no labeler file.
+func GetLabelerRules(commit *git.Commit) ([]*LabelerRule, error) {
+	for _, name := range LabelerFiles {
+		entry, err := commit.GetTreeEntryByPath(name)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if entry.IsDir() {
+			continue
+		}
+
+		r, err := entry.Blob().DataAsync()
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		content, err := ioutil.ReadAll(io.LimitReader(r, labelerMaxSize))
+		if err != nil {
+			return nil, err
+		}
+
+		rules, warnings, err := ParseLabeler(content)
+		if err != nil {
+			log.Debug("%s of commit %s: %v", name, commit.ID, err)
+			return nil, nil
+		}
+		for _, warning := range warnings {
+			log.Debug("%s of commit %s: %s", name, commit.ID, warning)
+		}
+		return rules, nil
+	}
+	return nil, nil
+}
+
+// getLabelByName returns the label of the repository or of its organization with the name, nil if there is none
+func getLabelByName(repo *models.Repository, name string) (*models.Label, error) {
+	label, err := models.GetLabelInRepoByName(repo.ID, name)
+	if err == nil {
+		return label, nil
+	} else if !models.IsErrRepoLabelNotExist(err) {
+		return nil, err
+	}
+	if !repo.Owner.IsOrganization() {
+		return nil, nil
+	}
+	label, err = models.GetLabelInOrgByName(repo.OwnerID, name)
+	if err == nil {
+		return label, nil
+	} else if !models.IsErrOrgLabelNotExist(err) {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// SyncLabelerLabels applies the labels of the labeler file of the base branch whose patterns match
+// the files changed by the pull request, and removes the labels of the file which match none of them.
+// Labels which are not in the labeler file are left alone.
+func SyncLabelerLabels(ctx context.Context, pr *models.PullRequest) error {
+	if err := pr.LoadIssue(); err != nil {
+		return fmt.Errorf("LoadIssue: %v", err)
+	}
+	if pr.Issue.IsClosed || pr.HasMerged {
+		return nil
+	}
+	if err := pr.LoadBaseRepo(); err != nil {
+		return fmt.Errorf("LoadBaseRepo: %v", err)
+	}
+	if err := pr.BaseRepo.GetOwner(); err != nil {
+		return fmt.Errorf("GetOwner: %v", err)
+	}
+
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	baseCommit, err := gitRepo.GetBranchCommit(pr.BaseBranch)
+	if err != nil {
+		return fmt.Errorf("GetBranchCommit: %v", err)
+	}
+	rules, err := GetLabelerRules(baseCommit)
+	if err != nil {
+		return fmt.Errorf("GetLabelerRules: %v", err)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	files, err := getChangedFiles(gitRepo, pr)
+	if err != nil {
+		return err
+	}
+
+	pr.Issue.Repo = pr.BaseRepo
+	if err := pr.Issue.LoadLabels(); err != nil {
+		return fmt.Errorf("LoadLabels: %v", err)
+	}
+	current := make(map[int64]bool, len(pr.Issue.Labels))
+	for _, label := range pr.Issue.Labels {
+		current[label.ID] = true
+	}
+
+	var add, remove []*models.Label
+	for _, rule := range rules {
+		label, err := getLabelByName(pr.BaseRepo, rule.Label)
+		if err != nil {
+			return fmt.Errorf("getLabelByName: %v", err)
+		}
+		if label == nil {
+			log.Debug("Label %q of the labeler file of %s does not exist", rule.Label, pr.BaseRepo.FullName())
+			continue
+		}
+		match := rule.MatchAny(files)
+		if match && !current[label.ID] {
+			add = append(add, label)
+			current[label.ID] = true
+		} else if !match && current[label.ID] {
+			remove = append(remove, label)
+			current[label.ID] = false
+		}
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		return nil
+	}
+
+	if err := pr.Issue.LoadPoster(); err != nil {
+		return fmt.Errorf("LoadPoster: %v", err)
+	}
+	if len(add) > 0 {
+		if err := issue_service.AddLabels(ctx, pr.Issue, pr.Issue.Poster, add); err != nil {
+			return fmt.Errorf("AddLabels: %v", err)
+		}
+	}
+	for _, label := range remove {
+		if err := models.DeleteIssueLabel(pr.Issue, label, pr.Issue.Poster); err != nil {
+			return fmt.Errorf("DeleteIssueLabel: %v", err)
+		}
+	}
+	if len(remove) > 0 {
+		notification.NotifyIssueChangeLabels(ctx, pr.Issue.Poster, pr.Issue, nil, remove)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLabeler(t *testing.T) {
+	rules, warnings, err := ParseLabeler([]byte(`backend: "**/*.go"
+docs:
+  - docs/**
+  - "*.md"
+frontend:
+  - /web_src/**
+  - "!web_src/fomantic/**"
+invalid:
+  any: ["*"]
+broken: "[a"
+`))
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 2)
+	if !assert.Len(t, rules, 3) {
+		return
+	}
+	assert.Equal(t, "backend", rules[0].Label)
+	assert.Equal(t, []string{"docs/**", "*.md"}, rules[1].Patterns)
+
+	cases := []struct {
+		rule  int
+		path  string
+		match bool
+	}{
+		{0, "main.go", true},
+		{0, "models/user.go", true},
+		{0, "go.mod", false},
+		{1, "docs/content/index.md", true},
+		{1, "README.md", true},
+		// * does not match across directories
+		{1, "models/README.md", false},
+		{2, "web_src/js/index.js", true},
+		{2, "web_src/fomantic/build.js", false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.match, rules[c.rule].Match(c.path), "%s %s", rules[c.rule].Label, c.path)
+	}
+	assert.True(t, rules[0].MatchAny([]string{"README.md", "cmd/web.go"}))
+	assert.False(t, rules[0].MatchAny(nil))
+
+	_, _, err = ParseLabeler([]byte("- not a map"))
+	assert.Error(t, err)
+}
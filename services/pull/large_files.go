@@ -0,0 +1,24 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// GetLargeFiles returns the files above the configured size which the commits of a pull request add
+func GetLargeFiles(pr *models.PullRequest) ([]*git.LargeFile, error) {
+	if setting.Repository.LargeFile.MaxSize <= 0 || pr.MergeBase == "" {
+		return nil, nil
+	}
+	if err := pr.LoadBaseRepo(); err != nil {
+		return nil, err
+	}
+
+	threshold := setting.Repository.LargeFile.MaxSize * 1024 * 1024
+	return git.FindLargeFiles(pr.BaseRepo.RepoPath(), nil, threshold, pr.GetGitRefName(), "^"+pr.MergeBase)
+}
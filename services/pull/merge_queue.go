@@ -0,0 +1,438 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/sync"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// mergeQueue is the queue of "repoID:branch" keys whose merge queues need to be processed
+var mergeQueue queue.UniqueQueue
+
+// mergeQueuePool makes sure a merge queue is only processed by one worker at a time
+var mergeQueuePool = sync.NewExclusivePool()
+
+func mergeQueueKey(repoID int64, branch string) string {
+	return strconv.FormatInt(repoID, 10) + ":" + branch
+}
+
+// checkMergeQueue schedules the merge queue of a branch to be processed
+func checkMergeQueue(repoID int64, branch string) {
+	if mergeQueue == nil {
+		return
+	}
+	if err := mergeQueue.Push(mergeQueueKey(repoID, branch)); err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Unable to push %d:%s to the merge queue: %v", repoID, branch, err)
+	}
+}
+
+// CheckMergeQueueForCommit schedules the merge queue that is testing the given commit to be processed.
+// It is called when the status of a commit changes.
+func CheckMergeQueueForCommit(repoID int64, sha string) {
+	entry, err := models.GetMergeQueueEntryByTestCommitID(repoID, sha)
+	if err != nil {
+		if !models.IsErrMergeQueueEntryNotExist(err) {
+			log.Error("GetMergeQueueEntryByTestCommitID[%d, %s]: %v", repoID, sha, err)
+		}
+		return
+	}
+	checkMergeQueue(entry.RepoID, entry.BaseBranch)
+}
+
+// CheckMergeQueues schedules all non-empty merge queues to be processed
+func CheckMergeQueues(ctx context.Context) error {
+	branches, err := models.GetMergeQueueBranches()
+	if err != nil {
+		return err
+	}
+	for _, branch := range branches {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Aborted due to shutdown")
+		default:
+		}
+		checkMergeQueue(branch.RepoID, branch.BaseBranch)
+	}
+	return nil
+}
+
+// AddToMergeQueue adds a pull request to the merge queue of its base branch.
+// Caller should check PR is ready to be merged (review and status checks)
+func AddToMergeQueue(pr *models.PullRequest, doer *models.User, mergeStyle models.MergeStyle, message string) error {
+	if err := pr.LoadBaseRepo(); err != nil {
+		return fmt.Errorf("LoadBaseRepo: %v", err)
+	}
+
+	prUnit, err := pr.BaseRepo.GetUnit(models.UnitTypePullRequests)
+	if err != nil {
+		return err
+	}
+	if !prUnit.PullRequestsConfig().IsMergeStyleAllowed(mergeStyle) {
+		return models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: mergeStyle}
+	}
+
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		return fmt.Errorf("GetRefCommitID: %v", err)
+	}
+
+	if err := models.AddToMergeQueue(&models.MergeQueueEntry{
+		PullID:       pr.ID,
+		RepoID:       pr.BaseRepoID,
+		BaseBranch:   pr.BaseBranch,
+		DoerID:       doer.ID,
+		MergeStyle:   mergeStyle,
+		Message:      message,
+		HeadCommitID: headCommitID,
+	}); err != nil {
+		return err
+	}
+
+	if err := pr.LoadIssue(); err != nil {
+		return fmt.Errorf("LoadIssue: %v", err)
+	}
+	if _, err := models.CreateComment(&models.CreateCommentOptions{
+		Type:  models.CommentTypeAddedToMergeQueue,
+		Doer:  doer,
+		Repo:  pr.BaseRepo,
+		Issue: pr.Issue,
+	}); err != nil {
+		return fmt.Errorf("CreateComment: %v", err)
+	}
+
+	checkMergeQueue(pr.BaseRepoID, pr.BaseBranch)
+	return nil
+}
+
+// RemoveFromMergeQueue removes a pull request from the merge queue of its base branch
+func RemoveFromMergeQueue(pr *models.PullRequest, doer *models.User) error {
+	key := mergeQueueKey(pr.BaseRepoID, pr.BaseBranch)
+	mergeQueuePool.CheckIn(key)
+	defer mergeQueuePool.CheckOut(key)
+
+	entry, err := models.GetMergeQueueEntryByPullID(pr.ID)
+	if err != nil {
+		return err
+	}
+	entry.Pull = pr
+	if err := removeFromMergeQueue(entry, doer, ""); err != nil {
+		return err
+	}
+
+	checkMergeQueue(entry.RepoID, entry.BaseBranch)
+	return nil
+}
+
+// removeFromMergeQueue removes an entry from the merge queue and records the reason on the pull request
+func removeFromMergeQueue(entry *models.MergeQueueEntry, doer *models.User, reason string) error {
+	if err := models.RemoveFromMergeQueue(entry); err != nil {
+		return err
+	}
+	if err := models.AddMergeQueueResult(entry.NewResult(doer.ID, false, reason)); err != nil {
+		return fmt.Errorf("AddMergeQueueResult: %v", err)
+	}
+	deleteMergeQueueTestBranch(entry)
+
+	pr := entry.Pull
+	if err := pr.LoadBaseRepo(); err != nil {
+		return fmt.Errorf("LoadBaseRepo: %v", err)
+	}
+	if err := pr.LoadIssue(); err != nil {
+		return fmt.Errorf("LoadIssue: %v", err)
+	}
+	if _, err := models.CreateComment(&models.CreateCommentOptions{
+		Type:    models.CommentTypeRemovedFromMergeQueue,
+		Doer:    doer,
+		Repo:    pr.BaseRepo,
+		Issue:   pr.Issue,
+		Content: reason,
+	}); err != nil {
+		return fmt.Errorf("CreateComment: %v", err)
+	}
+	return nil
+}
+
+func deleteMergeQueueTestBranch(entry *models.MergeQueueEntry) {
+	if entry.TestCommitID == "" {
+		return
+	}
+	if err := entry.Pull.LoadBaseRepo(); err != nil {
+		log.Error("LoadBaseRepo: %v", err)
+		return
+	}
+	branch := entry.TestBranchName()
+	if _, err := git.NewCommand("branch", "-D", branch).SetDescription(fmt.Sprintf("deleteMergeQueueTestBranch: %s", branch)).RunInDirTimeout(-1, entry.Pull.BaseRepo.RepoPath()); err != nil {
+		log.Error("Unable to delete merge queue test branch %s in %-v: %v", branch, entry.Pull.BaseRepo, err)
+	}
+}
+
+func handleMergeQueue(data ...queue.Data) {
+	ctx := graceful.GetManager().ShutdownContext()
+	for _, datum := range data {
+		key := datum.(string)
+		fields := strings.SplitN(key, ":", 2)
+		if len(fields) != 2 {
+			log.Error("Invalid merge queue key: %s", key)
+			continue
+		}
+		repoID, _ := strconv.ParseInt(fields[0], 10, 64)
+		if err := processMergeQueue(ctx, repoID, fields[1]); err != nil {
+			log.Error("Unable to process the merge queue of branch %s in repository %d: %v", fields[1], repoID, err)
+		}
+	}
+}
+
+// processMergeQueue merges the pull requests at the head of the merge queue of a branch
+// until it is empty or the head entry is waiting for its status checks
+func processMergeQueue(ctx context.Context, repoID int64, branch string) error {
+	key := mergeQueueKey(repoID, branch)
+	mergeQueuePool.CheckIn(key)
+	defer mergeQueuePool.CheckOut(key)
+	defer notifyMergeTrainChanged(repoID, branch)
+
+	for {
+		entries, err := models.GetMergeQueue(repoID, branch)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+
+		again, err := processMergeQueueEntry(ctx, entries[0])
+		if err != nil || !again {
+			return err
+		}
+	}
+}
+
+// processMergeQueueEntry tests and merges the entry at the head of a merge queue.
+// It returns true if the head of the queue has to be processed again.
+func processMergeQueueEntry(ctx context.Context, entry *models.MergeQueueEntry) (bool, error) {
+	if err := entry.LoadAttributes(); err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			return true, models.RemoveFromMergeQueue(entry)
+		}
+		return false, err
+	}
+	pr, doer := entry.Pull, entry.Doer
+
+	if err := pr.LoadIssue(); err != nil {
+		return false, fmt.Errorf("LoadIssue: %v", err)
+	}
+	if pr.HasMerged {
+		deleteMergeQueueTestBranch(entry)
+		return true, models.RemoveFromMergeQueue(entry)
+	} else if pr.Issue.IsClosed {
+		return true, removeFromMergeQueue(entry, doer, "The pull request has been closed.")
+	}
+
+	if err := pr.LoadProtectedBranch(); err != nil {
+		return false, fmt.Errorf("LoadProtectedBranch: %v", err)
+	}
+	if pr.ProtectedBranch == nil || !pr.ProtectedBranch.EnableMergeQueue {
+		return true, removeFromMergeQueue(entry, doer, "The merge queue has been disabled for the base branch.")
+	}
+
+	if err := pr.LoadBaseRepo(); err != nil {
+		return false, fmt.Errorf("LoadBaseRepo: %v", err)
+	}
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return false, fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		return false, fmt.Errorf("GetRefCommitID: %v", err)
+	}
+	if headCommitID != entry.HeadCommitID {
+		return true, removeFromMergeQueue(entry, doer, "New commits have been pushed to the pull request.")
+	}
+
+	// The doer or the reviews may have changed since the pull request was added to the queue
+	if reason, err := checkMergeQueueEntryAllowed(entry); err != nil {
+		return false, err
+	} else if reason != "" {
+		return true, removeFromMergeQueue(entry, doer, reason)
+	}
+
+	baseCommitID, err := gitRepo.GetBranchCommitID(entry.BaseBranch)
+	if err != nil {
+		return false, fmt.Errorf("GetBranchCommitID: %v", err)
+	}
+
+	// (Re)build the test commit on top of the current head of the base branch
+	if entry.Status != models.MergeQueueStatusTesting || entry.BaseCommitID != baseCommitID {
+		if err := pr.LoadHeadRepo(); err != nil {
+			return false, fmt.Errorf("LoadHeadRepo: %v", err)
+		}
+		testCommitID, err := rawMergeTo(git.DefaultContext, pr, doer, entry.MergeStyle, entry.Message, entry.TestBranchName())
+		if err != nil {
+			if models.IsErrMergeConflicts(err) || models.IsErrRebaseConflicts(err) || models.IsErrMergeUnrelatedHistories(err) {
+				return true, removeFromMergeQueue(entry, doer, "The pull request can not be merged into the base branch without conflicts.")
+			} else if models.IsErrMergeDivergingFastForwardOnly(err) {
+				return true, removeFromMergeQueue(entry, doer, "The base branch can not be fast-forwarded to the pull request.")
+			}
+			return false, err
+		}
+		entry.Status = models.MergeQueueStatusTesting
+		entry.BaseCommitID = baseCommitID
+		entry.TestCommitID = testCommitID
+		if err := models.UpdateMergeQueueEntry(entry); err != nil {
+			return false, err
+		}
+		entry.UpdatedUnix = timeutil.TimeStampNow()
+	}
+
+	state, err := getMergeQueueTestState(pr.ProtectedBranch, entry)
+	if err != nil {
+		return false, err
+	}
+	if state.IsPending() {
+		if time.Since(entry.UpdatedUnix.AsTime()) > setting.Repository.PullRequest.MergeQueueCheckTimeout {
+			return true, removeFromMergeQueue(entry, doer, "The required status checks did not finish in time.")
+		}
+		return false, nil
+	} else if !state.IsSuccess() {
+		return true, removeFromMergeQueue(entry, doer, "The required status checks failed.")
+	}
+
+	// All checks passed: fast-forward the base branch to the test commit
+	if err := pushMergeQueueEntry(entry); err != nil {
+		if git.IsErrPushOutOfDate(err) {
+			// The base branch has moved since the test commit was built
+			entry.Status = models.MergeQueueStatusWaiting
+			return true, models.UpdateMergeQueueEntry(entry)
+		} else if git.IsErrPushRejected(err) {
+			return true, removeFromMergeQueue(entry, doer, fmt.Sprintf("The merge was rejected: %s", err.(*git.ErrPushRejected).Message))
+		}
+		return false, err
+	}
+
+	if err := models.RemoveFromMergeQueue(entry); err != nil {
+		return false, err
+	}
+	if err := models.AddMergeQueueResult(entry.NewResult(doer.ID, true, "")); err != nil {
+		return false, fmt.Errorf("AddMergeQueueResult: %v", err)
+	}
+	deleteMergeQueueTestBranch(entry)
+
+	defer func() {
+		go AddTestPullRequestTask(ctx, doer, pr.BaseRepo.ID, pr.BaseBranch, false, "", "")
+	}()
+
+	pr.MergedCommitID = entry.TestCommitID
+	return true, finishMerge(ctx, pr, doer)
+}
+
+// checkMergeQueueEntryAllowed checks whether the doer of an entry is still allowed to merge the pull request
+// and the pull request is still ready to be merged. It returns the reason why the entry can not be merged if not.
+func checkMergeQueueEntryAllowed(entry *models.MergeQueueEntry) (string, error) {
+	pr, doer := entry.Pull, entry.Doer
+	if err := pr.LoadBaseRepo(); err != nil {
+		return "", fmt.Errorf("LoadBaseRepo: %v", err)
+	}
+	if err := pr.LoadHeadRepo(); err != nil {
+		return "", fmt.Errorf("LoadHeadRepo: %v", err)
+	}
+
+	perm, err := models.GetUserRepoPermission(pr.BaseRepo, doer)
+	if err != nil {
+		return "", fmt.Errorf("GetUserRepoPermission: %v", err)
+	}
+	if allowed, err := IsUserAllowedToMerge(pr, perm, doer); err != nil {
+		return "", fmt.Errorf("IsUserAllowedToMerge: %v", err)
+	} else if !allowed {
+		return fmt.Sprintf("%s is not allowed to merge the pull request anymore.", doer.Name), nil
+	}
+
+	if err := CheckPRReadyToMerge(pr, false); err != nil {
+		if models.IsErrNotAllowedToMerge(err) {
+			return fmt.Sprintf("The pull request can not be merged anymore: %s.", err.(models.ErrNotAllowedToMerge).Reason), nil
+		}
+		return "", err
+	}
+	return "", nil
+}
+
+// getMergeQueueTestState returns the combined state of the required status checks of the test commit.
+// The state is pending as long as no status has been reported if status checks are enabled.
+func getMergeQueueTestState(protectedBranch *models.ProtectedBranch, entry *models.MergeQueueEntry) (api.CommitStatusState, error) {
+	if !protectedBranch.EnableStatusCheck {
+		return api.CommitStatusSuccess, nil
+	}
+
+	statuses, err := models.GetLatestCommitStatus(entry.RepoID, entry.TestCommitID, models.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("GetLatestCommitStatus: %v", err)
+	}
+	if len(statuses) == 0 {
+		return api.CommitStatusPending, nil
+	}
+	return MergeRequiredContextsCommitStatus(statuses, protectedBranch.StatusCheckContexts), nil
+}
+
+// pushMergeQueueEntry pushes the tested commit of an entry to its base branch
+func pushMergeQueueEntry(entry *models.MergeQueueEntry) error {
+	pr, doer := entry.Pull, entry.Doer
+	if err := pr.LoadHeadRepo(); err != nil {
+		return fmt.Errorf("LoadHeadRepo: %v", err)
+	}
+	headUser, err := getHeadUser(pr, doer)
+	if err != nil {
+		return err
+	}
+
+	env := models.FullPushingEnvironment(
+		headUser,
+		doer,
+		pr.BaseRepo,
+		pr.BaseRepo.Name,
+		pr.ID,
+	)
+
+	var outbuf, errbuf strings.Builder
+	if err := git.NewCommand("push", ".", entry.TestCommitID+":refs/heads/"+entry.BaseBranch).RunInDirTimeoutEnvPipeline(env, -1, pr.BaseRepo.RepoPath(), &outbuf, &errbuf); err != nil {
+		return convertPushError(err, outbuf.String(), errbuf.String())
+	}
+	return nil
+}
+
+func initMergeQueue() error {
+	mergeQueue = queue.CreateUniqueQueue("pr_merge_queue", handleMergeQueue, "").(queue.UniqueQueue)
+	if mergeQueue == nil {
+		return fmt.Errorf("Unable to create pr_merge_queue Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(mergeQueue.Run)
+	go graceful.GetManager().RunWithShutdownContext(func(ctx context.Context) {
+		if err := CheckMergeQueues(ctx); err != nil {
+			log.Error("CheckMergeQueues: %v", err)
+		}
+	})
+	return nil
+}
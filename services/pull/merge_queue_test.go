@@ -0,0 +1,88 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddToMergeQueue(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 2}).(*models.PullRequest)
+
+	err := AddToMergeQueue(pr, doer, models.MergeStyle("unknown"), "")
+	assert.True(t, models.IsErrInvalidMergeStyle(err))
+
+	assert.NoError(t, AddToMergeQueue(pr, doer, models.MergeStyleMerge, "Merge"))
+	entry := models.AssertExistsAndLoadBean(t, &models.MergeQueueEntry{PullID: pr.ID}).(*models.MergeQueueEntry)
+	assert.EqualValues(t, "master", entry.BaseBranch)
+	assert.EqualValues(t, doer.ID, entry.DoerID)
+	assert.Equal(t, models.MergeQueueStatusWaiting, entry.Status)
+	assert.Len(t, entry.HeadCommitID, 40)
+	models.AssertExistsAndLoadBean(t, &models.Comment{Type: models.CommentTypeAddedToMergeQueue, IssueID: pr.IssueID})
+
+	err = AddToMergeQueue(pr, doer, models.MergeStyleMerge, "Merge")
+	assert.True(t, models.IsErrPullAlreadyInMergeQueue(err))
+
+	assert.NoError(t, RemoveFromMergeQueue(pr, doer))
+	models.AssertNotExistsBean(t, &models.MergeQueueEntry{PullID: pr.ID})
+	models.AssertExistsAndLoadBean(t, &models.Comment{Type: models.CommentTypeRemovedFromMergeQueue, IssueID: pr.IssueID})
+	result := models.AssertExistsAndLoadBean(t, &models.MergeQueueResult{PullID: pr.ID}).(*models.MergeQueueResult)
+	assert.False(t, result.IsMerged)
+	assert.EqualValues(t, doer.ID, result.DoerID)
+
+	err = RemoveFromMergeQueue(pr, doer)
+	assert.True(t, models.IsErrMergeQueueEntryNotExist(err))
+}
+
+func TestCheckMergeQueueEntryAllowed(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	check := func(doerID int64) string {
+		entry := &models.MergeQueueEntry{
+			PullID: 2,
+			Pull:   models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 2}).(*models.PullRequest),
+			Doer:   models.AssertExistsAndLoadBean(t, &models.User{ID: doerID}).(*models.User),
+		}
+		reason, err := checkMergeQueueEntryAllowed(entry)
+		assert.NoError(t, err)
+		return reason
+	}
+
+	assert.Empty(t, check(2))
+	// user5 can only read the repository
+	assert.Equal(t, "user5 is not allowed to merge the pull request anymore.", check(5))
+
+	// the pull request has an official review requesting changes
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.NoError(t, models.UpdateProtectBranch(repo, &models.ProtectedBranch{
+		RepoID:                 repo.ID,
+		BranchName:             "master",
+		BlockOnRejectedReviews: true,
+	}, models.WhitelistOptions{}))
+	assert.Equal(t, "The pull request can not be merged anymore: There are requested changes.", check(2))
+}
+
+func TestGetMergeQueueTestState(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	entry := &models.MergeQueueEntry{RepoID: 1, TestCommitID: "0000000000000000000000000000000000000000"}
+
+	state, err := getMergeQueueTestState(&models.ProtectedBranch{}, entry)
+	assert.NoError(t, err)
+	assert.Equal(t, structs.CommitStatusSuccess, state)
+
+	// no status has been reported for the test commit yet
+	state, err = getMergeQueueTestState(&models.ProtectedBranch{EnableStatusCheck: true}, entry)
+	assert.NoError(t, err)
+	assert.Equal(t, structs.CommitStatusPending, state)
+}
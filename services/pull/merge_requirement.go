@@ -0,0 +1,33 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"code.gitea.io/gitea/models"
+)
+
+// SetMergeRequirement registers a merge requirement on a pull request or changes its state, and returns whether it has
+// been registered. The scheduled merge of the pull request is checked when the requirement is satisfied.
+func SetMergeRequirement(pr *models.PullRequest, r *models.PullMergeRequirement) (bool, error) {
+	r.RepoID = pr.BaseRepoID
+	r.PullID = pr.ID
+	created, err := models.SetPullMergeRequirement(r)
+	if err != nil {
+		return false, err
+	}
+	if r.IsSatisfied {
+		addToAutoMergeQueue(pr.ID)
+	}
+	return created, nil
+}
+
+// DeleteMergeRequirement deletes a merge requirement of a pull request, whose scheduled merge is checked
+func DeleteMergeRequirement(pr *models.PullRequest, id int64) error {
+	if err := models.DeletePullMergeRequirement(pr.ID, id); err != nil {
+		return err
+	}
+	addToAutoMergeQueue(pr.ID)
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/eventsource"
+)
+
+// MergeTrainRecentFailures is the number of recent failures shown for a merge train
+const MergeTrainRecentFailures = 10
+
+// mergeTrainEvents notifies the viewers of the merge trains of a repository, keyed by the repository ID
+var mergeTrainEvents = eventsource.NewManager()
+
+// MergeTrainEntry represents a pull request waiting in a merge train
+type MergeTrainEntry struct {
+	*models.MergeQueueEntry
+	Position int
+	// EstimatedMerge is when the pull request is expected to be merged, zero if nothing has been merged yet
+	EstimatedMerge time.Time
+}
+
+// MergeTrain represents the state of the merge queue of a branch
+type MergeTrain struct {
+	Branch  string
+	Entries []*MergeTrainEntry
+	// AverageDuration is how long the recent merges took from the start of their test until they were merged
+	AverageDuration time.Duration
+	RecentFailures  []*models.MergeQueueResult
+}
+
+// GetMergeTrain returns the pull requests waiting in the merge queue of a branch with their estimated
+// merge times, and the entries which were recently removed from it
+func GetMergeTrain(repo *models.Repository, branch string) (*MergeTrain, error) {
+	entries, err := models.GetMergeQueue(repo.ID, branch)
+	if err != nil {
+		return nil, fmt.Errorf("GetMergeQueue: %v", err)
+	}
+	avg, err := models.GetMergeQueueAverageDuration(repo.ID, branch)
+	if err != nil {
+		return nil, fmt.Errorf("GetMergeQueueAverageDuration: %v", err)
+	}
+	failures, err := models.GetMergeQueueResults(repo.ID, branch, false, MergeTrainRecentFailures)
+	if err != nil {
+		return nil, fmt.Errorf("GetMergeQueueResults: %v", err)
+	}
+
+	train := &MergeTrain{
+		Branch:          branch,
+		Entries:         make([]*MergeTrainEntry, 0, len(entries)),
+		AverageDuration: avg,
+		RecentFailures:  make([]*models.MergeQueueResult, 0, len(failures)),
+	}
+
+	// The head of the queue needs the rest of the average duration if its test has already started,
+	// every entry after it is only tested after the entries before it have been merged.
+	estimate := time.Now()
+	for i, entry := range entries {
+		if err := entry.LoadAttributes(); err != nil {
+			if models.IsErrPullRequestNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if err := entry.Pull.LoadIssue(); err != nil {
+			return nil, err
+		}
+		trainEntry := &MergeTrainEntry{MergeQueueEntry: entry, Position: len(train.Entries) + 1}
+		if avg > 0 {
+			remaining := avg
+			if i == 0 && entry.Status == models.MergeQueueStatusTesting {
+				if remaining -= time.Since(entry.UpdatedUnix.AsTime()); remaining < 0 {
+					remaining = 0
+				}
+			}
+			estimate = estimate.Add(remaining)
+			trainEntry.EstimatedMerge = estimate
+		}
+		train.Entries = append(train.Entries, trainEntry)
+	}
+
+	for _, failure := range failures {
+		if err := failure.LoadAttributes(); err != nil {
+			if models.IsErrPullRequestNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		train.RecentFailures = append(train.RecentFailures, failure)
+	}
+	return train, nil
+}
+
+// RegisterMergeTrainEvents returns a channel which receives an event with the branch name whenever
+// a merge train of the repository changes
+func RegisterMergeTrainEvents(repoID int64) <-chan *eventsource.Event {
+	return mergeTrainEvents.Register(repoID)
+}
+
+// UnregisterMergeTrainEvents unregisters a channel returned by RegisterMergeTrainEvents
+func UnregisterMergeTrainEvents(repoID int64, channel <-chan *eventsource.Event) {
+	mergeTrainEvents.Unregister(repoID, channel)
+}
+
+func notifyMergeTrainChanged(repoID int64, branch string) {
+	mergeTrainEvents.SendMessage(repoID, &eventsource.Event{
+		Name: "merge-train",
+		Data: branch,
+	})
+}
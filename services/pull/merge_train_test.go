@@ -0,0 +1,62 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetMergeTrain(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	train, err := GetMergeTrain(repo, "master")
+	assert.NoError(t, err)
+	assert.Empty(t, train.Entries)
+	assert.Empty(t, train.RecentFailures)
+	assert.Zero(t, train.AverageDuration)
+
+	first := &models.MergeQueueEntry{PullID: 2, RepoID: 1, BaseBranch: "master", DoerID: 2, MergeStyle: models.MergeStyleMerge}
+	second := &models.MergeQueueEntry{PullID: 5, RepoID: 1, BaseBranch: "master", DoerID: 2, MergeStyle: models.MergeStyleMerge}
+	assert.NoError(t, models.AddToMergeQueue(first))
+	assert.NoError(t, models.AddToMergeQueue(second))
+
+	// without a merge no times can be estimated
+	train, err = GetMergeTrain(repo, "master")
+	assert.NoError(t, err)
+	if assert.Len(t, train.Entries, 2) {
+		assert.Equal(t, 1, train.Entries[0].Position)
+		assert.EqualValues(t, 2, train.Entries[0].Pull.ID)
+		assert.True(t, train.Entries[0].EstimatedMerge.IsZero())
+		assert.Equal(t, 2, train.Entries[1].Position)
+	}
+
+	merged := &models.MergeQueueEntry{PullID: 3, RepoID: 1, BaseBranch: "master", DoerID: 2, Status: models.MergeQueueStatusTesting,
+		UpdatedUnix: timeutil.TimeStampNow() - 600}
+	result := merged.NewResult(2, true, "")
+	assert.NoError(t, models.AddMergeQueueResult(result))
+	assert.NoError(t, models.AddMergeQueueResult(second.NewResult(2, false, "The required status checks failed.")))
+
+	before := time.Now()
+	train, err = GetMergeTrain(repo, "master")
+	assert.NoError(t, err)
+	assert.InDelta(t, 600, train.AverageDuration.Seconds(), 5)
+	if assert.Len(t, train.Entries, 2) {
+		// the first entry is not being tested yet, so it needs the whole average duration
+		assert.WithinDuration(t, before.Add(train.AverageDuration), train.Entries[0].EstimatedMerge, 5*time.Second)
+		assert.WithinDuration(t, before.Add(2*train.AverageDuration), train.Entries[1].EstimatedMerge, 5*time.Second)
+	}
+	if assert.Len(t, train.RecentFailures, 1) {
+		assert.EqualValues(t, 5, train.RecentFailures[0].PullID)
+		assert.NotNil(t, train.RecentFailures[0].Pull.Issue)
+	}
+}
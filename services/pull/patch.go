@@ -51,7 +51,7 @@ var patchErrorSuffices = []string{
 // TestPatch will test whether a simple patch will apply
 func TestPatch(pr *models.PullRequest) error {
 	// Clone base repo.
-	tmpBasePath, err := createTemporaryRepo(pr)
+	tmpBasePath, err := createTemporaryRepo(git.DefaultContext, pr)
 	if err != nil {
 		log.Error("CreateTemporaryPath: %v", err)
 		return err
@@ -0,0 +1,151 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/gobwas/glob"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	// protectionPreviewPushesSince is how far back the pushes are evaluated by a protection preview
+	protectionPreviewPushesSince = 7 * 24 * time.Hour
+	// protectionPreviewMaxPushes is the maximum number of pushes evaluated by a protection preview
+	protectionPreviewMaxPushes = 50
+)
+
+// BlockedPullRequest is an open pull request whose merge would be blocked by a protection rule
+type BlockedPullRequest struct {
+	PullRequest *models.PullRequest
+	Reason      string
+}
+
+// BlockedPush is a recent push which would have been rejected by a protection rule
+type BlockedPush struct {
+	Action  *models.Action
+	Commits []*repository.PushCommit
+	Reason  string
+}
+
+// ProtectionPreview is the evaluation of a protection rule of a branch against its open pull requests and its recent
+// pushes
+type ProtectionPreview struct {
+	PullRequests        []*BlockedPullRequest
+	CheckedPullRequests int
+	Pushes              []*BlockedPush
+	CheckedPushes       int
+}
+
+// PreviewProtectedBranch evaluates which open pull requests and which recent pushes of the branch of a protection rule
+// would be blocked by the rule, without saving it. The reviews keep the official state they were given under the
+// saved rule.
+func PreviewProtectedBranch(repo *models.Repository, gitRepo *git.Repository, protectBranch *models.ProtectedBranch) (*ProtectionPreview, error) {
+	preview := &ProtectionPreview{}
+
+	prs, err := models.GetUnmergedPullRequestsByBaseInfo(repo.ID, protectBranch.BranchName)
+	if err != nil {
+		return nil, fmt.Errorf("GetUnmergedPullRequestsByBaseInfo: %v", err)
+	}
+	patterns := protectBranch.GetProtectedFilePatterns()
+	for _, pr := range prs {
+		pr.BaseRepo = repo
+		pr.ProtectedBranch = protectBranch
+		if err := pr.LoadIssue(); err != nil {
+			return nil, fmt.Errorf("LoadIssue: %v", err)
+		}
+
+		// the protected files saved with the pull request were found with the patterns of the saved rule
+		pr.ChangedProtectedFiles, err = CheckFileProtection(pr.MergeBase, pr.GetGitRefName(), patterns, 10, os.Environ(), gitRepo)
+		if err != nil && !models.IsErrFilePathProtected(err) {
+			continue
+		}
+
+		preview.CheckedPullRequests++
+		if err := CheckPRReadyToMerge(pr, false); err != nil {
+			if !models.IsErrNotAllowedToMerge(err) {
+				log.Warn("Unable to preview the protection of %s for pull request #%d: %v", protectBranch.BranchName, pr.Index, err)
+				continue
+			}
+			preview.PullRequests = append(preview.PullRequests, &BlockedPullRequest{
+				PullRequest: pr,
+				Reason:      err.(models.ErrNotAllowedToMerge).Reason,
+			})
+		}
+	}
+
+	actions, err := models.GetBranchPushActions(repo.ID, protectBranch.BranchName,
+		timeutil.TimeStamp(time.Now().Add(-protectionPreviewPushesSince).Unix()), protectionPreviewMaxPushes)
+	if err != nil {
+		return nil, fmt.Errorf("GetBranchPushActions: %v", err)
+	}
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	for _, action := range actions {
+		action.LoadActUser()
+		commits := repository.NewPushCommits()
+		if err := json.Unmarshal([]byte(action.Content), commits); err != nil {
+			log.Warn("Unable to read the commits of push action %d: %v", action.ID, err)
+			continue
+		}
+
+		preview.CheckedPushes++
+		reason, err := pushBlockedReason(gitRepo, protectBranch, action, commits.Commits, patterns)
+		if err != nil {
+			return nil, err
+		}
+		if len(reason) > 0 {
+			preview.Pushes = append(preview.Pushes, &BlockedPush{
+				Action:  action,
+				Commits: commits.Commits,
+				Reason:  reason,
+			})
+		}
+	}
+	return preview, nil
+}
+
+// pushBlockedReason returns why the pre-receive hook would have rejected a push with a protection rule, empty if it
+// would have been accepted. Only the commits kept in the action of the push are checked, and the merges of pull
+// requests are never blocked.
+func pushBlockedReason(gitRepo *git.Repository, protectBranch *models.ProtectedBranch, action *models.Action, commits []*repository.PushCommit, patterns []glob.Glob) (string, error) {
+	if len(commits) == 0 {
+		return "", nil
+	}
+	if merged, err := models.IsPullRequestMergedCommit(protectBranch.RepoID, commits[0].Sha1); err != nil {
+		return "", err
+	} else if merged {
+		return "", nil
+	}
+
+	if !protectBranch.CanUserPush(action.ActUserID) {
+		return "Not allowed to push to the branch", nil
+	}
+	for _, c := range commits {
+		commit, err := gitRepo.GetCommit(c.Sha1)
+		if err != nil {
+			// the commit may no longer exist after a force push
+			log.Debug("Unable to get commit %s of push action %d: %v", c.Sha1, action.ID, err)
+			continue
+		}
+		if protectBranch.RequireSignedCommits && !models.ParseCommitWithSignature(commit).Verified {
+			return fmt.Sprintf("Unverified commit %s", c.Sha1), nil
+		}
+		if len(patterns) > 0 && commit.ParentCount() > 0 {
+			if _, err := CheckFileProtection(c.Sha1+"^", c.Sha1, patterns, 1, os.Environ(), gitRepo); models.IsErrFilePathProtected(err) {
+				return fmt.Sprintf("Changed protected file %s", err.(models.ErrFilePathProtected).Path), nil
+			}
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviewProtectedBranch(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	for _, pusherID := range []int64{2, 5} {
+		assert.NoError(t, models.NotifyWatchers(&models.Action{
+			ActUserID: pusherID,
+			OpType:    models.ActionCommitRepo,
+			RepoID:    repo.ID,
+			RefName:   git.BranchPrefix + "master",
+			Content:   `{"Len":1,"Commits":[{"Sha1":"65f1bf27bc3bf70f64657658635e66094edbcb4d"}]}`,
+		}))
+	}
+
+	// the push of user 5, who is not whitelisted, would have been rejected
+	preview, err := PreviewProtectedBranch(repo, gitRepo, &models.ProtectedBranch{
+		RepoID:           repo.ID,
+		BranchName:       "master",
+		CanPush:          true,
+		EnableWhitelist:  true,
+		WhitelistUserIDs: []int64{2},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, preview.CheckedPullRequests)
+	assert.Empty(t, preview.PullRequests)
+	assert.Equal(t, 2, preview.CheckedPushes)
+	if assert.Len(t, preview.Pushes, 1) {
+		assert.EqualValues(t, 5, preview.Pushes[0].Action.ActUserID)
+	}
+
+	// the open pull request does not have the required approvals, and user 5 has no write access
+	preview, err = PreviewProtectedBranch(repo, gitRepo, &models.ProtectedBranch{
+		RepoID:            repo.ID,
+		BranchName:        "master",
+		CanPush:           true,
+		RequiredApprovals: 5,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, preview.PullRequests, 1) {
+		assert.EqualValues(t, 2, preview.PullRequests[0].PullRequest.ID)
+		assert.Equal(t, "Does not have enough approvals", preview.PullRequests[0].Reason)
+	}
+	if assert.Len(t, preview.Pushes, 1) {
+		assert.EqualValues(t, 5, preview.Pushes[0].Action.ActUserID)
+	}
+}
@@ -24,7 +24,7 @@ import (
 )
 
 // NewPullRequest creates new pull request with labels for repository.
-func NewPullRequest(repo *models.Repository, pull *models.Issue, labelIDs []int64, uuids []string, pr *models.PullRequest, assigneeIDs []int64) error {
+func NewPullRequest(ctx context.Context, repo *models.Repository, pull *models.Issue, labelIDs []int64, uuids []string, pr *models.PullRequest, assigneeIDs []int64) error {
 	if err := TestPatch(pr); err != nil {
 		return err
 	}
@@ -41,7 +41,7 @@ func NewPullRequest(repo *models.Repository, pull *models.Issue, labelIDs []int6
 	}
 
 	for _, assigneeID := range assigneeIDs {
-		if err := issue_service.AddAssigneeIfNotAssigned(pull, pull.Poster, assigneeID); err != nil {
+		if err := issue_service.AddAssigneeIfNotAssigned(ctx, pull, pull.Poster, assigneeID); err != nil {
 			return err
 		}
 	}
@@ -58,12 +58,22 @@ func NewPullRequest(repo *models.Repository, pull *models.Issue, labelIDs []int6
 		return err
 	}
 
-	notification.NotifyNewPullRequest(pr, mentions)
+	notification.NotifyNewPullRequest(ctx, pr, mentions)
 	if len(pull.Labels) > 0 {
-		notification.NotifyIssueChangeLabels(pull.Poster, pull, pull.Labels, nil)
+		notification.NotifyIssueChangeLabels(ctx, pull.Poster, pull, pull.Labels, nil)
 	}
 	if pull.Milestone != nil {
-		notification.NotifyIssueChangeMilestone(pull.Poster, pull, 0)
+		notification.NotifyIssueChangeMilestone(ctx, pull.Poster, pull, 0)
+	}
+
+	if err := RequestCodeOwnerReviews(ctx, pr); err != nil {
+		log.Error("RequestCodeOwnerReviews[%d]: %v", pr.ID, err)
+	}
+	if err := AutoAssignReviewers(ctx, pr); err != nil {
+		log.Error("AutoAssignReviewers[%d]: %v", pr.ID, err)
+	}
+	if err := SyncLabelerLabels(ctx, pr); err != nil {
+		log.Error("SyncLabelerLabels[%d]: %v", pr.ID, err)
 	}
 
 	// add first push codes comment
@@ -226,9 +236,9 @@ func checkForInvalidation(requests models.PullRequestList, repoID int64, doer *m
 
 // AddTestPullRequestTask adds new test tasks by given head/base repository and head/base branch,
 // and generate new patch for testing as needed.
-func AddTestPullRequestTask(doer *models.User, repoID int64, branch string, isSync bool, oldCommitID, newCommitID string) {
+func AddTestPullRequestTask(ctx context.Context, doer *models.User, repoID int64, branch string, isSync bool, oldCommitID, newCommitID string) {
 	log.Trace("AddTestPullRequestTask [head_repo_id: %d, head_branch: %s]: finding pull requests", repoID, branch)
-	graceful.GetManager().RunWithShutdownContext(func(ctx context.Context) {
+	graceful.GetManager().RunWithShutdownContext(func(context.Context) {
 		// There is no sensible way to shut this down ":-("
 		// If you don't let it run all the way then you will lose data
 		// FIXME: graceful: AddTestPullRequestTask needs to become a queue!
@@ -275,7 +285,7 @@ func AddTestPullRequestTask(doer *models.User, repoID int64, branch string, isSy
 					}
 
 					pr.Issue.PullRequest = pr
-					notification.NotifyPullRequestSynchronized(doer, pr)
+					notification.NotifyPullRequestSynchronized(ctx, doer, pr)
 				}
 			}
 		}
@@ -290,8 +300,16 @@ func AddTestPullRequestTask(doer *models.User, repoID int64, branch string, isSy
 			AddToTaskQueue(pr)
 			comment, err := models.CreatePushPullComment(doer, pr, oldCommitID, newCommitID)
 			if err == nil && comment != nil {
-				notification.NotifyPullRequestPushCommits(doer, pr, comment)
+				notification.NotifyPullRequestPushCommits(ctx, doer, pr, comment)
+			}
+			if err := RequestCodeOwnerReviews(ctx, pr); err != nil {
+				log.Error("RequestCodeOwnerReviews[%d]: %v", pr.ID, err)
+			}
+			if err := SyncLabelerLabels(ctx, pr); err != nil {
+				log.Error("SyncLabelerLabels[%d]: %v", pr.ID, err)
 			}
+			// New commits remove the pull request from the merge queue of its base branch
+			checkMergeQueue(pr.BaseRepoID, pr.BaseBranch)
 		}
 
 		log.Trace("AddTestPullRequestTask [base_repo_id: %d, base_branch: %s]: finding pull requests", repoID, branch)
@@ -312,6 +330,7 @@ func AddTestPullRequestTask(doer *models.User, repoID int64, branch string, isSy
 			}
 			AddToTaskQueue(pr)
 		}
+		checkMergeQueue(repoID, branch)
 	})
 }
 
@@ -453,7 +472,7 @@ func (errs errlist) Error() string {
 }
 
 // CloseBranchPulls close all the pull requests who's head branch is the branch
-func CloseBranchPulls(doer *models.User, repoID int64, branch string) error {
+func CloseBranchPulls(ctx context.Context, doer *models.User, repoID int64, branch string) error {
 	prs, err := models.GetUnmergedPullRequestsByHeadInfo(repoID, branch)
 	if err != nil {
 		return err
@@ -471,7 +490,7 @@ func CloseBranchPulls(doer *models.User, repoID int64, branch string) error {
 
 	var errs errlist
 	for _, pr := range prs {
-		if err = issue_service.ChangeStatus(pr.Issue, doer, true); err != nil && !models.IsErrPullWasClosed(err) {
+		if err = issue_service.ChangeStatus(ctx, pr.Issue, doer, true); err != nil && !models.IsErrPullWasClosed(err) {
 			errs = append(errs, err)
 		}
 	}
@@ -482,7 +501,7 @@ func CloseBranchPulls(doer *models.User, repoID int64, branch string) error {
 }
 
 // CloseRepoBranchesPulls close all pull requests which head branches are in the given repository, but only whose base repo is not in the given repository
-func CloseRepoBranchesPulls(doer *models.User, repo *models.Repository) error {
+func CloseRepoBranchesPulls(ctx context.Context, doer *models.User, repo *models.Repository) error {
 	branches, _, err := git.GetBranchesByPath(repo.RepoPath(), 0, 0)
 	if err != nil {
 		return err
@@ -505,7 +524,7 @@ func CloseRepoBranchesPulls(doer *models.User, repo *models.Repository) error {
 			if pr.BaseRepoID == repo.ID {
 				continue
 			}
-			if err = issue_service.ChangeStatus(pr.Issue, doer, true); err != nil && !models.IsErrPullWasClosed(err) {
+			if err = issue_service.ChangeStatus(ctx, pr.Issue, doer, true); err != nil && !models.IsErrPullWasClosed(err) {
 				errs = append(errs, err)
 			}
 		}
@@ -6,6 +6,7 @@
 package pull
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"regexp"
@@ -18,14 +19,22 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 )
 
-// CreateCodeComment creates a comment on the code line
-func CreateCodeComment(doer *models.User, gitRepo *git.Repository, issue *models.Issue, line int64, content string, treePath string, isReview bool, replyReviewID int64, latestCommitID string) (*models.Comment, error) {
+// CreateCodeComment creates a comment on the code line.
+// If startLine is not 0 the comment spans the lines from startLine to line, both must be on the same side of the diff.
+func CreateCodeComment(ctx context.Context, doer *models.User, gitRepo *git.Repository, issue *models.Issue, line, startLine int64, content string, treePath string, isReview bool, replyReviewID int64, latestCommitID string) (*models.Comment, error) {
 
 	var (
 		existsReview bool
 		err          error
 	)
 
+	if startLine == line {
+		startLine = 0
+	}
+	if startLine != 0 && !(&models.Comment{Line: line, StartLine: startLine}).IsLineInRange(startLine) {
+		return nil, models.ErrInvalidCommentLineRange{StartLine: startLine, Line: line}
+	}
+
 	// CreateCodeComment() is used for:
 	// - Single comments
 	// - Comments that are part of a review
@@ -52,6 +61,7 @@ func CreateCodeComment(doer *models.User, gitRepo *git.Repository, issue *models
 			content,
 			treePath,
 			line,
+			startLine,
 			replyReviewID,
 		)
 		if err != nil {
@@ -63,7 +73,7 @@ func CreateCodeComment(doer *models.User, gitRepo *git.Repository, issue *models
 			return nil, err
 		}
 
-		notification.NotifyCreateIssueComment(doer, issue.Repo, issue, comment, mentions)
+		notification.NotifyCreateIssueComment(ctx, doer, issue.Repo, issue, comment, mentions)
 
 		return comment, nil
 	}
@@ -92,6 +102,7 @@ func CreateCodeComment(doer *models.User, gitRepo *git.Repository, issue *models
 		content,
 		treePath,
 		line,
+		startLine,
 		review.ID,
 	)
 	if err != nil {
@@ -100,7 +111,7 @@ func CreateCodeComment(doer *models.User, gitRepo *git.Repository, issue *models
 
 	if !isReview && !existsReview {
 		// Submit the review we've just created so the comment shows up in the issue view
-		if _, _, err = SubmitReview(doer, gitRepo, issue, models.ReviewTypeComment, "", latestCommitID); err != nil {
+		if _, _, err = SubmitReview(ctx, doer, gitRepo, issue, models.ReviewTypeComment, "", latestCommitID); err != nil {
 			return nil, err
 		}
 	}
@@ -113,7 +124,7 @@ func CreateCodeComment(doer *models.User, gitRepo *git.Repository, issue *models
 var notEnoughLines = regexp.MustCompile(`exit status 128 - fatal: file .* has only \d+ lines?`)
 
 // createCodeComment creates a plain code comment at the specified line / path
-func createCodeComment(doer *models.User, repo *models.Repository, issue *models.Issue, content, treePath string, line, reviewID int64) (*models.Comment, error) {
+func createCodeComment(doer *models.User, repo *models.Repository, issue *models.Issue, content, treePath string, line, startLine, reviewID int64) (*models.Comment, error) {
 	var commitID, patch string
 	if err := issue.LoadPullRequest(); err != nil {
 		return nil, fmt.Errorf("GetPullRequestByIssueID: %v", err)
@@ -146,6 +157,10 @@ func createCodeComment(doer *models.User, repo *models.Repository, issue *models
 				commitID = first[0].CommitSHA
 				invalidated = first[0].Invalidated
 				patch = first[0].Patch
+				if startLine == 0 {
+					// Replies belong to the range of the conversation
+					startLine = first[0].StartLine
+				}
 			} else if err != nil && !models.IsErrCommentNotExist(err) {
 				return nil, fmt.Errorf("Find first comment for %d line %d path %s. Error: %v", reviewID, line, treePath, err)
 			} else {
@@ -193,29 +208,36 @@ func createCodeComment(doer *models.User, repo *models.Repository, issue *models
 			_ = writer.Close()
 		}()
 
-		patch, err = git.CutDiffAroundLine(reader, int64((&models.Comment{Line: line}).UnsignedLine()), line < 0, setting.UI.CodeCommentLines)
+		// Make sure that the whole range of a multi-line comment is part of the patch
+		rangeComment := &models.Comment{Line: line, StartLine: startLine}
+		numberOfLines := setting.UI.CodeCommentLines
+		if span := int(rangeComment.UnsignedLine()-rangeComment.UnsignedStartLine()) + 1; span > numberOfLines {
+			numberOfLines = span
+		}
+		patch, err = git.CutDiffAroundLine(reader, int64(rangeComment.UnsignedLine()), line < 0, numberOfLines)
 		if err != nil {
 			log.Error("Error whilst generating patch: %v", err)
 			return nil, err
 		}
 	}
 	return models.CreateComment(&models.CreateCommentOptions{
-		Type:        models.CommentTypeCode,
-		Doer:        doer,
-		Repo:        repo,
-		Issue:       issue,
-		Content:     content,
-		LineNum:     line,
-		TreePath:    treePath,
-		CommitSHA:   commitID,
-		ReviewID:    reviewID,
-		Patch:       patch,
-		Invalidated: invalidated,
+		Type:         models.CommentTypeCode,
+		Doer:         doer,
+		Repo:         repo,
+		Issue:        issue,
+		Content:      content,
+		LineNum:      line,
+		StartLineNum: startLine,
+		TreePath:     treePath,
+		CommitSHA:    commitID,
+		ReviewID:     reviewID,
+		Patch:        patch,
+		Invalidated:  invalidated,
 	})
 }
 
 // SubmitReview creates a review out of the existing pending review or creates a new one if no pending review exist
-func SubmitReview(doer *models.User, gitRepo *git.Repository, issue *models.Issue, reviewType models.ReviewType, content, commitID string) (*models.Review, *models.Comment, error) {
+func SubmitReview(ctx context.Context, doer *models.User, gitRepo *git.Repository, issue *models.Issue, reviewType models.ReviewType, content, commitID string) (*models.Review, *models.Comment, error) {
 	pr, err := issue.GetPullRequest()
 	if err != nil {
 		return nil, nil, err
@@ -245,22 +267,26 @@ func SubmitReview(doer *models.User, gitRepo *git.Repository, issue *models.Issu
 		return nil, nil, err
 	}
 
-	ctx := models.DefaultDBContext()
-	mentions, err := issue.FindAndUpdateIssueMentions(ctx, doer, comm.Content)
+	dbCtx := models.DefaultDBContext()
+	mentions, err := issue.FindAndUpdateIssueMentions(dbCtx, doer, comm.Content)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	notification.NotifyPullRequestReview(pr, review, comm, mentions)
+	notification.NotifyPullRequestReview(ctx, pr, review, comm, mentions)
+
+	if reviewType == models.ReviewTypeApprove {
+		addToAutoMergeQueue(pr.ID)
+	}
 
 	for _, lines := range review.CodeComments {
 		for _, comments := range lines {
 			for _, codeComment := range comments {
-				mentions, err := issue.FindAndUpdateIssueMentions(ctx, doer, codeComment.Content)
+				mentions, err := issue.FindAndUpdateIssueMentions(dbCtx, doer, codeComment.Content)
 				if err != nil {
 					return nil, nil, err
 				}
-				notification.NotifyPullRequestCodeComment(pr, codeComment, mentions)
+				notification.NotifyPullRequestCodeComment(ctx, pr, codeComment, mentions)
 			}
 		}
 	}
@@ -269,7 +295,7 @@ func SubmitReview(doer *models.User, gitRepo *git.Repository, issue *models.Issu
 }
 
 // DismissReview dismissing stale review by repo admin
-func DismissReview(reviewID int64, message string, doer *models.User, isDismiss bool) (comment *models.Comment, err error) {
+func DismissReview(ctx context.Context, reviewID int64, message string, doer *models.User, isDismiss bool) (comment *models.Comment, err error) {
 	review, err := models.GetReviewByID(reviewID)
 	if err != nil {
 		return
@@ -314,7 +340,7 @@ func DismissReview(reviewID int64, message string, doer *models.User, isDismiss
 	comment.Poster = doer
 	comment.Issue = review.Issue
 
-	notification.NotifyPullRevieweDismiss(doer, review, comment)
+	notification.NotifyPullRevieweDismiss(ctx, doer, review, comment)
 
 	return
 }
@@ -0,0 +1,143 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	issue_service "code.gitea.io/gitea/services/issue"
+)
+
+// pickReviewers picks up to count of the candidates, which are sorted by ID. Round-robin
+// starts with the first candidate after the last requested user, least-busy picks the
+// candidates with the fewest pending review requests and uses the round-robin order for ties.
+func pickReviewers(candidates []*models.User, count int, strategy models.ReviewerAssignmentStrategy, lastRequestedID int64, pending map[int64]int64) []*models.User {
+	start := 0
+	for i, u := range candidates {
+		if u.ID > lastRequestedID {
+			start = i
+			break
+		}
+	}
+	ordered := make([]*models.User, 0, len(candidates))
+	ordered = append(ordered, candidates[start:]...)
+	ordered = append(ordered, candidates[:start]...)
+
+	if strategy == models.ReviewerAssignmentLeastBusy {
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return pending[ordered[i].ID] < pending[ordered[j].ID]
+		})
+	}
+
+	if len(ordered) > count {
+		ordered = ordered[:count]
+	}
+	return ordered
+}
+
+// AutoAssignReviewers requests reviews of a new pull request from the members of the team
+// configured in the pull request settings of the base repository. Members who were already
+// requested, e.g. as code owners, count towards the number of reviewers.
+func AutoAssignReviewers(ctx context.Context, pr *models.PullRequest) error {
+	if err := pr.LoadBaseRepo(); err != nil {
+		return fmt.Errorf("LoadBaseRepo: %v", err)
+	}
+	prUnit, err := pr.BaseRepo.GetUnit(models.UnitTypePullRequests)
+	if err != nil {
+		if models.IsErrUnitTypeNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("GetUnit: %v", err)
+	}
+	cfg := prUnit.PullRequestsConfig()
+	if !cfg.IsAutoAssignReviewersEnabled() {
+		return nil
+	}
+
+	team, err := models.GetTeamByID(cfg.AutoAssignReviewersTeamID)
+	if err != nil {
+		if models.IsErrTeamNotExist(err) {
+			log.Warn("Team %d to assign reviewers from in repository %s does not exist", cfg.AutoAssignReviewersTeamID, pr.BaseRepo.FullName())
+			return nil
+		}
+		return fmt.Errorf("GetTeamByID: %v", err)
+	}
+	if team.OrgID != pr.BaseRepo.OwnerID {
+		return nil
+	}
+
+	if err := pr.LoadIssue(); err != nil {
+		return fmt.Errorf("LoadIssue: %v", err)
+	}
+	if pr.Issue.IsClosed || pr.HasMerged {
+		return nil
+	}
+	if err := pr.Issue.LoadPoster(); err != nil {
+		return fmt.Errorf("LoadPoster: %v", err)
+	}
+	pr.Issue.Repo = pr.BaseRepo
+
+	members, err := models.GetTeamMembers(team.ID)
+	if err != nil {
+		return fmt.Errorf("GetTeamMembers: %v", err)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].ID < members[j].ID
+	})
+
+	count := cfg.AutoAssignReviewersCount
+	memberIDs := make([]int64, 0, len(members))
+	candidates := make([]*models.User, 0, len(members))
+	for _, u := range members {
+		memberIDs = append(memberIDs, u.ID)
+		if u.ID == pr.Issue.PosterID || !u.IsActive || u.ProhibitLogin {
+			continue
+		}
+
+		if _, err := models.GetReviewByIssueIDAndUserID(pr.IssueID, u.ID); err == nil {
+			count--
+			continue
+		} else if !models.IsErrReviewNotExist(err) {
+			return err
+		}
+		perm, err := models.GetUserRepoPermission(pr.BaseRepo, u)
+		if err != nil {
+			return err
+		}
+		if !perm.CanAccessAny(models.AccessModeRead, models.UnitTypePullRequests) {
+			continue
+		}
+		candidates = append(candidates, u)
+	}
+	if count <= 0 || len(candidates) == 0 {
+		return nil
+	}
+
+	lastRequestedID, err := models.GetLastRequestedReviewer(pr.BaseRepoID, memberIDs)
+	if err != nil {
+		return fmt.Errorf("GetLastRequestedReviewer: %v", err)
+	}
+	var pending map[int64]int64
+	if cfg.AutoAssignReviewersStrategy == models.ReviewerAssignmentLeastBusy {
+		ids := make([]int64, len(candidates))
+		for i, u := range candidates {
+			ids[i] = u.ID
+		}
+		if pending, err = models.CountPendingReviewRequests(ids); err != nil {
+			return fmt.Errorf("CountPendingReviewRequests: %v", err)
+		}
+	}
+
+	for _, u := range pickReviewers(candidates, count, cfg.AutoAssignReviewersStrategy, lastRequestedID, pending) {
+		if _, err := issue_service.ReviewRequest(ctx, pr.Issue, pr.Issue.Poster, u, true); err != nil {
+			return fmt.Errorf("ReviewRequest: %v", err)
+		}
+	}
+	return nil
+}
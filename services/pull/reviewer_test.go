@@ -0,0 +1,67 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickReviewers(t *testing.T) {
+	candidates := []*models.User{{ID: 2}, {ID: 4}, {ID: 5}, {ID: 8}}
+	ids := func(users []*models.User) []int64 {
+		res := make([]int64, 0, len(users))
+		for _, u := range users {
+			res = append(res, u.ID)
+		}
+		return res
+	}
+
+	assert.Equal(t, []int64{2, 4}, ids(pickReviewers(candidates, 2, models.ReviewerAssignmentRoundRobin, 0, nil)))
+	assert.Equal(t, []int64{5, 8}, ids(pickReviewers(candidates, 2, models.ReviewerAssignmentRoundRobin, 4, nil)))
+	assert.Equal(t, []int64{8, 2}, ids(pickReviewers(candidates, 2, models.ReviewerAssignmentRoundRobin, 5, nil)))
+	assert.Equal(t, []int64{2, 4, 5, 8}, ids(pickReviewers(candidates, 10, models.ReviewerAssignmentRoundRobin, 8, nil)))
+
+	pending := map[int64]int64{2: 3, 4: 1, 5: 2}
+	assert.Equal(t, []int64{8, 4}, ids(pickReviewers(candidates, 2, models.ReviewerAssignmentLeastBusy, 0, pending)))
+	pending = map[int64]int64{2: 1, 4: 1, 5: 1, 8: 1}
+	assert.Equal(t, []int64{5}, ids(pickReviewers(candidates, 1, models.ReviewerAssignmentLeastBusy, 4, pending)))
+}
+
+func TestAutoAssignReviewers(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 3}).(*models.Repository)
+	pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 6}).(*models.PullRequest)
+
+	// not enabled
+	assert.NoError(t, AutoAssignReviewers(context.Background(), pr))
+	models.AssertNotExistsBean(t, &models.Review{IssueID: pr.IssueID, ReviewerID: 4})
+
+	assert.NoError(t, models.UpdateRepositoryUnits(repo, []models.RepoUnit{{
+		RepoID: repo.ID,
+		Type:   models.UnitTypePullRequests,
+		Config: &models.PullRequestsConfig{
+			AllowMerge:                  true,
+			AutoAssignReviewersTeamID:   2,
+			AutoAssignReviewersCount:    2,
+			AutoAssignReviewersStrategy: models.ReviewerAssignmentRoundRobin,
+		},
+	}}, nil))
+
+	// team 2 consists of the poster user2 and user4
+	pr = models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 6}).(*models.PullRequest)
+	assert.NoError(t, AutoAssignReviewers(context.Background(), pr))
+	models.AssertExistsAndLoadBean(t, &models.Review{IssueID: pr.IssueID, ReviewerID: 4, Type: models.ReviewTypeRequest})
+	models.AssertNotExistsBean(t, &models.Review{IssueID: pr.IssueID, ReviewerID: 2})
+
+	// user4 has already been requested and counts towards the reviewers
+	assert.NoError(t, AutoAssignReviewers(context.Background(), pr))
+	assert.EqualValues(t, 1, models.GetCount(t, &models.Review{IssueID: pr.IssueID, ReviewerID: 4}))
+}
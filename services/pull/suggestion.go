@@ -0,0 +1,116 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/repofiles"
+)
+
+// CanApplySuggestion returns true if doer may commit the change suggested by a code comment
+// to the head branch of its pull request
+func CanApplySuggestion(doer *models.User, comment *models.Comment) (bool, error) {
+	if doer == nil || !comment.HasSuggestion() || comment.IsSuggestionApplied() || comment.Invalidated {
+		return false, nil
+	}
+	if err := comment.LoadIssue(); err != nil {
+		return false, err
+	}
+	if err := comment.Issue.LoadPullRequest(); err != nil {
+		return false, err
+	}
+	pr := comment.Issue.PullRequest
+	if comment.Issue.IsClosed || pr.HasMerged {
+		return false, nil
+	}
+	if err := pr.LoadHeadRepo(); err != nil {
+		return false, err
+	}
+	if pr.HeadRepo == nil {
+		return false, nil
+	}
+	return IsUserAllowedToUpdate(pr, doer)
+}
+
+// ApplySuggestion commits the change suggested by a code comment to the head branch of
+// its pull request on behalf of doer
+func ApplySuggestion(doer *models.User, comment *models.Comment) error {
+	suggestion, ok := comment.Suggestion()
+	if !ok {
+		return models.ErrSuggestionNotApplicable{Reason: "comment does not suggest a change", CommentID: comment.ID}
+	}
+	if comment.IsSuggestionApplied() {
+		return models.ErrSuggestionNotApplicable{Reason: "suggestion has already been applied", CommentID: comment.ID}
+	}
+	if comment.Invalidated {
+		return models.ErrSuggestionNotApplicable{Reason: "commented line has been changed", CommentID: comment.ID}
+	}
+
+	if err := comment.LoadIssue(); err != nil {
+		return err
+	}
+	if err := comment.Issue.LoadPullRequest(); err != nil {
+		return err
+	}
+	pr := comment.Issue.PullRequest
+	if comment.Issue.IsClosed || pr.HasMerged {
+		return models.ErrSuggestionNotApplicable{Reason: "pull request is closed", CommentID: comment.ID}
+	}
+	if err := pr.LoadHeadRepo(); err != nil {
+		return err
+	}
+	if pr.HeadRepo == nil {
+		return models.ErrSuggestionNotApplicable{Reason: "head repository has been deleted", CommentID: comment.ID}
+	}
+	if allowed, err := IsUserAllowedToUpdate(pr, doer); err != nil {
+		return err
+	} else if !allowed {
+		return models.ErrUserCannotCommit{UserName: doer.LowerName}
+	}
+	if err := comment.LoadPoster(); err != nil {
+		return err
+	}
+
+	headRepo, err := git.OpenRepository(pr.HeadRepo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer headRepo.Close()
+	commit, err := headRepo.GetBranchCommit(pr.HeadBranch)
+	if err != nil {
+		return err
+	}
+
+	patch, err := repofiles.LineReplacementPatch(commit, comment.TreePath, int(comment.UnsignedStartLine()), int(comment.Line), suggestion)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return models.ErrSuggestionNotApplicable{Reason: "commented file has been removed", CommentID: comment.ID}
+		}
+		return err
+	}
+
+	message := fmt.Sprintf("Apply suggestion from code review\n\nSuggested in %s\n", comment.HTMLURL())
+	if comment.PosterID != doer.ID {
+		message += fmt.Sprintf("\nCo-authored-by: %s <%s>\n", comment.Poster.Name, comment.Poster.GetEmail())
+	}
+	res, err := repofiles.ApplyDiffPatch(pr.HeadRepo, doer, &repofiles.ApplyDiffPatchOptions{
+		LastCommitID: commit.ID.String(),
+		OldBranch:    pr.HeadBranch,
+		NewBranch:    pr.HeadBranch,
+		Message:      message,
+		Content:      patch,
+	})
+	if err != nil {
+		if models.IsErrPatchDoesNotApply(err) || models.IsErrCommitIDDoesNotMatch(err) {
+			return models.ErrSuggestionNotApplicable{Reason: "commented line has been changed", CommentID: comment.ID}
+		}
+		return err
+	}
+
+	return models.MarkSuggestionApplied(comment, doer, res.Commit.SHA)
+}
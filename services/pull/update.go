@@ -5,15 +5,20 @@
 package pull
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 )
 
 // Update updates pull request with base branch.
-func Update(pull *models.PullRequest, doer *models.User, message string) error {
+// If rebase is true the head branch is rebased on to the base branch and force-pushed,
+// otherwise the base branch is merged into the head branch using message.
+func Update(ctx context.Context, pull *models.PullRequest, doer *models.User, message string, rebase bool) error {
 	//use merge functions but switch repo's and branch's
 	pr := &models.PullRequest{
 		HeadRepoID: pull.BaseRepoID,
@@ -37,15 +42,72 @@ func Update(pull *models.PullRequest, doer *models.User, message string) error {
 		return fmt.Errorf("HeadBranch of PR %d is up to date", pull.Index)
 	}
 
-	_, err = rawMerge(pr, doer, models.MergeStyleMerge, message)
-
 	defer func() {
-		go AddTestPullRequestTask(doer, pr.HeadRepo.ID, pr.HeadBranch, false, "", "")
+		go AddTestPullRequestTask(ctx, doer, pr.HeadRepo.ID, pr.HeadBranch, false, "", "")
 	}()
 
+	if rebase {
+		if err := pull.LoadHeadRepo(); err != nil {
+			return fmt.Errorf("LoadHeadRepo: %v", err)
+		} else if err = pull.LoadBaseRepo(); err != nil {
+			return fmt.Errorf("LoadBaseRepo: %v", err)
+		}
+		_, err = rawMerge(ctx, pull, doer, models.MergeStyleRebaseUpdate, "")
+		return err
+	}
+
+	_, err = rawMerge(ctx, pr, doer, models.MergeStyleMerge, message)
 	return err
 }
 
+// pushRebasedHead force-pushes the staging branch, which has been rebased on to the base branch,
+// to the head branch of the pull request. The push is refused if the head branch has changed meanwhile.
+func pushRebasedHead(ctx context.Context, pr *models.PullRequest, doer *models.User, tmpBasePath, trackingBranch, stagingBranch string) (string, error) {
+	headCommitID, err := git.GetFullCommitID(tmpBasePath, trackingBranch)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get full commit id for %s: %v", trackingBranch, err)
+	}
+	rebasedCommitID, err := git.GetFullCommitID(tmpBasePath, stagingBranch)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get full commit id for the rebased head: %v", err)
+	}
+
+	// The rebased head now contains the commits of the base branch, so their LFS objects
+	// have to be associated with the head repository
+	if setting.LFS.StartServer {
+		if err := LFSPush(tmpBasePath, rebasedCommitID, headCommitID, &models.PullRequest{
+			Index:      pr.Index,
+			HeadRepoID: pr.BaseRepoID,
+			HeadRepo:   pr.BaseRepo,
+			BaseRepoID: pr.HeadRepoID,
+			BaseRepo:   pr.HeadRepo,
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	headUser, err := getHeadUser(pr, doer)
+	if err != nil {
+		return "", err
+	}
+
+	env := models.FullPushingEnvironment(
+		headUser,
+		doer,
+		pr.HeadRepo,
+		pr.HeadRepo.Name,
+		0,
+	)
+
+	var outbuf, errbuf strings.Builder
+	headRef := git.BranchPrefix + pr.HeadBranch
+	if err := git.NewCommandContext(ctx, "push", "--force-with-lease="+headRef+":"+headCommitID, "head_repo", stagingBranch+":"+headRef).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, &outbuf, &errbuf); err != nil {
+		return "", convertPushError(err, outbuf.String(), errbuf.String())
+	}
+
+	return rebasedCommitID, nil
+}
+
 // IsUserAllowedToUpdate check if user is allowed to update PR with given permissions and branch protections
 func IsUserAllowedToUpdate(pull *models.PullRequest, user *models.User) (bool, error) {
 	if user == nil {
@@ -76,6 +138,20 @@ func IsUserAllowedToUpdate(pull *models.PullRequest, user *models.User) (bool, e
 	return IsUserAllowedToMerge(pr, headRepoPerm, user)
 }
 
+// IsUserAllowedToUpdateByRebase check if user is allowed to rebase the head branch of a PR on to its base branch.
+// A rebase has to be force-pushed, which is never allowed on protected branches.
+func IsUserAllowedToUpdateByRebase(pull *models.PullRequest, user *models.User) (bool, error) {
+	if allowed, err := IsUserAllowedToUpdate(pull, user); err != nil || !allowed {
+		return false, err
+	}
+
+	protectedBranch, err := models.GetProtectedBranchBy(pull.HeadRepoID, pull.HeadBranch)
+	if err != nil {
+		return false, err
+	}
+	return protectedBranch == nil, nil
+}
+
 // GetDiverging determines how many commits a PR is ahead or behind the PR base branch
 func GetDiverging(pr *models.PullRequest) (*git.DivergeObject, error) {
 	log.Trace("GetDiverging[%d]: compare commits", pr.ID)
@@ -86,7 +162,7 @@ func GetDiverging(pr *models.PullRequest) (*git.DivergeObject, error) {
 		return nil, err
 	}
 
-	tmpRepo, err := createTemporaryRepo(pr)
+	tmpRepo, err := createTemporaryRepo(git.DefaultContext, pr)
 	if err != nil {
 		log.Error("CreateTemporaryPath: %v", err)
 		return nil, err
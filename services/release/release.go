@@ -5,6 +5,7 @@
 package release
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -18,7 +19,7 @@ import (
 	"code.gitea.io/gitea/modules/timeutil"
 )
 
-func createTag(gitRepo *git.Repository, rel *models.Release, msg string) (bool, error) {
+func createTag(ctx context.Context, gitRepo *git.Repository, rel *models.Release, msg string) (bool, error) {
 	var created bool
 	// Only actual create when publish.
 	if !rel.IsDraft {
@@ -48,20 +49,22 @@ func createTag(gitRepo *git.Repository, rel *models.Release, msg string) (bool,
 				return false, err
 			}
 			created = true
+			git.InvalidateRefsCache(gitRepo.Path)
+			git.InvalidatePackCache(gitRepo.Path)
 			rel.LowerTagName = strings.ToLower(rel.TagName)
 			// Prepare Notify
 			if err := rel.LoadAttributes(); err != nil {
 				log.Error("LoadAttributes: %v", err)
 				return false, err
 			}
-			notification.NotifyPushCommits(
+			notification.NotifyPushCommits(ctx,
 				rel.Publisher, rel.Repo,
 				&repository.PushUpdateOptions{
 					RefFullName: git.TagPrefix + rel.TagName,
 					OldCommitID: git.EmptySHA,
 					NewCommitID: commit.ID.String(),
 				}, repository.NewPushCommits())
-			notification.NotifyCreateRef(rel.Publisher, rel.Repo, "tag", git.TagPrefix+rel.TagName)
+			notification.NotifyCreateRef(ctx, rel.Publisher, rel.Repo, "tag", git.TagPrefix+rel.TagName)
 			rel.CreatedUnix = timeutil.TimeStampNow()
 		}
 		commit, err := gitRepo.GetTagCommit(rel.TagName)
@@ -88,7 +91,7 @@ func createTag(gitRepo *git.Repository, rel *models.Release, msg string) (bool,
 }
 
 // CreateRelease creates a new release of repository.
-func CreateRelease(gitRepo *git.Repository, rel *models.Release, attachmentUUIDs []string, msg string) error {
+func CreateRelease(ctx context.Context, gitRepo *git.Repository, rel *models.Release, attachmentUUIDs []string, msg string) error {
 	isExist, err := models.IsReleaseExist(rel.RepoID, rel.TagName)
 	if err != nil {
 		return err
@@ -98,7 +101,7 @@ func CreateRelease(gitRepo *git.Repository, rel *models.Release, attachmentUUIDs
 		}
 	}
 
-	if _, err = createTag(gitRepo, rel, msg); err != nil {
+	if _, err = createTag(ctx, gitRepo, rel, msg); err != nil {
 		return err
 	}
 
@@ -112,14 +115,14 @@ func CreateRelease(gitRepo *git.Repository, rel *models.Release, attachmentUUIDs
 	}
 
 	if !rel.IsDraft {
-		notification.NotifyNewRelease(rel)
+		notification.NotifyNewRelease(ctx, rel)
 	}
 
 	return nil
 }
 
 // CreateNewTag creates a new repository tag
-func CreateNewTag(doer *models.User, repo *models.Repository, commit, tagName, msg string) error {
+func CreateNewTag(ctx context.Context, doer *models.User, repo *models.Repository, commit, tagName, msg string) error {
 	isExist, err := models.IsReleaseExist(repo.ID, tagName)
 	if err != nil {
 		return err
@@ -145,7 +148,7 @@ func CreateNewTag(doer *models.User, repo *models.Repository, commit, tagName, m
 		IsTag:        true,
 	}
 
-	if _, err = createTag(gitRepo, rel, msg); err != nil {
+	if _, err = createTag(ctx, gitRepo, rel, msg); err != nil {
 		return err
 	}
 
@@ -160,35 +163,35 @@ func CreateNewTag(doer *models.User, repo *models.Repository, commit, tagName, m
 // addAttachmentUUIDs accept a slice of new created attachments' uuids which will be reassigned release_id as the created release
 // delAttachmentUUIDs accept a slice of attachments' uuids which will be deleted from the release
 // editAttachments accept a map of attachment uuid to new attachment name which will be updated with attachments.
-func UpdateRelease(doer *models.User, gitRepo *git.Repository, rel *models.Release,
+func UpdateRelease(ctx context.Context, doer *models.User, gitRepo *git.Repository, rel *models.Release,
 	addAttachmentUUIDs, delAttachmentUUIDs []string, editAttachments map[string]string) (err error) {
 	if rel.ID == 0 {
 		return errors.New("UpdateRelease only accepts an exist release")
 	}
-	isCreated, err := createTag(gitRepo, rel, "")
+	isCreated, err := createTag(ctx, gitRepo, rel, "")
 	if err != nil {
 		return err
 	}
 	rel.LowerTagName = strings.ToLower(rel.TagName)
 
-	ctx, commiter, err := models.TxDBContext()
+	dbCtx, commiter, err := models.TxDBContext()
 	if err != nil {
 		return err
 	}
 	defer commiter.Close()
 
-	if err = models.UpdateRelease(ctx, rel); err != nil {
+	if err = models.UpdateRelease(dbCtx, rel); err != nil {
 		return err
 	}
 
-	if err = models.AddReleaseAttachments(ctx, rel.ID, addAttachmentUUIDs); err != nil {
+	if err = models.AddReleaseAttachments(dbCtx, rel.ID, addAttachmentUUIDs); err != nil {
 		return fmt.Errorf("AddReleaseAttachments: %v", err)
 	}
 
 	var deletedUUIDsMap = make(map[string]bool)
 	if len(delAttachmentUUIDs) > 0 {
 		// Check attachments
-		attachments, err := models.GetAttachmentsByUUIDs(ctx, delAttachmentUUIDs)
+		attachments, err := models.GetAttachmentsByUUIDs(dbCtx, delAttachmentUUIDs)
 		if err != nil {
 			return fmt.Errorf("GetAttachmentsByUUIDs [uuids: %v]: %v", delAttachmentUUIDs, err)
 		}
@@ -199,7 +202,7 @@ func UpdateRelease(doer *models.User, gitRepo *git.Repository, rel *models.Relea
 			deletedUUIDsMap[attach.UUID] = true
 		}
 
-		if _, err := models.DeleteAttachments(ctx, attachments, false); err != nil {
+		if _, err := models.DeleteAttachments(dbCtx, attachments, false); err != nil {
 			return fmt.Errorf("DeleteAttachments [uuids: %v]: %v", delAttachmentUUIDs, err)
 		}
 	}
@@ -210,7 +213,7 @@ func UpdateRelease(doer *models.User, gitRepo *git.Repository, rel *models.Relea
 			updateAttachmentsList = append(updateAttachmentsList, k)
 		}
 		// Check attachments
-		attachments, err := models.GetAttachmentsByUUIDs(ctx, updateAttachmentsList)
+		attachments, err := models.GetAttachmentsByUUIDs(dbCtx, updateAttachmentsList)
 		if err != nil {
 			return fmt.Errorf("GetAttachmentsByUUIDs [uuids: %v]: %v", updateAttachmentsList, err)
 		}
@@ -222,7 +225,7 @@ func UpdateRelease(doer *models.User, gitRepo *git.Repository, rel *models.Relea
 
 		for uuid, newName := range editAttachments {
 			if !deletedUUIDsMap[uuid] {
-				if err = models.UpdateAttachmentByUUID(ctx, &models.Attachment{
+				if err = models.UpdateAttachmentByUUID(dbCtx, &models.Attachment{
 					UUID: uuid,
 					Name: newName,
 				}, "name"); err != nil {
@@ -247,19 +250,19 @@ func UpdateRelease(doer *models.User, gitRepo *git.Repository, rel *models.Relea
 	}
 
 	if !isCreated {
-		notification.NotifyUpdateRelease(doer, rel)
+		notification.NotifyUpdateRelease(ctx, doer, rel)
 		return
 	}
 
 	if !rel.IsDraft {
-		notification.NotifyNewRelease(rel)
+		notification.NotifyNewRelease(ctx, rel)
 	}
 
 	return err
 }
 
 // DeleteReleaseByID deletes a release and corresponding Git tag by given ID.
-func DeleteReleaseByID(id int64, doer *models.User, delTag bool) error {
+func DeleteReleaseByID(ctx context.Context, id int64, doer *models.User, delTag bool) error {
 	rel, err := models.GetReleaseByID(id)
 	if err != nil {
 		return fmt.Errorf("GetReleaseByID: %v", err)
@@ -277,15 +280,17 @@ func DeleteReleaseByID(id int64, doer *models.User, delTag bool) error {
 			log.Error("DeleteReleaseByID (git tag -d): %d in %v Failed:\nStdout: %s\nError: %v", rel.ID, repo, stdout, err)
 			return fmt.Errorf("git tag -d: %v", err)
 		}
+		git.InvalidateRefsCache(repo.RepoPath())
+		git.InvalidatePackCache(repo.RepoPath())
 
-		notification.NotifyPushCommits(
+		notification.NotifyPushCommits(ctx,
 			doer, repo,
 			&repository.PushUpdateOptions{
 				RefFullName: git.TagPrefix + rel.TagName,
 				OldCommitID: rel.Sha1,
 				NewCommitID: git.EmptySHA,
 			}, repository.NewPushCommits())
-		notification.NotifyDeleteRef(doer, repo, "tag", git.TagPrefix+rel.TagName)
+		notification.NotifyDeleteRef(ctx, doer, repo, "tag", git.TagPrefix+rel.TagName)
 
 		if err := models.DeleteReleaseByID(id); err != nil {
 			return fmt.Errorf("DeleteReleaseByID: %v", err)
@@ -314,7 +319,7 @@ func DeleteReleaseByID(id int64, doer *models.User, delTag bool) error {
 		}
 	}
 
-	notification.NotifyDeleteRelease(doer, rel)
+	notification.NotifyDeleteRelease(ctx, doer, rel)
 
 	return nil
 }
@@ -0,0 +1,47 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"net/url"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// RecordEvent adds an administrative event to the timeline of a repository. A failure is only logged, the change the
+// event is about having already been made. Nothing is recorded without a repository, e.g. for the webhooks of an
+// organization.
+func RecordEvent(doer *models.User, repo *models.Repository, typ models.RepoEventType, target, content string) {
+	if repo == nil || repo.ID == 0 {
+		return
+	}
+	var doerID int64
+	if doer != nil {
+		doerID = doer.ID
+	}
+	if err := models.AddRepoEvent(&models.RepoEvent{
+		RepoID:  repo.ID,
+		DoerID:  doerID,
+		Type:    typ,
+		Target:  target,
+		Content: content,
+	}); err != nil {
+		log.Error("Unable to record the %s event of repository %d: %v", typ.Name(), repo.ID, err)
+	}
+}
+
+// RecordWebhookEvent adds a webhook event to the timeline of the repository of the webhook. Only the host and the type
+// of the webhook are kept, its URL may contain credentials.
+func RecordWebhookEvent(doer *models.User, repo *models.Repository, typ models.RepoEventType, w *models.Webhook) {
+	if w.RepoID == 0 {
+		return
+	}
+	var host string
+	if u, err := url.Parse(w.URL); err == nil {
+		host = u.Hostname()
+	}
+	RecordEvent(doer, repo, typ, host, w.Type)
+}
@@ -5,6 +5,8 @@
 package repository
 
 import (
+	"context"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
@@ -12,7 +14,7 @@ import (
 )
 
 // GenerateRepository generates a repository from a template
-func GenerateRepository(doer, owner *models.User, templateRepo *models.Repository, opts models.GenerateRepoOptions) (_ *models.Repository, err error) {
+func GenerateRepository(ctx context.Context, doer, owner *models.User, templateRepo *models.Repository, opts models.GenerateRepoOptions) (_ *models.Repository, err error) {
 	var generateRepo *models.Repository
 	if err = models.WithTx(func(ctx models.DBContext) error {
 		generateRepo, err = repo_module.GenerateRepository(ctx, doer, owner, templateRepo, opts)
@@ -72,7 +74,7 @@ func GenerateRepository(doer, owner *models.User, templateRepo *models.Repositor
 		return nil, err
 	}
 
-	notification.NotifyCreateRepository(doer, owner, generateRepo)
+	notification.NotifyCreateRepository(ctx, doer, owner, generateRepo)
 
 	return generateRepo, nil
 }
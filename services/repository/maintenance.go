@@ -0,0 +1,164 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+
+	"xorm.io/builder"
+)
+
+// The tasks of the maintenance of a repository
+const (
+	MaintenanceGC          = "gc"
+	MaintenanceRepack      = "repack"
+	MaintenanceCommitGraph = "commit-graph"
+)
+
+// maintenanceQueue is the queue of the repositories to check and maintain
+var maintenanceQueue queue.UniqueQueue
+
+type maintenanceRequest struct {
+	RepoID int64
+	Force  bool
+}
+
+func handleMaintenance(data ...queue.Data) {
+	for _, datum := range data {
+		req := datum.(maintenanceRequest)
+		if err := maintainRepository(graceful.GetManager().ShutdownContext(), req.RepoID, req.Force); err != nil {
+			log.Error("Unable to maintain repository %d: %v", req.RepoID, err)
+		}
+	}
+}
+
+func initMaintenanceQueue() error {
+	maintenanceQueue = queue.CreateUniqueQueue("repo_maintenance", handleMaintenance, maintenanceRequest{}).(queue.UniqueQueue)
+	if maintenanceQueue == nil {
+		return errors.New("unable to create repo_maintenance queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(maintenanceQueue.Run)
+	return nil
+}
+
+// QueueRepoMaintenance adds a repository to the maintenance queue. Unless force is true, the repository is only
+// maintained if the numbers of its objects and packs require it.
+func QueueRepoMaintenance(repoID int64, force bool) error {
+	if err := maintenanceQueue.Push(maintenanceRequest{RepoID: repoID, Force: force}); err != nil && err != queue.ErrAlreadyInQueue {
+		return err
+	}
+	return nil
+}
+
+// QueueAllReposMaintenance adds all the repositories to the maintenance queue, to be maintained if they need it
+func QueueAllReposMaintenance(ctx context.Context) error {
+	return models.Iterate(
+		models.DefaultDBContext(),
+		new(models.Repository),
+		builder.Gt{"id": 0},
+		func(idx int, bean interface{}) error {
+			repo := bean.(*models.Repository)
+			select {
+			case <-ctx.Done():
+				return models.ErrCancelledf("before queuing the maintenance of %s", repo.FullName())
+			default:
+			}
+			return QueueRepoMaintenance(repo.ID, false)
+		},
+	)
+}
+
+// maintenanceTasks returns the tasks which the objects of a repository require, gcObjectCount is the number of its
+// objects after its last garbage collection by the maintenance. A repository which has not been maintained yet is
+// garbage collected once so that its growth can be measured.
+func maintenanceTasks(count *git.CountObject, gcObjectCount int64, force bool) []string {
+	cfg := setting.Repository.Maintenance
+	objects := count.Count + count.InPack
+	switch {
+	case force:
+	case objects == 0:
+		return nil
+	case cfg.MaxPacks > 0 && count.Packs > cfg.MaxPacks:
+	case cfg.MaxGrowthPercent > 0 && objects > gcObjectCount*(100+cfg.MaxGrowthPercent)/100:
+	case cfg.MaxLooseObjects > 0 && count.Count > cfg.MaxLooseObjects:
+		return []string{MaintenanceRepack, MaintenanceCommitGraph}
+	default:
+		return nil
+	}
+	return []string{MaintenanceGC, MaintenanceCommitGraph}
+}
+
+// maintainRepository runs the maintenance tasks a repository needs and records the result
+func maintainRepository(ctx context.Context, repoID int64, force bool) error {
+	repo, err := models.GetRepositoryByID(repoID)
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	health, err := models.GetRepoHealth(repo.ID)
+	if err != nil {
+		return err
+	}
+	repoPath := repo.RepoPath()
+	count, err := git.CountObjects(repoPath)
+	if err != nil {
+		return fmt.Errorf("CountObjects: %v", err)
+	}
+	tasks := maintenanceTasks(count, health.GCObjectCount, force)
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	log.Trace("Running %v on repository %v", tasks, repo)
+	cfg := setting.Repository.Maintenance
+	start := time.Now()
+	gcObjectCount := health.GCObjectCount
+	var maintenanceErr error
+	for _, task := range tasks {
+		switch task {
+		case MaintenanceGC:
+			maintenanceErr = repo_module.GitGcRepo(ctx, repo, cfg.Timeout, setting.Git.GCArgs...)
+			if maintenanceErr == nil {
+				if count, err = git.CountObjects(repoPath); err == nil {
+					gcObjectCount = count.Count + count.InPack
+				}
+			}
+		case MaintenanceRepack:
+			if maintenanceErr = git.Repack(ctx, repoPath, cfg.Timeout); maintenanceErr == nil {
+				maintenanceErr = git.Prune(ctx, repoPath, cfg.Timeout, cfg.PruneExpire)
+			}
+		case MaintenanceCommitGraph:
+			maintenanceErr = git.WriteCommitGraph(ctx, repoPath, cfg.Timeout)
+		}
+		if maintenanceErr != nil {
+			maintenanceErr = fmt.Errorf("%s: %v", task, maintenanceErr)
+			break
+		}
+	}
+
+	if err := models.UpdateRepoMaintenanceResult(repo.ID, start, tasks, gcObjectCount, maintenanceErr); err != nil {
+		log.Error("UpdateRepoMaintenanceResult: %v", err)
+	}
+	if maintenanceErr != nil {
+		if err := models.CreateRepositoryNotice("Maintenance of repository %s failed: %v", repo.FullName(), maintenanceErr); err != nil {
+			log.Error("CreateRepositoryNotice: %v", err)
+		}
+	}
+	return maintenanceErr
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenanceTasks(t *testing.T) {
+	gc := []string{MaintenanceGC, MaintenanceCommitGraph}
+	repack := []string{MaintenanceRepack, MaintenanceCommitGraph}
+
+	assert.Nil(t, maintenanceTasks(&git.CountObject{}, 0, false))
+	assert.Equal(t, gc, maintenanceTasks(&git.CountObject{}, 0, true))
+	// never maintained
+	assert.Equal(t, gc, maintenanceTasks(&git.CountObject{InPack: 10}, 0, false))
+	assert.Nil(t, maintenanceTasks(&git.CountObject{Count: 10, InPack: 100, Packs: 2}, 100, false))
+	// too many packs
+	assert.Equal(t, gc, maintenanceTasks(&git.CountObject{InPack: 100, Packs: 51}, 100, false))
+	// the number of objects has more than doubled
+	assert.Equal(t, gc, maintenanceTasks(&git.CountObject{InPack: 201, Packs: 2}, 100, false))
+	// too many loose objects
+	assert.Equal(t, repack, maintenanceTasks(&git.CountObject{Count: 1001, InPack: 100000, Packs: 2}, 100000, false))
+}
+
+func TestMaintainRepository(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	assert.NoError(t, maintainRepository(context.Background(), 1, true))
+	health, err := models.GetRepoHealth(1)
+	assert.NoError(t, err)
+	assert.True(t, health.HasMaintenance())
+	assert.Empty(t, health.LastMaintenanceError)
+	assert.Equal(t, "gc,commit-graph", health.LastMaintenanceTasks)
+	assert.Greater(t, health.GCObjectCount, int64(0))
+	assert.True(t, health.HasGC())
+
+	// the repository does not need to be maintained again
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	count, err := git.CountObjects(repo.RepoPath())
+	assert.NoError(t, err)
+	assert.Nil(t, maintenanceTasks(count, health.GCObjectCount, false))
+
+	// a repository which does not exist any more is skipped
+	assert.NoError(t, maintainRepository(context.Background(), 9999, true))
+}
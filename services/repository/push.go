@@ -6,6 +6,7 @@ package repository
 
 import (
 	"container/list"
+	"context"
 	"fmt"
 	"time"
 
@@ -29,7 +30,9 @@ var pushQueue queue.Queue
 func handle(data ...queue.Data) {
 	for _, datum := range data {
 		opts := datum.([]*repo_module.PushUpdateOptions)
-		if err := pushUpdates(opts); err != nil {
+		ctx := log.WithRequestID(graceful.GetManager().ShutdownContext(), opts[0].RequestID)
+		ctx = opts[0].WithSpanContext(ctx)
+		if err := pushUpdates(ctx, opts); err != nil {
 			log.Error("pushUpdate failed: %v", err)
 		}
 	}
@@ -46,27 +49,34 @@ func initPushQueue() error {
 }
 
 // PushUpdate is an alias of PushUpdates for single push update options
-func PushUpdate(opts *repo_module.PushUpdateOptions) error {
-	return PushUpdates([]*repo_module.PushUpdateOptions{opts})
+func PushUpdate(ctx context.Context, opts *repo_module.PushUpdateOptions) error {
+	return PushUpdates(ctx, []*repo_module.PushUpdateOptions{opts})
 }
 
-// PushUpdates adds a push update to push queue
-func PushUpdates(opts []*repo_module.PushUpdateOptions) error {
+// PushUpdates adds a push update to push queue, it is handled with the ID of the request and in the trace carried by the context
+func PushUpdates(ctx context.Context, opts []*repo_module.PushUpdateOptions) error {
 	if len(opts) == 0 {
 		return nil
 	}
 
+	requestID := log.RequestIDFromContext(ctx)
 	for _, opt := range opts {
 		if opt.IsNewRef() && opt.IsDelRef() {
 			return fmt.Errorf("Old and new revisions are both %s", git.EmptySHA)
 		}
+		opt.RequestID = requestID
+		opt.SetSpanContext(ctx)
 	}
 
+	// the refs have already changed, the clients must not be sent the old ones until the queue is processed
+	git.InvalidateRefsCache(models.RepoPath(opts[0].RepoUserName, opts[0].RepoName))
+	git.InvalidatePackCache(models.RepoPath(opts[0].RepoUserName, opts[0].RepoName))
+
 	return pushQueue.Push(opts)
 }
 
 // pushUpdates generates push action history feeds for push updating multiple refs
-func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
+func pushUpdates(ctx context.Context, optsList []*repo_module.PushUpdateOptions) error {
 	if len(optsList) == 0 {
 		return nil
 	}
@@ -105,7 +115,7 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 			}
 			tagName := opts.TagName()
 			if opts.IsDelRef() {
-				notification.NotifyPushCommits(
+				notification.NotifyPushCommits(ctx,
 					pusher, repo,
 					&repo_module.PushUpdateOptions{
 						RefFullName: git.TagPrefix + tagName,
@@ -114,9 +124,9 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 					}, repo_module.NewPushCommits())
 
 				delTags = append(delTags, tagName)
-				notification.NotifyDeleteRef(pusher, repo, "tag", opts.RefFullName)
+				notification.NotifyDeleteRef(ctx, pusher, repo, "tag", opts.RefFullName)
 			} else { // is new tag
-				notification.NotifyPushCommits(
+				notification.NotifyPushCommits(ctx,
 					pusher, repo,
 					&repo_module.PushUpdateOptions{
 						RefFullName: git.TagPrefix + tagName,
@@ -125,7 +135,7 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 					}, repo_module.NewPushCommits())
 
 				addTags = append(addTags, tagName)
-				notification.NotifyCreateRef(pusher, repo, "tag", opts.RefFullName)
+				notification.NotifyCreateRef(ctx, pusher, repo, "tag", opts.RefFullName)
 			}
 		} else if opts.IsBranch() { // If is branch reference
 			if pusher == nil || pusher.ID != opts.PusherID {
@@ -138,7 +148,7 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 			branch := opts.BranchName()
 			if !opts.IsDelRef() {
 				log.Trace("TriggerTask '%s/%s' by %s", repo.Name, branch, pusher.Name)
-				go pull_service.AddTestPullRequestTask(pusher, repo.ID, branch, true, opts.OldCommitID, opts.NewCommitID)
+				go pull_service.AddTestPullRequestTask(ctx, pusher, repo.ID, branch, true, opts.OldCommitID, opts.NewCommitID)
 
 				newCommit, err := gitRepo.GetCommit(opts.NewCommitID)
 				if err != nil {
@@ -170,7 +180,7 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 					if err != nil {
 						return fmt.Errorf("newCommit.CommitsBeforeLimit: %v", err)
 					}
-					notification.NotifyCreateRef(pusher, repo, "branch", opts.RefFullName)
+					notification.NotifyCreateRef(ctx, pusher, repo, "branch", opts.RefFullName)
 				} else {
 					l, err = newCommit.CommitsBeforeUntil(opts.OldCommitID)
 					if err != nil {
@@ -197,9 +207,9 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 					commits.Commits = commits.Commits[:setting.UI.FeedMaxCommitNum]
 				}
 				commits.CompareURL = repo.ComposeCompareURL(opts.OldCommitID, opts.NewCommitID)
-				notification.NotifyPushCommits(pusher, repo, opts, commits)
+				notification.NotifyPushCommits(ctx, pusher, repo, opts, commits)
 
-				if err := repofiles.UpdateIssuesCommit(pusher, repo, commits.Commits, refName); err != nil {
+				if err := repofiles.UpdateIssuesCommit(ctx, pusher, repo, commits.Commits, refName); err != nil {
 					log.Error("updateIssuesCommit: %v", err)
 				}
 
@@ -212,8 +222,8 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 					log.Error("repo_module.CacheRef %s/%s failed: %v", repo.ID, branch, err)
 				}
 			} else {
-				notification.NotifyDeleteRef(pusher, repo, "branch", opts.RefFullName)
-				if err = pull_service.CloseBranchPulls(pusher, repo.ID, branch); err != nil {
+				notification.NotifyDeleteRef(ctx, pusher, repo, "branch", opts.RefFullName)
+				if err = pull_service.CloseBranchPulls(ctx, pusher, repo.ID, branch); err != nil {
 					// close all related pulls
 					log.Error("close related pull request failed: %v", err)
 				}
@@ -236,5 +246,11 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 		return fmt.Errorf("UpdateRepositoryUpdatedTime: %v", err)
 	}
 
+	if setting.Repository.Maintenance.Enabled {
+		if err := QueueRepoMaintenance(repo.ID, false); err != nil {
+			log.Error("QueueRepoMaintenance: %v", err)
+		}
+	}
+
 	return nil
 }
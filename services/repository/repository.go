@@ -5,6 +5,7 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 
 	"code.gitea.io/gitea/models"
@@ -16,27 +17,27 @@ import (
 )
 
 // CreateRepository creates a repository for the user/organization.
-func CreateRepository(doer, owner *models.User, opts models.CreateRepoOptions) (*models.Repository, error) {
+func CreateRepository(ctx context.Context, doer, owner *models.User, opts models.CreateRepoOptions) (*models.Repository, error) {
 	repo, err := repo_module.CreateRepository(doer, owner, opts)
 	if err != nil {
 		// No need to rollback here we should do this in CreateRepository...
 		return nil, err
 	}
 
-	notification.NotifyCreateRepository(doer, owner, repo)
+	notification.NotifyCreateRepository(ctx, doer, owner, repo)
 
 	return repo, nil
 }
 
 // AdoptRepository adopts pre-existing repository files for the user/organization.
-func AdoptRepository(doer, owner *models.User, opts models.CreateRepoOptions) (*models.Repository, error) {
+func AdoptRepository(ctx context.Context, doer, owner *models.User, opts models.CreateRepoOptions) (*models.Repository, error) {
 	repo, err := repo_module.AdoptRepository(doer, owner, opts)
 	if err != nil {
 		// No need to rollback here we should do this in AdoptRepository...
 		return nil, err
 	}
 
-	notification.NotifyCreateRepository(doer, owner, repo)
+	notification.NotifyCreateRepository(ctx, doer, owner, repo)
 
 	return repo, nil
 }
@@ -47,32 +48,32 @@ func DeleteUnadoptedRepository(doer, owner *models.User, name string) error {
 }
 
 // ForkRepository forks a repository
-func ForkRepository(doer, u *models.User, oldRepo *models.Repository, name, desc string) (*models.Repository, error) {
+func ForkRepository(ctx context.Context, doer, u *models.User, oldRepo *models.Repository, name, desc string) (*models.Repository, error) {
 	repo, err := repo_module.ForkRepository(doer, u, oldRepo, name, desc)
 	if err != nil {
 		return nil, err
 	}
 
-	notification.NotifyForkRepository(doer, oldRepo, repo)
+	notification.NotifyForkRepository(ctx, doer, oldRepo, repo)
 
 	return repo, nil
 }
 
 // DeleteRepository deletes a repository for a user or organization.
-func DeleteRepository(doer *models.User, repo *models.Repository) error {
-	if err := pull_service.CloseRepoBranchesPulls(doer, repo); err != nil {
+func DeleteRepository(ctx context.Context, doer *models.User, repo *models.Repository) error {
+	if err := pull_service.CloseRepoBranchesPulls(ctx, doer, repo); err != nil {
 		log.Error("CloseRepoBranchesPulls failed: %v", err)
 	}
 
 	// If the repo itself has webhooks, we need to trigger them before deleting it...
-	notification.NotifyDeleteRepository(doer, repo)
+	notification.NotifyDeleteRepository(ctx, doer, repo)
 
 	err := models.DeleteRepository(doer, repo.OwnerID, repo.ID)
 	return err
 }
 
 // PushCreateRepo creates a repository when a new repository is pushed to an appropriate namespace
-func PushCreateRepo(authUser, owner *models.User, repoName string) (*models.Repository, error) {
+func PushCreateRepo(ctx context.Context, authUser, owner *models.User, repoName string) (*models.Repository, error) {
 	if !authUser.IsAdmin {
 		if owner.IsOrganization() {
 			if ok, err := owner.CanCreateOrgRepo(authUser.ID); err != nil {
@@ -85,7 +86,7 @@ func PushCreateRepo(authUser, owner *models.User, repoName string) (*models.Repo
 		}
 	}
 
-	repo, err := CreateRepository(authUser, owner, models.CreateRepoOptions{
+	repo, err := CreateRepository(ctx, authUser, owner, models.CreateRepoOptions{
 		Name:      repoName,
 		IsPrivate: cfg.Repository.DefaultPushCreatePrivate,
 	})
@@ -98,5 +99,8 @@ func PushCreateRepo(authUser, owner *models.User, repoName string) (*models.Repo
 
 // NewContext start repository service
 func NewContext() error {
-	return initPushQueue()
+	if err := initPushQueue(); err != nil {
+		return err
+	}
+	return initMaintenanceQueue()
 }
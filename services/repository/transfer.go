@@ -5,6 +5,7 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 
 	"code.gitea.io/gitea/models"
@@ -16,7 +17,7 @@ import (
 var repoWorkingPool = sync.NewExclusivePool()
 
 // TransferOwnership transfers all corresponding setting from old user to new one.
-func TransferOwnership(doer, newOwner *models.User, repo *models.Repository, teams []*models.Team) error {
+func TransferOwnership(ctx context.Context, doer, newOwner *models.User, repo *models.Repository, teams []*models.Team) error {
 	if err := repo.GetOwner(); err != nil {
 		return err
 	}
@@ -46,13 +47,14 @@ func TransferOwnership(doer, newOwner *models.User, repo *models.Repository, tea
 		}
 	}
 
-	notification.NotifyTransferRepository(doer, repo, oldOwner.Name)
+	RecordEvent(doer, newRepo, models.RepoEventTransfer, newOwner.Name, oldOwner.Name)
+	notification.NotifyTransferRepository(ctx, doer, repo, oldOwner.Name)
 
 	return nil
 }
 
 // ChangeRepositoryName changes all corresponding setting from old repository name to new one.
-func ChangeRepositoryName(doer *models.User, repo *models.Repository, newRepoName string) error {
+func ChangeRepositoryName(ctx context.Context, doer *models.User, repo *models.Repository, newRepoName string) error {
 	oldRepoName := repo.Name
 
 	// Change repository directory name. We must lock the local copy of the
@@ -66,21 +68,21 @@ func ChangeRepositoryName(doer *models.User, repo *models.Repository, newRepoNam
 	}
 	repoWorkingPool.CheckOut(fmt.Sprint(repo.ID))
 
-	notification.NotifyRenameRepository(doer, repo, oldRepoName)
+	notification.NotifyRenameRepository(ctx, doer, repo, oldRepoName)
 
 	return nil
 }
 
 // StartRepositoryTransfer transfer a repo from one owner to a new one.
 // it make repository into pending transfer state, if doer can not create repo for new owner.
-func StartRepositoryTransfer(doer, newOwner *models.User, repo *models.Repository, teams []*models.Team) error {
+func StartRepositoryTransfer(ctx context.Context, doer, newOwner *models.User, repo *models.Repository, teams []*models.Team) error {
 	if err := models.TestRepositoryReadyForTransfer(repo.Status); err != nil {
 		return err
 	}
 
 	// Admin is always allowed to transfer || user transfer repo back to his account
 	if doer.IsAdmin || doer.ID == newOwner.ID {
-		return TransferOwnership(doer, newOwner, repo, teams)
+		return TransferOwnership(ctx, doer, newOwner, repo, teams)
 	}
 
 	// If new owner is an org and user can create repos he can transfer directly too
@@ -90,7 +92,7 @@ func StartRepositoryTransfer(doer, newOwner *models.User, repo *models.Repositor
 			return err
 		}
 		if allowed {
-			return TransferOwnership(doer, newOwner, repo, teams)
+			return TransferOwnership(ctx, doer, newOwner, repo, teams)
 		}
 	}
 
@@ -99,9 +101,10 @@ func StartRepositoryTransfer(doer, newOwner *models.User, repo *models.Repositor
 	if err := models.CreatePendingRepositoryTransfer(doer, newOwner, repo.ID, teams); err != nil {
 		return err
 	}
+	RecordEvent(doer, repo, models.RepoEventTransferStart, newOwner.Name, "")
 
 	// notify users who are able to accept / reject transfer
-	notification.NotifyRepoPendingTransfer(doer, newOwner, repo)
+	notification.NotifyRepoPendingTransfer(ctx, doer, newOwner, repo)
 
 	return nil
 }
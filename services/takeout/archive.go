@@ -0,0 +1,143 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package takeout
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// WriteArchive writes the takeout of a user as a zip archive to w
+func WriteArchive(ctx context.Context, u *models.User, w io.Writer, withRepos bool) error {
+	dir, err := models.CreateTemporaryPath("takeout")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := models.RemoveTemporaryPath(dir); err != nil {
+			log.Error("Unable to remove temporary directory: %s: Error: %v", dir, err)
+		}
+	}()
+
+	if _, err := Dump(ctx, u, dir, withRepos); err != nil {
+		return err
+	}
+	return zipDir(dir, w)
+}
+
+// zipDir writes the files and the directories in dir, which are kept even if they are empty as git needs them, as a
+// zip archive to w
+func zipDir(dir string, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			header.Name += "/"
+			_, err = zw.CreateHeader(header)
+			return err
+		}
+		header.Method = zip.Deflate
+
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(fw, f)
+		return err
+	}); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// RestoreArchive restores the takeout in the zip archive at archivePath as Restore does
+func RestoreArchive(ctx context.Context, archivePath, username string) (*models.User, string, error) {
+	dir, err := models.CreateTemporaryPath("takeout")
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		if err := models.RemoveTemporaryPath(dir); err != nil {
+			log.Error("Unable to remove temporary directory: %s: Error: %v", dir, err)
+		}
+	}()
+
+	if err := unzip(archivePath, dir); err != nil {
+		return nil, "", err
+	}
+	return Restore(ctx, dir, username)
+}
+
+// unzip extracts the zip archive at archivePath to dir, refusing the entries outside of it
+func unzip(archivePath, dir string) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		path := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if !strings.HasPrefix(path, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("the archive entry %s is outside of the takeout", f.Name)
+		}
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, os.ModePerm); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := unzipFile(f, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unzipFile(f *zip.File, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	w, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode().Perm()|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, rc); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,516 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package takeout
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations"
+	"code.gitea.io/gitea/modules/password"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	// Version is the version of the format of the takeouts, which is increased when it changes in a way older
+	// instances cannot restore
+	Version = 1
+
+	// ManifestName is the name of the file describing the user at the root of a takeout
+	ManifestName = "takeout.json"
+	// RepositoriesDir is the directory of a takeout where the repositories are dumped, one directory per repository
+	// in the format of the dump-repo command
+	RepositoriesDir = "repositories"
+
+	pageSize = 50
+)
+
+// Manifest is the content of the takeout.json file of a takeout
+type Manifest struct {
+	Version       int             `json:"version"`
+	Created       time.Time       `json:"created"`
+	User          User            `json:"user"`
+	Emails        []*Email        `json:"emails"`
+	PublicKeys    []*PublicKey    `json:"public_keys"`
+	GPGKeys       []*GPGKey       `json:"gpg_keys"`
+	ProfileFields []*ProfileField `json:"profile_fields"`
+	Repositories  []*Repository   `json:"repositories"`
+	// Issues are the issues and pull requests created by the user, in the repositories they can still read
+	Issues []*Issue `json:"issues"`
+	// Comments are the comments written by the user, in the repositories they can still read
+	Comments []*Comment `json:"comments"`
+}
+
+// User is the account and the settings of the user of a takeout
+type User struct {
+	Name             string    `json:"name"`
+	FullName         string    `json:"full_name"`
+	Email            string    `json:"email"`
+	KeepEmailPrivate bool      `json:"keep_email_private"`
+	Website          string    `json:"website"`
+	Location         string    `json:"location"`
+	Description      string    `json:"description"`
+	Language         string    `json:"language"`
+	Theme            string    `json:"theme"`
+	Visibility       string    `json:"visibility"`
+	Created          time.Time `json:"created"`
+}
+
+// Email is an email address of the user
+type Email struct {
+	Email       string `json:"email"`
+	IsPrimary   bool   `json:"is_primary"`
+	IsActivated bool   `json:"is_activated"`
+}
+
+// PublicKey is an SSH key of the user
+type PublicKey struct {
+	Name        string    `json:"name"`
+	Fingerprint string    `json:"fingerprint"`
+	Content     string    `json:"content"`
+	Created     time.Time `json:"created"`
+}
+
+// GPGKey is a GPG key of the user, whose content is its base64 encoded public key
+type GPGKey struct {
+	KeyID   string    `json:"key_id"`
+	Content string    `json:"content"`
+	Created time.Time `json:"created"`
+}
+
+// ProfileField is the value of a profile field of the user
+type ProfileField struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// Repository is a repository owned by the user
+type Repository struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IsPrivate   bool   `json:"is_private"`
+	IsFork      bool   `json:"is_fork"`
+	IsMirror    bool   `json:"is_mirror"`
+	// Path is the directory of the takeout where the repository is dumped, empty if it is not
+	Path string `json:"path,omitempty"`
+}
+
+// Issue is an issue or a pull request created by the user
+type Issue struct {
+	Repository string    `json:"repository"`
+	Index      int64     `json:"index"`
+	IsPull     bool      `json:"is_pull"`
+	Title      string    `json:"title"`
+	Content    string    `json:"content"`
+	State      string    `json:"state"`
+	Created    time.Time `json:"created"`
+	URL        string    `json:"url"`
+}
+
+// Comment is a comment of an issue or a pull request, or a code comment, written by the user
+type Comment struct {
+	Repository string    `json:"repository"`
+	IssueIndex int64     `json:"issue_index"`
+	Content    string    `json:"content"`
+	Created    time.Time `json:"created"`
+	URL        string    `json:"url"`
+}
+
+// Dump writes the takeout of a user to dir: its manifest and, if withRepos, the dumps of the repositories of the user
+func Dump(ctx context.Context, u *models.User, dir string, withRepos bool) (*Manifest, error) {
+	manifest := &Manifest{
+		Version: Version,
+		Created: time.Now().UTC(),
+		User: User{
+			Name:             u.Name,
+			FullName:         u.FullName,
+			Email:            u.Email,
+			KeepEmailPrivate: u.KeepEmailPrivate,
+			Website:          u.Website,
+			Location:         u.Location,
+			Description:      u.Description,
+			Language:         u.Language,
+			Theme:            u.Theme,
+			Visibility:       u.Visibility.String(),
+			Created:          u.CreatedUnix.AsTime().UTC(),
+		},
+	}
+
+	if err := dumpAccount(manifest, u); err != nil {
+		return nil, err
+	}
+	if err := dumpRepositories(ctx, manifest, u, dir, withRepos); err != nil {
+		return nil, err
+	}
+	if err := dumpIssues(manifest, u); err != nil {
+		return nil, err
+	}
+	if err := dumpComments(manifest, u); err != nil {
+		return nil, err
+	}
+
+	bs, err := jsoniter.ConfigCompatibleWithStandardLibrary.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, ManifestName), bs, 0644); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func dumpAccount(manifest *Manifest, u *models.User) error {
+	emails, err := models.GetEmailAddresses(u.ID)
+	if err != nil {
+		return fmt.Errorf("GetEmailAddresses: %v", err)
+	}
+	manifest.Emails = make([]*Email, 0, len(emails))
+	for _, email := range emails {
+		manifest.Emails = append(manifest.Emails, &Email{
+			Email:       email.Email,
+			IsPrimary:   email.IsPrimary,
+			IsActivated: email.IsActivated,
+		})
+	}
+
+	manifest.PublicKeys = make([]*PublicKey, 0, 10)
+	for page := 1; ; page++ {
+		keys, err := models.ListPublicKeys(u.ID, models.ListOptions{Page: page, PageSize: pageSize})
+		if err != nil {
+			return fmt.Errorf("ListPublicKeys: %v", err)
+		}
+		for _, key := range keys {
+			manifest.PublicKeys = append(manifest.PublicKeys, &PublicKey{
+				Name:        key.Name,
+				Fingerprint: key.Fingerprint,
+				Content:     key.Content,
+				Created:     key.CreatedUnix.AsTime().UTC(),
+			})
+		}
+		if len(keys) < pageSize {
+			break
+		}
+	}
+
+	manifest.GPGKeys = make([]*GPGKey, 0, 10)
+	for page := 1; ; page++ {
+		keys, err := models.ListGPGKeys(u.ID, models.ListOptions{Page: page, PageSize: pageSize})
+		if err != nil {
+			return fmt.Errorf("ListGPGKeys: %v", err)
+		}
+		for _, key := range keys {
+			manifest.GPGKeys = append(manifest.GPGKeys, &GPGKey{
+				KeyID:   key.KeyID,
+				Content: key.Content,
+				Created: key.CreatedUnix.AsTime().UTC(),
+			})
+		}
+		if len(keys) < pageSize {
+			break
+		}
+	}
+
+	fields, err := models.GetUserProfileFields(u.ID)
+	if err != nil {
+		return fmt.Errorf("GetUserProfileFields: %v", err)
+	}
+	manifest.ProfileFields = make([]*ProfileField, 0, len(fields))
+	for _, field := range fields {
+		if field.Value == "" {
+			continue
+		}
+		manifest.ProfileFields = append(manifest.ProfileFields, &ProfileField{
+			Name:  field.Name,
+			Label: field.Label,
+			Value: field.Value,
+		})
+	}
+	return nil
+}
+
+func dumpRepositories(ctx context.Context, manifest *Manifest, u *models.User, dir string, withRepos bool) error {
+	manifest.Repositories = make([]*Repository, 0, u.NumRepos)
+	for page := 1; ; page++ {
+		repos, _, err := models.GetUserRepositories(&models.SearchRepoOptions{
+			Actor:       u,
+			Private:     true,
+			OrderBy:     models.SearchOrderByAlphabetically,
+			ListOptions: models.ListOptions{Page: page, PageSize: pageSize},
+		})
+		if err != nil {
+			return fmt.Errorf("GetUserRepositories: %v", err)
+		}
+		for _, repo := range repos {
+			r := &Repository{
+				Name:        repo.Name,
+				Description: repo.Description,
+				IsPrivate:   repo.IsPrivate,
+				IsFork:      repo.IsFork,
+				IsMirror:    repo.IsMirror,
+			}
+			if withRepos && !repo.IsBeingCreated() {
+				if err := migrations.DumpLocalRepository(ctx, repo, filepath.Join(dir, RepositoriesDir), repo.Name); err != nil {
+					return fmt.Errorf("dump %s: %v", repo.FullName(), err)
+				}
+				r.Path = RepositoriesDir + "/" + repo.Name
+			}
+			manifest.Repositories = append(manifest.Repositories, r)
+		}
+		if len(repos) < pageSize {
+			break
+		}
+	}
+	return nil
+}
+
+// permissionCache returns the permissions of a user in the repositories, loaded once per repository
+type permissionCache struct {
+	user        *models.User
+	permissions map[int64]models.Permission
+}
+
+func (c *permissionCache) get(repo *models.Repository) (models.Permission, error) {
+	if perm, ok := c.permissions[repo.ID]; ok {
+		return perm, nil
+	}
+	perm, err := models.GetUserRepoPermission(repo, c.user)
+	if err != nil {
+		return perm, err
+	}
+	c.permissions[repo.ID] = perm
+	return perm, nil
+}
+
+func dumpIssues(manifest *Manifest, u *models.User) error {
+	perms := &permissionCache{user: u, permissions: make(map[int64]models.Permission)}
+	manifest.Issues = make([]*Issue, 0, 10)
+	for page := 1; ; page++ {
+		issues, err := models.Issues(&models.IssuesOptions{
+			ListOptions: models.ListOptions{Page: page, PageSize: pageSize},
+			PosterID:    u.ID,
+			SortType:    "oldest",
+		})
+		if err != nil {
+			return fmt.Errorf("Issues: %v", err)
+		}
+		for _, issue := range issues {
+			if err := issue.LoadRepo(); err != nil {
+				return err
+			}
+			perm, err := perms.get(issue.Repo)
+			if err != nil {
+				return err
+			}
+			if !perm.CanReadIssuesOrPulls(issue.IsPull) {
+				continue
+			}
+			state := string(structs.StateOpen)
+			if issue.IsClosed {
+				state = string(structs.StateClosed)
+			}
+			manifest.Issues = append(manifest.Issues, &Issue{
+				Repository: issue.Repo.FullName(),
+				Index:      issue.Index,
+				IsPull:     issue.IsPull,
+				Title:      issue.Title,
+				Content:    issue.Content,
+				State:      state,
+				Created:    issue.CreatedUnix.AsTime().UTC(),
+				URL:        issue.HTMLURL(),
+			})
+		}
+		if len(issues) < pageSize {
+			break
+		}
+	}
+	return nil
+}
+
+func dumpComments(manifest *Manifest, u *models.User) error {
+	perms := &permissionCache{user: u, permissions: make(map[int64]models.Permission)}
+	manifest.Comments = make([]*Comment, 0, 10)
+	for _, tp := range []models.CommentType{models.CommentTypeComment, models.CommentTypeCode} {
+		for page := 1; ; page++ {
+			comments, err := models.FindComments(models.FindCommentsOptions{
+				ListOptions: models.ListOptions{Page: page, PageSize: pageSize},
+				PosterID:    u.ID,
+				Type:        tp,
+			})
+			if err != nil {
+				return fmt.Errorf("FindComments: %v", err)
+			}
+			for _, comment := range comments {
+				if err := comment.LoadIssue(); err != nil {
+					return err
+				}
+				if err := comment.Issue.LoadRepo(); err != nil {
+					return err
+				}
+				perm, err := perms.get(comment.Issue.Repo)
+				if err != nil {
+					return err
+				}
+				if !perm.CanReadIssuesOrPulls(comment.Issue.IsPull) {
+					continue
+				}
+				manifest.Comments = append(manifest.Comments, &Comment{
+					Repository: comment.Issue.Repo.FullName(),
+					IssueIndex: comment.Issue.Index,
+					Content:    comment.Content,
+					Created:    comment.CreatedUnix.AsTime().UTC(),
+					URL:        comment.HTMLURL(),
+				})
+			}
+			if len(comments) < pageSize {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// ReadManifest reads the manifest of the takeout in dir
+func ReadManifest(dir string) (*Manifest, error) {
+	bs, err := ioutil.ReadFile(filepath.Join(dir, ManifestName))
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(bs, &manifest); err != nil {
+		return nil, fmt.Errorf("%s: %v", ManifestName, err)
+	}
+	if manifest.Version < 1 || manifest.Version > Version {
+		return nil, fmt.Errorf("the version %d of the takeout is not supported", manifest.Version)
+	}
+	return &manifest, nil
+}
+
+// Restore restores the takeout in dir to the user named username, or the user of the takeout if it is empty. The user
+// is created with a random password, which is returned and must be changed, if they do not exist. The profile, the
+// SSH keys, the profile fields which exist on this instance and the dumped repositories are restored, the SSH keys and
+// the repositories which already exist are skipped. The secondary email addresses and the GPG keys are not restored
+// as they would have to be verified again, nor the issues and comments of the repositories of other users.
+func Restore(ctx context.Context, dir, username string) (u *models.User, passwd string, err error) {
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	if username == "" {
+		username = manifest.User.Name
+	}
+
+	u, err = models.GetUserByName(username)
+	if err != nil {
+		if !models.IsErrUserNotExist(err) {
+			return nil, "", err
+		}
+		length := 12
+		if setting.MinPasswordLength > length {
+			length = setting.MinPasswordLength
+		}
+		if passwd, err = password.Generate(length); err != nil {
+			return nil, "", err
+		}
+		u = &models.User{
+			Name:               username,
+			Email:              manifest.User.Email,
+			Passwd:             passwd,
+			IsActive:           true,
+			MustChangePassword: true,
+		}
+		if err := models.CreateUser(u); err != nil {
+			return nil, "", fmt.Errorf("CreateUser: %v", err)
+		}
+		log.Trace("Takeout of %s: created user %s", manifest.User.Name, u.Name)
+	}
+
+	if err := restoreAccount(manifest, u); err != nil {
+		return nil, "", err
+	}
+
+	for _, repo := range manifest.Repositories {
+		if repo.Path == "" {
+			continue
+		}
+		if err := models.IsUsableRepoName(repo.Name); err != nil {
+			return nil, "", err
+		}
+		if exist, err := models.IsRepositoryExist(u, repo.Name); err != nil {
+			return nil, "", err
+		} else if exist {
+			log.Warn("Takeout of %s: repository %s/%s already exists, skipped", manifest.User.Name, u.Name, repo.Name)
+			continue
+		}
+		if err := migrations.RestoreRepository(ctx, filepath.Join(dir, RepositoriesDir, repo.Name), u.Name, repo.Name); err != nil {
+			return nil, "", fmt.Errorf("restore %s/%s: %v", u.Name, repo.Name, err)
+		}
+	}
+	return u, passwd, nil
+}
+
+func restoreAccount(manifest *Manifest, u *models.User) error {
+	u.FullName = manifest.User.FullName
+	u.KeepEmailPrivate = manifest.User.KeepEmailPrivate
+	u.Website = manifest.User.Website
+	u.Location = manifest.User.Location
+	u.Description = manifest.User.Description
+	u.Language = manifest.User.Language
+	cols := []string{"full_name", "keep_email_private", "website", "location", "description", "language"}
+	if util.IsStringInSlice(manifest.User.Theme, setting.UI.Themes) {
+		u.Theme = manifest.User.Theme
+		cols = append(cols, "theme")
+	}
+	if visibility, ok := structs.VisibilityModes[manifest.User.Visibility]; ok {
+		u.Visibility = visibility
+		cols = append(cols, "visibility")
+	}
+	if err := models.UpdateUserCols(u, cols...); err != nil {
+		return fmt.Errorf("UpdateUserCols: %v", err)
+	}
+
+	for _, key := range manifest.PublicKeys {
+		content, err := models.CheckPublicKeyString(key.Content)
+		if err == nil {
+			_, err = models.AddPublicKey(u.ID, key.Name, content, 0)
+		}
+		if err != nil {
+			if models.IsErrKeyUnableVerify(err) || models.IsErrKeyAlreadyExist(err) || models.IsErrKeyNameAlreadyUsed(err) {
+				log.Warn("Takeout of %s: SSH key %s skipped: %v", manifest.User.Name, key.Name, err)
+				continue
+			}
+			return fmt.Errorf("AddPublicKey: %v", err)
+		}
+	}
+
+	fields, err := models.GetProfileFields()
+	if err != nil {
+		return fmt.Errorf("GetProfileFields: %v", err)
+	}
+	fieldIDs := make(map[string]int64, len(fields))
+	for _, field := range fields {
+		fieldIDs[field.Name] = field.ID
+	}
+	for _, field := range manifest.ProfileFields {
+		id, ok := fieldIDs[field.Name]
+		if !ok {
+			log.Warn("Takeout of %s: profile field %s does not exist, skipped", manifest.User.Name, field.Name)
+			continue
+		}
+		if err := models.UpdateUserProfileFields(u.ID, map[int64]string{id: field.Value}); err != nil {
+			log.Warn("Takeout of %s: profile field %s skipped: %v", manifest.User.Name, field.Name, err)
+		}
+	}
+	return nil
+}
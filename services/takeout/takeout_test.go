@@ -0,0 +1,99 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package takeout
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDump(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	dir, err := ioutil.TempDir("", "takeout")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	manifest, err := Dump(context.Background(), user2, dir, false)
+	assert.NoError(t, err)
+	assert.Equal(t, Version, manifest.Version)
+	assert.Equal(t, "user2", manifest.User.Name)
+	assert.Equal(t, "user2@example.com", manifest.User.Email)
+	assert.True(t, manifest.User.KeepEmailPrivate)
+	if assert.Len(t, manifest.PublicKeys, 1) {
+		assert.Equal(t, "user2@localhost", manifest.PublicKeys[0].Name)
+	}
+	assert.Len(t, manifest.Repositories, 9)
+	for _, repo := range manifest.Repositories {
+		// the repositories are not dumped
+		assert.Empty(t, repo.Path)
+	}
+	assert.NotEmpty(t, manifest.Issues)
+	for _, issue := range manifest.Issues {
+		assert.NotEmpty(t, issue.Repository)
+		assert.NotEmpty(t, issue.URL)
+	}
+
+	read, err := ReadManifest(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, manifest.User, read.User)
+	assert.Len(t, read.Issues, len(manifest.Issues))
+
+	// the code comments are dumped with the plain comments
+	user1 := models.AssertExistsAndLoadBean(t, &models.User{ID: 1}).(*models.User)
+	manifest, err = Dump(context.Background(), user1, dir, false)
+	assert.NoError(t, err)
+	contents := make([]string, 0, len(manifest.Comments))
+	for _, comment := range manifest.Comments {
+		contents = append(contents, comment.Content)
+	}
+	assert.Contains(t, contents, "meh...")
+	assert.Contains(t, contents, "it's already invalidated. boring...")
+}
+
+func TestRestore(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	dir, err := ioutil.TempDir("", "takeout")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	manifest, err := Dump(context.Background(), user2, dir, false)
+	assert.NoError(t, err)
+
+	// the email address must not be used by another user
+	manifest.User.Email = "takeout@example.com"
+	bs, err := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(manifest)
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, ManifestName), bs, 0644))
+
+	u, passwd, err := Restore(context.Background(), dir, "takeout")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, passwd)
+	assert.True(t, u.MustChangePassword)
+
+	restored := models.AssertExistsAndLoadBean(t, &models.User{Name: "takeout"}).(*models.User)
+	assert.Equal(t, manifest.User.FullName, restored.FullName)
+	assert.Equal(t, manifest.User.Email, restored.Email)
+	assert.True(t, restored.KeepEmailPrivate)
+	// the SSH keys of other users are skipped
+	keys, err := models.ListPublicKeys(restored.ID, models.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+
+	// the existing users are updated
+	u, passwd, err = Restore(context.Background(), dir, "takeout")
+	assert.NoError(t, err)
+	assert.Empty(t, passwd)
+	assert.Equal(t, restored.ID, u.ID)
+}
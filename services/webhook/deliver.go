@@ -12,7 +12,6 @@ import (
 	"net"
 	"net/http"
 	"net/url"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -20,7 +19,9 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
 	"code.gitea.io/gitea/modules/setting"
+
 	"github.com/gobwas/glob"
 )
 
@@ -32,7 +33,7 @@ func Deliver(t *models.HookTask) error {
 			return
 		}
 		// There was a panic whilst delivering a hook...
-		log.Error("PANIC whilst trying to deliver webhook[%d] for repo[%d] to %s Panic: %v\nStacktrace: %s", t.ID, t.RepoID, t.URL, err, log.Stack(2))
+		log.LogWithRequestID(t.RequestID, 0, log.ERROR, "PANIC whilst trying to deliver webhook[%d] for repo[%d] to %s Panic: %v\nStacktrace: %s", t.ID, t.RepoID, t.URL, err, log.Stack(2))
 	}()
 	t.IsDelivered = true
 
@@ -115,9 +116,9 @@ func Deliver(t *models.HookTask) error {
 	defer func() {
 		t.Delivered = time.Now().UnixNano()
 		if t.IsSucceed {
-			log.Trace("Hook delivered: %s", t.UUID)
+			log.LogWithRequestID(t.RequestID, 0, log.TRACE, "Hook delivered: %s", t.UUID)
 		} else {
-			log.Trace("Hook delivery failed: %s", t.UUID)
+			log.LogWithRequestID(t.RequestID, 0, log.TRACE, "Hook delivery failed: %s", t.UUID)
 		}
 
 		if err := models.UpdateHookTask(t); err != nil {
@@ -168,67 +169,81 @@ func Deliver(t *models.HookTask) error {
 	return nil
 }
 
-// DeliverHooks checks and delivers undelivered hooks.
-// FIXME: graceful: This would likely benefit from either a worker pool with dummy queue
-// or a full queue. Then more hooks could be sent at same time.
-func DeliverHooks(ctx context.Context) {
-	select {
-	case <-ctx.Done():
-		return
-	default:
+// hookQueue is the persistent queue of the IDs of the repositories with undelivered hook tasks
+var hookQueue queue.UniqueQueue
+
+// handle delivers the undelivered hook tasks of the queued repositories. Once the shutdown has begun the repositories
+// left are pushed back to the queue, which persists them to be delivered after the restart.
+func handle(data ...queue.Data) {
+	for i, datum := range data {
+		select {
+		case <-graceful.GetManager().IsShutdown():
+			for _, repoID := range data[i:] {
+				enqueueHookTasks(repoID.(int64))
+			}
+			return
+		default:
+		}
+		deliverRepoHookTasks(datum.(int64))
 	}
-	tasks, err := models.FindUndeliveredHookTasks()
+}
+
+func deliverRepoHookTasks(repoID int64) {
+	log.Trace("DeliverHooks [repo_id: %d]", repoID)
+	tasks, err := models.FindRepoUndeliveredHookTasks(repoID)
 	if err != nil {
-		log.Error("DeliverHooks: %v", err)
+		log.Error("Get repository [%d] hook tasks: %v", repoID, err)
 		return
 	}
-
-	// Update hook task status.
 	for _, t := range tasks {
 		select {
-		case <-ctx.Done():
+		case <-graceful.GetManager().IsShutdown():
+			enqueueHookTasks(repoID)
 			return
 		default:
 		}
+
+		// the task is claimed first so that it is never delivered twice, even if the repository is queued again whilst
+		// its tasks are delivered
+		if claimed, err := models.ClaimHookTask(t.ID); err != nil {
+			log.Error("ClaimHookTask [%d]: %v", t.ID, err)
+			continue
+		} else if !claimed {
+			continue
+		}
 		if err = Deliver(t); err != nil {
 			log.Error("deliver: %v", err)
 		}
 	}
+}
+
+// enqueueHookTasks queues the delivery of the undelivered hook tasks of a repository
+func enqueueHookTasks(repoID int64) {
+	if hookQueue == nil {
+		// the tasks are queued when the deliveries start
+		return
+	}
+	if err := hookQueue.Push(repoID); err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Unable to queue the hook tasks of repository %d: %v", repoID, err)
+	}
+}
 
-	// Start listening on new hook requests.
-	for {
+// populateHookQueue queues the repositories with undelivered hook tasks, the tasks having been created whilst the
+// deliveries were stopped
+func populateHookQueue(ctx context.Context) {
+	repoIDs, err := models.FindUndeliveredHookTaskRepoIDs()
+	if err != nil {
+		log.Error("FindUndeliveredHookTaskRepoIDs: %v", err)
+		return
+	}
+	for _, repoID := range repoIDs {
 		select {
 		case <-ctx.Done():
-			hookQueue.Close()
 			return
-		case repoIDStr := <-hookQueue.Queue():
-			log.Trace("DeliverHooks [repo_id: %v]", repoIDStr)
-			hookQueue.Remove(repoIDStr)
-
-			repoID, err := strconv.ParseInt(repoIDStr, 10, 64)
-			if err != nil {
-				log.Error("Invalid repo ID: %s", repoIDStr)
-				continue
-			}
-
-			tasks, err := models.FindRepoUndeliveredHookTasks(repoID)
-			if err != nil {
-				log.Error("Get repository [%d] hook tasks: %v", repoID, err)
-				continue
-			}
-			for _, t := range tasks {
-				select {
-				case <-ctx.Done():
-					return
-				default:
-				}
-				if err = Deliver(t); err != nil {
-					log.Error("deliver: %v", err)
-				}
-			}
+		default:
 		}
+		enqueueHookTasks(repoID)
 	}
-
 }
 
 var (
@@ -281,5 +296,18 @@ func InitDeliverHooks() {
 		},
 	}
 
-	go graceful.GetManager().RunWithShutdownContext(DeliverHooks)
+	// the tasks claimed but never delivered have been interrupted by a crash, they are delivered again with the same
+	// delivery UUID. This is done before any task is claimed.
+	if n, err := models.ResetInterruptedHookTasks(); err != nil {
+		log.Error("ResetInterruptedHookTasks: %v", err)
+	} else if n > 0 {
+		log.Info("Resuming %d interrupted webhook deliveries", n)
+	}
+
+	hookQueue = queue.CreateUniqueQueue("webhook_sender", handle, int64(0)).(queue.UniqueQueue)
+	if hookQueue == nil {
+		log.Fatal("Unable to create webhook_sender Queue")
+	}
+	go graceful.GetManager().RunWithShutdownFns(hookQueue.Run)
+	go graceful.GetManager().RunWithShutdownContext(populateHookQueue)
 }
@@ -5,6 +5,7 @@
 package webhook
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
@@ -16,7 +17,6 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
-	"code.gitea.io/gitea/modules/sync"
 	"github.com/gobwas/glob"
 )
 
@@ -72,9 +72,6 @@ func IsValidHookTaskType(name string) bool {
 	return ok
 }
 
-// hookQueue is a global queue of web hooks
-var hookQueue = sync.NewUniqueQueue(setting.Webhook.QueueLength)
-
 // getPayloadBranch returns branch for hook event, if applicable.
 func getPayloadBranch(p api.Payloader) string {
 	switch pp := p.(type) {
@@ -95,12 +92,13 @@ func getPayloadBranch(p api.Payloader) string {
 }
 
 // PrepareWebhook adds special webhook to task queue for given payload.
-func PrepareWebhook(w *models.Webhook, repo *models.Repository, event models.HookEventType, p api.Payloader) error {
-	if err := prepareWebhook(w, repo, event, p); err != nil {
+// The ID of the request carried by the context is recorded for the delivery logs.
+func PrepareWebhook(ctx context.Context, w *models.Webhook, repo *models.Repository, event models.HookEventType, p api.Payloader) error {
+	if err := prepareWebhook(log.RequestIDFromContext(ctx), w, repo, event, p); err != nil {
 		return err
 	}
 
-	go hookQueue.Add(repo.ID)
+	go enqueueHookTasks(repo.ID)
 	return nil
 }
 
@@ -119,7 +117,7 @@ func checkBranch(w *models.Webhook, branch string) bool {
 	return g.Match(branch)
 }
 
-func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.HookEventType, p api.Payloader) error {
+func prepareWebhook(requestID string, w *models.Webhook, repo *models.Repository, event models.HookEventType, p api.Payloader) error {
 	// Skip sending if webhooks are disabled.
 	if setting.DisableWebhooks {
 		return nil
@@ -190,6 +188,7 @@ func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 		ContentType: w.ContentType,
 		EventType:   event,
 		IsSSL:       w.IsSSL,
+		RequestID:   requestID,
 	}); err != nil {
 		return fmt.Errorf("CreateHookTask: %v", err)
 	}
@@ -197,16 +196,17 @@ func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 }
 
 // PrepareWebhooks adds new webhooks to task queue for given payload.
-func PrepareWebhooks(repo *models.Repository, event models.HookEventType, p api.Payloader) error {
-	if err := prepareWebhooks(repo, event, p); err != nil {
+// The ID of the request carried by the context is recorded for the delivery logs.
+func PrepareWebhooks(ctx context.Context, repo *models.Repository, event models.HookEventType, p api.Payloader) error {
+	if err := prepareWebhooks(log.RequestIDFromContext(ctx), repo, event, p); err != nil {
 		return err
 	}
 
-	go hookQueue.Add(repo.ID)
+	go enqueueHookTasks(repo.ID)
 	return nil
 }
 
-func prepareWebhooks(repo *models.Repository, event models.HookEventType, p api.Payloader) error {
+func prepareWebhooks(requestID string, repo *models.Repository, event models.HookEventType, p api.Payloader) error {
 	ws, err := models.GetActiveWebhooksByRepoID(repo.ID)
 	if err != nil {
 		return fmt.Errorf("GetActiveWebhooksByRepoID: %v", err)
@@ -234,7 +234,7 @@ func prepareWebhooks(repo *models.Repository, event models.HookEventType, p api.
 	}
 
 	for _, w := range ws {
-		if err = prepareWebhook(w, repo, event, p); err != nil {
+		if err = prepareWebhook(requestID, w, repo, event, p); err != nil {
 			return err
 		}
 	}
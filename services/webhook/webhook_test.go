@@ -5,6 +5,7 @@
 package webhook
 
 import (
+	"context"
 	"testing"
 
 	"code.gitea.io/gitea/models"
@@ -34,7 +35,7 @@ func TestPrepareWebhooks(t *testing.T) {
 	for _, hookTask := range hookTasks {
 		models.AssertNotExistsBean(t, hookTask)
 	}
-	assert.NoError(t, PrepareWebhooks(repo, models.HookEventPush, &api.PushPayload{Commits: []*api.PayloadCommit{{}}}))
+	assert.NoError(t, PrepareWebhooks(context.Background(), repo, models.HookEventPush, &api.PushPayload{Commits: []*api.PayloadCommit{{}}}))
 	for _, hookTask := range hookTasks {
 		models.AssertExistsAndLoadBean(t, hookTask)
 	}
@@ -51,7 +52,7 @@ func TestPrepareWebhooksBranchFilterMatch(t *testing.T) {
 		models.AssertNotExistsBean(t, hookTask)
 	}
 	// this test also ensures that * doesn't handle / in any special way (like shell would)
-	assert.NoError(t, PrepareWebhooks(repo, models.HookEventPush, &api.PushPayload{Ref: "refs/heads/feature/7791", Commits: []*api.PayloadCommit{{}}}))
+	assert.NoError(t, PrepareWebhooks(context.Background(), repo, models.HookEventPush, &api.PushPayload{Ref: "refs/heads/feature/7791", Commits: []*api.PayloadCommit{{}}}))
 	for _, hookTask := range hookTasks {
 		models.AssertExistsAndLoadBean(t, hookTask)
 	}
@@ -67,7 +68,7 @@ func TestPrepareWebhooksBranchFilterNoMatch(t *testing.T) {
 	for _, hookTask := range hookTasks {
 		models.AssertNotExistsBean(t, hookTask)
 	}
-	assert.NoError(t, PrepareWebhooks(repo, models.HookEventPush, &api.PushPayload{Ref: "refs/heads/fix_weird_bug"}))
+	assert.NoError(t, PrepareWebhooks(context.Background(), repo, models.HookEventPush, &api.PushPayload{Ref: "refs/heads/fix_weird_bug"}))
 
 	for _, hookTask := range hookTasks {
 		models.AssertNotExistsBean(t, hookTask)
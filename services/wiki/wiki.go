@@ -223,6 +223,8 @@ func updateWikiPage(doer *models.User, repo *models.Repository, oldWikiName, new
 		}
 		return fmt.Errorf("Push: %v", err)
 	}
+	git.InvalidateRefsCache(repo.WikiPath())
+	git.InvalidatePackCache(repo.WikiPath())
 
 	return nil
 }
@@ -335,6 +337,8 @@ func DeleteWikiPage(doer *models.User, repo *models.Repository, wikiName string)
 		}
 		return fmt.Errorf("Push: %v", err)
 	}
+	git.InvalidateRefsCache(repo.WikiPath())
+	git.InvalidatePackCache(repo.WikiPath())
 
 	return nil
 }
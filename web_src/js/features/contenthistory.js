@@ -0,0 +1,11 @@
+export default function initContentHistory() {
+  const $modal = $('#content-history-modal');
+  if ($modal.length === 0) return;
+
+  $(document).on('click', '.content-history-link', async function (e) {
+    e.preventDefault();
+    const html = await $.get($(this).attr('href'));
+    $modal.find('.content').empty().append($(html).find('#content-history'));
+    $modal.modal('show');
+  });
+}
@@ -0,0 +1,85 @@
+const {AppSubUrl, csrf, NotificationSettings} = window.config;
+
+// The server forgets the subscriptions which are not renewed within two minutes
+const renewInterval = 60 * 1000;
+
+export default function initLiveUpdate(initMergeBox) {
+  const $liveUpdate = $('#live-update');
+  if ($liveUpdate.length === 0 || NotificationSettings.EventSourceUpdateTime <= 0 || !window.EventSource || !window.SharedWorker) return;
+
+  const topics = `${$liveUpdate.data('topics')}`.split(' ');
+  const userId = $liveUpdate.data('user-id');
+
+  const subscribe = async () => {
+    try {
+      await $.ajax({
+        type: 'POST',
+        url: `${AppSubUrl}/user/events/subscribe`,
+        data: {_csrf: csrf, topic: topics},
+        traditional: true,
+      });
+    } catch (error) {
+      console.error(error);
+    }
+  };
+
+  const updateMergeBox = async () => {
+    const $mergeBox = $('#pull-merge-box');
+    // Do not throw away a merge form the user has started to fill in
+    if ($mergeBox.length === 0 || $mergeBox.find('.form:visible').length !== 0) return;
+    const html = await $.get(window.location.href);
+    $mergeBox.replaceWith($(html).find('#pull-merge-box'));
+    initMergeBox();
+  };
+
+  const receiveLiveUpdate = async (event) => {
+    try {
+      const data = JSON.parse(event.data);
+      if (!topics.includes(data.topic)) return;
+      if (data.type === 'comment') {
+        if (data.doer_id !== userId) $liveUpdate.removeClass('hide');
+      } else {
+        await updateMergeBox();
+      }
+    } catch (error) {
+      console.error(error, event);
+    }
+  };
+
+  const worker = new SharedWorker(`${__webpack_public_path__}js/eventsource.sharedworker.js`, 'notification-worker');
+  worker.addEventListener('error', (event) => {
+    console.error(event);
+  });
+  worker.port.onmessageerror = () => {
+    console.error('Unable to deserialize message');
+  };
+  worker.port.postMessage({
+    type: 'start',
+    url: `${window.location.origin}${AppSubUrl}/user/events`,
+  });
+  worker.port.postMessage({
+    type: 'listen',
+    eventType: 'live-update',
+  });
+  worker.port.addEventListener('message', (event) => {
+    if (!event.data || !event.data.type) {
+      console.error(event);
+      return;
+    }
+    if (event.data.type === 'live-update') {
+      receiveLiveUpdate(event.data);
+    } else if (event.data.type === 'error') {
+      console.error(event.data);
+    }
+  });
+  worker.port.start();
+  window.addEventListener('beforeunload', () => {
+    worker.port.postMessage({
+      type: 'close',
+    });
+    worker.port.close();
+  });
+
+  subscribe();
+  setInterval(subscribe, renewInterval);
+}
@@ -0,0 +1,13 @@
+export default function initMergeTrain() {
+  const $train = $('#merge-train');
+  if ($train.length === 0 || !window.EventSource) return;
+
+  const source = new EventSource($train.data('events-url'));
+  source.addEventListener('merge-train', async () => {
+    const html = await $.get(window.location.href);
+    $('#merge-train-content').replaceWith($(html).find('#merge-train-content'));
+  });
+  window.addEventListener('beforeunload', () => {
+    source.close();
+  });
+}
@@ -4,6 +4,7 @@ const $pass = $('#auth_password');
 const $token = $('#auth_token');
 const $mirror = $('#mirror');
 const $items = $('#migrate_items').find('input[type=checkbox]');
+const $convertCI = $('#convert_ci');
 
 export default function initMigration() {
   checkAuth();
@@ -11,7 +12,11 @@ export default function initMigration() {
   $user.on('keyup', () => {checkItems(false)});
   $pass.on('keyup', () => {checkItems(false)});
   $token.on('keyup', () => {checkItems(true)});
-  $mirror.on('change', () => {checkItems(true)});
+  $mirror.on('change', () => {
+    checkItems(true);
+    // mirrors keep the content of the original repository
+    $convertCI.attr('disabled', $mirror.is(':checked'));
+  });
 
   const $cloneAddr = $('#clone_addr');
   $cloneAddr.on('change', () => {
@@ -36,27 +36,44 @@ export default async function initProject() {
     },
   );
 
+  // updates the number of issues of a column and warns when its work in progress limit is exceeded
+  const updateCardCount = (column) => {
+    const count = column.getElementsByClassName('board-card').length;
+    const counter = column.querySelector('.board-card-count');
+    const limit = parseInt(counter.dataset.wipLimit) || 0;
+    counter.textContent = limit > 0 ? `${count}/${limit}` : `${count}`;
+    counter.classList.toggle('red', limit > 0 && count > limit);
+    column.classList.toggle('wip-exceeded', limit > 0 && count > limit);
+  };
+
   for (const column of boardColumns) {
-    new Sortable(
-      column.getElementsByClassName('board')[0],
-      {
-        group: 'shared',
-        animation: 150,
-        onAdd: (e) => {
-          $.ajax(`${e.to.dataset.url}/${e.item.dataset.issue}`, {
-            headers: {
-              'X-Csrf-Token': csrf,
-              'X-Remote': true,
-            },
-            contentType: 'application/json',
-            type: 'POST',
-            error: () => {
-              e.from.insertBefore(e.item, e.from.children[e.oldIndex]);
-            },
-          });
+    // the issues can only be moved to another board of their swimlane
+    for (const cards of column.getElementsByClassName('board')) {
+      new Sortable(
+        cards,
+        {
+          group: `shared-${cards.dataset.swimlane}`,
+          animation: 150,
+          onAdd: (e) => {
+            updateCardCount(e.from.closest('.board-column'));
+            updateCardCount(e.to.closest('.board-column'));
+            $.ajax(`${e.to.dataset.url}/${e.item.dataset.issue}`, {
+              headers: {
+                'X-Csrf-Token': csrf,
+                'X-Remote': true,
+              },
+              contentType: 'application/json',
+              type: 'POST',
+              error: () => {
+                e.from.insertBefore(e.item, e.from.children[e.oldIndex]);
+                updateCardCount(e.from.closest('.board-column'));
+                updateCardCount(e.to.closest('.board-column'));
+              },
+            });
+          },
         },
-      },
-    );
+      );
+    }
   }
 
   $('.edit-project-board').each(function () {
@@ -64,6 +81,11 @@ export default async function initProject() {
     const projectTitleInput = $(this).find(
       '.content > .form > .field > .project-board-title',
     );
+    const wipLimitInput = $(this).find('.project-board-wip-limit');
+    const autoMoveOnCloseInput = $(this).find('.project-board-auto-move-on-close');
+    const autoMoveOnMergeInput = $(this).find('.project-board-auto-move-on-merge');
+    // only the boards of the organization projects have a repository filter
+    const repoFilterInput = $(this).find('.project-board-repo-filter');
 
     $(this)
       .find('.content > .form > .actions > .red')
@@ -72,7 +94,13 @@ export default async function initProject() {
 
         $.ajax({
           url: $(this).data('url'),
-          data: JSON.stringify({title: projectTitleInput.val()}),
+          data: JSON.stringify({
+            title: projectTitleInput.val(),
+            wip_limit: parseInt(wipLimitInput.val()) || 0,
+            auto_move_on_close: autoMoveOnCloseInput.is(':checked'),
+            auto_move_on_merge: autoMoveOnMergeInput.is(':checked'),
+            repo_filter: repoFilterInput.length ? repoFilterInput.val() : undefined,
+          }),
           headers: {
             'X-Csrf-Token': csrf,
             'X-Remote': true,
@@ -82,7 +110,8 @@ export default async function initProject() {
         }).done(() => {
           projectTitleLabel.text(projectTitleInput.val());
           projectTitleInput.closest('form').removeClass('dirty');
-          $('.ui.modal').modal('hide');
+          // the limit and the automation of the other boards may have changed too
+          window.location.reload();
         });
       });
   });
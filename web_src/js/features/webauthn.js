@@ -0,0 +1,119 @@
+const {AppSubUrl, csrf} = window.config;
+
+function decodeBase64Url(str) {
+  const base64 = str.replace(/-/g, '+').replace(/_/g, '/');
+  return Uint8Array.from(atob(base64), (c) => c.charCodeAt(0));
+}
+
+function encodeBase64Url(buffer) {
+  return btoa(String.fromCharCode(...new Uint8Array(buffer)))
+    .replace(/\+/g, '-')
+    .replace(/\//g, '_')
+    .replace(/=/g, '');
+}
+
+function decodeDescriptors(descriptors) {
+  return (descriptors || []).map((d) => ({...d, id: decodeBase64Url(d.id)}));
+}
+
+function postJSON(url, data) {
+  return fetch(url, {
+    method: 'POST',
+    headers: {'X-Csrf-Token': csrf, 'Content-Type': 'application/json; charset=utf-8'},
+    body: JSON.stringify(data),
+  });
+}
+
+async function registerCredential() {
+  const $error = $('#webauthn-error');
+  const $nickname = $('#webauthn-nickname');
+  $error.addClass('hide');
+
+  const res = await fetch(`${AppSubUrl}/user/settings/security/webauthn/request_register`, {
+    method: 'POST',
+    body: new URLSearchParams({_csrf: csrf, name: $nickname.val()}),
+  });
+  if (res.status === 409) {
+    $nickname.closest('div.field').addClass('error');
+    return;
+  }
+  $nickname.closest('div.field').removeClass('error');
+  if (!res.ok) throw new Error('request_register failed');
+
+  const options = await res.json();
+  options.challenge = decodeBase64Url(options.challenge);
+  options.user.id = decodeBase64Url(options.user.id);
+  options.excludeCredentials = decodeDescriptors(options.excludeCredentials);
+
+  const credential = await navigator.credentials.create({publicKey: options});
+  const registered = await postJSON(`${AppSubUrl}/user/settings/security/webauthn/register`, {
+    id: credential.id,
+    type: credential.type,
+    response: {
+      clientDataJSON: encodeBase64Url(credential.response.clientDataJSON),
+      attestationObject: encodeBase64Url(credential.response.attestationObject),
+    },
+  });
+  if (!registered.ok) throw new Error('register failed');
+  window.location.reload();
+}
+
+async function signInPasswordless() {
+  const res = await fetch(`${AppSubUrl}/user/webauthn/assertion`);
+  if (!res.ok) throw new Error('assertion failed');
+
+  const options = await res.json();
+  options.challenge = decodeBase64Url(options.challenge);
+  options.allowCredentials = decodeDescriptors(options.allowCredentials);
+
+  const credential = await navigator.credentials.get({publicKey: options});
+  const signedIn = await postJSON(`${AppSubUrl}/user/webauthn/passwordless`, {
+    id: credential.id,
+    type: credential.type,
+    response: {
+      clientDataJSON: encodeBase64Url(credential.response.clientDataJSON),
+      authenticatorData: encodeBase64Url(credential.response.authenticatorData),
+      signature: encodeBase64Url(credential.response.signature),
+      userHandle: credential.response.userHandle ? encodeBase64Url(credential.response.userHandle) : '',
+    },
+  });
+  const data = await signedIn.json().catch(() => ({}));
+  if (data.redirect) {
+    window.location.href = data.redirect;
+    return;
+  }
+  throw new Error('passwordless sign in failed');
+}
+
+export default function initWebAuthn() {
+  const $register = $('#register-webauthn-credential');
+  const $signIn = $('#webauthn-passwordless-signin');
+  if (!$register.length && !$signIn.length) return;
+
+  if (!window.PublicKeyCredential) {
+    $register.addClass('disabled');
+    $signIn.closest('.segment').addClass('hide');
+    return;
+  }
+
+  $register.on('click', async (e) => {
+    e.preventDefault();
+    try {
+      await registerCredential();
+    } catch (err) {
+      console.error(err);
+      $('#webauthn-error').removeClass('hide');
+    }
+  });
+
+  $signIn.on('click', async (e) => {
+    e.preventDefault();
+    $('#webauthn-passwordless-error').addClass('hide');
+    try {
+      await signInPasswordless();
+    } catch (err) {
+      console.error(err);
+      $('#webauthn-passwordless-error').removeClass('hide');
+    }
+  });
+}
@@ -8,6 +8,9 @@ import 'jquery.are-you-sure';
 import './vendor/semanticdropdown.js';
 
 import initMigration from './features/migration.js';
+import initMergeTrain from './features/mergetrain.js';
+import initContentHistory from './features/contenthistory.js';
+import initLiveUpdate from './features/liveupdate.js';
 import initContextPopups from './features/contextpopup.js';
 import initGitGraph from './features/gitgraph.js';
 import initClipboard from './features/clipboard.js';
@@ -20,6 +23,7 @@ import attachTribute from './features/tribute.js';
 import createColorPicker from './features/colorpicker.js';
 import createDropzone from './features/dropzone.js';
 import initTableSort from './features/tablesort.js';
+import initWebAuthn from './features/webauthn.js';
 import initImageDiff from './features/imagediff.js';
 import ActivityTopAuthors from './components/ActivityTopAuthors.vue';
 import {initNotificationsTable, initNotificationCount} from './features/notification.js';
@@ -375,7 +379,7 @@ function initCommentForm() {
     return;
   }
 
-  autoSimpleMDE = setCommentSimpleMDE($('.comment.form textarea:not(.review-textarea)'));
+  autoSimpleMDE = setCommentSimpleMDE($('.comment.form textarea:not(.review-textarea):not(.issue-form-textarea)'));
   initBranchSelector();
   initCommentPreviewTab($('.comment.form'));
   initImagePaste($('.comment.form textarea'));
@@ -527,6 +531,8 @@ function initCommentForm() {
         icon = svg('octicon-project', 18, 'mr-3');
       } else if (input_id === '#assignee_id') {
         icon = `<img class="ui avatar image mr-3" src=${$(this).data('avatar')}>`;
+      } else if (input_id === '#issue_type_id') {
+        icon = `<span class="label color" style="background-color: ${htmlEscape($(this).data('color'))}"></span>`;
       }
 
       $list.find('.selected').html(`
@@ -559,10 +565,11 @@ function initCommentForm() {
     });
   }
 
-  // Milestone, Assignee, Project
+  // Milestone, Assignee, Project, Issue type
   selectItem('.select-project', '#project_id');
   selectItem('.select-milestone', '#milestone_id');
   selectItem('.select-assignee', '#assignee_id');
+  selectItem('.select-issue-type', '#issue_type_id');
 }
 
 function initInstall() {
@@ -717,6 +724,12 @@ function getArchive($target, url, first) {
           return;
         }
 
+        if (xhr.responseJSON.status === 'failed') {
+          // The archive could not be generated, polling again would not help
+          $target.closest('.dropdown').children('i').removeClass('loading');
+          return;
+        }
+
         if (!xhr.responseJSON.complete) {
           $target.closest('.dropdown').children('i').addClass('loading');
           // Wait for only three quarters of a second initially, in case it's
@@ -750,6 +763,73 @@ function initArchiveLinks() {
   });
 }
 
+function initPullRequestMergeBox() {
+  // Pull Request merge button
+  const $mergeButton = $('.merge-button > button');
+  $mergeButton.on('click', function (e) {
+    e.preventDefault();
+    $(`.${$(this).data('do')}-fields`).show();
+    $(this).parent().hide();
+    $('.instruct-toggle').hide();
+    $('.instruct-content').hide();
+  });
+  $('.merge-button > .dropdown').dropdown({
+    onChange(_text, _value, $choice) {
+      if ($choice.data('do')) {
+        $mergeButton.find('.button-text').text($choice.text());
+        $mergeButton.data('do', $choice.data('do'));
+      }
+    }
+  });
+  $('.merge-cancel').on('click', function (e) {
+    e.preventDefault();
+    $(this).closest('.form').hide();
+    $mergeButton.parent().show();
+    $('.instruct-toggle').show();
+  });
+}
+
+async function loadIssueTimelinePage($loader) {
+  const $button = $loader.find('a');
+  if ($button.hasClass('loading')) return;
+  $button.addClass('loading disabled');
+  const html = await $.get($button.attr('href'));
+  // The fetched page links back to the events which are shown already
+  const shown = $loader.attr('id') === 'timeline-load-older' ? '#timeline-load-newer' : '#timeline-load-older';
+  const $items = $(html).find('#issue-timeline-events').children().not(shown);
+  $loader.replaceWith($items);
+
+  $items.find('.context-dropdown').dropdown({
+    action: 'hide'
+  });
+  $items.find('.dropdown:not(.custom):not(.context-dropdown)').dropdown({
+    fullTextSearch: 'exact'
+  });
+  $items.find('.comment-header-right, .reactions').each((_, el) => {
+    initReactionSelector($(el));
+  });
+  await renderMarkdownContent();
+}
+
+async function initIssueTimelinePages() {
+  if ($('#issue-timeline-events').length === 0) return;
+
+  $(document).on('click', '#timeline-load-older a, #timeline-load-newer a', async function (e) {
+    e.preventDefault();
+    await loadIssueTimelinePage($(this).closest('.timeline-item'));
+  });
+
+  // Load the older events until a linked comment is found
+  const hash = window.location.hash;
+  if (!/^#issuecomment-\d+$/.test(hash)) return;
+  while ($(hash).length === 0 && $('#timeline-load-older').length !== 0) {
+    await loadIssueTimelinePage($('#timeline-load-older'));
+  }
+  if ($(hash).length !== 0) {
+    $(window).scrollTop($(hash).offset().top);
+  }
+}
+
 async function initRepository() {
   if ($('.repository').length === 0) {
     return;
@@ -1163,29 +1243,7 @@ async function initRepository() {
       $('#comment-form').trigger('submit');
     });
 
-    // Pull Request merge button
-    const $mergeButton = $('.merge-button > button');
-    $mergeButton.on('click', function (e) {
-      e.preventDefault();
-      $(`.${$(this).data('do')}-fields`).show();
-      $(this).parent().hide();
-      $('.instruct-toggle').hide();
-      $('.instruct-content').hide();
-    });
-    $('.merge-button > .dropdown').dropdown({
-      onChange(_text, _value, $choice) {
-        if ($choice.data('do')) {
-          $mergeButton.find('.button-text').text($choice.text());
-          $mergeButton.data('do', $choice.data('do'));
-        }
-      }
-    });
-    $('.merge-cancel').on('click', function (e) {
-      e.preventDefault();
-      $(this).closest('.form').hide();
-      $mergeButton.parent().show();
-      $('.instruct-toggle').show();
-    });
+    initPullRequestMergeBox();
     initReactionSelector();
   }
 
@@ -1330,6 +1388,7 @@ function initPullRequestReview() {
     $(this).closest('.menu').toggle('visible');
   });
 
+  let lastCodeCommentLine = null;
   $('a.add-code-comment').on('click', async function (e) {
     if ($(e.target).hasClass('btn-add-single')) return; // https://github.com/go-gitea/gitea/issues/4745
     e.preventDefault();
@@ -1341,6 +1400,20 @@ function initPullRequestReview() {
     const tr = $(this).closest('tr');
     const lineType = tr.data('line-type');
 
+    // Shift-click extends the comment to the range from the previously clicked line of the same side
+    let startIdx = 0;
+    const last = lastCodeCommentLine;
+    if (e.shiftKey && last && last.path === path && last.side === side && last.idx < idx) {
+      startIdx = last.idx;
+    }
+    lastCodeCommentLine = {path, side, idx};
+    $(`a.add-code-comment[data-path="${path}"][data-side="${side}"]`).each(function () {
+      const lineIdx = $(this).data('idx');
+      if (startIdx && lineIdx >= startIdx && lineIdx <= idx) {
+        $(this).closest('tr').addClass('commented-range');
+      }
+    });
+
     let ntr = tr.next();
     if (!ntr.hasClass('add-comment')) {
       ntr = $(`
@@ -1368,6 +1441,7 @@ function initPullRequestReview() {
       commentCloud = td.find('.comment-code-cloud');
       assingMenuAttributes(commentCloud.find('.menu'));
       td.find("input[name='line']").val(idx);
+      if (startIdx) td.find("input[name='start_line']").val(startIdx);
       td.find("input[name='side']").val(side === 'left' ? 'previous' : 'proposed');
       td.find("input[name='path']").val(path);
       const $textarea = commentCloud.find('textarea');
@@ -1979,9 +2053,9 @@ function initAdmin() {
   // New authentication
   if ($('.admin.new.authentication').length > 0) {
     $('#auth_type').on('change', function () {
-      $('.ldap, .dldap, .smtp, .pam, .oauth2, .has-tls, .search-page-size, .sspi').hide();
+      $('.ldap, .dldap, .smtp, .pam, .oauth2, .has-tls, .search-page-size, .sspi, .saml').hide();
 
-      $('.ldap input[required], .binddnrequired input[required], .dldap input[required], .smtp input[required], .pam input[required], .oauth2 input[required], .has-tls input[required], .sspi input[required]').removeAttr('required');
+      $('.ldap input[required], .binddnrequired input[required], .dldap input[required], .smtp input[required], .pam input[required], .oauth2 input[required], .has-tls input[required], .sspi input[required], .saml input[required], .saml textarea[required]').removeAttr('required');
       $('.binddnrequired').removeClass('required');
 
       const authType = $(this).val();
@@ -2013,6 +2087,10 @@ function initAdmin() {
           $('.sspi').show();
           $('.sspi div.required input').attr('required', 'required');
           break;
+        case '8': // SAML
+          $('.saml').show();
+          $('.saml div.required input, .saml div.required textarea').attr('required', 'required');
+          break;
       }
       if (authType === '2' || authType === '5') {
         onSecurityProtocolChange();
@@ -2744,6 +2822,7 @@ $(document).ready(async () => {
   initTopicbar();
   initU2FAuth();
   initU2FRegister();
+  initWebAuthn();
   initIssueList();
   initIssueTimetracking();
   initIssueDue();
@@ -2758,6 +2837,10 @@ $(document).ready(async () => {
   initPullRequestMergeInstruction();
   initReleaseEditor();
   initRelease();
+  initMergeTrain();
+  initContentHistory();
+  initLiveUpdate(initPullRequestMergeBox);
+  initIssueTimelinePages();
 
   const routes = {
     'div.user.settings': initUserSettings,